@@ -22,19 +22,32 @@ import (
 
 	"github.com/go-logr/logr"
 	"go.opentelemetry.io/otel"
+	"k8s.io/apimachinery/pkg/util/version"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/rest"
 	cruntime "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
 
+	"github.com/crunchydata/postgres-operator/internal/agent"
 	"github.com/crunchydata/postgres-operator/internal/bridge"
 	"github.com/crunchydata/postgres-operator/internal/bridge/crunchybridgecluster"
+	"github.com/crunchydata/postgres-operator/internal/controller/pgclone"
+	"github.com/crunchydata/postgres-operator/internal/controller/pgsqlrequest"
+	"github.com/crunchydata/postgres-operator/internal/controller/pgsupportbundle"
+	"github.com/crunchydata/postgres-operator/internal/controller/pgtopology"
 	"github.com/crunchydata/postgres-operator/internal/controller/pgupgrade"
 	"github.com/crunchydata/postgres-operator/internal/controller/postgrescluster"
+	"github.com/crunchydata/postgres-operator/internal/controller/postgresdatabaseclaim"
 	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
 	"github.com/crunchydata/postgres-operator/internal/controller/standalone_pgadmin"
+	"github.com/crunchydata/postgres-operator/internal/dashboard"
+	"github.com/crunchydata/postgres-operator/internal/lifecycle"
 	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/management"
 	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/osb"
+	"github.com/crunchydata/postgres-operator/internal/servicemesh"
 	"github.com/crunchydata/postgres-operator/internal/upgradecheck"
 	"github.com/crunchydata/postgres-operator/internal/util"
 )
@@ -58,6 +71,12 @@ func initLogging() {
 }
 
 func main() {
+	// Run only the agent when asked; it runs in PostgreSQL instance Pods.
+	if len(os.Args) > 1 && os.Args[1] == "agent" {
+		runAgent()
+		return
+	}
+
 	// Set any supplied feature gates; panic on any unrecognized feature gate
 	err := util.AddAndSetFeatureGates(os.Getenv("PGO_FEATURE_GATES"))
 	assertNoError(err)
@@ -88,7 +107,8 @@ func main() {
 	// deprecation warnings when using an older version of a resource for backwards compatibility).
 	rest.SetDefaultWarningHandler(rest.NoWarnings{})
 
-	mgr, err := runtime.CreateRuntimeManager(os.Getenv("PGO_TARGET_NAMESPACE"), cfg, false)
+	mgr, err := runtime.CreateRuntimeManager(
+		os.Getenv("PGO_TARGET_NAMESPACE"), os.Getenv("PGO_CACHE_SELECTOR"), cfg, false)
 	assertNoError(err)
 
 	openshift := isOpenshift(cfg)
@@ -96,6 +116,11 @@ func main() {
 		log.Info("detected OpenShift environment")
 	}
 
+	// Service mesh proxies in Jobs are native sidecars when Kubernetes runs
+	// them. Otherwise, each Job stops its proxy when its command exits.
+	servicemesh.NativeSidecars = hasNativeSidecars(cfg)
+	log.Info("detected native sidecar containers", "enabled", servicemesh.NativeSidecars)
+
 	// add all PostgreSQL Operator controllers to the runtime manager
 	addControllersToManager(mgr, openshift, log)
 
@@ -109,6 +134,34 @@ func main() {
 		assertNoError(bridge.ManagedInstallationReconciler(mgr, constructor))
 	}
 
+	// Serve the Open Service Broker API when an address is set
+	if address := os.Getenv("PGO_OSB_ADDRESS"); address != "" {
+		assertNoError(osb.ManagedBroker(mgr, address,
+			os.Getenv("PGO_OSB_TLS_CERT"), os.Getenv("PGO_OSB_TLS_KEY"),
+			os.Getenv("PGO_OSB_NAMESPACE"),
+			os.Getenv("PGO_OSB_USERNAME"), os.Getenv("PGO_OSB_PASSWORD")))
+	}
+
+	// Serve the dashboard when an address is set. It listens on the loopback
+	// interface unless the address has a host.
+	if address := os.Getenv("PGO_DASHBOARD_ADDRESS"); address != "" {
+		assertNoError(dashboard.ManagedDashboard(mgr, address))
+	}
+
+	// Serve the management API when an address is set
+	if address := os.Getenv("PGO_MANAGEMENT_ADDRESS"); address != "" {
+		assertNoError(management.ManagedServer(mgr, address,
+			os.Getenv("PGO_MANAGEMENT_TLS_CERT"), os.Getenv("PGO_MANAGEMENT_TLS_KEY")))
+	}
+
+	// Serve the conversion webhook when a directory of certificates is set.
+	// The directory must contain "tls.crt" and "tls.key".
+	if directory := os.Getenv("PGO_WEBHOOK_CERT_DIR"); directory != "" {
+		server := mgr.GetWebhookServer()
+		server.CertDir = directory
+		server.Register("/convert", &conversion.Webhook{})
+	}
+
 	// Enable upgrade checking
 	upgradeCheckingDisabled := strings.EqualFold(os.Getenv("CHECK_FOR_UPGRADES"), "false")
 	if !upgradeCheckingDisabled {
@@ -126,6 +179,20 @@ func main() {
 	log.Info("signal received, exiting")
 }
 
+// runAgent serves the agent until a SIGTERM or SIGINT. It is configured by
+// environment variables that the operator sets on instance Pods.
+func runAgent() {
+	initLogging()
+
+	ctx := cruntime.SetupSignalHandler()
+	assertNoError((&agent.Server{
+		Address:  os.Getenv("PGO_AGENT_ADDRESS"),
+		CAFile:   os.Getenv("PGO_AGENT_CA_FILE"),
+		CertFile: os.Getenv("PGO_AGENT_CERT_FILE"),
+		KeyFile:  os.Getenv("PGO_AGENT_KEY_FILE"),
+	}).Start(ctx))
+}
+
 // addControllersToManager adds all PostgreSQL Operator controllers to the provided controller
 // runtime manager.
 func addControllersToManager(mgr manager.Manager, openshift bool, log logr.Logger) {
@@ -147,6 +214,18 @@ func addControllersToManager(mgr manager.Manager, openshift bool, log logr.Logge
 		Tracer:          otel.Tracer(postgrescluster.ControllerName),
 	}
 
+	// Call hooks compiled into the operator and any webhooks at points in the
+	// life of each cluster.
+	hooks := lifecycle.Registered()
+	for _, url := range strings.Split(os.Getenv("PGO_LIFECYCLE_WEBHOOKS"), ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			hooks = append(hooks, lifecycle.Webhook{URL: url})
+		}
+	}
+	if len(hooks) > 0 {
+		pgReconciler.LifecycleHooks = hooks
+	}
+
 	if err := pgReconciler.SetupWithManager(mgr); err != nil {
 		log.Error(err, "unable to create PostgresCluster controller")
 		os.Exit(1)
@@ -163,6 +242,62 @@ func addControllersToManager(mgr manager.Manager, openshift bool, log logr.Logge
 		os.Exit(1)
 	}
 
+	cloneReconciler := &pgclone.PGCloneReconciler{
+		Client: mgr.GetClient(),
+		Owner:  "pgclone-controller",
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err := cloneReconciler.SetupWithManager(mgr); err != nil {
+		log.Error(err, "unable to create PGClone controller")
+		os.Exit(1)
+	}
+
+	claimReconciler := &postgresdatabaseclaim.DatabaseClaimReconciler{
+		Client: mgr.GetClient(),
+		Owner:  "postgresdatabaseclaim-controller",
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err := claimReconciler.SetupWithManager(mgr); err != nil {
+		log.Error(err, "unable to create PostgresDatabaseClaim controller")
+		os.Exit(1)
+	}
+
+	supportBundleReconciler := &pgsupportbundle.PGSupportBundleReconciler{
+		Client: mgr.GetClient(),
+		Owner:  "pgsupportbundle-controller",
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err := supportBundleReconciler.SetupWithManager(mgr); err != nil {
+		log.Error(err, "unable to create PGSupportBundle controller")
+		os.Exit(1)
+	}
+
+	sqlRequestReconciler := &pgsqlrequest.PGSQLRequestReconciler{
+		Client:   mgr.GetClient(),
+		Owner:    "pgsqlrequest-controller",
+		Recorder: mgr.GetEventRecorderFor("pgsqlrequest-controller"),
+		Scheme:   mgr.GetScheme(),
+	}
+
+	if err := sqlRequestReconciler.SetupWithManager(mgr); err != nil {
+		log.Error(err, "unable to create PGSQLRequest controller")
+		os.Exit(1)
+	}
+
+	topologyReconciler := &pgtopology.PGTopologyReconciler{
+		Client: mgr.GetClient(),
+		Owner:  "pgtopology-controller",
+		Scheme: mgr.GetScheme(),
+	}
+
+	if err := topologyReconciler.SetupWithManager(mgr); err != nil {
+		log.Error(err, "unable to create PGTopology controller")
+		os.Exit(1)
+	}
+
 	pgAdminReconciler := &standalone_pgadmin.PGAdminReconciler{
 		Client:      mgr.GetClient(),
 		Owner:       "pgadmin-controller",
@@ -199,6 +334,22 @@ func addControllersToManager(mgr manager.Manager, openshift bool, log logr.Logge
 	}
 }
 
+// hasNativeSidecars returns whether the Kubernetes API runs native sidecar
+// containers by default, which it does since 1.29.
+// - https://kubernetes.io/docs/concepts/workloads/pods/sidecar-containers/
+func hasNativeSidecars(cfg *rest.Config) bool {
+	client, err := discovery.NewDiscoveryClientForConfig(cfg)
+	assertNoError(err)
+
+	info, err := client.ServerVersion()
+	assertNoError(err)
+
+	server, err := version.ParseGeneric(info.GitVersion)
+	assertNoError(err)
+
+	return server.AtLeast(version.MustParseGeneric("1.29"))
+}
+
 func isOpenshift(cfg *rest.Config) bool {
 	const sccGroupName, sccKind = "security.openshift.io", "SecurityContextConstraints"
 
@@ -67,6 +67,15 @@ operator["rules"] = rules.sort_by { |rule| rule.to_a }
 operator["metadata"] = { "name" => "postgres-operator" }
 IO.write(File.join(directory, "cluster", "role.yaml"), YAML.dump(operator))
 
+# A Role cannot grant access to cluster-scoped resources, so leave them out
+# of the namespace Role. Features that need them report missing permissions
+# in the status of PostgresClusters.
+cluster_scoped = Set["namespaces", "storageclasses", "subjectaccessreviews", "tokenreviews"]
+operator["rules"] = operator["rules"].filter_map do |rule|
+	resources = rule["resources"].reject { |resource| cluster_scoped.include? resource }
+	rule.merge("resources" => resources) unless resources.empty?
+end
+
 operator["kind"] = "Role"
 IO.write(File.join(directory, "namespace", "role.yaml"), YAML.dump(operator))
 ' -- "${directory}"
@@ -0,0 +1,88 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package agent implements a small gRPC service that runs in the database
+// container of PostgreSQL instance Pods. The operator asks it to run commands
+// rather than using the "pods/exec" subresource of the Kubernetes API. Both
+// sides authenticate with certificates issued by the root certificate
+// authority of the namespace.
+package agent
+
+import (
+	"context"
+	"encoding/json"
+
+	"google.golang.org/grpc"
+)
+
+const (
+	// DefaultPort is the port on which the agent listens in instance Pods.
+	DefaultPort int32 = 8433
+
+	// ClientCommonName is the common name of the certificate the operator
+	// presents to agents.
+	ClientCommonName = "postgres-operator"
+
+	// ClientURI is the only subject alternative name of the certificate the
+	// operator presents to agents. The same authority issues certificates
+	// to PostgreSQL users, but never with a URI, so theirs are refused.
+	ClientURI = "urn:postgres-operator.crunchydata.com:agent-client"
+
+	// serviceName is the full name of the gRPC service.
+	serviceName = "postgres_operator.agent.v1.Agent"
+)
+
+// ExecRequest is a command to run and its standard input.
+type ExecRequest struct {
+	Command []string `json:"command"`
+	Stdin   []byte   `json:"stdin,omitempty"`
+}
+
+// ExecResponse is the output and exit code of a command.
+type ExecResponse struct {
+	Stdout   []byte `json:"stdout,omitempty"`
+	Stderr   []byte `json:"stderr,omitempty"`
+	ExitCode int    `json:"exitCode"`
+}
+
+// agentServer is the interface of the gRPC service.
+type agentServer interface {
+	Exec(context.Context, *ExecRequest) (*ExecResponse, error)
+}
+
+// jsonCodec encodes messages as JSON so that the service needs no generated
+// Protocol Buffers code.
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                       { return "json" }
+
+// serviceDesc describes the gRPC service to a [grpc.Server].
+var serviceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*agentServer)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Exec",
+		Handler: func(
+			srv any, ctx context.Context, decode func(any) error, _ grpc.UnaryServerInterceptor,
+		) (any, error) {
+			request := new(ExecRequest)
+			if err := decode(request); err != nil {
+				return nil, err
+			}
+			return srv.(agentServer).Exec(ctx, request)
+		},
+	}},
+}
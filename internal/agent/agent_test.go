@@ -0,0 +1,149 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/pki"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+)
+
+func TestExecutor(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	root, err := pki.NewRootCertificateAuthority()
+	assert.NilError(t, err)
+
+	// The server presents the certificate of the cluster.
+	dir := t.TempDir()
+	server, err := root.GenerateLeafCertificate("hippo-primary.ns1.svc",
+		[]string{"hippo-primary.ns1.svc"})
+	assert.NilError(t, err)
+
+	write := func(name string, value interface{ MarshalText() ([]byte, error) }) string {
+		data, err := value.MarshalText()
+		assert.NilError(t, err)
+		assert.NilError(t, os.WriteFile(filepath.Join(dir, name), data, 0o600))
+		return filepath.Join(dir, name)
+	}
+	s := &Server{
+		CAFile:   write("ca.crt", root.Certificate),
+		CertFile: write("tls.crt", server.Certificate),
+		KeyFile:  write("tls.key", server.PrivateKey),
+	}
+	config, err := s.TLSConfig()
+	assert.NilError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NilError(t, err)
+	go func() { assert.Check(t, s.serve(ctx, listener, config)) }()
+
+	secret := &corev1.Secret{}
+	secret.Namespace, secret.Name = "ns1", naming.RootCertSecret
+	secret.Data = map[string][]byte{}
+	secret.Data["root.crt"], _ = root.Certificate.MarshalText()
+	secret.Data["root.key"], _ = root.PrivateKey.MarshalText()
+
+	pod := &corev1.Pod{}
+	pod.Namespace, pod.Name = "ns1", "hippo-00-abcd-0"
+	pod.Labels = map[string]string{naming.LabelCluster: "hippo"}
+	pod.Spec.Containers = []corev1.Container{
+		{Name: naming.ContainerDatabase, Ports: []corev1.ContainerPort{{
+			Name: naming.PortAgent, ContainerPort: int32(listener.Addr().(*net.TCPAddr).Port),
+		}}},
+		{Name: "other"},
+	}
+	pod.Status.PodIP = "127.0.0.1"
+
+	var fallbacks int
+	executor := &Executor{
+		Reader: fake.NewClientBuilder().WithObjects(pod, secret).Build(),
+		Fallback: func(_, _, container string, _ io.Reader, _, _ io.Writer, _ ...string) error {
+			assert.Equal(t, container, "other")
+			fallbacks++
+			return nil
+		},
+	}
+
+	t.Run("Exec", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		assert.NilError(t, executor.Exec("ns1", "hippo-00-abcd-0", naming.ContainerDatabase,
+			strings.NewReader("hello"), &stdout, &stderr, "bash", "-c", "cat; echo world >&2"))
+
+		assert.Equal(t, stdout.String(), "hello")
+		assert.Equal(t, stderr.String(), "world\n")
+	})
+
+	t.Run("ExitCode", func(t *testing.T) {
+		err := executor.Exec("ns1", "hippo-00-abcd-0", naming.ContainerDatabase,
+			nil, nil, nil, "bash", "-c", "exit 3")
+		assert.ErrorContains(t, err, "exit code 3")
+	})
+
+	t.Run("Fallback", func(t *testing.T) {
+		assert.NilError(t, executor.Exec("ns1", "hippo-00-abcd-0", "other",
+			nil, nil, nil, "true"))
+		assert.Equal(t, fallbacks, 1)
+	})
+
+	t.Run("OtherClients", func(t *testing.T) {
+		// Certificates for other purposes are refused even though they are
+		// issued by the same authority. That includes the certificate of a
+		// PostgreSQL user that has the same name as the operator.
+		for _, tt := range []struct {
+			commonName string
+			dnsNames   []string
+		}{
+			{commonName: "_crunchyrepl"},
+			{commonName: ClientCommonName, dnsNames: []string{ClientCommonName}},
+			{commonName: ClientCommonName},
+		} {
+			leaf, err := root.GenerateLeafCertificate(tt.commonName, tt.dnsNames)
+			assert.NilError(t, err)
+			certPEM, _ := leaf.Certificate.MarshalText()
+			keyPEM, _ := leaf.PrivateKey.MarshalText()
+			certificate, err := tls.X509KeyPair(certPEM, keyPEM)
+			assert.NilError(t, err)
+
+			config, err := executor.tlsConfig(ctx, pod)
+			assert.NilError(t, err)
+			config.Certificates = []tls.Certificate{certificate}
+
+			connection, err := tls.Dial("tcp", listener.Addr().String(), config)
+			if err == nil {
+				// TLS 1.3 reports client certificate failures on the first read.
+				_, err = connection.Read(make([]byte, 1))
+				connection.Close()
+			}
+			assert.Assert(t, err != nil, "expected %+v to be refused", tt)
+			assert.Assert(t, cmp.Contains(err.Error(), "certificate"))
+		}
+	})
+}
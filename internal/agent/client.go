@@ -0,0 +1,204 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/pki"
+)
+
+// Executor runs commands through the agent of a Pod when it has one.
+type Executor struct {
+	// Reader finds Pods and the root certificate authority of their namespace.
+	Reader client.Reader
+
+	// Fallback runs commands in Pods and containers without an agent.
+	Fallback func(
+		namespace, pod, container string,
+		stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error
+
+	// Timeout limits each command. It defaults to ten minutes.
+	Timeout time.Duration
+
+	mutex  sync.Mutex
+	leaves map[string]*pki.LeafCertificate
+}
+
+// Exec runs command in container of pod in namespace. Only the database
+// container of PostgreSQL instances has an agent; other commands are passed
+// to Fallback.
+func (e *Executor) Exec(
+	namespace, name, container string,
+	stdin io.Reader, stdout, stderr io.Writer, command ...string,
+) error {
+	timeout := e.Timeout
+	if timeout == 0 {
+		timeout = 10 * time.Minute
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	pod := &corev1.Pod{}
+	err := e.Reader.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, pod)
+	if err != nil {
+		return err
+	}
+
+	port := Port(pod, container)
+	if port == 0 || pod.Status.PodIP == "" {
+		return e.Fallback(namespace, name, container, stdin, stdout, stderr, command...)
+	}
+
+	config, err := e.tlsConfig(ctx, pod)
+	if err != nil {
+		return err
+	}
+
+	request := &ExecRequest{Command: command}
+	if stdin != nil {
+		if request.Stdin, err = io.ReadAll(stdin); err != nil {
+			return err
+		}
+	}
+
+	connection, err := grpc.DialContext(ctx,
+		net.JoinHostPort(pod.Status.PodIP, strconv.Itoa(int(port))),
+		grpc.WithTransportCredentials(credentials.NewTLS(config)),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(jsonCodec{})),
+	)
+	if err != nil {
+		return err
+	}
+	defer connection.Close()
+
+	response := &ExecResponse{}
+	if err := connection.Invoke(ctx, "/"+serviceName+"/Exec", request, response); err != nil {
+		return err
+	}
+
+	if stdout != nil {
+		if _, err := stdout.Write(response.Stdout); err != nil {
+			return err
+		}
+	}
+	if stderr != nil {
+		if _, err := stderr.Write(response.Stderr); err != nil {
+			return err
+		}
+	}
+	if response.ExitCode != 0 {
+		// This matches the error of the "pods/exec" subresource.
+		return fmt.Errorf("command terminated with exit code %d", response.ExitCode)
+	}
+	return nil
+}
+
+// Port returns the port of the agent in container of pod, if any.
+func Port(pod *corev1.Pod, container string) int32 {
+	for _, c := range pod.Spec.Containers {
+		if c.Name == container {
+			for _, port := range c.Ports {
+				if port.Name == naming.PortAgent {
+					return port.ContainerPort
+				}
+			}
+		}
+	}
+	return 0
+}
+
+// +kubebuilder:rbac:groups="",resources="pods",verbs={get}
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={get}
+
+// tlsConfig returns a configuration that presents a certificate issued by the
+// root certificate authority in the namespace of pod and that verifies the
+// agent has the certificate of its PostgresCluster.
+func (e *Executor) tlsConfig(ctx context.Context, pod *corev1.Pod) (*tls.Config, error) {
+	const keyCertificate, keyPrivateKey = "root.crt", "root.key"
+
+	secret := &corev1.Secret{}
+	err := e.Reader.Get(ctx, client.ObjectKey{
+		Namespace: pod.Namespace, Name: naming.RootCertSecret,
+	}, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	root := &pki.RootCertificateAuthority{}
+	if err := root.Certificate.UnmarshalText(secret.Data[keyCertificate]); err != nil {
+		return nil, err
+	}
+	if err := root.PrivateKey.UnmarshalText(secret.Data[keyPrivateKey]); err != nil {
+		return nil, err
+	}
+
+	// Issue a certificate once per namespace and again when it needs renewal
+	// or the root is replaced.
+	e.mutex.Lock()
+	if e.leaves == nil {
+		e.leaves = make(map[string]*pki.LeafCertificate)
+	}
+	uri, _ := url.Parse(ClientURI)
+	leaf, err := root.RegenerateLeafWhenNecessary(e.leaves[pod.Namespace], ClientCommonName, nil, uri)
+	if err == nil {
+		e.leaves[pod.Namespace] = leaf
+	}
+	e.mutex.Unlock()
+
+	var certificate tls.Certificate
+	var certPEM, keyPEM []byte
+	if err == nil {
+		certPEM, err = leaf.Certificate.MarshalText()
+	}
+	if err == nil {
+		keyPEM, err = leaf.PrivateKey.MarshalText()
+	}
+	if err == nil {
+		certificate, err = tls.X509KeyPair(certPEM, keyPEM)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	authorities := x509.NewCertPool()
+	authorities.AppendCertsFromPEM(secret.Data[keyCertificate])
+
+	// The agent presents the certificate of its cluster, which is valid for
+	// the primary Service.
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   tls.VersionTLS12,
+		RootCAs:      authorities,
+		ServerName: pod.Labels[naming.LabelCluster] + "-primary." +
+			pod.Namespace + ".svc",
+	}, nil
+}
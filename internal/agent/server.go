@@ -0,0 +1,157 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agent
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/status"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+)
+
+// Server serves the agent.
+type Server struct {
+	// Address is the TCP address on which to listen, such as ":8433".
+	Address string
+
+	// CertFile and KeyFile are the paths to the TLS certificate and key of
+	// the server. CAFile is the path to the authority that issued clients
+	// their certificates.
+	CertFile, KeyFile, CAFile string
+}
+
+// TLSConfig returns a configuration that requires clients to present a
+// certificate issued by the authority in CAFile to ClientCommonName and
+// ClientURI.
+func (s *Server) TLSConfig() (*tls.Config, error) {
+	certificate, err := tls.LoadX509KeyPair(s.CertFile, s.KeyFile)
+	if err != nil {
+		return nil, err
+	}
+
+	authority, err := os.ReadFile(s.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	clients := x509.NewCertPool()
+	if !clients.AppendCertsFromPEM(authority) {
+		return nil, fmt.Errorf("no certificates in %q", s.CAFile)
+	}
+
+	return &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    clients,
+		MinVersion:   tls.VersionTLS12,
+
+		// The chain is verified before this is called.
+		VerifyConnection: func(state tls.ConnectionState) error {
+			if len(state.PeerCertificates) == 0 || !isClientCertificate(state.PeerCertificates[0]) {
+				return errors.New("client certificate is not for " + ClientCommonName)
+			}
+			return nil
+		},
+	}, nil
+}
+
+// isClientCertificate returns whether or not certificate is the one that the
+// operator presents to agents. PostgreSQL users can choose their common name
+// but not their subject alternative names.
+func isClientCertificate(certificate *x509.Certificate) bool {
+	return certificate.Subject.CommonName == ClientCommonName &&
+		len(certificate.URIs) == 1 && certificate.URIs[0].String() == ClientURI &&
+		len(certificate.DNSNames) == 0 && len(certificate.EmailAddresses) == 0 &&
+		len(certificate.IPAddresses) == 0
+}
+
+// Start serves requests until ctx is cancelled.
+func (s *Server) Start(ctx context.Context) error {
+	config, err := s.TLSConfig()
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", s.Address)
+	if err != nil {
+		return err
+	}
+
+	return s.serve(ctx, listener, config)
+}
+
+// serve accepts connections on listener until ctx is cancelled.
+func (s *Server) serve(ctx context.Context, listener net.Listener, config *tls.Config) error {
+	server := grpc.NewServer(
+		grpc.Creds(credentials.NewTLS(config)),
+		grpc.ForceServerCodec(jsonCodec{}),
+	)
+	server.RegisterService(&serviceDesc, &executor{})
+
+	go func() {
+		<-ctx.Done()
+		server.GracefulStop()
+	}()
+
+	logging.FromContext(ctx).Info("serving the agent", "address", listener.Addr().String())
+
+	if err := server.Serve(listener); !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
+
+// executor runs commands in the container of the agent.
+type executor struct{}
+
+// Exec runs the command in request and returns its output. Every command is
+// logged so that what the operator did in the container can be audited.
+func (*executor) Exec(ctx context.Context, request *ExecRequest) (*ExecResponse, error) {
+	if len(request.Command) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "missing command")
+	}
+
+	var stdout, stderr bytes.Buffer
+	command := exec.CommandContext(ctx, request.Command[0], request.Command[1:]...)
+	command.Stdin = bytes.NewReader(request.Stdin)
+	command.Stdout, command.Stderr = &stdout, &stderr
+
+	err := command.Run()
+	response := &ExecResponse{Stdout: stdout.Bytes(), Stderr: stderr.Bytes()}
+
+	var exit *exec.ExitError
+	if errors.As(err, &exit) {
+		response.ExitCode, err = exit.ExitCode(), nil
+	}
+
+	logging.FromContext(ctx).Info("executed command",
+		"command", request.Command, "exitCode", response.ExitCode, "error", err)
+
+	if err != nil {
+		return nil, status.Error(codes.Unavailable, err.Error())
+	}
+	return response, nil
+}
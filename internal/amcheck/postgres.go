@@ -0,0 +1,127 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package amcheck
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// Enabled returns whether or not integrity checks are requested for cluster.
+// The pg_amcheck utility was introduced in PostgreSQL v14.
+func Enabled(cluster *v1beta1.PostgresCluster) bool {
+	return cluster.Spec.IntegrityChecks != nil && cluster.Spec.PostgresVersion >= 14
+}
+
+// DisableInPostgreSQL revokes the privileges that EnableInPostgreSQL grants
+// the maintenance user. The amcheck extension is left in place because other
+// roles may be using it.
+func DisableInPostgreSQL(ctx context.Context, exec postgres.Executor) error {
+	log := logging.FromContext(ctx)
+
+	stdout, stderr, err := exec.ExecInAllDatabases(ctx,
+		strings.TrimSpace(`
+SELECT pg_catalog.format('REVOKE EXECUTE ON FUNCTION %s FROM %I', objid::regprocedure, :'username')
+  FROM pg_catalog.pg_depend
+ WHERE classid = 'pg_catalog.pg_proc'::regclass
+   AND refclassid = 'pg_catalog.pg_extension'::regclass
+   AND refobjid = (SELECT oid FROM pg_catalog.pg_extension WHERE extname = 'amcheck')
+   AND deptype = 'e'
+   AND EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = :'username')
+\gexec`),
+		map[string]string{
+			"username": postgres.MaintenanceUser,
+
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("removed amcheck privileges", "stdout", stdout, "stderr", stderr)
+
+	return err
+}
+
+// EnableInPostgreSQL installs the amcheck extension into every database and
+// allows the maintenance user to execute its functions. Databases created
+// later from "template1" inherit both.
+// - https://www.postgresql.org/docs/current/amcheck.html
+func EnableInPostgreSQL(ctx context.Context, exec postgres.Executor) error {
+	log := logging.FromContext(ctx)
+
+	err := postgres.CreateMaintenanceUser(ctx, exec)
+	if err != nil {
+		return err
+	}
+
+	stdout, stderr, err := exec.ExecInAllDatabases(ctx,
+		strings.Join([]string{
+			// Quiet NOTICE messages from IF NOT EXISTS statements.
+			// - https://www.postgresql.org/docs/current/runtime-config-client.html
+			`SET client_min_messages = WARNING;`,
+
+			`CREATE EXTENSION IF NOT EXISTS amcheck;`,
+
+			// The amcheck functions are restricted to superusers by default.
+			// Grant each one that belongs to the extension, whatever its version.
+			strings.TrimSpace(`
+SELECT pg_catalog.format('GRANT EXECUTE ON FUNCTION %s TO %I', objid::regprocedure, :'username')
+  FROM pg_catalog.pg_depend
+ WHERE classid = 'pg_catalog.pg_proc'::regclass
+   AND refclassid = 'pg_catalog.pg_extension'::regclass
+   AND refobjid = (SELECT oid FROM pg_catalog.pg_extension WHERE extname = 'amcheck')
+   AND deptype = 'e'
+\gexec`),
+		}, "\n"),
+		map[string]string{
+			"username": postgres.MaintenanceUser,
+
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("enabled amcheck", "stdout", stdout, "stderr", stderr)
+
+	return err
+}
+
+// Command returns the pg_amcheck command that checks the databases of cluster.
+// - https://www.postgresql.org/docs/current/app-pgamcheck.html
+func Command(cluster *v1beta1.PostgresCluster) []string {
+	spec := cluster.Spec.IntegrityChecks
+	cmd := []string{"pg_amcheck"}
+
+	if spec.Connections != nil {
+		cmd = append(cmd, fmt.Sprintf("--jobs=%d", *spec.Connections))
+	}
+	if spec.HeapAllIndexed {
+		cmd = append(cmd, "--heapallindexed")
+	}
+
+	// Check the specified databases or every database that allows connections.
+	if len(spec.Databases) == 0 {
+		cmd = append(cmd, "--all")
+	}
+	for _, database := range spec.Databases {
+		cmd = append(cmd, "--database="+database)
+	}
+
+	return cmd
+}
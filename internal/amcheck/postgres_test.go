@@ -0,0 +1,107 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package amcheck
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestEnabled(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.PostgresVersion = 14
+	assert.Assert(t, !Enabled(cluster))
+
+	cluster.Spec.IntegrityChecks = new(v1beta1.IntegrityChecksSpec)
+	assert.Assert(t, Enabled(cluster))
+
+	cluster.Spec.PostgresVersion = 13
+	assert.Assert(t, !Enabled(cluster), "expected pg_amcheck to be unavailable")
+}
+
+func TestDisableInPostgreSQL(t *testing.T) {
+	expected := errors.New("whoops")
+	exec := func(
+		_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		assert.Assert(t, stdout != nil, "should capture stdout")
+		assert.Assert(t, stderr != nil, "should capture stderr")
+		assert.Assert(t, strings.Contains(strings.Join(command, "\n"),
+			`--set=username=_crunchymaintenance`))
+
+		b, err := io.ReadAll(stdin)
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(string(b), `REVOKE EXECUTE ON FUNCTION %s FROM %I`))
+
+		return expected
+	}
+
+	ctx := context.Background()
+	assert.Equal(t, expected, DisableInPostgreSQL(ctx, exec))
+}
+
+func TestEnableInPostgreSQL(t *testing.T) {
+	calls := 0
+	exec := func(
+		_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		calls++
+
+		b, err := io.ReadAll(stdin)
+		assert.NilError(t, err)
+
+		// The first call creates the maintenance user.
+		if calls == 1 {
+			assert.Assert(t, cmp.Contains(string(b), `CREATE ROLE %I`))
+			return nil
+		}
+
+		assert.Assert(t, strings.Contains(strings.Join(command, "\n"),
+			`SELECT datname FROM pg_catalog.pg_database`,
+		), "expected all databases and templates")
+		assert.Assert(t, cmp.Contains(string(b), `CREATE EXTENSION IF NOT EXISTS amcheck;`))
+		assert.Assert(t, cmp.Contains(string(b), `GRANT EXECUTE ON FUNCTION %s TO %I`))
+
+		return nil
+	}
+
+	ctx := context.Background()
+	assert.NilError(t, EnableInPostgreSQL(ctx, exec))
+	assert.Equal(t, calls, 2)
+}
+
+func TestCommand(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.IntegrityChecks = new(v1beta1.IntegrityChecksSpec)
+	assert.DeepEqual(t, Command(cluster), []string{"pg_amcheck", "--all"})
+
+	cluster.Spec.IntegrityChecks.Connections = initialize.Int32(3)
+	cluster.Spec.IntegrityChecks.HeapAllIndexed = true
+	cluster.Spec.IntegrityChecks.Databases = []string{"app", "other"}
+	assert.DeepEqual(t, Command(cluster), []string{
+		"pg_amcheck", "--jobs=3", "--heapallindexed",
+		"--database=app", "--database=other",
+	})
+}
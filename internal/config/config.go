@@ -62,6 +62,12 @@ func RegistrationRequiredBy(cluster *v1beta1.PostgresCluster) string {
 	return cluster.Status.RegistrationRequired.PGOVersion
 }
 
+// AgentContainerImage returns the container image that holds the agent. It is
+// the image of the operator itself.
+func AgentContainerImage() string {
+	return os.Getenv("PGO_AGENT_IMAGE")
+}
+
 // Red Hat Marketplace requires operators to use environment variables be used
 // for any image other than the operator itself. Those variables must start with
 // "RELATED_IMAGE_" so that OSBS can transform their tag values into digests
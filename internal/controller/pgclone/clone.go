@@ -0,0 +1,160 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgclone
+
+import (
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/config"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/servicemesh"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// generateCronJob populates cronjob with the schedule of clone. Its Jobs do
+// nothing but mark the time; the controller refreshes the target cluster once
+// for each of them.
+func generateCronJob(
+	clone *v1beta1.PGClone, source *v1beta1.PostgresCluster, cronjob *batchv1.CronJob,
+) {
+	labels := map[string]string{naming.LabelPGClone: clone.Name}
+	cronjob.Labels = labels
+
+	container := corev1.Container{
+		Name:            "refresh",
+		Image:           config.PostgresContainerImage(source),
+		ImagePullPolicy: source.Spec.ImagePullPolicy,
+		Command: []string{"echo", fmt.Sprintf(
+			"Refreshing PostgresCluster %q from %q", clone.Spec.TargetClusterName, source.Name)},
+		SecurityContext: initialize.RestrictedSecurityContext(),
+	}
+
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			// These Jobs don't use the network; keep any service mesh from
+			// adding a proxy that would keep them from completing.
+			Annotations: servicemesh.NoProxyAnnotations(source),
+			Labels:      labels,
+		},
+		Spec: corev1.PodSpec{
+			ImagePullSecrets: source.Spec.ImagePullSecrets,
+			Containers:       []corev1.Container{container},
+			RestartPolicy:    corev1.RestartPolicyNever,
+			// These Jobs don't make Kubernetes API calls, so we can just
+			// use the default ServiceAccount and not mount its credentials.
+			AutomountServiceAccountToken: initialize.Bool(false),
+			EnableServiceLinks:           initialize.Bool(false),
+			SecurityContext:              initialize.PodSecurityContext(),
+		},
+	}
+
+	cronjob.Spec = batchv1.CronJobSpec{
+		Schedule:          clone.Spec.Schedule,
+		Suspend:           initialize.Bool(clone.Spec.Suspend != nil && *clone.Spec.Suspend),
+		ConcurrencyPolicy: batchv1.ForbidConcurrent,
+		JobTemplate: batchv1.JobTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{Labels: labels},
+			Spec: batchv1.JobSpec{
+				BackoffLimit: initialize.Int32(0),
+				Template:     template,
+			},
+		},
+	}
+}
+
+// generateTargetCluster returns the PostgresCluster that clone creates for
+// the scheduled Job named tick. It is a copy of source that restores from the
+// latest backup of source and does not take backups of its own on a schedule.
+func generateTargetCluster(
+	clone *v1beta1.PGClone, source *v1beta1.PostgresCluster, tick string,
+) *v1beta1.PostgresCluster {
+	target := v1beta1.NewPostgresCluster()
+	target.Namespace, target.Name = clone.Namespace, clone.Spec.TargetClusterName
+	target.Annotations = map[string]string{naming.PGCloneRefresh: tick}
+	target.Labels = map[string]string{naming.LabelPGClone: clone.Name}
+
+	spec := source.Spec.DeepCopy()
+	spec.DataSource = &v1beta1.DataSource{
+		PostgresCluster: &v1beta1.PostgresClusterDataSource{
+			ClusterName: source.Name,
+			RepoName:    clone.Spec.RepoName,
+		},
+		Masking: clone.Spec.Masking.DeepCopy(),
+	}
+	spec.Shutdown = nil
+	spec.Standby = nil
+
+	// Backups of the target go to its own repositories, and only on request.
+	// Cloud repositories are at the same path for every cluster, so only
+	// those on volumes are kept. When there are none, the target gets a volume
+	// like that of its first instance set.
+	spec.Backups.PGBackRest.Manual = nil
+	spec.Backups.PGBackRest.Restore = nil
+	repos := spec.Backups.PGBackRest.Repos[:0]
+	for _, repo := range spec.Backups.PGBackRest.Repos {
+		if repo.Volume != nil {
+			repo.BackupSchedules = nil
+			repos = append(repos, repo)
+		}
+	}
+	if len(repos) == 0 && len(spec.InstanceSets) > 0 {
+		repos = append(repos, v1beta1.PGBackRestRepo{
+			Name: "repo1",
+			Volume: &v1beta1.RepoPVC{
+				VolumeClaimSpec: *spec.InstanceSets[0].DataVolumeClaimSpec.DeepCopy(),
+			},
+		})
+	}
+	spec.Backups.PGBackRest.Repos = repos
+
+	// Node ports are unique across a Kubernetes cluster.
+	for _, service := range []*v1beta1.ServiceSpec{spec.Service, spec.ReplicaService} {
+		if service != nil {
+			service.NodePort = nil
+		}
+	}
+	if spec.Proxy != nil && spec.Proxy.PGBouncer != nil && spec.Proxy.PGBouncer.Service != nil {
+		spec.Proxy.PGBouncer.Service.NodePort = nil
+	}
+
+	for i := range spec.InstanceSets {
+		if clone.Spec.Replicas != nil {
+			spec.InstanceSets[i].Replicas = initialize.Int32(*clone.Spec.Replicas)
+		}
+		if clone.Spec.Resources != nil {
+			spec.InstanceSets[i].Resources = *clone.Spec.Resources.DeepCopy()
+		}
+	}
+
+	target.Spec = *spec
+	return target
+}
+
+// latestScheduledJob returns the most recently created Job in jobs.
+func latestScheduledJob(jobs []batchv1.Job) *batchv1.Job {
+	var latest *batchv1.Job
+	for i := range jobs {
+		if latest == nil ||
+			latest.CreationTimestamp.Before(&jobs[i].CreationTimestamp) {
+			latest = &jobs[i]
+		}
+	}
+	return latest
+}
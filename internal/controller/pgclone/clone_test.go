@@ -0,0 +1,157 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgclone
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func testSource() *v1beta1.PostgresCluster {
+	source := v1beta1.NewPostgresCluster()
+	source.Namespace, source.Name = "ns1", "prod"
+	source.Spec.Image = "some-image"
+	source.Spec.PostgresVersion = 16
+	source.Spec.Service = &v1beta1.ServiceSpec{NodePort: initialize.Int32(30000)}
+	source.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{
+		Name:     "00",
+		Replicas: initialize.Int32(3),
+		DataVolumeClaimSpec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+		},
+	}}
+	source.Spec.Backups.PGBackRest.Repos = []v1beta1.PGBackRestRepo{
+		{
+			Name:            "repo1",
+			BackupSchedules: &v1beta1.PGBackRestBackupSchedules{Full: initialize.String("@daily")},
+			S3:              &v1beta1.RepoS3{Bucket: "prod"},
+		},
+		{
+			Name:            "repo2",
+			BackupSchedules: &v1beta1.PGBackRestBackupSchedules{Full: initialize.String("@daily")},
+			Volume:          &v1beta1.RepoPVC{},
+		},
+	}
+	return source
+}
+
+func TestGenerateCronJob(t *testing.T) {
+	clone := &v1beta1.PGClone{}
+	clone.Namespace, clone.Name = "ns1", "staging"
+	clone.Spec.Schedule = "0 6 * * 1"
+	clone.Spec.TargetClusterName = "stage"
+
+	cronjob := &batchv1.CronJob{ObjectMeta: naming.PGCloneCronJob(clone)}
+	generateCronJob(clone, testSource(), cronjob)
+
+	assert.Equal(t, cronjob.Name, "staging-pgclone")
+	assert.Equal(t, cronjob.Spec.Schedule, "0 6 * * 1")
+	assert.Equal(t, *cronjob.Spec.Suspend, false)
+	assert.Equal(t, cronjob.Spec.ConcurrencyPolicy, batchv1.ForbidConcurrent)
+	assert.DeepEqual(t, cronjob.Spec.JobTemplate.Labels, map[string]string{
+		naming.LabelPGClone: "staging",
+	})
+
+	container := cronjob.Spec.JobTemplate.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, container.Image, "some-image")
+
+	clone.Spec.Suspend = initialize.Bool(true)
+	generateCronJob(clone, testSource(), cronjob)
+	assert.Equal(t, *cronjob.Spec.Suspend, true)
+}
+
+func TestGenerateTargetCluster(t *testing.T) {
+	clone := &v1beta1.PGClone{}
+	clone.Namespace, clone.Name = "ns1", "staging"
+	clone.Spec.RepoName = "repo1"
+	clone.Spec.TargetClusterName = "stage"
+
+	source := testSource()
+
+	t.Run("Copy", func(t *testing.T) {
+		target := generateTargetCluster(clone, source, "tick-1")
+
+		assert.Equal(t, target.Namespace, "ns1")
+		assert.Equal(t, target.Name, "stage")
+		assert.Equal(t, target.Annotations[naming.PGCloneRefresh], "tick-1")
+		assert.Equal(t, target.Spec.PostgresVersion, 16)
+		assert.DeepEqual(t, target.Spec.DataSource, &v1beta1.DataSource{
+			PostgresCluster: &v1beta1.PostgresClusterDataSource{
+				ClusterName: "prod", RepoName: "repo1",
+			},
+		})
+		assert.Assert(t, target.Spec.Service.NodePort == nil)
+		assert.Equal(t, *target.Spec.InstanceSets[0].Replicas, int32(3))
+
+		// Only the volume repository remains, without schedules.
+		assert.Equal(t, len(target.Spec.Backups.PGBackRest.Repos), 1)
+		assert.Equal(t, target.Spec.Backups.PGBackRest.Repos[0].Name, "repo2")
+		assert.Assert(t, target.Spec.Backups.PGBackRest.Repos[0].BackupSchedules == nil)
+
+		// The source is unchanged.
+		assert.Equal(t, len(source.Spec.Backups.PGBackRest.Repos), 2)
+		assert.Equal(t, *source.Spec.Service.NodePort, int32(30000))
+	})
+
+	t.Run("Shrunk", func(t *testing.T) {
+		clone := clone.DeepCopy()
+		clone.Spec.Replicas = initialize.Int32(1)
+		clone.Spec.Resources = &corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+		}
+		clone.Spec.Masking = &v1beta1.DataMaskingSpec{
+			Scripts: []v1beta1.DatabaseInitSQL{{Name: "masks", Key: "sql"}},
+		}
+
+		target := generateTargetCluster(clone, source, "tick-1")
+		assert.Equal(t, *target.Spec.InstanceSets[0].Replicas, int32(1))
+		assert.Assert(t, target.Spec.InstanceSets[0].Resources.Limits.Memory().Equal(resource.MustParse("1Gi")))
+		assert.DeepEqual(t, target.Spec.DataSource.Masking, clone.Spec.Masking)
+	})
+
+	t.Run("NoVolumeRepository", func(t *testing.T) {
+		source := source.DeepCopy()
+		source.Spec.Backups.PGBackRest.Repos = source.Spec.Backups.PGBackRest.Repos[:1]
+
+		target := generateTargetCluster(clone, source, "tick-1")
+		assert.Equal(t, len(target.Spec.Backups.PGBackRest.Repos), 1)
+		assert.Equal(t, target.Spec.Backups.PGBackRest.Repos[0].Name, "repo1")
+		assert.Assert(t, target.Spec.Backups.PGBackRest.Repos[0].S3 == nil)
+		assert.DeepEqual(t, target.Spec.Backups.PGBackRest.Repos[0].Volume.VolumeClaimSpec,
+			source.Spec.InstanceSets[0].DataVolumeClaimSpec)
+	})
+}
+
+func TestLatestScheduledJob(t *testing.T) {
+	assert.Assert(t, latestScheduledJob(nil) == nil)
+
+	now := time.Now()
+	jobs := make([]batchv1.Job, 3)
+	jobs[0].Name, jobs[0].CreationTimestamp = "a", metav1.NewTime(now.Add(-time.Hour))
+	jobs[1].Name, jobs[1].CreationTimestamp = "b", metav1.NewTime(now)
+	jobs[2].Name, jobs[2].CreationTimestamp = "c", metav1.NewTime(now.Add(-time.Minute))
+
+	assert.Equal(t, latestScheduledJob(jobs).Name, "b")
+}
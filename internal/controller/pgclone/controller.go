@@ -0,0 +1,235 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgclone
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	// ConditionRefreshed is the type used in a condition to indicate whether
+	// or not the target cluster was created again for the latest scheduled Job.
+	ConditionRefreshed = "Refreshed"
+)
+
+// PGCloneReconciler reconciles a PGClone object
+type PGCloneReconciler struct {
+	client.Client
+	Owner  client.FieldOwner
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="pgclones",verbs={list,watch}
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={list,watch}
+//+kubebuilder:rbac:groups="batch",resources="cronjobs",verbs={list,watch}
+//+kubebuilder:rbac:groups="batch",resources="jobs",verbs={list,watch}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PGCloneReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta1.PGClone{}).
+		Owns(&batchv1.CronJob{}).
+		Owns(v1beta1.NewPostgresCluster()).
+		Watches(&source.Kind{Type: &batchv1.Job{}}, r.watchJobs()).
+		Complete(r)
+}
+
+// watchJobs returns a [handler.EventHandler] for the Jobs that schedule
+// refreshes. These are controlled by a CronJob rather than the PGClone.
+func (r *PGCloneReconciler) watchJobs() handler.Funcs {
+	return handler.Funcs{
+		CreateFunc: func(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			if name, ok := e.Object.GetLabels()[naming.LabelPGClone]; ok {
+				q.Add(ctrl.Request{NamespacedName: client.ObjectKey{
+					Namespace: e.Object.GetNamespace(), Name: name,
+				}})
+			}
+		},
+	}
+}
+
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="pgclones",verbs={get}
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="pgclones/status",verbs={patch}
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={get,create,delete}
+//+kubebuilder:rbac:groups="batch",resources="cronjobs",verbs={create,patch}
+//+kubebuilder:rbac:groups="batch",resources="jobs",verbs={list}
+
+// Reconcile schedules the refreshes described by the [v1beta1.PGClone]
+// identified by req. Each new scheduled Job deletes the target cluster and
+// creates it again from the latest backup of the source cluster.
+func (r *PGCloneReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	// NOTE: No DeepCopy is necessary here because controller-runtime makes a
+	// copy before returning from its cache.
+	// - https://github.com/kubernetes-sigs/controller-runtime/issues/1235
+	clone := &v1beta1.PGClone{}
+	err = r.Get(ctx, req.NamespacedName, clone)
+
+	if err == nil {
+		// Write any changes to the clone status on the way out.
+		before := clone.DeepCopy()
+		defer func() {
+			if !equality.Semantic.DeepEqual(before.Status, clone.Status) {
+				status := r.Status().Patch(ctx, clone, client.MergeFrom(before), r.Owner)
+
+				if err == nil && status != nil {
+					err = status
+				} else if status != nil {
+					log.Error(status, "Patching PGClone status")
+				}
+			}
+		}()
+	} else {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	clone.Status.ObservedGeneration = clone.Generation
+
+	source := &v1beta1.PostgresCluster{}
+	err = r.Get(ctx, client.ObjectKey{
+		Namespace: clone.Namespace, Name: clone.Spec.SourceClusterName,
+	}, source)
+
+	if apierrors.IsNotFound(err) {
+		setRefreshed(clone, metav1.ConditionFalse, "SourceNotFound",
+			fmt.Sprintf("PostgresCluster %q does not exist", clone.Spec.SourceClusterName))
+		return ctrl.Result{}, nil
+	}
+
+	cronjob := &batchv1.CronJob{ObjectMeta: naming.PGCloneCronJob(clone)}
+
+	// The owner reference blocks deletion, so the OwnerReferencesPermissionEnforcement
+	// plugin requires "update" permission on the owner's "finalizers" subresource.
+	// - https://docs.k8s.io/reference/access-authn-authz/admission-controllers/
+	// +kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="pgclones/finalizers",verbs={update}
+	if err == nil {
+		generateCronJob(clone, source, cronjob)
+
+		cronjob.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("CronJob"))
+		err = errors.WithStack(controllerutil.SetControllerReference(clone, cronjob, r.Client.Scheme()))
+	}
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, cronjob))
+	}
+
+	jobs := &batchv1.JobList{}
+	if err == nil {
+		err = errors.WithStack(r.List(ctx, jobs,
+			client.InNamespace(clone.Namespace),
+			client.MatchingLabels{naming.LabelPGClone: clone.Name}))
+	}
+
+	if err == nil {
+		if latest := latestScheduledJob(jobs.Items); latest != nil &&
+			latest.Name != clone.Status.LastScheduledJob {
+			err = r.refresh(ctx, clone, source, latest.Name)
+		}
+	}
+
+	return ctrl.Result{}, err
+}
+
+// refresh moves the target cluster of clone toward one that was created for
+// the scheduled Job named tick. The existing target is deleted first, and its
+// replacement is created once the deletion is complete.
+func (r *PGCloneReconciler) refresh(
+	ctx context.Context, clone *v1beta1.PGClone,
+	source *v1beta1.PostgresCluster, tick string,
+) error {
+	target := &v1beta1.PostgresCluster{}
+	err := errors.WithStack(r.Get(ctx, client.ObjectKey{
+		Namespace: clone.Namespace, Name: clone.Spec.TargetClusterName,
+	}, target))
+
+	switch {
+	case apierrors.IsNotFound(err):
+		target = generateTargetCluster(clone, source, tick)
+		err = errors.WithStack(controllerutil.SetControllerReference(clone, target, r.Client.Scheme()))
+		if err == nil {
+			err = errors.WithStack(r.Create(ctx, target, r.Owner))
+		}
+		if err == nil {
+			refreshed(clone, tick)
+		}
+
+	case err != nil:
+		// Return the error below.
+
+	case target.Annotations[naming.PGCloneRefresh] == tick:
+		// The target was created for this Job, but the status was not updated.
+		refreshed(clone, tick)
+
+	case !metav1.IsControlledBy(target, clone):
+		// Never delete a cluster that this clone did not create.
+		setRefreshed(clone, metav1.ConditionFalse, "TargetNotControlled", fmt.Sprintf(
+			"PostgresCluster %q exists and is not controlled by this PGClone",
+			clone.Spec.TargetClusterName))
+
+	case target.DeletionTimestamp == nil:
+		err = errors.WithStack(client.IgnoreNotFound(r.Delete(ctx, target,
+			client.Preconditions{UID: &target.UID})))
+		if err == nil {
+			setRefreshed(clone, metav1.ConditionFalse, "Deleting", fmt.Sprintf(
+				"Deleting PostgresCluster %q for Job %q", target.Name, tick))
+		}
+
+	default:
+		// Wait for the deletion to finish. The PostgresCluster is watched.
+	}
+
+	return err
+}
+
+// refreshed records that the target cluster of clone was created for the
+// scheduled Job named tick.
+func refreshed(clone *v1beta1.PGClone, tick string) {
+	clone.Status.LastScheduledJob = tick
+	clone.Status.LastRefreshTime = initialize.Pointer(metav1.Now())
+	setRefreshed(clone, metav1.ConditionTrue, "Created", fmt.Sprintf(
+		"Created PostgresCluster %q from the latest backup of %q for Job %q",
+		clone.Spec.TargetClusterName, clone.Spec.SourceClusterName, tick))
+}
+
+// setRefreshed sets the Refreshed condition of clone.
+func setRefreshed(clone *v1beta1.PGClone, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&clone.Status.Conditions, metav1.Condition{
+		ObservedGeneration: clone.Generation,
+		Type:               ConditionRefreshed,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+	})
+}
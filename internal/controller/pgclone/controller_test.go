@@ -0,0 +1,106 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgclone
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestRefresh(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	clone := &v1beta1.PGClone{}
+	clone.Namespace, clone.Name, clone.UID = "ns1", "staging", "clone-uid"
+	clone.Spec.RepoName = "repo1"
+	clone.Spec.SourceClusterName = "prod"
+	clone.Spec.TargetClusterName = "stage"
+
+	source := testSource()
+	key := client.ObjectKey{Namespace: "ns1", Name: "stage"}
+
+	t.Run("Create", func(t *testing.T) {
+		reconciler := &PGCloneReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).Build(),
+		}
+		clone := clone.DeepCopy()
+
+		assert.NilError(t, reconciler.refresh(ctx, clone, source, "tick-1"))
+		assert.Equal(t, clone.Status.LastScheduledJob, "tick-1")
+		assert.Assert(t, clone.Status.LastRefreshTime != nil)
+		assert.Assert(t, meta.IsStatusConditionTrue(clone.Status.Conditions, ConditionRefreshed))
+
+		target := &v1beta1.PostgresCluster{}
+		assert.NilError(t, reconciler.Get(ctx, key, target))
+		assert.Assert(t, metav1.IsControlledBy(target, clone))
+		assert.Equal(t, target.Annotations[naming.PGCloneRefresh], "tick-1")
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		existing := generateTargetCluster(clone, source, "tick-1")
+		existing.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(
+			clone, v1beta1.GroupVersion.WithKind("PGClone"))}
+
+		reconciler := &PGCloneReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build(),
+		}
+		clone := clone.DeepCopy()
+
+		// The first call deletes the existing target.
+		assert.NilError(t, reconciler.refresh(ctx, clone, source, "tick-2"))
+		assert.Equal(t, clone.Status.LastScheduledJob, "")
+		condition := meta.FindStatusCondition(clone.Status.Conditions, ConditionRefreshed)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Reason, "Deleting")
+		assert.Assert(t, apierrors.IsNotFound(reconciler.Get(ctx, key, &v1beta1.PostgresCluster{})))
+
+		// The next call creates it again.
+		assert.NilError(t, reconciler.refresh(ctx, clone, source, "tick-2"))
+		assert.Equal(t, clone.Status.LastScheduledJob, "tick-2")
+
+		target := &v1beta1.PostgresCluster{}
+		assert.NilError(t, reconciler.Get(ctx, key, target))
+		assert.Equal(t, target.Annotations[naming.PGCloneRefresh], "tick-2")
+	})
+
+	t.Run("NotControlled", func(t *testing.T) {
+		existing := v1beta1.NewPostgresCluster()
+		existing.Namespace, existing.Name = "ns1", "stage"
+
+		reconciler := &PGCloneReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing).Build(),
+		}
+		clone := clone.DeepCopy()
+
+		assert.NilError(t, reconciler.refresh(ctx, clone, source, "tick-1"))
+		condition := meta.FindStatusCondition(clone.Status.Conditions, ConditionRefreshed)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Reason, "TargetNotControlled")
+		assert.NilError(t, reconciler.Get(ctx, key, &v1beta1.PostgresCluster{}))
+	})
+}
@@ -0,0 +1,304 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgsqlrequest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	// ConditionExecuted is the type used in a condition to indicate whether
+	// or not the statement has been executed.
+	ConditionExecuted = "Executed"
+
+	// defaultLockTimeout is how long a statement waits for a lock when the
+	// request does not say otherwise.
+	defaultLockTimeout = 60
+
+	// maxOutputLength is the most output of PostgreSQL kept in the status.
+	// The end of the output is kept because that is where errors appear.
+	maxOutputLength = 1024
+)
+
+// PGSQLRequestReconciler reconciles a PGSQLRequest object
+type PGSQLRequestReconciler struct {
+	client.Client
+	Owner    client.FieldOwner
+	Recorder record.EventRecorder
+	Scheme   *runtime.Scheme
+
+	PodExec podExecutor
+}
+
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="pgsqlrequests",verbs={list,watch}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PGSQLRequestReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.PodExec == nil {
+		var err error
+		r.PodExec, err = newPodExecutor(mgr.GetConfig())
+		if err != nil {
+			return err
+		}
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta1.PGSQLRequest{}).
+		Complete(r)
+}
+
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="pgsqlrequests",verbs={get}
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="pgsqlrequests/status",verbs={patch}
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={get}
+//+kubebuilder:rbac:groups="",resources="pods",verbs={list}
+//+kubebuilder:rbac:groups="",resources="events",verbs={create,patch}
+
+// Reconcile executes the statement of the [v1beta1.PGSQLRequest] identified by
+// req on the primary of its cluster once it is approved. The statement runs
+// as the user of the request rather than as a superuser. It is executed at
+// most once:
+// its start is written to the status before it is executed, and a request that
+// started but did not finish, such as when the operator restarts, is marked
+// interrupted rather than executed again.
+func (r *PGSQLRequestReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	// NOTE: No DeepCopy is necessary here because controller-runtime makes a
+	// copy before returning from its cache.
+	// - https://github.com/kubernetes-sigs/controller-runtime/issues/1235
+	request := &v1beta1.PGSQLRequest{}
+	err = r.Get(ctx, req.NamespacedName, request)
+
+	var before *v1beta1.PGSQLRequest
+	if err == nil {
+		// Write any changes to the request status on the way out.
+		before = request.DeepCopy()
+		defer func() {
+			if !equality.Semantic.DeepEqual(before.Status, request.Status) {
+				status := r.Status().Patch(ctx, request, client.MergeFrom(before), r.Owner)
+
+				if err == nil && status != nil {
+					err = status
+				} else if status != nil {
+					log.Error(status, "Patching PGSQLRequest status")
+				}
+			}
+		}()
+	} else {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// Nothing changes after the statement finishes, and a statement that
+	// started but did not finish is not executed again.
+	if request.Status.CompletionTime != nil {
+		return ctrl.Result{}, nil
+	}
+	if request.Status.StartTime != nil {
+		const message = "The statement started but its result is unknown; it was not executed again"
+		request.Status.CompletionTime = initialize.Pointer(metav1.Now())
+		setExecuted(request, metav1.ConditionFalse, "Interrupted", message)
+		r.Recorder.Event(request, corev1.EventTypeWarning, "Interrupted", message)
+		return ctrl.Result{}, nil
+	}
+
+	statement, problem := checkStatement(request.Spec.Class, request.Spec.Statement)
+	if problem != nil {
+		setExecuted(request, metav1.ConditionFalse, "StatementRejected", problem.Error())
+		return ctrl.Result{}, nil
+	}
+
+	// The statement is executed only when the approval names it exactly.
+	// Changing the statement after it is approved requires another approval.
+	hash := fmt.Sprintf("%x", sha256.Sum256([]byte(statement)))
+	if approved := request.Annotations[naming.ApproveSQLRequest]; approved != hash {
+		reason := "AwaitingApproval"
+		if approved != "" {
+			reason = "ApprovalMismatch"
+		}
+		setExecuted(request, metav1.ConditionFalse, reason, fmt.Sprintf(
+			"The statement is executed once annotation %q is %q",
+			naming.ApproveSQLRequest, hash))
+		return ctrl.Result{}, nil
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	err = r.Get(ctx, client.ObjectKey{
+		Namespace: request.Namespace, Name: request.Spec.PostgresClusterName,
+	}, cluster)
+
+	if apierrors.IsNotFound(err) {
+		setExecuted(request, metav1.ConditionFalse, "ClusterNotFound",
+			fmt.Sprintf("PostgresCluster %q does not exist", request.Spec.PostgresClusterName))
+		return ctrl.Result{}, nil
+	}
+	if err == nil {
+		if problem := checkUser(cluster, request.Spec.User); problem != nil {
+			setExecuted(request, metav1.ConditionFalse, "UserRejected", problem.Error())
+			return ctrl.Result{}, nil
+		}
+	}
+
+	var pod *corev1.Pod
+	if err == nil {
+		pod, err = r.findPrimary(ctx, cluster)
+	}
+	if err == nil && pod == nil {
+		setExecuted(request, metav1.ConditionFalse, "PrimaryNotRunning",
+			fmt.Sprintf("PostgresCluster %q has no running primary", cluster.Name))
+		return ctrl.Result{RequeueAfter: time.Minute}, nil
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Record what is about to be executed before executing it. When this
+	// cannot be recorded, the statement is not executed.
+	request.Status.StartTime = initialize.Pointer(metav1.Now())
+	request.Status.Statement = statement
+	request.Status.StatementHash = hash
+	request.Status.Pod = pod.Name
+	setExecuted(request, metav1.ConditionUnknown, "Running", "The statement is executing")
+
+	if err = r.Status().Patch(ctx, request, client.MergeFrom(before), r.Owner); err != nil {
+		return ctrl.Result{}, err
+	}
+	before = request.DeepCopy()
+
+	log.Info("Executing SQL request",
+		"cluster", cluster.Name, "database", request.Spec.Database,
+		"user", request.Spec.User, "class", request.Spec.Class, "statementHash", request.Status.StatementHash,
+		"pod", pod.Name)
+
+	output, failure := r.execute(request, pod, statement)
+
+	request.Status.CompletionTime = initialize.Pointer(metav1.Now())
+	request.Status.Output = output
+
+	if failure == nil {
+		setExecuted(request, metav1.ConditionTrue, "Executed", "The statement succeeded")
+		message := fmt.Sprintf("Executed %s statement %.12s of PGSQLRequest %q in database %q as user %q",
+			request.Spec.Class, request.Status.StatementHash, request.Name, request.Spec.Database, request.Spec.User)
+		r.Recorder.Event(request, corev1.EventTypeNormal, "SQLRequestExecuted", message)
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, "SQLRequestExecuted", message)
+	} else {
+		setExecuted(request, metav1.ConditionFalse, "Failed", "The statement failed: "+failure.Error())
+		message := fmt.Sprintf("Failed to execute %s statement %.12s of PGSQLRequest %q in database %q as user %q",
+			request.Spec.Class, request.Status.StatementHash, request.Name, request.Spec.Database, request.Spec.User)
+		r.Recorder.Event(request, corev1.EventTypeWarning, "SQLRequestFailed", message)
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, "SQLRequestFailed", message)
+	}
+
+	log.Info("Executed SQL request",
+		"statementHash", request.Status.StatementHash,
+		"succeeded", failure == nil, "output", output)
+
+	return ctrl.Result{}, nil
+}
+
+// findPrimary returns the running primary Pod of cluster, if any.
+func (r *PGSQLRequestReconciler) findPrimary(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) (*corev1.Pod, error) {
+	pods := &corev1.PodList{}
+	selector, err := naming.AsSelector(naming.ClusterPrimary(cluster.Name))
+	if err == nil {
+		err = r.Client.List(ctx, pods,
+			client.InNamespace(cluster.Namespace),
+			client.MatchingLabelsSelector{Selector: selector},
+		)
+	}
+
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == corev1.PodRunning {
+			return &pods.Items[i], err
+		}
+	}
+	return nil, err
+}
+
+// execute runs statement with psql in the database container of pod as the
+// user of request. It returns the end of what psql printed and any error.
+func (r *PGSQLRequestReconciler) execute(
+	request *v1beta1.PGSQLRequest, pod *corev1.Pod, statement string,
+) (string, error) {
+	lockTimeout := int32(defaultLockTimeout)
+	if request.Spec.LockTimeoutSeconds != nil {
+		lockTimeout = *request.Spec.LockTimeoutSeconds
+	}
+
+	// The database is passed as a connection string so that its name is not
+	// interpreted any other way. Statements such as CREATE INDEX CONCURRENTLY
+	// cannot run in a transaction, so the statement is its own command.
+	// - https://www.postgresql.org/docs/current/libpq-connect.html#LIBPQ-CONNSTRING
+	//
+	// psql connects over the local socket as the superuser, so the role of the
+	// session is set to the user before the statement is read. A single
+	// statement of an approved class cannot set it back.
+	// - https://www.postgresql.org/docs/current/sql-set-role.html
+	conninfo := strings.Join([]string{
+		"dbname=" + quoteConninfo(request.Spec.Database),
+		"application_name=" + quoteConninfo("pgo-sqlrequest/"+request.Namespace+"/"+request.Name),
+		"options=" + quoteConninfo(fmt.Sprintf("-c lock_timeout=%ds -c role=%s",
+			lockTimeout, request.Spec.User)),
+	}, " ")
+
+	var output bytes.Buffer
+	err := r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase, nil, &output, &output,
+		"psql", "-Xw", "--set=ON_ERROR_STOP=1", "--dbname="+conninfo, "--command="+statement)
+
+	text := strings.TrimSpace(output.String())
+	if len(text) > maxOutputLength {
+		text = text[len(text)-maxOutputLength:]
+	}
+	return text, err
+}
+
+// quoteConninfo returns value quoted for a libpq connection string.
+func quoteConninfo(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
+}
+
+// setExecuted sets the Executed condition of request.
+func setExecuted(request *v1beta1.PGSQLRequest, status metav1.ConditionStatus, reason, message string) {
+	request.Status.ObservedGeneration = request.Generation
+	meta.SetStatusCondition(&request.Status.Conditions, metav1.Condition{
+		ObservedGeneration: request.Generation,
+		Type:               ConditionExecuted,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+	})
+}
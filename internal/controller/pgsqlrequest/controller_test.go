@@ -0,0 +1,262 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgsqlrequest
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestQuoteConninfo(t *testing.T) {
+	assert.Equal(t, quoteConninfo("app"), `'app'`)
+	assert.Equal(t, quoteConninfo(`it's \ here`), `'it\'s \\ here'`)
+}
+
+func TestReconcile(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+	cluster.Spec.Users = []v1beta1.PostgresUserSpec{
+		{Name: "app"}, {Name: "admin", Options: "LOGIN SUPERUSER"},
+	}
+
+	pod := &corev1.Pod{}
+	pod.Namespace, pod.Name = "ns1", "hippo-00-abcd-0"
+	pod.Labels = map[string]string{
+		naming.LabelCluster:  "hippo",
+		naming.LabelInstance: "hippo-00-abcd",
+		naming.LabelRole:     naming.RolePatroniLeader,
+	}
+	pod.Status.Phase = corev1.PodRunning
+
+	request := &v1beta1.PGSQLRequest{}
+	request.Namespace, request.Name = "ns1", "orders-index"
+	request.Spec.PostgresClusterName = "hippo"
+	request.Spec.Database = "app"
+	request.Spec.Class = "CreateIndexConcurrently"
+	request.Spec.Statement = "CREATE INDEX CONCURRENTLY orders_customer ON orders (customer_id);"
+	request.Spec.User = "app"
+	request.Annotations = map[string]string{
+		naming.ApproveSQLRequest: fmt.Sprintf("%x", sha256.Sum256([]byte(
+			"CREATE INDEX CONCURRENTLY orders_customer ON orders (customer_id)"))),
+	}
+
+	key := client.ObjectKeyFromObject(request)
+
+	setup := func(request *v1beta1.PGSQLRequest, exec podExecutor) (
+		*PGSQLRequestReconciler, *record.FakeRecorder,
+	) {
+		recorder := record.NewFakeRecorder(10)
+		return &PGSQLRequestReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(cluster.DeepCopy(), pod.DeepCopy(), request).Build(),
+			Recorder: recorder,
+			PodExec:  exec,
+		}, recorder
+	}
+
+	t.Run("Executed", func(t *testing.T) {
+		var commands [][]string
+		reconciler, recorder := setup(request.DeepCopy(), func(
+			namespace, pod, container string,
+			stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Equal(t, pod, "hippo-00-abcd-0")
+			assert.Equal(t, container, naming.ContainerDatabase)
+			commands = append(commands, command)
+			_, err := stdout.Write([]byte("CREATE INDEX\n"))
+			return err
+		})
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		assert.NilError(t, err)
+
+		assert.Equal(t, len(commands), 1)
+		assert.DeepEqual(t, commands[0], []string{
+			"psql", "-Xw", "--set=ON_ERROR_STOP=1",
+			"--dbname=dbname='app' application_name='pgo-sqlrequest/ns1/orders-index' options='-c lock_timeout=60s -c role=app'",
+			"--command=CREATE INDEX CONCURRENTLY orders_customer ON orders (customer_id)",
+		})
+
+		result := &v1beta1.PGSQLRequest{}
+		assert.NilError(t, reconciler.Get(ctx, key, result))
+		assert.Equal(t, result.Status.Statement, "CREATE INDEX CONCURRENTLY orders_customer ON orders (customer_id)")
+		assert.Equal(t, result.Status.StatementHash, request.Annotations[naming.ApproveSQLRequest])
+		assert.Equal(t, result.Status.Pod, "hippo-00-abcd-0")
+		assert.Equal(t, result.Status.Output, "CREATE INDEX")
+		assert.Assert(t, result.Status.StartTime != nil)
+		assert.Assert(t, result.Status.CompletionTime != nil)
+		assert.Assert(t, meta.IsStatusConditionTrue(result.Status.Conditions, ConditionExecuted))
+
+		// Events are recorded on the request and the cluster.
+		assert.Equal(t, len(recorder.Events), 2)
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "SQLRequestExecuted"))
+
+		// The statement is not executed again.
+		_, err = reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		assert.NilError(t, err)
+		assert.Equal(t, len(commands), 1)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		reconciler, recorder := setup(request.DeepCopy(), func(
+			namespace, pod, container string,
+			stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			_, _ = stderr.Write([]byte("ERROR:  canceling statement due to lock timeout\n"))
+			return errors.New("command terminated with exit code 1")
+		})
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		assert.NilError(t, err)
+
+		result := &v1beta1.PGSQLRequest{}
+		assert.NilError(t, reconciler.Get(ctx, key, result))
+		assert.Assert(t, cmp.Contains(result.Status.Output, "lock timeout"))
+
+		condition := meta.FindStatusCondition(result.Status.Conditions, ConditionExecuted)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "Failed")
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "SQLRequestFailed"))
+	})
+
+	t.Run("Interrupted", func(t *testing.T) {
+		request := request.DeepCopy()
+		request.Status.StartTime = initialize.Pointer(metav1.Now())
+
+		reconciler, _ := setup(request, func(
+			string, string, string, io.Reader, io.Writer, io.Writer, ...string,
+		) error {
+			t.Fatal("expected no execution")
+			return nil
+		})
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		assert.NilError(t, err)
+
+		result := &v1beta1.PGSQLRequest{}
+		assert.NilError(t, reconciler.Get(ctx, key, result))
+		assert.Assert(t, result.Status.CompletionTime != nil)
+
+		condition := meta.FindStatusCondition(result.Status.Conditions, ConditionExecuted)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Reason, "Interrupted")
+	})
+
+	t.Run("Rejected", func(t *testing.T) {
+		request := request.DeepCopy()
+		request.Spec.Statement = "CREATE INDEX CONCURRENTLY u ON t (a); DROP TABLE t"
+
+		reconciler, _ := setup(request, func(
+			string, string, string, io.Reader, io.Writer, io.Writer, ...string,
+		) error {
+			t.Fatal("expected no execution")
+			return nil
+		})
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+		assert.NilError(t, err)
+
+		result := &v1beta1.PGSQLRequest{}
+		assert.NilError(t, reconciler.Get(ctx, key, result))
+		assert.Assert(t, result.Status.StartTime == nil)
+
+		condition := meta.FindStatusCondition(result.Status.Conditions, ConditionExecuted)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Reason, "StatementRejected")
+		assert.Assert(t, cmp.Contains(condition.Message, "semicolon"))
+	})
+
+	t.Run("NotApproved", func(t *testing.T) {
+		for _, tt := range []struct {
+			approval, reason string
+		}{
+			{"", "AwaitingApproval"},
+			{fmt.Sprintf("%x", sha256.Sum256([]byte("ANALYZE"))), "ApprovalMismatch"},
+		} {
+			request := request.DeepCopy()
+			request.Annotations[naming.ApproveSQLRequest] = tt.approval
+
+			reconciler, _ := setup(request, func(
+				string, string, string, io.Reader, io.Writer, io.Writer, ...string,
+			) error {
+				t.Fatal("expected no execution")
+				return nil
+			})
+
+			_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			assert.NilError(t, err)
+
+			result := &v1beta1.PGSQLRequest{}
+			assert.NilError(t, reconciler.Get(ctx, key, result))
+			assert.Assert(t, result.Status.StartTime == nil)
+
+			condition := meta.FindStatusCondition(result.Status.Conditions, ConditionExecuted)
+			assert.Assert(t, condition != nil)
+			assert.Equal(t, condition.Reason, tt.reason)
+			assert.Assert(t, cmp.Contains(condition.Message,
+				fmt.Sprintf("%x", sha256.Sum256([]byte(
+					"CREATE INDEX CONCURRENTLY orders_customer ON orders (customer_id)")))))
+		}
+	})
+
+	t.Run("UserRejected", func(t *testing.T) {
+		for _, user := range []v1beta1.PostgresIdentifier{"admin", "postgres", "other"} {
+			request := request.DeepCopy()
+			request.Spec.User = user
+
+			reconciler, _ := setup(request, func(
+				string, string, string, io.Reader, io.Writer, io.Writer, ...string,
+			) error {
+				t.Fatal("expected no execution")
+				return nil
+			})
+
+			_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: key})
+			assert.NilError(t, err)
+
+			result := &v1beta1.PGSQLRequest{}
+			assert.NilError(t, reconciler.Get(ctx, key, result))
+			assert.Assert(t, result.Status.StartTime == nil)
+
+			condition := meta.FindStatusCondition(result.Status.Conditions, ConditionExecuted)
+			assert.Assert(t, condition != nil)
+			assert.Equal(t, condition.Reason, "UserRejected", "%q", user)
+		}
+	})
+}
@@ -0,0 +1,89 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgsqlrequest
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// statementClasses are the commands that begin a statement of each class in
+// [v1beta1.PGSQLRequestSpec]. Each is matched without regard to case.
+// - https://www.postgresql.org/docs/current/sql-commands.html
+var statementClasses = map[string]*regexp.Regexp{
+	"Analyze":                 regexp.MustCompile(`(?i)^ANALY[SZ]E\b`),
+	"CreateIndexConcurrently": regexp.MustCompile(`(?i)^CREATE\s+(UNIQUE\s+)?INDEX\s+CONCURRENTLY\b`),
+	"DropIndexConcurrently":   regexp.MustCompile(`(?i)^DROP\s+INDEX\s+CONCURRENTLY\b`),
+	"ReindexConcurrently": regexp.MustCompile(
+		`(?i)^REINDEX\s+(\([^)]*\bCONCURRENTLY\b[^)]*\)|(INDEX|TABLE|SCHEMA|DATABASE)\s+CONCURRENTLY\b)`),
+	"Vacuum": regexp.MustCompile(`(?i)^VACUUM\b`),
+}
+
+// vacuumFull matches the option of VACUUM that rewrites tables while holding
+// an ACCESS EXCLUSIVE lock.
+var vacuumFull = regexp.MustCompile(`(?i)\bFULL\b`)
+
+// checkStatement returns statement without surrounding space or a trailing
+// semicolon when it is a single statement of class. It returns an error when
+// statement contains anything that could end it and begin another, such as a
+// semicolon, a comment, or a psql meta-command.
+func checkStatement(class, statement string) (string, error) {
+	statement = strings.TrimSpace(statement)
+	statement = strings.TrimSpace(strings.TrimSuffix(statement, ";"))
+
+	pattern, ok := statementClasses[class]
+	switch {
+	case !ok:
+		return "", errors.Errorf("unknown statement class %q", class)
+	case statement == "":
+		return "", errors.New("statement is empty")
+	case strings.Contains(statement, ";"):
+		return "", errors.New("statement must not contain a semicolon")
+	case strings.Contains(statement, "--"), strings.Contains(statement, "/*"):
+		return "", errors.New("statement must not contain a comment")
+	case strings.Contains(statement, `\`):
+		return "", errors.New("statement must not contain a backslash")
+	case !pattern.MatchString(statement):
+		return "", errors.Errorf("statement is not of class %q", class)
+	case class == "Vacuum" && vacuumFull.MatchString(statement):
+		return "", errors.New("statement must not be VACUUM FULL")
+	}
+	return statement, nil
+}
+
+// superuser matches the role attribute that bypasses every permission check.
+// - https://www.postgresql.org/docs/current/role-attributes.html
+var superuser = regexp.MustCompile(`(?i)\bSUPERUSER\b`)
+
+// checkUser returns an error when user is not a user of cluster or when it
+// is a superuser.
+func checkUser(cluster *v1beta1.PostgresCluster, user v1beta1.PostgresIdentifier) error {
+	if user == "postgres" {
+		return errors.New(`user "postgres" is a superuser`)
+	}
+	for _, spec := range cluster.Spec.Users {
+		if spec.Name == user && superuser.MatchString(spec.Options) {
+			return errors.Errorf("user %q is a superuser", user)
+		}
+		if spec.Name == user {
+			return nil
+		}
+	}
+	return errors.Errorf("user %q is not a user of PostgresCluster %q", user, cluster.Name)
+}
@@ -0,0 +1,81 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgsqlrequest
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestCheckStatement(t *testing.T) {
+	for _, tt := range []struct {
+		class, statement, expected string
+	}{
+		{"Analyze", "ANALYZE", "ANALYZE"},
+		{"Analyze", "  analyse public.orders ;\n", "analyse public.orders"},
+		{"CreateIndexConcurrently",
+			"CREATE INDEX CONCURRENTLY orders_customer ON orders (customer_id);",
+			"CREATE INDEX CONCURRENTLY orders_customer ON orders (customer_id)"},
+		{"CreateIndexConcurrently",
+			"create unique index\n  concurrently if not exists u ON t (a)",
+			"create unique index\n  concurrently if not exists u ON t (a)"},
+		{"DropIndexConcurrently", "DROP INDEX CONCURRENTLY IF EXISTS u", "DROP INDEX CONCURRENTLY IF EXISTS u"},
+		{"ReindexConcurrently", "REINDEX TABLE CONCURRENTLY t", "REINDEX TABLE CONCURRENTLY t"},
+		{"ReindexConcurrently", "REINDEX (VERBOSE, CONCURRENTLY) INDEX u", "REINDEX (VERBOSE, CONCURRENTLY) INDEX u"},
+		{"Vacuum", "VACUUM (ANALYZE) full_text_documents", "VACUUM (ANALYZE) full_text_documents"},
+	} {
+		actual, err := checkStatement(tt.class, tt.statement)
+		assert.NilError(t, err, "%q", tt.statement)
+		assert.Equal(t, actual, tt.expected)
+	}
+
+	for _, tt := range []struct {
+		class, statement, message string
+	}{
+		{"Drop", "DROP TABLE t", "unknown"},
+		{"Analyze", " ; ", "empty"},
+		{"Analyze", "ANALYZE; DROP TABLE t", "semicolon"},
+		{"Analyze", "ANALYZE t -- comment", "comment"},
+		{"Analyze", "ANALYZE /* comment */ t", "comment"},
+		{"Analyze", `ANALYZE \! rm -rf /`, "backslash"},
+		{"Analyze", "VACUUM ANALYZE t", "not of class"},
+		{"CreateIndexConcurrently", "CREATE INDEX u ON t (a)", "not of class"},
+		{"DropIndexConcurrently", "DROP INDEX u", "not of class"},
+		{"ReindexConcurrently", "REINDEX TABLE t", "not of class"},
+		{"ReindexConcurrently", "REINDEX (VERBOSE) TABLE t", "not of class"},
+		{"Vacuum", "VACUUM FULL t", "FULL"},
+		{"Vacuum", "vacuum (full, analyze) t", "FULL"},
+	} {
+		_, err := checkStatement(tt.class, tt.statement)
+		assert.ErrorContains(t, err, tt.message, "%q", tt.statement)
+	}
+}
+
+func TestCheckUser(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Name = "hippo"
+	cluster.Spec.Users = []v1beta1.PostgresUserSpec{
+		{Name: "app", Options: "NOSUPERUSER CREATEDB"},
+		{Name: "admin", Options: "login superuser"},
+	}
+
+	assert.NilError(t, checkUser(cluster, "app"))
+	assert.ErrorContains(t, checkUser(cluster, "admin"), "superuser")
+	assert.ErrorContains(t, checkUser(cluster, "postgres"), "superuser")
+	assert.ErrorContains(t, checkUser(cluster, "other"), "not a user")
+}
@@ -0,0 +1,97 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgsupportbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"path"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// archive is a gzipped tarball built in memory. Every file is placed in a
+// directory named after the bundle so that archives can be extracted side by
+// side.
+type archive struct {
+	buffer    bytes.Buffer
+	directory string
+	gzip      *gzip.Writer
+	modified  time.Time
+	tar       *tar.Writer
+
+	// problems are things that could not be collected. They are written to
+	// a file when the archive is closed.
+	problems []string
+}
+
+func newArchive(directory string, modified time.Time) *archive {
+	a := &archive{directory: directory, modified: modified}
+	a.gzip = gzip.NewWriter(&a.buffer)
+	a.tar = tar.NewWriter(a.gzip)
+	return a
+}
+
+// add writes data to a file at name in the archive.
+func (a *archive) add(name string, data []byte) error {
+	err := a.tar.WriteHeader(&tar.Header{
+		Name:    path.Join(a.directory, name),
+		Mode:    0o644,
+		ModTime: a.modified,
+		Size:    int64(len(data)),
+	})
+	if err == nil {
+		_, err = a.tar.Write(data)
+	}
+	return errors.WithStack(err)
+}
+
+// addYAML writes object as YAML to a file at name in the archive.
+func (a *archive) addYAML(name string, object any) error {
+	data, err := yaml.Marshal(object)
+	if err == nil {
+		err = a.add(name, data)
+	}
+	return errors.WithStack(err)
+}
+
+// problem records that something could not be collected.
+func (a *archive) problem(format string, args ...any) {
+	a.problems = append(a.problems, fmt.Sprintf(format, args...))
+}
+
+// close finishes the archive and returns its contents.
+func (a *archive) close() ([]byte, error) {
+	var err error
+	if len(a.problems) > 0 {
+		var report bytes.Buffer
+		for _, problem := range a.problems {
+			report.WriteString(problem)
+			report.WriteString("\n")
+		}
+		err = a.add("problems.txt", report.Bytes())
+	}
+	if err == nil {
+		err = errors.WithStack(a.tar.Close())
+	}
+	if err == nil {
+		err = errors.WithStack(a.gzip.Close())
+	}
+	return a.buffer.Bytes(), err
+}
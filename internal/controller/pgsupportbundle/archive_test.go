@@ -0,0 +1,74 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgsupportbundle
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+)
+
+// readArchive returns the files in a gzipped tarball.
+func readArchive(t testing.TB, data []byte) map[string]string {
+	t.Helper()
+
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	assert.NilError(t, err)
+
+	files := map[string]string{}
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NilError(t, err)
+
+		content, err := io.ReadAll(reader)
+		assert.NilError(t, err)
+		files[header.Name] = string(content)
+	}
+	return files
+}
+
+func TestArchive(t *testing.T) {
+	a := newArchive("bundle", time.Unix(1700000000, 0))
+	assert.NilError(t, a.add("some/file.txt", []byte("content")))
+	assert.NilError(t, a.addYAML("object.yaml", map[string]int{"key": 1}))
+
+	data, err := a.close()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, readArchive(t, data), map[string]string{
+		"bundle/some/file.txt": "content",
+		"bundle/object.yaml":   "key: 1\n",
+	})
+
+	t.Run("Problems", func(t *testing.T) {
+		a := newArchive("bundle", time.Now())
+		a.problem("unable to %s", "read")
+		a.problem("no instance is running")
+
+		data, err := a.close()
+		assert.NilError(t, err)
+		assert.DeepEqual(t, readArchive(t, data), map[string]string{
+			"bundle/problems.txt": "unable to read\nno instance is running\n",
+		})
+	})
+}
@@ -0,0 +1,297 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgsupportbundle
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// metricsSQL reports the state of PostgreSQL that is most often asked for
+// during support: activity, replication, archiving, and changed settings.
+const metricsSQL = `
+SELECT pg_catalog.version(), pg_catalog.now(),
+  pg_catalog.pg_postmaster_start_time(), pg_catalog.pg_is_in_recovery();
+
+SELECT datname, numbackends, xact_commit, xact_rollback, blks_read, blks_hit,
+  temp_bytes, deadlocks, pg_catalog.pg_database_size(datid) AS size_bytes
+FROM pg_catalog.pg_stat_database WHERE datname IS NOT NULL ORDER BY datname;
+
+SELECT state, wait_event_type, count(*),
+  max(pg_catalog.now() - xact_start) AS longest_transaction
+FROM pg_catalog.pg_stat_activity WHERE backend_type = 'client backend'
+GROUP BY 1, 2 ORDER BY 1, 2;
+
+SELECT application_name, client_addr, state, sync_state,
+  write_lag, flush_lag, replay_lag FROM pg_catalog.pg_stat_replication;
+
+SELECT slot_name, slot_type, active, restart_lsn,
+  pg_catalog.pg_wal_lsn_diff(pg_catalog.pg_current_wal_lsn(), restart_lsn) AS retained_bytes
+FROM pg_catalog.pg_replication_slots;
+
+SELECT * FROM pg_catalog.pg_stat_archiver;
+
+SELECT name, setting, unit, source FROM pg_catalog.pg_settings
+WHERE source NOT IN ('default', 'override') ORDER BY name;
+`
+
+// postgresLogScript prints the end of the newest file written by the
+// PostgreSQL logging collector, if any.
+const postgresLogScript = `
+file=$(ls -t "${PGDATA}/log"/* 2> /dev/null | head -n1)
+[ -z "${file}" ] || tail -n "$1" "${file}"
+`
+
+// scrubObject removes fields that are noisy or that may contain credentials.
+// The keys of a Secret are kept but its values are not.
+func scrubObject(object client.Object) {
+	object.SetManagedFields(nil)
+
+	if annotations := object.GetAnnotations(); annotations != nil {
+		delete(annotations, corev1.LastAppliedConfigAnnotation)
+	}
+
+	if secret, ok := object.(*corev1.Secret); ok {
+		for key := range secret.Data {
+			secret.Data[key] = nil
+		}
+		secret.StringData = nil
+	}
+}
+
+// collectResources adds cluster and the objects that belong to it to a.
+// It returns the Pods that belong to cluster.
+func (r *PGSupportBundleReconciler) collectResources(
+	ctx context.Context, a *archive, cluster *v1beta1.PostgresCluster,
+) ([]corev1.Pod, error) {
+	var pods []corev1.Pod
+	names := sets.NewString(cluster.Name)
+
+	scrubObject(cluster)
+	err := a.addYAML("postgrescluster.yaml", cluster)
+
+	lists := []client.ObjectList{
+		&appsv1.DeploymentList{},
+		&appsv1.StatefulSetList{},
+		&batchv1.CronJobList{},
+		&batchv1.JobList{},
+		&corev1.ConfigMapList{},
+		&corev1.EndpointsList{},
+		&corev1.PersistentVolumeClaimList{},
+		&corev1.PodList{},
+		&corev1.SecretList{},
+		&corev1.ServiceAccountList{},
+		&corev1.ServiceList{},
+		&policyv1.PodDisruptionBudgetList{},
+		&rbacv1.RoleBindingList{},
+		&rbacv1.RoleList{},
+	}
+	for _, list := range lists {
+		if err != nil {
+			break
+		}
+		if err := r.Client.List(ctx, list,
+			client.InNamespace(cluster.Namespace),
+			client.MatchingLabels{naming.LabelCluster: cluster.Name},
+		); err != nil {
+			a.problem("unable to list %T: %v", list, err)
+			continue
+		}
+		if pods == nil {
+			if list, ok := list.(*corev1.PodList); ok {
+				pods = list.Items
+			}
+		}
+
+		items, _ := meta.ExtractList(list)
+		for _, item := range items {
+			object := item.(client.Object)
+			gvk, _ := apiutil.GVKForObject(object, r.Client.Scheme())
+			object.GetObjectKind().SetGroupVersionKind(gvk)
+			names.Insert(object.GetName())
+
+			scrubObject(object)
+			if err == nil {
+				err = a.addYAML(path.Join("resources",
+					strings.ToLower(gvk.Kind), object.GetName()+".yaml"), object)
+			}
+		}
+	}
+
+	if err == nil {
+		var events corev1.EventList
+		if err := r.Client.List(ctx, &events, client.InNamespace(cluster.Namespace)); err != nil {
+			a.problem("unable to list events: %v", err)
+		}
+
+		related := events.Items[:0]
+		for _, event := range events.Items {
+			if names.Has(event.InvolvedObject.Name) {
+				event.ManagedFields = nil
+				related = append(related, event)
+			}
+		}
+		sort.SliceStable(related, func(i, j int) bool {
+			return related[i].LastTimestamp.Before(&related[j].LastTimestamp)
+		})
+		err = a.addYAML("events.yaml", related)
+	}
+
+	return pods, err
+}
+
+// collectLogs adds the most recent logs of every container in pods to a.
+// The PostgreSQL log file of each instance is included when the logging
+// collector is enabled.
+func (r *PGSupportBundleReconciler) collectLogs(
+	ctx context.Context, a *archive, pods []corev1.Pod, lines int64,
+) error {
+	var err error
+	for _, pod := range pods {
+		for _, container := range pod.Spec.Containers {
+			data, problem := r.PodLogs(ctx, pod.Namespace, pod.Name, container.Name, lines)
+			if problem != nil {
+				a.problem("unable to read the log of %s/%s: %v", pod.Name, container.Name, problem)
+				continue
+			}
+			if err == nil {
+				err = a.add(path.Join("pods", pod.Name, container.Name+".log"), data)
+			}
+		}
+
+		if pod.Labels[naming.LabelInstance] != "" && pod.Status.Phase == corev1.PodRunning {
+			data, problem := r.exec(&pod, nil,
+				"bash", "-ceu", "--", postgresLogScript, "-", fmt.Sprint(lines))
+			if problem != nil {
+				a.problem("unable to read the PostgreSQL log of %s: %v", pod.Name, problem)
+			} else if err == nil && len(data) > 0 {
+				err = a.add(path.Join("pods", pod.Name, "postgresql.log"), data)
+			}
+		}
+	}
+	return err
+}
+
+// collectState adds the state of Patroni, pgBackRest, and PostgreSQL to a.
+// Everything is read from the primary, or from any running instance when
+// there is no primary.
+func (r *PGSupportBundleReconciler) collectState(a *archive, pods []corev1.Pod) error {
+	var pod *corev1.Pod
+	for i := range pods {
+		if pods[i].Labels[naming.LabelInstance] != "" &&
+			pods[i].Status.Phase == corev1.PodRunning &&
+			(pod == nil || pods[i].Labels[naming.LabelRole] == naming.RolePatroniLeader) {
+			pod = &pods[i]
+		}
+	}
+	if pod == nil {
+		a.problem("no instance is running")
+		return nil
+	}
+
+	var err error
+	for _, file := range []struct {
+		name    string
+		stdin   string
+		command []string
+	}{
+		{name: "patroni/list.json", command: []string{"patronictl", "list", "--format=json"}},
+		{name: "patroni/config.yaml", command: []string{"patronictl", "show-config"}},
+		{name: "pgbackrest/info.json", command: []string{"pgbackrest", "info", "--output=json"}},
+		{name: "postgres/metrics.txt", stdin: metricsSQL, command: []string{"psql", "-X", "--file=-"}},
+	} {
+		var stdin io.Reader
+		if file.stdin != "" {
+			stdin = strings.NewReader(file.stdin)
+		}
+
+		data, problem := r.exec(pod, stdin, file.command...)
+		if problem != nil {
+			a.problem("unable to run %q on %s: %v", file.command[0], pod.Name, problem)
+		}
+		if err == nil && len(data) > 0 {
+			err = a.add(file.name, data)
+		}
+	}
+	return err
+}
+
+// collectOperatorLogs adds the recent lines of the operator log that mention
+// cluster to a. Other lines may describe clusters in other namespaces, so
+// they are not collected.
+func (r *PGSupportBundleReconciler) collectOperatorLogs(
+	ctx context.Context, a *archive, cluster *v1beta1.PostgresCluster, lines int64,
+) error {
+	namespace := os.Getenv("PGO_NAMESPACE")
+	name, _ := os.Hostname()
+	if namespace == "" || name == "" {
+		a.problem("unable to identify the operator Pod")
+		return nil
+	}
+
+	// Read further back since most lines are filtered out.
+	data, err := r.PodLogs(ctx, namespace, name, "", lines*20)
+	if err != nil {
+		a.problem("unable to read the operator log: %v", err)
+		return nil
+	}
+
+	var matching [][]byte
+	for _, line := range bytes.SplitAfter(data, []byte("\n")) {
+		if bytes.Contains(line, []byte(cluster.Namespace)) &&
+			bytes.Contains(line, []byte(cluster.Name)) {
+			matching = append(matching, line)
+		}
+	}
+	if int64(len(matching)) > lines {
+		matching = matching[int64(len(matching))-lines:]
+	}
+	return a.add("operator.log", bytes.Join(matching, nil))
+}
+
+// exec runs command in the database container of pod and returns its output.
+// Anything printed to stderr is appended to the output.
+func (r *PGSupportBundleReconciler) exec(
+	pod *corev1.Pod, stdin io.Reader, command ...string,
+) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	err := r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase,
+		stdin, &stdout, &stderr, command...)
+
+	if stderr.Len() > 0 {
+		stdout.WriteString("\n")
+		stdout.Write(stderr.Bytes())
+	}
+	return stdout.Bytes(), err
+}
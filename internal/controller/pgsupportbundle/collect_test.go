@@ -0,0 +1,150 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgsupportbundle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestScrubObject(t *testing.T) {
+	secret := &corev1.Secret{}
+	secret.Annotations = map[string]string{
+		corev1.LastAppliedConfigAnnotation: `{"data":{"password":"c2VjcmV0"}}`,
+		"other":                            "kept",
+	}
+	secret.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "kubectl"}}
+	secret.Data = map[string][]byte{"password": []byte("secret")}
+	secret.StringData = map[string]string{"uri": "postgres://secret"}
+
+	scrubObject(secret)
+	assert.DeepEqual(t, secret.Annotations, map[string]string{"other": "kept"})
+	assert.Assert(t, secret.ManagedFields == nil)
+	assert.Assert(t, secret.StringData == nil)
+
+	// The keys remain without their values.
+	value, ok := secret.Data["password"]
+	assert.Assert(t, ok)
+	assert.Assert(t, value == nil)
+}
+
+func TestCollect(t *testing.T) {
+	t.Setenv("PGO_NAMESPACE", "pgo")
+	ctx := context.Background()
+
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+
+	labels := map[string]string{naming.LabelCluster: "hippo"}
+
+	secret := &corev1.Secret{}
+	secret.Namespace, secret.Name, secret.Labels = "ns1", "hippo-pguser-hippo", labels
+	secret.Data = map[string][]byte{"password": []byte("very-secret")}
+
+	pod := &corev1.Pod{}
+	pod.Namespace, pod.Name = "ns1", "hippo-00-abcd-0"
+	pod.Labels = map[string]string{
+		naming.LabelCluster:  "hippo",
+		naming.LabelInstance: "hippo-00-abcd",
+		naming.LabelRole:     naming.RolePatroniLeader,
+	}
+	pod.Spec.Containers = []corev1.Container{{Name: "database"}, {Name: "pgbackrest"}}
+	pod.Status.Phase = corev1.PodRunning
+
+	unrelated := &corev1.ConfigMap{}
+	unrelated.Namespace, unrelated.Name = "ns1", "other"
+
+	event := &corev1.Event{}
+	event.Namespace, event.Name = "ns1", "hippo.1"
+	event.InvolvedObject.Name = "hippo"
+	event.Reason = "Created"
+
+	reconciler := &PGSupportBundleReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(cluster, secret, pod, unrelated, event).Build(),
+		PodExec: func(
+			namespace, pod, container string,
+			stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			switch command[0] {
+			case "patronictl":
+				_, _ = stdout.Write([]byte("[]"))
+			case "pgbackrest":
+				_, _ = stderr.Write([]byte("ERROR: [056]: unable to find primary cluster"))
+				return errors.New("command terminated with exit code 56")
+			}
+			return nil
+		},
+		PodLogs: func(
+			ctx context.Context, namespace, pod, container string, lines int64,
+		) ([]byte, error) {
+			if namespace == "pgo" {
+				return []byte("" +
+					"reconciling name=hippo namespace=ns1\n" +
+					"reconciling name=rhino namespace=ns2\n"), nil
+			}
+			return []byte(container + " log\n"), nil
+		},
+	}
+
+	bundle := &v1beta1.PGSupportBundle{}
+	bundle.Name = "support"
+
+	data, err := reconciler.collect(ctx, bundle, cluster.DeepCopy(), time.Now())
+	assert.NilError(t, err)
+
+	files := readArchive(t, data)
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+
+	assert.Assert(t, cmp.Contains(names, "support/postgrescluster.yaml"))
+	assert.Assert(t, cmp.Contains(names, "support/resources/pod/hippo-00-abcd-0.yaml"))
+	assert.Assert(t, cmp.Contains(names, "support/pods/hippo-00-abcd-0/database.log"))
+	assert.Assert(t, cmp.Contains(names, "support/pods/hippo-00-abcd-0/pgbackrest.log"))
+	assert.Assert(t, cmp.Contains(names, "support/patroni/list.json"))
+	assert.Assert(t, !strings.Contains(strings.Join(names, " "), "resources/configmap/other"))
+
+	// Secrets are collected without their values.
+	assert.Assert(t, cmp.Contains(files["support/resources/secret/hippo-pguser-hippo.yaml"], "password: null"))
+	for name, content := range files {
+		assert.Assert(t, !strings.Contains(content, "very-secret"), "found in %q", name)
+	}
+
+	assert.Assert(t, cmp.Contains(files["support/events.yaml"], "reason: Created"))
+	assert.Equal(t, files["support/operator.log"], "reconciling name=hippo namespace=ns1\n")
+
+	// Failures are recorded rather than stopping the collection.
+	assert.Assert(t, cmp.Contains(files["support/pgbackrest/info.json"], "unable to find primary cluster"))
+	assert.Assert(t, cmp.Contains(files["support/problems.txt"], "exit code 56"))
+}
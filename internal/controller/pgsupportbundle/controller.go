@@ -0,0 +1,221 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgsupportbundle
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	// ConditionCollected is the type used in a condition to indicate whether
+	// or not the archive has been collected.
+	ConditionCollected = "Collected"
+
+	// archiveKey is the key of the ConfigMap that contains the archive.
+	archiveKey = "bundle.tar.gz"
+
+	// defaultLogLines is the number of log lines collected when the bundle
+	// does not say otherwise.
+	defaultLogLines = 500
+
+	// maxArchiveSize leaves room in the ConfigMap for its metadata. Objects
+	// in Kubernetes are limited to about 1.5MiB.
+	// - https://docs.k8s.io/concepts/configuration/configmap/#motivation
+	maxArchiveSize = 1 << 20
+)
+
+// PGSupportBundleReconciler reconciles a PGSupportBundle object
+type PGSupportBundleReconciler struct {
+	client.Client
+	Owner  client.FieldOwner
+	Scheme *runtime.Scheme
+
+	PodExec podExecutor
+	PodLogs podLogReader
+}
+
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="pgsupportbundles",verbs={list,watch}
+//+kubebuilder:rbac:groups="",resources="configmaps",verbs={list,watch}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PGSupportBundleReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if r.PodExec == nil {
+		var err error
+		r.PodExec, err = newPodExecutor(mgr.GetConfig())
+		if err != nil {
+			return err
+		}
+	}
+	if r.PodLogs == nil {
+		var err error
+		r.PodLogs, err = newPodLogReader(mgr.GetConfig())
+		if err != nil {
+			return err
+		}
+	}
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta1.PGSupportBundle{}).
+		Owns(&corev1.ConfigMap{}).
+		Complete(r)
+}
+
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="pgsupportbundles",verbs={get}
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="pgsupportbundles/status",verbs={patch}
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={get}
+//+kubebuilder:rbac:groups="",resources="configmaps",verbs={create,patch}
+//+kubebuilder:rbac:groups="",resources="events",verbs={list}
+//+kubebuilder:rbac:groups="",resources="configmaps;endpoints;persistentvolumeclaims;pods;secrets;serviceaccounts;services",verbs={list}
+//+kubebuilder:rbac:groups="apps",resources="deployments;statefulsets",verbs={list}
+//+kubebuilder:rbac:groups="batch",resources="cronjobs;jobs",verbs={list}
+//+kubebuilder:rbac:groups="policy",resources="poddisruptionbudgets",verbs={list}
+//+kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources="roles;rolebindings",verbs={list}
+
+// Reconcile collects the archive described by the [v1beta1.PGSupportBundle]
+// identified by req. The archive is collected once for each generation.
+func (r *PGSupportBundleReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	// NOTE: No DeepCopy is necessary here because controller-runtime makes a
+	// copy before returning from its cache.
+	// - https://github.com/kubernetes-sigs/controller-runtime/issues/1235
+	bundle := &v1beta1.PGSupportBundle{}
+	err = r.Get(ctx, req.NamespacedName, bundle)
+
+	if err == nil {
+		// Write any changes to the bundle status on the way out.
+		before := bundle.DeepCopy()
+		defer func() {
+			if !equality.Semantic.DeepEqual(before.Status, bundle.Status) {
+				status := r.Status().Patch(ctx, bundle, client.MergeFrom(before), r.Owner)
+
+				if err == nil && status != nil {
+					err = status
+				} else if status != nil {
+					log.Error(status, "Patching PGSupportBundle status")
+				}
+			}
+		}()
+	} else {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	// Collect only once for each generation.
+	if bundle.Status.ObservedGeneration == bundle.Generation &&
+		meta.IsStatusConditionTrue(bundle.Status.Conditions, ConditionCollected) {
+		return ctrl.Result{}, nil
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	err = r.Get(ctx, client.ObjectKey{
+		Namespace: bundle.Namespace, Name: bundle.Spec.PostgresClusterName,
+	}, cluster)
+
+	if apierrors.IsNotFound(err) {
+		setCollected(bundle, metav1.ConditionFalse, "ClusterNotFound",
+			fmt.Sprintf("PostgresCluster %q does not exist", bundle.Spec.PostgresClusterName))
+		return ctrl.Result{}, nil
+	}
+
+	var data []byte
+	if err == nil {
+		data, err = r.collect(ctx, bundle, cluster, time.Now())
+	}
+	if err == nil && len(data) > maxArchiveSize {
+		setCollected(bundle, metav1.ConditionFalse, "TooLarge", fmt.Sprintf(
+			"The archive is %d bytes; collect fewer log lines to fit in a ConfigMap", len(data)))
+		return ctrl.Result{}, nil
+	}
+
+	configmap := &corev1.ConfigMap{}
+	configmap.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+	configmap.Namespace, configmap.Name = bundle.Namespace, bundle.Name
+	configmap.BinaryData = map[string][]byte{archiveKey: data}
+
+	// The owner reference blocks deletion, so the OwnerReferencesPermissionEnforcement
+	// plugin requires "update" permission on the owner's "finalizers" subresource.
+	// - https://docs.k8s.io/reference/access-authn-authz/admission-controllers/
+	// +kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="pgsupportbundles/finalizers",verbs={update}
+	if err == nil {
+		err = errors.WithStack(controllerutil.SetControllerReference(bundle, configmap, r.Client.Scheme()))
+	}
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, configmap))
+	}
+	if err == nil {
+		bundle.Status.ConfigMap = configmap.Name
+		bundle.Status.CompletionTime = initialize.Pointer(metav1.Now())
+		setCollected(bundle, metav1.ConditionTrue, "Collected", fmt.Sprintf(
+			"The archive is in the %q key of ConfigMap %q", archiveKey, configmap.Name))
+	}
+
+	return ctrl.Result{}, err
+}
+
+// collect gathers everything about cluster into a gzipped tarball.
+func (r *PGSupportBundleReconciler) collect(
+	ctx context.Context, bundle *v1beta1.PGSupportBundle,
+	cluster *v1beta1.PostgresCluster, now time.Time,
+) ([]byte, error) {
+	lines := int64(defaultLogLines)
+	if bundle.Spec.LogLines != nil {
+		lines = int64(*bundle.Spec.LogLines)
+	}
+
+	a := newArchive(bundle.Name, now)
+	pods, err := r.collectResources(ctx, a, cluster)
+
+	if err == nil {
+		err = r.collectLogs(ctx, a, pods, lines)
+	}
+	if err == nil {
+		err = r.collectState(a, pods)
+	}
+	if err == nil {
+		err = r.collectOperatorLogs(ctx, a, cluster, lines)
+	}
+	if err == nil {
+		return a.close()
+	}
+	return nil, err
+}
+
+// setCollected sets the Collected condition of bundle.
+func setCollected(bundle *v1beta1.PGSupportBundle, status metav1.ConditionStatus, reason, message string) {
+	bundle.Status.ObservedGeneration = bundle.Generation
+	meta.SetStatusCondition(&bundle.Status.Conditions, metav1.Condition{
+		ObservedGeneration: bundle.Generation,
+		Type:               ConditionCollected,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+	})
+}
@@ -0,0 +1,287 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgtopology
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	// ConditionConverged is the type used in a condition to indicate whether
+	// or not every member of a topology has the role it should.
+	ConditionConverged = "Converged"
+)
+
+// PGTopologyReconciler reconciles a PGTopology object
+type PGTopologyReconciler struct {
+	client.Client
+	Owner  client.FieldOwner
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="pgtopologies",verbs={list,watch}
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={list,watch}
+//+kubebuilder:rbac:groups="",resources="pods",verbs={list,watch}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *PGTopologyReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta1.PGTopology{}).
+		Watches(
+			&source.Kind{Type: v1beta1.NewPostgresCluster()},
+			r.watchMembers(func(object client.Object) string { return object.GetName() }),
+		).
+		Watches(
+			&source.Kind{Type: &corev1.Pod{}},
+			r.watchMembers(func(object client.Object) string {
+				return object.GetLabels()[naming.LabelCluster]
+			}),
+		).
+		Complete(r)
+}
+
+// watchMembers returns a [handler.EventHandler] that enqueues the topologies
+// that have the PostgresCluster named by member as a member.
+func (r *PGTopologyReconciler) watchMembers(member func(client.Object) string) handler.Funcs {
+	handle := func(object client.Object, q workqueue.RateLimitingInterface) {
+		name := member(object)
+		if name == "" {
+			return
+		}
+
+		ctx := context.Background()
+		topologies := &v1beta1.PGTopologyList{}
+		if err := r.List(ctx, topologies, client.InNamespace(object.GetNamespace())); err != nil {
+			return
+		}
+
+		for i := range topologies.Items {
+			for _, m := range topologies.Items[i].Spec.Members {
+				if m.Name == name {
+					q.Add(ctrl.Request{
+						NamespacedName: client.ObjectKeyFromObject(&topologies.Items[i]),
+					})
+				}
+			}
+		}
+	}
+
+	return handler.Funcs{
+		CreateFunc: func(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			handle(e.Object, q)
+		},
+		UpdateFunc: func(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			handle(e.ObjectNew, q)
+		},
+		DeleteFunc: func(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			handle(e.Object, q)
+		},
+	}
+}
+
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="pgtopologies",verbs={get}
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="pgtopologies/status",verbs={patch}
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={get,patch}
+//+kubebuilder:rbac:groups="",resources="pods",verbs={list}
+
+// Reconcile moves the members of the [v1beta1.PGTopology] identified by req
+// toward their roles. Standbys are demoted and pointed at the primary first;
+// the primary is promoted once no other member acts as primary.
+func (r *PGTopologyReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	// NOTE: No DeepCopy is necessary here because controller-runtime makes a
+	// copy before returning from its cache.
+	// - https://github.com/kubernetes-sigs/controller-runtime/issues/1235
+	topology := &v1beta1.PGTopology{}
+	err = r.Get(ctx, req.NamespacedName, topology)
+
+	if err == nil {
+		// Write any changes to the topology status on the way out.
+		before := topology.DeepCopy()
+		defer func() {
+			if !equality.Semantic.DeepEqual(before.Status, topology.Status) {
+				status := r.Status().Patch(ctx, topology, client.MergeFrom(before), r.Owner)
+
+				if err == nil && status != nil {
+					err = status
+				} else if status != nil {
+					log.Error(status, "Patching PGTopology status")
+				}
+			}
+		}()
+	} else {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	topology.Status.ObservedGeneration = topology.Generation
+
+	if message := validate(topology); message != "" {
+		setConverged(topology, metav1.ConditionFalse, "InvalidSpec", message)
+		return ctrl.Result{}, nil
+	}
+
+	clusters := make(map[string]*v1beta1.PostgresCluster, len(topology.Spec.Members))
+	observed := make([]v1beta1.PGTopologyMemberStatus, 0, len(topology.Spec.Members))
+	var missing []string
+
+	for _, member := range topology.Spec.Members {
+		cluster := v1beta1.NewPostgresCluster()
+		err = errors.WithStack(r.Get(ctx, client.ObjectKey{
+			Namespace: topology.Namespace, Name: member.Name,
+		}, cluster))
+
+		if apierrors.IsNotFound(err) {
+			missing = append(missing, member.Name)
+			observed = append(observed, v1beta1.PGTopologyMemberStatus{
+				Name: member.Name, Role: roleUnknown,
+			})
+			continue
+		}
+
+		pods := &corev1.PodList{}
+		if err == nil {
+			err = errors.WithStack(r.List(ctx, pods,
+				client.InNamespace(topology.Namespace),
+				client.MatchingLabels{naming.LabelCluster: member.Name}))
+		}
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		clusters[member.Name] = cluster
+		observed = append(observed, observeMember(member.Name, pods.Items))
+	}
+
+	setLag(topology.Spec.Primary, observed)
+	topology.Status.Members = observed
+
+	// Do not change any roles without seeing every member.
+	if len(missing) > 0 {
+		setConverged(topology, metav1.ConditionFalse, "MemberNotFound", fmt.Sprintf(
+			"PostgresClusters %s do not exist", strings.Join(missing, ", ")))
+		return ctrl.Result{}, nil
+	}
+
+	err = r.converge(ctx, topology, clusters)
+	return ctrl.Result{}, err
+}
+
+// converge demotes every member of topology that is not the primary, points
+// it at the primary, and then promotes the primary. Each step waits for the
+// observed roles in the status of topology to catch up.
+func (r *PGTopologyReconciler) converge(
+	ctx context.Context, topology *v1beta1.PGTopology,
+	clusters map[string]*v1beta1.PostgresCluster,
+) error {
+	primary := clusters[topology.Spec.Primary]
+	standby := standbyPatch(topology, primary)
+
+	var err error
+	for _, member := range topology.Spec.Members {
+		cluster := clusters[member.Name]
+		if err == nil && cluster != primary && !standbyMatches(cluster.Spec.Standby, standby) {
+			err = r.patchStandby(ctx, cluster, standby)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	var actingPrimary, notStandby []string
+	primaryRole := roleUnknown
+	for _, member := range topology.Status.Members {
+		switch {
+		case member.Name == topology.Spec.Primary:
+			primaryRole = member.Role
+		case member.Role == rolePrimary:
+			actingPrimary = append(actingPrimary, member.Name)
+		case member.Role != roleStandby:
+			notStandby = append(notStandby, member.Name)
+		}
+	}
+
+	switch {
+	case len(actingPrimary) > 0:
+		// Two members that accept writes diverge; wait for the others to stop.
+		setConverged(topology, metav1.ConditionFalse, "WaitingForDemotion", fmt.Sprintf(
+			"Waiting for %s to stop acting as primary", strings.Join(actingPrimary, ", ")))
+
+	case primary.Spec.Standby != nil && primary.Spec.Standby.Enabled:
+		err = r.patchStandby(ctx, primary, map[string]any{"enabled": false})
+		if err == nil {
+			setConverged(topology, metav1.ConditionFalse, "Promoting", fmt.Sprintf(
+				"Promoting %q", primary.Name))
+		}
+
+	case primaryRole != rolePrimary:
+		setConverged(topology, metav1.ConditionFalse, "Promoting", fmt.Sprintf(
+			"Waiting for %q to act as primary", primary.Name))
+
+	case len(notStandby) > 0:
+		setConverged(topology, metav1.ConditionFalse, "WaitingForStandbys", fmt.Sprintf(
+			"Waiting for %s to act as standby", strings.Join(notStandby, ", ")))
+
+	default:
+		setConverged(topology, metav1.ConditionTrue, "Converged", fmt.Sprintf(
+			"%q is primary and every other member follows it", primary.Name))
+	}
+
+	return err
+}
+
+// patchStandby sends a merge patch of the "spec.standby" fields of cluster.
+func (r *PGTopologyReconciler) patchStandby(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, standby map[string]any,
+) error {
+	data, err := json.Marshal(map[string]any{
+		"spec": map[string]any{"standby": standby},
+	})
+	if err == nil {
+		err = r.Patch(ctx, cluster, client.RawPatch(client.Merge.Type(), data), r.Owner)
+	}
+	return errors.WithStack(err)
+}
+
+// setConverged sets the Converged condition of topology.
+func setConverged(topology *v1beta1.PGTopology, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&topology.Status.Conditions, metav1.Condition{
+		ObservedGeneration: topology.Generation,
+		Type:               ConditionConverged,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+	})
+}
@@ -0,0 +1,93 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgtopology
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestConverge(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	cluster := func(name string, standby bool) *v1beta1.PostgresCluster {
+		c := v1beta1.NewPostgresCluster()
+		c.Namespace, c.Name = "ns1", name
+		c.Spec.Port = initialize.Int32(5432)
+		if standby {
+			c.Spec.Standby = &v1beta1.PostgresStandbySpec{Enabled: true, RepoName: "repo1"}
+		}
+		return c
+	}
+
+	topology := &v1beta1.PGTopology{}
+	topology.Namespace, topology.Name = "ns1", "global"
+	topology.Spec.Primary = "west"
+	topology.Spec.RepoName = "repo1"
+	topology.Spec.Members = []v1beta1.PGTopologyMember{{Name: "east"}, {Name: "west"}}
+
+	// "east" is primary and "west" should take over.
+	east, west := cluster("east", false), cluster("west", true)
+	reconciler := &PGTopologyReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(east, west).Build(),
+	}
+
+	reload := func() map[string]*v1beta1.PostgresCluster {
+		out := map[string]*v1beta1.PostgresCluster{}
+		for _, name := range []string{"east", "west"} {
+			c := v1beta1.NewPostgresCluster()
+			assert.NilError(t, reconciler.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: name}, c))
+			out[name] = c
+		}
+		return out
+	}
+	reason := func() string {
+		return meta.FindStatusCondition(topology.Status.Conditions, ConditionConverged).Reason
+	}
+
+	// The old primary is demoted first, and the new one is not yet promoted.
+	topology.Status.Members = []v1beta1.PGTopologyMemberStatus{
+		{Name: "east", Role: "Primary"}, {Name: "west", Role: "Standby"},
+	}
+	assert.NilError(t, reconciler.converge(ctx, topology, reload()))
+	assert.Equal(t, reason(), "WaitingForDemotion")
+
+	clusters := reload()
+	assert.Assert(t, clusters["east"].Spec.Standby.Enabled)
+	assert.Equal(t, clusters["east"].Spec.Standby.RepoName, "repo1")
+	assert.Assert(t, clusters["west"].Spec.Standby.Enabled)
+
+	// Once the old primary is a standby, the new one is promoted.
+	topology.Status.Members[0].Role = "Standby"
+	assert.NilError(t, reconciler.converge(ctx, topology, reload()))
+	assert.Equal(t, reason(), "Promoting")
+	assert.Assert(t, !reload()["west"].Spec.Standby.Enabled)
+
+	// Converged once the new primary acts as primary.
+	topology.Status.Members[1].Role = "Primary"
+	assert.NilError(t, reconciler.converge(ctx, topology, reload()))
+	assert.Assert(t, meta.IsStatusConditionTrue(topology.Status.Conditions, ConditionConverged))
+}
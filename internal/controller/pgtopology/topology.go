@@ -0,0 +1,134 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgtopology
+
+import (
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/patroni"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	rolePrimary = "Primary"
+	roleStandby = "Standby"
+	roleUnknown = "Unknown"
+)
+
+// observeMember returns the role and WAL position of the leader among pods.
+// The WAL position is nil when it cannot be determined.
+func observeMember(name string, pods []corev1.Pod) v1beta1.PGTopologyMemberStatus {
+	status := v1beta1.PGTopologyMemberStatus{Name: name, Role: roleUnknown}
+
+	for i := range pods {
+		pod := &pods[i]
+		switch {
+		case patroni.PodIsPrimary(pod):
+			status.Role = rolePrimary
+		case patroni.PodIsStandbyLeader(pod):
+			status.Role = roleStandby
+		default:
+			continue
+		}
+
+		// Patroni stores the WAL position of each member in its annotation.
+		// - https://github.com/zalando/patroni/blob/v3.1.1/patroni/dcs/kubernetes.py
+		var annotation struct {
+			Position *int64 `json:"xlog_location"`
+		}
+		if json.Unmarshal([]byte(pod.Annotations["status"]), &annotation) == nil {
+			status.WALPosition = annotation.Position
+		}
+		break
+	}
+
+	return status
+}
+
+// setLag fills in how far each of members is behind the member named primary.
+func setLag(primary string, members []v1beta1.PGTopologyMemberStatus) {
+	var position *int64
+	for _, member := range members {
+		if member.Name == primary {
+			position = member.WALPosition
+		}
+	}
+
+	for i := range members {
+		members[i].LagBytes = nil
+		if position != nil && members[i].WALPosition != nil {
+			lag := *position - *members[i].WALPosition
+			if lag < 0 {
+				lag = 0
+			}
+			members[i].LagBytes = &lag
+		}
+	}
+}
+
+// standbyPatch returns the fields of "spec.standby" that make a member of
+// topology follow primary. Fields that are not used are null so that a merge
+// patch removes them.
+func standbyPatch(topology *v1beta1.PGTopology, primary *v1beta1.PostgresCluster) map[string]any {
+	patch := map[string]any{
+		"enabled":  true,
+		"repoName": nil,
+		"host":     nil,
+		"port":     nil,
+	}
+	if topology.Spec.RepoName != "" {
+		patch["repoName"] = topology.Spec.RepoName
+	}
+	if topology.Spec.Streaming {
+		service := naming.ClusterPrimaryService(primary)
+		patch["host"] = fmt.Sprintf("%s.%s.svc", service.Name, service.Namespace)
+		if primary.Spec.Port != nil {
+			patch["port"] = *primary.Spec.Port
+		}
+	}
+	return patch
+}
+
+// standbyMatches returns whether or not spec already has the fields of patch.
+func standbyMatches(spec *v1beta1.PostgresStandbySpec, patch map[string]any) bool {
+	if spec == nil || !spec.Enabled {
+		return false
+	}
+
+	repo, _ := patch["repoName"].(string)
+	host, _ := patch["host"].(string)
+	port, hasPort := patch["port"].(int32)
+
+	return spec.RepoName == repo && spec.Host == host &&
+		(spec.Port != nil) == hasPort && (!hasPort || *spec.Port == port)
+}
+
+// validate returns a message describing what is wrong with the spec of
+// topology, if anything.
+func validate(topology *v1beta1.PGTopology) string {
+	if topology.Spec.RepoName == "" && !topology.Spec.Streaming {
+		return "At least one of repoName or streaming is required"
+	}
+	for _, member := range topology.Spec.Members {
+		if member.Name == topology.Spec.Primary {
+			return ""
+		}
+	}
+	return fmt.Sprintf("The primary %q is not a member", topology.Spec.Primary)
+}
@@ -0,0 +1,114 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgtopology
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestObserveMember(t *testing.T) {
+	pod := func(status string) corev1.Pod {
+		p := corev1.Pod{}
+		p.Annotations = map[string]string{"status": status}
+		return p
+	}
+
+	status := observeMember("east", nil)
+	assert.Equal(t, status.Name, "east")
+	assert.Equal(t, status.Role, "Unknown")
+	assert.Assert(t, status.WALPosition == nil)
+
+	status = observeMember("east", []corev1.Pod{
+		pod(`{"role":"replica","xlog_location":50}`),
+		pod(`{"role":"master","xlog_location":100}`),
+	})
+	assert.Equal(t, status.Role, "Primary")
+	assert.Equal(t, *status.WALPosition, int64(100))
+
+	status = observeMember("west", []corev1.Pod{
+		pod(`{"role":"standby_leader","xlog_location":80}`),
+	})
+	assert.Equal(t, status.Role, "Standby")
+	assert.Equal(t, *status.WALPosition, int64(80))
+}
+
+func TestSetLag(t *testing.T) {
+	members := []v1beta1.PGTopologyMemberStatus{
+		{Name: "east", WALPosition: initialize.Pointer(int64(100))},
+		{Name: "west", WALPosition: initialize.Pointer(int64(80))},
+		{Name: "north", WALPosition: initialize.Pointer(int64(120))},
+		{Name: "south"},
+	}
+
+	setLag("east", members)
+	assert.Equal(t, *members[0].LagBytes, int64(0))
+	assert.Equal(t, *members[1].LagBytes, int64(20))
+	assert.Equal(t, *members[2].LagBytes, int64(0))
+	assert.Assert(t, members[3].LagBytes == nil)
+
+	setLag("south", members)
+	assert.Assert(t, members[0].LagBytes == nil)
+}
+
+func TestStandbyPatch(t *testing.T) {
+	topology := &v1beta1.PGTopology{}
+	primary := v1beta1.NewPostgresCluster()
+	primary.Namespace, primary.Name = "ns1", "east"
+	primary.Spec.Port = initialize.Int32(5432)
+
+	topology.Spec.RepoName = "repo2"
+	patch := standbyPatch(topology, primary)
+	assert.DeepEqual(t, patch, map[string]any{
+		"enabled": true, "repoName": "repo2", "host": nil, "port": nil,
+	})
+	assert.Assert(t, standbyMatches(&v1beta1.PostgresStandbySpec{
+		Enabled: true, RepoName: "repo2",
+	}, patch))
+	assert.Assert(t, !standbyMatches(nil, patch))
+	assert.Assert(t, !standbyMatches(&v1beta1.PostgresStandbySpec{RepoName: "repo2"}, patch))
+
+	topology.Spec.Streaming = true
+	patch = standbyPatch(topology, primary)
+	assert.DeepEqual(t, patch, map[string]any{
+		"enabled": true, "repoName": "repo2",
+		"host": "east-primary.ns1.svc", "port": int32(5432),
+	})
+	assert.Assert(t, !standbyMatches(&v1beta1.PostgresStandbySpec{
+		Enabled: true, RepoName: "repo2",
+	}, patch))
+	assert.Assert(t, standbyMatches(&v1beta1.PostgresStandbySpec{
+		Enabled: true, RepoName: "repo2",
+		Host: "east-primary.ns1.svc", Port: initialize.Int32(5432),
+	}, patch))
+}
+
+func TestValidate(t *testing.T) {
+	topology := &v1beta1.PGTopology{}
+	topology.Spec.Primary = "east"
+	topology.Spec.Members = []v1beta1.PGTopologyMember{{Name: "east"}, {Name: "west"}}
+	assert.Assert(t, validate(topology) != "", "expected repoName or streaming")
+
+	topology.Spec.Streaming = true
+	assert.Equal(t, validate(topology), "")
+
+	topology.Spec.Primary = "north"
+	assert.Equal(t, validate(topology), `The primary "north" is not a member`)
+}
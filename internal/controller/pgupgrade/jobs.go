@@ -26,6 +26,7 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/servicemesh"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
@@ -40,53 +41,97 @@ func pgUpgradeJob(upgrade *v1beta1.PGUpgrade) metav1.ObjectMeta {
 	}
 }
 
+// nssWrapperScript enables nss_wrapper so the current UID and GID resolve to
+// "postgres". It expects a "data_volume" variable for the home directory.
+//
+// Note: Rather than import the nss_wrapper init container, as we do in the
+// main postgres-operator, these jobs do the required nss_wrapper settings here.
+var nssWrapperScript = strings.Join([]string{
+	// Create a copy of the system group definitions, but remove the "postgres"
+	// group or any group with the current GID. Replace them with our own that
+	// has the current GID.
+	`gid=$(id -G); NSS_WRAPPER_GROUP=$(mktemp)`,
+	`(sed "/^postgres:x:/ d; /^[^:]*:x:${gid%% *}:/ d" /etc/group`,
+	`echo "postgres:x:${gid%% *}:") > "${NSS_WRAPPER_GROUP}"`,
+
+	// Create a copy of the system user definitions, but remove the "postgres"
+	// user or any user with the current UID. Replace them with our own that
+	// has the current UID and GID.
+	`uid=$(id -u); NSS_WRAPPER_PASSWD=$(mktemp)`,
+	`(sed "/^postgres:x:/ d; /^[^:]*:x:${uid}:/ d" /etc/passwd`,
+	`echo "postgres:x:${uid}:${gid%% *}::${data_volume}:") > "${NSS_WRAPPER_PASSWD}"`,
+
+	// Enable nss_wrapper so the current UID and GID resolve to "postgres".
+	// - https://cwrap.org/nss_wrapper.html
+	`export LD_PRELOAD='libnss_wrapper.so' NSS_WRAPPER_GROUP NSS_WRAPPER_PASSWD`,
+}, "\n")
+
+// upgradeVersions returns the major versions of PostgreSQL that upgrade goes
+// through, starting with its "from" version and ending with its "to" version.
+func upgradeVersions(upgrade *v1beta1.PGUpgrade) []string {
+	versions := []string{fmt.Sprint(upgrade.Spec.FromPostgresVersion)}
+	for _, version := range upgrade.Spec.IntermediatePostgresVersions {
+		versions = append(versions, fmt.Sprint(version))
+	}
+	return append(versions, fmt.Sprint(upgrade.Spec.ToPostgresVersion))
+}
+
+// verifyIntermediateVersions checks that the intermediate versions of upgrade
+// are in order and between its "from" and "to" versions.
+func verifyIntermediateVersions(upgrade *v1beta1.PGUpgrade) error {
+	previous := upgrade.Spec.FromPostgresVersion
+	for _, version := range upgrade.Spec.IntermediatePostgresVersions {
+		if version <= previous || version >= upgrade.Spec.ToPostgresVersion {
+			return fmt.Errorf(
+				"Intermediate postgres versions must increase from %d to %d",
+				upgrade.Spec.FromPostgresVersion, upgrade.Spec.ToPostgresVersion)
+		}
+		previous = version
+	}
+	return nil
+}
+
 // upgradeCommand returns an entrypoint that prepares the filesystem for
-// and performs a PostgreSQL major version upgrade using pg_upgrade.
+// and performs a PostgreSQL major version upgrade using pg_upgrade. When there
+// are intermediate versions, pg_upgrade runs once for each step and the data
+// directory of each intermediate version is validated before it is upgraded.
 func upgradeCommand(upgrade *v1beta1.PGUpgrade, fetchKeyCommand string) []string {
-	oldVersion := fmt.Sprint(upgrade.Spec.FromPostgresVersion)
-	newVersion := fmt.Sprint(upgrade.Spec.ToPostgresVersion)
-
 	// if the fetch key command is set for TDE, provide the value during initialization
-	initdb := `/usr/pgsql-"${new_version}"/bin/initdb -k -D /pgdata/pg"${new_version}"`
+	initdb := `/usr/pgsql-"${new_version}"/bin/initdb ${checksums} -D /pgdata/pg"${new_version}"`
 	if fetchKeyCommand != "" {
 		initdb += ` --encryption-key-command "` + fetchKeyCommand + `"`
 	}
 
-	args := []string{oldVersion, newVersion}
+	args := upgradeVersions(upgrade)
 	script := strings.Join([]string{
-		`declare -r data_volume='/pgdata' old_version="$1" new_version="$2"`,
-		`printf 'Performing PostgreSQL upgrade from version "%s" to "%s" ...\n\n' "$@"`,
-
-		// Note: Rather than import the nss_wrapper init container, as we do in
-		// the main postgres-operator, this job does the required nss_wrapper
-		// settings here.
-
-		// Create a copy of the system group definitions, but remove the "postgres"
-		// group or any group with the current GID. Replace them with our own that
-		// has the current GID.
-		`gid=$(id -G); NSS_WRAPPER_GROUP=$(mktemp)`,
-		`(sed "/^postgres:x:/ d; /^[^:]*:x:${gid%% *}:/ d" /etc/group`,
-		`echo "postgres:x:${gid%% *}:") > "${NSS_WRAPPER_GROUP}"`,
-
-		// Create a copy of the system user definitions, but remove the "postgres"
-		// user or any user with the current UID. Replace them with our own that
-		// has the current UID and GID.
-		`uid=$(id -u); NSS_WRAPPER_PASSWD=$(mktemp)`,
-		`(sed "/^postgres:x:/ d; /^[^:]*:x:${uid}:/ d" /etc/passwd`,
-		`echo "postgres:x:${uid}:${gid%% *}::${data_volume}:") > "${NSS_WRAPPER_PASSWD}"`,
-
-		// Enable nss_wrapper so the current UID and GID resolve to "postgres".
-		// - https://cwrap.org/nss_wrapper.html
-		`export LD_PRELOAD='libnss_wrapper.so' NSS_WRAPPER_GROUP NSS_WRAPPER_PASSWD`,
+		`declare -r data_volume='/pgdata' first_version="$1" final_version="${@: -1}"`,
+		`declare -a versions=("$@")`,
+		`printf 'Performing PostgreSQL upgrade from version "%s" to "%s" ...\n\n' "${first_version}" "${final_version}"`,
+
+		nssWrapperScript,
 
 		// Below is the pg_upgrade script used to upgrade a PostgresCluster from
 		// one major version to another. Additional information concerning the
 		// steps used and command flag specifics can be found in the documentation:
 		// - https://www.postgresql.org/docs/current/pgupgrade.html
 
+		// pg_upgrade requires that data checksums be enabled in the new
+		// cluster exactly when they are enabled in the old cluster. Every
+		// step also keeps the shared_preload_libraries of the first version.
+		`cd /pgdata || exit`,
+		`checksums='-k'`,
+		`if /usr/pgsql-"${first_version}"/bin/pg_controldata /pgdata/pg"${first_version}" |`,
+		`  grep -q '^Data page checksum version: *0$'; then checksums=''; fi`,
+		`shared_preload_libraries="$(/usr/pgsql-"${first_version}"/bin/postgres -D \`,
+		`/pgdata/pg"${first_version}" -C shared_preload_libraries)"`,
+
+		`while [[ "$#" -gt 1 ]]; do`,
+		`old_version="$1" new_version="$2"`,
+		`shift`,
+		`printf 'Upgrading from version "%s" to "%s" ...\n\n' "${old_version}" "${new_version}"`,
+
 		// To begin, we first move to the mounted /pgdata directory and create a
 		// new version directory which is then initialized with the initdb command.
-		`cd /pgdata || exit`,
 		`echo -e "Step 1: Making new pgdata directory...\n"`,
 		`mkdir /pgdata/pg"${new_version}"`,
 		`echo -e "Step 2: Initializing new pgdata directory...\n"`,
@@ -98,8 +143,7 @@ func upgradeCommand(upgrade *v1beta1.PGUpgrade, fetchKeyCommand string) []string
 		`echo -e "\nStep 3: Setting the expected permissions on the old pgdata directory...\n"`,
 		`chmod 700 /pgdata/pg"${old_version}"`,
 		`echo -e "Step 4: Copying shared_preload_libraries setting to new postgresql.conf file...\n"`,
-		`echo "shared_preload_libraries = '$(/usr/pgsql-"""${old_version}"""/bin/postgres -D \`,
-		`/pgdata/pg"""${old_version}""" -C shared_preload_libraries)'" >> /pgdata/pg"${new_version}"/postgresql.conf`,
+		`echo "shared_preload_libraries = '${shared_preload_libraries}'" >> /pgdata/pg"${new_version}"/postgresql.conf`,
 
 		// Before the actual upgrade is run, we will run the upgrade --check to
 		// verify everything before actually changing any data.
@@ -115,11 +159,37 @@ func upgradeCommand(upgrade *v1beta1.PGUpgrade, fetchKeyCommand string) []string
 		`--new-bindir /usr/pgsql-"${new_version}"/bin --old-datadir /pgdata/pg"${old_version}" \`,
 		`--new-datadir /pgdata/pg"${new_version}" --link`,
 
+		// An intermediate data directory is never started. It must be of its
+		// version and have been shut down cleanly by pg_upgrade before the
+		// next step reads it.
+		`if [[ "$#" -gt 1 ]]; then`,
+		`  echo -e "\nValidating the data directory of PostgreSQL ${new_version}...\n"`,
+		`  if [[ "$(< /pgdata/pg"${new_version}"/PG_VERSION)" != "${new_version}" ]] ||`,
+		`    ! /usr/pgsql-"${new_version}"/bin/pg_controldata /pgdata/pg"${new_version}" |`,
+		`      grep -q '^Database cluster state: *shut down$'; then`,
+		`    echo "The data directory of PostgreSQL ${new_version} is not ready to upgrade."`,
+		`    exit 1`,
+		`  fi`,
+		`fi`,
+		`done`,
+
+		// Record the checkpoint of the new data directory. A rollback compares
+		// this to detect that the upgraded cluster has started.
+		`/usr/pgsql-"${final_version}"/bin/pg_controldata /pgdata/pg"${final_version}" |`,
+		`  sed -n 's/^Latest checkpoint location: *//p' > /pgdata/pg"${final_version}"_checkpoint`,
+
+		// The files of intermediate versions are hard links to those of the
+		// final version, so their directories can go.
+		`shopt -s nullglob`,
+		`for version in "${versions[@]:1:${#versions[@]}-2}"; do`,
+		`  rm -rf /pgdata/pg"${version}" /tablespaces/*/data/PG_"${version}"_*`,
+		`done`,
+
 		// Since we have cleared the Patroni cluster step by removing the EndPoints, we copy patroni.dynamic.json
 		// from the old data dir to help retain PostgreSQL parameters you had set before.
 		// - https://patroni.readthedocs.io/en/latest/existing_data.html#major-upgrade-of-postgresql-version
 		`echo -e "\nStep 7: Copying patroni.dynamic.json...\n"`,
-		`cp /pgdata/pg"${old_version}"/patroni.dynamic.json /pgdata/pg"${new_version}"`,
+		`cp /pgdata/pg"${first_version}"/patroni.dynamic.json /pgdata/pg"${final_version}"`,
 
 		`echo -e "\npg_upgrade Job Complete!"`,
 	}, "\n")
@@ -200,6 +270,293 @@ func (r *PGUpgradeReconciler) generateUpgradeJob(
 	return job
 }
 
+// Preflight job
+
+// pgUpgradePreflightJob returns the ObjectMeta for the Job that checks whether
+// or not the data directory can be upgraded.
+func pgUpgradePreflightJob(upgrade *v1beta1.PGUpgrade) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: upgrade.Namespace,
+		Name:      upgrade.Name + "-preflight",
+	}
+}
+
+// preflightCommand returns an entrypoint that runs pg_upgrade in its "--check"
+// mode against a temporary data directory and reports the size of the old
+// data directory. When there are intermediate versions, only the first step
+// can be checked this way. The report is also written as the container's
+// termination message so that it can be read from the Pod status.
+// - https://docs.k8s.io/tasks/debug/debug-application/determine-reason-pod-failure/
+func preflightCommand(upgrade *v1beta1.PGUpgrade, fetchKeyCommand string) []string {
+	initdb := `/usr/pgsql-"${new_version}"/bin/initdb ${checksums} -D "${check_dir}"`
+	if fetchKeyCommand != "" {
+		initdb += ` --encryption-key-command "` + fetchKeyCommand + `"`
+	}
+
+	args := upgradeVersions(upgrade)
+	script := strings.Join([]string{
+		`declare -r data_volume='/pgdata' old_version="$1" new_version="$2" final_version="${@: -1}"`,
+		nssWrapperScript,
+
+		// Initialize a throwaway data directory next to the old one. It is
+		// removed however the script exits.
+		// The image needs every version that the upgrade goes through.
+		`for version in "$@"; do`,
+		`  if [[ ! -x /usr/pgsql-"${version}"/bin/postgres ]]; then`,
+		`    echo "This image does not have PostgreSQL ${version}." | tee /dev/termination-log`,
+		`    exit 1`,
+		`  fi`,
+		`done`,
+
+		`check_dir=$(mktemp -d /pgdata/pg"${new_version}"_preflight.XXXXXX)`,
+		`report=$(mktemp)`,
+		`trap 'rm -rf "${check_dir}"' EXIT`,
+		`cd "${check_dir}"`,
+
+		`checksums='-k'`,
+		`if /usr/pgsql-"${old_version}"/bin/pg_controldata /pgdata/pg"${old_version}" |`,
+		`  grep -q '^Data page checksum version: *0$'; then checksums=''; fi`,
+
+		`status=0`,
+		`{`,
+		`printf 'Preflight of PostgreSQL upgrade from version %s to %s\n' "${old_version}" "${final_version}"`,
+		`if [[ "$#" -gt 2 ]]; then printf 'Checking the first step, to version %s\n' "${new_version}"; fi`,
+		`printf 'Data directory size: %s\n' "$(du -sh /pgdata/pg"${old_version}" | cut -f1)"`,
+		`printf 'Data directory files: %s\n' "$(find /pgdata/pg"${old_version}" -type f | wc -l)"`,
+		`printf 'Data checksums: %s\n\n' "$([[ -n "${checksums}" ]] && echo enabled || echo disabled)"`,
+		initdb + ` > /dev/null &&`,
+		`echo "shared_preload_libraries = '$(/usr/pgsql-"${old_version}"/bin/postgres -D \`,
+		`/pgdata/pg"${old_version}" -C shared_preload_libraries)'" >> "${check_dir}"/postgresql.conf &&`,
+		`/usr/pgsql-"${new_version}"/bin/pg_upgrade --old-bindir /usr/pgsql-"${old_version}"/bin \`,
+		`--new-bindir /usr/pgsql-"${new_version}"/bin --old-datadir /pgdata/pg"${old_version}" \`,
+		`--new-datadir "${check_dir}" --link --check`,
+		`} > "${report}" 2>&1 || status=$?`,
+
+		// The termination message is limited to 4096 bytes; keep the end.
+		`cat "${report}"`,
+		`tail -c 4000 "${report}" > /dev/termination-log`,
+		`exit "${status}"`,
+	}, "\n")
+
+	return append([]string{"bash", "-ceu", "--", script, "preflight"}, args...)
+}
+
+// generatePreflightJob returns a Job that checks whether or not the PostgreSQL
+// data directory of the startup instance can be upgraded. It changes no data.
+func (r *PGUpgradeReconciler) generatePreflightJob(
+	ctx context.Context, upgrade *v1beta1.PGUpgrade,
+	startup *appsv1.StatefulSet, fetchKeyCommand string,
+) *batchv1.Job {
+	// Start with the upgrade Job so the preflight runs in the same environment.
+	job := r.generateUpgradeJob(ctx, upgrade, startup, fetchKeyCommand)
+	job.Name = pgUpgradePreflightJob(upgrade).Name
+
+	job.Labels = Merge(job.Labels, map[string]string{LabelRole: preflight})
+	job.Spec.Template.Labels = job.Labels
+
+	container := &job.Spec.Template.Spec.Containers[0]
+	container.Command = preflightCommand(upgrade, fetchKeyCommand)
+	container.TerminationMessagePolicy = corev1.TerminationMessageFallbackToLogsOnError
+
+	return job
+}
+
+// pgUpgradePreflightCatalogJob returns the ObjectMeta for the Job that checks
+// the catalogs of the running cluster for things the new version lacks.
+func pgUpgradePreflightCatalogJob(upgrade *v1beta1.PGUpgrade) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: upgrade.Namespace,
+		Name:      upgrade.Name + "-preflight-catalog",
+	}
+}
+
+// preflightCatalogCommand returns an entrypoint that connects to the running
+// cluster and looks in every database for extensions and data types that
+// pg_upgrade cannot carry to the new version. It estimates downtime from the
+// number of relations because pg_upgrade in its "--link" mode dumps and
+// restores the schema but does not copy data files. The report is also written
+// as the container's termination message.
+func preflightCatalogCommand(upgrade *v1beta1.PGUpgrade) []string {
+	args := upgradeVersions(upgrade)
+	script := strings.Join([]string{
+		`declare -r old_version="$1" new_version="${@: -1}"`,
+		`declare -ra later_versions=("${@:2}")`,
+		`export PATH="/usr/pgsql-${new_version}/bin:${PATH}"`,
+
+		`for version in "${later_versions[@]}"; do`,
+		`  if [[ ! -d /usr/pgsql-"${version}"/share/extension ]]; then`,
+		`    echo "This image does not have PostgreSQL ${version}." | tee /dev/termination-log`,
+		`    exit 1`,
+		`  fi`,
+		`done`,
+
+		`report=$(mktemp) status=0 relations=0`,
+		`{`,
+		`printf 'Catalog checks of PostgreSQL upgrade from version %s to %s\n' "${old_version}" "${new_version}"`,
+		`printf 'Total size of databases: %s\n\n' "$(psql --no-psqlrc --quiet --tuples-only --no-align --command=\`,
+		`  'SELECT pg_catalog.pg_size_pretty(sum(pg_catalog.pg_database_size(oid))) FROM pg_catalog.pg_database')"`,
+
+		`databases=$(psql --no-psqlrc --quiet --tuples-only --no-align --command=\`,
+		`  'SELECT datname FROM pg_catalog.pg_database WHERE datallowconn ORDER BY datname')`,
+		`while IFS= read -r database; do`,
+		`  export PGDATABASE="${database}"`,
+		`  count=$(psql --no-psqlrc --quiet --tuples-only --no-align --command='SELECT count(*) FROM pg_catalog.pg_class')`,
+		`  relations=$(( relations + count ))`,
+
+		// Every extension needs a control file in each later installation.
+		`  while IFS= read -r extension; do`,
+		`    for version in "${later_versions[@]}"; do`,
+		`      if [[ -n "${extension}" && ! -f /usr/pgsql-"${version}"/share/extension/"${extension}".control ]]; then`,
+		`        printf 'Database %s: extension %s is not available in PostgreSQL %s\n' "${database}" "${extension}" "${version}"`,
+		`        status=1`,
+		`      fi`,
+		`    done`,
+		`  done < <(psql --no-psqlrc --quiet --tuples-only --no-align --command='SELECT extname FROM pg_catalog.pg_extension')`,
+
+		`  problems=$(psql --no-psqlrc --quiet --tuples-only --no-align --set=ON_ERROR_STOP=1 \`,
+		`    --set=old_version="${old_version}" --set=new_version="${new_version}" <<'SQL'`,
+
+		// pg_upgrade cannot keep the OIDs stored in most reg* types, nor
+		// values of types that were removed or changed their format.
+		// - https://www.postgresql.org/docs/current/pgupgrade.html
+		`SELECT pg_catalog.format('column %s.%I has type %s', c.oid::regclass, a.attname, t.typname)`,
+		`  FROM pg_catalog.pg_attribute a`,
+		`  JOIN pg_catalog.pg_class c ON c.oid = a.attrelid`,
+		`  JOIN pg_catalog.pg_type t ON t.oid = a.atttypid`,
+		` WHERE c.relkind IN ('r', 'm', 'p') AND a.attnum > 0 AND NOT a.attisdropped`,
+		`   AND c.relnamespace NOT IN ('pg_catalog'::regnamespace, 'information_schema'::regnamespace)`,
+		`   AND t.typnamespace = 'pg_catalog'::regnamespace`,
+		`   AND (t.typname IN ('regcollation', 'regconfig', 'regdictionary', 'regnamespace',`,
+		`                      'regoper', 'regoperator', 'regproc', 'regprocedure')`,
+		`    OR (:old_version < 12 AND :new_version >= 12 AND t.typname IN ('abstime', 'reltime', 'tinterval'))`,
+		`    OR (:old_version < 16 AND :new_version >= 16 AND t.typname = 'aclitem'));`,
+
+		`SELECT :old_version < 12 AND :new_version >= 12 AS check_oids,`,
+		`       :old_version < 14 AND :new_version >= 14 AS check_postfix \gset`,
+		`\if :check_oids`,
+		`SELECT pg_catalog.format('table %s was created WITH OIDS', oid::regclass)`,
+		`  FROM pg_catalog.pg_class WHERE relhasoids AND oid >= 16384;`,
+		`\endif`,
+		`\if :check_postfix`,
+		`SELECT pg_catalog.format('operator %s is a postfix operator', oid::regoperator)`,
+		`  FROM pg_catalog.pg_operator WHERE oprkind = 'r' AND oid >= 16384;`,
+		`\endif`,
+		`SQL`,
+		`  )`,
+		`  if [[ -n "${problems}" ]]; then`,
+		`    while IFS= read -r problem; do`,
+		`      printf 'Database %s: %s\n' "${database}" "${problem}"`,
+		`    done <<< "${problems}"`,
+		`    status=1`,
+		`  fi`,
+		`done <<< "${databases}"`,
+
+		// Allow a minute to start and stop, then about 50 relations per second
+		// to dump and restore the schema.
+		`printf '\nRelations: %s\n' "${relations}"`,
+		`printf 'Estimated downtime: %s minutes, not counting the time to rebuild statistics\n' \`,
+		`  "$(( (60 + relations / 50 + 59) / 60 ))"`,
+		`} > "${report}" 2>&1 || status=$?`,
+
+		// The termination message is limited to 4096 bytes; keep the end.
+		`cat "${report}"`,
+		`tail -c 4000 "${report}" > /dev/termination-log`,
+		`exit "${status}"`,
+	}, "\n")
+
+	return append([]string{"bash", "-ceu", "--", script, "preflight"}, args...)
+}
+
+// generatePreflightCatalogJob returns a Job that runs the catalog checks as the
+// upgrade user against the primary of the running cluster. It changes no data.
+func (r *PGUpgradeReconciler) generatePreflightCatalogJob(
+	upgrade *v1beta1.PGUpgrade, cluster *v1beta1.PostgresCluster,
+) *batchv1.Job {
+	job := r.generatePostUpgradeJob(upgrade, cluster,
+		pgUpgradePreflightCatalogJob(upgrade), preflightCatalog,
+		preflightCatalogCommand(upgrade))
+
+	// Problems are reported once; delete the Job to check again.
+	job.Spec.BackoffLimit = initialize.Int32(0)
+
+	return job
+}
+
+// Rollback job
+
+// pgUpgradeRollbackJob returns the ObjectMeta for the Job that returns the
+// data directory to the version it had before the upgrade.
+func pgUpgradeRollbackJob(upgrade *v1beta1.PGUpgrade) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: upgrade.Namespace,
+		Name:      upgrade.Name + "-rollback",
+	}
+}
+
+// rollbackCommand returns an entrypoint that undoes pg_upgrade in its "--link"
+// mode, including any intermediate steps. It refuses when the upgraded cluster
+// has started because the old and new data directories share files.
+// - https://www.postgresql.org/docs/current/pgupgrade.html#PGUPGRADE-STEP-REVERT
+func rollbackCommand(upgrade *v1beta1.PGUpgrade) []string {
+	versions := upgradeVersions(upgrade)
+
+	// Pass the first and last versions followed by any in between.
+	args := append([]string{versions[0], versions[len(versions)-1]}, versions[1:len(versions)-1]...)
+	script := strings.Join([]string{
+		`declare -r old_version="$1" new_version="$2"`,
+		`declare -ra intermediate_versions=("${@:3}")`,
+		`printf 'Rolling back PostgreSQL upgrade from version %s to %s...\n\n' "${old_version}" "${new_version}"`,
+		`cd /pgdata || exit`,
+
+		`if [[ -d pg"${new_version}" && -f pg"${new_version}"_checkpoint ]]; then`,
+		`  checkpoint=$(/usr/pgsql-"${new_version}"/bin/pg_controldata pg"${new_version}" |`,
+		`    sed -n 's/^Latest checkpoint location: *//p')`,
+		`  if [[ "${checkpoint}" != "$(< pg"${new_version}"_checkpoint)" ]]; then`,
+		`    echo "PostgreSQL ${new_version} has started; its changes would be lost." |`,
+		`      tee /dev/termination-log`,
+		`    exit 1`,
+		`  fi`,
+		`fi`,
+
+		// pg_upgrade renames the control file of the old data directory so that
+		// it cannot start while its files are linked into the new one.
+		`echo -e "Step 1: Restoring the control file of PostgreSQL ${old_version}...\n"`,
+		`if [[ -f pg"${old_version}"/global/pg_control.old ]]; then`,
+		`  mv pg"${old_version}"/global/pg_control.old pg"${old_version}"/global/pg_control`,
+		`fi`,
+
+		`echo -e "Step 2: Removing the data of PostgreSQL ${new_version}...\n"`,
+		`shopt -s nullglob`,
+		`rm -rf pg"${new_version}" pg"${new_version}"_checkpoint /tablespaces/*/data/PG_"${new_version}"_*`,
+		`for version in "${intermediate_versions[@]}"; do`,
+		`  rm -rf pg"${version}" /tablespaces/*/data/PG_"${version}"_*`,
+		`done`,
+
+		`echo -e "Rollback Job Complete!"`,
+	}, "\n")
+
+	return append([]string{"bash", "-ceu", "--", script, "rollback"}, args...)
+}
+
+// generateRollbackJob returns a Job that returns the PostgreSQL data directory
+// of the startup instance to the version it had before the upgrade.
+func (r *PGUpgradeReconciler) generateRollbackJob(
+	ctx context.Context, upgrade *v1beta1.PGUpgrade, startup *appsv1.StatefulSet,
+) *batchv1.Job {
+	// Start with the upgrade Job so the rollback runs in the same environment.
+	job := r.generateUpgradeJob(ctx, upgrade, startup, "")
+	job.Name = pgUpgradeRollbackJob(upgrade).Name
+
+	job.Labels = Merge(job.Labels, map[string]string{LabelRole: rollback})
+	job.Spec.Template.Labels = job.Labels
+
+	container := &job.Spec.Template.Spec.Containers[0]
+	container.Command = rollbackCommand(upgrade)
+	container.TerminationMessagePolicy = corev1.TerminationMessageFallbackToLogsOnError
+
+	return job
+}
+
 // Remove data job
 
 // removeDataCommand returns an entrypoint that removes certain directories.
@@ -330,6 +687,30 @@ func verifyUpgradeImageValue(upgrade *v1beta1.PGUpgrade) error {
 	return nil
 }
 
+// withNoProxy keeps any service mesh of cluster from injecting a proxy into
+// the Pod of job, which works only on files. A proxy would keep running after
+// the Job finishes so that the Job never completes.
+func withNoProxy(cluster *v1beta1.PostgresCluster, job *batchv1.Job) *batchv1.Job {
+	job.Spec.Template.Annotations = Merge(job.Spec.Template.Annotations,
+		servicemesh.NoProxyAnnotations(cluster))
+	return job
+}
+
+// withJobLimits sets the deadline of job to that in the spec of cluster. The
+// retries of job change only when it is already retried; Jobs that must run at
+// most once, such as pg_upgrade, are not retried.
+func withJobLimits(cluster *v1beta1.PostgresCluster, job *batchv1.Job) *batchv1.Job {
+	if limits := cluster.Spec.Jobs; limits != nil {
+		job.Spec.ActiveDeadlineSeconds = limits.ActiveDeadlineSeconds
+
+		if limits.BackoffLimit != nil &&
+			job.Spec.BackoffLimit != nil && *job.Spec.BackoffLimit > 0 {
+			job.Spec.BackoffLimit = limits.BackoffLimit
+		}
+	}
+	return job
+}
+
 // jobFailed returns "true" if the Job provided has failed.  Otherwise it returns "false".
 func jobFailed(job *batchv1.Job) bool {
 	conditions := job.Status.Conditions
@@ -23,6 +23,7 @@ import (
 	"gotest.tools/v3/assert"
 	"gotest.tools/v3/assert/cmp"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"sigs.k8s.io/yaml"
@@ -113,8 +114,9 @@ spec:
         - -ceu
         - --
         - |-
-          declare -r data_volume='/pgdata' old_version="$1" new_version="$2"
-          printf 'Performing PostgreSQL upgrade from version "%s" to "%s" ...\n\n' "$@"
+          declare -r data_volume='/pgdata' first_version="$1" final_version="${@: -1}"
+          declare -a versions=("$@")
+          printf 'Performing PostgreSQL upgrade from version "%s" to "%s" ...\n\n' "${first_version}" "${final_version}"
           gid=$(id -G); NSS_WRAPPER_GROUP=$(mktemp)
           (sed "/^postgres:x:/ d; /^[^:]*:x:${gid%% *}:/ d" /etc/group
           echo "postgres:x:${gid%% *}:") > "${NSS_WRAPPER_GROUP}"
@@ -123,15 +125,23 @@ spec:
           echo "postgres:x:${uid}:${gid%% *}::${data_volume}:") > "${NSS_WRAPPER_PASSWD}"
           export LD_PRELOAD='libnss_wrapper.so' NSS_WRAPPER_GROUP NSS_WRAPPER_PASSWD
           cd /pgdata || exit
+          checksums='-k'
+          if /usr/pgsql-"${first_version}"/bin/pg_controldata /pgdata/pg"${first_version}" |
+            grep -q '^Data page checksum version: *0$'; then checksums=''; fi
+          shared_preload_libraries="$(/usr/pgsql-"${first_version}"/bin/postgres -D \
+          /pgdata/pg"${first_version}" -C shared_preload_libraries)"
+          while [[ "$#" -gt 1 ]]; do
+          old_version="$1" new_version="$2"
+          shift
+          printf 'Upgrading from version "%s" to "%s" ...\n\n' "${old_version}" "${new_version}"
           echo -e "Step 1: Making new pgdata directory...\n"
           mkdir /pgdata/pg"${new_version}"
           echo -e "Step 2: Initializing new pgdata directory...\n"
-          /usr/pgsql-"${new_version}"/bin/initdb -k -D /pgdata/pg"${new_version}"
+          /usr/pgsql-"${new_version}"/bin/initdb ${checksums} -D /pgdata/pg"${new_version}"
           echo -e "\nStep 3: Setting the expected permissions on the old pgdata directory...\n"
           chmod 700 /pgdata/pg"${old_version}"
           echo -e "Step 4: Copying shared_preload_libraries setting to new postgresql.conf file...\n"
-          echo "shared_preload_libraries = '$(/usr/pgsql-"""${old_version}"""/bin/postgres -D \
-          /pgdata/pg"""${old_version}""" -C shared_preload_libraries)'" >> /pgdata/pg"${new_version}"/postgresql.conf
+          echo "shared_preload_libraries = '${shared_preload_libraries}'" >> /pgdata/pg"${new_version}"/postgresql.conf
           echo -e "Step 5: Running pg_upgrade check...\n"
           time /usr/pgsql-"${new_version}"/bin/pg_upgrade --old-bindir /usr/pgsql-"${old_version}"/bin \
           --new-bindir /usr/pgsql-"${new_version}"/bin --old-datadir /pgdata/pg"${old_version}"\
@@ -140,8 +150,24 @@ spec:
           time /usr/pgsql-"${new_version}"/bin/pg_upgrade --old-bindir /usr/pgsql-"${old_version}"/bin \
           --new-bindir /usr/pgsql-"${new_version}"/bin --old-datadir /pgdata/pg"${old_version}" \
           --new-datadir /pgdata/pg"${new_version}" --link
+          if [[ "$#" -gt 1 ]]; then
+            echo -e "\nValidating the data directory of PostgreSQL ${new_version}...\n"
+            if [[ "$(< /pgdata/pg"${new_version}"/PG_VERSION)" != "${new_version}" ]] ||
+              ! /usr/pgsql-"${new_version}"/bin/pg_controldata /pgdata/pg"${new_version}" |
+                grep -q '^Database cluster state: *shut down$'; then
+              echo "The data directory of PostgreSQL ${new_version} is not ready to upgrade."
+              exit 1
+            fi
+          fi
+          done
+          /usr/pgsql-"${final_version}"/bin/pg_controldata /pgdata/pg"${final_version}" |
+            sed -n 's/^Latest checkpoint location: *//p' > /pgdata/pg"${final_version}"_checkpoint
+          shopt -s nullglob
+          for version in "${versions[@]:1:${#versions[@]}-2}"; do
+            rm -rf /pgdata/pg"${version}" /tablespaces/*/data/PG_"${version}"_*
+          done
           echo -e "\nStep 7: Copying patroni.dynamic.json...\n"
-          cp /pgdata/pg"${old_version}"/patroni.dynamic.json /pgdata/pg"${new_version}"
+          cp /pgdata/pg"${first_version}"/patroni.dynamic.json /pgdata/pg"${final_version}"
           echo -e "\npg_upgrade Job Complete!"
         - upgrade
         - "19"
@@ -164,10 +190,133 @@ spec:
 status: {}
 	`))
 
+	upgrade.Spec.IntermediatePostgresVersions = []int{21, 23}
+	chained := reconciler.generateUpgradeJob(ctx, upgrade, startup, "")
+	assert.DeepEqual(t, chained.Spec.Template.Spec.Containers[0].Command[4:],
+		[]string{"upgrade", "19", "21", "23", "25"})
+	upgrade.Spec.IntermediatePostgresVersions = nil
+
 	tdeJob := reconciler.generateUpgradeJob(ctx, upgrade, startup, "echo testKey")
 	b, _ := yaml.Marshal(tdeJob)
 	assert.Assert(t, strings.Contains(string(b),
-		`/usr/pgsql-"${new_version}"/bin/initdb -k -D /pgdata/pg"${new_version}" --encryption-key-command "echo testKey"`))
+		`/usr/pgsql-"${new_version}"/bin/initdb ${checksums} -D /pgdata/pg"${new_version}" --encryption-key-command "echo testKey"`))
+}
+
+func TestGeneratePreflightJob(t *testing.T) {
+	ctx := context.Background()
+	reconciler := &PGUpgradeReconciler{}
+
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Namespace = "ns1"
+	upgrade.Name = "pgu2"
+	upgrade.Spec.Image = initialize.Pointer("img4")
+	upgrade.Spec.PostgresClusterName = "pg5"
+	upgrade.Spec.FromPostgresVersion = 19
+	upgrade.Spec.ToPostgresVersion = 25
+
+	startup := &appsv1.StatefulSet{}
+	startup.Spec.Template.Spec = corev1.PodSpec{
+		Containers: []corev1.Container{{Name: ContainerDatabase}},
+	}
+
+	job := reconciler.generatePreflightJob(ctx, upgrade, startup, "")
+	assert.Equal(t, job.Name, "pgu2-preflight")
+	assert.Equal(t, job.Labels[LabelRole], "preflight")
+	assert.DeepEqual(t, job.Spec.Template.Labels, job.Labels)
+
+	container := job.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, container.TerminationMessagePolicy,
+		corev1.TerminationMessageFallbackToLogsOnError)
+	assert.DeepEqual(t, container.Command[len(container.Command)-3:],
+		[]string{"preflight", "19", "25"})
+
+	script := container.Command[3]
+	assert.Assert(t, strings.Contains(script, `--new-datadir "${check_dir}" --link --check`))
+	assert.Assert(t, strings.Contains(script, `> /dev/termination-log`))
+	assert.Assert(t, strings.Contains(script, `for version in "$@"; do`),
+		"expected every version to be checked")
+
+	upgrade.Spec.IntermediatePostgresVersions = []int{21, 23}
+	job = reconciler.generatePreflightJob(ctx, upgrade, startup, "")
+	assert.DeepEqual(t, job.Spec.Template.Spec.Containers[0].Command[4:],
+		[]string{"preflight", "19", "21", "23", "25"})
+	upgrade.Spec.IntermediatePostgresVersions = nil
+
+	tdeJob := reconciler.generatePreflightJob(ctx, upgrade, startup, "echo testKey")
+	assert.Assert(t, strings.Contains(tdeJob.Spec.Template.Spec.Containers[0].Command[3],
+		`initdb ${checksums} -D "${check_dir}" --encryption-key-command "echo testKey"`))
+}
+
+func TestGeneratePreflightCatalogJob(t *testing.T) {
+	reconciler := &PGUpgradeReconciler{}
+
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Namespace = "ns1"
+	upgrade.Name = "pgu2"
+	upgrade.Spec.Image = initialize.Pointer("img4")
+	upgrade.Spec.PostgresClusterName = "pg5"
+	upgrade.Spec.FromPostgresVersion = 11
+	upgrade.Spec.ToPostgresVersion = 16
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace = "ns1"
+	cluster.Name = "pg5"
+	cluster.Spec.Port = initialize.Int32(5432)
+
+	job := reconciler.generatePreflightCatalogJob(upgrade, cluster)
+	assert.Equal(t, job.Name, "pgu2-preflight-catalog")
+	assert.Equal(t, job.Labels[LabelRole], "preflight-catalog")
+	assert.Equal(t, *job.Spec.BackoffLimit, int32(0))
+
+	container := job.Spec.Template.Spec.Containers[0]
+	assert.Equal(t, container.Image, "img4")
+	assert.DeepEqual(t, container.Command[len(container.Command)-3:],
+		[]string{"preflight", "11", "16"})
+
+	script := container.Command[3]
+	assert.Assert(t, strings.Contains(script,
+		`/usr/pgsql-"${version}"/share/extension/"${extension}".control`),
+		"expected extensions to be checked")
+	assert.Assert(t, strings.Contains(script, `'regoper', 'regoperator', 'regproc', 'regprocedure'`))
+	assert.Assert(t, strings.Contains(script, `Estimated downtime:`))
+	assert.Assert(t, strings.Contains(script, `> /dev/termination-log`))
+}
+
+func TestGenerateRollbackJob(t *testing.T) {
+	ctx := context.Background()
+	reconciler := &PGUpgradeReconciler{}
+
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Namespace = "ns1"
+	upgrade.Name = "pgu2"
+	upgrade.Spec.Image = initialize.Pointer("img4")
+	upgrade.Spec.PostgresClusterName = "pg5"
+	upgrade.Spec.FromPostgresVersion = 19
+	upgrade.Spec.ToPostgresVersion = 25
+
+	startup := &appsv1.StatefulSet{}
+	startup.Spec.Template.Spec = corev1.PodSpec{
+		Containers: []corev1.Container{{Name: ContainerDatabase}},
+	}
+
+	job := reconciler.generateRollbackJob(ctx, upgrade, startup)
+	assert.Equal(t, job.Name, "pgu2-rollback")
+	assert.Equal(t, job.Labels[LabelRole], "rollback")
+	assert.Equal(t, *job.Spec.BackoffLimit, int32(0))
+
+	container := job.Spec.Template.Spec.Containers[0]
+	assert.DeepEqual(t, container.Command[len(container.Command)-3:],
+		[]string{"rollback", "19", "25"})
+
+	script := container.Command[3]
+	assert.Assert(t, strings.Contains(script, `mv pg"${old_version}"/global/pg_control.old`))
+	assert.Assert(t, strings.Contains(script, `rm -rf pg"${new_version}" `))
+	assert.Assert(t, strings.Contains(script, `pg"${new_version}"_checkpoint`))
+
+	upgrade.Spec.IntermediatePostgresVersions = []int{21, 23}
+	job = reconciler.generateRollbackJob(ctx, upgrade, startup)
+	assert.DeepEqual(t, job.Spec.Template.Spec.Containers[0].Command[4:],
+		[]string{"rollback", "19", "25", "21", "23"})
 }
 
 func TestGenerateRemoveDataJob(t *testing.T) {
@@ -297,6 +446,44 @@ func unsetEnv(t testing.TB, key string) {
 	assert.NilError(t, os.Unsetenv(key))
 }
 
+func TestWithNoProxy(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	job := &batchv1.Job{}
+	job.Spec.Template.Annotations = map[string]string{"a": "b"}
+
+	assert.DeepEqual(t, withNoProxy(cluster, job).Spec.Template.Annotations,
+		map[string]string{"a": "b"})
+
+	cluster.Spec.ServiceMesh = &v1beta1.ServiceMeshSpec{Type: "Istio"}
+	assert.DeepEqual(t, withNoProxy(cluster, job).Spec.Template.Annotations,
+		map[string]string{"a": "b", "sidecar.istio.io/inject": "false"})
+}
+
+func TestWithJobLimits(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	once := &batchv1.Job{}
+	once.Spec.BackoffLimit = initialize.Int32(0)
+	retried := &batchv1.Job{}
+	retried.Spec.BackoffLimit = initialize.Int32(6)
+
+	withJobLimits(cluster, once)
+	assert.Assert(t, once.Spec.ActiveDeadlineSeconds == nil)
+
+	cluster.Spec.Jobs = &v1beta1.JobsSpec{
+		ActiveDeadlineSeconds: initialize.Int64(600),
+		BackoffLimit:          initialize.Int32(2),
+	}
+
+	// Jobs that run at most once are not retried.
+	withJobLimits(cluster, once)
+	assert.Equal(t, *once.Spec.ActiveDeadlineSeconds, int64(600))
+	assert.Equal(t, *once.Spec.BackoffLimit, int32(0))
+
+	withJobLimits(cluster, retried)
+	assert.Equal(t, *retried.Spec.ActiveDeadlineSeconds, int64(600))
+	assert.Equal(t, *retried.Spec.BackoffLimit, int32(2))
+}
+
 func TestPGUpgradeContainerImage(t *testing.T) {
 	upgrade := &v1beta1.PGUpgrade{}
 
@@ -314,6 +501,22 @@ func TestPGUpgradeContainerImage(t *testing.T) {
 	assert.Equal(t, pgUpgradeContainerImage(upgrade), "spec-image")
 }
 
+func TestVerifyIntermediateVersions(t *testing.T) {
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Spec.FromPostgresVersion = 13
+	upgrade.Spec.ToPostgresVersion = 17
+	assert.NilError(t, verifyIntermediateVersions(upgrade))
+
+	upgrade.Spec.IntermediatePostgresVersions = []int{15}
+	assert.NilError(t, verifyIntermediateVersions(upgrade))
+
+	for _, versions := range [][]int{{13}, {17}, {16, 15}, {12}, {15, 15}} {
+		upgrade.Spec.IntermediatePostgresVersions = versions
+		assert.ErrorContains(t, verifyIntermediateVersions(upgrade),
+			"must increase from 13 to 17", "versions: %v", versions)
+	}
+}
+
 func TestVerifyUpgradeImageValue(t *testing.T) {
 	upgrade := &v1beta1.PGUpgrade{}
 
@@ -27,6 +27,10 @@ const (
 	// status of a Postgres major upgrade.
 	ConditionPGUpgradeSucceeded = "Succeeded"
 
+	// ConditionPGUpgradePostUpgradeCompleted is the type used in a condition to
+	// indicate the status of the work done after a Postgres major upgrade.
+	ConditionPGUpgradePostUpgradeCompleted = "PostUpgradeCompleted"
+
 	labelPrefix           = "postgres-operator.crunchydata.com/"
 	LabelPGUpgrade        = labelPrefix + "pgupgrade"
 	LabelCluster          = labelPrefix + "cluster"
@@ -39,8 +43,17 @@ const (
 	ReplicaCreate     = "replica-create"
 	ContainerDatabase = "database"
 
-	pgUpgrade  = "pgupgrade"
-	removeData = "removedata"
+	pgUpgrade        = "pgupgrade"
+	preflight        = "preflight"
+	preflightCatalog = "preflight-catalog"
+	removeData       = "removedata"
+	rollback         = "rollback"
+	postUpgrade      = "postupgrade"
+
+	logicalSetup   = "logical-setup"
+	logicalLag     = "logical-lag"
+	logicalCutover = "logical-cutover"
+	logicalResume  = "logical-resume"
 )
 
 func commonLabels(role string, upgrade *v1beta1.PGUpgrade) map[string]string {
@@ -0,0 +1,813 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgupgrade
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/pgbouncer"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/postupgrade"
+	"github.com/crunchydata/postgres-operator/internal/servicemesh"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	methodLogicalReplication = "LogicalReplication"
+
+	// The phases of an upgrade through logical replication.
+	phaseProvisioning = "Provisioning"
+	phaseReplicating  = "Replicating"
+	phaseCuttingOver  = "CuttingOver"
+	phaseCompleted    = "Completed"
+
+	// How often to measure replication lag.
+	logicalLagInterval = time.Minute
+)
+
+// logicalTargetClusterName returns the name of the new PostgresCluster that
+// upgrade replicates into.
+func logicalTargetClusterName(upgrade *v1beta1.PGUpgrade) string {
+	if spec := upgrade.Spec.LogicalReplication; spec != nil && spec.TargetClusterName != "" {
+		return spec.TargetClusterName
+	}
+	return fmt.Sprintf("%s-pg%d",
+		upgrade.Spec.PostgresClusterName, upgrade.Spec.ToPostgresVersion)
+}
+
+// pgUpgradeLogicalJob returns the ObjectMeta for the Job of role in an
+// upgrade through logical replication.
+func pgUpgradeLogicalJob(upgrade *v1beta1.PGUpgrade, role string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: upgrade.Namespace,
+		Name:      upgrade.Name + "-" + role,
+	}
+}
+
+// generateTargetCluster returns a PostgresCluster like source that runs the
+// new major version of upgrade. It starts empty and has no backup repositories
+// in cloud storage because those would be shared with source.
+func generateTargetCluster(
+	upgrade *v1beta1.PGUpgrade, source *v1beta1.PostgresCluster,
+) *v1beta1.PostgresCluster {
+	target := v1beta1.NewPostgresCluster()
+	target.Namespace = source.Namespace
+	target.Name = logicalTargetClusterName(upgrade)
+	target.Annotations = map[string]string{AnnotationAllowUpgrade: upgrade.Name}
+	target.Labels = Merge(upgrade.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{LabelPGUpgrade: upgrade.Name})
+
+	source.Spec.DeepCopyInto(&target.Spec)
+	target.Spec.PostgresVersion = upgrade.Spec.ToPostgresVersion
+	target.Spec.Image = upgrade.Spec.ToPostgresImage
+	target.Spec.DataSource = nil
+	target.Spec.Standby = nil
+	target.Spec.Shutdown = nil
+
+	var repos []v1beta1.PGBackRestRepo
+	for _, repo := range target.Spec.Backups.PGBackRest.Repos {
+		if repo.Volume != nil {
+			repos = append(repos, repo)
+		}
+	}
+	if len(repos) == 0 && len(target.Spec.InstanceSets) > 0 {
+		repos = append(repos, v1beta1.PGBackRestRepo{
+			Name: "repo1",
+			Volume: &v1beta1.RepoPVC{
+				VolumeClaimSpec: target.Spec.InstanceSets[0].DataVolumeClaimSpec,
+			},
+		})
+	}
+	target.Spec.Backups.PGBackRest.Repos = repos
+	target.Spec.Backups.PGBackRest.Restore = nil
+
+	return target
+}
+
+// logicalScript returns lines of bash that set up the environment shared by
+// every logical replication command.
+func logicalScript(lines ...string) string {
+	return strings.Join(append([]string{
+		`set -o pipefail`,
+		`declare -r new_version="$1"`,
+		`export PATH="/usr/pgsql-${new_version}/bin:${PATH}" PSQLRC=/dev/null`,
+	}, lines...), "\n")
+}
+
+// logicalSetupCommand returns an entrypoint that copies roles and schema from
+// the old cluster to the new one and subscribes every database of the new
+// cluster to its counterpart. It can run more than once.
+// - https://www.postgresql.org/docs/current/logical-replication.html
+func logicalSetupCommand(upgrade *v1beta1.PGUpgrade) []string {
+	args := []string{fmt.Sprint(upgrade.Spec.ToPostgresVersion)}
+	script := logicalScript(
+		`echo 'Copying roles...'`,
+		`pg_dumpall --dbname="${SOURCE}" --roles-only |`,
+		`  grep --invert-match --extended-regexp '^(CREATE|ALTER) ROLE (postgres|_crunchy[a-z]*)( |;)' |`,
+		`  psql "${TARGET} dbname=postgres" --quiet > /dev/null`,
+		``,
+		`password="$(LC_ALL=C tr -dc 'A-Za-z0-9' < /dev/urandom | head -c 32 || true)"`,
+		`psql "${SOURCE} dbname=postgres" --quiet --set=ON_ERROR_STOP=1 --set=password="${password}" <<'SQL'`,
+		`SELECT 'CREATE ROLE _crunchyreplicate'`,
+		` WHERE NOT EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = '_crunchyreplicate')`,
+		`\gexec`,
+		`ALTER ROLE _crunchyreplicate LOGIN REPLICATION PASSWORD :'password';`,
+		`SQL`,
+		``,
+		`psql "${SOURCE} dbname=postgres" --quiet --tuples-only --no-align --field-separator=' ' \`,
+		`  --command='SELECT oid, datname FROM pg_catalog.pg_database WHERE datallowconn AND NOT datistemplate ORDER BY datname' |`,
+		`while read -r oid database; do`,
+		`  echo "Replicating ${database}..."`,
+		`  export PGDATABASE="${database}"`,
+		`  psql "${TARGET} dbname=postgres" --quiet --set=ON_ERROR_STOP=1 --set=database="${database}" <<'SQL'`,
+		`SELECT pg_catalog.format('CREATE DATABASE %I', :'database')`,
+		` WHERE NOT EXISTS (SELECT 1 FROM pg_catalog.pg_database WHERE datname = :'database')`,
+		`\gexec`,
+		`SQL`,
+		``,
+		`  subscribed="$(psql "${TARGET}" --quiet --tuples-only --no-align \`,
+		`    --command="SELECT 1 FROM pg_catalog.pg_subscription WHERE subname = 'pgo_upgrade' AND subdbid = (SELECT oid FROM pg_catalog.pg_database WHERE datname = current_database())")"`,
+		`  if [[ -z "${subscribed}" ]]; then`,
+		`    pg_dump --dbname="${SOURCE}" --schema-only --exclude-schema=pgbouncer |`,
+		`      psql "${TARGET}" --quiet --set=ON_ERROR_STOP=1 > /dev/null`,
+		`  fi`,
+		``,
+		`  psql "${SOURCE}" --quiet --set=ON_ERROR_STOP=1 <<'SQL'`,
+		`SELECT pg_catalog.format('GRANT USAGE ON SCHEMA %I TO _crunchyreplicate', nspname),`,
+		`       pg_catalog.format('GRANT SELECT ON ALL TABLES IN SCHEMA %I TO _crunchyreplicate', nspname)`,
+		`  FROM pg_catalog.pg_namespace`,
+		` WHERE nspname NOT LIKE 'pg\_%' AND nspname <> 'information_schema'`,
+		`\gexec`,
+		`SELECT 'CREATE PUBLICATION pgo_upgrade FOR ALL TABLES'`,
+		` WHERE NOT EXISTS (SELECT 1 FROM pg_catalog.pg_publication WHERE pubname = 'pgo_upgrade')`,
+		`\gexec`,
+		`SQL`,
+		``,
+		`  # The new cluster is created from the specification of the old one, so`,
+		`  # its PostgreSQL trusts the same certificate authority.`,
+		`  psql "${TARGET}" --quiet --set=ON_ERROR_STOP=1 \`,
+		`    --set=host="${SOURCE_HOST}" --set=port="${SOURCE_PORT}" --set=password="${password}" \`,
+		`    --set=rootcert='/pgconf/tls/ca.crt' \`,
+		`    --set=database="${database}" --set=slot="pgo_upgrade_${oid}" <<'SQL'`,
+		`SELECT pg_catalog.format(`,
+		`  'host=%s port=%s user=_crunchyreplicate password=%s sslmode=verify-full sslrootcert=%s dbname=''%s''',`,
+		`  :'host', :'port', :'password', :'rootcert',`,
+		`  pg_catalog.replace(pg_catalog.replace(:'database', E'\\', E'\\\\'), '''', E'\\''')) AS connection`,
+		`\gset`,
+		`SELECT pg_catalog.format('CREATE SUBSCRIPTION pgo_upgrade CONNECTION %L PUBLICATION pgo_upgrade WITH (slot_name = %L)', :'connection', :'slot')`,
+		` WHERE NOT EXISTS (SELECT 1 FROM pg_catalog.pg_subscription WHERE subname = 'pgo_upgrade' AND subdbid = (SELECT oid FROM pg_catalog.pg_database WHERE datname = current_database()))`,
+		`\gexec`,
+		`SELECT pg_catalog.format('ALTER SUBSCRIPTION pgo_upgrade CONNECTION %L', :'connection')`,
+		`\gexec`,
+		`SQL`,
+		`done`,
+	)
+
+	return append([]string{"bash", "-ceu", "--", script, "logical-setup"}, args...)
+}
+
+// logicalLagCommand returns an entrypoint that writes the replication lag in
+// bytes and the number of tables still being copied to the container's
+// termination message.
+func logicalLagCommand(upgrade *v1beta1.PGUpgrade) []string {
+	args := []string{fmt.Sprint(upgrade.Spec.ToPostgresVersion)}
+	script := logicalScript(
+		`lag="$(psql "${SOURCE} dbname=postgres" --quiet --tuples-only --no-align --command="`,
+		`SELECT COALESCE(max(pg_catalog.pg_wal_lsn_diff(pg_catalog.pg_current_wal_lsn(), confirmed_flush_lsn)), 0)::bigint`,
+		`  FROM pg_catalog.pg_replication_slots WHERE slot_name LIKE 'pgo\_upgrade\_%'")"`,
+		``,
+		`pending=0`,
+		`while read -r database; do`,
+		`  count="$(PGDATABASE="${database}" psql "${TARGET}" --quiet --tuples-only --no-align \`,
+		`    --command="SELECT count(*) FROM pg_catalog.pg_subscription_rel WHERE srsubstate <> 'r'")"`,
+		`  pending=$((pending + count))`,
+		`done < <(psql "${TARGET} dbname=postgres" --quiet --tuples-only --no-align \`,
+		`  --command='SELECT datname FROM pg_catalog.pg_database WHERE datallowconn AND NOT datistemplate')`,
+		``,
+		`echo "lag=${lag} pending=${pending}" > /dev/termination-log`,
+		`cat /dev/termination-log`,
+	)
+
+	return append([]string{"bash", "-ceu", "--", script, "logical-lag"}, args...)
+}
+
+// pgBouncerAdminScript is a bash function that sends a command to the admin
+// console of the PgBouncer at a host. See [withPGBouncerAdmin].
+const pgBouncerAdminScript = `pgbouncer() {
+  PGPASSWORD="${PGBOUNCER_PASSWORD}" psql "host=$1 ${PGBOUNCER}" --quiet --tuples-only --no-align --command="$2"
+}`
+
+// logicalCutoverCommand returns an entrypoint that pauses PgBouncer, stops
+// writes to the old cluster, waits for the new cluster to catch up, copies
+// sequences and the PgBouncer credentials, and then stops replicating.
+// PgBouncer stays paused when this succeeds; see [logicalResumeCommand].
+// - https://www.pgbouncer.org/usage.html#pause-db
+func logicalCutoverCommand(upgrade *v1beta1.PGUpgrade) []string {
+	args := []string{fmt.Sprint(upgrade.Spec.ToPostgresVersion)}
+	script := logicalScript(
+		pgBouncerAdminScript,
+		``,
+		`# PAUSE returns once PgBouncer has released its server connections, which`,
+		`# happens when client backends are terminated below. Resume on failure.`,
+		`pids=()`,
+		`for host in ${PGBOUNCER_HOSTS-}; do`,
+		`  echo "Pausing PgBouncer at ${host}..."`,
+		`  pgbouncer "${host}" PAUSE > /dev/null & pids+=("$!")`,
+		`done`,
+		`trap 'for host in ${PGBOUNCER_HOSTS-}; do pgbouncer "${host}" RESUME > /dev/null || true; done' EXIT`,
+		``,
+		`echo 'Stopping writes...'`,
+		`psql "${SOURCE} dbname=postgres" --quiet --set=ON_ERROR_STOP=1 > /dev/null <<'SQL'`,
+		`SELECT pg_catalog.format('ALTER DATABASE %I SET default_transaction_read_only = on', datname)`,
+		`  FROM pg_catalog.pg_database WHERE datallowconn AND NOT datistemplate`,
+		`\gexec`,
+		`SELECT pg_catalog.pg_terminate_backend(pid) FROM pg_catalog.pg_stat_activity`,
+		` WHERE backend_type = 'client backend' AND pid <> pg_catalog.pg_backend_pid()`,
+		`   AND usename NOT LIKE '\_crunchy%';`,
+		`SQL`,
+		``,
+		`for pid in ${pids[@]+"${pids[@]}"}; do wait "${pid}"; done`,
+		``,
+		`# Commands below write to the old cluster regardless.`,
+		`export PGOPTIONS='-c default_transaction_read_only=off'`,
+		``,
+		`echo 'Waiting for changes to replicate...'`,
+		`position="$(psql "${SOURCE} dbname=postgres" --quiet --tuples-only --no-align --command='SELECT pg_catalog.pg_current_wal_lsn()')"`,
+		`until [[ "$(psql "${SOURCE} dbname=postgres" --quiet --tuples-only --no-align --command="`,
+		`SELECT count(*) FROM pg_catalog.pg_replication_slots`,
+		` WHERE slot_name LIKE 'pgo\_upgrade\_%' AND confirmed_flush_lsn < '${position}'")" == 0 ]]`,
+		`do sleep 1; done`,
+		``,
+		`while read -r database; do`,
+		`  echo "Finishing ${database}..."`,
+		`  export PGDATABASE="${database}"`,
+		`  psql "${SOURCE}" --quiet --tuples-only --no-align --command="`,
+		`SELECT pg_catalog.format('SELECT pg_catalog.setval(%L, %s, true);',`,
+		`       pg_catalog.quote_ident(schemaname) || '.' || pg_catalog.quote_ident(sequencename), last_value)`,
+		`  FROM pg_catalog.pg_sequences WHERE last_value IS NOT NULL" |`,
+		`    psql "${TARGET}" --quiet --set=ON_ERROR_STOP=1 > /dev/null`,
+		``,
+		`  psql "${TARGET}" --quiet --set=ON_ERROR_STOP=1 <<'SQL'`,
+		`SELECT command FROM pg_catalog.pg_subscription,`,
+		`       (VALUES (1, 'ALTER SUBSCRIPTION pgo_upgrade DISABLE'), (2, 'DROP SUBSCRIPTION pgo_upgrade')) AS c (n, command)`,
+		` WHERE subname = 'pgo_upgrade' AND subdbid = (SELECT oid FROM pg_catalog.pg_database WHERE datname = current_database())`,
+		` ORDER BY n`,
+		`\gexec`,
+		`SQL`,
+		``,
+		`  psql "${SOURCE}" --quiet --set=ON_ERROR_STOP=1 --command='DROP PUBLICATION IF EXISTS pgo_upgrade'`,
+		`done < <(psql "${SOURCE} dbname=postgres" --quiet --tuples-only --no-align \`,
+		`  --command='SELECT datname FROM pg_catalog.pg_database WHERE datallowconn AND NOT datistemplate')`,
+		`unset PGDATABASE`,
+		``,
+		`# PgBouncer authenticates clients with the password of this role.`,
+		`verifier="$(psql "${SOURCE} dbname=postgres" --quiet --tuples-only --no-align \`,
+		`  --command="SELECT rolpassword FROM pg_catalog.pg_authid WHERE rolname = '_crunchypgbouncer'")"`,
+		`psql "${TARGET} dbname=postgres" --quiet --set=ON_ERROR_STOP=1 --set=verifier="${verifier}" <<'SQL'`,
+		`SELECT pg_catalog.format('ALTER ROLE _crunchypgbouncer PASSWORD %L', :'verifier')`,
+		` WHERE :'verifier' <> '' AND EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = '_crunchypgbouncer')`,
+		`\gexec`,
+		`SQL`,
+		``,
+		`psql "${SOURCE} dbname=postgres" --quiet --set=ON_ERROR_STOP=1 --command='DROP ROLE IF EXISTS _crunchyreplicate'`,
+		`trap - EXIT`,
+	)
+
+	return append([]string{"bash", "-ceu", "--", script, "logical-cutover"}, args...)
+}
+
+// logicalResumeCommand returns an entrypoint that waits for PgBouncer to
+// reload the databases that point at the new cluster and then resumes it.
+// PgBouncer is resumed even when it does not reload in time.
+func logicalResumeCommand(upgrade *v1beta1.PGUpgrade) []string {
+	args := []string{fmt.Sprint(upgrade.Spec.ToPostgresVersion)}
+	script := logicalScript(
+		pgBouncerAdminScript,
+		``,
+		`failed=0`,
+		`for host in ${PGBOUNCER_HOSTS-}; do`,
+		`  echo "Waiting for PgBouncer at ${host} to reload..."`,
+		`  until databases="$(pgbouncer "${host}" 'SHOW DATABASES')" &&`,
+		`    ! awk -F'|' -v host="${SOURCE_HOST%%.*}" '$2 == host || index($2, host ".") == 1 { found = 1 } END { exit !found }' <<< "${databases}"`,
+		`  do`,
+		`    if [[ "${SECONDS}" -gt 300 ]]; then`,
+		`      echo "PgBouncer at ${host} still connects to ${SOURCE_HOST}"; failed=1; break`,
+		`    fi`,
+		`    sleep 5`,
+		`  done`,
+		`done`,
+		``,
+		`for host in ${PGBOUNCER_HOSTS-}; do`,
+		`  echo "Resuming PgBouncer at ${host}..."`,
+		`  pgbouncer "${host}" RESUME > /dev/null || true`,
+		`done`,
+		`exit "${failed}"`,
+	)
+
+	return append([]string{"bash", "-ceu", "--", script, "logical-resume"}, args...)
+}
+
+// generateLogicalJob returns a Job that runs command as the upgrade user of
+// both source and target. Connection parameters are in the SOURCE and TARGET
+// environment variables.
+func (r *PGUpgradeReconciler) generateLogicalJob(
+	upgrade *v1beta1.PGUpgrade, source, target *v1beta1.PostgresCluster,
+	role string, command []string,
+) *batchv1.Job {
+	job := &batchv1.Job{ObjectMeta: pgUpgradeLogicalJob(upgrade, role)}
+	job.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("Job"))
+
+	job.Annotations = upgrade.Spec.Metadata.GetAnnotationsOrNil()
+	job.Labels = Merge(upgrade.Spec.Metadata.GetLabelsOrNil(),
+		commonLabels(role, upgrade),
+		map[string]string{
+			LabelVersion: fmt.Sprint(upgrade.Spec.ToPostgresVersion),
+		})
+
+	connection := func(cluster *v1beta1.PostgresCluster, directory string) string {
+		service := naming.ClusterPrimaryService(cluster)
+		return strings.Join([]string{
+			fmt.Sprintf("host=%s.%s.svc", service.Name, service.Namespace),
+			fmt.Sprintf("port=%d", *cluster.Spec.Port),
+			"user=" + postupgrade.User,
+			"sslmode=verify-ca",
+			"sslcert=" + directory + "/" + postupgrade.CertFile,
+			"sslkey=" + directory + "/" + postupgrade.KeyFile,
+			"sslrootcert=" + directory + "/" + postupgrade.CAFile,
+		}, " ")
+	}
+	volume := func(name string, cluster *v1beta1.PostgresCluster) corev1.Volume {
+		return corev1.Volume{
+			Name: name,
+			VolumeSource: corev1.VolumeSource{
+				Secret: &corev1.SecretVolumeSource{
+					SecretName: naming.PostUpgradeSecret(cluster).Name,
+					// The client key must not be readable by others.
+					// - https://www.postgresql.org/docs/current/libpq-ssl.html
+					DefaultMode: initialize.Int32(0o600),
+				},
+			},
+		}
+	}
+
+	const sourceDirectory, targetDirectory = "/pgconf/source", "/pgconf/target"
+	sourceService := naming.ClusterPrimaryService(source)
+	container := corev1.Container{
+		Name:            ContainerDatabase,
+		Command:         servicemesh.JobCommand(source, command),
+		Image:           pgUpgradeContainerImage(upgrade),
+		ImagePullPolicy: upgrade.Spec.ImagePullPolicy,
+		Resources:       upgrade.Spec.Resources,
+		SecurityContext: initialize.RestrictedSecurityContext(),
+		Env: []corev1.EnvVar{
+			{Name: "SOURCE", Value: connection(source, sourceDirectory)},
+			{Name: "SOURCE_HOST", Value: fmt.Sprintf("%s.%s.svc", sourceService.Name, sourceService.Namespace)},
+			{Name: "SOURCE_PORT", Value: fmt.Sprint(*source.Spec.Port)},
+			{Name: "TARGET", Value: connection(target, targetDirectory)},
+		},
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "source-cert", MountPath: sourceDirectory, ReadOnly: true},
+			{Name: "target-cert", MountPath: targetDirectory, ReadOnly: true},
+		},
+	}
+
+	job.Spec.Template = corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: Merge(job.Annotations, servicemesh.JobAnnotations(source)),
+			Labels:      job.Labels,
+		},
+		Spec: corev1.PodSpec{
+			Affinity:          upgrade.Spec.Affinity,
+			Containers:        []corev1.Container{container},
+			ImagePullSecrets:  upgrade.Spec.ImagePullSecrets,
+			PriorityClassName: initialize.FromPointer(upgrade.Spec.PriorityClassName),
+			RestartPolicy:     corev1.RestartPolicyNever,
+			SecurityContext:   postgres.PodSecurityContext(source),
+			Tolerations:       upgrade.Spec.Tolerations,
+
+			// These Jobs don't make Kubernetes API calls, so we can just
+			// use the default ServiceAccount and not mount its credentials.
+			AutomountServiceAccountToken: initialize.Bool(false),
+			EnableServiceLinks:           initialize.Bool(false),
+			Volumes: []corev1.Volume{
+				volume("source-cert", source),
+				volume("target-cert", target),
+			},
+		},
+	}
+
+	job.Spec.BackoffLimit = initialize.Int32(6)
+	withJobLimits(source, job)
+
+	r.setControllerReference(upgrade, job)
+	return job
+}
+
+// withPGBouncerAdmin adds to job the environment of [pgBouncerAdminScript] to
+// reach the PgBouncer of cluster at hosts. Its frontend certificate is trusted
+// through the certificate authority of cluster.
+func withPGBouncerAdmin(
+	job *batchv1.Job, cluster *v1beta1.PostgresCluster, hosts []string,
+) *batchv1.Job {
+	container := &job.Spec.Template.Spec.Containers[0]
+	container.Env = append(container.Env, corev1.EnvVar{
+		Name: "PGBOUNCER_HOSTS", Value: strings.Join(hosts, " "),
+	})
+
+	if cluster.Spec.Proxy != nil && cluster.Spec.Proxy.PGBouncer != nil {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name: "PGBOUNCER",
+			Value: strings.Join([]string{
+				fmt.Sprintf("port=%d", *cluster.Spec.Proxy.PGBouncer.Port),
+				"user=" + pgbouncer.AdminUser,
+				"dbname=pgbouncer",
+				"sslmode=verify-ca",
+				"sslrootcert=/pgconf/source/" + postupgrade.CAFile,
+			}, " "),
+		}, corev1.EnvVar{
+			Name: "PGBOUNCER_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: naming.ClusterPGBouncer(cluster).Name,
+					},
+					Key: pgbouncer.AdminPasswordSecretKey,
+				},
+			},
+		})
+	}
+	return job
+}
+
+// pgBouncerHosts returns the addresses of the running PgBouncer Pods of cluster.
+func (r *PGUpgradeReconciler) pgBouncerHosts(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) ([]string, error) {
+	if cluster.Spec.Proxy == nil || cluster.Spec.Proxy.PGBouncer == nil {
+		return nil, nil
+	}
+
+	var pods corev1.PodList
+	selector, err := naming.AsSelector(naming.ClusterPGBouncerSelector(cluster))
+	if err == nil {
+		err = errors.WithStack(r.List(ctx, &pods,
+			client.InNamespace(cluster.Namespace),
+			client.MatchingLabelsSelector{Selector: selector},
+		))
+	}
+
+	var hosts []string
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == corev1.PodRunning && pods.Items[i].Status.PodIP != "" {
+			hosts = append(hosts, pods.Items[i].Status.PodIP)
+		}
+	}
+	return hosts, err
+}
+
+// pgBouncerHostsOf returns the PgBouncer addresses that job was created with.
+func pgBouncerHostsOf(job *batchv1.Job) []string {
+	for _, container := range job.Spec.Template.Spec.Containers {
+		for _, env := range container.Env {
+			if env.Name == "PGBOUNCER_HOSTS" {
+				return strings.Fields(env.Value)
+			}
+		}
+	}
+	return nil
+}
+
+// cutoverDatabases returns the PgBouncer databases of source with those that
+// connect to its primary pointed at the primary of target instead. Databases
+// on other hosts are unchanged.
+func cutoverDatabases(source, target *v1beta1.PostgresCluster) map[string]string {
+	sourceHost := naming.ClusterPrimaryService(source).Name
+	targetHost := naming.ClusterPrimaryService(target).Name
+
+	databases := source.Spec.Proxy.PGBouncer.Config.Databases
+	if len(databases) == 0 {
+		// This is the default of the PgBouncer configuration.
+		databases = map[string]string{
+			"*": fmt.Sprintf("host=%s port=%d", sourceHost, *source.Spec.Port),
+		}
+	}
+
+	result := make(map[string]string, len(databases))
+	for name, value := range databases {
+		fields := strings.Fields(value)
+		primary := false
+		for _, field := range fields {
+			host := strings.TrimPrefix(field, "host=")
+			primary = primary || field != host &&
+				(host == sourceHost || strings.HasPrefix(host, sourceHost+"."))
+		}
+		if !primary {
+			result[name] = value
+			continue
+		}
+
+		port := fmt.Sprintf("port=%d", *target.Spec.Port)
+		found := false
+		for i := range fields {
+			switch {
+			case strings.HasPrefix(fields[i], "host="):
+				fields[i] = "host=" + targetHost
+			case strings.HasPrefix(fields[i], "port="):
+				fields[i], found = port, true
+			}
+		}
+		if !found {
+			fields = append(fields, port)
+		}
+		result[name] = strings.Join(fields, " ")
+	}
+	return result
+}
+
+//+kubebuilder:rbac:groups="",resources="pods",verbs={list}
+
+// parseLogicalLag reads the lag and pending tables written by the command of
+// logicalLagCommand.
+func parseLogicalLag(message string) (lag int64, pending int32, err error) {
+	_, err = fmt.Sscanf(strings.TrimSpace(message), "lag=%d pending=%d", &lag, &pending)
+	return lag, pending, errors.WithStack(err)
+}
+
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={create,patch}
+
+// reconcileLogicalReplication upgrades by replicating the PostgresCluster of
+// upgrade into a new cluster of the new version. The new cluster is created
+// first. Once both clusters allow the upgrade, roles and schema are copied and
+// every database is subscribed. Replication lag is measured periodically until
+// cutover is requested and every table is copied. Cutover pauses the PgBouncer
+// of the old cluster, stops writes to it, points its PgBouncer at the new
+// cluster, and resumes PgBouncer.
+func (r *PGUpgradeReconciler) reconcileLogicalReplication(
+	ctx context.Context, upgrade *v1beta1.PGUpgrade,
+) (ctrl.Result, error) {
+	progressing := func(status metav1.ConditionStatus, reason, message string) {
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeProgressing,
+			Status:             status,
+			Reason:             reason,
+			Message:            message,
+		})
+	}
+
+	world, err := r.observeWorld(ctx, upgrade)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if world.ClusterNotFound != nil {
+		progressing(metav1.ConditionFalse, "PGClusterNotFound", world.ClusterNotFound.Error())
+		return ctrl.Result{}, nil
+	}
+	source := world.Cluster
+
+	status := upgrade.Status.LogicalReplication
+	if status == nil {
+		status = &v1beta1.PGUpgradeLogicalReplicationStatus{Phase: phaseProvisioning}
+		upgrade.Status.LogicalReplication = status
+	}
+	status.TargetClusterName = logicalTargetClusterName(upgrade)
+
+	// Create the new cluster when it does not exist. It is not owned by the
+	// upgrade so that it remains after the upgrade is deleted.
+	target := v1beta1.NewPostgresCluster()
+	err = errors.WithStack(r.Get(ctx, client.ObjectKey{
+		Namespace: upgrade.Namespace, Name: status.TargetClusterName,
+	}, target))
+	if apierrors.IsNotFound(err) {
+		target = generateTargetCluster(upgrade, source)
+		err = errors.WithStack(r.Create(ctx, target, r.Owner))
+		if err == nil {
+			progressing(metav1.ConditionTrue, "PGUpgradeProvisioning", fmt.Sprintf(
+				"Creating PostgresCluster %s", target.Name))
+		}
+		return ctrl.Result{}, err
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if target.GetAnnotations()[AnnotationAllowUpgrade] != upgrade.Name {
+		progressing(metav1.ConditionFalse, "PGClusterMissingRequiredAnnotation", fmt.Sprintf(
+			"PostgresCluster %s lacks annotation for upgrade %s", target.Name, upgrade.Name))
+		return ctrl.Result{}, nil
+	}
+
+	if source.GetAnnotations()[AnnotationAllowUpgrade] != upgrade.Name {
+		progressing(metav1.ConditionFalse, "PGClusterMissingRequiredAnnotation", fmt.Sprintf(
+			"PostgresCluster %s lacks annotation for upgrade %s", source.Name, upgrade.Name))
+		return ctrl.Result{}, nil
+	}
+	for _, cluster := range []*v1beta1.PostgresCluster{source, target} {
+		if cluster.Status.UpgradeUser == nil || cluster.Status.UpgradeUser.Revision == "" {
+			progressing(metav1.ConditionTrue, "PGUpgradeProvisioning", fmt.Sprintf(
+				"Waiting for PostgresCluster %s to accept the upgrade", cluster.Name))
+			return ctrl.Result{}, nil
+		}
+	}
+
+	// Subscribe the new cluster to the old one.
+	setup := world.Jobs[pgUpgradeLogicalJob(upgrade, logicalSetup).Name]
+	if setup == nil || !(jobCompleted(setup) || jobFailed(setup)) {
+		progressing(metav1.ConditionTrue, "PGUpgradeProvisioning", fmt.Sprintf(
+			"Copying roles and schema into PostgresCluster %s", target.Name))
+		return ctrl.Result{}, errors.WithStack(r.apply(ctx,
+			r.generateLogicalJob(upgrade, source, target, logicalSetup,
+				logicalSetupCommand(upgrade))))
+	}
+	if jobFailed(setup) {
+		progressing(metav1.ConditionFalse, "PGUpgradeFailed", fmt.Sprintf(
+			"Unable to replicate; see the logs of Job %s", setup.Name))
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeSucceeded,
+			Status:             metav1.ConditionFalse,
+			Reason:             "PGUpgradeFailed",
+			Message:            fmt.Sprintf("Job %s failed", setup.Name),
+		})
+		return ctrl.Result{}, nil
+	}
+	if status.Phase == phaseProvisioning {
+		status.Phase = phaseReplicating
+	}
+
+	cutover := upgrade.Spec.LogicalReplication != nil && upgrade.Spec.LogicalReplication.Cutover
+	cutoverJob := world.Jobs[pgUpgradeLogicalJob(upgrade, logicalCutover).Name]
+
+	// Measure the lag until cutover starts.
+	if status.Phase == phaseReplicating {
+		lag := world.Jobs[pgUpgradeLogicalJob(upgrade, logicalLag).Name]
+		switch {
+		case lag == nil:
+			err = errors.WithStack(r.apply(ctx,
+				r.generateLogicalJob(upgrade, source, target, logicalLag,
+					logicalLagCommand(upgrade))))
+		case jobCompleted(lag) || jobFailed(lag):
+			if jobCompleted(lag) {
+				var message string
+				message, err = r.terminationMessage(ctx, upgrade, logicalLag)
+				if err == nil {
+					bytes, pending, parseErr := parseLogicalLag(message)
+					if parseErr == nil {
+						status.LagBytes = &bytes
+						status.PendingTables = &pending
+						status.LastLagCheckTime = &metav1.Time{Time: time.Now()}
+					} else {
+						ctrl.LoggerFrom(ctx).Error(parseErr, "Reading replication lag")
+					}
+				}
+			}
+			if err == nil {
+				err = client.IgnoreNotFound(errors.WithStack(r.Delete(ctx, lag,
+					client.PropagationPolicy(metav1.DeletePropagationBackground))))
+			}
+		}
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if !cutover || status.PendingTables == nil || *status.PendingTables > 0 {
+			message := fmt.Sprintf("Replicating into PostgresCluster %s", target.Name)
+			if status.PendingTables != nil && *status.PendingTables > 0 {
+				message += fmt.Sprintf("; %d tables are still being copied", *status.PendingTables)
+			}
+			progressing(metav1.ConditionTrue, "PGUpgradeReplicating", message)
+			return ctrl.Result{RequeueAfter: logicalLagInterval}, nil
+		}
+
+		status.Phase = phaseCuttingOver
+	}
+
+	if status.Phase == phaseCuttingOver {
+		// PgBouncer is paused at the addresses the Job is created with, so
+		// create it once.
+		if cutoverJob == nil {
+			var hosts []string
+			hosts, err = r.pgBouncerHosts(ctx, source)
+			if err == nil {
+				err = errors.WithStack(r.apply(ctx, withPGBouncerAdmin(
+					r.generateLogicalJob(upgrade, source, target, logicalCutover,
+						logicalCutoverCommand(upgrade)), source, hosts)))
+			}
+			if err == nil {
+				progressing(metav1.ConditionTrue, "PGUpgradeCuttingOver", fmt.Sprintf(
+					"Stopping writes to PostgresCluster %s", source.Name))
+			}
+			return ctrl.Result{}, err
+		}
+		if !(jobCompleted(cutoverJob) || jobFailed(cutoverJob)) {
+			progressing(metav1.ConditionTrue, "PGUpgradeCuttingOver", fmt.Sprintf(
+				"Stopping writes to PostgresCluster %s", source.Name))
+			return ctrl.Result{}, nil
+		}
+		if jobFailed(cutoverJob) {
+			progressing(metav1.ConditionFalse, "PGUpgradeFailed", fmt.Sprintf(
+				"Unable to cut over; see the logs of Job %s", cutoverJob.Name))
+			meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+				ObservedGeneration: upgrade.Generation,
+				Type:               ConditionPGUpgradeSucceeded,
+				Status:             metav1.ConditionFalse,
+				Reason:             "PGUpgradeFailed",
+				Message:            fmt.Sprintf("Job %s failed", cutoverJob.Name),
+			})
+			return ctrl.Result{}, nil
+		}
+
+		if source.Spec.Proxy != nil && source.Spec.Proxy.PGBouncer != nil {
+			// Send PgBouncer connections of the old cluster to the new one.
+			// Keep the previous databases so they can be put back.
+			if !status.PGBouncerRerouted {
+				patch := source.DeepCopy()
+				patch.Spec.Proxy.PGBouncer.Config.Databases = cutoverDatabases(source, target)
+				err = errors.WithStack(r.Patch(ctx, patch, client.MergeFrom(source), r.Owner))
+				if err == nil {
+					status.PGBouncerRerouted = true
+					status.PreviousPGBouncerDatabases =
+						source.Spec.Proxy.PGBouncer.Config.Databases
+				}
+			}
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+
+			// Resume the PgBouncer paused by the cutover Job.
+			resume := world.Jobs[pgUpgradeLogicalJob(upgrade, logicalResume).Name]
+			if resume == nil || !(jobCompleted(resume) || jobFailed(resume)) {
+				if resume == nil {
+					err = errors.WithStack(r.apply(ctx, withPGBouncerAdmin(
+						r.generateLogicalJob(upgrade, source, target, logicalResume,
+							logicalResumeCommand(upgrade)), source, pgBouncerHostsOf(cutoverJob))))
+				}
+				progressing(metav1.ConditionTrue, "PGUpgradeCuttingOver", fmt.Sprintf(
+					"Resuming PgBouncer of PostgresCluster %s", source.Name))
+				return ctrl.Result{}, err
+			}
+			if jobFailed(resume) {
+				progressing(metav1.ConditionFalse, "PGUpgradeFailed", fmt.Sprintf(
+					"Unable to resume PgBouncer; see the logs of Job %s", resume.Name))
+				meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+					ObservedGeneration: upgrade.Generation,
+					Type:               ConditionPGUpgradeSucceeded,
+					Status:             metav1.ConditionFalse,
+					Reason:             "PGUpgradeFailed",
+					Message:            fmt.Sprintf("Job %s failed", resume.Name),
+				})
+				return ctrl.Result{}, nil
+			}
+		}
+
+		status.Phase = phaseCompleted
+	}
+
+	// Remove the upgrade user from both clusters.
+	for _, cluster := range []*v1beta1.PostgresCluster{source, target} {
+		if err == nil {
+			err = r.releaseUpgradeUser(ctx, upgrade, cluster)
+		}
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	message := fmt.Sprintf("PostgresCluster %s is running version %d",
+		target.Name, upgrade.Spec.ToPostgresVersion)
+	progressing(metav1.ConditionFalse, "PGUpgradeCompleted", message)
+	meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+		ObservedGeneration: upgrade.Generation,
+		Type:               ConditionPGUpgradeSucceeded,
+		Status:             metav1.ConditionTrue,
+		Reason:             "PGUpgradeSucceeded",
+		Message:            message,
+	})
+	return ctrl.Result{}, nil
+}
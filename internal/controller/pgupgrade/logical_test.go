@@ -0,0 +1,242 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgupgrade
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestLogicalTargetClusterName(t *testing.T) {
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Spec.PostgresClusterName = "hippo"
+	upgrade.Spec.ToPostgresVersion = 16
+	assert.Equal(t, logicalTargetClusterName(upgrade), "hippo-pg16")
+
+	upgrade.Spec.LogicalReplication = &v1beta1.PGUpgradeLogicalReplicationSpec{
+		TargetClusterName: "rhino",
+	}
+	assert.Equal(t, logicalTargetClusterName(upgrade), "rhino")
+}
+
+func TestGenerateTargetCluster(t *testing.T) {
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Name = "pgu2"
+	upgrade.Spec.PostgresClusterName = "pg5"
+	upgrade.Spec.ToPostgresVersion = 16
+	upgrade.Spec.ToPostgresImage = "img16"
+
+	source := &v1beta1.PostgresCluster{}
+	source.Namespace = "ns1"
+	source.Name = "pg5"
+	source.Spec.PostgresVersion = 14
+	source.Spec.Image = "img14"
+	source.Spec.Shutdown = initialize.Bool(true)
+	source.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{Name: "00"}}
+	source.Spec.Backups.PGBackRest.Repos = []v1beta1.PGBackRestRepo{
+		{Name: "repo1", S3: &v1beta1.RepoS3{Bucket: "b"}},
+	}
+
+	target := generateTargetCluster(upgrade, source)
+	assert.Equal(t, target.Namespace, "ns1")
+	assert.Equal(t, target.Name, "pg5-pg16")
+	assert.Equal(t, target.Annotations[AnnotationAllowUpgrade], "pgu2")
+	assert.Equal(t, target.Labels[LabelPGUpgrade], "pgu2")
+	assert.Equal(t, target.Spec.PostgresVersion, 16)
+	assert.Equal(t, target.Spec.Image, "img16")
+	assert.Assert(t, target.Spec.Shutdown == nil)
+
+	// Cloud repositories are replaced by a volume.
+	assert.Equal(t, len(target.Spec.Backups.PGBackRest.Repos), 1)
+	assert.Assert(t, target.Spec.Backups.PGBackRest.Repos[0].Volume != nil)
+
+	// The source is unchanged.
+	assert.Equal(t, source.Spec.PostgresVersion, 14)
+	assert.Assert(t, source.Spec.Backups.PGBackRest.Repos[0].S3 != nil)
+}
+
+func TestGenerateLogicalJob(t *testing.T) {
+	reconciler := &PGUpgradeReconciler{}
+
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Namespace = "ns1"
+	upgrade.Name = "pgu2"
+	upgrade.Spec.Image = initialize.Pointer("img4")
+	upgrade.Spec.PostgresClusterName = "pg5"
+	upgrade.Spec.ToPostgresVersion = 16
+
+	source := &v1beta1.PostgresCluster{}
+	source.Namespace = "ns1"
+	source.Name = "pg5"
+	source.Spec.Port = initialize.Int32(5432)
+
+	target := source.DeepCopy()
+	target.Name = "pg5-pg16"
+	target.Spec.Port = initialize.Int32(5433)
+
+	job := reconciler.generateLogicalJob(upgrade, source, target,
+		logicalSetup, logicalSetupCommand(upgrade))
+
+	assert.Equal(t, job.Name, "pgu2-logical-setup")
+	assert.DeepEqual(t, job.Spec.Template.Labels, map[string]string{
+		LabelPGUpgrade: "pgu2",
+		LabelCluster:   "pg5",
+		LabelRole:      "logical-setup",
+		LabelVersion:   "16",
+	})
+
+	pod := job.Spec.Template.Spec
+	assert.Equal(t, pod.Containers[0].Image, "img4")
+	assert.Assert(t, marshalMatches(pod.Containers[0].Env, `
+- name: SOURCE
+  value: host=pg5-primary.ns1.svc port=5432 user=_crunchyupgrade sslmode=verify-ca
+    sslcert=/pgconf/source/tls.crt sslkey=/pgconf/source/tls.key sslrootcert=/pgconf/source/ca.crt
+- name: SOURCE_HOST
+  value: pg5-primary.ns1.svc
+- name: SOURCE_PORT
+  value: "5432"
+- name: TARGET
+  value: host=pg5-pg16-primary.ns1.svc port=5433 user=_crunchyupgrade sslmode=verify-ca
+    sslcert=/pgconf/target/tls.crt sslkey=/pgconf/target/tls.key sslrootcert=/pgconf/target/ca.crt
+	`))
+	assert.Equal(t, pod.Volumes[0].Secret.SecretName, "pg5-upgrade-cert")
+	assert.Equal(t, pod.Volumes[1].Secret.SecretName, "pg5-pg16-upgrade-cert")
+	assert.Equal(t, *pod.Volumes[1].Secret.DefaultMode, int32(0o600))
+
+	command := pod.Containers[0].Command
+	assert.DeepEqual(t, command[len(command)-2:], []string{"logical-setup", "16"})
+	assert.Assert(t, strings.Contains(command[3], `CREATE PUBLICATION pgo_upgrade FOR ALL TABLES`))
+	assert.Assert(t, strings.Contains(command[3], `CREATE SUBSCRIPTION pgo_upgrade`))
+	assert.Assert(t, strings.Contains(command[3], `sslmode=verify-full sslrootcert=%s`))
+	assert.Assert(t, !strings.Contains(command[3], `sslmode=require`))
+}
+
+func TestWithPGBouncerAdmin(t *testing.T) {
+	reconciler := &PGUpgradeReconciler{}
+
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Namespace = "ns1"
+	upgrade.Name = "pgu2"
+	upgrade.Spec.ToPostgresVersion = 16
+
+	source := &v1beta1.PostgresCluster{}
+	source.Namespace = "ns1"
+	source.Name = "pg5"
+	source.Spec.Port = initialize.Int32(5432)
+
+	t.Run("NoPGBouncer", func(t *testing.T) {
+		job := withPGBouncerAdmin(reconciler.generateLogicalJob(upgrade, source, source,
+			logicalCutover, logicalCutoverCommand(upgrade)), source, nil)
+
+		env := job.Spec.Template.Spec.Containers[0].Env
+		assert.Assert(t, marshalMatches(env[len(env)-1:], `
+- name: PGBOUNCER_HOSTS
+		`))
+		assert.Equal(t, len(pgBouncerHostsOf(job)), 0)
+	})
+
+	source.Spec.Proxy = &v1beta1.PostgresProxySpec{
+		PGBouncer: &v1beta1.PGBouncerPodSpec{Port: initialize.Int32(6432)},
+	}
+	job := withPGBouncerAdmin(reconciler.generateLogicalJob(upgrade, source, source,
+		logicalCutover, logicalCutoverCommand(upgrade)), source, []string{"10.0.0.1", "10.0.0.2"})
+
+	env := job.Spec.Template.Spec.Containers[0].Env
+	assert.Assert(t, marshalMatches(env[len(env)-3:], `
+- name: PGBOUNCER_HOSTS
+  value: 10.0.0.1 10.0.0.2
+- name: PGBOUNCER
+  value: port=6432 user=_crunchypgbouncer dbname=pgbouncer sslmode=verify-ca sslrootcert=/pgconf/source/ca.crt
+- name: PGBOUNCER_PASSWORD
+  valueFrom:
+    secretKeyRef:
+      key: pgbouncer-password
+      name: pg5-pgbouncer
+		`))
+	assert.DeepEqual(t, pgBouncerHostsOf(job), []string{"10.0.0.1", "10.0.0.2"})
+}
+
+func TestCutoverDatabases(t *testing.T) {
+	source := &v1beta1.PostgresCluster{}
+	source.Namespace = "ns1"
+	source.Name = "pg5"
+	source.Spec.Port = initialize.Int32(5432)
+	source.Spec.Proxy = &v1beta1.PostgresProxySpec{
+		PGBouncer: &v1beta1.PGBouncerPodSpec{},
+	}
+
+	target := source.DeepCopy()
+	target.Name = "pg5-pg16"
+	target.Spec.Port = initialize.Int32(5433)
+
+	t.Run("Default", func(t *testing.T) {
+		assert.DeepEqual(t, cutoverDatabases(source, target), map[string]string{
+			"*": "host=pg5-pg16-primary port=5433",
+		})
+	})
+
+	t.Run("Custom", func(t *testing.T) {
+		source := source.DeepCopy()
+		source.Spec.Proxy.PGBouncer.Config.Databases = map[string]string{
+			"app":     "host=pg5-primary.ns1.svc dbname=app pool_size=5",
+			"reports": "host=pg5-replicas port=5432 dbname=app",
+			"other":   "host=elsewhere port=5432",
+			"*":       "host=pg5-primary port=5432",
+		}
+
+		assert.DeepEqual(t, cutoverDatabases(source, target), map[string]string{
+			"app":     "host=pg5-pg16-primary dbname=app pool_size=5 port=5433",
+			"reports": "host=pg5-replicas port=5432 dbname=app",
+			"other":   "host=elsewhere port=5432",
+			"*":       "host=pg5-pg16-primary port=5433",
+		})
+
+		// The source is unchanged.
+		assert.Equal(t, source.Spec.Proxy.PGBouncer.Config.Databases["*"],
+			"host=pg5-primary port=5432")
+	})
+}
+
+func TestLogicalCutoverCommand(t *testing.T) {
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Spec.ToPostgresVersion = 16
+
+	command := logicalCutoverCommand(upgrade)
+	assert.Assert(t, strings.Contains(command[3], `default_transaction_read_only = on`))
+	assert.Assert(t, strings.Contains(command[3], `pg_catalog.setval`))
+	assert.Assert(t, strings.Contains(command[3], `DROP SUBSCRIPTION pgo_upgrade`))
+	assert.Assert(t, strings.Contains(command[3], `pgbouncer "${host}" PAUSE`))
+	assert.Assert(t, strings.Contains(command[3], `pgbouncer "${host}" RESUME`))
+
+	command = logicalResumeCommand(upgrade)
+	assert.DeepEqual(t, command[len(command)-2:], []string{"logical-resume", "16"})
+	assert.Assert(t, strings.Contains(command[3], `SHOW DATABASES`))
+	assert.Assert(t, strings.Contains(command[3], `pgbouncer "${host}" RESUME`))
+}
+
+func TestParseLogicalLag(t *testing.T) {
+	lag, pending, err := parseLogicalLag("lag=1024 pending=3\n")
+	assert.NilError(t, err)
+	assert.Equal(t, lag, int64(1024))
+	assert.Equal(t, pending, int32(3))
+
+	_, _, err = parseLogicalLag("")
+	assert.Assert(t, err != nil)
+}
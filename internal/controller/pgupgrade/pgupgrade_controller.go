@@ -17,9 +17,11 @@ package pgupgrade
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -84,7 +86,9 @@ func (r *PGUpgradeReconciler) findUpgradesForPostgresCluster(
 		Namespace: cluster.Namespace,
 	}) == nil {
 		for i := range upgrades.Items {
-			if upgrades.Items[i].Spec.PostgresClusterName == cluster.Name {
+			status := upgrades.Items[i].Status.LogicalReplication
+			if upgrades.Items[i].Spec.PostgresClusterName == cluster.Name ||
+				status != nil && status.TargetClusterName == cluster.Name {
 				matching = append(matching, &upgrades.Items[i])
 			}
 		}
@@ -172,11 +176,29 @@ func (r *PGUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	// This controller may be changed in the future to allow multiple uses of
 	// a single pgupgrade; if that is the case, it will probably need to reset
 	// the succeeded condition and remove upgrade and removedata jobs.
+	// A rollback is final, too.
 	succeeded := meta.FindStatusCondition(upgrade.Status.Conditions,
 		ConditionPGUpgradeSucceeded)
-	if succeeded != nil && succeeded.Reason == "PGUpgradeSucceeded" {
+	if succeeded != nil && succeeded.Reason == "PGUpgradeRolledBack" {
 		return
 	}
+	if upgrade.Spec.Rollback {
+		return r.reconcileRollback(ctx, upgrade)
+	}
+	if succeeded != nil && succeeded.Reason == "PGUpgradeSucceeded" {
+		// A new cluster is created, so there is nothing to finish.
+		if upgrade.Spec.Method == methodLogicalReplication {
+			return
+		}
+
+		// Finish any work that waits for the cluster to start again.
+		world, err := r.observeWorld(ctx, upgrade)
+		if err == nil && world.Cluster != nil {
+			setRollbackStatus(upgrade, world)
+			err = r.reconcilePostUpgrade(ctx, upgrade, world)
+		}
+		return ctrl.Result{}, err
+	}
 
 	// Set progressing condition to true if it doesn't exist already
 	setStatusToProgressingIfReasonWas("", upgrade)
@@ -198,7 +220,10 @@ func (r *PGUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, nil
 	}
 
-	if err = verifyUpgradeImageValue(upgrade); err != nil {
+	if err = verifyIntermediateVersions(upgrade); err == nil {
+		err = verifyUpgradeImageValue(upgrade)
+	}
+	if err != nil {
 
 		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
 			ObservedGeneration: upgrade.GetGeneration(),
@@ -213,6 +238,10 @@ func (r *PGUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 
 	setStatusToProgressingIfReasonWas("PGUpgradeInvalid", upgrade)
 
+	if upgrade.Spec.Method == methodLogicalReplication {
+		return r.reconcileLogicalReplication(ctx, upgrade)
+	}
+
 	// Observations and cluster validation
 	//
 	// First, read everything we need from the API. Compare the state of the
@@ -251,6 +280,7 @@ func (r *PGUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	}
 
 	setStatusToProgressingIfReasonWas("PGClusterNotFound", upgrade)
+	setRollbackStatus(upgrade, world)
 
 	// Get the spec version to check if this cluster is at the requested version
 	version := int64(world.Cluster.Spec.PostgresVersion)
@@ -325,82 +355,161 @@ func (r *PGUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 		return ctrl.Result{}, nil
 	}
 
-	// The upgrade needs to manipulate the data directory of the primary while
-	// Postgres is stopped. Wait until all instances are gone and the primary
-	// is identified.
-	//
-	// Requiring the cluster be shutdown also provides some assurance that the
-	// user understands downtime requirement of upgrading
-	if !world.ClusterShutdown {
+	if version != int64(upgrade.Spec.FromPostgresVersion) &&
+		statusVersion != int64(upgrade.Spec.ToPostgresVersion) {
 		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
 			ObservedGeneration: upgrade.Generation,
 			Type:               ConditionPGUpgradeProgressing,
 			Status:             metav1.ConditionFalse,
-			Reason:             "PGClusterNotShutdown",
-			Message:            "PostgresCluster instances still running",
+			Reason:             "PGUpgradeInvalidForCluster",
+			Message: fmt.Sprintf(
+				"Current postgres version is %d, but upgrade expected %d",
+				version, upgrade.Spec.FromPostgresVersion),
 		})
 
 		return ctrl.Result{}, nil
 	}
 
-	setStatusToProgressingIfReasonWas("PGClusterNotShutdown", upgrade)
+	setStatusToProgressingIfReasonWas("PGUpgradeInvalidForCluster", upgrade)
 
-	// A separate check for primary identification allows for cases where the
-	// PostgresCluster may not have been initialized properly.
-	if world.ClusterPrimary == nil {
+	// Each upgrade can specify one cluster, but we also want to ensure that
+	// each cluster is managed by at most one upgrade. Check that the specified
+	// cluster is annotated with the name of *this* upgrade.
+	//
+	// Having an annotation on the cluster also provides some assurance that
+	// the user that created the upgrade also has authority to create or edit
+	// the cluster.
+
+	if allowed := world.Cluster.GetAnnotations()[AnnotationAllowUpgrade] == upgrade.Name; !allowed {
 		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
 			ObservedGeneration: upgrade.Generation,
 			Type:               ConditionPGUpgradeProgressing,
 			Status:             metav1.ConditionFalse,
-			Reason:             "PGClusterPrimaryNotIdentified",
-			Message:            "PostgresCluster primary instance not identified",
+			Reason:             "PGClusterMissingRequiredAnnotation",
+			Message: fmt.Sprintf(
+				"PostgresCluster %s lacks annotation for upgrade %s",
+				upgrade.Spec.PostgresClusterName, upgrade.GetName()),
 		})
 
 		return ctrl.Result{}, nil
 	}
 
-	setStatusToProgressingIfReasonWas("PGClusterPrimaryNotIdentified", upgrade)
+	setStatusToProgressingIfReasonWas("PGClusterMissingRequiredAnnotation", upgrade)
 
-	if version != int64(upgrade.Spec.FromPostgresVersion) &&
-		statusVersion != int64(upgrade.Spec.ToPostgresVersion) {
+	// Wait for a backup when the cluster protects itself from disruptive changes.
+	if upgradeJob == nil && !changeProtected(world.Cluster, upgrade) {
 		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
 			ObservedGeneration: upgrade.Generation,
 			Type:               ConditionPGUpgradeProgressing,
 			Status:             metav1.ConditionFalse,
-			Reason:             "PGUpgradeInvalidForCluster",
+			Reason:             "PGClusterChangesHeld",
 			Message: fmt.Sprintf(
-				"Current postgres version is %d, but upgrade expected %d",
-				version, upgrade.Spec.FromPostgresVersion),
+				"PostgresCluster %s is taking a backup before upgrade %s; it must be running to do so",
+				upgrade.Spec.PostgresClusterName, upgrade.GetName()),
 		})
 
 		return ctrl.Result{}, nil
 	}
 
-	setStatusToProgressingIfReasonWas("PGUpgradeInvalidForCluster", upgrade)
+	setStatusToProgressingIfReasonWas("PGClusterChangesHeld", upgrade)
+
+	// Check that the cluster can be upgraded before changing anything. The
+	// catalogs are checked while the cluster is running, and the data
+	// directory is checked once it stops. The upgrade Job is created only
+	// after these preflight Jobs succeed.
+	if upgradeJob == nil {
+		catalogJob := world.Jobs[pgUpgradePreflightCatalogJob(upgrade).Name]
+		checkJob := world.Jobs[pgUpgradePreflightJob(upgrade).Name]
+
+		// The catalogs can be read only while PostgreSQL is running. The
+		// upgrade user exists once the cluster has the annotation above.
+		if !world.ClusterShutdown && (catalogJob == nil || !(jobCompleted(catalogJob) || jobFailed(catalogJob))) {
+			if user := world.Cluster.Status.UpgradeUser; user != nil && user.Revision != "" {
+				err = errors.WithStack(r.apply(ctx,
+					r.generatePreflightCatalogJob(upgrade, world.Cluster)))
+			}
+			return ctrl.Result{}, err
+		}
 
-	// Each upgrade can specify one cluster, but we also want to ensure that
-	// each cluster is managed by at most one upgrade. Check that the specified
-	// cluster is annotated with the name of *this* upgrade.
+		// The data directory can be checked only while PostgreSQL is stopped.
+		if world.ClusterShutdown && world.ClusterPrimary != nil &&
+			(checkJob == nil || !(jobCompleted(checkJob) || jobFailed(checkJob))) {
+			err = errors.WithStack(r.apply(ctx,
+				withJobLimits(world.Cluster, withNoProxy(world.Cluster, r.generatePreflightJob(ctx, upgrade, world.ClusterPrimary, config.FetchKeyCommand(&world.Cluster.Spec))))))
+
+			return ctrl.Result{}, err
+		}
+
+		if err = r.reconcilePreflightReport(ctx, upgrade, catalogJob, checkJob); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		if preflight := upgrade.Status.Preflight; preflight != nil && !preflight.Compatible {
+			meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+				ObservedGeneration: upgrade.Generation,
+				Type:               ConditionPGUpgradeSucceeded,
+				Status:             metav1.ConditionFalse,
+				Reason:             "PGUpgradePreflightFailed",
+				Message: fmt.Sprintf(
+					"Preflight found problems; see the report in ConfigMap %s. Delete the failed Job to check again.",
+					preflight.ConfigMap),
+			})
+
+			return ctrl.Result{}, nil
+		}
+
+		if upgrade.Spec.PreflightOnly && upgrade.Status.Preflight != nil {
+			meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+				ObservedGeneration: upgrade.Generation,
+				Type:               ConditionPGUpgradeProgressing,
+				Status:             metav1.ConditionFalse,
+				Reason:             "PGUpgradePreflightOnly",
+				Message: fmt.Sprintf(
+					"Preflight passed; see the report in ConfigMap %s",
+					upgrade.Status.Preflight.ConfigMap),
+			})
+
+			return ctrl.Result{}, r.releaseUpgradeUser(ctx, upgrade, world.Cluster)
+		}
+	}
+
+	// The upgrade needs to manipulate the data directory of the primary while
+	// Postgres is stopped. Wait until all instances are gone and the primary
+	// is identified.
 	//
-	// Having an annotation on the cluster also provides some assurance that
-	// the user that created the upgrade also has authority to create or edit
-	// the cluster.
+	// Requiring the cluster be shutdown also provides some assurance that the
+	// user understands downtime requirement of upgrading
+	if !world.ClusterShutdown {
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeProgressing,
+			Status:             metav1.ConditionFalse,
+			Reason:             "PGClusterNotShutdown",
+			Message:            "PostgresCluster instances still running",
+		})
 
-	if allowed := world.Cluster.GetAnnotations()[AnnotationAllowUpgrade] == upgrade.Name; !allowed {
+		return ctrl.Result{}, nil
+	}
+
+	setStatusToProgressingIfReasonWas("PGClusterNotShutdown", upgrade)
+
+	// A separate check for primary identification allows for cases where the
+	// PostgresCluster may not have been initialized properly.
+	if world.ClusterPrimary == nil {
 		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
 			ObservedGeneration: upgrade.Generation,
 			Type:               ConditionPGUpgradeProgressing,
 			Status:             metav1.ConditionFalse,
-			Reason:             "PGClusterMissingRequiredAnnotation",
-			Message: fmt.Sprintf(
-				"PostgresCluster %s lacks annotation for upgrade %s",
-				upgrade.Spec.PostgresClusterName, upgrade.GetName()),
+			Reason:             "PGClusterPrimaryNotIdentified",
+			Message:            "PostgresCluster primary instance not identified",
 		})
 
 		return ctrl.Result{}, nil
 	}
 
-	setStatusToProgressingIfReasonWas("PGClusterMissingRequiredAnnotation", upgrade)
+	setStatusToProgressingIfReasonWas("PGClusterPrimaryNotIdentified", upgrade)
+
+	setStatusToProgressingIfReasonWas("PGUpgradePreflightOnly", upgrade)
 
 	// Currently our jobs are set to only run once, so if any job has failed, the
 	// upgrade has failed.
@@ -461,7 +570,7 @@ func (r *PGUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	// TODO: error from apply could mean that the job exists with a different spec.
 	if err == nil && !upgradeJobComplete {
 		err = errors.WithStack(r.apply(ctx,
-			r.generateUpgradeJob(ctx, upgrade, world.ClusterPrimary, config.FetchKeyCommand(&world.Cluster.Spec))))
+			withJobLimits(world.Cluster, withNoProxy(world.Cluster, r.generateUpgradeJob(ctx, upgrade, world.ClusterPrimary, config.FetchKeyCommand(&world.Cluster.Spec))))))
 	}
 
 	// Create the jobs to remove the data from the replicas, as long as
@@ -471,7 +580,8 @@ func (r *PGUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	if err == nil && upgradeJobComplete && !removeDataJobsComplete {
 		for _, sts := range world.ClusterReplicas {
 			if err == nil {
-				err = r.apply(ctx, r.generateRemoveDataJob(ctx, upgrade, sts))
+				err = r.apply(ctx, withJobLimits(world.Cluster,
+					withNoProxy(world.Cluster, r.generateRemoveDataJob(ctx, upgrade, sts))))
 			}
 		}
 	}
@@ -507,6 +617,81 @@ func (r *PGUpgradeReconciler) Reconcile(ctx context.Context, req ctrl.Request) (
 	return
 }
 
+//+kubebuilder:rbac:groups="",resources="pods",verbs={list,watch}
+
+// terminationMessage returns the termination message of the finished Pod
+// that has role in upgrade. The message is limited to 4096 bytes.
+// - https://docs.k8s.io/tasks/debug/debug-application/determine-reason-pod-failure/
+func (r *PGUpgradeReconciler) terminationMessage(
+	ctx context.Context, upgrade *v1beta1.PGUpgrade, role string,
+) (string, error) {
+	var pods corev1.PodList
+	err := errors.WithStack(
+		r.List(ctx, &pods,
+			client.InNamespace(upgrade.Namespace),
+			client.MatchingLabels(commonLabels(role, upgrade)),
+		))
+
+	var message string
+	for i := range pods.Items {
+		for _, status := range pods.Items[i].Status.ContainerStatuses {
+			if status.State.Terminated != nil {
+				message = status.State.Terminated.Message
+			}
+		}
+	}
+	return message, err
+}
+
+//+kubebuilder:rbac:groups="",resources="configmaps",verbs={create,patch}
+
+// reconcilePreflightReport copies the reports of finished preflight Jobs from
+// the termination messages of their Pods into a ConfigMap and the upgrade
+// status. The upgrade is compatible when none of those Jobs failed. Reports of
+// deleted Jobs are forgotten so they can run again.
+func (r *PGUpgradeReconciler) reconcilePreflightReport(
+	ctx context.Context, upgrade *v1beta1.PGUpgrade, jobs ...*batchv1.Job,
+) error {
+	var err error
+	var reports []string
+	compatible := true
+	for _, job := range jobs {
+		if job == nil || !(jobCompleted(job) || jobFailed(job)) {
+			continue
+		}
+
+		var report string
+		if err == nil {
+			report, err = r.terminationMessage(ctx, upgrade, job.Labels[LabelRole])
+		}
+		reports = append(reports, strings.TrimSpace(report))
+		compatible = compatible && jobCompleted(job)
+	}
+
+	if err != nil || len(reports) == 0 {
+		upgrade.Status.Preflight = nil
+		return err
+	}
+
+	configmap := &corev1.ConfigMap{ObjectMeta: pgUpgradePreflightJob(upgrade)}
+	configmap.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+	configmap.Annotations = upgrade.Spec.Metadata.GetAnnotationsOrNil()
+	configmap.Labels = Merge(upgrade.Spec.Metadata.GetLabelsOrNil(),
+		commonLabels(preflight, upgrade))
+	configmap.Data = map[string]string{"report.txt": strings.Join(reports, "\n\n") + "\n"}
+	r.setControllerReference(upgrade, configmap)
+
+	err = errors.WithStack(r.apply(ctx, configmap))
+	if err == nil {
+		upgrade.Status.Preflight = &v1beta1.PGUpgradePreflightStatus{
+			Compatible: compatible,
+			ConfigMap:  configmap.Name,
+			Report:     configmap.Data["report.txt"],
+		}
+	}
+	return err
+}
+
 func setStatusToProgressingIfReasonWas(reason string, upgrade *v1beta1.PGUpgrade) {
 	progressing := meta.FindStatusCondition(upgrade.Status.Conditions,
 		ConditionPGUpgradeProgressing)
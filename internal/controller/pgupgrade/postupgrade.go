@@ -0,0 +1,298 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgupgrade
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/postupgrade"
+	"github.com/crunchydata/postgres-operator/internal/servicemesh"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// postUpgradeAnalyze returns whether or not statistics should be rebuilt
+// after upgrade.
+func postUpgradeAnalyze(upgrade *v1beta1.PGUpgrade) bool {
+	spec := upgrade.Spec.PostUpgrade
+	return spec == nil || spec.Analyze == nil || *spec.Analyze
+}
+
+// postUpgradeUpdateExtensions returns whether or not extensions should be
+// updated after upgrade.
+func postUpgradeUpdateExtensions(upgrade *v1beta1.PGUpgrade) bool {
+	spec := upgrade.Spec.PostUpgrade
+	return spec == nil || spec.UpdateExtensions == nil || *spec.UpdateExtensions
+}
+
+// pgUpgradePostUpgradeJob returns the ObjectMeta for the Job that does the
+// work after the upgrade in every database.
+func pgUpgradePostUpgradeJob(upgrade *v1beta1.PGUpgrade) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: upgrade.Namespace,
+		Name:      upgrade.Name + "-postupgrade",
+	}
+}
+
+// postUpgradeCommand returns an entrypoint that updates extensions in every
+// database that allows connections and then rebuilds statistics in all of
+// them. The databases are listed and processed inside the Job, so there can be
+// any number of them. Those that could not be processed are written to the
+// container's termination message.
+// - https://www.postgresql.org/docs/current/pgupgrade.html
+func postUpgradeCommand(upgrade *v1beta1.PGUpgrade) []string {
+	args := []string{fmt.Sprint(upgrade.Spec.ToPostgresVersion)}
+	lines := []string{
+		`declare -r new_version="$1"`,
+		`export PATH="/usr/pgsql-${new_version}/bin:${PATH}"`,
+		`failed=()`,
+	}
+
+	if postUpgradeUpdateExtensions(upgrade) {
+		lines = append(lines,
+			`while IFS= read -r database; do`,
+			`  echo "Updating extensions in ${database}..."`,
+			`  PGDATABASE="${database}" psql --no-psqlrc --quiet --set=ON_ERROR_STOP=1 --echo-queries <<'SQL' || failed+=("${database}")`,
+			`SELECT pg_catalog.format('ALTER EXTENSION %I UPDATE', e.extname)`,
+			`  FROM pg_catalog.pg_extension e`,
+			`  JOIN pg_catalog.pg_available_extensions a ON a.name = e.extname`,
+			` WHERE e.extversion IS DISTINCT FROM a.default_version`,
+			`\gexec`,
+			`SQL`,
+			`done < <(psql --no-psqlrc --quiet --tuples-only --no-align \`,
+			`  --command='SELECT datname FROM pg_catalog.pg_database WHERE datallowconn ORDER BY datname')`,
+		)
+	}
+
+	// Generate minimal statistics quickly in every database, then generate
+	// the rest.
+	if postUpgradeAnalyze(upgrade) {
+		lines = append(lines,
+			`vacuumdb --all --analyze-in-stages || failed+=('(statistics)')`,
+		)
+	}
+
+	// The termination message is limited to 4096 bytes; the logs have the rest.
+	lines = append(lines,
+		`if [[ "${#failed[@]}" -gt 0 ]]; then`,
+		`  (IFS=','; echo "${failed[*]}") | head -c 4000 | tee /dev/termination-log`,
+		`  exit 1`,
+		`fi`,
+	)
+
+	script := strings.Join(lines, "\n")
+	return append([]string{"bash", "-ceu", "--", script, "postupgrade"}, args...)
+}
+
+// generatePostUpgradeJob returns a Job that runs command as the upgrade user
+// against the primary of cluster.
+func (r *PGUpgradeReconciler) generatePostUpgradeJob(
+	upgrade *v1beta1.PGUpgrade, cluster *v1beta1.PostgresCluster,
+	objectMeta metav1.ObjectMeta, role string, command []string,
+) *batchv1.Job {
+	job := &batchv1.Job{ObjectMeta: objectMeta}
+	job.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("Job"))
+
+	job.Annotations = upgrade.Spec.Metadata.GetAnnotationsOrNil()
+	job.Labels = Merge(upgrade.Spec.Metadata.GetLabelsOrNil(),
+		commonLabels(role, upgrade),
+		map[string]string{
+			LabelVersion: fmt.Sprint(upgrade.Spec.ToPostgresVersion),
+		})
+
+	service := naming.ClusterPrimaryService(cluster)
+	secret := naming.PostUpgradeSecret(cluster)
+
+	const certDirectory = "/pgconf/tls"
+	container := corev1.Container{
+		Name:            ContainerDatabase,
+		Command:         servicemesh.JobCommand(cluster, command),
+		Image:           pgUpgradeContainerImage(upgrade),
+		ImagePullPolicy: upgrade.Spec.ImagePullPolicy,
+		Resources:       upgrade.Spec.Resources,
+		SecurityContext: initialize.RestrictedSecurityContext(),
+		Env: []corev1.EnvVar{
+			{Name: "PGHOST", Value: fmt.Sprintf("%s.%s.svc", service.Name, service.Namespace)},
+			{Name: "PGPORT", Value: fmt.Sprint(*cluster.Spec.Port)},
+			{Name: "PGUSER", Value: postupgrade.User},
+			{Name: "PGSSLMODE", Value: "verify-ca"},
+			{Name: "PGSSLCERT", Value: certDirectory + "/" + postupgrade.CertFile},
+			{Name: "PGSSLKEY", Value: certDirectory + "/" + postupgrade.KeyFile},
+			{Name: "PGSSLROOTCERT", Value: certDirectory + "/" + postupgrade.CAFile},
+		},
+		TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+		VolumeMounts: []corev1.VolumeMount{{
+			Name: naming.CertVolume, MountPath: certDirectory, ReadOnly: true,
+		}},
+	}
+
+	job.Spec.Template = corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: Merge(job.Annotations, servicemesh.JobAnnotations(cluster)),
+			Labels:      job.Labels,
+		},
+		Spec: corev1.PodSpec{
+			Affinity:          upgrade.Spec.Affinity,
+			Containers:        []corev1.Container{container},
+			ImagePullSecrets:  upgrade.Spec.ImagePullSecrets,
+			PriorityClassName: initialize.FromPointer(upgrade.Spec.PriorityClassName),
+			RestartPolicy:     corev1.RestartPolicyNever,
+			SecurityContext:   postgres.PodSecurityContext(cluster),
+			Tolerations:       upgrade.Spec.Tolerations,
+
+			// These Jobs don't make Kubernetes API calls, so we can just
+			// use the default ServiceAccount and not mount its credentials.
+			AutomountServiceAccountToken: initialize.Bool(false),
+			EnableServiceLinks:           initialize.Bool(false),
+			Volumes: []corev1.Volume{{
+				Name: naming.CertVolume,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: secret.Name,
+						// The client key must not be readable by others.
+						// - https://www.postgresql.org/docs/current/libpq-ssl.html
+						DefaultMode: initialize.Int32(0o600),
+					},
+				},
+			}},
+		},
+	}
+
+	// PostgreSQL may still be starting, so retry with the default backoff.
+	job.Spec.BackoffLimit = initialize.Int32(6)
+	withJobLimits(cluster, job)
+
+	r.setControllerReference(upgrade, job)
+	return job
+}
+
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters/status",verbs={patch}
+
+// releaseUpgradeUser records in the status of cluster that upgrade no longer
+// needs the upgrade user. PostgresCluster then removes the user, its HBA rules,
+// and its client certificate.
+func (r *PGUpgradeReconciler) releaseUpgradeUser(
+	ctx context.Context, upgrade *v1beta1.PGUpgrade, cluster *v1beta1.PostgresCluster,
+) error {
+	if cluster.GetAnnotations()[AnnotationAllowUpgrade] != upgrade.Name {
+		return nil
+	}
+	if status := cluster.Status.UpgradeUser; status != nil && status.Completed == upgrade.Name {
+		return nil
+	}
+
+	patch := cluster.DeepCopy()
+	if patch.Status.UpgradeUser == nil {
+		patch.Status.UpgradeUser = new(v1beta1.UpgradeUserStatus)
+	}
+	patch.Status.UpgradeUser.Completed = upgrade.Name
+
+	return errors.WithStack(r.Status().Patch(ctx, patch, client.MergeFrom(cluster), r.Owner))
+}
+
+// reconcilePostUpgrade updates extensions and rebuilds statistics in every
+// database of an upgraded cluster using one Job. It waits for the cluster to
+// start the new version of PostgreSQL with the upgrade user in place. Progress
+// is reported in the PostUpgradeCompleted condition.
+func (r *PGUpgradeReconciler) reconcilePostUpgrade(
+	ctx context.Context, upgrade *v1beta1.PGUpgrade, world *World,
+) error {
+	// Remove the upgrade user once there is no more work for it.
+	if !postUpgradeAnalyze(upgrade) && !postUpgradeUpdateExtensions(upgrade) {
+		return r.releaseUpgradeUser(ctx, upgrade, world.Cluster)
+	}
+	if done := meta.FindStatusCondition(upgrade.Status.Conditions,
+		ConditionPGUpgradePostUpgradeCompleted); done != nil &&
+		done.Reason != "PGUpgradePostUpgradeWaiting" &&
+		done.Reason != "PGUpgradePostUpgradeRunning" {
+		return r.releaseUpgradeUser(ctx, upgrade, world.Cluster)
+	}
+
+	if cluster := world.Cluster; cluster.Spec.PostgresVersion != upgrade.Spec.ToPostgresVersion ||
+		cluster.Spec.Shutdown != nil && *cluster.Spec.Shutdown ||
+		cluster.Status.UpgradeUser == nil || cluster.Status.UpgradeUser.Revision == "" {
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradePostUpgradeCompleted,
+			Status:             metav1.ConditionFalse,
+			Reason:             "PGUpgradePostUpgradeWaiting",
+			Message: fmt.Sprintf(
+				"Waiting for PostgresCluster %s to run version %d with annotation %s",
+				upgrade.Spec.PostgresClusterName, upgrade.Spec.ToPostgresVersion,
+				AnnotationAllowUpgrade),
+		})
+		return nil
+	}
+
+	var err error
+	var message string
+	job := world.Jobs[pgUpgradePostUpgradeJob(upgrade).Name]
+
+	if job == nil || !(jobCompleted(job) || jobFailed(job)) {
+		err = errors.WithStack(r.apply(ctx,
+			r.generatePostUpgradeJob(upgrade, world.Cluster,
+				pgUpgradePostUpgradeJob(upgrade), postUpgrade,
+				postUpgradeCommand(upgrade))))
+	} else if jobFailed(job) {
+		message, err = r.terminationMessage(ctx, upgrade, postUpgrade)
+	}
+
+	if err == nil {
+		setPostUpgradeCompleted(upgrade, job, message)
+	}
+	return err
+}
+
+// setPostUpgradeCompleted sets the PostUpgradeCompleted condition of upgrade
+// according to job. When job failed, message lists the databases that could
+// not be processed.
+func setPostUpgradeCompleted(upgrade *v1beta1.PGUpgrade, job *batchv1.Job, message string) {
+	condition := metav1.Condition{
+		ObservedGeneration: upgrade.Generation,
+		Type:               ConditionPGUpgradePostUpgradeCompleted,
+	}
+	switch {
+	case job == nil || !(jobCompleted(job) || jobFailed(job)):
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "PGUpgradePostUpgradeRunning"
+		condition.Message = fmt.Sprintf("Processing databases in Job %s", pgUpgradePostUpgradeJob(upgrade).Name)
+	case jobFailed(job) && strings.TrimSpace(message) != "":
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "PGUpgradePostUpgradeFailed"
+		condition.Message = fmt.Sprintf("Unable to process databases: %s",
+			strings.TrimSpace(message))
+	case jobFailed(job):
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "PGUpgradePostUpgradeFailed"
+		condition.Message = fmt.Sprintf("Unable to process databases; see the logs of Job %s", job.Name)
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "PGUpgradePostUpgradeSucceeded"
+		condition.Message = "Processed every database"
+	}
+
+	meta.SetStatusCondition(&upgrade.Status.Conditions, condition)
+}
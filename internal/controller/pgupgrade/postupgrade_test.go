@@ -0,0 +1,140 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgupgrade
+
+import (
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestPostUpgradeCommand(t *testing.T) {
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Spec.ToPostgresVersion = 16
+
+	command := postUpgradeCommand(upgrade)
+	assert.DeepEqual(t, command[len(command)-2:], []string{"postupgrade", "16"})
+	assert.Assert(t, strings.Contains(command[3], `ALTER EXTENSION %I UPDATE`))
+	assert.Assert(t, strings.Contains(command[3], `vacuumdb --all --analyze-in-stages`))
+	assert.Assert(t, strings.Contains(command[3], `WHERE datallowconn ORDER BY datname`),
+		"expected databases to be listed inside the Job")
+
+	upgrade.Spec.PostUpgrade = &v1beta1.PGUpgradePostUpgradeSpec{
+		UpdateExtensions: initialize.Bool(false),
+	}
+	command = postUpgradeCommand(upgrade)
+	assert.Assert(t, !strings.Contains(command[3], `ALTER EXTENSION`))
+	assert.Assert(t, strings.Contains(command[3], `vacuumdb --all --analyze-in-stages`))
+
+	upgrade.Spec.PostUpgrade = &v1beta1.PGUpgradePostUpgradeSpec{
+		Analyze: initialize.Bool(false),
+	}
+	command = postUpgradeCommand(upgrade)
+	assert.Assert(t, strings.Contains(command[3], `ALTER EXTENSION %I UPDATE`))
+	assert.Assert(t, !strings.Contains(command[3], `vacuumdb`))
+}
+
+func TestGeneratePostUpgradeJob(t *testing.T) {
+	reconciler := &PGUpgradeReconciler{}
+
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Namespace = "ns1"
+	upgrade.Name = "pgu2"
+	upgrade.Spec.Image = initialize.Pointer("img4")
+	upgrade.Spec.PostgresClusterName = "pg5"
+	upgrade.Spec.ToPostgresVersion = 16
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace = "ns1"
+	cluster.Name = "pg5"
+	cluster.Spec.Port = initialize.Int32(5432)
+
+	job := reconciler.generatePostUpgradeJob(upgrade, cluster,
+		pgUpgradePostUpgradeJob(upgrade), postUpgrade,
+		postUpgradeCommand(upgrade))
+
+	assert.Equal(t, job.Name, "pgu2-postupgrade")
+	assert.DeepEqual(t, job.Spec.Template.Labels, map[string]string{
+		LabelPGUpgrade: "pgu2",
+		LabelCluster:   "pg5",
+		LabelRole:      "postupgrade",
+		LabelVersion:   "16",
+	})
+
+	pod := job.Spec.Template.Spec
+	assert.Equal(t, pod.Containers[0].Image, "img4")
+	assert.Assert(t, marshalMatches(pod.Containers[0].Env, `
+- name: PGHOST
+  value: pg5-primary.ns1.svc
+- name: PGPORT
+  value: "5432"
+- name: PGUSER
+  value: _crunchyupgrade
+- name: PGSSLMODE
+  value: verify-ca
+- name: PGSSLCERT
+  value: /pgconf/tls/tls.crt
+- name: PGSSLKEY
+  value: /pgconf/tls/tls.key
+- name: PGSSLROOTCERT
+  value: /pgconf/tls/ca.crt
+	`))
+	assert.Equal(t, pod.Volumes[0].Secret.SecretName, "pg5-upgrade-cert")
+	assert.Equal(t, *pod.Volumes[0].Secret.DefaultMode, int32(0o600))
+}
+
+func TestSetPostUpgradeCompleted(t *testing.T) {
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Name = "pgu2"
+
+	job := &batchv1.Job{}
+	job.Name = "pgu2-postupgrade"
+
+	setPostUpgradeCompleted(upgrade, job, "")
+	condition := meta.FindStatusCondition(upgrade.Status.Conditions,
+		ConditionPGUpgradePostUpgradeCompleted)
+	assert.Equal(t, condition.Status, metav1.ConditionFalse)
+	assert.Equal(t, condition.Reason, "PGUpgradePostUpgradeRunning")
+
+	job.Status.Conditions = []batchv1.JobCondition{{
+		Type: batchv1.JobFailed, Status: corev1.ConditionTrue,
+	}}
+	setPostUpgradeCompleted(upgrade, job, "postgres,app\n")
+	condition = meta.FindStatusCondition(upgrade.Status.Conditions,
+		ConditionPGUpgradePostUpgradeCompleted)
+	assert.Equal(t, condition.Status, metav1.ConditionFalse)
+	assert.Equal(t, condition.Reason, "PGUpgradePostUpgradeFailed")
+	assert.Equal(t, condition.Message, "Unable to process databases: postgres,app")
+
+	setPostUpgradeCompleted(upgrade, job, "")
+	condition = meta.FindStatusCondition(upgrade.Status.Conditions,
+		ConditionPGUpgradePostUpgradeCompleted)
+	assert.Equal(t, condition.Message, "Unable to process databases; see the logs of Job pgu2-postupgrade")
+
+	job.Status.Conditions[0].Type = batchv1.JobComplete
+	setPostUpgradeCompleted(upgrade, job, "")
+	condition = meta.FindStatusCondition(upgrade.Status.Conditions,
+		ConditionPGUpgradePostUpgradeCompleted)
+	assert.Equal(t, condition.Status, metav1.ConditionTrue)
+	assert.Equal(t, condition.Reason, "PGUpgradePostUpgradeSucceeded")
+}
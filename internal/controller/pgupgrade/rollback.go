@@ -0,0 +1,246 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgupgrade
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// setRollbackStatus describes what would be lost by rolling back upgrade. The
+// old data directory can be used until the upgraded cluster starts. After that,
+// only a backup taken before the upgrade has the old version of the data.
+func setRollbackStatus(upgrade *v1beta1.PGUpgrade, world *World) {
+	job := world.Jobs[pgUpgradeJob(upgrade).Name]
+	if world.Cluster == nil || job == nil || !(jobCompleted(job) || jobFailed(job)) {
+		return
+	}
+
+	// Once the cluster has started, the old data directory stays unusable.
+	cluster := world.Cluster
+	started := upgrade.Status.Rollback != nil && !upgrade.Status.Rollback.Available
+	started = started || jobCompleted(job) &&
+		cluster.Spec.PostgresVersion == upgrade.Spec.ToPostgresVersion &&
+		(cluster.Spec.Shutdown == nil || !*cluster.Spec.Shutdown)
+
+	if !started {
+		upgrade.Status.Rollback = &v1beta1.PGUpgradeRollbackStatus{
+			Available: true,
+			DataLoss:  "None. The old data directory is used again and the new one is removed.",
+		}
+		return
+	}
+
+	since := "the upgrade"
+	if job.Status.StartTime != nil {
+		since = job.Status.StartTime.UTC().Format(time.RFC3339)
+	}
+	upgrade.Status.Rollback = &v1beta1.PGUpgradeRollbackStatus{
+		Available: false,
+		DataLoss: fmt.Sprintf(
+			"PostgreSQL %d has started. Rolling back requires restoring a backup taken before %s; changes after that are lost.",
+			upgrade.Spec.ToPostgresVersion, since),
+	}
+}
+
+// reconcileRollback returns the PostgresCluster of upgrade to the major version
+// it had before the upgrade while the old data directory can still be used.
+func (r *PGUpgradeReconciler) reconcileRollback(
+	ctx context.Context, upgrade *v1beta1.PGUpgrade,
+) (ctrl.Result, error) {
+	progressing := func(reason, message string) {
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeProgressing,
+			Status:             metav1.ConditionFalse,
+			Reason:             reason,
+			Message:            message,
+		})
+	}
+
+	world, err := r.observeWorld(ctx, upgrade)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if world.ClusterNotFound != nil {
+		progressing("PGClusterNotFound", world.ClusterNotFound.Error())
+		return ctrl.Result{}, nil
+	}
+
+	if upgrade.Spec.Method == methodLogicalReplication {
+		return r.rollbackLogicalReplication(ctx, upgrade, world.Cluster)
+	}
+
+	setRollbackStatus(upgrade, world)
+
+	upgradeJob := world.Jobs[pgUpgradeJob(upgrade).Name]
+	rollbackJob := world.Jobs[pgUpgradeRollbackJob(upgrade).Name]
+
+	// Nothing has changed when there is no upgrade Job.
+	if upgradeJob == nil {
+		progressing("PGUpgradeRolledBack", "Nothing was upgraded")
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeSucceeded,
+			Status:             metav1.ConditionFalse,
+			Reason:             "PGUpgradeRolledBack",
+			Message:            "Nothing was upgraded",
+		})
+		return ctrl.Result{}, nil
+	}
+
+	if !(jobCompleted(upgradeJob) || jobFailed(upgradeJob)) {
+		progressing("PGUpgradeRollbackWaiting",
+			fmt.Sprintf("Waiting for Job %s to finish", upgradeJob.Name))
+		return ctrl.Result{}, nil
+	}
+
+	if rollbackJob != nil && jobCompleted(rollbackJob) {
+		// Report the old version so that the upgrade is not mistaken as done.
+		if world.Cluster.Status.PostgresVersion != upgrade.Spec.FromPostgresVersion {
+			patch := world.Cluster.DeepCopy()
+			patch.Status.PostgresVersion = upgrade.Spec.FromPostgresVersion
+			err = r.Status().Patch(ctx, patch, client.MergeFrom(world.Cluster), r.Owner)
+		}
+		if err == nil {
+			message := fmt.Sprintf(
+				"PostgresCluster %s can start version %d again",
+				upgrade.Spec.PostgresClusterName, upgrade.Spec.FromPostgresVersion)
+
+			progressing("PGUpgradeRolledBack", message)
+			meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+				ObservedGeneration: upgrade.Generation,
+				Type:               ConditionPGUpgradeSucceeded,
+				Status:             metav1.ConditionFalse,
+				Reason:             "PGUpgradeRolledBack",
+				Message:            message,
+			})
+		}
+		return ctrl.Result{}, err
+	}
+
+	if rollbackJob != nil && jobFailed(rollbackJob) {
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeSucceeded,
+			Status:             metav1.ConditionFalse,
+			Reason:             "PGUpgradeRollbackFailed",
+			Message:            fmt.Sprintf("Rollback failed; see the logs of Job %s", rollbackJob.Name),
+		})
+		return ctrl.Result{}, nil
+	}
+
+	if upgrade.Status.Rollback != nil && !upgrade.Status.Rollback.Available {
+		progressing("PGUpgradeRollbackUnavailable", upgrade.Status.Rollback.DataLoss)
+		return ctrl.Result{}, nil
+	}
+
+	// The rollback has the same requirements as the upgrade.
+	if !world.ClusterShutdown {
+		progressing("PGClusterNotShutdown", "PostgresCluster instances still running")
+		return ctrl.Result{}, nil
+	}
+	if world.ClusterPrimary == nil {
+		progressing("PGClusterPrimaryNotIdentified", "PostgresCluster primary instance not identified")
+		return ctrl.Result{}, nil
+	}
+	if world.Cluster.GetAnnotations()[AnnotationAllowUpgrade] != upgrade.Name {
+		progressing("PGClusterMissingRequiredAnnotation", fmt.Sprintf(
+			"PostgresCluster %s lacks annotation for upgrade %s",
+			upgrade.Spec.PostgresClusterName, upgrade.GetName()))
+		return ctrl.Result{}, nil
+	}
+
+	meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+		ObservedGeneration: upgrade.Generation,
+		Type:               ConditionPGUpgradeProgressing,
+		Status:             metav1.ConditionTrue,
+		Reason:             "PGUpgradeRollingBack",
+		Message: fmt.Sprintf("Returning PostgresCluster %s to version %d",
+			upgrade.Spec.PostgresClusterName, upgrade.Spec.FromPostgresVersion),
+	})
+
+	err = errors.WithStack(r.apply(ctx,
+		withJobLimits(world.Cluster, withNoProxy(world.Cluster, r.generateRollbackJob(ctx, upgrade, world.ClusterPrimary)))))
+
+	// The old data directory has the old system identifier. Clear any other
+	// identifier from Patroni by deleting its DCS Endpoints.
+	if err == nil && len(world.PatroniEndpoints) > 0 {
+		for _, object := range world.PatroniEndpoints {
+			uid := object.GetUID()
+			version := object.GetResourceVersion()
+			exactly := client.Preconditions{UID: &uid, ResourceVersion: &version}
+			err = client.IgnoreNotFound(r.Client.Delete(ctx, object, exactly))
+		}
+
+		// Requeue to verify that Patroni endpoints are deleted
+		return ctrl.Result{Requeue: true}, err
+	}
+
+	return ctrl.Result{}, err
+}
+
+// rollbackLogicalReplication puts back the PgBouncer databases of the old
+// cluster that cutover pointed at the new cluster. The new cluster remains.
+func (r *PGUpgradeReconciler) rollbackLogicalReplication(
+	ctx context.Context, upgrade *v1beta1.PGUpgrade, source *v1beta1.PostgresCluster,
+) (ctrl.Result, error) {
+	var err error
+	message := fmt.Sprintf("PostgresCluster %s was not changed", source.Name)
+
+	status := upgrade.Status.LogicalReplication
+	if status != nil && status.Phase != phaseProvisioning && status.Phase != phaseReplicating {
+		message = fmt.Sprintf(
+			"PgBouncer of PostgresCluster %s connects to it again; its databases stay read-only until default_transaction_read_only is reset",
+			source.Name)
+	}
+
+	if status != nil && status.PGBouncerRerouted &&
+		source.Spec.Proxy != nil && source.Spec.Proxy.PGBouncer != nil {
+		patch := source.DeepCopy()
+		patch.Spec.Proxy.PGBouncer.Config.Databases = status.PreviousPGBouncerDatabases
+		err = errors.WithStack(r.Patch(ctx, patch, client.MergeFrom(source), r.Owner))
+		if err == nil {
+			status.PGBouncerRerouted = false
+		}
+	}
+
+	if err == nil {
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeProgressing,
+			Status:             metav1.ConditionFalse,
+			Reason:             "PGUpgradeRolledBack",
+			Message:            message,
+		})
+		meta.SetStatusCondition(&upgrade.Status.Conditions, metav1.Condition{
+			ObservedGeneration: upgrade.Generation,
+			Type:               ConditionPGUpgradeSucceeded,
+			Status:             metav1.ConditionFalse,
+			Reason:             "PGUpgradeRolledBack",
+			Message:            message,
+		})
+	}
+	return ctrl.Result{}, err
+}
@@ -0,0 +1,76 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgupgrade
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestSetRollbackStatus(t *testing.T) {
+	upgrade := &v1beta1.PGUpgrade{}
+	upgrade.Name = "pgu2"
+	upgrade.Spec.FromPostgresVersion = 14
+	upgrade.Spec.ToPostgresVersion = 16
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Spec.PostgresVersion = 14
+	cluster.Spec.Shutdown = initialize.Bool(true)
+
+	world := NewWorld()
+	world.Cluster = cluster
+
+	// Nothing to describe before the upgrade Job finishes.
+	setRollbackStatus(upgrade, world)
+	assert.Assert(t, upgrade.Status.Rollback == nil)
+
+	started := metav1.NewTime(time.Date(2024, 5, 6, 7, 8, 9, 0, time.UTC))
+	job := &batchv1.Job{}
+	job.Status.StartTime = &started
+	job.Status.Conditions = []batchv1.JobCondition{{
+		Type: batchv1.JobComplete, Status: corev1.ConditionTrue,
+	}}
+	world.Jobs[pgUpgradeJob(upgrade).Name] = job
+
+	setRollbackStatus(upgrade, world)
+	assert.Assert(t, upgrade.Status.Rollback.Available)
+	assert.Assert(t, strings.HasPrefix(upgrade.Status.Rollback.DataLoss, "None."))
+
+	// The cluster has the new version but has not started.
+	cluster.Spec.PostgresVersion = 16
+	setRollbackStatus(upgrade, world)
+	assert.Assert(t, upgrade.Status.Rollback.Available)
+
+	// The cluster has started.
+	cluster.Spec.Shutdown = nil
+	setRollbackStatus(upgrade, world)
+	assert.Assert(t, !upgrade.Status.Rollback.Available)
+	assert.Assert(t, strings.Contains(upgrade.Status.Rollback.DataLoss,
+		"backup taken before 2024-05-06T07:08:09Z"))
+
+	// Shutting down again does not make the old data directory usable.
+	cluster.Spec.Shutdown = initialize.Bool(true)
+	setRollbackStatus(upgrade, world)
+	assert.Assert(t, !upgrade.Status.Rollback.Available)
+}
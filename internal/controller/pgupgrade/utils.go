@@ -16,6 +16,7 @@ package pgupgrade
 
 import (
 	"os"
+	"strings"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
@@ -72,3 +73,22 @@ func defaultFromEnv(value, key string) string {
 	}
 	return value
 }
+
+// changeProtected returns whether or not cluster has taken the backup it needs
+// before upgrade. Clusters without change protection need no backup.
+func changeProtected(cluster *v1beta1.PostgresCluster, upgrade *v1beta1.PGUpgrade) bool {
+	if cluster.Spec.ChangeProtection != v1beta1.ChangeProtectionSnapshotBeforeRestart {
+		return true
+	}
+	if cluster.Status.ChangeProtection == nil {
+		return false
+	}
+	for _, protected := range cluster.Status.ChangeProtection.History {
+		for _, change := range strings.Split(protected.Changes, ", ") {
+			if change == "upgrade "+upgrade.GetName() {
+				return true
+			}
+		}
+	}
+	return false
+}
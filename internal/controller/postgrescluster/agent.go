@@ -0,0 +1,92 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"path"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/agent"
+	"github.com/crunchydata/postgres-operator/internal/config"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/util"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	// agentDirectory is where the agent is copied in instance Pods.
+	agentDirectory = "/pgagent"
+
+	// agentVolume is the name of the volume that holds the agent.
+	agentVolume = "agent"
+)
+
+// addAgentToInstancePodSpec copies the agent into the database container of
+// pod and starts it alongside Patroni when the ExecAgent feature is enabled.
+// The agent presents the certificate of cluster and trusts the root certificate
+// authority, so clusters with a custom TLS certificate continue to use the
+// "pods/exec" subresource.
+func addAgentToInstancePodSpec(cluster *v1beta1.PostgresCluster, pod *corev1.PodSpec) {
+	image := config.AgentContainerImage()
+	if image == "" || cluster.Spec.CustomTLSSecret != nil ||
+		!util.DefaultMutableFeatureGate.Enabled(util.ExecAgent) {
+		return
+	}
+
+	pod.Volumes = append(pod.Volumes, corev1.Volume{
+		Name:         agentVolume,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	})
+	mount := corev1.VolumeMount{Name: agentVolume, MountPath: agentDirectory}
+
+	pod.InitContainers = append(pod.InitContainers, corev1.Container{
+		Name:            naming.ContainerAgentInstall,
+		Command:         []string{"cp", "/usr/local/bin/postgres-operator", agentDirectory},
+		Image:           image,
+		ImagePullPolicy: cluster.Spec.ImagePullPolicy,
+		SecurityContext: initialize.RestrictedSecurityContext(),
+		VolumeMounts:    []corev1.VolumeMount{mount},
+	})
+
+	for i := range pod.Containers {
+		container := &pod.Containers[i]
+		if container.Name != naming.ContainerDatabase {
+			continue
+		}
+
+		mount.ReadOnly = true
+		container.VolumeMounts = append(container.VolumeMounts, mount)
+		container.Ports = append(container.Ports, corev1.ContainerPort{
+			Name:          naming.PortAgent,
+			ContainerPort: agent.DefaultPort,
+			Protocol:      corev1.ProtocolTCP,
+		})
+		container.Env = append(container.Env,
+			corev1.EnvVar{Name: "PGO_AGENT_ADDRESS", Value: ":" + strconv.Itoa(int(agent.DefaultPort))},
+			corev1.EnvVar{Name: "PGO_AGENT_CA_FILE", Value: path.Join(naming.CertMountPath, rootCertFile)},
+			corev1.EnvVar{Name: "PGO_AGENT_CERT_FILE", Value: path.Join(naming.CertMountPath, clusterCertFile)},
+			corev1.EnvVar{Name: "PGO_AGENT_KEY_FILE", Value: path.Join(naming.CertMountPath, clusterKeyFile)},
+		)
+
+		// Start the agent in the background then replace the shell with the
+		// original command so that it receives signals.
+		container.Command = append([]string{"bash", "-c",
+			path.Join(agentDirectory, "postgres-operator") + ` agent & exec "$@"`,
+			"agent"}, container.Command...)
+	}
+}
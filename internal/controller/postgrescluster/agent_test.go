@@ -0,0 +1,110 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/internal/util"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestAddAgentToInstancePodSpec(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	pod := corev1.PodSpec{Containers: []corev1.Container{
+		{Name: naming.ContainerDatabase, Command: []string{"patroni", "/etc/patroni"}},
+		{Name: naming.PGBackRestRepoContainerName},
+	}}
+
+	assert.NilError(t, util.AddAndSetFeatureGates(""))
+
+	t.Run("Disabled", func(t *testing.T) {
+		t.Setenv("PGO_AGENT_IMAGE", "pgo:1")
+
+		out := pod.DeepCopy()
+		addAgentToInstancePodSpec(cluster, out)
+		assert.DeepEqual(t, out, pod.DeepCopy())
+	})
+
+	assert.NilError(t, util.AddAndSetFeatureGates(string(util.ExecAgent+"=true")))
+	t.Cleanup(func() {
+		assert.NilError(t, util.AddAndSetFeatureGates(string(util.ExecAgent+"=false")))
+	})
+
+	t.Run("NoImage", func(t *testing.T) {
+		t.Setenv("PGO_AGENT_IMAGE", "")
+
+		out := pod.DeepCopy()
+		addAgentToInstancePodSpec(cluster, out)
+		assert.DeepEqual(t, out, pod.DeepCopy())
+	})
+
+	t.Run("CustomTLS", func(t *testing.T) {
+		t.Setenv("PGO_AGENT_IMAGE", "pgo:1")
+
+		cluster := cluster.DeepCopy()
+		cluster.Spec.CustomTLSSecret = &corev1.SecretProjection{}
+
+		out := pod.DeepCopy()
+		addAgentToInstancePodSpec(cluster, out)
+		assert.DeepEqual(t, out, pod.DeepCopy())
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		t.Setenv("PGO_AGENT_IMAGE", "pgo:1")
+
+		out := pod.DeepCopy()
+		addAgentToInstancePodSpec(cluster, out)
+
+		assert.Assert(t, cmp.MarshalMatches(out.InitContainers, `
+- command:
+  - cp
+  - /usr/local/bin/postgres-operator
+  - /pgagent
+  image: pgo:1
+  name: agent-install
+  resources: {}
+  securityContext:
+    allowPrivilegeEscalation: false
+    capabilities:
+      drop:
+      - ALL
+    privileged: false
+    readOnlyRootFilesystem: true
+    runAsNonRoot: true
+  volumeMounts:
+  - mountPath: /pgagent
+    name: agent
+		`))
+
+		database := out.Containers[0]
+		assert.DeepEqual(t, database.Command, []string{
+			"bash", "-c", `/pgagent/postgres-operator agent & exec "$@"`, "agent",
+			"patroni", "/etc/patroni",
+		})
+		assert.Assert(t, cmp.MarshalMatches(database.Ports, `
+- containerPort: 8433
+  name: agent
+  protocol: TCP
+		`))
+		assert.Equal(t, len(database.Env), 4)
+		assert.DeepEqual(t, out.Containers[1], pod.Containers[1])
+	})
+}
@@ -0,0 +1,126 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/amcheck"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// integrityChecksJob reports the outcome of pg_amcheck in the
+// IntegrityChecked condition.
+var integrityChecksJob = scheduledJob{
+	name:      "amcheck",
+	label:     naming.LabelIntegrityChecks,
+	condition: v1beta1.IntegrityChecked,
+
+	succeededReason:  "NoCorruption",
+	succeededMessage: "pg_amcheck found no corruption",
+
+	failedReason:  "CheckFailed",
+	failedMessage: "pg_amcheck reported corruption or could not finish; see the logs of Job %q",
+}
+
+// +kubebuilder:rbac:groups="batch",resources="cronjobs",verbs={get,create,patch,delete}
+// +kubebuilder:rbac:groups="batch",resources="jobs",verbs={list}
+
+// reconcileIntegrityChecks installs the amcheck extension and schedules a
+// CronJob that checks tables and indexes for corruption as the maintenance
+// user. The outcome of the latest Job is reported in the IntegrityChecked
+// condition.
+func (r *Reconciler) reconcileIntegrityChecks(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+	secret *corev1.Secret,
+) error {
+	enabled := amcheck.Enabled(cluster)
+
+	if cluster.Spec.IntegrityChecks != nil && !enabled {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "IntegrityChecksUnsupported",
+			"Integrity checks require PostgreSQL 14 or later, not %d",
+			cluster.Spec.PostgresVersion)
+	}
+
+	// Change PostgreSQL only when something was installed or is requested.
+	status := cluster.Status.IntegrityChecks
+	if status != nil || enabled {
+		if status == nil {
+			status = new(v1beta1.IntegrityChecksStatus)
+		}
+
+		action := func(ctx context.Context, exec postgres.Executor) error {
+			if enabled {
+				return amcheck.EnableInPostgreSQL(ctx, exec)
+			}
+			return amcheck.DisableInPostgreSQL(ctx, exec)
+		}
+
+		current, err := r.reconcileSQLRevision(ctx, instances, &status.Revision, action)
+		if err != nil {
+			return err
+		}
+
+		cluster.Status.IntegrityChecks = status
+		if current && !enabled {
+			cluster.Status.IntegrityChecks = nil
+		}
+	}
+
+	// Wait for the amcheck extension to exist before scheduling any checks.
+	cronjob := &batchv1.CronJob{ObjectMeta: naming.IntegrityChecksCronJob(cluster)}
+	finished, err := r.reconcileScheduledJob(ctx, cluster, integrityChecksJob, cronjob,
+		enabled, status != nil && status.Revision != "",
+		func(cronjob *batchv1.CronJob) { generateIntegrityChecksCronJob(cluster, secret, cronjob) })
+
+	if finished != nil {
+		cluster.Status.IntegrityChecks.LastCheckTime = finished
+	}
+	return err
+}
+
+// generateIntegrityChecksCronJob populates cronjob with a schedule that runs
+// pg_amcheck against the primary or a replica of cluster.
+func generateIntegrityChecksCronJob(
+	cluster *v1beta1.PostgresCluster, secret *corev1.Secret,
+	cronjob *batchv1.CronJob,
+) {
+	spec := cluster.Spec.IntegrityChecks
+
+	service := naming.ClusterPrimaryService(cluster)
+	if spec.Target == "Replica" {
+		service = naming.ClusterReplicaService(cluster)
+	}
+
+	// Suspend the CronJob when shutdown. pg_amcheck limits its own
+	// connections to PostgreSQL.
+	suspend := cluster.Spec.Shutdown != nil && *cluster.Spec.Shutdown
+
+	template := generateScheduledJob(cluster, integrityChecksJob, cronjob,
+		spec.Schedule, suspend, corev1.Container{
+			Command:   amcheck.Command(cluster),
+			Name:      naming.ContainerJobIntegrityChecks,
+			Resources: spec.Resources,
+		})
+
+	withMaintenanceUser(cluster, secret, service, template)
+	cronjob.Spec.JobTemplate.Spec.TTLSecondsAfterFinished = spec.TTLSecondsAfterFinished
+}
@@ -0,0 +1,105 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestGenerateIntegrityChecksCronJob(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Name = "hippo"
+	cluster.Namespace = "ns1"
+	cluster.Spec.Port = initialize.Int32(5432)
+	cluster.Spec.PostgresVersion = 15
+	cluster.Spec.Image = "some-image"
+	cluster.Spec.IntegrityChecks = &v1beta1.IntegrityChecksSpec{
+		Schedule:                "0 2 * * 0",
+		Connections:             initialize.Int32(2),
+		TTLSecondsAfterFinished: initialize.Int32(100),
+	}
+	secret := &corev1.Secret{ObjectMeta: naming.MaintenanceSecret(cluster)}
+
+	cronjob := &batchv1.CronJob{ObjectMeta: naming.IntegrityChecksCronJob(cluster)}
+	generateIntegrityChecksCronJob(cluster, secret, cronjob)
+
+	assert.Equal(t, cronjob.Spec.Schedule, "0 2 * * 0")
+	assert.Equal(t, cronjob.Spec.ConcurrencyPolicy, batchv1.ForbidConcurrent)
+	assert.Equal(t, *cronjob.Spec.Suspend, false)
+	assert.Equal(t, *cronjob.Spec.JobTemplate.Spec.BackoffLimit, int32(0))
+	assert.Equal(t, *cronjob.Spec.JobTemplate.Spec.TTLSecondsAfterFinished, int32(100))
+	assert.DeepEqual(t, cronjob.Labels, map[string]string{
+		naming.LabelCluster:         "hippo",
+		naming.LabelIntegrityChecks: "",
+	})
+
+	pod := cronjob.Spec.JobTemplate.Spec.Template.Spec
+	assert.Equal(t, pod.RestartPolicy, corev1.RestartPolicyNever)
+	assert.Equal(t, *pod.AutomountServiceAccountToken, false)
+	assert.Equal(t, pod.Volumes[0].Secret.SecretName, "hippo-maintenance-cert")
+	assert.Equal(t, *pod.Volumes[0].Secret.DefaultMode, int32(0o600))
+
+	container := pod.Containers[0]
+	assert.Equal(t, container.Image, "some-image")
+	assert.DeepEqual(t, container.Command, []string{"pg_amcheck", "--jobs=2", "--all"})
+	assert.Assert(t, cmp.MarshalMatches(container.Env, `
+- name: PGHOST
+  value: hippo-primary.ns1.svc
+- name: PGPORT
+  value: "5432"
+- name: PGDATABASE
+  value: postgres
+- name: PGUSER
+  value: _crunchymaintenance
+- name: PGSSLMODE
+  value: verify-ca
+- name: PGSSLCERT
+  value: /pgconf/tls/tls.crt
+- name: PGSSLKEY
+  value: /pgconf/tls/tls.key
+- name: PGSSLROOTCERT
+  value: /pgconf/tls/ca.crt
+	`))
+
+	t.Run("Replica", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.IntegrityChecks.Target = "Replica"
+
+		cronjob := &batchv1.CronJob{ObjectMeta: naming.IntegrityChecksCronJob(cluster)}
+		generateIntegrityChecksCronJob(cluster, secret, cronjob)
+
+		env := cronjob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Env
+		assert.Equal(t, env[0].Value, "hippo-replicas.ns1.svc")
+	})
+
+	t.Run("Shutdown", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Shutdown = initialize.Bool(true)
+
+		cronjob := &batchv1.CronJob{ObjectMeta: naming.IntegrityChecksCronJob(cluster)}
+		generateIntegrityChecksCronJob(cluster, secret, cronjob)
+		assert.Equal(t, *cronjob.Spec.Suspend, true)
+	})
+}
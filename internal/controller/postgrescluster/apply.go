@@ -69,6 +69,18 @@ func (r *Reconciler) apply(ctx context.Context, object client.Object) error {
 	if err == nil && !patch.IsEmpty() {
 		err = r.patch(ctx, object, patch)
 	}
+
+	// Fields set by merge patches, such as those of older versions, belong to
+	// a separate entry of the same field manager. Move them into the apply
+	// entry so they are removed when they are no longer applied.
+	if err == nil {
+		var upgrade *kubeapi.JSON6902
+		upgrade, err = kubeapi.UpgradeManagedFields(object, string(r.Owner))
+
+		if err == nil && upgrade != nil {
+			err = r.Client.Patch(ctx, object, upgrade)
+		}
+	}
 	return err
 }
 
@@ -156,6 +156,47 @@ func TestServerSideApply(t *testing.T) {
 		)
 	})
 
+	t.Run("MergePatchedFields", func(t *testing.T) {
+		reconciler := Reconciler{Client: cc, Owner: client.FieldOwner(t.Name())}
+		constructor := func() *corev1.ConfigMap {
+			var cm corev1.ConfigMap
+			cm.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("ConfigMap"))
+			cm.Namespace, cm.Name = ns.Name, "merge-patched"
+			cm.Data = map[string]string{"applied": "value"}
+			return &cm
+		}
+
+		// Create the object.
+		before := constructor()
+		assert.NilError(t, reconciler.apply(ctx, before))
+
+		// The same manager sets a field with a merge patch.
+		assert.NilError(t,
+			cc.Patch(ctx, before,
+				client.RawPatch(client.Merge.Type(), []byte(`{"data":{"merged":"value"}}`)),
+				reconciler.Owner))
+		assert.Equal(t, len(before.Data), 2)
+
+		// The first apply moves the merged field into the apply entry.
+		after := constructor()
+		assert.NilError(t, reconciler.apply(ctx, after))
+		assert.Equal(t, len(after.Data), 2)
+
+		var count int
+		for _, managed := range after.ManagedFields {
+			if managed.Manager == t.Name() {
+				count++
+				assert.Equal(t, managed.Operation, metav1.ManagedFieldsOperationApply)
+			}
+		}
+		assert.Equal(t, count, 1, "expected manager once in %v", after.ManagedFields)
+
+		// The next apply removes it.
+		again := constructor()
+		assert.NilError(t, reconciler.apply(ctx, again))
+		assert.DeepEqual(t, again.Data, map[string]string{"applied": "value"})
+	})
+
 	t.Run("StatefulSetStatus", func(t *testing.T) {
 		constructor := func(name string) *appsv1.StatefulSet {
 			var sts appsv1.StatefulSet
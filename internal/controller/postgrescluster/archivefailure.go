@@ -0,0 +1,163 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/pgbackrest"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// archiveBacklog is the WAL that the primary has yet to archive.
+type archiveBacklog struct {
+	// Bytes is the size of the WAL files waiting to be archived.
+	Bytes int64
+
+	// Segment is the size of one WAL file.
+	Segment int64
+
+	// Failing is true when the most recent attempt to archive failed.
+	Failing bool
+
+	// LastFailed is the WAL file that most recently failed to archive.
+	LastFailed string
+}
+
+// parseArchiveBacklog parses the output of the query in
+// [Reconciler.reconcileDiskProtection].
+func parseArchiveBacklog(output string) (archiveBacklog, error) {
+	var backlog archiveBacklog
+	fields := strings.Split(strings.TrimSpace(output), "|")
+	if len(fields) != 4 {
+		return backlog, errors.Errorf("unexpected archiver status: %q", output)
+	}
+
+	ready, err := strconv.ParseInt(fields[0], 10, 64)
+	if err == nil {
+		backlog.Segment, err = strconv.ParseInt(fields[1], 10, 64)
+	}
+	backlog.Bytes = ready * backlog.Segment
+	backlog.Failing = fields[2] == "t"
+	backlog.LastFailed = fields[3]
+	return backlog, errors.WithStack(err)
+}
+
+// reconcileDiskProtection compares the WAL that the primary of cluster has yet
+// to archive with what cluster is configured to keep. While archiving fails,
+// WAL accumulates on the primary until it is archived or, with the "Drop"
+// policy, until [pgbackrest.ArchiveQueueMax] is reached. The DiskProtectionEngaged
+// condition is true while pgBackRest drops WAL at that limit. WAL accumulates
+// without changing any Kubernetes objects, so this returns a result that checks
+// it again later.
+func (r *Reconciler) reconcileDiskProtection(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) reconcile.Result {
+	log := logging.FromContext(ctx)
+
+	// Nothing is archived until there is a stanza, and only the primary archives.
+	var stanzaCreated bool
+	if cluster.Status.PGBackRest != nil {
+		for _, repo := range cluster.Status.PGBackRest.Repos {
+			stanzaCreated = stanzaCreated || repo.StanzaCreated
+		}
+	}
+	pod, _ := instances.writablePod(naming.ContainerDatabase)
+	if !stanzaCreated || pod == nil {
+		return reconcile.Result{}
+	}
+	next := reconcile.Result{RequeueAfter: time.Minute}
+
+	// Each WAL file waiting to be archived has a ".ready" file in the archive
+	// status directory.
+	// - https://www.postgresql.org/docs/current/monitoring-stats.html#MONITORING-PG-STAT-ARCHIVER-VIEW
+	var stdout, stderr bytes.Buffer
+	err := r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase, nil, &stdout, &stderr,
+		"psql", "-Xw", "--tuples-only", "--no-align", "--command="+strings.TrimSpace(`
+SELECT (SELECT pg_catalog.count(*)
+          FROM pg_catalog.pg_ls_dir('pg_wal/archive_status') AS status (name)
+         WHERE name LIKE '%.ready'),
+       pg_catalog.pg_size_bytes(pg_catalog.current_setting('wal_segment_size')),
+       COALESCE(last_failed_time > last_archived_time, last_failed_time IS NOT NULL),
+       COALESCE(last_failed_wal, '')
+  FROM pg_catalog.pg_stat_archiver`))
+
+	log.V(1).Info("observed WAL archiver",
+		"stdout", stdout.String(), "stderr", stderr.String())
+
+	var backlog archiveBacklog
+	if err == nil {
+		backlog, err = parseArchiveBacklog(stdout.String())
+	}
+	if err != nil {
+		// Keep any existing condition until the archiver can be observed again.
+		log.Error(err, "unable to observe the WAL archiver")
+		return next
+	}
+
+	queueMax := pgbackrest.ArchiveQueueMax(cluster)
+	previous := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.DiskProtectionEngaged)
+	failing := backlog.Failing && backlog.Bytes > 0
+
+	condition := metav1.Condition{
+		Type:               v1beta1.DiskProtectionEngaged,
+		ObservedGeneration: cluster.GetGeneration(),
+		Status:             metav1.ConditionFalse,
+		Reason:             "ArchiveCurrent",
+		Message:            "WAL is being archived",
+	}
+	switch {
+	// pgBackRest drops WAL once the next file would exceed the limit.
+	case failing && queueMax > 0 && backlog.Bytes+backlog.Segment > queueMax:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "WALDropped"
+		condition.Message = fmt.Sprintf(
+			"WAL has not been archived since %s; pgBackRest is dropping WAL beyond %s "+
+				"to protect the primary. Point-in-time recovery is unavailable until the next backup",
+			backlog.LastFailed, resource.NewQuantity(queueMax, resource.BinarySI))
+
+	case failing:
+		condition.Reason = "ArchiveFailing"
+		condition.Message = fmt.Sprintf(
+			"WAL has not been archived since %s; %s of WAL is kept on the primary until it is",
+			backlog.LastFailed, resource.NewQuantity(backlog.Bytes, resource.BinarySI))
+		if queueMax > 0 {
+			condition.Message += fmt.Sprintf(" or reaches %s",
+				resource.NewQuantity(queueMax, resource.BinarySI))
+		}
+	}
+
+	if condition.Reason != "ArchiveCurrent" &&
+		(previous == nil || previous.Reason != condition.Reason) {
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, condition.Reason, condition.Message)
+	}
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+	return next
+}
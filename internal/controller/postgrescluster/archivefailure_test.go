@@ -0,0 +1,162 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestParseArchiveBacklog(t *testing.T) {
+	backlog, err := parseArchiveBacklog("3|16777216|t|000000010000000000000004\n")
+	assert.NilError(t, err)
+	assert.Equal(t, backlog.Bytes, int64(3*16777216))
+	assert.Equal(t, backlog.Segment, int64(16777216))
+	assert.Assert(t, backlog.Failing)
+	assert.Equal(t, backlog.LastFailed, "000000010000000000000004")
+
+	backlog, err = parseArchiveBacklog("0|16777216|f|")
+	assert.NilError(t, err)
+	assert.Equal(t, backlog.Bytes, int64(0))
+	assert.Assert(t, !backlog.Failing)
+
+	_, err = parseArchiveBacklog("")
+	assert.ErrorContains(t, err, "unexpected")
+}
+
+func TestReconcileDiskProtection(t *testing.T) {
+	ctx := context.Background()
+
+	pod := &corev1.Pod{}
+	pod.Namespace, pod.Name = "ns1", "hippo-00-abcd-0"
+	pod.Labels = map[string]string{naming.LabelRole: naming.RolePatroniLeader}
+	pod.Annotations = map[string]string{"status": `{"role":"master"}`}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  naming.ContainerDatabase,
+		State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+	}}
+
+	instances := &observedInstances{forCluster: []*Instance{
+		{Name: "hippo-00-abcd", Pods: []*corev1.Pod{pod}},
+	}}
+
+	var archiver string
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Recorder: recorder,
+		PodExec: func(namespace, pod, container string, _ io.Reader, stdout, _ io.Writer, command ...string) error {
+			assert.Equal(t, container, naming.ContainerDatabase)
+			assert.Equal(t, command[0], "psql")
+			_, err := stdout.Write([]byte(archiver))
+			return err
+		},
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace = "ns1"
+	cluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{
+		Repos: []v1beta1.RepoStatus{{Name: "repo1", StanzaCreated: true}},
+	}
+
+	t.Run("NoStanza", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Status.PGBackRest = nil
+
+		result := reconciler.reconcileDiskProtection(ctx, cluster, instances)
+		assert.Equal(t, result.RequeueAfter, time.Duration(0))
+		assert.Equal(t, len(cluster.Status.Conditions), 0)
+	})
+
+	t.Run("Current", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		archiver = "1|16777216|f|"
+
+		result := reconciler.reconcileDiskProtection(ctx, cluster, instances)
+		assert.Assert(t, result.RequeueAfter > 0)
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.DiskProtectionEngaged)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "ArchiveCurrent")
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+
+	t.Run("Retain", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		archiver = "64|16777216|t|000000010000000000000004"
+
+		reconciler.reconcileDiskProtection(ctx, cluster, instances)
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.DiskProtectionEngaged)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "ArchiveFailing")
+		assert.Assert(t, cmp.Contains(condition.Message, "000000010000000000000004"))
+		assert.Assert(t, cmp.Contains(condition.Message, "1Gi of WAL"))
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "ArchiveFailing"))
+
+		// The event happens once.
+		reconciler.reconcileDiskProtection(ctx, cluster, instances)
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+
+	t.Run("Drop", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Backups.PGBackRest.ArchiveFailure = &v1beta1.PGBackRestArchiveFailure{
+			Policy:       "Drop",
+			MaxQueueSize: resource.NewQuantity(1<<30, resource.BinarySI),
+		}
+
+		archiver = "32|16777216|t|000000010000000000000004"
+		reconciler.reconcileDiskProtection(ctx, cluster, instances)
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.DiskProtectionEngaged)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Assert(t, cmp.Contains(condition.Message, "or reaches 1Gi"))
+		<-recorder.Events
+
+		archiver = "64|16777216|t|000000010000000000000004"
+		reconciler.reconcileDiskProtection(ctx, cluster, instances)
+
+		condition = meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.DiskProtectionEngaged)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionTrue)
+		assert.Equal(t, condition.Reason, "WALDropped")
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "dropping WAL beyond 1Gi"))
+
+		// The condition clears once archiving succeeds.
+		archiver = "0|16777216|f|000000010000000000000004"
+		reconciler.reconcileDiskProtection(ctx, cluster, instances)
+
+		condition = meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.DiskProtectionEngaged)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+}
@@ -0,0 +1,327 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// defaultScaleDownDelay is how long unscheduled pods keep running when the
+// instance set does not say otherwise.
+const defaultScaleDownDelay = 5 * time.Minute
+
+// scheduledReplicas returns the number of pods that spec schedules at now and
+// the next time that number might change. Outside every period, it returns
+// replicas. Times of day are interpreted in location.
+func scheduledReplicas(
+	spec *v1beta1.InstanceSetAutoscaling, replicas int32,
+	now time.Time, location *time.Location,
+) (int32, time.Time) {
+	local := now.In(location)
+	year, month, day := local.Date()
+
+	clock := func(value string) (hour, minute int) {
+		_, _ = fmt.Sscanf(value, "%d:%d", &hour, &minute)
+		return
+	}
+	allowed := func(schedule v1beta1.InstanceSetReplicaSchedule, start time.Time) bool {
+		weekday := start.Weekday().String()[:3]
+		for _, day := range schedule.Days {
+			if day == weekday {
+				return true
+			}
+		}
+		return len(schedule.Days) == 0
+	}
+
+	var next time.Time
+	found := false
+	wanted := replicas
+
+	for _, schedule := range spec.Schedules {
+		startHour, startMinute := clock(schedule.Start)
+		endHour, endMinute := clock(schedule.End)
+
+		// Look at periods that started yesterday through those that start a
+		// week from today.
+		for offset := -1; offset <= 7; offset++ {
+			start := time.Date(year, month, day+offset, startHour, startMinute, 0, 0, location)
+			end := time.Date(year, month, day+offset, endHour, endMinute, 0, 0, location)
+			if !end.After(start) {
+				end = end.AddDate(0, 0, 1)
+			}
+			if !allowed(schedule, start) {
+				continue
+			}
+
+			if !found && !start.After(local) && local.Before(end) {
+				found, wanted = true, schedule.Replicas
+			}
+			for _, boundary := range []time.Time{start, end} {
+				if boundary.After(local) && (next.IsZero() || boundary.Before(next)) {
+					next = boundary
+				}
+			}
+		}
+	}
+
+	return wanted, next
+}
+
+// demandReplicas returns the number of pods that scalers want through the
+// scale subresource, kept between the minimum and maximum of spec. It returns
+// replicas when scalers have not set a number.
+func demandReplicas(
+	spec *v1beta1.InstanceSetDemandScaling, wanted *int32, replicas int32,
+) int32 {
+	if wanted != nil {
+		replicas = *wanted
+	}
+	if replicas < spec.MinReplicas {
+		replicas = spec.MinReplicas
+	}
+	if replicas > spec.MaxReplicas {
+		replicas = spec.MaxReplicas
+	}
+	return replicas
+}
+
+// reconcileInstanceAutoscaling changes the replicas of every instance set that
+// has autoscaling to the number scheduled at now or wanted by demand. Pods are removed only after
+// the scale down delay of their instance set so that queries on them can
+// finish. It returns when the number might change again.
+//
+// NOTE: This changes cluster.Spec in memory only; it must be called before
+// anything reads the replicas of instance sets.
+func (r *Reconciler) reconcileInstanceAutoscaling(
+	cluster *v1beta1.PostgresCluster, now time.Time,
+) reconcile.Result {
+	previous := make(map[string]v1beta1.InstanceSetAutoscalingStatus)
+	for _, status := range cluster.Status.Autoscaling {
+		previous[status.Name] = status
+	}
+
+	var next time.Time
+	var statuses []v1beta1.InstanceSetAutoscalingStatus
+	earliest := func(t time.Time) {
+		if !t.IsZero() && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+
+	cluster.Status.DemandReplicas, cluster.Status.DemandSelector = 0, ""
+	demand := ""
+
+	for i := range cluster.Spec.InstanceSets {
+		set := &cluster.Spec.InstanceSets[i]
+		spec := set.Autoscaling
+		if spec == nil {
+			continue
+		}
+
+		location := time.UTC
+		if spec.TimeZone != "" {
+			if loaded, err := time.LoadLocation(spec.TimeZone); err == nil {
+				location = loaded
+			} else {
+				r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidTimeZone",
+					"Instance set %q uses UTC: %v", set.Name, err)
+			}
+		}
+
+		wanted, change := scheduledReplicas(spec, *set.Replicas, now, location)
+		earliest(change)
+
+		// Demand that calls for more pods than the schedule wins.
+		if spec.Demand != nil && demand != "" {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidAutoscaling",
+				"Instance set %q does not scale on demand; %q does", set.Name, demand)
+		}
+		if spec.Demand != nil && demand == "" {
+			demand = set.Name
+			if replicas := demandReplicas(spec.Demand, cluster.Spec.DemandReplicas, *set.Replicas); replicas > wanted {
+				wanted = replicas
+			}
+		}
+
+		status := v1beta1.InstanceSetAutoscalingStatus{Name: set.Name, Replicas: wanted}
+		if prior, ok := previous[set.Name]; ok && wanted < prior.Replicas {
+			delay := defaultScaleDownDelay
+			if spec.ScaleDownDelaySeconds != nil {
+				delay = time.Duration(*spec.ScaleDownDelaySeconds) * time.Second
+			}
+
+			since := now
+			if prior.ScaleDownTime != nil {
+				since = prior.ScaleDownTime.Time
+			}
+			if deadline := since.Add(delay); now.Before(deadline) {
+				status.Replicas = prior.Replicas
+				status.ScaleDownTime = &metav1.Time{Time: since}
+				earliest(deadline)
+			}
+		}
+
+		replicas := status.Replicas
+		set.Replicas = &replicas
+		statuses = append(statuses, status)
+
+		if demand == set.Name {
+			selector, _ := naming.AsSelector(naming.ClusterInstanceSet(cluster.Name, set.Name))
+			cluster.Status.DemandReplicas = replicas
+			cluster.Status.DemandSelector = selector.String()
+		}
+	}
+
+	cluster.Status.Autoscaling = statuses
+
+	if next.IsZero() {
+		return reconcile.Result{}
+	}
+	return reconcile.Result{RequeueAfter: next.Sub(now)}
+}
+
+// generateDemandScaledObject returns a KEDA ScaledObject that scales set through
+// the scale subresource of cluster. It adds a pod for every so many active
+// client connections that Prometheus reports for cluster.
+// - https://keda.sh/docs/latest/concepts/scaling-deployments/
+func generateDemandScaledObject(
+	cluster *v1beta1.PostgresCluster, set *v1beta1.PostgresInstanceSetSpec,
+) *unstructured.Unstructured {
+	spec := set.Autoscaling.Demand
+
+	query := spec.Query
+	if query == "" {
+		query = fmt.Sprintf(`sum(ccp_autoscaling_active_connections{pg_cluster="%s:%s"})`,
+			cluster.Namespace, cluster.Name)
+	}
+	threshold := int32(20)
+	if spec.ActiveConnectionsPerReplica != nil {
+		threshold = *spec.ActiveConnectionsPerReplica
+	}
+
+	object := &unstructured.Unstructured{}
+	object.SetAPIVersion("keda.sh/v1alpha1")
+	object.SetKind("ScaledObject")
+	object.SetNamespace(cluster.Namespace)
+	object.SetName(naming.ClusterDemandScaledObject(cluster).Name)
+	if annotations := naming.Merge(
+		cluster.Spec.Metadata.GetAnnotationsOrNil(),
+		set.Metadata.GetAnnotationsOrNil(),
+	); len(annotations) > 0 {
+		object.SetAnnotations(annotations)
+	}
+	object.SetLabels(naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		set.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster:     cluster.Name,
+			naming.LabelInstanceSet: set.Name,
+		}))
+
+	object.Object["spec"] = map[string]interface{}{
+		"scaleTargetRef": map[string]interface{}{
+			"apiVersion": v1beta1.GroupVersion.String(),
+			"kind":       "PostgresCluster",
+			"name":       cluster.Name,
+		},
+		"minReplicaCount": int64(spec.MinReplicas),
+		"maxReplicaCount": int64(spec.MaxReplicas),
+		"triggers": []interface{}{
+			map[string]interface{}{
+				"type": "prometheus",
+				"metadata": map[string]interface{}{
+					"serverAddress": spec.PrometheusAddress,
+					"query":         query,
+					"threshold":     fmt.Sprint(threshold),
+				},
+			},
+		},
+	}
+
+	return object
+}
+
+//+kubebuilder:rbac:groups="keda.sh",resources="scaledobjects",verbs={get}
+//+kubebuilder:rbac:groups="keda.sh",resources="scaledobjects",verbs={create,patch}
+//+kubebuilder:rbac:groups="keda.sh",resources="scaledobjects",verbs={delete}
+
+// reconcileDemandScaledObject writes the KEDA ScaledObject of the instance set
+// that scales on demand and removes it when there is none. KEDA is optional, so
+// nothing happens when its API is not installed.
+func (r *Reconciler) reconcileDemandScaledObject(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) error {
+	var set *v1beta1.PostgresInstanceSetSpec
+	for i := range cluster.Spec.InstanceSets {
+		if spec := cluster.Spec.InstanceSets[i].Autoscaling; spec != nil && spec.Demand != nil {
+			set = &cluster.Spec.InstanceSets[i]
+			break
+		}
+	}
+	create := set != nil && (set.Autoscaling.Demand.CreateScaledObject == nil ||
+		*set.Autoscaling.Demand.CreateScaledObject)
+
+	existing := &unstructured.Unstructured{}
+	existing.SetAPIVersion("keda.sh/v1alpha1")
+	existing.SetKind("ScaledObject")
+	err := r.Client.Get(ctx,
+		naming.AsObjectKey(naming.ClusterDemandScaledObject(cluster)), existing)
+
+	if meta.IsNoMatchError(err) {
+		if create {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "KEDANotInstalled",
+				"Instance set %q needs KEDA to scale on demand", set.Name)
+		}
+		return nil
+	}
+	err = errors.WithStack(client.IgnoreNotFound(err))
+
+	if !create {
+		if err == nil && existing.GetUID() != "" {
+			err = errors.WithStack(client.IgnoreNotFound(
+				r.deleteControlled(ctx, cluster, existing)))
+		}
+		return err
+	}
+
+	if set.Autoscaling.Demand.PrometheusAddress == "" {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidAutoscaling",
+			"Instance set %q needs a Prometheus address to scale on demand", set.Name)
+		return err
+	}
+
+	intent := generateDemandScaledObject(cluster, set)
+	if err == nil {
+		err = errors.WithStack(r.setControllerReference(cluster, intent))
+	}
+	if err == nil {
+		err = errors.WithStack(r.patch(ctx, intent, client.Apply, client.ForceOwnership))
+	}
+	return err
+}
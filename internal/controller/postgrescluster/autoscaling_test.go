@@ -0,0 +1,212 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestScheduledReplicas(t *testing.T) {
+	spec := &v1beta1.InstanceSetAutoscaling{
+		Schedules: []v1beta1.InstanceSetReplicaSchedule{
+			{Days: []string{"Mon", "Tue", "Wed", "Thu", "Fri"}, Start: "08:00", End: "18:00", Replicas: 4},
+			{Start: "22:00", End: "02:00", Replicas: 2},
+		},
+	}
+
+	// Wednesday, 2024-01-03
+	at := func(hour, minute int) time.Time {
+		return time.Date(2024, time.January, 3, hour, minute, 0, 0, time.UTC)
+	}
+
+	t.Run("BusinessHours", func(t *testing.T) {
+		replicas, next := scheduledReplicas(spec, 1, at(9, 30), time.UTC)
+		assert.Equal(t, replicas, int32(4))
+		assert.Equal(t, next, at(18, 0))
+	})
+
+	t.Run("Outside", func(t *testing.T) {
+		replicas, next := scheduledReplicas(spec, 1, at(19, 0), time.UTC)
+		assert.Equal(t, replicas, int32(1))
+		assert.Equal(t, next, at(22, 0))
+	})
+
+	t.Run("Overnight", func(t *testing.T) {
+		replicas, next := scheduledReplicas(spec, 1, at(1, 0), time.UTC)
+		assert.Equal(t, replicas, int32(2))
+		assert.Equal(t, next, at(2, 0))
+	})
+
+	t.Run("Weekend", func(t *testing.T) {
+		saturday := time.Date(2024, time.January, 6, 9, 30, 0, 0, time.UTC)
+		replicas, _ := scheduledReplicas(spec, 1, saturday, time.UTC)
+		assert.Equal(t, replicas, int32(1))
+	})
+
+	t.Run("TimeZone", func(t *testing.T) {
+		location := time.FixedZone("UTC-5", -5*60*60)
+
+		// 09:30 UTC is 04:30 in location.
+		replicas, next := scheduledReplicas(spec, 1, at(9, 30), location)
+		assert.Equal(t, replicas, int32(1))
+		assert.Assert(t, next.Equal(at(13, 0)), "got %v", next)
+	})
+}
+
+func TestReconcileInstanceAutoscaling(t *testing.T) {
+	reconciler := &Reconciler{Recorder: record.NewFakeRecorder(10)}
+	now := time.Date(2024, time.January, 3, 9, 30, 0, 0, time.UTC)
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+		{Name: "00", Replicas: initialize.Int32(2)},
+		{
+			Name: "reports", Replicas: initialize.Int32(1),
+			Autoscaling: &v1beta1.InstanceSetAutoscaling{
+				Schedules: []v1beta1.InstanceSetReplicaSchedule{
+					{Start: "08:00", End: "18:00", Replicas: 3},
+				},
+				ScaleDownDelaySeconds: initialize.Int32(600),
+			},
+		},
+	}
+
+	t.Run("ScaleUp", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		result := reconciler.reconcileInstanceAutoscaling(cluster, now)
+
+		assert.Equal(t, *cluster.Spec.InstanceSets[0].Replicas, int32(2))
+		assert.Equal(t, *cluster.Spec.InstanceSets[1].Replicas, int32(3))
+		assert.Equal(t, len(cluster.Status.Autoscaling), 1)
+		assert.Equal(t, cluster.Status.Autoscaling[0].Name, "reports")
+		assert.Equal(t, cluster.Status.Autoscaling[0].Replicas, int32(3))
+		assert.Equal(t, result.RequeueAfter, 8*time.Hour+30*time.Minute)
+	})
+
+	t.Run("ScaleDownDelay", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Status.Autoscaling = []v1beta1.InstanceSetAutoscalingStatus{
+			{Name: "reports", Replicas: 3},
+		}
+
+		evening := now.Add(9 * time.Hour)
+		result := reconciler.reconcileInstanceAutoscaling(cluster, evening)
+		assert.Equal(t, *cluster.Spec.InstanceSets[1].Replicas, int32(3))
+		assert.Assert(t, cluster.Status.Autoscaling[0].ScaleDownTime != nil)
+		assert.Equal(t, result.RequeueAfter, 10*time.Minute)
+
+		// Pods are removed after the delay.
+		cluster.Spec.InstanceSets[1].Replicas = initialize.Int32(1)
+		result = reconciler.reconcileInstanceAutoscaling(cluster, evening.Add(10*time.Minute))
+		assert.Equal(t, *cluster.Spec.InstanceSets[1].Replicas, int32(1))
+		assert.Assert(t, cluster.Status.Autoscaling[0].ScaleDownTime == nil)
+		assert.Equal(t, cluster.Status.Autoscaling[0].Replicas, int32(1))
+		assert.Assert(t, result.RequeueAfter > 0)
+	})
+
+	t.Run("Demand", func(t *testing.T) {
+		recorder := record.NewFakeRecorder(10)
+		reconciler := &Reconciler{Recorder: recorder}
+
+		cluster := cluster.DeepCopy()
+		cluster.Name = "hippo"
+		cluster.Spec.InstanceSets[0].Autoscaling = &v1beta1.InstanceSetAutoscaling{
+			Demand: &v1beta1.InstanceSetDemandScaling{MinReplicas: 1, MaxReplicas: 4},
+		}
+		cluster.Spec.InstanceSets[1].Autoscaling.Demand = &v1beta1.InstanceSetDemandScaling{
+			MinReplicas: 1, MaxReplicas: 2,
+		}
+
+		// Without a number from scalers, the replicas field applies.
+		_ = reconciler.reconcileInstanceAutoscaling(cluster, now)
+		assert.Equal(t, *cluster.Spec.InstanceSets[0].Replicas, int32(2))
+		assert.Equal(t, cluster.Status.DemandReplicas, int32(2))
+		assert.Equal(t, cluster.Status.DemandSelector,
+			"postgres-operator.crunchydata.com/cluster=hippo,"+
+				"postgres-operator.crunchydata.com/instance-set=00")
+
+		// Only the first instance set scales on demand.
+		assert.Equal(t, *cluster.Spec.InstanceSets[1].Replicas, int32(3))
+		assert.Equal(t, len(recorder.Events), 1)
+
+		// The number from scalers is kept within bounds.
+		cluster.Spec.DemandReplicas = initialize.Int32(9)
+		_ = reconciler.reconcileInstanceAutoscaling(cluster, now)
+		assert.Equal(t, *cluster.Spec.InstanceSets[0].Replicas, int32(4))
+		assert.Equal(t, cluster.Status.DemandReplicas, int32(4))
+	})
+
+	t.Run("InvalidTimeZone", func(t *testing.T) {
+		recorder := record.NewFakeRecorder(10)
+		reconciler := &Reconciler{Recorder: recorder}
+
+		cluster := cluster.DeepCopy()
+		cluster.Spec.InstanceSets[1].Autoscaling.TimeZone = "Nowhere/Special"
+		_ = reconciler.reconcileInstanceAutoscaling(cluster, now)
+		assert.Equal(t, *cluster.Spec.InstanceSets[1].Replicas, int32(3))
+		assert.Equal(t, len(recorder.Events), 1)
+	})
+}
+
+func TestGenerateDemandScaledObject(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace = "ns1"
+	cluster.Name = "hippo"
+	cluster.Spec.Metadata = &v1beta1.Metadata{Labels: map[string]string{"x": "y"}}
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{
+		Name: "00",
+		Autoscaling: &v1beta1.InstanceSetAutoscaling{
+			Demand: &v1beta1.InstanceSetDemandScaling{
+				MinReplicas:       2,
+				MaxReplicas:       5,
+				PrometheusAddress: "http://prometheus:9090",
+			},
+		},
+	}}
+
+	object := generateDemandScaledObject(cluster, &cluster.Spec.InstanceSets[0])
+	assert.Equal(t, object.GetName(), "hippo-demand")
+	assert.Assert(t, marshalMatches(object.Object, `
+apiVersion: keda.sh/v1alpha1
+kind: ScaledObject
+metadata:
+  labels:
+    postgres-operator.crunchydata.com/cluster: hippo
+    postgres-operator.crunchydata.com/instance-set: "00"
+    x: "y"
+  name: hippo-demand
+  namespace: ns1
+spec:
+  maxReplicaCount: 5
+  minReplicaCount: 2
+  scaleTargetRef:
+    apiVersion: postgres-operator.crunchydata.com/v1beta1
+    kind: PostgresCluster
+    name: hippo
+  triggers:
+  - metadata:
+      query: sum(ccp_autoscaling_active_connections{pg_cluster="ns1:hippo"})
+      serverAddress: http://prometheus:9090
+      threshold: "20"
+    type: prometheus
+	`))
+}
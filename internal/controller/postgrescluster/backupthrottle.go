@@ -0,0 +1,174 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"hash/fnv"
+	"time"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// BackupThrottle staggers the scheduled pgBackRest backups of every cluster so
+// that backups scheduled at the same time do not all read from the same Nodes
+// at once. When either field is set, scheduled backup Jobs are created suspended
+// and resumed by the operator.
+type BackupThrottle struct {
+	// Jitter is the longest a scheduled backup waits after its Job is created.
+	// Each Job waits a stable portion of it that depends on its name.
+	Jitter time.Duration
+
+	// PerNode is how many scheduled backups may read from one Node at a time.
+	// Zero means there is no limit.
+	PerNode int
+}
+
+// enabled returns whether or not scheduled backup Jobs wait for the operator.
+func (t BackupThrottle) enabled() bool { return t.Jitter > 0 || t.PerNode > 0 }
+
+// jitter returns how long job waits after it is created.
+func (t BackupThrottle) jitter(job *batchv1.Job) time.Duration {
+	if t.Jitter < time.Second {
+		return 0
+	}
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(job.Namespace + "/" + job.Name))
+	return time.Duration(hash.Sum32()%uint32(t.Jitter/time.Second)) * time.Second
+}
+
+// wait returns how long job should remain suspended, given the scheduled backup
+// Jobs of every cluster. Jobs read from the Node in their PGBackRestBackupNode
+// annotation; those whose jitter ended first go first.
+func (t BackupThrottle) wait(job *batchv1.Job, jobs []batchv1.Job, now time.Time) time.Duration {
+	created := job.CreationTimestamp.Time
+	if remaining := created.Add(t.jitter(job)).Sub(now); remaining > 0 {
+		return remaining
+	}
+	if t.PerNode <= 0 {
+		return 0
+	}
+
+	node := job.Annotations[naming.PGBackRestBackupNode]
+	ready := func(job *batchv1.Job) time.Time { return job.CreationTimestamp.Add(t.jitter(job)) }
+
+	key := func(job *batchv1.Job) string { return job.Namespace + "/" + job.Name }
+
+	busy := 0
+	for i := range jobs {
+		other := &jobs[i]
+		if key(other) == key(job) || other.Annotations[naming.PGBackRestBackupNode] != node ||
+			jobCompleted(other) || jobFailed(other) {
+			continue
+		}
+
+		// Count the Jobs that are running and those that are ready and ahead of this one.
+		suspended := other.Spec.Suspend != nil && *other.Spec.Suspend
+		if !suspended ||
+			(!ready(other).After(now) && (ready(other).Before(ready(job)) ||
+				(ready(other).Equal(ready(job)) && key(other) < key(job)))) {
+			busy++
+		}
+	}
+	if busy >= t.PerNode {
+		return time.Minute
+	}
+	return 0
+}
+
+// +kubebuilder:rbac:groups="batch",resources="jobs",verbs={list,patch}
+
+// reconcileScheduledBackupThrottle resumes the suspended Jobs of scheduled
+// backups of cluster according to r.BackupThrottle. Each Job is annotated with
+// the Node of the current primary so that backups of other clusters can see it.
+func (r *Reconciler) reconcileScheduledBackupThrottle(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) (reconcile.Result, error) {
+	jobs := &batchv1.JobList{}
+	err := errors.WithStack(r.Client.List(ctx, jobs,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{naming.LabelCluster: cluster.Name},
+		client.HasLabels{naming.LabelPGBackRestCronJob},
+	))
+
+	var node string
+	if instances != nil {
+		for _, instance := range instances.forCluster {
+			if primary, known := instance.IsPrimary(); primary && known && len(instance.Pods) > 0 {
+				node = instance.Pods[0].Spec.NodeName
+			}
+		}
+	}
+
+	var everyone *batchv1.JobList
+	result := reconcile.Result{}
+	for i := range jobs.Items {
+		job := &jobs.Items[i]
+		if err != nil || job.Spec.Suspend == nil || !*job.Spec.Suspend ||
+			jobCompleted(job) || jobFailed(job) {
+			continue
+		}
+
+		// Limiting backups per Node requires the Node of the primary and the
+		// scheduled backup Jobs of every cluster.
+		if r.BackupThrottle.PerNode > 0 {
+			if node == "" {
+				result = updateReconcileResult(result, reconcile.Result{RequeueAfter: time.Minute})
+				continue
+			}
+			if everyone == nil {
+				everyone = &batchv1.JobList{}
+				err = errors.WithStack(r.Client.List(ctx, everyone,
+					client.HasLabels{naming.LabelPGBackRestCronJob}))
+				if err != nil {
+					continue
+				}
+			}
+		}
+
+		before := job.DeepCopy()
+		changed := false
+		if node != "" && job.Annotations[naming.PGBackRestBackupNode] != node {
+			initialize.StringMap(&job.Annotations)
+			job.Annotations[naming.PGBackRestBackupNode] = node
+			changed = true
+		}
+
+		var others []batchv1.Job
+		if everyone != nil {
+			others = everyone.Items
+		}
+		if wait := r.BackupThrottle.wait(job, others, time.Now()); wait > 0 {
+			result = updateReconcileResult(result, reconcile.Result{RequeueAfter: wait})
+		} else {
+			job.Spec.Suspend = initialize.Bool(false)
+			changed = true
+		}
+
+		if changed {
+			err = errors.WithStack(r.patch(ctx, job, client.MergeFrom(before)))
+		}
+	}
+
+	return result, err
+}
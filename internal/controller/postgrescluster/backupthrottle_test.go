@@ -0,0 +1,199 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestBackupThrottleJitter(t *testing.T) {
+	job := &batchv1.Job{}
+	job.Namespace, job.Name = "ns1", "hippo-repo1-full-12345"
+
+	assert.Equal(t, BackupThrottle{}.jitter(job), time.Duration(0))
+	assert.Equal(t, BackupThrottle{Jitter: time.Millisecond}.jitter(job), time.Duration(0))
+
+	throttle := BackupThrottle{Jitter: time.Hour}
+	first := throttle.jitter(job)
+	assert.Assert(t, first >= 0 && first < time.Hour)
+	assert.Equal(t, throttle.jitter(job), first, "expected a stable value")
+
+	// Different Jobs wait different amounts.
+	seen := map[time.Duration]bool{}
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: name}}
+		seen[throttle.jitter(job)] = true
+	}
+	assert.Assert(t, len(seen) > 1)
+}
+
+func TestBackupThrottleWait(t *testing.T) {
+	now := time.Date(2024, time.March, 1, 2, 0, 0, 0, time.UTC)
+
+	job := func(name, node string, created time.Time, suspended bool) batchv1.Job {
+		job := batchv1.Job{}
+		job.Namespace, job.Name = "ns1", name
+		job.CreationTimestamp = metav1.NewTime(created)
+		job.Annotations = map[string]string{naming.PGBackRestBackupNode: node}
+		job.Spec.Suspend = initialize.Bool(suspended)
+		return job
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		waiting := job("a", "node1", now, true)
+		running := job("b", "node1", now, false)
+		assert.Equal(t, BackupThrottle{}.wait(&waiting, []batchv1.Job{running}, now),
+			time.Duration(0))
+	})
+
+	t.Run("Jitter", func(t *testing.T) {
+		throttle := BackupThrottle{Jitter: time.Hour}
+		waiting := job("a", "node1", now, true)
+		delay := throttle.jitter(&waiting)
+
+		assert.Equal(t, throttle.wait(&waiting, nil, now), delay)
+		assert.Equal(t, throttle.wait(&waiting, nil, now.Add(delay)), time.Duration(0))
+	})
+
+	t.Run("PerNode", func(t *testing.T) {
+		throttle := BackupThrottle{PerNode: 1}
+		waiting := job("a", "node1", now, true)
+
+		// Nothing else is running.
+		assert.Equal(t, throttle.wait(&waiting, []batchv1.Job{waiting}, now), time.Duration(0))
+
+		// Another Job is running on the same Node.
+		running := job("b", "node1", now.Add(-time.Hour), false)
+		assert.Equal(t, throttle.wait(&waiting, []batchv1.Job{running}, now), time.Minute)
+
+		// Another Job is running on a different Node.
+		running.Annotations[naming.PGBackRestBackupNode] = "node2"
+		assert.Equal(t, throttle.wait(&waiting, []batchv1.Job{running}, now), time.Duration(0))
+
+		// A finished Job on the same Node does not count.
+		finished := job("c", "node1", now.Add(-time.Hour), false)
+		finished.Status.Conditions = []batchv1.JobCondition{{
+			Type: batchv1.JobComplete, Status: corev1.ConditionTrue,
+		}}
+		assert.Equal(t, throttle.wait(&waiting, []batchv1.Job{finished}, now), time.Duration(0))
+
+		// The Job that waited longer goes first.
+		older := job("d", "node1", now.Add(-time.Minute), true)
+		assert.Equal(t, throttle.wait(&waiting, []batchv1.Job{older}, now), time.Minute)
+		assert.Equal(t, throttle.wait(&older, []batchv1.Job{waiting}, now), time.Duration(0))
+
+		// Ties go to the lesser name.
+		twin := job("e", "node1", now, true)
+		assert.Equal(t, throttle.wait(&twin, []batchv1.Job{waiting}, now), time.Minute)
+		assert.Equal(t, throttle.wait(&waiting, []batchv1.Job{twin}, now), time.Duration(0))
+
+		// Two at a time.
+		throttle.PerNode = 2
+		assert.Equal(t, throttle.wait(&waiting, []batchv1.Job{running, older}, now),
+			time.Duration(0))
+	})
+}
+
+func TestReconcileScheduledBackupThrottle(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+
+	primary := &corev1.Pod{}
+	primary.Labels = map[string]string{naming.LabelRole: naming.RolePatroniLeader}
+	primary.Spec.NodeName = "node1"
+	instances := &observedInstances{forCluster: []*Instance{
+		{Name: "hippo-00-abcd", Pods: []*corev1.Pod{primary}},
+	}}
+
+	scheduled := func(namespace, cluster, name string, suspended bool) *batchv1.Job {
+		job := &batchv1.Job{}
+		job.Namespace, job.Name = namespace, name
+		job.Labels = naming.PGBackRestCronJobLabels(cluster, "repo1", full)
+		job.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+		job.Spec.Suspend = initialize.Bool(suspended)
+		return job
+	}
+
+	t.Run("Resume", func(t *testing.T) {
+		waiting := scheduled("ns1", "hippo", "hippo-repo1-full-1", true)
+		r := &Reconciler{Client: fake.NewClientBuilder().WithObjects(waiting).Build()}
+
+		result, err := r.reconcileScheduledBackupThrottle(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Equal(t, result, reconcile.Result{})
+
+		job := &batchv1.Job{}
+		assert.NilError(t, r.Client.Get(ctx, client.ObjectKeyFromObject(waiting), job))
+		assert.Assert(t, job.Spec.Suspend != nil && !*job.Spec.Suspend)
+	})
+
+	t.Run("PerNode", func(t *testing.T) {
+		waiting := scheduled("ns1", "hippo", "hippo-repo1-full-1", true)
+		running := scheduled("ns2", "other", "other-repo1-full-1", false)
+		running.Annotations = map[string]string{naming.PGBackRestBackupNode: "node1"}
+
+		r := &Reconciler{
+			BackupThrottle: BackupThrottle{PerNode: 1},
+			Client:         fake.NewClientBuilder().WithObjects(waiting, running).Build(),
+		}
+
+		result, err := r.reconcileScheduledBackupThrottle(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Equal(t, result.RequeueAfter, time.Minute)
+
+		job := &batchv1.Job{}
+		assert.NilError(t, r.Client.Get(ctx, client.ObjectKeyFromObject(waiting), job))
+		assert.Assert(t, job.Spec.Suspend != nil && *job.Spec.Suspend)
+		assert.Equal(t, job.Annotations[naming.PGBackRestBackupNode], "node1")
+
+		// The other backup finishes.
+		assert.NilError(t, r.Client.Delete(ctx, running))
+
+		result, err = r.reconcileScheduledBackupThrottle(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Equal(t, result, reconcile.Result{})
+
+		assert.NilError(t, r.Client.Get(ctx, client.ObjectKeyFromObject(waiting), job))
+		assert.Assert(t, job.Spec.Suspend != nil && !*job.Spec.Suspend)
+	})
+
+	t.Run("NoPrimary", func(t *testing.T) {
+		waiting := scheduled("ns1", "hippo", "hippo-repo1-full-1", true)
+		r := &Reconciler{
+			BackupThrottle: BackupThrottle{PerNode: 1},
+			Client:         fake.NewClientBuilder().WithObjects(waiting).Build(),
+		}
+
+		result, err := r.reconcileScheduledBackupThrottle(ctx, cluster, nil)
+		assert.NilError(t, err)
+		assert.Equal(t, result.RequeueAfter, time.Minute)
+	})
+}
@@ -0,0 +1,283 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/crunchydata/postgres-operator/internal/config"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/pgbackrest"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// protectedChangesKept is the number of protected changes kept in the status
+// of a PostgresCluster.
+const protectedChangesKept = 10
+
+// disruptiveChanges returns the changes the operator is about to make that
+// stop or replace the data of cluster: a new PostgreSQL image, an in-place
+// restore, and a major upgrade. Each is identified by its kind and value so
+// that a backup protects it only once.
+func disruptiveChanges(
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) []string {
+	var changes []string
+
+	if pod, _ := instances.writablePod(naming.ContainerDatabase); pod != nil {
+		for _, container := range pod.Spec.Containers {
+			if container.Name == naming.ContainerDatabase &&
+				container.Image != config.PostgresContainerImage(cluster) {
+				changes = append(changes, "image "+config.PostgresContainerImage(cluster))
+			}
+		}
+	}
+
+	if id := pendingRestore(cluster); id != "" {
+		changes = append(changes, "restore "+id)
+	}
+
+	if upgrade := cluster.GetAnnotations()[naming.AllowUpgrade]; upgrade != "" &&
+		(cluster.Status.UpgradeUser == nil || cluster.Status.UpgradeUser.Completed != upgrade) {
+		changes = append(changes, "upgrade "+upgrade)
+	}
+
+	return changes
+}
+
+// protectedChange returns whether or not a backup was taken before change.
+func protectedChange(cluster *v1beta1.PostgresCluster, change string) bool {
+	if cluster.Status.ChangeProtection == nil {
+		return false
+	}
+	for _, protected := range cluster.Status.ChangeProtection.History {
+		for _, c := range strings.Split(protected.Changes, ", ") {
+			if c == change {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// reconcileChangeProtection holds disruptive changes to cluster until a backup
+// is taken before them. The image of a running cluster is kept in memory, and
+// it returns true when an in-place restore must wait. The backup itself is
+// reconciled by reconcileChangeProtectionBackup. PGUpgrade waits for the
+// ChangesHeld condition of the cluster.
+func (r *Reconciler) reconcileChangeProtection(
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) (holdRestore bool) {
+	if cluster.Spec.ChangeProtection != v1beta1.ChangeProtectionSnapshotBeforeRestart {
+		cluster.Status.ChangeProtection = nil
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.ChangesHeld)
+		return false
+	}
+
+	status := cluster.Status.ChangeProtection
+	if status == nil {
+		status = new(v1beta1.ChangeProtectionStatus)
+		cluster.Status.ChangeProtection = status
+	}
+
+	var pending []string
+	for _, change := range disruptiveChanges(cluster, instances) {
+		if !protectedChange(cluster, change) {
+			pending = append(pending, change)
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               v1beta1.ChangesHeld,
+		ObservedGeneration: cluster.GetGeneration(),
+		Status:             metav1.ConditionFalse,
+		Reason:             "ChangesProtected",
+		Message:            "No disruptive changes are waiting for a backup",
+	}
+
+	if len(pending) > 0 {
+		if changes := strings.Join(pending, ", "); status.Pending != changes {
+			r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "ChangesHeld",
+				"Holding %s until a backup is taken", changes)
+			status.Pending = changes
+		}
+
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "WaitingForBackup"
+		condition.Message = "Holding " + status.Pending + " until a backup is taken"
+
+		for _, change := range pending {
+			switch {
+			case strings.HasPrefix(change, "image "):
+				// Keep the image that is running until the backup completes.
+				pod, _ := instances.writablePod(naming.ContainerDatabase)
+				for _, container := range pod.Spec.Containers {
+					if container.Name == naming.ContainerDatabase {
+						cluster.Spec.Image = container.Image
+					}
+				}
+			case strings.HasPrefix(change, "restore "):
+				holdRestore = true
+			}
+		}
+	} else {
+		status.Pending = ""
+	}
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+	return holdRestore
+}
+
+// +kubebuilder:rbac:groups="batch",resources="jobs",verbs={create,patch,delete}
+
+// reconcileChangeProtectionBackup takes a differential pgBackRest backup of
+// cluster before the changes held by reconcileChangeProtection. It returns
+// true while the backup must wait for another backup to finish.
+func (r *Reconciler) reconcileChangeProtectionBackup(ctx context.Context,
+	postgresCluster *v1beta1.PostgresCluster, repoResources *RepoResources,
+	serviceAccount *corev1.ServiceAccount, instances *observedInstances,
+	repo v1beta1.PGBackRestRepo) (bool, error) {
+
+	status := postgresCluster.Status.ChangeProtection
+	if status == nil {
+		return false, nil
+	}
+
+	// Update status according to the Job of the pending changes, and delete the Jobs of any
+	// previous changes.
+	var currentJob *batchv1.Job
+	for _, job := range repoResources.changeProtectionJobs {
+		if status.Pending == "" ||
+			job.GetAnnotations()[naming.ProtectedChanges] != status.Pending {
+			if status.Pending != "" {
+				if err := r.Client.Delete(ctx, job,
+					client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+					return false, errors.WithStack(client.IgnoreNotFound(err))
+				}
+			}
+			continue
+		}
+		currentJob = job
+	}
+	if status.Pending == "" {
+		return false, nil
+	}
+	if currentJob != nil {
+		if jobCompleted(currentJob) {
+			status.Protected = status.Pending
+			history := append(status.History, v1beta1.ProtectedChange{
+				Changes: status.Pending,
+				Backup:  currentJob.GetName(),
+				Time:    metav1.Now(),
+			})
+			if currentJob.Status.CompletionTime != nil {
+				history[len(history)-1].Time = *currentJob.Status.CompletionTime
+			}
+			if len(history) > protectedChangesKept {
+				history = history[len(history)-protectedChangesKept:]
+			}
+			status.History = history
+			r.Recorder.Eventf(postgresCluster, corev1.EventTypeNormal, "ChangesProtected",
+				"Differential backup completed in %q before %s", repo.Name, status.Pending)
+		}
+		if jobFailed(currentJob) {
+			r.Recorder.Eventf(postgresCluster, corev1.EventTypeWarning, "ChangeProtectionFailed",
+				"Differential backup did not complete in %q. Delete Job %q to try again.",
+				repo.Name, currentJob.GetName())
+		}
+		return false, nil
+	}
+
+	// pgBackRest connects to a PostgreSQL instance that is not in recovery to
+	// initiate a backup.
+	clusterWritable := false
+	for _, instance := range instances.forCluster {
+		writable, known := instance.IsWritable()
+		if writable && known {
+			clusterWritable = true
+			break
+		}
+	}
+	if !clusterWritable {
+		return false, nil
+	}
+
+	// determine if the dedicated repository host is ready (if enabled) using the repo host ready
+	// condition, and return if not
+	if pgbackrest.DedicatedRepoHostEnabled(postgresCluster) {
+		condition := meta.FindStatusCondition(postgresCluster.Status.Conditions, ConditionRepoHostReady)
+		if condition == nil || condition.Status != metav1.ConditionTrue {
+			return false, nil
+		}
+	}
+
+	// A differential backup needs the full backup taken to create replicas.
+	condition := meta.FindStatusCondition(postgresCluster.Status.Conditions,
+		ConditionReplicaCreate)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		return false, nil
+	}
+
+	// Likewise, wait for any manual, scheduled, or migration backup that is running.
+	for _, jobs := range [][]*batchv1.Job{
+		repoResources.manualBackupJobs, repoResources.scheduledBackupJobs,
+		repoResources.repoMigrationJobs,
+	} {
+		for _, job := range jobs {
+			if !jobCompleted(job) && !jobFailed(job) {
+				return true, nil
+			}
+		}
+	}
+
+	backupJob := &batchv1.Job{}
+	backupJob.ObjectMeta = naming.PGBackRestChangeProtectionJob(postgresCluster)
+
+	var labels, annotations map[string]string
+	labels = naming.Merge(postgresCluster.Spec.Metadata.GetLabelsOrNil(),
+		postgresCluster.Spec.Backups.PGBackRest.Metadata.GetLabelsOrNil(),
+		naming.PGBackRestBackupJobLabels(postgresCluster.GetName(), repo.Name,
+			naming.BackupChangeProtection))
+	annotations = naming.Merge(postgresCluster.Spec.Metadata.GetAnnotationsOrNil(),
+		postgresCluster.Spec.Backups.PGBackRest.Metadata.GetAnnotationsOrNil(),
+		map[string]string{naming.ProtectedChanges: status.Pending})
+	backupJob.ObjectMeta.Labels = labels
+	backupJob.ObjectMeta.Annotations = annotations
+
+	spec, err := generateBackupJobSpecIntent(postgresCluster, repo,
+		serviceAccount.GetName(), labels, annotations, "--type=diff")
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	backupJob.Spec = *spec
+
+	// set gvk and ownership refs
+	backupJob.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("Job"))
+	if err := controllerutil.SetControllerReference(postgresCluster, backupJob,
+		r.Client.Scheme()); err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	return false, errors.WithStack(r.apply(ctx, backupJob))
+}
@@ -0,0 +1,114 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestReconcileChangeProtection(t *testing.T) {
+	pod := &corev1.Pod{}
+	pod.Namespace, pod.Name = "ns1", "hippo-00-abcd-0"
+	pod.Annotations = map[string]string{"status": `{"role":"master"}`}
+	pod.Spec.Containers = []corev1.Container{{Name: naming.ContainerDatabase, Image: "postgres:1"}}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  naming.ContainerDatabase,
+		State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+	}}
+
+	instances := &observedInstances{forCluster: []*Instance{
+		{Name: "hippo-00-abcd", Pods: []*corev1.Pod{pod}},
+	}}
+
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{Recorder: recorder}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+	cluster.Spec.Image = "postgres:2"
+	cluster.Spec.Backups.PGBackRest.Restore = &v1beta1.PGBackRestRestore{
+		Enabled: initialize.Bool(true),
+	}
+	cluster.Annotations = map[string]string{
+		naming.AllowUpgrade:      "up",
+		naming.PGBackRestRestore: "one",
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Status.ChangeProtection = &v1beta1.ChangeProtectionStatus{Pending: "image x"}
+
+		assert.Assert(t, !reconciler.reconcileChangeProtection(cluster, instances))
+		assert.Assert(t, cluster.Status.ChangeProtection == nil)
+		assert.Equal(t, cluster.Spec.Image, "postgres:2")
+	})
+
+	t.Run("Changes", func(t *testing.T) {
+		assert.DeepEqual(t, disruptiveChanges(cluster, instances),
+			[]string{"image postgres:2", "restore one", "upgrade up"})
+
+		cluster := cluster.DeepCopy()
+		cluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{
+			Restore: &v1beta1.PGBackRestJobStatus{ID: "one"},
+		}
+		cluster.Status.UpgradeUser = &v1beta1.UpgradeUserStatus{Completed: "up"}
+		cluster.Spec.Image = "postgres:1"
+		assert.Assert(t, disruptiveChanges(cluster, instances) == nil)
+	})
+
+	t.Run("Held", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.ChangeProtection = v1beta1.ChangeProtectionSnapshotBeforeRestart
+
+		assert.Assert(t, reconciler.reconcileChangeProtection(cluster, instances))
+		assert.Equal(t, cluster.Spec.Image, "postgres:1", "expected the running image")
+		assert.Equal(t, cluster.Status.ChangeProtection.Pending,
+			"image postgres:2, restore one, upgrade up")
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.ChangesHeld)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionTrue)
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "ChangesHeld"))
+	})
+
+	t.Run("Protected", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.ChangeProtection = v1beta1.ChangeProtectionSnapshotBeforeRestart
+		cluster.Status.ChangeProtection = &v1beta1.ChangeProtectionStatus{
+			Pending: "image postgres:2, restore one, upgrade up",
+			History: []v1beta1.ProtectedChange{
+				{Changes: "image postgres:2, restore one, upgrade up"},
+			},
+		}
+
+		assert.Assert(t, !reconciler.reconcileChangeProtection(cluster, instances))
+		assert.Equal(t, cluster.Spec.Image, "postgres:2")
+		assert.Equal(t, cluster.Status.ChangeProtection.Pending, "")
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.ChangesHeld)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+	})
+}
@@ -0,0 +1,164 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// checkpointTuningInterval is how often the WAL location of the primary is
+// observed to measure how fast it writes WAL.
+const checkpointTuningInterval = 5 * time.Minute
+
+// parseWALLocation returns the byte position of a PostgreSQL WAL location, as
+// printed by pg_current_wal_lsn.
+// - https://www.postgresql.org/docs/current/datatype-pg-lsn.html
+func parseWALLocation(location string) (uint64, error) {
+	high, low, ok := strings.Cut(strings.TrimSpace(location), "/")
+	if !ok {
+		return 0, errors.Errorf("unexpected WAL location: %q", location)
+	}
+	h, err := strconv.ParseUint(high, 16, 32)
+	if err != nil {
+		return 0, errors.WithStack(err)
+	}
+	l, err := strconv.ParseUint(low, 16, 32)
+	return h<<32 | l, errors.WithStack(err)
+}
+
+// nextWriteRate returns the rate at which to tune checkpoints given the rate
+// from before and a new sample. Increases are followed immediately so that
+// checkpoints keep up with bursts; decreases are followed gradually so that
+// parameters do not change with every lull.
+func nextWriteRate(previous, sample int64) int64 {
+	if sample >= previous {
+		return sample
+	}
+	return (previous*3 + sample) / 4
+}
+
+// checkpointsChanged returns whether or not the checkpoint parameters of next
+// differ enough from those of status to change PostgreSQL. Small changes to
+// max_wal_size are ignored so that PostgreSQL is not reloaded over noise.
+func checkpointsChanged(status *v1beta1.CheckpointTuningStatus, maxWALSize, timeout string) bool {
+	if status.MaxWALSize == "" || status.CheckpointTimeout != timeout {
+		return true
+	}
+
+	var before, after int64
+	_, err1 := fmt.Sscanf(status.MaxWALSize, "%dMB", &before)
+	_, err2 := fmt.Sscanf(maxWALSize, "%dMB", &after)
+	if err1 != nil || err2 != nil {
+		return status.MaxWALSize != maxWALSize
+	}
+	return after*4 < before*3 || after*4 > before*5
+}
+
+// reconcileCheckpointTuning measures how fast the primary of cluster writes
+// WAL and derives checkpoint parameters from that with
+// [postgres.TuneCheckpoints]. The measurements and decisions are kept in the
+// status of cluster, and an event is recorded each time the parameters change.
+// WAL is written without changing any Kubernetes objects, so this returns a
+// result that measures it again later.
+func (r *Reconciler) reconcileCheckpointTuning(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) reconcile.Result {
+	log := logging.FromContext(ctx)
+
+	if !postgres.CheckpointTuningEnabled(cluster) {
+		cluster.Status.CheckpointTuning = nil
+		return reconcile.Result{}
+	}
+	if cluster.Status.CheckpointTuning == nil {
+		cluster.Status.CheckpointTuning = &v1beta1.CheckpointTuningStatus{}
+	}
+	status := cluster.Status.CheckpointTuning
+
+	pod, _ := instances.writablePod(naming.ContainerDatabase)
+	if pod == nil {
+		return reconcile.Result{}
+	}
+
+	now := metav1.Now()
+	if status.ObservedTime != nil {
+		if elapsed := now.Sub(status.ObservedTime.Time); elapsed < checkpointTuningInterval {
+			return reconcile.Result{RequeueAfter: checkpointTuningInterval - elapsed}
+		}
+	}
+	next := reconcile.Result{RequeueAfter: checkpointTuningInterval}
+
+	var stdout, stderr bytes.Buffer
+	err := r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase, nil, &stdout, &stderr,
+		"psql", "-Xw", "--tuples-only", "--no-align",
+		"--command=SELECT pg_catalog.pg_current_wal_lsn()")
+
+	log.V(1).Info("observed WAL location",
+		"stdout", stdout.String(), "stderr", stderr.String())
+
+	var current uint64
+	if err == nil {
+		current, err = parseWALLocation(stdout.String())
+	}
+	if err != nil {
+		log.Error(err, "unable to observe the WAL location of the primary")
+		return next
+	}
+
+	// The first observation, and any after the location moves backward such as
+	// after a restore, only start the next measurement.
+	previous, err := parseWALLocation(status.WALLocation)
+	measured := err == nil && status.ObservedTime != nil && current >= previous
+	if measured {
+		seconds := int64(now.Sub(status.ObservedTime.Time).Seconds())
+		if seconds < 1 {
+			seconds = 1
+		}
+		status.WriteRate = nextWriteRate(status.WriteRate, int64(current-previous)/seconds)
+	}
+	status.WALLocation = strings.TrimSpace(stdout.String())
+	status.ObservedTime = &now
+
+	if !measured {
+		return next
+	}
+
+	maxWALSize, timeout := postgres.TuneCheckpoints(cluster, status.WriteRate)
+	if checkpointsChanged(status, maxWALSize, timeout) {
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "CheckpointsTuned",
+			"Set max_wal_size to %s and checkpoint_timeout to %s for %s of WAL per second",
+			maxWALSize, timeout, resource.NewQuantity(status.WriteRate, resource.BinarySI))
+
+		status.MaxWALSize = maxWALSize
+		status.CheckpointTimeout = timeout
+		status.TunedTime = &now
+	}
+	return next
+}
@@ -0,0 +1,152 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestParseWALLocation(t *testing.T) {
+	position, err := parseWALLocation("1/A0000028\n")
+	assert.NilError(t, err)
+	assert.Equal(t, position, uint64(0x1_A0000028))
+
+	_, err = parseWALLocation("")
+	assert.ErrorContains(t, err, "unexpected")
+
+	_, err = parseWALLocation("1/XYZ")
+	assert.ErrorContains(t, err, "invalid")
+}
+
+func TestCheckpointsChanged(t *testing.T) {
+	status := &v1beta1.CheckpointTuningStatus{}
+	assert.Assert(t, checkpointsChanged(status, "1024MB", "15min"))
+
+	status.MaxWALSize, status.CheckpointTimeout = "1024MB", "15min"
+	assert.Assert(t, !checkpointsChanged(status, "1024MB", "15min"))
+	assert.Assert(t, !checkpointsChanged(status, "1200MB", "15min"))
+	assert.Assert(t, checkpointsChanged(status, "1400MB", "15min"))
+	assert.Assert(t, checkpointsChanged(status, "700MB", "15min"))
+	assert.Assert(t, checkpointsChanged(status, "1024MB", "10min"))
+}
+
+func TestReconcileCheckpointTuning(t *testing.T) {
+	ctx := context.Background()
+	const MiB = int64(1024 * 1024)
+
+	pod := &corev1.Pod{}
+	pod.Namespace, pod.Name = "ns1", "hippo-00-abcd-0"
+	pod.Labels = map[string]string{naming.LabelRole: naming.RolePatroniLeader}
+	pod.Annotations = map[string]string{"status": `{"role":"master"}`}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  naming.ContainerDatabase,
+		State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+	}}
+
+	instances := &observedInstances{forCluster: []*Instance{
+		{Name: "hippo-00-abcd", Pods: []*corev1.Pod{pod}},
+	}}
+
+	var location string
+	var calls int
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Recorder: recorder,
+		PodExec: func(namespace, pod, container string, _ io.Reader, stdout, _ io.Writer, command ...string) error {
+			assert.Equal(t, container, naming.ContainerDatabase)
+			assert.Equal(t, command[0], "psql")
+			calls++
+			_, err := stdout.Write([]byte(location + "\n"))
+			return err
+		},
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace = "ns1"
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{}}
+	cluster.Spec.InstanceSets[0].DataVolumeClaimSpec.Resources.Requests = corev1.ResourceList{
+		corev1.ResourceStorage: resource.MustParse("100Gi"),
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Status.CheckpointTuning = &v1beta1.CheckpointTuningStatus{MaxWALSize: "1024MB"}
+
+		result := reconciler.reconcileCheckpointTuning(ctx, cluster, instances)
+		assert.Equal(t, result.RequeueAfter, time.Duration(0))
+		assert.Assert(t, cluster.Status.CheckpointTuning == nil)
+		assert.Equal(t, calls, 0)
+	})
+
+	cluster.Spec.Tuning = &v1beta1.TuningSpec{Mode: "Manual", Checkpoints: "Observed"}
+
+	t.Run("Observed", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+
+		// The first observation measures nothing.
+		location = "0/10000000"
+		result := reconciler.reconcileCheckpointTuning(ctx, cluster, instances)
+		assert.Equal(t, result.RequeueAfter, checkpointTuningInterval)
+
+		status := cluster.Status.CheckpointTuning
+		assert.Equal(t, status.WALLocation, "0/10000000")
+		assert.Assert(t, status.ObservedTime != nil)
+		assert.Equal(t, status.MaxWALSize, "")
+
+		// Nothing is observed until the interval passes.
+		calls = 0
+		result = reconciler.reconcileCheckpointTuning(ctx, cluster, instances)
+		assert.Assert(t, result.RequeueAfter > 0 && result.RequeueAfter <= checkpointTuningInterval)
+		assert.Equal(t, calls, 0)
+
+		// 300MiB over 5 minutes is 1MiB per second.
+		status.ObservedTime = &metav1.Time{Time: time.Now().Add(-5 * time.Minute)}
+		location = "0/22C00000"
+		reconciler.reconcileCheckpointTuning(ctx, cluster, instances)
+		assert.Equal(t, calls, 1)
+		assert.Equal(t, status.WriteRate, 1*MiB)
+		assert.Equal(t, status.MaxWALSize, "1800MB")
+		assert.Equal(t, status.CheckpointTimeout, "15min")
+		assert.Assert(t, status.TunedTime != nil)
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "max_wal_size to 1800MB"))
+
+		// A brief lull lowers the rate gradually and changes nothing.
+		status.ObservedTime = &metav1.Time{Time: time.Now().Add(-5 * time.Minute)}
+		reconciler.reconcileCheckpointTuning(ctx, cluster, instances)
+		assert.Equal(t, status.WriteRate, 3*MiB/4)
+		assert.Equal(t, status.MaxWALSize, "1800MB")
+		assert.Equal(t, len(recorder.Events), 0)
+
+		// A location that moves backward starts a new measurement.
+		status.ObservedTime = &metav1.Time{Time: time.Now().Add(-5 * time.Minute)}
+		location = "0/01000000"
+		reconciler.reconcileCheckpointTuning(ctx, cluster, instances)
+		assert.Equal(t, status.WALLocation, "0/01000000")
+		assert.Equal(t, status.WriteRate, 3*MiB/4)
+	})
+}
@@ -0,0 +1,475 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/config"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/servicemesh"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	dataChecksumsEnable = "enable"
+	dataChecksumsVerify = "verify"
+)
+
+// +kubebuilder:rbac:groups="batch",resources="jobs",verbs={list,create,patch}
+
+// reconcileDataChecksumsEnablement enables data page checksums on the volumes
+// of every instance when they are requested but were not enabled at initdb.
+// This requires that PostgreSQL be stopped, so nothing happens until the cluster
+// is shutdown. A boolean value is returned to indicate whether the main control
+// loop should return early while the enablement Jobs are running or have failed.
+func (r *Reconciler) reconcileDataChecksumsEnablement(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+	clusterVolumes []corev1.PersistentVolumeClaim,
+) (bool, error) {
+	// Only act on clusters that have been observed to be without checksums
+	// when the spec asks for them.
+	if cluster.Spec.DataChecksums == nil ||
+		!postgres.DataChecksumsEnabled(cluster) ||
+		cluster.Status.DataChecksums == nil ||
+		cluster.Status.DataChecksums.Enabled {
+		return false, nil
+	}
+
+	// The `pg_checksums --enable` option was introduced in PostgreSQL v12.
+	if cluster.Spec.PostgresVersion < 12 {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "DataChecksumsUnsupported",
+			"Data page checksums cannot be enabled on PostgreSQL %d",
+			cluster.Spec.PostgresVersion)
+		return false, nil
+	}
+
+	jobs := &batchv1.JobList{}
+	if err := r.Client.List(ctx, jobs, &client.ListOptions{
+		Namespace: cluster.Namespace,
+		LabelSelector: naming.DataChecksumsJobLabels(
+			cluster.Name, dataChecksumsEnable).AsSelector(),
+	}); err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	// Instances must not start on data directories that are being rewritten,
+	// even when the cluster is no longer shutdown.
+	active := false
+	for i := range jobs.Items {
+		if !jobCompleted(&jobs.Items[i]) && !jobFailed(&jobs.Items[i]) {
+			active = true
+		}
+	}
+
+	// Wait for the cluster to be shutdown and for every instance Pod to stop.
+	if cluster.Spec.Shutdown == nil || !*cluster.Spec.Shutdown {
+		return active, nil
+	}
+	for _, instance := range instances.forCluster {
+		if len(instance.Pods) > 0 {
+			return active, nil
+		}
+	}
+
+	// Group the volumes of each instance so they can be mounted together.
+	// `pg_checksums` rewrites every data file, including those in tablespaces.
+	volumes := make(map[string][]corev1.PersistentVolumeClaim)
+	for _, pvc := range clusterVolumes {
+		if instance := pvc.Labels[naming.LabelInstance]; instance != "" {
+			volumes[instance] = append(volumes[instance], pvc)
+		}
+	}
+
+	running := false
+	for instance := range volumes {
+		job := &batchv1.Job{ObjectMeta: naming.DataChecksumsEnableJob(cluster, instance)}
+
+		completed, failed, found := false, false, false
+		for i := range jobs.Items {
+			if jobs.Items[i].Name == job.Name {
+				found = true
+				completed = jobCompleted(&jobs.Items[i])
+				failed = jobFailed(&jobs.Items[i])
+			}
+		}
+		if completed {
+			continue
+		}
+		running = true
+
+		// A failed Job stays in place until someone looks at its logs and
+		// deletes it to try again.
+		if failed {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "DataChecksumsFailed",
+				"Unable to enable data page checksums on instance %q; see the logs of Job %q",
+				instance, job.Name)
+		}
+
+		if !found {
+			job.Spec = generateDataChecksumsEnableJob(cluster, volumes[instance])
+			job.Annotations = naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil())
+			job.Labels = naming.Merge(cluster.Spec.Metadata.GetLabelsOrNil(),
+				job.Spec.Template.Labels)
+
+			job.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("Job"))
+			err := errors.WithStack(r.setControllerReference(cluster, job))
+			if err == nil {
+				err = r.apply(ctx, job)
+			}
+			if err != nil {
+				return true, err
+			}
+		}
+	}
+
+	// Every instance has checksums now.
+	if !running && len(volumes) > 0 {
+		cluster.Status.DataChecksums.Enabled = true
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, "DataChecksumsEnabled",
+			"Enabled data page checksums on every instance")
+	}
+
+	return running, nil
+}
+
+// generateDataChecksumsEnableJob returns a Job spec that runs `pg_checksums`
+// against the data directory on volumes. It does nothing when checksums are
+// already enabled so that it can be retried safely.
+func generateDataChecksumsEnableJob(
+	cluster *v1beta1.PostgresCluster, volumes []corev1.PersistentVolumeClaim,
+) batchv1.JobSpec {
+	const script = `
+declare -r datadir="$1"
+if pg_controldata "${datadir}" | grep -q '^Data page checksum version:[[:space:]]*0$'
+then pg_checksums --enable --progress --pgdata="${datadir}"
+else echo 'Data page checksums are already enabled'
+fi
+`
+	labels := naming.Merge(cluster.Spec.Metadata.GetLabelsOrNil(),
+		naming.DataChecksumsJobLabels(cluster.Name, dataChecksumsEnable))
+
+	container := corev1.Container{
+		Command: []string{"bash", "-ceu", "--", script, "-",
+			postgres.DataDirectory(cluster)},
+		Image:           config.PostgresContainerImage(cluster),
+		ImagePullPolicy: cluster.Spec.ImagePullPolicy,
+		Name:            naming.ContainerJobDataChecksums,
+		SecurityContext: initialize.RestrictedSecurityContext(),
+	}
+	if len(cluster.Spec.InstanceSets) > 0 {
+		container.Resources = cluster.Spec.InstanceSets[0].Resources
+	}
+
+	var podVolumes []corev1.Volume
+	for _, pvc := range volumes {
+		var mount corev1.VolumeMount
+		switch pvc.Labels[naming.LabelRole] {
+		case naming.RolePostgresData:
+			mount = postgres.DataVolumeMount()
+		case naming.RolePostgresWAL:
+			mount = postgres.WALVolumeMount()
+		case naming.RolePostgresTemp:
+			mount = postgres.TempVolumeMount()
+		case "tablespace":
+			mount = postgres.TablespaceVolumeMount(pvc.Labels[naming.LabelData])
+		default:
+			continue
+		}
+		container.VolumeMounts = append(container.VolumeMounts, mount)
+		podVolumes = append(podVolumes, corev1.Volume{
+			Name: mount.Name,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: pvc.Name,
+				},
+			},
+		})
+	}
+
+	spec := batchv1.JobSpec{
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: servicemesh.NoProxyAnnotations(cluster),
+				Labels:      labels,
+			},
+			Spec: corev1.PodSpec{
+				// Set the image pull secrets, if any exist.
+				// This is set here rather than using the service account due to the lack
+				// of propagation to existing pods when the CRD is updated:
+				// https://github.com/kubernetes/kubernetes/issues/88456
+				ImagePullSecrets: cluster.Spec.ImagePullSecrets,
+				Containers:       []corev1.Container{container},
+				SecurityContext:  postgres.PodSecurityContext(cluster),
+				// Set RestartPolicy to "Never" since we want a new Pod to be
+				// created by the Job controller when there is a failure
+				// (instead of the container simply restarting).
+				RestartPolicy: corev1.RestartPolicyNever,
+				// These Jobs don't make Kubernetes API calls, so we can just
+				// use the default ServiceAccount and not mount its credentials.
+				AutomountServiceAccountToken: initialize.Bool(false),
+				EnableServiceLinks:           initialize.Bool(false),
+				Volumes:                      podVolumes,
+			},
+		},
+	}
+
+	// set the priority class name, if it exists
+	if len(cluster.Spec.InstanceSets) > 0 &&
+		cluster.Spec.InstanceSets[0].PriorityClassName != nil {
+		spec.Template.Spec.PriorityClassName =
+			*cluster.Spec.InstanceSets[0].PriorityClassName
+	}
+
+	return spec
+}
+
+// +kubebuilder:rbac:groups="batch",resources="cronjobs",verbs={get,create,patch,delete}
+// +kubebuilder:rbac:groups="batch",resources="jobs",verbs={list}
+
+// reconcileDataChecksums observes whether or not PostgreSQL has data page
+// checksums and manages the CronJob that verifies them.
+func (r *Reconciler) reconcileDataChecksums(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+	replicationSecret *corev1.Secret,
+) error {
+	if cluster.Spec.DataChecksums == nil {
+		cluster.Status.DataChecksums = nil
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.DataChecksumsVerified)
+	}
+
+	err := r.observeDataChecksums(ctx, cluster, instances)
+
+	cronjob := &batchv1.CronJob{ObjectMeta: naming.DataChecksumsVerifyCronJob(cluster)}
+	verify := cluster.Spec.DataChecksums != nil &&
+		cluster.Spec.DataChecksums.Verification != nil &&
+		cluster.Status.DataChecksums != nil &&
+		cluster.Status.DataChecksums.Enabled
+
+	if err == nil && !verify {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.DataChecksumsVerified)
+
+		err = errors.WithStack(r.Client.Get(ctx, client.ObjectKeyFromObject(cronjob), cronjob))
+		if err == nil {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, cronjob))
+		}
+		return client.IgnoreNotFound(err)
+	}
+
+	if err == nil {
+		generateDataChecksumsVerifyCronJob(cluster, replicationSecret, cronjob)
+
+		cronjob.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("CronJob"))
+		err = errors.WithStack(r.setControllerReference(cluster, cronjob))
+	}
+	if err == nil {
+		err = r.apply(ctx, cronjob)
+	}
+
+	jobs := &batchv1.JobList{}
+	if err == nil {
+		err = errors.WithStack(r.Client.List(ctx, jobs, &client.ListOptions{
+			Namespace: cluster.Namespace,
+			LabelSelector: naming.DataChecksumsJobLabels(
+				cluster.Name, dataChecksumsVerify).AsSelector(),
+		}))
+	}
+	if err == nil {
+		setDataChecksumsVerified(cluster, jobs.Items)
+	}
+
+	return err
+}
+
+// observeDataChecksums asks PostgreSQL whether or not data page checksums are
+// enabled when that has not been observed already.
+func (r *Reconciler) observeDataChecksums(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) error {
+	if cluster.Spec.DataChecksums == nil || cluster.Status.DataChecksums != nil {
+		return nil
+	}
+
+	pod, _ := instances.writablePod(naming.ContainerDatabase)
+	if pod == nil {
+		return nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	err := errors.WithStack(r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase,
+		nil, &stdout, &stderr, "psql", "-Xw", "--tuples-only", "--no-align",
+		"--command=SHOW data_checksums"))
+
+	logging.FromContext(ctx).V(1).Info("observed data checksums",
+		"stdout", stdout.String(), "stderr", stderr.String())
+
+	if err == nil {
+		cluster.Status.DataChecksums = &v1beta1.DataChecksumsStatus{
+			Enabled: strings.TrimSpace(stdout.String()) == "on",
+		}
+	}
+	return err
+}
+
+// generateDataChecksumsVerifyCronJob populates cronjob with a schedule that
+// streams a base backup of cluster to nowhere. The server verifies data page
+// checksums while sending a base backup and the client exits with an error
+// when any fail.
+// - https://www.postgresql.org/docs/current/app-pgbasebackup.html
+func generateDataChecksumsVerifyCronJob(
+	cluster *v1beta1.PostgresCluster, replicationSecret *corev1.Secret,
+	cronjob *batchv1.CronJob,
+) {
+	verification := cluster.Spec.DataChecksums.Verification
+
+	cronjob.Annotations = naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil())
+	cronjob.Labels = naming.Merge(cluster.Spec.Metadata.GetLabelsOrNil(),
+		naming.DataChecksumsJobLabels(cluster.Name, dataChecksumsVerify))
+
+	// PostgreSQL v15 can discard the backup on the server. Older versions
+	// write a tar stream, which only works for clusters without tablespaces.
+	args := []string{"pg_basebackup", "--wal-method=none", "--checkpoint=spread"}
+	if cluster.Spec.PostgresVersion >= 15 {
+		args = append(args, "--target=blackhole")
+	} else {
+		args = append(args, "--pgdata=-", "--format=tar")
+	}
+	if cluster.Spec.PostgresVersion >= 13 {
+		args = append(args, "--no-manifest")
+	}
+	script := strings.Join(args, " ") + " > /dev/null"
+
+	const certDirectory = "/pgconf/tls"
+	container := corev1.Container{
+		Command:         servicemesh.JobCommand(cluster, []string{"bash", "-ceu", "--", script}),
+		Image:           config.PostgresContainerImage(cluster),
+		ImagePullPolicy: cluster.Spec.ImagePullPolicy,
+		Name:            naming.ContainerJobDataChecksums,
+		Resources:       verification.Resources,
+		SecurityContext: initialize.RestrictedSecurityContext(),
+		Env: []corev1.EnvVar{
+			{Name: "PGHOST", Value: fmt.Sprintf("%s.%s.svc",
+				naming.ClusterPrimaryService(cluster).Name, cluster.Namespace)},
+			{Name: "PGPORT", Value: fmt.Sprint(*cluster.Spec.Port)},
+			{Name: "PGUSER", Value: postgres.ReplicationUser},
+			{Name: "PGSSLMODE", Value: "verify-ca"},
+			{Name: "PGSSLCERT", Value: certDirectory + "/" + naming.ReplicationCert},
+			{Name: "PGSSLKEY", Value: certDirectory + "/" + naming.ReplicationPrivateKey},
+			{Name: "PGSSLROOTCERT", Value: certDirectory + "/" + naming.ReplicationCACert},
+		},
+		VolumeMounts: []corev1.VolumeMount{{
+			Name: naming.CertVolume, MountPath: certDirectory, ReadOnly: true,
+		}},
+	}
+
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: naming.Merge(cronjob.Annotations,
+				servicemesh.JobAnnotations(cluster)),
+			Labels: cronjob.Labels,
+		},
+		Spec: corev1.PodSpec{
+			// Set the image pull secrets, if any exist.
+			// This is set here rather than using the service account due to the lack
+			// of propagation to existing pods when the CRD is updated:
+			// https://github.com/kubernetes/kubernetes/issues/88456
+			ImagePullSecrets: cluster.Spec.ImagePullSecrets,
+			Containers:       []corev1.Container{container},
+			SecurityContext:  postgres.PodSecurityContext(cluster),
+			RestartPolicy:    corev1.RestartPolicyNever,
+			// These Jobs don't make Kubernetes API calls, so we can just
+			// use the default ServiceAccount and not mount its credentials.
+			AutomountServiceAccountToken: initialize.Bool(false),
+			EnableServiceLinks:           initialize.Bool(false),
+			Volumes: []corev1.Volume{{
+				Name: naming.CertVolume,
+				VolumeSource: corev1.VolumeSource{
+					Secret: &corev1.SecretVolumeSource{
+						SecretName: replicationSecret.Name,
+						// The client key must not be readable by others.
+						// - https://www.postgresql.org/docs/current/libpq-ssl.html
+						DefaultMode: initialize.Int32(0o600),
+						Items: []corev1.KeyToPath{
+							{Key: naming.ReplicationCert, Path: naming.ReplicationCert},
+							{Key: naming.ReplicationPrivateKey, Path: naming.ReplicationPrivateKey},
+							{Key: naming.ReplicationCACert, Path: naming.ReplicationCACert},
+						},
+					},
+				},
+			}},
+		},
+	}
+
+	// Suspend the CronJob when shutdown or read-only. Any Jobs that have
+	// already started will continue.
+	suspend := (cluster.Spec.Shutdown != nil && *cluster.Spec.Shutdown) ||
+		(cluster.Spec.Standby != nil && cluster.Spec.Standby.Enabled)
+
+	cronjob.Spec = batchv1.CronJobSpec{
+		Schedule:          verification.Schedule,
+		Suspend:           &suspend,
+		TimeZone:          cronJobTimeZone(cluster),
+		ConcurrencyPolicy: batchv1.ForbidConcurrent,
+		JobTemplate: batchv1.JobTemplateSpec{
+			ObjectMeta: template.ObjectMeta,
+			Spec: batchv1.JobSpec{
+				BackoffLimit:            initialize.Int32(0),
+				TTLSecondsAfterFinished: verification.TTLSecondsAfterFinished,
+				Template:                template,
+			},
+		},
+	}
+}
+
+// setDataChecksumsVerified sets the DataChecksumsVerified condition and last
+// verification time of cluster according to the most recently finished Job.
+func setDataChecksumsVerified(cluster *v1beta1.PostgresCluster, jobs []batchv1.Job) {
+	latest, finished := latestFinishedJob(jobs)
+	if latest == nil {
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               v1beta1.DataChecksumsVerified,
+		ObservedGeneration: cluster.GetGeneration(),
+	}
+	if jobCompleted(latest) {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "VerificationSucceeded"
+		condition.Message = "Every data page passed checksum verification"
+	} else {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "VerificationFailed"
+		condition.Message = fmt.Sprintf(
+			"Checksum verification failed; see the logs of Job %q", latest.Name)
+	}
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+	cluster.Status.DataChecksums.LastVerificationTime = finished
+}
@@ -0,0 +1,275 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestReconcileDataChecksumsEnablement(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+	cluster.Spec.PostgresVersion = 16
+	cluster.Spec.DataChecksums = &v1beta1.DataChecksumsSpec{}
+	cluster.Status.DataChecksums = &v1beta1.DataChecksumsStatus{Enabled: false}
+
+	job := &batchv1.Job{ObjectMeta: naming.DataChecksumsEnableJob(cluster, "hippo-00-abcd")}
+	job.Labels = naming.DataChecksumsJobLabels(cluster.Name, dataChecksumsEnable)
+
+	reconcile := func(job *batchv1.Job) (bool, *record.FakeRecorder) {
+		recorder := record.NewFakeRecorder(10)
+		reconciler := &Reconciler{
+			Client:   fake.NewClientBuilder().WithObjects(job).Build(),
+			Recorder: recorder,
+		}
+		returnEarly, err := reconciler.reconcileDataChecksumsEnablement(
+			ctx, cluster, &observedInstances{}, []corev1.PersistentVolumeClaim{{
+				ObjectMeta: metav1.ObjectMeta{Name: "pgdata", Labels: map[string]string{
+					naming.LabelInstance: "hippo-00-abcd",
+					naming.LabelRole:     naming.RolePostgresData,
+				}},
+			}})
+		assert.NilError(t, err)
+		return returnEarly, recorder
+	}
+
+	t.Run("RunningWithoutShutdown", func(t *testing.T) {
+		cluster.Spec.Shutdown = initialize.Bool(false)
+
+		returnEarly, _ := reconcile(job.DeepCopy())
+		assert.Assert(t, returnEarly, "expected instances to wait for the Job")
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		cluster.Spec.Shutdown = initialize.Bool(true)
+
+		failed := job.DeepCopy()
+		failed.Status.Conditions = []batchv1.JobCondition{{
+			Type: batchv1.JobFailed, Status: corev1.ConditionTrue,
+		}}
+
+		returnEarly, recorder := reconcile(failed)
+		assert.Assert(t, returnEarly)
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Assert(t, strings.Contains(<-recorder.Events, "DataChecksumsFailed"))
+		assert.Assert(t, !cluster.Status.DataChecksums.Enabled)
+	})
+}
+
+func TestGenerateDataChecksumsEnableJob(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Name = "hippo"
+	cluster.Namespace = "ns1"
+	cluster.Spec.PostgresVersion = 14
+	cluster.Spec.Image = "some-image"
+
+	volumes := []corev1.PersistentVolumeClaim{
+		{ObjectMeta: metav1.ObjectMeta{Name: "data", Labels: map[string]string{
+			naming.LabelRole: naming.RolePostgresData,
+		}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "wal", Labels: map[string]string{
+			naming.LabelRole: naming.RolePostgresWAL,
+		}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "space", Labels: map[string]string{
+			naming.LabelRole: "tablespace",
+			naming.LabelData: "trial",
+		}}},
+	}
+
+	spec := generateDataChecksumsEnableJob(cluster, volumes)
+
+	assert.DeepEqual(t, spec.Template.Labels, map[string]string{
+		naming.LabelCluster:       "hippo",
+		naming.LabelDataChecksums: "enable",
+	})
+
+	pod := spec.Template.Spec
+	assert.Equal(t, pod.RestartPolicy, corev1.RestartPolicyNever)
+	assert.Equal(t, *pod.AutomountServiceAccountToken, false)
+	assert.Equal(t, len(pod.Containers), 1)
+
+	container := pod.Containers[0]
+	assert.Equal(t, container.Image, "some-image")
+	assert.Equal(t, container.Command[len(container.Command)-1], "/pgdata/pg14")
+	assert.Assert(t, cmp.Contains(strings.Join(container.Command, "\n"),
+		`pg_checksums --enable`))
+
+	assert.Assert(t, cmp.MarshalMatches(container.VolumeMounts, `
+- mountPath: /pgdata
+  name: postgres-data
+- mountPath: /pgwal
+  name: postgres-wal
+- mountPath: /tablespaces/trial
+  name: tablespace-trial
+	`))
+	assert.Assert(t, cmp.MarshalMatches(pod.Volumes, `
+- name: postgres-data
+  persistentVolumeClaim:
+    claimName: data
+- name: postgres-wal
+  persistentVolumeClaim:
+    claimName: wal
+- name: tablespace-trial
+  persistentVolumeClaim:
+    claimName: space
+	`))
+}
+
+func TestGenerateDataChecksumsVerifyCronJob(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Name = "hippo"
+	cluster.Namespace = "ns1"
+	cluster.Spec.Port = initialize.Int32(5432)
+	cluster.Spec.DataChecksums = &v1beta1.DataChecksumsSpec{
+		Verification: &v1beta1.DataChecksumsVerification{
+			Schedule:                "0 1 * * 6",
+			TTLSecondsAfterFinished: initialize.Int32(100),
+		},
+	}
+	secret := &corev1.Secret{ObjectMeta: naming.ReplicationClientCertSecret(cluster)}
+
+	for _, tt := range []struct {
+		version int
+		command string
+	}{
+		{version: 12, command: "pg_basebackup --wal-method=none --checkpoint=spread --pgdata=- --format=tar > /dev/null"},
+		{version: 14, command: "pg_basebackup --wal-method=none --checkpoint=spread --pgdata=- --format=tar --no-manifest > /dev/null"},
+		{version: 16, command: "pg_basebackup --wal-method=none --checkpoint=spread --target=blackhole --no-manifest > /dev/null"},
+	} {
+		cluster.Spec.PostgresVersion = tt.version
+
+		cronjob := &batchv1.CronJob{ObjectMeta: naming.DataChecksumsVerifyCronJob(cluster)}
+		generateDataChecksumsVerifyCronJob(cluster, secret, cronjob)
+
+		assert.Equal(t, cronjob.Spec.Schedule, "0 1 * * 6")
+		assert.Equal(t, *cronjob.Spec.Suspend, false)
+		assert.Equal(t, *cronjob.Spec.JobTemplate.Spec.TTLSecondsAfterFinished, int32(100))
+
+		container := cronjob.Spec.JobTemplate.Spec.Template.Spec.Containers[0]
+		assert.DeepEqual(t, container.Command, []string{"bash", "-ceu", "--", tt.command})
+	}
+
+	t.Run("Connection", func(t *testing.T) {
+		cronjob := &batchv1.CronJob{ObjectMeta: naming.DataChecksumsVerifyCronJob(cluster)}
+		generateDataChecksumsVerifyCronJob(cluster, secret, cronjob)
+
+		pod := cronjob.Spec.JobTemplate.Spec.Template.Spec
+		assert.Assert(t, cmp.MarshalMatches(pod.Containers[0].Env, `
+- name: PGHOST
+  value: hippo-primary.ns1.svc
+- name: PGPORT
+  value: "5432"
+- name: PGUSER
+  value: _crunchyrepl
+- name: PGSSLMODE
+  value: verify-ca
+- name: PGSSLCERT
+  value: /pgconf/tls/tls.crt
+- name: PGSSLKEY
+  value: /pgconf/tls/tls.key
+- name: PGSSLROOTCERT
+  value: /pgconf/tls/ca.crt
+		`))
+		assert.Equal(t, pod.Volumes[0].Secret.SecretName, "hippo-replication-cert")
+		assert.Equal(t, *pod.Volumes[0].Secret.DefaultMode, int32(0o600))
+	})
+
+	t.Run("Shutdown", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Shutdown = initialize.Bool(true)
+
+		cronjob := &batchv1.CronJob{ObjectMeta: naming.DataChecksumsVerifyCronJob(cluster)}
+		generateDataChecksumsVerifyCronJob(cluster, secret, cronjob)
+		assert.Equal(t, *cronjob.Spec.Suspend, true)
+	})
+}
+
+func TestSetDataChecksumsVerified(t *testing.T) {
+	finished := func(name string, kind batchv1.JobConditionType, at time.Time) batchv1.Job {
+		job := batchv1.Job{}
+		job.Name = name
+		job.Status.Conditions = []batchv1.JobCondition{{
+			Type: kind, Status: corev1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(at),
+		}}
+		return job
+	}
+
+	now := time.Now().Truncate(time.Second)
+
+	t.Run("NoJobs", func(t *testing.T) {
+		cluster := &v1beta1.PostgresCluster{}
+		cluster.Status.DataChecksums = &v1beta1.DataChecksumsStatus{Enabled: true}
+
+		setDataChecksumsVerified(cluster, nil)
+		assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions,
+			v1beta1.DataChecksumsVerified) == nil)
+		assert.Assert(t, cluster.Status.DataChecksums.LastVerificationTime == nil)
+	})
+
+	t.Run("Succeeded", func(t *testing.T) {
+		cluster := &v1beta1.PostgresCluster{}
+		cluster.Status.DataChecksums = &v1beta1.DataChecksumsStatus{Enabled: true}
+
+		setDataChecksumsVerified(cluster, []batchv1.Job{
+			finished("old", batchv1.JobFailed, now.Add(-time.Hour)),
+			finished("new", batchv1.JobComplete, now),
+			{ObjectMeta: metav1.ObjectMeta{Name: "running"}},
+		})
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions,
+			v1beta1.DataChecksumsVerified)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionTrue)
+		assert.Equal(t, condition.Reason, "VerificationSucceeded")
+		assert.Assert(t, cluster.Status.DataChecksums.LastVerificationTime.Time.Equal(now))
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		cluster := &v1beta1.PostgresCluster{}
+		cluster.Status.DataChecksums = &v1beta1.DataChecksumsStatus{Enabled: true}
+
+		setDataChecksumsVerified(cluster, []batchv1.Job{
+			finished("old", batchv1.JobComplete, now.Add(-time.Hour)),
+			finished("new", batchv1.JobFailed, now),
+		})
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions,
+			v1beta1.DataChecksumsVerified)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "VerificationFailed")
+		assert.Assert(t, cmp.Contains(condition.Message, `"new"`))
+	})
+}
@@ -91,6 +91,7 @@ func (r *Reconciler) reconcileClusterPodService(
 	clusterPodService.Spec.Selector = map[string]string{
 		naming.LabelCluster: cluster.Name,
 	}
+	setServiceIPFamilies(cluster, &clusterPodService.Spec)
 
 	if err == nil {
 		err = errors.WithStack(r.apply(ctx, clusterPodService))
@@ -99,6 +100,15 @@ func (r *Reconciler) reconcileClusterPodService(
 	return clusterPodService, err
 }
 
+// setServiceIPFamilies copies the IP families and IP family policy in the
+// spec of cluster to a Service. Kubernetes assigns its defaults when they are
+// not set.
+// - https://docs.k8s.io/concepts/services-networking/dual-stack/#services
+func setServiceIPFamilies(cluster *v1beta1.PostgresCluster, service *corev1.ServiceSpec) {
+	service.IPFamilyPolicy = cluster.Spec.IPFamilyPolicy
+	service.IPFamilies = cluster.Spec.IPFamilies
+}
+
 // generateClusterPrimaryService returns a v1.Service and v1.Endpoints that
 // resolve to the PostgreSQL primary instance.
 func (r *Reconciler) generateClusterPrimaryService(
@@ -124,6 +134,12 @@ func (r *Reconciler) generateClusterPrimaryService(
 			naming.LabelRole:    naming.RolePrimary,
 		})
 
+	// The leader of a standby cluster is a standby, too. Mark the primary
+	// Service so clients can tell it serves only reads.
+	if cluster.Spec.Standby != nil && cluster.Spec.Standby.Enabled {
+		service.Labels[naming.LabelReadOnly] = "true"
+	}
+
 	err := errors.WithStack(r.setControllerReference(cluster, service))
 
 	// Endpoints for a Service have the same name as the Service. Copy labels,
@@ -142,6 +158,7 @@ func (r *Reconciler) generateClusterPrimaryService(
 	// - https://docs.k8s.io/concepts/services-networking/service/#services-without-selectors
 	service.Spec.ClusterIP = corev1.ClusterIPNone
 	service.Spec.Selector = nil
+	setServiceIPFamilies(cluster, &service.Spec)
 
 	service.Spec.Ports = []corev1.ServicePort{{
 		Name:       naming.PortPostgreSQL,
@@ -209,6 +226,10 @@ func (r *Reconciler) generateClusterReplicaService(
 		service.Labels = naming.Merge(service.Labels,
 			spec.Metadata.GetLabelsOrNil())
 	}
+	if dns := cluster.Spec.DNS; dns != nil {
+		service.Annotations = naming.Merge(service.Annotations,
+			externalDNSAnnotations(dns, dns.Hostnames.Replicas))
+	}
 
 	// add our labels last so they aren't overwritten
 	service.Labels = naming.Merge(
@@ -258,6 +279,7 @@ func (r *Reconciler) generateClusterReplicaService(
 		naming.LabelCluster: cluster.Name,
 		naming.LabelRole:    naming.RolePatroniReplica,
 	}
+	setServiceIPFamilies(cluster, &service.Spec)
 
 	err := errors.WithStack(r.setControllerReference(cluster, service))
 
@@ -828,4 +828,42 @@ postgres-operator.crunchydata.com/cluster: pg2
 postgres-operator.crunchydata.com/role: replica
 		`))
 	})
+
+	t.Run("IPFamilies", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		policy := corev1.IPFamilyPolicyPreferDualStack
+		cluster.Spec.IPFamilyPolicy = &policy
+		cluster.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv6Protocol, corev1.IPv4Protocol}
+
+		service, err := reconciler.generateClusterReplicaService(cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, marshalMatches(service.Spec, `
+ipFamilies:
+- IPv6
+- IPv4
+ipFamilyPolicy: PreferDualStack
+ports:
+- name: postgres
+  port: 9876
+  protocol: TCP
+  targetPort: postgres
+selector:
+  postgres-operator.crunchydata.com/cluster: pg2
+  postgres-operator.crunchydata.com/role: replica
+type: ClusterIP
+		`))
+	})
+
+	t.Run("DNS", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.DNS = &v1beta1.PostgresClusterDNS{}
+		cluster.Spec.DNS.Hostnames.Primary = []string{"primary.example.com"}
+		cluster.Spec.DNS.Hostnames.Replicas = []string{"replicas.example.com"}
+
+		service, err := reconciler.generateClusterReplicaService(cluster)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, service.Annotations, map[string]string{
+			"external-dns.alpha.kubernetes.io/hostname": "replicas.example.com",
+		})
+	})
 }
@@ -0,0 +1,147 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// reconcileCollationVersions compares the collation versions recorded in every
+// database with those provided by the image of the primary. The C library and
+// ICU of a new image can sort text differently, and indexes built with the old
+// order may no longer find their rows. Versions are compared whenever the image
+// changes and periodically while they differ. Differences are reported in the
+// CollationVersionMismatch condition. When the cluster is annotated to reindex
+// collations, the affected databases are reindexed and their collation
+// versions refreshed.
+func (r *Reconciler) reconcileCollationVersions(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) reconcile.Result {
+	log := logging.FromContext(ctx)
+
+	pod, _ := instances.writablePod(naming.ContainerDatabase)
+	if pod == nil {
+		return reconcile.Result{}
+	}
+
+	var image string
+	for _, container := range pod.Spec.Containers {
+		if container.Name == naming.ContainerDatabase {
+			image = container.Image
+		}
+	}
+
+	status := cluster.Status.Collations
+	if status == nil {
+		status = new(v1beta1.CollationsStatus)
+	}
+
+	exec := func(_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string) error {
+		return r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase, stdin, stdout, stderr, command...)
+	}
+	mismatched := meta.IsStatusConditionTrue(cluster.Status.Conditions, v1beta1.CollationVersionMismatch)
+	next := reconcile.Result{RequeueAfter: 5 * time.Minute}
+
+	// Rebuild indexes only when asked and only when something needs it.
+	if requested := cluster.GetAnnotations()[naming.ReindexCollations]; mismatched &&
+		requested != "" && requested != status.Reindexed {
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "CollationReindexStarted",
+			"Rebuilding indexes of databases with collation version mismatches")
+
+		err := postgres.RefreshCollationVersions(ctx, exec, cluster.Spec.PostgresVersion)
+		if err != nil {
+			log.Error(err, "unable to refresh collation versions")
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "CollationReindexFailed",
+				"Unable to rebuild indexes and refresh collation versions: %v", err)
+			return next
+		}
+
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "CollationReindexCompleted",
+			"Rebuilt indexes and refreshed collation versions")
+		status.Reindexed = requested
+		status.Image = ""
+	}
+
+	if status.Image == image && !mismatched {
+		cluster.Status.Collations = status
+		return reconcile.Result{}
+	}
+
+	mismatches, err := postgres.CollationVersionMismatches(ctx, exec, cluster.Spec.PostgresVersion)
+	if err != nil {
+		// Keep any existing condition until the versions can be compared again.
+		log.Error(err, "unable to compare collation versions")
+		cluster.Status.Collations = status
+		return next
+	}
+
+	status.Image = image
+	cluster.Status.Collations = status
+
+	condition := metav1.Condition{
+		Type:               v1beta1.CollationVersionMismatch,
+		ObservedGeneration: cluster.GetGeneration(),
+		Status:             metav1.ConditionFalse,
+		Reason:             "VersionsMatch",
+		Message:            "Collation versions match the image of the primary",
+	}
+	if len(mismatches) > 0 {
+		databases := make([]string, 0, len(mismatches))
+		for database, collations := range mismatches {
+			databases = append(databases, fmt.Sprintf("%s (%s)", database, strings.Join(collations, ", ")))
+		}
+		sort.Strings(databases)
+
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "VersionsChanged"
+		condition.Message = fmt.Sprintf(
+			"Collations changed version in databases %s; indexes that use them may be corrupt. "+
+				"Annotate the cluster with %q to rebuild them.",
+			strings.Join(databases, ", "), naming.ReindexCollations)
+
+		// Indexes are rebuilt automatically when spec.reindex is set.
+		if cluster.Spec.Reindex != nil {
+			condition.Message = fmt.Sprintf(
+				"Collations changed version in databases %s; indexes that use them may be corrupt "+
+					"and are being rebuilt.", strings.Join(databases, ", "))
+		}
+
+		if !mismatched {
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "CollationVersionMismatch", condition.Message)
+		}
+	}
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+
+	if condition.Status == metav1.ConditionTrue {
+		return next
+	}
+	return reconcile.Result{}
+}
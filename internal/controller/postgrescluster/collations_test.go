@@ -0,0 +1,141 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestReconcileCollationVersions(t *testing.T) {
+	ctx := context.Background()
+
+	pod := &corev1.Pod{}
+	pod.Namespace, pod.Name = "ns1", "hippo-00-abcd-0"
+	pod.Annotations = map[string]string{"status": `{"role":"master"}`}
+	pod.Spec.Containers = []corev1.Container{{Name: naming.ContainerDatabase, Image: "postgres:2"}}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  naming.ContainerDatabase,
+		State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+	}}
+
+	instances := &observedInstances{forCluster: []*Instance{
+		{Name: "hippo-00-abcd", Pods: []*corev1.Pod{pod}},
+	}}
+
+	var compared, refreshed int
+	var mismatches string
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Recorder: recorder,
+		PodExec: func(_, _, container string, stdin io.Reader, stdout, _ io.Writer, _ ...string) error {
+			assert.Equal(t, container, naming.ContainerDatabase)
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			if strings.Contains(string(b), "REINDEX") {
+				refreshed++
+				mismatches = ""
+				return nil
+			}
+			compared++
+			_, err = stdout.Write([]byte(mismatches))
+			return err
+		},
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+	cluster.Spec.PostgresVersion = 16
+
+	t.Run("NoPrimary", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		result := reconciler.reconcileCollationVersions(ctx, cluster, &observedInstances{})
+		assert.Equal(t, result.RequeueAfter, time.Duration(0))
+		assert.Assert(t, cluster.Status.Collations == nil)
+	})
+
+	t.Run("Match", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		compared = 0
+
+		result := reconciler.reconcileCollationVersions(ctx, cluster, instances)
+		assert.Equal(t, result.RequeueAfter, time.Duration(0))
+		assert.Equal(t, compared, 1)
+		assert.Equal(t, cluster.Status.Collations.Image, "postgres:2")
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.CollationVersionMismatch)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+
+		// Versions are not compared again until the image changes.
+		reconciler.reconcileCollationVersions(ctx, cluster, instances)
+		assert.Equal(t, compared, 1)
+
+		cluster.Status.Collations.Image = "postgres:1"
+		reconciler.reconcileCollationVersions(ctx, cluster, instances)
+		assert.Equal(t, compared, 2)
+	})
+
+	t.Run("Mismatch", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		compared, refreshed = 0, 0
+		mismatches = "app|default\napp|public.german\npostgres|default\n"
+
+		result := reconciler.reconcileCollationVersions(ctx, cluster, instances)
+		assert.Assert(t, result.RequeueAfter > 0)
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.CollationVersionMismatch)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionTrue)
+		assert.Equal(t, condition.Reason, "VersionsChanged")
+		assert.Assert(t, cmp.Contains(condition.Message,
+			"app (default, public.german), postgres (default)"))
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "CollationVersionMismatch"))
+
+		// Versions are compared again while they differ, but the event happens once.
+		reconciler.reconcileCollationVersions(ctx, cluster, instances)
+		assert.Equal(t, compared, 2)
+		assert.Equal(t, refreshed, 0)
+		assert.Equal(t, len(recorder.Events), 0)
+
+		// Indexes are rebuilt once per annotation value.
+		cluster.Annotations = map[string]string{naming.ReindexCollations: "now"}
+		reconciler.reconcileCollationVersions(ctx, cluster, instances)
+		assert.Equal(t, refreshed, 1)
+		assert.Equal(t, cluster.Status.Collations.Reindexed, "now")
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "CollationReindexStarted"))
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "CollationReindexCompleted"))
+
+		condition = meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.CollationVersionMismatch)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+
+		reconciler.reconcileCollationVersions(ctx, cluster, instances)
+		assert.Equal(t, refreshed, 1)
+	})
+}
@@ -0,0 +1,192 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/pki"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={get,list}
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={create,delete,patch}
+
+// reconcileConnectionDetails writes the connection details of one user of
+// cluster to the Secret named in its spec, using the keys that Crossplane
+// expects. It deletes that Secret when it is no longer named and sets the Ready
+// condition that Crossplane reads from composed resources.
+// - https://docs.crossplane.io/latest/concepts/managed-resources/
+func (r *Reconciler) reconcileConnectionDetails(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	root *pki.RootCertificateAuthority,
+) error {
+	spec := cluster.Spec.WriteConnectionSecretToRef
+
+	existing := &corev1.SecretList{}
+	err := errors.WithStack(r.Client.List(ctx, existing,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelRole:    naming.RoleConnectionDetails,
+		}))
+
+	for i := range existing.Items {
+		if err == nil && (spec == nil || existing.Items[i].Name != spec.Name) {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, &existing.Items[i]))
+		}
+	}
+
+	if spec == nil {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.Ready)
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.Synced)
+		return err
+	}
+
+	user := &corev1.Secret{ObjectMeta: naming.CurrentPostgresUserSecret(cluster, connectionDetailsUser(cluster))}
+	if err == nil {
+		err = errors.WithStack(r.Client.Get(ctx, client.ObjectKeyFromObject(user), user))
+	}
+
+	// The user Secret is written before this is called, so it is missing only
+	// when the user is not in the spec.
+	published := false
+	if apierrors.IsNotFound(err) {
+		err = nil
+	} else if err == nil {
+		var ca []byte
+		ca, err = root.Trusted().MarshalText()
+
+		secret := generateConnectionDetailsSecret(cluster, user, ca)
+		if err == nil {
+			err = errors.WithStack(r.setControllerReference(cluster, secret))
+		}
+		if err == nil {
+			err = errors.WithStack(r.apply(ctx, secret))
+		}
+		published = err == nil
+	}
+
+	setReadyCondition(cluster, published)
+	return err
+}
+
+// connectionDetailsUser returns the name of the user whose connection details
+// are written for cluster. That is the user named in the spec, the first user
+// in the spec, or the default user, in that order.
+func connectionDetailsUser(cluster *v1beta1.PostgresCluster) string {
+	if spec := cluster.Spec.WriteConnectionSecretToRef; spec != nil && spec.User != "" {
+		return string(spec.User)
+	}
+	if cluster.Spec.Users == nil {
+		return cluster.Name
+	}
+	if len(cluster.Spec.Users) > 0 {
+		return string(cluster.Spec.Users[0].Name)
+	}
+	return ""
+}
+
+// generateConnectionDetailsSecret returns the Secret named in the spec of
+// cluster with the connection details in user and the certificate authority
+// in ca. The keys are the ones Crossplane uses for database credentials.
+func generateConnectionDetailsSecret(
+	cluster *v1beta1.PostgresCluster, user *corev1.Secret, ca []byte,
+) *corev1.Secret {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Spec.WriteConnectionSecretToRef.Name,
+	}}
+	secret.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+
+	secret.Annotations = naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil())
+	secret.Labels = naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+			naming.LabelRole:    naming.RoleConnectionDetails,
+		})
+	secret.Type = corev1.SecretTypeOpaque
+
+	secret.Data = map[string][]byte{
+		"endpoint": user.Data["host"],
+		"port":     user.Data["port"],
+		"username": user.Data["user"],
+		"password": user.Data["password"],
+		"ca.crt":   ca,
+	}
+	return secret
+}
+
+// setReadyCondition sets the Ready condition of cluster in the manner of
+// Crossplane. The cluster is available once its connection details are
+// published and at least one PostgreSQL instance is ready.
+func setReadyCondition(cluster *v1beta1.PostgresCluster, published bool) {
+	var ready int32
+	for _, set := range cluster.Status.InstanceSets {
+		ready += set.ReadyReplicas
+	}
+
+	condition := metav1.Condition{
+		Type:               v1beta1.Ready,
+		ObservedGeneration: cluster.GetGeneration(),
+		Status:             metav1.ConditionTrue,
+		Reason:             "Available",
+		Message:            "Connection details are published and PostgreSQL is ready",
+	}
+	switch {
+	case !published:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Creating"
+		condition.Message = "Connection details are not published yet"
+	case cluster.Status.Patroni.SystemIdentifier == "":
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Creating"
+		condition.Message = "PostgreSQL is not initialized yet"
+	case ready == 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Unavailable"
+		condition.Message = "No PostgreSQL instances are ready"
+	}
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+}
+
+// setSyncedCondition sets the Synced condition of cluster in the manner of
+// Crossplane according to the error, if any, that ended reconciliation.
+func setSyncedCondition(cluster *v1beta1.PostgresCluster, err error) {
+	condition := metav1.Condition{
+		Type:               v1beta1.Synced,
+		ObservedGeneration: cluster.GetGeneration(),
+		Status:             metav1.ConditionTrue,
+		Reason:             "ReconcileSuccess",
+		Message:            "The spec has been applied",
+	}
+	if err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ReconcileError"
+		condition.Message = err.Error()
+	}
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+}
@@ -0,0 +1,152 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestConnectionDetailsUser(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Name = "hippo"
+	assert.Equal(t, connectionDetailsUser(cluster), "hippo")
+
+	cluster.Spec.Users = []v1beta1.PostgresUserSpec{}
+	assert.Equal(t, connectionDetailsUser(cluster), "")
+
+	cluster.Spec.Users = []v1beta1.PostgresUserSpec{{Name: "app"}, {Name: "other"}}
+	assert.Equal(t, connectionDetailsUser(cluster), "app")
+
+	cluster.Spec.WriteConnectionSecretToRef = &v1beta1.ConnectionSecretReference{
+		Name: "hippo-conn", User: "other",
+	}
+	assert.Equal(t, connectionDetailsUser(cluster), "other")
+}
+
+func TestGenerateConnectionDetailsSecret(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+	cluster.Spec.Metadata = &v1beta1.Metadata{Labels: map[string]string{"x": "y"}}
+	cluster.Spec.WriteConnectionSecretToRef = &v1beta1.ConnectionSecretReference{Name: "hippo-conn"}
+
+	user := &corev1.Secret{Data: map[string][]byte{
+		"host":     []byte("hippo-primary.ns1.svc"),
+		"port":     []byte("5432"),
+		"user":     []byte("hippo"),
+		"password": []byte("secret"),
+		"verifier": []byte("SCRAM-SHA-256$..."),
+	}}
+
+	secret := generateConnectionDetailsSecret(cluster, user, []byte("-----BEGIN CERTIFICATE-----"))
+	assert.Equal(t, secret.Namespace, "ns1")
+	assert.Equal(t, secret.Name, "hippo-conn")
+	assert.DeepEqual(t, map[string]string(secret.Labels), map[string]string{
+		"x":                 "y",
+		naming.LabelCluster: "hippo",
+		naming.LabelRole:    naming.RoleConnectionDetails,
+	})
+	assert.DeepEqual(t, secret.Data, map[string][]byte{
+		"endpoint": []byte("hippo-primary.ns1.svc"),
+		"port":     []byte("5432"),
+		"username": []byte("hippo"),
+		"password": []byte("secret"),
+		"ca.crt":   []byte("-----BEGIN CERTIFICATE-----"),
+	})
+}
+
+func TestSetReadyCondition(t *testing.T) {
+	reason := func(cluster *v1beta1.PostgresCluster) string {
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.Ready)
+		assert.Assert(t, condition != nil)
+		return condition.Reason
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	setReadyCondition(cluster, false)
+	assert.Equal(t, reason(cluster), "Creating")
+
+	setReadyCondition(cluster, true)
+	assert.Equal(t, reason(cluster), "Creating")
+
+	cluster.Status.Patroni.SystemIdentifier = "123"
+	cluster.Status.InstanceSets = []v1beta1.PostgresInstanceSetStatus{{Name: "00"}}
+	setReadyCondition(cluster, true)
+	assert.Equal(t, reason(cluster), "Unavailable")
+	assert.Assert(t, meta.IsStatusConditionFalse(cluster.Status.Conditions, v1beta1.Ready))
+
+	cluster.Status.InstanceSets[0].ReadyReplicas = 1
+	setReadyCondition(cluster, true)
+	assert.Equal(t, reason(cluster), "Available")
+	assert.Assert(t, meta.IsStatusConditionTrue(cluster.Status.Conditions, v1beta1.Ready))
+}
+
+func TestSetSyncedCondition(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+
+	setSyncedCondition(cluster, errors.New("boom"))
+	condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.Synced)
+	assert.Assert(t, condition != nil)
+	assert.Equal(t, condition.Status, metav1.ConditionFalse)
+	assert.Equal(t, condition.Reason, "ReconcileError")
+	assert.Equal(t, condition.Message, "boom")
+
+	setSyncedCondition(cluster, nil)
+	assert.Assert(t, meta.IsStatusConditionTrue(cluster.Status.Conditions, v1beta1.Synced))
+}
+
+func TestReconcileConnectionDetailsRemoved(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name, cluster.UID = "ns1", "hippo", "cluster-uid"
+	cluster.Status.Conditions = []metav1.Condition{
+		{Type: v1beta1.Ready, Status: metav1.ConditionTrue},
+		{Type: v1beta1.Synced, Status: metav1.ConditionTrue},
+	}
+
+	stale := &corev1.Secret{}
+	stale.Namespace, stale.Name = "ns1", "hippo-conn"
+	stale.Labels = map[string]string{
+		naming.LabelCluster: "hippo",
+		naming.LabelRole:    naming.RoleConnectionDetails,
+	}
+	stale.OwnerReferences = []metav1.OwnerReference{*metav1.NewControllerRef(
+		cluster, v1beta1.GroupVersion.WithKind("PostgresCluster"))}
+
+	r := &Reconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(stale).Build(),
+	}
+
+	assert.NilError(t, r.reconcileConnectionDetails(ctx, cluster, nil))
+	assert.Equal(t, len(cluster.Status.Conditions), 0)
+	assert.Assert(t, apierrors.IsNotFound(
+		r.Client.Get(ctx, client.ObjectKeyFromObject(stale), &corev1.Secret{})))
+}
@@ -21,6 +21,7 @@ import (
 	"io"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -39,11 +40,18 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	"github.com/crunchydata/postgres-operator/internal/agent"
 	"github.com/crunchydata/postgres-operator/internal/config"
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/healthprobe"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/kubeapi"
+	"github.com/crunchydata/postgres-operator/internal/lifecycle"
 	"github.com/crunchydata/postgres-operator/internal/logging"
 	"github.com/crunchydata/postgres-operator/internal/pgaudit"
 	"github.com/crunchydata/postgres-operator/internal/pgbackrest"
@@ -51,6 +59,7 @@ import (
 	"github.com/crunchydata/postgres-operator/internal/pgmonitor"
 	"github.com/crunchydata/postgres-operator/internal/pki"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/postupgrade"
 	"github.com/crunchydata/postgres-operator/internal/util"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
@@ -62,18 +71,24 @@ const (
 
 // Reconciler holds resources for the PostgresCluster reconciler
 type Reconciler struct {
-	Client      client.Client
-	IsOpenShift bool
-	Owner       client.FieldOwner
-	PGOVersion  string
-	PodExec     func(
+	BackupThrottle BackupThrottle
+	Client         client.Client
+	IsOpenShift    bool
+	LifecycleHooks lifecycle.Hook
+	Owner          client.FieldOwner
+	PGOVersion     string
+	PodExec        func(
 		namespace, pod, container string,
 		stdin io.Reader, stdout, stderr io.Writer, command ...string,
 	) error
-	Recorder        record.EventRecorder
-	Registration    util.Registration
-	RegistrationURL string
-	Tracer          trace.Tracer
+	PodEphemeralContainers func(ctx context.Context, pod *corev1.Pod) error
+	PodLogs                func(ctx context.Context, pod *corev1.Pod, container string, since time.Time) ([]byte, error)
+	Recorder               record.EventRecorder
+	Registration           util.Registration
+	RegistrationURL        string
+	Tracer                 trace.Tracer
+
+	backupInfo *pgBackRestInfoCache
 }
 
 // +kubebuilder:rbac:groups="",resources="events",verbs={create,patch}
@@ -106,17 +121,31 @@ func (r *Reconciler) Reconcile(
 		if err = client.IgnoreNotFound(err); err != nil {
 			log.Error(err, "unable to fetch PostgresCluster")
 			span.RecordError(err)
+		} else {
+			setResourceMetrics(request.NamespacedName, nil)
+			for _, job := range []scheduledJob{
+				healthProbesJob, integrityChecksJob, maintenanceJob, partitioningJob,
+			} {
+				setScheduledJobMetrics(request.NamespacedName, job, nil, nil)
+			}
 		}
 		return result, err
 	}
 
-	// Set any defaults that may not have been stored in the API. No DeepCopy
-	// is necessary because controller-runtime makes a copy before returning
-	// from its cache.
+	// Fill in any fields that come from a template, then set any defaults that
+	// may not have been stored in the API. No DeepCopy is necessary because
+	// controller-runtime makes a copy before returning from its cache.
+	templateFound, templateErr := r.applyTemplate(ctx, cluster)
+	if templateErr != nil {
+		log.Error(templateErr, "unable to fetch PostgresClusterTemplate")
+		span.RecordError(templateErr)
+		return result, templateErr
+	}
 	cluster.Default()
 
 	if cluster.Spec.OpenShift == nil {
-		cluster.Spec.OpenShift = &r.IsOpenShift
+		cluster.Spec.OpenShift = initialize.Bool(
+			kubeapi.IsOpenShiftNamespace(ctx, r.Client, cluster.Namespace, r.IsOpenShift))
 	}
 
 	// Keep a copy of cluster prior to any manipulations.
@@ -147,37 +176,6 @@ func (r *Reconciler) Reconcile(
 		return *result, nil
 	}
 
-	// Perform initial validation on a cluster
-	// TODO: Move this to a defaulting (mutating admission) webhook
-	// to leverage regular validation.
-
-	// verify all needed image values are defined
-	if err := config.VerifyImageValues(cluster); err != nil {
-		// warning event with missing image information
-		r.Recorder.Event(cluster, corev1.EventTypeWarning, "MissingRequiredImage",
-			err.Error())
-		// specifically allow reconciliation if the cluster is shutdown to
-		// facilitate upgrades, otherwise return
-		if cluster.Spec.Shutdown == nil ||
-			(cluster.Spec.Shutdown != nil && !*cluster.Spec.Shutdown) {
-			return result, err
-		}
-	}
-
-	if cluster.Spec.Standby != nil &&
-		cluster.Spec.Standby.Enabled &&
-		cluster.Spec.Standby.Host == "" &&
-		cluster.Spec.Standby.RepoName == "" {
-		// When a standby cluster is requested but a repoName or host is not provided
-		// the cluster will be created as a non-standby. Reject any clusters with
-		// this configuration and provide an event
-		path := field.NewPath("spec", "standby")
-		err := field.Invalid(path, cluster.Name, "Standby requires a host or repoName to be enabled")
-		r.Recorder.Event(cluster, corev1.EventTypeWarning, "InvalidStandbyConfiguration",
-			err.Error())
-		return result, err
-	}
-
 	var (
 		clusterConfigMap         *corev1.ConfigMap
 		clusterReplicationSecret *corev1.Secret
@@ -200,6 +198,11 @@ func (r *Reconciler) Reconcile(
 	// occurs while attempting to patch the status, while otherwise simply returning the
 	// Result and error variables that are populated while reconciling the PostgresCluster.
 	patchClusterStatus := func() (reconcile.Result, error) {
+		if cluster.Spec.WriteConnectionSecretToRef != nil {
+			setSyncedCondition(cluster, err)
+		}
+		setPermissionsCondition(cluster, err)
+		setStalledCondition(cluster, err)
 		if !equality.Semantic.DeepEqual(before.Status, cluster.Status) {
 			// NOTE(cbandy): Kubernetes prior to v1.16.10 and v1.17.6 does not track
 			// managed fields on the status subresource: https://issue.k8s.io/88901
@@ -213,6 +216,62 @@ func (r *Reconciler) Reconcile(
 		return result, err
 	}
 
+	// Perform initial validation on a cluster
+	// TODO: Move this to a defaulting (mutating admission) webhook
+	// to leverage regular validation.
+
+	// Without its template, a cluster is missing the fields it would set. Report
+	// that rather than each missing field.
+	if r.setTemplateCondition(cluster, templateFound); !templateFound {
+		if !equality.Semantic.DeepEqual(before.Status, cluster.Status) {
+			err := errors.WithStack(r.Client.Status().Patch(
+				ctx, cluster, client.MergeFrom(before), r.Owner))
+			span.RecordError(err)
+			return result, err
+		}
+		return result, nil
+	}
+
+	// These fields can come from a template, so they are checked here rather
+	// than by the API.
+	if errs := missingRequiredFields(cluster); len(errs) > 0 {
+		err = errs.ToAggregate()
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, "MissingRequiredFields",
+			err.Error())
+		return patchClusterStatus()
+	}
+
+	// verify all needed image values are defined
+	if imageErr := config.VerifyImageValues(cluster); imageErr != nil {
+		// warning event with missing image information
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, "MissingRequiredImage",
+			imageErr.Error())
+		// specifically allow reconciliation if the cluster is shutdown to
+		// facilitate upgrades, otherwise return
+		if cluster.Spec.Shutdown == nil ||
+			(cluster.Spec.Shutdown != nil && !*cluster.Spec.Shutdown) {
+			err = runtime.Permanent(imageErr)
+			return patchClusterStatus()
+		}
+	}
+
+	if cluster.Spec.Standby != nil &&
+		cluster.Spec.Standby.Enabled &&
+		cluster.Spec.Standby.Host == "" &&
+		cluster.Spec.Standby.RepoName == "" {
+		// When a standby cluster is requested but a repoName or host is not provided
+		// the cluster will be created as a non-standby. Reject any clusters with
+		// this configuration and provide an event
+		path := field.NewPath("spec", "standby")
+		err = field.Invalid(path, cluster.Name, "Standby requires a host or repoName to be enabled")
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, "InvalidStandbyConfiguration",
+			err.Error())
+		return patchClusterStatus()
+	}
+
+	// Report the feature gates that apply to this cluster.
+	cluster.Status.EnabledFeatures = util.EnabledFeatures(cluster.Spec.Features)
+
 	if config.RegistrationRequired() && !r.registrationValid() {
 		if !registrationRequiredStatusFound(cluster) {
 			addRegistrationRequiredStatus(cluster, r.PGOVersion)
@@ -258,21 +317,72 @@ func (r *Reconciler) Reconcile(
 		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.PostgresClusterProgressing)
 	}
 
+	// Wait for other PostgresClusters, such as the source of a clone, before
+	// creating anything.
+	if waiting, err := r.reconcileDependencies(ctx, cluster); err != nil {
+		return result, err
+	} else if waiting {
+		return patchClusterStatus()
+	}
+
+	// Scheduled scaling changes the replicas of instance sets, so do it before
+	// anything reads them.
+	result = updateReconcileResult(result, r.reconcileInstanceAutoscaling(cluster, time.Now()))
+
 	pgHBAs := postgres.NewHBAs()
 	pgmonitor.PostgreSQLHBAs(cluster, &pgHBAs)
 	pgbouncer.PostgreSQL(cluster, &pgHBAs)
+	healthprobe.PostgreSQLHBAs(cluster, &pgHBAs)
+	if maintenanceUserEnabled(cluster) {
+		postgres.MaintenanceUserHBAs(&pgHBAs)
+	}
+	postgres.UserCertificateHBAs(cluster, &pgHBAs)
+	postupgrade.PostgreSQLHBAs(cluster, &pgHBAs)
+	dataMaskingHBAs(cluster, &pgHBAs)
 
 	pgParameters := postgres.NewParameters()
 	pgaudit.PostgreSQLParameters(&pgParameters)
 	pgbackrest.PostgreSQL(cluster, &pgParameters)
+	injectArchivePushDelay(cluster, &pgParameters)
 	pgmonitor.PostgreSQLParameters(cluster, &pgParameters)
 
 	// Set huge_pages = try if a hugepages resource limit > 0, otherwise set "off"
 	postgres.SetHugePages(cluster, &pgParameters)
 
+	// Set wal_log_hints = on when data page checksums are disabled
+	postgres.SetDataChecksums(cluster, &pgParameters)
+
+	// Set timezone and log_timezone when the cluster has a time zone
+	postgres.SetTimeZone(cluster, &pgParameters)
+
+	// Set default_transaction_read_only = on when the cluster is read-only
+	postgres.SetReadOnly(cluster, &pgParameters)
+
+	// Set temp_tablespaces when instances have a volume for temporary files
+	postgres.SetTempTablespaces(cluster, &pgParameters)
+
+	// Apply any workload profile, then derive memory, WAL, and checkpoint
+	// settings from instance resources and observed WAL when asked
+	postgres.SetWorkloadProfile(cluster, &pgParameters)
+	postgres.SetAutoTuning(cluster, r.observeStorageMedia(ctx, cluster), &pgParameters)
+	postgres.SetCheckpointTuning(cluster, &pgParameters)
+
 	if err == nil {
 		rootCA, err = r.reconcileRootCertificate(ctx, cluster)
 	}
+	if err == nil {
+		result = updateReconcileResult(result,
+			r.reconcileRootReplacement(cluster, rootCA, time.Now()))
+	}
+	if err == nil {
+		// Secrets named in the spec cannot be regenerated, so wait for them to
+		// be restored rather than rolling out pods that would not start.
+		var returnEarly bool
+		returnEarly, err = r.reconcileCustomSecrets(ctx, cluster)
+		if err != nil || returnEarly {
+			return patchClusterStatus()
+		}
+	}
 
 	if err == nil {
 		// Since any existing data directories must be moved prior to bootstrapping the
@@ -302,6 +412,23 @@ func (r *Reconciler) Reconcile(
 	if err == nil {
 		err = r.reconcilePatroniSwitchover(ctx, cluster, instances)
 	}
+	if err == nil {
+		err = r.reconcilePatroniRestart(ctx, cluster, instances)
+	}
+	if err == nil {
+		result = updateReconcileResult(result, r.reconcilePatroniPause(cluster, time.Now()))
+	}
+	if err == nil {
+		// An instance that is being split from the cluster must stop before
+		// instance sets are reconciled; otherwise, it would be started again.
+		var wait *reconcile.Result
+		if wait, err = r.reconcileSplit(ctx, cluster, instances); err != nil || wait != nil {
+			if wait != nil {
+				result = updateReconcileResult(result, *wait)
+			}
+			return patchClusterStatus()
+		}
+	}
 	// reconcile the Pod service before reconciling any data source in case it is necessary
 	// to start Pods during data source reconciliation that require network connections (e.g.
 	// if it is necessary to start a dedicated repo host to bootstrap a new cluster using its
@@ -316,10 +443,22 @@ func (r *Reconciler) Reconcile(
 	if err == nil {
 		instanceServiceAccount, err = r.reconcileRBACResources(ctx, cluster)
 	}
+	// Hold disruptive changes until a backup is taken before them. This keeps the running
+	// image in memory and reports whether an in-place restore must wait.
+	var holdRestore bool
+	if err == nil {
+		holdRestore = r.reconcileChangeProtection(cluster, instances)
+	}
+	// Pause PgBouncer, when configured, before an in-place restore begins.
+	if err == nil && !holdRestore && pendingRestore(cluster) != "" {
+		var ready bool
+		ready, err = r.drainForCutover(ctx, cluster, instances, v1beta1.PGBouncerCutoverRestore)
+		holdRestore = !ready
+	}
 	// First handle reconciling any data source configured for the PostgresCluster.  This includes
 	// reconciling the data source defined to bootstrap a new cluster, as well as a reconciling
 	// a data source to perform restore in-place and re-bootstrap the cluster.
-	if err == nil {
+	if err == nil && !holdRestore {
 		// Since the PostgreSQL data source needs to be populated prior to bootstrapping the
 		// cluster, further reconciliation will not occur until the data source (if configured) is
 		// initialized.  Func reconcileDataSource() will therefore return a bool indicating that
@@ -332,6 +471,15 @@ func (r *Reconciler) Reconcile(
 			return patchClusterStatus()
 		}
 	}
+	if err == nil {
+		// Enabling data page checksums rewrites every data file, so instances
+		// must not start until the enablement Jobs (if any) have completed.
+		var returnEarly bool
+		returnEarly, err = r.reconcileDataChecksumsEnablement(ctx, cluster, instances, clusterVolumes)
+		if err != nil || returnEarly {
+			return patchClusterStatus()
+		}
+	}
 	if err == nil {
 		clusterConfigMap, err = r.reconcileClusterConfigMap(ctx, cluster, pgHBAs, pgParameters)
 	}
@@ -357,7 +505,7 @@ func (r *Reconciler) Reconcile(
 		err = r.reconcilePatroniDynamicConfiguration(ctx, cluster, instances, pgHBAs, pgParameters)
 	}
 	if err == nil {
-		monitoringSecret, err = r.reconcileMonitoringSecret(ctx, cluster)
+		monitoringSecret, err = r.reconcileMonitoringSecret(ctx, cluster, rootCA)
 	}
 	if err == nil {
 		exporterQueriesConfig, err = r.reconcileExporterQueriesConfig(ctx, cluster)
@@ -365,6 +513,10 @@ func (r *Reconciler) Reconcile(
 	if err == nil {
 		exporterWebConfig, err = r.reconcileExporterWebConfig(ctx, cluster)
 	}
+	if err == nil {
+		err = updateResult(r.reconcileVolumeNodeAffinity(
+			ctx, cluster, instances, clusterVolumes, time.Now()))
+	}
 	if err == nil {
 		err = r.reconcileInstanceSets(
 			ctx, cluster, clusterConfigMap, clusterReplicationSecret, rootCA,
@@ -372,13 +524,47 @@ func (r *Reconciler) Reconcile(
 			primaryCertificate, clusterVolumes, exporterQueriesConfig, exporterWebConfig)
 	}
 
+	if err == nil {
+		// This is before any other SQL so that the operator is not turned
+		// away by a read-only default.
+		err = r.reconcileReadOnly(ctx, cluster, instances)
+	}
+	if err == nil {
+		err = r.reconcileTempTablespace(ctx, cluster, instances)
+	}
 	if err == nil {
 		err = r.reconcilePostgresDatabases(ctx, cluster, instances)
 	}
 	if err == nil {
-		err = r.reconcilePostgresUsers(ctx, cluster, instances)
+		err = r.reconcilePostgresUsers(ctx, cluster, instances, rootCA)
+	}
+	if err == nil {
+		err = r.reconcileForeignServers(ctx, cluster, instances)
+	}
+	if err == nil {
+		err = r.reconcileConnectionDetails(ctx, cluster, rootCA)
+	}
+	if err == nil {
+		result = updateReconcileResult(result, r.reconcileCollationVersions(ctx, cluster, instances))
+	}
+	if err == nil {
+		err = r.reconcileRestorePoints(ctx, cluster, instances)
 	}
 
+	if err == nil {
+		// This is before [Reconciler.reconcilePGBackRest] so that its
+		// configuration reflects the WALExpirationHeld condition.
+		result = updateReconcileResult(result, r.reconcileWALExpiration(ctx, cluster, instances))
+	}
+	if err == nil {
+		result = updateReconcileResult(result, r.reconcileDiskProtection(ctx, cluster, instances))
+	}
+	if err == nil {
+		result = updateReconcileResult(result, r.reconcileCheckpointTuning(ctx, cluster, instances))
+	}
+	if err == nil {
+		result = updateReconcileResult(result, r.reconcileDiagnostics(ctx, cluster, instances))
+	}
 	if err == nil {
 		err = updateResult(r.reconcilePGBackRest(ctx, cluster, instances, rootCA))
 	}
@@ -388,17 +574,61 @@ func (r *Reconciler) Reconcile(
 	if err == nil {
 		err = r.reconcilePGMonitor(ctx, cluster, instances, monitoringSecret)
 	}
+	if err == nil {
+		err = r.reconcileDataMasking(ctx, cluster, instances)
+	}
 	if err == nil {
 		err = r.reconcileDatabaseInitSQL(ctx, cluster, instances)
 	}
+	if err == nil {
+		err = r.reconcileDataChecksums(ctx, cluster, instances, clusterReplicationSecret)
+	}
+	if err == nil {
+		err = r.reconcileWALStreaming(ctx, cluster, instances, clusterReplicationSecret)
+	}
+	if err == nil {
+		err = r.reconcileMaintenanceUser(ctx, cluster, instances, rootCA)
+	}
+	if err == nil {
+		// This is after [Reconciler.reconcileMaintenanceUser] so that it
+		// sees the outcome of the latest integrity check.
+		result = updateReconcileResult(result, r.reconcileReindex(ctx, cluster, instances))
+	}
+	if err == nil {
+		err = r.reconcileHealthProbes(ctx, cluster, instances)
+	}
+	if err == nil {
+		err = r.reconcileUpgradeUser(ctx, cluster, instances, rootCA)
+	}
 	if err == nil {
 		err = r.reconcilePGAdmin(ctx, cluster)
 	}
+	if err == nil {
+		err = r.reconcileDebugContainer(ctx, cluster, instances)
+	}
+	if err == nil {
+		err = r.reconcileFaultInjection(ctx, cluster, instances)
+	}
+	if err == nil {
+		err = r.reconcileDemandScaledObject(ctx, cluster)
+	}
 	if err == nil {
 		// This is after [Reconciler.rolloutInstances] to ensure that recreating
 		// Pods takes precedence.
 		err = r.handlePatroniRestarts(ctx, cluster, instances)
 	}
+	if err == nil {
+		// This is after every operation around which PgBouncer may pause.
+		err = r.reconcileCutoverResume(ctx, cluster, instances)
+	}
+
+	if err == nil {
+		result = updateReconcileResult(result,
+			r.reconcileResourcesStatus(ctx, cluster, clusterVolumes, instances))
+	}
+	if err == nil {
+		err = r.reconcileLifecycleHooks(ctx, cluster, instances)
+	}
 
 	// at this point everything reconciled successfully, and we can update the
 	// observedGeneration
@@ -497,6 +727,26 @@ func (r *Reconciler) SetupWithManager(mgr manager.Manager) error {
 			return err
 		}
 	}
+	if util.DefaultMutableFeatureGate.Enabled(util.ExecAgent) {
+		r.PodExec = (&agent.Executor{
+			Reader:   mgr.GetClient(),
+			Fallback: r.PodExec,
+		}).Exec
+	}
+	if r.PodEphemeralContainers == nil {
+		var err error
+		r.PodEphemeralContainers, err = newPodEphemeralContainers(mgr.GetConfig())
+		if err != nil {
+			return err
+		}
+	}
+	if r.PodLogs == nil {
+		var err error
+		r.PodLogs, err = newPodLogReader(mgr.GetConfig())
+		if err != nil {
+			return err
+		}
+	}
 
 	var opts controller.Options
 
@@ -512,25 +762,87 @@ func (r *Reconciler) SetupWithManager(mgr manager.Manager) error {
 	if opts.MaxConcurrentReconciles == 0 {
 		opts.MaxConcurrentReconciles = 2
 	}
+	if s := os.Getenv("PGO_BACKUP_JITTER"); s != "" && r.BackupThrottle.Jitter == 0 {
+		if d, err := time.ParseDuration(s); err == nil && d > 0 {
+			r.BackupThrottle.Jitter = d
+		} else {
+			mgr.GetLogger().Error(err, "PGO_BACKUP_JITTER must be a positive duration")
+		}
+	}
+	if s := os.Getenv("PGO_BACKUPS_PER_NODE"); s != "" && r.BackupThrottle.PerNode == 0 {
+		if i, err := strconv.Atoi(s); err == nil && i > 0 {
+			r.BackupThrottle.PerNode = i
+		} else {
+			mgr.GetLogger().Error(err, "PGO_BACKUPS_PER_NODE must be a positive number")
+		}
+	}
+
+	// Read "pgbackrest info" of every cluster in the background rather than
+	// during each reconcile.
+	r.backupInfo = &pgBackRestInfoCache{interval: 5 * time.Minute}
+	if s := os.Getenv("PGO_PGBACKREST_INFO_INTERVAL"); s != "" {
+		if d, err := time.ParseDuration(s); err == nil && d > 0 {
+			r.backupInfo.interval = d
+		} else {
+			mgr.GetLogger().Error(err, "PGO_PGBACKREST_INFO_INTERVAL must be a positive duration")
+		}
+	}
+	if err := mgr.Add(manager.RunnableFunc(r.observePGBackRestInfo)); err != nil {
+		return err
+	}
+
+	// Report reconciles that run too long or keep failing. A stuck reconcile
+	// can be cancelled so that its worker returns to the queue.
+	watchdog := &runtime.Watchdog{
+		Name: "postgrescluster", Reconciler: r, Recorder: r.Recorder,
+		NewObject:  func() client.Object { return &v1beta1.PostgresCluster{} },
+		Timeout:    10 * time.Minute,
+		ErrorLimit: 10,
+	}
+	if s := os.Getenv("PGO_WATCHDOG_TIMEOUT"); s != "" {
+		if d, err := time.ParseDuration(s); err == nil && d >= 0 {
+			watchdog.Timeout = d
+		} else {
+			mgr.GetLogger().Error(err, "PGO_WATCHDOG_TIMEOUT must be a duration")
+		}
+	}
+	if s := os.Getenv("PGO_WATCHDOG_ERRORS"); s != "" {
+		if i, err := strconv.Atoi(s); err == nil && i >= 0 {
+			watchdog.ErrorLimit = i
+		} else {
+			mgr.GetLogger().Error(err, "PGO_WATCHDOG_ERRORS must be a number")
+		}
+	}
+	watchdog.Cancel = strings.EqualFold(os.Getenv("PGO_WATCHDOG_CANCEL"), "true")
+
+	// Hold periodic resyncs and changes to owned objects while the queue is
+	// busy so that changes made by users and failovers are handled first.
+	queue := runtime.NewPriorityQueue("postgrescluster", opts.MaxConcurrentReconciles, reconcilePriority)
+	throttle := builder.WithPredicates(queue.Predicate())
 
 	return builder.ControllerManagedBy(mgr).
-		For(&v1beta1.PostgresCluster{}).
+		For(&v1beta1.PostgresCluster{}, throttle).
 		WithOptions(opts).
-		Owns(&corev1.ConfigMap{}).
-		Owns(&corev1.Endpoints{}).
-		Owns(&corev1.PersistentVolumeClaim{}).
-		Owns(&corev1.Secret{}).
-		Owns(&corev1.Service{}).
-		Owns(&corev1.ServiceAccount{}).
-		Owns(&appsv1.Deployment{}).
-		Owns(&appsv1.StatefulSet{}).
-		Owns(&batchv1.Job{}).
-		Owns(&rbacv1.Role{}).
-		Owns(&rbacv1.RoleBinding{}).
-		Owns(&batchv1.CronJob{}).
-		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&corev1.ConfigMap{}, throttle).
+		Owns(&corev1.Endpoints{}, throttle).
+		Owns(&corev1.PersistentVolumeClaim{}, throttle).
+		Owns(&corev1.Secret{}, throttle).
+		Owns(&corev1.Service{}, throttle).
+		Owns(&corev1.ServiceAccount{}, throttle).
+		Owns(&appsv1.Deployment{}, throttle).
+		Owns(&appsv1.StatefulSet{}, throttle).
+		Owns(&batchv1.Job{}, throttle).
+		Owns(&rbacv1.Role{}, throttle).
+		Owns(&rbacv1.RoleBinding{}, throttle).
+		Owns(&batchv1.CronJob{}, throttle).
+		Owns(&policyv1.PodDisruptionBudget{}, throttle).
+		Watches(queue, &handler.Funcs{}).
 		Watches(&source.Kind{Type: &corev1.Pod{}}, r.watchPods()).
+		Watches(&source.Kind{Type: &batchv1.Job{}}, r.watchScheduledBackupJobs()).
+		Watches(&source.Kind{Type: &v1beta1.PostgresCluster{}}, r.watchDependencies()).
+		Watches(&source.Kind{Type: &v1beta1.PostgresClusterTemplate{}}, r.watchTemplates()).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, r.watchCustomSecrets()).
 		Watches(&source.Kind{Type: &appsv1.StatefulSet{}},
 			r.controllerRefHandlerFuncs()). // watch all StatefulSets
-		Complete(r)
+		Complete(&runtime.ErrorBackoff{Name: "postgrescluster", Reconciler: watchdog})
 }
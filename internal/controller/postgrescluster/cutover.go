@@ -0,0 +1,250 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/patroni"
+	"github.com/crunchydata/postgres-operator/internal/pgbouncer"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// cutoverSpec returns the cutover settings of cluster when PgBouncer should
+// pause around operation, or nil.
+func cutoverSpec(cluster *v1beta1.PostgresCluster, operation string) *v1beta1.PGBouncerCutoverSpec {
+	if cluster.Spec.Proxy == nil || cluster.Spec.Proxy.PGBouncer == nil ||
+		cluster.Spec.Proxy.PGBouncer.Cutover == nil {
+		return nil
+	}
+	spec := cluster.Spec.Proxy.PGBouncer.Cutover
+	for _, o := range spec.Operations {
+		if o == operation {
+			return spec
+		}
+	}
+	return nil
+}
+
+// pendingRestore returns the ID of the in-place restore requested by the
+// restore annotation of cluster when it has not started yet.
+func pendingRestore(cluster *v1beta1.PostgresCluster) string {
+	restore := cluster.Spec.Backups.PGBackRest.Restore
+	if restore == nil || restore.Enabled == nil || !*restore.Enabled {
+		return ""
+	}
+
+	var status string
+	if cluster.Status.PGBackRest != nil && cluster.Status.PGBackRest.Restore != nil {
+		status = cluster.Status.PGBackRest.Restore.ID
+	}
+	if id := cluster.GetAnnotations()[naming.PGBackRestRestore]; id != status {
+		return id
+	}
+	return ""
+}
+
+// cutoverFinished returns whether or not operation is done so that PgBouncer
+// can resume.
+func cutoverFinished(
+	cluster *v1beta1.PostgresCluster, instances *observedInstances, operation string,
+) bool {
+	annotations := cluster.GetAnnotations()
+	patroniStatus := cluster.Status.Patroni
+
+	switch operation {
+	case v1beta1.PGBouncerCutoverRestart:
+		if spec := cluster.Spec.Patroni; spec != nil && spec.Restart != nil && spec.Restart.Enabled {
+			if restart := annotations[naming.PatroniRestart]; restart != "" &&
+				(patroniStatus.Restart == nil || *patroniStatus.Restart != restart) {
+				return false
+			}
+		}
+		for _, instance := range instances.forCluster {
+			if primary, _ := instance.IsPrimary(); primary &&
+				len(instance.Pods) > 0 && patroni.PodRequiresRestart(instance.Pods[0]) {
+				return false
+			}
+		}
+
+	case v1beta1.PGBouncerCutoverRestore:
+		if pendingRestore(cluster) != "" {
+			return false
+		}
+		if meta.IsStatusConditionTrue(cluster.Status.Conditions, ConditionPGBackRestRestoreProgressing) {
+			return false
+		}
+		if status := cluster.Status.PGBackRest; status != nil && status.Restore != nil &&
+			!status.Restore.Finished {
+			return false
+		}
+
+	case v1beta1.PGBouncerCutoverSwitchover:
+		if spec := cluster.Spec.Patroni; spec != nil && spec.Switchover != nil && spec.Switchover.Enabled {
+			if switchover := annotations[naming.PatroniSwitchover]; switchover != "" &&
+				(patroniStatus.Switchover == nil || *patroniStatus.Switchover != switchover) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// pgBouncerAdmin returns an executor in a running PostgreSQL container of
+// cluster along with the addresses and credentials of its running PgBouncer
+// Pods. The executor is nil when there are none.
+func (r *Reconciler) pgBouncerAdmin(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) (exec postgres.Executor, hosts []string, password string, err error) {
+	var pods corev1.PodList
+	selector, err := naming.AsSelector(naming.ClusterPGBouncerSelector(cluster))
+	if err == nil {
+		err = errors.WithStack(r.Client.List(ctx, &pods,
+			client.InNamespace(cluster.Namespace),
+			client.MatchingLabelsSelector{Selector: selector},
+		))
+	}
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == corev1.PodRunning && pods.Items[i].Status.PodIP != "" {
+			hosts = append(hosts, pods.Items[i].Status.PodIP)
+		}
+	}
+	if err != nil || len(hosts) == 0 {
+		return nil, nil, "", err
+	}
+
+	secret := &corev1.Secret{ObjectMeta: naming.ClusterPGBouncer(cluster)}
+	if err := errors.WithStack(
+		r.Client.Get(ctx, client.ObjectKeyFromObject(secret), secret),
+	); err != nil {
+		return nil, nil, "", err
+	}
+
+	// Any running PostgreSQL container has psql and can reach PgBouncer.
+	var pod *corev1.Pod
+	for _, instance := range instances.forCluster {
+		if running, known := instance.IsRunning(naming.ContainerDatabase); running &&
+			known && len(instance.Pods) == 1 {
+			pod = instance.Pods[0]
+			break
+		}
+	}
+	if pod == nil {
+		return nil, nil, "", errors.New("no running PostgreSQL container from which to reach PgBouncer")
+	}
+
+	exec = func(_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string) error {
+		return r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase, stdin, stdout, stderr, command...)
+	}
+	return exec, hosts, string(secret.Data[pgbouncer.AdminPasswordSecretKey]), nil
+}
+
+// drainForCutover pauses PgBouncer before operation when cluster asks for it.
+// It returns true when operation can proceed, either because PgBouncer is
+// paused or because there is nothing to pause. When transactions do not finish
+// in time, it resumes PgBouncer and returns an error unless the cutover is
+// configured to proceed anyway.
+func (r *Reconciler) drainForCutover(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances, operation string,
+) (bool, error) {
+	spec := cutoverSpec(cluster, operation)
+	if spec == nil || cluster.Status.Proxy.PGBouncer.Cutover != nil {
+		return true, nil
+	}
+
+	exec, hosts, password, err := r.pgBouncerAdmin(ctx, cluster, instances)
+	if err != nil || exec == nil {
+		return err == nil, err
+	}
+
+	port := *cluster.Spec.Proxy.PGBouncer.Port
+	timeout := 30 * time.Second
+	if spec.DrainTimeoutSeconds != nil {
+		timeout = time.Duration(*spec.DrainTimeoutSeconds) * time.Second
+	}
+
+	timedOut, err := pgbouncer.Pause(ctx, exec, password, port, timeout, hosts)
+	if err == nil && len(timedOut) > 0 && spec.OnTimeout != "Proceed" {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "CutoverAborted",
+			"Transactions did not finish within %v at PgBouncer %s; %s will be attempted again",
+			timeout, strings.Join(timedOut, ", "), operation)
+		err = errors.Errorf("connections did not drain before %s", operation)
+	}
+	if err != nil {
+		// Let clients through again until the next attempt.
+		if resumeErr := pgbouncer.Resume(ctx, exec, password, port, hosts); resumeErr != nil {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "CutoverResumeFailed",
+				"Unable to resume PgBouncer: %v", resumeErr)
+		}
+		return false, err
+	}
+
+	now := metav1.Now()
+	cluster.Status.Proxy.PGBouncer.Cutover = &v1beta1.PGBouncerCutoverStatus{
+		Operation: operation,
+		PauseTime: &now,
+	}
+	if len(timedOut) > 0 {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "CutoverPaused",
+			"Paused PgBouncer for %s; transactions did not finish within %v at %s",
+			operation, timeout, strings.Join(timedOut, ", "))
+	} else {
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "CutoverPaused",
+			"Paused PgBouncer for %s", operation)
+	}
+	return true, nil
+}
+
+// reconcileCutoverResume resumes PgBouncer once the operation for which it was
+// paused is done, or when PgBouncer should no longer pause around it.
+func (r *Reconciler) reconcileCutoverResume(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) error {
+	status := cluster.Status.Proxy.PGBouncer.Cutover
+	if status == nil {
+		return nil
+	}
+	if cutoverSpec(cluster, status.Operation) != nil &&
+		!cutoverFinished(cluster, instances, status.Operation) {
+		return nil
+	}
+
+	if cluster.Spec.Proxy != nil && cluster.Spec.Proxy.PGBouncer != nil {
+		exec, hosts, password, err := r.pgBouncerAdmin(ctx, cluster, instances)
+		if err == nil && exec != nil {
+			err = pgbouncer.Resume(ctx, exec, password, *cluster.Spec.Proxy.PGBouncer.Port, hosts)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "CutoverResumed",
+		"Resumed PgBouncer after %s", status.Operation)
+	cluster.Status.Proxy.PGBouncer.Cutover = nil
+	return nil
+}
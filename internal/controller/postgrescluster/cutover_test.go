@@ -0,0 +1,236 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/pgbouncer"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestCutoverFinished(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Spec.Patroni = &v1beta1.PatroniSpec{
+		Restart:    &v1beta1.PatroniRestart{Enabled: true},
+		Switchover: &v1beta1.PatroniSwitchover{Enabled: true},
+	}
+	cluster.Spec.Backups.PGBackRest.Restore = &v1beta1.PGBackRestRestore{
+		Enabled: initialize.Bool(true),
+	}
+
+	primary := &corev1.Pod{}
+	primary.Labels = map[string]string{naming.LabelRole: naming.RolePatroniLeader}
+	primary.Annotations = map[string]string{"status": `{"role":"master"}`}
+	instances := &observedInstances{forCluster: []*Instance{{Pods: []*corev1.Pod{primary}}}}
+
+	t.Run("Nothing", func(t *testing.T) {
+		for _, operation := range []string{
+			v1beta1.PGBouncerCutoverRestart,
+			v1beta1.PGBouncerCutoverRestore,
+			v1beta1.PGBouncerCutoverSwitchover,
+		} {
+			assert.Assert(t, cutoverFinished(cluster, instances, operation), "%s", operation)
+		}
+	})
+
+	t.Run("Restart", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Annotations = map[string]string{naming.PatroniRestart: "one"}
+		assert.Assert(t, !cutoverFinished(cluster, instances, v1beta1.PGBouncerCutoverRestart))
+
+		cluster.Status.Patroni.Restart = initialize.String("one")
+		assert.Assert(t, cutoverFinished(cluster, instances, v1beta1.PGBouncerCutoverRestart))
+
+		// The primary has yet to apply PostgreSQL parameters.
+		primary := primary.DeepCopy()
+		primary.Annotations["status"] = `{"role":"master","pending_restart":true}`
+		instances := &observedInstances{forCluster: []*Instance{{Pods: []*corev1.Pod{primary}}}}
+		assert.Assert(t, !cutoverFinished(cluster, instances, v1beta1.PGBouncerCutoverRestart))
+	})
+
+	t.Run("Restore", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Annotations = map[string]string{naming.PGBackRestRestore: "one"}
+		assert.Equal(t, pendingRestore(cluster), "one")
+		assert.Assert(t, !cutoverFinished(cluster, instances, v1beta1.PGBouncerCutoverRestore))
+
+		cluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{
+			Restore: &v1beta1.PGBackRestJobStatus{ID: "one"},
+		}
+		assert.Equal(t, pendingRestore(cluster), "")
+		assert.Assert(t, !cutoverFinished(cluster, instances, v1beta1.PGBouncerCutoverRestore))
+
+		cluster.Status.PGBackRest.Restore.Finished = true
+		assert.Assert(t, cutoverFinished(cluster, instances, v1beta1.PGBouncerCutoverRestore))
+	})
+
+	t.Run("Switchover", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Annotations = map[string]string{naming.PatroniSwitchover: "one"}
+		assert.Assert(t, !cutoverFinished(cluster, instances, v1beta1.PGBouncerCutoverSwitchover))
+
+		cluster.Status.Patroni.Switchover = initialize.String("one")
+		assert.Assert(t, cutoverFinished(cluster, instances, v1beta1.PGBouncerCutoverSwitchover))
+	})
+}
+
+func TestDrainForCutover(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+	cluster.Spec.Proxy = &v1beta1.PostgresProxySpec{PGBouncer: &v1beta1.PGBouncerPodSpec{
+		Port: initialize.Int32(5432),
+		Cutover: &v1beta1.PGBouncerCutoverSpec{
+			Operations: []string{v1beta1.PGBouncerCutoverSwitchover},
+		},
+	}}
+
+	bouncer := &corev1.Pod{}
+	bouncer.Namespace, bouncer.Name = "ns1", "hippo-pgbouncer-abcd"
+	bouncer.Labels = naming.ClusterPGBouncerSelector(cluster).MatchLabels
+	bouncer.Status.Phase = corev1.PodRunning
+	bouncer.Status.PodIP = "10.0.0.1"
+
+	secret := &corev1.Secret{ObjectMeta: naming.ClusterPGBouncer(cluster)}
+	secret.Data = map[string][]byte{pgbouncer.AdminPasswordSecretKey: []byte("secret")}
+
+	pod := &corev1.Pod{}
+	pod.Namespace, pod.Name = "ns1", "hippo-00-abcd-0"
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  naming.ContainerDatabase,
+		State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+	}}
+	instances := &observedInstances{forCluster: []*Instance{{Pods: []*corev1.Pod{pod}}}}
+
+	var commands []string
+	result := "10.0.0.1 ok"
+
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Client:   fake.NewClientBuilder().WithObjects(bouncer, secret).Build(),
+		Recorder: recorder,
+		PodExec: func(_, name, container string, stdin io.Reader, stdout, _ io.Writer, command ...string) error {
+			assert.Equal(t, name, pod.Name)
+			assert.Equal(t, container, naming.ContainerDatabase)
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Equal(t, string(b), "secret")
+
+			commands = append(commands, command[5])
+			if command[5] == "PAUSE" {
+				_, err = stdout.Write([]byte(result + "\n"))
+			} else {
+				_, err = stdout.Write([]byte("10.0.0.1 ok\n"))
+			}
+			return err
+		},
+	}
+
+	t.Run("NotConfigured", func(t *testing.T) {
+		ready, err := reconciler.drainForCutover(ctx, cluster, instances, v1beta1.PGBouncerCutoverRestore)
+		assert.NilError(t, err)
+		assert.Assert(t, ready)
+		assert.Assert(t, commands == nil)
+	})
+
+	t.Run("Abort", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		commands, result = nil, "10.0.0.1 timeout"
+
+		ready, err := reconciler.drainForCutover(ctx, cluster, instances, v1beta1.PGBouncerCutoverSwitchover)
+		assert.ErrorContains(t, err, "did not drain")
+		assert.Assert(t, !ready)
+		assert.DeepEqual(t, commands, []string{"PAUSE", "RESUME"})
+		assert.Assert(t, cluster.Status.Proxy.PGBouncer.Cutover == nil)
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "CutoverAborted"))
+	})
+
+	t.Run("Proceed", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Proxy.PGBouncer.Cutover.OnTimeout = "Proceed"
+		commands, result = nil, "10.0.0.1 timeout"
+
+		ready, err := reconciler.drainForCutover(ctx, cluster, instances, v1beta1.PGBouncerCutoverSwitchover)
+		assert.NilError(t, err)
+		assert.Assert(t, ready)
+		assert.DeepEqual(t, commands, []string{"PAUSE"})
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "transactions did not finish"))
+	})
+
+	t.Run("PauseAndResume", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Annotations = map[string]string{naming.PatroniSwitchover: "one"}
+		cluster.Spec.Patroni = &v1beta1.PatroniSpec{
+			Switchover: &v1beta1.PatroniSwitchover{Enabled: true},
+		}
+		commands, result = nil, "10.0.0.1 ok"
+
+		ready, err := reconciler.drainForCutover(ctx, cluster, instances, v1beta1.PGBouncerCutoverSwitchover)
+		assert.NilError(t, err)
+		assert.Assert(t, ready)
+		assert.Equal(t, cluster.Status.Proxy.PGBouncer.Cutover.Operation, "Switchover")
+		assert.Assert(t, cluster.Status.Proxy.PGBouncer.Cutover.PauseTime != nil)
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "Paused PgBouncer for Switchover"))
+
+		// PgBouncer pauses only once.
+		ready, err = reconciler.drainForCutover(ctx, cluster, instances, v1beta1.PGBouncerCutoverSwitchover)
+		assert.NilError(t, err)
+		assert.Assert(t, ready)
+		assert.DeepEqual(t, commands, []string{"PAUSE"})
+
+		// PgBouncer stays paused until the switchover is done.
+		assert.NilError(t, reconciler.reconcileCutoverResume(ctx, cluster, instances))
+		assert.Assert(t, cluster.Status.Proxy.PGBouncer.Cutover != nil)
+
+		cluster.Status.Patroni.Switchover = initialize.String("one")
+		assert.NilError(t, reconciler.reconcileCutoverResume(ctx, cluster, instances))
+		assert.Assert(t, cluster.Status.Proxy.PGBouncer.Cutover == nil)
+		assert.DeepEqual(t, commands, []string{"PAUSE", "RESUME"})
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "Resumed PgBouncer after Switchover"))
+	})
+
+	t.Run("ResumeWhenRemoved", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Annotations = map[string]string{naming.PatroniSwitchover: "two"}
+		cluster.Spec.Patroni = &v1beta1.PatroniSpec{
+			Switchover: &v1beta1.PatroniSwitchover{Enabled: true},
+		}
+		cluster.Status.Proxy.PGBouncer.Cutover = &v1beta1.PGBouncerCutoverStatus{
+			Operation: v1beta1.PGBouncerCutoverSwitchover,
+			PauseTime: &metav1.Time{},
+		}
+		cluster.Spec.Proxy.PGBouncer.Cutover = nil
+		commands = nil
+
+		assert.NilError(t, reconciler.reconcileCutoverResume(ctx, cluster, instances))
+		assert.Assert(t, cluster.Status.Proxy.PGBouncer.Cutover == nil)
+		assert.DeepEqual(t, commands, []string{"RESUME"})
+		<-recorder.Events
+	})
+}
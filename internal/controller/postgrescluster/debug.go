@@ -0,0 +1,121 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// generateDebugContainer returns an ephemeral container that shares the
+// processes of the database container in pod. It runs the same image, so
+// psql, pg_waldump, and the other PostgreSQL tools match the running server.
+// Every volume of the database container is mounted read-only.
+func generateDebugContainer(pod *corev1.Pod) (corev1.EphemeralContainer, bool) {
+	var database *corev1.Container
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == naming.ContainerDatabase {
+			database = &pod.Spec.Containers[i]
+		}
+	}
+	if database == nil {
+		return corev1.EphemeralContainer{}, false
+	}
+
+	mounts := make([]corev1.VolumeMount, len(database.VolumeMounts))
+	for i := range database.VolumeMounts {
+		mounts[i] = database.VolumeMounts[i]
+		mounts[i].ReadOnly = true
+	}
+
+	return corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon{
+			Name:    naming.ContainerDebug,
+			Image:   database.Image,
+			Command: []string{"bash"},
+			Env:     database.Env,
+
+			ImagePullPolicy: database.ImagePullPolicy,
+			SecurityContext: initialize.RestrictedSecurityContext(),
+			VolumeMounts:    mounts,
+
+			// Wait for someone to attach.
+			Stdin: true,
+			TTY:   true,
+		},
+
+		// Processes of the database container are visible to the debug
+		// container, so ps, top, and the files under /proc can inspect them.
+		// Tracing them, with strace or gdb, needs CAP_SYS_PTRACE and is not
+		// possible with the restricted security context.
+		TargetContainerName: naming.ContainerDatabase,
+	}, true
+}
+
+// reconcileDebugContainer adds a debug container to the instance Pod named by
+// the debug annotation on cluster. Ephemeral containers cannot be removed, so
+// this does nothing when the Pod already has one. The annotation should be
+// removed when debugging is finished; otherwise, the container is added again
+// when the Pod is recreated.
+func (r *Reconciler) reconcileDebugContainer(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) error {
+	name := cluster.GetAnnotations()[naming.DebugInstance]
+	if name == "" {
+		return nil
+	}
+
+	var pod *corev1.Pod
+	for _, instance := range instances.forCluster {
+		for _, p := range instance.Pods {
+			if p.Name == name {
+				pod = p
+			}
+		}
+	}
+	if pod == nil {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "DebugInstanceNotFound",
+			"Pod %q is not an instance of this cluster", name)
+		return nil
+	}
+
+	for _, container := range pod.Spec.EphemeralContainers {
+		if container.Name == naming.ContainerDebug {
+			return nil
+		}
+	}
+
+	container, ok := generateDebugContainer(pod)
+	if !ok {
+		return nil
+	}
+
+	pod = pod.DeepCopy()
+	pod.Spec.EphemeralContainers = append(pod.Spec.EphemeralContainers, container)
+
+	err := errors.WithStack(r.PodEphemeralContainers(ctx, pod))
+	if err == nil {
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "DebugContainerAdded",
+			"Attach to it with: kubectl attach --namespace %s -it %s --container %s",
+			pod.Namespace, pod.Name, naming.ContainerDebug)
+	}
+	return err
+}
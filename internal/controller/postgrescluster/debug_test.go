@@ -0,0 +1,131 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestGenerateDebugContainer(t *testing.T) {
+	pod := &corev1.Pod{}
+	_, ok := generateDebugContainer(pod)
+	assert.Assert(t, !ok)
+
+	pod.Spec.Containers = []corev1.Container{{
+		Name:  naming.ContainerDatabase,
+		Image: "postgres-image",
+		Env:   []corev1.EnvVar{{Name: "PGDATA", Value: "/pgdata/pg16"}},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: "postgres-data", MountPath: "/pgdata"},
+		},
+	}}
+
+	container, ok := generateDebugContainer(pod)
+	assert.Assert(t, ok)
+	assert.Assert(t, marshalMatches(container, `
+command:
+- bash
+env:
+- name: PGDATA
+  value: /pgdata/pg16
+image: postgres-image
+name: debug
+resources: {}
+securityContext:
+  allowPrivilegeEscalation: false
+  capabilities:
+    drop:
+    - ALL
+  privileged: false
+  readOnlyRootFilesystem: true
+  runAsNonRoot: true
+stdin: true
+targetContainerName: database
+tty: true
+volumeMounts:
+- mountPath: /pgdata
+  name: postgres-data
+  readOnly: true
+	`))
+
+	// The Pod is unchanged.
+	assert.Assert(t, !pod.Spec.Containers[0].VolumeMounts[0].ReadOnly)
+}
+
+func TestReconcileDebugContainer(t *testing.T) {
+	ctx := context.Background()
+
+	pod := &corev1.Pod{}
+	pod.Namespace, pod.Name = "ns1", "hippo-00-abcd-0"
+	pod.Spec.Containers = []corev1.Container{{Name: naming.ContainerDatabase}}
+
+	instances := &observedInstances{forCluster: []*Instance{
+		{Name: "hippo-00-abcd", Pods: []*corev1.Pod{pod}},
+	}}
+
+	var updated []*corev1.Pod
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Recorder: recorder,
+		PodEphemeralContainers: func(_ context.Context, pod *corev1.Pod) error {
+			updated = append(updated, pod)
+			return nil
+		},
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+
+	t.Run("NoAnnotation", func(t *testing.T) {
+		assert.NilError(t, reconciler.reconcileDebugContainer(ctx, cluster, instances))
+		assert.Equal(t, len(updated), 0)
+	})
+
+	t.Run("UnknownPod", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Annotations = map[string]string{naming.DebugInstance: "other"}
+
+		assert.NilError(t, reconciler.reconcileDebugContainer(ctx, cluster, instances))
+		assert.Equal(t, len(updated), 0)
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "DebugInstanceNotFound"))
+	})
+
+	t.Run("Added", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Annotations = map[string]string{naming.DebugInstance: pod.Name}
+
+		assert.NilError(t, reconciler.reconcileDebugContainer(ctx, cluster, instances))
+		assert.Equal(t, len(updated), 1)
+		assert.Equal(t, updated[0].Name, pod.Name)
+		assert.Equal(t, len(updated[0].Spec.EphemeralContainers), 1)
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "kubectl attach"))
+
+		// The observed Pod is unchanged.
+		assert.Equal(t, len(pod.Spec.EphemeralContainers), 0)
+
+		// Nothing happens when the container exists.
+		pod.Spec.EphemeralContainers = updated[0].Spec.EphemeralContainers
+		assert.NilError(t, reconciler.reconcileDebugContainer(ctx, cluster, instances))
+		assert.Equal(t, len(updated), 1)
+	})
+}
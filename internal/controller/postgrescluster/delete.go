@@ -19,6 +19,8 @@ import (
 	"context"
 
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -57,7 +59,13 @@ func (r *Reconciler) handleDelete(
 			return nil, nil
 		}
 
-		// The cluster is not being deleted and needs a finalizer; set it.
+		// The cluster is not being deleted and needs a finalizer. It is new, so
+		// call any hooks before anything is created for it.
+		if err := r.reconcilePreProvisionHooks(ctx, cluster); err != nil {
+			return nil, err
+		}
+
+		// Set the finalizer.
 
 		// The Finalizers field is shared by multiple controllers, but the
 		// server-side merge strategy does not work on our custom resource due
@@ -85,6 +93,10 @@ func (r *Reconciler) handleDelete(
 	// The cluster is being deleted and our finalizer is still set; run our
 	// finalizer logic.
 
+	if err := r.reconcilePreDeleteHooks(ctx, cluster); err != nil {
+		return nil, err
+	}
+
 	if result, err := r.deleteInstances(ctx, cluster); err != nil {
 		return nil, err
 	} else if result != nil {
@@ -96,6 +108,12 @@ func (r *Reconciler) handleDelete(
 		return nil, err
 	}
 
+	// Release volumes from the cluster before the garbage collector sees they
+	// are dependents of a deleted owner.
+	if err := r.retainVolumes(ctx, cluster); err != nil {
+		return nil, err
+	}
+
 	// Our finalizer logic is finished; remove our finalizer.
 	// The Finalizers field is shared by multiple controllers, but the
 	// server-side merge strategy does not work on our custom resource due to a
@@ -113,3 +131,64 @@ func (r *Reconciler) handleDelete(
 	// The caller should wait for further events or requeue upon error.
 	return &reconcile.Result{}, err
 }
+
+// +kubebuilder:rbac:groups="",resources="persistentvolumeclaims",verbs={list,patch}
+
+// retainVolumes removes cluster from the owner references of its
+// PersistentVolumeClaims when its DeletionPolicy is "Retain". The garbage
+// collector then leaves them in place, and a PostgresCluster created later
+// with the same name finds them by their labels and takes ownership.
+//
+// NOTE: Foreground deletion removes dependents before the finalizer runs, so
+// volumes are retained only during background or orphan deletion.
+func (r *Reconciler) retainVolumes(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) error {
+	if cluster.Spec.DeletionPolicy != "Retain" {
+		return nil
+	}
+
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	selector, err := naming.AsSelector(naming.Cluster(cluster.Name))
+	if err == nil {
+		err = errors.WithStack(
+			r.Client.List(ctx, pvcs,
+				client.InNamespace(cluster.Namespace),
+				client.MatchingLabelsSelector{Selector: selector},
+			))
+	}
+
+	var retained int
+	for i := range pvcs.Items {
+		if err != nil {
+			break
+		}
+
+		pvc := &pvcs.Items[i]
+		owners := make([]metav1.OwnerReference, 0, len(pvc.OwnerReferences))
+		for _, owner := range pvc.OwnerReferences {
+			if owner.UID != cluster.UID {
+				owners = append(owners, owner)
+			}
+		}
+		if len(owners) == len(pvc.OwnerReferences) {
+			continue
+		}
+
+		// The OwnerReferences field is a list that merge-patch replaces as a
+		// whole. Include ResourceVersion to detect conflicts with other writers.
+		before := pvc.DeepCopy()
+		pvc.OwnerReferences = owners
+		err = errors.WithStack(r.patch(ctx, pvc,
+			client.MergeFromWithOptions(before, client.MergeFromWithOptimisticLock{})))
+		retained++
+	}
+
+	if err == nil && retained > 0 {
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "VolumesRetained",
+			"Retained %d PersistentVolumeClaims for a future PostgresCluster named %q",
+			retained, cluster.Name)
+	}
+
+	return err
+}
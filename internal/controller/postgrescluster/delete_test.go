@@ -0,0 +1,99 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestRetainVolumes(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name, cluster.UID = "ns1", "hippo", "some-uid"
+
+	pvc := func(name string, owners ...metav1.OwnerReference) *corev1.PersistentVolumeClaim {
+		pvc := &corev1.PersistentVolumeClaim{}
+		pvc.Namespace, pvc.Name = cluster.Namespace, name
+		pvc.Labels = map[string]string{naming.LabelCluster: cluster.Name}
+		pvc.OwnerReferences = owners
+		return pvc
+	}
+	owner := metav1.OwnerReference{
+		APIVersion: v1beta1.GroupVersion.String(), Kind: "PostgresCluster",
+		Name: cluster.Name, UID: cluster.UID, Controller: initialize.Bool(true),
+	}
+	other := metav1.OwnerReference{APIVersion: "v1", Kind: "ConfigMap", Name: "x", UID: "other"}
+
+	setup := func() (*Reconciler, *record.FakeRecorder) {
+		recorder := record.NewFakeRecorder(10)
+		return &Reconciler{
+			Client: fake.NewClientBuilder().WithObjects(
+				pvc("hippo-instance1-abcd-pgdata", owner),
+				pvc("hippo-repo1", owner, other),
+				pvc("hippo-unowned"),
+			).Build(),
+			Recorder: recorder,
+		}, recorder
+	}
+
+	t.Run("Delete", func(t *testing.T) {
+		r, recorder := setup()
+		assert.NilError(t, r.retainVolumes(ctx, cluster))
+		assert.Equal(t, len(recorder.Events), 0)
+
+		current := &corev1.PersistentVolumeClaim{}
+		assert.NilError(t, r.Client.Get(ctx,
+			client.ObjectKey{Namespace: "ns1", Name: "hippo-repo1"}, current))
+		assert.Equal(t, len(current.OwnerReferences), 2)
+	})
+
+	t.Run("Retain", func(t *testing.T) {
+		r, recorder := setup()
+		retain := cluster.DeepCopy()
+		retain.Spec.DeletionPolicy = "Retain"
+		assert.NilError(t, r.retainVolumes(ctx, retain))
+
+		pvcs := &corev1.PersistentVolumeClaimList{}
+		assert.NilError(t, r.Client.List(ctx, pvcs, client.InNamespace("ns1")))
+		assert.Equal(t, len(pvcs.Items), 3)
+
+		for _, pvc := range pvcs.Items {
+			for _, ref := range pvc.OwnerReferences {
+				assert.Assert(t, ref.UID != cluster.UID, "%s", pvc.Name)
+			}
+			if pvc.Name == "hippo-repo1" {
+				assert.DeepEqual(t, pvc.OwnerReferences, []metav1.OwnerReference{other})
+			}
+		}
+
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Equal(t, <-recorder.Events,
+			`Normal VolumesRetained Retained 2 PersistentVolumeClaims for a future PostgresCluster named "hippo"`)
+	})
+}
@@ -0,0 +1,106 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/dependency"
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// +kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={get}
+
+// reconcileDependencies checks the PostgresClusters that cluster depends on
+// and sets the DependenciesSatisfied condition. It returns true when cluster
+// should not be created yet. Once satisfied, dependencies are not checked
+// again so that a dependency restarting does not affect cluster.
+func (r *Reconciler) reconcileDependencies(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) (bool, error) {
+	if len(cluster.Spec.DependsOn) == 0 ||
+		meta.IsStatusConditionTrue(cluster.Status.Conditions, v1beta1.DependenciesSatisfied) {
+		return false, nil
+	}
+
+	unmet, err := dependency.Unmet(ctx, r.Client, cluster.Namespace, cluster.Spec.DependsOn)
+	if err != nil {
+		return false, err
+	}
+
+	condition := dependency.Condition(cluster.GetGeneration(), unmet)
+	if len(unmet) > 0 && !meta.IsStatusConditionFalse(cluster.Status.Conditions, v1beta1.DependenciesSatisfied) {
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, condition.Reason, condition.Message)
+	}
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+	return len(unmet) > 0, nil
+}
+
+// +kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={list}
+
+// watchDependencies returns a handler.EventHandler for PostgresClusters that
+// enqueues the clusters in the same namespace that depend on them.
+func (r *Reconciler) watchDependencies() handler.Funcs {
+	handle := func(dependency client.Object, q workqueue.RateLimitingInterface) {
+		ctx := context.Background()
+		for _, cluster := range r.findDependentClusters(ctx, dependency) {
+			q.Add(reconcile.Request{NamespacedName: client.ObjectKeyFromObject(cluster)})
+		}
+	}
+
+	return handler.Funcs{
+		CreateFunc: func(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			handle(e.Object, q)
+		},
+		UpdateFunc: func(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			handle(e.ObjectNew, q)
+		},
+	}
+}
+
+// findDependentClusters returns the PostgresClusters that depend on object
+// and are still waiting for their dependencies.
+func (r *Reconciler) findDependentClusters(
+	ctx context.Context, object client.Object,
+) []*v1beta1.PostgresCluster {
+	log := logging.FromContext(ctx)
+
+	var clusters v1beta1.PostgresClusterList
+	if err := r.Client.List(ctx, &clusters, client.InNamespace(object.GetNamespace())); err != nil {
+		log.Error(err, "listing PostgresClusters")
+		return nil
+	}
+
+	var matching []*v1beta1.PostgresCluster
+	for i := range clusters.Items {
+		cluster := &clusters.Items[i]
+		if dependency.Depends(cluster.Spec.DependsOn, object.GetName()) &&
+			!meta.IsStatusConditionTrue(cluster.Status.Conditions, v1beta1.DependenciesSatisfied) {
+			matching = append(matching, cluster)
+		}
+	}
+	return matching
+}
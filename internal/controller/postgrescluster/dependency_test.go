@@ -0,0 +1,102 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestReconcileDependencies(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	source := &v1beta1.PostgresCluster{}
+	source.Namespace, source.Name = "ns1", "source"
+
+	clone := &v1beta1.PostgresCluster{}
+	clone.Namespace, clone.Name = "ns1", "clone"
+	clone.Spec.DependsOn = []v1beta1.Dependency{
+		{ClusterName: "source", Condition: "BackupCompleted"},
+	}
+
+	other := &v1beta1.PostgresCluster{}
+	other.Namespace, other.Name = "ns1", "other"
+
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(source, clone, other).Build(),
+		Recorder: recorder,
+	}
+
+	t.Run("NoDependencies", func(t *testing.T) {
+		waiting, err := r.reconcileDependencies(ctx, other.DeepCopy())
+		assert.NilError(t, err)
+		assert.Assert(t, !waiting)
+	})
+
+	t.Run("Dependents", func(t *testing.T) {
+		found := r.findDependentClusters(ctx, source)
+		assert.Equal(t, len(found), 1)
+		assert.Equal(t, found[0].Name, "clone")
+
+		assert.Equal(t, len(r.findDependentClusters(ctx, other)), 0)
+	})
+
+	t.Run("Waiting", func(t *testing.T) {
+		waiting, err := r.reconcileDependencies(ctx, clone)
+		assert.NilError(t, err)
+		assert.Assert(t, waiting)
+		assert.Assert(t, meta.IsStatusConditionFalse(clone.Status.Conditions, v1beta1.DependenciesSatisfied))
+
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Equal(t, <-recorder.Events, `Normal WaitingForDependencies `+
+			`Waiting because PostgresCluster "source" has no completed backup`)
+
+		// The event happens once, when the condition changes.
+		waiting, err = r.reconcileDependencies(ctx, clone)
+		assert.NilError(t, err)
+		assert.Assert(t, waiting)
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+
+	t.Run("Satisfied", func(t *testing.T) {
+		source.Status.PGBackRest = &v1beta1.PGBackRestStatus{
+			ManualBackup: &v1beta1.PGBackRestJobStatus{Succeeded: 1},
+		}
+		assert.NilError(t, r.Client.Status().Update(ctx, source))
+
+		waiting, err := r.reconcileDependencies(ctx, clone)
+		assert.NilError(t, err)
+		assert.Assert(t, !waiting)
+		assert.Assert(t, meta.IsStatusConditionTrue(clone.Status.Conditions, v1beta1.DependenciesSatisfied))
+
+		// Once satisfied, dependencies are not checked again.
+		assert.NilError(t, r.Client.Delete(ctx, source))
+		waiting, err = r.reconcileDependencies(ctx, clone)
+		assert.NilError(t, err)
+		assert.Assert(t, !waiting)
+	})
+}
@@ -0,0 +1,256 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	// diagnosticsDirectory is where instances keep the diagnostics of crashes.
+	// It is on the data volume outside the data directory.
+	diagnosticsDirectory = "/pgdata/diagnostics"
+
+	// diagnosticsInterval is how often the logs of PostgreSQL are read.
+	diagnosticsInterval = time.Minute
+
+	// crashRecent is how long the CrashDetected condition is true after a crash.
+	crashRecent = 24 * time.Hour
+)
+
+// postgresCrash is a crash of a PostgreSQL process found in the log of the
+// database container.
+type postgresCrash struct {
+	// Time is when PostgreSQL began restarting after the crash.
+	Time time.Time
+
+	// Message is how PostgreSQL described the process that crashed.
+	Message string
+
+	// Excerpt is the log around the crash.
+	Excerpt []byte
+}
+
+// findCrash returns the last crash in log, which has a timestamp at the start
+// of each line. PostgreSQL logs the process that failed and then that it is
+// "terminating any other active server processes" to restart after a crash.
+// - https://www.postgresql.org/docs/current/runtime-config-error-handling.html#GUC-RESTART-AFTER-CRASH
+func findCrash(log []byte) (postgresCrash, bool) {
+	var crash postgresCrash
+	lines := strings.Split(strings.TrimRight(string(log), "\n"), "\n")
+
+	for i := len(lines) - 1; i >= 0; i-- {
+		if !strings.Contains(lines[i], "terminating any other active server processes") {
+			continue
+		}
+		stamp, _, _ := strings.Cut(lines[i], " ")
+		when, err := time.Parse(time.RFC3339Nano, stamp)
+		if err != nil {
+			continue
+		}
+
+		crash.Time = when
+		crash.Message = "a PostgreSQL process exited unexpectedly"
+		for j := i - 1; j >= 0 && j >= i-10; j-- {
+			if strings.Contains(lines[j], " (PID ") &&
+				(strings.Contains(lines[j], " was terminated by ") ||
+					strings.Contains(lines[j], " exited with exit code ")) {
+				_, message, _ := strings.Cut(lines[j], "LOG:")
+				crash.Message = strings.TrimSpace(message)
+				break
+			}
+		}
+
+		first, last := i-50, i+20
+		if first < 0 {
+			first = 0
+		}
+		if last > len(lines) {
+			last = len(lines)
+		}
+		excerpt := lines[first:last]
+		crash.Excerpt = []byte(strings.Join(excerpt, "\n") + "\n")
+		return crash, true
+	}
+	return crash, false
+}
+
+// reconcileDiagnostics reads the logs of PostgreSQL in the instances of cluster
+// for crashes. The diagnostics of each crash are captured by [Reconciler.captureCrash],
+// and the most recent crash is kept in the status of cluster and reported in the
+// CrashDetected condition. Processes crash without changing any Kubernetes
+// objects, so this returns a result that reads the logs again later.
+func (r *Reconciler) reconcileDiagnostics(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) reconcile.Result {
+	log := logging.FromContext(ctx)
+
+	if cluster.Spec.Diagnostics == nil {
+		cluster.Status.Diagnostics = nil
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.CrashDetected)
+		return reconcile.Result{}
+	}
+	if cluster.Status.Diagnostics == nil {
+		cluster.Status.Diagnostics = &v1beta1.DiagnosticsStatus{}
+	}
+	status := cluster.Status.Diagnostics
+
+	now := metav1.Now()
+	if status.ObservedTime != nil {
+		if elapsed := now.Sub(status.ObservedTime.Time); elapsed < diagnosticsInterval {
+			return reconcile.Result{RequeueAfter: diagnosticsInterval - elapsed}
+		}
+	}
+	next := reconcile.Result{RequeueAfter: diagnosticsInterval}
+
+	// The first observation only starts reading the logs. The logs are read
+	// again when any of them could not be read or any crash not captured.
+	var since time.Time
+	if status.LastCrash != nil && status.LastCrash.Time != nil {
+		since = status.LastCrash.Time.Time
+	}
+	observed := true
+	for _, instance := range instances.forCluster {
+		running, known := instance.IsRunning(naming.ContainerDatabase)
+		if status.ObservedTime == nil || !running || !known {
+			continue
+		}
+
+		pod := instance.Pods[0]
+		content, err := r.PodLogs(ctx, pod, naming.ContainerDatabase, status.ObservedTime.Time)
+		crash, found := findCrash(content)
+		if err == nil && found && crash.Time.After(since) {
+			directory := path.Join(diagnosticsDirectory, crash.Time.UTC().Format("20060102T150405Z"))
+			err = r.captureCrash(ctx, cluster, pod, directory, crash.Excerpt)
+
+			if err == nil {
+				r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "PostgreSQLCrashed",
+					"PostgreSQL crashed in pod %s: %s. Diagnostics are in %s",
+					pod.Name, crash.Message, directory)
+
+				if status.LastCrash == nil || status.LastCrash.Time == nil ||
+					crash.Time.After(status.LastCrash.Time.Time) {
+					status.LastCrash = &v1beta1.CrashStatus{
+						Pod:     pod.Name,
+						Time:    &metav1.Time{Time: crash.Time},
+						Message: crash.Message,
+						Path:    directory,
+					}
+				}
+			}
+		}
+		if err != nil {
+			log.Error(err, "unable to read PostgreSQL logs for crashes", "pod", pod.Name)
+			observed = false
+		}
+	}
+	if observed {
+		status.ObservedTime = &now
+	}
+
+	condition := metav1.Condition{
+		Type:               v1beta1.CrashDetected,
+		ObservedGeneration: cluster.GetGeneration(),
+		Status:             metav1.ConditionFalse,
+		Reason:             "NoRecentCrash",
+		Message:            "PostgreSQL has not crashed in the past day",
+	}
+	if crash := status.LastCrash; crash != nil && crash.Time != nil &&
+		now.Sub(crash.Time.Time) < crashRecent {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ProcessCrashed"
+		condition.Message = fmt.Sprintf(
+			"PostgreSQL crashed in pod %s at %s: %s. Diagnostics are in %s",
+			crash.Pod, crash.Time.UTC().Format(time.RFC3339), crash.Message, crash.Path)
+	}
+	meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+
+	return next
+}
+
+// captureCrash writes the diagnostics of a crash to directory in pod: excerpt
+// from the log, snapshots of statistics views, and any core files in the data
+// directory. Only the diagnostics of the most recent crashes are kept.
+func (r *Reconciler) captureCrash(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, pod *corev1.Pod, directory string, excerpt []byte,
+) error {
+	keep := int32(3)
+	if cluster.Spec.Diagnostics.Keep != nil {
+		keep = *cluster.Spec.Diagnostics.Keep
+	}
+
+	// The statistics views describe PostgreSQL after it restarted, which
+	// includes the activity of any clients that reconnected.
+	// - https://www.postgresql.org/docs/current/monitoring-stats.html
+	const script = `
+directory="$1" data="$2" keep="$3"
+mkdir -p "${directory}"
+cat > "${directory}/postgres.log"
+for view in pg_stat_activity pg_stat_database pg_stat_replication pg_stat_bgwriter; do
+  psql -Xw --quiet --command="COPY (SELECT * FROM pg_catalog.${view}) TO STDOUT WITH (FORMAT csv, HEADER)" \
+    > "${directory}/${view}.csv" || true
+done
+find "${data}" -maxdepth 1 -type f -name 'core*' -exec mv --target-directory="${directory}" {} +
+ls -1d "${directory%/*}"/*/ | head --lines="-${keep}" | xargs --no-run-if-empty rm -rf
+`
+	var stdout, stderr bytes.Buffer
+	err := r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase,
+		bytes.NewReader(excerpt), &stdout, &stderr,
+		"bash", "-ceu", "--", script, "-",
+		directory, postgres.DataDirectory(cluster), fmt.Sprint(keep))
+
+	logging.FromContext(ctx).V(1).Info("captured crash diagnostics",
+		"stdout", stdout.String(), "stderr", stderr.String())
+
+	return errors.WithStack(err)
+}
+
+// addCoreDumpsToInstancePodSpec raises the limit on the size of core files in
+// the database container of pod to what the Node allows when cluster asks for
+// core dumps. PostgreSQL processes write them in the data directory.
+func addCoreDumpsToInstancePodSpec(cluster *v1beta1.PostgresCluster, pod *corev1.PodSpec) {
+	if cluster.Spec.Diagnostics == nil || !cluster.Spec.Diagnostics.CoreDumps {
+		return
+	}
+
+	for i := range pod.Containers {
+		container := &pod.Containers[i]
+		if container.Name != naming.ContainerDatabase {
+			continue
+		}
+
+		// Raise the soft limit to the hard limit then replace the shell with
+		// the original command so that it receives signals.
+		container.Command = append([]string{"bash", "-c",
+			`ulimit -S -c "$(ulimit -H -c)" && exec "$@"`,
+			"coredumps"}, container.Command...)
+	}
+}
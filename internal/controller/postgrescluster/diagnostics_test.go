@@ -0,0 +1,195 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const crashLog = `2024-05-01T10:00:00.000000000Z 2024-05-01 10:00:00.000 UTC [90] LOG:  checkpoint starting: time
+2024-05-01T10:00:05.000000000Z 2024-05-01 10:00:05.000 UTC [90] LOG:  server process (PID 123) was terminated by signal 11: Segmentation fault
+2024-05-01T10:00:05.000000000Z 2024-05-01 10:00:05.000 UTC [90] DETAIL:  Failed process was running: SELECT crash()
+2024-05-01T10:00:05.100000000Z 2024-05-01 10:00:05.100 UTC [90] LOG:  terminating any other active server processes
+2024-05-01T10:00:06.000000000Z 2024-05-01 10:00:06.000 UTC [90] LOG:  all server processes terminated; reinitializing
+`
+
+func TestFindCrash(t *testing.T) {
+	_, found := findCrash(nil)
+	assert.Assert(t, !found)
+
+	_, found = findCrash([]byte(strings.SplitAfter(crashLog, "\n")[0]))
+	assert.Assert(t, !found)
+
+	crash, found := findCrash([]byte(crashLog))
+	assert.Assert(t, found)
+	assert.Equal(t, crash.Time, time.Date(2024, 5, 1, 10, 0, 5, 100000000, time.UTC))
+	assert.Equal(t, crash.Message, "server process (PID 123) was terminated by signal 11: Segmentation fault")
+	assert.Equal(t, string(crash.Excerpt), crashLog)
+
+	// Without the process that crashed, the message is generic.
+	crash, found = findCrash([]byte(strings.SplitAfterN(crashLog, "\n", 3)[2]))
+	assert.Assert(t, found)
+	assert.Equal(t, crash.Message, "a PostgreSQL process exited unexpectedly")
+}
+
+func TestReconcileDiagnostics(t *testing.T) {
+	ctx := context.Background()
+
+	pod := &corev1.Pod{}
+	pod.Namespace, pod.Name = "ns1", "hippo-00-abcd-0"
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  naming.ContainerDatabase,
+		State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+	}}
+
+	instances := &observedInstances{forCluster: []*Instance{
+		{Name: "hippo-00-abcd", Pods: []*corev1.Pod{pod}},
+	}}
+
+	var logs string
+	var commands [][]string
+	var excerpt []byte
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Recorder: recorder,
+		PodExec: func(namespace, pod, container string, stdin io.Reader, _, _ io.Writer, command ...string) error {
+			assert.Equal(t, container, naming.ContainerDatabase)
+			commands = append(commands, command)
+			excerpt, _ = io.ReadAll(stdin)
+			return nil
+		},
+		PodLogs: func(_ context.Context, _ *corev1.Pod, container string, _ time.Time) ([]byte, error) {
+			assert.Equal(t, container, naming.ContainerDatabase)
+			return []byte(logs), nil
+		},
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace = "ns1"
+	cluster.Spec.PostgresVersion = 16
+
+	t.Run("Disabled", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Status.Diagnostics = &v1beta1.DiagnosticsStatus{}
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type: v1beta1.CrashDetected, Status: metav1.ConditionTrue, Reason: "ProcessCrashed",
+		})
+
+		result := reconciler.reconcileDiagnostics(ctx, cluster, instances)
+		assert.Equal(t, result.RequeueAfter, time.Duration(0))
+		assert.Assert(t, cluster.Status.Diagnostics == nil)
+		assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.CrashDetected) == nil)
+	})
+
+	cluster.Spec.Diagnostics = &v1beta1.DiagnosticsSpec{Keep: initialize.Int32(5)}
+
+	t.Run("Crash", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+
+		// The first observation reads nothing.
+		logs = crashLog
+		result := reconciler.reconcileDiagnostics(ctx, cluster, instances)
+		assert.Equal(t, result.RequeueAfter, diagnosticsInterval)
+		assert.Assert(t, cluster.Status.Diagnostics.ObservedTime != nil)
+		assert.Equal(t, len(commands), 0)
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.CrashDetected)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "NoRecentCrash")
+
+		// Nothing is read until the interval passes.
+		result = reconciler.reconcileDiagnostics(ctx, cluster, instances)
+		assert.Assert(t, result.RequeueAfter > 0 && result.RequeueAfter <= diagnosticsInterval)
+		assert.Equal(t, len(commands), 0)
+
+		// A crash is captured and recorded.
+		logs = strings.ReplaceAll(crashLog, "2024-05-01T10", time.Now().UTC().Format("2006-01-02T15"))
+		cluster.Status.Diagnostics.ObservedTime = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+		reconciler.reconcileDiagnostics(ctx, cluster, instances)
+		assert.Equal(t, len(commands), 1)
+		assert.Equal(t, string(excerpt), logs)
+
+		crash := cluster.Status.Diagnostics.LastCrash
+		assert.Equal(t, crash.Pod, "hippo-00-abcd-0")
+		assert.Assert(t, strings.HasPrefix(crash.Path, "/pgdata/diagnostics/"))
+		assert.Equal(t, crash.Message, "server process (PID 123) was terminated by signal 11: Segmentation fault")
+		assert.DeepEqual(t, commands[0][len(commands[0])-3:], []string{crash.Path, "/pgdata/pg16", "5"})
+
+		condition = meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.CrashDetected)
+		assert.Equal(t, condition.Status, metav1.ConditionTrue)
+		assert.Equal(t, condition.Reason, "ProcessCrashed")
+		assert.Assert(t, cmp.Contains(condition.Message, "Diagnostics are in "+crash.Path))
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "PostgreSQLCrashed"))
+
+		// The same crash is not captured again.
+		cluster.Status.Diagnostics.ObservedTime = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+		reconciler.reconcileDiagnostics(ctx, cluster, instances)
+		assert.Equal(t, len(commands), 1)
+		assert.Equal(t, len(recorder.Events), 0)
+
+		// The condition is false a day later.
+		cluster.Status.Diagnostics.ObservedTime = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+		crash.Time = &metav1.Time{Time: time.Now().Add(-25 * time.Hour)}
+		logs = ""
+		reconciler.reconcileDiagnostics(ctx, cluster, instances)
+		condition = meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.CrashDetected)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+	})
+}
+
+func TestAddCoreDumpsToInstancePodSpec(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	pod := &corev1.PodSpec{Containers: []corev1.Container{
+		{Name: naming.ContainerDatabase, Command: []string{"patroni", "/etc/patroni"}},
+		{Name: "other", Command: []string{"other"}},
+	}}
+
+	addCoreDumpsToInstancePodSpec(cluster, pod)
+	assert.DeepEqual(t, pod.Containers[0].Command, []string{"patroni", "/etc/patroni"})
+
+	cluster.Spec.Diagnostics = &v1beta1.DiagnosticsSpec{CoreDumps: true}
+	addCoreDumpsToInstancePodSpec(cluster, pod)
+
+	assert.Assert(t, cmp.MarshalMatches(pod.Containers, `
+- command:
+  - bash
+  - -c
+  - ulimit -S -c "$(ulimit -H -c)" && exec "$@"
+  - coredumps
+  - patroni
+  - /etc/patroni
+  name: database
+  resources: {}
+- command:
+  - other
+  name: other
+  resources: {}
+	`))
+}
@@ -0,0 +1,38 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// externalDNSAnnotations returns the annotations that tell external-dns to
+// publish hostnames for a Service. It returns nil when there are none.
+// - https://github.com/kubernetes-sigs/external-dns/blob/master/docs/annotations/annotations.md
+func externalDNSAnnotations(dns *v1beta1.PostgresClusterDNS, hostnames []string) map[string]string {
+	if dns == nil || len(hostnames) == 0 {
+		return nil
+	}
+	annotations := map[string]string{
+		"external-dns.alpha.kubernetes.io/hostname": strings.Join(hostnames, ","),
+	}
+	if dns.TTLSeconds != nil {
+		annotations["external-dns.alpha.kubernetes.io/ttl"] = fmt.Sprint(*dns.TTLSeconds)
+	}
+	return annotations
+}
@@ -0,0 +1,42 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestExternalDNSAnnotations(t *testing.T) {
+	assert.Assert(t, externalDNSAnnotations(nil, []string{"a.example.com"}) == nil)
+
+	dns := &v1beta1.PostgresClusterDNS{}
+	assert.Assert(t, externalDNSAnnotations(dns, nil) == nil)
+	assert.DeepEqual(t, externalDNSAnnotations(dns, []string{"a.example.com", "b.example.com"}),
+		map[string]string{
+			"external-dns.alpha.kubernetes.io/hostname": "a.example.com,b.example.com",
+		})
+
+	dns.TTLSeconds = initialize.Int32(60)
+	assert.DeepEqual(t, externalDNSAnnotations(dns, []string{"a.example.com"}),
+		map[string]string{
+			"external-dns.alpha.kubernetes.io/hostname": "a.example.com",
+			"external-dns.alpha.kubernetes.io/ttl":      "60",
+		})
+}
@@ -0,0 +1,148 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/util"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// injectArchivePushDelay makes PostgreSQL wait before archiving each WAL file
+// when the fault-archive-push-delay annotation is a positive duration.
+func injectArchivePushDelay(cluster *v1beta1.PostgresCluster, outParameters *postgres.Parameters) {
+	if !util.DefaultMutableFeatureGate.Enabled(util.FaultInjection) {
+		return
+	}
+
+	delay, err := time.ParseDuration(cluster.GetAnnotations()[naming.FaultArchivePushDelay])
+	if command, ok := outParameters.Mandatory.Get("archive_command"); ok && err == nil && delay >= time.Second {
+		outParameters.Mandatory.Add("archive_command",
+			fmt.Sprintf("sleep %d && %s", int64(delay/time.Second), command))
+	}
+}
+
+// reconcileFaultInjection injects the faults requested by annotations on
+// cluster. Faults are injected only when the FaultInjection feature gate is
+// enabled, but those that persist are always removed when not requested.
+func (r *Reconciler) reconcileFaultInjection(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) error {
+	enabled := util.DefaultMutableFeatureGate.Enabled(util.FaultInjection)
+
+	var err error
+	if enabled {
+		err = r.reconcileFaultKillPrimary(ctx, cluster, instances)
+	}
+	if err == nil {
+		err = r.reconcileFaultRepoHostPartition(ctx, cluster, enabled)
+	}
+	return err
+}
+
+// +kubebuilder:rbac:groups="",resources="pods",verbs={delete}
+
+// reconcileFaultKillPrimary deletes the primary Pod without waiting for it to
+// stop, once for each value of the fault-kill-primary annotation.
+func (r *Reconciler) reconcileFaultKillPrimary(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) error {
+	annotation := cluster.GetAnnotations()[naming.FaultKillPrimary]
+	if annotation == "" || (cluster.Status.FaultInjection != nil &&
+		cluster.Status.FaultInjection.PrimaryKilled == annotation) {
+		return nil
+	}
+
+	// Wait for a primary.
+	pod, _ := instances.writablePod(naming.ContainerDatabase)
+	if pod == nil {
+		return nil
+	}
+
+	err := errors.WithStack(client.IgnoreNotFound(
+		r.Client.Delete(ctx, pod, client.GracePeriodSeconds(0))))
+
+	if err == nil {
+		if cluster.Status.FaultInjection == nil {
+			cluster.Status.FaultInjection = new(v1beta1.FaultInjectionStatus)
+		}
+		cluster.Status.FaultInjection.PrimaryKilled = annotation
+
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "FaultInjected",
+			"Deleted primary Pod %q", pod.Name)
+	}
+	return err
+}
+
+// +kubebuilder:rbac:groups="networking.k8s.io",resources="networkpolicies",verbs={get,list,watch}
+// +kubebuilder:rbac:groups="networking.k8s.io",resources="networkpolicies",verbs={create,patch,delete}
+
+// reconcileFaultRepoHostPartition isolates the pgBackRest repository host
+// with a NetworkPolicy while enabled and the fault-partition-repo-host
+// annotation is "true". The NetworkPolicy is deleted otherwise, so turning off
+// the feature gate heals the partition. Isolation requires a network plugin
+// that enforces NetworkPolicy.
+func (r *Reconciler) reconcileFaultRepoHostPartition(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, enabled bool,
+) error {
+	policy := &networkingv1.NetworkPolicy{ObjectMeta: naming.FaultRepoHostNetworkPolicy(cluster)}
+
+	if !enabled || cluster.GetAnnotations()[naming.FaultPartitionRepoHost] != "true" {
+		err := errors.WithStack(r.Client.Get(ctx, client.ObjectKeyFromObject(policy), policy))
+		if err == nil {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, policy))
+		}
+		return client.IgnoreNotFound(err)
+	}
+
+	generateFaultRepoHostNetworkPolicy(cluster, policy)
+
+	policy.SetGroupVersionKind(networkingv1.SchemeGroupVersion.WithKind("NetworkPolicy"))
+	err := errors.WithStack(r.setControllerReference(cluster, policy))
+
+	if err == nil {
+		err = r.apply(ctx, policy)
+	}
+	return err
+}
+
+// generateFaultRepoHostNetworkPolicy populates policy so that nothing is
+// allowed in or out of the repository host of cluster. A policy without rules
+// denies every connection of the types it lists.
+func generateFaultRepoHostNetworkPolicy(
+	cluster *v1beta1.PostgresCluster, policy *networkingv1.NetworkPolicy,
+) {
+	policy.Labels = map[string]string{naming.LabelCluster: cluster.Name}
+	policy.Spec = networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{
+			MatchLabels: naming.PGBackRestDedicatedLabels(cluster.Name),
+		},
+		PolicyTypes: []networkingv1.PolicyType{
+			networkingv1.PolicyTypeIngress,
+			networkingv1.PolicyTypeEgress,
+		},
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/util"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestInjectArchivePushDelay(t *testing.T) {
+	t.Cleanup(func() {
+		assert.NilError(t, util.AddAndSetFeatureGates(string(util.FaultInjection+"=false")))
+	})
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Annotations = map[string]string{naming.FaultArchivePushDelay: "30s"}
+
+	archive := func(cluster *v1beta1.PostgresCluster) string {
+		parameters := postgres.NewParameters()
+		parameters.Mandatory.Add("archive_command", "pgbackrest archive-push")
+		injectArchivePushDelay(cluster, &parameters)
+		value, _ := parameters.Mandatory.Get("archive_command")
+		return value
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		assert.NilError(t, util.AddAndSetFeatureGates(string(util.FaultInjection+"=false")))
+		assert.Equal(t, archive(cluster), "pgbackrest archive-push")
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		assert.NilError(t, util.AddAndSetFeatureGates(string(util.FaultInjection+"=true")))
+		assert.Equal(t, archive(cluster), "sleep 30 && pgbackrest archive-push")
+
+		for _, value := range []string{"", "soon", "500ms", "-1m"} {
+			cluster := cluster.DeepCopy()
+			cluster.Annotations[naming.FaultArchivePushDelay] = value
+			assert.Equal(t, archive(cluster), "pgbackrest archive-push", "value %q", value)
+		}
+	})
+}
+
+func TestReconcileFaultKillPrimary(t *testing.T) {
+	ctx := context.Background()
+
+	pod := &corev1.Pod{}
+	pod.Namespace, pod.Name = "ns1", "hippo-00-abcd-0"
+	pod.Annotations = map[string]string{"status": `{"role":"master"}`}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  naming.ContainerDatabase,
+		State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+	}}
+
+	instances := &observedInstances{forCluster: []*Instance{
+		{Name: "hippo-00-abcd", Pods: []*corev1.Pod{pod}},
+	}}
+
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Client:   fake.NewClientBuilder().WithObjects(pod.DeepCopy()).Build(),
+		Recorder: recorder,
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	assert.NilError(t, reconciler.reconcileFaultKillPrimary(ctx, cluster, instances))
+	assert.Assert(t, cluster.Status.FaultInjection == nil)
+
+	cluster.Annotations = map[string]string{naming.FaultKillPrimary: "one"}
+	assert.NilError(t, reconciler.reconcileFaultKillPrimary(ctx, cluster, instances))
+	assert.Equal(t, cluster.Status.FaultInjection.PrimaryKilled, "one")
+	assert.Equal(t, len(recorder.Events), 1)
+
+	var pods corev1.PodList
+	assert.NilError(t, reconciler.Client.List(ctx, &pods))
+	assert.Equal(t, len(pods.Items), 0)
+
+	// The same value does nothing.
+	assert.NilError(t, reconciler.reconcileFaultKillPrimary(ctx, cluster, instances))
+	assert.Equal(t, len(recorder.Events), 1)
+}
+
+func TestReconcileFaultRepoHostPartition(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name, cluster.UID = "ns1", "hippo", "some-uid"
+	cluster.Annotations = map[string]string{naming.FaultPartitionRepoHost: "true"}
+
+	policy := &networkingv1.NetworkPolicy{ObjectMeta: naming.FaultRepoHostNetworkPolicy(cluster)}
+	policy.OwnerReferences = []metav1.OwnerReference{{
+		APIVersion: v1beta1.GroupVersion.String(), Kind: "PostgresCluster",
+		Name: cluster.Name, UID: cluster.UID, Controller: initialize.Bool(true),
+	}}
+
+	reconciler := &Reconciler{
+		Client: fake.NewClientBuilder().WithObjects(policy.DeepCopy()).Build(),
+	}
+
+	// The partition is removed when the feature gate is disabled, even while
+	// the annotation asks for it.
+	assert.NilError(t, reconciler.reconcileFaultRepoHostPartition(ctx, cluster, false))
+
+	var policies networkingv1.NetworkPolicyList
+	assert.NilError(t, reconciler.Client.List(ctx, &policies))
+	assert.Equal(t, len(policies.Items), 0)
+
+	// Nothing to remove is fine.
+	assert.NilError(t, reconciler.reconcileFaultRepoHostPartition(ctx, cluster, false))
+}
+
+func TestGenerateFaultRepoHostNetworkPolicy(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Name = "hippo"
+
+	policy := &networkingv1.NetworkPolicy{}
+	generateFaultRepoHostNetworkPolicy(cluster, policy)
+
+	assert.Assert(t, marshalMatches(policy.Spec, `
+podSelector:
+  matchLabels:
+    postgres-operator.crunchydata.com/cluster: hippo
+    postgres-operator.crunchydata.com/pgbackrest: ""
+    postgres-operator.crunchydata.com/pgbackrest-dedicated: ""
+policyTypes:
+- Ingress
+- Egress
+	`))
+}
@@ -0,0 +1,150 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={get}
+
+// reconcileForeignServers writes the foreign servers and user mappings of
+// every database in the spec into PostgreSQL. Credentials are read from the
+// Secrets of each user mapping, so a change to a Secret changes the revision
+// and the mapping is written again.
+func (r *Reconciler) reconcileForeignServers(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) error {
+	var databases []v1beta1.PostgresDatabaseSpec
+	for _, database := range cluster.Spec.Databases {
+		if len(database.ForeignServers) > 0 {
+			databases = append(databases, database)
+		}
+	}
+	if len(databases) == 0 {
+		cluster.Status.ForeignServersRevision = ""
+		return nil
+	}
+
+	// Find the PostgreSQL instance that can execute SQL that writes system
+	// catalogs. When there is none, return early.
+	pod, _ := instances.writablePod(naming.ContainerDatabase)
+	if pod == nil {
+		return nil
+	}
+
+	ctx = logging.NewContext(ctx, logging.FromContext(ctx).WithValues("pod", pod.Name))
+	podExecutor := func(
+		_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		return r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase, stdin, stdout, stderr, command...)
+	}
+
+	// Read the credentials of every user mapping. Nothing is written until
+	// all of them are available.
+	credentials := make(map[string]postgres.ForeignCredentials)
+	var names []string
+	for _, database := range databases {
+		for _, server := range database.ForeignServers {
+			names = append(names, fmt.Sprintf("%s/%s", database.Name, server.Name))
+
+			for _, mapping := range server.UserMappings {
+				if _, ok := credentials[mapping.SecretName]; ok {
+					continue
+				}
+
+				secret := &corev1.Secret{}
+				err := errors.WithStack(r.Client.Get(ctx, client.ObjectKey{
+					Namespace: cluster.Namespace, Name: mapping.SecretName,
+				}, secret))
+
+				if apierrors.IsNotFound(err) || (err == nil &&
+					(len(secret.Data["user"]) == 0 || len(secret.Data["password"]) == 0)) {
+					r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "ForeignServerCredentialsMissing",
+						`Secret %q of foreign server %q must have "user" and "password" keys`,
+						mapping.SecretName, server.Name)
+					return nil
+				}
+				if err != nil {
+					return err
+				}
+
+				credentials[mapping.SecretName] = postgres.ForeignCredentials{
+					User:     string(secret.Data["user"]),
+					Password: string(secret.Data["password"]),
+				}
+			}
+		}
+	}
+
+	// Calculate a hash of the SQL that should be executed in PostgreSQL.
+
+	write := func(ctx context.Context, exec postgres.Executor) error {
+		var err error
+		for _, database := range databases {
+			if err == nil {
+				err = postgres.WriteForeignServersInPostgreSQL(ctx, exec,
+					string(database.Name), database.ForeignServers, credentials)
+			}
+		}
+		return err
+	}
+
+	revision, err := safeHash32(func(hasher io.Writer) error {
+		// Discard log messages about executing SQL.
+		return write(logging.NewContext(ctx, logging.Discard()), func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			_, err := fmt.Fprint(hasher, command)
+			if err == nil && stdin != nil {
+				_, err = io.Copy(hasher, stdin)
+			}
+			return err
+		})
+	})
+
+	if err == nil && revision == cluster.Status.ForeignServersRevision {
+		// The necessary SQL has already been applied; there's nothing more to do.
+		return nil
+	}
+
+	// Apply the necessary SQL and record its hash in cluster.Status. Include
+	// the hash in any log messages. The event does not include credentials.
+
+	if err == nil {
+		log := logging.FromContext(ctx).WithValues("revision", revision)
+		err = errors.WithStack(write(logging.NewContext(ctx, log), podExecutor))
+	}
+	if err == nil {
+		cluster.Status.ForeignServersRevision = revision
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "ForeignServersUpdated",
+			"Updated foreign servers and user mappings: %s", strings.Join(names, ", "))
+	}
+
+	return err
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestReconcileForeignServers(t *testing.T) {
+	ctx := context.Background()
+
+	pod := &corev1.Pod{}
+	pod.Namespace, pod.Name = "ns1", "hippo-00-abcd-0"
+	pod.Annotations = map[string]string{"status": `{"role":"master"}`}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  naming.ContainerDatabase,
+		State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+	}}
+
+	instances := &observedInstances{forCluster: []*Instance{
+		{Name: "hippo-00-abcd", Pods: []*corev1.Pod{pod}},
+	}}
+
+	secret := &corev1.Secret{}
+	secret.Namespace, secret.Name = "ns1", "remote-creds"
+	secret.Data = map[string][]byte{"user": []byte("reader"), "password": []byte("secret1")}
+
+	var calls int
+	var written []byte
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Client:   fake.NewClientBuilder().WithObjects(secret).Build(),
+		Recorder: recorder,
+		PodExec: func(_, _, container string, stdin io.Reader, _, _ io.Writer, _ ...string) error {
+			assert.Equal(t, container, naming.ContainerDatabase)
+			calls++
+
+			var err error
+			written, err = io.ReadAll(stdin)
+			return err
+		},
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+	cluster.Spec.Databases = []v1beta1.PostgresDatabaseSpec{{
+		Name: "app",
+		ForeignServers: []v1beta1.PostgresForeignServerSpec{{
+			Name: "billing", Host: "billing.example.com", DBName: "billing",
+			UserMappings: []v1beta1.PostgresUserMappingSpec{
+				{User: "app", SecretName: "remote-creds"},
+			},
+		}},
+	}}
+
+	t.Run("Unspecified", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Databases[0].ForeignServers = nil
+		cluster.Status.ForeignServersRevision = "old"
+
+		assert.NilError(t, reconciler.reconcileForeignServers(ctx, cluster, instances))
+		assert.Equal(t, calls, 0)
+		assert.Equal(t, cluster.Status.ForeignServersRevision, "")
+	})
+
+	t.Run("MissingCredentials", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Databases[0].ForeignServers[0].UserMappings[0].SecretName = "missing"
+
+		assert.NilError(t, reconciler.reconcileForeignServers(ctx, cluster, instances))
+		assert.Equal(t, calls, 0)
+		assert.Equal(t, cluster.Status.ForeignServersRevision, "")
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "ForeignServerCredentialsMissing"))
+	})
+
+	t.Run("Written", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		calls = 0
+
+		assert.NilError(t, reconciler.reconcileForeignServers(ctx, cluster, instances))
+		assert.Equal(t, calls, 1)
+		assert.Assert(t, cluster.Status.ForeignServersRevision != "")
+		assert.Assert(t, cmp.Contains(string(written), `"password":"secret1"`))
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "app/billing"))
+
+		// Nothing is written again until something changes.
+		assert.NilError(t, reconciler.reconcileForeignServers(ctx, cluster, instances))
+		assert.Equal(t, calls, 1)
+
+		// A new password is written.
+		secret.Data["password"] = []byte("secret2")
+		assert.NilError(t, reconciler.Client.Update(ctx, secret))
+		assert.NilError(t, reconciler.reconcileForeignServers(ctx, cluster, instances))
+		assert.Equal(t, calls, 2)
+		assert.Assert(t, cmp.Contains(string(written), `"password":"secret2"`))
+	})
+}
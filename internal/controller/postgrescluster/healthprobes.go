@@ -0,0 +1,233 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/healthprobe"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	pgpassword "github.com/crunchydata/postgres-operator/internal/postgres/password"
+	"github.com/crunchydata/postgres-operator/internal/util"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// healthProbesJob reports the outcome of the synthetic transaction in the
+// ClusterUsable condition.
+var healthProbesJob = scheduledJob{
+	name:      "healthprobe",
+	label:     naming.LabelHealthProbes,
+	condition: v1beta1.ClusterUsable,
+
+	succeededReason:  "ProbeSucceeded",
+	succeededMessage: "A heartbeat was written, read, and replicated",
+
+	failedReason:  "ProbeFailed",
+	failedMessage: "The synthetic transaction did not finish; see the logs of Job %q",
+}
+
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={get,create,patch,delete}
+// +kubebuilder:rbac:groups="batch",resources="cronjobs",verbs={get,create,patch,delete}
+// +kubebuilder:rbac:groups="batch",resources="jobs",verbs={list}
+
+// reconcileHealthProbes creates the health probe user and its heartbeat table,
+// stores its password in a Secret, and schedules a CronJob that runs a
+// synthetic transaction against the cluster. The outcome of the latest Job is
+// reported in the ClusterUsable condition, which is distinct from the
+// readiness of any one Pod.
+//
+// Unlike other scheduled Jobs, the probe connects the way applications do,
+// through PgBouncer, so it authenticates with a password rather than as the
+// maintenance user.
+func (r *Reconciler) reconcileHealthProbes(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) error {
+	enabled := healthprobe.Enabled(cluster)
+
+	secret, err := r.reconcileHealthProbesSecret(ctx, cluster)
+	if err != nil {
+		return err
+	}
+
+	// Change PostgreSQL only when something was installed or is requested.
+	status := cluster.Status.HealthProbes
+	if status != nil || enabled {
+		if status == nil {
+			status = new(v1beta1.HealthProbesStatus)
+		}
+
+		action := func(ctx context.Context, exec postgres.Executor) error {
+			if enabled {
+				return healthprobe.EnableInPostgreSQL(ctx, exec, string(secret.Data["verifier"]))
+			}
+			return healthprobe.DisableInPostgreSQL(ctx, exec)
+		}
+
+		current, err := r.reconcileSQLRevision(ctx, instances, &status.Revision, action)
+		if err != nil {
+			return err
+		}
+
+		cluster.Status.HealthProbes = status
+		if current && !enabled {
+			cluster.Status.HealthProbes = nil
+		}
+	}
+
+	// Wait for the heartbeat table to exist before scheduling any probes.
+	cronjob := &batchv1.CronJob{ObjectMeta: naming.HealthProbesCronJob(cluster)}
+	finished, err := r.reconcileScheduledJob(ctx, cluster, healthProbesJob, cronjob,
+		enabled, status != nil && status.Revision != "",
+		func(cronjob *batchv1.CronJob) { generateHealthProbesCronJob(cluster, secret, cronjob) })
+
+	if finished != nil {
+		cluster.Status.HealthProbes.LastProbeTime = finished
+	}
+	return err
+}
+
+// reconcileHealthProbesSecret creates a Secret containing the password and
+// SCRAM verifier of the health probe user when health probes are enabled.
+// The Secret is deleted otherwise.
+func (r *Reconciler) reconcileHealthProbesSecret(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) (*corev1.Secret, error) {
+	existing := &corev1.Secret{ObjectMeta: naming.HealthProbesSecret(cluster)}
+	err := errors.WithStack(
+		r.Client.Get(ctx, client.ObjectKeyFromObject(existing), existing))
+	if client.IgnoreNotFound(err) != nil {
+		return nil, err
+	}
+
+	if !healthprobe.Enabled(cluster) {
+		if err == nil {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, existing))
+		}
+		return nil, client.IgnoreNotFound(err)
+	}
+
+	intent := &corev1.Secret{ObjectMeta: naming.HealthProbesSecret(cluster)}
+	intent.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+
+	intent.Annotations = naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil())
+	intent.Labels = naming.Merge(cluster.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster:      cluster.Name,
+			naming.LabelHealthProbes: "",
+		})
+
+	// Copy existing password and verifier into the intent
+	intent.Data = make(map[string][]byte)
+	if existing.Data != nil {
+		intent.Data["password"] = existing.Data["password"]
+		intent.Data["verifier"] = existing.Data["verifier"]
+	}
+
+	// When password is unset, generate a new one and unset the verifier so
+	// that it is regenerated.
+	if len(intent.Data["password"]) == 0 {
+		password, err := util.GenerateASCIIPassword(util.DefaultGeneratedPasswordLength)
+		if err != nil {
+			return nil, err
+		}
+		intent.Data["password"] = []byte(password)
+		intent.Data["verifier"] = nil
+	}
+	if len(intent.Data["verifier"]) == 0 {
+		verifier, err := pgpassword.NewSCRAMPassword(string(intent.Data["password"])).Build()
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		intent.Data["verifier"] = []byte(verifier)
+	}
+
+	err = errors.WithStack(r.setControllerReference(cluster, intent))
+	if err == nil {
+		err = r.apply(ctx, intent)
+	}
+	if err == nil {
+		return intent, nil
+	}
+	return nil, err
+}
+
+// generateHealthProbesCronJob populates cronjob with a schedule that writes a
+// heartbeat through PgBouncer or the primary of cluster and waits for a
+// replica to replay it.
+func generateHealthProbesCronJob(
+	cluster *v1beta1.PostgresCluster, secret *corev1.Secret,
+	cronjob *batchv1.CronJob,
+) {
+	spec := cluster.Spec.HealthProbes
+
+	// Connect the way applications do: through PgBouncer, when it is enabled.
+	primary := naming.ClusterPrimaryService(cluster)
+	port := *cluster.Spec.Port
+	if cluster.Spec.Proxy != nil && cluster.Spec.Proxy.PGBouncer != nil {
+		primary = naming.ClusterPGBouncer(cluster)
+		port = *cluster.Spec.Proxy.PGBouncer.Port
+	}
+	connection := func(service metav1.ObjectMeta, port int32) string {
+		return fmt.Sprintf("host=%s.%s.svc port=%d dbname=postgres user=%s sslmode=require",
+			service.Name, service.Namespace, port, healthprobe.User)
+	}
+
+	env := []corev1.EnvVar{
+		{Name: "PRIMARY", Value: connection(primary, port)},
+		{Name: "PGPASSWORD", ValueFrom: &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: secret.Name},
+				Key:                  "password",
+			},
+		}},
+	}
+
+	// Check replica freshness only when the cluster is expected to have one.
+	var replicas int32
+	for _, set := range cluster.Spec.InstanceSets {
+		if set.Replicas != nil {
+			replicas += *set.Replicas
+		}
+	}
+	if replicas > 1 {
+		env = append(env, corev1.EnvVar{
+			Name:  "REPLICA",
+			Value: connection(naming.ClusterReplicaService(cluster), *cluster.Spec.Port),
+		})
+	}
+
+	// Suspend the CronJob when shutdown. A probe that is still waiting on a
+	// replica does not overlap the next one; their heartbeats would race.
+	suspend := cluster.Spec.Shutdown != nil && *cluster.Spec.Shutdown
+
+	generateScheduledJob(cluster, healthProbesJob, cronjob,
+		spec.Schedule, suspend, corev1.Container{
+			Command:   healthprobe.Command(cluster),
+			Env:       env,
+			Name:      naming.ContainerJobHealthProbe,
+			Resources: spec.Resources,
+		})
+
+	cronjob.Spec.JobTemplate.Spec.TTLSecondsAfterFinished = spec.TTLSecondsAfterFinished
+}
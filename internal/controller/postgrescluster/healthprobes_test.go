@@ -0,0 +1,94 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestGenerateHealthProbesCronJob(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Name = "hippo"
+	cluster.Namespace = "ns1"
+	cluster.Spec.Port = initialize.Int32(5432)
+	cluster.Spec.Image = "some-image"
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+		{Name: "one", Replicas: initialize.Int32(1)},
+	}
+	cluster.Spec.HealthProbes = &v1beta1.HealthProbesSpec{
+		Schedule:                "*/5 * * * *",
+		TTLSecondsAfterFinished: initialize.Int32(100),
+	}
+	secret := &corev1.Secret{ObjectMeta: naming.HealthProbesSecret(cluster)}
+
+	env := func(cronjob *batchv1.CronJob) map[string]string {
+		out := map[string]string{}
+		for _, e := range cronjob.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Env {
+			out[e.Name] = e.Value
+		}
+		return out
+	}
+
+	cronjob := &batchv1.CronJob{ObjectMeta: naming.HealthProbesCronJob(cluster)}
+	generateHealthProbesCronJob(cluster, secret, cronjob)
+
+	assert.Equal(t, cronjob.Spec.Schedule, "*/5 * * * *")
+	assert.Equal(t, cronjob.Spec.ConcurrencyPolicy, batchv1.ForbidConcurrent)
+	assert.Equal(t, *cronjob.Spec.Suspend, false)
+	assert.Equal(t, *cronjob.Spec.JobTemplate.Spec.BackoffLimit, int32(0))
+	assert.Equal(t, *cronjob.Spec.JobTemplate.Spec.TTLSecondsAfterFinished, int32(100))
+	assert.DeepEqual(t, cronjob.Labels, map[string]string{
+		naming.LabelCluster:      "hippo",
+		naming.LabelHealthProbes: "",
+	})
+	assert.DeepEqual(t, env(cronjob), map[string]string{
+		"PRIMARY":    "host=hippo-primary.ns1.svc port=5432 dbname=postgres user=_crunchyhealthprobe sslmode=require",
+		"PGPASSWORD": "",
+	})
+
+	t.Run("PgBouncerAndReplicas", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.InstanceSets[0].Replicas = initialize.Int32(2)
+		cluster.Spec.Proxy = &v1beta1.PostgresProxySpec{
+			PGBouncer: &v1beta1.PGBouncerPodSpec{Port: initialize.Int32(6432)},
+		}
+
+		cronjob := &batchv1.CronJob{ObjectMeta: naming.HealthProbesCronJob(cluster)}
+		generateHealthProbesCronJob(cluster, secret, cronjob)
+		assert.DeepEqual(t, env(cronjob), map[string]string{
+			"PRIMARY":    "host=hippo-pgbouncer.ns1.svc port=6432 dbname=postgres user=_crunchyhealthprobe sslmode=require",
+			"REPLICA":    "host=hippo-replicas.ns1.svc port=5432 dbname=postgres user=_crunchyhealthprobe sslmode=require",
+			"PGPASSWORD": "",
+		})
+	})
+
+	t.Run("Shutdown", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Shutdown = initialize.Bool(true)
+
+		cronjob := &batchv1.CronJob{ObjectMeta: naming.HealthProbesCronJob(cluster)}
+		generateHealthProbesCronJob(cluster, secret, cronjob)
+		assert.Equal(t, *cronjob.Spec.Suspend, true)
+	})
+}
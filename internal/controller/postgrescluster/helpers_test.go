@@ -212,7 +212,7 @@ func testCluster() *v1beta1.PostgresCluster {
 func setupManager(t *testing.T, cfg *rest.Config,
 	controllerSetup func(mgr manager.Manager)) (context.Context, context.CancelFunc) {
 
-	mgr, err := runtime.CreateRuntimeManager("", cfg, true)
+	mgr, err := runtime.CreateRuntimeManager("", "", cfg, true)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -17,6 +17,7 @@ package postgrescluster
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"sort"
@@ -29,6 +30,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -45,6 +47,7 @@ import (
 	"github.com/crunchydata/postgres-operator/internal/pgbackrest"
 	"github.com/crunchydata/postgres-operator/internal/pki"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/servicemesh"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
@@ -78,6 +81,24 @@ func (i Instance) IsPrimary() (primary bool, known bool) {
 	return i.Pods[0].Labels[naming.LabelRole] == naming.RolePatroniLeader, true
 }
 
+// WALPosition returns the location of WAL that Patroni last reported for this
+// instance: written on a primary, received or replayed on a replica.
+func (i Instance) WALPosition() (position int64, known bool) {
+	if len(i.Pods) != 1 {
+		return 0, false
+	}
+
+	var status struct {
+		XLogLocation *int64 `json:"xlog_location"`
+	}
+	if json.Unmarshal([]byte(i.Pods[0].Annotations["status"]), &status) != nil ||
+		status.XLogLocation == nil {
+		return 0, false
+	}
+
+	return *status.XLogLocation, true
+}
+
 // IsReady returns whether or not this instance is ready to receive PostgreSQL
 // connections.
 func (i Instance) IsReady() (ready bool, known bool) {
@@ -477,6 +498,10 @@ func (r *Reconciler) deleteInstance(
 				))
 
 			for i := range uList.Items {
+				if gvk.Kind == "PersistentVolumeClaimList" &&
+					retainVolume(cluster, uList.Items[i].GetLabels()[naming.LabelInstanceSet]) {
+					continue
+				}
 				if err == nil {
 					err = errors.WithStack(client.IgnoreNotFound(
 						r.deleteControlled(ctx, cluster, &uList.Items[i])))
@@ -559,10 +584,19 @@ func (r *Reconciler) reconcileInstanceSets(
 
 	// Cleanup Instance Set resources that are no longer needed
 	err = r.cleanupPodDisruptionBudgets(ctx, cluster)
+	if err == nil {
+		err = r.cleanupRemovedInstanceSets(ctx, cluster, instances)
+	}
 	if err != nil {
 		return err
 	}
 
+	// Patroni cannot switch over while paused, and it may not start PostgreSQL
+	// in a redeployed Pod. Hold any rollout until Patroni resumes.
+	if meta.IsStatusConditionTrue(cluster.Status.Conditions, v1beta1.PausedByUser) {
+		return nil
+	}
+
 	// Rollout changes to instances by calling rolloutInstance.
 	err = r.rolloutInstances(ctx, cluster, instances,
 		func(ctx context.Context, instance *Instance) error {
@@ -698,8 +732,16 @@ func (r *Reconciler) rolloutInstance(
 	primary, known := instance.IsPrimary()
 	primary = primary && known
 
+	switchover := true
+	if cluster.Spec.UpdateStrategy != nil &&
+		cluster.Spec.UpdateStrategy.RollingUpdate != nil &&
+		cluster.Spec.UpdateStrategy.RollingUpdate.SwitchoverPrimary != nil {
+		switchover = *cluster.Spec.UpdateStrategy.RollingUpdate.SwitchoverPrimary
+	}
+
 	// When the cluster has more than one instance participating in failover,
-	// perform a controlled switchover to one of those instances. Patroni will
+	// perform a controlled switchover to one of those instances, unless the
+	// rolling update strategy says otherwise. Patroni will
 	// choose the best candidate and demote the primary. It stops PostgreSQL
 	// using what it calls "graceful" mode: it takes an immediate checkpoint in
 	// the background then uses "pg_ctl" to perform a "fast" shutdown when the
@@ -709,7 +751,7 @@ func (r *Reconciler) rolloutInstance(
 	//
 	// NOTE(cbandy): The StatefulSet controlling this Pod reflects this change
 	// in its Status and triggers another reconcile.
-	if primary && len(instances.forCluster) > 1 {
+	if primary && switchover && len(instances.forCluster) > 1 {
 		var span trace.Span
 		ctx, span = r.Tracer.Start(ctx, "patroni-change-primary")
 		defer span.End()
@@ -723,8 +765,8 @@ func (r *Reconciler) rolloutInstance(
 		return err
 	}
 
-	// When the cluster has only one instance for failover, perform a series of
-	// immediate checkpoints to increase the likelihood that a "fast" shutdown
+	// When the primary is not switched over, perform a series of immediate
+	// checkpoints to increase the likelihood that a "fast" shutdown
 	// will complete before the SIGKILL near TerminationGracePeriodSeconds.
 	// - https://docs.k8s.io/concepts/workloads/pods/pod-lifecycle/#pod-termination
 	if primary {
@@ -807,8 +849,25 @@ func (r *Reconciler) rolloutInstances(
 	ctx, span := r.Tracer.Start(ctx, "rollout-instances")
 	defer span.End()
 
+	var strategy v1beta1.RollingUpdateSpec
+	if cluster.Spec.UpdateStrategy != nil && cluster.Spec.UpdateStrategy.RollingUpdate != nil {
+		strategy = *cluster.Spec.UpdateStrategy.RollingUpdate
+	}
+
+	// By default, one instance of the entire cluster can be unavailable.
+	// When MaxUnavailable is set, each instance set has its own allowance.
+	maxUnavailable := 1
+	budget := func(string) string { return "" }
+	if strategy.MaxUnavailable != nil {
+		maxUnavailable = int(*strategy.MaxUnavailable)
+		budget = func(set string) string { return set }
+	}
+	budgetAvailable := make(map[string]int)
+	budgetSpecified := make(map[string]int)
+
 	for _, set := range cluster.Spec.InstanceSets {
 		numSpecified += int(*set.Replicas)
+		budgetSpecified[budget(set.Name)] += int(*set.Replicas)
 	}
 
 	for _, instance := range instances.forCluster {
@@ -826,6 +885,7 @@ func (r *Reconciler) rolloutInstances(
 
 		if available, known := instance.IsAvailable(); known && available {
 			numAvailable++
+			budgetAvailable[budget(instance.Spec.Name)]++
 		}
 
 		if matches, known := instance.PodMatchesPodTemplate(); known && !matches {
@@ -834,32 +894,70 @@ func (r *Reconciler) rolloutInstances(
 		}
 	}
 
-	const maxUnavailable = 1
-	numUnavailable := numSpecified - numAvailable
+	numUnavailable := make(map[string]int)
+	for key, specified := range budgetSpecified {
+		numUnavailable[key] = specified - budgetAvailable[key]
+	}
 
 	// When multiple instances need to redeploy, sort them so the lowest
-	// priority instances are first.
+	// priority instances are first. The primary is the highest priority, so
+	// move it to the front when it should go first.
 	if len(consider) > 1 {
 		sort.Sort(byPriority(consider))
+
+		last := consider[len(consider)-1]
+		if primary, known := last.IsPrimary(); known && primary &&
+			strategy.Order == v1beta1.RollingUpdatePrimaryFirst {
+			copy(consider[1:], consider[:len(consider)-1])
+			consider[0] = last
+		}
 	}
 
+	// Hold back instances that are available while any replica is too far
+	// behind the primary.
+	caughtUp := strategy.MaxLagBytes == nil ||
+		replicasCaughtUp(instances.forCluster, *strategy.MaxLagBytes)
+
 	span.SetAttributes(
 		attribute.Int("instances", len(instances.forCluster)),
 		attribute.Int("specified", numSpecified),
 		attribute.Int("available", numAvailable),
 		attribute.Int("considering", len(consider)),
+		attribute.Bool("caught-up", caughtUp),
 	)
 
+	// The primary is redeployed alone within its instance set, even when
+	// MaxUnavailable allows more, so that a failover always has somewhere to go.
+	redeployed := make(map[string]int)
+	primaryRedeployed := make(map[string]bool)
+	alone := func(instance *Instance) bool {
+		set := instance.Spec.Name
+		if primary, known := instance.IsPrimary(); known && primary {
+			return redeployed[set] == 0
+		}
+		return !primaryRedeployed[set]
+	}
+	redeployAlone := func(ctx context.Context, instance *Instance) error {
+		set := instance.Spec.Name
+		if primary, known := instance.IsPrimary(); known && primary {
+			primaryRedeployed[set] = true
+		}
+		redeployed[set]++
+		return redeploy(ctx, instance)
+	}
+
 	// Redeploy instances up to the allowed maximum while "rolling over" any
 	// unavailable instances.
 	// - https://issue.k8s.io/67250
 	for _, instance := range consider {
-		if err == nil {
+		if err == nil && alone(instance) {
+			key := budget(instance.Spec.Name)
+
 			if available, known := instance.IsAvailable(); known && !available {
-				err = redeploy(ctx, instance)
-			} else if numUnavailable < maxUnavailable {
-				err = redeploy(ctx, instance)
-				numUnavailable++
+				err = redeployAlone(ctx, instance)
+			} else if caughtUp && numUnavailable[key] < maxUnavailable {
+				err = redeployAlone(ctx, instance)
+				numUnavailable[key]++
 			}
 		}
 	}
@@ -868,6 +966,39 @@ func (r *Reconciler) rolloutInstances(
 	return err
 }
 
+// replicasCaughtUp returns whether or not every available replica in instances
+// is within maxLag bytes of WAL behind the primary, according to Patroni. It
+// returns false when the primary or the position of a replica is not known.
+func replicasCaughtUp(instances []*Instance, maxLag int64) bool {
+	var primary *Instance
+	for _, instance := range instances {
+		if isPrimary, known := instance.IsPrimary(); known && isPrimary {
+			primary = instance
+		}
+	}
+	if primary == nil {
+		return false
+	}
+
+	primaryPosition, known := primary.WALPosition()
+	if !known {
+		return false
+	}
+
+	for _, instance := range instances {
+		if instance == primary {
+			continue
+		}
+		if available, known := instance.IsAvailable(); !known || !available {
+			continue
+		}
+		if position, known := instance.WALPosition(); !known || primaryPosition-position > maxLag {
+			return false
+		}
+	}
+	return true
+}
+
 // scaleDownInstances removes extra instances from a cluster until it matches
 // the spec. This function can delete the primary instance and force the
 // cluster to failover under two conditions:
@@ -1071,6 +1202,7 @@ func (r *Reconciler) reconcileInstance(
 		instanceCertificates *corev1.Secret
 		postgresDataVolume   *corev1.PersistentVolumeClaim
 		postgresWALVolume    *corev1.PersistentVolumeClaim
+		postgresTempVolume   *corev1.PersistentVolumeClaim
 		tablespaceVolumes    []*corev1.PersistentVolumeClaim
 	)
 
@@ -1087,6 +1219,9 @@ func (r *Reconciler) reconcileInstance(
 	if err == nil {
 		postgresWALVolume, err = r.reconcilePostgresWALVolume(ctx, cluster, spec, instance, observed, clusterVolumes)
 	}
+	if err == nil {
+		postgresTempVolume, err = r.reconcilePostgresTempVolume(ctx, cluster, spec, instance, clusterVolumes)
+	}
 	if err == nil {
 		tablespaceVolumes, err = r.reconcileTablespaceVolumes(ctx, cluster, spec, instance, clusterVolumes)
 	}
@@ -1097,6 +1232,8 @@ func (r *Reconciler) reconcileInstance(
 			postgresDataVolume, postgresWALVolume, tablespaceVolumes,
 			&instance.Spec.Template.Spec)
 
+		postgres.InstanceTempVolume(cluster, postgresTempVolume, &instance.Spec.Template.Spec)
+
 		addPGBackRestToInstancePodSpec(
 			cluster, instanceCertificates, &instance.Spec.Template.Spec)
 
@@ -1104,6 +1241,10 @@ func (r *Reconciler) reconcileInstance(
 			ctx, cluster, clusterConfigMap, clusterPodService, patroniLeaderService,
 			spec, instanceCertificates, instanceConfigMap, &instance.Spec.Template)
 	}
+	if err == nil {
+		addAgentToInstancePodSpec(cluster, &instance.Spec.Template.Spec)
+		addCoreDumpsToInstancePodSpec(cluster, &instance.Spec.Template.Spec)
+	}
 
 	// Add pgMonitor resources to the instance Pod spec
 	if err == nil {
@@ -1119,6 +1260,13 @@ func (r *Reconciler) reconcileInstance(
 			&instance.Spec.Template)
 
 	}
+	// apply the image and resources of init containers from the spec, if any
+	if err == nil && spec.InitContainers != nil {
+		setInitContainer(&instance.Spec.Template.Spec,
+			naming.ContainerPostgresStartup, spec.InitContainers.Startup)
+		setInitContainer(&instance.Spec.Template.Spec,
+			naming.ContainerNSSWrapperInit, spec.InitContainers.NSSWrapper)
+	}
 	// add an emptyDir volume to the PodTemplateSpec and an associated '/tmp' volume mount to
 	// all containers included within that spec
 	if err == nil {
@@ -1170,6 +1318,7 @@ func generateInstanceStatefulSetIntent(_ context.Context,
 	sts.Spec.Template.Annotations = naming.Merge(
 		cluster.Spec.Metadata.GetAnnotationsOrNil(),
 		spec.Metadata.GetAnnotationsOrNil(),
+		servicemesh.PodAnnotations(cluster),
 	)
 	sts.Spec.Template.Labels = naming.Merge(
 		cluster.Spec.Metadata.GetLabelsOrNil(),
@@ -1366,7 +1515,7 @@ func (r *Reconciler) reconcileInstanceCertificates(
 	}
 	if err == nil {
 		err = patroni.InstanceCertificates(ctx,
-			root.Certificate, leafCert.Certificate,
+			root.Trusted(), leafCert.Certificate,
 			leafCert.PrivateKey, instanceCerts)
 	}
 	if err == nil {
@@ -17,6 +17,7 @@ package postgrescluster
 
 import (
 	"context"
+	"fmt"
 	"io"
 	"strings"
 	"testing"
@@ -171,6 +172,38 @@ func TestReconcilerRolloutInstance(t *testing.T) {
 			err := reconciler.rolloutInstance(ctx, cluster, observed, instances[0])
 			assert.ErrorContains(t, err, "switchover")
 		})
+
+		t.Run("NoSwitchover", func(t *testing.T) {
+			cluster := cluster.DeepCopy()
+			cluster.Spec.UpdateStrategy = &v1beta1.UpdateStrategySpec{
+				RollingUpdate: &v1beta1.RollingUpdateSpec{
+					SwitchoverPrimary: initialize.Bool(false),
+				},
+			}
+
+			primary := *instances[0]
+			primary.Pods = []*corev1.Pod{instances[0].Pods[0].DeepCopy()}
+
+			key := client.ObjectKeyFromObject(primary.Pods[0])
+			reconciler := &Reconciler{}
+			reconciler.Client = fake.NewClientBuilder().WithObjects(primary.Pods[0]).Build()
+			reconciler.Tracer = otel.Tracer(t.Name())
+			reconciler.PodExec = func(
+				_, _, _ string, stdin io.Reader, _, _ io.Writer, command ...string,
+			) error {
+				// Checkpoint rather than switchover.
+				b, _ := io.ReadAll(stdin)
+				assert.Assert(t, cmp.Contains(string(b), "CHECKPOINT"))
+				assert.Assert(t, command[0] != "patronictl")
+				return nil
+			}
+
+			assert.NilError(t, reconciler.rolloutInstance(ctx, cluster, observed, &primary))
+
+			err := reconciler.Client.Get(ctx, key, &corev1.Pod{})
+			assert.Assert(t, apierrors.IsNotFound(err),
+				"expected pod to be deleted, got: %#v", err)
+		})
 	})
 }
 
@@ -651,4 +684,112 @@ func TestReconcilerRolloutInstances(t *testing.T) {
 				return nil
 			}))
 	})
+
+	// Outdated instances in two sets, rolled out according to the strategy.
+	t.Run("UpdateStrategy", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+			{Name: "00", Replicas: initialize.Int32(2)},
+			{Name: "01", Replicas: initialize.Int32(2)},
+		}
+
+		outdated := func(name string, set int, role string, position int) *Instance {
+			return &Instance{
+				Name: name,
+				Spec: &cluster.Spec.InstanceSets[set],
+				Pods: []*corev1.Pod{{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{
+							"status": fmt.Sprintf(`{"role":%q,"xlog_location":%d}`, role, position),
+						},
+						Labels: map[string]string{
+							"controller-revision-hash":               "beta",
+							"postgres-operator.crunchydata.com/role": role,
+						},
+					},
+					Status: corev1.PodStatus{
+						Conditions: []corev1.PodCondition{{
+							Type:   corev1.PodReady,
+							Status: corev1.ConditionTrue,
+						}},
+					},
+				}},
+				Runner: &appsv1.StatefulSet{
+					ObjectMeta: metav1.ObjectMeta{
+						Generation: 1,
+					},
+					Status: appsv1.StatefulSetStatus{
+						ObservedGeneration: 1,
+						UpdateRevision:     "gamma",
+					},
+				},
+			}
+		}
+		observed := &observedInstances{forCluster: []*Instance{
+			outdated("a", 0, "master", 1000),
+			outdated("b", 0, "replica", 1000),
+			outdated("c", 1, "replica", 900),
+			outdated("d", 1, "replica", 1000),
+		}}
+
+		names := func(instances []*Instance) []string {
+			var out []string
+			for _, instance := range instances {
+				out = append(out, instance.Name)
+			}
+			return out
+		}
+
+		for _, tt := range []struct {
+			name     string
+			strategy v1beta1.RollingUpdateSpec
+			expected []string
+		}{
+			{name: "Default", expected: []string{"b"}},
+			{
+				name:     "PrimaryFirst",
+				strategy: v1beta1.RollingUpdateSpec{Order: "PrimaryFirst"},
+				expected: []string{"a"},
+			},
+			{
+				name:     "MaxUnavailable",
+				strategy: v1beta1.RollingUpdateSpec{MaxUnavailable: initialize.Int32(1)},
+				expected: []string{"b", "c"},
+			},
+			{
+				// The primary never goes with other members of its set.
+				name:     "MaxUnavailableAboveOne",
+				strategy: v1beta1.RollingUpdateSpec{MaxUnavailable: initialize.Int32(2)},
+				expected: []string{"b", "c", "d"},
+			},
+			{
+				name: "MaxUnavailablePrimaryFirst",
+				strategy: v1beta1.RollingUpdateSpec{
+					MaxUnavailable: initialize.Int32(2), Order: "PrimaryFirst",
+				},
+				expected: []string{"a", "c", "d"},
+			},
+			{
+				name:     "Lagging",
+				strategy: v1beta1.RollingUpdateSpec{MaxLagBytes: initialize.Pointer(int64(50))},
+			},
+			{
+				name:     "CaughtUp",
+				strategy: v1beta1.RollingUpdateSpec{MaxLagBytes: initialize.Pointer(int64(100))},
+				expected: []string{"b"},
+			},
+		} {
+			t.Run(tt.name, func(t *testing.T) {
+				cluster.Spec.UpdateStrategy = &v1beta1.UpdateStrategySpec{
+					RollingUpdate: &tt.strategy,
+				}
+
+				var redeploys []*Instance
+
+				logSpanAttributes(t)
+				assert.NilError(t, reconciler.rolloutInstances(ctx, cluster, observed, accumulate(&redeploys)))
+				assert.DeepEqual(t, names(redeploys), tt.expected)
+			})
+		}
+	})
 }
@@ -0,0 +1,141 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/lifecycle"
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// callLifecycleHooks calls r.LifecycleHooks about event for cluster. A failure
+// is recorded as a warning event and returned so that it is tried again.
+func (r *Reconciler) callLifecycleHooks(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	event lifecycle.Event, primary, previous string,
+) error {
+	err := r.LifecycleHooks.Call(ctx, lifecycle.Request{
+		Event:           event,
+		Namespace:       cluster.Namespace,
+		Name:            cluster.Name,
+		UID:             cluster.UID,
+		Primary:         primary,
+		PreviousPrimary: previous,
+		Time:            time.Now().UTC(),
+	})
+
+	if err != nil {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "LifecycleHookFailed",
+			"%s hook failed: %v", event, err)
+	} else {
+		logging.FromContext(ctx).V(1).Info("called lifecycle hooks", "event", event)
+	}
+	return err
+}
+
+// reconcilePreProvisionHooks calls the PreProvision hooks of cluster. It is
+// called before the finalizer is added to a new cluster, so the finalizer
+// records that the hooks succeeded.
+func (r *Reconciler) reconcilePreProvisionHooks(ctx context.Context, cluster *v1beta1.PostgresCluster) error {
+	if r.LifecycleHooks == nil {
+		return nil
+	}
+	return r.callLifecycleHooks(ctx, cluster, lifecycle.PreProvision, "", "")
+}
+
+// reconcilePreDeleteHooks calls the PreDelete hooks of cluster once. Deletion
+// takes more than one reconcile, so this records their success in the status
+// of cluster right away.
+func (r *Reconciler) reconcilePreDeleteHooks(ctx context.Context, cluster *v1beta1.PostgresCluster) error {
+	if r.LifecycleHooks == nil || lifecycleHookCalled(cluster, lifecycle.PreDelete) {
+		return nil
+	}
+
+	before := cluster.DeepCopy()
+	err := r.callLifecycleHooks(ctx, cluster, lifecycle.PreDelete, "", "")
+	if err == nil {
+		setLifecycleHookCalled(cluster, lifecycle.PreDelete)
+		err = errors.WithStack(r.Client.Status().Patch(
+			ctx, cluster, client.MergeFrom(before), r.Owner))
+	}
+	return err
+}
+
+// reconcileLifecycleHooks calls the PostReady hooks of cluster once its primary
+// first runs, and the PostFailover hooks each time a different instance
+// becomes the primary after that.
+func (r *Reconciler) reconcileLifecycleHooks(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) error {
+	if r.LifecycleHooks == nil {
+		return nil
+	}
+
+	_, instance := instances.writablePod(naming.ContainerDatabase)
+	if instance == nil || cluster.Status.Patroni.SystemIdentifier == "" {
+		return nil
+	}
+
+	var previous string
+	if cluster.Status.LifecycleHooks != nil {
+		previous = cluster.Status.LifecycleHooks.Primary
+	}
+
+	var err error
+	switch {
+	case !lifecycleHookCalled(cluster, lifecycle.PostReady):
+		err = r.callLifecycleHooks(ctx, cluster, lifecycle.PostReady, instance.Name, "")
+		if err == nil {
+			setLifecycleHookCalled(cluster, lifecycle.PostReady)
+		}
+	case previous != instance.Name:
+		err = r.callLifecycleHooks(ctx, cluster, lifecycle.PostFailover, instance.Name, previous)
+	}
+	if err == nil {
+		cluster.Status.LifecycleHooks.Primary = instance.Name
+	}
+	return err
+}
+
+// lifecycleHookCalled returns whether or not the hooks of event have succeeded
+// for cluster.
+func lifecycleHookCalled(cluster *v1beta1.PostgresCluster, event lifecycle.Event) bool {
+	if cluster.Status.LifecycleHooks != nil {
+		for _, called := range cluster.Status.LifecycleHooks.Called {
+			if called == string(event) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// setLifecycleHookCalled records that the hooks of event succeeded for cluster.
+func setLifecycleHookCalled(cluster *v1beta1.PostgresCluster, event lifecycle.Event) {
+	if cluster.Status.LifecycleHooks == nil {
+		cluster.Status.LifecycleHooks = &v1beta1.LifecycleHooksStatus{}
+	}
+	if !lifecycleHookCalled(cluster, event) {
+		cluster.Status.LifecycleHooks.Called = append(cluster.Status.LifecycleHooks.Called, string(event))
+	}
+}
@@ -0,0 +1,113 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/crunchydata/postgres-operator/internal/lifecycle"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestReconcileLifecycleHooks(t *testing.T) {
+	ctx := context.Background()
+
+	primary := func(name string) *observedInstances {
+		pod := &corev1.Pod{}
+		pod.Namespace, pod.Name = "ns1", name+"-0"
+		pod.Labels = map[string]string{naming.LabelRole: naming.RolePatroniLeader}
+		pod.Annotations = map[string]string{"status": `{"role":"master"}`}
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name:  naming.ContainerDatabase,
+			State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+		}}
+		return &observedInstances{forCluster: []*Instance{
+			{Name: name, Pods: []*corev1.Pod{pod}},
+		}}
+	}
+
+	var requests []lifecycle.Request
+	var failure error
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Recorder: recorder,
+		LifecycleHooks: lifecycle.HookFunc(func(_ context.Context, r lifecycle.Request) error {
+			requests = append(requests, r)
+			return failure
+		}),
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+
+	t.Run("Disabled", func(t *testing.T) {
+		reconciler := &Reconciler{}
+		cluster := cluster.DeepCopy()
+		cluster.Status.Patroni.SystemIdentifier = "123"
+
+		assert.NilError(t, reconciler.reconcileLifecycleHooks(ctx, cluster, primary("hippo-00-abcd")))
+		assert.Assert(t, cluster.Status.LifecycleHooks == nil)
+	})
+
+	t.Run("NotInitialized", func(t *testing.T) {
+		assert.NilError(t, reconciler.reconcileLifecycleHooks(ctx, cluster, primary("hippo-00-abcd")))
+		assert.Equal(t, len(requests), 0)
+	})
+
+	cluster.Status.Patroni.SystemIdentifier = "123"
+
+	t.Run("PostReady", func(t *testing.T) {
+		requests = nil
+		failure = errors.New("boom")
+		assert.ErrorContains(t, reconciler.reconcileLifecycleHooks(ctx, cluster, primary("hippo-00-abcd")), "boom")
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "PostReady hook failed"))
+		assert.Assert(t, !lifecycleHookCalled(cluster, lifecycle.PostReady))
+
+		failure = nil
+		assert.NilError(t, reconciler.reconcileLifecycleHooks(ctx, cluster, primary("hippo-00-abcd")))
+		assert.Assert(t, lifecycleHookCalled(cluster, lifecycle.PostReady))
+		assert.Equal(t, cluster.Status.LifecycleHooks.Primary, "hippo-00-abcd")
+		assert.Equal(t, requests[1].Event, lifecycle.PostReady)
+		assert.Equal(t, requests[1].Primary, "hippo-00-abcd")
+
+		// Nothing is called while the primary stays the same.
+		requests = nil
+		assert.NilError(t, reconciler.reconcileLifecycleHooks(ctx, cluster, primary("hippo-00-abcd")))
+		assert.Equal(t, len(requests), 0)
+	})
+
+	t.Run("PostFailover", func(t *testing.T) {
+		requests = nil
+		assert.NilError(t, reconciler.reconcileLifecycleHooks(ctx, cluster, primary("hippo-00-efgh")))
+		assert.Equal(t, len(requests), 1)
+		assert.Equal(t, requests[0].Event, lifecycle.PostFailover)
+		assert.Equal(t, requests[0].Primary, "hippo-00-efgh")
+		assert.Equal(t, requests[0].PreviousPrimary, "hippo-00-abcd")
+		assert.Equal(t, cluster.Status.LifecycleHooks.Primary, "hippo-00-efgh")
+
+		// Nothing is called while there is no primary.
+		requests = nil
+		assert.NilError(t, reconciler.reconcileLifecycleHooks(ctx, cluster, &observedInstances{}))
+		assert.Equal(t, len(requests), 0)
+	})
+}
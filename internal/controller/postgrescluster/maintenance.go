@@ -0,0 +1,174 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/maintenance"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// maintenanceJob reports the outcome of rewriting bloated tables in the
+// MaintenanceCompleted condition.
+var maintenanceJob = scheduledJob{
+	name:      "maintenance",
+	label:     naming.LabelMaintenance,
+	condition: v1beta1.MaintenanceCompleted,
+
+	succeededReason:  "MaintenanceSucceeded",
+	succeededMessage: "Bloated tables were rewritten or skipped",
+
+	failedReason:  "MaintenanceFailed",
+	failedMessage: "Maintenance failed or did not finish within the window; see the logs of Job %q",
+}
+
+// +kubebuilder:rbac:groups="batch",resources="cronjobs",verbs={get,create,patch,delete}
+// +kubebuilder:rbac:groups="batch",resources="jobs",verbs={list}
+// +kubebuilder:rbac:groups="",resources="pods",verbs={list}
+
+// reconcileMaintenance allows the maintenance user to estimate bloat and
+// rewrite tables, then schedules a CronJob that rewrites bloated tables during
+// the maintenance window. The progress of the latest Job is reported in status
+// and its outcome in the MaintenanceCompleted condition.
+func (r *Reconciler) reconcileMaintenance(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+	secret *corev1.Secret,
+) error {
+	enabled := maintenance.Enabled(cluster)
+
+	// Change PostgreSQL only when something was installed or is requested.
+	status := cluster.Status.Maintenance
+	if status != nil || enabled {
+		if status == nil {
+			status = new(v1beta1.MaintenanceStatus)
+		}
+
+		action := func(ctx context.Context, exec postgres.Executor) error {
+			if enabled {
+				return maintenance.EnableInPostgreSQL(ctx, exec, cluster)
+			}
+			return maintenance.DisableInPostgreSQL(ctx, exec)
+		}
+
+		current, err := r.reconcileSQLRevision(ctx, instances, &status.Revision, action)
+		if err != nil {
+			return err
+		}
+
+		cluster.Status.Maintenance = status
+		if current && !enabled {
+			cluster.Status.Maintenance = nil
+		}
+	}
+
+	// Wait for the privileges to be granted before scheduling any Jobs.
+	cronjob := &batchv1.CronJob{ObjectMeta: naming.MaintenanceCronJob(cluster)}
+	finished, err := r.reconcileScheduledJob(ctx, cluster, maintenanceJob, cronjob,
+		enabled, status != nil && status.Revision != "",
+		func(cronjob *batchv1.CronJob) { generateMaintenanceCronJob(cluster, secret, cronjob) })
+
+	if err != nil || finished == nil {
+		return err
+	}
+
+	previous := status.LastMaintenanceTime
+	if previous != nil && !previous.Before(finished) {
+		return nil
+	}
+	status.LastMaintenanceTime = finished
+
+	// Report the progress that the latest Pod wrote to its termination message.
+	pods := &corev1.PodList{}
+	err = errors.WithStack(r.Client.List(ctx, pods,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabels{
+			naming.LabelCluster:     cluster.Name,
+			naming.LabelMaintenance: "",
+		}))
+	if err == nil {
+		if progress := maintenanceProgress(pods.Items); progress != nil {
+			status.BloatedTables = progress.BloatedTables
+			status.RewrittenTables = progress.RewrittenTables
+		}
+	}
+
+	// Tables and owners that are created after the privileges are granted are
+	// included each time a maintenance Job finishes.
+	if err == nil {
+		if pod, _ := instances.writablePod(naming.ContainerDatabase); pod != nil {
+			err = maintenance.EnableInPostgreSQL(ctx, r.databaseExecutor(pod), cluster)
+		}
+	}
+	return err
+}
+
+// maintenanceProgress returns the progress that the most recently terminated
+// of pods wrote to its termination message, if any.
+func maintenanceProgress(pods []corev1.Pod) *maintenance.Progress {
+	var latest *corev1.ContainerStateTerminated
+	for i := range pods {
+		for _, status := range pods[i].Status.ContainerStatuses {
+			if status.Name != naming.ContainerJobMaintenance {
+				continue
+			}
+			if terminated := status.State.Terminated; terminated != nil &&
+				(latest == nil || latest.FinishedAt.Before(&terminated.FinishedAt)) {
+				latest = terminated
+			}
+		}
+	}
+
+	var progress maintenance.Progress
+	if latest == nil || json.Unmarshal([]byte(latest.Message), &progress) != nil {
+		return nil
+	}
+	return &progress
+}
+
+// generateMaintenanceCronJob populates cronjob with a schedule that rewrites
+// the bloated tables of cluster through the primary.
+func generateMaintenanceCronJob(
+	cluster *v1beta1.PostgresCluster, secret *corev1.Secret,
+	cronjob *batchv1.CronJob,
+) {
+	spec := cluster.Spec.Maintenance
+
+	// Suspend the CronJob when shutdown or a standby.
+	suspend := (cluster.Spec.Shutdown != nil && *cluster.Spec.Shutdown) ||
+		(cluster.Spec.Standby != nil && cluster.Spec.Standby.Enabled)
+
+	template := generateScheduledJob(cluster, maintenanceJob, cronjob,
+		spec.Window.Schedule, suspend, corev1.Container{
+			Command:   maintenance.Command(cluster),
+			Name:      naming.ContainerJobMaintenance,
+			Resources: spec.Resources,
+		})
+
+	withMaintenanceUser(cluster, secret, naming.ClusterPrimaryService(cluster), template)
+
+	// Stop rewriting tables at the end of the window.
+	cronjob.Spec.JobTemplate.Spec.ActiveDeadlineSeconds = spec.Window.DurationSeconds
+	cronjob.Spec.JobTemplate.Spec.TTLSecondsAfterFinished = spec.TTLSecondsAfterFinished
+}
@@ -0,0 +1,104 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestGenerateMaintenanceCronJob(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Name = "hippo"
+	cluster.Namespace = "ns1"
+	cluster.Spec.Port = initialize.Int32(5432)
+	cluster.Spec.Maintenance = &v1beta1.MaintenanceSpec{
+		Window: v1beta1.MaintenanceWindow{
+			Schedule:        "0 3 * * 6",
+			DurationSeconds: initialize.Int64(7200),
+		},
+	}
+	secret := &corev1.Secret{ObjectMeta: naming.MaintenanceSecret(cluster)}
+
+	cronjob := &batchv1.CronJob{ObjectMeta: naming.MaintenanceCronJob(cluster)}
+	generateMaintenanceCronJob(cluster, secret, cronjob)
+
+	assert.Equal(t, cronjob.Spec.Schedule, "0 3 * * 6")
+	assert.Equal(t, cronjob.Spec.ConcurrencyPolicy, batchv1.ForbidConcurrent)
+	assert.Equal(t, *cronjob.Spec.Suspend, false)
+	assert.Equal(t, *cronjob.Spec.JobTemplate.Spec.ActiveDeadlineSeconds, int64(7200))
+	assert.DeepEqual(t, cronjob.Labels, map[string]string{
+		naming.LabelCluster:     "hippo",
+		naming.LabelMaintenance: "",
+	})
+
+	pod := cronjob.Spec.JobTemplate.Spec.Template.Spec
+	assert.Equal(t, pod.Volumes[0].Secret.SecretName, "hippo-maintenance-cert")
+
+	container := pod.Containers[0]
+	assert.Equal(t, container.Name, "maintenance")
+	assert.Equal(t, container.Env[0].Value, "hippo-primary.ns1.svc")
+	assert.Equal(t, container.Env[3].Value, "_crunchymaintenance")
+
+	t.Run("Standby", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Standby = &v1beta1.PostgresStandbySpec{Enabled: true}
+
+		cronjob := &batchv1.CronJob{ObjectMeta: naming.MaintenanceCronJob(cluster)}
+		generateMaintenanceCronJob(cluster, secret, cronjob)
+		assert.Equal(t, *cronjob.Spec.Suspend, true)
+	})
+}
+
+func TestMaintenanceProgress(t *testing.T) {
+	assert.Assert(t, maintenanceProgress(nil) == nil)
+
+	now := time.Now()
+	pod := func(finished time.Time, message string) corev1.Pod {
+		var pod corev1.Pod
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+			Name: naming.ContainerJobMaintenance,
+			State: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{
+					FinishedAt: metav1.NewTime(finished), Message: message,
+				},
+			},
+		}}
+		return pod
+	}
+
+	// The most recently finished Pod wins.
+	progress := maintenanceProgress([]corev1.Pod{
+		pod(now.Add(-time.Hour), `{"bloatedTables":9,"rewrittenTables":9}`),
+		pod(now, `{"bloatedTables":5,"rewrittenTables":2}`),
+		{}, // still running
+	})
+	assert.Assert(t, progress != nil)
+	assert.Equal(t, progress.BloatedTables, int32(5))
+	assert.Equal(t, progress.RewrittenTables, int32(2))
+
+	// A Pod that stopped before writing any progress has none.
+	assert.Assert(t, maintenanceProgress([]corev1.Pod{pod(now, "")}) == nil)
+}
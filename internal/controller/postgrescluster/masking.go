@@ -0,0 +1,142 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// dataMaskingPending returns whether or not cluster has masking scripts that
+// have not yet succeeded.
+func dataMaskingPending(cluster *v1beta1.PostgresCluster) bool {
+	return cluster.Spec.DataSource != nil &&
+		cluster.Spec.DataSource.Masking != nil &&
+		!meta.IsStatusConditionTrue(cluster.Status.Conditions, v1beta1.DataMasked)
+}
+
+// dataMaskingHBAs rejects network connections from any user that is not
+// already allowed by a mandatory rule while masking scripts are pending. This
+// keeps applications away from unmasked data.
+func dataMaskingHBAs(cluster *v1beta1.PostgresCluster, outHBAs *postgres.HBAs) {
+	if dataMaskingPending(cluster) {
+		outHBAs.Mandatory = append(outHBAs.Mandatory,
+			*postgres.NewHBA().TCP().Method("reject"))
+	}
+}
+
+// reconcileDataMasking executes the masking scripts of cluster, in order,
+// using the writable instance. The outcome is reported in the DataMasked
+// condition; the scripts do not run again once it is true.
+func (r *Reconciler) reconcileDataMasking(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) error {
+	if cluster.Spec.DataSource == nil || cluster.Spec.DataSource.Masking == nil {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.DataMasked)
+		return nil
+	}
+	if !dataMaskingPending(cluster) {
+		return nil
+	}
+
+	pod, _ := instances.writablePod(naming.ContainerDatabase)
+	if pod == nil {
+		return nil
+	}
+
+	exec := func(_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string) error {
+		return r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase, stdin, stdout, stderr, command...)
+	}
+
+	condition := metav1.Condition{
+		Type:               v1beta1.DataMasked,
+		ObservedGeneration: cluster.GetGeneration(),
+	}
+
+	var err error
+	for i, script := range cluster.Spec.DataSource.Masking.Scripts {
+		log := logging.FromContext(ctx).WithValues("name", script.Name, "key", script.Key)
+
+		var sql string
+		sql, err = r.dataMaskingScript(ctx, cluster, script)
+		if err != nil {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "ScriptNotFound"
+			condition.Message = fmt.Sprintf("Script %d: %v", i, err)
+			break
+		}
+
+		var stdout, stderr string
+		stdout, stderr, err = postgres.Executor(exec).Exec(ctx, strings.NewReader(sql),
+			map[string]string{
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			})
+		log.V(1).Info("applied masking SQL", "stdout", stdout, "stderr", stderr)
+
+		if err != nil {
+			condition.Status = metav1.ConditionFalse
+			condition.Reason = "ScriptFailed"
+			condition.Message = fmt.Sprintf(
+				"Script %d from ConfigMap %q, key %q failed: %s",
+				i, script.Name, script.Key, strings.TrimSpace(stderr))
+			err = errors.WithStack(err)
+			break
+		}
+	}
+
+	if err == nil {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ScriptsSucceeded"
+		condition.Message = "Every masking script succeeded"
+
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, "DataMasked", condition.Message)
+	} else {
+		r.Recorder.Event(cluster, corev1.EventTypeWarning, "DataMaskingFailed", condition.Message)
+	}
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+	return err
+}
+
+// dataMaskingScript returns the SQL of script from its ConfigMap.
+func (r *Reconciler) dataMaskingScript(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, script v1beta1.DatabaseInitSQL,
+) (string, error) {
+	cm := &corev1.ConfigMap{}
+	cm.Namespace, cm.Name = cluster.Namespace, script.Name
+
+	err := errors.WithStack(r.Client.Get(ctx, client.ObjectKeyFromObject(cm), cm))
+	if err == nil {
+		if _, ok := cm.Data[script.Key]; !ok {
+			err = errors.Errorf("ConfigMap %q did not contain expected key: %s",
+				script.Name, script.Key)
+		}
+	}
+	return cm.Data[script.Key], err
+}
@@ -0,0 +1,158 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestDataMaskingHBAs(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	mandatory := func(cluster *v1beta1.PostgresCluster) []postgres.HostBasedAuthentication {
+		hbas := postgres.HBAs{}
+		dataMaskingHBAs(cluster, &hbas)
+		return hbas.Mandatory
+	}
+
+	assert.Equal(t, len(mandatory(cluster)), 0)
+
+	cluster.Spec.DataSource = &v1beta1.DataSource{
+		Masking: &v1beta1.DataMaskingSpec{},
+	}
+	hbas := mandatory(cluster)
+	assert.Equal(t, len(hbas), 1)
+	assert.Equal(t, hbas[0].String(), `host all all all reject`)
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+		Type: v1beta1.DataMasked, Status: metav1.ConditionTrue, Reason: "ScriptsSucceeded",
+	})
+	assert.Equal(t, len(mandatory(cluster)), 0)
+}
+
+func TestReconcileDataMasking(t *testing.T) {
+	ctx := context.Background()
+
+	pod := &corev1.Pod{}
+	pod.Namespace, pod.Name = "ns1", "hippo-00-abcd-0"
+	pod.Annotations = map[string]string{"status": `{"role":"master"}`}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  naming.ContainerDatabase,
+		State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+	}}
+
+	instances := &observedInstances{forCluster: []*Instance{
+		{Name: "hippo-00-abcd", Pods: []*corev1.Pod{pod}},
+	}}
+
+	configmap := &corev1.ConfigMap{}
+	configmap.Namespace, configmap.Name = "ns1", "masks"
+	configmap.Data = map[string]string{
+		"first":  "SELECT anon.anonymize_database();",
+		"second": "UPDATE people SET email = NULL;",
+	}
+
+	var executed []string
+	var failure error
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Client:   fake.NewClientBuilder().WithObjects(configmap).Build(),
+		Recorder: recorder,
+		PodExec: func(namespace, pod, container string, stdin io.Reader, _, _ io.Writer, command ...string) error {
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, strings.Contains(strings.Join(command, " "), "--set=ON_ERROR_STOP=on"))
+			executed = append(executed, string(b))
+			return failure
+		},
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace = "ns1"
+
+	t.Run("NotRequested", func(t *testing.T) {
+		assert.NilError(t, reconciler.reconcileDataMasking(ctx, cluster, instances))
+		assert.Equal(t, len(executed), 0)
+		assert.Equal(t, len(cluster.Status.Conditions), 0)
+	})
+
+	cluster.Spec.DataSource = &v1beta1.DataSource{
+		Masking: &v1beta1.DataMaskingSpec{
+			Scripts: []v1beta1.DatabaseInitSQL{
+				{Name: "masks", Key: "first"},
+				{Name: "masks", Key: "second"},
+			},
+		},
+	}
+
+	t.Run("Failed", func(t *testing.T) {
+		executed, failure = nil, errors.New("boom")
+		cluster := cluster.DeepCopy()
+
+		assert.ErrorContains(t, reconciler.reconcileDataMasking(ctx, cluster, instances), "boom")
+		assert.DeepEqual(t, executed, []string{"SELECT anon.anonymize_database();"})
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.DataMasked)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "ScriptFailed")
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "DataMaskingFailed"))
+	})
+
+	t.Run("MissingKey", func(t *testing.T) {
+		executed, failure = nil, nil
+		cluster := cluster.DeepCopy()
+		cluster.Spec.DataSource.Masking.Scripts[1].Key = "missing"
+
+		assert.ErrorContains(t, reconciler.reconcileDataMasking(ctx, cluster, instances), "missing")
+		assert.Equal(t, len(executed), 1)
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.DataMasked)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Reason, "ScriptNotFound")
+		<-recorder.Events
+	})
+
+	t.Run("Succeeded", func(t *testing.T) {
+		executed, failure = nil, nil
+
+		assert.NilError(t, reconciler.reconcileDataMasking(ctx, cluster, instances))
+		assert.DeepEqual(t, executed, []string{
+			"SELECT anon.anonymize_database();",
+			"UPDATE people SET email = NULL;",
+		})
+		assert.Assert(t, meta.IsStatusConditionTrue(cluster.Status.Conditions, v1beta1.DataMasked))
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "DataMasked"))
+
+		// The scripts do not run again.
+		assert.NilError(t, reconciler.reconcileDataMasking(ctx, cluster, instances))
+		assert.Equal(t, len(executed), 2)
+	})
+}
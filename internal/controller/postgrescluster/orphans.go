@@ -0,0 +1,136 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// retainVolume returns whether the volumes of instances in set should be kept
+// when those instances are deleted. Only sets that are no longer in the spec
+// of cluster are subject to its RemovedInstanceSetPolicy.
+func retainVolume(cluster *v1beta1.PostgresCluster, set string) bool {
+	if cluster.Spec.RemovedInstanceSetPolicy != v1beta1.RemovedInstanceSetRetain {
+		return false
+	}
+	for i := range cluster.Spec.InstanceSets {
+		if cluster.Spec.InstanceSets[i].Name == set {
+			return false
+		}
+	}
+	return true
+}
+
+// +kubebuilder:rbac:groups="",resources="configmaps",verbs={delete,list}
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={delete,list}
+// +kubebuilder:rbac:groups="",resources="persistentvolumeclaims",verbs={delete,list}
+// +kubebuilder:rbac:groups="apps",resources="statefulsets",verbs={delete,list}
+
+// cleanupRemovedInstanceSets deletes the StatefulSets, ConfigMaps, certificates,
+// and volumes of instances in sets that are no longer in the spec, such as
+// those that were renamed. Volumes are kept when the RemovedInstanceSetPolicy
+// is Retain. Kept volumes and resources not controlled by cluster are reported
+// in its status. Instances with Pods are left to scaleDownInstances.
+func (r *Reconciler) cleanupRemovedInstanceSets(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, observed *observedInstances,
+) error {
+	// Do nothing until the instance sets are known.
+	if len(cluster.Spec.InstanceSets) == 0 {
+		return nil
+	}
+
+	setNames := sets.NewString()
+	for _, set := range cluster.Spec.InstanceSets {
+		setNames.Insert(set.Name)
+	}
+
+	// Keep the instances that are still running and the one that should
+	// start first after a shutdown.
+	keep := sets.NewString(cluster.Status.StartupInstance)
+	for _, instance := range observed.forCluster {
+		if len(instance.Pods) > 0 {
+			keep.Insert(instance.Name)
+		}
+	}
+
+	gvks := []schema.GroupVersionKind{
+		corev1.SchemeGroupVersion.WithKind("ConfigMapList"),
+		corev1.SchemeGroupVersion.WithKind("SecretList"),
+		appsv1.SchemeGroupVersion.WithKind("StatefulSetList"),
+		corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaimList"),
+	}
+
+	var orphans []v1beta1.OrphanedResource
+	selector, err := naming.AsSelector(naming.ClusterInstances(cluster.Name))
+	for _, gvk := range gvks {
+		uList := &unstructured.UnstructuredList{}
+		uList.SetGroupVersionKind(gvk)
+
+		if err == nil {
+			err = errors.WithStack(
+				r.Client.List(ctx, uList,
+					client.InNamespace(cluster.Namespace),
+					client.MatchingLabelsSelector{Selector: selector},
+				))
+		}
+
+		for i := range uList.Items {
+			object := &uList.Items[i]
+			labels := object.GetLabels()
+
+			if err != nil || setNames.Has(labels[naming.LabelInstanceSet]) ||
+				keep.Has(labels[naming.LabelInstance]) {
+				continue
+			}
+
+			orphan := v1beta1.OrphanedResource{
+				Kind:        strings.TrimSuffix(gvk.Kind, "List"),
+				Name:        object.GetName(),
+				InstanceSet: labels[naming.LabelInstanceSet],
+			}
+			switch {
+			case !metav1.IsControlledBy(object, cluster):
+				orphan.Reason = "Uncontrolled"
+				orphans = append(orphans, orphan)
+
+			case orphan.Kind == "PersistentVolumeClaim" && retainVolume(cluster, orphan.InstanceSet):
+				orphan.Reason = "Retained"
+				orphans = append(orphans, orphan)
+
+			default:
+				err = errors.WithStack(client.IgnoreNotFound(
+					r.deleteControlled(ctx, cluster, object)))
+			}
+		}
+	}
+
+	if err == nil {
+		cluster.Status.OrphanedResources = orphans
+	}
+	return err
+}
@@ -0,0 +1,147 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestCleanupRemovedInstanceSets(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name, cluster.UID = "ns1", "hippo", "uid1"
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{Name: "new"}}
+
+	labels := func(set, instance string) map[string]string {
+		return map[string]string{
+			naming.LabelCluster:     "hippo",
+			naming.LabelInstanceSet: set,
+			naming.LabelInstance:    instance,
+		}
+	}
+	controlled := func(object client.Object) client.Object {
+		assert.NilError(t, controllerutil.SetControllerReference(cluster, object, scheme))
+		return object
+	}
+
+	objects := func() []client.Object {
+		current, removed, running, legacy :=
+			&appsv1.StatefulSet{}, &appsv1.StatefulSet{}, &appsv1.StatefulSet{}, &corev1.ConfigMap{}
+		current.Namespace, current.Name, current.Labels = "ns1", "hippo-new-abcd", labels("new", "hippo-new-abcd")
+		removed.Namespace, removed.Name, removed.Labels = "ns1", "hippo-old-wxyz", labels("old", "hippo-old-wxyz")
+		running.Namespace, running.Name, running.Labels = "ns1", "hippo-old-stop", labels("old", "hippo-old-stop")
+		legacy.Namespace, legacy.Name, legacy.Labels = "ns1", "hippo-old-config", labels("old", "hippo-old-qrst")
+
+		volume, certificates := &corev1.PersistentVolumeClaim{}, &corev1.Secret{}
+		volume.Namespace, volume.Name, volume.Labels = "ns1", "hippo-old-wxyz-pgdata", labels("old", "hippo-old-wxyz")
+		certificates.Namespace, certificates.Name, certificates.Labels = "ns1", "hippo-old-wxyz-certs", labels("old", "hippo-old-wxyz")
+
+		return []client.Object{
+			controlled(current), controlled(removed), controlled(running), legacy,
+			controlled(volume), controlled(certificates),
+		}
+	}
+
+	// The instance "hippo-old-stop" is still running.
+	observed := &observedInstances{forCluster: []*Instance{
+		{Name: "hippo-old-stop", Pods: []*corev1.Pod{{}}},
+	}}
+
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: "ns1", Name: name}
+	}
+	exists := func(c client.Client, object client.Object) bool {
+		err := c.Get(ctx, client.ObjectKeyFromObject(object), object)
+		assert.Assert(t, err == nil || apierrors.IsNotFound(err), "%v", err)
+		return err == nil
+	}
+
+	t.Run("Delete", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects()...).Build()
+		r := &Reconciler{Client: c}
+
+		assert.NilError(t, r.cleanupRemovedInstanceSets(ctx, cluster, observed))
+
+		assert.Assert(t, exists(c, &appsv1.StatefulSet{ObjectMeta: meta("hippo-new-abcd")}))
+		assert.Assert(t, exists(c, &appsv1.StatefulSet{ObjectMeta: meta("hippo-old-stop")}))
+		assert.Assert(t, !exists(c, &appsv1.StatefulSet{ObjectMeta: meta("hippo-old-wxyz")}))
+		assert.Assert(t, !exists(c, &corev1.Secret{ObjectMeta: meta("hippo-old-wxyz-certs")}))
+		assert.Assert(t, !exists(c, &corev1.PersistentVolumeClaim{ObjectMeta: meta("hippo-old-wxyz-pgdata")}))
+		assert.Assert(t, exists(c, &corev1.ConfigMap{ObjectMeta: meta("hippo-old-config")}))
+
+		assert.DeepEqual(t, cluster.Status.OrphanedResources, []v1beta1.OrphanedResource{{
+			Kind: "ConfigMap", Name: "hippo-old-config", InstanceSet: "old", Reason: "Uncontrolled",
+		}})
+	})
+
+	t.Run("Retain", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.RemovedInstanceSetPolicy = v1beta1.RemovedInstanceSetRetain
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects()...).Build()
+		r := &Reconciler{Client: c}
+
+		assert.NilError(t, r.cleanupRemovedInstanceSets(ctx, cluster, observed))
+
+		assert.Assert(t, !exists(c, &appsv1.StatefulSet{ObjectMeta: meta("hippo-old-wxyz")}))
+		assert.Assert(t, exists(c, &corev1.PersistentVolumeClaim{ObjectMeta: meta("hippo-old-wxyz-pgdata")}))
+
+		assert.DeepEqual(t, cluster.Status.OrphanedResources, []v1beta1.OrphanedResource{{
+			Kind: "ConfigMap", Name: "hippo-old-config", InstanceSet: "old", Reason: "Uncontrolled",
+		}, {
+			Kind: "PersistentVolumeClaim", Name: "hippo-old-wxyz-pgdata", InstanceSet: "old", Reason: "Retained",
+		}})
+	})
+
+	t.Run("NoInstanceSets", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.InstanceSets = nil
+
+		c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects()...).Build()
+		r := &Reconciler{Client: c}
+
+		assert.NilError(t, r.cleanupRemovedInstanceSets(ctx, cluster, observed))
+		assert.Assert(t, exists(c, &appsv1.StatefulSet{ObjectMeta: meta("hippo-old-wxyz")}))
+	})
+}
+
+func TestRetainVolume(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{Name: "new"}}
+
+	assert.Assert(t, !retainVolume(cluster, "old"), "expected Delete by default")
+
+	cluster.Spec.RemovedInstanceSetPolicy = v1beta1.RemovedInstanceSetRetain
+	assert.Assert(t, retainVolume(cluster, "old"))
+	assert.Assert(t, !retainVolume(cluster, "new"), "expected only removed sets")
+}
@@ -0,0 +1,145 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/partman"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// partitioningJob reports the outcome of pg_partman maintenance in the
+// PartitionsMaintained condition.
+var partitioningJob = scheduledJob{
+	name:      "partman",
+	label:     naming.LabelPartitioning,
+	condition: v1beta1.PartitionsMaintained,
+
+	succeededReason:  "MaintenanceSucceeded",
+	succeededMessage: "Partitions were created and expired partitions dropped",
+
+	failedReason:  "MaintenanceFailed",
+	failedMessage: "Partition maintenance failed; see the logs of Job %q",
+}
+
+// +kubebuilder:rbac:groups="batch",resources="cronjobs",verbs={get,create,patch,delete}
+// +kubebuilder:rbac:groups="batch",resources="jobs",verbs={list}
+
+// reconcilePartitioning installs pg_partman, registers partitioned tables with
+// it, and schedules a CronJob that creates and drops partitions as the
+// maintenance user. The outcome of the latest Job is reported in the
+// PartitionsMaintained condition.
+func (r *Reconciler) reconcilePartitioning(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+	secret *corev1.Secret,
+) error {
+	enabled := partman.Enabled(cluster)
+
+	if cluster.Spec.Partitioning != nil && !enabled {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "PartitioningUnsupported",
+			"Partitioning requires PostgreSQL 14 or later, not %d",
+			cluster.Spec.PostgresVersion)
+	}
+
+	// Change PostgreSQL only when something was installed or is requested.
+	status := cluster.Status.Partitioning
+	if status != nil || enabled {
+		if status == nil {
+			status = new(v1beta1.PartitioningStatus)
+		}
+
+		var unregistered []string
+		action := func(ctx context.Context, exec postgres.Executor) error {
+			if enabled {
+				var err error
+				unregistered, err = partman.EnableInPostgreSQL(ctx, exec, cluster.Spec.Partitioning.Tables)
+				return err
+			}
+			return partman.DisableInPostgreSQL(ctx, exec)
+		}
+
+		previous := status.Revision
+		current, err := r.reconcileSQLRevision(ctx, instances, &status.Revision, action)
+		if err != nil {
+			return err
+		}
+		if status.Revision != previous {
+			status.UnregisteredTables = unregistered
+		}
+
+		cluster.Status.Partitioning = status
+		if current && !enabled {
+			cluster.Status.Partitioning = nil
+		}
+	}
+
+	// Wait for pg_partman to be configured before scheduling any Jobs.
+	cronjob := &batchv1.CronJob{ObjectMeta: naming.PartitioningCronJob(cluster)}
+	finished, err := r.reconcileScheduledJob(ctx, cluster, partitioningJob, cronjob,
+		enabled, status != nil && status.Revision != "",
+		func(cronjob *batchv1.CronJob) { generatePartitioningCronJob(cluster, secret, cronjob) })
+
+	if err != nil || finished == nil {
+		return err
+	}
+
+	// Tables that are created after pg_partman is configured are registered
+	// each time a maintenance Job finishes until all of them exist.
+	previous := status.LastMaintenanceTime
+	status.LastMaintenanceTime = finished
+
+	if len(status.UnregisteredTables) > 0 &&
+		(previous == nil || previous.Before(finished)) {
+		if pod, _ := instances.writablePod(naming.ContainerDatabase); pod != nil {
+			var unregistered []string
+			unregistered, err = partman.EnableInPostgreSQL(ctx,
+				r.databaseExecutor(pod), cluster.Spec.Partitioning.Tables)
+			if err == nil {
+				status.UnregisteredTables = unregistered
+			}
+		}
+	}
+	return err
+}
+
+// generatePartitioningCronJob populates cronjob with a schedule that runs
+// pg_partman maintenance through the primary of cluster.
+func generatePartitioningCronJob(
+	cluster *v1beta1.PostgresCluster, secret *corev1.Secret,
+	cronjob *batchv1.CronJob,
+) {
+	spec := cluster.Spec.Partitioning
+
+	// Suspend the CronJob when shutdown or a standby.
+	suspend := (cluster.Spec.Shutdown != nil && *cluster.Spec.Shutdown) ||
+		(cluster.Spec.Standby != nil && cluster.Spec.Standby.Enabled)
+
+	template := generateScheduledJob(cluster, partitioningJob, cronjob,
+		spec.Schedule, suspend, corev1.Container{
+			Command:   partman.Command(cluster),
+			Name:      naming.ContainerJobPartitioning,
+			Resources: spec.Resources,
+		})
+
+	withMaintenanceUser(cluster, secret, naming.ClusterPrimaryService(cluster), template)
+	cronjob.Spec.JobTemplate.Spec.TTLSecondsAfterFinished = spec.TTLSecondsAfterFinished
+}
@@ -0,0 +1,63 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestGeneratePartitioningCronJob(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Name = "hippo"
+	cluster.Namespace = "ns1"
+	cluster.Spec.Port = initialize.Int32(5432)
+	cluster.Spec.PostgresVersion = 16
+	cluster.Spec.Partitioning = &v1beta1.PartitioningSpec{
+		Schedule: "*/30 * * * *",
+		Tables: []v1beta1.PartitionedTable{
+			{Database: "app", Table: "public.events", Column: "at", Interval: "1 day"},
+		},
+	}
+	secret := &corev1.Secret{ObjectMeta: naming.MaintenanceSecret(cluster)}
+
+	cronjob := &batchv1.CronJob{ObjectMeta: naming.PartitioningCronJob(cluster)}
+	generatePartitioningCronJob(cluster, secret, cronjob)
+
+	assert.Equal(t, cronjob.Spec.Schedule, "*/30 * * * *")
+	assert.Equal(t, cronjob.Spec.ConcurrencyPolicy, batchv1.ForbidConcurrent)
+	assert.Equal(t, *cronjob.Spec.Suspend, false)
+	assert.DeepEqual(t, cronjob.Labels, map[string]string{
+		naming.LabelCluster:      "hippo",
+		naming.LabelPartitioning: "",
+	})
+
+	pod := cronjob.Spec.JobTemplate.Spec.Template.Spec
+	assert.Equal(t, pod.Volumes[0].Secret.SecretName, "hippo-maintenance-cert")
+
+	container := pod.Containers[0]
+	assert.Equal(t, container.Name, "partman")
+	assert.Equal(t, container.Env[0].Value, "hippo-primary.ns1.svc")
+	assert.Equal(t, container.Env[3].Value, "_crunchymaintenance")
+	assert.Equal(t, container.Command[len(container.Command)-1], "app")
+}
@@ -23,8 +23,10 @@ import (
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
@@ -99,6 +101,11 @@ func (r *Reconciler) handlePatroniRestarts(
 	// replicas here, replicas will typically restart first because we see them
 	// first.
 	if primaryNeedsRestart != nil {
+		if ready, err := r.drainForCutover(ctx, cluster, instances,
+			v1beta1.PGBouncerCutoverRestart); err != nil || !ready {
+			return err
+		}
+
 		exec := patroni.Executor(func(
 			ctx context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
 		) error {
@@ -170,6 +177,7 @@ func (r *Reconciler) reconcilePatroniDistributedConfiguration(
 	// - https://docs.k8s.io/concepts/services-networking/service/#headless-services
 	dcsService.Spec.ClusterIP = corev1.ClusterIPNone
 	dcsService.Spec.Selector = nil
+	setServiceIPFamilies(cluster, &dcsService.Spec)
 
 	if err == nil {
 		err = errors.WithStack(r.apply(ctx, dcsService))
@@ -222,10 +230,73 @@ func (r *Reconciler) reconcilePatroniDynamicConfiguration(
 	}
 	configuration = patroni.DynamicConfiguration(cluster, configuration, pgHBAs, pgParameters)
 
+	// Patroni stops managing PostgreSQL while "pause" is true. Replacing the
+	// configuration without it resumes Patroni.
+	// - https://patroni.readthedocs.io/en/latest/pause.html
+	if meta.IsStatusConditionTrue(cluster.Status.Conditions, v1beta1.PausedByUser) {
+		configuration["pause"] = true
+	}
+
 	return errors.WithStack(
 		patroni.Executor(exec).ReplaceConfiguration(ctx, configuration))
 }
 
+// reconcilePatroniPause tracks a pause requested in the spec with the
+// PausedByUser condition. The pause ends when the spec no longer asks for it
+// or after PausedTimeoutSeconds, whichever comes first.
+func (r *Reconciler) reconcilePatroniPause(
+	cluster *v1beta1.PostgresCluster, now time.Time,
+) reconcile.Result {
+	spec := cluster.Spec.Patroni
+	if spec == nil || spec.Paused == nil || !*spec.Paused {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.PausedByUser)
+		return reconcile.Result{}
+	}
+
+	previous := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.PausedByUser)
+	if previous != nil && previous.Status == metav1.ConditionFalse {
+		// The pause has expired. It stays that way until the spec stops and
+		// starts asking for it again.
+		previous.ObservedGeneration = cluster.GetGeneration()
+		return reconcile.Result{}
+	}
+
+	since := now
+	if previous != nil {
+		since = previous.LastTransitionTime.Time
+	}
+
+	timeout := time.Duration(*spec.PausedTimeoutSeconds) * time.Second
+	remaining := since.Add(timeout).Sub(now)
+
+	condition := metav1.Condition{
+		Type:               v1beta1.PausedByUser,
+		LastTransitionTime: metav1.NewTime(now),
+		ObservedGeneration: cluster.GetGeneration(),
+	}
+
+	if remaining > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "Paused"
+		condition.Message = fmt.Sprintf(
+			"Patroni is paused. It will be resumed at %v.",
+			since.Add(timeout).UTC().Format(time.RFC3339))
+	} else {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "Expired"
+		condition.Message = fmt.Sprintf(
+			"Patroni was resumed after %v. Set paused to false then true to pause again.",
+			timeout)
+	}
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+
+	if remaining > 0 {
+		return reconcile.Result{RequeueAfter: remaining}
+	}
+	return reconcile.Result{}
+}
+
 // generatePatroniLeaderLeaseService returns a v1.Service that exposes the
 // Patroni leader when Patroni is using Endpoints for its leader elections.
 func (r *Reconciler) generatePatroniLeaderLeaseService(
@@ -246,6 +317,13 @@ func (r *Reconciler) generatePatroniLeaderLeaseService(
 			spec.Metadata.GetLabelsOrNil())
 	}
 
+	// Hostnames of the primary go on this Service because Patroni manages its
+	// Endpoints; they follow the elected leader through failover.
+	if dns := cluster.Spec.DNS; dns != nil {
+		service.Annotations = naming.Merge(service.Annotations,
+			externalDNSAnnotations(dns, dns.Hostnames.Primary))
+	}
+
 	// add our labels last so they aren't overwritten
 	service.Labels = naming.Merge(service.Labels,
 		map[string]string{
@@ -257,6 +335,7 @@ func (r *Reconciler) generatePatroniLeaderLeaseService(
 	// Patroni will ensure that they always route to the elected leader.
 	// - https://docs.k8s.io/concepts/services-networking/service/#services-without-selectors
 	service.Spec.Selector = nil
+	setServiceIPFamilies(cluster, &service.Spec)
 
 	// The TargetPort must be the name (not the number) of the PostgreSQL
 	// ContainerPort. This name allows the port number to differ between
@@ -384,6 +463,8 @@ func (r *Reconciler) reconcileReplicationSecret(
 	dnsNames := []string{commonName}
 
 	if err == nil {
+		r.recordRegeneratedSecret(cluster, existing, "the replication client certificate")
+
 		// Unmarshal and validate the stored leaf. These first errors can
 		// be ignored because they result in an invalid leaf which is then
 		// correctly regenerated.
@@ -420,7 +501,7 @@ func (r *Reconciler) reconcileReplicationSecret(
 		err = errors.WithStack(err)
 	}
 	if err == nil {
-		intent.Data[naming.ReplicationCACert], err = root.Certificate.MarshalText()
+		intent.Data[naming.ReplicationCACert], err = root.Trusted().MarshalText()
 		err = errors.WithStack(err)
 	}
 	if err == nil {
@@ -577,6 +658,12 @@ func (r *Reconciler) reconcilePatroniSwitchover(ctx context.Context,
 		return nil
 	}
 
+	// Pause PgBouncer, when configured, so that clients wait for the new primary.
+	if ready, err := r.drainForCutover(ctx, cluster, instances,
+		v1beta1.PGBouncerCutoverSwitchover); err != nil || !ready {
+		return err
+	}
+
 	// We have the pod executor, now we need to figure out which API call to use
 	// In the default case we will be using SwitchoverAndWait. This API call uses
 	// a Patronictl switchover to move to the target instance.
@@ -613,3 +700,101 @@ func (r *Reconciler) reconcilePatroniSwitchover(ctx context.Context,
 
 	return err
 }
+
+// reconcilePatroniRestart restarts or reloads PostgreSQL in the target
+// instances when the trigger-restart or trigger-reload annotation changes.
+// Replicas restart one at a time before the primary so that connections to
+// the primary are interrupted only once, at the end.
+func (r *Reconciler) reconcilePatroniRestart(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances) error {
+
+	// Like switchovers, disabling restarts clears the status fields. This gives
+	// the user a way to try the same annotation again.
+	if cluster.Spec.Patroni == nil ||
+		cluster.Spec.Patroni.Restart == nil ||
+		!cluster.Spec.Patroni.Restart.Enabled {
+		cluster.Status.Patroni.Restart = nil
+		cluster.Status.Patroni.Reload = nil
+		return nil
+	}
+
+	spec := cluster.Spec.Patroni.Restart
+	status := &cluster.Status.Patroni
+	restart := cluster.GetAnnotations()[naming.PatroniRestart]
+	reload := cluster.GetAnnotations()[naming.PatroniReload]
+
+	restartRequested := restart != "" && (status.Restart == nil || *status.Restart != restart)
+	reloadRequested := reload != "" && (status.Reload == nil || *status.Reload != reload)
+	if !restartRequested && !reloadRequested {
+		return nil
+	}
+
+	// Sort the Patroni members of the target instances into replicas and the
+	// primary. Every target must be running so that a request is not carried
+	// out on only some of them.
+	targets := sets.NewString(spec.TargetInstances...)
+	found := sets.NewString()
+	var primary, replicas []string
+	var runningPod *corev1.Pod
+
+	for _, instance := range instances.forCluster {
+		if targets.Len() > 0 && !targets.Has(instance.Name) {
+			continue
+		}
+		found.Insert(instance.Name)
+
+		running, known := instance.IsRunning(naming.ContainerDatabase)
+		if !running || !known || len(instance.Pods) != 1 {
+			return errors.Errorf("instance %q is not running", instance.Name)
+		}
+
+		runningPod = instance.Pods[0]
+		if isPrimary, _ := instance.IsPrimary(); isPrimary {
+			primary = append(primary, runningPod.Name)
+		} else {
+			replicas = append(replicas, runningPod.Name)
+		}
+	}
+	if missing := targets.Difference(found); missing.Len() > 0 {
+		return errors.Errorf("TargetInstances not found in the cluster: %v", missing.List())
+	}
+	if runningPod == nil {
+		return errors.New("Could not find a running pod when attempting restart.")
+	}
+
+	exec := patroni.Executor(func(_ context.Context, stdin io.Reader, stdout, stderr io.Writer,
+		command ...string) error {
+		return r.PodExec(runningPod.Namespace, runningPod.Name, naming.ContainerDatabase, stdin,
+			stdout, stderr, command...)
+	})
+	scope := naming.PatroniScope(cluster)
+
+	if reloadRequested {
+		err := exec.ReloadMembers(ctx, scope, append(replicas, primary...)...)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		status.Reload = initialize.String(reload)
+	}
+
+	if restartRequested && len(primary) > 0 {
+		if ready, err := r.drainForCutover(ctx, cluster, instances,
+			v1beta1.PGBouncerCutoverRestart); err != nil || !ready {
+			return err
+		}
+	}
+
+	if restartRequested {
+		for _, members := range [][]string{replicas, primary} {
+			if len(members) == 0 {
+				continue
+			}
+			if err := exec.RestartMembers(ctx, scope, spec.PendingOnly, members...); err != nil {
+				return errors.WithStack(err)
+			}
+		}
+		status.Restart = initialize.String(restart)
+	}
+
+	return nil
+}
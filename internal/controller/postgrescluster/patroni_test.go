@@ -33,6 +33,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/record"
@@ -157,6 +158,26 @@ ownerReferences:
 			"got %v", service.Spec.Selector)
 	})
 
+	t.Run("DNS", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.DNS = &v1beta1.PostgresClusterDNS{TTLSeconds: initialize.Int32(30)}
+		cluster.Spec.DNS.Hostnames.Primary = []string{"db.example.com", "primary.example.com"}
+		cluster.Spec.DNS.Hostnames.Replicas = []string{"replicas.example.com"}
+		cluster.Spec.Service = &v1beta1.ServiceSpec{
+			Metadata: &v1beta1.Metadata{
+				Annotations: map[string]string{"c": "v3"},
+			},
+		}
+
+		service, err := reconciler.generatePatroniLeaderLeaseService(cluster)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, service.Annotations, map[string]string{
+			"c": "v3",
+			"external-dns.alpha.kubernetes.io/hostname": "db.example.com,primary.example.com",
+			"external-dns.alpha.kubernetes.io/ttl":      "30",
+		})
+	})
+
 	types := []struct {
 		Type   string
 		Expect func(testing.TB, *corev1.Service)
@@ -539,6 +560,146 @@ func TestReconcilePatroniStatus(t *testing.T) {
 	}
 }
 
+func TestReconcilePatroniPause(t *testing.T) {
+	r := &Reconciler{}
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Spec.Patroni = &v1beta1.PatroniSpec{
+		PausedTimeoutSeconds: initialize.Int32(600),
+	}
+
+	// Nothing happens until the spec asks for a pause.
+	assert.Equal(t, r.reconcilePatroniPause(cluster, now), reconcile.Result{})
+	assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.PausedByUser) == nil)
+
+	cluster.Spec.Patroni.Paused = initialize.Bool(true)
+	assert.Equal(t, r.reconcilePatroniPause(cluster, now),
+		reconcile.Result{RequeueAfter: 10 * time.Minute})
+	assert.Assert(t, meta.IsStatusConditionTrue(cluster.Status.Conditions, v1beta1.PausedByUser))
+
+	// The timeout counts from when the pause began.
+	assert.Equal(t, r.reconcilePatroniPause(cluster, now.Add(4*time.Minute)),
+		reconcile.Result{RequeueAfter: 6 * time.Minute})
+	assert.Assert(t, meta.IsStatusConditionTrue(cluster.Status.Conditions, v1beta1.PausedByUser))
+
+	// The pause expires and stays expired.
+	for _, later := range []time.Duration{10 * time.Minute, time.Hour} {
+		assert.Equal(t, r.reconcilePatroniPause(cluster, now.Add(later)), reconcile.Result{})
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.PausedByUser)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "Expired")
+	}
+
+	// Turning it off and on again starts another pause.
+	cluster.Spec.Patroni.Paused = initialize.Bool(false)
+	assert.Equal(t, r.reconcilePatroniPause(cluster, now.Add(2*time.Hour)), reconcile.Result{})
+	assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.PausedByUser) == nil)
+
+	cluster.Spec.Patroni.Paused = initialize.Bool(true)
+	assert.Equal(t, r.reconcilePatroniPause(cluster, now.Add(3*time.Hour)),
+		reconcile.Result{RequeueAfter: 10 * time.Minute})
+	assert.Assert(t, meta.IsStatusConditionTrue(cluster.Status.Conditions, v1beta1.PausedByUser))
+}
+
+func TestReconcilePatroniRestart(t *testing.T) {
+	ctx := context.Background()
+
+	var commands []string
+	r := &Reconciler{
+		PodExec: func(namespace, pod, container string,
+			stdin io.Reader, stdout, stderr io.Writer, command ...string) error {
+			commands = append(commands, strings.Join(command, " "))
+			return nil
+		},
+	}
+
+	instance := func(name, role string) *Instance {
+		return &Instance{
+			Name: name,
+			Pods: []*corev1.Pod{{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   name + "-0",
+					Labels: map[string]string{naming.LabelRole: role},
+				},
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{{
+						Name: naming.ContainerDatabase,
+						State: corev1.ContainerState{
+							Running: new(corev1.ContainerStateRunning),
+						},
+					}},
+				},
+			}},
+			Runner: &appsv1.StatefulSet{},
+		}
+	}
+	observed := &observedInstances{forCluster: []*Instance{
+		instance("one", naming.RolePatroniLeader),
+		instance("two", naming.RolePatroniReplica),
+		instance("three", naming.RolePatroniReplica),
+	}}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Name = "hippo"
+	cluster.Annotations = map[string]string{naming.PatroniRestart: "first"}
+	cluster.Spec.Patroni = &v1beta1.PatroniSpec{}
+
+	t.Run("Disabled", func(t *testing.T) {
+		cluster.Status.Patroni.Restart = initialize.String("old")
+
+		assert.NilError(t, r.reconcilePatroniRestart(ctx, cluster, observed))
+		assert.Assert(t, cluster.Status.Patroni.Restart == nil)
+		assert.Assert(t, commands == nil)
+	})
+
+	cluster.Spec.Patroni.Restart = &v1beta1.PatroniRestart{Enabled: true}
+
+	t.Run("ReplicasFirst", func(t *testing.T) {
+		commands = nil
+
+		assert.NilError(t, r.reconcilePatroniRestart(ctx, cluster, observed))
+		assert.DeepEqual(t, commands, []string{
+			"patronictl restart --force hippo-ha two-0 three-0",
+			"patronictl restart --force hippo-ha one-0",
+		})
+		assert.Equal(t, *cluster.Status.Patroni.Restart, "first")
+
+		// Nothing happens until the annotation changes.
+		commands = nil
+		assert.NilError(t, r.reconcilePatroniRestart(ctx, cluster, observed))
+		assert.Assert(t, commands == nil)
+	})
+
+	t.Run("TargetInstances", func(t *testing.T) {
+		commands = nil
+		cluster.Annotations[naming.PatroniRestart] = "second"
+		cluster.Annotations[naming.PatroniReload] = "first"
+		cluster.Spec.Patroni.Restart.TargetInstances = []string{"three"}
+		cluster.Spec.Patroni.Restart.PendingOnly = true
+
+		assert.NilError(t, r.reconcilePatroniRestart(ctx, cluster, observed))
+		assert.DeepEqual(t, commands, []string{
+			"patronictl reload --force hippo-ha three-0",
+			"patronictl restart --force --pending hippo-ha three-0",
+		})
+		assert.Equal(t, *cluster.Status.Patroni.Reload, "first")
+		assert.Equal(t, *cluster.Status.Patroni.Restart, "second")
+	})
+
+	t.Run("MissingInstance", func(t *testing.T) {
+		commands = nil
+		cluster.Annotations[naming.PatroniRestart] = "third"
+		cluster.Spec.Patroni.Restart.TargetInstances = []string{"four"}
+
+		err := r.reconcilePatroniRestart(ctx, cluster, observed)
+		assert.ErrorContains(t, err, "four")
+		assert.Assert(t, commands == nil)
+		assert.Equal(t, *cluster.Status.Patroni.Restart, "second")
+	})
+}
+
 func TestReconcilePatroniSwitchover(t *testing.T) {
 	_, client := setupKubernetes(t)
 	require.ParallelCapacity(t, 0)
@@ -0,0 +1,56 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// setPermissionsCondition sets the PermissionsAvailable condition of cluster
+// according to the error, if any, that ended reconciliation. When the
+// Kubernetes API denied the operator a permission, the condition says which
+// one so that it can be added to the operator's Role or ClusterRole. The
+// condition returns to True once reconciliation succeeds.
+func setPermissionsCondition(cluster *v1beta1.PostgresCluster, err error) {
+	var status apierrors.APIStatus
+
+	switch {
+	case apierrors.IsForbidden(err) && errors.As(err, &status):
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type:               v1beta1.PermissionsAvailable,
+			ObservedGeneration: cluster.GetGeneration(),
+			Status:             metav1.ConditionFalse,
+			Reason:             "Forbidden",
+			Message: status.Status().Message +
+				"; grant this permission to the operator's Role or ClusterRole",
+		})
+
+	case err == nil && meta.FindStatusCondition(
+		cluster.Status.Conditions, v1beta1.PermissionsAvailable) != nil:
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type:               v1beta1.PermissionsAvailable,
+			ObservedGeneration: cluster.GetGeneration(),
+			Status:             metav1.ConditionTrue,
+			Reason:             "PermissionsGranted",
+			Message:            "The operator has the permissions it needs",
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"errors"
+	"testing"
+
+	pkgerrors "github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestSetPermissionsCondition(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Generation = 3
+
+	t.Run("Success", func(t *testing.T) {
+		setPermissionsCondition(cluster, nil)
+		assert.Assert(t, meta.FindStatusCondition(
+			cluster.Status.Conditions, v1beta1.PermissionsAvailable) == nil,
+			"expected no condition until a permission is denied")
+	})
+
+	t.Run("OtherError", func(t *testing.T) {
+		setPermissionsCondition(cluster, errors.New("boom"))
+		assert.Assert(t, meta.FindStatusCondition(
+			cluster.Status.Conditions, v1beta1.PermissionsAvailable) == nil)
+	})
+
+	t.Run("Forbidden", func(t *testing.T) {
+		err := pkgerrors.WithStack(apierrors.NewForbidden(
+			schema.GroupResource{Group: "storage.k8s.io", Resource: "storageclasses"},
+			"fast", errors.New(`User "pgo" cannot get resource`)))
+
+		setPermissionsCondition(cluster, err)
+
+		condition := meta.FindStatusCondition(
+			cluster.Status.Conditions, v1beta1.PermissionsAvailable)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "Forbidden")
+		assert.Equal(t, condition.ObservedGeneration, int64(3))
+		assert.Assert(t, cmp.Contains(condition.Message, `storageclasses.storage.k8s.io "fast" is forbidden`))
+		assert.Assert(t, cmp.Contains(condition.Message, "Role or ClusterRole"))
+	})
+
+	t.Run("Recovered", func(t *testing.T) {
+		setPermissionsCondition(cluster, nil)
+		assert.Assert(t, meta.IsStatusConditionTrue(
+			cluster.Status.Conditions, v1beta1.PermissionsAvailable))
+	})
+}
@@ -160,6 +160,7 @@ func (r *Reconciler) generatePGAdminService(
 		naming.LabelCluster: cluster.Name,
 		naming.LabelRole:    naming.RolePGAdmin,
 	}
+	setServiceIPFamilies(cluster, &service.Spec)
 
 	// The TargetPort must be the name (not the number) of the pgAdmin
 	// ContainerPort. This name allows the port number to differ between Pods,
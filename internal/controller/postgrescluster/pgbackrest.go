@@ -21,6 +21,7 @@ import (
 	"io"
 	"regexp"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -29,6 +30,7 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -50,6 +52,7 @@ import (
 	"github.com/crunchydata/postgres-operator/internal/pgbackrest"
 	"github.com/crunchydata/postgres-operator/internal/pki"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/servicemesh"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
@@ -117,9 +120,12 @@ var regexRepoIndex = regexp.MustCompile(`\d+`)
 // RepoResources is used to store various resources for pgBackRest repositories and
 // repository hosts
 type RepoResources struct {
+	changeProtectionJobs    []*batchv1.Job
 	cronjobs                []*batchv1.CronJob
 	manualBackupJobs        []*batchv1.Job
 	replicaCreateBackupJobs []*batchv1.Job
+	repoMigrationJobs       []*batchv1.Job
+	scheduledBackupJobs     []*batchv1.Job
 	hosts                   []*appsv1.StatefulSet
 	pvcs                    []*corev1.PersistentVolumeClaim
 }
@@ -179,6 +185,19 @@ func (r *Reconciler) applyRepoVolumeIntent(ctx context.Context,
 	postgresCluster *v1beta1.PostgresCluster, spec corev1.PersistentVolumeClaimSpec,
 	repoName string, repoResources *RepoResources) (*corev1.PersistentVolumeClaim, error) {
 
+	for _, existing := range repoResources.pvcs {
+		if existing.GetLabels()[naming.LabelPGBackRestRepo] == repoName {
+			var unchanged []string
+			spec, unchanged = resizeRepoVolume(existing.Spec, spec)
+			if len(unchanged) > 0 {
+				r.Recorder.Eventf(postgresCluster, corev1.EventTypeWarning, "RepoVolumeUnchanged",
+					"Unable to change %s of the volume of %q. Define another repo with the new "+
+						"volume and migrate to it using repoMigration.",
+					strings.Join(unchanged, ", "), repoName)
+			}
+		}
+	}
+
 	repo, err := r.generateRepoVolumeIntent(postgresCluster, spec, repoName, repoResources)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -192,6 +211,43 @@ func (r *Reconciler) applyRepoVolumeIntent(ctx context.Context,
 	return repo, nil
 }
 
+// resizeRepoVolume returns the spec to apply to an existing repo volume when
+// spec is wanted. The storage request of a volume can grow, which expands the
+// volume when its StorageClass allows it, but never shrink. Other fields of an
+// existing volume cannot change; their names are returned when spec asks for a
+// change and the existing values are kept so that the volume is not replaced.
+func resizeRepoVolume(
+	existing, spec corev1.PersistentVolumeClaimSpec,
+) (corev1.PersistentVolumeClaimSpec, []string) {
+	var unchanged []string
+	spec = *spec.DeepCopy()
+
+	current, hasCurrent := existing.Resources.Requests[corev1.ResourceStorage]
+	wanted, hasWanted := spec.Resources.Requests[corev1.ResourceStorage]
+	if hasCurrent && hasWanted && wanted.Cmp(current) < 0 {
+		spec.Resources.Requests[corev1.ResourceStorage] = current
+		unchanged = append(unchanged, "storage request")
+	}
+
+	if spec.StorageClassName != nil && existing.StorageClassName != nil &&
+		*spec.StorageClassName != *existing.StorageClassName {
+		spec.StorageClassName = existing.StorageClassName
+		unchanged = append(unchanged, "storageClassName")
+	}
+	if len(spec.AccessModes) > 0 && len(existing.AccessModes) > 0 &&
+		!equality.Semantic.DeepEqual(spec.AccessModes, existing.AccessModes) {
+		spec.AccessModes = existing.AccessModes
+		unchanged = append(unchanged, "accessModes")
+	}
+	if spec.VolumeMode != nil && existing.VolumeMode != nil &&
+		*spec.VolumeMode != *existing.VolumeMode {
+		spec.VolumeMode = existing.VolumeMode
+		unchanged = append(unchanged, "volumeMode")
+	}
+
+	return spec, unchanged
+}
+
 // getPGBackRestResources returns the existing pgBackRest resources that should utilized by the
 // PostgresCluster controller during reconciliation.  Any items returned are verified to be owned
 // by the PostgresCluster controller and still applicable per the current PostgresCluster spec.
@@ -306,7 +362,14 @@ func (r *Reconciler) cleanupRepoResources(ctx context.Context,
 			}
 		case hasLabel(naming.LabelPGBackRestRepoVolume):
 			// If a volume (PVC) is identified for a repo that no longer exists in the
-			// spec then delete it.  Otherwise add it to the slice and continue.
+			// spec then delete it.  Otherwise add it to the slice and continue.  The volume
+			// of a repo being migrated elsewhere is kept until the migration is removed.
+			if migration := postgresCluster.Spec.Backups.PGBackRest.RepoMigration; migration != nil &&
+				migration.From == owned.GetLabels()[naming.LabelPGBackRestRepo] {
+				ownedNoDelete = append(ownedNoDelete, owned)
+				delete = false
+				break
+			}
 			for _, repo := range postgresCluster.Spec.Backups.PGBackRest.Repos {
 				// we only care about cleaning up local repo volumes (PVCs), and ignore other repo
 				// types (e.g. for external Azure, GCS or S3 repositories)
@@ -400,6 +463,18 @@ func unstructuredToRepoResources(kind string, repoResources *RepoResources,
 			case string(naming.BackupManual):
 				repoResources.manualBackupJobs =
 					append(repoResources.manualBackupJobs, &jobList.Items[i])
+			case string(naming.BackupRepoMigration):
+				repoResources.repoMigrationJobs =
+					append(repoResources.repoMigrationJobs, &jobList.Items[i])
+			case string(naming.BackupChangeProtection):
+				repoResources.changeProtectionJobs =
+					append(repoResources.changeProtectionJobs, &jobList.Items[i])
+			default:
+				// Jobs of backup CronJobs have the labels of their CronJob.
+				if _, ok := job.GetLabels()[naming.LabelPGBackRestCronJob]; ok {
+					repoResources.scheduledBackupJobs =
+						append(repoResources.scheduledBackupJobs, &jobList.Items[i])
+				}
 			}
 		}
 	case "PersistentVolumeClaimList":
@@ -465,10 +540,23 @@ func (r *Reconciler) setScheduledJobStatus(ctx context.Context,
 		return
 	}
 
+	// Index the previous status by Job so that details gathered from pgBackRest,
+	// and the status of Jobs that have since been deleted, are not lost.
+	previous := make(map[string]v1beta1.PGBackRestScheduledBackupStatus)
+	if postgresCluster.Status.PGBackRest != nil {
+		for _, sbs := range postgresCluster.Status.PGBackRest.ScheduledBackups {
+			if sbs.JobName != "" {
+				previous[sbs.JobName] = sbs
+			}
+		}
+	}
+
 	// TODO(tjmoore4): PGBackRestScheduledBackupStatus can likely be combined with
 	// PGBackRestJobStatus as they both contain most of the same information
 	scheduledStatus := []v1beta1.PGBackRestScheduledBackupStatus{}
-	for _, job := range jobList.Items {
+	for i := range jobList.Items {
+		job := &jobList.Items[i]
+
 		// we only care about the scheduled backup Jobs created by the
 		// associated CronJobs
 		sbs := v1beta1.PGBackRestScheduledBackupStatus{}
@@ -483,11 +571,33 @@ func (r *Reconciler) setScheduledJobStatus(ctx context.Context,
 			sbs.Active = job.Status.Active
 			sbs.Succeeded = job.Status.Succeeded
 			sbs.Failed = job.Status.Failed
+			sbs.JobName = job.Name
+
+			if sbs.StartTime != nil && sbs.CompletionTime != nil {
+				sbs.Duration = &metav1.Duration{
+					Duration: sbs.CompletionTime.Sub(sbs.StartTime.Time),
+				}
+			}
+			for _, c := range job.Status.Conditions {
+				if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+					sbs.FailureMessage = c.Reason
+					if c.Message != "" {
+						sbs.FailureMessage += ": " + c.Message
+					}
+				}
+			}
+			if prior, ok := previous[job.Name]; ok {
+				sbs.BackupLabel, sbs.Size = prior.BackupLabel, prior.Size
+				delete(previous, job.Name)
+			}
 
 			scheduledStatus = append(scheduledStatus, sbs)
 		}
 	}
 
+	scheduledStatus = append(scheduledStatus,
+		retainedScheduledBackups(postgresCluster, scheduledStatus, previous)...)
+
 	// if nil, create the pgBackRest status
 	if postgresCluster.Status.PGBackRest == nil {
 		postgresCluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{}
@@ -495,6 +605,135 @@ func (r *Reconciler) setScheduledJobStatus(ctx context.Context,
 	postgresCluster.Status.PGBackRest.ScheduledBackups = scheduledStatus
 }
 
+// retainedScheduledBackups returns the status of finished scheduled backup Jobs
+// that no longer exist, newest first. Each CronJob keeps as many of these as its
+// Job history limits allow, counting the Jobs that still exist in current.
+func retainedScheduledBackups(cluster *v1beta1.PostgresCluster,
+	current []v1beta1.PGBackRestScheduledBackupStatus,
+	deleted map[string]v1beta1.PGBackRestScheduledBackupStatus,
+) []v1beta1.PGBackRestScheduledBackupStatus {
+	successfulLimit, failedLimit := jobHistoryLimits(cluster)
+
+	// outcome returns a key for counting finished Jobs of each CronJob and
+	// the limit of that key. Jobs that have not finished return no key.
+	outcome := func(sbs v1beta1.PGBackRestScheduledBackupStatus) (string, int32) {
+		switch {
+		case sbs.CompletionTime != nil:
+			return sbs.CronJobName + "/successful", successfulLimit
+		case sbs.FailureMessage != "":
+			return sbs.CronJobName + "/failed", failedLimit
+		}
+		return "", 0
+	}
+
+	counts := make(map[string]int32)
+	for _, sbs := range current {
+		if key, _ := outcome(sbs); key != "" {
+			counts[key]++
+		}
+	}
+
+	candidates := make([]v1beta1.PGBackRestScheduledBackupStatus, 0, len(deleted))
+	for _, sbs := range deleted {
+		candidates = append(candidates, sbs)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i].StartTime, candidates[j].StartTime
+		if a == nil || b == nil || a.Equal(b) {
+			return candidates[i].JobName > candidates[j].JobName
+		}
+		return b.Before(a)
+	})
+
+	var retained []v1beta1.PGBackRestScheduledBackupStatus
+	for _, sbs := range candidates {
+		if key, limit := outcome(sbs); key != "" && counts[key] < limit {
+			counts[key]++
+			retained = append(retained, sbs)
+		}
+	}
+	return retained
+}
+
+// jobHistoryLimits returns the number of successful and failed Jobs to keep for
+// each scheduled backup CronJob. The defaults match those of Kubernetes.
+// - https://docs.k8s.io/concepts/workloads/controllers/cron-jobs/#jobs-history-limits
+func jobHistoryLimits(cluster *v1beta1.PostgresCluster) (successful, failed int32) {
+	successful, failed = 3, 1
+
+	if limits := cluster.Spec.Backups.PGBackRest.JobHistoryLimit; limits != nil {
+		if limits.Successful != nil {
+			successful = *limits.Successful
+		}
+		if limits.Failed != nil {
+			failed = *limits.Failed
+		}
+	}
+	return
+}
+
+// reconcileScheduledBackupDetails records the label and size of scheduled
+// backups that completed recently. pgBackRest is asked only while some backup is
+// missing these details, and backups that completed long ago are not looked for
+// because they may have already expired.
+func (r *Reconciler) reconcileScheduledBackupDetails(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) error {
+	if cluster.Status.PGBackRest == nil {
+		return nil
+	}
+
+	recent := metav1.NewTime(time.Now().Add(-time.Hour))
+	var pending bool
+	var latest time.Time
+	for _, sbs := range cluster.Status.PGBackRest.ScheduledBackups {
+		if sbs.BackupLabel == "" && sbs.CompletionTime != nil && recent.Before(sbs.CompletionTime) {
+			pending = true
+			if sbs.CompletionTime.After(latest) {
+				latest = sbs.CompletionTime.Time
+			}
+		}
+	}
+
+	pod, _ := instances.writablePod(naming.ContainerDatabase)
+	if !pending || pod == nil {
+		return nil
+	}
+
+	exec := func(_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string) error {
+		return r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase, stdin, stdout, stderr, command...)
+	}
+	backups, err := r.pgBackRestInfo(ctx, cluster, exec, latest)
+	if err == nil {
+		setScheduledBackupDetails(cluster.Status.PGBackRest.ScheduledBackups, backups)
+	}
+	return err
+}
+
+// setScheduledBackupDetails fills in the label and size of each completed
+// scheduled backup in status using the pgBackRest backup that ran in the same
+// repository during its Job.
+func setScheduledBackupDetails(status []v1beta1.PGBackRestScheduledBackupStatus,
+	backups []pgbackrest.InfoBackup,
+) {
+	for i := range status {
+		sbs := &status[i]
+		if sbs.BackupLabel != "" || sbs.StartTime == nil || sbs.CompletionTime == nil {
+			continue
+		}
+
+		index, _ := strconv.Atoi(regexRepoIndex.FindString(sbs.RepoName))
+		for _, backup := range backups {
+			if backup.Database.RepoKey == index && backup.Type == sbs.Type &&
+				backup.Timestamp.Start >= sbs.StartTime.Unix() &&
+				backup.Timestamp.Stop <= sbs.CompletionTime.Unix() {
+				sbs.BackupLabel = backup.Label
+				sbs.Size = initialize.Int64(backup.Info.Size)
+			}
+		}
+	}
+}
+
 // generateRepoHostIntent creates and populates StatefulSet with the PostgresCluster's full intent
 // as needed to create and reconcile a pgBackRest dedicated repository host within the kubernetes
 // cluster.
@@ -531,8 +770,9 @@ func (r *Reconciler) generateRepoHostIntent(postgresCluster *v1beta1.PostgresClu
 			ServiceName: naming.ClusterPodService(postgresCluster).Name,
 			Template: corev1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels:      labels,
-					Annotations: annotations,
+					Labels: labels,
+					Annotations: naming.Merge(annotations,
+						servicemesh.PodAnnotations(postgresCluster)),
 				},
 			},
 		},
@@ -730,7 +970,11 @@ func generateBackupJobSpecIntent(postgresCluster *v1beta1.PostgresCluster,
 
 	jobSpec := &batchv1.JobSpec{
 		Template: corev1.PodTemplateSpec{
-			ObjectMeta: metav1.ObjectMeta{Labels: labels, Annotations: annotations},
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: labels,
+				Annotations: naming.Merge(annotations,
+					servicemesh.NoProxyAnnotations(postgresCluster)),
+			},
 			Spec: corev1.PodSpec{
 				Containers: []corev1.Container{container},
 
@@ -750,7 +994,13 @@ func generateBackupJobSpecIntent(postgresCluster *v1beta1.PostgresCluster,
 		},
 	}
 
-	if jobs := postgresCluster.Spec.Backups.PGBackRest.Jobs; jobs != nil {
+	addJobLimits(postgresCluster, jobSpec)
+
+	// The TTL of backup Jobs takes precedence over that of the cluster.
+	if jobs := postgresCluster.Spec.Jobs; jobs != nil {
+		jobSpec.TTLSecondsAfterFinished = jobs.TTLSecondsAfterFinished
+	}
+	if jobs := postgresCluster.Spec.Backups.PGBackRest.Jobs; jobs != nil && jobs.TTLSecondsAfterFinished != nil {
 		jobSpec.TTLSecondsAfterFinished = jobs.TTLSecondsAfterFinished
 	}
 
@@ -1072,6 +1322,18 @@ func (r *Reconciler) reconcileRestoreJob(ctx context.Context,
 		}
 	}
 
+	// A restore point replaces the recovery type and target.
+	if dataSource.RestorePoint != "" {
+		for _, opt := range options {
+			if strings.Contains(opt, "--type") || strings.Contains(opt, "--target") {
+				r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidDataSource",
+					"Options '--type' and '--target' are not allowed with 'restorePoint' for %q",
+					repoName)
+				return nil
+			}
+		}
+	}
+
 	pgdata := postgres.DataDirectory(cluster)
 	// combine options provided by user in the spec with those populated by the operator for a
 	// successful restore
@@ -1079,6 +1341,8 @@ func (r *Reconciler) reconcileRestoreJob(ctx context.Context,
 		"--stanza=" + stanzaName,
 		"--pg1-path=" + pgdata,
 		"--repo=" + regexRepoIndex.FindString(repoName)}...)
+	opts = append(opts, pgbackrest.RestoreDatabaseOptions(dataSource.Databases)...)
+	opts = append(opts, pgbackrest.RestorePointOptions(dataSource.RestorePoint)...)
 
 	var deltaOptFound, foundTarget bool
 	for _, opt := range opts {
@@ -1170,6 +1434,20 @@ func (r *Reconciler) reconcileRestoreJob(ctx context.Context,
 		volumeMounts = append(volumeMounts, tablespaceVolumeMount)
 	}
 
+	// The tablespace for temporary files is restored to a volume that goes
+	// away with the Job. PostgreSQL needs only its directory, which the
+	// instance recreates on its own volume.
+	if postgres.TempVolume(cluster) {
+		tempVolumeMount := postgres.TempVolumeMount()
+		volumes = append(volumes, corev1.Volume{
+			Name: tempVolumeMount.Name,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+		volumeMounts = append(volumeMounts, tempVolumeMount)
+	}
+
 	restoreJob := &batchv1.Job{}
 	if err := r.generateRestoreJobIntent(cluster, configHash, instanceName, cmd,
 		volumeMounts, volumes, dataSource, restoreJob); err != nil {
@@ -1215,12 +1493,13 @@ func (r *Reconciler) generateRestoreJobIntent(cluster *v1beta1.PostgresCluster,
 	job.Spec = batchv1.JobSpec{
 		Template: corev1.PodTemplateSpec{
 			ObjectMeta: metav1.ObjectMeta{
-				Annotations: annotations,
-				Labels:      labels,
+				Annotations: naming.Merge(annotations,
+					servicemesh.JobAnnotations(cluster)),
+				Labels: labels,
 			},
 			Spec: corev1.PodSpec{
 				Containers: []corev1.Container{{
-					Command:         cmd,
+					Command:         servicemesh.JobCommand(cluster, cmd),
 					Image:           config.PostgresContainerImage(cluster),
 					ImagePullPolicy: cluster.Spec.ImagePullPolicy,
 					Name:            naming.PGBackRestRestoreContainerName,
@@ -1256,6 +1535,8 @@ func (r *Reconciler) generateRestoreJobIntent(cluster *v1beta1.PostgresCluster,
 
 	job.Spec.Template.Spec.SecurityContext = postgres.PodSecurityContext(cluster)
 
+	addJobLimits(cluster, &job.Spec)
+
 	// set the priority class name, if it exists
 	if dataSource.PriorityClassName != nil {
 		job.Spec.Template.Spec.PriorityClassName = *dataSource.PriorityClassName
@@ -1398,6 +1679,23 @@ func (r *Reconciler) reconcilePGBackRest(ctx context.Context,
 		result = updateReconcileResult(result, reconcile.Result{RequeueAfter: 10 * time.Second})
 	}
 
+	// Resume scheduled backup Jobs that are waiting their turn
+	if next, err := r.reconcileScheduledBackupThrottle(ctx, postgresCluster,
+		instances); err != nil {
+		log.Error(err, "unable to resume scheduled backups")
+		result = updateReconcileResult(result, reconcile.Result{Requeue: true})
+	} else {
+		result = updateReconcileResult(result, next)
+	}
+
+	// Record details of scheduled backups before their Jobs are deleted
+	if err := r.reconcileScheduledBackupDetails(ctx, postgresCluster, instances); err != nil {
+		log.Error(err, "unable to observe scheduled backups")
+	}
+
+	// Record the most recent backup in each repository as last read from pgBackRest
+	r.setRepoBackupStatus(postgresCluster)
+
 	// Reconcile the initial backup that is needed to enable replica creation using pgBackRest.
 	// This is done once stanza creation is successful
 	if err := r.reconcileReplicaCreateBackup(ctx, postgresCluster, instances,
@@ -1414,6 +1712,26 @@ func (r *Reconciler) reconcilePGBackRest(ctx context.Context,
 		result = updateReconcileResult(result, reconcile.Result{Requeue: true})
 	}
 
+	// Reconcile the full backup that begins a repo migration as defined in the spec
+	if waiting, err := r.reconcileRepoMigration(ctx, postgresCluster,
+		repoResources, sa, instances); err != nil {
+		log.Error(err, "unable to reconcile repo migration")
+		result = updateReconcileResult(result, reconcile.Result{Requeue: true})
+	} else if waiting {
+		// Jobs of backup CronJobs do not trigger a reconcile when they finish.
+		result = updateReconcileResult(result, reconcile.Result{RequeueAfter: time.Minute})
+	}
+
+	// Reconcile the backup that is taken before disruptive changes
+	if waiting, err := r.reconcileChangeProtectionBackup(ctx, postgresCluster,
+		repoResources, sa, instances, replicaCreateRepo); err != nil {
+		log.Error(err, "unable to reconcile change protection backup")
+		result = updateReconcileResult(result, reconcile.Result{Requeue: true})
+	} else if waiting {
+		// Jobs of backup CronJobs do not trigger a reconcile when they finish.
+		result = updateReconcileResult(result, reconcile.Result{RequeueAfter: time.Minute})
+	}
+
 	return result, nil
 }
 
@@ -1669,6 +1987,8 @@ func (r *Reconciler) reconcileCloudBasedDataSource(ctx context.Context,
 	tmpDataSource := &v1beta1.PostgresClusterDataSource{
 		RepoName:          dataSource.Repo.Name,
 		Options:           dataSource.Options,
+		Databases:         dataSource.Databases,
+		RestorePoint:      dataSource.RestorePoint,
 		Resources:         dataSource.Resources,
 		Affinity:          dataSource.Affinity,
 		Tolerations:       dataSource.Tolerations,
@@ -2306,6 +2626,22 @@ func (r *Reconciler) reconcileManualBackup(ctx context.Context,
 	backupJob.ObjectMeta.Labels = labels
 	backupJob.ObjectMeta.Annotations = annotations
 
+	// pgBackRest takes incremental backups when no type is specified. Leave
+	// "backup-standby" alone when the user has set it.
+	backupType, userStandby := incremental, false
+	for _, opt := range backupOpts {
+		if strings.HasPrefix(opt, "--type=") {
+			backupType = strings.TrimPrefix(opt, "--type=")
+		}
+		if strings.Contains(opt, "backup-standby") {
+			userStandby = true
+		}
+	}
+
+	if !userStandby {
+		backupOpts = append(backupStandbyOptions(postgresCluster, repo, backupType), backupOpts...)
+	}
+
 	spec, err := generateBackupJobSpecIntent(postgresCluster, repo,
 		serviceAccount.GetName(), labels, annotations, backupOpts...)
 	if err != nil {
@@ -2330,6 +2666,169 @@ func (r *Reconciler) reconcileManualBackup(ctx context.Context,
 
 // +kubebuilder:rbac:groups="batch",resources="jobs",verbs={create,patch,delete}
 
+// reconcileRepoMigration is responsible for the full backup that moves a PostgresCluster from
+// one pgBackRest repo to another.  pgBackRest cannot copy existing backups between repos, so a
+// new full backup is taken in the new repo while the volume of the old repo is kept.  Once the
+// retention of the new repo covers the period needed for recovery, the old repo and then the
+// migration can be removed from the spec.  It returns true when the backup is waiting for
+// another backup, scheduled or manual, to finish.
+func (r *Reconciler) reconcileRepoMigration(ctx context.Context,
+	postgresCluster *v1beta1.PostgresCluster, repoResources *RepoResources,
+	serviceAccount *corev1.ServiceAccount, instances *observedInstances) (bool, error) {
+
+	migration := postgresCluster.Spec.Backups.PGBackRest.RepoMigration
+	if migration == nil {
+		postgresCluster.Status.PGBackRest.RepoMigration = nil
+		return false, nil
+	}
+
+	// reset the status when a different migration is requested
+	migrationStatus := postgresCluster.Status.PGBackRest.RepoMigration
+	if migrationStatus == nil ||
+		migrationStatus.From != migration.From || migrationStatus.To != migration.To {
+		migrationStatus = &v1beta1.PGBackRestRepoMigrationStatus{
+			From: migration.From,
+			To:   migration.To,
+		}
+		postgresCluster.Status.PGBackRest.RepoMigration = migrationStatus
+	}
+
+	// Update status according to the Job of the current migration, and delete the Jobs of any
+	// previous migrations.
+	var currentJob *batchv1.Job
+	for _, job := range repoResources.repoMigrationJobs {
+		if job.GetLabels()[naming.LabelPGBackRestRepo] != migration.To {
+			if err := r.Client.Delete(ctx, job,
+				client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+				return false, errors.WithStack(client.IgnoreNotFound(err))
+			}
+			continue
+		}
+		currentJob = job
+	}
+	if currentJob != nil {
+		if jobCompleted(currentJob) && !migrationStatus.Finished {
+			migrationStatus.Finished = true
+			migrationStatus.CompletionTime = currentJob.Status.CompletionTime
+			r.Recorder.Eventf(postgresCluster, corev1.EventTypeNormal, "RepoMigrated",
+				"Full backup completed in %q. Remove %q from the spec once the retention of %q "+
+					"covers the period needed for recovery.",
+				migration.To, migration.From, migration.To)
+		}
+		if jobFailed(currentJob) {
+			r.Recorder.Eventf(postgresCluster, corev1.EventTypeWarning, "RepoMigrationFailed",
+				"Full backup did not complete in %q. Delete Job %q to try again.",
+				migration.To, currentJob.GetName())
+		}
+		return false, nil
+	}
+	if migrationStatus.Finished {
+		return false, nil
+	}
+
+	if migration.From == migration.To {
+		r.Recorder.Eventf(postgresCluster, corev1.EventTypeWarning, "InvalidRepoMigration",
+			"Unable to migrate %q to itself.", migration.From)
+		return false, nil
+	}
+
+	var repo v1beta1.PGBackRestRepo
+	for i := range postgresCluster.Spec.Backups.PGBackRest.Repos {
+		if postgresCluster.Spec.Backups.PGBackRest.Repos[i].Name == migration.To {
+			repo = postgresCluster.Spec.Backups.PGBackRest.Repos[i]
+		}
+	}
+	if repo.Name == "" {
+		r.Recorder.Eventf(postgresCluster, corev1.EventTypeWarning, "InvalidRepoMigration",
+			"Unable to find %q as configured for a repo migration.  Please ensure "+
+				"this repo is defined in the spec.", migration.To)
+		return false, nil
+	}
+
+	// pgBackRest connects to a PostgreSQL instance that is not in recovery to
+	// initiate a backup.
+	clusterWritable := false
+	for _, instance := range instances.forCluster {
+		writable, known := instance.IsWritable()
+		if writable && known {
+			clusterWritable = true
+			break
+		}
+	}
+	if !clusterWritable {
+		return false, nil
+	}
+
+	// determine if the dedicated repository host is ready (if enabled) using the repo host ready
+	// condition, and return if not
+	if pgbackrest.DedicatedRepoHostEnabled(postgresCluster) {
+		condition := meta.FindStatusCondition(postgresCluster.Status.Conditions, ConditionRepoHostReady)
+		if condition == nil || condition.Status != metav1.ConditionTrue {
+			return false, nil
+		}
+	}
+
+	// Wait for the replica create backup since only one backup can be run at a time.
+	condition := meta.FindStatusCondition(postgresCluster.Status.Conditions,
+		ConditionReplicaCreate)
+	if condition == nil || condition.Status != metav1.ConditionTrue {
+		return false, nil
+	}
+
+	// Likewise, wait for any manual or scheduled backup that is running.
+	for _, jobs := range [][]*batchv1.Job{
+		repoResources.manualBackupJobs, repoResources.scheduledBackupJobs,
+	} {
+		for _, job := range jobs {
+			if !jobCompleted(job) && !jobFailed(job) {
+				return true, nil
+			}
+		}
+	}
+
+	// wait for a stanza in the new repo
+	var stanzaCreated bool
+	for _, repoStatus := range postgresCluster.Status.PGBackRest.Repos {
+		if repoStatus.Name == migration.To {
+			stanzaCreated = repoStatus.StanzaCreated
+		}
+	}
+	if !stanzaCreated {
+		return false, nil
+	}
+
+	backupJob := &batchv1.Job{}
+	backupJob.ObjectMeta = naming.PGBackRestRepoMigrationJob(postgresCluster, migration.To)
+
+	var labels, annotations map[string]string
+	labels = naming.Merge(postgresCluster.Spec.Metadata.GetLabelsOrNil(),
+		postgresCluster.Spec.Backups.PGBackRest.Metadata.GetLabelsOrNil(),
+		naming.PGBackRestBackupJobLabels(postgresCluster.GetName(), migration.To,
+			naming.BackupRepoMigration))
+	annotations = naming.Merge(postgresCluster.Spec.Metadata.GetAnnotationsOrNil(),
+		postgresCluster.Spec.Backups.PGBackRest.Metadata.GetAnnotationsOrNil())
+	backupJob.ObjectMeta.Labels = labels
+	backupJob.ObjectMeta.Annotations = annotations
+
+	spec, err := generateBackupJobSpecIntent(postgresCluster, repo,
+		serviceAccount.GetName(), labels, annotations, "--type=full")
+	if err != nil {
+		return false, errors.WithStack(err)
+	}
+	backupJob.Spec = *spec
+
+	// set gvk and ownership refs
+	backupJob.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("Job"))
+	if err := controllerutil.SetControllerReference(postgresCluster, backupJob,
+		r.Client.Scheme()); err != nil {
+		return false, errors.WithStack(err)
+	}
+
+	return false, errors.WithStack(r.apply(ctx, backupJob))
+}
+
+// +kubebuilder:rbac:groups="batch",resources="jobs",verbs={create,patch,delete}
+
 // reconcileReplicaCreateBackup is responsible for reconciling a full pgBackRest backup for the
 // cluster as required to create replicas
 func (r *Reconciler) reconcileReplicaCreateBackup(ctx context.Context,
@@ -2746,6 +3245,7 @@ func getRepoVolumeStatus(repoStatus []v1beta1.RepoStatus, repoVolumes []*corev1.
 				if rs.VolumeName != "" && rs.VolumeName != rv.Spec.VolumeName {
 					rs.StanzaCreated = false
 					rs.ReplicaCreateBackupComplete = false
+					rs.FullBackupWAL = nil
 				}
 				rs.VolumeName = rv.Spec.VolumeName
 
@@ -2788,6 +3288,7 @@ func getRepoVolumeStatus(repoStatus []v1beta1.RepoStatus, repoVolumes []*corev1.
 					rs.RepoOptionsHash = hash
 					rs.StanzaCreated = false
 					rs.ReplicaCreateBackupComplete = false
+					rs.FullBackupWAL = nil
 				}
 
 				updatedRepoStatus = append(updatedRepoStatus, rs)
@@ -2851,6 +3352,54 @@ func (r *Reconciler) reconcileScheduledBackups(
 	return requeue
 }
 
+// backupScheduleTimeZone returns the time zone in which the backup schedules
+// of repo are interpreted: that of its schedules, otherwise that of cluster. It
+// returns an error when the time zone is unknown or when schedule already
+// names a time zone.
+// - https://docs.k8s.io/concepts/workloads/controllers/cron-jobs/#time-zones
+func backupScheduleTimeZone(
+	cluster *v1beta1.PostgresCluster, repo v1beta1.PGBackRestRepo, schedule string,
+) (*string, error) {
+	timeZone := cronJobTimeZone(cluster)
+	if repo.BackupSchedules != nil && repo.BackupSchedules.TimeZone != "" {
+		timeZone = initialize.String(repo.BackupSchedules.TimeZone)
+	}
+	if timeZone == nil {
+		return nil, nil
+	}
+
+	// Kubernetes rejects CronJobs with both.
+	if strings.Contains(schedule, "TZ=") {
+		return nil, fmt.Errorf("schedule %q cannot name a time zone when timeZone is %q",
+			schedule, *timeZone)
+	}
+
+	// The kube-controller-manager loads time zones in the same way.
+	if _, err := time.LoadLocation(*timeZone); err != nil {
+		return nil, err
+	}
+	return timeZone, nil
+}
+
+// backupStandbyOptions returns the pgBackRest options that have a backup of
+// backupType read from a replica, if any. Only full and differential backups
+// to "volume" repositories do this: their Jobs run on the repository host, the
+// only place where pgBackRest is configured with every PostgreSQL instance.
+// pgBackRest decides when the backup starts: it reads from the primary when no
+// replica is ready. The options do not depend on the state of instances, so
+// neither do the Jobs and CronJobs that use them.
+// - https://pgbackrest.org/configuration.html#section-backup/option-backup-standby
+func backupStandbyOptions(cluster *v1beta1.PostgresCluster,
+	repo v1beta1.PGBackRestRepo, backupType string,
+) []string {
+	standby := cluster.Spec.Backups.PGBackRest.BackupStandby
+	if standby == nil || !*standby || repo.Volume == nil ||
+		(backupType != full && backupType != differential) {
+		return nil
+	}
+	return []string{"--backup-standby=prefer"}
+}
+
 // +kubebuilder:rbac:groups="batch",resources="cronjobs",verbs={create,patch}
 
 // reconcilePGBackRestCronJob creates the CronJob for the given repo, pgBackRest
@@ -2931,8 +3480,17 @@ func (r *Reconciler) reconcilePGBackRestCronJob(
 		return nil
 	}
 
+	// Schedules are interpreted in a time zone that Kubernetes must recognize.
+	timeZone, err := backupScheduleTimeZone(cluster, repo, *schedule)
+	if err != nil {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidTimeZone",
+			"Unable to schedule %s backups for %q: %v", backupType, repo.Name, err)
+		return nil
+	}
+
 	// set backup type (i.e. "full", "diff", "incr")
 	backupOpts := []string{"--type=" + backupType}
+	backupOpts = append(backupOpts, backupStandbyOptions(cluster, repo, backupType)...)
 
 	jobSpec, err := generateBackupJobSpecIntent(cluster, repo,
 		serviceAccount.GetName(), labels, annotations, backupOpts...)
@@ -2946,12 +3504,23 @@ func (r *Reconciler) reconcilePGBackRestCronJob(
 	suspend := (cluster.Spec.Shutdown != nil && *cluster.Spec.Shutdown) ||
 		(cluster.Spec.Standby != nil && cluster.Spec.Standby.Enabled)
 
+	// Jobs wait for the operator to resume them when scheduled backups are throttled.
+	if r.BackupThrottle.enabled() {
+		jobSpec.Suspend = initialize.Bool(true)
+	}
+
+	successfulLimit, failedLimit := jobHistoryLimits(cluster)
+
 	pgBackRestCronJob := &batchv1.CronJob{
 		ObjectMeta: objectmeta,
 		Spec: batchv1.CronJobSpec{
 			Schedule:          *schedule,
 			Suspend:           &suspend,
+			TimeZone:          timeZone,
 			ConcurrencyPolicy: batchv1.ForbidConcurrent,
+
+			SuccessfulJobsHistoryLimit: &successfulLimit,
+			FailedJobsHistoryLimit:     &failedLimit,
 			JobTemplate: batchv1.JobTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
 					Annotations: annotations,
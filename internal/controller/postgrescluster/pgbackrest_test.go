@@ -46,7 +46,9 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -2714,6 +2716,35 @@ volumes:
 				assert.Equal(t, *spec.TTLSecondsAfterFinished, int32(100))
 			}
 		})
+
+		t.Run("Cluster", func(t *testing.T) {
+			cluster := cluster.DeepCopy()
+			cluster.Spec.Backups.PGBackRest.Jobs = nil
+			cluster.Spec.Jobs = &v1beta1.JobsSpec{
+				ActiveDeadlineSeconds:   initialize.Int64(600),
+				BackoffLimit:            initialize.Int32(1),
+				TTLSecondsAfterFinished: initialize.Int32(300),
+			}
+
+			spec, err := generateBackupJobSpecIntent(
+				cluster, v1beta1.PGBackRestRepo{}, "", nil, nil,
+			)
+			assert.NilError(t, err)
+			assert.Equal(t, *spec.ActiveDeadlineSeconds, int64(600))
+			assert.Equal(t, *spec.BackoffLimit, int32(1))
+			assert.Equal(t, *spec.TTLSecondsAfterFinished, int32(300))
+
+			// The TTL of backup Jobs takes precedence.
+			cluster.Spec.Backups.PGBackRest.Jobs = &v1beta1.BackupJobs{
+				TTLSecondsAfterFinished: initialize.Int32(100),
+			}
+
+			spec, err = generateBackupJobSpecIntent(
+				cluster, v1beta1.PGBackRestRepo{}, "", nil, nil,
+			)
+			assert.NilError(t, err)
+			assert.Equal(t, *spec.TTLSecondsAfterFinished, int32(100))
+		})
 	})
 }
 
@@ -3786,4 +3817,386 @@ func TestSetScheduledJobStatus(t *testing.T) {
 		r.setScheduledJobStatus(ctx, postgresCluster, uList.Items)
 		assert.Assert(t, len(postgresCluster.Status.PGBackRest.ScheduledBackups) == 0)
 	})
+
+	t.Run("keep status of deleted jobs", func(t *testing.T) {
+		postgresCluster := fakePostgresCluster(clusterName, ns.GetName(), clusterUID, true)
+		postgresCluster.Spec.Backups.PGBackRest.JobHistoryLimit = &v1beta1.PGBackRestJobHistoryLimit{
+			Successful: initialize.Int32(2),
+		}
+
+		start := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		finish := metav1.NewTime(start.Add(90 * time.Second))
+		earlier := metav1.NewTime(start.Add(-time.Hour))
+		postgresCluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{
+			ScheduledBackups: []v1beta1.PGBackRestScheduledBackupStatus{
+				{JobName: "current", CronJobName: "daily", BackupLabel: "20240101-000000F"},
+				{JobName: "old", CronJobName: "daily", StartTime: &earlier, CompletionTime: &earlier},
+				{JobName: "older", CronJobName: "daily", StartTime: &earlier, CompletionTime: &earlier},
+				{JobName: "broken", CronJobName: "daily", FailureMessage: "BackoffLimitExceeded"},
+				{JobName: "running", CronJobName: "daily", Active: 1},
+			},
+		}
+
+		testJob := &batchv1.Job{
+			TypeMeta: metav1.TypeMeta{Kind: "Job"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "current",
+				Labels:          map[string]string{"postgres-operator.crunchydata.com/pgbackrest-cronjob": "full"},
+				OwnerReferences: []metav1.OwnerReference{{Name: "daily"}},
+			},
+			Status: batchv1.JobStatus{
+				StartTime: &start, CompletionTime: &finish, Succeeded: 1,
+			},
+		}
+		unstructuredObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(testJob)
+		assert.NilError(t, err)
+
+		r.setScheduledJobStatus(ctx, postgresCluster,
+			[]unstructured.Unstructured{{Object: unstructuredObj}})
+
+		status := postgresCluster.Status.PGBackRest.ScheduledBackups
+		assert.Equal(t, len(status), 3)
+
+		// Details of the existing Job are kept.
+		assert.Equal(t, status[0].JobName, "current")
+		assert.Equal(t, status[0].BackupLabel, "20240101-000000F")
+		assert.Equal(t, status[0].Duration.Duration, 90*time.Second)
+
+		// One successful Job is kept beside it, then the failed one.
+		// Jobs that never finished are dropped.
+		assert.Equal(t, status[1].JobName, "older")
+		assert.Equal(t, status[2].JobName, "broken")
+	})
+
+	t.Run("record why a job failed", func(t *testing.T) {
+		postgresCluster := fakePostgresCluster(clusterName, ns.GetName(), clusterUID, true)
+
+		testJob := &batchv1.Job{
+			TypeMeta: metav1.TypeMeta{Kind: "Job"},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "failed",
+				Labels: map[string]string{"postgres-operator.crunchydata.com/pgbackrest-cronjob": "full"},
+			},
+			Status: batchv1.JobStatus{
+				Failed: 1,
+				Conditions: []batchv1.JobCondition{{
+					Type: batchv1.JobFailed, Status: corev1.ConditionTrue,
+					Reason:  "BackoffLimitExceeded",
+					Message: "Job has reached the specified backoff limit",
+				}},
+			},
+		}
+		unstructuredObj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(testJob)
+		assert.NilError(t, err)
+
+		r.setScheduledJobStatus(ctx, postgresCluster,
+			[]unstructured.Unstructured{{Object: unstructuredObj}})
+
+		status := postgresCluster.Status.PGBackRest.ScheduledBackups
+		assert.Equal(t, len(status), 1)
+		assert.Equal(t, status[0].FailureMessage,
+			"BackoffLimitExceeded: Job has reached the specified backoff limit")
+		assert.Assert(t, status[0].Duration == nil)
+	})
+}
+
+func TestJobHistoryLimits(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+
+	successful, failed := jobHistoryLimits(cluster)
+	assert.Equal(t, successful, int32(3))
+	assert.Equal(t, failed, int32(1))
+
+	cluster.Spec.Backups.PGBackRest.JobHistoryLimit = &v1beta1.PGBackRestJobHistoryLimit{
+		Failed: initialize.Int32(5),
+	}
+	successful, failed = jobHistoryLimits(cluster)
+	assert.Equal(t, successful, int32(3))
+	assert.Equal(t, failed, int32(5))
+
+	cluster.Spec.Backups.PGBackRest.JobHistoryLimit.Successful = initialize.Int32(0)
+	successful, _ = jobHistoryLimits(cluster)
+	assert.Equal(t, successful, int32(0))
+}
+
+func TestSetScheduledBackupDetails(t *testing.T) {
+	start := metav1.NewTime(time.Unix(1704067200, 0))
+	finish := metav1.NewTime(time.Unix(1704067300, 0))
+
+	backup := func(repo int, kind, label string, begin, end, size int64) pgbackrest.InfoBackup {
+		var b pgbackrest.InfoBackup
+		b.Database.RepoKey = repo
+		b.Info.Size = size
+		b.Label = label
+		b.Timestamp.Start, b.Timestamp.Stop = begin, end
+		b.Type = kind
+		return b
+	}
+	backups := []pgbackrest.InfoBackup{
+		backup(1, "full", "20231231-000000F", 1703980800, 1703980900, 100),
+		backup(1, "full", "20240101-000010F", 1704067210, 1704067290, 200),
+		backup(2, "full", "20240101-000020F", 1704067220, 1704067280, 300),
+		backup(1, "incr", "20240101-000010F_20240101-000030I", 1704067230, 1704067240, 400),
+	}
+
+	status := []v1beta1.PGBackRestScheduledBackupStatus{
+		{RepoName: "repo1", Type: "full", StartTime: &start, CompletionTime: &finish},
+		{RepoName: "repo2", Type: "full", StartTime: &start, CompletionTime: &finish},
+		{RepoName: "repo1", Type: "diff", StartTime: &start, CompletionTime: &finish},
+		{RepoName: "repo1", Type: "full", StartTime: &start},
+		{RepoName: "repo1", Type: "full", StartTime: &start, CompletionTime: &finish,
+			BackupLabel: "unchanged"},
+	}
+	setScheduledBackupDetails(status, backups)
+
+	assert.Equal(t, status[0].BackupLabel, "20240101-000010F")
+	assert.Equal(t, *status[0].Size, int64(200))
+	assert.Equal(t, status[1].BackupLabel, "20240101-000020F")
+	assert.Equal(t, *status[1].Size, int64(300))
+
+	// No backup of that type, not finished, or already known.
+	assert.Equal(t, status[2].BackupLabel, "")
+	assert.Equal(t, status[3].BackupLabel, "")
+	assert.Equal(t, status[4].BackupLabel, "unchanged")
+	assert.Assert(t, status[4].Size == nil)
+}
+
+func TestBackupStandbyOptions(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	volume := v1beta1.PGBackRestRepo{Name: "repo1", Volume: &v1beta1.RepoPVC{}}
+	cloud := v1beta1.PGBackRestRepo{Name: "repo2", S3: &v1beta1.RepoS3{}}
+
+	// Nothing unless the spec asks for it.
+	assert.Assert(t, backupStandbyOptions(cluster, volume, full) == nil)
+
+	// pgBackRest falls back to the primary when no replica is ready.
+	cluster.Spec.Backups.PGBackRest.BackupStandby = initialize.Bool(true)
+	assert.DeepEqual(t, backupStandbyOptions(cluster, volume, full),
+		[]string{"--backup-standby=prefer"})
+	assert.DeepEqual(t, backupStandbyOptions(cluster, volume, differential),
+		[]string{"--backup-standby=prefer"})
+
+	// Incremental backups and cloud repositories read from the primary.
+	assert.Assert(t, backupStandbyOptions(cluster, volume, incremental) == nil)
+	assert.Assert(t, backupStandbyOptions(cluster, cloud, full) == nil)
+}
+
+func TestResizeRepoVolume(t *testing.T) {
+	volume := func(storage, class string) corev1.PersistentVolumeClaimSpec {
+		spec := corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: initialize.String(class),
+		}
+		spec.Resources.Requests = corev1.ResourceList{
+			corev1.ResourceStorage: resource.MustParse(storage),
+		}
+		return spec
+	}
+	existing := volume("1Gi", "fast")
+
+	t.Run("Grow", func(t *testing.T) {
+		spec, unchanged := resizeRepoVolume(existing, volume("2Gi", "fast"))
+		assert.Assert(t, len(unchanged) == 0)
+		assert.DeepEqual(t, spec, volume("2Gi", "fast"))
+	})
+
+	t.Run("Shrink", func(t *testing.T) {
+		wanted := volume("500Mi", "slow")
+		spec, unchanged := resizeRepoVolume(existing, wanted)
+		assert.DeepEqual(t, unchanged, []string{"storage request", "storageClassName"})
+		assert.DeepEqual(t, spec, existing)
+
+		// The wanted spec is not modified.
+		assert.DeepEqual(t, wanted, volume("500Mi", "slow"))
+	})
+
+	t.Run("AccessModes", func(t *testing.T) {
+		wanted := volume("1Gi", "fast")
+		wanted.AccessModes = []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}
+		spec, unchanged := resizeRepoVolume(existing, wanted)
+		assert.DeepEqual(t, unchanged, []string{"accessModes"})
+		assert.DeepEqual(t, spec, existing)
+	})
+}
+
+func TestReconcileRepoMigration(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+	cluster.Spec.Backups.PGBackRest.Repos = []v1beta1.PGBackRestRepo{
+		{Name: "repo1", Volume: &v1beta1.RepoPVC{}},
+		{Name: "repo2", S3: &v1beta1.RepoS3{}},
+	}
+
+	job := func(repoName string, condition batchv1.JobConditionType) *batchv1.Job {
+		job := &batchv1.Job{ObjectMeta: naming.PGBackRestRepoMigrationJob(cluster, repoName)}
+		job.Labels = naming.PGBackRestBackupJobLabels(cluster.Name, repoName,
+			naming.BackupRepoMigration)
+		job.Status.Conditions = []batchv1.JobCondition{{
+			Type: condition, Status: corev1.ConditionTrue,
+		}}
+		job.Status.CompletionTime = &metav1.Time{Time: time.Unix(1700000000, 0)}
+		return job
+	}
+
+	t.Run("Unset", func(t *testing.T) {
+		recorder := record.NewFakeRecorder(10)
+		r := &Reconciler{Client: fake.NewClientBuilder().Build(), Recorder: recorder}
+
+		cluster := cluster.DeepCopy()
+		cluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{
+			RepoMigration: &v1beta1.PGBackRestRepoMigrationStatus{From: "repo1", To: "repo2"},
+		}
+
+		_, err := r.reconcileRepoMigration(ctx, cluster, &RepoResources{}, nil, nil)
+		assert.NilError(t, err)
+		assert.Assert(t, cluster.Status.PGBackRest.RepoMigration == nil)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		recorder := record.NewFakeRecorder(10)
+		r := &Reconciler{Client: fake.NewClientBuilder().Build(), Recorder: recorder}
+
+		cluster := cluster.DeepCopy()
+		cluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{}
+		cluster.Spec.Backups.PGBackRest.RepoMigration = &v1beta1.PGBackRestRepoMigration{
+			From: "repo1", To: "repo1",
+		}
+
+		_, err := r.reconcileRepoMigration(ctx, cluster, &RepoResources{}, nil, nil)
+		assert.NilError(t, err)
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Assert(t, strings.Contains(<-recorder.Events, "InvalidRepoMigration"))
+
+		cluster.Spec.Backups.PGBackRest.RepoMigration.To = "repo3"
+		_, err = r.reconcileRepoMigration(ctx, cluster, &RepoResources{}, nil, nil)
+		assert.NilError(t, err)
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Assert(t, strings.Contains(<-recorder.Events, "InvalidRepoMigration"))
+
+		assert.DeepEqual(t, cluster.Status.PGBackRest.RepoMigration,
+			&v1beta1.PGBackRestRepoMigrationStatus{From: "repo1", To: "repo3"})
+	})
+
+	t.Run("Completed", func(t *testing.T) {
+		previous := job("repo3", batchv1.JobComplete)
+		recorder := record.NewFakeRecorder(10)
+		r := &Reconciler{
+			Client:   fake.NewClientBuilder().WithObjects(previous).Build(),
+			Recorder: recorder,
+		}
+
+		cluster := cluster.DeepCopy()
+		cluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{}
+		cluster.Spec.Backups.PGBackRest.RepoMigration = &v1beta1.PGBackRestRepoMigration{
+			From: "repo1", To: "repo2",
+		}
+
+		jobs := []*batchv1.Job{previous, job("repo2", batchv1.JobComplete)}
+		_, err := r.reconcileRepoMigration(ctx, cluster,
+			&RepoResources{repoMigrationJobs: jobs}, nil, nil)
+		assert.NilError(t, err)
+
+		status := cluster.Status.PGBackRest.RepoMigration
+		assert.Assert(t, status.Finished)
+		assert.Assert(t, status.CompletionTime != nil)
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Assert(t, strings.Contains(<-recorder.Events, "RepoMigrated"))
+
+		// The Job of another migration is deleted.
+		err = r.Client.Get(ctx, client.ObjectKeyFromObject(previous), &batchv1.Job{})
+		assert.Assert(t, apierrors.IsNotFound(err), "got %#v", err)
+
+		// The event happens once.
+		_, err = r.reconcileRepoMigration(ctx, cluster,
+			&RepoResources{repoMigrationJobs: jobs[1:]}, nil, nil)
+		assert.NilError(t, err)
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		recorder := record.NewFakeRecorder(10)
+		r := &Reconciler{Client: fake.NewClientBuilder().Build(), Recorder: recorder}
+
+		cluster := cluster.DeepCopy()
+		cluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{}
+		cluster.Spec.Backups.PGBackRest.RepoMigration = &v1beta1.PGBackRestRepoMigration{
+			From: "repo1", To: "repo2",
+		}
+
+		jobs := []*batchv1.Job{job("repo2", batchv1.JobFailed)}
+		_, err := r.reconcileRepoMigration(ctx, cluster,
+			&RepoResources{repoMigrationJobs: jobs}, nil, nil)
+		assert.NilError(t, err)
+		assert.Assert(t, !cluster.Status.PGBackRest.RepoMigration.Finished)
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Assert(t, strings.Contains(<-recorder.Events, "RepoMigrationFailed"))
+	})
+
+	t.Run("WaitsForBackups", func(t *testing.T) {
+		r := &Reconciler{Client: fake.NewClientBuilder().Build(), Recorder: record.NewFakeRecorder(10)}
+
+		cluster := cluster.DeepCopy()
+		cluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{
+			Repos: []v1beta1.RepoStatus{{Name: "repo2", StanzaCreated: true}},
+		}
+		cluster.Spec.Backups.PGBackRest.RepoMigration = &v1beta1.PGBackRestRepoMigration{
+			From: "repo1", To: "repo2",
+		}
+		for _, condition := range []string{ConditionRepoHostReady, ConditionReplicaCreate} {
+			meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+				Type: condition, Status: metav1.ConditionTrue, Reason: "testing",
+			})
+		}
+
+		primary := &corev1.Pod{}
+		primary.Annotations = map[string]string{"status": `{"role":"master"}`}
+		instances := &observedInstances{forCluster: []*Instance{{Pods: []*corev1.Pod{primary}}}}
+
+		running := &batchv1.Job{}
+		running.Name = "hippo-repo1-full-12345"
+		running.Labels = naming.PGBackRestCronJobLabels(cluster.Name, "repo1", full)
+
+		waiting, err := r.reconcileRepoMigration(ctx, cluster,
+			&RepoResources{scheduledBackupJobs: []*batchv1.Job{running}}, nil, instances)
+		assert.NilError(t, err)
+		assert.Assert(t, waiting)
+
+		running.Labels = naming.PGBackRestBackupJobLabels(cluster.Name, "repo1", naming.BackupManual)
+		waiting, err = r.reconcileRepoMigration(ctx, cluster,
+			&RepoResources{manualBackupJobs: []*batchv1.Job{running}}, nil, instances)
+		assert.NilError(t, err)
+		assert.Assert(t, waiting)
+
+		var jobs batchv1.JobList
+		assert.NilError(t, r.Client.List(ctx, &jobs))
+		assert.Equal(t, len(jobs.Items), 0)
+	})
+}
+
+func TestBackupScheduleTimeZone(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	repo := v1beta1.PGBackRestRepo{Name: "repo1"}
+
+	timeZone, err := backupScheduleTimeZone(cluster, repo, "TZ=UTC 0 2 * * *")
+	assert.NilError(t, err)
+	assert.Assert(t, timeZone == nil)
+
+	cluster.Spec.TimeZone = "America/New_York"
+	timeZone, err = backupScheduleTimeZone(cluster, repo, "0 2 * * *")
+	assert.NilError(t, err)
+	assert.Equal(t, *timeZone, "America/New_York")
+
+	repo.BackupSchedules = &v1beta1.PGBackRestBackupSchedules{TimeZone: "Europe/Paris"}
+	timeZone, err = backupScheduleTimeZone(cluster, repo, "0 2 * * *")
+	assert.NilError(t, err)
+	assert.Equal(t, *timeZone, "Europe/Paris")
+
+	_, err = backupScheduleTimeZone(cluster, repo, "CRON_TZ=UTC 0 2 * * *")
+	assert.ErrorContains(t, err, "cannot name a time zone")
+
+	repo.BackupSchedules.TimeZone = "Mars/Olympus_Mons"
+	_, err = backupScheduleTimeZone(cluster, repo, "0 2 * * *")
+	assert.ErrorContains(t, err, "Mars/Olympus_Mons")
 }
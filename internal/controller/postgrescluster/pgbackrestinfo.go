@@ -0,0 +1,263 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/pgbackrest"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// These gauges export the most recent pgBackRest backup of every PostgresCluster.
+var (
+	pgBackRestLastBackupTime = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "postgrescluster",
+		Name:      "pgbackrest_last_backup_timestamp_seconds",
+		Help: "The Unix time at which the most recent pgBackRest backup of each type finished. " +
+			"Subtract it from time() for the age of the backup.",
+	}, []string{"namespace", "name", "repo", "type"})
+	pgBackRestLastBackupSize = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "postgrescluster",
+		Name:      "pgbackrest_last_backup_size_bytes",
+		Help:      "The size of the database in the most recent pgBackRest backup of each type.",
+	}, []string{"namespace", "name", "repo", "type"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(pgBackRestLastBackupTime, pgBackRestLastBackupSize)
+}
+
+// pgBackRestInfoCache holds the output of "pgbackrest info" for every
+// PostgresCluster. It is refreshed periodically by a goroutine so that
+// reconciling a cluster does not need to ask pgBackRest each time.
+type pgBackRestInfoCache struct {
+	interval time.Duration
+
+	mutex sync.Mutex
+	infos map[client.ObjectKey]pgBackRestInfo
+}
+
+// pgBackRestInfo is the output of "pgbackrest info" and when it was read.
+type pgBackRestInfo struct {
+	backups  []pgbackrest.InfoBackup
+	observed time.Time
+}
+
+// get returns the backups of cluster read after since, if any.
+func (c *pgBackRestInfoCache) get(cluster client.ObjectKey, since time.Time) ([]pgbackrest.InfoBackup, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	info, ok := c.infos[cluster]
+	if !ok || !info.observed.After(since) {
+		return nil, false
+	}
+	return info.backups, true
+}
+
+// set stores the backups of cluster and exports the most recent ones as metrics.
+func (c *pgBackRestInfoCache) set(cluster client.ObjectKey, backups []pgbackrest.InfoBackup) {
+	if c == nil {
+		setPGBackRestInfoMetrics(cluster, nil, backups)
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.infos == nil {
+		c.infos = make(map[client.ObjectKey]pgBackRestInfo)
+	}
+	setPGBackRestInfoMetrics(cluster, c.infos[cluster].backups, backups)
+	c.infos[cluster] = pgBackRestInfo{backups: backups, observed: time.Now()}
+}
+
+// retain removes the backups and metrics of every cluster not in keep.
+func (c *pgBackRestInfoCache) retain(keep map[client.ObjectKey]bool) {
+	if c == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for cluster, info := range c.infos {
+		if !keep[cluster] {
+			delete(c.infos, cluster)
+			setPGBackRestInfoMetrics(cluster, info.backups, nil)
+		}
+	}
+}
+
+// setPGBackRestInfoMetrics exports the most recent backup of each type in each
+// repository as gauges labeled with the namespace and name of a PostgresCluster.
+// The gauges of previous backups that are no longer reported are removed.
+func setPGBackRestInfoMetrics(cluster client.ObjectKey, previous, backups []pgbackrest.InfoBackup) {
+	values := func(backup pgbackrest.InfoBackup) []string {
+		return []string{cluster.Namespace, cluster.Name,
+			"repo" + strconv.Itoa(backup.Database.RepoKey), backup.Type}
+	}
+
+	for _, backup := range previous {
+		pgBackRestLastBackupTime.DeleteLabelValues(values(backup)...)
+		pgBackRestLastBackupSize.DeleteLabelValues(values(backup)...)
+	}
+
+	// Backups are oldest first, so later ones replace earlier ones.
+	for _, backup := range backups {
+		pgBackRestLastBackupTime.WithLabelValues(values(backup)...).Set(float64(backup.Timestamp.Stop))
+		pgBackRestLastBackupSize.WithLabelValues(values(backup)...).Set(float64(backup.Info.Size))
+	}
+}
+
+// pgBackRestInfo returns the backups of cluster read after since. They come
+// from the cache when it is recent enough and from exec otherwise.
+func (r *Reconciler) pgBackRestInfo(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, exec pgbackrest.Executor, since time.Time,
+) ([]pgbackrest.InfoBackup, error) {
+	key := client.ObjectKeyFromObject(cluster)
+	if backups, ok := r.backupInfo.get(key, since); ok {
+		return backups, nil
+	}
+
+	backups, err := exec.Info(ctx)
+	if err == nil {
+		r.backupInfo.set(key, backups)
+	}
+	return backups, err
+}
+
+// +kubebuilder:rbac:groups="",resources="pods",verbs={list}
+// +kubebuilder:rbac:groups="",resources="pods/exec",verbs={create}
+// +kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={list}
+
+// observePGBackRestInfo reads "pgbackrest info" of every PostgresCluster with a
+// stanza at the interval of the cache until ctx is cancelled.
+func (r *Reconciler) observePGBackRestInfo(ctx context.Context) error {
+	ticker := time.NewTicker(r.backupInfo.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			r.refreshPGBackRestInfo(ctx)
+		}
+	}
+}
+
+// refreshPGBackRestInfo reads "pgbackrest info" of every PostgresCluster with a
+// stanza using its primary. Errors are logged because the cache is refreshed
+// again at the next interval.
+func (r *Reconciler) refreshPGBackRestInfo(ctx context.Context) {
+	log := logging.FromContext(ctx).WithName("pgbackrest-info")
+
+	clusters := &v1beta1.PostgresClusterList{}
+	if err := r.Client.List(ctx, clusters); err != nil {
+		log.Error(err, "unable to list clusters")
+		return
+	}
+
+	keep := make(map[client.ObjectKey]bool, len(clusters.Items))
+	for i := range clusters.Items {
+		cluster := &clusters.Items[i]
+		keep[client.ObjectKeyFromObject(cluster)] = true
+
+		var stanzaCreated bool
+		if cluster.Status.PGBackRest != nil {
+			for _, repo := range cluster.Status.PGBackRest.Repos {
+				stanzaCreated = stanzaCreated || repo.StanzaCreated
+			}
+		}
+		if !stanzaCreated {
+			continue
+		}
+
+		pods := &corev1.PodList{}
+		selector, err := naming.AsSelector(naming.ClusterPrimary(cluster.Name))
+		if err == nil {
+			err = r.Client.List(ctx, pods,
+				client.InNamespace(cluster.Namespace),
+				client.MatchingLabelsSelector{Selector: selector},
+			)
+		}
+		if err != nil {
+			log.Error(err, "unable to find primary", "cluster", client.ObjectKeyFromObject(cluster))
+			continue
+		}
+
+		for j := range pods.Items {
+			pod := &pods.Items[j]
+			instance := &Instance{Pods: []*corev1.Pod{pod}}
+			if running, known := instance.IsRunning(naming.ContainerDatabase); !running || !known {
+				continue
+			}
+
+			exec := func(_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string) error {
+				return r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase, stdin, stdout, stderr, command...)
+			}
+			if backups, err := pgbackrest.Executor(exec).Info(ctx); err == nil {
+				r.backupInfo.set(client.ObjectKeyFromObject(cluster), backups)
+			} else {
+				log.Error(err, "unable to read backups", "cluster", client.ObjectKeyFromObject(cluster))
+			}
+			break
+		}
+	}
+
+	r.backupInfo.retain(keep)
+}
+
+// setRepoBackupStatus records the most recent backup in each repository of
+// status using the cached backups of cluster.
+func (r *Reconciler) setRepoBackupStatus(cluster *v1beta1.PostgresCluster) {
+	status := cluster.Status.PGBackRest
+	if status == nil {
+		return
+	}
+	backups, ok := r.backupInfo.get(client.ObjectKeyFromObject(cluster), time.Time{})
+	if !ok {
+		return
+	}
+
+	for i := range status.Repos {
+		index, _ := strconv.Atoi(regexRepoIndex.FindString(status.Repos[i].Name))
+		for _, backup := range backups {
+			if backup.Database.RepoKey == index {
+				completion := metav1.NewTime(time.Unix(backup.Timestamp.Stop, 0))
+				status.Repos[i].LatestBackupLabel = backup.Label
+				status.Repos[i].LatestBackupCompletion = &completion
+			}
+		}
+	}
+}
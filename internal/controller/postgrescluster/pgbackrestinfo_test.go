@@ -0,0 +1,93 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gotest.tools/v3/assert"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestPGBackRestInfo(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+	cluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{
+		Repos: []v1beta1.RepoStatus{{Name: "repo1"}, {Name: "repo2"}},
+	}
+	key := client.ObjectKeyFromObject(cluster)
+
+	var calls int
+	exec := func(_ context.Context, _ io.Reader, stdout, _ io.Writer, command ...string) error {
+		calls++
+		assert.Equal(t, command[1], "info")
+		_, err := stdout.Write([]byte(`[{"backup":[
+			{"database":{"repo-key":1},"info":{"size":10},"label":"one","timestamp":{"stop":100},"type":"full"},
+			{"database":{"repo-key":1},"info":{"size":20},"label":"two","timestamp":{"stop":200},"type":"full"}
+		]}]`))
+		return err
+	}
+
+	r := &Reconciler{backupInfo: &pgBackRestInfoCache{}}
+
+	backups, err := r.pgBackRestInfo(ctx, cluster, exec, time.Time{})
+	assert.NilError(t, err)
+	assert.Equal(t, len(backups), 2)
+	assert.Equal(t, calls, 1)
+
+	assert.Equal(t, testutil.ToFloat64(pgBackRestLastBackupTime.WithLabelValues(
+		key.Namespace, key.Name, "repo1", "full")), float64(200))
+	assert.Equal(t, testutil.ToFloat64(pgBackRestLastBackupSize.WithLabelValues(
+		key.Namespace, key.Name, "repo1", "full")), float64(20))
+
+	t.Run("Cached", func(t *testing.T) {
+		_, err := r.pgBackRestInfo(ctx, cluster, exec, time.Now().Add(-time.Minute))
+		assert.NilError(t, err)
+		assert.Equal(t, calls, 1, "expected no exec")
+
+		_, err = r.pgBackRestInfo(ctx, cluster, exec, time.Now().Add(time.Minute))
+		assert.NilError(t, err)
+		assert.Equal(t, calls, 2, "expected exec after a newer backup")
+	})
+
+	t.Run("Status", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		r.setRepoBackupStatus(cluster)
+
+		repo := cluster.Status.PGBackRest.Repos[0]
+		assert.Equal(t, repo.LatestBackupLabel, "two")
+		assert.Equal(t, repo.LatestBackupCompletion.Unix(), int64(200))
+
+		assert.Equal(t, cluster.Status.PGBackRest.Repos[1].LatestBackupLabel, "")
+		assert.Assert(t, cluster.Status.PGBackRest.Repos[1].LatestBackupCompletion == nil)
+	})
+
+	t.Run("Forget", func(t *testing.T) {
+		r.backupInfo.retain(nil)
+
+		_, ok := r.backupInfo.get(key, time.Time{})
+		assert.Assert(t, !ok)
+		assert.Equal(t, testutil.CollectAndCount(pgBackRestLastBackupTime), 0)
+		assert.Equal(t, testutil.CollectAndCount(pgBackRestLastBackupSize), 0)
+	})
+}
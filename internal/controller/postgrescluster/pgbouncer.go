@@ -35,9 +35,23 @@ import (
 	"github.com/crunchydata/postgres-operator/internal/pgbouncer"
 	"github.com/crunchydata/postgres-operator/internal/pki"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/servicemesh"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
+// pgbouncerReplicas returns the number of PgBouncer Pods to run. None run when
+// the cluster is shut down or is a standby that should not serve connections.
+func pgbouncerReplicas(cluster *v1beta1.PostgresCluster) *int32 {
+	spec := cluster.Spec.Proxy.PGBouncer
+	standby := cluster.Spec.Standby != nil && cluster.Spec.Standby.Enabled
+
+	if (cluster.Spec.Shutdown != nil && *cluster.Spec.Shutdown) ||
+		(standby && (spec.ServeStandby == nil || !*spec.ServeStandby)) {
+		return initialize.Int32(0)
+	}
+	return spec.Replicas
+}
+
 // reconcilePGBouncer writes the objects necessary to run a PgBouncer Pod.
 func (r *Reconciler) reconcilePGBouncer(
 	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
@@ -271,6 +285,10 @@ func (r *Reconciler) generatePGBouncerService(
 		service.Labels = naming.Merge(service.Labels,
 			spec.Metadata.GetLabelsOrNil())
 	}
+	if dns := cluster.Spec.DNS; dns != nil {
+		service.Annotations = naming.Merge(service.Annotations,
+			externalDNSAnnotations(dns, dns.Hostnames.PGBouncer))
+	}
 
 	// add our labels last so they aren't overwritten
 	service.Labels = naming.Merge(service.Labels,
@@ -278,6 +296,9 @@ func (r *Reconciler) generatePGBouncerService(
 			naming.LabelCluster: cluster.Name,
 			naming.LabelRole:    naming.RolePGBouncer,
 		})
+	if cluster.Spec.Standby != nil && cluster.Spec.Standby.Enabled {
+		service.Labels[naming.LabelReadOnly] = "true"
+	}
 
 	// Allocate an IP address and/or node port and let Kubernetes manage the
 	// Endpoints by selecting Pods with the PgBouncer role.
@@ -286,6 +307,7 @@ func (r *Reconciler) generatePGBouncerService(
 		naming.LabelCluster: cluster.Name,
 		naming.LabelRole:    naming.RolePGBouncer,
 	}
+	setServiceIPFamilies(cluster, &service.Spec)
 
 	// The TargetPort must be the name (not the number) of the PgBouncer
 	// ContainerPort. This name allows the port number to differ between Pods,
@@ -380,7 +402,8 @@ func (r *Reconciler) generatePGBouncerDeployment(
 	}
 	deploy.Spec.Template.Annotations = naming.Merge(
 		cluster.Spec.Metadata.GetAnnotationsOrNil(),
-		cluster.Spec.Proxy.PGBouncer.Metadata.GetAnnotationsOrNil())
+		cluster.Spec.Proxy.PGBouncer.Metadata.GetAnnotationsOrNil(),
+		servicemesh.PodAnnotations(cluster))
 	deploy.Spec.Template.Labels = naming.Merge(
 		cluster.Spec.Metadata.GetLabelsOrNil(),
 		cluster.Spec.Proxy.PGBouncer.Metadata.GetLabelsOrNil(),
@@ -389,12 +412,7 @@ func (r *Reconciler) generatePGBouncerDeployment(
 			naming.LabelRole:    naming.RolePGBouncer,
 		})
 
-	// if the shutdown flag is set, set pgBouncer replicas to 0
-	if cluster.Spec.Shutdown != nil && *cluster.Spec.Shutdown {
-		deploy.Spec.Replicas = initialize.Int32(0)
-	} else {
-		deploy.Spec.Replicas = cluster.Spec.Proxy.PGBouncer.Replicas
-	}
+	deploy.Spec.Replicas = pgbouncerReplicas(cluster)
 
 	// Don't clutter the namespace with extra ReplicaSets.
 	deploy.Spec.RevisionHistoryLimit = initialize.Int32(0)
@@ -547,13 +565,12 @@ func (r *Reconciler) reconcilePGBouncerPodDisruptionBudget(
 		// Replicas should always have a value because of defaults in the spec
 		return errors.New("Replicas should be defined")
 	}
-	minAvailable := getMinAvailable(cluster.Spec.Proxy.PGBouncer.MinAvailable,
-		*cluster.Spec.Proxy.PGBouncer.Replicas)
+	replicas := *pgbouncerReplicas(cluster)
+	minAvailable := getMinAvailable(cluster.Spec.Proxy.PGBouncer.MinAvailable, replicas)
 
 	// If 'minAvailable' is set to '0', we will not reconcile the PDB. If one
 	// already exists, we will remove it.
-	scaled, err := intstr.GetScaledValueFromIntOrPercent(minAvailable,
-		int(*cluster.Spec.Proxy.PGBouncer.Replicas), true)
+	scaled, err := intstr.GetScaledValueFromIntOrPercent(minAvailable, int(replicas), true)
 	if err == nil && scaled <= 0 {
 		return deleteExistingPDB(cluster)
 	}
@@ -268,6 +268,29 @@ ownerReferences:
 			assert.Assert(t, specified)
 		})
 	}
+
+	t.Run("Standby", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Standby = &v1beta1.PostgresStandbySpec{Enabled: true}
+
+		service, specified, err := reconciler.generatePGBouncerService(cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, specified)
+		assert.Equal(t, service.Labels["postgres-operator.crunchydata.com/read-only"], "true")
+	})
+
+	t.Run("DNS", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.DNS = &v1beta1.PostgresClusterDNS{}
+		cluster.Spec.DNS.Hostnames.Primary = []string{"primary.example.com"}
+		cluster.Spec.DNS.Hostnames.PGBouncer = []string{"pooler.example.com"}
+
+		service, _, err := reconciler.generatePGBouncerService(cluster)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, service.Annotations, map[string]string{
+			"external-dns.alpha.kubernetes.io/hostname": "pooler.example.com",
+		})
+	})
 }
 
 func TestReconcilePGBouncerService(t *testing.T) {
@@ -519,6 +542,25 @@ topologySpreadConstraints:
 			assert.Assert(t, deploy.Spec.Template.Spec.TopologySpreadConstraints == nil)
 		})
 	})
+
+	t.Run("Standby", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Standby = &v1beta1.PostgresStandbySpec{Enabled: true}
+
+		deploy, specified, err := reconciler.generatePGBouncerDeployment(
+			cluster, primary, configmap, secret)
+		assert.NilError(t, err)
+		assert.Assert(t, specified)
+		assert.Equal(t, *deploy.Spec.Replicas, int32(0))
+
+		cluster.Spec.Proxy.PGBouncer.ServeStandby = initialize.Bool(true)
+
+		deploy, specified, err = reconciler.generatePGBouncerDeployment(
+			cluster, primary, configmap, secret)
+		assert.NilError(t, err)
+		assert.Assert(t, specified)
+		assert.Equal(t, *deploy.Spec.Replicas, int32(1))
+	})
 }
 
 func TestReconcilePGBouncerDisruptionBudget(t *testing.T) {
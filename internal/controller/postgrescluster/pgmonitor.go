@@ -30,7 +30,9 @@ import (
 	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/logging"
 	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/patroni"
 	"github.com/crunchydata/postgres-operator/internal/pgmonitor"
+	"github.com/crunchydata/postgres-operator/internal/pki"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
 	pgpassword "github.com/crunchydata/postgres-operator/internal/postgres/password"
 	"github.com/crunchydata/postgres-operator/internal/util"
@@ -103,6 +105,12 @@ func (r *Reconciler) reconcilePGMonitorExporter(ctx context.Context,
 		if pgImageSHA == "" {
 			return nil
 		}
+
+		// The extension can be installed once PostgreSQL has restarted with
+		// the library loaded. Changing setup reruns it then.
+		if pgmonitor.ExporterStatKCache(cluster) && !patroni.PodRequiresRestart(writablePod) {
+			setup += pgmonitor.StatKCacheSetup
+		}
 	}
 
 	// PostgreSQL is available for writes. Prepare to either add or remove
@@ -158,10 +166,13 @@ func (r *Reconciler) reconcilePGMonitorExporter(ctx context.Context,
 }
 
 // reconcileMonitoringSecret reconciles the secret containing authentication
-// for monitoring tools
+// for monitoring tools. This is either a password and its verifier or, when
+// the exporter uses certificate authentication, a client certificate signed
+// by root.
 func (r *Reconciler) reconcileMonitoringSecret(
 	ctx context.Context,
-	cluster *v1beta1.PostgresCluster) (*corev1.Secret, error) {
+	cluster *v1beta1.PostgresCluster,
+	root *pki.RootCertificateAuthority) (*corev1.Secret, error) {
 
 	existing := &corev1.Secret{ObjectMeta: naming.MonitoringUserSecret(cluster)}
 	err := errors.WithStack(
@@ -197,6 +208,20 @@ func (r *Reconciler) reconcileMonitoringSecret(
 
 	intent.Data = make(map[string][]byte)
 
+	if pgmonitor.ExporterCertificateAuthentication(cluster) {
+		err = r.monitoringCertificate(existing, intent, root)
+		if err == nil {
+			err = errors.WithStack(r.setControllerReference(cluster, intent))
+		}
+		if err == nil {
+			err = errors.WithStack(r.apply(ctx, intent))
+		}
+		if err == nil {
+			return intent, nil
+		}
+		return nil, err
+	}
+
 	// Copy existing password and verifier into the intent
 	if existing.Data != nil {
 		intent.Data["password"] = existing.Data["password"]
@@ -237,6 +262,40 @@ func (r *Reconciler) reconcileMonitoringSecret(
 	return nil, err
 }
 
+// monitoringCertificate populates intent with a client certificate, private key,
+// and CA certificate that the exporter uses to authenticate as the monitoring
+// user. The certificate in existing is kept until it needs to be regenerated.
+func (*Reconciler) monitoringCertificate(
+	existing, intent *corev1.Secret, root *pki.RootCertificateAuthority,
+) error {
+	leaf := &pki.LeafCertificate{}
+	commonName := pgmonitor.MonitoringUser
+	dnsNames := []string{commonName}
+
+	// Unmarshal and validate the stored leaf. These first errors can
+	// be ignored because they result in an invalid leaf which is then
+	// correctly regenerated.
+	_ = leaf.Certificate.UnmarshalText(existing.Data[pgmonitor.ExporterCertFile])
+	_ = leaf.PrivateKey.UnmarshalText(existing.Data[pgmonitor.ExporterKeyFile])
+
+	leaf, err := root.RegenerateLeafWhenNecessary(leaf, commonName, dnsNames)
+	err = errors.WithStack(err)
+
+	if err == nil {
+		intent.Data[pgmonitor.ExporterCertFile], err = leaf.Certificate.MarshalText()
+		err = errors.WithStack(err)
+	}
+	if err == nil {
+		intent.Data[pgmonitor.ExporterKeyFile], err = leaf.PrivateKey.MarshalText()
+		err = errors.WithStack(err)
+	}
+	if err == nil {
+		intent.Data[pgmonitor.ExporterCAFile], err = root.Trusted().MarshalText()
+		err = errors.WithStack(err)
+	}
+	return err
+}
+
 // addPGMonitorToInstancePodSpec performs the necessary setup to add
 // pgMonitor resources on a PodTemplateSpec
 func addPGMonitorToInstancePodSpec(
@@ -315,6 +374,24 @@ func addPGMonitorExporterToInstancePodSpec(
 			},
 		},
 	}
+	if pgmonitor.ExporterCertificateAuthentication(cluster) {
+		// Connect over TLS and present the client certificate in place of a
+		// password. The server certificate is issued for Service names rather
+		// than localhost, so verify only that it is signed by the cluster CA.
+		exporterContainer.Env = []corev1.EnvVar{
+			{Name: "DATA_SOURCE_URI", Value: fmt.Sprintf(
+				"%s:%d/%s?sslmode=verify-ca&sslcert=%s&sslkey=%s&sslrootcert=%s",
+				pgmonitor.ExporterHost, *cluster.Spec.Port, pgmonitor.ExporterDB,
+				"/opt/crunchy/"+pgmonitor.ExporterCertFile,
+				"/opt/crunchy/"+pgmonitor.ExporterKeyFile,
+				"/opt/crunchy/"+pgmonitor.ExporterCAFile)},
+			{Name: "DATA_SOURCE_USER", Value: pgmonitor.MonitoringUser},
+		}
+
+		// libpq refuses a private key that is readable by group or others.
+		passwordVolume.Secret.DefaultMode = initialize.Int32(0o600)
+	}
+
 	template.Spec.Volumes = append(template.Spec.Volumes, configVolume, passwordVolume)
 
 	// The original "custom queries" ability allowed users to provide a file with custom queries;
@@ -323,7 +400,7 @@ func addPGMonitorExporterToInstancePodSpec(
 	// Therefore, we only want to add the default queries ConfigMap as a source for the
 	// "exporter-config" volume if the AppendCustomQueries feature gate is turned on OR if the
 	// user has not provided any custom configuration.
-	if util.DefaultMutableFeatureGate.Enabled(util.AppendCustomQueries) ||
+	if util.FeatureEnabled(cluster.Spec.Features, util.AppendCustomQueries) ||
 		cluster.Spec.Monitoring.PGMonitor.Exporter.Configuration == nil {
 
 		defaultConfigVolumeProjection := corev1.VolumeProjection{
@@ -36,6 +36,7 @@ import (
 
 	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/pki"
 	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
 	"github.com/crunchydata/postgres-operator/internal/testing/require"
 	"github.com/crunchydata/postgres-operator/internal/util"
@@ -343,6 +344,44 @@ name: exporter-config
 
 		testExporterCollectorsAnnotation(t, cluster, exporterQueriesConfig, testConfigMap)
 	})
+
+	t.Run("CertificateAuthentication", func(t *testing.T) {
+		assert.NilError(t, util.AddAndSetFeatureGates(string(util.AppendCustomQueries+"=false")))
+
+		cluster.Spec.Monitoring = &v1beta1.MonitoringSpec{
+			PGMonitor: &v1beta1.PGMonitorSpec{
+				Exporter: &v1beta1.ExporterSpec{
+					Authentication: "Certificate",
+				},
+			},
+		}
+		template := &corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name: naming.ContainerDatabase,
+				}},
+			},
+		}
+
+		assert.NilError(t, addPGMonitorExporterToInstancePodSpec(cluster, template, exporterQueriesConfig, nil))
+
+		assert.Equal(t, len(template.Spec.Containers), 2)
+		container := template.Spec.Containers[1]
+
+		assert.Assert(t, cmp.MarshalMatches(container.Env, `
+- name: DATA_SOURCE_URI
+  value: localhost:5432/postgres?sslmode=verify-ca&sslcert=/opt/crunchy/tls.crt&sslkey=/opt/crunchy/tls.key&sslrootcert=/opt/crunchy/ca.crt
+- name: DATA_SOURCE_USER
+  value: ccp_monitoring
+		`))
+
+		assert.Assert(t, cmp.MarshalMatches(template.Spec.Volumes[1], `
+name: monitoring-secret
+secret:
+  defaultMode: 384
+  secretName: pg1-monitoring
+		`))
+	})
 }
 
 // TestReconcilePGMonitorExporterSetupErrors tests how reconcilePGMonitorExporter
@@ -716,11 +755,14 @@ func TestReconcileMonitoringSecret(t *testing.T) {
 	cluster.UID = types.UID("hippouid")
 	cluster.Namespace = setupNamespace(t, cc).Name
 
+	root, err := pki.NewRootCertificateAuthority()
+	assert.NilError(t, err)
+
 	// If the exporter is disabled then the secret should not exist
 	// Existing secrets should be removed
 	t.Run("ExporterDisabled", func(t *testing.T) {
 		t.Run("NotExisting", func(t *testing.T) {
-			secret, err := reconciler.reconcileMonitoringSecret(ctx, cluster)
+			secret, err := reconciler.reconcileMonitoringSecret(ctx, cluster, root)
 			assert.NilError(t, err)
 			assert.Assert(t, secret == nil, "Monitoring secret was not nil.")
 		})
@@ -729,12 +771,12 @@ func TestReconcileMonitoringSecret(t *testing.T) {
 			cluster.Spec.Monitoring = &v1beta1.MonitoringSpec{
 				PGMonitor: &v1beta1.PGMonitorSpec{
 					Exporter: &v1beta1.ExporterSpec{Image: "image"}}}
-			existing, err := reconciler.reconcileMonitoringSecret(ctx, cluster)
+			existing, err := reconciler.reconcileMonitoringSecret(ctx, cluster, root)
 			assert.NilError(t, err, "error in test; existing secret not created")
 			assert.Assert(t, existing != nil, "error in test; existing secret not created")
 
 			cluster.Spec.Monitoring = nil
-			actual, err := reconciler.reconcileMonitoringSecret(ctx, cluster)
+			actual, err := reconciler.reconcileMonitoringSecret(ctx, cluster, root)
 			assert.NilError(t, err)
 			assert.Assert(t, actual == nil, "Monitoring secret still exists after turning exporter off.")
 		})
@@ -758,17 +800,48 @@ func TestReconcileMonitoringSecret(t *testing.T) {
 		}
 
 		t.Run("NotExisting", func(t *testing.T) {
-			existing, err = reconciler.reconcileMonitoringSecret(ctx, cluster)
+			existing, err = reconciler.reconcileMonitoringSecret(ctx, cluster, root)
 			assert.NilError(t, err)
 			assert.Assert(t, existing != nil, "Monitoring secret does not exist.")
 		})
 
 		t.Run("Existing", func(t *testing.T) {
-			actual, err = reconciler.reconcileMonitoringSecret(ctx, cluster)
+			actual, err = reconciler.reconcileMonitoringSecret(ctx, cluster, root)
 			assert.NilError(t, err)
 			assert.Assert(t, bytes.Equal(actual.Data["password"], existing.Data["password"]), "Passwords do not match.")
 		})
 	})
+
+	// When the exporter authenticates with a certificate, the secret holds
+	// a client certificate rather than a password.
+	t.Run("CertificateAuthentication", func(t *testing.T) {
+		cluster.Spec.Monitoring = &v1beta1.MonitoringSpec{
+			PGMonitor: &v1beta1.PGMonitorSpec{
+				Exporter: &v1beta1.ExporterSpec{
+					Image:          "image",
+					Authentication: "Certificate",
+				},
+			},
+		}
+
+		existing, err := reconciler.reconcileMonitoringSecret(ctx, cluster, root)
+		assert.NilError(t, err)
+		assert.Assert(t, existing != nil)
+		assert.Assert(t, len(existing.Data["password"]) == 0)
+		assert.Assert(t, len(existing.Data["verifier"]) == 0)
+		assert.Assert(t, len(existing.Data["tls.crt"]) > 0)
+		assert.Assert(t, len(existing.Data["tls.key"]) > 0)
+		assert.Assert(t, len(existing.Data["ca.crt"]) > 0)
+
+		leaf := &pki.LeafCertificate{}
+		assert.NilError(t, leaf.Certificate.UnmarshalText(existing.Data["tls.crt"]))
+		assert.Equal(t, leaf.Certificate.CommonName(), "ccp_monitoring")
+
+		actual, err := reconciler.reconcileMonitoringSecret(ctx, cluster, root)
+		assert.NilError(t, err)
+		assert.Assert(t, bytes.Equal(actual.Data["tls.crt"], existing.Data["tls.crt"]),
+			"Certificate was regenerated.")
+	})
 }
 
 // TestReconcileExporterQueriesConfig checks that the ConfigMap intent returned by
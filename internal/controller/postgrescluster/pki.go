@@ -17,11 +17,14 @@ package postgrescluster
 
 import (
 	"context"
+	"time"
 
 	"github.com/pkg/errors"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/pki"
@@ -33,6 +36,12 @@ const (
 	clusterCertFile = "tls.crt"
 	clusterKeyFile  = "tls.key"
 	rootCertFile    = "ca.crt"
+
+	// rootReplacementGrace is how long it takes for a change to a Secret to
+	// reach the files of every pod that mounts it and for the processes in
+	// those pods to reload them.
+	// - https://docs.k8s.io/concepts/configuration/secret/#mounted-secrets-are-updated-automatically
+	rootReplacementGrace = 3 * time.Minute
 )
 
 // +kubebuilder:rbac:groups="",resources="secrets",verbs={get}
@@ -58,6 +67,9 @@ func (r *Reconciler) reconcileRootCertificate(
 	root := &pki.RootCertificateAuthority{}
 
 	if err == nil {
+		r.recordRegeneratedSecret(cluster, existing,
+			"the root certificate authority; every cluster in the namespace trusts it before its certificates are reissued")
+
 		// Unmarshal and validate the stored root. These first errors can
 		// be ignored because they result in an invalid root which is then
 		// correctly regenerated.
@@ -103,6 +115,63 @@ func (r *Reconciler) reconcileRootCertificate(
 	return root, err
 }
 
+// reconcileRootReplacement coordinates the certificates of cluster when its
+// root certificate authority is replaced, such as after the root Secret is
+// deleted. Pods see changes to their Secrets at different times, so each step
+// lasts long enough for every pod to see the last:
+//
+//  1. Pods trust both the previous and current roots while keeping certificates
+//     from the previous one.
+//  2. Certificates are reissued by the current root; pods still trust both.
+//  3. Pods trust only the current root.
+//
+// It sets the fields of root that accomplish this and returns a result that
+// reconciles again when the next step begins.
+func (r *Reconciler) reconcileRootReplacement(
+	cluster *v1beta1.PostgresCluster, root *pki.RootCertificateAuthority, now time.Time,
+) reconcile.Result {
+	current, err := root.Certificate.MarshalText()
+	if err != nil {
+		return reconcile.Result{}
+	}
+
+	if cluster.Status.CertificateAuthority == nil {
+		cluster.Status.CertificateAuthority = &v1beta1.CertificateAuthorityStatus{}
+	}
+	status := cluster.Status.CertificateAuthority
+
+	if status.Certificate != "" && status.Certificate != string(current) {
+		status.Previous = status.Certificate
+		status.ReplacedAt = &metav1.Time{Time: now}
+
+		r.Recorder.Event(cluster, corev1.EventTypeNormal, "RootCertificateReplaced",
+			"The root certificate authority changed; certificates are reissued "+
+				"once every pod trusts it")
+	}
+	status.Certificate = string(current)
+
+	var previous pki.Certificate
+	if status.ReplacedAt == nil || previous.UnmarshalText([]byte(status.Previous)) != nil {
+		status.Previous, status.ReplacedAt = "", nil
+		return reconcile.Result{}
+	}
+
+	elapsed := now.Sub(status.ReplacedAt.Time)
+	switch {
+	case elapsed < rootReplacementGrace:
+		root.Previous, root.KeepPreviousLeaves = previous, true
+		return reconcile.Result{RequeueAfter: rootReplacementGrace - elapsed}
+
+	case elapsed < 2*rootReplacementGrace:
+		root.Previous = previous
+		return reconcile.Result{RequeueAfter: 2*rootReplacementGrace - elapsed}
+
+	default:
+		status.Previous, status.ReplacedAt = "", nil
+		return reconcile.Result{}
+	}
+}
+
 // +kubebuilder:rbac:groups="",resources="secrets",verbs={get}
 // +kubebuilder:rbac:groups="",resources="secrets",verbs={create,patch}
 
@@ -138,6 +207,8 @@ func (r *Reconciler) reconcileClusterCertificate(
 	dnsFQDN := dnsNames[0]
 
 	if err == nil {
+		r.recordRegeneratedSecret(cluster, existing, "the PostgreSQL server certificate")
+
 		// Unmarshal and validate the stored leaf. These first errors can
 		// be ignored because they result in an invalid leaf which is then
 		// correctly regenerated.
@@ -174,7 +245,7 @@ func (r *Reconciler) reconcileClusterCertificate(
 		err = errors.WithStack(err)
 	}
 	if err == nil {
-		intent.Data[rootCA], err = root.Certificate.MarshalText()
+		intent.Data[rootCA], err = root.Trusted().MarshalText()
 		err = errors.WithStack(err)
 	}
 
@@ -262,3 +333,71 @@ func clusterCertSecretProjection(certificate *corev1.Secret) *corev1.SecretProje
 		},
 	}
 }
+
+// reconcileClientCertificateSecret writes a Secret containing a client
+// certificate for the PostgreSQL role user, signed by root. The Secret is
+// deleted and nil is returned when enabled is false.
+func (r *Reconciler) reconcileClientCertificateSecret(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	root *pki.RootCertificateAuthority, secret metav1.ObjectMeta,
+	user string, enabled bool,
+) (
+	*corev1.Secret, error,
+) {
+	existing := &corev1.Secret{ObjectMeta: *secret.DeepCopy()}
+	err := errors.WithStack(client.IgnoreNotFound(
+		r.Client.Get(ctx, client.ObjectKeyFromObject(existing), existing)))
+
+	if err == nil && !enabled {
+		// Delete the Secret when it exists.
+		if existing.UID != "" {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, existing))
+		}
+		return nil, client.IgnoreNotFound(err)
+	}
+
+	leaf := &pki.LeafCertificate{}
+
+	if err == nil {
+		// Unmarshal and validate the stored leaf. These first errors can
+		// be ignored because they result in an invalid leaf which is then
+		// correctly regenerated.
+		_ = leaf.Certificate.UnmarshalText(existing.Data[clusterCertFile])
+		_ = leaf.PrivateKey.UnmarshalText(existing.Data[clusterKeyFile])
+
+		leaf, err = root.RegenerateLeafWhenNecessary(leaf, user, []string{user})
+		err = errors.WithStack(err)
+	}
+
+	intent := &corev1.Secret{ObjectMeta: *secret.DeepCopy()}
+	intent.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+	intent.Data = make(map[string][]byte)
+
+	intent.Annotations = naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil(),
+		secret.Annotations)
+	intent.Labels = naming.Merge(cluster.Spec.Metadata.GetLabelsOrNil(),
+		secret.Labels,
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+		})
+
+	if err == nil {
+		intent.Data[clusterCertFile], err = leaf.Certificate.MarshalText()
+		err = errors.WithStack(err)
+	}
+	if err == nil {
+		intent.Data[clusterKeyFile], err = leaf.PrivateKey.MarshalText()
+		err = errors.WithStack(err)
+	}
+	if err == nil {
+		intent.Data[rootCertFile], err = root.Trusted().MarshalText()
+		err = errors.WithStack(err)
+	}
+	if err == nil {
+		err = errors.WithStack(r.setControllerReference(cluster, intent))
+	}
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, intent))
+	}
+	return intent, err
+}
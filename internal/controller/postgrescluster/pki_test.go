@@ -25,6 +25,7 @@ import (
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/pkg/errors"
 	"gotest.tools/v3/assert"
@@ -32,10 +33,12 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/pki"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
 	"github.com/crunchydata/postgres-operator/internal/testing/require"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
@@ -390,6 +393,61 @@ func TestReconcileCerts(t *testing.T) {
 	})
 }
 
+func TestReconcileRootReplacement(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{Recorder: recorder}
+
+	first, err := pki.NewRootCertificateAuthority()
+	assert.NilError(t, err)
+	second, err := pki.NewRootCertificateAuthority()
+	assert.NilError(t, err)
+
+	cluster := &v1beta1.PostgresCluster{}
+	now := time.Now()
+
+	// The first root is recorded without replacing anything.
+	result := r.reconcileRootReplacement(cluster, first, now)
+	assert.Equal(t, result.RequeueAfter, time.Duration(0))
+	assert.Assert(t, cluster.Status.CertificateAuthority.ReplacedAt == nil)
+	assert.Equal(t, len(first.Trusted()), 1)
+	assert.Equal(t, len(recorder.Events), 0)
+
+	firstText, err := first.Certificate.MarshalText()
+	assert.NilError(t, err)
+	assert.Equal(t, cluster.Status.CertificateAuthority.Certificate, string(firstText))
+
+	t.Run("Replaced", func(t *testing.T) {
+		root := *second
+		result := r.reconcileRootReplacement(cluster, &root, now)
+		assert.Equal(t, result.RequeueAfter, rootReplacementGrace)
+		assert.Equal(t, cluster.Status.CertificateAuthority.Previous, string(firstText))
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "RootCertificateReplaced"))
+
+		// Certificates of the previous root are kept while pods learn to trust
+		// the current one.
+		assert.Assert(t, root.Previous.Equal(first.Certificate))
+		assert.Assert(t, root.KeepPreviousLeaves)
+	})
+
+	t.Run("Reissued", func(t *testing.T) {
+		root := *second
+		result := r.reconcileRootReplacement(cluster, &root, now.Add(rootReplacementGrace+time.Second))
+		assert.Equal(t, result.RequeueAfter, rootReplacementGrace-time.Second)
+		assert.Assert(t, root.Previous.Equal(first.Certificate))
+		assert.Assert(t, !root.KeepPreviousLeaves)
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+
+	t.Run("Finished", func(t *testing.T) {
+		root := *second
+		result := r.reconcileRootReplacement(cluster, &root, now.Add(2*rootReplacementGrace))
+		assert.Equal(t, result.RequeueAfter, time.Duration(0))
+		assert.Equal(t, len(root.Trusted()), 1)
+		assert.Equal(t, cluster.Status.CertificateAuthority.Previous, "")
+		assert.Assert(t, cluster.Status.CertificateAuthority.ReplacedAt == nil)
+	})
+}
+
 // getCertFromSecret returns a parsed certificate from the named secret
 func getCertFromSecret(
 	ctx context.Context, tClient client.Client, name, namespace, dataKey string,
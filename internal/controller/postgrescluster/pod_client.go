@@ -16,9 +16,12 @@
 package postgrescluster
 
 import (
+	"context"
 	"io"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
@@ -72,3 +75,51 @@ func newPodExecutor(config *rest.Config) (podExecutor, error) {
 		return err
 	}, err
 }
+
+// podEphemeralContainers replaces the ephemeral containers of pod with those
+// in its spec.
+type podEphemeralContainers func(ctx context.Context, pod *corev1.Pod) error
+
+// +kubebuilder:rbac:groups="",resources="pods/ephemeralcontainers",verbs={update}
+
+func newPodEphemeralContainers(config *rest.Config) (podEphemeralContainers, error) {
+	client, err := newPodClient(config)
+
+	return func(ctx context.Context, pod *corev1.Pod) error {
+		return client.Put().
+			Resource("pods").SubResource("ephemeralcontainers").
+			Namespace(pod.Namespace).Name(pod.Name).
+			Body(pod).Do(ctx).Into(pod)
+	}, err
+}
+
+// podLogReader returns the log of container in pod since a time. Each line
+// begins with the time it was written.
+type podLogReader func(
+	ctx context.Context, pod *corev1.Pod, container string, since time.Time,
+) ([]byte, error)
+
+// podLogLimit is the most of a log that [podLogReader] returns at once.
+const podLogLimit = 4 << 20
+
+// +kubebuilder:rbac:groups="",resources="pods/log",verbs={get}
+
+func newPodLogReader(config *rest.Config) (podLogReader, error) {
+	client, err := newPodClient(config)
+
+	return func(
+		ctx context.Context, pod *corev1.Pod, container string, since time.Time,
+	) ([]byte, error) {
+		limit := int64(podLogLimit)
+		return client.Get().
+			Resource("pods").SubResource("log").
+			Namespace(pod.Namespace).Name(pod.Name).
+			VersionedParams(&corev1.PodLogOptions{
+				Container:  container,
+				LimitBytes: &limit,
+				SinceTime:  &metav1.Time{Time: since},
+				Timestamps: true,
+			}, scheme.ParameterCodec).
+			Do(ctx).Raw()
+	}, err
+}
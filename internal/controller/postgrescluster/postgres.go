@@ -23,6 +23,8 @@ import (
 	"net"
 	"net/url"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/pkg/errors"
@@ -33,10 +35,12 @@ import (
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	"github.com/crunchydata/postgres-operator/internal/agent"
 	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/logging"
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/pgaudit"
+	"github.com/crunchydata/postgres-operator/internal/pki"
 	"github.com/crunchydata/postgres-operator/internal/postgis"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
 	pgpassword "github.com/crunchydata/postgres-operator/internal/postgres/password"
@@ -181,6 +185,70 @@ func (r *Reconciler) generatePostgresUserSecret(
 	return intent, err
 }
 
+// reconcileReadOnly exempts the superuser from the read-only default of
+// PostgreSQL so that the operator can continue to manage databases and users.
+// PostgreSQL is changed only when the cluster is or was read-only.
+func (r *Reconciler) reconcileReadOnly(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) error {
+	readOnly := postgres.ReadOnly(cluster)
+	if !readOnly && cluster.Status.ReadOnlyRevision == "" {
+		return nil
+	}
+
+	revision := cluster.Status.ReadOnlyRevision
+	current, err := r.reconcileSQLRevision(ctx, instances, &revision,
+		func(ctx context.Context, exec postgres.Executor) error {
+			return postgres.WriteReadOnlyInPostgreSQL(ctx, exec, readOnly)
+		})
+
+	cluster.Status.ReadOnlyRevision = revision
+	if current && !readOnly {
+		cluster.Status.ReadOnlyRevision = ""
+	}
+	return err
+}
+
+// reconcileTempTablespace creates the tablespace for temporary files when
+// instances have a volume for them and drops it when they no longer do.
+func (r *Reconciler) reconcileTempTablespace(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) error {
+	enabled := postgres.TempVolume(cluster)
+	if !enabled && cluster.Status.TempTablespaceRevision == "" {
+		return nil
+	}
+
+	// Replicas replay the creation of the tablespace, so wait until every
+	// instance has a directory for it.
+	if enabled {
+		mount := postgres.TempVolumeMount()
+		for _, instance := range instances.forCluster {
+			for _, pod := range instance.Pods {
+				mounted := false
+				for _, volume := range pod.Spec.Volumes {
+					mounted = mounted || volume.Name == mount.Name
+				}
+				if !mounted {
+					return nil
+				}
+			}
+		}
+	}
+
+	revision := cluster.Status.TempTablespaceRevision
+	current, err := r.reconcileSQLRevision(ctx, instances, &revision,
+		func(ctx context.Context, exec postgres.Executor) error {
+			return postgres.WriteTempTablespaceInPostgreSQL(ctx, exec, enabled)
+		})
+
+	cluster.Status.TempTablespaceRevision = revision
+	if current && !enabled {
+		cluster.Status.TempTablespaceRevision = ""
+	}
+	return err
+}
+
 // reconcilePostgresDatabases creates databases inside of PostgreSQL.
 func (r *Reconciler) reconcilePostgresDatabases(
 	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
@@ -228,6 +296,9 @@ func (r *Reconciler) reconcilePostgresDatabases(
 			}
 		}
 	}
+	for _, database := range cluster.Spec.Databases {
+		databases.Insert(string(database.Name))
+	}
 
 	// Calculate a hash of the SQL that should be executed in PostgreSQL.
 
@@ -296,8 +367,12 @@ func (r *Reconciler) reconcilePostgresDatabases(
 // passwords in PostgreSQL.
 func (r *Reconciler) reconcilePostgresUsers(
 	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+	root *pki.RootCertificateAuthority,
 ) error {
 	users, secrets, err := r.reconcilePostgresUserSecrets(ctx, cluster)
+	if err == nil {
+		err = r.reconcilePostgresUserCertificates(ctx, cluster, root, users)
+	}
 	if err == nil {
 		err = r.reconcilePostgresUsersInPostgreSQL(ctx, cluster, instances, users, secrets)
 	}
@@ -375,13 +450,52 @@ func (r *Reconciler) reconcilePostgresUserSecrets(
 			))
 	}
 
+	// When user Secrets are immutable, the current version of each is recorded
+	// in status. Index those by PostgreSQL user name.
+	immutable := cluster.Spec.ImmutableUserSecrets != nil && *cluster.Spec.ImmutableUserSecrets
+	versions := make(map[string]v1beta1.PostgresUserSecretStatus, len(cluster.Status.UserSecrets))
+	for _, status := range cluster.Status.UserSecrets {
+		versions[string(status.Name)] = status
+	}
+
+	// Status can fall behind when it fails to be written after a new version
+	// is created. Take the latest version that exists so that it is reused
+	// rather than created again.
+	if err == nil && immutable {
+		for i := range secrets.Items {
+			secret := &secrets.Items[i]
+			userName := secret.Labels[naming.LabelPostgresUser]
+			version := postgresUserSecretVersion(cluster, userName, secret)
+
+			if version > versions[userName].Version {
+				versions[userName] = v1beta1.PostgresUserSecretStatus{
+					Name:    v1beta1.PostgresIdentifier(userName),
+					Secret:  secret.Name,
+					Version: version,
+				}
+			}
+		}
+	}
+	currentSecretName := func(userName string) string {
+		if !immutable {
+			return naming.PostgresUserSecret(cluster, userName).Name
+		}
+		version := versions[userName].Version
+		if version < 1 {
+			version = 1
+		}
+		return naming.PostgresUserSecretVersion(cluster, userName, version).Name
+	}
+
 	// Index secrets by PostgreSQL user name and delete any that are not in the
 	// cluster spec. Keep track of the deprecated default secret to migrate its
-	// contents when the current secret doesn't exist.
+	// contents when the current secret doesn't exist. Keep track of other
+	// versions of each user Secret, too.
 	var (
 		defaultSecret     *corev1.Secret
 		defaultSecretName = naming.DeprecatedPostgresUserSecret(cluster).Name
 		defaultUserName   string
+		staleSecrets      = make(map[string][]*corev1.Secret)
 		userSecrets       = make(map[string]*corev1.Secret, len(secrets.Items))
 	)
 	if err == nil {
@@ -393,8 +507,10 @@ func (r *Reconciler) reconcilePostgresUserSecrets(
 				if secret.Name == defaultSecretName {
 					defaultSecret = secret
 					defaultUserName = secretUserName
-				} else {
+				} else if secret.Name == currentSecretName(secretUserName) {
 					userSecrets[secretUserName] = secret
+				} else {
+					staleSecrets[secretUserName] = append(staleSecrets[secretUserName], secret)
 				}
 			} else if err == nil {
 				err = errors.WithStack(r.deleteControlled(ctx, cluster, secret))
@@ -404,25 +520,211 @@ func (r *Reconciler) reconcilePostgresUserSecrets(
 
 	// Reconcile each PostgreSQL user in the cluster spec.
 	for userName, user := range userSpecs {
-		secret := userSecrets[userName]
+		current := userSecrets[userName]
+		secret := current
 
 		if secret == nil && userName == defaultUserName {
 			// The current secret doesn't exist, so read from the deprecated
 			// default secret, if any.
 			secret = defaultSecret
 		}
+		if secret == nil {
+			// Read from the Secret of the other mode or the last version, if any.
+			for _, stale := range staleSecrets[userName] {
+				if secret == nil || stale.Name == versions[userName].Secret {
+					secret = stale
+				}
+			}
+		}
+
+		// Generate a new password when the current immutable Secret asks for it.
+		rotate := immutable && current != nil && current.Annotations[naming.RotatePassword] != ""
+		if rotate {
+			secret = nil
+		}
 
 		if err == nil {
 			userSecrets[userName], err = r.generatePostgresUserSecret(cluster, user, secret)
 		}
+		var version v1beta1.PostgresUserSecretStatus
+		if err == nil && immutable {
+			version = r.versionPostgresUserSecret(
+				cluster, userSecrets[userName], versions[userName], current, rotate)
+		} else if err == nil && secret == nil &&
+			cluster.Status.ObservedGeneration == cluster.GetGeneration() {
+			// A user added to the spec has no Secret yet, but the spec has not
+			// changed since the last reconcile when an existing Secret is deleted.
+			r.recordRegeneratedSecret(cluster, userSecrets[userName], fmt.Sprintf(
+				"the password of user %q; applications must read the new password", userName))
+		}
 		if err == nil {
 			err = errors.WithStack(r.apply(ctx, userSecrets[userName]))
 		}
+		if err == nil && immutable {
+			versions[userName] = version
+		}
+
+		// Delete other versions once the current Secret is written.
+		if err == nil && current != nil && current.Name != userSecrets[userName].Name {
+			staleSecrets[userName] = append(staleSecrets[userName], current)
+		}
+		for _, stale := range staleSecrets[userName] {
+			if err == nil {
+				err = errors.WithStack(client.IgnoreNotFound(
+					r.deleteControlled(ctx, cluster, stale)))
+			}
+		}
+	}
+
+	// Record the current version of each immutable user Secret.
+	cluster.Status.UserSecrets = nil
+	if immutable {
+		for userName := range userSpecs {
+			if status, ok := versions[userName]; ok && status.Secret != "" {
+				cluster.Status.UserSecrets = append(cluster.Status.UserSecrets, status)
+			}
+		}
+		sort.Slice(cluster.Status.UserSecrets, func(i, j int) bool {
+			return cluster.Status.UserSecrets[i].Name < cluster.Status.UserSecrets[j].Name
+		})
 	}
 
 	return specUsers, userSecrets, err
 }
 
+// versionPostgresUserSecret names intent for the version of the immutable
+// Secret of its user that it should be written to. The version increases when
+// the contents of current differ from intent, when rotate is true, and when
+// current was deleted. It returns the status of that version.
+func (r *Reconciler) versionPostgresUserSecret(
+	cluster *v1beta1.PostgresCluster, intent *corev1.Secret,
+	status v1beta1.PostgresUserSecretStatus, current *corev1.Secret, rotate bool,
+) v1beta1.PostgresUserSecretStatus {
+	userName := intent.Labels[naming.LabelPostgresUser]
+	version := status.Version
+	if version < 1 {
+		version = 1
+	}
+
+	var reason string
+	switch {
+	case current == nil && status.Version > 0:
+		reason = fmt.Sprintf("because Secret %q was deleted", status.Secret)
+	case current != nil && rotate:
+		reason = "with a new password"
+	case current != nil && !equalSecretData(current.Data, intent.Data):
+		reason = "because its connection details changed"
+	}
+	if reason != "" {
+		version++
+	}
+
+	intent.Name = naming.PostgresUserSecretVersion(cluster, userName, version).Name
+	intent.Immutable = initialize.Bool(true)
+
+	if reason != "" {
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "UserSecretVersioned",
+			"Created Secret %q for user %q %s; applications must read the new Secret",
+			intent.Name, userName, reason)
+	}
+
+	return v1beta1.PostgresUserSecretStatus{
+		Name:    v1beta1.PostgresIdentifier(userName),
+		Secret:  intent.Name,
+		Version: version,
+	}
+}
+
+// postgresUserSecretVersion returns the version of the immutable Secret of
+// userName that secret is, or zero when it is not one.
+func postgresUserSecretVersion(
+	cluster *v1beta1.PostgresCluster, userName string, secret *corev1.Secret,
+) int32 {
+	prefix := naming.PostgresUserSecretVersion(cluster, userName, 0).Name
+	prefix = strings.TrimSuffix(prefix, "0")
+
+	if !strings.HasPrefix(secret.Name, prefix) {
+		return 0
+	}
+	version, err := strconv.ParseInt(strings.TrimPrefix(secret.Name, prefix), 10, 32)
+	if err != nil || version < 1 {
+		return 0
+	}
+	return int32(version)
+}
+
+// equalSecretData returns whether or not a and b have the same keys and values.
+func equalSecretData(a, b map[string][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for key, value := range a {
+		if other, ok := b[key]; !ok || !bytes.Equal(value, other) {
+			return false
+		}
+	}
+	return true
+}
+
+// reconcilePostgresUserCertificates writes a Secret containing a client
+// certificate for each user in users with certificate authentication. The
+// common name of each certificate is the name of its user. Secrets of other
+// users are deleted. No certificate is issued to a user with the name of the
+// operator, which the agents of instances trust.
+func (r *Reconciler) reconcilePostgresUserCertificates(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	root *pki.RootCertificateAuthority, users []v1beta1.PostgresUserSpec,
+) error {
+	const role = "pguser-client-tls"
+
+	secrets := &corev1.SecretList{}
+	selector, err := naming.AsSelector(metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			naming.LabelCluster:            cluster.Name,
+			naming.LabelClusterCertificate: role,
+		},
+	})
+	if err == nil {
+		err = errors.WithStack(
+			r.Client.List(ctx, secrets,
+				client.InNamespace(cluster.Namespace),
+				client.MatchingLabelsSelector{Selector: selector},
+			))
+	}
+
+	wanted := sets.NewString()
+	for i := range users {
+		if !users[i].CertAuth {
+			continue
+		}
+
+		username := string(users[i].Name)
+		if username == agent.ClientCommonName {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidUser",
+				"User %q cannot authenticate with a certificate because that name is reserved",
+				username)
+			continue
+		}
+
+		secret := naming.PostgresUserCertSecret(cluster, username)
+		secret.Labels = map[string]string{naming.LabelClusterCertificate: role}
+		wanted.Insert(secret.Name)
+
+		if err == nil {
+			_, err = r.reconcileClientCertificateSecret(ctx, cluster, root, secret, username, true)
+		}
+	}
+
+	for i := range secrets.Items {
+		if err == nil && !wanted.Has(secrets.Items[i].Name) {
+			err = errors.WithStack(client.IgnoreNotFound(
+				r.deleteControlled(ctx, cluster, &secrets.Items[i])))
+		}
+	}
+
+	return err
+}
+
 // reconcilePostgresUsersInPostgreSQL creates users inside of PostgreSQL and
 // sets their options and database access as specified.
 func (r *Reconciler) reconcilePostgresUsersInPostgreSQL(
@@ -571,7 +873,7 @@ func (r *Reconciler) reconcileTablespaceVolumes(
 	clusterVolumes []corev1.PersistentVolumeClaim,
 ) (tablespaceVolumes []*corev1.PersistentVolumeClaim, err error) {
 
-	if !util.DefaultMutableFeatureGate.Enabled(util.TablespaceVolumes) {
+	if !util.FeatureEnabled(cluster.Spec.Features, util.TablespaceVolumes) {
 		return
 	}
 
@@ -736,6 +1038,75 @@ func (r *Reconciler) reconcilePostgresWALVolume(
 	return pvc, err
 }
 
+// +kubebuilder:rbac:groups="",resources="persistentvolumeclaims",verbs={get}
+// +kubebuilder:rbac:groups="",resources="persistentvolumeclaims",verbs={create,delete,patch}
+
+// reconcilePostgresTempVolume writes the PersistentVolumeClaim for instance's
+// volume of temporary files. The PVC is deleted when it is no longer specified;
+// it holds nothing that outlives PostgreSQL.
+func (r *Reconciler) reconcilePostgresTempVolume(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	instanceSpec *v1beta1.PostgresInstanceSetSpec, instance *appsv1.StatefulSet,
+	clusterVolumes []corev1.PersistentVolumeClaim,
+) (*corev1.PersistentVolumeClaim, error) {
+
+	labelMap := map[string]string{
+		naming.LabelCluster:     cluster.Name,
+		naming.LabelInstanceSet: instanceSpec.Name,
+		naming.LabelInstance:    instance.Name,
+		naming.LabelRole:        naming.RolePostgresTemp,
+		naming.LabelData:        naming.DataPostgres,
+	}
+
+	var pvc *corev1.PersistentVolumeClaim
+	existingPVCName, err := getPGPVCName(labelMap, clusterVolumes)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if existingPVCName != "" {
+		pvc = &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+			Namespace: cluster.GetNamespace(),
+			Name:      existingPVCName,
+		}}
+	} else {
+		pvc = &corev1.PersistentVolumeClaim{ObjectMeta: naming.InstancePostgresTempVolume(instance)}
+	}
+
+	pvc.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"))
+
+	if instanceSpec.TempVolumeClaimSpec == nil {
+		// No temp volume is specified; delete the PVC if it exists. The PVC
+		// continues to exist until all Pods using it are also deleted.
+		// - https://docs.k8s.io/concepts/storage/persistent-volumes/#storage-object-in-use-protection
+		if existingPVCName == "" {
+			return nil, nil
+		}
+		return nil, errors.WithStack(
+			client.IgnoreNotFound(r.deleteControlled(ctx, cluster, pvc)))
+	}
+
+	err = errors.WithStack(r.setControllerReference(cluster, pvc))
+
+	pvc.Annotations = naming.Merge(
+		cluster.Spec.Metadata.GetAnnotationsOrNil(),
+		instanceSpec.Metadata.GetAnnotationsOrNil())
+
+	pvc.Labels = naming.Merge(
+		cluster.Spec.Metadata.GetLabelsOrNil(),
+		instanceSpec.Metadata.GetLabelsOrNil(),
+		labelMap,
+	)
+
+	pvc.Spec = *instanceSpec.TempVolumeClaimSpec
+
+	if err == nil {
+		err = r.handlePersistentVolumeClaimError(cluster,
+			errors.WithStack(r.apply(ctx, pvc)))
+	}
+
+	return pvc, err
+}
+
 // reconcileDatabaseInitSQL runs custom SQL files in the database. When
 // DatabaseInitSQL is defined, the function will find the primary pod and run
 // SQL from the defined ConfigMap
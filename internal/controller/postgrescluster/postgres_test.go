@@ -30,11 +30,13 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/yaml"
 
 	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/pki"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
 	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
 	"github.com/crunchydata/postgres-operator/internal/testing/require"
@@ -661,3 +663,264 @@ func TestReconcileDatabaseInitSQLConfigMap(t *testing.T) {
 		assert.Assert(t, called)
 	})
 }
+
+func TestReconcileReadOnly(t *testing.T) {
+	ctx := context.Background()
+
+	pod := &corev1.Pod{}
+	pod.Namespace, pod.Name = "ns1", "hippo-00-abcd-0"
+	pod.Annotations = map[string]string{"status": `{"role":"master"}`}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  naming.ContainerDatabase,
+		State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+	}}
+
+	instances := &observedInstances{forCluster: []*Instance{
+		{Name: "hippo-00-abcd", Pods: []*corev1.Pod{pod}},
+	}}
+
+	var calls []string
+	reconciler := &Reconciler{
+		PodExec: func(_, _, _ string, stdin io.Reader, _, _ io.Writer, _ ...string) error {
+			b, err := io.ReadAll(stdin)
+			calls = append(calls, string(b))
+			return err
+		},
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+
+	// Nothing happens until the cluster is read-only.
+	assert.NilError(t, reconciler.reconcileReadOnly(ctx, cluster, instances))
+	assert.Equal(t, len(calls), 0)
+
+	cluster.Spec.ReadOnly = initialize.Bool(true)
+	assert.NilError(t, reconciler.reconcileReadOnly(ctx, cluster, instances))
+	assert.Equal(t, len(calls), 1)
+	assert.Assert(t, cmp.Contains(calls[0], "SET default_transaction_read_only = off;"))
+	assert.Assert(t, cluster.Status.ReadOnlyRevision != "")
+
+	// The same SQL is not executed again.
+	assert.NilError(t, reconciler.reconcileReadOnly(ctx, cluster, instances))
+	assert.Equal(t, len(calls), 1)
+
+	// The exemption is removed when the cluster is no longer read-only.
+	cluster.Spec.ReadOnly = nil
+	assert.NilError(t, reconciler.reconcileReadOnly(ctx, cluster, instances))
+	assert.Equal(t, len(calls), 2)
+	assert.Assert(t, cmp.Contains(calls[1], "RESET default_transaction_read_only"))
+	assert.Equal(t, cluster.Status.ReadOnlyRevision, "")
+
+	assert.NilError(t, reconciler.reconcileReadOnly(ctx, cluster, instances))
+	assert.Equal(t, len(calls), 2)
+}
+
+func TestReconcileTempTablespace(t *testing.T) {
+	ctx := context.Background()
+
+	pod := &corev1.Pod{}
+	pod.Namespace, pod.Name = "ns1", "hippo-00-abcd-0"
+	pod.Annotations = map[string]string{"status": `{"role":"master"}`}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  naming.ContainerDatabase,
+		State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+	}}
+
+	instances := &observedInstances{forCluster: []*Instance{
+		{Name: "hippo-00-abcd", Pods: []*corev1.Pod{pod}},
+	}}
+
+	var calls []string
+	reconciler := &Reconciler{
+		PodExec: func(_, _, _ string, stdin io.Reader, _, _ io.Writer, _ ...string) error {
+			b, err := io.ReadAll(stdin)
+			calls = append(calls, string(b))
+			return err
+		},
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{Name: "00"}}
+
+	// Nothing happens until an instance set has a temp volume.
+	assert.NilError(t, reconciler.reconcileTempTablespace(ctx, cluster, instances))
+	assert.Equal(t, len(calls), 0)
+
+	// Nothing happens until every instance has the volume.
+	cluster.Spec.InstanceSets[0].TempVolumeClaimSpec = &corev1.PersistentVolumeClaimSpec{}
+	assert.NilError(t, reconciler.reconcileTempTablespace(ctx, cluster, instances))
+	assert.Equal(t, len(calls), 0)
+
+	pod.Spec.Volumes = []corev1.Volume{{Name: postgres.TempVolumeMount().Name}}
+	assert.NilError(t, reconciler.reconcileTempTablespace(ctx, cluster, instances))
+	assert.Equal(t, len(calls), 1)
+	assert.Assert(t, cmp.Contains(calls[0], "CREATE TABLESPACE"))
+	assert.Assert(t, cluster.Status.TempTablespaceRevision != "")
+
+	// The same SQL is not executed again.
+	assert.NilError(t, reconciler.reconcileTempTablespace(ctx, cluster, instances))
+	assert.Equal(t, len(calls), 1)
+
+	// The tablespace is dropped when no instance set has a temp volume.
+	cluster.Spec.InstanceSets[0].TempVolumeClaimSpec = nil
+	assert.NilError(t, reconciler.reconcileTempTablespace(ctx, cluster, instances))
+	assert.Equal(t, len(calls), 2)
+	assert.Assert(t, cmp.Contains(calls[1], "DROP TABLESPACE"))
+	assert.Equal(t, cluster.Status.TempTablespaceRevision, "")
+}
+
+func TestReconcilePostgresUserCertificates(t *testing.T) {
+	ctx := context.Background()
+	_, tClient := setupKubernetes(t)
+	require.ParallelCapacity(t, 1)
+
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Client:   tClient,
+		Owner:    client.FieldOwner(t.Name()),
+		Recorder: recorder,
+	}
+
+	cluster := testCluster()
+	cluster.Namespace = setupNamespace(t, tClient).Name
+
+	assert.NilError(t, tClient.Create(ctx, cluster))
+	t.Cleanup(func() { assert.Check(t, tClient.Delete(ctx, cluster)) })
+
+	root, err := pki.NewRootCertificateAuthority()
+	assert.NilError(t, err)
+
+	users := []v1beta1.PostgresUserSpec{
+		{Name: "app"},
+		{Name: "reports", CertAuth: true},
+		{Name: "postgres-operator", CertAuth: true},
+	}
+	assert.NilError(t, reconciler.reconcilePostgresUserCertificates(ctx, cluster, root, users))
+
+	secret := &corev1.Secret{ObjectMeta: naming.PostgresUserCertSecret(cluster, "reports")}
+	assert.NilError(t, tClient.Get(ctx, client.ObjectKeyFromObject(secret), secret))
+	assert.Assert(t, metav1.IsControlledBy(secret, cluster))
+	assert.Equal(t, secret.Labels[naming.LabelCluster], cluster.Name)
+
+	// The common name of the certificate is the name of the user.
+	var certificate pki.Certificate
+	assert.NilError(t, certificate.UnmarshalText(secret.Data["tls.crt"]))
+	assert.Equal(t, certificate.CommonName(), "reports")
+	assert.Assert(t, len(secret.Data["tls.key"]) > 0)
+	assert.Assert(t, len(secret.Data["ca.crt"]) > 0)
+
+	none := &corev1.Secret{ObjectMeta: naming.PostgresUserCertSecret(cluster, "app")}
+	assert.Assert(t, apierrors.IsNotFound(tClient.Get(ctx, client.ObjectKeyFromObject(none), none)))
+
+	// The agents of instances trust certificates with the name of the
+	// operator, so no user gets one.
+	reserved := &corev1.Secret{ObjectMeta: naming.PostgresUserCertSecret(cluster, "postgres-operator")}
+	assert.Assert(t, apierrors.IsNotFound(tClient.Get(ctx, client.ObjectKeyFromObject(reserved), reserved)))
+	assert.Equal(t, len(recorder.Events), 1)
+	assert.Assert(t, cmp.Contains(<-recorder.Events, "InvalidUser"))
+
+	// The Secret is deleted when the user no longer uses certificates.
+	users[1].CertAuth = false
+	assert.NilError(t, reconciler.reconcilePostgresUserCertificates(ctx, cluster, root, users))
+	assert.Assert(t, apierrors.IsNotFound(tClient.Get(ctx, client.ObjectKeyFromObject(secret), secret)))
+}
+
+func TestReconcilePostgresUserSecretsImmutable(t *testing.T) {
+	ctx := context.Background()
+	_, tClient := setupKubernetes(t)
+	require.ParallelCapacity(t, 1)
+
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Client:   tClient,
+		Owner:    client.FieldOwner(t.Name()),
+		Recorder: recorder,
+	}
+
+	cluster := testCluster()
+	cluster.Namespace = setupNamespace(t, tClient).Name
+	cluster.Spec.Users = []v1beta1.PostgresUserSpec{{Name: "app"}}
+
+	assert.NilError(t, tClient.Create(ctx, cluster))
+	t.Cleanup(func() { assert.Check(t, tClient.Delete(ctx, cluster)) })
+
+	exists := func(name string) bool {
+		err := tClient.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: name}, &corev1.Secret{})
+		assert.Assert(t, err == nil || apierrors.IsNotFound(err), "%v", err)
+		return err == nil
+	}
+
+	_, secrets, err := reconciler.reconcilePostgresUserSecrets(ctx, cluster)
+	assert.NilError(t, err)
+	mutable := secrets["app"].Name
+	password := string(secrets["app"].Data["password"])
+	assert.Equal(t, mutable, naming.PostgresUserSecret(cluster, "app").Name)
+	assert.Assert(t, cluster.Status.UserSecrets == nil)
+
+	// The password moves to the first version of an immutable Secret.
+	cluster.Spec.ImmutableUserSecrets = initialize.Bool(true)
+	_, secrets, err = reconciler.reconcilePostgresUserSecrets(ctx, cluster)
+	assert.NilError(t, err)
+
+	v1 := &corev1.Secret{ObjectMeta: naming.PostgresUserSecretVersion(cluster, "app", 1)}
+	assert.Equal(t, secrets["app"].Name, v1.Name)
+	assert.Equal(t, string(secrets["app"].Data["password"]), password)
+	assert.DeepEqual(t, cluster.Status.UserSecrets, []v1beta1.PostgresUserSecretStatus{
+		{Name: "app", Secret: v1.Name, Version: 1},
+	})
+	assert.Equal(t, naming.CurrentPostgresUserSecret(cluster, "app").Name, v1.Name)
+
+	assert.NilError(t, tClient.Get(ctx, client.ObjectKeyFromObject(v1), v1))
+	assert.Assert(t, v1.Immutable != nil && *v1.Immutable)
+	assert.Assert(t, !exists(mutable))
+
+	// Nothing changes when nothing is requested.
+	_, secrets, err = reconciler.reconcilePostgresUserSecrets(ctx, cluster)
+	assert.NilError(t, err)
+	assert.Equal(t, secrets["app"].Name, v1.Name)
+	assert.Equal(t, len(recorder.Events), 0)
+
+	// The annotation writes a new password to the next version.
+	v1.Annotations = map[string]string{naming.RotatePassword: "true"}
+	assert.NilError(t, tClient.Update(ctx, v1))
+
+	_, secrets, err = reconciler.reconcilePostgresUserSecrets(ctx, cluster)
+	assert.NilError(t, err)
+	v2 := secrets["app"]
+	assert.Equal(t, v2.Name, naming.PostgresUserSecretVersion(cluster, "app", 2).Name)
+	assert.Assert(t, string(v2.Data["password"]) != password)
+	assert.Equal(t, cluster.Status.UserSecrets[0].Version, int32(2))
+	assert.Assert(t, cmp.Contains(<-recorder.Events, "with a new password"))
+	assert.Assert(t, !exists(v1.Name))
+
+	// The latest version is reused when status was not written.
+	cluster.Status.UserSecrets = []v1beta1.PostgresUserSecretStatus{
+		{Name: "app", Secret: v1.Name, Version: 1},
+	}
+	_, secrets, err = reconciler.reconcilePostgresUserSecrets(ctx, cluster)
+	assert.NilError(t, err)
+	assert.Equal(t, secrets["app"].Name, v2.Name)
+	assert.Equal(t, string(secrets["app"].Data["password"]), string(v2.Data["password"]))
+	assert.Equal(t, cluster.Status.UserSecrets[0].Version, int32(2))
+	assert.Equal(t, len(recorder.Events), 0)
+
+	// Other changes to the contents go to the next version, too.
+	cluster.Spec.Port = initialize.Int32(5433)
+	_, secrets, err = reconciler.reconcilePostgresUserSecrets(ctx, cluster)
+	assert.NilError(t, err)
+	v3 := secrets["app"]
+	assert.Equal(t, v3.Name, naming.PostgresUserSecretVersion(cluster, "app", 3).Name)
+	assert.Equal(t, string(v3.Data["password"]), string(v2.Data["password"]))
+	assert.Equal(t, string(v3.Data["port"]), "5433")
+	assert.Assert(t, cmp.Contains(<-recorder.Events, "connection details changed"))
+	assert.Assert(t, !exists(v2.Name))
+
+	// The password moves back to a mutable Secret.
+	cluster.Spec.ImmutableUserSecrets = nil
+	_, secrets, err = reconciler.reconcilePostgresUserSecrets(ctx, cluster)
+	assert.NilError(t, err)
+	assert.Equal(t, secrets["app"].Name, mutable)
+	assert.Equal(t, string(secrets["app"].Data["password"]), string(v3.Data["password"]))
+	assert.Assert(t, cluster.Status.UserSecrets == nil)
+	assert.Assert(t, !exists(v3.Name))
+}
@@ -0,0 +1,125 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/pki"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/postupgrade"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={get,create,patch,delete}
+
+// reconcileUpgradeUser issues a client certificate for the user that PGUpgrade
+// Jobs use after a major upgrade and creates that user in PostgreSQL. Both are
+// removed when the cluster is no longer annotated to allow an upgrade.
+func (r *Reconciler) reconcileUpgradeUser(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+	root *pki.RootCertificateAuthority,
+) error {
+	secret := naming.PostUpgradeSecret(cluster)
+	secret.Labels = map[string]string{naming.LabelPostUpgrade: ""}
+
+	_, err := r.reconcileClientCertificateSecret(ctx, cluster, root, secret,
+		postupgrade.User, postupgrade.Enabled(cluster))
+
+	if err == nil {
+		err = r.reconcileUpgradeUserInPostgreSQL(ctx, cluster, instances)
+	}
+	return err
+}
+
+// reconcileUpgradeUserInPostgreSQL adds or removes the upgrade user using the
+// writable instance.
+func (r *Reconciler) reconcileUpgradeUserInPostgreSQL(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) error {
+	enabled := postupgrade.Enabled(cluster)
+
+	// Nothing was created and nothing is requested.
+	if !enabled && (cluster.Status.UpgradeUser == nil || cluster.Status.UpgradeUser.Revision == "") {
+		return nil
+	}
+
+	pod, _ := instances.writablePod(naming.ContainerDatabase)
+	if pod == nil {
+		if cluster.Status.UpgradeUser == nil {
+			cluster.Status.UpgradeUser = new(v1beta1.UpgradeUserStatus)
+		}
+		return nil
+	}
+
+	action := func(ctx context.Context, exec postgres.Executor) error {
+		return postupgrade.EnableInPostgreSQL(ctx, exec)
+	}
+	if !enabled {
+		action = func(ctx context.Context, exec postgres.Executor) error {
+			return postupgrade.DisableInPostgreSQL(ctx, exec)
+		}
+	}
+
+	revision, err := safeHash32(func(hasher io.Writer) error {
+		// Discard log messages from the postupgrade package about executing
+		// SQL. Nothing is being "executed" yet.
+		return action(logging.NewContext(ctx, logging.Discard()), func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			_, err := io.Copy(hasher, stdin)
+			if err == nil {
+				_, err = fmt.Fprint(hasher, command)
+			}
+			return err
+		})
+	})
+
+	status := cluster.Status.UpgradeUser
+	if status == nil {
+		status = new(v1beta1.UpgradeUserStatus)
+	}
+
+	if err == nil && revision != status.Revision {
+		// Include the revision hash in any log messages.
+		ctx := logging.NewContext(ctx, logging.FromContext(ctx).WithValues("revision", revision))
+
+		err = action(ctx, func(_ context.Context, stdin io.Reader,
+			stdout, stderr io.Writer, command ...string) error {
+			return r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase, stdin, stdout, stderr, command...)
+		})
+		if err == nil {
+			status.Revision = revision
+		}
+	}
+
+	if err == nil {
+		cluster.Status.UpgradeUser = status
+		if !enabled {
+			cluster.Status.UpgradeUser = nil
+
+			// Remember the finished upgrade so the user is not created again.
+			if status.Completed != "" && status.Completed == cluster.GetAnnotations()[naming.AllowUpgrade] {
+				cluster.Status.UpgradeUser = &v1beta1.UpgradeUserStatus{Completed: status.Completed}
+			}
+		}
+	}
+	return err
+}
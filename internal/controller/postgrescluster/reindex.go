@@ -0,0 +1,255 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// reindexReason returns why the indexes of cluster should be rebuilt, if at
+// all. Changed collations come first because their indexes may return wrong
+// results; indexes from before PostgreSQL 12 are only slower.
+func reindexReason(cluster *v1beta1.PostgresCluster, status *v1beta1.ReindexStatus) postgres.ReindexReason {
+	conditions := cluster.Status.Conditions
+
+	if meta.IsStatusConditionTrue(conditions, v1beta1.CollationVersionMismatch) {
+		return postgres.ReindexCollationVersionChanged
+	}
+
+	if checked := meta.FindStatusCondition(conditions, v1beta1.IntegrityChecked); checked != nil &&
+		checked.Status == metav1.ConditionFalse && checked.Reason == integrityChecksJob.failedReason &&
+		cluster.Status.IntegrityChecks != nil && cluster.Status.IntegrityChecks.LastCheckTime != nil &&
+		(status.IntegrityCheckTime == nil ||
+			!status.IntegrityCheckTime.Equal(cluster.Status.IntegrityChecks.LastCheckTime)) {
+		return postgres.ReindexIntegrityCheckFailed
+	}
+
+	if status.PostgresVersion > 0 && status.PostgresVersion < 12 &&
+		cluster.Spec.PostgresVersion >= 12 {
+		return postgres.ReindexMajorUpgrade
+	}
+
+	return ""
+}
+
+// reconcileReindex rebuilds indexes that may be corrupt or outdated, one batch
+// of at most spec.reindex.concurrency indexes at a time. Progress is recorded
+// in cluster.Status so that it continues where it left off after a restart of
+// the operator or a failover. It is reported in the IndexesRebuilt condition.
+func (r *Reconciler) reconcileReindex(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) reconcile.Result {
+	log := logging.FromContext(ctx)
+	spec := cluster.Spec.Reindex
+
+	if spec == nil {
+		cluster.Status.Reindex = nil
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.IndexesRebuilt)
+		return reconcile.Result{}
+	}
+
+	version := cluster.Spec.PostgresVersion
+	if version < 12 {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "ReindexUnsupported",
+			"Rebuilding indexes concurrently requires PostgreSQL 12 or later, not %d", version)
+		return reconcile.Result{}
+	}
+
+	pod, _ := instances.writablePod(naming.ContainerDatabase)
+	if pod == nil {
+		return reconcile.Result{}
+	}
+
+	exec := func(_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string) error {
+		return r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase, stdin, stdout, stderr, command...)
+	}
+
+	status := cluster.Status.Reindex
+	if status == nil {
+		status = new(v1beta1.ReindexStatus)
+		cluster.Status.Reindex = status
+	}
+	retry := reconcile.Result{RequeueAfter: time.Minute}
+
+	if status.Reason == "" {
+		reason := reindexReason(cluster, status)
+		if reason == "" {
+			status.PostgresVersion = version
+			return reconcile.Result{}
+		}
+
+		counts, err := postgres.CountReindexCandidates(ctx, exec, reason, version)
+		if err != nil {
+			log.Error(err, "unable to count indexes to rebuild")
+			return retry
+		}
+
+		var total int32
+		for _, count := range counts {
+			total += count
+		}
+
+		now := metav1.Now()
+		*status = v1beta1.ReindexStatus{
+			Reason:             string(reason),
+			PostgresVersion:    status.PostgresVersion,
+			IntegrityCheckTime: status.IntegrityCheckTime,
+			Total:              total,
+			StartTime:          &now,
+		}
+		if reason == postgres.ReindexIntegrityCheckFailed {
+			status.IntegrityCheckTime = cluster.Status.IntegrityChecks.LastCheckTime.DeepCopy()
+		}
+
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "ReindexStarted",
+			"Rebuilding %d indexes because %s", total, reason)
+	}
+
+	reason := postgres.ReindexReason(status.Reason)
+	concurrency := int32(1)
+	if spec.Concurrency != nil {
+		concurrency = *spec.Concurrency
+	}
+
+	// Find the next indexes to rebuild, moving on to the next database when
+	// there are none left in this one.
+	var indexes []string
+	var err error
+	for err == nil && status.Reason != "" && len(indexes) == 0 {
+		if status.Database != "" {
+			indexes, err = postgres.ReindexCandidates(ctx, exec,
+				status.Database, reason, version, status.Index, concurrency)
+		}
+		if err == nil && len(indexes) == 0 {
+			var counts map[string]int32
+			counts, err = postgres.CountReindexCandidates(ctx, exec, reason, version)
+
+			next := ""
+			for database := range counts {
+				if database > status.Database && (next == "" || database < next) {
+					next = database
+				}
+			}
+			if err == nil {
+				status.Database, status.Index = next, ""
+			}
+			if err == nil && next == "" {
+				err = r.finishReindex(ctx, cluster, exec)
+			}
+		}
+	}
+	if err != nil {
+		log.Error(err, "unable to rebuild indexes")
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "ReindexFailed",
+			"Unable to rebuild indexes: %v", err)
+		setReindexCondition(cluster, metav1.ConditionFalse, "RebuildFailed", err.Error())
+		return retry
+	}
+	if status.Reason == "" {
+		return reconcile.Result{}
+	}
+
+	// Rebuild the indexes at the same time and wait for all of them.
+	errs := make([]error, len(indexes))
+	var wg sync.WaitGroup
+	for i := range indexes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = postgres.RebuildIndex(ctx, exec, status.Database, indexes[i],
+				reason == postgres.ReindexIntegrityCheckFailed)
+		}(i)
+	}
+	wg.Wait()
+
+	// Advance past the indexes that were rebuilt before the first failure so
+	// that those after it are attempted again.
+	for i := range indexes {
+		if errs[i] != nil {
+			err = errs[i]
+			log.Error(err, "unable to rebuild index", "database", status.Database, "index", indexes[i])
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "ReindexFailed",
+				"Unable to rebuild index %s in database %q: %v", indexes[i], status.Database, err)
+			break
+		}
+		status.Index = indexes[i]
+		status.Completed++
+	}
+
+	message := fmt.Sprintf("Rebuilt %d of %d indexes because %s", status.Completed, status.Total, reason)
+	if err != nil {
+		setReindexCondition(cluster, metav1.ConditionFalse, "RebuildFailed", message)
+		return retry
+	}
+
+	// Continue with the next batch soon without waiting for other changes.
+	setReindexCondition(cluster, metav1.ConditionFalse, "Rebuilding", message)
+	return reconcile.Result{RequeueAfter: time.Second}
+}
+
+// finishReindex records that every index has been rebuilt. Collation versions
+// are refreshed so that the CollationVersionMismatch condition clears the next
+// time they are compared.
+func (r *Reconciler) finishReindex(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, exec postgres.Executor,
+) error {
+	status := cluster.Status.Reindex
+
+	if status.Reason == string(postgres.ReindexCollationVersionChanged) {
+		if err := postgres.RecordCollationVersions(ctx, exec, cluster.Spec.PostgresVersion); err != nil {
+			return err
+		}
+		if cluster.Status.Collations != nil {
+			cluster.Status.Collations.Image = ""
+		}
+	}
+
+	message := fmt.Sprintf("Rebuilt %d indexes because %s", status.Completed, status.Reason)
+	r.Recorder.Event(cluster, corev1.EventTypeNormal, "ReindexCompleted", message)
+	setReindexCondition(cluster, metav1.ConditionTrue, "Rebuilt", message)
+
+	now := metav1.Now()
+	status.Reason = ""
+	status.PostgresVersion = cluster.Spec.PostgresVersion
+	status.CompletionTime = &now
+	return nil
+}
+
+// setReindexCondition sets the IndexesRebuilt condition of cluster.
+func setReindexCondition(
+	cluster *v1beta1.PostgresCluster, status metav1.ConditionStatus, reason, message string,
+) {
+	meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+		Type:               v1beta1.IndexesRebuilt,
+		ObservedGeneration: cluster.GetGeneration(),
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+	})
+}
@@ -0,0 +1,260 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestReindexReason(t *testing.T) {
+	checked := metav1.NewTime(time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC))
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Spec.PostgresVersion = 16
+
+	t.Run("Nothing", func(t *testing.T) {
+		assert.Equal(t, reindexReason(cluster, &v1beta1.ReindexStatus{}), postgres.ReindexReason(""))
+		assert.Equal(t, reindexReason(cluster, &v1beta1.ReindexStatus{PostgresVersion: 15}),
+			postgres.ReindexReason(""))
+	})
+
+	t.Run("CollationVersionChanged", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type: v1beta1.CollationVersionMismatch, Status: metav1.ConditionTrue, Reason: "VersionsChanged",
+		})
+		assert.Equal(t, reindexReason(cluster, &v1beta1.ReindexStatus{PostgresVersion: 11}),
+			postgres.ReindexCollationVersionChanged)
+	})
+
+	t.Run("IntegrityCheckFailed", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Status.IntegrityChecks = &v1beta1.IntegrityChecksStatus{LastCheckTime: &checked}
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type: v1beta1.IntegrityChecked, Status: metav1.ConditionFalse, Reason: "CheckFailed",
+		})
+		assert.Equal(t, reindexReason(cluster, &v1beta1.ReindexStatus{}),
+			postgres.ReindexIntegrityCheckFailed)
+
+		// The same failed check does not rebuild indexes again.
+		assert.Equal(t, reindexReason(cluster, &v1beta1.ReindexStatus{
+			IntegrityCheckTime: checked.DeepCopy(),
+		}), postgres.ReindexReason(""))
+	})
+
+	t.Run("MajorUpgrade", func(t *testing.T) {
+		assert.Equal(t, reindexReason(cluster, &v1beta1.ReindexStatus{PostgresVersion: 11}),
+			postgres.ReindexMajorUpgrade)
+
+		cluster := cluster.DeepCopy()
+		cluster.Spec.PostgresVersion = 11
+		assert.Equal(t, reindexReason(cluster, &v1beta1.ReindexStatus{PostgresVersion: 10}),
+			postgres.ReindexReason(""))
+	})
+}
+
+func TestReconcileReindex(t *testing.T) {
+	ctx := context.Background()
+
+	pod := &corev1.Pod{}
+	pod.Namespace, pod.Name = "ns1", "hippo-00-abcd-0"
+	pod.Annotations = map[string]string{"status": `{"role":"master"}`}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  naming.ContainerDatabase,
+		State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+	}}
+
+	instances := &observedInstances{forCluster: []*Instance{
+		{Name: "hippo-00-abcd", Pods: []*corev1.Pod{pod}},
+	}}
+
+	candidates := map[string][]string{
+		"app":      {"public.a", "public.b", "public.c"},
+		"postgres": {"public.z"},
+	}
+
+	var mutex sync.Mutex
+	var rebuilt []string
+	var recorded int
+	failing := ""
+
+	variable := func(command []string, name string) string {
+		for _, arg := range command {
+			if strings.HasPrefix(arg, "--set="+name+"=") {
+				return strings.TrimPrefix(arg, "--set="+name+"=")
+			}
+		}
+		return ""
+	}
+
+	recorder := record.NewFakeRecorder(20)
+	reconciler := &Reconciler{
+		Recorder: recorder,
+		PodExec: func(_, _, container string, stdin io.Reader, stdout, _ io.Writer, command ...string) error {
+			assert.Equal(t, container, naming.ContainerDatabase)
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			sql := string(b)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			switch {
+			case strings.Contains(sql, "REFRESH VERSION"):
+				recorded++
+			case strings.Contains(sql, "pg_catalog.count(*)"):
+				for database, indexes := range candidates {
+					_, _ = stdout.Write([]byte(database + "|" + strconv.Itoa(len(indexes)) + "\n"))
+				}
+			case strings.Contains(sql, "ORDER BY name"):
+				after := variable(command, "after")
+				limit, _ := strconv.Atoi(variable(command, "limit"))
+				for _, index := range candidates[variable(command, "database")] {
+					if index > after && limit > 0 {
+						_, _ = stdout.Write([]byte(index + "\n"))
+						limit--
+					}
+				}
+			case strings.Contains(sql, "REINDEX INDEX CONCURRENTLY"):
+				index := variable(command, "index")
+				if index == failing {
+					return io.ErrUnexpectedEOF
+				}
+				rebuilt = append(rebuilt, variable(command, "database")+"/"+index)
+			}
+			return nil
+		},
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+	cluster.Spec.PostgresVersion = 16
+	cluster.Spec.Reindex = &v1beta1.ReindexSpec{Concurrency: initialize.Int32(2)}
+
+	t.Run("Disabled", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Reindex = nil
+		cluster.Status.Reindex = &v1beta1.ReindexStatus{PostgresVersion: 16}
+		setReindexCondition(cluster, metav1.ConditionTrue, "Rebuilt", "")
+
+		reconciler.reconcileReindex(ctx, cluster, instances)
+		assert.Assert(t, cluster.Status.Reindex == nil)
+		assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.IndexesRebuilt) == nil)
+	})
+
+	t.Run("Unsupported", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.PostgresVersion = 11
+
+		reconciler.reconcileReindex(ctx, cluster, instances)
+		assert.Assert(t, cluster.Status.Reindex == nil)
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "ReindexUnsupported"))
+	})
+
+	t.Run("Nothing", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+
+		result := reconciler.reconcileReindex(ctx, cluster, instances)
+		assert.Equal(t, result.RequeueAfter, time.Duration(0))
+		assert.DeepEqual(t, cluster.Status.Reindex, &v1beta1.ReindexStatus{PostgresVersion: 16})
+	})
+
+	t.Run("CollationVersionChanged", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Status.Collations = &v1beta1.CollationsStatus{Image: "postgres:2"}
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type: v1beta1.CollationVersionMismatch, Status: metav1.ConditionTrue, Reason: "VersionsChanged",
+		})
+		rebuilt, recorded = nil, 0
+
+		// The first batch is two indexes in the first database.
+		result := reconciler.reconcileReindex(ctx, cluster, instances)
+		assert.Assert(t, result.RequeueAfter > 0)
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "Rebuilding 4 indexes because CollationVersionChanged"))
+
+		status := cluster.Status.Reindex
+		assert.Equal(t, status.Reason, "CollationVersionChanged")
+		assert.Equal(t, status.Database, "app")
+		assert.Equal(t, status.Index, "public.b")
+		assert.Equal(t, status.Total, int32(4))
+		assert.Equal(t, status.Completed, int32(2))
+		assert.Assert(t, status.StartTime != nil)
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.IndexesRebuilt)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "Rebuilding")
+		assert.Equal(t, condition.Message, "Rebuilt 2 of 4 indexes because CollationVersionChanged")
+
+		// Indexes that fail are attempted again.
+		failing = "public.c"
+		result = reconciler.reconcileReindex(ctx, cluster, instances)
+		assert.Equal(t, result.RequeueAfter, time.Minute)
+		assert.Equal(t, status.Index, "public.b")
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "ReindexFailed"))
+
+		condition = meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.IndexesRebuilt)
+		assert.Equal(t, condition.Reason, "RebuildFailed")
+
+		failing = ""
+		reconciler.reconcileReindex(ctx, cluster, instances)
+		assert.Equal(t, status.Index, "public.c")
+
+		// The next database comes after the first.
+		reconciler.reconcileReindex(ctx, cluster, instances)
+		assert.Equal(t, status.Database, "postgres")
+		assert.Equal(t, status.Index, "public.z")
+		assert.Equal(t, status.Completed, int32(4))
+
+		// Collation versions are recorded when every index has been rebuilt.
+		result = reconciler.reconcileReindex(ctx, cluster, instances)
+		assert.Equal(t, result.RequeueAfter, time.Duration(0))
+		assert.Equal(t, recorded, 1)
+		assert.Equal(t, status.Reason, "")
+		assert.Equal(t, status.PostgresVersion, 16)
+		assert.Assert(t, status.CompletionTime != nil)
+		assert.Equal(t, cluster.Status.Collations.Image, "")
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "ReindexCompleted"))
+
+		condition = meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.IndexesRebuilt)
+		assert.Equal(t, condition.Status, metav1.ConditionTrue)
+		assert.Equal(t, condition.Message, "Rebuilt 4 indexes because CollationVersionChanged")
+
+		sort.Strings(rebuilt)
+		assert.DeepEqual(t, rebuilt, []string{
+			"app/public.a", "app/public.b", "app/public.c", "postgres/public.z",
+		})
+	})
+}
@@ -0,0 +1,290 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// resourceUsageInterval is how long to wait between measurements of usage.
+const resourceUsageInterval = 5 * time.Minute
+
+// These gauges export [v1beta1.ResourcesStatus] of every PostgresCluster.
+var (
+	clusterResourceCapacity = newResourceGauge("capacity",
+		"The storage capacity of bound PersistentVolumeClaims of a PostgresCluster")
+	clusterResourceLimits = newResourceGauge("limits",
+		"The CPU and memory limits of the containers of a PostgresCluster")
+	clusterResourceRequests = newResourceGauge("requests",
+		"The CPU, memory, and storage requested by the Pods and volumes of a PostgresCluster")
+	clusterResourceUsage = newResourceGauge("usage",
+		"The CPU, memory, and storage used by the Pods and volumes of a PostgresCluster")
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		clusterResourceCapacity, clusterResourceLimits,
+		clusterResourceRequests, clusterResourceUsage)
+}
+
+func newResourceGauge(name, help string) *prometheus.GaugeVec {
+	return prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "postgrescluster",
+		Name:      "resource_" + name,
+		Help:      help + ". CPU is in cores; memory and storage are in bytes.",
+	}, []string{"namespace", "name", "resource"})
+}
+
+// setResourceMetrics exports status as gauges labeled with the namespace and
+// name of a PostgresCluster. A nil status removes the gauges of that cluster.
+func setResourceMetrics(cluster client.ObjectKey, status *v1beta1.ResourcesStatus) {
+	if status == nil {
+		status = &v1beta1.ResourcesStatus{}
+	}
+
+	for gauge, list := range map[*prometheus.GaugeVec]corev1.ResourceList{
+		clusterResourceCapacity: status.Capacity,
+		clusterResourceLimits:   status.Limits,
+		clusterResourceRequests: status.Requests,
+		clusterResourceUsage:    status.Usage,
+	} {
+		for _, name := range []corev1.ResourceName{
+			corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceStorage,
+		} {
+			labels := []string{cluster.Namespace, cluster.Name, string(name)}
+
+			if quantity, ok := list[name]; ok {
+				gauge.WithLabelValues(labels...).Set(quantity.AsApproximateFloat64())
+			} else {
+				gauge.DeleteLabelValues(labels...)
+			}
+		}
+	}
+}
+
+// addResource adds quantity to the total of name in list.
+func addResource(list corev1.ResourceList, name corev1.ResourceName, quantity resource.Quantity) {
+	total := list[name]
+	total.Add(quantity)
+	list[name] = total
+}
+
+// +kubebuilder:rbac:groups="",resources="pods",verbs={list}
+// +kubebuilder:rbac:groups="metrics.k8s.io",resources="pods",verbs={list}
+
+// reconcileResourcesStatus totals the requests, limits, and capacity of the Pods
+// and volumes of cluster in its status. Usage changes without changing any
+// Kubernetes objects, so it is measured periodically and this returns a result
+// that measures it again later. Errors are logged rather than returned because
+// nothing else depends on these totals.
+func (r *Reconciler) reconcileResourcesStatus(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, volumes []corev1.PersistentVolumeClaim,
+	instances *observedInstances,
+) reconcile.Result {
+	log := logging.FromContext(ctx)
+
+	pods := &corev1.PodList{}
+	selector, err := naming.AsSelector(naming.Cluster(cluster.Name))
+	if err == nil {
+		err = r.Client.List(ctx, pods,
+			client.InNamespace(cluster.Namespace),
+			client.MatchingLabelsSelector{Selector: selector},
+		)
+	}
+	if err != nil {
+		log.Error(err, "unable to total cluster resources")
+		return reconcile.Result{}
+	}
+
+	status := totalResources(pods.Items, volumes)
+
+	// Keep the previous measurement of usage until it is time for another.
+	if previous := cluster.Status.Resources; previous != nil && previous.UsageTime != nil {
+		status.Usage, status.UsageTime = previous.Usage, previous.UsageTime
+	}
+
+	now := time.Now()
+	if status.UsageTime == nil || !now.Before(status.UsageTime.Add(resourceUsageInterval)) {
+		status.Usage = r.measureResourceUsage(ctx, cluster, selector, instances)
+		status.UsageTime = &metav1.Time{Time: now.Truncate(time.Second)}
+	}
+
+	cluster.Status.Resources = status
+	setResourceMetrics(client.ObjectKeyFromObject(cluster), status)
+
+	return reconcile.Result{RequeueAfter: time.Until(status.UsageTime.Add(resourceUsageInterval))}
+}
+
+// totalResources returns the CPU and memory of the containers of pods that
+// have not finished, and the storage of volumes.
+func totalResources(
+	pods []corev1.Pod, volumes []corev1.PersistentVolumeClaim,
+) *v1beta1.ResourcesStatus {
+	requests := corev1.ResourceList{}
+	limits := corev1.ResourceList{}
+	capacity := corev1.ResourceList{}
+
+	for i := range pods {
+		if phase := pods[i].Status.Phase; phase == corev1.PodSucceeded || phase == corev1.PodFailed {
+			continue
+		}
+		for _, container := range pods[i].Spec.Containers {
+			for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+				if quantity, ok := container.Resources.Requests[name]; ok {
+					addResource(requests, name, quantity)
+				}
+				if quantity, ok := container.Resources.Limits[name]; ok {
+					addResource(limits, name, quantity)
+				}
+			}
+		}
+	}
+
+	for i := range volumes {
+		if quantity, ok := volumes[i].Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+			addResource(requests, corev1.ResourceStorage, quantity)
+		}
+		if quantity, ok := volumes[i].Status.Capacity[corev1.ResourceStorage]; ok &&
+			volumes[i].Status.Phase == corev1.ClaimBound {
+			addResource(capacity, corev1.ResourceStorage, quantity)
+		}
+	}
+
+	status := &v1beta1.ResourcesStatus{}
+	if len(requests) > 0 {
+		status.Requests = requests
+	}
+	if len(limits) > 0 {
+		status.Limits = limits
+	}
+	if len(capacity) > 0 {
+		status.Capacity = capacity
+	}
+	return status
+}
+
+// measureResourceUsage returns the CPU and memory used by the Pods of cluster
+// and the storage used by its PostgreSQL volumes. Resources that cannot be
+// measured are omitted.
+func (r *Reconciler) measureResourceUsage(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, selector labels.Selector,
+	instances *observedInstances,
+) corev1.ResourceList {
+	log := logging.FromContext(ctx)
+	usage := corev1.ResourceList{}
+
+	// The metrics API is optional; it is often provided by metrics-server.
+	// - https://github.com/kubernetes-sigs/metrics-server
+	podMetrics := &unstructured.UnstructuredList{}
+	podMetrics.SetAPIVersion("metrics.k8s.io/v1beta1")
+	podMetrics.SetKind("PodMetricsList")
+
+	err := r.Client.List(ctx, podMetrics,
+		client.InNamespace(cluster.Namespace),
+		client.MatchingLabelsSelector{Selector: selector},
+	)
+	if err == nil {
+		addPodMetrics(usage, podMetrics.Items)
+	} else if !meta.IsNoMatchError(err) {
+		log.Error(err, "unable to measure Pod resources")
+	}
+
+	if instances != nil {
+		for _, instance := range instances.forCluster {
+			if running, known := instance.IsRunning(naming.ContainerDatabase); !running || !known {
+				continue
+			}
+
+			pod := instance.Pods[0]
+			var stdout, stderr bytes.Buffer
+			err := r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase,
+				nil, &stdout, &stderr, "df", "--block-size=1", "--output=target,used")
+
+			if err == nil {
+				addVolumeUsage(usage, stdout.String())
+			} else {
+				log.Error(err, "unable to measure volume usage",
+					"pod", pod.Name, "stderr", stderr.String())
+			}
+		}
+	}
+
+	if len(usage) == 0 {
+		return nil
+	}
+	return usage
+}
+
+// addPodMetrics adds the CPU and memory used by the containers of items, which
+// are PodMetrics of the Kubernetes metrics API, to usage.
+func addPodMetrics(usage corev1.ResourceList, items []unstructured.Unstructured) {
+	for _, item := range items {
+		containers, _, _ := unstructured.NestedSlice(item.Object, "containers")
+		for _, container := range containers {
+			object, _ := container.(map[string]interface{})
+			used, _, _ := unstructured.NestedStringMap(object, "usage")
+
+			for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+				if quantity, err := resource.ParseQuantity(used[string(name)]); err == nil {
+					addResource(usage, name, quantity)
+				}
+			}
+		}
+	}
+}
+
+// addVolumeUsage adds the bytes used by PostgreSQL data, WAL, and tablespace
+// volumes to usage. The output is from "df --output=target,used" in the
+// database container.
+func addVolumeUsage(usage corev1.ResourceList, output string) {
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+
+		// These are the mount points of the volumes in the database container.
+		// See [postgres.InstancePod].
+		target := fields[0]
+		if target != "/pgdata" && target != "/pgwal" &&
+			!strings.HasPrefix(target, "/tablespaces/") {
+			continue
+		}
+		if used, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+			addResource(usage, corev1.ResourceStorage,
+				*resource.NewQuantity(used, resource.BinarySI))
+		}
+	}
+}
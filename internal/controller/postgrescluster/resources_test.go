@@ -0,0 +1,211 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestTotalResources(t *testing.T) {
+	container := func(cpu, memory string) corev1.Container {
+		return corev1.Container{Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse(memory),
+			},
+		}}
+	}
+	pods := []corev1.Pod{
+		{
+			Spec:   corev1.PodSpec{Containers: []corev1.Container{container("500m", "1Gi"), container("1", "512Mi")}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		{
+			Spec:   corev1.PodSpec{Containers: []corev1.Container{container("250m", "256Mi"), {}}},
+			Status: corev1.PodStatus{Phase: corev1.PodPending},
+		},
+		{
+			// Finished Pods do not count.
+			Spec:   corev1.PodSpec{Containers: []corev1.Container{container("4", "4Gi")}},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+		},
+	}
+
+	volume := func(request, capacity string, phase corev1.PersistentVolumeClaimPhase) corev1.PersistentVolumeClaim {
+		var pvc corev1.PersistentVolumeClaim
+		pvc.Spec.Resources.Requests = corev1.ResourceList{
+			corev1.ResourceStorage: resource.MustParse(request),
+		}
+		if capacity != "" {
+			pvc.Status.Capacity = corev1.ResourceList{
+				corev1.ResourceStorage: resource.MustParse(capacity),
+			}
+		}
+		pvc.Status.Phase = phase
+		return pvc
+	}
+	volumes := []corev1.PersistentVolumeClaim{
+		volume("1Gi", "2Gi", corev1.ClaimBound),
+		volume("1Gi", "", corev1.ClaimPending),
+	}
+
+	status := totalResources(pods, volumes)
+	assert.Equal(t, status.Requests.Cpu().String(), "1750m")
+	assert.Equal(t, status.Requests.Memory().String(), "1792Mi")
+	assert.Equal(t, status.Requests.Storage().String(), "2Gi")
+	assert.Equal(t, status.Limits.Memory().String(), "1792Mi")
+	assert.Equal(t, len(status.Limits), 1, "no CPU limits")
+	assert.Equal(t, status.Capacity.Storage().String(), "2Gi")
+
+	status = totalResources(nil, nil)
+	assert.Assert(t, status.Requests == nil)
+	assert.Assert(t, status.Limits == nil)
+	assert.Assert(t, status.Capacity == nil)
+}
+
+func TestAddPodMetrics(t *testing.T) {
+	usage := corev1.ResourceList{}
+	addPodMetrics(usage, []unstructured.Unstructured{
+		{Object: map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "database", "usage": map[string]interface{}{
+					"cpu": "250000000n", "memory": "1024Ki",
+				}},
+				map[string]interface{}{"name": "pgbackrest", "usage": map[string]interface{}{
+					"cpu": "1m", "memory": "1Mi",
+				}},
+			},
+		}},
+		{Object: map[string]interface{}{"containers": "wrong"}},
+	})
+
+	assert.Equal(t, usage.Cpu().MilliValue(), int64(251))
+	assert.Equal(t, usage.Memory().Value(), int64(2<<20))
+}
+
+func TestAddVolumeUsage(t *testing.T) {
+	usage := corev1.ResourceList{}
+	addVolumeUsage(usage, `
+Mounted on                    Used
+/                       4096000000
+/pgdata                  104857600
+/pgwal                    52428800
+/tablespaces/trial         1048576
+/dev/shm                         0
+/etc/hosts                 8192000
+`)
+	assert.Equal(t, usage.Storage().Value(), int64(104857600+52428800+1048576))
+
+	usage = corev1.ResourceList{}
+	addVolumeUsage(usage, "Mounted on Used\n")
+	assert.Equal(t, len(usage), 0)
+}
+
+func TestReconcileResourcesStatus(t *testing.T) {
+	ctx := context.Background()
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+
+	pod := &corev1.Pod{}
+	pod.Namespace, pod.Name = "ns1", "hippo-instance-abcd-0"
+	pod.Labels = map[string]string{naming.LabelCluster: "hippo"}
+	pod.Spec.Containers = []corev1.Container{{
+		Name: naming.ContainerDatabase,
+		Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("1"),
+		}},
+	}}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  naming.ContainerDatabase,
+		State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+	}}
+	instances := &observedInstances{forCluster: []*Instance{{Pods: []*corev1.Pod{pod}}}}
+
+	var calls int
+	r := &Reconciler{
+		Client: fake.NewClientBuilder().WithObjects(pod).Build(),
+		PodExec: func(namespace, name, container string, _ io.Reader, stdout, _ io.Writer, command ...string) error {
+			calls++
+			assert.Equal(t, name, pod.Name)
+			assert.Equal(t, container, naming.ContainerDatabase)
+			assert.DeepEqual(t, command, []string{"df", "--block-size=1", "--output=target,used"})
+			_, err := stdout.Write([]byte("Mounted on Used\n/pgdata 1024\n"))
+			return err
+		},
+	}
+
+	result := r.reconcileResourcesStatus(ctx, cluster, nil, instances)
+	assert.Assert(t, result.RequeueAfter > 4*time.Minute)
+	assert.Equal(t, calls, 1)
+
+	status := cluster.Status.Resources
+	assert.Assert(t, status != nil)
+	assert.Equal(t, status.Requests.Cpu().String(), "1")
+	assert.Equal(t, status.Usage.Storage().String(), "1Ki")
+	assert.Assert(t, status.UsageTime != nil)
+
+	// The metrics API is missing, so CPU and memory usage are unknown.
+	assert.Equal(t, len(status.Usage), 1)
+
+	key := client.ObjectKeyFromObject(cluster)
+	assert.Equal(t, testutil.ToFloat64(clusterResourceRequests.WithLabelValues(
+		key.Namespace, key.Name, "cpu")), float64(1))
+	assert.Equal(t, testutil.ToFloat64(clusterResourceUsage.WithLabelValues(
+		key.Namespace, key.Name, "storage")), float64(1024))
+
+	t.Run("UsageIsRecent", func(t *testing.T) {
+		previous := status.UsageTime.DeepCopy()
+		r.reconcileResourcesStatus(ctx, cluster, nil, instances)
+
+		assert.Equal(t, calls, 1, "expected no measurement")
+		assert.DeepEqual(t, cluster.Status.Resources.UsageTime, previous)
+		assert.Equal(t, cluster.Status.Resources.Usage.Storage().String(), "1Ki")
+	})
+
+	t.Run("UsageIsOld", func(t *testing.T) {
+		cluster.Status.Resources.UsageTime = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+		r.reconcileResourcesStatus(ctx, cluster, nil, instances)
+
+		assert.Equal(t, calls, 2, "expected another measurement")
+		assert.Assert(t, time.Since(cluster.Status.Resources.UsageTime.Time) < time.Minute)
+	})
+
+	t.Run("Forget", func(t *testing.T) {
+		setResourceMetrics(key, nil)
+
+		assert.Equal(t, testutil.CollectAndCount(clusterResourceRequests), 0)
+		assert.Equal(t, testutil.CollectAndCount(clusterResourceUsage), 0)
+	})
+}
@@ -0,0 +1,132 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// restorePointsKept is the number of restore points kept in the status of a
+// PostgresCluster.
+const restorePointsKept = 20
+
+// reconcileRestorePoints creates the restore point named by the restore-point
+// annotation of cluster using the writable instance. The name, location, and
+// time of the restore point are stored in the cluster status so that it is
+// created only once and can later be the target of a restore.
+// - https://www.postgresql.org/docs/current/functions-admin.html#FUNCTIONS-ADMIN-BACKUP
+func (r *Reconciler) reconcileRestorePoints(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) error {
+	name := cluster.GetAnnotations()[naming.RestorePoint]
+	if name == "" {
+		return nil
+	}
+	for _, point := range cluster.Status.RestorePoints {
+		if point.Name == name {
+			return nil
+		}
+	}
+
+	// PostgreSQL truncates longer names.
+	if len(name) > 63 {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidRestorePoint",
+			"Restore point %q is longer than 63 characters", name)
+		return nil
+	}
+
+	// Restore points are written to WAL by a primary.
+	if cluster.Spec.Standby != nil && cluster.Spec.Standby.Enabled {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidRestorePoint",
+			"Restore point %q cannot be created in a standby cluster", name)
+		return nil
+	}
+
+	pod, _ := instances.writablePod(naming.ContainerDatabase)
+	if pod == nil {
+		return nil
+	}
+
+	exec := func(_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string) error {
+		return r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase, stdin, stdout, stderr, command...)
+	}
+
+	point, err := createRestorePoint(ctx, exec, name)
+	if err != nil {
+		return err
+	}
+
+	r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "RestorePointCreated",
+		"Created restore point %q at %s", point.Name, point.LSN)
+
+	points := append(cluster.Status.RestorePoints, point)
+	if len(points) > restorePointsKept {
+		points = points[len(points)-restorePointsKept:]
+	}
+	cluster.Status.RestorePoints = points
+	return nil
+}
+
+// createRestorePoint creates a restore point named name and returns its
+// location and time.
+func createRestorePoint(
+	ctx context.Context, exec postgres.Executor, name string,
+) (v1beta1.RestorePointStatus, error) {
+	point := v1beta1.RestorePointStatus{Name: name}
+
+	stdout, stderr, err := exec.Exec(ctx, strings.NewReader(strings.Join([]string{
+		`\pset format unaligned`,
+		`\pset tuples_only on`,
+		`SELECT pg_catalog.pg_create_restore_point(:'name'),`,
+		`       pg_catalog.to_json(pg_catalog.now()) #>> '{}';`,
+	}, "\n")), map[string]string{
+		"name": name,
+
+		"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+		"QUIET":         "on", // Do not print successful statements to stdout.
+	})
+
+	logging.FromContext(ctx).V(1).Info("created restore point",
+		"stdout", stdout, "stderr", stderr)
+
+	if err != nil {
+		return point, err
+	}
+
+	lsn, created, ok := strings.Cut(strings.TrimSpace(stdout), "|")
+	if !ok {
+		return point, errors.Errorf("unexpected output from restore point: %q", stdout)
+	}
+
+	point.LSN = lsn
+	if t, err := time.Parse(time.RFC3339Nano, created); err == nil {
+		point.Time = metav1.NewTime(t)
+	} else {
+		point.Time = metav1.Now()
+	}
+	return point, nil
+}
@@ -0,0 +1,121 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestReconcileRestorePoints(t *testing.T) {
+	ctx := context.Background()
+
+	pod := &corev1.Pod{}
+	pod.Namespace, pod.Name = "ns1", "hippo-00-abcd-0"
+	pod.Annotations = map[string]string{"status": `{"role":"master"}`}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  naming.ContainerDatabase,
+		State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+	}}
+
+	instances := &observedInstances{forCluster: []*Instance{
+		{Name: "hippo-00-abcd", Pods: []*corev1.Pod{pod}},
+	}}
+
+	var calls int
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Recorder: recorder,
+		PodExec: func(_, _, container string, stdin io.Reader, stdout, _ io.Writer, command ...string) error {
+			calls++
+			assert.Equal(t, container, naming.ContainerDatabase)
+			assert.Assert(t, cmp.Contains(strings.Join(command, " "), "--set=name=before-deploy"))
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), "pg_create_restore_point(:'name')"))
+
+			_, err = stdout.Write([]byte("0/3000090|2024-01-01T12:00:00.123456+00:00\n"))
+			return err
+		},
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+
+	t.Run("NoAnnotation", func(t *testing.T) {
+		assert.NilError(t, reconciler.reconcileRestorePoints(ctx, cluster, instances))
+		assert.Equal(t, calls, 0)
+	})
+
+	t.Run("Create", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Annotations = map[string]string{naming.RestorePoint: "before-deploy"}
+		calls = 0
+
+		assert.NilError(t, reconciler.reconcileRestorePoints(ctx, cluster, instances))
+		assert.Equal(t, calls, 1)
+		assert.Equal(t, len(cluster.Status.RestorePoints), 1)
+
+		point := cluster.Status.RestorePoints[0]
+		assert.Equal(t, point.Name, "before-deploy")
+		assert.Equal(t, point.LSN, "0/3000090")
+		assert.Assert(t, point.Time.Time.Equal(
+			time.Date(2024, 1, 1, 12, 0, 0, 123456000, time.UTC)), "got %v", point.Time)
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "RestorePointCreated"))
+
+		// The restore point is created once.
+		assert.NilError(t, reconciler.reconcileRestorePoints(ctx, cluster, instances))
+		assert.Equal(t, calls, 1)
+	})
+
+	t.Run("Kept", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Annotations = map[string]string{naming.RestorePoint: "before-deploy"}
+		for i := 0; i < restorePointsKept; i++ {
+			cluster.Status.RestorePoints = append(cluster.Status.RestorePoints,
+				v1beta1.RestorePointStatus{Name: fmt.Sprint(i)})
+		}
+
+		assert.NilError(t, reconciler.reconcileRestorePoints(ctx, cluster, instances))
+		assert.Equal(t, len(cluster.Status.RestorePoints), restorePointsKept)
+		assert.Equal(t, cluster.Status.RestorePoints[0].Name, "1")
+		assert.Equal(t, cluster.Status.RestorePoints[restorePointsKept-1].Name, "before-deploy")
+		<-recorder.Events
+	})
+
+	t.Run("Standby", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Annotations = map[string]string{naming.RestorePoint: "before-deploy"}
+		cluster.Spec.Standby = &v1beta1.PostgresStandbySpec{Enabled: true}
+		calls = 0
+
+		assert.NilError(t, reconciler.reconcileRestorePoints(ctx, cluster, instances))
+		assert.Equal(t, calls, 0)
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "standby cluster"))
+	})
+}
@@ -0,0 +1,376 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/crunchydata/postgres-operator/internal/amcheck"
+	"github.com/crunchydata/postgres-operator/internal/config"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/maintenance"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/partman"
+	"github.com/crunchydata/postgres-operator/internal/pki"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/servicemesh"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// scheduledJob describes a CronJob that runs against PostgreSQL and the
+// condition that reports the outcome of its most recently finished Job.
+type scheduledJob struct {
+	// name is the value of the "job" label of exported metrics.
+	name string
+
+	// label identifies the CronJob and its Jobs.
+	label string
+
+	// condition is the type of condition that reports the latest outcome.
+	condition string
+
+	succeededReason, succeededMessage string
+
+	// failedMessage is formatted with the name of the Job that failed.
+	failedReason, failedMessage string
+}
+
+// These gauges export the outcome of the latest scheduled Job of every
+// PostgresCluster.
+var (
+	scheduledJobSucceeded = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "postgrescluster",
+		Name:      "scheduled_job_succeeded",
+		Help:      "Whether or not the latest scheduled Job of a PostgresCluster succeeded",
+	}, []string{"namespace", "name", "job"})
+	scheduledJobFinished = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "postgrescluster",
+		Name:      "scheduled_job_finished_timestamp_seconds",
+		Help:      "When the latest scheduled Job of a PostgresCluster finished, in seconds since the Unix epoch",
+	}, []string{"namespace", "name", "job"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(scheduledJobSucceeded, scheduledJobFinished)
+}
+
+// setScheduledJobMetrics exports the outcome of latest as gauges labeled with
+// the namespace and name of cluster. A nil latest removes the gauges.
+func setScheduledJobMetrics(
+	cluster client.ObjectKey, job scheduledJob, latest *batchv1.Job, finished *metav1.Time,
+) {
+	labels := []string{cluster.Namespace, cluster.Name, job.name}
+
+	if latest == nil {
+		scheduledJobSucceeded.DeleteLabelValues(labels...)
+		scheduledJobFinished.DeleteLabelValues(labels...)
+		return
+	}
+
+	succeeded := float64(0)
+	if jobCompleted(latest) {
+		succeeded = 1
+	}
+	scheduledJobSucceeded.WithLabelValues(labels...).Set(succeeded)
+	scheduledJobFinished.WithLabelValues(labels...).Set(float64(finished.Unix()))
+}
+
+// maintenanceUserEnabled returns whether or not any scheduled Job that
+// connects as [postgres.MaintenanceUser] is enabled.
+func maintenanceUserEnabled(cluster *v1beta1.PostgresCluster) bool {
+	return amcheck.Enabled(cluster) || maintenance.Enabled(cluster) || partman.Enabled(cluster)
+}
+
+// reconcileMaintenanceUser issues a client certificate for the maintenance
+// user then reconciles every scheduled Job that connects as that user. The
+// user is dropped after the last of those is disabled.
+func (r *Reconciler) reconcileMaintenanceUser(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+	root *pki.RootCertificateAuthority,
+) error {
+	installed := cluster.Status.IntegrityChecks != nil ||
+		cluster.Status.Maintenance != nil || cluster.Status.Partitioning != nil
+
+	secret, err := r.reconcileClientCertificateSecret(ctx, cluster, root,
+		naming.MaintenanceSecret(cluster), postgres.MaintenanceUser,
+		maintenanceUserEnabled(cluster))
+
+	if err == nil {
+		err = r.reconcileIntegrityChecks(ctx, cluster, instances, secret)
+	}
+	if err == nil {
+		err = r.reconcileMaintenance(ctx, cluster, instances, secret)
+	}
+	if err == nil {
+		err = r.reconcilePartitioning(ctx, cluster, instances, secret)
+	}
+
+	// Each of the above leaves its status in place until it has revoked its
+	// privileges in PostgreSQL.
+	if err == nil && installed && cluster.Status.IntegrityChecks == nil &&
+		cluster.Status.Maintenance == nil && cluster.Status.Partitioning == nil {
+		if pod, _ := instances.writablePod(naming.ContainerDatabase); pod != nil {
+			err = postgres.DropMaintenanceUser(ctx, r.databaseExecutor(pod))
+		}
+	}
+
+	return err
+}
+
+// reconcileSQLRevision calls action with the writable instance when the SQL
+// it executes differs from revision. It stores the new revision and returns
+// true when revision is current. It returns false without error when there
+// is no writable instance.
+func (r *Reconciler) reconcileSQLRevision(ctx context.Context,
+	instances *observedInstances, revision *string,
+	action func(context.Context, postgres.Executor) error,
+) (bool, error) {
+	pod, _ := instances.writablePod(naming.ContainerDatabase)
+	if pod == nil {
+		return false, nil
+	}
+
+	next, err := safeHash32(func(hasher io.Writer) error {
+		// Discard log messages about executing SQL. Nothing is being
+		// "executed" yet.
+		return action(logging.NewContext(ctx, logging.Discard()), func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			_, err := io.Copy(hasher, stdin)
+			if err == nil {
+				_, err = fmt.Fprint(hasher, command)
+			}
+			return err
+		})
+	})
+
+	if err == nil && next != *revision {
+		// Include the revision hash in any log messages.
+		ctx := logging.NewContext(ctx, logging.FromContext(ctx).WithValues("revision", next))
+
+		err = action(ctx, r.databaseExecutor(pod))
+		if err == nil {
+			*revision = next
+		}
+	}
+
+	return err == nil, err
+}
+
+// databaseExecutor returns an Executor that runs commands in the database
+// container of pod.
+func (r *Reconciler) databaseExecutor(pod *corev1.Pod) postgres.Executor {
+	return func(_ context.Context, stdin io.Reader,
+		stdout, stderr io.Writer, command ...string) error {
+		return r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase, stdin, stdout, stderr, command...)
+	}
+}
+
+// reconcileScheduledJob applies the CronJob of job when enabled and ready,
+// then sets the condition of job according to the most recently finished Job.
+// It returns when that Job finished, if any. When not enabled, the CronJob
+// and condition are removed.
+func (r *Reconciler) reconcileScheduledJob(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, job scheduledJob, cronjob *batchv1.CronJob,
+	enabled, ready bool, generate func(*batchv1.CronJob),
+) (*metav1.Time, error) {
+	if !enabled {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, job.condition)
+		setScheduledJobMetrics(client.ObjectKeyFromObject(cluster), job, nil, nil)
+
+		err := errors.WithStack(r.Client.Get(ctx, client.ObjectKeyFromObject(cronjob), cronjob))
+		if err == nil {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, cronjob))
+		}
+		return nil, client.IgnoreNotFound(err)
+	}
+
+	if !ready {
+		return nil, nil
+	}
+
+	generate(cronjob)
+
+	cronjob.SetGroupVersionKind(batchv1.SchemeGroupVersion.WithKind("CronJob"))
+	err := errors.WithStack(r.setControllerReference(cluster, cronjob))
+
+	if err == nil {
+		err = r.apply(ctx, cronjob)
+	}
+
+	jobs := &batchv1.JobList{}
+	if err == nil {
+		err = errors.WithStack(r.Client.List(ctx, jobs, &client.ListOptions{
+			Namespace: cluster.Namespace,
+			LabelSelector: naming.Merge(map[string]string{
+				naming.LabelCluster: cluster.Name,
+				job.label:           "",
+			}).AsSelector(),
+		}))
+	}
+	if err == nil {
+		return setScheduledJobCondition(cluster, job, jobs.Items), nil
+	}
+	return nil, err
+}
+
+// setScheduledJobCondition sets the condition of job according to the most
+// recently finished of jobs and returns when it finished.
+func setScheduledJobCondition(
+	cluster *v1beta1.PostgresCluster, job scheduledJob, jobs []batchv1.Job,
+) *metav1.Time {
+	latest, finished := latestFinishedJob(jobs)
+	if latest == nil {
+		return nil
+	}
+
+	condition := metav1.Condition{
+		Type:               job.condition,
+		ObservedGeneration: cluster.GetGeneration(),
+	}
+	if jobCompleted(latest) {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = job.succeededReason
+		condition.Message = job.succeededMessage
+	} else {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = job.failedReason
+		condition.Message = fmt.Sprintf(job.failedMessage, latest.Name)
+	}
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+	setScheduledJobMetrics(client.ObjectKeyFromObject(cluster), job, latest, finished)
+
+	return finished
+}
+
+// cronJobTimeZone returns the time zone in which the schedules of cluster are
+// interpreted. It is nil, which means the time zone of the kube-controller-manager,
+// when cluster does not have one.
+func cronJobTimeZone(cluster *v1beta1.PostgresCluster) *string {
+	if cluster.Spec.TimeZone == "" {
+		return nil
+	}
+	return initialize.String(cluster.Spec.TimeZone)
+}
+
+// withMaintenanceUser mounts the client certificate in secret into the first
+// container of template and connects that container to service as the
+// maintenance user.
+func withMaintenanceUser(
+	cluster *v1beta1.PostgresCluster, secret *corev1.Secret,
+	service metav1.ObjectMeta, template *corev1.PodTemplateSpec,
+) {
+	const certDirectory = "/pgconf/tls"
+
+	container := &template.Spec.Containers[0]
+	container.Env = append(container.Env, []corev1.EnvVar{
+		{Name: "PGHOST", Value: fmt.Sprintf("%s.%s.svc", service.Name, service.Namespace)},
+		{Name: "PGPORT", Value: fmt.Sprint(*cluster.Spec.Port)},
+		{Name: "PGDATABASE", Value: "postgres"},
+		{Name: "PGUSER", Value: postgres.MaintenanceUser},
+		{Name: "PGSSLMODE", Value: "verify-ca"},
+		{Name: "PGSSLCERT", Value: certDirectory + "/" + clusterCertFile},
+		{Name: "PGSSLKEY", Value: certDirectory + "/" + clusterKeyFile},
+		{Name: "PGSSLROOTCERT", Value: certDirectory + "/" + rootCertFile},
+	}...)
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name: naming.CertVolume, MountPath: certDirectory, ReadOnly: true,
+	})
+
+	template.Spec.Volumes = append(template.Spec.Volumes, corev1.Volume{
+		Name: naming.CertVolume,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: secret.Name,
+				// The client key must not be readable by others.
+				// - https://www.postgresql.org/docs/current/libpq-ssl.html
+				DefaultMode: initialize.Int32(0o600),
+			},
+		},
+	})
+}
+
+// generateScheduledJob populates cronjob with schedule and a Job that runs
+// container in the image of cluster. The CronJob is suspended when suspend is
+// true; any Jobs that have already started continue.
+func generateScheduledJob(
+	cluster *v1beta1.PostgresCluster, job scheduledJob, cronjob *batchv1.CronJob,
+	schedule string, suspend bool, container corev1.Container,
+) *corev1.PodTemplateSpec {
+	cronjob.Annotations = naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil())
+	cronjob.Labels = naming.Merge(cluster.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelCluster: cluster.Name,
+			job.label:           "",
+		})
+
+	container.Command = servicemesh.JobCommand(cluster, container.Command)
+	container.Image = config.PostgresContainerImage(cluster)
+	container.ImagePullPolicy = cluster.Spec.ImagePullPolicy
+	container.SecurityContext = initialize.RestrictedSecurityContext()
+
+	template := corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: naming.Merge(cronjob.Annotations,
+				servicemesh.JobAnnotations(cluster)),
+			Labels: cronjob.Labels,
+		},
+		Spec: corev1.PodSpec{
+			// Set the image pull secrets, if any exist.
+			// This is set here rather than using the service account due to the lack
+			// of propagation to existing pods when the CRD is updated:
+			// https://github.com/kubernetes/kubernetes/issues/88456
+			ImagePullSecrets: cluster.Spec.ImagePullSecrets,
+			Containers:       []corev1.Container{container},
+			SecurityContext:  postgres.PodSecurityContext(cluster),
+			RestartPolicy:    corev1.RestartPolicyNever,
+			// These Jobs don't make Kubernetes API calls, so we can just
+			// use the default ServiceAccount and not mount its credentials.
+			AutomountServiceAccountToken: initialize.Bool(false),
+			EnableServiceLinks:           initialize.Bool(false),
+		},
+	}
+
+	cronjob.Spec = batchv1.CronJobSpec{
+		Schedule: schedule,
+		Suspend:  &suspend,
+		TimeZone: cronJobTimeZone(cluster),
+		// Only one Job of each kind runs at a time.
+		ConcurrencyPolicy: batchv1.ForbidConcurrent,
+		JobTemplate: batchv1.JobTemplateSpec{
+			ObjectMeta: template.ObjectMeta,
+			Spec: batchv1.JobSpec{
+				BackoffLimit: initialize.Int32(0),
+				Template:     template,
+			},
+		},
+	}
+
+	return &cronjob.Spec.JobTemplate.Spec.Template
+}
@@ -0,0 +1,168 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gotest.tools/v3/assert"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestMaintenanceUserEnabled(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Spec.PostgresVersion = 13
+	assert.Assert(t, !maintenanceUserEnabled(cluster))
+
+	cluster.Spec.IntegrityChecks = new(v1beta1.IntegrityChecksSpec)
+	assert.Assert(t, !maintenanceUserEnabled(cluster), "expected PostgreSQL 14")
+
+	cluster.Spec.PostgresVersion = 14
+	assert.Assert(t, maintenanceUserEnabled(cluster))
+
+	cluster.Spec.IntegrityChecks = nil
+	cluster.Spec.Maintenance = new(v1beta1.MaintenanceSpec)
+	assert.Assert(t, maintenanceUserEnabled(cluster))
+}
+
+func TestGenerateScheduledJob(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Name = "hippo"
+	cluster.Namespace = "ns1"
+	cluster.Spec.Port = initialize.Int32(5432)
+	cluster.Spec.Image = "some-image"
+	cluster.Spec.Metadata = &v1beta1.Metadata{Labels: map[string]string{"x": "y"}}
+
+	secret := &corev1.Secret{ObjectMeta: naming.MaintenanceSecret(cluster)}
+	cronjob := &batchv1.CronJob{}
+
+	template := generateScheduledJob(cluster, integrityChecksJob, cronjob,
+		"@daily", true, corev1.Container{Name: "some-job"})
+	withMaintenanceUser(cluster, secret, naming.ClusterPrimaryService(cluster), template)
+
+	assert.Equal(t, cronjob.Spec.Schedule, "@daily")
+	assert.Equal(t, *cronjob.Spec.Suspend, true)
+	assert.Assert(t, cronjob.Spec.TimeZone == nil)
+	assert.DeepEqual(t, cronjob.Labels, map[string]string{
+		"x":                         "y",
+		naming.LabelCluster:         "hippo",
+		naming.LabelIntegrityChecks: "",
+	})
+	assert.DeepEqual(t, cronjob.Spec.JobTemplate.Labels, cronjob.Labels)
+
+	pod := cronjob.Spec.JobTemplate.Spec.Template.Spec
+	assert.Equal(t, pod.Volumes[0].Secret.SecretName, "hippo-maintenance-cert")
+	assert.Equal(t, pod.Containers[0].Image, "some-image")
+	assert.Assert(t, cmp.MarshalMatches(pod.Containers[0].VolumeMounts, `
+- mountPath: /pgconf/tls
+  name: cert-volume
+  readOnly: true
+	`))
+
+	t.Run("TimeZone", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.TimeZone = "Europe/Berlin"
+
+		generateScheduledJob(cluster, integrityChecksJob, cronjob,
+			"0 2 * * *", false, corev1.Container{Name: "some-job"})
+		assert.Equal(t, *cronjob.Spec.TimeZone, "Europe/Berlin")
+	})
+}
+
+func TestSetScheduledJobCondition(t *testing.T) {
+	finished := func(name string, kind batchv1.JobConditionType, at time.Time) batchv1.Job {
+		job := batchv1.Job{}
+		job.Name = name
+		job.Status.Conditions = []batchv1.JobCondition{{
+			Type: kind, Status: corev1.ConditionTrue,
+			LastTransitionTime: metav1.NewTime(at),
+		}}
+		return job
+	}
+
+	now := time.Now().Truncate(time.Second)
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Name = "hippo"
+	cluster.Namespace = "ns1"
+	key := client.ObjectKeyFromObject(cluster)
+
+	t.Run("NoJobs", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+
+		assert.Assert(t, setScheduledJobCondition(cluster, integrityChecksJob, nil) == nil)
+		assert.Assert(t, meta.FindStatusCondition(cluster.Status.Conditions,
+			v1beta1.IntegrityChecked) == nil)
+	})
+
+	t.Run("Succeeded", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+
+		at := setScheduledJobCondition(cluster, integrityChecksJob, []batchv1.Job{
+			finished("old", batchv1.JobFailed, now.Add(-time.Hour)),
+			finished("new", batchv1.JobComplete, now),
+		})
+		assert.Assert(t, at.Time.Equal(now))
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions,
+			v1beta1.IntegrityChecked)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionTrue)
+		assert.Equal(t, condition.Reason, "NoCorruption")
+
+		assert.Equal(t, testutil.ToFloat64(scheduledJobSucceeded.WithLabelValues(
+			key.Namespace, key.Name, "amcheck")), float64(1))
+		assert.Equal(t, testutil.ToFloat64(scheduledJobFinished.WithLabelValues(
+			key.Namespace, key.Name, "amcheck")), float64(now.Unix()))
+	})
+
+	t.Run("Failed", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+
+		setScheduledJobCondition(cluster, partitioningJob, []batchv1.Job{
+			finished("new", batchv1.JobFailed, now),
+		})
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions,
+			v1beta1.PartitionsMaintained)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "MaintenanceFailed")
+		assert.Assert(t, cmp.Contains(condition.Message, `"new"`))
+
+		assert.Equal(t, testutil.ToFloat64(scheduledJobSucceeded.WithLabelValues(
+			key.Namespace, key.Name, "partman")), float64(0))
+	})
+
+	t.Run("Forget", func(t *testing.T) {
+		setScheduledJobMetrics(key, integrityChecksJob, nil, nil)
+		setScheduledJobMetrics(key, partitioningJob, nil, nil)
+
+		assert.Equal(t, testutil.CollectAndCount(scheduledJobSucceeded), 0)
+		assert.Equal(t, testutil.CollectAndCount(scheduledJobFinished), 0)
+	})
+}
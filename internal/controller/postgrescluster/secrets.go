@@ -0,0 +1,148 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={get}
+
+// reconcileCustomSecrets checks that the Secrets named in the spec of cluster
+// exist. Unlike the Secrets the operator generates, these cannot be recreated
+// when they are deleted. When any are missing, it sets the SecretsAvailable
+// condition to False and returns true to indicate that reconciliation should
+// stop until they are restored; changing instances without them leaves pods
+// that cannot start. The clusters are reconciled again when the Secrets are
+// created; see [Reconciler.watchCustomSecrets].
+func (r *Reconciler) reconcileCustomSecrets(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) (bool, error) {
+	type reference struct{ field, name string }
+	var references []reference
+
+	if cluster.Spec.CustomTLSSecret != nil {
+		references = append(references, reference{
+			"spec.customTLSSecret", cluster.Spec.CustomTLSSecret.Name})
+	}
+	if cluster.Spec.CustomReplicationClientTLSSecret != nil {
+		references = append(references, reference{
+			"spec.customReplicationTLSSecret", cluster.Spec.CustomReplicationClientTLSSecret.Name})
+	}
+
+	var missing, fields []string
+	for _, ref := range references {
+		secret := &corev1.Secret{}
+		err := r.Client.Get(ctx,
+			client.ObjectKey{Namespace: cluster.Namespace, Name: ref.name}, secret)
+
+		if apierrors.IsNotFound(err) {
+			missing = append(missing, fmt.Sprintf("%q", ref.name))
+			fields = append(fields, ref.field)
+		} else if err != nil {
+			return false, errors.WithStack(err)
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               v1beta1.SecretsAvailable,
+		ObservedGeneration: cluster.GetGeneration(),
+		Status:             metav1.ConditionTrue,
+		Reason:             "SecretsFound",
+		Message:            "Secrets referenced by the spec exist",
+	}
+	if len(missing) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "SecretsNotFound"
+		condition.Message = fmt.Sprintf(
+			"Secrets %s do not exist; reconciliation is blocked until they are "+
+				"recreated or %s removed so the operator generates certificates",
+			strings.Join(missing, ", "), strings.Join(fields, " and "))
+
+		if !meta.IsStatusConditionFalse(cluster.Status.Conditions, v1beta1.SecretsAvailable) {
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "SecretsNotFound", condition.Message)
+		}
+	}
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+	return len(missing) > 0, nil
+}
+
+// +kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={list}
+
+// watchCustomSecrets returns a handler.EventHandler for Secrets that enqueues
+// the clusters in the same namespace that are waiting for them.
+func (r *Reconciler) watchCustomSecrets() handler.Funcs {
+	handle := func(secret client.Object, q workqueue.RateLimitingInterface) {
+		ctx := context.Background()
+		log := logging.FromContext(ctx)
+
+		var clusters v1beta1.PostgresClusterList
+		if err := r.Client.List(ctx, &clusters, client.InNamespace(secret.GetNamespace())); err != nil {
+			log.Error(err, "listing PostgresClusters")
+			return
+		}
+		for i := range clusters.Items {
+			cluster := &clusters.Items[i]
+			tls := cluster.Spec.CustomTLSSecret
+			replication := cluster.Spec.CustomReplicationClientTLSSecret
+
+			if meta.IsStatusConditionFalse(cluster.Status.Conditions, v1beta1.SecretsAvailable) &&
+				((tls != nil && tls.Name == secret.GetName()) ||
+					(replication != nil && replication.Name == secret.GetName())) {
+				q.Add(reconcile.Request{NamespacedName: client.ObjectKeyFromObject(cluster)})
+			}
+		}
+	}
+
+	return handler.Funcs{
+		CreateFunc: func(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			handle(e.Object, q)
+		},
+	}
+}
+
+// recordRegeneratedSecret emits an event when the operator is about to
+// recreate a Secret of cluster that was deleted after PostgreSQL was
+// initialized. The new contents reach running pods through their projected
+// volumes; purpose describes what was regenerated and what follows from it.
+func (r *Reconciler) recordRegeneratedSecret(
+	cluster *v1beta1.PostgresCluster, existing *corev1.Secret, purpose string,
+) {
+	initialized := cluster.Status.Patroni.SystemIdentifier != ""
+
+	// A Secret that was read from the API always has a ResourceVersion.
+	if initialized && existing.ResourceVersion == "" {
+		r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "SecretRegenerated",
+			"Secret %q was missing; regenerated %s", existing.Name, purpose)
+	}
+}
@@ -0,0 +1,169 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestReconcileCustomSecrets(t *testing.T) {
+	ctx := context.Background()
+
+	existing := &corev1.Secret{}
+	existing.Namespace, existing.Name = "ns1", "custom-tls"
+
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{
+		Client:   fake.NewClientBuilder().WithObjects(existing).Build(),
+		Recorder: recorder,
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+
+	t.Run("Unspecified", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		blocked, err := r.reconcileCustomSecrets(ctx, cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, !blocked)
+		assert.Assert(t, meta.IsStatusConditionTrue(cluster.Status.Conditions, v1beta1.SecretsAvailable))
+	})
+
+	cluster.Spec.CustomTLSSecret = &corev1.SecretProjection{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "custom-tls"},
+	}
+	cluster.Spec.CustomReplicationClientTLSSecret = &corev1.SecretProjection{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "custom-replication"},
+	}
+
+	t.Run("Missing", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		blocked, err := r.reconcileCustomSecrets(ctx, cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, blocked)
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.SecretsAvailable)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "SecretsNotFound")
+		assert.Equal(t, condition.Message, `Secrets "custom-replication" do not exist; `+
+			`reconciliation is blocked until they are recreated or `+
+			`spec.customReplicationTLSSecret removed so the operator generates certificates`)
+
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Equal(t, <-recorder.Events, "Warning SecretsNotFound "+condition.Message)
+
+		// The event happens once, when the condition changes.
+		blocked, err = r.reconcileCustomSecrets(ctx, cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, blocked)
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+
+	t.Run("Found", func(t *testing.T) {
+		replication := &corev1.Secret{}
+		replication.Namespace, replication.Name = "ns1", "custom-replication"
+		assert.NilError(t, r.Client.Create(ctx, replication))
+
+		cluster := cluster.DeepCopy()
+		blocked, err := r.reconcileCustomSecrets(ctx, cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, !blocked)
+		assert.Assert(t, meta.IsStatusConditionTrue(cluster.Status.Conditions, v1beta1.SecretsAvailable))
+	})
+}
+
+func TestWatchCustomSecrets(t *testing.T) {
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	waiting := &v1beta1.PostgresCluster{}
+	waiting.Namespace, waiting.Name = "ns1", "waiting"
+	waiting.Spec.CustomTLSSecret = &corev1.SecretProjection{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "custom-tls"},
+	}
+	waiting.Status.Conditions = []metav1.Condition{{
+		Type: v1beta1.SecretsAvailable, Status: metav1.ConditionFalse,
+	}}
+
+	// This cluster has its Secrets, so it is not waiting.
+	satisfied := waiting.DeepCopy()
+	satisfied.Name = "satisfied"
+	satisfied.Status.Conditions[0].Status = metav1.ConditionTrue
+
+	queue := controllertest.Queue{Interface: workqueue.New()}
+	reconciler := &Reconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(waiting, satisfied).Build(),
+	}
+
+	create := reconciler.watchCustomSecrets().CreateFunc
+	assert.Assert(t, create != nil)
+
+	other := &corev1.Secret{}
+	other.Namespace, other.Name = "ns1", "other"
+	create(event.CreateEvent{Object: other}, queue)
+	assert.Equal(t, queue.Len(), 0)
+
+	custom := &corev1.Secret{}
+	custom.Namespace, custom.Name = "ns1", "custom-tls"
+	create(event.CreateEvent{Object: custom}, queue)
+	assert.Equal(t, queue.Len(), 1)
+
+	item, _ := queue.Get()
+	assert.Equal(t, item, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(waiting)})
+	queue.Done(item)
+}
+
+func TestRecordRegeneratedSecret(t *testing.T) {
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{Recorder: recorder}
+
+	cluster := &v1beta1.PostgresCluster{}
+	missing := &corev1.Secret{}
+	missing.Name = "hippo-cluster-cert"
+
+	// Nothing is regenerated before PostgreSQL is initialized.
+	r.recordRegeneratedSecret(cluster, missing, "something")
+	assert.Equal(t, len(recorder.Events), 0)
+
+	cluster.Status.Patroni.SystemIdentifier = "6952526174828511264"
+
+	found := missing.DeepCopy()
+	found.ResourceVersion = "1"
+	r.recordRegeneratedSecret(cluster, found, "something")
+	assert.Equal(t, len(recorder.Events), 0)
+
+	r.recordRegeneratedSecret(cluster, missing, "the PostgreSQL server certificate")
+	assert.Equal(t, len(recorder.Events), 1)
+	assert.Equal(t, <-recorder.Events, `Warning SecretRegenerated `+
+		`Secret "hippo-cluster-cert" was missing; regenerated the PostgreSQL server certificate`)
+}
@@ -0,0 +1,257 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// generateSplitCluster returns the PostgresCluster that starts from the
+// volumes of instance, a replica in set of source. It is a copy of source with
+// one instance, and it has its own Secrets, certificates, Services, and
+// pgBackRest stanza.
+func generateSplitCluster(
+	source *v1beta1.PostgresCluster, set *v1beta1.PostgresInstanceSetSpec,
+	split *v1beta1.SplitStatus, walVolume bool,
+) *v1beta1.PostgresCluster {
+	target := v1beta1.NewPostgresCluster()
+	target.Namespace, target.Name = source.Namespace, split.ClusterName
+
+	spec := source.Spec.DeepCopy()
+	spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{*set.DeepCopy()}
+	spec.InstanceSets[0].Replicas = initialize.Int32(1)
+
+	// Bootstrap from the data directory of the replica as it is. Its WAL
+	// directory is already where the target expects it.
+	instance := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: split.Instance}}
+	spec.DataSource = &v1beta1.DataSource{
+		Volumes: &v1beta1.DataSourceVolumes{
+			PGDataVolume: &v1beta1.DataSourceVolume{
+				PVCName:   naming.InstancePostgresDataVolume(instance).Name,
+				Directory: fmt.Sprintf("pg%d", spec.PostgresVersion),
+			},
+		},
+	}
+	if walVolume {
+		spec.DataSource.Volumes.PGWALVolume = &v1beta1.DataSourceVolume{
+			PVCName: naming.InstancePostgresWALVolume(instance).Name,
+		}
+	}
+	spec.Shutdown = nil
+	spec.Standby = nil
+
+	// Backups of the target go to its own repositories. Cloud repositories
+	// are at the same path for every cluster, so only those on volumes are
+	// kept. When there are none, the target gets a volume like its data.
+	spec.Backups.PGBackRest.Manual = nil
+	spec.Backups.PGBackRest.Restore = nil
+	repos := spec.Backups.PGBackRest.Repos[:0]
+	for _, repo := range spec.Backups.PGBackRest.Repos {
+		if repo.Volume != nil {
+			repos = append(repos, repo)
+		}
+	}
+	if len(repos) == 0 {
+		repos = append(repos, v1beta1.PGBackRestRepo{
+			Name: "repo1",
+			Volume: &v1beta1.RepoPVC{
+				VolumeClaimSpec: *set.DataVolumeClaimSpec.DeepCopy(),
+			},
+		})
+	}
+	spec.Backups.PGBackRest.Repos = repos
+
+	// Node ports are unique across a Kubernetes cluster.
+	for _, service := range []*v1beta1.ServiceSpec{spec.Service, spec.ReplicaService} {
+		if service != nil {
+			service.NodePort = nil
+		}
+	}
+	if spec.Proxy != nil && spec.Proxy.PGBouncer != nil && spec.Proxy.PGBouncer.Service != nil {
+		spec.Proxy.PGBouncer.Service.NodePort = nil
+	}
+
+	target.Spec = *spec
+	return target
+}
+
+// +kubebuilder:rbac:groups="",resources="persistentvolumeclaims",verbs={get,patch}
+
+// releaseSplitVolumes removes the data and WAL volumes of instance from
+// cluster so that they are kept when the instance is deleted. Nothing of
+// cluster selects them afterward.
+func (r *Reconciler) releaseSplitVolumes(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instance string,
+) error {
+	runner := &appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: instance}}
+	patch := client.RawPatch(client.Merge.Type(), []byte(fmt.Sprintf(
+		`{"metadata":{"ownerReferences":null,"labels":{%q:null,%q:null,%q:null}}}`,
+		naming.LabelCluster, naming.LabelInstanceSet, naming.LabelInstance)))
+
+	var err error
+	for _, meta := range []metav1.ObjectMeta{
+		naming.InstancePostgresDataVolume(runner),
+		naming.InstancePostgresWALVolume(runner),
+	} {
+		pvc := &corev1.PersistentVolumeClaim{}
+		key := client.ObjectKey{Namespace: cluster.Namespace, Name: meta.Name}
+
+		if err == nil {
+			err = errors.WithStack(client.IgnoreNotFound(r.Client.Get(ctx, key, pvc)))
+		}
+		if err == nil && metav1.IsControlledBy(pvc, cluster) {
+			err = errors.WithStack(r.patch(ctx, pvc, patch))
+		}
+	}
+	return err
+}
+
+// +kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={get,create}
+
+// reconcileSplit stops the replica named by the split annotation on cluster,
+// keeps its volumes, and creates a new PostgresCluster that starts from them.
+// Nothing is copied, so the new cluster is ready as soon as PostgreSQL starts.
+// The instance is replaced in cluster unless its replicas are reduced. It
+// returns a non-nil Result while the instance is stopping; the caller should
+// return early until it is gone.
+func (r *Reconciler) reconcileSplit(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) (*reconcile.Result, error) {
+	split := cluster.Status.Split
+
+	// Start another split only after the previous one is complete.
+	if split == nil || split.CompletionTime != nil {
+		value := cluster.GetAnnotations()[naming.SplitInstance]
+		if value == "" {
+			return nil, nil
+		}
+		name, target, _ := strings.Cut(value, ":")
+		if split != nil && split.Instance == name && split.ClusterName == target {
+			return nil, nil
+		}
+		if name == "" || target == "" {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidSplit",
+				"Annotation %q must be an instance and a cluster name separated by a colon",
+				naming.SplitInstance)
+			return nil, nil
+		}
+
+		instance := instances.byName[name]
+		if instance == nil || instance.Spec == nil {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidSplit",
+				"Instance %q is not in an instance set of this cluster", name)
+			return nil, nil
+		}
+		if primary, known := instance.IsPrimary(); primary || !known {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidSplit",
+				"Instance %q must be a running replica", name)
+			return nil, nil
+		}
+		if len(instance.Spec.TablespaceVolumes) > 0 {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidSplit",
+				"Instance %q has tablespace volumes, which cannot be split", name)
+			return nil, nil
+		}
+
+		existing := v1beta1.NewPostgresCluster()
+		err := r.Client.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: target}, existing)
+		if err == nil {
+			r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidSplit",
+				"PostgresCluster %q already exists", target)
+			return nil, nil
+		}
+		if !apierrors.IsNotFound(err) {
+			return nil, errors.WithStack(err)
+		}
+
+		split = &v1beta1.SplitStatus{
+			Instance:    name,
+			ClusterName: target,
+			StartTime:   &metav1.Time{Time: time.Now()},
+		}
+		cluster.Status.Split = split
+
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "InstanceSplitting",
+			"Stopping instance %q to create PostgresCluster %q from its volumes",
+			split.Instance, split.ClusterName)
+	}
+
+	// Keep the volumes of the instance and delete everything else. Wait for
+	// its Pod to stop before anything else mounts its volumes.
+	instance := instances.byName[split.Instance]
+	if instance != nil && (instance.Runner != nil || len(instance.Pods) > 0) {
+		err := r.releaseSplitVolumes(ctx, cluster, split.Instance)
+		if err == nil {
+			err = r.deleteInstance(ctx, cluster, split.Instance)
+		}
+		return &reconcile.Result{RequeueAfter: 5 * time.Second}, err
+	}
+
+	// The set of the instance may have been removed while it was stopping.
+	var set *v1beta1.PostgresInstanceSetSpec
+	if instance != nil {
+		set = instance.Spec
+	}
+	if set == nil && len(cluster.Spec.InstanceSets) > 0 {
+		set = &cluster.Spec.InstanceSets[0]
+	}
+	if set == nil {
+		return nil, nil
+	}
+
+	wal := &corev1.PersistentVolumeClaim{}
+	err := errors.WithStack(r.Client.Get(ctx, client.ObjectKey{
+		Namespace: cluster.Namespace,
+		Name: naming.InstancePostgresWALVolume(&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: split.Instance},
+		}).Name,
+	}, wal))
+	walVolume := err == nil
+	err = client.IgnoreNotFound(err)
+
+	if err == nil {
+		target := generateSplitCluster(cluster, set, split, walVolume)
+		err = r.Client.Create(ctx, target, r.Owner)
+
+		// The cluster may have been created before status was last written.
+		if apierrors.IsAlreadyExists(err) {
+			err = nil
+		}
+		err = errors.WithStack(err)
+	}
+	if err == nil {
+		split.CompletionTime = &metav1.Time{Time: time.Now()}
+
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "InstanceSplit",
+			"Created PostgresCluster %q from the volumes of instance %q",
+			split.ClusterName, split.Instance)
+	}
+	return nil, err
+}
@@ -0,0 +1,222 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestGenerateSplitCluster(t *testing.T) {
+	source := v1beta1.NewPostgresCluster()
+	source.Namespace, source.Name = "ns1", "hippo"
+	source.Spec.PostgresVersion = 16
+	source.Spec.Service = &v1beta1.ServiceSpec{Type: "NodePort", NodePort: initialize.Int32(30000)}
+	source.Spec.Shutdown = initialize.Bool(false)
+	source.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+		{Name: "00", Replicas: initialize.Int32(3)},
+		{Name: "reports", Replicas: initialize.Int32(2)},
+	}
+	source.Spec.InstanceSets[1].DataVolumeClaimSpec.Resources.Requests = corev1.ResourceList{
+		corev1.ResourceStorage: resource.MustParse("1Gi"),
+	}
+	source.Spec.Backups.PGBackRest.Repos = []v1beta1.PGBackRestRepo{
+		{Name: "repo1", S3: &v1beta1.RepoS3{Bucket: "b"}},
+	}
+
+	split := &v1beta1.SplitStatus{Instance: "hippo-reports-abcd", ClusterName: "reporting"}
+	target := generateSplitCluster(source, &source.Spec.InstanceSets[1], split, false)
+
+	assert.Equal(t, target.Namespace, "ns1")
+	assert.Equal(t, target.Name, "reporting")
+	assert.Assert(t, target.Spec.Shutdown == nil)
+	assert.Assert(t, target.Spec.Service.NodePort == nil)
+	assert.Equal(t, len(target.Spec.InstanceSets), 1)
+	assert.Equal(t, target.Spec.InstanceSets[0].Name, "reports")
+	assert.Equal(t, *target.Spec.InstanceSets[0].Replicas, int32(1))
+
+	assert.Assert(t, cmp.MarshalMatches(target.Spec.DataSource, `
+volumes:
+  pgDataVolume:
+    directory: pg16
+    pvcName: hippo-reports-abcd-pgdata
+	`))
+	assert.Assert(t, cmp.MarshalMatches(target.Spec.Backups.PGBackRest.Repos, `
+- name: repo1
+  volume:
+    volumeClaimSpec:
+      resources:
+        requests:
+          storage: 1Gi
+	`))
+
+	// The source is unchanged.
+	assert.Equal(t, *source.Spec.Service.NodePort, int32(30000))
+	assert.Equal(t, len(source.Spec.InstanceSets), 2)
+	assert.Assert(t, source.Spec.Backups.PGBackRest.Repos[0].S3 != nil)
+
+	t.Run("WALVolume", func(t *testing.T) {
+		target := generateSplitCluster(source, &source.Spec.InstanceSets[1], split, true)
+		assert.Assert(t, cmp.MarshalMatches(target.Spec.DataSource.Volumes.PGWALVolume, `
+pvcName: hippo-reports-abcd-pgwal
+		`))
+	})
+}
+
+func TestReconcileSplit(t *testing.T) {
+	ctx := context.Background()
+
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	cluster := v1beta1.NewPostgresCluster()
+	cluster.Namespace, cluster.Name, cluster.UID = "ns1", "hippo", "some-uid"
+	cluster.Spec.PostgresVersion = 16
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+		{Name: "00", Replicas: initialize.Int32(2)},
+	}
+	controller := metav1.NewControllerRef(cluster, v1beta1.GroupVersion.WithKind("PostgresCluster"))
+
+	labels := func(instance, role string) map[string]string {
+		return map[string]string{
+			naming.LabelCluster:     "hippo",
+			naming.LabelInstanceSet: "00",
+			naming.LabelInstance:    instance,
+			naming.LabelRole:        role,
+		}
+	}
+
+	primary := &corev1.Pod{}
+	primary.Namespace, primary.Name = "ns1", "hippo-00-aaaa-0"
+	primary.Labels = labels("hippo-00-aaaa", naming.RolePatroniLeader)
+
+	replica := &corev1.Pod{}
+	replica.Namespace, replica.Name = "ns1", "hippo-00-bbbb-0"
+	replica.Labels = labels("hippo-00-bbbb", naming.RolePatroniReplica)
+
+	runner := &appsv1.StatefulSet{}
+	runner.Namespace, runner.Name = "ns1", "hippo-00-bbbb"
+	runner.Labels = labels("hippo-00-bbbb", "")
+	runner.OwnerReferences = []metav1.OwnerReference{*controller}
+
+	volume := &corev1.PersistentVolumeClaim{}
+	volume.Namespace, volume.Name = "ns1", "hippo-00-bbbb-pgdata"
+	volume.Labels = labels("hippo-00-bbbb", naming.RolePostgresData)
+	volume.OwnerReferences = []metav1.OwnerReference{*controller}
+
+	other := v1beta1.NewPostgresCluster()
+	other.Namespace, other.Name = "ns1", "taken"
+
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(runner, volume, other).Build(),
+		Owner:    client.FieldOwner(t.Name()),
+		Recorder: recorder,
+	}
+
+	instances := newObservedInstances(cluster,
+		[]appsv1.StatefulSet{*runner}, []corev1.Pod{*primary, *replica})
+
+	t.Run("NoAnnotation", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		wait, err := reconciler.reconcileSplit(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Assert(t, wait == nil)
+		assert.Assert(t, cluster.Status.Split == nil)
+	})
+
+	for _, tt := range []struct {
+		name, value, message string
+	}{
+		{name: "Malformed", value: "hippo-00-bbbb", message: "separated by a colon"},
+		{name: "UnknownInstance", value: "hippo-00-cccc:reporting", message: "not in an instance set"},
+		{name: "Primary", value: "hippo-00-aaaa:reporting", message: "must be a running replica"},
+		{name: "ExistingCluster", value: "hippo-00-bbbb:taken", message: `"taken" already exists`},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := cluster.DeepCopy()
+			cluster.Annotations = map[string]string{naming.SplitInstance: tt.value}
+
+			wait, err := reconciler.reconcileSplit(ctx, cluster, instances)
+			assert.NilError(t, err)
+			assert.Assert(t, wait == nil)
+			assert.Assert(t, cluster.Status.Split == nil)
+			assert.Assert(t, cmp.Contains(<-recorder.Events, tt.message))
+		})
+	}
+
+	t.Run("Split", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Annotations = map[string]string{naming.SplitInstance: "hippo-00-bbbb:reporting"}
+
+		// The instance stops and its volumes are released.
+		wait, err := reconciler.reconcileSplit(ctx, cluster, instances)
+		assert.NilError(t, err)
+		assert.Assert(t, wait != nil)
+		assert.Assert(t, cluster.Status.Split != nil)
+		assert.Equal(t, cluster.Status.Split.Instance, "hippo-00-bbbb")
+		assert.Equal(t, cluster.Status.Split.ClusterName, "reporting")
+		assert.Assert(t, cluster.Status.Split.CompletionTime == nil)
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "InstanceSplitting"))
+
+		released := &corev1.PersistentVolumeClaim{}
+		assert.NilError(t, reconciler.Client.Get(ctx, client.ObjectKeyFromObject(volume), released))
+		assert.Equal(t, len(released.OwnerReferences), 0)
+		assert.DeepEqual(t, released.Labels, map[string]string{
+			naming.LabelRole: naming.RolePostgresData,
+		})
+
+		err = reconciler.Client.Get(ctx, client.ObjectKeyFromObject(runner), &appsv1.StatefulSet{})
+		assert.Assert(t, apierrors.IsNotFound(err), "expected StatefulSet to be deleted, got %v", err)
+
+		// The new cluster is created once the instance is gone.
+		stopped := newObservedInstances(cluster, nil, []corev1.Pod{*primary})
+
+		wait, err = reconciler.reconcileSplit(ctx, cluster, stopped)
+		assert.NilError(t, err)
+		assert.Assert(t, wait == nil)
+		assert.Assert(t, cluster.Status.Split.CompletionTime != nil)
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "InstanceSplit"))
+
+		target := v1beta1.NewPostgresCluster()
+		assert.NilError(t, reconciler.Client.Get(ctx,
+			client.ObjectKey{Namespace: "ns1", Name: "reporting"}, target))
+		assert.Equal(t, target.Spec.DataSource.Volumes.PGDataVolume.PVCName, "hippo-00-bbbb-pgdata")
+		assert.Assert(t, target.Spec.DataSource.Volumes.PGWALVolume == nil)
+
+		// Nothing happens again for the same annotation.
+		wait, err = reconciler.reconcileSplit(ctx, cluster, stopped)
+		assert.NilError(t, err)
+		assert.Assert(t, wait == nil)
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+}
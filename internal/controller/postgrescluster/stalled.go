@@ -0,0 +1,50 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// setStalledCondition sets the Stalled condition of cluster according to the
+// error, if any, that ended reconciliation. The condition is True while the
+// error is one that retrying cannot resolve, such as an invalid spec; cluster
+// is not reconciled again until it changes. The condition returns to False
+// once reconciliation gets past that error.
+func setStalledCondition(cluster *v1beta1.PostgresCluster, err error) {
+	switch {
+	case err != nil && runtime.ClassifyError(err) == runtime.ErrorPermanent:
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type:               v1beta1.Stalled,
+			ObservedGeneration: cluster.GetGeneration(),
+			Status:             metav1.ConditionTrue,
+			Reason:             "PermanentError",
+			Message:            err.Error() + "; change the spec to continue",
+		})
+
+	case meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.Stalled) != nil:
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type:               v1beta1.Stalled,
+			ObservedGeneration: cluster.GetGeneration(),
+			Status:             metav1.ConditionFalse,
+			Reason:             "Progressing",
+			Message:            "Reconciliation is no longer blocked",
+		})
+	}
+}
@@ -0,0 +1,64 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"errors"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestSetStalledCondition(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Generation = 3
+
+	t.Run("Transient", func(t *testing.T) {
+		setStalledCondition(cluster, apierrors.NewConflict(
+			schema.GroupResource{Resource: "configmaps"}, "hippo", errors.New("changed")))
+		assert.Assert(t, meta.FindStatusCondition(
+			cluster.Status.Conditions, v1beta1.Stalled) == nil,
+			"expected no condition until an error is permanent")
+	})
+
+	t.Run("Permanent", func(t *testing.T) {
+		setStalledCondition(cluster, field.ErrorList{
+			field.Required(field.NewPath("spec", "postgresVersion"), ""),
+		}.ToAggregate())
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.Stalled)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionTrue)
+		assert.Equal(t, condition.Reason, "PermanentError")
+		assert.Equal(t, condition.ObservedGeneration, int64(3))
+		assert.Assert(t, cmp.Contains(condition.Message, "spec.postgresVersion"))
+	})
+
+	t.Run("Recovered", func(t *testing.T) {
+		setStalledCondition(cluster, errors.New("connection refused"))
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.Stalled)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+	})
+}
@@ -0,0 +1,160 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// +kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclustertemplates",verbs={get}
+
+// applyTemplate fills in the fields of cluster that are not set from the
+// PostgresClusterTemplate it references, if any. It returns false when that
+// template does not exist.
+func (r *Reconciler) applyTemplate(ctx context.Context, cluster *v1beta1.PostgresCluster) (bool, error) {
+	if cluster.Spec.TemplateName == "" {
+		return true, nil
+	}
+
+	template := &v1beta1.PostgresClusterTemplate{}
+	err := r.Client.Get(ctx, client.ObjectKey{
+		Namespace: cluster.Namespace, Name: cluster.Spec.TemplateName,
+	}, template)
+
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err == nil {
+		mergeTemplate(&cluster.Spec, &template.Spec.Template)
+	}
+	return err == nil, errors.WithStack(err)
+}
+
+// setTemplateCondition sets the TemplateAvailable condition of cluster when it
+// references a PostgresClusterTemplate and removes it otherwise. Nothing is
+// reconciled while the template is missing because the fields it would set
+// are empty; see [Reconciler.watchTemplates].
+func (r *Reconciler) setTemplateCondition(cluster *v1beta1.PostgresCluster, found bool) {
+	if cluster.Spec.TemplateName == "" {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.TemplateAvailable)
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               v1beta1.TemplateAvailable,
+		ObservedGeneration: cluster.GetGeneration(),
+		Status:             metav1.ConditionTrue,
+		Reason:             "TemplateFound",
+		Message:            fmt.Sprintf("PostgresClusterTemplate %q exists", cluster.Spec.TemplateName),
+	}
+	if !found {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "TemplateNotFound"
+		condition.Message = fmt.Sprintf(
+			"PostgresClusterTemplate %q does not exist; reconciliation is blocked "+
+				"until it is created or spec.templateName is removed",
+			cluster.Spec.TemplateName)
+
+		if !meta.IsStatusConditionFalse(cluster.Status.Conditions, v1beta1.TemplateAvailable) {
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, condition.Reason, condition.Message)
+		}
+	}
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+}
+
+// mergeTemplate sets each top-level field of spec that is not set to a copy
+// of the same field in template. Templates do not reference other templates.
+func mergeTemplate(spec, template *v1beta1.PostgresClusterSpec) {
+	template = template.DeepCopy()
+	template.TemplateName = ""
+
+	into, from := reflect.ValueOf(spec).Elem(), reflect.ValueOf(template).Elem()
+	for i := 0; i < into.NumField(); i++ {
+		if into.Field(i).IsZero() {
+			into.Field(i).Set(from.Field(i))
+		}
+	}
+}
+
+// missingRequiredFields returns an error for each field that a cluster needs
+// but that neither its spec nor its template set.
+func missingRequiredFields(cluster *v1beta1.PostgresCluster) field.ErrorList {
+	var errs field.ErrorList
+	path := field.NewPath("spec")
+
+	if len(cluster.Spec.Backups.PGBackRest.Repos) == 0 {
+		errs = append(errs, field.Required(path.Child("backups", "pgbackrest", "repos"), ""))
+	}
+	if len(cluster.Spec.InstanceSets) == 0 {
+		errs = append(errs, field.Required(path.Child("instances"), ""))
+	}
+	if cluster.Spec.PostgresVersion == 0 {
+		errs = append(errs, field.Required(path.Child("postgresVersion"), ""))
+	}
+	return errs
+}
+
+// +kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclustertemplates",verbs={list,watch}
+
+// watchTemplates returns a handler.EventHandler for PostgresClusterTemplates
+// that enqueues the clusters in the same namespace that reference them.
+func (r *Reconciler) watchTemplates() handler.Funcs {
+	handle := func(template client.Object, q workqueue.RateLimitingInterface) {
+		ctx := context.Background()
+		log := logging.FromContext(ctx)
+
+		var clusters v1beta1.PostgresClusterList
+		if err := r.Client.List(ctx, &clusters, client.InNamespace(template.GetNamespace())); err != nil {
+			log.Error(err, "listing PostgresClusters")
+			return
+		}
+		for i := range clusters.Items {
+			if clusters.Items[i].Spec.TemplateName == template.GetName() {
+				q.Add(reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&clusters.Items[i])})
+			}
+		}
+	}
+
+	return handler.Funcs{
+		CreateFunc: func(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			handle(e.Object, q)
+		},
+		UpdateFunc: func(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			handle(e.ObjectNew, q)
+		},
+		DeleteFunc: func(e event.DeleteEvent, q workqueue.RateLimitingInterface) {
+			handle(e.Object, q)
+		},
+	}
+}
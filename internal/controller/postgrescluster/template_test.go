@@ -0,0 +1,138 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestMergeTemplate(t *testing.T) {
+	template := &v1beta1.PostgresClusterSpec{
+		Image:           "golden",
+		PostgresVersion: 16,
+		InstanceSets:    []v1beta1.PostgresInstanceSetSpec{{Name: "a", Replicas: initialize.Int32(3)}},
+		Port:            initialize.Int32(5433),
+		TemplateName:    "other",
+	}
+	template.Backups.PGBackRest.Repos = []v1beta1.PGBackRestRepo{{Name: "repo1"}}
+
+	spec := &v1beta1.PostgresClusterSpec{
+		Image:        "custom",
+		Port:         initialize.Int32(5432),
+		TemplateName: "golden",
+	}
+	mergeTemplate(spec, template)
+
+	assert.Equal(t, spec.Image, "custom")
+	assert.Equal(t, *spec.Port, int32(5432))
+	assert.Equal(t, spec.PostgresVersion, 16)
+	assert.Equal(t, spec.TemplateName, "golden")
+	assert.Equal(t, len(spec.InstanceSets), 1)
+	assert.Equal(t, *spec.InstanceSets[0].Replicas, int32(3))
+	assert.DeepEqual(t, spec.Backups, template.Backups)
+
+	// The spec does not share memory with the template.
+	*spec.InstanceSets[0].Replicas = 1
+	assert.Equal(t, *template.InstanceSets[0].Replicas, int32(3))
+}
+
+func TestMissingRequiredFields(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	assert.Equal(t, missingRequiredFields(cluster).ToAggregate().Error(),
+		"[spec.backups.pgbackrest.repos: Required value, "+
+			"spec.instances: Required value, spec.postgresVersion: Required value]")
+
+	cluster.Spec.Backups.PGBackRest.Repos = []v1beta1.PGBackRestRepo{{Name: "repo1"}}
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{Name: "00"}}
+	cluster.Spec.PostgresVersion = 16
+	assert.Assert(t, len(missingRequiredFields(cluster)) == 0)
+}
+
+func TestApplyTemplate(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	template := &v1beta1.PostgresClusterTemplate{}
+	template.Namespace, template.Name = "ns1", "golden"
+	template.Spec.Template.PostgresVersion = 16
+
+	recorder := record.NewFakeRecorder(10)
+	r := &Reconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(template).Build(),
+		Recorder: recorder,
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+
+	t.Run("None", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		found, err := r.applyTemplate(ctx, cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, found)
+		assert.Equal(t, cluster.Spec.PostgresVersion, 0)
+
+		r.setTemplateCondition(cluster, found)
+		assert.Equal(t, len(cluster.Status.Conditions), 0)
+	})
+
+	t.Run("Found", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.TemplateName = "golden"
+		found, err := r.applyTemplate(ctx, cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, found)
+		assert.Equal(t, cluster.Spec.PostgresVersion, 16)
+
+		r.setTemplateCondition(cluster, found)
+		assert.Assert(t, meta.IsStatusConditionTrue(cluster.Status.Conditions, v1beta1.TemplateAvailable))
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.TemplateName = "missing"
+		found, err := r.applyTemplate(ctx, cluster)
+		assert.NilError(t, err)
+		assert.Assert(t, !found)
+		assert.Equal(t, cluster.Spec.PostgresVersion, 0)
+
+		r.setTemplateCondition(cluster, found)
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.TemplateAvailable)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "TemplateNotFound")
+
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Equal(t, <-recorder.Events, "Warning TemplateNotFound "+condition.Message)
+
+		// The event happens once, when the condition changes.
+		r.setTemplateCondition(cluster, found)
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+}
@@ -23,11 +23,13 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/rand"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 var tmpDirSizeLimit = resource.MustParse("16Mi")
@@ -263,6 +265,34 @@ func addNSSWrapper(image string, imagePullPolicy corev1.PullPolicy, template *co
 	template.Spec.InitContainers = append(template.Spec.InitContainers, container)
 }
 
+// setInitContainer replaces the image and resources of the init container
+// named name in pod with those in settings, if any.
+func setInitContainer(pod *corev1.PodSpec, name string, settings *v1beta1.InitContainer) {
+	if settings == nil {
+		return
+	}
+	for i := range pod.InitContainers {
+		if pod.InitContainers[i].Name != name {
+			continue
+		}
+		if settings.Image != "" {
+			pod.InitContainers[i].Image = settings.Image
+		}
+		if settings.Resources != nil {
+			pod.InitContainers[i].Resources = *settings.Resources
+		}
+	}
+}
+
+// addJobLimits sets the deadline and retries of job to those in the spec of
+// cluster, if any.
+func addJobLimits(cluster *v1beta1.PostgresCluster, job *batchv1.JobSpec) {
+	if limits := cluster.Spec.Jobs; limits != nil {
+		job.ActiveDeadlineSeconds = limits.ActiveDeadlineSeconds
+		job.BackoffLimit = limits.BackoffLimit
+	}
+}
+
 // jobFailed returns "true" if the Job provided has failed.  Otherwise it returns "false".
 func jobFailed(job *batchv1.Job) bool {
 	conditions := job.Status.Conditions
@@ -286,6 +316,25 @@ func jobCompleted(job *batchv1.Job) bool {
 	return false
 }
 
+// latestFinishedJob returns the Job that most recently completed or failed,
+// along with the time that it did so. It returns nil when no Job has finished.
+func latestFinishedJob(jobs []batchv1.Job) (*batchv1.Job, *metav1.Time) {
+	var latest *batchv1.Job
+	var latestTime *metav1.Time
+
+	for i := range jobs {
+		for _, c := range jobs[i].Status.Conditions {
+			if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) &&
+				c.Status == corev1.ConditionTrue &&
+				(latestTime == nil || latestTime.Before(&c.LastTransitionTime)) {
+				latest, latestTime = &jobs[i], c.LastTransitionTime.DeepCopy()
+			}
+		}
+	}
+
+	return latest, latestTime
+}
+
 // safeHash32 runs content and returns a short alphanumeric string that
 // represents everything written to w. The string is unlikely to have bad words
 // and is safe to store in the Kubernetes API. This is the same algorithm used
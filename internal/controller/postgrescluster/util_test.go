@@ -28,8 +28,10 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
 func TestSafeHash32(t *testing.T) {
@@ -422,6 +424,59 @@ func TestAddNSSWrapper(t *testing.T) {
 	}
 }
 
+func TestSetInitContainer(t *testing.T) {
+	pod := &corev1.PodSpec{InitContainers: []corev1.Container{
+		{Name: naming.ContainerPostgresStartup, Image: "postgres"},
+		{Name: naming.ContainerNSSWrapperInit, Image: "postgres"},
+	}}
+
+	setInitContainer(pod, naming.ContainerNSSWrapperInit, nil)
+	assert.Equal(t, pod.InitContainers[1].Image, "postgres")
+
+	setInitContainer(pod, naming.ContainerNSSWrapperInit, &v1beta1.InitContainer{
+		Image: "registry.example.com/postgres@sha256:abc",
+		Resources: &corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("10m"),
+		}},
+	})
+
+	assert.Assert(t, cmp.MarshalMatches(pod.InitContainers, `
+- image: postgres
+  name: postgres-startup
+  resources: {}
+- image: registry.example.com/postgres@sha256:abc
+  name: nss-wrapper-init
+  resources:
+    requests:
+      cpu: 10m
+	`))
+
+	// The image is kept when only resources are set.
+	setInitContainer(pod, naming.ContainerPostgresStartup, &v1beta1.InitContainer{
+		Resources: &corev1.ResourceRequirements{},
+	})
+	assert.Equal(t, pod.InitContainers[0].Image, "postgres")
+}
+
+func TestAddJobLimits(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	job := &batchv1.JobSpec{BackoffLimit: initialize.Int32(0)}
+
+	addJobLimits(cluster, job)
+	assert.Assert(t, job.ActiveDeadlineSeconds == nil)
+	assert.Equal(t, *job.BackoffLimit, int32(0))
+
+	cluster.Spec.Jobs = &v1beta1.JobsSpec{
+		ActiveDeadlineSeconds: initialize.Int64(3600),
+		BackoffLimit:          initialize.Int32(2),
+	}
+
+	addJobLimits(cluster, job)
+	assert.Equal(t, *job.ActiveDeadlineSeconds, int64(3600))
+	assert.Equal(t, *job.BackoffLimit, int32(2))
+	assert.Assert(t, job.TTLSecondsAfterFinished == nil)
+}
+
 func TestJobCompleted(t *testing.T) {
 
 	testCases := []struct {
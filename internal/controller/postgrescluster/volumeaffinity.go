@@ -0,0 +1,253 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	// volumeNodeAffinityGrace is how long an instance must be unschedulable
+	// because of its volumes before it is rebuilt. The scheduler reports the
+	// same reason while a Node restarts.
+	volumeNodeAffinityGrace = 5 * time.Minute
+
+	// selectedNodeAnnotation is set by the scheduler on a claim whose volume
+	// is provisioned for a particular Node, such as a local volume.
+	// - https://docs.k8s.io/reference/labels-annotations-taints/#volume-kubernetes-io-selected-node
+	selectedNodeAnnotation = "volume.kubernetes.io/selected-node"
+)
+
+// +kubebuilder:rbac:groups="",resources="persistentvolumeclaims",verbs={delete}
+// +kubebuilder:rbac:groups="",resources="pods",verbs={delete,patch}
+
+// reconcileVolumeNodeAffinity looks for instances that cannot be scheduled
+// because their volumes are bound to a Node that is gone or unusable, such as
+// local volumes on a Node that died. According to the policy of each instance
+// set, it either reports how to recover or deletes the volumes and Pod of a
+// replica so that it is recreated on another Node and copies its data from
+// the primary. It returns a result that checks again when a grace period ends.
+func (r *Reconciler) reconcileVolumeNodeAffinity(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+	instances *observedInstances, clusterVolumes []corev1.PersistentVolumeClaim,
+	now time.Time,
+) (reconcile.Result, error) {
+	var result reconcile.Result
+	var err error
+
+	primary, _ := instances.writablePod(naming.ContainerDatabase)
+
+	for _, instance := range instances.forCluster {
+		since, stuck := volumeNodeAffinityConflict(instance)
+		if !stuck || instance.Spec == nil || err != nil {
+			continue
+		}
+
+		volumes := instanceVolumes(instance.Name, clusterVolumes)
+		nodes := make([]string, 0, len(volumes))
+		for i := range volumes {
+			if node := volumes[i].Annotations[selectedNodeAnnotation]; node != "" {
+				nodes = append(nodes, node)
+			}
+		}
+		where := "a Node that is unavailable"
+		if len(nodes) > 0 {
+			where = fmt.Sprintf("Node %q, which is unavailable", nodes[0])
+		}
+
+		pod := instance.Pods[0]
+		if instance.Spec.VolumeNodeAffinityPolicy != v1beta1.VolumeNodeAffinityRebuild {
+			err = r.warnVolumeNodeUnavailable(ctx, cluster, pod, since, "Retain",
+				"Instance %q cannot be scheduled because its volumes are bound to %s. "+
+					"Restore the Node, or set volumeNodeAffinityPolicy of instance set %q "+
+					"to Rebuild to recreate the instance on another Node.",
+				instance.Name, where, instance.Spec.Name)
+			continue
+		}
+
+		// Only a replica can be rebuilt, and only from a primary that is running.
+		if primary == nil || primary == pod {
+			err = r.warnVolumeNodeUnavailable(ctx, cluster, pod, since, "Primary",
+				"Instance %q cannot be scheduled because its volumes are bound to %s. "+
+					"It cannot be rebuilt until another instance is primary.",
+				instance.Name, where)
+			continue
+		}
+
+		if wait := since.Add(volumeNodeAffinityGrace).Sub(now); wait > 0 {
+			result = updateReconcileResult(result, reconcile.Result{RequeueAfter: wait})
+			continue
+		}
+
+		// The volumes are deleted only when their Node is known to be gone
+		// or broken. A Node that is Ready may still hold the only good copy
+		// of some data, such as WAL that was never archived.
+		var wait time.Duration
+		var gone bool
+		gone, wait, err = r.volumeNodesUnavailable(ctx, nodes, now)
+		if err != nil {
+			continue
+		}
+		if wait > 0 {
+			result = updateReconcileResult(result, reconcile.Result{RequeueAfter: wait})
+			continue
+		}
+		if !gone {
+			err = r.warnVolumeNodeUnavailable(ctx, cluster, pod, since, "NodeAvailable",
+				"Instance %q cannot be scheduled because of the Node affinity of its volumes. "+
+					"It is rebuilt only after the Node of its volumes is deleted or "+
+					"has not been Ready for %v.",
+				instance.Name, volumeNodeAffinityGrace)
+			continue
+		}
+
+		logging.FromContext(ctx).Info("rebuilding instance on another Node",
+			"instance", instance.Name, "nodes", nodes)
+		r.Recorder.Eventf(cluster, corev1.EventTypeNormal, "VolumeNodeRebuild",
+			"Rebuilding instance %q because its volumes are bound to %s", instance.Name, where)
+
+		// The claims remain until the Pod that uses them is gone.
+		for i := range volumes {
+			if err == nil {
+				err = errors.WithStack(client.IgnoreNotFound(r.Client.Delete(ctx, &volumes[i],
+					client.Preconditions{UID: &volumes[i].UID})))
+			}
+		}
+		if err == nil {
+			err = errors.WithStack(client.IgnoreNotFound(r.Client.Delete(ctx, pod,
+				client.Preconditions{UID: &pod.UID})))
+		}
+	}
+
+	return result, err
+}
+
+// +kubebuilder:rbac:groups="",resources="nodes",verbs={get}
+
+// volumeNodesUnavailable returns whether or not every Node in nodes is deleted
+// or has not been Ready for the grace period. When a Node has not been Ready
+// for less than that, it returns how long until the grace period ends. Nodes
+// that cannot be read, such as when the operator has no permission, are
+// treated as available.
+func (r *Reconciler) volumeNodesUnavailable(
+	ctx context.Context, nodes []string, now time.Time,
+) (bool, time.Duration, error) {
+	var wait time.Duration
+
+	for _, name := range nodes {
+		node := &corev1.Node{}
+		err := r.Client.Get(ctx, client.ObjectKey{Name: name}, node)
+
+		switch {
+		case apierrors.IsNotFound(err):
+			continue
+		case apierrors.IsForbidden(err):
+			return false, 0, nil
+		case err != nil:
+			return false, 0, errors.WithStack(err)
+		}
+
+		ready := corev1.NodeCondition{Status: corev1.ConditionUnknown}
+		for _, condition := range node.Status.Conditions {
+			if condition.Type == corev1.NodeReady {
+				ready = condition
+			}
+		}
+		if ready.Status == corev1.ConditionTrue {
+			return false, 0, nil
+		}
+		if remaining := ready.LastTransitionTime.Add(volumeNodeAffinityGrace).Sub(now); remaining > wait {
+			wait = remaining
+		}
+	}
+
+	return len(nodes) > 0 && wait == 0, wait, nil
+}
+
+// warnVolumeNodeUnavailable emits a Warning event about pod, an instance that
+// cannot be scheduled since a time because of the Node affinity of its
+// volumes. Each kind of warning is emitted once each time pod becomes
+// unschedulable.
+func (r *Reconciler) warnVolumeNodeUnavailable(
+	ctx context.Context, cluster *v1beta1.PostgresCluster, pod *corev1.Pod,
+	since time.Time, kind, format string, args ...any,
+) error {
+	value := since.UTC().Format(time.RFC3339) + " " + kind
+	if pod.Annotations[naming.VolumeNodeWarning] == value {
+		return nil
+	}
+
+	r.Recorder.Eventf(cluster, corev1.EventTypeWarning, "VolumeNodeUnavailable", format, args...)
+
+	before := pod.DeepCopy()
+	if pod.Annotations == nil {
+		pod.Annotations = map[string]string{}
+	}
+	pod.Annotations[naming.VolumeNodeWarning] = value
+
+	return errors.WithStack(client.IgnoreNotFound(
+		r.Client.Patch(ctx, pod, client.MergeFrom(before))))
+}
+
+// volumeNodeAffinityConflict returns when the scheduler last reported that the
+// Pod of instance conflicts with the Node affinity of its volumes. It returns
+// false when the Pod is scheduled or unschedulable for another reason.
+func volumeNodeAffinityConflict(instance *Instance) (time.Time, bool) {
+	if len(instance.Pods) != 1 {
+		return time.Time{}, false
+	}
+
+	pod := instance.Pods[0]
+	if pod.Spec.NodeName != "" || pod.DeletionTimestamp != nil {
+		return time.Time{}, false
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled &&
+			condition.Status == corev1.ConditionFalse &&
+			condition.Reason == corev1.PodReasonUnschedulable &&
+			strings.Contains(condition.Message, "volume node affinity conflict") {
+			return condition.LastTransitionTime.Time, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// instanceVolumes returns the claims in clusterVolumes that belong to the
+// instance named instance.
+func instanceVolumes(
+	instance string, clusterVolumes []corev1.PersistentVolumeClaim,
+) []corev1.PersistentVolumeClaim {
+	var volumes []corev1.PersistentVolumeClaim
+	for i := range clusterVolumes {
+		if clusterVolumes[i].Labels[naming.LabelInstance] == instance {
+			volumes = append(volumes, clusterVolumes[i])
+		}
+	}
+	return volumes
+}
@@ -0,0 +1,245 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestVolumeNodeAffinityConflict(t *testing.T) {
+	since := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	pod := &corev1.Pod{}
+	pod.Status.Conditions = []corev1.PodCondition{{
+		Type:               corev1.PodScheduled,
+		Status:             corev1.ConditionFalse,
+		Reason:             corev1.PodReasonUnschedulable,
+		Message:            "0/3 nodes are available: 1 node(s) had volume node affinity conflict, 2 node(s) didn't match pod anti-affinity rules.",
+		LastTransitionTime: since,
+	}}
+
+	at, stuck := volumeNodeAffinityConflict(&Instance{Pods: []*corev1.Pod{pod}})
+	assert.Assert(t, stuck)
+	assert.Equal(t, at, since.Time)
+
+	t.Run("NoPod", func(t *testing.T) {
+		_, stuck := volumeNodeAffinityConflict(&Instance{})
+		assert.Assert(t, !stuck)
+	})
+
+	t.Run("Scheduled", func(t *testing.T) {
+		pod := pod.DeepCopy()
+		pod.Spec.NodeName = "node1"
+		_, stuck := volumeNodeAffinityConflict(&Instance{Pods: []*corev1.Pod{pod}})
+		assert.Assert(t, !stuck)
+	})
+
+	t.Run("OtherReason", func(t *testing.T) {
+		pod := pod.DeepCopy()
+		pod.Status.Conditions[0].Message = "0/3 nodes are available: 3 Insufficient cpu."
+		_, stuck := volumeNodeAffinityConflict(&Instance{Pods: []*corev1.Pod{pod}})
+		assert.Assert(t, !stuck)
+	})
+}
+
+func TestReconcileVolumeNodeAffinity(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{Name: "00"}}
+
+	primary := &corev1.Pod{}
+	primary.Namespace, primary.Name = "ns1", "hippo-00-aaaa-0"
+	primary.Labels = map[string]string{
+		naming.LabelInstance: "hippo-00-aaaa", naming.LabelInstanceSet: "00",
+	}
+	primary.Annotations = map[string]string{"status": `{"role":"master"}`}
+	primary.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name: naming.ContainerDatabase, State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+	}}
+
+	replica := &corev1.Pod{}
+	replica.Namespace, replica.Name = "ns1", "hippo-00-bbbb-0"
+	replica.Labels = map[string]string{
+		naming.LabelInstance: "hippo-00-bbbb", naming.LabelInstanceSet: "00",
+	}
+	replica.Status.Conditions = []corev1.PodCondition{{
+		Type:               corev1.PodScheduled,
+		Status:             corev1.ConditionFalse,
+		Reason:             corev1.PodReasonUnschedulable,
+		Message:            "0/2 nodes are available: 2 node(s) had volume node affinity conflict.",
+		LastTransitionTime: metav1.NewTime(now.Add(-time.Minute)),
+	}}
+
+	volume := &corev1.PersistentVolumeClaim{}
+	volume.Namespace, volume.Name = "ns1", "hippo-00-bbbb-pgdata"
+	volume.Labels = map[string]string{naming.LabelInstance: "hippo-00-bbbb"}
+	volume.Annotations = map[string]string{selectedNodeAnnotation: "node2"}
+
+	setup := func(t *testing.T, cluster *v1beta1.PostgresCluster, objects ...client.Object) (
+		*Reconciler, *record.FakeRecorder, *observedInstances, []corev1.PersistentVolumeClaim,
+	) {
+		pods := []corev1.Pod{*primary.DeepCopy(), *replica.DeepCopy()}
+		volumes := []corev1.PersistentVolumeClaim{*volume.DeepCopy()}
+		recorder := record.NewFakeRecorder(10)
+		return &Reconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme).
+					WithObjects(&pods[0], &pods[1], &volumes[0]).
+					WithObjects(objects...).Build(),
+				Recorder: recorder,
+			}, recorder,
+			newObservedInstances(cluster, nil, pods), volumes
+	}
+
+	t.Run("Wait", func(t *testing.T) {
+		r, recorder, instances, volumes := setup(t, cluster)
+
+		result, err := r.reconcileVolumeNodeAffinity(ctx, cluster, instances, volumes, now)
+		assert.NilError(t, err)
+		assert.Equal(t, result.RequeueAfter, time.Duration(0))
+
+		assert.Equal(t, len(recorder.Events), 1)
+		event := <-recorder.Events
+		assert.Assert(t, strings.Contains(event, "VolumeNodeUnavailable"), event)
+		assert.Assert(t, strings.Contains(event, `Node "node2"`), event)
+		assert.Assert(t, strings.Contains(event, "Rebuild"), event)
+
+		assert.NilError(t, r.Client.Get(ctx, client.ObjectKeyFromObject(volume), &corev1.PersistentVolumeClaim{}))
+
+		// The warning is not repeated while the Pod remains unschedulable.
+		pod := &corev1.Pod{}
+		assert.NilError(t, r.Client.Get(ctx, client.ObjectKeyFromObject(replica), pod))
+		assert.Assert(t, pod.Annotations[naming.VolumeNodeWarning] != "")
+
+		pods := []corev1.Pod{*primary.DeepCopy(), *pod}
+		_, err = r.reconcileVolumeNodeAffinity(ctx, cluster, newObservedInstances(cluster, nil, pods), volumes, now)
+		assert.NilError(t, err)
+		assert.Equal(t, len(recorder.Events), 0)
+
+		// It is reported again when the Pod becomes unschedulable again.
+		pods[1].Status.Conditions[0].LastTransitionTime = metav1.NewTime(now)
+		_, err = r.reconcileVolumeNodeAffinity(ctx, cluster, newObservedInstances(cluster, nil, pods), volumes, now)
+		assert.NilError(t, err)
+		assert.Equal(t, len(recorder.Events), 1)
+	})
+
+	rebuild := cluster.DeepCopy()
+	rebuild.Spec.InstanceSets[0].VolumeNodeAffinityPolicy = v1beta1.VolumeNodeAffinityRebuild
+
+	t.Run("RebuildGrace", func(t *testing.T) {
+		r, recorder, instances, volumes := setup(t, rebuild)
+
+		result, err := r.reconcileVolumeNodeAffinity(ctx, rebuild, instances, volumes, now)
+		assert.NilError(t, err)
+		assert.Equal(t, result.RequeueAfter, 4*time.Minute)
+		assert.Equal(t, len(recorder.Events), 0)
+
+		assert.NilError(t, r.Client.Get(ctx, client.ObjectKeyFromObject(volume), &corev1.PersistentVolumeClaim{}))
+	})
+
+	t.Run("Rebuild", func(t *testing.T) {
+		r, recorder, instances, volumes := setup(t, rebuild)
+
+		_, err := r.reconcileVolumeNodeAffinity(ctx, rebuild, instances, volumes, now.Add(10*time.Minute))
+		assert.NilError(t, err)
+
+		assert.Equal(t, len(recorder.Events), 1)
+		event := <-recorder.Events
+		assert.Assert(t, strings.Contains(event, "VolumeNodeRebuild"), event)
+
+		err = r.Client.Get(ctx, client.ObjectKeyFromObject(volume), &corev1.PersistentVolumeClaim{})
+		assert.Assert(t, apierrors.IsNotFound(err), "expected the claim to be deleted, got %v", err)
+		err = r.Client.Get(ctx, client.ObjectKeyFromObject(replica), &corev1.Pod{})
+		assert.Assert(t, apierrors.IsNotFound(err), "expected the Pod to be deleted, got %v", err)
+		assert.NilError(t, r.Client.Get(ctx, client.ObjectKeyFromObject(primary), &corev1.Pod{}))
+	})
+
+	t.Run("RebuildNodeReady", func(t *testing.T) {
+		node := &corev1.Node{}
+		node.Name = "node2"
+		node.Status.Conditions = []corev1.NodeCondition{{
+			Type: corev1.NodeReady, Status: corev1.ConditionTrue,
+		}}
+		r, recorder, instances, volumes := setup(t, rebuild, node)
+
+		_, err := r.reconcileVolumeNodeAffinity(ctx, rebuild, instances, volumes, now.Add(10*time.Minute))
+		assert.NilError(t, err)
+
+		assert.Equal(t, len(recorder.Events), 1)
+		event := <-recorder.Events
+		assert.Assert(t, strings.Contains(event, "VolumeNodeUnavailable"), event)
+		assert.Assert(t, strings.Contains(event, "is deleted or has not been Ready"), event)
+		assert.NilError(t, r.Client.Get(ctx, client.ObjectKeyFromObject(volume), &corev1.PersistentVolumeClaim{}))
+	})
+
+	t.Run("RebuildNodeNotReady", func(t *testing.T) {
+		node := &corev1.Node{}
+		node.Name = "node2"
+		node.Status.Conditions = []corev1.NodeCondition{{
+			Type: corev1.NodeReady, Status: corev1.ConditionUnknown,
+			LastTransitionTime: metav1.NewTime(now.Add(8 * time.Minute)),
+		}}
+		r, recorder, instances, volumes := setup(t, rebuild, node)
+
+		// The Node has not been Ready for two minutes.
+		result, err := r.reconcileVolumeNodeAffinity(ctx, rebuild, instances, volumes, now.Add(10*time.Minute))
+		assert.NilError(t, err)
+		assert.Equal(t, result.RequeueAfter, 3*time.Minute)
+		assert.Equal(t, len(recorder.Events), 0)
+		assert.NilError(t, r.Client.Get(ctx, client.ObjectKeyFromObject(volume), &corev1.PersistentVolumeClaim{}))
+
+		// The Node has not been Ready for six minutes.
+		_, err = r.reconcileVolumeNodeAffinity(ctx, rebuild, instances, volumes, now.Add(14*time.Minute))
+		assert.NilError(t, err)
+		assert.Equal(t, len(recorder.Events), 1)
+		event := <-recorder.Events
+		assert.Assert(t, strings.Contains(event, "VolumeNodeRebuild"), event)
+
+		err = r.Client.Get(ctx, client.ObjectKeyFromObject(volume), &corev1.PersistentVolumeClaim{})
+		assert.Assert(t, apierrors.IsNotFound(err), "expected the claim to be deleted, got %v", err)
+	})
+
+	t.Run("RebuildWithoutPrimary", func(t *testing.T) {
+		r, recorder, _, volumes := setup(t, rebuild)
+		instances := newObservedInstances(rebuild, nil, []corev1.Pod{*replica.DeepCopy()})
+
+		_, err := r.reconcileVolumeNodeAffinity(ctx, rebuild, instances, volumes, now.Add(10*time.Minute))
+		assert.NilError(t, err)
+
+		assert.Equal(t, len(recorder.Events), 1)
+		event := <-recorder.Events
+		assert.Assert(t, strings.Contains(event, "another instance is primary"), event)
+		assert.NilError(t, r.Client.Get(ctx, client.ObjectKeyFromObject(volume), &corev1.PersistentVolumeClaim{}))
+	})
+}
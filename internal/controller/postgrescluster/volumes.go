@@ -23,6 +23,7 @@ import (
 	"github.com/pkg/errors"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -33,9 +34,11 @@ import (
 
 	"github.com/crunchydata/postgres-operator/internal/config"
 	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/logging"
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/pgbackrest"
 	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/servicemesh"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
@@ -145,6 +148,66 @@ func (r *Reconciler) observePersistentVolumeClaims(
 	return volumes.Items, err
 }
 
+// +kubebuilder:rbac:groups="storage.k8s.io",resources="storageclasses",verbs={get,list}
+
+// observeStorageMedia returns the kind of storage behind the data volumes of
+// cluster as told by their StorageClasses: "HDD" when any is HDD, "SSD" when
+// all are SSD, and an empty string otherwise. StorageClasses are only read
+// when automatic tuning is enabled, and those that cannot be read, such as
+// when the operator is limited to namespaces, count as unknown.
+func (r *Reconciler) observeStorageMedia(
+	ctx context.Context, cluster *v1beta1.PostgresCluster,
+) string {
+	if !postgres.AutoTuningEnabled(cluster) {
+		return ""
+	}
+	log := logging.FromContext(ctx)
+
+	var classes *storagev1.StorageClassList
+	class := func(name *string) *storagev1.StorageClass {
+		if name != nil && *name != "" {
+			found := &storagev1.StorageClass{}
+			if err := r.Client.Get(ctx, client.ObjectKey{Name: *name}, found); err != nil {
+				log.V(1).Info("unable to read StorageClass", "name", *name, "error", err.Error())
+				return nil
+			}
+			return found
+		}
+
+		// Volumes without a class get the default StorageClass.
+		if classes == nil {
+			classes = &storagev1.StorageClassList{}
+			if err := r.Client.List(ctx, classes); err != nil {
+				log.V(1).Info("unable to list StorageClasses", "error", err.Error())
+			}
+		}
+		for i := range classes.Items {
+			if classes.Items[i].Annotations["storageclass.kubernetes.io/is-default-class"] == "true" {
+				return &classes.Items[i]
+			}
+		}
+		return nil
+	}
+
+	hdd, ssd := false, len(cluster.Spec.InstanceSets) > 0
+	for _, set := range cluster.Spec.InstanceSets {
+		media := ""
+		if found := class(set.DataVolumeClaimSpec.StorageClassName); found != nil {
+			media = postgres.StorageMedia(found)
+		}
+		hdd = hdd || media == "HDD"
+		ssd = ssd && media == "SSD"
+	}
+
+	switch {
+	case hdd:
+		return "HDD"
+	case ssd:
+		return "SSD"
+	}
+	return ""
+}
+
 // configureExistingPVCs configures the defined pgData, pg_wal and pgBackRest
 // repo volumes to be used by the PostgresCluster. In the case of existing
 // pgData volumes, an appropriate instance set name is defined that will be
@@ -471,7 +534,10 @@ func (r *Reconciler) reconcileMovePGDataDir(ctx context.Context,
 
 	jobSpec := &batchv1.JobSpec{
 		Template: corev1.PodTemplateSpec{
-			ObjectMeta: metav1.ObjectMeta{Labels: labels},
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: servicemesh.NoProxyAnnotations(cluster),
+				Labels:      labels,
+			},
 			Spec: corev1.PodSpec{
 				// Set the image pull secrets, if any exist.
 				// This is set here rather than using the service account due to the lack
@@ -506,6 +572,7 @@ func (r *Reconciler) reconcileMovePGDataDir(ctx context.Context,
 		jobSpec.Template.Spec.PriorityClassName =
 			*cluster.Spec.InstanceSets[0].PriorityClassName
 	}
+	addJobLimits(cluster, jobSpec)
 	moveDirJob.Spec = *jobSpec
 
 	// set gvk and ownership refs
@@ -589,7 +656,10 @@ func (r *Reconciler) reconcileMoveWALDir(ctx context.Context,
 
 	jobSpec := &batchv1.JobSpec{
 		Template: corev1.PodTemplateSpec{
-			ObjectMeta: metav1.ObjectMeta{Labels: labels},
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: servicemesh.NoProxyAnnotations(cluster),
+				Labels:      labels,
+			},
 			Spec: corev1.PodSpec{
 				// Set the image pull secrets, if any exist.
 				// This is set here rather than using the service account due to the lack
@@ -624,6 +694,7 @@ func (r *Reconciler) reconcileMoveWALDir(ctx context.Context,
 		jobSpec.Template.Spec.PriorityClassName =
 			*cluster.Spec.InstanceSets[0].PriorityClassName
 	}
+	addJobLimits(cluster, jobSpec)
 	moveDirJob.Spec = *jobSpec
 
 	// set gvk and ownership refs
@@ -712,7 +783,10 @@ func (r *Reconciler) reconcileMoveRepoDir(ctx context.Context,
 
 	jobSpec := &batchv1.JobSpec{
 		Template: corev1.PodTemplateSpec{
-			ObjectMeta: metav1.ObjectMeta{Labels: labels},
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: servicemesh.NoProxyAnnotations(cluster),
+				Labels:      labels,
+			},
 			Spec: corev1.PodSpec{
 				// Set the image pull secrets, if any exist.
 				// This is set here rather than using the service account due to the lack
@@ -746,6 +820,7 @@ func (r *Reconciler) reconcileMoveRepoDir(ctx context.Context,
 			jobSpec.Template.Spec.PriorityClassName = *repoHost.PriorityClassName
 		}
 	}
+	addJobLimits(cluster, jobSpec)
 	moveDirJob.Spec = *jobSpec
 
 	// set gvk and ownership refs
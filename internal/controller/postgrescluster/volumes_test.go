@@ -27,12 +27,14 @@ import (
 	"gotest.tools/v3/assert"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
 	"github.com/crunchydata/postgres-operator/internal/initialize"
@@ -927,3 +929,33 @@ volumes:
 
 	})
 }
+
+func TestObserveStorageMedia(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	fast := &storagev1.StorageClass{Parameters: map[string]string{"type": "gp3"}}
+	fast.Name = "fast"
+	fast.Annotations = map[string]string{"storageclass.kubernetes.io/is-default-class": "true"}
+	slow := &storagev1.StorageClass{Parameters: map[string]string{"type": "st1"}}
+	slow.Name = "slow"
+
+	r := &Reconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(fast, slow).Build(),
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{}}
+	assert.Equal(t, r.observeStorageMedia(ctx, cluster), "", "expected nothing without tuning")
+
+	cluster.Spec.Tuning = &v1beta1.TuningSpec{Mode: "Auto"}
+	assert.Equal(t, r.observeStorageMedia(ctx, cluster), "SSD", "expected the default class")
+
+	cluster.Spec.InstanceSets = append(cluster.Spec.InstanceSets, v1beta1.PostgresInstanceSetSpec{})
+	cluster.Spec.InstanceSets[1].DataVolumeClaimSpec.StorageClassName = initialize.String("slow")
+	assert.Equal(t, r.observeStorageMedia(ctx, cluster), "HDD")
+
+	cluster.Spec.InstanceSets[1].DataVolumeClaimSpec.StorageClassName = initialize.String("missing")
+	assert.Equal(t, r.observeStorageMedia(ctx, cluster), "")
+}
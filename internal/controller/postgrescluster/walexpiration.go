@@ -0,0 +1,252 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgrescluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/pgbackrest"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// reconcileWALExpiration compares the replication slots managed by Patroni with
+// the full backups in every pgBackRest repository. When the next full backup
+// could expire WAL that a slot has not yet consumed, the WALExpirationHeld
+// condition is set and pgBackRest stops expiring backups and WAL until the slots
+// catch up. The full backups are read from pgBackRest only after a backup
+// finishes and are otherwise taken from the repository status. Slots advance
+// without changing any Kubernetes objects, so this returns a result that checks
+// them again later.
+func (r *Reconciler) reconcileWALExpiration(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+) reconcile.Result {
+	log := logging.FromContext(ctx)
+
+	slots := managedReplicationSlots(cluster)
+	if slots.Len() == 0 {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, v1beta1.WALExpirationHeld)
+		return reconcile.Result{}
+	}
+
+	// Nothing expires until there is a stanza, and only the primary knows where
+	// its slots are.
+	var stanzaCreated bool
+	if cluster.Status.PGBackRest != nil {
+		for _, repo := range cluster.Status.PGBackRest.Repos {
+			stanzaCreated = stanzaCreated || repo.StanzaCreated
+		}
+	}
+	pod, _ := instances.writablePod(naming.ContainerDatabase)
+	if !stanzaCreated || pod == nil {
+		return reconcile.Result{}
+	}
+
+	exec := func(_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string) error {
+		return r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase, stdin, stdout, stderr, command...)
+	}
+	next := reconcile.Result{RequeueAfter: 5 * time.Minute}
+
+	// The first row is the current WAL file; the rest are slots and the oldest
+	// WAL file each one needs.
+	var stdout, stderr bytes.Buffer
+	err := exec(ctx, nil, &stdout, &stderr,
+		"psql", "-Xw", "--tuples-only", "--no-align", "--command="+strings.TrimSpace(`
+SELECT NULL, pg_catalog.pg_walfile_name(pg_catalog.pg_current_wal_lsn())
+UNION ALL
+SELECT slot_name, pg_catalog.pg_walfile_name(restart_lsn)
+  FROM pg_catalog.pg_replication_slots WHERE restart_lsn IS NOT NULL`))
+
+	log.V(1).Info("observed replication slots",
+		"stdout", stdout.String(), "stderr", stderr.String())
+
+	var current string
+	needed := make(map[string]string)
+	for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+		if name, file, ok := strings.Cut(line, "|"); ok && name == "" {
+			current = file
+		} else if ok && slots.Has(name) {
+			needed[name] = file
+		}
+	}
+
+	// Backups change only when one finishes, so read them again only when a
+	// backup has finished since they were last read.
+	status := cluster.Status.PGBackRest
+	if err == nil && (status.BackupsObserved == nil ||
+		!lastBackupCompletion(cluster).Before(status.BackupsObserved)) {
+		var backups []pgbackrest.InfoBackup
+		if backups, err = r.pgBackRestInfo(ctx, cluster, exec,
+			lastBackupCompletion(cluster).Time); err == nil {
+			for i := range status.Repos {
+				index, _ := strconv.Atoi(regexRepoIndex.FindString(status.Repos[i].Name))
+				status.Repos[i].FullBackupWAL = nil
+				for _, backup := range backups {
+					if backup.Database.RepoKey == index && backup.Type == "full" {
+						status.Repos[i].FullBackupWAL = append(
+							status.Repos[i].FullBackupWAL, backup.Archive.Start)
+					}
+				}
+			}
+			now := metav1.Now()
+			status.BackupsObserved = &now
+		}
+	}
+	if err != nil {
+		// Keep any existing condition until the slots can be observed again.
+		log.Error(err, "unable to compare replication slots with pgBackRest backups")
+		return next
+	}
+
+	fulls := make(map[string][]string, len(status.Repos))
+	for _, repo := range status.Repos {
+		fulls[repo.Name] = repo.FullBackupWAL
+	}
+
+	condition := metav1.Condition{
+		Type:               v1beta1.WALExpirationHeld,
+		ObservedGeneration: cluster.GetGeneration(),
+		Status:             metav1.ConditionFalse,
+		Reason:             "SlotsCaughtUp",
+		Message:            "Replication slots do not need WAL that pgBackRest would expire",
+	}
+	if behind := slotsNeedingExpiredWAL(cluster, current, needed, fulls); len(behind) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "SlotsBehind"
+		condition.Message = fmt.Sprintf(
+			"Replication slots %s need WAL that pgBackRest would expire; "+
+				"backups and WAL are kept until they catch up",
+			strings.Join(behind, ", "))
+
+		if !meta.IsStatusConditionTrue(cluster.Status.Conditions, v1beta1.WALExpirationHeld) {
+			r.Recorder.Event(cluster, corev1.EventTypeWarning, "WALExpirationHeld", condition.Message)
+		}
+	}
+
+	meta.SetStatusCondition(&cluster.Status.Conditions, condition)
+	return next
+}
+
+// lastBackupCompletion returns when the most recent backup of cluster finished
+// according to its status. It is zero when no backup has finished.
+func lastBackupCompletion(cluster *v1beta1.PostgresCluster) *metav1.Time {
+	latest := &metav1.Time{}
+	later := func(t *metav1.Time) {
+		if t != nil && latest.Before(t) {
+			latest = t
+		}
+	}
+
+	if status := cluster.Status.PGBackRest; status != nil {
+		if status.ManualBackup != nil {
+			later(status.ManualBackup.CompletionTime)
+		}
+		for i := range status.ScheduledBackups {
+			later(status.ScheduledBackups[i].CompletionTime)
+		}
+		if status.RepoMigration != nil {
+			later(status.RepoMigration.CompletionTime)
+		}
+	}
+	if condition := meta.FindStatusCondition(cluster.Status.Conditions,
+		ConditionReplicaCreate); condition != nil && condition.Status == metav1.ConditionTrue {
+		later(&condition.LastTransitionTime)
+	}
+	return latest
+}
+
+// managedReplicationSlots returns the names of the permanent replication slots
+// defined in the Patroni dynamic configuration of cluster. Standby clusters that
+// stream from cluster use these slots.
+// - https://patroni.readthedocs.io/en/latest/dynamic_configuration.html
+func managedReplicationSlots(cluster *v1beta1.PostgresCluster) sets.String {
+	names := sets.NewString()
+	if cluster.Spec.Patroni != nil {
+		if slots, ok := cluster.Spec.Patroni.DynamicConfiguration["slots"].(map[string]any); ok {
+			for name := range slots {
+				names.Insert(name)
+			}
+		}
+	}
+	return names
+}
+
+// slotsNeedingExpiredWAL returns the sorted names of slots that need a WAL file
+// older than every WAL file pgBackRest keeps after the next full backup. The
+// current WAL file is where that backup would start. The WAL files at which the
+// full backups of each repository start, oldest first, determine what that
+// repository keeps according to its "retention-full" option. Repositories
+// without a count of full backups to keep, and those that hold fewer than that
+// count, expire nothing.
+func slotsNeedingExpiredWAL(cluster *v1beta1.PostgresCluster, current string,
+	slots map[string]string, fulls map[string][]string,
+) []string {
+	// WAL files are named for their timeline and position. Compare positions so
+	// that a promotion does not change the order.
+	position := func(file string) string {
+		if len(file) > 8 {
+			return strings.ToUpper(file[8:])
+		}
+		return ""
+	}
+
+	global := cluster.Spec.Backups.PGBackRest.Global
+	oldest := ""
+	for _, repo := range cluster.Spec.Backups.PGBackRest.Repos {
+		retention, err := strconv.Atoi(global[repo.Name+"-retention-full"])
+		if kind := global[repo.Name+"-retention-full-type"]; err != nil || retention < 1 ||
+			(kind != "" && kind != "count") {
+			continue
+		}
+
+		starts := fulls[repo.Name]
+		if len(starts) < retention {
+			continue
+		}
+
+		// The next full backup and the newest (retention - 1) existing ones are kept.
+		kept := current
+		if retention > 1 {
+			kept = starts[len(starts)-retention+1]
+		}
+		if oldest == "" || position(kept) < position(oldest) {
+			oldest = kept
+		}
+	}
+
+	var names []string
+	for name, file := range slots {
+		if oldest != "" && position(file) < position(oldest) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,240 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestManagedReplicationSlots(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	assert.Equal(t, managedReplicationSlots(cluster).Len(), 0)
+
+	cluster.Spec.Patroni = &v1beta1.PatroniSpec{
+		DynamicConfiguration: map[string]any{
+			"slots": map[string]any{
+				"standby1": map[string]any{"type": "physical"},
+				"standby2": map[string]any{"type": "physical"},
+			},
+		},
+	}
+	assert.DeepEqual(t, managedReplicationSlots(cluster).List(),
+		[]string{"standby1", "standby2"})
+
+	cluster.Spec.Patroni.DynamicConfiguration["slots"] = "wrong"
+	assert.Equal(t, managedReplicationSlots(cluster).Len(), 0)
+}
+
+func TestSlotsNeedingExpiredWAL(t *testing.T) {
+	fulls := map[string][]string{
+		"repo1": {"000000010000000000000002", "000000010000000000000006"},
+		"repo2": {"000000010000000000000003"},
+	}
+	slots := map[string]string{
+		"far":  "000000010000000000000003",
+		"near": "000000010000000000000007",
+	}
+	const current = "000000020000000000000008"
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Spec.Backups.PGBackRest.Repos = []v1beta1.PGBackRestRepo{{Name: "repo1"}}
+
+	for _, tt := range []struct {
+		name   string
+		global map[string]string
+		expect []string
+	}{
+		{name: "NoRetention"},
+		{name: "TimeRetention", global: map[string]string{
+			"repo1-retention-full": "1", "repo1-retention-full-type": "time",
+		}},
+		{name: "KeepOne", expect: []string{"far", "near"}, global: map[string]string{
+			"repo1-retention-full": "1",
+		}},
+		{name: "KeepTwo", expect: []string{"far"}, global: map[string]string{
+			"repo1-retention-full": "2",
+		}},
+		{name: "KeepThree", global: map[string]string{
+			"repo1-retention-full": "3", "repo1-retention-full-type": "count",
+		}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cluster := cluster.DeepCopy()
+			cluster.Spec.Backups.PGBackRest.Global = tt.global
+
+			assert.DeepEqual(t,
+				slotsNeedingExpiredWAL(cluster, current, slots, fulls), tt.expect)
+		})
+	}
+
+	t.Run("MultipleRepos", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Backups.PGBackRest.Repos = append(
+			cluster.Spec.Backups.PGBackRest.Repos, v1beta1.PGBackRestRepo{Name: "repo2"})
+		cluster.Spec.Backups.PGBackRest.Global = map[string]string{
+			"repo1-retention-full": "2",
+		}
+
+		// A repository without a count of full backups to keep is ignored.
+		assert.DeepEqual(t, slotsNeedingExpiredWAL(cluster, current, slots, fulls),
+			[]string{"far"})
+
+		// So is one that holds fewer full backups than it keeps.
+		cluster.Spec.Backups.PGBackRest.Global["repo2-retention-full"] = "2"
+		assert.DeepEqual(t, slotsNeedingExpiredWAL(cluster, current, slots, fulls),
+			[]string{"far"})
+
+		// WAL in any repository that keeps it remains available.
+		fulls := map[string][]string{
+			"repo1": fulls["repo1"],
+			"repo2": {"000000010000000000000001", "000000010000000000000003"},
+		}
+		assert.Assert(t, slotsNeedingExpiredWAL(cluster, current, slots, fulls) == nil)
+	})
+}
+
+func TestReconcileWALExpiration(t *testing.T) {
+	ctx := context.Background()
+
+	pod := &corev1.Pod{}
+	pod.Namespace, pod.Name = "ns1", "hippo-00-abcd-0"
+	pod.Annotations = map[string]string{"status": `{"role":"master"}`}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  naming.ContainerDatabase,
+		State: corev1.ContainerState{Running: new(corev1.ContainerStateRunning)},
+	}}
+
+	instances := &observedInstances{forCluster: []*Instance{
+		{Name: "hippo-00-abcd", Pods: []*corev1.Pod{pod}},
+	}}
+
+	var slots, info string
+	var infoCalls int
+	recorder := record.NewFakeRecorder(10)
+	reconciler := &Reconciler{
+		Recorder: recorder,
+		PodExec: func(namespace, pod, container string, _ io.Reader, stdout, _ io.Writer, command ...string) error {
+			assert.Equal(t, container, naming.ContainerDatabase)
+			if command[0] == "psql" {
+				_, err := stdout.Write([]byte(slots))
+				return err
+			}
+			infoCalls++
+			_, err := stdout.Write([]byte(info))
+			return err
+		},
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Namespace = "ns1"
+	cluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{
+		Repos: []v1beta1.RepoStatus{{Name: "repo1", StanzaCreated: true}},
+	}
+	cluster.Spec.Backups.PGBackRest.Global = map[string]string{"repo1-retention-full": "1"}
+	cluster.Spec.Backups.PGBackRest.Repos = []v1beta1.PGBackRestRepo{{Name: "repo1"}}
+
+	t.Run("NoSlots", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type: v1beta1.WALExpirationHeld, Status: metav1.ConditionTrue,
+		})
+
+		result := reconciler.reconcileWALExpiration(ctx, cluster, instances)
+		assert.Equal(t, result.RequeueAfter, time.Duration(0))
+		assert.Equal(t, len(cluster.Status.Conditions), 0)
+	})
+
+	cluster.Spec.Patroni = &v1beta1.PatroniSpec{
+		DynamicConfiguration: map[string]any{
+			"slots": map[string]any{"standby1": map[string]any{"type": "physical"}},
+		},
+	}
+	info = `[{"name":"db","backup":[{"archive":{"start":"000000010000000000000004"},` +
+		`"database":{"repo-key":1},"type":"full"}]}]`
+
+	t.Run("Behind", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		slots = strings.Join([]string{
+			"|000000010000000000000006",
+			"standby1|000000010000000000000005",
+			"unmanaged|000000010000000000000001",
+		}, "\n")
+
+		result := reconciler.reconcileWALExpiration(ctx, cluster, instances)
+		assert.Assert(t, result.RequeueAfter > 0)
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.WALExpirationHeld)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionTrue)
+		assert.Equal(t, condition.Reason, "SlotsBehind")
+		assert.Assert(t, cmp.Contains(condition.Message, "standby1"))
+		assert.Assert(t, !strings.Contains(condition.Message, "unmanaged"))
+		assert.Assert(t, cmp.Contains(<-recorder.Events, "WALExpirationHeld"))
+
+		assert.DeepEqual(t, cluster.Status.PGBackRest.Repos[0].FullBackupWAL,
+			[]string{"000000010000000000000004"})
+		assert.Assert(t, cluster.Status.PGBackRest.BackupsObserved != nil)
+
+		// The event happens once, and backups are not read again until another
+		// one finishes.
+		infoCalls = 0
+		reconciler.reconcileWALExpiration(ctx, cluster, instances)
+		assert.Equal(t, len(recorder.Events), 0)
+		assert.Equal(t, infoCalls, 0)
+
+		cluster.Status.PGBackRest.ManualBackup = &v1beta1.PGBackRestJobStatus{
+			CompletionTime: &metav1.Time{Time: time.Now().Add(time.Minute)},
+		}
+		reconciler.reconcileWALExpiration(ctx, cluster, instances)
+		assert.Equal(t, infoCalls, 1)
+	})
+
+	t.Run("CaughtUp", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		slots = strings.Join([]string{
+			"|000000010000000000000006",
+			"standby1|000000010000000000000006",
+		}, "\n")
+
+		reconciler.reconcileWALExpiration(ctx, cluster, instances)
+
+		condition := meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.WALExpirationHeld)
+		assert.Assert(t, condition != nil)
+		assert.Equal(t, condition.Status, metav1.ConditionFalse)
+		assert.Equal(t, condition.Reason, "SlotsCaughtUp")
+	})
+
+	t.Run("NoStanza", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Status.PGBackRest = nil
+
+		reconciler.reconcileWALExpiration(ctx, cluster, instances)
+		assert.Equal(t, len(cluster.Status.Conditions), 0)
+	})
+}
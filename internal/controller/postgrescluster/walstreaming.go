@@ -0,0 +1,325 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/crunchydata/postgres-operator/internal/config"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	// walStreamingDirectory is where pg_receivewal writes WAL.
+	walStreamingDirectory = "/pgwalstream"
+
+	// walStreamingSlot is the physical replication slot and application name
+	// of pg_receivewal.
+	walStreamingSlot = "pgo_walstream"
+)
+
+var (
+	walStreamingReady = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "postgrescluster",
+		Name:      "wal_streaming_ready",
+		Help:      "Whether or not pg_receivewal is connected and writing WAL (1) or not (0).",
+	}, []string{"namespace", "name"})
+
+	walStreamingRestarts = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "postgrescluster",
+		Name:      "wal_streaming_restarts",
+		Help:      "The number of times the pg_receivewal container has restarted.",
+	}, []string{"namespace", "name"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(walStreamingReady, walStreamingRestarts)
+}
+
+// +kubebuilder:rbac:groups="",resources="persistentvolumeclaims",verbs={get,create,patch,delete}
+// +kubebuilder:rbac:groups="apps",resources="deployments",verbs={get,create,patch,delete}
+// +kubebuilder:rbac:groups="",resources="pods",verbs={list}
+
+// reconcileWALStreaming manages the Deployment and volume of pg_receivewal,
+// which streams WAL from the primary independent of pgBackRest archiving.
+// When streaming is disabled, they are deleted along with the replication slot
+// of pg_receivewal so that the primary no longer keeps WAL for it.
+func (r *Reconciler) reconcileWALStreaming(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+	replicationSecret *corev1.Secret,
+) error {
+	deploy := &appsv1.Deployment{ObjectMeta: naming.ClusterWALStreaming(cluster)}
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: naming.ClusterWALStreaming(cluster)}
+
+	if cluster.Spec.Backups.WALStreaming == nil {
+		return r.deleteWALStreaming(ctx, cluster, instances, deploy, pvc)
+	}
+	spec := cluster.Spec.Backups.WALStreaming
+
+	pvc.Annotations = naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil())
+	pvc.Labels = naming.Merge(cluster.Spec.Metadata.GetLabelsOrNil(),
+		walStreamingLabels(cluster))
+	pvc.Spec = spec.VolumeClaimSpec
+
+	pvc.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"))
+	err := errors.WithStack(r.setControllerReference(cluster, pvc))
+	if err == nil {
+		err = r.handlePersistentVolumeClaimError(cluster,
+			errors.WithStack(r.apply(ctx, pvc)))
+	}
+
+	if err == nil {
+		generateWALStreamingDeployment(cluster, replicationSecret, deploy)
+
+		deploy.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("Deployment"))
+		err = errors.WithStack(r.setControllerReference(cluster, deploy))
+	}
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, deploy))
+	}
+
+	pods := &corev1.PodList{}
+	if err == nil {
+		err = errors.WithStack(r.Client.List(ctx, pods,
+			client.InNamespace(cluster.Namespace),
+			client.MatchingLabels(walStreamingLabels(cluster))))
+	}
+	if err == nil {
+		setWALStreamingStatus(cluster, deploy, pods.Items)
+	}
+
+	return err
+}
+
+// deleteWALStreaming deletes the Deployment and volume of pg_receivewal then
+// drops its replication slot once pg_receivewal has disconnected.
+func (r *Reconciler) deleteWALStreaming(ctx context.Context,
+	cluster *v1beta1.PostgresCluster, instances *observedInstances,
+	deploy *appsv1.Deployment, pvc *corev1.PersistentVolumeClaim,
+) error {
+	// Check the client cache first using Get.
+	for _, object := range []client.Object{deploy, pvc} {
+		err := errors.WithStack(r.Client.Get(ctx, client.ObjectKeyFromObject(object), object))
+		if err == nil {
+			err = errors.WithStack(r.deleteControlled(ctx, cluster, object))
+		}
+		if err = client.IgnoreNotFound(err); err != nil {
+			return err
+		}
+	}
+
+	key := client.ObjectKeyFromObject(cluster)
+	walStreamingReady.DeleteLabelValues(key.Namespace, key.Name)
+	walStreamingRestarts.DeleteLabelValues(key.Namespace, key.Name)
+
+	// The status remains until the replication slot is gone.
+	if cluster.Status.WALStreaming == nil {
+		return nil
+	}
+	pod, _ := instances.writablePod(naming.ContainerDatabase)
+	if pod == nil {
+		return nil
+	}
+
+	// A slot cannot be dropped while it is in use, so this waits for the
+	// pg_receivewal Pod to stop.
+	var stdout, stderr bytes.Buffer
+	err := errors.WithStack(r.PodExec(pod.Namespace, pod.Name, naming.ContainerDatabase,
+		nil, &stdout, &stderr, "psql", "-Xw", "--tuples-only", "--no-align",
+		"--command="+strings.Join([]string{
+			`SELECT pg_drop_replication_slot(slot_name) FROM pg_replication_slots`,
+			`WHERE slot_name = '` + walStreamingSlot + `' AND NOT active;`,
+			`SELECT count(*) FROM pg_replication_slots`,
+			`WHERE slot_name = '` + walStreamingSlot + `'`,
+		}, " ")))
+
+	logging.FromContext(ctx).V(1).Info("dropped WAL streaming slot",
+		"stdout", stdout.String(), "stderr", stderr.String())
+
+	if err == nil && strings.TrimSpace(stdout.String()) == "0" {
+		cluster.Status.WALStreaming = nil
+	}
+	return err
+}
+
+// walStreamingLabels returns the labels of pg_receivewal objects of cluster.
+func walStreamingLabels(cluster *v1beta1.PostgresCluster) labels.Set {
+	return map[string]string{
+		naming.LabelCluster: cluster.Name,
+		naming.LabelRole:    naming.RoleWALStreaming,
+	}
+}
+
+// generateWALStreamingDeployment populates deploy with a single Pod that runs
+// pg_receivewal as the replication user. It creates a physical replication
+// slot on the primary so that WAL is kept until it is received. Slots do not
+// survive a failover, so pg_receivewal exits when its connection is lost and
+// creates the slot again on the new primary when it restarts.
+// - https://www.postgresql.org/docs/current/app-pgreceivewal.html
+func generateWALStreamingDeployment(
+	cluster *v1beta1.PostgresCluster, replicationSecret *corev1.Secret,
+	deploy *appsv1.Deployment,
+) {
+	spec := cluster.Spec.Backups.WALStreaming
+
+	deploy.Annotations = naming.Merge(cluster.Spec.Metadata.GetAnnotationsOrNil())
+	deploy.Labels = naming.Merge(cluster.Spec.Metadata.GetLabelsOrNil(),
+		walStreamingLabels(cluster))
+
+	args := []string{"pg_receivewal", "--no-loop", "--no-password",
+		"--directory=" + walStreamingDirectory, "--slot=" + walStreamingSlot}
+	if spec.Synchronous != nil && *spec.Synchronous {
+		args = append(args, "--synchronous")
+	}
+	script := strings.Join([]string{
+		`pg_receivewal --no-password --create-slot --if-not-exists --slot=` + walStreamingSlot,
+		`exec ` + strings.Join(args, " "),
+	}, "\n")
+
+	const certDirectory = "/pgconf/tls"
+	container := corev1.Container{
+		Command:         []string{"bash", "-ceu", "--", script},
+		Image:           config.PostgresContainerImage(cluster),
+		ImagePullPolicy: cluster.Spec.ImagePullPolicy,
+		Name:            naming.ContainerWALStreaming,
+		Resources:       spec.Resources,
+		SecurityContext: initialize.RestrictedSecurityContext(),
+		Env: []corev1.EnvVar{
+			{Name: "PGHOST", Value: fmt.Sprintf("%s.%s.svc",
+				naming.ClusterPrimaryService(cluster).Name, cluster.Namespace)},
+			{Name: "PGPORT", Value: fmt.Sprint(*cluster.Spec.Port)},
+			{Name: "PGUSER", Value: postgres.ReplicationUser},
+			{Name: "PGAPPNAME", Value: walStreamingSlot},
+			{Name: "PGSSLMODE", Value: "verify-ca"},
+			{Name: "PGSSLCERT", Value: certDirectory + "/" + naming.ReplicationCert},
+			{Name: "PGSSLKEY", Value: certDirectory + "/" + naming.ReplicationPrivateKey},
+			{Name: "PGSSLROOTCERT", Value: certDirectory + "/" + naming.ReplicationCACert},
+		},
+		// pg_receivewal writes to a file with the ".partial" suffix until
+		// a segment is complete.
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				Exec: &corev1.ExecAction{Command: []string{
+					"bash", "-c", "--", `compgen -G "$1/*.partial"`, "-", walStreamingDirectory,
+				}},
+			},
+			PeriodSeconds: 10,
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: naming.CertVolume, MountPath: certDirectory, ReadOnly: true},
+			{Name: "walstream", MountPath: walStreamingDirectory},
+		},
+	}
+
+	// Stop streaming when the cluster is shutdown or is a standby; there is
+	// no primary to stream from.
+	replicas := int32(1)
+	if (cluster.Spec.Shutdown != nil && *cluster.Spec.Shutdown) ||
+		(cluster.Spec.Standby != nil && cluster.Spec.Standby.Enabled) {
+		replicas = 0
+	}
+
+	deploy.Spec = appsv1.DeploymentSpec{
+		Replicas: &replicas,
+		Selector: &metav1.LabelSelector{MatchLabels: walStreamingLabels(cluster)},
+		// The volume can be mounted by only one Pod at a time, and only one
+		// client can use the replication slot.
+		Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+		Template: corev1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: deploy.Annotations,
+				Labels:      deploy.Labels,
+			},
+			Spec: corev1.PodSpec{
+				// Set the image pull secrets, if any exist.
+				// This is set here rather than using the service account due to the lack
+				// of propagation to existing pods when the CRD is updated:
+				// https://github.com/kubernetes/kubernetes/issues/88456
+				ImagePullSecrets: cluster.Spec.ImagePullSecrets,
+				Containers:       []corev1.Container{container},
+				SecurityContext:  postgres.PodSecurityContext(cluster),
+				// pg_receivewal doesn't make Kubernetes API calls, so we can just
+				// use the default ServiceAccount and not mount its credentials.
+				AutomountServiceAccountToken: initialize.Bool(false),
+				EnableServiceLinks:           initialize.Bool(false),
+				Volumes: []corev1.Volume{
+					{
+						Name: naming.CertVolume,
+						VolumeSource: corev1.VolumeSource{
+							Secret: &corev1.SecretVolumeSource{
+								SecretName: replicationSecret.Name,
+								// The client key must not be readable by others.
+								// - https://www.postgresql.org/docs/current/libpq-ssl.html
+								DefaultMode: initialize.Int32(0o600),
+								Items: []corev1.KeyToPath{
+									{Key: naming.ReplicationCert, Path: naming.ReplicationCert},
+									{Key: naming.ReplicationPrivateKey, Path: naming.ReplicationPrivateKey},
+									{Key: naming.ReplicationCACert, Path: naming.ReplicationCACert},
+								},
+							},
+						},
+					},
+					{
+						Name: "walstream",
+						VolumeSource: corev1.VolumeSource{
+							PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+								ClaimName: naming.ClusterWALStreaming(cluster).Name,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// setWALStreamingStatus records the health of pg_receivewal in the status of
+// cluster and exports it as metrics.
+func setWALStreamingStatus(
+	cluster *v1beta1.PostgresCluster, deploy *appsv1.Deployment, pods []corev1.Pod,
+) {
+	status := &v1beta1.WALStreamingStatus{Ready: deploy.Status.ReadyReplicas > 0}
+	for _, pod := range pods {
+		for _, container := range pod.Status.ContainerStatuses {
+			if container.Name == naming.ContainerWALStreaming {
+				status.Restarts += container.RestartCount
+			}
+		}
+	}
+	cluster.Status.WALStreaming = status
+
+	ready := 0.0
+	if status.Ready {
+		ready = 1
+	}
+	walStreamingReady.WithLabelValues(cluster.Namespace, cluster.Name).Set(ready)
+	walStreamingRestarts.WithLabelValues(cluster.Namespace, cluster.Name).Set(float64(status.Restarts))
+}
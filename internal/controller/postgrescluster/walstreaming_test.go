@@ -0,0 +1,129 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgrescluster
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"gotest.tools/v3/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestGenerateWALStreamingDeployment(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Name = "hippo"
+	cluster.Namespace = "ns1"
+	cluster.Spec.Port = initialize.Int32(5432)
+	cluster.Spec.Backups.WALStreaming = &v1beta1.WALStreamingSpec{}
+	secret := &corev1.Secret{ObjectMeta: naming.ReplicationClientCertSecret(cluster)}
+
+	deploy := &appsv1.Deployment{ObjectMeta: naming.ClusterWALStreaming(cluster)}
+	generateWALStreamingDeployment(cluster, secret, deploy)
+
+	assert.Equal(t, *deploy.Spec.Replicas, int32(1))
+	assert.Equal(t, deploy.Spec.Strategy.Type, appsv1.RecreateDeploymentStrategyType)
+	assert.DeepEqual(t, deploy.Labels, map[string]string{
+		"postgres-operator.crunchydata.com/cluster": "hippo",
+		"postgres-operator.crunchydata.com/role":    "walstream",
+	})
+
+	pod := deploy.Spec.Template.Spec
+	assert.DeepEqual(t, pod.Containers[0].Command, []string{"bash", "-ceu", "--", `
+pg_receivewal --no-password --create-slot --if-not-exists --slot=pgo_walstream
+exec pg_receivewal --no-loop --no-password --directory=/pgwalstream --slot=pgo_walstream`[1:]})
+	assert.Assert(t, cmp.MarshalMatches(pod.Containers[0].Env, `
+- name: PGHOST
+  value: hippo-primary.ns1.svc
+- name: PGPORT
+  value: "5432"
+- name: PGUSER
+  value: _crunchyrepl
+- name: PGAPPNAME
+  value: pgo_walstream
+- name: PGSSLMODE
+  value: verify-ca
+- name: PGSSLCERT
+  value: /pgconf/tls/tls.crt
+- name: PGSSLKEY
+  value: /pgconf/tls/tls.key
+- name: PGSSLROOTCERT
+  value: /pgconf/tls/ca.crt
+	`))
+	assert.Equal(t, pod.Volumes[0].Secret.SecretName, "hippo-replication-cert")
+	assert.Equal(t, *pod.Volumes[0].Secret.DefaultMode, int32(0o600))
+	assert.Equal(t, pod.Volumes[1].PersistentVolumeClaim.ClaimName, "hippo-walstream")
+
+	t.Run("Synchronous", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Backups.WALStreaming.Synchronous = initialize.Bool(true)
+
+		deploy := &appsv1.Deployment{ObjectMeta: naming.ClusterWALStreaming(cluster)}
+		generateWALStreamingDeployment(cluster, secret, deploy)
+
+		command := deploy.Spec.Template.Spec.Containers[0].Command
+		assert.Assert(t, cmp.Contains(command[3], "--slot=pgo_walstream --synchronous"))
+	})
+
+	t.Run("Shutdown", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Shutdown = initialize.Bool(true)
+
+		deploy := &appsv1.Deployment{ObjectMeta: naming.ClusterWALStreaming(cluster)}
+		generateWALStreamingDeployment(cluster, secret, deploy)
+		assert.Equal(t, *deploy.Spec.Replicas, int32(0))
+	})
+
+	t.Run("Standby", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Standby = &v1beta1.PostgresStandbySpec{Enabled: true}
+
+		deploy := &appsv1.Deployment{ObjectMeta: naming.ClusterWALStreaming(cluster)}
+		generateWALStreamingDeployment(cluster, secret, deploy)
+		assert.Equal(t, *deploy.Spec.Replicas, int32(0))
+	})
+}
+
+func TestSetWALStreamingStatus(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Name = "hippo"
+	cluster.Namespace = "ns1"
+
+	deploy := &appsv1.Deployment{}
+	deploy.Status.ReadyReplicas = 1
+
+	pod := corev1.Pod{}
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{
+		{Name: naming.ContainerWALStreaming, RestartCount: 3},
+		{Name: "other", RestartCount: 5},
+	}
+
+	setWALStreamingStatus(cluster, deploy, []corev1.Pod{pod})
+	assert.DeepEqual(t, cluster.Status.WALStreaming,
+		&v1beta1.WALStreamingStatus{Ready: true, Restarts: 3})
+	assert.Equal(t, testutil.ToFloat64(walStreamingReady.WithLabelValues("ns1", "hippo")), 1.0)
+	assert.Equal(t, testutil.ToFloat64(walStreamingRestarts.WithLabelValues("ns1", "hippo")), 3.0)
+
+	deploy.Status.ReadyReplicas = 0
+	setWALStreamingStatus(cluster, deploy, nil)
+	assert.DeepEqual(t, cluster.Status.WALStreaming, &v1beta1.WALStreamingStatus{})
+	assert.Equal(t, testutil.ToFloat64(walStreamingReady.WithLabelValues("ns1", "hippo")), 0.0)
+}
@@ -16,16 +16,41 @@
 package postgrescluster
 
 import (
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/internal/patroni"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
+// reconcilePriority returns the priority of an event that creates or updates
+// a PostgresCluster or an object it owns. Changes to the specification or
+// annotations of a cluster are made by users. Patroni changes Endpoints during
+// failover. Jobs are backups and restores that can wait.
+func reconcilePriority(old, object client.Object) runtime.Priority {
+	switch object.(type) {
+	case *v1beta1.PostgresCluster:
+		if old == nil ||
+			old.GetGeneration() != object.GetGeneration() ||
+			!equality.Semantic.DeepEqual(old.GetAnnotations(), object.GetAnnotations()) {
+			return runtime.PriorityUrgent
+		}
+	case *corev1.Endpoints:
+		return runtime.PriorityUrgent
+	case *batchv1.Job:
+		return runtime.PriorityLow
+	}
+	return runtime.PriorityNormal
+}
+
 // watchPods returns a handler.EventHandler for Pods.
 func (*Reconciler) watchPods() handler.Funcs {
 	return handler.Funcs{
@@ -72,3 +97,22 @@ func (*Reconciler) watchPods() handler.Funcs {
 		},
 	}
 }
+
+// watchScheduledBackupJobs returns a handler.EventHandler for Jobs created by
+// scheduled backup CronJobs. Those Jobs are not controlled by the cluster, but
+// they may be waiting for it to resume them.
+func (*Reconciler) watchScheduledBackupJobs() handler.Funcs {
+	return handler.Funcs{
+		CreateFunc: func(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			labels := e.Object.GetLabels()
+			cluster := labels[naming.LabelCluster]
+
+			if _, scheduled := labels[naming.LabelPGBackRestCronJob]; scheduled && len(cluster) != 0 {
+				q.Add(reconcile.Request{NamespacedName: client.ObjectKey{
+					Namespace: e.Object.GetNamespace(),
+					Name:      cluster,
+				}})
+			}
+		},
+	}
+}
@@ -19,14 +19,39 @@ import (
 	"testing"
 
 	"gotest.tools/v3/assert"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
+func TestReconcilePriority(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Generation = 1
+
+	assert.Equal(t, reconcilePriority(nil, cluster), runtime.PriorityUrgent)
+	assert.Equal(t, reconcilePriority(cluster, cluster.DeepCopy()), runtime.PriorityNormal,
+		"expected status changes to be normal")
+
+	changed := cluster.DeepCopy()
+	changed.Generation = 2
+	assert.Equal(t, reconcilePriority(cluster, changed), runtime.PriorityUrgent)
+
+	changed = cluster.DeepCopy()
+	changed.Annotations = map[string]string{"some": "thing"}
+	assert.Equal(t, reconcilePriority(cluster, changed), runtime.PriorityUrgent)
+
+	assert.Equal(t, reconcilePriority(nil, &corev1.Endpoints{}), runtime.PriorityUrgent)
+	assert.Equal(t, reconcilePriority(nil, &batchv1.Job{}), runtime.PriorityLow)
+	assert.Equal(t, reconcilePriority(nil, &corev1.ConfigMap{}), runtime.PriorityNormal)
+}
+
 func TestWatchPodsUpdate(t *testing.T) {
 	queue := controllertest.Queue{Interface: workqueue.New()}
 	reconciler := &Reconciler{}
@@ -141,3 +166,44 @@ func TestWatchPodsUpdate(t *testing.T) {
 		queue.Done(item)
 	})
 }
+
+func TestWatchScheduledBackupJobsCreate(t *testing.T) {
+	queue := controllertest.Queue{Interface: workqueue.New()}
+	reconciler := &Reconciler{}
+
+	create := reconciler.watchScheduledBackupJobs().CreateFunc
+	assert.Assert(t, create != nil)
+
+	// No metadata; no reconcile.
+	create(event.CreateEvent{Object: &batchv1.Job{}}, queue)
+	assert.Equal(t, queue.Len(), 0)
+
+	// Cluster label, but not scheduled; no reconcile.
+	create(event.CreateEvent{Object: &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{
+				"postgres-operator.crunchydata.com/cluster": "starfish",
+			},
+		},
+	}}, queue)
+	assert.Equal(t, queue.Len(), 0)
+
+	// Scheduled backup; reconcile its cluster.
+	create(event.CreateEvent{Object: &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "some-ns",
+			Labels: map[string]string{
+				"postgres-operator.crunchydata.com/cluster":            "starfish",
+				"postgres-operator.crunchydata.com/pgbackrest-cronjob": "full",
+			},
+		},
+	}}, queue)
+	assert.Equal(t, queue.Len(), 1)
+
+	item, _ := queue.Get()
+	expected := reconcile.Request{}
+	expected.Namespace = "some-ns"
+	expected.Name = "starfish"
+	assert.Equal(t, item, expected)
+	queue.Done(item)
+}
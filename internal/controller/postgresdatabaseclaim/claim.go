@@ -0,0 +1,109 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresdatabaseclaim
+
+import (
+	"net"
+	"net/url"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// targetCluster returns the PostgresCluster on which claim is fulfilled. The
+// second value is true when that cluster is shared with other claims.
+func targetCluster(claim *v1beta1.PostgresDatabaseClaim) (v1beta1.PostgresClusterReference, bool) {
+	if shared := claim.Spec.SharedCluster; shared != nil {
+		return *shared, true
+	}
+	return v1beta1.PostgresClusterReference{
+		Namespace: claim.Namespace, Name: claim.Name,
+	}, false
+}
+
+// claimsAllowed reports whether the annotations of cluster allow claims from
+// namespace to be fulfilled on it.
+func claimsAllowed(cluster *v1beta1.PostgresCluster, namespace string) bool {
+	for _, allowed := range strings.Split(cluster.Annotations[naming.AllowDatabaseClaims], ",") {
+		if allowed = strings.TrimSpace(allowed); allowed == "*" || allowed == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldManager returns the name with which claim applies its user to a shared
+// cluster. Every claim has its own so that each one owns only its own entry.
+// - https://docs.k8s.io/reference/using-api/server-side-apply/#merge-strategy
+func fieldManager(claim *v1beta1.PostgresDatabaseClaim) client.FieldOwner {
+	return client.FieldOwner("postgresdatabaseclaim-" + string(claim.UID))
+}
+
+// generateClusterUsers returns the fields of a PostgresCluster at ref that
+// create the owner and database of claim.
+func generateClusterUsers(
+	claim *v1beta1.PostgresDatabaseClaim, ref v1beta1.PostgresClusterReference,
+) *v1beta1.PostgresCluster {
+	cluster := v1beta1.NewPostgresCluster()
+	cluster.Namespace, cluster.Name = ref.Namespace, ref.Name
+	cluster.Spec.Users = []v1beta1.PostgresUserSpec{{
+		Name:      claim.Spec.Owner,
+		Databases: []v1beta1.PostgresIdentifier{claim.Spec.DatabaseName},
+	}}
+	return cluster
+}
+
+// generateDedicatedCluster returns a PostgresCluster that is created from the
+// size class of claim and contains only its owner and database.
+func generateDedicatedCluster(claim *v1beta1.PostgresDatabaseClaim) *v1beta1.PostgresCluster {
+	ref, _ := targetCluster(claim)
+	cluster := generateClusterUsers(claim, ref)
+	cluster.Labels = map[string]string{naming.LabelDatabaseClaim: claim.Name}
+	cluster.Spec.TemplateName = claim.Spec.SizeClass
+	return cluster
+}
+
+// generateConnectionSecret returns a Secret for claim with the connection
+// details in user, the Secret of its owner. The database is the one claimed
+// rather than the first one of the owner.
+func generateConnectionSecret(claim *v1beta1.PostgresDatabaseClaim, user *corev1.Secret) *corev1.Secret {
+	secret := &corev1.Secret{ObjectMeta: naming.DatabaseClaimSecret(claim)}
+	secret.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Secret"))
+	secret.Labels = map[string]string{naming.LabelDatabaseClaim: claim.Name}
+	secret.Type = corev1.SecretTypeOpaque
+
+	host, port := string(user.Data["host"]), string(user.Data["port"])
+	username, password := string(user.Data["user"]), string(user.Data["password"])
+	database := string(claim.Spec.DatabaseName)
+
+	secret.Data = map[string][]byte{
+		"host":     user.Data["host"],
+		"port":     user.Data["port"],
+		"user":     user.Data["user"],
+		"password": user.Data["password"],
+		"dbname":   []byte(database),
+		"uri": []byte((&url.URL{
+			Scheme: "postgresql",
+			User:   url.UserPassword(username, password),
+			Host:   net.JoinHostPort(host, port),
+			Path:   database,
+		}).String()),
+	}
+	return secret
+}
@@ -0,0 +1,302 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresdatabaseclaim
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	// ConditionReady is the type used in a condition to indicate whether or
+	// not the connection Secret of a claim has been written.
+	ConditionReady = "Ready"
+)
+
+// DatabaseClaimReconciler reconciles a PostgresDatabaseClaim object
+type DatabaseClaimReconciler struct {
+	client.Client
+	Owner  client.FieldOwner
+	Scheme *runtime.Scheme
+}
+
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresdatabaseclaims",verbs={list,watch}
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={list,watch}
+//+kubebuilder:rbac:groups="",resources="secrets",verbs={list,watch}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *DatabaseClaimReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&v1beta1.PostgresDatabaseClaim{}).
+		Owns(&corev1.Secret{}).
+		Owns(v1beta1.NewPostgresCluster()).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, r.watchUserSecrets()).
+		Complete(r)
+}
+
+// watchUserSecrets returns a [handler.EventHandler] for the Secrets of
+// PostgreSQL users. These are controlled by a PostgresCluster that can be in
+// another namespace than the claims fulfilled on it.
+func (r *DatabaseClaimReconciler) watchUserSecrets() handler.Funcs {
+	handle := func(secret client.Object, q workqueue.RateLimitingInterface) {
+		ctx := context.Background()
+		labels := secret.GetLabels()
+		if labels[naming.LabelRole] != naming.RolePostgresUser {
+			return
+		}
+
+		var claims v1beta1.PostgresDatabaseClaimList
+		if err := r.List(ctx, &claims); err != nil {
+			ctrl.LoggerFrom(ctx).Error(err, "listing PostgresDatabaseClaims")
+			return
+		}
+		for i := range claims.Items {
+			ref := claims.Items[i].Status.Cluster
+			if ref != nil && ref.Namespace == secret.GetNamespace() &&
+				ref.Name == labels[naming.LabelCluster] {
+				q.Add(ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&claims.Items[i])})
+			}
+		}
+	}
+
+	return handler.Funcs{
+		CreateFunc: func(e event.CreateEvent, q workqueue.RateLimitingInterface) {
+			handle(e.Object, q)
+		},
+		UpdateFunc: func(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			handle(e.ObjectNew, q)
+		},
+	}
+}
+
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresdatabaseclaims",verbs={get,patch}
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresdatabaseclaims/status",verbs={patch}
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={get,create,patch}
+//+kubebuilder:rbac:groups="",resources="secrets",verbs={get,create,patch}
+
+// Reconcile fulfills the [v1beta1.PostgresDatabaseClaim] identified by req.
+// The owner and database of the claim are added to a shared PostgresCluster
+// or to a dedicated one, and the connection details of the owner are copied
+// into a Secret next to the claim.
+func (r *DatabaseClaimReconciler) Reconcile(ctx context.Context, req ctrl.Request) (result ctrl.Result, err error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	// NOTE: No DeepCopy is necessary here because controller-runtime makes a
+	// copy before returning from its cache.
+	// - https://github.com/kubernetes-sigs/controller-runtime/issues/1235
+	claim := &v1beta1.PostgresDatabaseClaim{}
+	err = r.Get(ctx, req.NamespacedName, claim)
+
+	if err == nil {
+		// Write any changes to the claim status on the way out.
+		before := claim.DeepCopy()
+		defer func() {
+			if !equality.Semantic.DeepEqual(before.Status, claim.Status) {
+				status := r.Status().Patch(ctx, claim, client.MergeFrom(before), r.Owner)
+
+				if err == nil && status != nil {
+					err = status
+				} else if status != nil {
+					log.Error(status, "Patching PostgresDatabaseClaim status")
+				}
+			}
+		}()
+	} else {
+		return ctrl.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if claim.DeletionTimestamp != nil {
+		return ctrl.Result{}, r.finalize(ctx, claim)
+	}
+
+	claim.Status.ObservedGeneration = claim.Generation
+	ref, shared := targetCluster(claim)
+
+	// Release a shared cluster that this claim no longer uses.
+	if previous := claim.Status.Cluster; previous != nil && *previous != ref {
+		if previous.Namespace != claim.Namespace || previous.Name != claim.Name {
+			err = r.release(ctx, claim, *previous)
+		}
+		if err == nil {
+			claim.Status.Cluster = nil
+		}
+	}
+
+	cluster := &v1beta1.PostgresCluster{}
+	if err == nil {
+		err = errors.WithStack(r.Get(ctx, client.ObjectKey{
+			Namespace: ref.Namespace, Name: ref.Name,
+		}, cluster))
+	}
+
+	switch {
+	case err == nil && shared && !claimsAllowed(cluster, claim.Namespace):
+		setReady(claim, metav1.ConditionFalse, "ClusterNotAllowed", fmt.Sprintf(
+			"PostgresCluster %q in namespace %q does not allow claims from namespace %q",
+			ref.Name, ref.Namespace, claim.Namespace))
+		return ctrl.Result{}, nil
+
+	case err == nil && shared:
+		err = r.addFinalizer(ctx, claim)
+		if err == nil {
+			claim.Status.Cluster = &ref
+			err = errors.WithStack(r.applyAs(ctx,
+				generateClusterUsers(claim, ref), fieldManager(claim)))
+		}
+
+	case err == nil && !metav1.IsControlledBy(cluster, claim):
+		// Never change a dedicated cluster that this claim did not create.
+		setReady(claim, metav1.ConditionFalse, "ClusterNotControlled", fmt.Sprintf(
+			"PostgresCluster %q exists and is not controlled by this claim", ref.Name))
+		return ctrl.Result{}, nil
+
+	case apierrors.IsNotFound(err) && shared:
+		setReady(claim, metav1.ConditionFalse, "ClusterNotFound", fmt.Sprintf(
+			"PostgresCluster %q in namespace %q does not exist", ref.Name, ref.Namespace))
+		return ctrl.Result{}, nil
+
+	case err == nil || apierrors.IsNotFound(err):
+		// The owner reference blocks deletion, so the OwnerReferencesPermissionEnforcement
+		// plugin requires "update" permission on the owner's "finalizers" subresource.
+		// - https://docs.k8s.io/reference/access-authn-authz/admission-controllers/
+		// +kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresdatabaseclaims/finalizers",verbs={update}
+		cluster = generateDedicatedCluster(claim)
+		err = errors.WithStack(controllerutil.SetControllerReference(claim, cluster, r.Client.Scheme()))
+		if err == nil {
+			claim.Status.Cluster = &ref
+			err = errors.WithStack(r.apply(ctx, cluster))
+		}
+	}
+
+	user := &corev1.Secret{}
+	if err == nil {
+		err = errors.WithStack(r.Get(ctx, client.ObjectKey{
+			Namespace: ref.Namespace,
+			Name:      naming.CurrentPostgresUserSecret(cluster, string(claim.Spec.Owner)).Name,
+		}, user))
+
+		if apierrors.IsNotFound(err) {
+			// The user Secrets are watched, but the cluster may also be waiting
+			// for something else, such as its template.
+			setReady(claim, metav1.ConditionFalse, "Provisioning", fmt.Sprintf(
+				"Waiting for user %q on PostgresCluster %q", claim.Spec.Owner, ref.Name))
+			return ctrl.Result{RequeueAfter: time.Minute}, nil
+		}
+	}
+
+	if err == nil {
+		secret := generateConnectionSecret(claim, user)
+		err = errors.WithStack(controllerutil.SetControllerReference(claim, secret, r.Client.Scheme()))
+		if err == nil {
+			err = errors.WithStack(r.apply(ctx, secret))
+		}
+		if err == nil {
+			claim.Status.ConnectionSecretName = secret.Name
+			setReady(claim, metav1.ConditionTrue, "Fulfilled", fmt.Sprintf(
+				"Database %q is owned by %q on PostgresCluster %q",
+				claim.Spec.DatabaseName, claim.Spec.Owner, ref.Name))
+		}
+	}
+
+	return ctrl.Result{}, err
+}
+
+// addFinalizer sets a finalizer on claim so that its user can be removed from
+// a shared cluster before it goes away.
+func (r *DatabaseClaimReconciler) addFinalizer(
+	ctx context.Context, claim *v1beta1.PostgresDatabaseClaim,
+) error {
+	if controllerutil.ContainsFinalizer(claim, naming.Finalizer) {
+		return nil
+	}
+
+	// Build a merge-patch that includes the full list of Finalizers plus
+	// ResourceVersion to detect conflicts with other potential writers.
+	before := claim.DeepCopy()
+	intent := before.DeepCopy()
+	intent.Finalizers = append(intent.Finalizers, naming.Finalizer)
+	return errors.WithStack(r.patch(ctx, intent,
+		client.MergeFromWithOptions(before, client.MergeFromWithOptimisticLock{})))
+}
+
+// finalize removes the user of claim from the shared cluster it was fulfilled
+// on and then removes the finalizer. A dedicated cluster is controlled by the
+// claim and left to the garbage collector.
+func (r *DatabaseClaimReconciler) finalize(
+	ctx context.Context, claim *v1beta1.PostgresDatabaseClaim,
+) error {
+	finalizers := sets.NewString(claim.Finalizers...)
+	if !finalizers.Has(naming.Finalizer) {
+		return nil
+	}
+
+	var err error
+	if ref := claim.Status.Cluster; ref != nil {
+		err = r.release(ctx, claim, *ref)
+	}
+	if err == nil {
+		before := claim.DeepCopy()
+		intent := before.DeepCopy()
+		intent.Finalizers = finalizers.Delete(naming.Finalizer).List()
+		err = errors.WithStack(r.patch(ctx, intent,
+			client.MergeFromWithOptions(before, client.MergeFromWithOptimisticLock{})))
+	}
+	return err
+}
+
+// release removes the user of claim from the cluster at ref by applying
+// nothing as the field manager of claim. The user and database remain in
+// PostgreSQL, as they do when removed from the spec by hand.
+func (r *DatabaseClaimReconciler) release(
+	ctx context.Context, claim *v1beta1.PostgresDatabaseClaim,
+	ref v1beta1.PostgresClusterReference,
+) error {
+	intent := v1beta1.NewPostgresCluster()
+	intent.Namespace, intent.Name = ref.Namespace, ref.Name
+
+	err := r.applyAs(ctx, intent, fieldManager(claim))
+	return errors.WithStack(client.IgnoreNotFound(err))
+}
+
+// setReady sets the Ready condition of claim.
+func setReady(claim *v1beta1.PostgresDatabaseClaim, status metav1.ConditionStatus, reason, message string) {
+	meta.SetStatusCondition(&claim.Status.Conditions, metav1.Condition{
+		ObservedGeneration: claim.Generation,
+		Type:               ConditionReady,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+	})
+}
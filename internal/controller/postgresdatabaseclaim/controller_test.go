@@ -0,0 +1,123 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgresdatabaseclaim
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestReconcileNotFulfilled(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	shared := v1beta1.NewPostgresCluster()
+	shared.Namespace, shared.Name = "db", "shared"
+
+	for _, tt := range []struct {
+		name    string
+		objects []client.Object
+		shared  bool
+		reason  string
+	}{
+		{name: "SharedNotFound", shared: true, reason: "ClusterNotFound"},
+		{name: "SharedNotAllowed", shared: true, objects: []client.Object{shared}, reason: "ClusterNotAllowed"},
+		{name: "DedicatedNotControlled", objects: []client.Object{func() client.Object {
+			existing := v1beta1.NewPostgresCluster()
+			existing.Namespace, existing.Name = "team1", "orders"
+			return existing
+		}()}, reason: "ClusterNotControlled"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			claim := testClaim()
+			if tt.shared {
+				claim.Spec.SharedCluster = &v1beta1.PostgresClusterReference{
+					Namespace: "db", Name: "shared",
+				}
+			}
+
+			reconciler := &DatabaseClaimReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme).
+					WithObjects(append(tt.objects, claim)...).Build(),
+			}
+
+			result, err := reconciler.Reconcile(ctx, ctrl.Request{
+				NamespacedName: client.ObjectKeyFromObject(claim),
+			})
+			assert.NilError(t, err)
+			assert.Equal(t, result, ctrl.Result{})
+
+			assert.NilError(t, reconciler.Get(ctx, client.ObjectKeyFromObject(claim), claim))
+			condition := meta.FindStatusCondition(claim.Status.Conditions, ConditionReady)
+			assert.Assert(t, condition != nil)
+			assert.Equal(t, condition.Status, metav1.ConditionFalse)
+			assert.Equal(t, condition.Reason, tt.reason)
+			assert.Equal(t, claim.Status.ConnectionSecretName, "")
+		})
+	}
+}
+
+func TestWatchUserSecrets(t *testing.T) {
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	fulfilled := testClaim()
+	fulfilled.Status.Cluster = &v1beta1.PostgresClusterReference{Namespace: "db", Name: "shared"}
+
+	elsewhere := testClaim()
+	elsewhere.Name = "invoices"
+	elsewhere.Status.Cluster = &v1beta1.PostgresClusterReference{Namespace: "team1", Name: "shared"}
+
+	pending := testClaim()
+	pending.Name = "payments"
+
+	reconciler := &DatabaseClaimReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(fulfilled, elsewhere, pending).Build(),
+	}
+	handler := reconciler.watchUserSecrets()
+
+	secret := &corev1.Secret{}
+	secret.Namespace, secret.Name = "db", "shared-pguser-orders-app"
+	secret.Labels = map[string]string{naming.LabelCluster: "shared"}
+
+	// Other Secrets are ignored.
+	queue := controllertest.Queue{Interface: workqueue.New()}
+	handler.Create(event.CreateEvent{Object: secret}, queue)
+	assert.Equal(t, queue.Len(), 0)
+
+	secret.Labels[naming.LabelRole] = naming.RolePostgresUser
+	handler.Update(event.UpdateEvent{ObjectOld: secret, ObjectNew: secret}, queue)
+	assert.Equal(t, queue.Len(), 1)
+
+	item, _ := queue.Get()
+	assert.Equal(t, item, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(fulfilled)})
+}
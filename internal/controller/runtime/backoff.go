@@ -0,0 +1,240 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+)
+
+// ErrorClass is how a reconcile error is retried.
+type ErrorClass string
+
+const (
+	// ErrorTransient is for errors that usually resolve on their own in
+	// moments, such as conflicting writes and throttling by the Kubernetes API.
+	ErrorTransient ErrorClass = "Transient"
+
+	// ErrorExternal is for errors from systems outside the operator, such as
+	// an unavailable Kubernetes API, a permission it has yet to be granted,
+	// PostgreSQL, or pgBackRest. These may take minutes or more to resolve.
+	ErrorExternal ErrorClass = "External"
+
+	// ErrorPermanent is for errors that do not resolve until the object being
+	// reconciled changes, such as an invalid spec.
+	ErrorPermanent ErrorClass = "Permanent"
+)
+
+// permanentError marks an error that requires a change to resolve.
+type permanentError struct{ error }
+
+func (e permanentError) Unwrap() error { return e.error }
+
+// Permanent marks err as an ErrorPermanent. It returns nil when err is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return permanentError{err}
+}
+
+// ClassifyError returns the ErrorClass of err. Errors from the Kubernetes API
+// are classified by their status; validation errors are permanent; timeouts
+// and dropped connections are transient; any other error came from outside
+// the operator. Wrapped errors are classified by what they wrap.
+func ClassifyError(err error) ErrorClass {
+	var (
+		aggregate  utilerrors.Aggregate
+		fieldError *field.Error
+		permanent  permanentError
+		status     apierrors.APIStatus
+	)
+
+	switch {
+	case errors.As(err, &permanent),
+		errors.As(err, &fieldError),
+		apierrors.IsInvalid(err),
+		apierrors.IsBadRequest(err),
+		apierrors.IsRequestEntityTooLargeError(err):
+		return ErrorPermanent
+
+	case errors.As(err, &aggregate) && len(aggregate.Errors()) > 0:
+		// An aggregate is permanent when all of its errors are.
+		for _, err := range aggregate.Errors() {
+			if ClassifyError(err) != ErrorPermanent {
+				return ClassifyError(err)
+			}
+		}
+		return ErrorPermanent
+
+	case apierrors.IsConflict(err),
+		apierrors.IsAlreadyExists(err),
+		apierrors.IsNotFound(err),
+		apierrors.IsResourceExpired(err),
+		apierrors.IsServerTimeout(err),
+		apierrors.IsTimeout(err),
+		apierrors.IsTooManyRequests(err):
+		return ErrorTransient
+
+	case errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		utilnet.IsConnectionReset(err),
+		utilnet.IsProbableEOF(err),
+		utilnet.IsTimeout(err):
+		return ErrorTransient
+
+	case errors.As(err, &status) && status.Status().Code < 500 &&
+		!apierrors.IsForbidden(err) && !apierrors.IsUnauthorized(err):
+		return ErrorTransient
+	}
+	return ErrorExternal
+}
+
+// errorBackoff is the least and most time between attempts for each class.
+// Permanent errors are not retried; the object is reconciled when it changes.
+var errorBackoff = map[ErrorClass]struct{ base, max time.Duration }{
+	ErrorTransient: {base: 100 * time.Millisecond, max: time.Minute},
+	ErrorExternal:  {base: 5 * time.Second, max: 10 * time.Minute},
+}
+
+// forgetAfter is how long the backoff of a request is kept after its last
+// error. It is longer than the most time between attempts of any class, so a
+// request that is not attempted again in that time is gone.
+const forgetAfter = 30 * time.Minute
+
+// These metrics count reconcile errors by class.
+var reconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "pgo",
+	Name:      "reconcile_errors_total",
+	Help:      "The number of reconcile errors, by class",
+}, []string{"controller", "class"})
+
+func init() {
+	metrics.Registry.MustRegister(reconcileErrors)
+}
+
+// ErrorBackoff is a reconcile.Reconciler that retries the errors of another
+// according to their ErrorClass. Each request backs off exponentially from
+// the base of its class until it succeeds. Permanent errors are not retried
+// so they do not loop forever; the object is reconciled when it changes. The
+// backoff of an object that no longer exists is forgotten.
+type ErrorBackoff struct {
+	// Name is the name of the controller in metrics and logs.
+	Name string
+
+	// Reconciler is the reconciler whose errors are retried.
+	Reconciler reconcile.Reconciler
+
+	mutex    sync.Mutex
+	failures map[reconcile.Request]failures
+	now      func() time.Time
+}
+
+// failures counts the consecutive errors of one request.
+type failures struct {
+	count int
+	last  time.Time
+}
+
+var _ reconcile.Reconciler = (*ErrorBackoff)(nil)
+
+// Reconcile calls the wrapped Reconciler and converts any error into a result
+// that retries it after the backoff of its class.
+func (b *ErrorBackoff) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	result, err := b.Reconciler.Reconcile(ctx, request)
+	if err == nil {
+		b.forget(request)
+		return result, nil
+	}
+
+	class := ClassifyError(err)
+	reconcileErrors.WithLabelValues(b.Name, string(class)).Inc()
+
+	next := reconcile.Result{}
+	if class == ErrorPermanent || isRequestNotFound(err, request) {
+		b.forget(request)
+	} else {
+		next.RequeueAfter = b.when(request, class)
+	}
+
+	logging.FromContext(ctx).Error(err, "Reconciler error",
+		"controller", b.Name, "class", class, "retryAfter", next.RequeueAfter)
+	return next, nil
+}
+
+// when returns how long to wait before retrying request after another error
+// of class.
+func (b *ErrorBackoff) when(request reconcile.Request, class ErrorClass) time.Duration {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if b.failures == nil {
+		b.failures = make(map[reconcile.Request]failures)
+	}
+	if b.now == nil {
+		b.now = time.Now
+	}
+	now := b.now()
+
+	// Forget requests that have not failed in a while. Their objects were
+	// deleted without another attempt that failed or succeeded.
+	for other, previous := range b.failures {
+		if now.Sub(previous.last) > forgetAfter {
+			delete(b.failures, other)
+		}
+	}
+
+	previous := b.failures[request]
+	b.failures[request] = failures{count: previous.count + 1, last: now}
+
+	backoff := errorBackoff[class]
+	delay := backoff.base
+	for i := 0; i < previous.count && delay < backoff.max; i++ {
+		delay *= 2
+	}
+	if delay > backoff.max {
+		delay = backoff.max
+	}
+	return delay
+}
+
+// isRequestNotFound returns whether err says that the object of request does
+// not exist.
+func isRequestNotFound(err error, request reconcile.Request) bool {
+	var status apierrors.APIStatus
+	return apierrors.IsNotFound(err) && errors.As(err, &status) &&
+		status.Status().Details != nil && status.Status().Details.Name == request.Name
+}
+
+// forget resets the backoff of request.
+func (b *ErrorBackoff) forget(request reconcile.Request) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	delete(b.failures, request)
+}
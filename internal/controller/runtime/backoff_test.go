@@ -0,0 +1,159 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	pkgerrors "github.com/pkg/errors"
+	"gotest.tools/v3/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestClassifyError(t *testing.T) {
+	resource := schema.GroupResource{Resource: "configmaps"}
+	path := field.NewPath("spec", "image")
+
+	for _, tt := range []struct {
+		err   error
+		class ErrorClass
+	}{
+		{err: apierrors.NewConflict(resource, "x", errors.New("changed")), class: ErrorTransient},
+		{err: apierrors.NewAlreadyExists(resource, "x"), class: ErrorTransient},
+		{err: apierrors.NewTooManyRequests("slow down", 1), class: ErrorTransient},
+		{err: pkgerrors.WithStack(apierrors.NewNotFound(resource, "x")), class: ErrorTransient},
+		{err: pkgerrors.WithStack(io.ErrUnexpectedEOF), class: ErrorTransient},
+		{err: pkgerrors.WithStack(context.DeadlineExceeded), class: ErrorTransient},
+		{err: pkgerrors.Wrap(&net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET}, "get"), class: ErrorTransient},
+		{err: pkgerrors.WithStack(&net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}), class: ErrorExternal},
+
+		{err: apierrors.NewServiceUnavailable("down"), class: ErrorExternal},
+		{err: apierrors.NewInternalError(errors.New("etcd")), class: ErrorExternal},
+		{err: apierrors.NewForbidden(resource, "x", errors.New("denied")), class: ErrorExternal},
+		{err: errors.New("connection refused"), class: ErrorExternal},
+
+		{err: apierrors.NewBadRequest("nope"), class: ErrorPermanent},
+		{err: field.Invalid(path, "x", "bad"), class: ErrorPermanent},
+		{err: field.ErrorList{field.Required(path, "")}.ToAggregate(), class: ErrorPermanent},
+		{err: pkgerrors.WithStack(Permanent(errors.New("no image"))), class: ErrorPermanent},
+	} {
+		assert.Equal(t, ClassifyError(tt.err), tt.class, "%v", tt.err)
+	}
+
+	assert.NilError(t, Permanent(nil))
+}
+
+func TestErrorBackoff(t *testing.T) {
+	ctx := context.Background()
+	request := reconcile.Request{}
+	request.Namespace, request.Name = "ns1", "hippo"
+
+	var failure error
+	backoff := &ErrorBackoff{
+		Name: "test",
+		Reconciler: reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+			return reconcile.Result{RequeueAfter: time.Hour}, failure
+		}),
+	}
+
+	t.Run("Transient", func(t *testing.T) {
+		failure = apierrors.NewConflict(schema.GroupResource{}, "x", errors.New("changed"))
+
+		var delays []time.Duration
+		for i := 0; i < 4; i++ {
+			result, err := backoff.Reconcile(ctx, request)
+			assert.NilError(t, err)
+			delays = append(delays, result.RequeueAfter)
+		}
+		assert.DeepEqual(t, delays, []time.Duration{
+			100 * time.Millisecond, 200 * time.Millisecond,
+			400 * time.Millisecond, 800 * time.Millisecond,
+		})
+
+		// A success starts over and keeps its own result.
+		failure = nil
+		result, err := backoff.Reconcile(ctx, request)
+		assert.NilError(t, err)
+		assert.Equal(t, result.RequeueAfter, time.Hour)
+	})
+
+	t.Run("External", func(t *testing.T) {
+		failure = errors.New("connection refused")
+
+		result, _ := backoff.Reconcile(ctx, request)
+		assert.Equal(t, result.RequeueAfter, 5*time.Second)
+
+		for i := 0; i < 20; i++ {
+			result, _ = backoff.Reconcile(ctx, request)
+		}
+		assert.Equal(t, result.RequeueAfter, 10*time.Minute)
+	})
+
+	t.Run("Permanent", func(t *testing.T) {
+		failure = field.Invalid(field.NewPath("spec"), "x", "bad")
+
+		result, err := backoff.Reconcile(ctx, request)
+		assert.NilError(t, err)
+		assert.Equal(t, result, reconcile.Result{}, "expected no retry")
+		assert.Equal(t, len(backoff.failures), 0)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		resource := schema.GroupResource{Resource: "postgresclusters"}
+
+		// Another object that does not exist yet is retried.
+		failure = pkgerrors.WithStack(apierrors.NewNotFound(resource, "hippo-ha"))
+		result, _ := backoff.Reconcile(ctx, request)
+		assert.Assert(t, result.RequeueAfter > 0)
+		assert.Equal(t, len(backoff.failures), 1)
+
+		// The object of the request is gone, so it is forgotten.
+		failure = pkgerrors.WithStack(apierrors.NewNotFound(resource, "hippo"))
+		result, err := backoff.Reconcile(ctx, request)
+		assert.NilError(t, err)
+		assert.Equal(t, result, reconcile.Result{}, "expected no retry")
+		assert.Equal(t, len(backoff.failures), 0)
+	})
+
+	t.Run("Forgotten", func(t *testing.T) {
+		now := time.Now()
+		backoff.now = func() time.Time { return now }
+		failure = apierrors.NewConflict(schema.GroupResource{}, "x", errors.New("changed"))
+
+		other := reconcile.Request{}
+		other.Namespace, other.Name = "ns1", "other"
+		_, _ = backoff.Reconcile(ctx, other)
+		_, _ = backoff.Reconcile(ctx, other)
+		assert.Equal(t, len(backoff.failures), 1)
+
+		// Requests that have not failed in a while are forgotten.
+		now = now.Add(time.Hour)
+		_, _ = backoff.Reconcile(ctx, request)
+		assert.Equal(t, len(backoff.failures), 1)
+
+		result, _ := backoff.Reconcile(ctx, other)
+		assert.Equal(t, result.RequeueAfter, 100*time.Millisecond)
+	})
+}
@@ -0,0 +1,149 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
+)
+
+// newCache returns a constructor for a cache of objects in namespaces, a
+// comma-separated list. An empty list caches every namespace. When selector
+// is not empty, only Secrets and ConfigMaps that match it are cached. These
+// are the most numerous objects the operator watches that it does not own.
+func newCache(namespaces string, selector labels.Selector) cache.NewCacheFunc {
+	return func(config *rest.Config, options cache.Options) (cache.Cache, error) {
+		if !selector.Empty() {
+			options.SelectorsByObject = cache.SelectorsByObject{
+				&corev1.ConfigMap{}: {Label: selector},
+				&corev1.Secret{}:    {Label: selector},
+			}
+		}
+
+		if list := strings.Split(namespaces, ","); len(list) > 1 {
+			for i := range list {
+				list[i] = strings.TrimSpace(list[i])
+			}
+			return cache.MultiNamespacedCacheBuilder(list)(config, options)
+		}
+
+		options.Namespace = strings.TrimSpace(namespaces)
+		return cache.New(config, options)
+	}
+}
+
+// newClient returns a constructor for a client that reads Secrets and
+// ConfigMaps directly from the API when the cache may not have them.
+func newClient(selector labels.Selector) cluster.NewClientFunc {
+	return func(
+		cache cache.Cache, config *rest.Config, options client.Options,
+		uncachedObjects ...client.Object,
+	) (client.Client, error) {
+		cached, err := cluster.DefaultNewClient(cache, config, options, uncachedObjects...)
+		if err != nil || selector.Empty() {
+			return cached, err
+		}
+
+		uncached, err := client.New(config, options)
+		if err != nil {
+			return nil, err
+		}
+
+		return &selectiveClient{Client: cached, uncached: uncached, selector: selector}, nil
+	}
+}
+
+// selectiveClient reads from a cache that holds only the Secrets and
+// ConfigMaps that match selector. Other Secrets and ConfigMaps, such as those
+// provided by users, are read from the API.
+type selectiveClient struct {
+	client.Client
+	uncached client.Reader
+	selector labels.Selector
+}
+
+// selective returns whether the cache holds only some objects of the same
+// type as obj.
+func selective(obj client.Object) bool {
+	switch obj.(type) {
+	case *corev1.ConfigMap, *corev1.Secret:
+		return true
+	}
+	return false
+}
+
+// Get reads obj from the cache and then from the API when it is not there.
+func (c *selectiveClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	err := c.Client.Get(ctx, key, obj)
+	if apierrors.IsNotFound(err) && selective(obj) {
+		err = c.uncached.Get(ctx, key, obj)
+	}
+	return err
+}
+
+// List reads from the cache when the label selector of opts selects only
+// objects that are in it, and from the API otherwise.
+func (c *selectiveClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	switch list.(type) {
+	case *corev1.ConfigMapList, *corev1.SecretList:
+		options := client.ListOptions{}
+		options.ApplyOptions(opts)
+
+		if !implies(options.LabelSelector, c.selector) {
+			return c.uncached.List(ctx, list, opts...)
+		}
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+// implies returns whether every object matched by selector is also matched by
+// other. It is conservative and looks only for requirements of other in selector.
+func implies(selector, other labels.Selector) bool {
+	if selector == nil {
+		return other.Empty()
+	}
+
+	requirements, _ := selector.Requirements()
+	wanted, _ := other.Requirements()
+
+	for _, want := range wanted {
+		var found bool
+		for _, have := range requirements {
+			switch {
+			case have.Key() != want.Key():
+			case have.Operator() == want.Operator() && have.Values().Equal(want.Values()):
+				found = true
+			case want.Operator() == selection.Exists &&
+				(have.Operator() == selection.Equals || have.Operator() == selection.DoubleEquals ||
+					have.Operator() == selection.In):
+				found = true
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
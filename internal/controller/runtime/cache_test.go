@@ -0,0 +1,101 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestImplies(t *testing.T) {
+	parse := func(s string) labels.Selector {
+		selector, err := labels.Parse(s)
+		assert.NilError(t, err)
+		return selector
+	}
+
+	for _, tt := range []struct {
+		selector, other string
+		expected        bool
+	}{
+		{"", "", true},
+		{"", "app", false},
+		{"app", "app", true},
+		{"app=x", "app", true},
+		{"app in (x,y)", "app", true},
+		{"app!=x", "app", false},
+		{"app=x,other=y", "app=x", true},
+		{"app=y", "app=x", false},
+		{"other=x", "app", false},
+	} {
+		assert.Equal(t, implies(parse(tt.selector), parse(tt.other)), tt.expected,
+			"%q implies %q", tt.selector, tt.other)
+	}
+
+	assert.Assert(t, implies(nil, labels.Everything()))
+	assert.Assert(t, !implies(nil, parse("app")))
+}
+
+func TestSelectiveClient(t *testing.T) {
+	ctx := context.Background()
+
+	owned := &corev1.Secret{}
+	owned.Namespace, owned.Name = "ns1", "owned"
+	owned.Labels = map[string]string{"app": "pgo"}
+
+	other := &corev1.Secret{}
+	other.Namespace, other.Name = "ns1", "other"
+	other.Labels = map[string]string{"mine": "yes"}
+
+	selector, err := labels.Parse("app")
+	assert.NilError(t, err)
+
+	c := &selectiveClient{
+		Client:   fake.NewClientBuilder().WithObjects(owned).Build(),
+		uncached: fake.NewClientBuilder().WithObjects(owned, other).Build(),
+		selector: selector,
+	}
+
+	t.Run("Get", func(t *testing.T) {
+		var secret corev1.Secret
+		assert.NilError(t, c.Get(ctx, client.ObjectKeyFromObject(owned), &secret))
+		assert.NilError(t, c.Get(ctx, client.ObjectKeyFromObject(other), &secret))
+		assert.DeepEqual(t, secret.Labels, other.Labels)
+
+		err := c.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: "missing"}, &secret)
+		assert.Assert(t, err != nil)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		var secrets corev1.SecretList
+		assert.NilError(t, c.List(ctx, &secrets, client.InNamespace("ns1")))
+		assert.Equal(t, len(secrets.Items), 2, "expected uncached list")
+
+		assert.NilError(t, c.List(ctx, &secrets, client.HasLabels{"mine"}))
+		assert.Equal(t, len(secrets.Items), 1)
+		assert.Equal(t, secrets.Items[0].Name, "other")
+
+		assert.NilError(t, c.List(ctx, &secrets, client.MatchingLabels{"app": "pgo"}))
+		assert.Equal(t, len(secrets.Items), 1)
+		assert.Equal(t, secrets.Items[0].Name, "owned")
+	})
+}
@@ -0,0 +1,251 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Priority orders the events that queue reconcile requests.
+type Priority int
+
+const (
+	// PriorityLow is for periodic resyncs, the initial list of objects after
+	// the operator starts, and long running work such as backups.
+	PriorityLow Priority = iota
+
+	// PriorityNormal is for changes to objects that the operator owns.
+	PriorityNormal
+
+	// PriorityUrgent is for changes made by users, deletions, and failovers.
+	// These are never held.
+	PriorityUrgent
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityUrgent:
+		return "urgent"
+	}
+	return strconv.Itoa(int(p))
+}
+
+// These metrics count the events of each priority and the requests being held.
+var (
+	queueEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "pgo",
+		Name:      "queue_events_total",
+		Help:      "The number of events that queued reconcile requests, by priority",
+	}, []string{"controller", "priority"})
+	queueHeld = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "pgo",
+		Name:      "queue_held_requests",
+		Help:      "The number of reconcile requests waiting for a busy queue, by priority",
+	}, []string{"controller", "priority"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(queueEvents, queueHeld)
+}
+
+// PriorityQueue holds low and normal priority reconcile requests while the
+// queue of a controller is busy, so that urgent requests are not delayed by
+// a mass resync. Its Predicate goes on the watches to throttle, and it is
+// itself a Source that releases held requests to the controller.
+type PriorityQueue struct {
+	// Name is the name of the controller in metrics.
+	Name string
+
+	// Limit is the length of the controller queue at or above which low and
+	// normal priority requests are held. It is usually the number of workers.
+	Limit int
+
+	// Interval is how often held requests are considered for release.
+	Interval time.Duration
+
+	// Classify returns the priority of an event that creates or updates an
+	// object. When old is nil, the object was created. When Classify is nil,
+	// every such event is PriorityNormal.
+	Classify func(old, new client.Object) Priority
+
+	mutex   sync.Mutex
+	queue   workqueue.RateLimitingInterface
+	started time.Time
+	held    [PriorityUrgent][]reconcile.Request
+	holding map[reconcile.Request]Priority
+}
+
+// NewPriorityQueue returns a PriorityQueue for the controller named name.
+func NewPriorityQueue(name string, limit int, classify func(old, new client.Object) Priority) *PriorityQueue {
+	return &PriorityQueue{
+		Name: name, Limit: limit, Interval: time.Second, Classify: classify,
+		started: time.Now(),
+	}
+}
+
+// priority returns the priority of an event about object. Deletions are urgent,
+// and events that do not change the object are low.
+func (pq *PriorityQueue) priority(old, object client.Object, deleted bool) Priority {
+	switch {
+	case deleted:
+		return PriorityUrgent
+	case old != nil && old.GetResourceVersion() == object.GetResourceVersion():
+		return PriorityLow
+	case old == nil && object.GetCreationTimestamp().Time.Before(pq.started):
+		return PriorityLow
+	case pq.Classify != nil:
+		return pq.Classify(old, object)
+	}
+	return PriorityNormal
+}
+
+// request returns the reconcile request for object. This is its controller
+// when it has one and the object itself otherwise.
+func request(object client.Object) reconcile.Request {
+	key := client.ObjectKeyFromObject(object)
+	if owner := metav1.GetControllerOf(object); owner != nil {
+		key.Name = owner.Name
+	}
+	return reconcile.Request{NamespacedName: key}
+}
+
+// admit returns whether an event of priority p about object should pass to
+// the controller now. When it should not, its request is held.
+func (pq *PriorityQueue) admit(object client.Object, p Priority) bool {
+	if object == nil {
+		return true
+	}
+	queueEvents.WithLabelValues(pq.Name, p.String()).Inc()
+
+	pq.mutex.Lock()
+	defer pq.mutex.Unlock()
+
+	r := request(object)
+	held, holding := pq.holding[r]
+
+	if p == PriorityUrgent || (pq.queue != nil && pq.queue.Len() < pq.Limit) {
+		// The request is about to be queued; stop holding it.
+		if holding {
+			pq.remove(r, held)
+		}
+		return true
+	}
+
+	if holding && held >= p {
+		return false
+	}
+	if holding {
+		pq.remove(r, held)
+	}
+	if pq.holding == nil {
+		pq.holding = make(map[reconcile.Request]Priority)
+	}
+	pq.holding[r] = p
+	pq.held[p] = append(pq.held[p], r)
+	queueHeld.WithLabelValues(pq.Name, p.String()).Set(float64(len(pq.held[p])))
+	return false
+}
+
+// remove stops holding r at priority p. The caller must hold the mutex.
+func (pq *PriorityQueue) remove(r reconcile.Request, p Priority) {
+	delete(pq.holding, r)
+	for i := range pq.held[p] {
+		if pq.held[p][i] == r {
+			pq.held[p] = append(pq.held[p][:i], pq.held[p][i+1:]...)
+			break
+		}
+	}
+	queueHeld.WithLabelValues(pq.Name, p.String()).Set(float64(len(pq.held[p])))
+}
+
+// release moves held requests into the controller queue, highest priority
+// first, until it reaches Limit.
+func (pq *PriorityQueue) release() {
+	pq.mutex.Lock()
+	defer pq.mutex.Unlock()
+
+	for p := PriorityUrgent - 1; p >= PriorityLow; p-- {
+		for len(pq.held[p]) > 0 && pq.queue.Len() < pq.Limit {
+			r := pq.held[p][0]
+			pq.remove(r, p)
+			pq.queue.Add(r)
+		}
+	}
+}
+
+// Predicate returns a predicate that holds events according to their priority.
+func (pq *PriorityQueue) Predicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return pq.admit(e.Object, pq.priority(nil, e.Object, false))
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return pq.admit(e.ObjectNew, pq.priority(e.ObjectOld, e.ObjectNew, false))
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return pq.admit(e.Object, pq.priority(nil, e.Object, true))
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return pq.admit(e.Object, PriorityLow)
+		},
+	}
+}
+
+func (pq *PriorityQueue) String() string { return "priority queue " + pq.Name }
+
+// Start is called by controller-runtime Controller and returns quickly.
+// It releases held requests to q until ctx is cancelled.
+func (pq *PriorityQueue) Start(
+	ctx context.Context, _ handler.EventHandler,
+	q workqueue.RateLimitingInterface, _ ...predicate.Predicate,
+) error {
+	pq.mutex.Lock()
+	pq.queue = q
+	pq.mutex.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(pq.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				pq.release()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return nil
+}
@@ -0,0 +1,103 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package runtime
+
+import (
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestPriorityQueue(t *testing.T) {
+	q := workqueue.NewRateLimitingQueue(workqueue.DefaultItemBasedRateLimiter())
+	t.Cleanup(q.ShutDown)
+
+	pq := NewPriorityQueue("test", 1, func(_, object client.Object) Priority {
+		if object.GetName() == "urgent" {
+			return PriorityUrgent
+		}
+		return PriorityNormal
+	})
+	pq.queue = q
+	p := pq.Predicate()
+
+	object := func(name string) *corev1.ConfigMap {
+		cm := &corev1.ConfigMap{}
+		cm.Namespace, cm.Name = "ns1", name
+		cm.CreationTimestamp = metav1.NewTime(time.Now().Add(time.Hour))
+		cm.ResourceVersion = "1"
+		return cm
+	}
+	key := func(name string) reconcile.Request {
+		return reconcile.Request{NamespacedName: client.ObjectKey{Namespace: "ns1", Name: name}}
+	}
+
+	// The queue is empty, so the first event passes.
+	assert.Assert(t, p.Create(event.CreateEvent{Object: object("first")}))
+	q.Add(key("first"))
+
+	// The queue is full; resyncs and normal changes are held.
+	old, changed := object("a"), object("b")
+	changed.ResourceVersion = "2"
+	assert.Assert(t, !p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: object("a")}))
+	assert.Assert(t, !p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: changed}))
+	assert.DeepEqual(t, pq.held[PriorityLow], []reconcile.Request{key("a")})
+	assert.DeepEqual(t, pq.held[PriorityNormal], []reconcile.Request{key("b")})
+
+	// Objects that existed before the operator started are low priority.
+	existing := object("c")
+	existing.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	assert.Assert(t, !p.Create(event.CreateEvent{Object: existing}))
+	assert.Equal(t, len(pq.held[PriorityLow]), 2)
+
+	// Owned objects are held as their controller.
+	owned := object("owned")
+	owned.OwnerReferences = []metav1.OwnerReference{{Name: "a", Controller: new(bool)}}
+	*owned.OwnerReferences[0].Controller = true
+	assert.Assert(t, !p.Create(event.CreateEvent{Object: owned}))
+	assert.Equal(t, pq.holding[key("a")], PriorityNormal, "expected to be raised")
+	assert.DeepEqual(t, pq.held[PriorityLow], []reconcile.Request{key("c")})
+
+	// Urgent events and deletions are never held.
+	assert.Assert(t, p.Create(event.CreateEvent{Object: object("urgent")}))
+	assert.Assert(t, p.Delete(event.DeleteEvent{Object: object("b")}))
+	_, holding := pq.holding[key("b")]
+	assert.Assert(t, !holding, "expected deletion to stop holding")
+
+	// Held requests are released in priority order as the queue drains.
+	item, _ := q.Get()
+	q.Done(item)
+	q.Forget(item)
+	pq.release()
+	assert.Equal(t, q.Len(), 1)
+
+	item, _ = q.Get()
+	assert.Equal(t, item, key("a"))
+	q.Done(item)
+	pq.release()
+
+	item, _ = q.Get()
+	assert.Equal(t, item, key("c"))
+	q.Done(item)
+	assert.Equal(t, len(pq.holding), 0)
+}
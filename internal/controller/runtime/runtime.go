@@ -18,12 +18,16 @@ limitations under the License.
 import (
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 
+	v1 "github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
@@ -34,8 +38,10 @@ var refreshInterval = 60 * time.Minute
 // manager returned is configured specifically for the PostgreSQL Operator, and includes any
 // controllers that will be responsible for managing PostgreSQL clusters using the
 // 'postgrescluster' custom resource.  Additionally, the manager will only watch for resources in
-// the namespace specified, with an empty string resulting in the manager watching all namespaces.
-func CreateRuntimeManager(namespace string, config *rest.Config,
+// the namespaces specified, a comma-separated list, with an empty string resulting in the manager
+// watching all namespaces.  When cacheSelector is not empty, only Secrets and ConfigMaps that
+// match that label selector are kept in memory; others are read from the API when needed.
+func CreateRuntimeManager(namespace, cacheSelector string, config *rest.Config,
 	disableMetrics bool) (manager.Manager, error) {
 
 	pgoScheme, err := CreatePostgresOperatorScheme()
@@ -43,10 +49,20 @@ func CreateRuntimeManager(namespace string, config *rest.Config,
 		return nil, err
 	}
 
+	selector, err := labels.Parse(cacheSelector)
+	if err != nil {
+		return nil, err
+	}
+
 	options := manager.Options{
-		Namespace:  namespace, // if empty then watching all namespaces
+		NewCache:   newCache(namespace, selector), // if empty then watching all namespaces
+		NewClient:  newClient(selector),
 		SyncPeriod: &refreshInterval,
 		Scheme:     pgoScheme,
+
+		// Namespaces are read only to detect OpenShift. Read them from the API
+		// so that the operator does not need to watch every namespace.
+		ClientDisableCacheFor: []client.Object{&corev1.Namespace{}, &corev1.Node{}},
 	}
 	if disableMetrics {
 		options.HealthProbeBindAddress = "0"
@@ -82,6 +98,9 @@ func CreatePostgresOperatorScheme() (*runtime.Scheme, error) {
 	if err := v1beta1.AddToScheme(pgoScheme); err != nil {
 		return nil, err
 	}
+	if err := v1.AddToScheme(pgoScheme); err != nil {
+		return nil, err
+	}
 
 	return pgoScheme, nil
 }
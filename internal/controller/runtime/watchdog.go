@@ -0,0 +1,165 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+)
+
+// These metrics count reconciles that run too long and requests that keep
+// failing, so that a controller that is stuck is visible.
+var (
+	watchdogStuck = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "pgo",
+		Name:      "watchdog_stuck_reconciles",
+		Help:      "The number of reconciles running longer than the watchdog timeout",
+	}, []string{"controller"})
+	watchdogFailing = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "pgo",
+		Name:      "watchdog_failing_requests",
+		Help:      "The number of reconcile requests that failed at least the watchdog limit in a row",
+	}, []string{"controller"})
+	watchdogDetections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "pgo",
+		Name:      "watchdog_detections_total",
+		Help:      "The number of stuck reconciles and failing requests detected, by reason",
+	}, []string{"controller", "reason"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(watchdogStuck, watchdogFailing, watchdogDetections)
+}
+
+// Watchdog is a reconcile.Reconciler that watches another. It detects
+// reconciles that run longer than Timeout and requests that fail ErrorLimit
+// times in a row. Each detection is logged, counted in metrics, and recorded
+// as a warning event on the object of the request.
+type Watchdog struct {
+	// Name is the name of the controller in metrics and logs.
+	Name string
+
+	// Reconciler is the reconciler being watched.
+	Reconciler reconcile.Reconciler
+
+	// Recorder records events about detections. When Recorder or NewObject
+	// is nil, no events are recorded.
+	Recorder record.EventRecorder
+
+	// NewObject returns an empty object of the kind being reconciled.
+	NewObject func() client.Object
+
+	// Timeout is how long a reconcile may run before it is stuck. When Timeout
+	// is zero, reconciles are not timed.
+	Timeout time.Duration
+
+	// Cancel, when true, cancels the context of a stuck reconcile so that its
+	// worker can return to the queue and the request is tried again.
+	Cancel bool
+
+	// ErrorLimit is how many times in a row a request may fail before it is
+	// reported. When ErrorLimit is zero, errors are not counted.
+	ErrorLimit int
+
+	mutex  sync.Mutex
+	errors map[reconcile.Request]int
+}
+
+var _ reconcile.Reconciler = (*Watchdog)(nil)
+
+// Reconcile calls the watched Reconciler and reports when it runs too long
+// or fails too often.
+func (w *Watchdog) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
+	if w.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithCancel(ctx)
+		defer cancel()
+
+		fired := make(chan struct{})
+		timer := time.AfterFunc(w.Timeout, func() {
+			defer close(fired)
+			watchdogStuck.WithLabelValues(w.Name).Inc()
+			w.report(ctx, request, "ReconcileStuck",
+				fmt.Sprintf("Reconcile has been running longer than %v", w.Timeout))
+			if w.Cancel {
+				cancel()
+			}
+		})
+		defer func() {
+			if !timer.Stop() {
+				<-fired
+				watchdogStuck.WithLabelValues(w.Name).Dec()
+			}
+		}()
+	}
+
+	result, err := w.Reconciler.Reconcile(ctx, request)
+
+	if w.ErrorLimit > 0 {
+		w.count(ctx, request, err)
+	}
+	return result, err
+}
+
+// count tracks the errors of request in a row and reports when they reach
+// ErrorLimit. A request that succeeds starts over.
+func (w *Watchdog) count(ctx context.Context, request reconcile.Request, err error) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.errors == nil {
+		w.errors = make(map[reconcile.Request]int)
+	}
+	if err == nil {
+		if w.errors[request] >= w.ErrorLimit {
+			watchdogFailing.WithLabelValues(w.Name).Dec()
+		}
+		delete(w.errors, request)
+		return
+	}
+
+	w.errors[request]++
+	if w.errors[request] == w.ErrorLimit {
+		watchdogFailing.WithLabelValues(w.Name).Inc()
+		w.report(ctx, request, "ReconcileFailing",
+			fmt.Sprintf("Reconcile has failed %d times in a row: %v", w.ErrorLimit, err))
+	}
+}
+
+// report logs and counts a detection and records it as an event.
+func (w *Watchdog) report(ctx context.Context, request reconcile.Request, reason, message string) {
+	watchdogDetections.WithLabelValues(w.Name, reason).Inc()
+	logging.FromContext(ctx).Info(message,
+		"controller", w.Name, "reason", reason, "request", request.NamespacedName)
+
+	if w.Recorder != nil && w.NewObject != nil {
+		object := w.NewObject()
+		object.SetNamespace(request.Namespace)
+		object.SetName(request.Name)
+		w.Recorder.Event(object, corev1.EventTypeWarning, reason, message)
+	}
+}
@@ -0,0 +1,116 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package runtime
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func TestWatchdog(t *testing.T) {
+	ctx := context.Background()
+	request := reconcile.Request{}
+	request.Namespace, request.Name = "ns1", "hippo"
+
+	t.Run("Stuck", func(t *testing.T) {
+		recorder := record.NewFakeRecorder(10)
+		watchdog := &Watchdog{
+			Name: "test", Recorder: recorder, Timeout: 10 * time.Millisecond,
+			NewObject: func() client.Object { return &corev1.ConfigMap{} },
+			Reconciler: reconcile.Func(func(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+				select {
+				case <-ctx.Done():
+					return reconcile.Result{}, ctx.Err()
+				case <-time.After(100 * time.Millisecond):
+					return reconcile.Result{}, nil
+				}
+			}),
+		}
+
+		_, err := watchdog.Reconcile(ctx, request)
+		assert.NilError(t, err, "expected to finish without Cancel")
+
+		event := <-recorder.Events
+		assert.Assert(t, strings.Contains(event, "ReconcileStuck"), "got %q", event)
+
+		watchdog.Cancel = true
+		_, err = watchdog.Reconcile(ctx, request)
+		assert.ErrorIs(t, err, context.Canceled)
+		<-recorder.Events
+	})
+
+	t.Run("Fast", func(t *testing.T) {
+		recorder := record.NewFakeRecorder(10)
+		watchdog := &Watchdog{
+			Name: "test", Recorder: recorder, Timeout: time.Minute,
+			NewObject: func() client.Object { return &corev1.ConfigMap{} },
+			Reconciler: reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+				return reconcile.Result{}, nil
+			}),
+		}
+
+		_, err := watchdog.Reconcile(ctx, request)
+		assert.NilError(t, err)
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+
+	t.Run("Failing", func(t *testing.T) {
+		var failure error
+		recorder := record.NewFakeRecorder(10)
+		watchdog := &Watchdog{
+			Name: "test", Recorder: recorder, ErrorLimit: 3,
+			NewObject: func() client.Object { return &corev1.ConfigMap{} },
+			Reconciler: reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+				return reconcile.Result{}, failure
+			}),
+		}
+
+		failure = errors.New("boom")
+		for i := 0; i < 2; i++ {
+			_, _ = watchdog.Reconcile(ctx, request)
+		}
+		assert.Equal(t, len(recorder.Events), 0)
+
+		// The event happens once when the limit is reached.
+		_, _ = watchdog.Reconcile(ctx, request)
+		event := <-recorder.Events
+		assert.Assert(t, strings.Contains(event, "ReconcileFailing"), "got %q", event)
+		assert.Assert(t, strings.Contains(event, "3 times in a row: boom"), "got %q", event)
+
+		_, _ = watchdog.Reconcile(ctx, request)
+		assert.Equal(t, len(recorder.Events), 0)
+
+		// A success starts over.
+		failure = nil
+		_, _ = watchdog.Reconcile(ctx, request)
+		assert.Equal(t, len(watchdog.errors), 0)
+
+		failure = errors.New("boom")
+		for i := 0; i < 2; i++ {
+			_, _ = watchdog.Reconcile(ctx, request)
+		}
+		assert.Equal(t, len(recorder.Events), 0)
+	})
+}
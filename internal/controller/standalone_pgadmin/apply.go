@@ -18,9 +18,29 @@ import (
 	"context"
 	"reflect"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
+// deleteControlled safely deletes object when it is controlled by pgadmin.
+//
+// TODO(tjmoore4): This function is duplicated from a version that takes a PostgresCluster object.
+func (r *PGAdminReconciler) deleteControlled(
+	ctx context.Context, pgadmin *v1beta1.PGAdmin, object client.Object,
+) error {
+	if metav1.IsControlledBy(object, pgadmin) {
+		uid := object.GetUID()
+		version := object.GetResourceVersion()
+		exactly := client.Preconditions{UID: &uid, ResourceVersion: &version}
+
+		return r.Client.Delete(ctx, object, exactly)
+	}
+
+	return nil
+}
+
 // patch sends patch to object's endpoint in the Kubernetes API and updates
 // object with any returned content. The fieldManager is set to r.Owner, but
 // can be overridden in options.
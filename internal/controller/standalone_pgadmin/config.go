@@ -19,6 +19,11 @@ const (
 	// ConfigMap keys used also in mounting volume to pod
 	settingsConfigMapKey  = "pgadmin-settings.json"
 	settingsClusterMapKey = "pgadmin-shared-clusters.json"
+	settingsUsersMapKey   = "pgadmin-users.json"
+
+	// Secret keys used also in mounting volume to pod
+	pgAdminSecretKey = "secret-key"
+	pgAdminSaltKey   = "security-password-salt"
 
 	// Port address used to define pod and service
 	pgAdminPort = 5050
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 
@@ -76,6 +77,12 @@ func configmap(pgadmin *v1beta1.PGAdmin,
 		configmap.Data[settingsClusterMapKey] = clusterSettings
 	}
 
+	if err == nil && len(pgadmin.Spec.Users) > 0 {
+		var userSettings string
+		userSettings, err = generateUsersConfig(pgadmin)
+		configmap.Data[settingsUsersMapKey] = userSettings
+	}
+
 	return configmap, err
 }
 
@@ -88,11 +95,76 @@ func generateConfig(pgadmin *v1beta1.PGAdmin) (string, error) {
 		"DEFAULT_SERVER": "0.0.0.0",
 	}
 
+	// Copy the settings of any security profile over the defaults.
+	for k, v := range securityProfileSettings(pgadmin.Spec.Config.SecurityProfile) {
+		settings[k] = v
+	}
+
+	// An ingress controller is one proxy that sets each X-Forwarded header.
+	// Trust it so that pgAdmin builds redirects with the host and scheme of
+	// the browser rather than its own.
+	// - https://www.pgadmin.org/docs/pgadmin4/latest/config_py.html
+	if pgadmin.Spec.Ingress != nil {
+		settings["PROXY_X_FOR_COUNT"] = 1
+		settings["PROXY_X_HOST_COUNT"] = 1
+		settings["PROXY_X_PORT_COUNT"] = 1
+		settings["PROXY_X_PROTO_COUNT"] = 1
+	}
+
 	// Copy any specified settings over the defaults.
 	for k, v := range pgadmin.Spec.Config.Settings {
 		settings[k] = v
 	}
 
+	// Write settings from typed fields over any specified ones.
+	// - https://www.pgadmin.org/docs/pgadmin4/latest/config_py.html
+	if mail := pgadmin.Spec.Config.Mail; mail != nil {
+		settings["MAIL_SERVER"] = mail.Server
+		settings["MAIL_USE_TLS"] = mail.UseTLS
+		if mail.Port != nil {
+			settings["MAIL_PORT"] = *mail.Port
+		}
+	}
+	if mfa := pgadmin.Spec.Config.MFA; mfa != nil {
+		settings["MFA_ENABLED"] = true
+		settings["MFA_FORCE_REGISTRATION"] = mfa.Required
+		settings["MFA_SUPPORTED_METHODS"] = mfa.Methods
+	}
+	if oauth2 := pgadmin.Spec.Config.OAuth2; oauth2 != nil {
+		settings["OAUTH2_AUTO_CREATE_USER"] = oauth2.AutoCreateUser == nil || *oauth2.AutoCreateUser
+		settings["OAUTH2_CONFIG"] = oauth2Providers(oauth2.Providers)
+
+		// Keep any other authentication sources, including the internal one
+		// that the operator uses to register servers.
+		sources, ok := settings["AUTHENTICATION_SOURCES"].([]any)
+		if !ok {
+			sources = []any{"internal"}
+		}
+		found := false
+		for _, source := range sources {
+			found = found || source == "oauth2"
+		}
+		if !found {
+			settings["AUTHENTICATION_SOURCES"] = append([]any{"oauth2"}, sources...)
+		}
+	}
+	if pgadmin.Spec.StorageVolumeClaimSpec != nil {
+		settings["STORAGE_DIR"] = storageMountPath
+	}
+	if proxies := pgadmin.Spec.Config.TrustedProxies; proxies != nil {
+		for key, count := range map[string]*int32{
+			"PROXY_X_FOR_COUNT":    proxies.For,
+			"PROXY_X_HOST_COUNT":   proxies.Host,
+			"PROXY_X_PORT_COUNT":   proxies.Port,
+			"PROXY_X_PREFIX_COUNT": proxies.Prefix,
+			"PROXY_X_PROTO_COUNT":  proxies.Proto,
+		} {
+			if count != nil {
+				settings[key] = *count
+			}
+		}
+	}
+
 	// Write mandatory settings over any specified ones.
 	// SERVER_MODE must always be enabled when running on a webserver.
 	// - https://github.com/pgadmin-org/pgadmin4/blob/REL-7_7/web/config.py#L110
@@ -114,6 +186,78 @@ func generateConfig(pgadmin *v1beta1.PGAdmin) (string, error) {
 	return buffer.String(), err
 }
 
+// oauth2Providers returns the OAUTH2_CONFIG setting for providers. Client
+// secrets are not part of it; pgAdmin reads them from files when it starts.
+// - https://www.pgadmin.org/docs/pgadmin4/latest/oauth2.html
+func oauth2Providers(providers []v1beta1.StandalonePGAdminOAuth2Provider) []map[string]any {
+	config := make([]map[string]any, 0, len(providers))
+	for _, provider := range providers {
+		display, scopes := provider.DisplayName, provider.Scopes
+		if display == "" {
+			display = provider.Name
+		}
+		if len(scopes) == 0 {
+			scopes = []string{"openid", "email", "profile"}
+		}
+
+		c := map[string]any{
+			"OAUTH2_NAME":              provider.Name,
+			"OAUTH2_DISPLAY_NAME":      display,
+			"OAUTH2_CLIENT_ID":         provider.ClientID,
+			"OAUTH2_AUTHORIZATION_URL": provider.AuthorizationURL,
+			"OAUTH2_TOKEN_URL":         provider.TokenURL,
+			"OAUTH2_API_BASE_URL":      provider.APIBaseURL,
+			"OAUTH2_USERINFO_ENDPOINT": provider.UserInfoEndpoint,
+			"OAUTH2_SCOPE":             strings.Join(scopes, " "),
+		}
+		if provider.ServerMetadataURL != "" {
+			c["OAUTH2_SERVER_METADATA_URL"] = provider.ServerMetadataURL
+		}
+		config = append(config, c)
+	}
+	return config
+}
+
+// securityProfileSettings returns the pgAdmin settings of profile. The "Strict"
+// profile follows the hardening guidance of pgAdmin.
+// - https://www.pgadmin.org/docs/pgadmin4/latest/config_py.html
+// - https://www.pgadmin.org/docs/pgadmin4/latest/enabling_webserver_hardening.html
+func securityProfileSettings(profile string) map[string]any {
+	if profile != "Strict" {
+		return nil
+	}
+	return map[string]any{
+		// Lock accounts after repeated failures and require long passwords.
+		"MAX_LOGIN_ATTEMPTS":  5,
+		"PASSWORD_LENGTH_MIN": 12,
+
+		// Do not keep the passwords of servers or SSH tunnels.
+		"ALLOW_SAVE_PASSWORD":        false,
+		"ALLOW_SAVE_TUNNEL_PASSWORD": false,
+
+		// Log out after 15 minutes without activity, and expire every session
+		// after one day. SESSION_EXPIRATION_TIME is in days.
+		"OVERRIDE_USER_INACTIVITY_TIMEOUT": true,
+		"USER_INACTIVITY_TIMEOUT":          900,
+		"SESSION_EXPIRATION_TIME":          1,
+
+		// Bind sessions to the client and send cookies only over HTTPS.
+		"ENHANCED_COOKIE_PROTECTION": true,
+		"SESSION_COOKIE_HTTPONLY":    true,
+		"SESSION_COOKIE_SAMESITE":    "Strict",
+		"SESSION_COOKIE_SECURE":      true,
+
+		// Send security headers. pgAdmin opens some tools in frames of its own
+		// origin, so frames from that origin are allowed.
+		"ENABLE_SECURITY_HEADERS":           true,
+		"STRICT_TRANSPORT_SECURITY_ENABLED": true,
+		"STRICT_TRANSPORT_SECURITY":         "max-age=31536000; includeSubDomains",
+		"X_CONTENT_TYPE_OPTIONS":            "nosniff",
+		"X_FRAME_OPTIONS":                   "SAMEORIGIN",
+		"X_XSS_PROTECTION":                  "1; mode=block",
+	}
+}
+
 // generateClusterConfig generates the settings for the servers registered in pgAdmin.
 // pgAdmin's `setup.py --load-server` function ingests this list of servers as JSON,
 // in the following form:
@@ -181,3 +325,29 @@ func generateClusterConfig(
 	err := encoder.Encode(servers)
 	return buffer.String(), err
 }
+
+// generateUsersConfig generates the list of users that the startup script of
+// pgAdmin creates or updates. Their passwords are not in this list; each user
+// has the path of a file that contains its password.
+func generateUsersConfig(pgadmin *v1beta1.PGAdmin) (string, error) {
+	buffer := new(bytes.Buffer)
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+
+	users := make([]map[string]any, 0, len(pgadmin.Spec.Users))
+	for i, user := range pgadmin.Spec.Users {
+		role := user.Role
+		if role == "" {
+			role = "User"
+		}
+		users = append(users, map[string]any{
+			"username":     user.Username,
+			"role":         role,
+			"passwordFile": fmt.Sprintf("%s/%s/%d", configMountPath, passwordsFilePath, i),
+		})
+	}
+
+	err := encoder.Encode(users)
+	return buffer.String(), err
+}
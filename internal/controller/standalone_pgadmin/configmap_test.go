@@ -18,7 +18,9 @@ import (
 	"testing"
 
 	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
 
+	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
 	"github.com/crunchydata/postgres-operator/internal/testing/require"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
@@ -79,6 +81,229 @@ func TestGenerateConfig(t *testing.T) {
   "UPGRADE_CHECK_ENABLED": false,
   "UPGRADE_CHECK_KEY": "",
   "UPGRADE_CHECK_URL": ""
+}`+"\n")
+	})
+
+	t.Run("Mail", func(t *testing.T) {
+		pgadmin := new(v1beta1.PGAdmin)
+		pgadmin.Spec.Config.Settings = map[string]any{
+			"MAIL_SERVER":   "ignored",
+			"MAIL_PASSWORD": "ignored",
+		}
+		pgadmin.Spec.Config.Mail = &v1beta1.StandalonePGAdminMail{
+			Server: "smtp.example.com",
+			Port:   initialize.Int32(587),
+			UseTLS: true,
+		}
+		result, err := generateConfig(pgadmin)
+
+		assert.NilError(t, err)
+		assert.Equal(t, result, `{
+  "DEFAULT_SERVER": "0.0.0.0",
+  "MAIL_PASSWORD": "ignored",
+  "MAIL_PORT": 587,
+  "MAIL_SERVER": "smtp.example.com",
+  "MAIL_USE_TLS": true,
+  "SERVER_MODE": true,
+  "UPGRADE_CHECK_ENABLED": false,
+  "UPGRADE_CHECK_KEY": "",
+  "UPGRADE_CHECK_URL": ""
+}`+"\n")
+	})
+
+	t.Run("MFA", func(t *testing.T) {
+		pgadmin := new(v1beta1.PGAdmin)
+		pgadmin.Spec.Config.Settings = map[string]any{
+			"MFA_ENABLED": false,
+		}
+		pgadmin.Spec.Config.MFA = &v1beta1.StandalonePGAdminMFA{
+			Methods:  []v1beta1.StandalonePGAdminMFAMethod{"authenticator", "email"},
+			Required: true,
+		}
+		result, err := generateConfig(pgadmin)
+
+		assert.NilError(t, err)
+		assert.Equal(t, result, `{
+  "DEFAULT_SERVER": "0.0.0.0",
+  "MFA_ENABLED": true,
+  "MFA_FORCE_REGISTRATION": true,
+  "MFA_SUPPORTED_METHODS": [
+    "authenticator",
+    "email"
+  ],
+  "SERVER_MODE": true,
+  "UPGRADE_CHECK_ENABLED": false,
+  "UPGRADE_CHECK_KEY": "",
+  "UPGRADE_CHECK_URL": ""
+}`+"\n")
+	})
+
+	t.Run("OAuth2", func(t *testing.T) {
+		pgadmin := new(v1beta1.PGAdmin)
+		pgadmin.Spec.Config.Settings = map[string]any{
+			"AUTHENTICATION_SOURCES": []any{"ldap", "internal"},
+		}
+		pgadmin.Spec.Config.OAuth2 = &v1beta1.StandalonePGAdminOAuth2{
+			AutoCreateUser: initialize.Bool(false),
+			Providers: []v1beta1.StandalonePGAdminOAuth2Provider{{
+				Name:             "github",
+				DisplayName:      "GitHub",
+				ClientID:         "some-client",
+				AuthorizationURL: "https://github.com/login/oauth/authorize",
+				TokenURL:         "https://github.com/login/oauth/access_token",
+				APIBaseURL:       "https://api.github.com/",
+				UserInfoEndpoint: "user",
+				Scopes:           []string{"user:email"},
+			}, {
+				Name:              "keycloak",
+				ClientID:          "pgadmin",
+				AuthorizationURL:  "https://sso.example.com/auth",
+				TokenURL:          "https://sso.example.com/token",
+				APIBaseURL:        "https://sso.example.com/",
+				UserInfoEndpoint:  "userinfo",
+				ServerMetadataURL: "https://sso.example.com/.well-known/openid-configuration",
+			}},
+		}
+		result, err := generateConfig(pgadmin)
+
+		assert.NilError(t, err)
+		assert.Equal(t, result, `{
+  "AUTHENTICATION_SOURCES": [
+    "oauth2",
+    "ldap",
+    "internal"
+  ],
+  "DEFAULT_SERVER": "0.0.0.0",
+  "OAUTH2_AUTO_CREATE_USER": false,
+  "OAUTH2_CONFIG": [
+    {
+      "OAUTH2_API_BASE_URL": "https://api.github.com/",
+      "OAUTH2_AUTHORIZATION_URL": "https://github.com/login/oauth/authorize",
+      "OAUTH2_CLIENT_ID": "some-client",
+      "OAUTH2_DISPLAY_NAME": "GitHub",
+      "OAUTH2_NAME": "github",
+      "OAUTH2_SCOPE": "user:email",
+      "OAUTH2_TOKEN_URL": "https://github.com/login/oauth/access_token",
+      "OAUTH2_USERINFO_ENDPOINT": "user"
+    },
+    {
+      "OAUTH2_API_BASE_URL": "https://sso.example.com/",
+      "OAUTH2_AUTHORIZATION_URL": "https://sso.example.com/auth",
+      "OAUTH2_CLIENT_ID": "pgadmin",
+      "OAUTH2_DISPLAY_NAME": "keycloak",
+      "OAUTH2_NAME": "keycloak",
+      "OAUTH2_SCOPE": "openid email profile",
+      "OAUTH2_SERVER_METADATA_URL": "https://sso.example.com/.well-known/openid-configuration",
+      "OAUTH2_TOKEN_URL": "https://sso.example.com/token",
+      "OAUTH2_USERINFO_ENDPOINT": "userinfo"
+    }
+  ],
+  "SERVER_MODE": true,
+  "UPGRADE_CHECK_ENABLED": false,
+  "UPGRADE_CHECK_KEY": "",
+  "UPGRADE_CHECK_URL": ""
+}`+"\n")
+	})
+
+	t.Run("Ingress", func(t *testing.T) {
+		pgadmin := new(v1beta1.PGAdmin)
+		pgadmin.Spec.Ingress = &v1beta1.StandalonePGAdminIngress{Host: "pgadmin.example.com"}
+		pgadmin.Spec.Config.Settings = map[string]any{
+			"PROXY_X_HOST_COUNT": 0,
+		}
+		pgadmin.Spec.Config.TrustedProxies = &v1beta1.StandalonePGAdminTrustedProxies{
+			For: initialize.Int32(2),
+		}
+		result, err := generateConfig(pgadmin)
+
+		assert.NilError(t, err)
+		assert.Equal(t, result, `{
+  "DEFAULT_SERVER": "0.0.0.0",
+  "PROXY_X_FOR_COUNT": 2,
+  "PROXY_X_HOST_COUNT": 0,
+  "PROXY_X_PORT_COUNT": 1,
+  "PROXY_X_PROTO_COUNT": 1,
+  "SERVER_MODE": true,
+  "UPGRADE_CHECK_ENABLED": false,
+  "UPGRADE_CHECK_KEY": "",
+  "UPGRADE_CHECK_URL": ""
+}`+"\n")
+	})
+
+	t.Run("TrustedProxies", func(t *testing.T) {
+		pgadmin := new(v1beta1.PGAdmin)
+		pgadmin.Spec.Config.Settings = map[string]any{
+			"PROXY_X_FOR_COUNT": 3,
+		}
+		pgadmin.Spec.Config.TrustedProxies = &v1beta1.StandalonePGAdminTrustedProxies{
+			For:   initialize.Int32(2),
+			Proto: initialize.Int32(1),
+		}
+		result, err := generateConfig(pgadmin)
+
+		assert.NilError(t, err)
+		assert.Equal(t, result, `{
+  "DEFAULT_SERVER": "0.0.0.0",
+  "PROXY_X_FOR_COUNT": 2,
+  "PROXY_X_PROTO_COUNT": 1,
+  "SERVER_MODE": true,
+  "UPGRADE_CHECK_ENABLED": false,
+  "UPGRADE_CHECK_KEY": "",
+  "UPGRADE_CHECK_URL": ""
+}`+"\n")
+	})
+
+	t.Run("StorageVolume", func(t *testing.T) {
+		pgadmin := new(v1beta1.PGAdmin)
+		pgadmin.Spec.Config.Settings = map[string]any{
+			"STORAGE_DIR": "/tmp",
+		}
+		pgadmin.Spec.StorageVolumeClaimSpec = &corev1.PersistentVolumeClaimSpec{}
+		result, err := generateConfig(pgadmin)
+
+		assert.NilError(t, err)
+		assert.Equal(t, result, `{
+  "DEFAULT_SERVER": "0.0.0.0",
+  "SERVER_MODE": true,
+  "STORAGE_DIR": "/var/lib/pgadmin-storage",
+  "UPGRADE_CHECK_ENABLED": false,
+  "UPGRADE_CHECK_KEY": "",
+  "UPGRADE_CHECK_URL": ""
+}`+"\n")
+	})
+
+	t.Run("SecurityProfile", func(t *testing.T) {
+		pgadmin := new(v1beta1.PGAdmin)
+		pgadmin.Spec.Config.SecurityProfile = "Strict"
+		pgadmin.Spec.Config.Settings = map[string]any{
+			"USER_INACTIVITY_TIMEOUT": 3600,
+		}
+		result, err := generateConfig(pgadmin)
+
+		assert.NilError(t, err)
+		assert.Equal(t, result, `{
+  "ALLOW_SAVE_PASSWORD": false,
+  "ALLOW_SAVE_TUNNEL_PASSWORD": false,
+  "DEFAULT_SERVER": "0.0.0.0",
+  "ENABLE_SECURITY_HEADERS": true,
+  "ENHANCED_COOKIE_PROTECTION": true,
+  "MAX_LOGIN_ATTEMPTS": 5,
+  "OVERRIDE_USER_INACTIVITY_TIMEOUT": true,
+  "PASSWORD_LENGTH_MIN": 12,
+  "SERVER_MODE": true,
+  "SESSION_COOKIE_HTTPONLY": true,
+  "SESSION_COOKIE_SAMESITE": "Strict",
+  "SESSION_COOKIE_SECURE": true,
+  "SESSION_EXPIRATION_TIME": 1,
+  "STRICT_TRANSPORT_SECURITY": "max-age=31536000; includeSubDomains",
+  "STRICT_TRANSPORT_SECURITY_ENABLED": true,
+  "UPGRADE_CHECK_ENABLED": false,
+  "UPGRADE_CHECK_KEY": "",
+  "UPGRADE_CHECK_URL": "",
+  "USER_INACTIVITY_TIMEOUT": 3600,
+  "X_CONTENT_TYPE_OPTIONS": "nosniff",
+  "X_FRAME_OPTIONS": "SAMEORIGIN",
+  "X_XSS_PROTECTION": "1; mode=block"
 }`+"\n")
 	})
 }
@@ -167,6 +392,30 @@ func TestGenerateClusterConfig(t *testing.T) {
 	assert.Equal(t, actualString, expectedString)
 }
 
+func TestGenerateUsersConfig(t *testing.T) {
+	pgadmin := new(v1beta1.PGAdmin)
+	pgadmin.Spec.Users = []v1beta1.PGAdminUser{
+		{Username: "one@example.com"},
+		{Username: "two@example.com", Role: "Administrator"},
+	}
+
+	actual, err := generateUsersConfig(pgadmin)
+	assert.NilError(t, err)
+	assert.Equal(t, actual, `[
+  {
+    "passwordFile": "/etc/pgadmin/conf.d/~postgres-operator/user-passwords/0",
+    "role": "User",
+    "username": "one@example.com"
+  },
+  {
+    "passwordFile": "/etc/pgadmin/conf.d/~postgres-operator/user-passwords/1",
+    "role": "Administrator",
+    "username": "two@example.com"
+  }
+]
+`)
+}
+
 func TestGeneratePGAdminConfigMap(t *testing.T) {
 	require.ParallelCapacity(t, 0)
 
@@ -19,6 +19,7 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
@@ -30,6 +31,8 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/kubeapi"
 	"github.com/crunchydata/postgres-operator/internal/logging"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
@@ -48,6 +51,9 @@ type PGAdminReconciler struct {
 //+kubebuilder:rbac:groups="",resources="secrets",verbs={list,watch}
 //+kubebuilder:rbac:groups="",resources="configmaps",verbs={list,watch}
 //+kubebuilder:rbac:groups="apps",resources="statefulsets",verbs={list,watch}
+//+kubebuilder:rbac:groups="apps",resources="deployments",verbs={list,watch}
+//+kubebuilder:rbac:groups="",resources="services",verbs={list,watch}
+//+kubebuilder:rbac:groups="networking.k8s.io",resources="ingresses",verbs={list,watch}
 
 // SetupWithManager sets up the controller with the Manager.
 //
@@ -59,6 +65,9 @@ func (r *PGAdminReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&corev1.PersistentVolumeClaim{}).
 		Owns(&corev1.Secret{}).
 		Owns(&appsv1.StatefulSet{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&corev1.Service{}).
+		Owns(&networkingv1.Ingress{}).
 		Watches(
 			&source.Kind{Type: v1beta1.NewPostgresCluster()},
 			r.watchPostgresClusters(),
@@ -127,13 +136,25 @@ func (r *PGAdminReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 
 	// Set defaults if unset
 	pgAdmin.Default()
+	if pgAdmin.Spec.OpenShift == nil {
+		pgAdmin.Spec.OpenShift = initialize.Bool(
+			kubeapi.IsOpenShiftNamespace(ctx, r.Client, pgAdmin.Namespace, r.IsOpenShift))
+	}
 
 	var (
 		configmap  *corev1.ConfigMap
 		dataVolume *corev1.PersistentVolumeClaim
+		service    *corev1.Service
 		clusters   map[string]*v1beta1.PostgresClusterList
 	)
 
+	// Wait for any PostgresClusters this pgAdmin depends on before creating
+	// anything.
+	var waiting bool
+	if waiting, err = r.reconcileDependencies(ctx, pgAdmin); err != nil || waiting {
+		return ctrl.Result{}, err
+	}
+
 	_, err = r.reconcilePGAdminSecret(ctx, pgAdmin)
 
 	if err == nil {
@@ -145,9 +166,21 @@ func (r *PGAdminReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ct
 	if err == nil {
 		dataVolume, err = r.reconcilePGAdminDataVolume(ctx, pgAdmin)
 	}
+	if err == nil {
+		err = r.reconcilePGAdminStorageVolume(ctx, pgAdmin)
+	}
 	if err == nil {
 		err = r.reconcilePGAdminStatefulSet(ctx, pgAdmin, configmap, dataVolume)
 	}
+	if err == nil {
+		err = r.reconcilePGAdminDeployment(ctx, pgAdmin, configmap)
+	}
+	if err == nil {
+		service, err = r.reconcilePGAdminService(ctx, pgAdmin)
+	}
+	if err == nil {
+		err = r.reconcilePGAdminIngress(ctx, pgAdmin, service)
+	}
 
 	if err == nil {
 		// at this point everything reconciled successfully, and we can update the
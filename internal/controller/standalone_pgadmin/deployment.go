@@ -0,0 +1,100 @@
+// Copyright 2023 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standalone_pgadmin
+
+import (
+	"context"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/pkg/errors"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// +kubebuilder:rbac:groups="apps",resources="deployments",verbs={create,delete,patch}
+
+// reconcilePGAdminDeployment writes the Deployment that runs pgAdmin when it
+// keeps its configuration in another database. Otherwise, it removes any
+// Deployment that pgAdmin ran in before.
+func (r *PGAdminReconciler) reconcilePGAdminDeployment(
+	ctx context.Context, pgadmin *v1beta1.PGAdmin, configmap *corev1.ConfigMap,
+) error {
+	if pgadmin.Spec.Config.ConfigDatabaseURI == nil {
+		existing := &appsv1.Deployment{ObjectMeta: naming.StandalonePGAdmin(pgadmin)}
+		err := errors.WithStack(client.IgnoreNotFound(
+			r.Client.Get(ctx, client.ObjectKeyFromObject(existing), existing)))
+		if err == nil {
+			err = errors.WithStack(client.IgnoreNotFound(
+				r.deleteControlled(ctx, pgadmin, existing)))
+		}
+		return err
+	}
+
+	deploy := deployment(pgadmin, configmap)
+
+	err := errors.WithStack(r.setControllerReference(pgadmin, deploy))
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, deploy))
+	}
+	return err
+}
+
+// deployment defines the Deployment that runs pgAdmin when it keeps its
+// configuration in another database. Its Pods share nothing but that database
+// and any volume for the files of users, so there can be more than one.
+func deployment(pgadmin *v1beta1.PGAdmin, configmap *corev1.ConfigMap) *appsv1.Deployment {
+	deploy := &appsv1.Deployment{ObjectMeta: naming.StandalonePGAdmin(pgadmin)}
+	deploy.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind("Deployment"))
+
+	deploy.Annotations = pgadmin.Spec.Metadata.GetAnnotationsOrNil()
+	deploy.Labels = naming.Merge(
+		pgadmin.Spec.Metadata.GetLabelsOrNil(),
+		naming.StandalonePGAdminCommonLabels(pgadmin),
+	)
+	deploy.Spec.Selector = &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			naming.LabelStandalonePGAdmin: pgadmin.Name,
+			naming.LabelRole:              naming.RolePGAdmin,
+		},
+	}
+
+	deploy.Spec.Replicas = initialize.Int32(2)
+	if pgadmin.Spec.Replicas != nil {
+		deploy.Spec.Replicas = pgadmin.Spec.Replicas
+	}
+
+	// Don't clutter the namespace with extra ReplicaSets.
+	deploy.Spec.RevisionHistoryLimit = initialize.Int32(0)
+
+	// Ensure that the number of Ready pods is never less than the specified
+	// Replicas by starting new pods while old pods are still running.
+	// - https://docs.k8s.io/concepts/workloads/controllers/deployment/#rolling-update-deployment
+	deploy.Spec.Strategy.Type = appsv1.RollingUpdateDeploymentStrategyType
+	deploy.Spec.Strategy.RollingUpdate = &appsv1.RollingUpdateDeployment{
+		MaxUnavailable: intstr.ValueOrDefault(nil, intstr.FromInt(0)),
+	}
+
+	// The data volume of each Pod is discarded with it.
+	podTemplate(pgadmin, configmap, &deploy.Spec.Template, nil)
+
+	return deploy
+}
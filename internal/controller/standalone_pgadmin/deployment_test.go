@@ -0,0 +1,72 @@
+// Copyright 2023 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standalone_pgadmin
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestDeployment(t *testing.T) {
+	configmap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "some-cm"}}
+
+	pgadmin := new(v1beta1.PGAdmin)
+	pgadmin.Namespace, pgadmin.Name = "ns1", "test-standalone-pgadmin"
+	pgadmin.Spec.Config.ConfigDatabaseURI = &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: "pgadmin-db"},
+		Key:                  "uri",
+	}
+
+	t.Run("Defaults", func(t *testing.T) {
+		deploy := deployment(pgadmin, configmap)
+
+		assert.Equal(t, deploy.Name, "pgadmin-")
+		assert.Assert(t, cmp.MarshalMatches(deploy.Spec.Selector, `
+matchLabels:
+  postgres-operator.crunchydata.com/pgadmin: test-standalone-pgadmin
+  postgres-operator.crunchydata.com/role: pgadmin
+		`))
+		assert.Equal(t, *deploy.Spec.Replicas, int32(2))
+		assert.Equal(t, *deploy.Spec.RevisionHistoryLimit, int32(0))
+		assert.Assert(t, cmp.MarshalMatches(deploy.Spec.Strategy, `
+rollingUpdate:
+  maxUnavailable: 0
+type: RollingUpdate
+		`))
+
+		// The data of each Pod is not kept.
+		assert.Assert(t, cmp.Contains(deploy.Spec.Template.Spec.Volumes, corev1.Volume{
+			Name: "pgadmin-data",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		}))
+	})
+
+	t.Run("Replicas", func(t *testing.T) {
+		pgadmin := pgadmin.DeepCopy()
+		pgadmin.Spec.Replicas = initialize.Int32(3)
+
+		deploy := deployment(pgadmin, configmap)
+		assert.Equal(t, *deploy.Spec.Replicas, int32(3))
+	})
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standalone_pgadmin
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/pkg/errors"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// +kubebuilder:rbac:groups="networking.k8s.io",resources="ingresses",verbs={create,delete,patch}
+
+// reconcilePGAdminIngress writes the Ingress that routes requests to the
+// pgAdmin Service. When there is no Ingress in the spec, it removes any
+// Ingress it wrote before.
+func (r *PGAdminReconciler) reconcilePGAdminIngress(
+	ctx context.Context, pgadmin *v1beta1.PGAdmin, service *corev1.Service,
+) error {
+	if pgadmin.Spec.Ingress == nil {
+		existing := &networkingv1.Ingress{ObjectMeta: naming.StandalonePGAdmin(pgadmin)}
+		err := errors.WithStack(client.IgnoreNotFound(
+			r.Client.Get(ctx, client.ObjectKeyFromObject(existing), existing)))
+		if err == nil {
+			err = errors.WithStack(client.IgnoreNotFound(
+				r.deleteControlled(ctx, pgadmin, existing)))
+		}
+		return err
+	}
+
+	ingress := ingress(pgadmin, service)
+
+	err := errors.WithStack(r.setControllerReference(pgadmin, ingress))
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, ingress))
+	}
+	return err
+}
+
+// ingress defines the Ingress that routes requests for the host in the spec
+// to service.
+func ingress(pgadmin *v1beta1.PGAdmin, service *corev1.Service) *networkingv1.Ingress {
+	spec := pgadmin.Spec.Ingress
+
+	ingress := &networkingv1.Ingress{ObjectMeta: naming.StandalonePGAdmin(pgadmin)}
+	ingress.SetGroupVersionKind(networkingv1.SchemeGroupVersion.WithKind("Ingress"))
+
+	ingress.Annotations = naming.Merge(
+		pgadmin.Spec.Metadata.GetAnnotationsOrNil(),
+		spec.Metadata.GetAnnotationsOrNil(),
+	)
+	ingress.Labels = naming.Merge(
+		pgadmin.Spec.Metadata.GetLabelsOrNil(),
+		spec.Metadata.GetLabelsOrNil(),
+		naming.StandalonePGAdminCommonLabels(pgadmin),
+	)
+
+	ingress.Spec.IngressClassName = spec.IngressClassName
+
+	// Send everything under the URL prefix of pgAdmin, if any, to its Service.
+	// pgAdmin expects that prefix in the path of each request.
+	path := "/"
+	if pgadmin.Spec.Config.URLPrefix != "" {
+		path = pgadmin.Spec.Config.URLPrefix
+	}
+	pathType := networkingv1.PathTypePrefix
+
+	ingress.Spec.Rules = []networkingv1.IngressRule{{
+		Host: spec.Host,
+		IngressRuleValue: networkingv1.IngressRuleValue{
+			HTTP: &networkingv1.HTTPIngressRuleValue{
+				Paths: []networkingv1.HTTPIngressPath{{
+					Path:     path,
+					PathType: &pathType,
+					Backend: networkingv1.IngressBackend{
+						Service: &networkingv1.IngressServiceBackend{
+							Name: service.Name,
+							Port: networkingv1.ServiceBackendPort{
+								Name: naming.PortPGAdmin,
+							},
+						},
+					},
+				}},
+			},
+		},
+	}}
+
+	if spec.TLSSecretName != "" {
+		ingress.Spec.TLS = []networkingv1.IngressTLS{{
+			Hosts:      []string{spec.Host},
+			SecretName: spec.TLSSecretName,
+		}}
+	}
+
+	return ingress
+}
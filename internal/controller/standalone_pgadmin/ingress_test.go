@@ -0,0 +1,94 @@
+// Copyright 2023 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standalone_pgadmin
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestIngress(t *testing.T) {
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "some-svc"}}
+
+	pgadmin := new(v1beta1.PGAdmin)
+	pgadmin.Namespace, pgadmin.Name = "ns1", "test-standalone-pgadmin"
+	pgadmin.Spec.Ingress = &v1beta1.StandalonePGAdminIngress{
+		Host: "pgadmin.example.com",
+	}
+
+	t.Run("Defaults", func(t *testing.T) {
+		ingress := ingress(pgadmin, service)
+
+		assert.Equal(t, ingress.Name, "pgadmin-")
+		assert.Assert(t, cmp.MarshalMatches(ingress.Spec, `
+rules:
+- host: pgadmin.example.com
+  http:
+    paths:
+    - backend:
+        service:
+          name: some-svc
+          port:
+            name: pgadmin
+      path: /
+      pathType: Prefix
+		`))
+	})
+
+	t.Run("Customizations", func(t *testing.T) {
+		pgadmin := pgadmin.DeepCopy()
+		pgadmin.Spec.Metadata = &v1beta1.Metadata{
+			Annotations: map[string]string{"a": "v1", "c": "v3"},
+		}
+		pgadmin.Spec.Config.URLPrefix = "/pgadmin"
+		pgadmin.Spec.Ingress.Metadata = &v1beta1.Metadata{
+			Annotations: map[string]string{"c": "v4"},
+			Labels:      map[string]string{"d": "v5"},
+		}
+		pgadmin.Spec.Ingress.IngressClassName = initialize.String("nginx")
+		pgadmin.Spec.Ingress.TLSSecretName = "pgadmin-ingress-tls"
+
+		ingress := ingress(pgadmin, service)
+
+		assert.DeepEqual(t, ingress.Annotations, map[string]string{"a": "v1", "c": "v4"})
+		assert.Equal(t, ingress.Labels["d"], "v5")
+		assert.Equal(t, ingress.Labels["postgres-operator.crunchydata.com/pgadmin"], "test-standalone-pgadmin")
+		assert.Assert(t, cmp.MarshalMatches(ingress.Spec, `
+ingressClassName: nginx
+rules:
+- host: pgadmin.example.com
+  http:
+    paths:
+    - backend:
+        service:
+          name: some-svc
+          port:
+            name: pgadmin
+      path: /pgadmin
+      pathType: Prefix
+tls:
+- hosts:
+  - pgadmin.example.com
+  secretName: pgadmin-ingress-tls
+		`))
+	})
+}
@@ -16,10 +16,12 @@ package standalone_pgadmin
 
 import (
 	"fmt"
+	"net"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/crunchydata/postgres-operator/internal/config"
 	"github.com/crunchydata/postgres-operator/internal/initialize"
@@ -31,10 +33,31 @@ const (
 	configMountPath = "/etc/pgadmin/conf.d"
 	configFilePath  = "~postgres-operator/" + settingsConfigMapKey
 	clusterFilePath = "~postgres-operator/" + settingsClusterMapKey
+	usersFilePath   = "~postgres-operator/" + settingsUsersMapKey
 	ldapFilePath    = "~postgres-operator/ldap-bind-password"
+	dbURIFilePath   = "~postgres-operator/config-database-uri"
+	mailFilePath    = "~postgres-operator/mail"
+	oauth2FilePath  = "~postgres-operator/oauth2-client-secrets"
+	keyFilePath     = "~postgres-operator/" + pgAdminSecretKey
+	saltFilePath    = "~postgres-operator/" + pgAdminSaltKey
+	tlsCertFilePath = "~postgres-operator/tls.crt"
+	tlsKeyFilePath  = "~postgres-operator/tls.key"
+
+	// The password of each user in the spec is mounted in a file named for its
+	// position in the list
+	passwordsFilePath = "~postgres-operator/user-passwords"
 
 	// Nothing should be mounted to this location except the script our initContainer writes
 	scriptMountPath = "/etc/pgadmin"
+
+	// Python packages from the spec are installed to this location
+	packagesMountPath  = "/var/lib/pgadmin-packages"
+	packagesVolumeName = "pgadmin-packages"
+
+	// The files of pgAdmin users are kept at this location when they have a
+	// volume of their own
+	storageMountPath  = "/var/lib/pgadmin-storage"
+	storageVolumeName = "pgadmin-storage"
 )
 
 // pod populates a PodSpec with the container and volumes needed to run pgAdmin.
@@ -63,13 +86,21 @@ func pod(
 		},
 	}
 
-	// create the data volume for the persistent database
+	// create the data volume for the persistent database. When pgAdmin keeps
+	// its configuration in another database, this holds only files that belong
+	// to the Pod, such as login sessions.
 	dataVolume := corev1.Volume{Name: dataVolumeName}
-	dataVolume.VolumeSource = corev1.VolumeSource{
-		PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
-			ClaimName: pgAdminVolume.Name,
-			ReadOnly:  false,
-		},
+	if pgAdminVolume != nil {
+		dataVolume.VolumeSource = corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: pgAdminVolume.Name,
+				ReadOnly:  false,
+			},
+		}
+	} else {
+		dataVolume.VolumeSource = corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		}
 	}
 
 	// create the temp volume for logs
@@ -161,6 +192,54 @@ func pod(
 			},
 		},
 	}
+	// pgAdmin serves everything beneath SCRIPT_NAME and prefers the path in
+	// any X-Script-Name header sent by a proxy.
+	// - https://www.pgadmin.org/docs/pgadmin4/latest/container_deployment.html
+	if prefix := inPGAdmin.Spec.Config.URLPrefix; prefix != "" {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:  "SCRIPT_NAME",
+			Value: prefix,
+		})
+	}
+
+	// Settings for gunicorn are passed in the environment so they are not
+	// interpreted by a shell.
+	// - https://docs.gunicorn.org/en/stable/settings.html
+	if usesGunicorn(inPGAdmin) {
+		container.Env = append(container.Env, corev1.EnvVar{
+			Name:  "GUNICORN_CMD_ARGS",
+			Value: gunicornArgs(inPGAdmin),
+		})
+	}
+
+	// Probe pgAdmin at an endpoint that answers without a login. pgAdmin
+	// serves it beneath any URL prefix, over HTTPS when it has a certificate.
+	// - https://github.com/pgadmin-org/pgadmin4/blob/REL-7_8/web/pgadmin/misc/__init__.py
+	ping := corev1.ProbeHandler{
+		HTTPGet: &corev1.HTTPGetAction{
+			Path:   inPGAdmin.Spec.Config.URLPrefix + "/misc/ping",
+			Port:   intstr.FromString(naming.PortPGAdmin),
+			Scheme: corev1.URISchemeHTTP,
+		},
+	}
+	if inPGAdmin.Spec.TLS != nil {
+		ping.HTTPGet.Scheme = corev1.URISchemeHTTPS
+	}
+
+	probes := inPGAdmin.Spec.Probes
+	if probes == nil {
+		probes = new(v1beta1.StandalonePGAdminProbes)
+	}
+	container.StartupProbe = podProbe(ping, probes.Startup, corev1.Probe{
+		PeriodSeconds: 10, TimeoutSeconds: 5, FailureThreshold: 30,
+	})
+	container.ReadinessProbe = podProbe(ping, probes.Readiness, corev1.Probe{
+		PeriodSeconds: 10, TimeoutSeconds: 5, FailureThreshold: 3,
+	})
+	container.LivenessProbe = podProbe(ping, probes.Liveness, corev1.Probe{
+		PeriodSeconds: 20, TimeoutSeconds: 5, FailureThreshold: 3,
+	})
+
 	startup := corev1.Container{
 		Name:            naming.ContainerPGAdminStartup,
 		Command:         startupCommand(),
@@ -188,6 +267,88 @@ func pod(
 	}
 	outPod.Containers = []corev1.Container{container}
 	outPod.InitContainers = []corev1.Container{startup}
+
+	// Mount any volume for the files of pgAdmin users. The STORAGE_DIR setting
+	// points there.
+	if inPGAdmin.Spec.StorageVolumeClaimSpec != nil {
+		outPod.Volumes = append(outPod.Volumes, corev1.Volume{
+			Name: storageVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: naming.StandalonePGAdminStorage(inPGAdmin).Name,
+				},
+			},
+		})
+		outPod.Containers[0].VolumeMounts = append(outPod.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      storageVolumeName,
+			MountPath: storageMountPath,
+		})
+	}
+
+	// Install any Python packages into a volume that pgAdmin imports from.
+	// The volume is discarded with the Pod, so packages are installed again
+	// each time the Pod starts.
+	if len(inPGAdmin.Spec.ExtraPythonPackages) > 0 {
+		outPod.Volumes = append(outPod.Volumes, corev1.Volume{
+			Name: packagesVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		})
+		outPod.InitContainers = append(outPod.InitContainers, corev1.Container{
+			Name:            naming.ContainerPGAdminPackages,
+			Command:         packagesCommand(inPGAdmin.Spec.ExtraPythonPackages),
+			Image:           container.Image,
+			ImagePullPolicy: container.ImagePullPolicy,
+			Resources:       container.Resources,
+			SecurityContext: initialize.RestrictedSecurityContext(),
+			VolumeMounts: []corev1.VolumeMount{
+				{
+					Name:      packagesVolumeName,
+					MountPath: packagesMountPath,
+				},
+				{
+					Name:      tempVolumeName,
+					MountPath: "/tmp",
+				},
+			},
+		})
+
+		outPod.Containers[0].Env = append(outPod.Containers[0].Env, corev1.EnvVar{
+			Name:  "PYTHONPATH",
+			Value: packagesMountPath,
+		})
+		outPod.Containers[0].VolumeMounts = append(outPod.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      packagesVolumeName,
+			MountPath: packagesMountPath,
+			ReadOnly:  true,
+		})
+	}
+}
+
+// podProbe returns a Probe of handler with the timing in spec over that of
+// defaults.
+func podProbe(
+	handler corev1.ProbeHandler, spec *v1beta1.StandalonePGAdminProbe, defaults corev1.Probe,
+) *corev1.Probe {
+	probe := defaults
+	probe.ProbeHandler = handler
+
+	if spec != nil {
+		if spec.InitialDelaySeconds != nil {
+			probe.InitialDelaySeconds = *spec.InitialDelaySeconds
+		}
+		if spec.PeriodSeconds != nil {
+			probe.PeriodSeconds = *spec.PeriodSeconds
+		}
+		if spec.TimeoutSeconds != nil {
+			probe.TimeoutSeconds = *spec.TimeoutSeconds
+		}
+		if spec.FailureThreshold != nil {
+			probe.FailureThreshold = *spec.FailureThreshold
+		}
+	}
+	return &probe
 }
 
 // podConfigFiles returns projections of pgAdmin's configuration files to
@@ -215,6 +376,54 @@ func podConfigFiles(configmap *corev1.ConfigMap, pgadmin v1beta1.PGAdmin) []core
 			},
 		}...)
 
+	// The list of users is in the ConfigMap and the password of each user is
+	// mounted from its Secret. The startup script reads these to create and
+	// update the users.
+	if users := pgadmin.Spec.Users; len(users) > 0 {
+		items := &config[len(config)-1].ConfigMap.Items
+		*items = append(*items, corev1.KeyToPath{
+			Key:  settingsUsersMapKey,
+			Path: usersFilePath,
+		})
+
+		for i, user := range users {
+			config = append(config, corev1.VolumeProjection{
+				Secret: &corev1.SecretProjection{
+					LocalObjectReference: user.PasswordRef.LocalObjectReference,
+					Optional:             user.PasswordRef.Optional,
+					Items: []corev1.KeyToPath{
+						{
+							Key:  user.PasswordRef.Key,
+							Path: fmt.Sprintf("%s/%d", passwordsFilePath, i),
+						},
+					},
+				},
+			})
+		}
+	}
+
+	// The operator keeps the SECRET_KEY and SECURITY_PASSWORD_SALT of pgAdmin
+	// in its Secret. The salt is missing from the Secrets of pgAdmins that
+	// were set up before it was kept there.
+	config = append(config, corev1.VolumeProjection{
+		Secret: &corev1.SecretProjection{
+			LocalObjectReference: corev1.LocalObjectReference{
+				Name: naming.StandalonePGAdmin(&pgadmin).Name,
+			},
+			Optional: initialize.Bool(true),
+			Items: []corev1.KeyToPath{
+				{
+					Key:  pgAdminSecretKey,
+					Path: keyFilePath,
+				},
+				{
+					Key:  pgAdminSaltKey,
+					Path: saltFilePath,
+				},
+			},
+		},
+	})
+
 	// To enable LDAP authentication for pgAdmin, various LDAP settings must be configured.
 	// While most of the required configuration can be set using the 'settings'
 	// feature on the spec (.Spec.UserInterface.PGAdmin.Config.Settings), those
@@ -238,9 +447,130 @@ func podConfigFiles(configmap *corev1.ConfigMap, pgadmin v1beta1.PGAdmin) []core
 		})
 	}
 
+	// Likewise, mount the Secret containing the CONFIG_DATABASE_URI. The URI
+	// usually contains a password.
+	if uri := pgadmin.Spec.Config.ConfigDatabaseURI; uri != nil {
+		config = append(config, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: uri.LocalObjectReference,
+				Optional:             uri.Optional,
+				Items: []corev1.KeyToPath{
+					{
+						Key:  uri.Key,
+						Path: dbURIFilePath,
+					},
+				},
+			},
+		})
+	}
+
+	// Likewise, mount the client secret of each OAuth2 provider in a file
+	// named for the provider. The rest of OAUTH2_CONFIG is in the ConfigMap.
+	// - https://www.pgadmin.org/docs/pgadmin4/latest/oauth2.html
+	if oauth2 := pgadmin.Spec.Config.OAuth2; oauth2 != nil {
+		for _, provider := range oauth2.Providers {
+			config = append(config, corev1.VolumeProjection{
+				Secret: &corev1.SecretProjection{
+					LocalObjectReference: provider.ClientSecret.LocalObjectReference,
+					Optional:             provider.ClientSecret.Optional,
+					Items: []corev1.KeyToPath{
+						{
+							Key:  provider.ClientSecret.Key,
+							Path: oauth2FilePath + "/" + provider.Name,
+						},
+					},
+				},
+			})
+		}
+	}
+
+	// Mount the credentials of the SMTP server for the MAIL_USERNAME and
+	// MAIL_PASSWORD settings.
+	if mail := pgadmin.Spec.Config.Mail; mail != nil && mail.SecretName != "" {
+		config = append(config, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: mail.SecretName,
+				},
+				Items: []corev1.KeyToPath{
+					{Key: "username", Path: mailFilePath + "/username"},
+					{Key: "password", Path: mailFilePath + "/password"},
+				},
+			},
+		})
+	}
+
+	// Mount the certificate and private key of pgAdmin when it serves HTTPS.
+	if tls := pgadmin.Spec.TLS; tls != nil {
+		config = append(config, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: corev1.LocalObjectReference{
+					Name: tls.SecretName,
+				},
+				Items: []corev1.KeyToPath{
+					{
+						Key:  corev1.TLSCertKey,
+						Path: tlsCertFilePath,
+					},
+					{
+						Key:  corev1.TLSPrivateKeyKey,
+						Path: tlsKeyFilePath,
+					},
+				},
+			},
+		})
+	}
+
 	return config
 }
 
+// usesGunicorn returns true when pgAdmin runs in gunicorn rather than its own
+// web server. pgAdmin serves HTTPS only through gunicorn.
+func usesGunicorn(pgadmin *v1beta1.PGAdmin) bool {
+	return pgadmin.Spec.TLS != nil || pgadmin.Spec.Gunicorn != nil
+}
+
+// gunicornArgs returns the settings of gunicorn for pgAdmin. It listens on the
+// same address that pgAdmin would. pgAdmin supports only one gunicorn worker.
+// - https://docs.gunicorn.org/en/stable/settings.html
+func gunicornArgs(pgadmin *v1beta1.PGAdmin) string {
+	host, _ := pgadmin.Spec.Config.Settings["DEFAULT_SERVER"].(string)
+	if host == "" {
+		host = "0.0.0.0"
+	}
+
+	threads, timeout, accessLog := int32(25), int32(86400), false
+	if settings := pgadmin.Spec.Gunicorn; settings != nil {
+		if settings.Threads != nil {
+			threads = *settings.Threads
+		}
+		if settings.TimeoutSeconds != nil {
+			timeout = *settings.TimeoutSeconds
+		}
+		accessLog = settings.AccessLog
+	}
+
+	args := []string{
+		"--bind=" + net.JoinHostPort(host, fmt.Sprint(pgAdminPort)),
+		"--workers=1",
+		fmt.Sprintf("--threads=%d", threads),
+		fmt.Sprintf("--timeout=%d", timeout),
+	}
+	if accessLog {
+		args = append(args, "--access-logfile=-")
+	}
+	if pgadmin.Spec.TLS != nil {
+		args = append(args,
+			"--certfile="+configMountPath+"/"+tlsCertFilePath,
+			"--keyfile="+configMountPath+"/"+tlsKeyFilePath)
+	}
+	return strings.Join(args, " ")
+}
+
+// keptDataBackups is the number of copies of the pgAdmin database that the
+// startup script keeps from before each change of pgAdmin release.
+const keptDataBackups = 3
+
 func startupScript(pgadmin *v1beta1.PGAdmin) []string {
 	// loadServerCommand is a python command leveraging the pgadmin setup.py script
 	// with the `--load-servers` flag to replace the servers registered to the admin user
@@ -250,20 +580,68 @@ func startupScript(pgadmin *v1beta1.PGAdmin) []string {
 		clusterFilePath,
 		fmt.Sprintf("admin@%s.%s.svc", pgadmin.Name, pgadmin.Namespace))
 
+	// When there are users in the spec, a Python script creates or updates
+	// them and loads the servers for each of them and for the admin user. The
+	// script is passed as an argument so that it isn't interpreted by the shell.
+	args := []string{"pgadmin", fmt.Sprintf("%s/%s", configMountPath, clusterFilePath)}
+	exports := `export cluster_file="$1";`
+	if len(pgadmin.Spec.Users) > 0 {
+		loadServerCommand = fmt.Sprintf(`python3 -c "${users_script}" ${PGADMIN_DIR}/setup.py %s/%s %s/%s %s`,
+			configMountPath, clusterFilePath,
+			configMountPath, usersFilePath,
+			fmt.Sprintf("admin@%s.%s.svc", pgadmin.Name, pgadmin.Namespace))
+		exports += ` export users_script="$2";`
+		args = append(args, strings.TrimLeft(usersScript, "\n"))
+	}
+
+	// pgAdmin runs in its own web server unless it serves HTTPS or gunicorn is
+	// configured. Then it runs in gunicorn, which is configured by the
+	// GUNICORN_CMD_ARGS environment variable.
+	// - https://www.pgadmin.org/docs/pgadmin4/latest/server_deployment.html
+	var serverCommand = `pgadmin4`
+	if usesGunicorn(pgadmin) {
+		serverCommand = `gunicorn --chdir "${PGADMIN_DIR}" pgAdmin4:app`
+	}
+
 	// This script sets up, starts pgadmin, and runs the `loadServerCommand` to register the discovered servers.
+	//
+	// pgAdmin upgrades its SQLite database and migrates preferences when
+	// setup.py runs with a newer release. The script records the release that
+	// last set up the data volume and, when that changes, copies the database
+	// aside before setup.py changes it. There is one copy for each change of
+	// release, so a setup.py that fails and runs again does not replace the
+	// copy with a database it already changed. Only the newest few are kept.
+	// Nothing is copied when the release cannot be determined.
+	// - https://www.pgadmin.org/docs/pgadmin4/latest/container_deployment.html
 	var startScript = fmt.Sprintf(`
 PGADMIN_DIR=/usr/local/lib/python3.11/site-packages/pgadmin4
 
+PGADMIN_DATA=/var/lib/pgadmin
+version=$(python3 -c 'import importlib.metadata as m; print(m.version("pgadmin4"))' || true)
+previous=$(cat "${PGADMIN_DATA}/pgadmin-version" 2> /dev/null || true)
+if [ -n "${version}" ] && [ -f "${PGADMIN_DATA}/pgadmin4.db" ] && [ "${version}" != "${previous}" ]
+then
+	backup="${PGADMIN_DATA}/pgadmin4.db.${previous:-earlier}-${version}.bak"
+	if [ ! -f "${backup}" ]
+	then
+		echo "Upgrading pgAdmin4 data from ${previous:-an earlier release} to ${version}"
+		cp --preserve=mode,ownership "${PGADMIN_DATA}/pgadmin4.db" "${backup}.tmp"
+		mv "${backup}.tmp" "${backup}"
+	fi
+	ls -1t "${PGADMIN_DATA}"/pgadmin4.db.*.bak | tail -n +%d | xargs --no-run-if-empty rm --
+fi
+
 echo "Running pgAdmin4 Setup"
 python3 ${PGADMIN_DIR}/setup.py
+if [ -n "${version}" ]; then echo "${version}" > "${PGADMIN_DATA}/pgadmin-version"; fi
 
 echo "Starting pgAdmin4"
 PGADMIN4_PIDFILE=/tmp/pgadmin4.pid
-pgadmin4 &
+%s &
 echo $! > $PGADMIN4_PIDFILE
 
 %s
-`, loadServerCommand)
+`, keptDataBackups+1, serverCommand, loadServerCommand)
 
 	// Use a Bash loop to periodically check:
 	// 1. the mtime of the mounted configuration volume for shared/discovered servers.
@@ -286,18 +664,53 @@ while read -r -t 5 -u "${fd}" || true; do
 	fi
 	if [ ! -d /proc/$(cat $PGADMIN4_PIDFILE) ]
 	then
-		pgadmin4 &
+		%s &
 		echo $! > $PGADMIN4_PIDFILE
 		echo "Restarting pgAdmin4"
 	fi
 done
-`, loadServerCommand)
+`, loadServerCommand, serverCommand)
 
-	wrapper := `monitor() {` + startScript + reloadScript + `}; export cluster_file="$1"; export -f monitor; exec -a "$0" bash -ceu monitor`
+	wrapper := `monitor() {` + startScript + reloadScript + `}; ` + exports + ` export -f monitor; exec -a "$0" bash -ceu monitor`
 
-	return []string{"bash", "-ceu", "--", wrapper, "pgadmin", fmt.Sprintf("%s/%s", configMountPath, clusterFilePath)}
+	return append([]string{"bash", "-ceu", "--", wrapper}, args...)
 }
 
+// usersScript creates or updates the users listed in a file using the setup.py
+// script of pgAdmin. Then it replaces the servers of each user and of the admin
+// user with those in another file. It continues past a user that fails and
+// exits nonzero at the end so that the startup script tries again later.
+// - https://www.pgadmin.org/docs/pgadmin4/latest/user_management.html
+const usersScript = `
+import json, subprocess, sys
+setup, servers, path, admin = sys.argv[1:]
+def run(*args):
+    result = subprocess.run([sys.executable, setup, *args], capture_output=True, text=True)
+    if result.returncode != 0:
+        print(result.stdout, result.stderr, file=sys.stderr)
+    return result
+with open(path) as f:
+    users = json.load(f)
+failed = False
+for user in users:
+    with open(user['passwordFile']) as f:
+        password = f.read()
+    admin_flag = '--admin' if user['role'] == 'Administrator' else '--nonadmin'
+    try:
+        existing = json.loads(run('get-users', '--json', '--username', user['username']).stdout)
+    except ValueError:
+        existing = []
+    if existing:
+        result = run('update-user', admin_flag, '--password', password, '--', user['username'])
+    else:
+        result = run('add-user', admin_flag, '--', user['username'], password)
+    failed = failed or result.returncode != 0
+for username in [admin] + [user['username'] for user in users]:
+    result = run('--load-servers', servers, '--user', username, '--replace')
+    failed = failed or result.returncode != 0
+sys.exit(1 if failed else 0)
+`
+
 // startupCommand returns an entrypoint that prepares the filesystem for pgAdmin.
 func startupCommand() []string {
 	// pgAdmin reads from the `/etc/pgadmin/config_system.py` file during startup
@@ -305,18 +718,24 @@ func startupCommand() []string {
 	// - https://github.com/pgadmin-org/pgadmin4/blob/REL-7_7/docs/en_US/config_py.rst
 	//
 	// This command writes a script in `/etc/pgadmin/config_system.py` that reads from
-	// the `pgadmin-settings.json` file and the `ldap-bind-password` file (if it exists)
-	// and sets those variables globally. That way those values are available as pgAdmin
-	// configurations when pgAdmin starts.
+	// the `pgadmin-settings.json` file and the `ldap-bind-password` and
+	// `config-database-uri` files (if they exist) and sets those variables globally. That way those values are available as pgAdmin
+	// configurations when pgAdmin starts. The client secret of each OAuth2 provider
+	// is read from the file named for it into its entry of OAUTH2_CONFIG.
 	//
 	// Note: All pgAdmin settings are uppercase with underscores, so ignore any keys/names
 	// that are not.
 	//
-	// Note: set pgAdmin's LDAP_BIND_PASSWORD setting from the Secret last
-	// in order to overwrite configuration of LDAP_BIND_PASSWORD via ConfigMap JSON.
+	// Note: set pgAdmin's LDAP_BIND_PASSWORD and MAIL_PASSWORD settings from
+	// their Secrets last in order to overwrite configuration of them via ConfigMap JSON.
 	const (
 		// ldapFilePath is the path for mounting the LDAP Bind Password
 		ldapPasswordAbsolutePath = configMountPath + "/" + ldapFilePath
+		dbURIAbsolutePath        = configMountPath + "/" + dbURIFilePath
+		mailAbsolutePath         = configMountPath + "/" + mailFilePath
+		keyAbsolutePath          = configMountPath + "/" + keyFilePath
+		saltAbsolutePath         = configMountPath + "/" + saltFilePath
+		oauth2AbsolutePath       = configMountPath + "/" + oauth2FilePath
 
 		configSystem = `
 import glob, json, re, os
@@ -325,9 +744,28 @@ with open('` + configMountPath + `/` + configFilePath + `') as _f:
     _conf, _data = re.compile(r'[A-Z_0-9]+'), json.load(_f)
     if type(_data) is dict:
         globals().update({k: v for k, v in _data.items() if _conf.fullmatch(k)})
+if os.path.isfile('` + keyAbsolutePath + `'):
+    with open('` + keyAbsolutePath + `') as _f:
+        SECRET_KEY = _f.read()
+if os.path.isfile('` + saltAbsolutePath + `'):
+    with open('` + saltAbsolutePath + `') as _f:
+        SECURITY_PASSWORD_SALT = _f.read()
 if os.path.isfile('` + ldapPasswordAbsolutePath + `'):
     with open('` + ldapPasswordAbsolutePath + `') as _f:
         LDAP_BIND_PASSWORD = _f.read()
+if os.path.isfile('` + dbURIAbsolutePath + `'):
+    with open('` + dbURIAbsolutePath + `') as _f:
+        CONFIG_DATABASE_URI = _f.read()
+if os.path.isfile('` + mailAbsolutePath + `/password'):
+    with open('` + mailAbsolutePath + `/username') as _f:
+        MAIL_USERNAME = _f.read()
+    with open('` + mailAbsolutePath + `/password') as _f:
+        MAIL_PASSWORD = _f.read()
+for _p in globals().get('OAUTH2_CONFIG') or []:
+    _path = os.path.join('` + oauth2AbsolutePath + `', os.path.basename(str(_p.get('OAUTH2_NAME'))))
+    if os.path.isfile(_path):
+        with open(_path) as _f:
+            _p['OAUTH2_CLIENT_SECRET'] = _f.read()
 `
 	)
 
@@ -344,9 +782,22 @@ if os.path.isfile('` + ldapPasswordAbsolutePath + `'):
 	return append([]string{"bash", "-ceu", "--", script, "startup"}, args...)
 }
 
+// packagesCommand returns an entrypoint that installs Python packages for
+// pgAdmin. The packages are passed as arguments rather than through the shell
+// so that nothing in them is interpreted as a command or an option of pip.
+// - https://pip.pypa.io/en/stable/cli/pip_install/
+func packagesCommand(packages []string) []string {
+	script := strings.Join([]string{
+		`export HOME=/tmp PIP_DISABLE_PIP_VERSION_CHECK=1 PIP_NO_CACHE_DIR=1`,
+		`exec python3 -m pip install --no-input --target=` + packagesMountPath + ` -- "$@"`,
+	}, "\n")
+
+	return append([]string{"bash", "-ceu", "--", script, "packages"}, packages...)
+}
+
 // podSecurityContext returns a v1.PodSecurityContext for pgadmin that can write
 // to PersistentVolumes.
-func podSecurityContext(r *PGAdminReconciler) *corev1.PodSecurityContext {
+func podSecurityContext(pgadmin *v1beta1.PGAdmin) *corev1.PodSecurityContext {
 	podSecurityContext := initialize.PodSecurityContext()
 
 	// TODO (dsessler7): Add ability to add supplemental groups
@@ -357,7 +808,7 @@ func podSecurityContext(r *PGAdminReconciler) *corev1.PodSecurityContext {
 	// - https://cloud.redhat.com/blog/a-guide-to-openshift-and-uids
 	// - https://docs.k8s.io/tasks/configure-pod-container/security-context/
 	// - https://docs.openshift.com/container-platform/4.14/authentication/managing-security-context-constraints.html
-	if !r.IsOpenShift {
+	if pgadmin.Spec.OpenShift == nil || !*pgadmin.Spec.OpenShift {
 		podSecurityContext.FSGroup = initialize.Int64(2)
 	}
 
@@ -15,6 +15,7 @@
 package standalone_pgadmin
 
 import (
+	"strings"
 	"testing"
 
 	"gotest.tools/v3/assert"
@@ -49,13 +50,22 @@ containers:
   - bash
   - -ceu
   - --
-  - "monitor() {\nPGADMIN_DIR=/usr/local/lib/python3.11/site-packages/pgadmin4\n\necho
-    \"Running pgAdmin4 Setup\"\npython3 ${PGADMIN_DIR}/setup.py\n\necho \"Starting
-    pgAdmin4\"\nPGADMIN4_PIDFILE=/tmp/pgadmin4.pid\npgadmin4 &\necho $! > $PGADMIN4_PIDFILE\n\npython3
-    ${PGADMIN_DIR}/setup.py --load-servers /etc/pgadmin/conf.d/~postgres-operator/pgadmin-shared-clusters.json
-    --user admin@pgadmin.postgres-operator.svc --replace\n\nexec {fd}<> <(:)\nwhile
-    read -r -t 5 -u \"${fd}\" || true; do\n\tif [ \"${cluster_file}\" -nt \"/proc/self/fd/${fd}\"
-    ] && python3 ${PGADMIN_DIR}/setup.py --load-servers /etc/pgadmin/conf.d/~postgres-operator/pgadmin-shared-clusters.json
+  - "monitor() {\nPGADMIN_DIR=/usr/local/lib/python3.11/site-packages/pgadmin4\n\nPGADMIN_DATA=/var/lib/pgadmin\nversion=$(python3
+    -c 'import importlib.metadata as m; print(m.version(\"pgadmin4\"))' || true)\nprevious=$(cat
+    \"${PGADMIN_DATA}/pgadmin-version\" 2> /dev/null || true)\nif [ -n \"${version}\"
+    ] && [ -f \"${PGADMIN_DATA}/pgadmin4.db\" ] && [ \"${version}\" != \"${previous}\"
+    ]\nthen\n\tbackup=\"${PGADMIN_DATA}/pgadmin4.db.${previous:-earlier}-${version}.bak\"\n\tif
+    [ ! -f \"${backup}\" ]\n\tthen\n\t\techo \"Upgrading pgAdmin4 data from ${previous:-an
+    earlier release} to ${version}\"\n\t\tcp --preserve=mode,ownership \"${PGADMIN_DATA}/pgadmin4.db\"
+    \"${backup}.tmp\"\n\t\tmv \"${backup}.tmp\" \"${backup}\"\n\tfi\n\tls -1t \"${PGADMIN_DATA}\"/pgadmin4.db.*.bak
+    | tail -n +4 | xargs --no-run-if-empty rm --\nfi\n\necho \"Running pgAdmin4 Setup\"\npython3
+    ${PGADMIN_DIR}/setup.py\nif [ -n \"${version}\" ]; then echo \"${version}\" >
+    \"${PGADMIN_DATA}/pgadmin-version\"; fi\n\necho \"Starting pgAdmin4\"\nPGADMIN4_PIDFILE=/tmp/pgadmin4.pid\npgadmin4
+    &\necho $! > $PGADMIN4_PIDFILE\n\npython3 ${PGADMIN_DIR}/setup.py --load-servers
+    /etc/pgadmin/conf.d/~postgres-operator/pgadmin-shared-clusters.json --user admin@pgadmin.postgres-operator.svc
+    --replace\n\nexec {fd}<> <(:)\nwhile read -r -t 5 -u \"${fd}\" || true; do\n\tif
+    [ \"${cluster_file}\" -nt \"/proc/self/fd/${fd}\" ] && python3 ${PGADMIN_DIR}/setup.py
+    --load-servers /etc/pgadmin/conf.d/~postgres-operator/pgadmin-shared-clusters.json
     --user admin@pgadmin.postgres-operator.svc --replace\n\tthen\n\t\texec {fd}>&-
     && exec {fd}<> <(:)\n\t\tstat --format='Loaded shared servers dated %y' \"${cluster_file}\"\n\tfi\n\tif
     [ ! -d /proc/$(cat $PGADMIN4_PIDFILE) ]\n\tthen\n\t\tpgadmin4 &\n\t\techo $! >
@@ -73,11 +83,27 @@ containers:
         name: pgadmin-
   - name: PGADMIN_LISTEN_PORT
     value: "5050"
+  livenessProbe:
+    failureThreshold: 3
+    httpGet:
+      path: /misc/ping
+      port: pgadmin
+      scheme: HTTP
+    periodSeconds: 20
+    timeoutSeconds: 5
   name: pgadmin
   ports:
   - containerPort: 5050
     name: pgadmin
     protocol: TCP
+  readinessProbe:
+    failureThreshold: 3
+    httpGet:
+      path: /misc/ping
+      port: pgadmin
+      scheme: HTTP
+    periodSeconds: 10
+    timeoutSeconds: 5
   resources: {}
   securityContext:
     allowPrivilegeEscalation: false
@@ -87,6 +113,14 @@ containers:
     privileged: false
     readOnlyRootFilesystem: true
     runAsNonRoot: true
+  startupProbe:
+    failureThreshold: 30
+    httpGet:
+      path: /misc/ping
+      port: pgadmin
+      scheme: HTTP
+    periodSeconds: 10
+    timeoutSeconds: 5
   volumeMounts:
   - mountPath: /etc/pgadmin/conf.d
     name: pgadmin-config
@@ -116,9 +150,28 @@ initContainers:
         _conf, _data = re.compile(r'[A-Z_0-9]+'), json.load(_f)
         if type(_data) is dict:
             globals().update({k: v for k, v in _data.items() if _conf.fullmatch(k)})
+    if os.path.isfile('/etc/pgadmin/conf.d/~postgres-operator/secret-key'):
+        with open('/etc/pgadmin/conf.d/~postgres-operator/secret-key') as _f:
+            SECRET_KEY = _f.read()
+    if os.path.isfile('/etc/pgadmin/conf.d/~postgres-operator/security-password-salt'):
+        with open('/etc/pgadmin/conf.d/~postgres-operator/security-password-salt') as _f:
+            SECURITY_PASSWORD_SALT = _f.read()
     if os.path.isfile('/etc/pgadmin/conf.d/~postgres-operator/ldap-bind-password'):
         with open('/etc/pgadmin/conf.d/~postgres-operator/ldap-bind-password') as _f:
             LDAP_BIND_PASSWORD = _f.read()
+    if os.path.isfile('/etc/pgadmin/conf.d/~postgres-operator/config-database-uri'):
+        with open('/etc/pgadmin/conf.d/~postgres-operator/config-database-uri') as _f:
+            CONFIG_DATABASE_URI = _f.read()
+    if os.path.isfile('/etc/pgadmin/conf.d/~postgres-operator/mail/password'):
+        with open('/etc/pgadmin/conf.d/~postgres-operator/mail/username') as _f:
+            MAIL_USERNAME = _f.read()
+        with open('/etc/pgadmin/conf.d/~postgres-operator/mail/password') as _f:
+            MAIL_PASSWORD = _f.read()
+    for _p in globals().get('OAUTH2_CONFIG') or []:
+        _path = os.path.join('/etc/pgadmin/conf.d/~postgres-operator/oauth2-client-secrets', os.path.basename(str(_p.get('OAUTH2_NAME'))))
+        if os.path.isfile(_path):
+            with open(_path) as _f:
+                _p['OAUTH2_CLIENT_SECRET'] = _f.read()
   name: pgadmin-startup
   resources: {}
   securityContext:
@@ -142,6 +195,14 @@ volumes:
           path: ~postgres-operator/pgadmin-settings.json
         - key: pgadmin-shared-clusters.json
           path: ~postgres-operator/pgadmin-shared-clusters.json
+    - secret:
+        items:
+        - key: secret-key
+          path: ~postgres-operator/secret-key
+        - key: security-password-salt
+          path: ~postgres-operator/security-password-salt
+        name: pgadmin-
+        optional: true
 - name: pgadmin-data
   persistentVolumeClaim:
     claimName: ""
@@ -178,13 +239,22 @@ containers:
   - bash
   - -ceu
   - --
-  - "monitor() {\nPGADMIN_DIR=/usr/local/lib/python3.11/site-packages/pgadmin4\n\necho
-    \"Running pgAdmin4 Setup\"\npython3 ${PGADMIN_DIR}/setup.py\n\necho \"Starting
-    pgAdmin4\"\nPGADMIN4_PIDFILE=/tmp/pgadmin4.pid\npgadmin4 &\necho $! > $PGADMIN4_PIDFILE\n\npython3
-    ${PGADMIN_DIR}/setup.py --load-servers /etc/pgadmin/conf.d/~postgres-operator/pgadmin-shared-clusters.json
-    --user admin@pgadmin.postgres-operator.svc --replace\n\nexec {fd}<> <(:)\nwhile
-    read -r -t 5 -u \"${fd}\" || true; do\n\tif [ \"${cluster_file}\" -nt \"/proc/self/fd/${fd}\"
-    ] && python3 ${PGADMIN_DIR}/setup.py --load-servers /etc/pgadmin/conf.d/~postgres-operator/pgadmin-shared-clusters.json
+  - "monitor() {\nPGADMIN_DIR=/usr/local/lib/python3.11/site-packages/pgadmin4\n\nPGADMIN_DATA=/var/lib/pgadmin\nversion=$(python3
+    -c 'import importlib.metadata as m; print(m.version(\"pgadmin4\"))' || true)\nprevious=$(cat
+    \"${PGADMIN_DATA}/pgadmin-version\" 2> /dev/null || true)\nif [ -n \"${version}\"
+    ] && [ -f \"${PGADMIN_DATA}/pgadmin4.db\" ] && [ \"${version}\" != \"${previous}\"
+    ]\nthen\n\tbackup=\"${PGADMIN_DATA}/pgadmin4.db.${previous:-earlier}-${version}.bak\"\n\tif
+    [ ! -f \"${backup}\" ]\n\tthen\n\t\techo \"Upgrading pgAdmin4 data from ${previous:-an
+    earlier release} to ${version}\"\n\t\tcp --preserve=mode,ownership \"${PGADMIN_DATA}/pgadmin4.db\"
+    \"${backup}.tmp\"\n\t\tmv \"${backup}.tmp\" \"${backup}\"\n\tfi\n\tls -1t \"${PGADMIN_DATA}\"/pgadmin4.db.*.bak
+    | tail -n +4 | xargs --no-run-if-empty rm --\nfi\n\necho \"Running pgAdmin4 Setup\"\npython3
+    ${PGADMIN_DIR}/setup.py\nif [ -n \"${version}\" ]; then echo \"${version}\" >
+    \"${PGADMIN_DATA}/pgadmin-version\"; fi\n\necho \"Starting pgAdmin4\"\nPGADMIN4_PIDFILE=/tmp/pgadmin4.pid\npgadmin4
+    &\necho $! > $PGADMIN4_PIDFILE\n\npython3 ${PGADMIN_DIR}/setup.py --load-servers
+    /etc/pgadmin/conf.d/~postgres-operator/pgadmin-shared-clusters.json --user admin@pgadmin.postgres-operator.svc
+    --replace\n\nexec {fd}<> <(:)\nwhile read -r -t 5 -u \"${fd}\" || true; do\n\tif
+    [ \"${cluster_file}\" -nt \"/proc/self/fd/${fd}\" ] && python3 ${PGADMIN_DIR}/setup.py
+    --load-servers /etc/pgadmin/conf.d/~postgres-operator/pgadmin-shared-clusters.json
     --user admin@pgadmin.postgres-operator.svc --replace\n\tthen\n\t\texec {fd}>&-
     && exec {fd}<> <(:)\n\t\tstat --format='Loaded shared servers dated %y' \"${cluster_file}\"\n\tfi\n\tif
     [ ! -d /proc/$(cat $PGADMIN4_PIDFILE) ]\n\tthen\n\t\tpgadmin4 &\n\t\techo $! >
@@ -204,11 +274,27 @@ containers:
     value: "5050"
   image: new-image
   imagePullPolicy: Always
+  livenessProbe:
+    failureThreshold: 3
+    httpGet:
+      path: /misc/ping
+      port: pgadmin
+      scheme: HTTP
+    periodSeconds: 20
+    timeoutSeconds: 5
   name: pgadmin
   ports:
   - containerPort: 5050
     name: pgadmin
     protocol: TCP
+  readinessProbe:
+    failureThreshold: 3
+    httpGet:
+      path: /misc/ping
+      port: pgadmin
+      scheme: HTTP
+    periodSeconds: 10
+    timeoutSeconds: 5
   resources:
     requests:
       cpu: 100m
@@ -220,6 +306,14 @@ containers:
     privileged: false
     readOnlyRootFilesystem: true
     runAsNonRoot: true
+  startupProbe:
+    failureThreshold: 30
+    httpGet:
+      path: /misc/ping
+      port: pgadmin
+      scheme: HTTP
+    periodSeconds: 10
+    timeoutSeconds: 5
   volumeMounts:
   - mountPath: /etc/pgadmin/conf.d
     name: pgadmin-config
@@ -249,9 +343,28 @@ initContainers:
         _conf, _data = re.compile(r'[A-Z_0-9]+'), json.load(_f)
         if type(_data) is dict:
             globals().update({k: v for k, v in _data.items() if _conf.fullmatch(k)})
+    if os.path.isfile('/etc/pgadmin/conf.d/~postgres-operator/secret-key'):
+        with open('/etc/pgadmin/conf.d/~postgres-operator/secret-key') as _f:
+            SECRET_KEY = _f.read()
+    if os.path.isfile('/etc/pgadmin/conf.d/~postgres-operator/security-password-salt'):
+        with open('/etc/pgadmin/conf.d/~postgres-operator/security-password-salt') as _f:
+            SECURITY_PASSWORD_SALT = _f.read()
     if os.path.isfile('/etc/pgadmin/conf.d/~postgres-operator/ldap-bind-password'):
         with open('/etc/pgadmin/conf.d/~postgres-operator/ldap-bind-password') as _f:
             LDAP_BIND_PASSWORD = _f.read()
+    if os.path.isfile('/etc/pgadmin/conf.d/~postgres-operator/config-database-uri'):
+        with open('/etc/pgadmin/conf.d/~postgres-operator/config-database-uri') as _f:
+            CONFIG_DATABASE_URI = _f.read()
+    if os.path.isfile('/etc/pgadmin/conf.d/~postgres-operator/mail/password'):
+        with open('/etc/pgadmin/conf.d/~postgres-operator/mail/username') as _f:
+            MAIL_USERNAME = _f.read()
+        with open('/etc/pgadmin/conf.d/~postgres-operator/mail/password') as _f:
+            MAIL_PASSWORD = _f.read()
+    for _p in globals().get('OAUTH2_CONFIG') or []:
+        _path = os.path.join('/etc/pgadmin/conf.d/~postgres-operator/oauth2-client-secrets', os.path.basename(str(_p.get('OAUTH2_NAME'))))
+        if os.path.isfile(_path):
+            with open(_path) as _f:
+                _p['OAUTH2_CLIENT_SECRET'] = _f.read()
   image: new-image
   imagePullPolicy: Always
   name: pgadmin-startup
@@ -279,6 +392,14 @@ volumes:
           path: ~postgres-operator/pgadmin-settings.json
         - key: pgadmin-shared-clusters.json
           path: ~postgres-operator/pgadmin-shared-clusters.json
+    - secret:
+        items:
+        - key: secret-key
+          path: ~postgres-operator/secret-key
+        - key: security-password-salt
+          path: ~postgres-operator/security-password-salt
+        name: pgadmin-
+        optional: true
 - name: pgadmin-data
   persistentVolumeClaim:
     claimName: ""
@@ -294,6 +415,175 @@ volumes:
   name: tmp
 `))
 	})
+
+	t.Run("URLPrefix", func(t *testing.T) {
+		pgadmin := pgadmin.DeepCopy()
+		pgadmin.Spec.Config.URLPrefix = "/pgadmin"
+
+		pod(pgadmin, config, testpod, pvc)
+
+		assert.Assert(t, cmp.Contains(testpod.Containers[0].Env, corev1.EnvVar{
+			Name: "SCRIPT_NAME", Value: "/pgadmin",
+		}))
+	})
+
+	t.Run("ExtraPythonPackages", func(t *testing.T) {
+		pgadmin := pgadmin.DeepCopy()
+		pgadmin.Spec.ExtraPythonPackages = []string{"psycopg[binary]==3.1.18", "pgadmin-extension"}
+
+		pod(pgadmin, config, testpod, pvc)
+
+		assert.Equal(t, len(testpod.InitContainers), 2)
+		assert.Assert(t, cmp.MarshalMatches(testpod.InitContainers[1], `
+command:
+- bash
+- -ceu
+- --
+- |-
+  export HOME=/tmp PIP_DISABLE_PIP_VERSION_CHECK=1 PIP_NO_CACHE_DIR=1
+  exec python3 -m pip install --no-input --target=/var/lib/pgadmin-packages -- "$@"
+- packages
+- psycopg[binary]==3.1.18
+- pgadmin-extension
+image: new-image
+imagePullPolicy: Always
+name: pgadmin-packages
+resources:
+  requests:
+    cpu: 100m
+securityContext:
+  allowPrivilegeEscalation: false
+  capabilities:
+    drop:
+    - ALL
+  privileged: false
+  readOnlyRootFilesystem: true
+  runAsNonRoot: true
+volumeMounts:
+- mountPath: /var/lib/pgadmin-packages
+  name: pgadmin-packages
+- mountPath: /tmp
+  name: tmp
+		`))
+
+		assert.Assert(t, cmp.Contains(testpod.Containers[0].Env, corev1.EnvVar{
+			Name: "PYTHONPATH", Value: "/var/lib/pgadmin-packages",
+		}))
+		assert.Assert(t, cmp.Contains(testpod.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name: "pgadmin-packages", MountPath: "/var/lib/pgadmin-packages", ReadOnly: true,
+		}))
+		assert.Equal(t, testpod.Volumes[len(testpod.Volumes)-1].Name, "pgadmin-packages")
+	})
+
+	t.Run("StorageVolume", func(t *testing.T) {
+		pgadmin := pgadmin.DeepCopy()
+		pgadmin.UID = "123"
+		pgadmin.Spec.StorageVolumeClaimSpec = &corev1.PersistentVolumeClaimSpec{}
+
+		pod(pgadmin, config, testpod, pvc)
+
+		assert.Assert(t, cmp.MarshalMatches(testpod.Volumes[len(testpod.Volumes)-1], `
+name: pgadmin-storage
+persistentVolumeClaim:
+  claimName: pgadmin-123-storage
+		`))
+		assert.Assert(t, cmp.Contains(testpod.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name: "pgadmin-storage", MountPath: "/var/lib/pgadmin-storage",
+		}))
+	})
+
+	t.Run("TLS", func(t *testing.T) {
+		pgadmin := pgadmin.DeepCopy()
+		pgadmin.Spec.TLS = &v1beta1.StandalonePGAdminTLS{SecretName: "pgadmin-tls"}
+
+		pod(pgadmin, config, testpod, pvc)
+
+		assert.Assert(t, cmp.Contains(testpod.Containers[0].Env, corev1.EnvVar{
+			Name: "GUNICORN_CMD_ARGS",
+			Value: "--bind=0.0.0.0:5050 --workers=1 --threads=25 --timeout=86400" +
+				" --certfile=/etc/pgadmin/conf.d/~postgres-operator/tls.crt" +
+				" --keyfile=/etc/pgadmin/conf.d/~postgres-operator/tls.key",
+		}))
+
+		script := testpod.Containers[0].Command[3]
+		assert.Assert(t, cmp.Contains(script, `gunicorn --chdir "${PGADMIN_DIR}" pgAdmin4:app &`))
+		assert.Assert(t, !strings.Contains(script, "pgadmin4 &"))
+
+		// gunicorn listens on the address configured for pgAdmin.
+		pgadmin.Spec.Config.Settings = map[string]any{"DEFAULT_SERVER": "::"}
+		pod(pgadmin, config, testpod, pvc)
+
+		assert.Assert(t, cmp.Contains(testpod.Containers[0].Env[len(testpod.Containers[0].Env)-1].Value,
+			"--bind=[::]:5050 "))
+	})
+
+	t.Run("Gunicorn", func(t *testing.T) {
+		pgadmin := pgadmin.DeepCopy()
+		pgadmin.Spec.Gunicorn = &v1beta1.StandalonePGAdminGunicorn{
+			Threads:        initialize.Int32(50),
+			TimeoutSeconds: initialize.Int32(0),
+			AccessLog:      true,
+		}
+
+		pod(pgadmin, config, testpod, pvc)
+
+		// gunicorn serves HTTP without a certificate.
+		assert.Assert(t, cmp.Contains(testpod.Containers[0].Env, corev1.EnvVar{
+			Name:  "GUNICORN_CMD_ARGS",
+			Value: "--bind=0.0.0.0:5050 --workers=1 --threads=50 --timeout=0 --access-logfile=-",
+		}))
+
+		script := testpod.Containers[0].Command[3]
+		assert.Assert(t, cmp.Contains(script, `gunicorn --chdir "${PGADMIN_DIR}" pgAdmin4:app &`))
+		assert.Equal(t, testpod.Containers[0].LivenessProbe.HTTPGet.Scheme, corev1.URISchemeHTTP)
+	})
+
+	t.Run("Probes", func(t *testing.T) {
+		pgadmin := pgadmin.DeepCopy()
+		pgadmin.Spec.Config.URLPrefix = "/pgadmin"
+		pgadmin.Spec.TLS = &v1beta1.StandalonePGAdminTLS{SecretName: "pgadmin-tls"}
+		pgadmin.Spec.Probes = &v1beta1.StandalonePGAdminProbes{
+			Liveness: &v1beta1.StandalonePGAdminProbe{
+				InitialDelaySeconds: initialize.Int32(0),
+				PeriodSeconds:       initialize.Int32(60),
+				FailureThreshold:    initialize.Int32(5),
+			},
+		}
+
+		pod(pgadmin, config, testpod, pvc)
+
+		assert.Assert(t, cmp.MarshalMatches(testpod.Containers[0].LivenessProbe, `
+failureThreshold: 5
+httpGet:
+  path: /pgadmin/misc/ping
+  port: pgadmin
+  scheme: HTTPS
+periodSeconds: 60
+timeoutSeconds: 5
+		`))
+		assert.Equal(t, testpod.Containers[0].ReadinessProbe.PeriodSeconds, int32(10))
+		assert.Equal(t, testpod.Containers[0].StartupProbe.HTTPGet.Scheme, corev1.URISchemeHTTPS)
+	})
+
+	t.Run("Users", func(t *testing.T) {
+		pgadmin := pgadmin.DeepCopy()
+		pgadmin.Spec.Users = []v1beta1.PGAdminUser{{Username: "someone@example.com"}}
+
+		pod(pgadmin, config, testpod, pvc)
+
+		command := testpod.Containers[0].Command
+		assert.Equal(t, len(command), 7)
+		assert.Equal(t, command[6], strings.TrimLeft(usersScript, "\n"))
+
+		// The script replaces the command that loads servers for the admin user.
+		script := command[3]
+		assert.Assert(t, cmp.Contains(script, `python3 -c "${users_script}" ${PGADMIN_DIR}/setup.py`+
+			` /etc/pgadmin/conf.d/~postgres-operator/pgadmin-shared-clusters.json`+
+			` /etc/pgadmin/conf.d/~postgres-operator/pgadmin-users.json`+
+			` admin@pgadmin.postgres-operator.svc`))
+		assert.Assert(t, !strings.Contains(script, "--load-servers"))
+		assert.Assert(t, cmp.Contains(script, `export users_script="$2";`))
+	})
 }
 
 func TestPodConfigFiles(t *testing.T) {
@@ -326,18 +616,152 @@ func TestPodConfigFiles(t *testing.T) {
     - key: pgadmin-shared-clusters.json
       path: ~postgres-operator/pgadmin-shared-clusters.json
     name: some-cm
+- secret:
+    items:
+    - key: secret-key
+      path: ~postgres-operator/secret-key
+    - key: security-password-salt
+      path: ~postgres-operator/security-password-salt
+    name: pgadmin-
+    optional: true
 	`))
+
+	t.Run("OAuth2", func(t *testing.T) {
+		pgadmin := pgadmin.DeepCopy()
+		pgadmin.Spec.Config.OAuth2 = &v1beta1.StandalonePGAdminOAuth2{
+			Providers: []v1beta1.StandalonePGAdminOAuth2Provider{{
+				Name: "github",
+				ClientSecret: corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "oauth"},
+					Key:                  "github-secret",
+				},
+			}},
+		}
+
+		projections := podConfigFiles(configmap, *pgadmin)
+		assert.Assert(t, cmp.MarshalMatches(projections[len(projections)-1], `
+secret:
+  items:
+  - key: github-secret
+    path: ~postgres-operator/oauth2-client-secrets/github
+  name: oauth
+		`))
+	})
+
+	t.Run("Users", func(t *testing.T) {
+		pgadmin := pgadmin.DeepCopy()
+		pgadmin.Spec.Users = []v1beta1.PGAdminUser{{
+			Username: "one@example.com",
+			PasswordRef: corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "users"},
+				Key:                  "one",
+			},
+		}, {
+			Username: "two@example.com",
+			PasswordRef: corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "two"},
+				Key:                  "password",
+			},
+		}}
+
+		projections := podConfigFiles(configmap, *pgadmin)
+		assert.Assert(t, cmp.MarshalMatches(projections[2:], `
+- configMap:
+    items:
+    - key: pgadmin-settings.json
+      path: ~postgres-operator/pgadmin-settings.json
+    - key: pgadmin-shared-clusters.json
+      path: ~postgres-operator/pgadmin-shared-clusters.json
+    - key: pgadmin-users.json
+      path: ~postgres-operator/pgadmin-users.json
+    name: some-cm
+- secret:
+    items:
+    - key: one
+      path: ~postgres-operator/user-passwords/0
+    name: users
+- secret:
+    items:
+    - key: password
+      path: ~postgres-operator/user-passwords/1
+    name: two
+- secret:
+    items:
+    - key: secret-key
+      path: ~postgres-operator/secret-key
+    - key: security-password-salt
+      path: ~postgres-operator/security-password-salt
+    name: pgadmin-
+    optional: true
+		`))
+	})
+
+	t.Run("ConfigDatabaseURI", func(t *testing.T) {
+		pgadmin := pgadmin.DeepCopy()
+		pgadmin.Spec.Config.ConfigDatabaseURI = &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "pgadmin-db"},
+			Key:                  "uri",
+		}
+
+		projections := podConfigFiles(configmap, *pgadmin)
+		assert.Assert(t, cmp.MarshalMatches(projections[len(projections)-1], `
+secret:
+  items:
+  - key: uri
+    path: ~postgres-operator/config-database-uri
+  name: pgadmin-db
+		`))
+	})
+
+	t.Run("Mail", func(t *testing.T) {
+		pgadmin := pgadmin.DeepCopy()
+		before := len(podConfigFiles(configmap, *pgadmin))
+
+		// Nothing is mounted when there are no credentials.
+		pgadmin.Spec.Config.Mail = &v1beta1.StandalonePGAdminMail{
+			Server: "smtp.example.com",
+		}
+		assert.Equal(t, len(podConfigFiles(configmap, *pgadmin)), before)
+
+		pgadmin.Spec.Config.Mail.SecretName = "pgadmin-smtp"
+		projections := podConfigFiles(configmap, *pgadmin)
+		assert.Assert(t, cmp.MarshalMatches(projections[len(projections)-1], `
+secret:
+  items:
+  - key: username
+    path: ~postgres-operator/mail/username
+  - key: password
+    path: ~postgres-operator/mail/password
+  name: pgadmin-smtp
+		`))
+	})
+
+	t.Run("TLS", func(t *testing.T) {
+		pgadmin := pgadmin.DeepCopy()
+		pgadmin.Spec.TLS = &v1beta1.StandalonePGAdminTLS{SecretName: "pgadmin-tls"}
+
+		projections := podConfigFiles(configmap, *pgadmin)
+		assert.Assert(t, cmp.MarshalMatches(projections[len(projections)-1], `
+secret:
+  items:
+  - key: tls.crt
+    path: ~postgres-operator/tls.crt
+  - key: tls.key
+    path: ~postgres-operator/tls.key
+  name: pgadmin-tls
+		`))
+	})
 }
 
 func TestPodSecurityContext(t *testing.T) {
-	pgAdminReconciler := &PGAdminReconciler{}
+	pgadmin := &v1beta1.PGAdmin{}
 
-	assert.Assert(t, cmp.MarshalMatches(podSecurityContext(pgAdminReconciler), `
+	assert.Assert(t, cmp.MarshalMatches(podSecurityContext(pgadmin), `
 fsGroup: 2
 fsGroupChangePolicy: OnRootMismatch
 	`))
 
-	pgAdminReconciler.IsOpenShift = true
-	assert.Assert(t, cmp.MarshalMatches(podSecurityContext(pgAdminReconciler),
+	pgadmin.Spec.OpenShift = initialize.Bool(true)
+	assert.Assert(t, cmp.MarshalMatches(podSecurityContext(pgadmin),
 		`fsGroupChangePolicy: OnRootMismatch`))
 }
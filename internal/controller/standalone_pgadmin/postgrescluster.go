@@ -17,16 +17,20 @@ package standalone_pgadmin
 import (
 	"context"
 
+	"github.com/crunchydata/postgres-operator/internal/dependency"
 	"github.com/crunchydata/postgres-operator/internal/naming"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 //+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="pgadmins",verbs={list}
 
-// findPGAdminsForPostgresCluster returns PGAdmins that target a given cluster.
+// findPGAdminsForPostgresCluster returns PGAdmins that target or depend on a
+// given cluster.
 func (r *PGAdminReconciler) findPGAdminsForPostgresCluster(
 	ctx context.Context, cluster client.Object,
 ) []*v1beta1.PGAdmin {
@@ -43,6 +47,10 @@ func (r *PGAdminReconciler) findPGAdminsForPostgresCluster(
 		Namespace: cluster.GetNamespace(),
 	}) == nil {
 		for i := range pgadmins.Items {
+			if dependency.Depends(pgadmins.Items[i].Spec.DependsOn, cluster.GetName()) {
+				matching = append(matching, &pgadmins.Items[i])
+				continue
+			}
 			for _, serverGroup := range pgadmins.Items[i].Spec.ServerGroups {
 				if selector, err := naming.AsSelector(serverGroup.PostgresClusterSelector); err == nil {
 					if selector.Matches(labels.Set(cluster.GetLabels())) {
@@ -81,3 +89,31 @@ func (r *PGAdminReconciler) getClustersForPGAdmin(
 
 	return matching, err
 }
+
+//+kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={get}
+
+// reconcileDependencies checks the PostgresClusters that pgAdmin depends on
+// and sets the DependenciesSatisfied condition. It returns true when pgAdmin
+// should not be created yet. Once satisfied, dependencies are not checked
+// again.
+func (r *PGAdminReconciler) reconcileDependencies(
+	ctx context.Context, pgAdmin *v1beta1.PGAdmin,
+) (bool, error) {
+	if len(pgAdmin.Spec.DependsOn) == 0 ||
+		meta.IsStatusConditionTrue(pgAdmin.Status.Conditions, v1beta1.DependenciesSatisfied) {
+		return false, nil
+	}
+
+	unmet, err := dependency.Unmet(ctx, r.Client, pgAdmin.Namespace, pgAdmin.Spec.DependsOn)
+	if err != nil {
+		return false, err
+	}
+
+	condition := dependency.Condition(pgAdmin.GetGeneration(), unmet)
+	if len(unmet) > 0 && !meta.IsStatusConditionFalse(pgAdmin.Status.Conditions, v1beta1.DependenciesSatisfied) {
+		r.Recorder.Event(pgAdmin, corev1.EventTypeNormal, condition.Reason, condition.Message)
+	}
+
+	meta.SetStatusCondition(&pgAdmin.Status.Conditions, condition)
+	return len(unmet) > 0, nil
+}
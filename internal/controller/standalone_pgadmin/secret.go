@@ -79,9 +79,11 @@ func secret(pgadmin *v1beta1.PGAdmin, existing *corev1.Secret) (*corev1.Secret,
 	intent.StringData["username"] = fmt.Sprintf("admin@%s.%s.svc",
 		pgadmin.Name, pgadmin.Namespace)
 
-	// Copy existing password into the intent
+	// Copy existing password and keys into the intent
 	if existing.Data != nil {
 		intent.Data["password"] = existing.Data["password"]
+		intent.Data[pgAdminSecretKey] = existing.Data[pgAdminSecretKey]
+		intent.Data[pgAdminSaltKey] = existing.Data[pgAdminSaltKey]
 	}
 
 	// When password is unset, generate a new one
@@ -93,5 +95,31 @@ func secret(pgadmin *v1beta1.PGAdmin, existing *corev1.Secret) (*corev1.Secret,
 		intent.Data["password"] = []byte(password)
 	}
 
+	// pgAdmin signs sessions and CSRF tokens with SECRET_KEY. Keep one here
+	// so that every pgAdmin Pod agrees on it and sessions outlive Pods.
+	// - https://www.pgadmin.org/docs/pgadmin4/latest/config_py.html
+	if len(intent.Data[pgAdminSecretKey]) == 0 {
+		key, err := util.GenerateASCIIPassword(64)
+		if err != nil {
+			return nil, err
+		}
+		intent.Data[pgAdminSecretKey] = []byte(key)
+	}
+
+	// pgAdmin hashes the passwords of its users with SECURITY_PASSWORD_SALT.
+	// Changing the salt of an existing pgAdmin makes those passwords unusable,
+	// so generate one only along with a new Secret. Otherwise, pgAdmin keeps
+	// the salt in its database.
+	if len(intent.Data[pgAdminSaltKey]) == 0 && len(existing.Data) == 0 {
+		salt, err := util.GenerateASCIIPassword(64)
+		if err != nil {
+			return nil, err
+		}
+		intent.Data[pgAdminSaltKey] = []byte(salt)
+	}
+	if len(intent.Data[pgAdminSaltKey]) == 0 {
+		delete(intent.Data, pgAdminSaltKey)
+	}
+
 	return intent, nil
 }
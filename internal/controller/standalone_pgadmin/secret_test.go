@@ -0,0 +1,65 @@
+// Copyright 2023 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standalone_pgadmin
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestSecret(t *testing.T) {
+	pgadmin := new(v1beta1.PGAdmin)
+	pgadmin.Namespace, pgadmin.Name = "ns1", "pgadmin"
+
+	t.Run("New", func(t *testing.T) {
+		intent, err := secret(pgadmin, new(corev1.Secret))
+		assert.NilError(t, err)
+
+		assert.Equal(t, intent.StringData["username"], "admin@pgadmin.ns1.svc")
+		assert.Assert(t, len(intent.Data["password"]) > 0)
+		assert.Equal(t, len(intent.Data[pgAdminSecretKey]), 64)
+		assert.Equal(t, len(intent.Data[pgAdminSaltKey]), 64)
+	})
+
+	t.Run("Existing", func(t *testing.T) {
+		existing := new(corev1.Secret)
+		existing.Data = map[string][]byte{
+			"password":       []byte("pass"),
+			pgAdminSecretKey: []byte("key"),
+			pgAdminSaltKey:   []byte("salt"),
+		}
+
+		intent, err := secret(pgadmin, existing)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, intent.Data, existing.Data)
+	})
+
+	t.Run("BeforeKeys", func(t *testing.T) {
+		existing := new(corev1.Secret)
+		existing.Data = map[string][]byte{"password": []byte("pass")}
+
+		// The salt of an existing pgAdmin stays in its database.
+		intent, err := secret(pgadmin, existing)
+		assert.NilError(t, err)
+		assert.Equal(t, string(intent.Data["password"]), "pass")
+		assert.Equal(t, len(intent.Data[pgAdminSecretKey]), 64)
+		_, ok := intent.Data[pgAdminSaltKey]
+		assert.Assert(t, !ok)
+	})
+}
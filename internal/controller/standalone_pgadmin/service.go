@@ -0,0 +1,142 @@
+// Copyright 2023 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standalone_pgadmin
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/pkg/errors"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// +kubebuilder:rbac:groups="",resources="services",verbs={get,list}
+// +kubebuilder:rbac:groups="",resources="services",verbs={create,delete,patch}
+
+// reconcilePGAdminService writes the Service that resolves to pgAdmin. Changes
+// to its name delete the Service with the previous name; other changes are
+// applied to the existing Service.
+func (r *PGAdminReconciler) reconcilePGAdminService(
+	ctx context.Context, pgadmin *v1beta1.PGAdmin,
+) (*corev1.Service, error) {
+	service := service(pgadmin)
+
+	// The NodePort can only be set when the Service type is NodePort or
+	// LoadBalancer. Log an Event and return an error rather than let the
+	// apply below clear it.
+	if spec := pgadmin.Spec.Service; spec != nil && spec.NodePort != nil &&
+		service.Spec.Type == corev1.ServiceTypeClusterIP {
+		r.Recorder.Eventf(pgadmin, corev1.EventTypeWarning, "MisconfiguredClusterIP",
+			"NodePort cannot be set with type ClusterIP on Service %q", service.Name)
+		return nil, fmt.Errorf("NodePort cannot be set with type ClusterIP on Service %q", service.Name)
+	}
+
+	// Do not take over a Service that something else controls, such as one
+	// named by spec.serviceName before this PGAdmin existed.
+	existing := &corev1.Service{ObjectMeta: service.ObjectMeta}
+	err := errors.WithStack(client.IgnoreNotFound(
+		r.Client.Get(ctx, client.ObjectKeyFromObject(existing), existing)))
+	if err == nil && existing.UID != "" && !metav1.IsControlledBy(existing, pgadmin) {
+		r.Recorder.Eventf(pgadmin, corev1.EventTypeWarning, "DuplicateServiceName",
+			"Service %q exists and is not controlled by this PGAdmin", service.Name)
+		return nil, fmt.Errorf("service %q exists and is not controlled by this PGAdmin", service.Name)
+	}
+
+	// Delete any Service written for a previous spec.serviceName.
+	services := &corev1.ServiceList{}
+	if err == nil {
+		err = errors.WithStack(r.Client.List(ctx, services,
+			client.InNamespace(pgadmin.Namespace),
+			client.MatchingLabels{naming.LabelStandalonePGAdmin: pgadmin.Name}))
+	}
+	for i := range services.Items {
+		if err == nil && services.Items[i].Name != service.Name {
+			err = errors.WithStack(client.IgnoreNotFound(
+				r.deleteControlled(ctx, pgadmin, &services.Items[i])))
+		}
+	}
+
+	if err == nil {
+		err = errors.WithStack(r.setControllerReference(pgadmin, service))
+	}
+	if err == nil {
+		err = errors.WithStack(r.apply(ctx, service))
+	}
+	return service, err
+}
+
+// service defines the Service that resolves to the pgAdmin Pods of either its
+// StatefulSet or its Deployment.
+func service(pgadmin *v1beta1.PGAdmin) *corev1.Service {
+	service := &corev1.Service{ObjectMeta: naming.StandalonePGAdmin(pgadmin)}
+	service.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("Service"))
+
+	if pgadmin.Spec.ServiceName != "" {
+		service.Name = pgadmin.Spec.ServiceName
+	}
+
+	service.Annotations = pgadmin.Spec.Metadata.GetAnnotationsOrNil()
+	service.Labels = pgadmin.Spec.Metadata.GetLabelsOrNil()
+
+	// Default to a service type of ClusterIP
+	service.Spec.Type = corev1.ServiceTypeClusterIP
+
+	if spec := pgadmin.Spec.Service; spec != nil {
+		service.Annotations = naming.Merge(service.Annotations,
+			spec.Metadata.GetAnnotationsOrNil())
+		service.Labels = naming.Merge(service.Labels,
+			spec.Metadata.GetLabelsOrNil())
+
+		service.Spec.Type = corev1.ServiceType(spec.Type)
+		if service.Spec.Type == corev1.ServiceTypeLoadBalancer {
+			service.Spec.LoadBalancerSourceRanges = spec.LoadBalancerSourceRanges
+		}
+	}
+
+	// add our labels last so they aren't overwritten
+	service.Labels = naming.Merge(service.Labels,
+		naming.StandalonePGAdminCommonLabels(pgadmin))
+
+	// Allocate an IP address and let Kubernetes manage the Endpoints by
+	// selecting Pods with the pgAdmin role.
+	// - https://docs.k8s.io/concepts/services-networking/service/#defining-a-service
+	service.Spec.Selector = map[string]string{
+		naming.LabelStandalonePGAdmin: pgadmin.Name,
+		naming.LabelRole:              naming.RolePGAdmin,
+	}
+
+	// The TargetPort must be the name (not the number) of the pgAdmin
+	// ContainerPort. This name allows the port number to differ between Pods,
+	// which can happen during a rolling update.
+	service.Spec.Ports = []corev1.ServicePort{{
+		Name:       naming.PortPGAdmin,
+		Port:       pgAdminPort,
+		Protocol:   corev1.ProtocolTCP,
+		TargetPort: intstr.FromString(naming.PortPGAdmin),
+	}}
+	if spec := pgadmin.Spec.Service; spec != nil && spec.NodePort != nil &&
+		service.Spec.Type != corev1.ServiceTypeClusterIP {
+		service.Spec.Ports[0].NodePort = *spec.NodePort
+	}
+
+	return service
+}
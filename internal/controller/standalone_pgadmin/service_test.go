@@ -0,0 +1,162 @@
+// Copyright 2023 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package standalone_pgadmin
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/internal/testing/events"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestService(t *testing.T) {
+	pgadmin := new(v1beta1.PGAdmin)
+	pgadmin.Namespace, pgadmin.Name = "ns1", "test-standalone-pgadmin"
+	pgadmin.Spec.Metadata = &v1beta1.Metadata{
+		Annotations: map[string]string{"a": "v1"},
+		Labels:      map[string]string{"b": "v2"},
+	}
+
+	t.Run("Defaults", func(t *testing.T) {
+		service := service(pgadmin)
+
+		assert.Equal(t, service.Name, "pgadmin-")
+		assert.Assert(t, cmp.MarshalMatches(service.ObjectMeta, `
+annotations:
+  a: v1
+creationTimestamp: null
+labels:
+  b: v2
+  postgres-operator.crunchydata.com/data: pgadmin
+  postgres-operator.crunchydata.com/pgadmin: test-standalone-pgadmin
+  postgres-operator.crunchydata.com/role: pgadmin
+name: pgadmin-
+namespace: ns1
+		`))
+		assert.Assert(t, cmp.MarshalMatches(service.Spec, `
+ports:
+- name: pgadmin
+  port: 5050
+  protocol: TCP
+  targetPort: pgadmin
+selector:
+  postgres-operator.crunchydata.com/pgadmin: test-standalone-pgadmin
+  postgres-operator.crunchydata.com/role: pgadmin
+type: ClusterIP
+		`))
+	})
+
+	t.Run("Customizations", func(t *testing.T) {
+		pgadmin := pgadmin.DeepCopy()
+		pgadmin.Spec.ServiceName = "some-name"
+		pgadmin.Spec.Service = &v1beta1.StandalonePGAdminService{
+			ServiceSpec: v1beta1.ServiceSpec{
+				Metadata: &v1beta1.Metadata{
+					Annotations: map[string]string{"a": "v3", "c": "v4"},
+					Labels: map[string]string{
+						"d":                                      "v5",
+						"postgres-operator.crunchydata.com/role": "nope",
+					},
+				},
+				NodePort: initialize.Int32(30050),
+				Type:     "LoadBalancer",
+			},
+			LoadBalancerSourceRanges: []string{"203.0.113.0/24"},
+		}
+
+		service := service(pgadmin)
+
+		assert.Equal(t, service.Name, "some-name")
+		assert.Assert(t, cmp.MarshalMatches(service.ObjectMeta, `
+annotations:
+  a: v3
+  c: v4
+creationTimestamp: null
+labels:
+  b: v2
+  d: v5
+  postgres-operator.crunchydata.com/data: pgadmin
+  postgres-operator.crunchydata.com/pgadmin: test-standalone-pgadmin
+  postgres-operator.crunchydata.com/role: pgadmin
+name: some-name
+namespace: ns1
+		`))
+		assert.Assert(t, cmp.MarshalMatches(service.Spec, `
+loadBalancerSourceRanges:
+- 203.0.113.0/24
+ports:
+- name: pgadmin
+  nodePort: 30050
+  port: 5050
+  protocol: TCP
+  targetPort: pgadmin
+selector:
+  postgres-operator.crunchydata.com/pgadmin: test-standalone-pgadmin
+  postgres-operator.crunchydata.com/role: pgadmin
+type: LoadBalancer
+		`))
+
+	})
+
+	t.Run("NodePort", func(t *testing.T) {
+		pgadmin := pgadmin.DeepCopy()
+		pgadmin.Spec.Service = &v1beta1.StandalonePGAdminService{
+			ServiceSpec: v1beta1.ServiceSpec{
+				NodePort: initialize.Int32(30050),
+				Type:     "NodePort",
+			},
+			LoadBalancerSourceRanges: []string{"203.0.113.0/24"},
+		}
+
+		// The source ranges apply only to load balancers.
+		service := service(pgadmin)
+		assert.Equal(t, service.Spec.Type, corev1.ServiceTypeNodePort)
+		assert.Assert(t, service.Spec.LoadBalancerSourceRanges == nil)
+		assert.Equal(t, service.Spec.Ports[0].NodePort, int32(30050))
+	})
+}
+
+func TestReconcilePGAdminService(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	recorder := events.NewRecorder(t, scheme)
+	reconciler := &PGAdminReconciler{Recorder: recorder}
+
+	t.Run("NodePortWithClusterIP", func(t *testing.T) {
+		pgadmin := new(v1beta1.PGAdmin)
+		pgadmin.Namespace, pgadmin.Name = "ns1", "test-standalone-pgadmin"
+		pgadmin.Spec.Service = &v1beta1.StandalonePGAdminService{
+			ServiceSpec: v1beta1.ServiceSpec{
+				NodePort: initialize.Int32(30050),
+				Type:     "ClusterIP",
+			},
+		}
+
+		_, err := reconciler.reconcilePGAdminService(ctx, pgadmin)
+		assert.ErrorContains(t, err, "NodePort cannot be set with type ClusterIP")
+
+		assert.Equal(t, len(recorder.Events), 1)
+		assert.Equal(t, recorder.Events[0].Reason, "MisconfiguredClusterIP")
+	})
+}
@@ -35,6 +35,19 @@ func (r *PGAdminReconciler) reconcilePGAdminStatefulSet(
 	ctx context.Context, pgadmin *v1beta1.PGAdmin,
 	configmap *corev1.ConfigMap, dataVolume *corev1.PersistentVolumeClaim,
 ) error {
+	// pgAdmin runs in a Deployment when it keeps its configuration in another
+	// database. Remove any StatefulSet that it ran in before.
+	if pgadmin.Spec.Config.ConfigDatabaseURI != nil {
+		existing := &appsv1.StatefulSet{ObjectMeta: naming.StandalonePGAdmin(pgadmin)}
+		err := errors.WithStack(client.IgnoreNotFound(
+			r.Client.Get(ctx, client.ObjectKeyFromObject(existing), existing)))
+		if err == nil {
+			err = errors.WithStack(client.IgnoreNotFound(
+				r.deleteControlled(ctx, pgadmin, existing)))
+		}
+		return err
+	}
+
 	sts := statefulset(r, pgadmin, configmap, dataVolume)
 
 	// Previous versions of PGO used a StatefulSet Pod Management Policy that could leave the Pod
@@ -87,11 +100,6 @@ func statefulset(
 			naming.LabelRole:              naming.RolePGAdmin,
 		},
 	}
-	sts.Spec.Template.Annotations = pgadmin.Spec.Metadata.GetAnnotationsOrNil()
-	sts.Spec.Template.Labels = naming.Merge(
-		pgadmin.Spec.Metadata.GetLabelsOrNil(),
-		naming.StandalonePGAdminCommonLabels(pgadmin),
-	)
 
 	// Don't clutter the namespace with extra ControllerRevisions.
 	sts.Spec.RevisionHistoryLimit = initialize.Int32(0)
@@ -104,31 +112,47 @@ func statefulset(
 	sts.Spec.PodManagementPolicy = appsv1.ParallelPodManagement
 	sts.Spec.UpdateStrategy.Type = appsv1.RollingUpdateStatefulSetStrategyType
 
+	podTemplate(pgadmin, configmap, &sts.Spec.Template, dataVolume)
+
+	return sts
+}
+
+// podTemplate populates template with the metadata and Pod that run pgAdmin.
+func podTemplate(
+	pgadmin *v1beta1.PGAdmin,
+	configmap *corev1.ConfigMap,
+	template *corev1.PodTemplateSpec,
+	dataVolume *corev1.PersistentVolumeClaim,
+) {
+	template.Annotations = pgadmin.Spec.Metadata.GetAnnotationsOrNil()
+	template.Labels = naming.Merge(
+		pgadmin.Spec.Metadata.GetLabelsOrNil(),
+		naming.StandalonePGAdminCommonLabels(pgadmin),
+	)
+
 	// Use scheduling constraints from the cluster spec.
-	sts.Spec.Template.Spec.Affinity = pgadmin.Spec.Affinity
-	sts.Spec.Template.Spec.Tolerations = pgadmin.Spec.Tolerations
+	template.Spec.Affinity = pgadmin.Spec.Affinity
+	template.Spec.Tolerations = pgadmin.Spec.Tolerations
 
 	if pgadmin.Spec.PriorityClassName != nil {
-		sts.Spec.Template.Spec.PriorityClassName = *pgadmin.Spec.PriorityClassName
+		template.Spec.PriorityClassName = *pgadmin.Spec.PriorityClassName
 	}
 
 	// Restart containers any time they stop, die, are killed, etc.
 	// - https://docs.k8s.io/concepts/workloads/pods/pod-lifecycle/#restart-policy
-	sts.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyAlways
+	template.Spec.RestartPolicy = corev1.RestartPolicyAlways
 
 	// pgAdmin does not make any Kubernetes API calls. Use the default
 	// ServiceAccount and do not mount its credentials.
-	sts.Spec.Template.Spec.AutomountServiceAccountToken = initialize.Bool(false)
+	template.Spec.AutomountServiceAccountToken = initialize.Bool(false)
 
 	// Do not add environment variables describing services in this namespace.
-	sts.Spec.Template.Spec.EnableServiceLinks = initialize.Bool(false)
+	template.Spec.EnableServiceLinks = initialize.Bool(false)
 
 	// set the image pull secrets, if any exist
-	sts.Spec.Template.Spec.ImagePullSecrets = pgadmin.Spec.ImagePullSecrets
+	template.Spec.ImagePullSecrets = pgadmin.Spec.ImagePullSecrets
 
-	sts.Spec.Template.Spec.SecurityContext = podSecurityContext(r)
+	template.Spec.SecurityContext = podSecurityContext(pgadmin)
 
-	pod(pgadmin, configmap, &sts.Spec.Template.Spec, dataVolume)
-
-	return sts
+	pod(pgadmin, configmap, &template.Spec, dataVolume)
 }
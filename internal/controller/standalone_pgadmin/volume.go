@@ -70,6 +70,46 @@ func pvc(pgadmin *v1beta1.PGAdmin) *corev1.PersistentVolumeClaim {
 	return pvc
 }
 
+// reconcilePGAdminStorageVolume writes the PersistentVolumeClaim for the files
+// of pgAdmin users, when there is one.
+func (r *PGAdminReconciler) reconcilePGAdminStorageVolume(
+	ctx context.Context, pgadmin *v1beta1.PGAdmin,
+) error {
+	if pgadmin.Spec.StorageVolumeClaimSpec == nil {
+		return nil
+	}
+
+	pvc := storagePVC(pgadmin)
+
+	err := errors.WithStack(r.setControllerReference(pgadmin, pvc))
+
+	if err == nil {
+		err = r.handlePersistentVolumeClaimError(pgadmin,
+			errors.WithStack(r.apply(ctx, pvc)))
+	}
+
+	return err
+}
+
+// storagePVC defines the volume for the files of pgAdmin users.
+func storagePVC(pgadmin *v1beta1.PGAdmin) *corev1.PersistentVolumeClaim {
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: naming.StandalonePGAdminStorage(pgadmin)}
+	pvc.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim"))
+
+	pvc.Annotations = pgadmin.Spec.Metadata.GetAnnotationsOrNil()
+	pvc.Labels = naming.Merge(
+		pgadmin.Spec.Metadata.GetLabelsOrNil(),
+		map[string]string{
+			naming.LabelStandalonePGAdmin: pgadmin.Name,
+			naming.LabelRole:              naming.RolePGAdmin,
+			naming.LabelData:              naming.DataPGAdminStorage,
+		},
+	)
+	pvc.Spec = *pgadmin.Spec.StorageVolumeClaimSpec
+
+	return pvc
+}
+
 // handlePersistentVolumeClaimError inspects err for expected Kubernetes API
 // responses to writing a PVC. It turns errors it understands into conditions
 // and events. When err is handled it returns nil. Otherwise it returns err.
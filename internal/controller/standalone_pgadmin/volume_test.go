@@ -88,6 +88,24 @@ storageClassName: storage-class-for-data
 volumeMode: Filesystem
 		`))
 	})
+
+	t.Run("StorageVolume", func(t *testing.T) {
+		pgadmin := pgadmin.DeepCopy()
+
+		// Nothing is written without a spec.
+		assert.NilError(t, reconciler.reconcilePGAdminStorageVolume(ctx, pgadmin))
+		assert.Assert(t, apierrors.IsNotFound(cc.Get(ctx,
+			client.ObjectKeyFromObject(storagePVC(pgadmin)), &corev1.PersistentVolumeClaim{})))
+
+		pgadmin.Spec.StorageVolumeClaimSpec = pgadmin.Spec.DataVolumeClaimSpec.DeepCopy()
+		assert.NilError(t, reconciler.reconcilePGAdminStorageVolume(ctx, pgadmin))
+
+		pvc := &corev1.PersistentVolumeClaim{}
+		assert.NilError(t, cc.Get(ctx, client.ObjectKeyFromObject(storagePVC(pgadmin)), pvc))
+		assert.Assert(t, metav1.IsControlledBy(pvc, pgadmin))
+		assert.Equal(t, pvc.Labels[naming.LabelData], naming.DataPGAdminStorage)
+		assert.Equal(t, pvc.Spec.Resources.Requests.Storage().String(), "1Gi")
+	})
 }
 
 func TestHandlePersistentVolumeClaimError(t *testing.T) {
@@ -0,0 +1,242 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package dashboard serves a read-only web page that summarizes every
+// PostgresCluster the operator manages. It reads from the cache of the
+// operator, so it adds no load to the Kubernetes API and needs no permissions
+// beyond those of the operator. It shows no credentials, but it does show
+// names and locations, so it listens on the loopback interface unless told
+// otherwise. Reach it with "kubectl port-forward".
+package dashboard
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"html/template"
+	"net"
+	"net/http"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// Server serves the dashboard.
+type Server struct {
+	Client client.Reader
+
+	// Address is the TCP address on which to listen, such as ":8080". The
+	// dashboard listens on the loopback interface when Address has no host.
+	Address string
+}
+
+// ManagedDashboard creates a [Server] and adds it to m.
+func ManagedDashboard(m manager.Manager, address string) error {
+	return m.Add(&Server{Client: m.GetClient(), Address: address})
+}
+
+// NeedLeaderElection returns false so that every [manager.Manager] serves
+// the dashboard. Each reads from its own cache.
+func (s *Server) NeedLeaderElection() bool { return false }
+
+// listenAddress returns address with the loopback interface in place of an
+// empty host.
+func listenAddress(address string) string {
+	if host, port, err := net.SplitHostPort(address); err == nil && host == "" {
+		return net.JoinHostPort("localhost", port)
+	}
+	return address
+}
+
+// Start serves requests until ctx is cancelled.
+func (s *Server) Start(ctx context.Context) error {
+	address := listenAddress(s.Address)
+	server := &http.Server{
+		Addr:              address,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(_ net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdown)
+	}()
+
+	logging.FromContext(ctx).Info("serving the dashboard", "address", address)
+
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// ServeHTTP renders the dashboard.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	rows, err := s.summaries(r.Context())
+	if err != nil {
+		logging.FromContext(r.Context()).Error(err, "summarizing PostgresClusters")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = page.Execute(w, map[string]any{"Now": time.Now(), "Clusters": rows})
+}
+
+// +kubebuilder:rbac:groups="",resources="pods",verbs={list}
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={get}
+// +kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={list}
+
+// summaries returns a summary of every PostgresCluster ordered by namespace
+// and name.
+func (s *Server) summaries(ctx context.Context) ([]summary, error) {
+	clusters := &v1beta1.PostgresClusterList{}
+	if err := s.Client.List(ctx, clusters); err != nil {
+		return nil, err
+	}
+
+	rows := make([]summary, 0, len(clusters.Items))
+	for i := range clusters.Items {
+		cluster := &clusters.Items[i]
+
+		pods := &corev1.PodList{}
+		selector, err := naming.AsSelector(naming.ClusterInstances(cluster.Name))
+		if err == nil {
+			err = s.Client.List(ctx, pods,
+				client.InNamespace(cluster.Namespace),
+				client.MatchingLabelsSelector{Selector: selector})
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		rows = append(rows, summarize(cluster, pods.Items, s.certificate(ctx, cluster)))
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].Namespace != rows[j].Namespace {
+			return rows[i].Namespace < rows[j].Namespace
+		}
+		return rows[i].Name < rows[j].Name
+	})
+	return rows, nil
+}
+
+// certificate returns the server certificate of cluster, or nil when it
+// cannot be read.
+func (s *Server) certificate(ctx context.Context, cluster *v1beta1.PostgresCluster) *x509.Certificate {
+	secret := &corev1.Secret{ObjectMeta: naming.PostgresTLSSecret(cluster)}
+	key := "tls.crt"
+
+	if custom := cluster.Spec.CustomTLSSecret; custom != nil {
+		secret.Name = custom.Name
+		for _, item := range custom.Items {
+			if item.Path == "tls.crt" {
+				key = item.Key
+			}
+		}
+	}
+
+	if s.Client.Get(ctx, client.ObjectKeyFromObject(secret), secret) != nil {
+		return nil
+	}
+	return parseCertificate(secret.Data[key])
+}
+
+var page = template.Must(template.New("dashboard").Funcs(template.FuncMap{
+	"age": func(now time.Time, t *time.Time) string {
+		if t == nil {
+			return "never"
+		}
+		return duration.HumanDuration(now.Sub(*t))
+	},
+	"until": func(now time.Time, t *time.Time) string {
+		if t == nil {
+			return "unknown"
+		}
+		if t.Before(now) {
+			return "expired"
+		}
+		return duration.HumanDuration(t.Sub(now))
+	},
+	"bytes": func(n int64) string {
+		if n < 0 {
+			return "unknown"
+		}
+		return resource.NewQuantity(n, resource.BinarySI).String()
+	},
+	"timestamp": func(t time.Time) string { return t.UTC().Format(time.RFC3339) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="30">
+<title>PostgresClusters</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; width: 100%; }
+th, td { border-bottom: 1px solid #ddd; padding: 0.4em 0.8em; text-align: left; }
+th { background: #f4f4f4; }
+.warn { color: #b00; font-weight: bold; }
+</style>
+</head>
+<body>
+<h1>PostgresClusters</h1>
+<p>{{ len .Clusters }} clusters as of {{ timestamp .Now }}</p>
+<table>
+<tr>
+<th>Namespace</th><th>Name</th><th>Version</th><th>Ready</th>
+<th>Primary</th><th>Node</th><th>Replication lag</th>
+<th>Last backup</th><th>Certificate expires in</th><th>Pending restarts</th>
+</tr>
+{{- $now := .Now }}
+{{- range .Clusters }}
+<tr>
+<td>{{ .Namespace }}</td>
+<td>{{ .Name }}</td>
+<td>{{ .PostgresVersion }}</td>
+<td{{ if lt .Ready .Instances }} class="warn"{{ end }}>{{ .Ready }}/{{ .Instances }}</td>
+<td{{ if not .Primary }} class="warn"{{ end }}>{{ or .Primary "none" }}</td>
+<td>{{ .PrimaryNode }}</td>
+<td>{{ bytes .ReplicationLag }}</td>
+<td{{ if not .LastBackup }} class="warn"{{ end }}>{{ age $now .LastBackup }}</td>
+<td>{{ until $now .CertificateExpiry }}</td>
+<td{{ if .PendingRestarts }} class="warn"{{ end }}>{{ .PendingRestarts }}</td>
+</tr>
+{{- end }}
+</table>
+</body>
+</html>
+`))
@@ -0,0 +1,131 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/pki"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestListenAddress(t *testing.T) {
+	assert.Equal(t, listenAddress(":8080"), "localhost:8080")
+	assert.Equal(t, listenAddress("0.0.0.0:8080"), "0.0.0.0:8080")
+	assert.Equal(t, listenAddress("[::]:8080"), "[::]:8080")
+	assert.Equal(t, listenAddress("10.0.0.1:8080"), "10.0.0.1:8080")
+}
+
+func TestSummarize(t *testing.T) {
+	cluster := v1beta1.NewPostgresCluster()
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+	cluster.Spec.PostgresVersion = 16
+
+	t.Run("Empty", func(t *testing.T) {
+		row := summarize(cluster, nil, nil)
+		assert.Equal(t, row.Name, "hippo")
+		assert.Equal(t, row.PostgresVersion, 16)
+		assert.Equal(t, row.Primary, "")
+		assert.Equal(t, row.ReplicationLag, int64(-1))
+		assert.Assert(t, row.LastBackup == nil)
+		assert.Assert(t, row.CertificateExpiry == nil)
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		earlier := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+		later := metav1.NewTime(earlier.Add(time.Hour))
+		failed := metav1.NewTime(earlier.Add(2 * time.Hour))
+
+		cluster := cluster.DeepCopy()
+		cluster.Status.InstanceSets = []v1beta1.PostgresInstanceSetStatus{
+			{Name: "00", Replicas: 3, ReadyReplicas: 2},
+		}
+		cluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{
+			ManualBackup: &v1beta1.PGBackRestJobStatus{Succeeded: 1, CompletionTime: &earlier},
+			ScheduledBackups: []v1beta1.PGBackRestScheduledBackupStatus{
+				{Succeeded: 1, CompletionTime: &later},
+				{Failed: 1, CompletionTime: &failed},
+			},
+		}
+
+		pods := []corev1.Pod{{}, {}, {}}
+		pods[0].Name = "primary"
+		pods[0].Labels = map[string]string{naming.LabelRole: naming.RolePatroniLeader}
+		pods[0].Spec.NodeName = "node1"
+		pods[0].Annotations = map[string]string{"status": `{"xlog_location":1000}`}
+		pods[1].Name = "replica1"
+		pods[1].Annotations = map[string]string{"status": `{"xlog_location":900,"pending_restart":true}`}
+		pods[2].Name = "replica2"
+		pods[2].Annotations = map[string]string{"status": `{"xlog_location":990}`}
+
+		expiry := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		row := summarize(cluster, pods, &x509.Certificate{NotAfter: expiry})
+		assert.Equal(t, row.Ready, int32(2))
+		assert.Equal(t, row.Instances, int32(3))
+		assert.Equal(t, row.Primary, "primary")
+		assert.Equal(t, row.PrimaryNode, "node1")
+		assert.Equal(t, row.ReplicationLag, int64(100))
+		assert.Equal(t, row.PendingRestarts, 1)
+		assert.Equal(t, *row.LastBackup, later.Time)
+		assert.Equal(t, *row.CertificateExpiry, expiry)
+	})
+}
+
+func TestServeHTTP(t *testing.T) {
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	root, err := pki.NewRootCertificateAuthority()
+	assert.NilError(t, err)
+	certificate, err := root.Certificate.MarshalText()
+	assert.NilError(t, err)
+
+	cluster := v1beta1.NewPostgresCluster()
+	cluster.Namespace, cluster.Name = "ns1", "hippo"
+	cluster.Spec.PostgresVersion = 16
+
+	secret := &corev1.Secret{ObjectMeta: naming.PostgresTLSSecret(cluster)}
+	secret.Data = map[string][]byte{"tls.crt": certificate}
+
+	server := &Server{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, secret).Build(),
+	}
+
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, recorder.Code, http.StatusOK)
+
+	body := recorder.Body.String()
+	assert.Assert(t, strings.Contains(body, "<td>hippo</td>"), body)
+	assert.Assert(t, !strings.Contains(body, "<td>unknown</td>\n<td>0</td>"),
+		"expected the certificate expiry, got\n%s", body)
+
+	recorder = httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/other", nil))
+	assert.Equal(t, recorder.Code, http.StatusNotFound)
+}
@@ -0,0 +1,138 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dashboard
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/patroni"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// summary is one row of the dashboard.
+type summary struct {
+	Namespace, Name string
+	PostgresVersion int
+
+	// Primary is the name of the primary Pod and PrimaryNode is the Node it
+	// runs on. Both are empty when there is no primary.
+	Primary, PrimaryNode string
+
+	// Ready and Instances count the PostgreSQL instances of the cluster.
+	Ready, Instances int32
+
+	// ReplicationLag is the most bytes of WAL that any replica is behind the
+	// primary, according to Patroni. It is negative when that is not known.
+	ReplicationLag int64
+
+	// LastBackup is when the most recent backup completed, if any.
+	LastBackup *time.Time
+
+	// CertificateExpiry is when the server certificate expires, if known.
+	CertificateExpiry *time.Time
+
+	// PendingRestarts counts instances with parameter changes that take
+	// effect only after PostgreSQL restarts.
+	PendingRestarts int
+}
+
+// summarize describes cluster using the Pods of its instances and its server
+// certificate, which may be nil.
+func summarize(
+	cluster *v1beta1.PostgresCluster, pods []corev1.Pod, certificate *x509.Certificate,
+) summary {
+	row := summary{
+		Namespace:       cluster.Namespace,
+		Name:            cluster.Name,
+		PostgresVersion: cluster.Spec.PostgresVersion,
+		ReplicationLag:  -1,
+	}
+
+	for _, set := range cluster.Status.InstanceSets {
+		row.Ready += set.ReadyReplicas
+		row.Instances += set.Replicas
+	}
+
+	// Patroni reports the WAL position of each member in an annotation.
+	positions := make(map[string]int64, len(pods))
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Labels[naming.LabelRole] == naming.RolePatroniLeader {
+			row.Primary, row.PrimaryNode = pod.Name, pod.Spec.NodeName
+		}
+		if patroni.PodRequiresRestart(pod) {
+			row.PendingRestarts++
+		}
+
+		var status struct {
+			XLogLocation *int64 `json:"xlog_location"`
+		}
+		if json.Unmarshal([]byte(pod.Annotations["status"]), &status) == nil &&
+			status.XLogLocation != nil {
+			positions[pod.Name] = *status.XLogLocation
+		}
+	}
+
+	if primary, known := positions[row.Primary]; known {
+		row.ReplicationLag = 0
+		for name, position := range positions {
+			if name != row.Primary && primary-position > row.ReplicationLag {
+				row.ReplicationLag = primary - position
+			}
+		}
+	}
+
+	if status := cluster.Status.PGBackRest; status != nil {
+		latest := func(t *metav1.Time) {
+			if t != nil && (row.LastBackup == nil || t.Time.After(*row.LastBackup)) {
+				row.LastBackup = &t.Time
+			}
+		}
+		if status.ManualBackup != nil && status.ManualBackup.Succeeded > 0 {
+			latest(status.ManualBackup.CompletionTime)
+		}
+		for _, backup := range status.ScheduledBackups {
+			if backup.Succeeded > 0 {
+				latest(backup.CompletionTime)
+			}
+		}
+	}
+
+	if certificate != nil {
+		row.CertificateExpiry = &certificate.NotAfter
+	}
+
+	return row
+}
+
+// parseCertificate returns the first certificate in PEM-encoded data.
+func parseCertificate(data []byte) *x509.Certificate {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil
+	}
+	certificate, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil
+	}
+	return certificate
+}
@@ -0,0 +1,145 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package dependency checks the PostgresClusters that other resources wait
+// for before they are created.
+package dependency
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	Ready           = "Ready"
+	BackupCompleted = "BackupCompleted"
+)
+
+// Unmet returns a description of each dependency in namespace that has not
+// reached its condition. A PostgresCluster that does not exist is unmet.
+func Unmet(
+	ctx context.Context, reader client.Reader, namespace string,
+	dependencies []v1beta1.Dependency,
+) ([]string, error) {
+	var unmet []string
+	for _, dependency := range dependencies {
+		condition := dependency.Condition
+		if condition == "" {
+			condition = Ready
+		}
+
+		cluster := &v1beta1.PostgresCluster{}
+		err := reader.Get(ctx,
+			client.ObjectKey{Namespace: namespace, Name: dependency.ClusterName}, cluster)
+
+		switch {
+		case apierrors.IsNotFound(err):
+			unmet = append(unmet, fmt.Sprintf("PostgresCluster %q does not exist", dependency.ClusterName))
+		case err != nil:
+			return nil, errors.WithStack(err)
+		case condition == Ready && !IsReady(cluster):
+			unmet = append(unmet, fmt.Sprintf("PostgresCluster %q is not ready", dependency.ClusterName))
+		case condition == BackupCompleted && !HasCompletedBackup(cluster):
+			unmet = append(unmet, fmt.Sprintf("PostgresCluster %q has no completed backup", dependency.ClusterName))
+		}
+	}
+	return unmet, nil
+}
+
+// Depends reports whether any of dependencies names the PostgresCluster called
+// name. Use it to find what to reconcile when that cluster changes.
+func Depends(dependencies []v1beta1.Dependency, name string) bool {
+	for _, dependency := range dependencies {
+		if dependency.ClusterName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// IsReady reports whether PostgreSQL is initialized in cluster and every
+// instance it specifies is ready.
+func IsReady(cluster *v1beta1.PostgresCluster) bool {
+	if cluster.Status.Patroni.SystemIdentifier == "" ||
+		(cluster.Spec.Shutdown != nil && *cluster.Spec.Shutdown) {
+		return false
+	}
+
+	ready := make(map[string]int32, len(cluster.Status.InstanceSets))
+	for _, status := range cluster.Status.InstanceSets {
+		ready[status.Name] = status.ReadyReplicas
+	}
+	for _, set := range cluster.Spec.InstanceSets {
+		replicas := int32(1)
+		if set.Replicas != nil {
+			replicas = *set.Replicas
+		}
+		if ready[set.Name] < replicas {
+			return false
+		}
+	}
+	return true
+}
+
+// HasCompletedBackup reports whether any manual, scheduled, or replica-create
+// backup of cluster has completed.
+func HasCompletedBackup(cluster *v1beta1.PostgresCluster) bool {
+	// This is the condition the PostgresCluster controller sets when the backup
+	// that new replicas are created from is complete.
+	if meta.IsStatusConditionTrue(cluster.Status.Conditions, "PGBackRestReplicaCreate") {
+		return true
+	}
+
+	status := cluster.Status.PGBackRest
+	if status == nil {
+		return false
+	}
+	if status.ManualBackup != nil && status.ManualBackup.Succeeded > 0 {
+		return true
+	}
+	for _, backup := range status.ScheduledBackups {
+		if backup.Succeeded > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Condition returns a DependenciesSatisfied condition for the unmet
+// dependencies of an object at generation.
+func Condition(generation int64, unmet []string) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               v1beta1.DependenciesSatisfied,
+		ObservedGeneration: generation,
+		Status:             metav1.ConditionTrue,
+		Reason:             "DependenciesSatisfied",
+		Message:            "Dependencies reached their conditions",
+	}
+	if len(unmet) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "WaitingForDependencies"
+		condition.Message = "Waiting because " + strings.Join(unmet, ", ")
+	}
+	return condition
+}
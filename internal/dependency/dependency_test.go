@@ -0,0 +1,113 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package dependency
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestIsReady(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+		{Name: "a"}, {Name: "b", Replicas: initialize.Int32(2)},
+	}
+	assert.Assert(t, !IsReady(cluster), "expected uninitialized")
+
+	cluster.Status.Patroni.SystemIdentifier = "12345"
+	cluster.Status.InstanceSets = []v1beta1.PostgresInstanceSetStatus{
+		{Name: "a", ReadyReplicas: 1}, {Name: "b", ReadyReplicas: 1},
+	}
+	assert.Assert(t, !IsReady(cluster), "expected a replica of b to be unready")
+
+	cluster.Status.InstanceSets[1].ReadyReplicas = 2
+	assert.Assert(t, IsReady(cluster))
+
+	cluster.Spec.Shutdown = initialize.Bool(true)
+	assert.Assert(t, !IsReady(cluster), "expected shutdown")
+}
+
+func TestHasCompletedBackup(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	assert.Assert(t, !HasCompletedBackup(cluster))
+
+	cluster.Status.PGBackRest = &v1beta1.PGBackRestStatus{
+		ScheduledBackups: []v1beta1.PGBackRestScheduledBackupStatus{{Failed: 1}},
+	}
+	assert.Assert(t, !HasCompletedBackup(cluster))
+
+	cluster.Status.PGBackRest.ManualBackup = &v1beta1.PGBackRestJobStatus{Succeeded: 1}
+	assert.Assert(t, HasCompletedBackup(cluster))
+
+	cluster.Status.PGBackRest = nil
+	cluster.Status.Conditions = []metav1.Condition{{
+		Type: "PGBackRestReplicaCreate", Status: metav1.ConditionTrue,
+	}}
+	assert.Assert(t, HasCompletedBackup(cluster))
+}
+
+func TestUnmet(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	ready := &v1beta1.PostgresCluster{}
+	ready.Namespace, ready.Name = "ns1", "ready"
+	ready.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{Name: "00"}}
+	ready.Status.Patroni.SystemIdentifier = "12345"
+	ready.Status.InstanceSets = []v1beta1.PostgresInstanceSetStatus{{Name: "00", ReadyReplicas: 1}}
+
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ready).Build()
+
+	unmet, err := Unmet(ctx, reader, "ns1", nil)
+	assert.NilError(t, err)
+	assert.Assert(t, len(unmet) == 0)
+
+	unmet, err = Unmet(ctx, reader, "ns1", []v1beta1.Dependency{
+		{ClusterName: "ready"},
+		{ClusterName: "ready", Condition: BackupCompleted},
+		{ClusterName: "missing", Condition: Ready},
+	})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, unmet, []string{
+		`PostgresCluster "ready" has no completed backup`,
+		`PostgresCluster "missing" does not exist`,
+	})
+
+	unmet, err = Unmet(ctx, reader, "ns2", []v1beta1.Dependency{{ClusterName: "ready"}})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, unmet, []string{`PostgresCluster "ready" does not exist`})
+}
+
+func TestCondition(t *testing.T) {
+	condition := Condition(3, nil)
+	assert.Equal(t, condition.Type, v1beta1.DependenciesSatisfied)
+	assert.Equal(t, condition.Status, metav1.ConditionTrue)
+	assert.Equal(t, condition.ObservedGeneration, int64(3))
+
+	condition = Condition(4, []string{"one", "two"})
+	assert.Equal(t, condition.Status, metav1.ConditionFalse)
+	assert.Equal(t, condition.Reason, "WaitingForDependencies")
+	assert.Equal(t, condition.Message, "Waiting because one, two")
+}
@@ -0,0 +1,172 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package healthprobe
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	// User is the PostgreSQL role that runs health probes. It authenticates
+	// with a password so that it can connect through PgBouncer. It owns a
+	// schema of the same name in the "postgres" database.
+	User = "_crunchyhealthprobe"
+
+	// defaultMaxReplicaLagSeconds is how long a replica may take to replay the
+	// heartbeat when the cluster does not say otherwise.
+	defaultMaxReplicaLagSeconds = 30
+)
+
+// Enabled returns whether or not health probes are requested for cluster.
+func Enabled(cluster *v1beta1.PostgresCluster) bool {
+	return cluster.Spec.HealthProbes != nil
+}
+
+// PostgreSQLHBAs provides the HBA rules that allow health probes to connect.
+func PostgreSQLHBAs(cluster *v1beta1.PostgresCluster, outHBAs *postgres.HBAs) {
+	if Enabled(cluster) {
+		// Only password authentication over TLS is allowed for this user. The
+		// "md5" method accepts SCRAM-SHA-256 verifiers, too.
+		outHBAs.Mandatory = append(outHBAs.Mandatory,
+			*postgres.NewHBA().TLS().User(User).Method("md5"),
+			*postgres.NewHBA().TCP().User(User).Method("reject"),
+		)
+	}
+}
+
+// DisableInPostgreSQL removes the health probe user and its heartbeat table.
+func DisableInPostgreSQL(ctx context.Context, exec postgres.Executor) error {
+	log := logging.FromContext(ctx)
+
+	stdout, stderr, err := exec.ExecInDatabasesFromQuery(ctx,
+		`SELECT pg_catalog.current_database()`,
+		strings.Join([]string{
+			// Quiet NOTICE messages from IF EXISTS statements.
+			// - https://www.postgresql.org/docs/current/runtime-config-client.html
+			`SET client_min_messages = WARNING;`,
+
+			`BEGIN;`,
+			`DROP SCHEMA IF EXISTS :"username" CASCADE;`,
+			strings.TrimSpace(`
+SELECT pg_catalog.format('DROP OWNED BY %I CASCADE', :'username')
+ WHERE EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = :'username')
+\gexec`),
+			`DROP ROLE IF EXISTS :"username";`,
+			`COMMIT;`,
+		}, "\n"),
+		map[string]string{
+			"username": User,
+
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("removed health probe user", "stdout", stdout, "stderr", stderr)
+
+	return err
+}
+
+// EnableInPostgreSQL creates the health probe user with password verifier and
+// the heartbeat table that it writes.
+func EnableInPostgreSQL(ctx context.Context, exec postgres.Executor, verifier string) error {
+	log := logging.FromContext(ctx)
+
+	stdout, stderr, err := exec.ExecInDatabasesFromQuery(ctx,
+		`SELECT pg_catalog.current_database()`,
+		strings.Join([]string{
+			// Quiet NOTICE messages from IF NOT EXISTS statements.
+			// - https://www.postgresql.org/docs/current/runtime-config-client.html
+			`SET client_min_messages = WARNING;`,
+
+			// Create the following objects in a transaction so that permissions
+			// are correct before any other session sees them.
+			`BEGIN;`,
+
+			// Create the user if it does not already exist. It cannot login
+			// until the end of the transaction.
+			strings.TrimSpace(`
+SELECT pg_catalog.format('CREATE ROLE %I NOLOGIN', :'username')
+ WHERE NOT EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = :'username')
+\gexec`),
+
+			`CREATE SCHEMA IF NOT EXISTS :"username" AUTHORIZATION :"username";`,
+			strings.TrimSpace(`
+CREATE TABLE IF NOT EXISTS :"username".heartbeat (
+  id integer PRIMARY KEY, written timestamptz NOT NULL);`),
+			`ALTER TABLE :"username".heartbeat OWNER TO :"username";`,
+
+			// Remove "public" from the user's search_path.
+			// - https://www.postgresql.org/docs/current/perm-functions.html
+			`ALTER ROLE :"username" SET search_path TO :'username';`,
+			`ALTER ROLE :"username" LOGIN PASSWORD :'verifier';`,
+
+			// Commit (finish) the transaction.
+			`COMMIT;`,
+		}, "\n"),
+		map[string]string{
+			"username": User,
+			"verifier": verifier,
+
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("enabled health probes", "stdout", stdout, "stderr", stderr)
+
+	return err
+}
+
+// probeScript writes a heartbeat through the PRIMARY connection, reads it
+// back, and waits for a replica to replay it through the REPLICA connection,
+// if any. Both connections are libpq connection strings.
+const probeScript = `
+declare -r max_lag="$1"
+query() { psql -Xq --tuples-only --no-align --dbname="$1" --command="$2"; }
+
+written=$(query "${PRIMARY}" 'INSERT INTO heartbeat (id, written)
+  VALUES (1, pg_catalog.clock_timestamp())
+  ON CONFLICT (id) DO UPDATE SET written = excluded.written RETURNING written')
+got=$(query "${PRIMARY}" 'SELECT written FROM heartbeat WHERE id = 1')
+
+if [[ -z "${written}" || "${got}" != "${written}" ]]; then
+  echo "wrote '${written}' but read '${got}'"; exit 1
+fi
+echo "wrote and read ${written}"
+
+[[ -z "${REPLICA:-}" ]] && exit 0
+for (( i = 0; i < max_lag; i++ )); do
+  fresh=$(query "${REPLICA}" "SELECT written >= '${written}' FROM heartbeat WHERE id = 1" || true)
+  [[ "${fresh}" == 't' ]] && { echo "replica replayed ${written}"; exit 0; }
+  sleep 1
+done
+echo "no replica replayed ${written} within ${max_lag} seconds"; exit 1
+`
+
+// Command returns the command that probes cluster.
+func Command(cluster *v1beta1.PostgresCluster) []string {
+	maxLag := int32(defaultMaxReplicaLagSeconds)
+	if spec := cluster.Spec.HealthProbes; spec.MaxReplicaLagSeconds != nil {
+		maxLag = *spec.MaxReplicaLagSeconds
+	}
+
+	return []string{"bash", "-ceu", "--", probeScript, "health-probe", fmt.Sprint(maxLag)}
+}
@@ -0,0 +1,110 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package healthprobe
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestEnabled(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	assert.Assert(t, !Enabled(cluster))
+
+	cluster.Spec.HealthProbes = new(v1beta1.HealthProbesSpec)
+	assert.Assert(t, Enabled(cluster))
+}
+
+func TestPostgreSQLHBAs(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+
+	hbas := postgres.HBAs{}
+	PostgreSQLHBAs(cluster, &hbas)
+	assert.Equal(t, len(hbas.Mandatory), 0)
+
+	cluster.Spec.HealthProbes = new(v1beta1.HealthProbesSpec)
+	PostgreSQLHBAs(cluster, &hbas)
+	assert.Equal(t, len(hbas.Mandatory), 2)
+	assert.Equal(t, hbas.Mandatory[0].String(), `hostssl all "_crunchyhealthprobe" all md5`)
+	assert.Equal(t, hbas.Mandatory[1].String(), `host all "_crunchyhealthprobe" all reject`)
+}
+
+func TestDisableInPostgreSQL(t *testing.T) {
+	expected := errors.New("whoops")
+	exec := func(
+		_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		assert.Assert(t, stdout != nil, "should capture stdout")
+		assert.Assert(t, stderr != nil, "should capture stderr")
+		assert.Assert(t, strings.Contains(strings.Join(command, "\n"),
+			`--set=username=_crunchyhealthprobe`))
+
+		b, err := io.ReadAll(stdin)
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(string(b), `DROP SCHEMA IF EXISTS :"username" CASCADE;`))
+		assert.Assert(t, cmp.Contains(string(b), `DROP ROLE IF EXISTS :"username";`))
+
+		return expected
+	}
+
+	ctx := context.Background()
+	assert.Equal(t, expected, DisableInPostgreSQL(ctx, exec))
+}
+
+func TestEnableInPostgreSQL(t *testing.T) {
+	expected := errors.New("whoops")
+	exec := func(
+		_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		assert.Assert(t, strings.Contains(strings.Join(command, "\n"),
+			`--set=verifier=SCRAM-SHA-256$secret`))
+
+		b, err := io.ReadAll(stdin)
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(string(b), `CREATE TABLE IF NOT EXISTS :"username".heartbeat`))
+		assert.Assert(t, cmp.Contains(string(b), `ALTER ROLE :"username" LOGIN PASSWORD :'verifier';`))
+
+		return expected
+	}
+
+	ctx := context.Background()
+	assert.Equal(t, expected, EnableInPostgreSQL(ctx, exec, "SCRAM-SHA-256$secret"))
+}
+
+func TestCommand(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.HealthProbes = new(v1beta1.HealthProbesSpec)
+
+	command := Command(cluster)
+	assert.Equal(t, command[0], "bash")
+	assert.Equal(t, command[len(command)-1], "30")
+	assert.Assert(t, cmp.Contains(command[3], `ON CONFLICT (id) DO UPDATE`))
+	assert.Assert(t, cmp.Contains(command[3], `"${REPLICA}"`))
+
+	cluster.Spec.HealthProbes.MaxReplicaLagSeconds = initialize.Int32(5)
+	command = Command(cluster)
+	assert.Equal(t, command[len(command)-1], "5")
+}
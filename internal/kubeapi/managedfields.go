@@ -0,0 +1,89 @@
+package kubeapi
+
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"bytes"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/structured-merge-diff/v4/fieldpath"
+)
+
+// UpgradeManagedFields returns a JSON Patch that moves the fields manager set
+// with update operations, such as merge patches, into the fields it applies
+// with server-side apply. Server-side apply removes fields that manager no
+// longer applies only when they are not owned by any other entry, including
+// its own update entries. It returns nil when there is nothing to move.
+// - https://docs.k8s.io/reference/using-api/server-side-apply/#upgrading-from-client-side-apply-to-server-side-apply
+func UpgradeManagedFields(object metav1.Object, manager string) (*JSON6902, error) {
+	entries := object.GetManagedFields()
+	applied := -1
+	for i := range entries {
+		if entries[i].Manager == manager && entries[i].Subresource == "" &&
+			entries[i].Operation == metav1.ManagedFieldsOperationApply {
+			applied = i
+		}
+	}
+	if applied < 0 {
+		return nil, nil
+	}
+
+	read := func(entry metav1.ManagedFieldsEntry) (*fieldpath.Set, error) {
+		set := &fieldpath.Set{}
+		if entry.FieldsV1 == nil {
+			return set, nil
+		}
+		return set, set.FromJSON(bytes.NewReader(entry.FieldsV1.Raw))
+	}
+
+	fields, err := read(entries[applied])
+	if err != nil {
+		return nil, err
+	}
+
+	var upgraded []metav1.ManagedFieldsEntry
+	for i := range entries {
+		if entries[i].Manager == manager && entries[i].Subresource == "" &&
+			entries[i].Operation == metav1.ManagedFieldsOperationUpdate {
+			updated, err := read(entries[i])
+			if err != nil {
+				return nil, err
+			}
+			fields = fields.Union(updated)
+			continue
+		}
+		upgraded = append(upgraded, entries[i])
+	}
+	if len(upgraded) == len(entries) {
+		return nil, nil
+	}
+
+	raw, err := fields.ToJSON()
+	if err != nil {
+		return nil, err
+	}
+	for i := range upgraded {
+		if upgraded[i].Manager == manager && upgraded[i].Subresource == "" &&
+			upgraded[i].Operation == metav1.ManagedFieldsOperationApply {
+			upgraded[i].FieldsV1 = &metav1.FieldsV1{Raw: raw}
+		}
+	}
+
+	// Replace the entries only when the object has not changed since it was read.
+	return NewJSONPatch().
+		Test("metadata", "resourceVersion")(object.GetResourceVersion()).
+		Replace("metadata", "managedFields")(upgraded), nil
+}
@@ -0,0 +1,87 @@
+package kubeapi
+
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpgradeManagedFields(t *testing.T) {
+	t.Parallel()
+
+	entry := func(manager string, operation metav1.ManagedFieldsOperationType, fields string) metav1.ManagedFieldsEntry {
+		return metav1.ManagedFieldsEntry{
+			Manager: manager, Operation: operation,
+			FieldsType: "FieldsV1", FieldsV1: &metav1.FieldsV1{Raw: []byte(fields)},
+		}
+	}
+
+	// Nothing to do without an apply entry.
+	{
+		object := &metav1.ObjectMeta{ManagedFields: []metav1.ManagedFieldsEntry{
+			entry("pgo", metav1.ManagedFieldsOperationUpdate, `{"f:spec":{"f:replicas":{}}}`),
+		}}
+		patch, err := UpgradeManagedFields(object, "pgo")
+		if err != nil || patch != nil {
+			t.Fatalf("expected nothing, got %v, %v", patch, err)
+		}
+	}
+
+	// Nothing to do without update entries of the manager.
+	{
+		object := &metav1.ObjectMeta{ManagedFields: []metav1.ManagedFieldsEntry{
+			entry("pgo", metav1.ManagedFieldsOperationApply, `{"f:spec":{"f:template":{}}}`),
+			entry("other", metav1.ManagedFieldsOperationUpdate, `{"f:spec":{"f:replicas":{}}}`),
+		}}
+		patch, err := UpgradeManagedFields(object, "pgo")
+		if err != nil || patch != nil {
+			t.Fatalf("expected nothing, got %v, %v", patch, err)
+		}
+	}
+
+	// Update entries of the manager are merged into its apply entry.
+	{
+		object := &metav1.ObjectMeta{ResourceVersion: "9", ManagedFields: []metav1.ManagedFieldsEntry{
+			entry("pgo", metav1.ManagedFieldsOperationApply, `{"f:spec":{"f:template":{}}}`),
+			entry("other", metav1.ManagedFieldsOperationUpdate, `{"f:status":{}}`),
+			entry("pgo", metav1.ManagedFieldsOperationUpdate, `{"f:spec":{"f:replicas":{}}}`),
+		}}
+		patch, err := UpgradeManagedFields(object, "pgo")
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+
+		b, err := patch.Bytes()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		assertJSON(t, `[
+			{"op":"test","path":"/metadata/resourceVersion","value":"9"},
+			{"op":"replace","path":"/metadata/managedFields","value":[
+				{"manager":"pgo","operation":"Apply","fieldsType":"FieldsV1",
+				 "fieldsV1":{"f:spec":{"f:replicas":{},"f:template":{}}}},
+				{"manager":"other","operation":"Update","fieldsType":"FieldsV1",
+				 "fieldsV1":{"f:status":{}}}
+			]}
+		]`, b)
+
+		if len(object.ManagedFields) != 3 {
+			t.Fatal("expected object to be unchanged")
+		}
+	}
+}
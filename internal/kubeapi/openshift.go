@@ -0,0 +1,84 @@
+package kubeapi
+
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+)
+
+// OpenShift assigns each namespace a range of user IDs and a range of groups
+// that its SecurityContextConstraints allow. These annotations hold them.
+// - https://docs.openshift.com/container-platform/4.14/authentication/managing-security-context-constraints.html
+const (
+	openShiftGroupRange = "openshift.io/sa.scc.supplemental-groups"
+	openShiftUIDRange   = "openshift.io/sa.scc.uid-range"
+)
+
+// OpenShiftRanges are the user IDs and groups OpenShift assigned to a
+// namespace, such as "1000650000/10000".
+type OpenShiftRanges struct {
+	Groups string
+	UIDs   string
+}
+
+// +kubebuilder:rbac:groups="",resources="namespaces",verbs={get}
+
+// DetectOpenShiftRanges reads the ranges OpenShift assigned to namespace. It
+// returns nil when the namespace has none, which means Pods there are not
+// subject to SecurityContextConstraints.
+func DetectOpenShiftRanges(
+	ctx context.Context, reader client.Reader, namespace string,
+) (*OpenShiftRanges, error) {
+	object := &corev1.Namespace{}
+	if err := reader.Get(ctx, client.ObjectKey{Name: namespace}, object); err != nil {
+		return nil, err
+	}
+
+	uids, ok := object.Annotations[openShiftUIDRange]
+	if !ok {
+		return nil, nil
+	}
+	return &OpenShiftRanges{
+		Groups: object.Annotations[openShiftGroupRange],
+		UIDs:   uids,
+	}, nil
+}
+
+// IsOpenShiftNamespace returns whether Pods in namespace are subject to
+// SecurityContextConstraints. One operator can manage namespaces that are and
+// are not, so this is decided for each namespace. It returns fallback when
+// the namespace cannot be read, such as when the operator is limited to
+// namespaces.
+func IsOpenShiftNamespace(
+	ctx context.Context, reader client.Reader, namespace string, fallback bool,
+) bool {
+	ranges, err := DetectOpenShiftRanges(ctx, reader, namespace)
+	if err != nil {
+		logging.FromContext(ctx).V(1).Info("unable to read namespace",
+			"namespace", namespace, "error", err.Error())
+		return fallback
+	}
+	if ranges != nil {
+		logging.FromContext(ctx).V(1).Info("detected OpenShift namespace",
+			"namespace", namespace, "uids", ranges.UIDs, "groups", ranges.Groups)
+	}
+	return ranges != nil
+}
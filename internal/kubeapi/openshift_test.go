@@ -0,0 +1,74 @@
+package kubeapi
+
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+      http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+import (
+	"context"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDetectOpenShiftRanges(t *testing.T) {
+	ctx := context.Background()
+	reader := fake.NewClientBuilder().WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "vanilla"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name: "project",
+			Annotations: map[string]string{
+				"openshift.io/sa.scc.supplemental-groups": "1000650000/10000",
+				"openshift.io/sa.scc.uid-range":           "1000650000/10000",
+			},
+		}},
+	).Build()
+
+	ranges, err := DetectOpenShiftRanges(ctx, reader, "vanilla")
+	assert.NilError(t, err)
+	assert.Assert(t, ranges == nil)
+
+	ranges, err = DetectOpenShiftRanges(ctx, reader, "project")
+	assert.NilError(t, err)
+	assert.DeepEqual(t, ranges, &OpenShiftRanges{
+		Groups: "1000650000/10000",
+		UIDs:   "1000650000/10000",
+	})
+
+	_, err = DetectOpenShiftRanges(ctx, reader, "missing")
+	assert.Assert(t, apierrors.IsNotFound(err))
+}
+
+func TestIsOpenShiftNamespace(t *testing.T) {
+	ctx := context.Background()
+	reader := fake.NewClientBuilder().WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "vanilla"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+			Name: "project",
+			Annotations: map[string]string{
+				"openshift.io/sa.scc.uid-range": "1000650000/10000",
+			},
+		}},
+	).Build()
+
+	assert.Assert(t, !IsOpenShiftNamespace(ctx, reader, "vanilla", true))
+	assert.Assert(t, IsOpenShiftNamespace(ctx, reader, "project", false))
+
+	assert.Assert(t, IsOpenShiftNamespace(ctx, reader, "missing", true))
+	assert.Assert(t, !IsOpenShiftNamespace(ctx, reader, "missing", false))
+}
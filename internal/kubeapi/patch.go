@@ -113,6 +113,28 @@ func (patch *JSON6902) Replace(path ...string) func(value interface{}) *JSON6902
 	return f
 }
 
+// Test appends a "test" operation to patch.
+//
+// > The "test" operation tests that a value at the target location is
+// > equal to a specified value.
+// >
+// > If the test fails, then the patch as a whole MUST NOT be applied.
+func (patch *JSON6902) Test(path ...string) func(value interface{}) *JSON6902 {
+	i := len(*patch)
+	f := func(value interface{}) *JSON6902 {
+		(*patch)[i] = map[string]interface{}{
+			"op":    "test",
+			"path":  patch.pointer(path...),
+			"value": value,
+		}
+		return patch
+	}
+
+	*patch = append(*patch, f)
+
+	return f
+}
+
 // Bytes returns the JSON representation of patch.
 func (patch JSON6902) Bytes() ([]byte, error) { return patch.Data(nil) }
 
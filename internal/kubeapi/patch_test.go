@@ -132,6 +132,23 @@ func TestJSON6902(t *testing.T) {
 		assertJSON(t, `[{"op":"replace","path":"/metadata/labels/some~1thing","value":"5"}]`, b)
 	}
 
+	// Calling Test without its value is an error.
+	{
+		patch := NewJSONPatch()
+		patch.Test("a")
+		_, err := patch.Bytes()
+		if err == nil {
+			t.Fatal("expected an error, got none")
+		}
+	}
+	{
+		b, err := NewJSONPatch().Test("metadata", "resourceVersion")("12").Bytes()
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		assertJSON(t, `[{"op":"test","path":"/metadata/resourceVersion","value":"12"}]`, b)
+	}
+
 	// Calls are chainable.
 	{
 		b, err := NewJSONPatch().
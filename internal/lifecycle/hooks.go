@@ -0,0 +1,150 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package lifecycle calls hooks at points in the life of a PostgresCluster so
+// that other systems, such as an inventory, DNS, or billing, can follow it
+// without changes to the operator.
+package lifecycle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Event is a point in the life of a PostgresCluster.
+type Event string
+
+const (
+	// PreProvision is before anything is created for a new cluster. An error
+	// from a hook keeps the cluster from being created until it succeeds.
+	PreProvision Event = "PreProvision"
+
+	// PostReady is after PostgreSQL of a cluster first accepts connections.
+	PostReady Event = "PostReady"
+
+	// PreDelete is before anything of a deleted cluster is removed. An error
+	// from a hook keeps the cluster from being removed until it succeeds.
+	PreDelete Event = "PreDelete"
+
+	// PostFailover is after a different instance becomes the primary.
+	PostFailover Event = "PostFailover"
+)
+
+// Request describes an Event to a Hook.
+type Request struct {
+	Event Event `json:"event"`
+
+	// The cluster that the event is about.
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	UID       types.UID `json:"uid"`
+
+	// The instance that is the primary, if any, and the instance that was the
+	// primary before a failover.
+	Primary         string `json:"primary,omitempty"`
+	PreviousPrimary string `json:"previousPrimary,omitempty"`
+
+	Time time.Time `json:"time"`
+}
+
+// Hook is called at lifecycle events. Hooks may be called more than once for
+// the same event, so they should be idempotent.
+type Hook interface {
+	Call(ctx context.Context, request Request) error
+}
+
+// HookFunc is a Hook that is a function.
+type HookFunc func(ctx context.Context, request Request) error
+
+func (fn HookFunc) Call(ctx context.Context, request Request) error { return fn(ctx, request) }
+
+// Hooks is a Hook that calls other hooks in order. It stops at the first
+// error.
+type Hooks []Hook
+
+func (hooks Hooks) Call(ctx context.Context, request Request) error {
+	for _, hook := range hooks {
+		if err := hook.Call(ctx, request); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var (
+	registeredMutex sync.Mutex
+	registered      Hooks
+)
+
+// Register adds hook to those returned by [Registered]. Integrations that are
+// compiled into the operator call this from their init function.
+func Register(hook Hook) {
+	registeredMutex.Lock()
+	defer registeredMutex.Unlock()
+	registered = append(registered, hook)
+}
+
+// Registered returns the hooks passed to [Register].
+func Registered() Hooks {
+	registeredMutex.Lock()
+	defer registeredMutex.Unlock()
+	return append(Hooks(nil), registered...)
+}
+
+// Webhook is a Hook that sends each Request as JSON in an HTTP POST to URL.
+// Any response other than 2xx is an error.
+type Webhook struct {
+	URL    string
+	Client *http.Client
+}
+
+func (w Webhook) Call(ctx context.Context, request Request) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := w.Client
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		message, _ := io.ReadAll(io.LimitReader(res.Body, 256))
+		return errors.Errorf("%s hook at %s returned %s: %s",
+			request.Event, w.URL, res.Status, bytes.TrimSpace(message))
+	}
+	return nil
+}
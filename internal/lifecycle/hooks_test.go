@@ -0,0 +1,88 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package lifecycle
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func TestHooks(t *testing.T) {
+	ctx := context.Background()
+
+	var called []string
+	hooks := Hooks{
+		HookFunc(func(_ context.Context, r Request) error {
+			called = append(called, "one:"+string(r.Event))
+			return nil
+		}),
+		HookFunc(func(context.Context, Request) error {
+			called = append(called, "two")
+			return errors.New("boom")
+		}),
+		HookFunc(func(context.Context, Request) error {
+			called = append(called, "three")
+			return nil
+		}),
+	}
+
+	assert.ErrorContains(t, hooks.Call(ctx, Request{Event: PostReady}), "boom")
+	assert.DeepEqual(t, called, []string{"one:PostReady", "two"})
+}
+
+func TestRegister(t *testing.T) {
+	before := Registered()
+	t.Cleanup(func() { registered = before })
+
+	Register(HookFunc(func(context.Context, Request) error { return nil }))
+	assert.Equal(t, len(Registered()), len(before)+1)
+}
+
+func TestWebhook(t *testing.T) {
+	ctx := context.Background()
+
+	var received Request
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, r.Method, http.MethodPost)
+		assert.Equal(t, r.Header.Get("Content-Type"), "application/json")
+		assert.NilError(t, json.NewDecoder(r.Body).Decode(&received))
+
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte("nope\n"))
+	}))
+	t.Cleanup(server.Close)
+
+	hook := Webhook{URL: server.URL}
+	assert.NilError(t, hook.Call(ctx, Request{
+		Event: PostFailover, Namespace: "ns1", Name: "hippo",
+		Primary: "hippo-00-abcd", PreviousPrimary: "hippo-00-efgh",
+	}))
+	assert.Equal(t, received.Event, PostFailover)
+	assert.Equal(t, received.Name, "hippo")
+	assert.Equal(t, received.PreviousPrimary, "hippo-00-efgh")
+
+	status = http.StatusServiceUnavailable
+	err := hook.Call(ctx, Request{Event: PreDelete})
+	assert.ErrorContains(t, err, "PreDelete hook")
+	assert.ErrorContains(t, err, "503 Service Unavailable: nope")
+}
@@ -0,0 +1,245 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// Schema is the PostgreSQL schema in which the pgstattuple extension is
+// installed when it is not installed already.
+const Schema = "pgstattuple"
+
+// Enabled returns whether or not scheduled maintenance is requested for cluster.
+func Enabled(cluster *v1beta1.PostgresCluster) bool {
+	return cluster.Spec.Maintenance != nil
+}
+
+// databases returns a query for the databases of cluster that are maintained.
+// It expects a "databases" variable that is a JSON array of names; an empty
+// array means every database that allows connections.
+const databases = `SELECT datname FROM pg_catalog.pg_database` +
+	` WHERE datallowconn AND NOT datistemplate AND (` +
+	`:'databases' = '[]' OR datname IN (` +
+	`SELECT value FROM pg_catalog.json_array_elements_text(:'databases')))`
+
+// DisableInPostgreSQL revokes the privileges that EnableInPostgreSQL grants
+// the maintenance user. The extensions are left in place. Memberships in
+// table owners are removed with the user.
+func DisableInPostgreSQL(ctx context.Context, exec postgres.Executor) error {
+	log := logging.FromContext(ctx)
+
+	stdout, stderr, err := exec.ExecInDatabasesFromQuery(ctx,
+		`SELECT pg_catalog.current_database()`,
+		strings.TrimSpace(`
+SELECT pg_catalog.format('REVOKE pg_stat_scan_tables FROM %I', :'username')
+ WHERE EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = :'username')
+\gexec`),
+		map[string]string{
+			"username": postgres.MaintenanceUser,
+
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("removed maintenance privileges", "stdout", stdout, "stderr", stderr)
+
+	return err
+}
+
+// EnableInPostgreSQL installs the extensions that maintenance of cluster needs
+// into each of its maintained databases. The maintenance user can estimate
+// the bloat of every table and becomes a member of each table owner so that
+// it can rewrite their tables. Tables owned by superusers are skipped. Call
+// this again to include owners of tables that are created later.
+// - https://www.postgresql.org/docs/current/pgstattuple.html
+// - https://reorg.github.io/pg_repack/
+func EnableInPostgreSQL(
+	ctx context.Context, exec postgres.Executor, cluster *v1beta1.PostgresCluster,
+) error {
+	log := logging.FromContext(ctx)
+	spec := cluster.Spec.Maintenance
+
+	encodedDatabases, err := json.Marshal(append([]string{}, spec.Databases...))
+	if err != nil {
+		return err
+	}
+
+	statements := []string{
+		// Quiet NOTICE messages from IF NOT EXISTS statements.
+		// - https://www.postgresql.org/docs/current/runtime-config-client.html
+		`SET client_min_messages = WARNING;`,
+
+		// Members of "pg_stat_scan_tables" can execute pgstattuple_approx.
+		`GRANT pg_stat_scan_tables TO :"username";`,
+
+		`CREATE SCHEMA IF NOT EXISTS :"namespace";`,
+		`CREATE EXTENSION IF NOT EXISTS pgstattuple SCHEMA :"namespace";`,
+	}
+
+	// pg_repack keeps its functions and log tables in the "repack" schema.
+	// Its client must be told to skip its superuser check.
+	if spec.Method == "Repack" {
+		statements = append(statements,
+			`CREATE EXTENSION IF NOT EXISTS pg_repack;`,
+			`GRANT ALL ON SCHEMA repack TO :"username";`,
+			`GRANT ALL ON ALL TABLES IN SCHEMA repack TO :"username";`,
+			`GRANT ALL ON ALL SEQUENCES IN SCHEMA repack TO :"username";`,
+			`GRANT EXECUTE ON ALL FUNCTIONS IN SCHEMA repack TO :"username";`,
+		)
+	}
+
+	statements = append(statements,
+		// The extension may have been installed into another schema before.
+		strings.TrimSpace(`
+SELECT pg_catalog.format('GRANT USAGE ON SCHEMA %I TO %I', n.nspname, :'username')
+  FROM pg_catalog.pg_extension e
+  JOIN pg_catalog.pg_namespace n ON n.oid = e.extnamespace
+ WHERE e.extname = 'pgstattuple'
+\gexec`),
+
+		// Allow the user to rewrite every table that is not owned by a
+		// superuser.
+		strings.TrimSpace(`
+SELECT DISTINCT pg_catalog.format('GRANT %I TO %I', r.rolname, :'username')
+  FROM pg_catalog.pg_class c
+  JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+  JOIN pg_catalog.pg_roles r ON r.oid = c.relowner
+ WHERE c.relkind = 'r'
+   AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+   AND NOT r.rolsuper AND r.rolname <> :'username'
+   AND NOT pg_catalog.pg_has_role(:'username', r.oid, 'MEMBER')
+\gexec`),
+	)
+
+	err = postgres.CreateMaintenanceUser(ctx, exec)
+	if err != nil {
+		return err
+	}
+
+	stdout, stderr, err := exec.ExecInDatabasesFromQuery(ctx, databases,
+		strings.Join(statements, "\n"),
+		map[string]string{
+			"databases": string(encodedDatabases),
+			"namespace": Schema,
+			"username":  postgres.MaintenanceUser,
+
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("enabled maintenance", "stdout", stdout, "stderr", stderr)
+
+	return err
+}
+
+// Progress is the summary that a maintenance Job writes to its termination
+// message after each table.
+type Progress struct {
+	BloatedTables   int32 `json:"bloatedTables"`
+	RewrittenTables int32 `json:"rewrittenTables"`
+}
+
+// Command returns the command that rewrites the bloated tables of cluster. It
+// expects libpq environment variables that connect to the primary. Its
+// progress is written to the termination message of its container.
+func Command(cluster *v1beta1.PostgresCluster) []string {
+	spec := cluster.Spec.Maintenance
+
+	threshold := int32(30)
+	if spec.BloatThreshold != nil {
+		threshold = *spec.BloatThreshold
+	}
+
+	method := spec.Method
+	if method == "" {
+		method = "Vacuum"
+	}
+
+	// Rewrite tables in order of their wasted space so that the worst are
+	// handled first when the window is too short for everything. Tables smaller
+	// than 1MiB are always cheap to scan, so they are ignored. A table that
+	// cannot be rewritten, such as one owned by a superuser or one that cannot
+	// be locked within a few seconds, is reported and skipped.
+	const script = `
+declare -r threshold="$1" method="$2"
+shift 2
+databases=("$@")
+if [[ "${#databases[@]}" -eq 0 ]]; then
+  mapfile -t databases < <(psql -Xw --tuples-only --no-align --command='
+SELECT datname FROM pg_catalog.pg_database WHERE datallowconn AND NOT datistemplate')
+fi
+bloated=0 rewritten=0
+progress() {
+  printf '{"bloatedTables":%d,"rewrittenTables":%d}' "${bloated}" "${rewritten}" > /dev/termination-log
+}
+progress
+for database in "${databases[@]}"; do
+  printf 'Estimating bloat in database "%s"\n' "${database}"
+  list=$(psql -Xw --tuples-only --no-align --dbname="${database}" \
+    --set=ON_ERROR_STOP=on --set=threshold="${threshold}" <<'SQL'
+SELECT n.nspname AS schema
+  FROM pg_catalog.pg_extension e
+  JOIN pg_catalog.pg_namespace n ON n.oid = e.extnamespace
+ WHERE e.extname = 'pgstattuple'
+\gset
+SELECT pg_catalog.format('%I.%I', n.nspname, c.relname)
+  FROM pg_catalog.pg_class c
+  JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+ CROSS JOIN LATERAL :"schema".pgstattuple_approx(c.oid) AS s
+ WHERE c.relkind = 'r' AND c.relpersistence = 'p'
+   AND n.nspname NOT IN ('pg_catalog', 'information_schema')
+   AND pg_catalog.pg_relation_size(c.oid) >= 1048576
+   AND s.dead_tuple_percent + s.approx_free_percent > :threshold
+ ORDER BY s.dead_tuple_len + s.approx_free_space DESC
+SQL
+)
+  tables=()
+  [[ -z "${list}" ]] || mapfile -t tables <<< "${list}"
+  bloated=$((bloated + ${#tables[@]}))
+  progress
+  for table in "${tables[@]}"; do
+    printf 'Rewriting table %s with %s\n' "${table}" "${method}"
+    case "${method}" in
+      Repack) command=(pg_repack --no-superuser-check --no-kill-backend --wait-timeout=5
+        --dbname="${database}" --table="${table}") ;;
+      VacuumFull) command=(psql -Xw --dbname="${database}" --set=ON_ERROR_STOP=on
+        --command="SET lock_timeout = '5s'" --command="VACUUM (FULL, ANALYZE) ${table}") ;;
+      *) command=(psql -Xw --dbname="${database}" --set=ON_ERROR_STOP=on
+        --command="VACUUM (ANALYZE) ${table}") ;;
+    esac
+    if "${command[@]}"; then
+      rewritten=$((rewritten + 1))
+      progress
+    else
+      printf 'Skipped table %s\n' "${table}"
+    fi
+  done
+done
+printf 'Rewrote %d of %d bloated tables\n' "${rewritten}" "${bloated}"
+`
+	return append([]string{
+		"bash", "-ceu", "--", script, "maintenance",
+		fmt.Sprint(threshold), method,
+	}, spec.Databases...)
+}
@@ -0,0 +1,124 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package maintenance
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestEnabled(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	assert.Assert(t, !Enabled(cluster))
+
+	cluster.Spec.Maintenance = new(v1beta1.MaintenanceSpec)
+	assert.Assert(t, Enabled(cluster))
+}
+
+func TestDisableInPostgreSQL(t *testing.T) {
+	expected := errors.New("whoops")
+	exec := func(
+		_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+	) error {
+		assert.Assert(t, strings.Contains(strings.Join(command, "\n"),
+			`--set=username=_crunchymaintenance`))
+
+		b, err := io.ReadAll(stdin)
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(string(b), `REVOKE pg_stat_scan_tables FROM %I`))
+
+		return expected
+	}
+
+	ctx := context.Background()
+	assert.Equal(t, expected, DisableInPostgreSQL(ctx, exec))
+}
+
+func TestEnableInPostgreSQL(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.Maintenance = new(v1beta1.MaintenanceSpec)
+
+	expected := errors.New("whoops")
+	var sql, args string
+	calls := 0
+	exec := func(
+		_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		// The first call creates the maintenance user.
+		if calls++; calls == 1 {
+			return nil
+		}
+
+		assert.Assert(t, stdout != nil, "should capture stdout")
+		assert.Assert(t, stderr != nil, "should capture stderr")
+
+		b, err := io.ReadAll(stdin)
+		assert.NilError(t, err)
+		sql, args = string(b), strings.Join(command, "\n")
+
+		return expected
+	}
+
+	ctx := context.Background()
+	assert.Equal(t, expected, EnableInPostgreSQL(ctx, exec, cluster))
+	assert.Assert(t, cmp.Contains(args, `--set=databases=[]`))
+	assert.Assert(t, cmp.Contains(sql, `GRANT pg_stat_scan_tables TO :"username";`))
+	assert.Assert(t, cmp.Contains(sql, `CREATE EXTENSION IF NOT EXISTS pgstattuple SCHEMA :"namespace";`))
+	assert.Assert(t, cmp.Contains(sql, `GRANT %I TO %I`))
+	assert.Assert(t, cmp.Contains(sql, `NOT r.rolsuper`))
+	assert.Assert(t, !strings.Contains(sql, `pg_repack`))
+
+	t.Run("Repack", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Maintenance.Method = "Repack"
+		cluster.Spec.Maintenance.Databases = []string{"app"}
+
+		calls = 0
+		assert.Equal(t, expected, EnableInPostgreSQL(ctx, exec, cluster))
+		assert.Assert(t, cmp.Contains(args, `--set=databases=["app"]`))
+		assert.Assert(t, cmp.Contains(sql, `CREATE EXTENSION IF NOT EXISTS pg_repack;`))
+		assert.Assert(t, cmp.Contains(sql, `GRANT ALL ON SCHEMA repack TO :"username";`))
+	})
+}
+
+func TestCommand(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.Maintenance = new(v1beta1.MaintenanceSpec)
+
+	command := Command(cluster)
+	assert.DeepEqual(t, command[:3], []string{"bash", "-ceu", "--"})
+	assert.DeepEqual(t, command[4:], []string{"maintenance", "30", "Vacuum"})
+	assert.Assert(t, cmp.Contains(command[3], `pgstattuple_approx(c.oid)`))
+	assert.Assert(t, cmp.Contains(command[3], `> /dev/termination-log`))
+	assert.Assert(t, cmp.Contains(command[3], `--command="SET lock_timeout = '5s'"`))
+	assert.Assert(t, cmp.Contains(command[3], `pg_repack --no-superuser-check --no-kill-backend`))
+
+	cluster.Spec.Maintenance.Method = "Repack"
+	cluster.Spec.Maintenance.BloatThreshold = initialize.Int32(50)
+	cluster.Spec.Maintenance.Databases = []string{"app", "other"}
+	assert.DeepEqual(t, Command(cluster)[4:], []string{
+		"maintenance", "50", "Repack", "app", "other",
+	})
+}
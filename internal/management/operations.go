@@ -0,0 +1,170 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package management
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// clusterSummary is the representation of a PostgresCluster in responses.
+type clusterSummary struct {
+	Name             string                              `json:"name"`
+	PostgresVersion  int                                 `json:"postgresVersion"`
+	SystemIdentifier string                              `json:"systemIdentifier,omitempty"`
+	Instances        []v1beta1.PostgresInstanceSetStatus `json:"instances"`
+	Conditions       []metav1.Condition                  `json:"conditions"`
+}
+
+func summarize(cluster *v1beta1.PostgresCluster) clusterSummary {
+	summary := clusterSummary{
+		Name:             cluster.Name,
+		PostgresVersion:  cluster.Spec.PostgresVersion,
+		SystemIdentifier: cluster.Status.Patroni.SystemIdentifier,
+		Instances:        cluster.Status.InstanceSets,
+		Conditions:       cluster.Status.Conditions,
+	}
+	if summary.Instances == nil {
+		summary.Instances = []v1beta1.PostgresInstanceSetStatus{}
+	}
+	if summary.Conditions == nil {
+		summary.Conditions = []metav1.Condition{}
+	}
+	return summary
+}
+
+// getCluster reads the PostgresCluster at key and responds when that fails.
+func (s *Server) getCluster(
+	w http.ResponseWriter, r *http.Request, key client.ObjectKey,
+) (*v1beta1.PostgresCluster, bool) {
+	cluster := &v1beta1.PostgresCluster{}
+	err := s.Client.Get(r.Context(), key, cluster)
+
+	if apierrors.IsNotFound(err) {
+		respond(w, http.StatusNotFound, failure("The PostgresCluster does not exist"))
+	} else if err != nil {
+		logging.FromContext(r.Context()).Error(err, "reading PostgresCluster")
+		respond(w, http.StatusInternalServerError, failure(err.Error()))
+	}
+	return cluster, err == nil
+}
+
+// +kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={get,list}
+
+// list responds with a summary of every PostgresCluster in the namespace.
+func (s *Server) list(w http.ResponseWriter, r *http.Request, key client.ObjectKey) {
+	clusters := &v1beta1.PostgresClusterList{}
+	if err := s.Client.List(r.Context(), clusters, client.InNamespace(key.Namespace)); err != nil {
+		logging.FromContext(r.Context()).Error(err, "listing PostgresClusters")
+		respond(w, http.StatusInternalServerError, failure(err.Error()))
+		return
+	}
+
+	items := make([]clusterSummary, 0, len(clusters.Items))
+	for i := range clusters.Items {
+		items = append(items, summarize(&clusters.Items[i]))
+	}
+	respond(w, http.StatusOK, map[string]any{"items": items})
+}
+
+// health responds with the conditions and instances of one PostgresCluster.
+func (s *Server) health(w http.ResponseWriter, r *http.Request, key client.ObjectKey) {
+	if cluster, ok := s.getCluster(w, r, key); ok {
+		respond(w, http.StatusOK, summarize(cluster))
+	}
+}
+
+// backups responds with the pgBackRest status of one PostgresCluster, which
+// includes its repositories and the manual and scheduled backups it has run.
+func (s *Server) backups(w http.ResponseWriter, r *http.Request, key client.ObjectKey) {
+	if cluster, ok := s.getCluster(w, r, key); ok {
+		status := cluster.Status.PGBackRest
+		if status == nil {
+			status = &v1beta1.PGBackRestStatus{}
+		}
+		respond(w, http.StatusOK, status)
+	}
+}
+
+// backup starts a manual pgBackRest backup of one PostgresCluster.
+func (s *Server) backup(w http.ResponseWriter, r *http.Request, key client.ObjectKey) {
+	s.trigger(w, r, key, naming.PGBackRestBackup, func(cluster *v1beta1.PostgresCluster) bool {
+		return cluster.Spec.Backups.PGBackRest.Manual != nil
+	}, "Manual backups are not configured in spec.backups.pgbackrest.manual")
+}
+
+// switchover starts a Patroni switchover of one PostgresCluster.
+func (s *Server) switchover(w http.ResponseWriter, r *http.Request, key client.ObjectKey) {
+	s.trigger(w, r, key, naming.PatroniSwitchover, func(cluster *v1beta1.PostgresCluster) bool {
+		return cluster.Spec.Patroni != nil &&
+			cluster.Spec.Patroni.Switchover != nil && cluster.Spec.Patroni.Switchover.Enabled
+	}, "Switchovers are not enabled in spec.patroni.switchover")
+}
+
+// restart starts a rolling restart of PostgreSQL in one PostgresCluster.
+func (s *Server) restart(w http.ResponseWriter, r *http.Request, key client.ObjectKey) {
+	s.trigger(w, r, key, naming.PatroniRestart, func(cluster *v1beta1.PostgresCluster) bool {
+		return cluster.Spec.Patroni != nil &&
+			cluster.Spec.Patroni.Restart != nil && cluster.Spec.Patroni.Restart.Enabled
+	}, "Restarts are not enabled in spec.patroni.restart")
+}
+
+// +kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={patch}
+
+// trigger sets annotation of one PostgresCluster to the current time, which
+// starts the operation it names. It responds with a conflict when enabled
+// reports that the spec does not allow that operation.
+func (s *Server) trigger(
+	w http.ResponseWriter, r *http.Request, key client.ObjectKey, annotation string,
+	enabled func(*v1beta1.PostgresCluster) bool, disabled string,
+) {
+	cluster, ok := s.getCluster(w, r, key)
+	if !ok {
+		return
+	}
+	if !enabled(cluster) {
+		respond(w, http.StatusConflict, failure(disabled))
+		return
+	}
+
+	value := time.Now().UTC().Format(time.RFC3339)
+	patch, _ := json.Marshal(map[string]any{
+		"metadata": map[string]any{
+			"annotations": map[string]string{annotation: value},
+		},
+	})
+
+	if err := s.Client.Patch(r.Context(), cluster,
+		client.RawPatch(client.Merge.Type(), patch),
+	); err != nil {
+		logging.FromContext(r.Context()).Error(err, "triggering operation",
+			"annotation", annotation)
+		respond(w, http.StatusInternalServerError, failure(err.Error()))
+		return
+	}
+
+	logging.FromContext(r.Context()).Info("triggered operation",
+		"cluster", key.String(), "annotation", annotation, "value", value)
+	respond(w, http.StatusAccepted, map[string]string{"annotation": annotation, "value": value})
+}
@@ -0,0 +1,221 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package management implements an HTTP API for day-2 operations on
+// PostgresClusters. Callers authenticate with a Kubernetes bearer token and
+// need permission only on the "postgresclusters/operations" subresource, so
+// portals can drive operations without broad access to the Kubernetes API.
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// Server serves the management API.
+type Server struct {
+	Client client.Client
+
+	// Address is the TCP address on which to listen, such as ":8443".
+	Address string
+
+	// CertFile and KeyFile are the paths to a TLS certificate and key. They
+	// are required because every request carries a bearer token.
+	CertFile, KeyFile string
+
+	// Authorize reports whether token may perform the action in attributes.
+	// It defaults to asking the Kubernetes API with a TokenReview followed
+	// by a SubjectAccessReview.
+	Authorize func(ctx context.Context, token string, attributes authorizationv1.ResourceAttributes) (bool, error)
+}
+
+// ManagedServer creates a [Server] and adds it to m.
+func ManagedServer(m manager.Manager, address, certFile, keyFile string) error {
+	if certFile == "" || keyFile == "" {
+		return errors.New("the management API requires a TLS certificate and key")
+	}
+
+	s := &Server{
+		Client:   m.GetClient(),
+		Address:  address,
+		CertFile: certFile,
+		KeyFile:  keyFile,
+	}
+	s.Authorize = s.review
+	return m.Add(s)
+}
+
+// NeedLeaderElection returns false so that every [manager.Manager] serves
+// requests. Each request reads and writes the Kubernetes API directly.
+func (s *Server) NeedLeaderElection() bool { return false }
+
+// Start serves requests over TLS until ctx is cancelled.
+func (s *Server) Start(ctx context.Context) error {
+	if s.CertFile == "" || s.KeyFile == "" {
+		return errors.New("the management API requires a TLS certificate and key")
+	}
+
+	server := &http.Server{
+		Addr:              s.Address,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(_ net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdown)
+	}()
+
+	logging.FromContext(ctx).Info("serving the management API", "address", s.Address)
+
+	if err := server.ListenAndServeTLS(s.CertFile, s.KeyFile); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// +kubebuilder:rbac:groups="authentication.k8s.io",resources="tokenreviews",verbs={create}
+// +kubebuilder:rbac:groups="authorization.k8s.io",resources="subjectaccessreviews",verbs={create}
+
+// review asks the Kubernetes API who token belongs to and whether they may
+// perform the action in attributes.
+func (s *Server) review(
+	ctx context.Context, token string, attributes authorizationv1.ResourceAttributes,
+) (bool, error) {
+	tokenReview := &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}
+	if err := s.Client.Create(ctx, tokenReview); err != nil || !tokenReview.Status.Authenticated {
+		return false, err
+	}
+
+	user := tokenReview.Status.User
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for key, values := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(values)
+	}
+
+	accessReview := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			ResourceAttributes: &attributes,
+			User:               user.Username,
+			UID:                user.UID,
+			Groups:             user.Groups,
+			Extra:              extra,
+		},
+	}
+	err := s.Client.Create(ctx, accessReview)
+	return err == nil && accessReview.Status.Allowed, err
+}
+
+// ServeHTTP authenticates, authorizes, and routes one request. The paths are
+//
+//	GET  /api/v1/namespaces/:namespace/postgresclusters
+//	GET  /api/v1/namespaces/:namespace/postgresclusters/:name/health
+//	GET  /api/v1/namespaces/:namespace/postgresclusters/:name/backups
+//	POST /api/v1/namespaces/:namespace/postgresclusters/:name/backup
+//	POST /api/v1/namespaces/:namespace/postgresclusters/:name/switchover
+//	POST /api/v1/namespaces/:namespace/postgresclusters/:name/restart
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) < 5 || len(parts) > 7 || len(parts) == 6 ||
+		parts[0] != "api" || parts[1] != "v1" || parts[2] != "namespaces" ||
+		parts[4] != "postgresclusters" {
+		respond(w, http.StatusNotFound, failure("Not found"))
+		return
+	}
+
+	namespace := parts[3]
+	attributes := authorizationv1.ResourceAttributes{
+		Namespace:   namespace,
+		Group:       v1beta1.GroupVersion.Group,
+		Resource:    "postgresclusters",
+		Subresource: "operations",
+	}
+
+	var handle func(http.ResponseWriter, *http.Request, client.ObjectKey)
+	switch {
+	case len(parts) == 5 && r.Method == http.MethodGet:
+		attributes.Verb = "list"
+		handle = s.list
+	case len(parts) == 7 && parts[6] == "health" && r.Method == http.MethodGet:
+		attributes.Verb = "get"
+		handle = s.health
+	case len(parts) == 7 && parts[6] == "backups" && r.Method == http.MethodGet:
+		attributes.Verb = "get"
+		handle = s.backups
+	case len(parts) == 7 && parts[6] == "backup" && r.Method == http.MethodPost:
+		attributes.Verb = "create"
+		handle = s.backup
+	case len(parts) == 7 && parts[6] == "switchover" && r.Method == http.MethodPost:
+		attributes.Verb = "create"
+		handle = s.switchover
+	case len(parts) == 7 && parts[6] == "restart" && r.Method == http.MethodPost:
+		attributes.Verb = "create"
+		handle = s.restart
+	default:
+		respond(w, http.StatusNotFound, failure("Not found"))
+		return
+	}
+
+	key := client.ObjectKey{Namespace: namespace}
+	if len(parts) == 7 {
+		key.Name, attributes.Name = parts[5], parts[5]
+	}
+
+	authorization := r.Header.Get("Authorization")
+	token := strings.TrimPrefix(authorization, "Bearer ")
+	if token == authorization || token == "" {
+		respond(w, http.StatusUnauthorized, failure("A bearer token is required"))
+		return
+	}
+	if allowed, err := s.Authorize(r.Context(), token, attributes); err != nil {
+		logging.FromContext(r.Context()).Error(err, "authorizing management request")
+		respond(w, http.StatusInternalServerError, failure(err.Error()))
+		return
+	} else if !allowed {
+		respond(w, http.StatusForbidden, failure("Forbidden"))
+		return
+	}
+
+	handle(w, r, key)
+}
+
+// respond writes status and body as JSON.
+func respond(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// failure returns the body of an error response.
+func failure(message string) map[string]string {
+	return map[string]string{"error": message}
+}
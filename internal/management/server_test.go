@@ -0,0 +1,133 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package management
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestServerStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Bearer tokens are never sent in plain text.
+	server := &Server{Address: "127.0.0.1:0"}
+	assert.ErrorContains(t, server.Start(ctx), "requires a TLS certificate")
+}
+
+func TestServer(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	manual := v1beta1.NewPostgresCluster()
+	manual.Namespace, manual.Name = "portal", "manual"
+	manual.Spec.Backups.PGBackRest.Manual = &v1beta1.PGBackRestManualBackup{RepoName: "repo1"}
+	manual.Status.Patroni.SystemIdentifier = "12345"
+
+	plain := v1beta1.NewPostgresCluster()
+	plain.Namespace, plain.Name = "portal", "plain"
+
+	var requested []authorizationv1.ResourceAttributes
+	server := &Server{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(manual, plain).Build(),
+		Authorize: func(_ context.Context, token string, attributes authorizationv1.ResourceAttributes) (bool, error) {
+			requested = append(requested, attributes)
+			return token == "good", nil
+		},
+	}
+
+	call := func(method, path, token string) (int, map[string]any) {
+		request := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, request)
+
+		var decoded map[string]any
+		assert.NilError(t, json.Unmarshal(recorder.Body.Bytes(), &decoded))
+		return recorder.Code, decoded
+	}
+
+	t.Run("Authorization", func(t *testing.T) {
+		code, _ := call(http.MethodGet, "/api/v1/namespaces/portal/postgresclusters", "")
+		assert.Equal(t, code, http.StatusUnauthorized)
+
+		requested = nil
+		code, _ = call(http.MethodPost, "/api/v1/namespaces/portal/postgresclusters/manual/backup", "bad")
+		assert.Equal(t, code, http.StatusForbidden)
+		assert.DeepEqual(t, requested, []authorizationv1.ResourceAttributes{{
+			Namespace:   "portal",
+			Verb:        "create",
+			Group:       "postgres-operator.crunchydata.com",
+			Resource:    "postgresclusters",
+			Subresource: "operations",
+			Name:        "manual",
+		}})
+
+		code, _ = call(http.MethodDelete, "/api/v1/namespaces/portal/postgresclusters/manual/backup", "good")
+		assert.Equal(t, code, http.StatusNotFound)
+	})
+
+	t.Run("List", func(t *testing.T) {
+		code, body := call(http.MethodGet, "/api/v1/namespaces/portal/postgresclusters", "good")
+		assert.Equal(t, code, http.StatusOK)
+		assert.Equal(t, len(body["items"].([]any)), 2)
+	})
+
+	t.Run("Health", func(t *testing.T) {
+		code, body := call(http.MethodGet, "/api/v1/namespaces/portal/postgresclusters/manual/health", "good")
+		assert.Equal(t, code, http.StatusOK)
+		assert.Equal(t, body["systemIdentifier"], "12345")
+
+		code, _ = call(http.MethodGet, "/api/v1/namespaces/portal/postgresclusters/missing/health", "good")
+		assert.Equal(t, code, http.StatusNotFound)
+	})
+
+	t.Run("Backups", func(t *testing.T) {
+		code, _ := call(http.MethodGet, "/api/v1/namespaces/portal/postgresclusters/plain/backups", "good")
+		assert.Equal(t, code, http.StatusOK)
+	})
+
+	t.Run("Trigger", func(t *testing.T) {
+		code, body := call(http.MethodPost, "/api/v1/namespaces/portal/postgresclusters/manual/backup", "good")
+		assert.Equal(t, code, http.StatusAccepted)
+		assert.Equal(t, body["annotation"], naming.PGBackRestBackup)
+
+		updated := &v1beta1.PostgresCluster{}
+		assert.NilError(t, server.Client.Get(ctx, client.ObjectKeyFromObject(manual), updated))
+		assert.Equal(t, updated.Annotations[naming.PGBackRestBackup], body["value"])
+
+		for _, operation := range []string{"backup", "switchover", "restart"} {
+			code, _ = call(http.MethodPost, "/api/v1/namespaces/portal/postgresclusters/plain/"+operation, "good")
+			assert.Equal(t, code, http.StatusConflict, operation)
+		}
+	})
+}
@@ -25,6 +25,88 @@ const (
 	// Patroni Switchover (or Failover).
 	PatroniSwitchover = annotationPrefix + "trigger-switchover"
 
+	// PatroniRestart is the annotation added to a PostgresCluster to restart
+	// PostgreSQL in some or all of its instances.
+	PatroniRestart = annotationPrefix + "trigger-restart"
+
+	// PatroniReload is the annotation added to a PostgresCluster to reload
+	// the PostgreSQL configuration in some or all of its instances.
+	PatroniReload = annotationPrefix + "trigger-reload"
+
+	// ReindexCollations is the annotation added to a PostgresCluster to rebuild
+	// indexes and refresh collation versions when the CollationVersionMismatch
+	// condition is true. The value is a unique identifier that is stored in the
+	// PostgresCluster status once the indexes are rebuilt.
+	ReindexCollations = annotationPrefix + "reindex-collations"
+
+	// RestorePoint is the annotation added to a PostgresCluster to create a
+	// named restore point in its WAL, such as before a risky deployment. The
+	// value is the name of the restore point, which is stored in the
+	// PostgresCluster status once it is created.
+	RestorePoint = annotationPrefix + "restore-point"
+
+	// DebugInstance is the annotation added to a PostgresCluster to attach a
+	// debug container to one of its instance Pods. The value is the name of
+	// the Pod.
+	DebugInstance = annotationPrefix + "debug-instance"
+
+	// SplitInstance is the annotation added to a PostgresCluster to stop one
+	// of its replicas and create a new PostgresCluster from the volumes of that
+	// replica. The value is the name of the instance and the name of the new
+	// PostgresCluster separated by a colon, e.g. "hippo-instance1-abcd:hippo2".
+	// Both are stored in the PostgresCluster status once the split is done.
+	SplitInstance = annotationPrefix + "split-instance"
+
+	// FaultArchivePushDelay is the annotation added to a PostgresCluster to
+	// delay every WAL archive by a duration, e.g. "30s". It requires the
+	// FaultInjection feature gate.
+	FaultArchivePushDelay = annotationPrefix + "fault-archive-push-delay"
+
+	// FaultKillPrimary is the annotation added to a PostgresCluster to delete
+	// its primary Pod immediately. The value is a unique identifier that is
+	// stored in the PostgresCluster status once the Pod is deleted. It
+	// requires the FaultInjection feature gate.
+	FaultKillPrimary = annotationPrefix + "fault-kill-primary"
+
+	// FaultPartitionRepoHost is the annotation added to a PostgresCluster to
+	// block network traffic to and from its pgBackRest repository host. The
+	// value must be "true". It requires the FaultInjection feature gate.
+	FaultPartitionRepoHost = annotationPrefix + "fault-partition-repo-host"
+
+	// StorageMedia is the annotation added to a StorageClass to say whether
+	// its volumes are "SSD" or "HDD". Automatic tuning uses it to estimate the
+	// cost of random reads.
+	StorageMedia = annotationPrefix + "storage-media"
+
+	// AllowUpgrade is the annotation added to a PostgresCluster to allow a
+	// PGUpgrade of the same name to upgrade it.
+	AllowUpgrade = annotationPrefix + "allow-upgrade"
+
+	// ProtectedChanges is the annotation added to a pgBackRest backup Job to
+	// identify the disruptive changes it is taken before.
+	ProtectedChanges = annotationPrefix + "protected-changes"
+
+	// AllowDatabaseClaims is the annotation added to a PostgresCluster to allow
+	// PostgresDatabaseClaims to be fulfilled on it. Its value is a comma-separated
+	// list of the namespaces of those claims, or "*" for every namespace.
+	AllowDatabaseClaims = annotationPrefix + "allow-database-claims"
+
+	// ApproveSQLRequest is the annotation added to a PGSQLRequest to approve
+	// its statement. The value must be the SHA-256 of the statement; a request
+	// whose statement changes after it is approved is not executed.
+	ApproveSQLRequest = annotationPrefix + "approve-sql-request"
+
+	// RotatePassword is the annotation added to the current Secret of a
+	// PostgreSQL user to generate a new password when the Secrets of its
+	// cluster are immutable. The new password is written to the next version
+	// of the Secret.
+	RotatePassword = annotationPrefix + "rotate-password"
+
+	// PGCloneRefresh is the annotation added to a PostgresCluster created by a
+	// PGClone. Its value is the name of the scheduled Job that started the
+	// refresh.
+	PGCloneRefresh = annotationPrefix + "pgclone-refresh"
+
 	// PGBackRestBackup is the annotation that is added to a PostgresCluster to initiate a manual
 	// backup.  The value of the annotation will be a unique identifier for a backup Job (e.g. a
 	// timestamp), which will be stored in the PostgresCluster status to properly track completion
@@ -32,6 +114,11 @@ const (
 	// ID associated with a specific manual backup Job.
 	PGBackRestBackup = annotationPrefix + "pgbackrest-backup"
 
+	// PGBackRestBackupNode is the annotation added to a suspended scheduled backup Job. Its
+	// value is the name of the Node that the backup reads from, which limits how many scheduled
+	// backups of any cluster read from that Node at once.
+	PGBackRestBackupNode = annotationPrefix + "pgbackrest-backup-node"
+
 	// PGBackRestConfigHash is an annotation used to specify the hash value associated with a
 	// repo configuration as needed to detect configuration changes that invalidate running Jobs
 	// (and therefore must be recreated)
@@ -70,4 +157,14 @@ const (
 	// bridge cluster, the user must add this annotation to the CR to allow the CR to take control of
 	// the Bridge Cluster. The Value assigned to the annotation must be the ID of existing cluster.
 	CrunchyBridgeClusterAdoptionAnnotation = annotationPrefix + "adopt-bridge-cluster"
+
+	// ServiceBindings is an annotation that the Open Service Broker records on
+	// the PostgresClusters it creates. Its value is a JSON object of the
+	// parameters of each binding keyed by binding ID.
+	ServiceBindings = annotationPrefix + "osb-bindings"
+
+	// VolumeNodeWarning is an annotation on an instance Pod that cannot be
+	// scheduled because of the Node affinity of its volumes. It records the
+	// warning last reported about it so that each is reported once.
+	VolumeNodeWarning = annotationPrefix + "volume-node-warning"
 )
@@ -25,11 +25,20 @@ import (
 func TestAnnotationsValid(t *testing.T) {
 	assert.Assert(t, nil == validation.IsQualifiedName(Finalizer))
 	assert.Assert(t, nil == validation.IsQualifiedName(PatroniSwitchover))
+	assert.Assert(t, nil == validation.IsQualifiedName(PatroniRestart))
+	assert.Assert(t, nil == validation.IsQualifiedName(PatroniReload))
+	assert.Assert(t, nil == validation.IsQualifiedName(PGCloneRefresh))
+	assert.Assert(t, nil == validation.IsQualifiedName(AllowDatabaseClaims))
+	assert.Assert(t, nil == validation.IsQualifiedName(ApproveSQLRequest))
 	assert.Assert(t, nil == validation.IsQualifiedName(PGBackRestBackup))
+	assert.Assert(t, nil == validation.IsQualifiedName(PGBackRestBackupNode))
 	assert.Assert(t, nil == validation.IsQualifiedName(PGBackRestConfigHash))
 	assert.Assert(t, nil == validation.IsQualifiedName(PGBackRestCurrentConfig))
 	assert.Assert(t, nil == validation.IsQualifiedName(PGBackRestRestore))
 	assert.Assert(t, nil == validation.IsQualifiedName(PGBackRestIPVersion))
 	assert.Assert(t, nil == validation.IsQualifiedName(PostgresExporterCollectorsAnnotation))
+	assert.Assert(t, nil == validation.IsQualifiedName(StorageMedia))
 	assert.Assert(t, nil == validation.IsQualifiedName(CrunchyBridgeClusterAdoptionAnnotation))
+	assert.Assert(t, nil == validation.IsQualifiedName(ServiceBindings))
+	assert.Assert(t, nil == validation.IsQualifiedName(VolumeNodeWarning))
 }
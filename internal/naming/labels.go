@@ -39,6 +39,22 @@ const (
 	// LabelData is used to identify Pods and Volumes store Postgres data.
 	LabelData = labelPrefix + "data"
 
+	// LabelDataChecksums is used to identify Jobs that enable or verify
+	// PostgreSQL data page checksums. Its value is one of "enable" or "verify".
+	LabelDataChecksums = labelPrefix + "data-checksums"
+
+	// LabelHealthProbes is used to identify the CronJob and Jobs that run
+	// synthetic transactions against PostgreSQL.
+	LabelHealthProbes = labelPrefix + "health-probes"
+
+	// LabelIntegrityChecks is used to identify the CronJob and Jobs that check
+	// PostgreSQL tables and indexes for corruption.
+	LabelIntegrityChecks = labelPrefix + "integrity-checks"
+
+	// LabelMaintenance is used to identify the CronJob and Jobs that rewrite
+	// bloated tables.
+	LabelMaintenance = labelPrefix + "maintenance"
+
 	// LabelMoveJob is used to identify a directory move Job.
 	LabelMoveJob = labelPrefix + "move-job"
 
@@ -51,6 +67,26 @@ const (
 	// LabelMovePGWalDir is used to identify the Job that moves an existing pg_wal directory.
 	LabelMovePGWalDir = labelPrefix + "move-pgwal-dir"
 
+	// LabelPostUpgrade is used to identify the Secret of the Jobs that run
+	// after a major upgrade.
+	LabelPostUpgrade = labelPrefix + "post-upgrade"
+
+	// LabelPartitioning is used to identify the CronJob and Jobs that maintain
+	// partitioned tables.
+	LabelPartitioning = labelPrefix + "partitioning"
+
+	// LabelPGClone is used to identify the CronJob and Jobs that schedule the
+	// refreshes of a PGClone. Its value is the name of the PGClone.
+	LabelPGClone = labelPrefix + "pgclone"
+
+	// LabelServiceInstance is used to identify a PostgresCluster that the Open
+	// Service Broker API created. Its value is the ID of the service instance.
+	LabelServiceInstance = labelPrefix + "osb-instance"
+
+	// LabelDatabaseClaim is used to identify the connection Secret of a
+	// PostgresDatabaseClaim. Its value is the name of the claim.
+	LabelDatabaseClaim = labelPrefix + "database-claim"
+
 	// LabelPGBackRest is used to indicate that a resource is for pgBackRest
 	LabelPGBackRest = labelPrefix + "pgbackrest"
 
@@ -88,6 +124,10 @@ const (
 	// LabelPostgresUser identifies the PostgreSQL user an object is for or about.
 	LabelPostgresUser = labelPrefix + "pguser"
 
+	// LabelReadOnly is applied to Services that reach PostgreSQL that does not
+	// accept writes, such as those of a standby cluster. Its value is "true".
+	LabelReadOnly = labelPrefix + "read-only"
+
 	// LabelStartupInstance is used to indicate the startup instance associated with a resource
 	LabelStartupInstance = labelPrefix + "startup-instance"
 
@@ -117,8 +157,19 @@ const (
 	// RolePostgresWAL is the LabelRole applied to PostgreSQL WAL volumes.
 	RolePostgresWAL = "pgwal"
 
+	// RolePostgresTemp is the LabelRole applied to PostgreSQL volumes of
+	// temporary files.
+	RolePostgresTemp = "pgtemp"
+
+	// RoleConnectionDetails is the LabelRole applied to the Secret to which the
+	// connection details of a PostgresCluster are written.
+	RoleConnectionDetails = "connection-details"
+
 	// RoleMonitoring is the LabelRole applied to Monitoring resources
 	RoleMonitoring = "monitoring"
+
+	// RoleWALStreaming is the LabelRole applied to pg_receivewal objects.
+	RoleWALStreaming = "walstream"
 )
 
 const (
@@ -133,6 +184,10 @@ const (
 	// DataPGAdmin is a LabelData value that indicates the object has pgAdmin data.
 	DataPGAdmin = "pgadmin"
 
+	// DataPGAdminStorage is a LabelData value that indicates the object has
+	// the files of pgAdmin users.
+	DataPGAdminStorage = "pgadmin-storage"
+
 	// DataPGBackRest is a LabelData value that indicates the object has pgBackRest data.
 	DataPGBackRest = "pgbackrest"
 
@@ -151,6 +206,14 @@ const (
 	// BackupReplicaCreate is the backup type for the backup taken to enable pgBackRest replica
 	// creation
 	BackupReplicaCreate BackupJobType = "replica-create"
+
+	// BackupRepoMigration is the backup type for the full backup taken in a repository that
+	// replaces another
+	BackupRepoMigration BackupJobType = "repo-migration"
+
+	// BackupChangeProtection is the backup type for the differential backup taken before
+	// a disruptive change to a cluster
+	BackupChangeProtection BackupJobType = "change-protection"
 )
 
 const (
@@ -178,6 +241,15 @@ func DirectoryMoveJobLabels(clusterName string) labels.Set {
 	return jobLabels
 }
 
+// DataChecksumsJobLabels provides labels for Jobs that enable or verify
+// PostgreSQL data page checksums.
+func DataChecksumsJobLabels(clusterName, action string) labels.Set {
+	return map[string]string{
+		LabelCluster:       clusterName,
+		LabelDataChecksums: action,
+	}
+}
+
 // PGBackRestLabels provides common labels for pgBackRest resources.
 func PGBackRestLabels(clusterName string) labels.Set {
 	return map[string]string{
@@ -26,14 +26,22 @@ import (
 func TestLabelsValid(t *testing.T) {
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelCluster))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelData))
+	assert.Assert(t, nil == validation.IsQualifiedName(LabelDataChecksums))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelInstance))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelInstanceSet))
+	assert.Assert(t, nil == validation.IsQualifiedName(LabelHealthProbes))
+	assert.Assert(t, nil == validation.IsQualifiedName(LabelIntegrityChecks))
+	assert.Assert(t, nil == validation.IsQualifiedName(LabelMaintenance))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelMoveJob))
+	assert.Assert(t, nil == validation.IsQualifiedName(LabelPartitioning))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelMovePGBackRestRepoDir))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelMovePGDataDir))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelMovePGWalDir))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelPatroni))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelRole))
+	assert.Assert(t, nil == validation.IsQualifiedName(LabelPGClone))
+	assert.Assert(t, nil == validation.IsQualifiedName(LabelDatabaseClaim))
+	assert.Assert(t, nil == validation.IsQualifiedName(LabelServiceInstance))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelPGBackRest))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelPGBackRestBackup))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelPGBackRestConfig))
@@ -44,6 +52,7 @@ func TestLabelsValid(t *testing.T) {
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelPGBackRestRestoreConfig))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelPGMonitorDiscovery))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelPostgresUser))
+	assert.Assert(t, nil == validation.IsQualifiedName(LabelReadOnly))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelStandalonePGAdmin))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelStartupInstance))
 	assert.Assert(t, nil == validation.IsQualifiedName(LabelCrunchyBridgeClusterPostgresRole))
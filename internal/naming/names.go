@@ -29,10 +29,18 @@ import (
 )
 
 const (
+	// ContainerAgentInstall is the name of the initialization container that
+	// copies the agent into PostgreSQL instance Pods.
+	ContainerAgentInstall = "agent-install"
+
 	// ContainerDatabase is the name of the container running PostgreSQL and
 	// supporting tools: Patroni, pgBackRest, etc.
 	ContainerDatabase = "database"
 
+	// ContainerDebug is the name of the ephemeral container used to debug
+	// PostgreSQL in an instance Pod.
+	ContainerDebug = "debug"
+
 	// ContainerPGAdmin is the name of a container running pgAdmin.
 	ContainerPGAdmin = "pgadmin"
 
@@ -40,6 +48,10 @@ const (
 	// that prepares the filesystem for pgAdmin.
 	ContainerPGAdminStartup = "pgadmin-startup"
 
+	// ContainerPGAdminPackages is the name of the initialization container
+	// that installs Python packages for pgAdmin.
+	ContainerPGAdminPackages = "pgadmin-packages"
+
 	// ContainerPGBackRestConfig is the name of a container supporting pgBackRest.
 	ContainerPGBackRestConfig = "pgbackrest-config"
 
@@ -67,6 +79,29 @@ const (
 	// ContainerPGMonitorExporter is the name of a container running postgres_exporter
 	ContainerPGMonitorExporter = "exporter"
 
+	// ContainerWALStreaming is the name of a container running pg_receivewal
+	ContainerWALStreaming = "pg-receivewal"
+
+	// ContainerJobDataChecksums is the name of the job container that enables
+	// or verifies PostgreSQL data page checksums
+	ContainerJobDataChecksums = "data-checksums"
+
+	// ContainerJobHealthProbe is the name of the job container that runs a
+	// synthetic transaction
+	ContainerJobHealthProbe = "health-probe"
+
+	// ContainerJobIntegrityChecks is the name of the job container that runs
+	// pg_amcheck
+	ContainerJobIntegrityChecks = "amcheck"
+
+	// ContainerJobMaintenance is the name of the job container that rewrites
+	// bloated tables
+	ContainerJobMaintenance = "maintenance"
+
+	// ContainerJobPartitioning is the name of the job container that runs
+	// pg_partman maintenance
+	ContainerJobPartitioning = "partman"
+
 	// ContainerJobMovePGDataDir is the name of the job container utilized to copy v4 Operator
 	// pgData directories to the v5 default location
 	ContainerJobMovePGDataDir = "pgdata-move-job"
@@ -79,6 +114,8 @@ const (
 )
 
 const (
+	// PortAgent is the name of a port that connects to the agent.
+	PortAgent = "agent"
 	// PortExporter is the named port for the "exporter" container
 	PortExporter = "exporter"
 	// PortPGAdmin is the name of a port that connects to pgAdmin.
@@ -201,6 +238,15 @@ func ClusterConfigMap(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
 	}
 }
 
+// ClusterDemandScaledObject returns the ObjectMeta necessary to lookup the
+// KEDA ScaledObject that scales cluster on demand.
+func ClusterDemandScaledObject(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Name + "-demand",
+	}
+}
+
 // ClusterInstanceRBAC returns the ObjectMeta necessary to lookup the
 // ServiceAccount, Role, and RoleBinding for cluster's PostgreSQL instances.
 func ClusterInstanceRBAC(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
@@ -260,6 +306,15 @@ func ClusterReplicaService(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
 	}
 }
 
+// ClusterWALStreaming returns the ObjectMeta necessary to lookup the Deployment
+// and PersistentVolumeClaim that stream the WAL of cluster with pg_receivewal.
+func ClusterWALStreaming(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Name + "-walstream",
+	}
+}
+
 // GenerateInstance returns a random name for a member of cluster and set.
 func GenerateInstance(
 	cluster *v1beta1.PostgresCluster, set *v1beta1.PostgresInstanceSetSpec,
@@ -345,6 +400,15 @@ func InstancePostgresWALVolume(instance *appsv1.StatefulSet) metav1.ObjectMeta {
 	}
 }
 
+// InstancePostgresTempVolume returns the ObjectMeta for the PostgreSQL volume
+// of temporary files for instance.
+func InstancePostgresTempVolume(instance *appsv1.StatefulSet) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: instance.GetNamespace(),
+		Name:      instance.GetName() + "-pgtemp",
+	}
+}
+
 // MonitoringUserSecret returns ObjectMeta necessary to lookup the Secret
 // containing authentication credentials for monitoring tools.
 func MonitoringUserSecret(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
@@ -454,6 +518,24 @@ func PGBackRestBackupJob(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
 	}
 }
 
+// PGBackRestRepoMigrationJob returns the ObjectMeta for the pgBackRest backup Job
+// that takes the first full backup in a repository replacing another
+func PGBackRestRepoMigrationJob(cluster *v1beta1.PostgresCluster, repoName string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      cluster.GetName() + "-" + repoName + "-migration",
+		Namespace: cluster.GetNamespace(),
+	}
+}
+
+// PGBackRestChangeProtectionJob returns the ObjectMeta for the pgBackRest backup Job
+// that is taken before a disruptive change to cluster
+func PGBackRestChangeProtectionJob(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Name:      cluster.GetName() + "-backup-change",
+		Namespace: cluster.GetNamespace(),
+	}
+}
+
 // PGBackRestCronJob returns the ObjectMeta for a pgBackRest CronJob
 func PGBackRestCronJob(cluster *v1beta1.PostgresCluster, backuptype, repoName string) metav1.ObjectMeta {
 	return metav1.ObjectMeta{
@@ -535,6 +617,39 @@ func PostgresUserSecret(cluster *v1beta1.PostgresCluster, username string) metav
 	}
 }
 
+// PostgresUserSecretVersion returns the ObjectMeta of one version of an
+// immutable Secret containing a PostgreSQL user and its connection information.
+func PostgresUserSecretVersion(
+	cluster *v1beta1.PostgresCluster, username string, version int32,
+) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      fmt.Sprintf("%s-pguser-%s-v%d", cluster.Name, username, version),
+	}
+}
+
+// CurrentPostgresUserSecret returns the ObjectMeta necessary to lookup the
+// Secret containing the current connection information of a PostgreSQL user.
+// This is the version recorded in status when the user Secrets of cluster
+// are immutable.
+func CurrentPostgresUserSecret(cluster *v1beta1.PostgresCluster, username string) metav1.ObjectMeta {
+	for _, status := range cluster.Status.UserSecrets {
+		if string(status.Name) == username {
+			return metav1.ObjectMeta{Namespace: cluster.Namespace, Name: status.Secret}
+		}
+	}
+	return PostgresUserSecret(cluster, username)
+}
+
+// PostgresUserCertSecret returns the ObjectMeta for the Secret that contains
+// the client certificate of a PostgreSQL user.
+func PostgresUserCertSecret(cluster *v1beta1.PostgresCluster, username string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.Namespace,
+		Name:      cluster.Name + "-pgusercert-" + username,
+	}
+}
+
 // PostgresTLSSecret returns the ObjectMeta necessary to lookup the Secret
 // containing the default Postgres TLS certificates and key
 func PostgresTLSSecret(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
@@ -552,6 +667,115 @@ func MovePGDataDirJob(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
 	}
 }
 
+// DataChecksumsEnableJob returns the ObjectMeta for the Job that enables data
+// page checksums on the volumes of instance.
+func DataChecksumsEnableJob(cluster *v1beta1.PostgresCluster, instance string) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.GetNamespace(),
+		Name:      instance + "-checksums",
+	}
+}
+
+// DataChecksumsVerifyCronJob returns the ObjectMeta for the CronJob that
+// verifies the data page checksums of cluster.
+func DataChecksumsVerifyCronJob(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.GetNamespace(),
+		Name:      cluster.Name + "-checksums-verify",
+	}
+}
+
+// FaultRepoHostNetworkPolicy returns the ObjectMeta for the NetworkPolicy that
+// isolates the pgBackRest repository host of cluster.
+func FaultRepoHostNetworkPolicy(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.GetNamespace(),
+		Name:      cluster.Name + "-fault-repo-host",
+	}
+}
+
+// PGCloneCronJob returns the ObjectMeta for the CronJob that schedules the
+// refreshes of clone.
+func PGCloneCronJob(clone *v1beta1.PGClone) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: clone.GetNamespace(),
+		Name:      clone.Name + "-pgclone",
+	}
+}
+
+// DatabaseClaimSecret returns the ObjectMeta for the Secret that contains the
+// connection details of the database requested by claim.
+func DatabaseClaimSecret(claim *v1beta1.PostgresDatabaseClaim) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: claim.GetNamespace(),
+		Name:      claim.Name + "-connection",
+	}
+}
+
+// HealthProbesCronJob returns the ObjectMeta for the CronJob that runs
+// synthetic transactions against cluster.
+func HealthProbesCronJob(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.GetNamespace(),
+		Name:      cluster.Name + "-health-probe",
+	}
+}
+
+// HealthProbesSecret returns the ObjectMeta for the Secret that contains the
+// password of the health probe user.
+func HealthProbesSecret(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.GetNamespace(),
+		Name:      cluster.Name + "-health-probe",
+	}
+}
+
+// IntegrityChecksCronJob returns the ObjectMeta for the CronJob that checks
+// the tables and indexes of cluster for corruption.
+func IntegrityChecksCronJob(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.GetNamespace(),
+		Name:      cluster.Name + "-amcheck",
+	}
+}
+
+// MaintenanceCronJob returns the ObjectMeta for the CronJob that rewrites the
+// bloated tables of cluster.
+func MaintenanceCronJob(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.GetNamespace(),
+		Name:      cluster.Name + "-maintenance",
+	}
+}
+
+// MaintenanceSecret returns the ObjectMeta for the Secret that contains the
+// client certificate of the maintenance user, which is used by the Jobs that
+// check, rewrite, and partition tables.
+func MaintenanceSecret(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.GetNamespace(),
+		Name:      cluster.Name + "-maintenance-cert",
+	}
+}
+
+// PartitioningCronJob returns the ObjectMeta for the CronJob that maintains
+// the partitioned tables of cluster.
+func PartitioningCronJob(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.GetNamespace(),
+		Name:      cluster.Name + "-partman",
+	}
+}
+
+// PostUpgradeSecret returns the ObjectMeta for the Secret that contains the
+// client certificate used by the Jobs that run after a major upgrade.
+func PostUpgradeSecret(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: cluster.GetNamespace(),
+		Name:      cluster.Name + "-upgrade-cert",
+	}
+}
+
 // MovePGWALDirJob returns the ObjectMeta for a pg_wal directory move Job
 func MovePGWALDirJob(cluster *v1beta1.PostgresCluster) metav1.ObjectMeta {
 	return metav1.ObjectMeta{
@@ -587,6 +811,15 @@ func StandalonePGAdmin(pgadmin *v1beta1.PGAdmin) metav1.ObjectMeta {
 	}
 }
 
+// StandalonePGAdminStorage returns the ObjectMeta necessary to lookup the
+// Volume for the files of pgAdmin users.
+func StandalonePGAdminStorage(pgadmin *v1beta1.PGAdmin) metav1.ObjectMeta {
+	return metav1.ObjectMeta{
+		Namespace: pgadmin.Namespace,
+		Name:      fmt.Sprintf("pgadmin-%s-storage", pgadmin.UID),
+	}
+}
+
 // UpgradeCheckConfigMap returns the ObjectMeta for the PGO ConfigMap
 func UpgradeCheckConfigMap() metav1.ObjectMeta {
 	return metav1.ObjectMeta{
@@ -105,6 +105,11 @@ func TestClusterNamesUniqueAndValid(t *testing.T) {
 
 	t.Run("CronJobs", func(t *testing.T) {
 		testUniqueAndValid(t, []test{
+			{"DataChecksumsVerifyCronJob", DataChecksumsVerifyCronJob(cluster)},
+			{"HealthProbesCronJob", HealthProbesCronJob(cluster)},
+			{"IntegrityChecksCronJob", IntegrityChecksCronJob(cluster)},
+			{"MaintenanceCronJob", MaintenanceCronJob(cluster)},
+			{"PartitioningCronJob", PartitioningCronJob(cluster)},
 			{"PGBackRestCronJon", PGBackRestCronJob(cluster, "full", "repo1")},
 			{"PGBackRestCronJon", PGBackRestCronJob(cluster, "incr", "repo2")},
 			{"PGBackRestCronJon", PGBackRestCronJob(cluster, "diff", "repo3")},
@@ -112,15 +117,24 @@ func TestClusterNamesUniqueAndValid(t *testing.T) {
 		})
 	})
 
+	t.Run("ScaledObjects", func(t *testing.T) {
+		testUniqueAndValid(t, []test{
+			{"ClusterDemandScaledObject", ClusterDemandScaledObject(cluster)},
+		})
+	})
+
 	t.Run("Deployments", func(t *testing.T) {
 		testUniqueAndValid(t, []test{
 			{"ClusterPGBouncer", ClusterPGBouncer(cluster)},
+			{"ClusterWALStreaming", ClusterWALStreaming(cluster)},
 		})
 	})
 
 	t.Run("Jobs", func(t *testing.T) {
 		testUniqueAndValid(t, []test{
+			{"DataChecksumsEnableJob", DataChecksumsEnableJob(cluster, "pg0-set-1-abcd")},
 			{"PGBackRestBackupJob", PGBackRestBackupJob(cluster)},
+			{"PGBackRestRepoMigrationJob", PGBackRestRepoMigrationJob(cluster, "repo2")},
 			{"PGBackRestRestoreJob", PGBackRestRestoreJob(cluster)},
 		})
 	})
@@ -150,6 +164,8 @@ func TestClusterNamesUniqueAndValid(t *testing.T) {
 		names := testUniqueAndValid(t, []test{
 			{"ClusterPGBouncer", ClusterPGBouncer(cluster)},
 			{"DeprecatedPostgresUserSecret", DeprecatedPostgresUserSecret(cluster)},
+			{"HealthProbesSecret", HealthProbesSecret(cluster)},
+			{"MaintenanceSecret", MaintenanceSecret(cluster)},
 			{"PostgresTLSSecret", PostgresTLSSecret(cluster)},
 			{"ReplicationClientCertSecret", ReplicationClientCertSecret(cluster)},
 			{"PGBackRestSSHSecret", PGBackRestSSHSecret(cluster)},
@@ -174,6 +190,37 @@ func TestClusterNamesUniqueAndValid(t *testing.T) {
 				assert.Assert(t, !strings.HasPrefix(name, prefix), "%q may collide", name)
 			}
 		})
+
+		t.Run("PostgresUserSecretVersion", func(t *testing.T) {
+			value := PostgresUserSecretVersion(cluster, "some-user", 12)
+
+			assert.Equal(t, value.Namespace, cluster.Namespace)
+			assert.Equal(t, value.Name, PostgresUserSecret(cluster, "some-user").Name+"-v12")
+			assert.Assert(t, nil == validation.IsDNS1123Label(value.Name))
+
+			// The current version is recorded in status.
+			assert.Equal(t, CurrentPostgresUserSecret(cluster, "some-user").Name,
+				PostgresUserSecret(cluster, "some-user").Name)
+
+			cluster := cluster.DeepCopy()
+			cluster.Status.UserSecrets = []v1beta1.PostgresUserSecretStatus{
+				{Name: "some-user", Secret: value.Name, Version: 12},
+			}
+			assert.Equal(t, CurrentPostgresUserSecret(cluster, "some-user").Name, value.Name)
+		})
+
+		t.Run("PostgresUserCertSecret", func(t *testing.T) {
+			value := PostgresUserCertSecret(cluster, "some-user")
+
+			assert.Equal(t, value.Namespace, cluster.Namespace)
+			assert.Assert(t, nil == validation.IsDNS1123Label(value.Name))
+
+			prefix := PostgresUserCertSecret(cluster, "").Name
+			assert.Assert(t, !strings.HasPrefix(prefix, PostgresUserSecret(cluster, "").Name))
+			for _, name := range names.List() {
+				assert.Assert(t, !strings.HasPrefix(name, prefix), "%q may collide", name)
+			}
+		})
 	})
 
 	t.Run("ServiceAccounts", func(t *testing.T) {
@@ -207,6 +254,7 @@ func TestClusterNamesUniqueAndValid(t *testing.T) {
 		testUniqueAndValid(t, []test{
 			{"ClusterPGAdmin", ClusterPGAdmin(cluster)},
 			{"PGBackRestRepoVolume", PGBackRestRepoVolume(cluster, repoName)},
+			{"ClusterWALStreaming", ClusterWALStreaming(cluster)},
 		})
 	})
 }
@@ -243,6 +291,7 @@ func TestInstanceNamesUniqueAndValid(t *testing.T) {
 		for _, tt := range []test{
 			{"InstancePostgresDataVolume", InstancePostgresDataVolume(instance)},
 			{"InstancePostgresWALVolume", InstancePostgresWALVolume(instance)},
+			{"InstancePostgresTempVolume", InstancePostgresTempVolume(instance)},
 		} {
 			t.Run(tt.name, func(t *testing.T) {
 				assert.Equal(t, tt.value.Namespace, instance.Namespace)
@@ -0,0 +1,179 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package osb implements the Open Service Broker API so that platforms that
+// consume databases through a broker can provision and bind PostgresClusters.
+// - https://github.com/openservicebrokerapi/servicebroker/blob/v2.17/spec.md
+package osb
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/crunchydata/postgres-operator/internal/config"
+	"github.com/crunchydata/postgres-operator/internal/logging"
+)
+
+// Broker serves the Open Service Broker API. Each service instance is a
+// PostgresCluster created from the PostgresClusterTemplate named by its plan.
+type Broker struct {
+	Client client.Client
+
+	// Address is the TCP address on which to listen, such as ":8443".
+	Address string
+
+	// CertFile and KeyFile are the paths to a TLS certificate and key. They
+	// are required because every request carries credentials.
+	CertFile, KeyFile string
+
+	// Namespace is where templates are read and instances are created.
+	Namespace string
+
+	// Username and Password are the HTTP basic authentication credentials
+	// that the platform sends with every request.
+	Username, Password string
+}
+
+// ManagedBroker creates a [Broker] and adds it to m. Instances are created in
+// the namespace of the operator when namespace is empty.
+func ManagedBroker(
+	m manager.Manager, address, certFile, keyFile, namespace, username, password string,
+) error {
+	if username == "" || password == "" {
+		return errors.New("the service broker requires a username and password")
+	}
+	if certFile == "" || keyFile == "" {
+		return errors.New("the service broker requires a TLS certificate and key")
+	}
+	if namespace == "" {
+		namespace = config.PGONamespace()
+	}
+
+	return m.Add(&Broker{
+		Client:    m.GetClient(),
+		Address:   address,
+		CertFile:  certFile,
+		KeyFile:   keyFile,
+		Namespace: namespace,
+		Username:  username,
+		Password:  password,
+	})
+}
+
+// NeedLeaderElection returns false so that every [manager.Manager] serves
+// requests. Each request reads and writes the Kubernetes API directly.
+func (b *Broker) NeedLeaderElection() bool { return false }
+
+// Start serves requests over TLS until ctx is cancelled.
+func (b *Broker) Start(ctx context.Context) error {
+	if b.CertFile == "" || b.KeyFile == "" {
+		return errors.New("the service broker requires a TLS certificate and key")
+	}
+
+	server := &http.Server{
+		Addr:              b.Address,
+		Handler:           b,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(_ net.Listener) context.Context { return ctx },
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdown)
+	}()
+
+	logging.FromContext(ctx).Info("serving the Open Service Broker API", "address", b.Address)
+
+	if err := server.ListenAndServeTLS(b.CertFile, b.KeyFile); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// ServeHTTP authenticates and routes one request of the Open Service Broker API.
+func (b *Broker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	username, password, ok := r.BasicAuth()
+	if !ok ||
+		subtle.ConstantTimeCompare([]byte(username), []byte(b.Username)) != 1 ||
+		subtle.ConstantTimeCompare([]byte(password), []byte(b.Password)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Basic realm="postgres-operator"`)
+		respond(w, http.StatusUnauthorized, failure("", "Unauthorized"))
+		return
+	}
+
+	// Platforms send the version of the API they expect, and this broker
+	// implements version 2.
+	if version := r.Header.Get("X-Broker-API-Version"); !strings.HasPrefix(version, "2.") {
+		respond(w, http.StatusPreconditionFailed,
+			failure("", "X-Broker-API-Version 2.x is required"))
+		return
+	}
+
+	// The paths are
+	//   /v2/catalog
+	//   /v2/service_instances/:instance
+	//   /v2/service_instances/:instance/last_operation
+	//   /v2/service_instances/:instance/service_bindings/:binding
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	switch {
+	case len(parts) == 2 && parts[0] == "v2" && parts[1] == "catalog" &&
+		r.Method == http.MethodGet:
+		b.catalog(w, r)
+
+	case len(parts) < 3 || parts[0] != "v2" || parts[1] != "service_instances":
+		respond(w, http.StatusNotFound, failure("", "Not found"))
+
+	case len(parts) == 3 && r.Method == http.MethodPut:
+		b.provision(w, r, parts[2])
+	case len(parts) == 3 && r.Method == http.MethodDelete:
+		b.deprovision(w, r, parts[2])
+	case len(parts) == 4 && parts[3] == "last_operation" && r.Method == http.MethodGet:
+		b.lastOperation(w, r, parts[2])
+	case len(parts) == 5 && parts[3] == "service_bindings" && r.Method == http.MethodPut:
+		b.bind(w, r, parts[2], parts[4])
+	case len(parts) == 5 && parts[3] == "service_bindings" && r.Method == http.MethodDelete:
+		b.unbind(w, r, parts[2], parts[4])
+
+	default:
+		respond(w, http.StatusMethodNotAllowed, failure("", "Method not allowed"))
+	}
+}
+
+// respond writes status and body as JSON.
+func respond(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// failure returns the body of an error response. The code is one that
+// platforms recognize, such as "AsyncRequired", or empty.
+func failure(code, description string) map[string]string {
+	body := map[string]string{"description": description}
+	if code != "" {
+		body["error"] = code
+	}
+	return body
+}
@@ -0,0 +1,211 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package osb
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/crunchydata/postgres-operator/internal/controller/runtime"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestBrokerStart(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// Credentials are never sent in plain text.
+	broker := &Broker{Address: "127.0.0.1:0", Username: "platform", Password: "secret"}
+	assert.ErrorContains(t, broker.Start(ctx), "requires a TLS certificate")
+}
+
+func TestBroker(t *testing.T) {
+	ctx := context.Background()
+	scheme, err := runtime.CreatePostgresOperatorScheme()
+	assert.NilError(t, err)
+
+	template := &v1beta1.PostgresClusterTemplate{}
+	template.Namespace, template.Name = "brokered", "small"
+
+	broker := &Broker{
+		Client:    fake.NewClientBuilder().WithScheme(scheme).WithObjects(template).Build(),
+		Namespace: "brokered",
+		Username:  "platform",
+		Password:  "secret",
+	}
+
+	call := func(method, path, body string) (int, map[string]any) {
+		request := httptest.NewRequest(method, path, strings.NewReader(body))
+		request.SetBasicAuth("platform", "secret")
+		request.Header.Set("X-Broker-API-Version", "2.17")
+
+		recorder := httptest.NewRecorder()
+		broker.ServeHTTP(recorder, request)
+
+		var decoded map[string]any
+		assert.NilError(t, json.Unmarshal(recorder.Body.Bytes(), &decoded))
+		return recorder.Code, decoded
+	}
+
+	t.Run("Authentication", func(t *testing.T) {
+		request := httptest.NewRequest(http.MethodGet, "/v2/catalog", nil)
+		request.Header.Set("X-Broker-API-Version", "2.17")
+		request.SetBasicAuth("platform", "wrong")
+
+		recorder := httptest.NewRecorder()
+		broker.ServeHTTP(recorder, request)
+		assert.Equal(t, recorder.Code, http.StatusUnauthorized)
+
+		request.SetBasicAuth("platform", "secret")
+		request.Header.Set("X-Broker-API-Version", "1.0")
+		recorder = httptest.NewRecorder()
+		broker.ServeHTTP(recorder, request)
+		assert.Equal(t, recorder.Code, http.StatusPreconditionFailed)
+	})
+
+	t.Run("Catalog", func(t *testing.T) {
+		code, body := call(http.MethodGet, "/v2/catalog", "")
+		assert.Equal(t, code, http.StatusOK)
+
+		services := body["services"].([]any)
+		assert.Equal(t, len(services), 1)
+		plans := services[0].(map[string]any)["plans"].([]any)
+		assert.Equal(t, len(plans), 1)
+		assert.Equal(t, plans[0].(map[string]any)["id"], "small")
+	})
+
+	const instance = "/v2/service_instances/a1b2c3"
+	const provision = `{"service_id":"postgres-operator-postgresql","plan_id":"small"}`
+
+	t.Run("Provision", func(t *testing.T) {
+		code, body := call(http.MethodPut, instance, provision)
+		assert.Equal(t, code, http.StatusUnprocessableEntity)
+		assert.Equal(t, body["error"], "AsyncRequired")
+
+		code, _ = call(http.MethodPut, instance+"?accepts_incomplete=true", `{}`)
+		assert.Equal(t, code, http.StatusBadRequest)
+
+		code, body = call(http.MethodPut, instance+"?accepts_incomplete=true", provision)
+		assert.Equal(t, code, http.StatusAccepted)
+		assert.Equal(t, body["operation"], "provision")
+
+		cluster := &v1beta1.PostgresCluster{}
+		assert.NilError(t, broker.Client.Get(ctx,
+			client.ObjectKey{Namespace: "brokered", Name: "osb-a1b2c3"}, cluster))
+		assert.Equal(t, cluster.Labels[naming.LabelServiceInstance], "a1b2c3")
+		assert.Equal(t, cluster.Spec.TemplateName, "small")
+
+		// The same request is accepted again; another plan conflicts.
+		code, _ = call(http.MethodPut, instance+"?accepts_incomplete=true", provision)
+		assert.Equal(t, code, http.StatusAccepted)
+		code, _ = call(http.MethodPut, instance+"?accepts_incomplete=true",
+			strings.Replace(provision, "small", "large", 1))
+		assert.Equal(t, code, http.StatusConflict)
+	})
+
+	t.Run("LastOperation", func(t *testing.T) {
+		code, body := call(http.MethodGet, instance+"/last_operation", "")
+		assert.Equal(t, code, http.StatusOK)
+		assert.Equal(t, body["state"], "in progress")
+
+		cluster := &v1beta1.PostgresCluster{}
+		assert.NilError(t, broker.Client.Get(ctx,
+			client.ObjectKey{Namespace: "brokered", Name: "osb-a1b2c3"}, cluster))
+		cluster.Status.InstanceSets = []v1beta1.PostgresInstanceSetStatus{{Name: "00", ReadyReplicas: 1}}
+		assert.NilError(t, broker.Client.Status().Update(ctx, cluster))
+
+		code, body = call(http.MethodGet, instance+"/last_operation", "")
+		assert.Equal(t, code, http.StatusOK)
+		assert.Equal(t, body["state"], "succeeded")
+
+		code, _ = call(http.MethodGet, "/v2/service_instances/missing/last_operation", "")
+		assert.Equal(t, code, http.StatusGone)
+	})
+
+	t.Run("Bind", func(t *testing.T) {
+		code, body := call(http.MethodPut, instance+"/service_bindings/b1", `{}`)
+		assert.Equal(t, code, http.StatusUnprocessableEntity)
+		assert.Equal(t, body["error"], "ConcurrencyError")
+
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Namespace: "brokered", Name: "osb-a1b2c3-pguser-app",
+		}}
+		secret.Data = map[string][]byte{
+			"host": []byte("osb-a1b2c3-primary.brokered.svc"), "port": []byte("5432"),
+			"user": []byte("app"), "password": []byte("pw"),
+		}
+		assert.NilError(t, broker.Client.Create(ctx, secret))
+
+		const bind = `{"service_id":"postgres-operator-postgresql","plan_id":"small","parameters":{"b":1,"a":2}}`
+		code, body = call(http.MethodPut, instance+"/service_bindings/b1", bind)
+		assert.Equal(t, code, http.StatusCreated)
+		credentials := body["credentials"].(map[string]any)
+		assert.Equal(t, credentials["uri"], "postgresql://app:pw@osb-a1b2c3-primary.brokered.svc:5432/app")
+
+		// The same binding is returned again, even when its parameters arrive
+		// in another order; other parameters conflict.
+		code, body = call(http.MethodPut, instance+"/service_bindings/b1",
+			`{"plan_id":"small","parameters":{"a":2,"b":1},"service_id":"postgres-operator-postgresql"}`)
+		assert.Equal(t, code, http.StatusOK)
+		assert.DeepEqual(t, body["credentials"], credentials)
+
+		code, _ = call(http.MethodPut, instance+"/service_bindings/b1", `{}`)
+		assert.Equal(t, code, http.StatusConflict)
+
+		code, _ = call(http.MethodPut, instance+"/service_bindings/b2", `{}`)
+		assert.Equal(t, code, http.StatusCreated)
+
+		code, _ = call(http.MethodDelete, instance+"/service_bindings/b1", "")
+		assert.Equal(t, code, http.StatusOK)
+		code, _ = call(http.MethodDelete, instance+"/service_bindings/b1", "")
+		assert.Equal(t, code, http.StatusGone)
+
+		cluster := &v1beta1.PostgresCluster{}
+		assert.NilError(t, broker.Client.Get(ctx,
+			client.ObjectKey{Namespace: "brokered", Name: "osb-a1b2c3"}, cluster))
+		assert.Assert(t, cmp.Contains(cluster.Annotations[naming.ServiceBindings], `"b2"`))
+		assert.Assert(t, !strings.Contains(cluster.Annotations[naming.ServiceBindings], `"b1"`))
+	})
+
+	t.Run("Deprovision", func(t *testing.T) {
+		// Clusters that the broker did not create are never deleted.
+		other := v1beta1.NewPostgresCluster()
+		other.Namespace, other.Name = "brokered", "osb-other"
+		assert.NilError(t, broker.Client.Create(ctx, other))
+
+		code, _ := call(http.MethodDelete, "/v2/service_instances/other?accepts_incomplete=true", "")
+		assert.Equal(t, code, http.StatusGone)
+		assert.NilError(t, broker.Client.Get(ctx, client.ObjectKeyFromObject(other), other))
+
+		code, body := call(http.MethodDelete, instance+"?accepts_incomplete=true", "")
+		assert.Equal(t, code, http.StatusAccepted)
+		assert.Equal(t, body["operation"], "deprovision")
+		assert.Assert(t, apierrors.IsNotFound(broker.Client.Get(ctx,
+			client.ObjectKey{Namespace: "brokered", Name: "osb-a1b2c3"}, &v1beta1.PostgresCluster{})))
+	})
+}
@@ -0,0 +1,353 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package osb
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	// serviceID identifies the one service that this broker offers.
+	serviceID = "postgres-operator-postgresql"
+
+	// bindingUser is the PostgreSQL user and database of every instance. All
+	// bindings of an instance share its credentials.
+	bindingUser = "app"
+)
+
+// instanceCluster returns the PostgresCluster of the service instance id.
+func (b *Broker) instanceCluster(id string) *v1beta1.PostgresCluster {
+	cluster := v1beta1.NewPostgresCluster()
+	cluster.Namespace, cluster.Name = b.Namespace, "osb-"+id
+	return cluster
+}
+
+// getInstance reads the PostgresCluster of the service instance id. It returns
+// a NotFound error when the cluster exists but was not created by this broker.
+func (b *Broker) getInstance(r *http.Request, id string) (*v1beta1.PostgresCluster, error) {
+	cluster := b.instanceCluster(id)
+	err := b.Client.Get(r.Context(), client.ObjectKeyFromObject(cluster), cluster)
+
+	if err == nil && cluster.Labels[naming.LabelServiceInstance] != id {
+		err = apierrors.NewNotFound(
+			v1beta1.GroupVersion.WithResource("postgresclusters").GroupResource(), cluster.Name)
+	}
+	return cluster, err
+}
+
+// +kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclustertemplates",verbs={list}
+
+// catalog responds with the service of this broker. Its plans are the
+// PostgresClusterTemplates in the namespace of the broker.
+func (b *Broker) catalog(w http.ResponseWriter, r *http.Request) {
+	templates := &v1beta1.PostgresClusterTemplateList{}
+	if err := b.Client.List(r.Context(), templates, client.InNamespace(b.Namespace)); err != nil {
+		logging.FromContext(r.Context()).Error(err, "listing PostgresClusterTemplates")
+		respond(w, http.StatusInternalServerError, failure("", err.Error()))
+		return
+	}
+
+	plans := make([]map[string]any, 0, len(templates.Items))
+	for _, template := range templates.Items {
+		plans = append(plans, map[string]any{
+			"id":          template.Name,
+			"name":        template.Name,
+			"description": fmt.Sprintf("PostgresCluster from template %q", template.Name),
+		})
+	}
+
+	respond(w, http.StatusOK, map[string]any{
+		"services": []map[string]any{{
+			"id":          serviceID,
+			"name":        "postgresql",
+			"description": "PostgreSQL managed by Crunchy Postgres for Kubernetes",
+			"bindable":    true,
+			"tags":        []string{"postgresql", "relational"},
+			"plans":       plans,
+		}},
+	})
+}
+
+// +kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={get,create}
+
+// provision creates the PostgresCluster of the service instance id from the
+// template named by its plan. Clusters take a while to start, so platforms
+// must accept an incomplete result and poll the last operation.
+func (b *Broker) provision(w http.ResponseWriter, r *http.Request, id string) {
+	var request struct {
+		ServiceID string `json:"service_id"`
+		PlanID    string `json:"plan_id"`
+	}
+
+	if r.URL.Query().Get("accepts_incomplete") != "true" {
+		respond(w, http.StatusUnprocessableEntity,
+			failure("AsyncRequired", "Provisioning a PostgresCluster is asynchronous"))
+		return
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil ||
+		request.ServiceID != serviceID || request.PlanID == "" {
+		respond(w, http.StatusBadRequest, failure("", "A service_id and plan_id are required"))
+		return
+	}
+
+	cluster := b.instanceCluster(id)
+	if errs := validation.IsDNS1035Label(cluster.Name); len(errs) > 0 {
+		respond(w, http.StatusBadRequest, failure("", fmt.Sprintf("Invalid instance ID: %v", errs)))
+		return
+	}
+
+	existing, err := b.getInstance(r, id)
+	switch {
+	case err == nil && existing.Spec.TemplateName != request.PlanID:
+		respond(w, http.StatusConflict, failure("", "The instance exists with another plan"))
+		return
+	case err == nil:
+		respond(w, http.StatusAccepted, map[string]string{"operation": "provision"})
+		return
+	case !apierrors.IsNotFound(err):
+		respond(w, http.StatusInternalServerError, failure("", err.Error()))
+		return
+	}
+
+	cluster.Labels = map[string]string{naming.LabelServiceInstance: id}
+	cluster.Spec.TemplateName = request.PlanID
+	cluster.Spec.Users = []v1beta1.PostgresUserSpec{{
+		Name: bindingUser, Databases: []v1beta1.PostgresIdentifier{bindingUser},
+	}}
+
+	if err := b.Client.Create(r.Context(), cluster); apierrors.IsAlreadyExists(err) {
+		respond(w, http.StatusConflict, failure("", "A PostgresCluster of that name exists"))
+	} else if err != nil {
+		respond(w, http.StatusInternalServerError, failure("", err.Error()))
+	} else {
+		respond(w, http.StatusAccepted, map[string]string{"operation": "provision"})
+	}
+}
+
+// +kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={delete}
+
+// deprovision deletes the PostgresCluster of the service instance id.
+func (b *Broker) deprovision(w http.ResponseWriter, r *http.Request, id string) {
+	if r.URL.Query().Get("accepts_incomplete") != "true" {
+		respond(w, http.StatusUnprocessableEntity,
+			failure("AsyncRequired", "Deleting a PostgresCluster is asynchronous"))
+		return
+	}
+
+	cluster, err := b.getInstance(r, id)
+	if err == nil {
+		err = b.Client.Delete(r.Context(), cluster, client.Preconditions{UID: &cluster.UID})
+	}
+
+	switch {
+	case apierrors.IsNotFound(err):
+		respond(w, http.StatusGone, map[string]string{})
+	case err != nil:
+		respond(w, http.StatusInternalServerError, failure("", err.Error()))
+	default:
+		respond(w, http.StatusAccepted, map[string]string{"operation": "deprovision"})
+	}
+}
+
+// lastOperation reports the progress of provisioning or deprovisioning the
+// service instance id. An instance is provisioned once one of its PostgreSQL
+// instances is ready.
+func (b *Broker) lastOperation(w http.ResponseWriter, r *http.Request, id string) {
+	cluster, err := b.getInstance(r, id)
+	if apierrors.IsNotFound(err) {
+		respond(w, http.StatusGone, map[string]string{})
+		return
+	} else if err != nil {
+		respond(w, http.StatusInternalServerError, failure("", err.Error()))
+		return
+	}
+
+	var ready int32
+	for _, set := range cluster.Status.InstanceSets {
+		ready += set.ReadyReplicas
+	}
+
+	state, description := "in progress", "Creating PostgreSQL"
+	switch {
+	case cluster.DeletionTimestamp != nil:
+		description = "Deleting PostgreSQL"
+	case meta.IsStatusConditionFalse(cluster.Status.Conditions, v1beta1.TemplateAvailable):
+		state = "failed"
+		description = meta.FindStatusCondition(cluster.Status.Conditions, v1beta1.TemplateAvailable).Message
+	case ready > 0:
+		state, description = "succeeded", "PostgreSQL is ready"
+	}
+
+	respond(w, http.StatusOK, map[string]string{"state": state, "description": description})
+}
+
+// serviceBindings returns the parameters of each binding of cluster keyed by
+// binding ID.
+func serviceBindings(cluster *v1beta1.PostgresCluster) map[string]string {
+	bindings := map[string]string{}
+	if value := cluster.Annotations[naming.ServiceBindings]; value != "" {
+		_ = json.Unmarshal([]byte(value), &bindings)
+	}
+	return bindings
+}
+
+// +kubebuilder:rbac:groups="postgres-operator.crunchydata.com",resources="postgresclusters",verbs={patch}
+
+// recordBindings stores bindings in cluster. It fails with a Conflict error when
+// cluster changed since it was read.
+func (b *Broker) recordBindings(
+	r *http.Request, cluster *v1beta1.PostgresCluster, bindings map[string]string,
+) error {
+	before := cluster.DeepCopy()
+	value, err := json.Marshal(bindings)
+
+	if err == nil {
+		if cluster.Annotations == nil {
+			cluster.Annotations = map[string]string{}
+		}
+		cluster.Annotations[naming.ServiceBindings] = string(value)
+
+		err = b.Client.Patch(r.Context(), cluster,
+			client.MergeFromWithOptions(before, client.MergeFromWithOptimisticLock{}))
+	}
+	return err
+}
+
+// +kubebuilder:rbac:groups="",resources="secrets",verbs={get}
+
+// bind responds with the credentials of the service instance id and records
+// the binding in its PostgresCluster. Binding again with identical parameters
+// responds the same way. Every binding receives the same user, so unbinding
+// revokes nothing.
+func (b *Broker) bind(w http.ResponseWriter, r *http.Request, id, binding string) {
+	var request struct {
+		ServiceID    string         `json:"service_id,omitempty"`
+		PlanID       string         `json:"plan_id,omitempty"`
+		AppGUID      string         `json:"app_guid,omitempty"`
+		BindResource map[string]any `json:"bind_resource,omitempty"`
+		Parameters   map[string]any `json:"parameters,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		respond(w, http.StatusBadRequest, failure("", "The request body is not valid JSON"))
+		return
+	}
+
+	// Marshaling sorts the keys of maps, so identical parameters encode the
+	// same way regardless of how they were sent.
+	parameters, _ := json.Marshal(request)
+
+	cluster, err := b.getInstance(r, id)
+	if apierrors.IsNotFound(err) {
+		respond(w, http.StatusNotFound, failure("", "The instance does not exist"))
+		return
+	}
+
+	bindings := serviceBindings(cluster)
+	previous, exists := bindings[binding]
+	if exists && previous != string(parameters) {
+		respond(w, http.StatusConflict, failure("", "The binding exists with other parameters"))
+		return
+	}
+
+	secret := &corev1.Secret{}
+	if err == nil {
+		err = b.Client.Get(r.Context(), client.ObjectKeyFromObject(&corev1.Secret{
+			ObjectMeta: naming.CurrentPostgresUserSecret(cluster, bindingUser),
+		}), secret)
+	}
+	if err == nil && !exists {
+		bindings[binding] = string(parameters)
+		err = b.recordBindings(r, cluster, bindings)
+	}
+	if apierrors.IsNotFound(err) {
+		respond(w, http.StatusUnprocessableEntity,
+			failure("ConcurrencyError", "The instance is not ready to be bound"))
+		return
+	} else if apierrors.IsConflict(err) {
+		respond(w, http.StatusUnprocessableEntity,
+			failure("ConcurrencyError", "The instance changed while being bound"))
+		return
+	} else if err != nil {
+		respond(w, http.StatusInternalServerError, failure("", err.Error()))
+		return
+	}
+
+	status := http.StatusCreated
+	if exists {
+		status = http.StatusOK
+	}
+
+	host, port := string(secret.Data["host"]), string(secret.Data["port"])
+	username, password := string(secret.Data["user"]), string(secret.Data["password"])
+
+	respond(w, status, map[string]any{
+		"credentials": map[string]string{
+			"hostname": host,
+			"port":     port,
+			"name":     bindingUser,
+			"username": username,
+			"password": password,
+			"uri": (&url.URL{
+				Scheme: "postgresql",
+				User:   url.UserPassword(username, password),
+				Host:   net.JoinHostPort(host, port),
+				Path:   bindingUser,
+			}).String(),
+		},
+	})
+}
+
+// unbind forgets a binding of the service instance id.
+func (b *Broker) unbind(w http.ResponseWriter, r *http.Request, id, binding string) {
+	cluster, err := b.getInstance(r, id)
+
+	bindings := map[string]string{}
+	if err == nil {
+		bindings = serviceBindings(cluster)
+	}
+	if _, exists := bindings[binding]; err == nil && !exists {
+		err = apierrors.NewNotFound(corev1.Resource("bindings"), binding)
+	}
+	if err == nil {
+		delete(bindings, binding)
+		err = b.recordBindings(r, cluster, bindings)
+	}
+
+	switch {
+	case apierrors.IsNotFound(err):
+		respond(w, http.StatusGone, map[string]string{})
+	case apierrors.IsConflict(err):
+		respond(w, http.StatusUnprocessableEntity,
+			failure("ConcurrencyError", "The instance changed while being unbound"))
+	case err != nil:
+		respond(w, http.StatusInternalServerError, failure("", err.Error()))
+	default:
+		respond(w, http.StatusOK, map[string]string{})
+	}
+}
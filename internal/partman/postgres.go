@@ -0,0 +1,246 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package partman
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// Schema is the PostgreSQL schema that contains the pg_partman extension.
+const Schema = "partman"
+
+// Enabled returns whether or not partitioning is requested for cluster.
+// pg_partman v5 supports PostgreSQL v14 and later.
+func Enabled(cluster *v1beta1.PostgresCluster) bool {
+	return cluster.Spec.Partitioning != nil && cluster.Spec.PostgresVersion >= 14
+}
+
+// Databases returns the distinct databases of tables in the order they appear.
+func Databases(tables []v1beta1.PartitionedTable) []string {
+	var databases []string
+	seen := make(map[string]bool)
+	for _, table := range tables {
+		if !seen[table.Database] {
+			seen[table.Database] = true
+			databases = append(databases, table.Database)
+		}
+	}
+	return databases
+}
+
+// DisableInPostgreSQL revokes the privileges on pg_partman that
+// EnableInPostgreSQL grants the maintenance user. The pg_partman extension and
+// its configuration are left in place because removing them does not remove
+// any partitions. Memberships in table owners are removed with the user.
+func DisableInPostgreSQL(ctx context.Context, exec postgres.Executor) error {
+	log := logging.FromContext(ctx)
+
+	stdout, stderr, err := exec.ExecInAllDatabases(ctx,
+		strings.TrimSpace(`
+SELECT pg_catalog.format('REVOKE ALL ON SCHEMA %1$I FROM %2$I;'
+    ' REVOKE ALL ON ALL TABLES IN SCHEMA %1$I FROM %2$I;'
+    ' REVOKE ALL ON ALL ROUTINES IN SCHEMA %1$I FROM %2$I', :'namespace', :'username')
+ WHERE EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = :'username')
+   AND EXISTS (SELECT 1 FROM pg_catalog.pg_namespace WHERE nspname = :'namespace')
+\gexec`),
+		map[string]string{
+			"namespace": Schema,
+			"username":  postgres.MaintenanceUser,
+
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("removed partman privileges", "stdout", stdout, "stderr", stderr)
+
+	return err
+}
+
+// EnableInPostgreSQL installs pg_partman into the databases of tables and
+// registers each table with it. The maintenance user becomes a member of each
+// table owner so that it can create and drop partitions. It returns the
+// tables that could not be registered because they do not exist yet, as
+// "database/table".
+func EnableInPostgreSQL(
+	ctx context.Context, exec postgres.Executor, tables []v1beta1.PartitionedTable,
+) ([]string, error) {
+	log := logging.FromContext(ctx)
+
+	type record struct {
+		Database  string  `json:"database"`
+		Table     string  `json:"table"`
+		Column    string  `json:"column"`
+		Interval  string  `json:"interval"`
+		Premake   int32   `json:"premake"`
+		Retention *string `json:"retention"`
+	}
+	records := make([]record, 0, len(tables))
+	for i := range tables {
+		table := tables[i]
+		r := record{
+			Database: table.Database, Table: table.Table,
+			Column: table.Column, Interval: table.Interval, Premake: 4,
+		}
+		if table.Premake != nil {
+			r.Premake = *table.Premake
+		}
+		if table.Retention != "" {
+			r.Retention = &table.Retention
+		}
+		records = append(records, r)
+	}
+
+	encodedTables, err := json.Marshal(records)
+	if err != nil {
+		return nil, err
+	}
+	encodedDatabases, err := json.Marshal(Databases(tables))
+	if err != nil {
+		return nil, err
+	}
+
+	sql := strings.Join([]string{
+		// Quiet NOTICE messages from IF NOT EXISTS statements.
+		// - https://www.postgresql.org/docs/current/runtime-config-client.html
+		`SET client_min_messages = WARNING;`,
+
+		// Prevent unexpected dereferences by emptying "search_path".
+		// - https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-SEARCH-PATH
+		`SET search_path TO '';`,
+
+		// Create the following objects in a transaction so that permissions
+		// are correct before any other session sees them.
+		`BEGIN;`,
+
+		`CREATE SCHEMA IF NOT EXISTS :"namespace";`,
+		`CREATE EXTENSION IF NOT EXISTS pg_partman SCHEMA :"namespace";`,
+		`GRANT ALL ON SCHEMA :"namespace" TO :"username";`,
+		`GRANT ALL ON ALL TABLES IN SCHEMA :"namespace" TO :"username";`,
+		`GRANT EXECUTE ON ALL FUNCTIONS IN SCHEMA :"namespace" TO :"username";`,
+		`GRANT EXECUTE ON ALL PROCEDURES IN SCHEMA :"namespace" TO :"username";`,
+
+		// Allow the user to create and drop partitions of each table. Tables
+		// owned by superusers are skipped; maintenance of those fails.
+		strings.TrimSpace(`
+SELECT DISTINCT pg_catalog.format('GRANT %I TO %I', r.rolname, :'username')
+  FROM pg_catalog.json_to_recordset(:'tables') AS t("database" text, "table" text)
+  JOIN pg_catalog.pg_class c ON c.oid = pg_catalog.to_regclass(t."table")
+  JOIN pg_catalog.pg_roles r ON r.oid = c.relowner
+ WHERE t."database" = pg_catalog.current_database()
+   AND NOT r.rolsuper
+\gexec`),
+
+		// Register tables that pg_partman does not yet know about.
+		strings.TrimSpace(`
+SELECT pg_catalog.format(
+         'SELECT %I.create_parent(p_parent_table := %L, p_control := %L, p_interval := %L, p_premake := %s)',
+         :'namespace', t."table", t."column", t."interval", t.premake)
+  FROM pg_catalog.json_to_recordset(:'tables')
+    AS t("database" text, "table" text, "column" text, "interval" text, premake int)
+ WHERE t."database" = pg_catalog.current_database()
+   AND pg_catalog.to_regclass(t."table") IS NOT NULL
+   AND NOT EXISTS (
+       SELECT 1 FROM :"namespace".part_config c WHERE c.parent_table = t."table")
+\gexec`),
+
+		// Keep the settings of every table up to date.
+		strings.TrimSpace(`
+UPDATE :"namespace".part_config c
+   SET premake = t.premake, retention = t.retention, retention_keep_table = false
+  FROM pg_catalog.json_to_recordset(:'tables')
+    AS t("database" text, "table" text, premake int, retention text)
+ WHERE t."database" = pg_catalog.current_database()
+   AND c.parent_table = t."table";`),
+
+		// Commit (finish) the transaction.
+		`COMMIT;`,
+
+		// Print the tables that are registered, one JSON object per line.
+		`\pset format unaligned`,
+		`\pset tuples_only on`,
+		strings.TrimSpace(`
+SELECT pg_catalog.json_build_object('database', t."database", 'table', t."table")
+  FROM pg_catalog.json_to_recordset(:'tables') AS t("database" text, "table" text)
+ WHERE t."database" = pg_catalog.current_database()
+   AND EXISTS (
+       SELECT 1 FROM :"namespace".part_config c WHERE c.parent_table = t."table");`),
+	}, "\n")
+
+	err = postgres.CreateMaintenanceUser(ctx, exec)
+	if err != nil {
+		return nil, err
+	}
+
+	stdout, stderr, err := exec.ExecInDatabasesFromQuery(ctx,
+		// Only databases that exist and contain a managed table.
+		`SELECT datname FROM pg_catalog.pg_database`+
+			` WHERE datallowconn AND datname IN (`+
+			`SELECT value FROM pg_catalog.json_array_elements_text(:'databases'))`,
+		sql,
+		map[string]string{
+			"databases": string(encodedDatabases),
+			"namespace": Schema,
+			"tables":    string(encodedTables),
+			"username":  postgres.MaintenanceUser,
+
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("wrote partman configuration", "stdout", stdout, "stderr", stderr)
+
+	registered := make(map[string]bool)
+	for _, line := range strings.Split(stdout, "\n") {
+		var r record
+		if json.Unmarshal([]byte(line), &r) == nil {
+			registered[r.Database+"/"+r.Table] = true
+		}
+	}
+
+	var unregistered []string
+	for _, r := range records {
+		if name := r.Database + "/" + r.Table; !registered[name] {
+			unregistered = append(unregistered, name)
+		}
+	}
+
+	return unregistered, err
+}
+
+// Command returns the command that runs pg_partman maintenance in every
+// database of cluster that has partitioned tables. It expects libpq
+// environment variables that connect to the primary.
+func Command(cluster *v1beta1.PostgresCluster) []string {
+	const script = `
+declare -r schema="$1"
+shift
+for database in "$@"; do
+  printf 'Maintaining partitions in database "%s"\n' "${database}"
+  psql -Xw --dbname="${database}" --set=ON_ERROR_STOP=on \
+    --set=namespace="${schema}" --file=- <<'SQL'
+CALL :"namespace".run_maintenance_proc();
+SQL
+done
+`
+	return append([]string{"bash", "-ceu", "--", script, "partman", Schema},
+		Databases(cluster.Spec.Partitioning.Tables)...)
+}
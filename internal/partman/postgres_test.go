@@ -0,0 +1,130 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package partman
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestEnabled(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.PostgresVersion = 14
+	assert.Assert(t, !Enabled(cluster))
+
+	cluster.Spec.Partitioning = new(v1beta1.PartitioningSpec)
+	assert.Assert(t, Enabled(cluster))
+
+	cluster.Spec.PostgresVersion = 13
+	assert.Assert(t, !Enabled(cluster))
+}
+
+func TestDatabases(t *testing.T) {
+	assert.Assert(t, Databases(nil) == nil)
+	assert.DeepEqual(t, Databases([]v1beta1.PartitionedTable{
+		{Database: "one", Table: "public.a"},
+		{Database: "two", Table: "public.b"},
+		{Database: "one", Table: "public.c"},
+	}), []string{"one", "two"})
+}
+
+func TestEnableInPostgreSQL(t *testing.T) {
+	expected := errors.New("whoops")
+	calls := 0
+	exec := func(
+		_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		// The first call creates the maintenance user.
+		if calls++; calls == 1 {
+			return nil
+		}
+
+		assert.Assert(t, stdout != nil, "should capture stdout")
+		assert.Assert(t, stderr != nil, "should capture stderr")
+
+		joined := strings.Join(command, "\n")
+		assert.Assert(t, cmp.Contains(joined, `--set=databases=["app"]`))
+		assert.Assert(t, cmp.Contains(joined,
+			`--set=tables=[{"database":"app","table":"public.events","column":"created_at","interval":"1 day","premake":4,"retention":"30 days"},`+
+				`{"database":"app","table":"public.logs","column":"id","interval":"1000","premake":10,"retention":null}]`))
+
+		b, err := io.ReadAll(stdin)
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(string(b), `CREATE EXTENSION IF NOT EXISTS pg_partman SCHEMA :"namespace";`))
+		assert.Assert(t, cmp.Contains(string(b), `create_parent(p_parent_table := %L`))
+		assert.Assert(t, cmp.Contains(string(b), `UPDATE :"namespace".part_config c`))
+
+		// Only one table exists.
+		_, _ = stdout.Write([]byte(`{"database" : "app", "table" : "public.events"}` + "\n"))
+		return expected
+	}
+
+	ctx := context.Background()
+	unregistered, err := EnableInPostgreSQL(ctx, exec, []v1beta1.PartitionedTable{
+		{
+			Database: "app", Table: "public.events", Column: "created_at",
+			Interval: "1 day", Retention: "30 days",
+		},
+		{
+			Database: "app", Table: "public.logs", Column: "id",
+			Interval: "1000", Premake: initialize.Int32(10),
+		},
+	})
+	assert.Equal(t, expected, err)
+	assert.DeepEqual(t, unregistered, []string{"app/public.logs"})
+}
+
+func TestDisableInPostgreSQL(t *testing.T) {
+	expected := errors.New("whoops")
+	exec := func(
+		_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+	) error {
+		b, err := io.ReadAll(stdin)
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(string(b), `REVOKE ALL ON SCHEMA %1$I FROM %2$I;`))
+		assert.Assert(t, cmp.Contains(strings.Join(command, "\n"),
+			`--set=username=_crunchymaintenance`))
+
+		return expected
+	}
+
+	ctx := context.Background()
+	assert.Equal(t, expected, DisableInPostgreSQL(ctx, exec))
+}
+
+func TestCommand(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.Partitioning = &v1beta1.PartitioningSpec{
+		Tables: []v1beta1.PartitionedTable{
+			{Database: "one", Table: "public.a"},
+			{Database: "two", Table: "public.b"},
+		},
+	}
+
+	command := Command(cluster)
+	assert.DeepEqual(t, command[:3], []string{"bash", "-ceu", "--"})
+	assert.DeepEqual(t, command[4:], []string{"partman", "partman", "one", "two"})
+	assert.Assert(t, cmp.Contains(command[3], `CALL :"namespace".run_maintenance_proc();`))
+}
@@ -179,6 +179,64 @@ func (exec Executor) RestartPendingMembers(ctx context.Context, role, scope stri
 	return err
 }
 
+// RestartMembers restarts PostgreSQL in the named Patroni members of scope,
+// one at a time. When pending is true, only members that have a pending
+// restart are restarted. At least one member must be named.
+func (exec Executor) RestartMembers(
+	ctx context.Context, scope string, pending bool, members ...string,
+) error {
+	var stdout, stderr bytes.Buffer
+
+	if len(members) == 0 {
+		return errors.New("no members to restart")
+	}
+
+	// The following exits zero when it is able to read the DCS and communicate
+	// with the Patroni HTTP API. It prints the result of calling "POST /restart"
+	// on each member, which returns after PostgreSQL has started again.
+	// - https://github.com/zalando/patroni/blob/v2.1.1/patroni/ctl.py#L580-L596
+	command := []string{"patronictl", "restart", "--force"}
+	if pending {
+		command = append(command, "--pending")
+	}
+	command = append(append(command, scope), members...)
+
+	err := exec(ctx, nil, &stdout, &stderr, command...)
+
+	log := logging.FromContext(ctx)
+	log.V(1).Info("restarted members",
+		"stdout", stdout.String(),
+		"stderr", stderr.String(),
+	)
+
+	return err
+}
+
+// ReloadMembers reloads the PostgreSQL configuration in the named Patroni
+// members of scope. At least one member must be named.
+func (exec Executor) ReloadMembers(ctx context.Context, scope string, members ...string) error {
+	var stdout, stderr bytes.Buffer
+
+	if len(members) == 0 {
+		return errors.New("no members to reload")
+	}
+
+	// The following exits zero when it is able to read the DCS and communicate
+	// with the Patroni HTTP API. It prints the result of calling "POST /reload"
+	// on each member.
+	// - https://github.com/zalando/patroni/blob/v2.1.1/patroni/ctl.py#L544-L565
+	err := exec(ctx, nil, &stdout, &stderr,
+		append([]string{"patronictl", "reload", "--force", scope}, members...)...)
+
+	log := logging.FromContext(ctx)
+	log.V(1).Info("reloaded members",
+		"stdout", stdout.String(),
+		"stderr", stderr.String(),
+	)
+
+	return err
+}
+
 // GetTimeline gets the patronictl status and returns the timeline,
 // currently the only information required by PGO.
 // Returns zero if it runs into errors or cannot find a running Leader pod
@@ -237,6 +237,55 @@ func TestExecutorRestartPendingMembers(t *testing.T) {
 	assert.Equal(t, expected, actual, "should call exec")
 }
 
+func TestExecutorRestartMembers(t *testing.T) {
+	expected := errors.New("oop")
+	exec := func(
+		_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		assert.DeepEqual(t, command, strings.Fields(
+			`patronictl restart --force --pending shoe-scope left right`,
+		))
+		assert.Assert(t, stdin == nil, "expected no stdin, got %T", stdin)
+		assert.Assert(t, stderr != nil, "should capture stderr")
+		assert.Assert(t, stdout != nil, "should capture stdout")
+		return expected
+	}
+
+	actual := Executor(exec).RestartMembers(
+		context.Background(), "shoe-scope", true, "left", "right")
+
+	assert.Equal(t, expected, actual, "should call exec")
+
+	t.Run("NoMembers", func(t *testing.T) {
+		err := Executor(func(
+			context.Context, io.Reader, io.Writer, io.Writer, ...string,
+		) error {
+			panic("should not call exec")
+		}).RestartMembers(context.Background(), "shoe-scope", false)
+
+		assert.ErrorContains(t, err, "no members")
+	})
+}
+
+func TestExecutorReloadMembers(t *testing.T) {
+	expected := errors.New("oop")
+	exec := func(
+		_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		assert.DeepEqual(t, command, strings.Fields(
+			`patronictl reload --force shoe-scope left`,
+		))
+		assert.Assert(t, stdin == nil, "expected no stdin, got %T", stdin)
+		assert.Assert(t, stderr != nil, "should capture stderr")
+		assert.Assert(t, stdout != nil, "should capture stdout")
+		return expected
+	}
+
+	actual := Executor(exec).ReloadMembers(context.Background(), "shoe-scope", "left")
+
+	assert.Equal(t, expected, actual, "should call exec")
+}
+
 func TestExecutorGetTimeline(t *testing.T) {
 	t.Run("Error", func(t *testing.T) {
 		expected := errors.New("bang")
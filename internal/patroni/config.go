@@ -605,27 +605,56 @@ func instanceYAML(
 			}
 		} else {
 
+			encoding := "UTF8"
+			if spec := cluster.Spec.Initdb; spec != nil && spec.Encoding != "" {
+				encoding = spec.Encoding
+			}
+
 			initdb := []string{
-				// Enable checksums on data pages to help detect corruption of
-				// storage that would otherwise be silent. This also enables
-				// "wal_log_hints" which is a prerequisite for using `pg_rewind`.
-				// - https://www.postgresql.org/docs/current/app-initdb.html
-				// - https://www.postgresql.org/docs/current/app-pgrewind.html
-				// - https://www.postgresql.org/docs/current/runtime-config-wal.html
-				//
-				// The benefits of checksums in the Kubernetes storage landscape
-				// outweigh their negligible overhead, and enabling them later
-				// is costly. (Every file of the cluster must be rewritten.)
-				// PostgreSQL v12 introduced the `pg_checksums` utility which
-				// can cheaply disable them while PostgreSQL is stopped.
-				// - https://www.postgresql.org/docs/current/app-pgchecksums.html
-				"data-checksums",
-				"encoding=UTF8",
+				"encoding=" + encoding,
 
 				// NOTE(cbandy): The "--waldir" option was introduced in PostgreSQL v10.
 				"waldir=" + postgres.WALDirectory(cluster, instance),
 			}
 
+			// Enable checksums on data pages to help detect corruption of
+			// storage that would otherwise be silent. This also enables
+			// "wal_log_hints" which is a prerequisite for using `pg_rewind`.
+			// - https://www.postgresql.org/docs/current/app-initdb.html
+			// - https://www.postgresql.org/docs/current/app-pgrewind.html
+			// - https://www.postgresql.org/docs/current/runtime-config-wal.html
+			//
+			// The benefits of checksums in the Kubernetes storage landscape
+			// outweigh their negligible overhead, and enabling them later
+			// is costly. (Every file of the cluster must be rewritten.)
+			// PostgreSQL v12 introduced the `pg_checksums` utility which
+			// can cheaply disable them while PostgreSQL is stopped.
+			// - https://www.postgresql.org/docs/current/app-pgchecksums.html
+			if postgres.DataChecksumsEnabled(cluster) {
+				initdb = append([]string{"data-checksums"}, initdb...)
+			}
+
+			// Choose the locale and collation provider of the template databases
+			// and the size of WAL segments. None of these can change after
+			// initialization. Patroni prepends "--" to each of these.
+			// - https://www.postgresql.org/docs/current/app-initdb.html
+			if spec := cluster.Spec.Initdb; spec != nil {
+				for _, option := range []struct{ name, value string }{
+					{"locale", spec.Locale},
+					{"lc-collate", spec.LCCollate},
+					{"lc-ctype", spec.LCCType},
+					{"locale-provider", spec.LocaleProvider},
+					{"icu-locale", spec.ICULocale},
+				} {
+					if option.value != "" {
+						initdb = append(initdb, option.name+"="+option.value)
+					}
+				}
+				if spec.WALSegmentSize != nil {
+					initdb = append(initdb, fmt.Sprintf("wal-segsize=%d", *spec.WALSegmentSize))
+				}
+			}
+
 			// Append the encryption key command, if provided.
 			if ekc := config.FetchKeyCommand(&cluster.Spec); ekc != "" {
 				initdb = append(initdb, fmt.Sprintf("encryption-key-command=%s", ekc))
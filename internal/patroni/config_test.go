@@ -977,6 +977,42 @@ restapi: {}
 tags: {}
 	`, "\t\n")+"\n")
 
+	cluster.Spec.Patroni = nil
+	cluster.Spec.DataChecksums = &v1beta1.DataChecksumsSpec{
+		Enabled: initialize.Bool(false),
+	}
+
+	dataWithoutChecksums, err := instanceYAML(cluster, instance, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, cmp.Contains(dataWithoutChecksums, `
+  initdb:
+  - encoding=UTF8
+  - waldir=/pgdata/pg12_wal
+  method: initdb
+`))
+
+	cluster.Spec.Initdb = &v1beta1.InitdbSpec{
+		Encoding:       "LATIN1",
+		Locale:         "en_US.UTF-8",
+		LCCollate:      "C",
+		LocaleProvider: "icu",
+		ICULocale:      "en-US",
+		WALSegmentSize: initialize.Int32(64),
+	}
+
+	dataWithInitdb, err := instanceYAML(cluster, instance, nil)
+	assert.NilError(t, err)
+	assert.Assert(t, cmp.Contains(dataWithInitdb, `
+  initdb:
+  - encoding=LATIN1
+  - waldir=/pgdata/pg12_wal
+  - locale=en_US.UTF-8
+  - lc-collate=C
+  - locale-provider=icu
+  - icu-locale=en-US
+  - wal-segsize=64
+  method: initdb
+`))
 }
 
 func TestPGBackRestCreateReplicaCommand(t *testing.T) {
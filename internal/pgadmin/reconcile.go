@@ -291,6 +291,11 @@ func Pod(
 		},
 	}
 
+	// pgAdmin displays and logs times in the local time zone.
+	if tz := inCluster.Spec.TimeZone; tz != "" {
+		container.Env = append(container.Env, corev1.EnvVar{Name: "TZ", Value: tz})
+	}
+
 	startup := corev1.Container{
 		Name:    naming.ContainerPGAdminStartup,
 		Command: startupCommand(),
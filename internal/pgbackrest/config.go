@@ -22,6 +22,7 @@ import (
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/crunchydata/postgres-operator/internal/config"
@@ -111,7 +112,7 @@ func CreatePGBackRestConfigMapIntent(postgresCluster *v1beta1.PostgresCluster,
 			config.FetchKeyCommand(&postgresCluster.Spec),
 			strconv.Itoa(postgresCluster.Spec.PostgresVersion),
 			pgPort, postgresCluster.Spec.Backups.PGBackRest.Repos,
-			postgresCluster.Spec.Backups.PGBackRest.Global,
+			globalConfig(postgresCluster),
 		).String()
 
 	// As the cluster transitions from having a repository host to having none,
@@ -131,7 +132,7 @@ func CreatePGBackRestConfigMapIntent(postgresCluster *v1beta1.PostgresCluster,
 				strconv.Itoa(postgresCluster.Spec.PostgresVersion),
 				pgPort, instanceNames,
 				postgresCluster.Spec.Backups.PGBackRest.Repos,
-				postgresCluster.Spec.Backups.PGBackRest.Global,
+				globalConfig(postgresCluster),
 			).String()
 	}
 
@@ -140,6 +141,43 @@ func CreatePGBackRestConfigMapIntent(postgresCluster *v1beta1.PostgresCluster,
 	return cm
 }
 
+// ArchiveQueueMax returns the most bytes of WAL that postgresCluster keeps while
+// it cannot be archived. It is zero when WAL is kept until it is archived.
+// - https://pgbackrest.org/configuration.html#section-archive/option-archive-push-queue-max
+func ArchiveQueueMax(postgresCluster *v1beta1.PostgresCluster) int64 {
+	failure := postgresCluster.Spec.Backups.PGBackRest.ArchiveFailure
+	if failure == nil || failure.Policy != "Drop" || failure.MaxQueueSize == nil {
+		return 0
+	}
+	return failure.MaxQueueSize.Value()
+}
+
+// globalConfig returns the global pgBackRest options of postgresCluster. Backups and WAL are
+// not expired automatically while the WALExpirationHeld condition is true. WAL that cannot be
+// archived is dropped beyond [ArchiveQueueMax].
+func globalConfig(postgresCluster *v1beta1.PostgresCluster) map[string]string {
+	global := postgresCluster.Spec.Backups.PGBackRest.Global
+	held := meta.IsStatusConditionTrue(postgresCluster.Status.Conditions,
+		v1beta1.WALExpirationHeld)
+	queueMax := ArchiveQueueMax(postgresCluster)
+
+	if held || queueMax > 0 {
+		options := make(map[string]string, len(global)+2)
+		for k, v := range global {
+			options[k] = v
+		}
+		if held {
+			options["expire-auto"] = "n"
+		}
+		if queueMax > 0 {
+			options["archive-push-queue-max"] = fmt.Sprint(queueMax)
+		}
+		global = options
+	}
+
+	return global
+}
+
 // MakePGBackrestLogDir creates the pgBackRest default log path directory used when a
 // dedicated repo host is configured.
 func MakePGBackrestLogDir(template *corev1.PodTemplateSpec,
@@ -171,6 +209,41 @@ func MakePGBackrestLogDir(template *corev1.PodTemplateSpec,
 	template.Spec.InitContainers = append(template.Spec.InitContainers, container)
 }
 
+// RestoreDatabaseOptions returns the pgBackRest restore options that limit a restore to some
+// databases. Database names are quoted for the shell that runs [RestoreCommand].
+func RestoreDatabaseOptions(databases *v1beta1.PGBackRestRestoreDatabases) []string {
+	if databases == nil {
+		return nil
+	}
+
+	// https://www.gnu.org/software/bash/manual/html_node/Quoting.html
+	quote := func(s string) string { return `'` + strings.ReplaceAll(s, `'`, `'"'"'`) + `'` }
+
+	opts := make([]string, 0, len(databases.Include)+len(databases.Exclude))
+	for _, name := range databases.Include {
+		opts = append(opts, "--db-include="+quote(name))
+	}
+	for _, name := range databases.Exclude {
+		opts = append(opts, "--db-exclude="+quote(name))
+	}
+	return opts
+}
+
+// RestorePointOptions returns the pgBackRest restore options that stop recovery
+// at the restore point named name. The name is quoted for the shell that runs
+// [RestoreCommand].
+// - https://www.postgresql.org/docs/current/runtime-config-wal.html#GUC-RECOVERY-TARGET-NAME
+func RestorePointOptions(name string) []string {
+	if name == "" {
+		return nil
+	}
+
+	// https://www.gnu.org/software/bash/manual/html_node/Quoting.html
+	quote := func(s string) string { return `'` + strings.ReplaceAll(s, `'`, `'"'"'`) + `'` }
+
+	return []string{"--type=name", "--target=" + quote(name)}
+}
+
 // RestoreCommand returns the command for performing a pgBackRest restore.  In addition to calling
 // the pgBackRest restore command with any pgBackRest options provided, the script also does the
 // following:
@@ -426,11 +499,57 @@ func getExternalRepoConfigs(repo v1beta1.PGBackRestRepo) map[string]string {
 		repoConfigs[repo.Name+"-s3-bucket"] = repo.S3.Bucket
 		repoConfigs[repo.Name+"-s3-endpoint"] = repo.S3.Endpoint
 		repoConfigs[repo.Name+"-s3-region"] = repo.S3.Region
+	} else if repo.SFTP != nil {
+		repoConfigs[repo.Name+"-type"] = "sftp"
+		repoConfigs[repo.Name+"-sftp-host"] = repo.SFTP.Host
+		repoConfigs[repo.Name+"-sftp-host-user"] = repo.SFTP.User
+		repoConfigs[repo.Name+"-sftp-known-host"] =
+			configDirectory + "/" + sftpKnownHostsPath(repo.Name)
+		repoConfigs[repo.Name+"-sftp-private-key-file"] =
+			configDirectory + "/" + sftpPrivateKeyPath(repo.Name)
+
+		if repo.SFTP.Port != nil {
+			repoConfigs[repo.Name+"-sftp-host-port"] = fmt.Sprint(*repo.SFTP.Port)
+		}
+	}
+
+	if connection := repo.Connection; connection != nil {
+		if connection.CABundle != nil {
+			repoConfigs[repo.Name+"-storage-ca-file"] =
+				configDirectory + "/" + storageCertificateAuthorityPath(repo.Name)
+		}
+		if connection.Proxy != nil {
+			repoConfigs[repo.Name+"-storage-host"] = connection.Proxy.Host
+			if connection.Proxy.Port != nil {
+				repoConfigs[repo.Name+"-storage-port"] = fmt.Sprint(*connection.Proxy.Port)
+			}
+		}
+		if connection.VerifyTLS != nil && !*connection.VerifyTLS {
+			repoConfigs[repo.Name+"-storage-verify-tls"] = "n"
+		}
 	}
 
 	return repoConfigs
 }
 
+// storageCertificateAuthorityPath returns the path, relative to the configuration
+// directory, of the certificate authorities for the storage of repoName.
+func storageCertificateAuthorityPath(repoName string) string {
+	return repoName + "-storage-ca.crt"
+}
+
+// sftpKnownHostsPath returns the path, relative to the configuration directory,
+// of the SFTP server host keys of repoName.
+func sftpKnownHostsPath(repoName string) string {
+	return repoName + "-sftp/known_hosts"
+}
+
+// sftpPrivateKeyPath returns the path, relative to the configuration directory,
+// of the private key pgBackRest uses to log in to the SFTP server of repoName.
+func sftpPrivateKeyPath(repoName string) string {
+	return repoName + "-sftp/id"
+}
+
 // reloadCommand returns an entrypoint that convinces the pgBackRest TLS server
 // to reload its options and certificate files when they change. The process
 // will appear as name in `ps` and `top`.
@@ -513,6 +632,12 @@ func serverConfig(cluster *v1beta1.PostgresCluster) iniSectionSet {
 		global.Set("tls-server-address", "::")
 	}
 
+	// Pod DNS names resolve to addresses of the primary IP family of the
+	// cluster, so listen on the IPv6 wildcard address when that is IPv6.
+	if families := cluster.Spec.IPFamilies; len(families) > 0 && families[0] == corev1.IPv6Protocol {
+		global.Set("tls-server-address", "::")
+	}
+
 	// The client certificate for this cluster is allowed to connect for any stanza.
 	// Without the wildcard "*", the "pgbackrest info" and "pgbackrest repo-ls"
 	// commands fail with "access denied" when invoked without a "--stanza" flag.
@@ -26,7 +26,9 @@ import (
 	"gotest.tools/v3/assert"
 	"gotest.tools/v3/assert/cmp"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 
 	"github.com/crunchydata/postgres-operator/internal/initialize"
@@ -252,6 +254,164 @@ pg1-socket-path = /tmp/postgres
 			strings.Contains(configmap.Data["pgbackrest_repo.conf"],
 				"pg-version-force"))
 	})
+
+	t.Run("WALExpirationHeld", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Backups.PGBackRest.Global = map[string]string{
+			"repo1-retention-full": "2",
+		}
+		cluster.Spec.Backups.PGBackRest.Repos = []v1beta1.PGBackRestRepo{
+			{
+				Name:   "repo1",
+				Volume: &v1beta1.RepoPVC{},
+			},
+		}
+
+		configmap := CreatePGBackRestConfigMapIntent(cluster,
+			"repo1", "number", "pod-service-name", "test-ns",
+			[]string{"some-instance"})
+
+		assert.Assert(t,
+			!strings.Contains(configmap.Data["pgbackrest_instance.conf"], "expire-auto"))
+		assert.Assert(t,
+			!strings.Contains(configmap.Data["pgbackrest_repo.conf"], "expire-auto"))
+
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type:   v1beta1.WALExpirationHeld,
+			Status: metav1.ConditionTrue,
+		})
+
+		configmap = CreatePGBackRestConfigMapIntent(cluster,
+			"repo1", "number", "pod-service-name", "test-ns",
+			[]string{"some-instance"})
+
+		assert.Assert(t,
+			strings.Contains(configmap.Data["pgbackrest_instance.conf"], "expire-auto = n"))
+		assert.Assert(t,
+			strings.Contains(configmap.Data["pgbackrest_repo.conf"], "expire-auto = n"))
+
+		// The spec is not changed.
+		assert.DeepEqual(t, cluster.Spec.Backups.PGBackRest.Global, map[string]string{
+			"repo1-retention-full": "2",
+		})
+	})
+
+	t.Run("ArchiveFailure", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Backups.PGBackRest.Repos = []v1beta1.PGBackRestRepo{
+			{
+				Name:   "repo1",
+				Volume: &v1beta1.RepoPVC{},
+			},
+		}
+		cluster.Spec.Backups.PGBackRest.ArchiveFailure = &v1beta1.PGBackRestArchiveFailure{
+			Policy:       "Retain",
+			MaxQueueSize: resource.NewQuantity(1<<30, resource.BinarySI),
+		}
+		assert.Equal(t, ArchiveQueueMax(cluster), int64(0))
+
+		configmap := CreatePGBackRestConfigMapIntent(cluster,
+			"repo1", "number", "pod-service-name", "test-ns",
+			[]string{"some-instance"})
+
+		assert.Assert(t,
+			!strings.Contains(configmap.Data["pgbackrest_instance.conf"], "archive-push-queue-max"))
+
+		cluster.Spec.Backups.PGBackRest.ArchiveFailure.Policy = "Drop"
+		assert.Equal(t, ArchiveQueueMax(cluster), int64(1<<30))
+
+		configmap = CreatePGBackRestConfigMapIntent(cluster,
+			"repo1", "number", "pod-service-name", "test-ns",
+			[]string{"some-instance"})
+
+		assert.Assert(t, cmp.Contains(configmap.Data["pgbackrest_instance.conf"],
+			"archive-push-queue-max = 1073741824"))
+	})
+
+	t.Run("StorageConnection", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Backups.PGBackRest.Repos = []v1beta1.PGBackRestRepo{
+			{
+				Name:   "repo1",
+				Volume: &v1beta1.RepoPVC{},
+				Connection: &v1beta1.RepoConnection{
+					VerifyTLS: initialize.Bool(false),
+				},
+			},
+			{
+				Name: "repo2",
+				S3: &v1beta1.RepoS3{
+					Bucket: "s-bucket", Endpoint: "endpoint-s", Region: "earth",
+				},
+				Connection: &v1beta1.RepoConnection{
+					CABundle: &corev1.ConfigMapKeySelector{Key: "ca.crt"},
+					Proxy: &v1beta1.RepoProxy{
+						Host: "gateway.example.com", Port: initialize.Int32(8443),
+					},
+					VerifyTLS: initialize.Bool(false),
+				},
+			},
+			{
+				Name: "repo3",
+				GCS:  &v1beta1.RepoGCS{Bucket: "g-bucket"},
+				Connection: &v1beta1.RepoConnection{
+					Proxy:     &v1beta1.RepoProxy{Host: "10.0.0.1"},
+					VerifyTLS: initialize.Bool(true),
+				},
+			},
+		}
+
+		configmap := CreatePGBackRestConfigMapIntent(cluster,
+			"repo-hostname", "number", "pod-service-name", "test-ns",
+			[]string{"some-instance"})
+
+		for _, key := range []string{"pgbackrest_instance.conf", "pgbackrest_repo.conf"} {
+			config := configmap.Data[key]
+
+			assert.Assert(t, strings.Contains(config,
+				"repo2-storage-ca-file = /etc/pgbackrest/conf.d/repo2-storage-ca.crt\n"), key)
+			assert.Assert(t, strings.Contains(config,
+				"repo2-storage-host = gateway.example.com\n"), key)
+			assert.Assert(t, strings.Contains(config,
+				"repo2-storage-port = 8443\n"), key)
+			assert.Assert(t, strings.Contains(config,
+				"repo2-storage-verify-tls = n\n"), key)
+			assert.Assert(t, strings.Contains(config,
+				"repo3-storage-host = 10.0.0.1\n"), key)
+
+			// Volumes have no storage options, and verification is the default.
+			assert.Assert(t, !strings.Contains(config, "repo1-storage"), key)
+			assert.Assert(t, !strings.Contains(config, "repo3-storage-port"), key)
+			assert.Assert(t, !strings.Contains(config, "repo3-storage-verify-tls"), key)
+		}
+	})
+
+	t.Run("SFTPRepo", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Backups.PGBackRest.Repos = []v1beta1.PGBackRestRepo{
+			{
+				Name: "repo1",
+				SFTP: &v1beta1.RepoSFTP{
+					Host: "backups.example.com", Port: initialize.Int32(2222),
+					User: "pgbackrest", KeyPairSecret: "keys",
+				},
+			},
+		}
+
+		configmap := CreatePGBackRestConfigMapIntent(cluster,
+			"", "number", "pod-service-name", "test-ns",
+			[]string{"some-instance"})
+
+		assert.Assert(t, strings.Contains(configmap.Data["pgbackrest_instance.conf"], strings.Trim(`
+repo1-path = /pgbackrest/repo1
+repo1-sftp-host = backups.example.com
+repo1-sftp-host-port = 2222
+repo1-sftp-host-user = pgbackrest
+repo1-sftp-known-host = /etc/pgbackrest/conf.d/repo1-sftp/known_hosts
+repo1-sftp-private-key-file = /etc/pgbackrest/conf.d/repo1-sftp/id
+repo1-type = sftp
+		`, "\t\n")+"\n"))
+	})
 }
 
 func TestMakePGBackrestLogDir(t *testing.T) {
@@ -359,6 +519,44 @@ func TestRestoreCommand(t *testing.T) {
 	assert.NilError(t, err, "%q\n%s", cmd.Args, output)
 }
 
+func TestRestoreDatabaseOptions(t *testing.T) {
+	assert.Assert(t, RestoreDatabaseOptions(nil) == nil)
+	assert.DeepEqual(t, RestoreDatabaseOptions(&v1beta1.PGBackRestRestoreDatabases{}), []string{})
+
+	assert.DeepEqual(t, RestoreDatabaseOptions(&v1beta1.PGBackRestRestoreDatabases{
+		Include: []string{"app", "it's"},
+		Exclude: []string{"scratch"},
+	}), []string{
+		`--db-include='app'`,
+		`--db-include='it'"'"'s'`,
+		`--db-exclude='scratch'`,
+	})
+
+	t.Run("Shell", func(t *testing.T) {
+		opts := RestoreDatabaseOptions(&v1beta1.PGBackRestRestoreDatabases{
+			Include: []string{"a b", `$(c)`, "d'e"},
+		})
+
+		// The restore command passes options through another shell.
+		output, err := exec.Command("bash", "-c",
+			`printf '%s\n' `+strings.Join(opts, " ")).CombinedOutput()
+		assert.NilError(t, err, "%s", output)
+		assert.Equal(t, string(output), strings.Join([]string{
+			"--db-include=a b", "--db-include=$(c)", "--db-include=d'e", "",
+		}, "\n"))
+	})
+}
+
+func TestRestorePointOptions(t *testing.T) {
+	assert.Assert(t, RestorePointOptions("") == nil)
+	assert.DeepEqual(t, RestorePointOptions("before-deploy"), []string{
+		"--type=name", `--target='before-deploy'`,
+	})
+	assert.DeepEqual(t, RestorePointOptions("it's"), []string{
+		"--type=name", `--target='it'"'"'s'`,
+	})
+}
+
 func TestRestoreCommandPrettyYAML(t *testing.T) {
 	b, err := yaml.Marshal(RestoreCommand("/dir", "try", "", nil, "--options"))
 
@@ -416,3 +614,14 @@ log-level-stderr = error
 log-timestamp = n
 `)
 }
+
+func TestServerConfigIPFamilies(t *testing.T) {
+	cluster := &v1beta1.PostgresCluster{}
+	cluster.UID = "shoe"
+
+	cluster.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol}
+	assert.Assert(t, strings.Contains(serverConfig(cluster).String(), "tls-server-address = 0.0.0.0\n"))
+
+	cluster.Spec.IPFamilies = []corev1.IPFamily{corev1.IPv6Protocol}
+	assert.Assert(t, strings.Contains(serverConfig(cluster).String(), "tls-server-address = ::\n"))
+}
@@ -18,6 +18,7 @@ package pgbackrest
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
@@ -98,3 +99,58 @@ fi
 
 	return false, nil
 }
+
+// InfoBackup is the part of "pgbackrest info" output that describes one backup.
+// - https://pgbackrest.org/command.html#command-info
+type InfoBackup struct {
+	Archive struct {
+		// The first WAL file needed to make this backup consistent
+		Start string `json:"start"`
+	} `json:"archive"`
+
+	Database struct {
+		// The index of the repository that holds this backup, e.g. 1 for "repo1"
+		RepoKey int `json:"repo-key"`
+	} `json:"database"`
+
+	Info struct {
+		// The size of the database in bytes
+		Size int64 `json:"size"`
+	} `json:"info"`
+
+	// The name pgBackRest uses for this backup, e.g. "20240101-000000F"
+	Label string `json:"label"`
+
+	Timestamp struct {
+		// The Unix times at which this backup started and stopped
+		Start int64 `json:"start"`
+		Stop  int64 `json:"stop"`
+	} `json:"timestamp"`
+
+	// One of "full", "diff", or "incr"
+	Type string `json:"type"`
+}
+
+// Info runs the pgBackRest "info" command and returns the backups of the default
+// stanza in every repository, oldest first.
+func (exec Executor) Info(ctx context.Context) ([]InfoBackup, error) {
+	var stdout, stderr bytes.Buffer
+
+	if err := exec(ctx, nil, &stdout, &stderr, "pgbackrest", "info",
+		"--output=json", "--stanza="+DefaultStanzaName); err != nil {
+		return nil, errors.WithStack(fmt.Errorf("%w: %v", err, stderr.String()))
+	}
+
+	var stanzas []struct {
+		Backup []InfoBackup `json:"backup"`
+	}
+	if err := json.Unmarshal(stdout.Bytes(), &stanzas); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	var backups []InfoBackup
+	for _, stanza := range stanzas {
+		backups = append(backups, stanza.Backup...)
+	}
+	return backups, nil
+}
@@ -17,6 +17,7 @@ package pgbackrest
 
 import (
 	"context"
+	"errors"
 	"io"
 	"os"
 	"os/exec"
@@ -72,3 +73,48 @@ fi
 	output, err := cmd.CombinedOutput()
 	assert.NilError(t, err, "%q\n%s", cmd.Args, output)
 }
+
+func TestInfo(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Command", func(t *testing.T) {
+		exec := func(_ context.Context, stdin io.Reader, stdout, _ io.Writer,
+			command ...string) error {
+			assert.DeepEqual(t, command, []string{
+				"pgbackrest", "info", "--output=json", "--stanza=db",
+			})
+			_, err := stdout.Write([]byte(`[{"name":"db","backup":[
+				{"archive":{"start":"000000010000000000000004","stop":"000000010000000000000004"},
+				 "database":{"id":1,"repo-key":1},"info":{"size":31457280},"label":"20240101-000000F",
+				 "timestamp":{"start":1704067200,"stop":1704067260},"type":"full"},
+				{"archive":{"start":"000000010000000000000007","stop":"000000010000000000000007"},
+				 "database":{"id":1,"repo-key":2},"label":"20240102-000000F","type":"full"}
+			]}]`))
+			return err
+		}
+
+		backups, err := Executor(exec).Info(ctx)
+		assert.NilError(t, err)
+		assert.Equal(t, len(backups), 2)
+		assert.Equal(t, backups[0].Archive.Start, "000000010000000000000004")
+		assert.Equal(t, backups[0].Database.RepoKey, 1)
+		assert.Equal(t, backups[0].Info.Size, int64(31457280))
+		assert.Equal(t, backups[0].Label, "20240101-000000F")
+		assert.Equal(t, backups[0].Timestamp.Start, int64(1704067200))
+		assert.Equal(t, backups[0].Timestamp.Stop, int64(1704067260))
+		assert.Equal(t, backups[1].Database.RepoKey, 2)
+		assert.Equal(t, backups[1].Type, "full")
+	})
+
+	t.Run("Error", func(t *testing.T) {
+		expected := errors.New("bang")
+		exec := func(_ context.Context, _ io.Reader, _, stderr io.Writer, _ ...string) error {
+			_, _ = stderr.Write([]byte("oops"))
+			return expected
+		}
+
+		_, err := Executor(exec).Info(ctx)
+		assert.ErrorIs(t, err, expected)
+		assert.ErrorContains(t, err, "oops")
+	})
+}
@@ -138,6 +138,8 @@ func AddConfigToInstancePod(
 	// - https://kubernetes.io/docs/concepts/storage/volumes/#projected
 	sources := append([]corev1.VolumeProjection{},
 		cluster.Spec.Backups.PGBackRest.Configuration...)
+	sources = append(sources,
+		storageFiles(cluster.Spec.Backups.PGBackRest.Repos)...)
 
 	if len(secret.Secret.Items) > 0 {
 		sources = append(sources, configmap, secret)
@@ -171,6 +173,8 @@ func AddConfigToRepoPod(
 	// - https://kubernetes.io/docs/concepts/storage/volumes/#projected
 	sources := append([]corev1.VolumeProjection{},
 		cluster.Spec.Backups.PGBackRest.Configuration...)
+	sources = append(sources,
+		storageFiles(cluster.Spec.Backups.PGBackRest.Repos)...)
 
 	addConfigVolumeAndMounts(pod, append(sources, configmap, secret))
 }
@@ -205,11 +209,15 @@ func AddConfigToRestorePod(
 	// - https://kubernetes.io/docs/concepts/storage/volumes/#projected
 	sources := append([]corev1.VolumeProjection{},
 		cluster.Spec.Backups.PGBackRest.Configuration...)
+	sources = append(sources,
+		storageFiles(cluster.Spec.Backups.PGBackRest.Repos)...)
 
 	// For a PostgresCluster restore, append all pgBackRest configuration from
 	// the source cluster for the restore.
 	if sourceCluster != nil {
 		sources = append(sources, sourceCluster.Spec.Backups.PGBackRest.Configuration...)
+		sources = append(sources,
+			storageFiles(sourceCluster.Spec.Backups.PGBackRest.Repos)...)
 	}
 
 	// Currently the spec accepts a dataSource with both a PostgresCluster and
@@ -228,6 +236,8 @@ func AddConfigToRestorePod(
 
 		sources = append([]corev1.VolumeProjection{},
 			cluster.Spec.DataSource.PGBackRest.Configuration...)
+		sources = append(sources, storageFiles(
+			[]v1beta1.PGBackRestRepo{cluster.Spec.DataSource.PGBackRest.Repo})...)
 	}
 
 	// mount any provided configuration files to the restore Job Pod
@@ -250,6 +260,51 @@ func AddConfigToRestorePod(
 	addConfigVolumeAndMounts(pod, append(sources, configmap, secret))
 }
 
+// storageFiles returns projections of the files pgBackRest needs to reach the
+// storage of repos, such as certificate authorities and SSH keys. Each goes into
+// the configuration volume where the options of its repository expect it.
+func storageFiles(repos []v1beta1.PGBackRestRepo) []corev1.VolumeProjection {
+	var sources []corev1.VolumeProjection
+
+	for _, repo := range repos {
+		if repo.Volume != nil {
+			continue
+		}
+
+		if repo.Connection != nil && repo.Connection.CABundle != nil {
+			bundle := repo.Connection.CABundle
+			configmap := corev1.VolumeProjection{ConfigMap: &corev1.ConfigMapProjection{}}
+			configmap.ConfigMap.Name = bundle.Name
+			configmap.ConfigMap.Optional = bundle.Optional
+			configmap.ConfigMap.Items = []corev1.KeyToPath{{
+				Key: bundle.Key, Path: storageCertificateAuthorityPath(repo.Name),
+			}}
+			sources = append(sources, configmap)
+		}
+
+		if repo.SFTP != nil {
+			hosts := corev1.VolumeProjection{Secret: &corev1.SecretProjection{}}
+			hosts.Secret.Name = repo.SFTP.KnownHosts.Name
+			hosts.Secret.Optional = repo.SFTP.KnownHosts.Optional
+			hosts.Secret.Items = []corev1.KeyToPath{{
+				Key: repo.SFTP.KnownHosts.Key, Path: sftpKnownHostsPath(repo.Name),
+			}}
+
+			// SSH refuses private keys that others can read.
+			key := corev1.VolumeProjection{Secret: &corev1.SecretProjection{}}
+			key.Secret.Name = repo.SFTP.KeyPairSecret
+			key.Secret.Items = []corev1.KeyToPath{{
+				Key:  corev1.SSHAuthPrivateKey,
+				Path: sftpPrivateKeyPath(repo.Name),
+				Mode: initialize.Int32(0o600),
+			}}
+			sources = append(sources, hosts, key)
+		}
+	}
+
+	return sources
+}
+
 // addConfigVolumeAndMounts adds the config projections to pod as the
 // configuration volume. It mounts that volume to the database container and
 // all pgBackRest containers in pod.
@@ -332,7 +387,7 @@ func addServerContainerAndVolume(
 		postgres.DataVolumeMount().Name: postgres.DataVolumeMount(),
 		postgres.WALVolumeMount().Name:  postgres.WALVolumeMount(),
 	}
-	if util.DefaultMutableFeatureGate.Enabled(util.TablespaceVolumes) {
+	if util.FeatureEnabled(cluster.Spec.Features, util.TablespaceVolumes) {
 		for _, instance := range cluster.Spec.InstanceSets {
 			for _, vol := range instance.TablespaceVolumes {
 				tablespaceVolumeMount := postgres.TablespaceVolumeMount(vol.Name)
@@ -549,7 +604,7 @@ func Secret(ctx context.Context,
 		}
 
 		if err == nil {
-			outSecret.Data[certAuthoritySecretKey], err = certFile(inRoot.Certificate)
+			outSecret.Data[certAuthoritySecretKey], err = certFile(inRoot.Trusted())
 		}
 		if err == nil {
 			outSecret.Data[certClientPrivateKeySecretKey], err = certFile(leaf.PrivateKey)
@@ -381,6 +381,64 @@ func TestAddConfigToRepoPod(t *testing.T) {
         name: hippo-pgbackrest
 		`))
 	})
+
+	t.Run("StorageFiles", func(t *testing.T) {
+		bundle := &corev1.ConfigMapKeySelector{Key: "bundle.pem"}
+		bundle.Name = "trusted"
+
+		cluster := cluster.DeepCopy()
+		cluster.Spec.Backups.PGBackRest.Repos = []v1beta1.PGBackRestRepo{
+			{
+				Name:       "repo1",
+				Volume:     &v1beta1.RepoPVC{},
+				Connection: &v1beta1.RepoConnection{CABundle: bundle},
+			},
+			{
+				Name:       "repo2",
+				S3:         &v1beta1.RepoS3{},
+				Connection: &v1beta1.RepoConnection{CABundle: bundle},
+			},
+			{
+				Name: "repo3",
+				SFTP: &v1beta1.RepoSFTP{
+					KeyPairSecret: "ssh-keys",
+					KnownHosts: corev1.SecretKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "ssh-hosts"},
+						Key:                  "known_hosts",
+					},
+				},
+			},
+		}
+
+		out := pod.DeepCopy()
+		AddConfigToRepoPod(cluster, out)
+		alwaysExpect(t, out)
+
+		// Files for the cloud and SFTP repositories are before the repository
+		// configuration. Volume repositories have none.
+		assert.Assert(t, marshalMatches(out.Volumes[0].Projected.Sources[0], `
+configMap:
+  items:
+  - key: bundle.pem
+    path: repo2-storage-ca.crt
+  name: trusted
+		`))
+		assert.Assert(t, marshalMatches(out.Volumes[0].Projected.Sources[1:3], `
+- secret:
+    items:
+    - key: known_hosts
+      path: repo3-sftp/known_hosts
+    name: ssh-hosts
+- secret:
+    items:
+    - key: ssh-privatekey
+      mode: 384
+      path: repo3-sftp/id
+    name: ssh-keys
+		`))
+		assert.Equal(t, out.Volumes[0].Projected.Sources[3].ConfigMap.Name,
+			"hippo-pgbackrest-config")
+	})
 }
 
 func TestAddConfigToRestorePod(t *testing.T) {
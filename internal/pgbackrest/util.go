@@ -75,6 +75,13 @@ func CalculateConfigHashes(
 		case repo.S3 != nil:
 			hash, err = hashFunc([]string{repo.S3.Bucket, repo.S3.Endpoint, repo.S3.Region})
 			name = repo.Name
+		case repo.SFTP != nil:
+			var port string
+			if repo.SFTP.Port != nil {
+				port = fmt.Sprint(*repo.SFTP.Port)
+			}
+			hash, err = hashFunc([]string{repo.SFTP.Host, port, repo.SFTP.User})
+			name = repo.Name
 		default:
 			return map[string]string{}, "", errors.New("found unexpected repo type")
 		}
@@ -24,6 +24,7 @@ import (
 	"gotest.tools/v3/assert"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
@@ -131,4 +132,24 @@ func TestCalculateConfigHashes(t *testing.T) {
 		repo := "repo" + strconv.Itoa(i+1)
 		assert.Assert(t, hashMap[repo] != configHashMap[repo])
 	}
+
+	// SFTP repositories are identified by their host, port, and user
+	sftpCluster := postgresCluster.DeepCopy()
+	sftpCluster.Spec.Backups.PGBackRest.Repos = append(sftpCluster.Spec.Backups.PGBackRest.Repos,
+		v1beta1.PGBackRestRepo{
+			Name: "repo4",
+			SFTP: &v1beta1.RepoSFTP{Host: "backups.example.com", User: "pgbackrest"},
+		})
+	preCalculatedRepo4SFTPHash, err := hashFunc([]string{"backups.example.com", "", "pgbackrest"})
+	assert.NilError(t, err)
+
+	hashMap, hash, err := CalculateConfigHashes(sftpCluster)
+	assert.NilError(t, err)
+	assert.Assert(t, configHash != hash)
+	assert.Equal(t, preCalculatedRepo4SFTPHash, hashMap["repo4"])
+
+	sftpCluster.Spec.Backups.PGBackRest.Repos[3].SFTP.Port = initialize.Int32(2222)
+	portMap, _, err := CalculateConfigHashes(sftpCluster)
+	assert.NilError(t, err)
+	assert.Assert(t, portMap["repo4"] != hashMap["repo4"])
 }
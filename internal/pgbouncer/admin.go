@@ -0,0 +1,111 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgbouncer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+)
+
+// adminScript sends a command to the admin console of the PgBouncer at each
+// host at the same time. It prints one line per host: "ok", "timeout" when the
+// command did not return in time, or "failed" followed by the error. The
+// password of [AdminUser] is read from stdin so that it does not appear in
+// the process list.
+// - https://www.pgbouncer.org/usage.html#admin-console
+const adminScript = `
+read -r -d '' PGPASSWORD || true; export PGPASSWORD
+declare -r command="$1" seconds="$2" port="$3"; shift 3
+admin() {
+  local output status=0
+  output="$(timeout "${seconds}" psql -Xq --no-align --tuples-only --command="${command}" \
+    "host=$1 port=${port} user=` + AdminUser + ` dbname=pgbouncer sslmode=require connect_timeout=10" 2>&1)" || status=$?
+  case "${status}:${output}" in
+    0:*|*'already suspended'*|*'not paused'*) echo "$1 ok" ;;
+    124:*) echo "$1 timeout" ;;
+    *) echo "$1 failed ${output//$'\n'/ }" ;;
+  esac
+}
+for host in "$@"; do admin "${host}" & done
+wait
+`
+
+// admin calls exec to send command to the PgBouncers at hosts and returns the
+// hosts at which it did not return within timeout.
+func admin(
+	ctx context.Context, exec postgres.Executor, password string, port int32,
+	timeout time.Duration, command string, hosts []string,
+) ([]string, error) {
+	var stdout, stderr bytes.Buffer
+
+	err := exec(ctx, strings.NewReader(password), &stdout, &stderr,
+		append([]string{"bash", "-ceu", "--", adminScript, "pgbouncer-admin",
+			command, fmt.Sprint(int(timeout.Seconds())), fmt.Sprint(port)}, hosts...)...)
+
+	logging.FromContext(ctx).V(1).Info("sent PgBouncer command",
+		"command", command, "stdout", stdout.String(), "stderr", stderr.String())
+
+	var failed, timedOut []string
+	for _, line := range strings.Split(strings.TrimSpace(stdout.String()), "\n") {
+		host, result, _ := strings.Cut(line, " ")
+		switch {
+		case result == "timeout":
+			timedOut = append(timedOut, host)
+		case strings.HasPrefix(result, "failed"):
+			failed = append(failed, line)
+		}
+	}
+	sort.Strings(failed)
+	sort.Strings(timedOut)
+
+	if err == nil && len(failed) > 0 {
+		err = errors.Errorf("PgBouncer %s: %s", command, strings.Join(failed, "; "))
+	}
+	return timedOut, errors.WithStack(err)
+}
+
+// Pause calls exec to PAUSE the PgBouncers at hosts. PAUSE returns once every
+// server connection has been released, which happens as transactions finish.
+// Pause returns the hosts that were still waiting on transactions after
+// timeout; they remain paused until [Resume].
+// - https://www.pgbouncer.org/usage.html#pause-db
+func Pause(
+	ctx context.Context, exec postgres.Executor, password string, port int32,
+	timeout time.Duration, hosts []string,
+) ([]string, error) {
+	return admin(ctx, exec, password, port, timeout, "PAUSE", hosts)
+}
+
+// Resume calls exec to RESUME the PgBouncers at hosts. It succeeds at hosts
+// that are not paused.
+// - https://www.pgbouncer.org/usage.html#resume-db
+func Resume(
+	ctx context.Context, exec postgres.Executor, password string, port int32, hosts []string,
+) error {
+	timedOut, err := admin(ctx, exec, password, port, 10*time.Second, "RESUME", hosts)
+	if err == nil && len(timedOut) > 0 {
+		err = errors.Errorf("PgBouncer RESUME timed out at %s", strings.Join(timedOut, ", "))
+	}
+	return err
+}
@@ -0,0 +1,116 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgbouncer
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+)
+
+func TestPause(t *testing.T) {
+	ctx := context.Background()
+
+	exec := func(
+		_ context.Context, stdin io.Reader, stdout, _ io.Writer, command ...string,
+	) error {
+		assert.DeepEqual(t, command[:3], []string{"bash", "-ceu", "--"})
+		assert.DeepEqual(t, command[5:], []string{"PAUSE", "30", "5432", "10.0.0.1", "10.0.0.2"})
+
+		b, err := io.ReadAll(stdin)
+		assert.NilError(t, err)
+		assert.Equal(t, string(b), "secret")
+
+		_, err = stdout.Write([]byte("10.0.0.2 timeout\n10.0.0.1 ok\n"))
+		return err
+	}
+
+	timedOut, err := Pause(ctx, exec, "secret", 5432, 30*time.Second,
+		[]string{"10.0.0.1", "10.0.0.2"})
+	assert.NilError(t, err)
+	assert.DeepEqual(t, timedOut, []string{"10.0.0.2"})
+}
+
+func TestResume(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Failed", func(t *testing.T) {
+		exec := func(
+			_ context.Context, _ io.Reader, stdout, _ io.Writer, command ...string,
+		) error {
+			assert.Equal(t, command[5], "RESUME")
+
+			_, err := stdout.Write([]byte("10.0.0.1 failed psql: error: connection refused\n"))
+			return err
+		}
+
+		err := Resume(ctx, exec, "secret", 5432, []string{"10.0.0.1"})
+		assert.ErrorContains(t, err, "PgBouncer RESUME: 10.0.0.1 failed psql: error: connection refused")
+	})
+
+	t.Run("TimedOut", func(t *testing.T) {
+		exec := func(
+			_ context.Context, _ io.Reader, stdout, _ io.Writer, _ ...string,
+		) error {
+			_, err := stdout.Write([]byte("10.0.0.1 timeout\n"))
+			return err
+		}
+
+		err := Resume(ctx, exec, "secret", 5432, []string{"10.0.0.1"})
+		assert.ErrorContains(t, err, "timed out at 10.0.0.1")
+	})
+}
+
+func TestAdminScript(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip(`requires "bash" executable`)
+	}
+
+	// Stand in for psql and timeout with scripts that report what happened.
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "timeout"), []byte(`#!/bin/bash
+shift; exec "$@"`), 0o755))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "psql"), []byte(`#!/bin/bash
+case "${@: -1}" in
+  *10.0.0.1*) [[ "${PGPASSWORD}" == secret ]] ;;
+  *10.0.0.2*) exit 124 ;;
+  *10.0.0.3*) echo 'ERROR:  pooler is not paused/suspended' >&2; exit 1 ;;
+  *) echo 'psql: error: connection refused' >&2; exit 2 ;;
+esac`), 0o755))
+
+	cmd := exec.Command("bash", "-ceu", "--", adminScript, "-",
+		"RESUME", "10", "5432", "10.0.0.1", "10.0.0.2", "10.0.0.3", "10.0.0.4")
+	cmd.Env = append(os.Environ(), "PATH="+dir+":"+os.Getenv("PATH"))
+	cmd.Stdin = strings.NewReader("secret")
+
+	output, err := cmd.CombinedOutput()
+	assert.NilError(t, err, "%s", output)
+
+	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+	assert.Equal(t, len(lines), 4, "%s", output)
+	assert.Assert(t, cmp.Contains(lines, "10.0.0.1 ok"))
+	assert.Assert(t, cmp.Contains(lines, "10.0.0.2 timeout"))
+	assert.Assert(t, cmp.Contains(lines, "10.0.0.3 ok"))
+	assert.Assert(t, cmp.Contains(lines, "10.0.0.4 failed psql: error: connection refused"))
+}
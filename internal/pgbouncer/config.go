@@ -23,6 +23,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 
 	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
@@ -44,6 +45,13 @@ const (
 	iniFileConfigMapKey = "pgbouncer.ini"
 )
 
+// AdminUser is allowed to run commands in the admin console of PgBouncer. Its
+// password is in the PgBouncer Secret at AdminPasswordSecretKey.
+const (
+	AdminUser              = postgresqlUser
+	AdminPasswordSecretKey = passwordSecretKey
+)
+
 const (
 	iniGeneratedWarning = "" +
 		"# Generated by postgres-operator. DO NOT EDIT.\n" +
@@ -111,11 +119,15 @@ func clusterINI(cluster *v1beta1.PostgresCluster) string {
 		"auth_user":  postgresqlUser,
 
 		// TODO(cbandy): Use an HBA file to control authentication of PgBouncer
-		// accounts; e.g. "admin_users" below.
+		// accounts.
 		// - https://www.pgbouncer.org/config.html#hba-file-format
 		//"auth_hba_file": "",
 		//"auth_type":     "hba",
-		//"admin_users": "pgbouncer",
+
+		// Allow the "auth_user" to PAUSE and RESUME pools in the admin console.
+		// It authenticates with the password in "auth_file".
+		// - https://www.pgbouncer.org/usage.html#admin-console
+		"admin_users": postgresqlUser,
 
 		// Require TLS encryption on client connections.
 		"client_tls_sslmode":   "require",
@@ -159,6 +171,14 @@ func clusterINI(cluster *v1beta1.PostgresCluster) string {
 			naming.ClusterPrimaryService(cluster).Name, postgresPort),
 	}
 
+	// Make every connection to PostgreSQL read-only by default when the
+	// cluster is read-only, even for users that are exempt from the setting
+	// in PostgreSQL.
+	// - https://www.pgbouncer.org/config.html#connect_query
+	if postgres.ReadOnly(cluster) {
+		databases["*"] += " connect_query='SET default_transaction_read_only = on'"
+	}
+
 	// Replace the above with any specified databases.
 	if len(cluster.Spec.Proxy.PGBouncer.Config.Databases) > 0 {
 		databases = iniValueSet(cluster.Spec.Proxy.PGBouncer.Config.Databases)
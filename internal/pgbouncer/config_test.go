@@ -27,6 +27,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 
+	"github.com/crunchydata/postgres-operator/internal/initialize"
 	"github.com/crunchydata/postgres-operator/internal/testing/require"
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
@@ -72,6 +73,7 @@ func TestClusterINI(t *testing.T) {
 %include /etc/pgbouncer/pgbouncer.ini
 
 [pgbouncer]
+admin_users = _crunchypgbouncer
 auth_file = /etc/pgbouncer/~postgres-operator/users.txt
 auth_query = SELECT username, password from pgbouncer.get_auth($1)
 auth_user = _crunchypgbouncer
@@ -92,6 +94,16 @@ unix_socket_dir =
 		`, "\t\n")+"\n")
 	})
 
+	t.Run("ReadOnly", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.ReadOnly = initialize.Bool(true)
+
+		assert.Assert(t, strings.HasSuffix(clusterINI(cluster), `
+[databases]
+* = host=foo-baz-primary port=9999 connect_query='SET default_transaction_read_only = on'
+`))
+	})
+
 	t.Run("CustomSettings", func(t *testing.T) {
 		cluster.Spec.Proxy.PGBouncer.Config.Global = map[string]string{
 			"ignore_startup_parameters": "custom",
@@ -112,6 +124,7 @@ unix_socket_dir =
 %include /etc/pgbouncer/pgbouncer.ini
 
 [pgbouncer]
+admin_users = _crunchypgbouncer
 auth_file = /etc/pgbouncer/~postgres-operator/users.txt
 auth_query = SELECT username, password from pgbouncer.get_auth($1)
 auth_user = _crunchypgbouncer
@@ -99,7 +99,7 @@ func Secret(ctx context.Context,
 		}
 
 		if err == nil {
-			outSecret.Data[certFrontendAuthoritySecretKey], err = inRoot.Certificate.MarshalText()
+			outSecret.Data[certFrontendAuthoritySecretKey], err = inRoot.Trusted().MarshalText()
 		}
 		if err == nil {
 			outSecret.Data[certFrontendPrivateKeySecretKey], err = leaf.PrivateKey.MarshalText()
@@ -155,6 +155,11 @@ func Pod(
 		VolumeMounts: []corev1.VolumeMount{configVolumeMount},
 	}
 
+	// PgBouncer writes its logs in the local time zone.
+	if tz := inCluster.Spec.TimeZone; tz != "" {
+		container.Env = []corev1.EnvVar{{Name: "TZ", Value: tz}}
+	}
+
 	// TODO container.LivenessProbe?
 	// TODO container.ReadinessProbe?
 
@@ -191,7 +196,7 @@ func Pod(
 
 	// If the PGBouncerSidecars feature gate is enabled and custom pgBouncer
 	// sidecars are defined, add the defined container to the Pod.
-	if util.DefaultMutableFeatureGate.Enabled(util.PGBouncerSidecars) &&
+	if util.FeatureEnabled(inCluster.Spec.Features, util.PGBouncerSidecars) &&
 		inCluster.Spec.Proxy.PGBouncer.Containers != nil {
 		outPod.Containers = append(outPod.Containers, inCluster.Spec.Proxy.PGBouncer.Containers...)
 	}
@@ -433,6 +433,15 @@ volumes:
 		`))
 	})
 
+	t.Run("TimeZone", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.TimeZone = "Asia/Tokyo"
+
+		pod := new(corev1.PodSpec)
+		Pod(cluster, configMap, primaryCertificate, secret, pod)
+		assert.DeepEqual(t, pod.Containers[0].Env, []corev1.EnvVar{{Name: "TZ", Value: "Asia/Tokyo"}})
+	})
+
 	t.Run("WithCustomSidecarContainer", func(t *testing.T) {
 		cluster.Spec.Proxy.PGBouncer.Containers = []corev1.Container{
 			{Name: "customsidecar1"},
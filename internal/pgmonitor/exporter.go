@@ -39,6 +39,12 @@ const (
 	// https://kubernetes.io/docs/concepts/cluster-administration/networking/
 	// https://releases.k8s.io/v1.21.0/pkg/kubelet/kubelet_pods.go#L343
 	ExporterHost = "localhost"
+
+	// The files of the monitoring Secret used when the exporter authenticates
+	// with a client certificate.
+	ExporterCertFile = "tls.crt"
+	ExporterKeyFile  = "tls.key"
+	ExporterCAFile   = "ca.crt"
 )
 
 // postgres_exporter command flags
@@ -54,6 +60,70 @@ var DefaultValuesForQueries = map[string]string{
 	"PG_STAT_STATEMENTS_THROTTLE_MINUTES": "-1",
 }
 
+// autoscalingQueries measure demand on each instance. The primary reports how
+// far its replicas are behind; replicas report zero.
+// - https://github.com/prometheus-community/postgres_exporter#adding-new-metrics-via-a-config-file
+const autoscalingQueries = `ccp_autoscaling:
+  query: "SELECT
+      (SELECT count(*) FROM pg_catalog.pg_stat_activity
+        WHERE backend_type = 'client backend' AND state <> 'idle') AS active_connections,
+      (SELECT count(*) FROM pg_catalog.pg_stat_activity
+        WHERE backend_type = 'client backend') AS connections,
+      (SELECT COALESCE(max(pg_catalog.pg_wal_lsn_diff(pg_catalog.pg_current_wal_lsn(), replay_lsn)), 0)
+        FROM pg_catalog.pg_stat_replication
+        WHERE NOT pg_catalog.pg_is_in_recovery()) AS replica_lag_bytes"
+  metrics:
+    - active_connections:
+        usage: "GAUGE"
+        description: "Number of client connections running a statement"
+    - connections:
+        usage: "GAUGE"
+        description: "Number of client connections"
+    - replica_lag_bytes:
+        usage: "GAUGE"
+        description: "Bytes of WAL that the furthest behind replica has yet to replay"
+`
+
+// statKCacheQueries export the CPU time and filesystem I/O of the queries
+// that use the most CPU.
+// - https://github.com/powa-team/pg_stat_kcache#usage
+const statKCacheQueries = `ccp_pg_stat_kcache:
+  query: "SELECT d.datname AS dbname,
+      pg_catalog.pg_get_userbyid(k.userid) AS role,
+      k.queryid::text AS queryid,
+      k.exec_user_time AS user_time_seconds,
+      k.exec_system_time AS system_time_seconds,
+      k.exec_reads AS reads_bytes,
+      k.exec_writes AS writes_bytes
+    FROM public.pg_stat_kcache() k
+    JOIN pg_catalog.pg_database d ON d.oid = k.dbid
+    WHERE k.top
+    ORDER BY k.exec_user_time + k.exec_system_time DESC
+    LIMIT #PG_STAT_STATEMENTS_LIMIT#"
+  metrics:
+    - dbname:
+        usage: "LABEL"
+        description: "Name of the database"
+    - role:
+        usage: "LABEL"
+        description: "Name of the role that ran the query"
+    - queryid:
+        usage: "LABEL"
+        description: "Identifier of the query in pg_stat_statements"
+    - user_time_seconds:
+        usage: "COUNTER"
+        description: "CPU time spent running the query in user space"
+    - system_time_seconds:
+        usage: "COUNTER"
+        description: "CPU time spent running the query in the kernel"
+    - reads_bytes:
+        usage: "COUNTER"
+        description: "Bytes read from the filesystem by the query"
+    - writes_bytes:
+        usage: "COUNTER"
+        description: "Bytes written to the filesystem by the query"
+`
+
 // GenerateDefaultExporterQueries generates the default queries used by exporter
 func GenerateDefaultExporterQueries(ctx context.Context, cluster *v1beta1.PostgresCluster) string {
 	log := logging.FromContext(ctx)
@@ -105,6 +175,13 @@ func GenerateDefaultExporterQueries(ctx context.Context, cluster *v1beta1.Postgr
 		}
 	}
 
+	if ExporterAutoscalingMetrics(cluster) {
+		queries += autoscalingQueries + "\n"
+	}
+	if ExporterStatKCache(cluster) {
+		queries += statKCacheQueries + "\n"
+	}
+
 	// Find and replace default values in queries
 	for k, v := range DefaultValuesForQueries {
 		queries = strings.ReplaceAll(queries, fmt.Sprintf("#%s#", k), v)
@@ -47,6 +47,45 @@ func TestGenerateDefaultExporterQueries(t *testing.T) {
 		assert.Assert(t, strings.Contains(queries, "ccp_pg_stat_statements_reset"),
 			"Queries do not contain 'ccp_pg_stat_statements_reset' query when they should.")
 	})
+
+	t.Run("AutoscalingMetrics", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		queries := GenerateDefaultExporterQueries(ctx, cluster)
+		assert.Assert(t, !strings.Contains(queries, "ccp_autoscaling"))
+
+		cluster.Spec.Monitoring = &v1beta1.MonitoringSpec{
+			PGMonitor: &v1beta1.PGMonitorSpec{
+				Exporter: &v1beta1.ExporterSpec{AutoscalingMetrics: true},
+			},
+		}
+		queries = GenerateDefaultExporterQueries(ctx, cluster)
+		assert.Assert(t, cmp.Contains(queries, "ccp_autoscaling:"))
+
+		// The result is valid YAML.
+		var parsed map[string]any
+		assert.NilError(t, yaml.Unmarshal([]byte(autoscalingQueries), &parsed))
+		assert.Assert(t, parsed["ccp_autoscaling"] != nil)
+	})
+
+	t.Run("StatKCache", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		queries := GenerateDefaultExporterQueries(ctx, cluster)
+		assert.Assert(t, !strings.Contains(queries, "ccp_pg_stat_kcache"))
+
+		cluster.Spec.Monitoring = &v1beta1.MonitoringSpec{
+			PGMonitor: &v1beta1.PGMonitorSpec{
+				Exporter: &v1beta1.ExporterSpec{StatKCache: true},
+			},
+		}
+		queries = GenerateDefaultExporterQueries(ctx, cluster)
+		assert.Assert(t, cmp.Contains(queries, "ccp_pg_stat_kcache:"))
+		assert.Assert(t, !strings.Contains(queries, "#PG_STAT_STATEMENTS_LIMIT#"))
+
+		// The result is valid YAML.
+		var parsed map[string]any
+		assert.NilError(t, yaml.Unmarshal([]byte(statKCacheQueries), &parsed))
+		assert.Assert(t, parsed["ccp_pg_stat_kcache"] != nil)
+	})
 }
 
 func TestExporterStartCommand(t *testing.T) {
@@ -34,7 +34,14 @@ const (
 // PostgreSQLHBAs provides the Postgres HBA rules for allowing the monitoring
 // exporter to be accessible
 func PostgreSQLHBAs(inCluster *v1beta1.PostgresCluster, outHBAs *postgres.HBAs) {
-	if ExporterEnabled(inCluster) {
+	if ExporterCertificateAuthentication(inCluster) {
+		// Limit the monitoring user to local TLS connections that present
+		// a client certificate. No password is accepted.
+		outHBAs.Mandatory = append(outHBAs.Mandatory,
+			*postgres.NewHBA().TLS().User(MonitoringUser).Method("cert").Network("127.0.0.0/8"),
+			*postgres.NewHBA().TLS().User(MonitoringUser).Method("cert").Network("::1/128"),
+			*postgres.NewHBA().TCP().User(MonitoringUser).Method("reject"))
+	} else if ExporterEnabled(inCluster) {
 		// Limit the monitoring user to local connections using SCRAM.
 		outHBAs.Mandatory = append(outHBAs.Mandatory,
 			*postgres.NewHBA().TCP().User(MonitoringUser).Method("scram-sha-256").Network("127.0.0.0/8"),
@@ -51,11 +58,24 @@ func PostgreSQLParameters(inCluster *v1beta1.PostgresCluster, outParameters *pos
 		// pg_stat_statements: https://access.crunchydata.com/documentation/pgmonitor/latest/exporter/
 		// pgnodemx: https://github.com/CrunchyData/pgnodemx
 		outParameters.Mandatory.AppendToList("shared_preload_libraries", "pg_stat_statements", "pgnodemx")
+
+		// pg_stat_kcache must load after pg_stat_statements.
+		// - https://github.com/powa-team/pg_stat_kcache#installation
+		if ExporterStatKCache(inCluster) {
+			outParameters.Mandatory.AppendToList("shared_preload_libraries", "pg_stat_kcache")
+		}
 		outParameters.Mandatory.Add("pgnodemx.kdapi_path",
 			postgres.DownwardAPIVolumeMount().MountPath)
 	}
 }
 
+// StatKCacheSetup installs pg_stat_kcache in the exporter database. It fails
+// until PostgreSQL has loaded the library.
+const StatKCacheSetup = `
+CREATE EXTENSION IF NOT EXISTS pg_stat_kcache;
+ALTER EXTENSION pg_stat_kcache UPDATE;
+`
+
 // DisableExporterInPostgreSQL disables the exporter configuration in PostgreSQL.
 // Currently the exporter is disabled by removing login permissions for the
 // monitoring user.
@@ -79,11 +99,20 @@ func DisableExporterInPostgreSQL(ctx context.Context, exec postgres.Executor) er
 // EnableExporterInPostgreSQL runs SQL setup commands in `database` to enable
 // the exporter to retrieve metrics. pgMonitor objects are created and expected
 // extensions are installed. We also ensure that the monitoring user has the
-// current password and can login.
+// current password and can login. When monitoringSecret has no verifier, the
+// exporter authenticates with a client certificate and the monitoring user's
+// password is removed.
 func EnableExporterInPostgreSQL(ctx context.Context, exec postgres.Executor,
 	monitoringSecret *corev1.Secret, database, setup string) error {
 	log := logging.FromContext(ctx)
 
+	// ccp_monitoring user is created in Setup.sql without a password; update
+	// the password and ensure that the ROLE can login to the database.
+	login := `ALTER ROLE :"username" LOGIN PASSWORD :'verifier';`
+	if len(monitoringSecret.Data["verifier"]) == 0 {
+		login = `ALTER ROLE :"username" LOGIN PASSWORD NULL;`
+	}
+
 	stdout, stderr, err := exec.ExecInAllDatabases(ctx,
 		strings.Join([]string{
 			// Quiet NOTICE messages from IF EXISTS statements.
@@ -127,10 +156,7 @@ func EnableExporterInPostgreSQL(ctx context.Context, exec postgres.Executor,
 				// Run idempotent update
 				"ALTER EXTENSION pgnodemx UPDATE;",
 
-				// ccp_monitoring user is created in Setup.sql without a
-				// password; update the password and ensure that the ROLE
-				// can login to the database
-				`ALTER ROLE :"username" LOGIN PASSWORD :'verifier';`,
+				login,
 			}, "\n"),
 			map[string]string{
 				"database": database,
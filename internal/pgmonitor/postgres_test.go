@@ -51,6 +51,25 @@ func TestPostgreSQLHBA(t *testing.T) {
 		assert.Equal(t, outHBAs.Mandatory[1].String(), `host all "ccp_monitoring" "::1/128" scram-sha-256`)
 		assert.Equal(t, outHBAs.Mandatory[2].String(), `host all "ccp_monitoring" all reject`)
 	})
+
+	t.Run("CertificateAuthentication", func(t *testing.T) {
+		inCluster := &v1beta1.PostgresCluster{}
+		inCluster.Spec.Monitoring = &v1beta1.MonitoringSpec{
+			PGMonitor: &v1beta1.PGMonitorSpec{
+				Exporter: &v1beta1.ExporterSpec{
+					Authentication: "Certificate",
+				},
+			},
+		}
+
+		outHBAs := postgres.HBAs{}
+		PostgreSQLHBAs(inCluster, &outHBAs)
+
+		assert.Equal(t, len(outHBAs.Mandatory), 3)
+		assert.Equal(t, outHBAs.Mandatory[0].String(), `hostssl all "ccp_monitoring" "127.0.0.0/8" cert`)
+		assert.Equal(t, outHBAs.Mandatory[1].String(), `hostssl all "ccp_monitoring" "::1/128" cert`)
+		assert.Equal(t, outHBAs.Mandatory[2].String(), `host all "ccp_monitoring" all reject`)
+	})
 }
 
 func TestPostgreSQLParameters(t *testing.T) {
@@ -98,4 +117,22 @@ func TestPostgreSQLParameters(t *testing.T) {
 		assert.Assert(t, strings.Contains(libs, "pgnodemx"))
 		assert.Assert(t, strings.Contains(libs, "daisy"))
 	})
+
+	t.Run("StatKCache", func(t *testing.T) {
+		inCluster := &v1beta1.PostgresCluster{}
+		inCluster.Spec.Monitoring = &v1beta1.MonitoringSpec{
+			PGMonitor: &v1beta1.PGMonitorSpec{
+				Exporter: &v1beta1.ExporterSpec{
+					Image:      "image",
+					StatKCache: true,
+				},
+			},
+		}
+		outParameters := postgres.NewParameters()
+
+		PostgreSQLParameters(inCluster, &outParameters)
+		libs, found := outParameters.Mandatory.Get("shared_preload_libraries")
+		assert.Assert(t, found)
+		assert.Equal(t, libs, "pg_stat_statements,pgnodemx,pg_stat_kcache")
+	})
 }
@@ -49,3 +49,33 @@ func ExporterEnabled(cluster *v1beta1.PostgresCluster) bool {
 	}
 	return true
 }
+
+// ExporterCertificateAuthentication returns true when the monitoring exporter
+// is enabled and authenticates to PostgreSQL using a client certificate rather
+// than a password.
+func ExporterCertificateAuthentication(cluster *v1beta1.PostgresCluster) bool {
+	return ExporterEnabled(cluster) &&
+		cluster.Spec.Monitoring.PGMonitor.Exporter.Authentication == "Certificate"
+}
+
+// ExporterAutoscalingMetrics returns true when the monitoring exporter is
+// enabled and exports metrics for demand-driven scaling. They are exported
+// whenever an instance set scales on demand.
+func ExporterAutoscalingMetrics(cluster *v1beta1.PostgresCluster) bool {
+	if !ExporterEnabled(cluster) {
+		return false
+	}
+	for _, set := range cluster.Spec.InstanceSets {
+		if set.Autoscaling != nil && set.Autoscaling.Demand != nil {
+			return true
+		}
+	}
+	return cluster.Spec.Monitoring.PGMonitor.Exporter.AutoscalingMetrics
+}
+
+// ExporterStatKCache returns true when the monitoring exporter is enabled and
+// exports the resource usage of each query measured by pg_stat_kcache.
+func ExporterStatKCache(cluster *v1beta1.PostgresCluster) bool {
+	return ExporterEnabled(cluster) &&
+		cluster.Spec.Monitoring.PGMonitor.Exporter.StatKCache
+}
@@ -22,6 +22,7 @@ import (
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"math/big"
+	"net/url"
 	"time"
 )
 
@@ -49,7 +50,7 @@ func generateSerialNumber() (*big.Int, error) {
 func generateLeafCertificate(
 	signer *x509.Certificate, signerPrivate *ecdsa.PrivateKey,
 	signeePublic *ecdsa.PublicKey, serialNumber *big.Int,
-	commonName string, dnsNames []string,
+	commonName string, dnsNames []string, uris []*url.URL,
 ) (*x509.Certificate, error) {
 	const leafExpiration = time.Hour * 24 * 365
 	const leafStartValid = time.Hour * -1
@@ -66,6 +67,7 @@ func generateLeafCertificate(
 		Subject: pkix.Name{
 			CommonName: commonName,
 		},
+		URIs: uris,
 	}
 
 	bytes, err := x509.CreateCertificate(rand.Reader, template, signer,
@@ -67,6 +67,25 @@ func (c *Certificate) UnmarshalText(data []byte) error {
 	return err
 }
 
+var _ encoding.TextMarshaler = Certificates{}
+
+// Certificates is a list of certificates, such as a bundle of trusted
+// authorities.
+type Certificates []Certificate
+
+// MarshalText returns the PEM encodings of cs, one after another.
+func (cs Certificates) MarshalText() ([]byte, error) {
+	var out []byte
+	for i := range cs {
+		b, err := cs[i].MarshalText()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b...)
+	}
+	return out, nil
+}
+
 var (
 	_ encoding.TextMarshaler   = PrivateKey{}
 	_ encoding.TextMarshaler   = (*PrivateKey)(nil)
@@ -100,6 +100,24 @@ func TestCertificateTextMarshaling(t *testing.T) {
 	})
 }
 
+func TestCertificatesTextMarshaling(t *testing.T) {
+	empty, err := Certificates{}.MarshalText()
+	assert.NilError(t, err)
+	assert.Equal(t, len(empty), 0)
+
+	_, err = Certificates{{}}.MarshalText()
+	assert.ErrorContains(t, err, "malformed")
+
+	root1, _ := NewRootCertificateAuthority()
+	root2, _ := NewRootCertificateAuthority()
+	txt1, _ := root1.Certificate.MarshalText()
+	txt2, _ := root2.Certificate.MarshalText()
+
+	bundle, err := Certificates{root1.Certificate, root2.Certificate}.MarshalText()
+	assert.NilError(t, err)
+	assert.DeepEqual(t, bundle, bytes.Join([][]byte{txt1, txt2}, nil))
+}
+
 func TestPrivateKeyTextMarshaling(t *testing.T) {
 	t.Run("Zero", func(t *testing.T) {
 		// Zero cannot marshal.
@@ -19,6 +19,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/x509"
 	"math/big"
+	"net/url"
 	"time"
 )
 
@@ -53,15 +54,33 @@ func (c Certificate) DNSNames() []string {
 	return append([]string{}, c.x509.DNSNames...)
 }
 
+// URIs returns a copy of the certificate subject alternative names
+// (ASN.1 OID 2.5.29.17) that are URIs.
+func (c Certificate) URIs() []*url.URL {
+	if c.x509 == nil || len(c.x509.URIs) == 0 {
+		return nil
+	}
+	uris := make([]*url.URL, len(c.x509.URIs))
+	for i := range c.x509.URIs {
+		copied := *c.x509.URIs[i]
+		uris[i] = &copied
+	}
+	return uris
+}
+
 // hasSubject checks that c has these values in its subject.
-func (c Certificate) hasSubject(commonName string, dnsNames []string) bool {
+func (c Certificate) hasSubject(commonName string, dnsNames []string, uris ...*url.URL) bool {
 	ok := c.x509 != nil &&
 		c.x509.Subject.CommonName == commonName &&
-		len(c.x509.DNSNames) == len(dnsNames)
+		len(c.x509.DNSNames) == len(dnsNames) &&
+		len(c.x509.URIs) == len(uris)
 
 	for i := range dnsNames {
 		ok = ok && c.x509.DNSNames[i] == dnsNames[i]
 	}
+	for i := range uris {
+		ok = ok && c.x509.URIs[i].String() == uris[i].String()
+	}
 
 	return ok
 }
@@ -86,6 +105,23 @@ type LeafCertificate struct {
 type RootCertificateAuthority struct {
 	Certificate Certificate
 	PrivateKey  PrivateKey
+
+	// Previous is the certificate of an authority that this one is replacing.
+	// It is trusted alongside Certificate until peers trust Certificate.
+	Previous Certificate
+
+	// KeepPreviousLeaves indicates that leaf certificates signed by Previous
+	// are still valid and should not be regenerated yet.
+	KeepPreviousLeaves bool
+}
+
+// Trusted returns the certificates of root and any authority it is replacing.
+func (root *RootCertificateAuthority) Trusted() Certificates {
+	trusted := Certificates{root.Certificate}
+	if root.Previous.x509 != nil {
+		trusted = append(trusted, root.Previous)
+	}
+	return trusted
 }
 
 // NewRootCertificateAuthority generates a new key and self-signed certificate
@@ -143,8 +179,9 @@ func RootIsValid(root *RootCertificateAuthority) bool {
 }
 
 // GenerateLeafCertificate generates a new key and certificate signed by root.
+// The subject alternative names of the certificate are dnsNames and uris.
 func (root *RootCertificateAuthority) GenerateLeafCertificate(
-	commonName string, dnsNames []string,
+	commonName string, dnsNames []string, uris ...*url.URL,
 ) (*LeafCertificate, error) {
 	var leaf LeafCertificate
 	var serial *big.Int
@@ -157,14 +194,15 @@ func (root *RootCertificateAuthority) GenerateLeafCertificate(
 		leaf.PrivateKey.ecdsa = key
 		leaf.Certificate.x509, err = generateLeafCertificate(
 			root.Certificate.x509, root.PrivateKey.ecdsa, &key.PublicKey, serial,
-			commonName, dnsNames)
+			commonName, dnsNames, uris)
 	}
 
 	return &leaf, err
 }
 
 // leafIsValid checks if leaf is valid according to this package's policies and
-// is signed by root.
+// is signed by root, or by the authority it is replacing when KeepPreviousLeaves
+// is true.
 func (root *RootCertificateAuthority) leafIsValid(leaf *LeafCertificate) bool {
 	if root == nil || root.Certificate.x509 == nil {
 		return false
@@ -175,6 +213,9 @@ func (root *RootCertificateAuthority) leafIsValid(leaf *LeafCertificate) bool {
 
 	trusted := x509.NewCertPool()
 	trusted.AddCert(root.Certificate.x509)
+	if root.KeepPreviousLeaves && root.Previous.x509 != nil {
+		trusted.AddCert(root.Previous.x509)
+	}
 
 	// Go 1.10 enforces name constraints for all names in the certificate.
 	// Go 1.15 does not enforce name constraints on the CommonName field.
@@ -216,16 +257,16 @@ func isBeforeRenewalTime(before, after time.Time) bool {
 }
 
 // RegenerateLeafWhenNecessary returns leaf when it is valid according to this
-// package's policies, signed by root, and has commonName and dnsNames in its
-// subject. Otherwise, it returns a new key and certificate signed by root.
+// package's policies, signed by root, and has commonName, dnsNames, and uris
+// in its subject. Otherwise, it returns a new key and certificate signed by root.
 func (root *RootCertificateAuthority) RegenerateLeafWhenNecessary(
-	leaf *LeafCertificate, commonName string, dnsNames []string,
+	leaf *LeafCertificate, commonName string, dnsNames []string, uris ...*url.URL,
 ) (*LeafCertificate, error) {
 	ok := root.leafIsValid(leaf) &&
-		leaf.Certificate.hasSubject(commonName, dnsNames)
+		leaf.Certificate.hasSubject(commonName, dnsNames, uris...)
 
 	if ok {
 		return leaf, nil
 	}
-	return root.GenerateLeafCertificate(commonName, dnsNames)
+	return root.GenerateLeafCertificate(commonName, dnsNames, uris...)
 }
@@ -18,6 +18,7 @@ package pki
 import (
 	"crypto/ecdsa"
 	"crypto/x509"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -183,7 +184,9 @@ func TestRootIsInvalid(t *testing.T) {
 		leaf, err := root.GenerateLeafCertificate("", nil)
 		assert.NilError(t, err)
 
-		assert.Assert(t, !RootIsValid((*RootCertificateAuthority)(leaf)))
+		assert.Assert(t, !RootIsValid(&RootCertificateAuthority{
+			Certificate: leaf.Certificate, PrivateKey: leaf.PrivateKey,
+		}))
 	})
 
 	t.Run("TooEarly", func(t *testing.T) {
@@ -367,7 +370,9 @@ func TestLeafIsInvalid(t *testing.T) {
 	})
 
 	t.Run("IsAuthority", func(t *testing.T) {
-		assert.Assert(t, !root.leafIsValid((*LeafCertificate)(root)))
+		assert.Assert(t, !root.leafIsValid(&LeafCertificate{
+			Certificate: root.Certificate, PrivateKey: root.PrivateKey,
+		}))
 	})
 
 	t.Run("TooEarly", func(t *testing.T) {
@@ -444,6 +449,57 @@ func TestRegenerateLeaf(t *testing.T) {
 
 	assert.Assert(t, after.Certificate.hasSubject("after", nil))
 	assert.Assert(t, !after.Certificate.Equal(before.Certificate))
+
+	t.Run("URIs", func(t *testing.T) {
+		uri, err := url.Parse("urn:example:client")
+		assert.NilError(t, err)
+
+		// Leaf is replaced when its URIs differ.
+		with, err := root.RegenerateLeafWhenNecessary(before, "before", nil, uri)
+		assert.NilError(t, err)
+		assert.Assert(t, !with.Certificate.Equal(before.Certificate))
+		assert.DeepEqual(t, with.Certificate.URIs(), []*url.URL{uri})
+		assert.Assert(t, before.Certificate.URIs() == nil)
+
+		same, err := root.RegenerateLeafWhenNecessary(with, "before", nil, uri)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, same, with)
+
+		without, err := root.RegenerateLeafWhenNecessary(with, "before", nil)
+		assert.NilError(t, err)
+		assert.Assert(t, without.Certificate.hasSubject("before", nil))
+		assert.Assert(t, !without.Certificate.Equal(with.Certificate))
+	})
+}
+
+func TestRegenerateLeafPreviousRoot(t *testing.T) {
+	previous, err := NewRootCertificateAuthority()
+	assert.NilError(t, err)
+
+	before, err := previous.GenerateLeafCertificate("leaf", nil)
+	assert.NilError(t, err)
+
+	root, err := NewRootCertificateAuthority()
+	assert.NilError(t, err)
+	root.Previous = previous.Certificate
+
+	assert.DeepEqual(t, root.Trusted(), Certificates{root.Certificate, previous.Certificate})
+
+	// Leaves of the previous root are kept while they are valid.
+	root.KeepPreviousLeaves = true
+	same, err := root.RegenerateLeafWhenNecessary(before, "leaf", nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, same, before)
+
+	// Otherwise, they are replaced by leaves of the current root.
+	root.KeepPreviousLeaves = false
+	after, err := root.RegenerateLeafWhenNecessary(before, "leaf", nil)
+	assert.NilError(t, err)
+	assert.Assert(t, !after.Certificate.Equal(before.Certificate))
+	assert.Assert(t, root.leafIsValid(after))
+
+	root.Previous = Certificate{}
+	assert.DeepEqual(t, root.Trusted(), Certificates{root.Certificate})
 }
 
 func basicOpenSSLVerify(t *testing.T, openssl string, root, leaf Certificate) {
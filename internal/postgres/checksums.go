@@ -0,0 +1,38 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgres
+
+import (
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// DataChecksumsEnabled returns whether or not cluster should have data page
+// checksums. Checksums are enabled unless the spec explicitly disables them.
+func DataChecksumsEnabled(cluster *v1beta1.PostgresCluster) bool {
+	return cluster.Spec.DataChecksums == nil ||
+		cluster.Spec.DataChecksums.Enabled == nil ||
+		*cluster.Spec.DataChecksums.Enabled
+}
+
+// SetDataChecksums populates PostgreSQL parameters that depend on data page
+// checksums. Without checksums, "wal_log_hints" must be enabled for Patroni
+// to use `pg_rewind` when a former primary rejoins the cluster.
+// - https://www.postgresql.org/docs/current/app-pgrewind.html
+func SetDataChecksums(cluster *v1beta1.PostgresCluster, pgParameters *Parameters) {
+	if !DataChecksumsEnabled(cluster) {
+		pgParameters.Mandatory.Add("wal_log_hints", "on")
+	}
+}
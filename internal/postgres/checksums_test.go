@@ -0,0 +1,60 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgres
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestDataChecksumsEnabled(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	assert.Assert(t, DataChecksumsEnabled(cluster), "expected default")
+
+	cluster.Spec.DataChecksums = new(v1beta1.DataChecksumsSpec)
+	assert.Assert(t, DataChecksumsEnabled(cluster), "expected default")
+
+	cluster.Spec.DataChecksums.Enabled = initialize.Bool(true)
+	assert.Assert(t, DataChecksumsEnabled(cluster))
+
+	cluster.Spec.DataChecksums.Enabled = initialize.Bool(false)
+	assert.Assert(t, !DataChecksumsEnabled(cluster))
+}
+
+func TestSetDataChecksums(t *testing.T) {
+	t.Run("Enabled", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		pgParameters := NewParameters()
+		SetDataChecksums(cluster, &pgParameters)
+
+		assert.Assert(t, !pgParameters.Mandatory.Has("wal_log_hints"))
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.DataChecksums = &v1beta1.DataChecksumsSpec{
+			Enabled: initialize.Bool(false),
+		}
+		pgParameters := NewParameters()
+		SetDataChecksums(cluster, &pgParameters)
+
+		assert.Equal(t, pgParameters.Mandatory.Value("wal_log_hints"), "on")
+	})
+}
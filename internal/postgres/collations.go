@@ -0,0 +1,161 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+)
+
+// collationMismatches returns SQL that selects the schema and name of every
+// collation in the current database whose recorded version differs from the
+// version provided by the C library or ICU. On PostgreSQL 15 and later, the
+// default collation of the database is included with a NULL schema.
+// - https://www.postgresql.org/docs/current/sql-altercollation.html#SQL-ALTERCOLLATION-NOTES
+func collationMismatches(version int) string {
+	sql := strings.TrimSpace(`
+SELECT n.nspname, c.collname
+  FROM pg_catalog.pg_collation c
+  JOIN pg_catalog.pg_namespace n ON n.oid = c.collnamespace
+ WHERE c.collprovider <> 'd'
+   AND c.collversion <> pg_catalog.pg_collation_actual_version(c.oid)`)
+
+	// The version of the default collation is recorded in pg_database since
+	// PostgreSQL 15.
+	if version >= 15 {
+		sql += "\n" + strings.TrimSpace(`
+UNION ALL
+SELECT NULL, 'default'
+  FROM pg_catalog.pg_database
+ WHERE datname = pg_catalog.current_database()
+   AND datcollversion <> pg_catalog.pg_database_collation_actual_version(oid)`)
+	}
+	return sql
+}
+
+// CollationVersionMismatches returns, for every database that allows
+// connections, the collations whose recorded version differs from the version
+// the C library or ICU of the running PostgreSQL provides. This happens when
+// those libraries change in a new image. Indexes that sort by such collations
+// may no longer find their rows.
+func CollationVersionMismatches(
+	ctx context.Context, exec Executor, version int,
+) (map[string][]string, error) {
+	log := logging.FromContext(ctx)
+
+	stdout, stderr, err := exec.ExecInAllDatabases(ctx,
+		strings.Join([]string{
+			`\pset format unaligned`,
+			`\pset tuples_only on`,
+			`SELECT pg_catalog.current_database(), pg_catalog.concat_ws('.', nspname, collname)`,
+			` FROM (` + collationMismatches(version) + `) AS mismatches(nspname, collname);`,
+		}, "\n"),
+		map[string]string{
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("compared collation versions", "stdout", stdout, "stderr", stderr)
+
+	var mismatches map[string][]string
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		if database, collation, ok := strings.Cut(line, "|"); ok {
+			if mismatches == nil {
+				mismatches = make(map[string][]string)
+			}
+			mismatches[database] = append(mismatches[database], collation)
+		}
+	}
+	for _, collations := range mismatches {
+		sort.Strings(collations)
+	}
+	return mismatches, err
+}
+
+// refreshCollationVersions returns psql statements that record the current
+// versions of every collation in the current database whose recorded version
+// differs.
+func refreshCollationVersions(version int) []string {
+	statements := []string{strings.TrimSpace(`
+SELECT pg_catalog.format('ALTER COLLATION %I.%I REFRESH VERSION', nspname, collname)
+  FROM (` + collationMismatches(version) + `) AS mismatches(nspname, collname)
+ WHERE nspname IS NOT NULL
+\gexec`)}
+
+	if version >= 15 {
+		statements = append(statements, `SELECT pg_catalog.format(`+
+			`'ALTER DATABASE %I REFRESH COLLATION VERSION', pg_catalog.current_database()) \gexec`)
+	}
+	return statements
+}
+
+// RefreshCollationVersions rebuilds the indexes of every database that has a
+// collation version mismatch and then records the current versions of its
+// collations. Indexes are rebuilt concurrently on PostgreSQL 12 and later so
+// that reads and writes continue.
+// - https://www.postgresql.org/docs/current/sql-reindex.html
+func RefreshCollationVersions(ctx context.Context, exec Executor, version int) error {
+	log := logging.FromContext(ctx)
+
+	reindex := `REINDEX DATABASE %I`
+	if version >= 12 {
+		reindex = `REINDEX DATABASE CONCURRENTLY %I`
+	}
+
+	statements := []string{
+		// Quiet NOTICE messages from REINDEX.
+		// - https://www.postgresql.org/docs/current/runtime-config-client.html
+		`SET client_min_messages = WARNING;`,
+
+		`SELECT EXISTS (` + collationMismatches(version) + `) AS mismatched \gset`,
+		`\if :mismatched`,
+
+		`SELECT pg_catalog.format('` + reindex + `', pg_catalog.current_database()) \gexec`,
+	}
+	statements = append(statements, refreshCollationVersions(version)...)
+	statements = append(statements, `\endif`)
+
+	stdout, stderr, err := exec.ExecInAllDatabases(ctx,
+		strings.Join(statements, "\n"),
+		map[string]string{
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.Info("refreshed collation versions", "stdout", stdout, "stderr", stderr)
+
+	return err
+}
+
+// RecordCollationVersions records the current versions of the collations of
+// every database without rebuilding any indexes. Call it after the indexes that
+// use changed collations have been rebuilt some other way.
+func RecordCollationVersions(ctx context.Context, exec Executor, version int) error {
+	log := logging.FromContext(ctx)
+
+	stdout, stderr, err := exec.ExecInAllDatabases(ctx,
+		strings.Join(refreshCollationVersions(version), "\n"),
+		map[string]string{
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.Info("recorded collation versions", "stdout", stdout, "stderr", stderr)
+
+	return err
+}
@@ -0,0 +1,139 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+)
+
+func TestCollationVersionMismatches(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("PG14", func(t *testing.T) {
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, _ io.Writer, command ...string,
+		) error {
+			assert.Equal(t, command[0], "bash")
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), `pg_collation_actual_version`))
+			assert.Assert(t, !strings.Contains(string(b), `datcollversion`))
+
+			_, err = stdout.Write([]byte("app|public.german\napp|pg_catalog.en-US-x-icu\n"))
+			return err
+		}
+
+		mismatches, err := CollationVersionMismatches(ctx, exec, 14)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, mismatches, map[string][]string{
+			"app": {"pg_catalog.en-US-x-icu", "public.german"},
+		})
+	})
+
+	t.Run("PG15", func(t *testing.T) {
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, _ io.Writer, _ ...string,
+		) error {
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), `datcollversion`))
+			assert.Assert(t, cmp.Contains(string(b), "oid)\nUNION ALL\nSELECT"))
+
+			_, err = stdout.Write([]byte("postgres|default\n"))
+			return err
+		}
+
+		mismatches, err := CollationVersionMismatches(ctx, exec, 15)
+		assert.NilError(t, err)
+		assert.DeepEqual(t, mismatches, map[string][]string{"postgres": {"default"}})
+	})
+
+	t.Run("None", func(t *testing.T) {
+		exec := func(context.Context, io.Reader, io.Writer, io.Writer, ...string) error {
+			return nil
+		}
+
+		mismatches, err := CollationVersionMismatches(ctx, exec, 16)
+		assert.NilError(t, err)
+		assert.Assert(t, mismatches == nil)
+	})
+}
+
+func TestRefreshCollationVersions(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		version  int
+		contains []string
+		excludes []string
+	}{
+		{
+			version:  11,
+			contains: []string{`'REINDEX DATABASE %I'`, `ALTER COLLATION %I.%I REFRESH VERSION`},
+			excludes: []string{`CONCURRENTLY`, `REFRESH COLLATION VERSION`},
+		},
+		{
+			version:  16,
+			contains: []string{`'REINDEX DATABASE CONCURRENTLY %I'`, `ALTER DATABASE %I REFRESH COLLATION VERSION`},
+		},
+	} {
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			assert.Assert(t, cmp.Contains(strings.Join(command, "\n"), `--set=ON_ERROR_STOP=on`))
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), `\if :mismatched`))
+			assert.Assert(t, strings.HasSuffix(string(b), `\endif`))
+			for _, s := range tt.contains {
+				assert.Assert(t, cmp.Contains(string(b), s))
+			}
+			for _, s := range tt.excludes {
+				assert.Assert(t, !strings.Contains(string(b), s), "unexpected %q", s)
+			}
+			return nil
+		}
+
+		assert.NilError(t, RefreshCollationVersions(ctx, exec, tt.version))
+	}
+}
+
+func TestRecordCollationVersions(t *testing.T) {
+	ctx := context.Background()
+
+	exec := func(
+		_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+	) error {
+		assert.Equal(t, command[0], "bash")
+
+		b, err := io.ReadAll(stdin)
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(string(b), `ALTER COLLATION %I.%I REFRESH VERSION`))
+		assert.Assert(t, cmp.Contains(string(b), `ALTER DATABASE %I REFRESH COLLATION VERSION`))
+		assert.Assert(t, !strings.Contains(string(b), `REINDEX`))
+		return nil
+	}
+
+	assert.NilError(t, RecordCollationVersions(ctx, exec, 16))
+}
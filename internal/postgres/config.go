@@ -70,6 +70,9 @@ safelink() (
 	// walMountPath is where to mount the optional WAL volume.
 	walMountPath = "/pgwal"
 
+	// tempMountPath is where to mount the optional volume for temporary files.
+	tempMountPath = "/pgtemp"
+
 	// downwardAPIPath is where to mount the downwardAPI volume.
 	downwardAPIPath = "/etc/database-containerinfo"
 
@@ -80,6 +83,11 @@ safelink() (
 	// for streaming replication and for `pg_rewind`.
 	ReplicationUser = "_crunchyrepl"
 
+	// MaintenanceUser is the PostgreSQL role that scheduled Jobs use to check,
+	// rewrite, and partition tables. It authenticates with a client certificate
+	// and has only the privileges that enabled features grant it.
+	MaintenanceUser = "_crunchymaintenance"
+
 	// configMountPath is where to mount additional config files
 	configMountPath = "/etc/postgres"
 )
@@ -209,7 +217,7 @@ func startupCommand(
 	// If the user requests tablespaces, we want to make sure the directories exist with the
 	// correct owner and permissions.
 	tablespaceCmd := ""
-	if util.DefaultMutableFeatureGate.Enabled(util.TablespaceVolumes) {
+	if util.FeatureEnabled(cluster.Spec.Features, util.TablespaceVolumes) {
 		// This command checks if a dir exists and if not, creates it;
 		// if the dir does exist, then we `recreate` it to make sure the owner is correct;
 		// if the dir exists with the wrong owner and is not writeable, we error.
@@ -247,6 +255,29 @@ func startupCommand(
 		}
 	}
 
+	// When any instance has a volume for temporary files, prepare the
+	// directory of their tablespace the same way. Its contents are lost when
+	// the volume is an emptyDir or is replaced, so recreate the subdirectory
+	// that PostgreSQL expects inside it once the tablespace exists. Without
+	// that, PostgreSQL puts temporary files in the default tablespace.
+	// - https://git.postgresql.org/gitweb/?p=postgresql.git;f=src/backend/storage/file/fd.c;hb=REL_16_0#l1708
+	if TempVolume(cluster) {
+		tablespaceCmd = tablespaceCmd + "\n" + strings.Join([]string{
+			`tablespace_dir=` + tempTablespaceDirectory,
+			`if [[ ! -e "${tablespace_dir}" || -O "${tablespace_dir}" ]]; then`,
+			`install --directory --mode=0700 "${tablespace_dir}"`,
+			`elif [[ -w "${tablespace_dir}" && -g "${tablespace_dir}" ]]; then`,
+			`recreate "${tablespace_dir}" '0700'`,
+			`else (halt Permissions!); fi ||`,
+			`halt "$(permissions "${tablespace_dir}" ||:)"`,
+			`for tablespace_link in "${postgres_data_directory}"/pg_tblspc/*; do`,
+			`[[ "$(readlink "${tablespace_link}")" == "${tablespace_dir}" ]] || continue`,
+			`catalog_version=$(LC_ALL=C pg_controldata "${postgres_data_directory}" | sed -n 's/^Catalog version number: *//p')`,
+			`install --directory --mode=0700 "${tablespace_dir}/PG_${expected_major_version}_${catalog_version}"`,
+			`done`,
+		}, "\n")
+	}
+
 	pg_rewind_override := ""
 	if config.FetchKeyCommand(&cluster.Spec) != "" {
 		// Quoting "EOF" disables parameter substitution during write.
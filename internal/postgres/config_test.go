@@ -515,4 +515,24 @@ pg_rewind -K "$(postgres -C encryption_key_command)" "$@"
 EOF
 chmod +x /tmp/pg_rewind_tde.sh`))
 	})
+
+	t.Run("TempVolume", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{
+			TempVolumeClaimSpec: &corev1.PersistentVolumeClaimSpec{},
+		}}
+
+		// Every instance prepares the directory of the tablespace.
+		command := startupCommand(cluster, instance)
+		assert.Assert(t, len(command) > 3)
+		assert.Assert(t, strings.Contains(command[3], "\ntablespace_dir=/pgtemp/tablespace\n"))
+		assert.Assert(t, strings.Contains(command[3], "pg_controldata"))
+
+		file := filepath.Join(dir, "temp.bash")
+		assert.NilError(t, os.WriteFile(file, []byte(command[3]), 0o600))
+
+		cmd := exec.Command(shellcheck, "--enable=all", file)
+		output, err := cmd.CombinedOutput()
+		assert.NilError(t, err, "%q\n%s", cmd.Args, output)
+	})
 }
@@ -0,0 +1,274 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// ForeignCredentials are the user and password with which a user mapping
+// connects to a foreign server.
+type ForeignCredentials struct {
+	User     string
+	Password string
+}
+
+// WriteForeignServersInPostgreSQL calls exec to install postgres_fdw into
+// database and to create or update its foreign servers and user mappings.
+// The credentials of each user mapping are found in credentials by the name
+// of its Secret. Server options and user mappings that are not in servers are
+// removed from those servers. Remote schemas are imported by the first user
+// mapping when the local schema has no foreign tables from the server.
+// - https://www.postgresql.org/docs/current/postgres-fdw.html
+func WriteForeignServersInPostgreSQL(
+	ctx context.Context, exec Executor, database string,
+	servers []v1beta1.PostgresForeignServerSpec,
+	credentials map[string]ForeignCredentials,
+) error {
+	log := logging.FromContext(ctx)
+
+	var err error
+	var sql bytes.Buffer
+
+	// Connect to the database and install the extension there. Quiet the
+	// NOTICE from IF NOT EXISTS.
+	// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMAND-CONNECT
+	_, _ = sql.WriteString(`\connect :"database"
+SET client_min_messages = WARNING;
+CREATE EXTENSION IF NOT EXISTS postgres_fdw;
+`)
+
+	// Prevent unexpected dereferences by emptying "search_path". The "pg_catalog"
+	// schema is still searched, and only temporary objects can be created.
+	// - https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-SEARCH-PATH
+	_, _ = sql.WriteString(`SET search_path TO '';`)
+
+	// Fill a temporary table with the JSON of the server specifications.
+	// "\copy" reads from subsequent lines until the special line "\.".
+	// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-META-COMMANDS-COPY
+	_, _ = sql.WriteString(`
+CREATE TEMPORARY TABLE input (id serial, data json);
+\copy input (data) from stdin with (format text)
+`)
+	encoder := json.NewEncoder(&sql)
+	encoder.SetEscapeHTML(false)
+
+	for i := range servers {
+		spec := servers[i]
+
+		options := make(map[string]string, len(spec.Options)+3)
+		for k, v := range spec.Options {
+			options[k] = v
+		}
+		options["host"] = spec.Host
+		options["dbname"] = string(spec.DBName)
+		options["port"] = "5432"
+		if spec.Port != nil {
+			options["port"] = fmt.Sprint(*spec.Port)
+		}
+
+		mappings := make([]map[string]any, 0, len(spec.UserMappings))
+		for _, mapping := range spec.UserMappings {
+			credential := credentials[mapping.SecretName]
+			mappings = append(mappings, map[string]any{
+				"user": mapping.User,
+				"options": map[string]string{
+					"user":     credential.User,
+					"password": credential.Password,
+				},
+			})
+		}
+
+		// Tables are imported by the first user mapping; there is nothing
+		// to import without one.
+		imports := make([]map[string]any, 0, len(spec.ImportSchemas))
+		for _, schema := range spec.ImportSchemas {
+			if len(spec.UserMappings) > 0 {
+				imports = append(imports, map[string]any{
+					"local":  schema.LocalSchema,
+					"owner":  spec.UserMappings[0].User,
+					"remote": schema.RemoteSchema,
+				})
+			}
+		}
+
+		if err == nil {
+			err = encoder.Encode(map[string]any{
+				"imports":  imports,
+				"mappings": mappings,
+				"options":  options,
+				"server":   spec.Name,
+			})
+		}
+	}
+	_, _ = sql.WriteString(`\.` + "\n")
+
+	// Create the following objects in a transaction so that servers are
+	// complete before any other session sees them.
+	_, _ = sql.WriteString(`BEGIN;`)
+
+	// Create servers that do not already exist.
+	// - https://www.postgresql.org/docs/current/sql-createserver.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('CREATE SERVER %I FOREIGN DATA WRAPPER postgres_fdw',
+       pg_catalog.json_extract_path_text(input.data, 'server'))
+  FROM input
+ WHERE NOT EXISTS (
+       SELECT 1 FROM pg_catalog.pg_foreign_server
+       WHERE srvname = pg_catalog.json_extract_path_text(input.data, 'server'))
+ ORDER BY input.id
+\gexec
+`)
+
+	// Remove server options that are not in the specification then add or
+	// change the rest. Options cannot be added when they exist nor changed
+	// when they do not.
+	// - https://www.postgresql.org/docs/current/sql-alterserver.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('ALTER SERVER %I OPTIONS (DROP %I)',
+       srv.srvname, opt.option_name)
+  FROM input
+  JOIN pg_catalog.pg_foreign_server srv
+    ON srv.srvname = pg_catalog.json_extract_path_text(input.data, 'server')
+ CROSS JOIN pg_catalog.pg_options_to_table(srv.srvoptions) AS opt
+ WHERE pg_catalog.json_extract_path(input.data, 'options', opt.option_name) IS NULL
+ ORDER BY input.id
+\gexec
+
+SELECT pg_catalog.format('ALTER SERVER %I OPTIONS (%s %I %L)',
+       srv.srvname, CASE WHEN opt.option_name IS NULL THEN 'ADD' ELSE 'SET' END,
+       spec.key, spec.value)
+  FROM input
+  JOIN pg_catalog.pg_foreign_server srv
+    ON srv.srvname = pg_catalog.json_extract_path_text(input.data, 'server')
+ CROSS JOIN pg_catalog.json_each_text(
+       pg_catalog.json_extract_path(input.data, 'options')) AS spec
+  LEFT JOIN pg_catalog.pg_options_to_table(srv.srvoptions) AS opt
+    ON opt.option_name = spec.key
+ ORDER BY input.id
+\gexec
+`)
+
+	// Drop user mappings that are not in the specification, except the one
+	// for PUBLIC. Allow the remaining users to use their servers.
+	// - https://www.postgresql.org/docs/current/sql-dropusermapping.html
+	// - https://www.postgresql.org/docs/current/sql-grant.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('DROP USER MAPPING FOR %I SERVER %I',
+       um.usename, um.srvname)
+  FROM input
+  JOIN pg_catalog.pg_user_mappings um
+    ON um.srvname = pg_catalog.json_extract_path_text(input.data, 'server')
+ WHERE um.umuser <> 0 AND NOT EXISTS (
+       SELECT 1 FROM pg_catalog.json_array_elements(
+              pg_catalog.json_extract_path(input.data, 'mappings')) AS mapping
+       WHERE pg_catalog.json_extract_path_text(mapping, 'user') = um.usename)
+ ORDER BY input.id
+\gexec
+
+SELECT pg_catalog.format('GRANT USAGE ON FOREIGN SERVER %I TO %I',
+       pg_catalog.json_extract_path_text(input.data, 'server'),
+       pg_catalog.json_extract_path_text(mapping, 'user'))
+  FROM input
+ CROSS JOIN pg_catalog.json_array_elements(
+       pg_catalog.json_extract_path(input.data, 'mappings')) AS mapping
+ ORDER BY input.id
+\gexec
+`)
+
+	// Create user mappings then add or change their credentials. A change to
+	// the credentials in a Secret changes the password here.
+	// - https://www.postgresql.org/docs/current/sql-createusermapping.html
+	// - https://www.postgresql.org/docs/current/sql-alterusermapping.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('CREATE USER MAPPING IF NOT EXISTS FOR %I SERVER %I',
+       pg_catalog.json_extract_path_text(mapping, 'user'),
+       pg_catalog.json_extract_path_text(input.data, 'server'))
+  FROM input
+ CROSS JOIN pg_catalog.json_array_elements(
+       pg_catalog.json_extract_path(input.data, 'mappings')) AS mapping
+ ORDER BY input.id
+\gexec
+
+SELECT pg_catalog.format('ALTER USER MAPPING FOR %I SERVER %I OPTIONS (%s %I %L)',
+       um.usename, um.srvname,
+       CASE WHEN opt.option_name IS NULL THEN 'ADD' ELSE 'SET' END,
+       spec.key, spec.value)
+  FROM input
+ CROSS JOIN pg_catalog.json_array_elements(
+       pg_catalog.json_extract_path(input.data, 'mappings')) AS mapping
+  JOIN pg_catalog.pg_user_mappings um
+    ON um.srvname = pg_catalog.json_extract_path_text(input.data, 'server')
+   AND um.usename = pg_catalog.json_extract_path_text(mapping, 'user')
+ CROSS JOIN pg_catalog.json_each_text(
+       pg_catalog.json_extract_path(mapping, 'options')) AS spec
+  LEFT JOIN pg_catalog.pg_options_to_table(um.umoptions) AS opt
+    ON opt.option_name = spec.key
+ ORDER BY input.id
+\gexec
+`)
+
+	// Create local schemas then import remote tables into those that have
+	// none from the server. The first user mapping owns both.
+	// - https://www.postgresql.org/docs/current/sql-importforeignschema.html
+	_, _ = sql.WriteString(`
+SELECT pg_catalog.format('CREATE SCHEMA IF NOT EXISTS %I AUTHORIZATION %I',
+       pg_catalog.json_extract_path_text(imports, 'local'),
+       pg_catalog.json_extract_path_text(imports, 'owner'))
+  FROM input
+ CROSS JOIN pg_catalog.json_array_elements(
+       pg_catalog.json_extract_path(input.data, 'imports')) AS imports
+ ORDER BY input.id
+\gexec
+
+SELECT pg_catalog.format('SET ROLE %I; IMPORT FOREIGN SCHEMA %I FROM SERVER %I INTO %I; RESET ROLE',
+       pg_catalog.json_extract_path_text(imports, 'owner'),
+       pg_catalog.json_extract_path_text(imports, 'remote'),
+       pg_catalog.json_extract_path_text(input.data, 'server'),
+       pg_catalog.json_extract_path_text(imports, 'local'))
+  FROM input
+ CROSS JOIN pg_catalog.json_array_elements(
+       pg_catalog.json_extract_path(input.data, 'imports')) AS imports
+ WHERE NOT EXISTS (
+       SELECT 1 FROM pg_catalog.pg_foreign_table ft
+         JOIN pg_catalog.pg_class c ON c.oid = ft.ftrelid
+         JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+         JOIN pg_catalog.pg_foreign_server s ON s.oid = ft.ftserver
+        WHERE n.nspname = pg_catalog.json_extract_path_text(imports, 'local')
+          AND s.srvname = pg_catalog.json_extract_path_text(input.data, 'server'))
+ ORDER BY input.id
+\gexec
+`)
+
+	// Commit (finish) the transaction.
+	_, _ = sql.WriteString(`COMMIT;`)
+
+	stdout, stderr, err := exec.Exec(ctx, &sql,
+		map[string]string{
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+			"database":      database,
+		})
+
+	log.V(1).Info("wrote PostgreSQL foreign servers", "stdout", stdout, "stderr", stderr)
+
+	return err
+}
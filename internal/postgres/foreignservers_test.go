@@ -0,0 +1,98 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestWriteForeignServersInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Arguments", func(t *testing.T) {
+		expected := errors.New("pass-through")
+		exec := func(
+			_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+		) error {
+			assert.Assert(t, stdout != nil, "should capture stdout")
+			assert.Assert(t, stderr != nil, "should capture stderr")
+			assert.Assert(t, cmp.Contains(command, "--set=database=some db"))
+			return expected
+		}
+
+		assert.Equal(t, expected, WriteForeignServersInPostgreSQL(ctx, exec, "some db", nil, nil))
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		calls := 0
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			calls++
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+
+			sql := string(b)
+			assert.Assert(t, strings.HasPrefix(sql, `\connect :"database"`))
+			assert.Assert(t, cmp.Contains(sql, "CREATE EXTENSION IF NOT EXISTS postgres_fdw;"))
+			assert.Assert(t, cmp.Contains(sql, `
+\copy input (data) from stdin with (format text)
+{"imports":[{"local":"billing","owner":"app","remote":"public"}],"mappings":[{"options":{"password":"p@ss","user":"reader"},"user":"app"},{"options":{"password":"","user":""},"user":"other"}],"options":{"dbname":"billing","host":"billing.example.com","port":"6543","sslmode":"require"},"server":"billing"}
+{"imports":[],"mappings":[],"options":{"dbname":"orders","host":"orders","port":"5432"},"server":"orders"}
+\.
+BEGIN;`))
+			assert.Assert(t, strings.HasSuffix(sql, "COMMIT;"))
+			return nil
+		}
+
+		assert.NilError(t, WriteForeignServersInPostgreSQL(ctx, exec, "app",
+			[]v1beta1.PostgresForeignServerSpec{
+				{
+					Name: "billing", Host: "billing.example.com", DBName: "billing",
+					Port:    initialize.Int32(6543),
+					Options: map[string]string{"sslmode": "require", "host": "ignored"},
+					UserMappings: []v1beta1.PostgresUserMappingSpec{
+						{User: "app", SecretName: "billing-reader"},
+						{User: "other", SecretName: "missing"},
+					},
+					ImportSchemas: []v1beta1.PostgresForeignSchemaSpec{
+						{RemoteSchema: "public", LocalSchema: "billing"},
+					},
+				},
+				{
+					Name: "orders", Host: "orders", DBName: "orders",
+					// Nothing is imported without a user mapping.
+					ImportSchemas: []v1beta1.PostgresForeignSchemaSpec{
+						{RemoteSchema: "public", LocalSchema: "orders"},
+					},
+				},
+			},
+			map[string]ForeignCredentials{
+				"billing-reader": {User: "reader", Password: "p@ss"},
+			}))
+		assert.Equal(t, calls, 1)
+	})
+}
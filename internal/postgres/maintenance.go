@@ -0,0 +1,101 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+)
+
+// MaintenanceUserHBAs provides the HBA rules that allow MaintenanceUser to
+// connect. Only certificate authentication over TLS is allowed.
+func MaintenanceUserHBAs(outHBAs *HBAs) {
+	outHBAs.Mandatory = append(outHBAs.Mandatory,
+		*NewHBA().TLS().User(MaintenanceUser).Method("cert"),
+		*NewHBA().TCP().User(MaintenanceUser).Method("reject"),
+	)
+}
+
+// CreateMaintenanceUser creates MaintenanceUser when it does not exist. It can
+// login with a certificate, never a password, but it has no privileges until
+// a feature grants them.
+func CreateMaintenanceUser(ctx context.Context, exec Executor) error {
+	log := logging.FromContext(ctx)
+
+	stdout, stderr, err := exec.ExecInDatabasesFromQuery(ctx,
+		`SELECT pg_catalog.current_database()`,
+		strings.Join([]string{
+			strings.TrimSpace(`
+SELECT pg_catalog.format('CREATE ROLE %I', :'username')
+ WHERE NOT EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = :'username')
+\gexec`),
+			`ALTER ROLE :"username" LOGIN NOSUPERUSER NOCREATEDB NOCREATEROLE PASSWORD NULL;`,
+		}, "\n"),
+		map[string]string{
+			"username": MaintenanceUser,
+
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("created maintenance user", "stdout", stdout, "stderr", stderr)
+
+	return err
+}
+
+// DropMaintenanceUser removes MaintenanceUser along with any privileges and
+// memberships granted to it in every database.
+func DropMaintenanceUser(ctx context.Context, exec Executor) error {
+	log := logging.FromContext(ctx)
+
+	stdout, stderr, err := exec.ExecInAllDatabases(ctx,
+		strings.Join([]string{
+			// Quiet NOTICE messages from IF EXISTS statements.
+			// - https://www.postgresql.org/docs/current/runtime-config-client.html
+			`SET client_min_messages = WARNING;`,
+
+			strings.TrimSpace(`
+SELECT pg_catalog.format('DROP OWNED BY %I CASCADE', :'username')
+ WHERE EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = :'username')
+\gexec`),
+		}, "\n"),
+		map[string]string{
+			"username": MaintenanceUser,
+
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("removed maintenance privileges", "stdout", stdout, "stderr", stderr)
+
+	if err == nil {
+		stdout, stderr, err = exec.ExecInDatabasesFromQuery(ctx,
+			`SELECT pg_catalog.current_database()`,
+			`SET client_min_messages = WARNING; DROP ROLE IF EXISTS :"username";`,
+			map[string]string{
+				"username": MaintenanceUser,
+
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+				"QUIET":         "on", // Do not print successful statements to stdout.
+			})
+
+		log.V(1).Info("removed maintenance user", "stdout", stdout, "stderr", stderr)
+	}
+
+	return err
+}
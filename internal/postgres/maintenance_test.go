@@ -0,0 +1,82 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgres
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+)
+
+func TestMaintenanceUserHBAs(t *testing.T) {
+	hbas := HBAs{}
+	MaintenanceUserHBAs(&hbas)
+	assert.Equal(t, len(hbas.Mandatory), 2)
+	assert.Equal(t, hbas.Mandatory[0].String(), `hostssl all "_crunchymaintenance" all cert`)
+	assert.Equal(t, hbas.Mandatory[1].String(), `host all "_crunchymaintenance" all reject`)
+}
+
+func TestCreateMaintenanceUser(t *testing.T) {
+	expected := errors.New("whoops")
+	exec := func(
+		_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		assert.Assert(t, stdout != nil, "should capture stdout")
+		assert.Assert(t, stderr != nil, "should capture stderr")
+		assert.Assert(t, strings.Contains(strings.Join(command, "\n"),
+			`--set=username=_crunchymaintenance`))
+
+		b, err := io.ReadAll(stdin)
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(string(b), `CREATE ROLE %I`))
+		assert.Assert(t, cmp.Contains(string(b), `NOSUPERUSER`))
+		assert.Assert(t, cmp.Contains(string(b), `PASSWORD NULL;`))
+
+		return expected
+	}
+
+	ctx := context.Background()
+	assert.Equal(t, expected, CreateMaintenanceUser(ctx, exec))
+}
+
+func TestDropMaintenanceUser(t *testing.T) {
+	calls := 0
+	exec := func(
+		_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+	) error {
+		calls++
+
+		b, err := io.ReadAll(stdin)
+		assert.NilError(t, err)
+		switch calls {
+		case 1:
+			assert.Assert(t, cmp.Contains(string(b), `DROP OWNED BY %I CASCADE`))
+		case 2:
+			assert.Assert(t, cmp.Contains(string(b), `DROP ROLE IF EXISTS :"username";`))
+		}
+		return nil
+	}
+
+	ctx := context.Background()
+	assert.NilError(t, DropMaintenanceUser(ctx, exec))
+	assert.Equal(t, calls, 2)
+}
@@ -0,0 +1,69 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// ReadOnly returns whether or not cluster should reject writes by default.
+func ReadOnly(cluster *v1beta1.PostgresCluster) bool {
+	return cluster.Spec.ReadOnly != nil && *cluster.Spec.ReadOnly
+}
+
+// SetReadOnly populates the PostgreSQL parameter that makes every transaction
+// read-only by default when cluster is read-only.
+// - https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-DEFAULT-TRANSACTION-READ-ONLY
+func SetReadOnly(cluster *v1beta1.PostgresCluster, pgParameters *Parameters) {
+	if ReadOnly(cluster) {
+		pgParameters.Mandatory.Add("default_transaction_read_only", "on")
+	}
+}
+
+// WriteReadOnlyInPostgreSQL calls exec to exempt the current user, the
+// superuser with which the operator and Patroni connect, from the read-only
+// default when readOnly is true. The exemption is removed otherwise. A role
+// setting takes precedence over the server configuration.
+// - https://www.postgresql.org/docs/current/sql-alterrole.html
+func WriteReadOnlyInPostgreSQL(ctx context.Context, exec Executor, readOnly bool) error {
+	log := logging.FromContext(ctx)
+
+	statements := []string{
+		// The server may already be read-only; this session is not.
+		`SET default_transaction_read_only = off;`,
+	}
+	if readOnly {
+		statements = append(statements,
+			`ALTER ROLE CURRENT_USER SET default_transaction_read_only = off;`)
+	} else {
+		statements = append(statements,
+			`ALTER ROLE CURRENT_USER RESET default_transaction_read_only;`)
+	}
+
+	stdout, stderr, err := exec.Exec(ctx,
+		strings.NewReader(strings.Join(statements, "\n")),
+		map[string]string{
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("wrote read-only settings", "stdout", stdout, "stderr", stderr)
+
+	return err
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestSetReadOnly(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	pgParameters := NewParameters()
+	SetReadOnly(cluster, &pgParameters)
+
+	assert.Assert(t, !pgParameters.Mandatory.Has("default_transaction_read_only"))
+
+	cluster.Spec.ReadOnly = initialize.Bool(false)
+	SetReadOnly(cluster, &pgParameters)
+
+	assert.Assert(t, !pgParameters.Mandatory.Has("default_transaction_read_only"))
+
+	cluster.Spec.ReadOnly = initialize.Bool(true)
+	SetReadOnly(cluster, &pgParameters)
+
+	assert.Equal(t, pgParameters.Mandatory.Value("default_transaction_read_only"), "on")
+}
+
+func TestWriteReadOnlyInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		readOnly bool
+		expected string
+	}{
+		{readOnly: true, expected: `ALTER ROLE CURRENT_USER SET default_transaction_read_only = off;`},
+		{readOnly: false, expected: `ALTER ROLE CURRENT_USER RESET default_transaction_read_only;`},
+	} {
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			assert.Assert(t, strings.Contains(strings.Join(command, " "), "--set=ON_ERROR_STOP=on"))
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Equal(t, string(b), "SET default_transaction_read_only = off;\n"+tt.expected)
+			return nil
+		}
+
+		assert.NilError(t, WriteReadOnlyInPostgreSQL(ctx, exec, tt.readOnly))
+	}
+}
@@ -276,7 +276,7 @@ func InstancePod(ctx context.Context,
 
 	// If the InstanceSidecars feature gate is enabled and instance sidecars are
 	// defined, add the defined container to the Pod.
-	if util.DefaultMutableFeatureGate.Enabled(util.InstanceSidecars) &&
+	if util.FeatureEnabled(inCluster.Spec.Features, util.InstanceSidecars) &&
 		inInstanceSpec.Containers != nil {
 		outInstancePod.Containers = append(outInstancePod.Containers, inInstanceSpec.Containers...)
 	}
@@ -0,0 +1,233 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+)
+
+// ReindexReason identifies why indexes are rebuilt and, by extension, which
+// indexes are rebuilt.
+type ReindexReason string
+
+const (
+	// ReindexCollationVersionChanged rebuilds indexes with a key column that
+	// sorts by a collation whose version changed.
+	ReindexCollationVersionChanged ReindexReason = "CollationVersionChanged"
+
+	// ReindexIntegrityCheckFailed verifies every B-tree index using amcheck
+	// and rebuilds those that fail.
+	ReindexIntegrityCheckFailed ReindexReason = "IntegrityCheckFailed"
+
+	// ReindexMajorUpgrade rebuilds every B-tree index. Indexes upgraded from
+	// PostgreSQL 11 and earlier keep an older format that cannot deduplicate
+	// nor truncate keys.
+	// - https://www.postgresql.org/docs/current/btree-implementation.html
+	ReindexMajorUpgrade ReindexReason = "MajorUpgrade"
+)
+
+// reindexCandidates returns SQL that selects the qualified name of every index
+// in the current database that should be rebuilt for reason. The name, unlike
+// the OID, stays the same when an index is rebuilt concurrently. Indexes of
+// system catalogs and temporary tables cannot be rebuilt concurrently, and
+// invalid indexes are left for their owners to drop or rebuild.
+// - https://www.postgresql.org/docs/current/sql-reindex.html#SQL-REINDEX-CONCURRENTLY
+func reindexCandidates(reason ReindexReason, version int) string {
+	sql := strings.TrimSpace(`
+SELECT pg_catalog.format('%I.%I', n.nspname, c.relname) AS name
+  FROM pg_catalog.pg_index i
+  JOIN pg_catalog.pg_class c ON c.oid = i.indexrelid
+  JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+ WHERE c.relkind = 'i' AND c.relpersistence <> 't'
+   AND i.indisvalid AND i.indexrelid >= 16384`)
+
+	const btree = `
+   AND c.relam = (SELECT oid FROM pg_catalog.pg_am WHERE amname = 'btree')`
+
+	switch reason {
+	case ReindexCollationVersionChanged:
+		sql += `
+   AND EXISTS (
+       SELECT 1 FROM pg_catalog.pg_collation coll
+        WHERE coll.oid = ANY (i.indcollation::pg_catalog.oid[])
+          AND ((coll.collprovider <> 'd'
+                AND coll.collversion <> pg_catalog.pg_collation_actual_version(coll.oid))`
+
+		// The version of the default collation is recorded in pg_database
+		// since PostgreSQL 15.
+		if version >= 15 {
+			sql += `
+            OR (coll.collprovider = 'd' AND EXISTS (
+                SELECT 1 FROM pg_catalog.pg_database d
+                 WHERE d.datname = pg_catalog.current_database()
+                   AND d.datcollversion <> pg_catalog.pg_database_collation_actual_version(d.oid)))`
+		}
+		sql += `))`
+
+	case ReindexIntegrityCheckFailed:
+		sql += btree + `
+   AND EXISTS (SELECT 1 FROM pg_catalog.pg_extension WHERE extname = 'amcheck')`
+
+	case ReindexMajorUpgrade:
+		sql += btree
+	}
+
+	return sql
+}
+
+// CountReindexCandidates returns, for every database that allows connections,
+// the number of indexes that should be rebuilt for reason. Databases without
+// any are omitted.
+func CountReindexCandidates(
+	ctx context.Context, exec Executor, reason ReindexReason, version int,
+) (map[string]int32, error) {
+	log := logging.FromContext(ctx)
+
+	stdout, stderr, err := exec.ExecInAllDatabases(ctx,
+		strings.Join([]string{
+			`\pset format unaligned`,
+			`\pset tuples_only on`,
+			`SELECT pg_catalog.current_database(), pg_catalog.count(*)`,
+			` FROM (` + reindexCandidates(reason, version) + `) AS candidates`,
+			`HAVING pg_catalog.count(*) > 0;`,
+		}, "\n"),
+		map[string]string{
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("counted indexes to rebuild", "stdout", stdout, "stderr", stderr)
+
+	var counts map[string]int32
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		if database, count, ok := strings.Cut(line, "|"); ok && err == nil {
+			var n int64
+			if n, err = strconv.ParseInt(count, 10, 32); err == nil {
+				if counts == nil {
+					counts = make(map[string]int32)
+				}
+				counts[database] = int32(n)
+			}
+		}
+	}
+	return counts, err
+}
+
+// ReindexCandidates returns the qualified names of at most limit indexes in
+// database that should be rebuilt for reason, in order, starting after the
+// index named after.
+func ReindexCandidates(
+	ctx context.Context, exec Executor, database string,
+	reason ReindexReason, version int, after string, limit int32,
+) ([]string, error) {
+	log := logging.FromContext(ctx)
+
+	stdout, stderr, err := exec.Exec(ctx,
+		strings.NewReader(strings.Join([]string{
+			`\connect :"database"`,
+			`\pset format unaligned`,
+			`\pset tuples_only on`,
+			`SELECT name FROM (` + reindexCandidates(reason, version) + `) AS candidates`,
+			` WHERE name COLLATE "C" > :'after'`,
+			` ORDER BY name COLLATE "C" LIMIT :'limit';`,
+		}, "\n")),
+		map[string]string{
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+
+			"after":    after,
+			"database": database,
+			"limit":    fmt.Sprint(limit),
+		})
+
+	log.V(1).Info("listed indexes to rebuild", "stdout", stdout, "stderr", stderr)
+
+	var indexes []string
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		if line != "" {
+			indexes = append(indexes, line)
+		}
+	}
+	return indexes, err
+}
+
+// RebuildIndex rebuilds the index with qualified name index in database
+// without blocking reads or writes of its table. When verify is true, the
+// index is rebuilt only when amcheck reports a problem with it. An index that
+// no longer exists is ignored.
+// - https://www.postgresql.org/docs/current/sql-reindex.html
+// - https://www.postgresql.org/docs/current/amcheck.html
+func RebuildIndex(
+	ctx context.Context, exec Executor, database, index string, verify bool,
+) error {
+	log := logging.FromContext(ctx)
+
+	reindex := strings.TrimSpace(`
+SELECT pg_catalog.format('REINDEX INDEX CONCURRENTLY %s', c.oid::pg_catalog.regclass)
+  FROM pg_catalog.pg_class c
+ WHERE c.oid = pg_catalog.to_regclass(:'index') AND c.relkind = 'i'
+\gexec`)
+
+	statements := []string{
+		`\connect :"database"`,
+
+		// Quiet NOTICE messages from REINDEX and qualify every index name.
+		// - https://www.postgresql.org/docs/current/runtime-config-client.html
+		`SET client_min_messages = WARNING;`,
+		`SET search_path TO '';`,
+	}
+
+	if verify {
+		// Check the index with the extension wherever it is installed. The
+		// check fails by raising an error, so allow it to fail and rebuild
+		// only when it does.
+		// - https://www.postgresql.org/docs/current/app-psql.html#APP-PSQL-VARIABLES-ERROR
+		statements = append(statements,
+			`\set ON_ERROR_STOP off`,
+			strings.TrimSpace(`
+SELECT pg_catalog.format('SELECT %I.bt_index_check(%L)', n.nspname, c.oid::pg_catalog.regclass)
+  FROM pg_catalog.pg_class c, pg_catalog.pg_extension e
+  JOIN pg_catalog.pg_namespace n ON n.oid = e.extnamespace
+ WHERE c.oid = pg_catalog.to_regclass(:'index') AND c.relkind = 'i' AND e.extname = 'amcheck'
+\gexec`),
+			`\if :ERROR`,
+			`\set ON_ERROR_STOP on`,
+			reindex,
+			`\endif`,
+		)
+	} else {
+		statements = append(statements, reindex)
+	}
+
+	stdout, stderr, err := exec.Exec(ctx,
+		strings.NewReader(strings.Join(statements, "\n")),
+		map[string]string{
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+
+			"database": database,
+			"index":    index,
+		})
+
+	log.V(1).Info("rebuilt index", "database", database, "index", index,
+		"stdout", stdout, "stderr", stderr)
+
+	return err
+}
@@ -0,0 +1,155 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+)
+
+func TestReindexCandidatesSQL(t *testing.T) {
+	for _, tt := range []struct {
+		reason   ReindexReason
+		version  int
+		contains []string
+		excludes []string
+	}{
+		{
+			reason:   ReindexCollationVersionChanged,
+			version:  14,
+			contains: []string{`pg_collation_actual_version`, `indcollation`},
+			excludes: []string{`datcollversion`, `btree`},
+		},
+		{
+			reason:   ReindexCollationVersionChanged,
+			version:  16,
+			contains: []string{`pg_collation_actual_version`, `datcollversion`},
+		},
+		{
+			reason:   ReindexIntegrityCheckFailed,
+			version:  16,
+			contains: []string{`amname = 'btree'`, `extname = 'amcheck'`},
+			excludes: []string{`indcollation`},
+		},
+		{
+			reason:   ReindexMajorUpgrade,
+			version:  16,
+			contains: []string{`amname = 'btree'`},
+			excludes: []string{`amcheck`, `indcollation`},
+		},
+	} {
+		sql := reindexCandidates(tt.reason, tt.version)
+		assert.Assert(t, cmp.Contains(sql, `i.indisvalid AND i.indexrelid >= 16384`))
+		assert.Assert(t, strings.Count(sql, "(") == strings.Count(sql, ")"), "unbalanced: %s", sql)
+
+		for _, s := range tt.contains {
+			assert.Assert(t, cmp.Contains(sql, s))
+		}
+		for _, s := range tt.excludes {
+			assert.Assert(t, !strings.Contains(sql, s), "unexpected %q", s)
+		}
+	}
+}
+
+func TestCountReindexCandidates(t *testing.T) {
+	ctx := context.Background()
+
+	exec := func(
+		_ context.Context, stdin io.Reader, stdout, _ io.Writer, command ...string,
+	) error {
+		assert.Equal(t, command[0], "bash")
+
+		b, err := io.ReadAll(stdin)
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(string(b), `HAVING pg_catalog.count(*) > 0;`))
+
+		_, err = stdout.Write([]byte("app|12\npostgres|1\n"))
+		return err
+	}
+
+	counts, err := CountReindexCandidates(ctx, exec, ReindexMajorUpgrade, 16)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, counts, map[string]int32{"app": 12, "postgres": 1})
+}
+
+func TestReindexCandidates(t *testing.T) {
+	ctx := context.Background()
+
+	exec := func(
+		_ context.Context, stdin io.Reader, stdout, _ io.Writer, command ...string,
+	) error {
+		assert.DeepEqual(t, command, []string{"psql", "-Xw", "--file=-",
+			"--set=ON_ERROR_STOP=on", "--set=QUIET=on",
+			"--set=after=public.a", "--set=database=app", "--set=limit=2",
+		})
+
+		b, err := io.ReadAll(stdin)
+		assert.NilError(t, err)
+		assert.Assert(t, strings.HasPrefix(string(b), `\connect :"database"`))
+		assert.Assert(t, cmp.Contains(string(b), `ORDER BY name COLLATE "C" LIMIT :'limit';`))
+
+		_, err = stdout.Write([]byte("public.b\n\"Mixed\".\"Case\"\n"))
+		return err
+	}
+
+	indexes, err := ReindexCandidates(ctx, exec, "app", ReindexCollationVersionChanged, 16, "public.a", 2)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, indexes, []string{"public.b", `"Mixed"."Case"`})
+}
+
+func TestRebuildIndex(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("Rebuild", func(t *testing.T) {
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			assert.Assert(t, cmp.Contains(strings.Join(command, "\n"), "--set=index=public.b"))
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Assert(t, cmp.Contains(string(b), `REINDEX INDEX CONCURRENTLY %s`))
+			assert.Assert(t, !strings.Contains(string(b), `bt_index_check`))
+			return nil
+		}
+
+		assert.NilError(t, RebuildIndex(ctx, exec, "app", "public.b", false))
+	})
+
+	t.Run("Verify", func(t *testing.T) {
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, _ ...string,
+		) error {
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+
+			// The index is checked, and rebuilt only when the check fails.
+			sql := string(b)
+			assert.Assert(t, cmp.Contains(sql, `bt_index_check`))
+			assert.Assert(t, strings.Index(sql, `\set ON_ERROR_STOP off`) < strings.Index(sql, `bt_index_check`))
+			assert.Assert(t, strings.Index(sql, `\if :ERROR`) < strings.Index(sql, `REINDEX INDEX`))
+			assert.Assert(t, strings.HasSuffix(sql, `\endif`))
+			return nil
+		}
+
+		assert.NilError(t, RebuildIndex(ctx, exec, "app", "public.b", true))
+	})
+}
@@ -0,0 +1,135 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	// TempTablespace is the name of the tablespace for temporary files.
+	TempTablespace = "pgtemp"
+
+	// tempTablespaceDirectory is the location of [TempTablespace]. It is a
+	// subdirectory of the volume so that its owner and permissions can be
+	// arranged like those of tablespace volumes.
+	tempTablespaceDirectory = tempMountPath + "/tablespace"
+)
+
+// TempVolume returns whether or not any instance set of cluster has a volume
+// for temporary files.
+func TempVolume(cluster *v1beta1.PostgresCluster) bool {
+	for i := range cluster.Spec.InstanceSets {
+		if cluster.Spec.InstanceSets[i].TempVolumeClaimSpec != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// TempVolumeMount returns the name and mount path of the volume for temporary
+// files.
+func TempVolumeMount() corev1.VolumeMount {
+	return corev1.VolumeMount{Name: "postgres-temp", MountPath: tempMountPath}
+}
+
+// SetTempTablespaces populates the PostgreSQL parameter that puts temporary
+// files in [TempTablespace] when cluster has a volume for them. PostgreSQL
+// ignores the parameter until the tablespace exists.
+// - https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-TEMP-TABLESPACES
+func SetTempTablespaces(cluster *v1beta1.PostgresCluster, pgParameters *Parameters) {
+	if TempVolume(cluster) {
+		pgParameters.Default.Add("temp_tablespaces", TempTablespace)
+	}
+}
+
+// InstanceTempVolume adds the volume for temporary files to the database and
+// startup containers of outInstancePod when any instance of cluster has one.
+// Every instance needs the directory of [TempTablespace] to replay its
+// creation, so inTempVolume is used when it exists and an emptyDir otherwise.
+func InstanceTempVolume(
+	cluster *v1beta1.PostgresCluster, inTempVolume *corev1.PersistentVolumeClaim,
+	outInstancePod *corev1.PodSpec,
+) {
+	if !TempVolume(cluster) {
+		return
+	}
+
+	mount := TempVolumeMount()
+	volume := corev1.Volume{Name: mount.Name}
+	if inTempVolume != nil {
+		volume.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{
+			ClaimName: inTempVolume.Name,
+		}
+	} else {
+		volume.EmptyDir = &corev1.EmptyDirVolumeSource{}
+	}
+	outInstancePod.Volumes = append(outInstancePod.Volumes, volume)
+
+	for i := range outInstancePod.Containers {
+		if outInstancePod.Containers[i].Name == naming.ContainerDatabase {
+			outInstancePod.Containers[i].VolumeMounts =
+				append(outInstancePod.Containers[i].VolumeMounts, mount)
+		}
+	}
+	for i := range outInstancePod.InitContainers {
+		if outInstancePod.InitContainers[i].Name == naming.ContainerPostgresStartup {
+			outInstancePod.InitContainers[i].VolumeMounts =
+				append(outInstancePod.InitContainers[i].VolumeMounts, mount)
+		}
+	}
+}
+
+// WriteTempTablespaceInPostgreSQL calls exec to create [TempTablespace] when
+// enabled is true or to drop it otherwise. A tablespace cannot be dropped while
+// it contains temporary tables.
+// - https://www.postgresql.org/docs/current/sql-createtablespace.html
+func WriteTempTablespaceInPostgreSQL(ctx context.Context, exec Executor, enabled bool) error {
+	log := logging.FromContext(ctx)
+
+	var statements []string
+	if enabled {
+		// CREATE TABLESPACE cannot run inside a transaction block, so it is
+		// generated and executed by psql.
+		statements = []string{
+			`SELECT pg_catalog.format('CREATE TABLESPACE %I LOCATION %L', :'name', :'location')`,
+			` WHERE NOT EXISTS (SELECT 1 FROM pg_catalog.pg_tablespace WHERE spcname = :'name')`,
+			`\gexec`,
+		}
+	} else {
+		statements = []string{`DROP TABLESPACE IF EXISTS :"name";`}
+	}
+
+	stdout, stderr, err := exec.Exec(ctx,
+		strings.NewReader(strings.Join(statements, "\n")),
+		map[string]string{
+			"name":     TempTablespace,
+			"location": tempTablespaceDirectory,
+
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("wrote temporary tablespace", "stdout", stdout, "stderr", stderr)
+
+	return err
+}
@@ -0,0 +1,120 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestSetTempTablespaces(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{Name: "a"}, {Name: "b"}}
+
+	pgParameters := NewParameters()
+	SetTempTablespaces(cluster, &pgParameters)
+	assert.Assert(t, !pgParameters.Default.Has("temp_tablespaces"))
+
+	cluster.Spec.InstanceSets[1].TempVolumeClaimSpec = &corev1.PersistentVolumeClaimSpec{}
+	SetTempTablespaces(cluster, &pgParameters)
+	assert.Equal(t, pgParameters.Default.Value("temp_tablespaces"), "pgtemp")
+}
+
+func TestInstanceTempVolume(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{Name: "a"}, {Name: "b"}}
+
+	pod := func() *corev1.PodSpec {
+		return &corev1.PodSpec{
+			Containers:     []corev1.Container{{Name: naming.ContainerDatabase}, {Name: "other"}},
+			InitContainers: []corev1.Container{{Name: naming.ContainerPostgresStartup}},
+		}
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		out := pod()
+		InstanceTempVolume(cluster, nil, out)
+		assert.DeepEqual(t, out, pod())
+	})
+
+	cluster.Spec.InstanceSets[0].TempVolumeClaimSpec = &corev1.PersistentVolumeClaimSpec{}
+
+	t.Run("PersistentVolumeClaim", func(t *testing.T) {
+		pvc := &corev1.PersistentVolumeClaim{}
+		pvc.Name = "hippo-a-abcd-pgtemp"
+
+		out := pod()
+		InstanceTempVolume(cluster, pvc, out)
+
+		assert.Assert(t, cmp.MarshalMatches(out.Volumes, `
+- name: postgres-temp
+  persistentVolumeClaim:
+    claimName: hippo-a-abcd-pgtemp
+		`))
+		assert.DeepEqual(t, out.Containers[0].VolumeMounts, []corev1.VolumeMount{TempVolumeMount()})
+		assert.DeepEqual(t, out.InitContainers[0].VolumeMounts, []corev1.VolumeMount{TempVolumeMount()})
+		assert.Assert(t, out.Containers[1].VolumeMounts == nil)
+	})
+
+	t.Run("EmptyDir", func(t *testing.T) {
+		out := pod()
+		InstanceTempVolume(cluster, nil, out)
+
+		assert.Assert(t, cmp.MarshalMatches(out.Volumes, `
+- emptyDir: {}
+  name: postgres-temp
+		`))
+		assert.DeepEqual(t, out.Containers[0].VolumeMounts, []corev1.VolumeMount{TempVolumeMount()})
+	})
+}
+
+func TestWriteTempTablespaceInPostgreSQL(t *testing.T) {
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		enabled  bool
+		expected string
+	}{
+		{enabled: true, expected: `SELECT pg_catalog.format('CREATE TABLESPACE %I LOCATION %L', :'name', :'location')
+ WHERE NOT EXISTS (SELECT 1 FROM pg_catalog.pg_tablespace WHERE spcname = :'name')
+\gexec`},
+		{enabled: false, expected: `DROP TABLESPACE IF EXISTS :"name";`},
+	} {
+		exec := func(
+			_ context.Context, stdin io.Reader, _, _ io.Writer, command ...string,
+		) error {
+			args := strings.Join(command, " ")
+			assert.Assert(t, strings.Contains(args, "--set=ON_ERROR_STOP=on"))
+			assert.Assert(t, strings.Contains(args, "--set=name=pgtemp"))
+			assert.Assert(t, strings.Contains(args, "--set=location=/pgtemp/tablespace"))
+
+			b, err := io.ReadAll(stdin)
+			assert.NilError(t, err)
+			assert.Equal(t, string(b), tt.expected)
+			return nil
+		}
+
+		assert.NilError(t, WriteTempTablespaceInPostgreSQL(ctx, exec, tt.enabled))
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// SetTimeZone populates the PostgreSQL parameters that set the time zone of
+// sessions and of log messages when cluster has a time zone. Both remain
+// configurable through Patroni.
+// - https://www.postgresql.org/docs/current/datatype-datetime.html#DATATYPE-TIMEZONES
+func SetTimeZone(cluster *v1beta1.PostgresCluster, pgParameters *Parameters) {
+	if tz := cluster.Spec.TimeZone; tz != "" {
+		pgParameters.Default.Add("timezone", tz)
+		pgParameters.Default.Add("log_timezone", tz)
+	}
+}
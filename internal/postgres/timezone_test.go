@@ -0,0 +1,38 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postgres
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestSetTimeZone(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	pgParameters := NewParameters()
+	SetTimeZone(cluster, &pgParameters)
+
+	assert.Assert(t, !pgParameters.Default.Has("timezone"))
+	assert.Assert(t, !pgParameters.Default.Has("log_timezone"))
+
+	cluster.Spec.TimeZone = "America/New_York"
+	SetTimeZone(cluster, &pgParameters)
+
+	assert.Equal(t, pgParameters.Default.Value("timezone"), "America/New_York")
+	assert.Equal(t, pgParameters.Default.Value("log_timezone"), "America/New_York")
+}
@@ -0,0 +1,321 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// AutoTuningEnabled returns whether or not PostgreSQL parameters of cluster
+// are derived from the resources of its instances.
+func AutoTuningEnabled(cluster *v1beta1.PostgresCluster) bool {
+	return cluster.Spec.Tuning != nil && cluster.Spec.Tuning.Mode == "Auto"
+}
+
+// CheckpointTuningEnabled returns whether or not checkpoint parameters of
+// cluster are derived from the rate at which its primary writes WAL.
+func CheckpointTuningEnabled(cluster *v1beta1.PostgresCluster) bool {
+	return cluster.Spec.Tuning != nil && cluster.Spec.Tuning.Checkpoints == "Observed"
+}
+
+// tuningResources returns the smallest memory in bytes, CPU in millicores,
+// and WAL storage in bytes of any instance set in cluster. Every instance
+// uses the same parameters, so they must fit the smallest one. Zero means
+// that at least one instance set does not say.
+func tuningResources(cluster *v1beta1.PostgresCluster) (memory, cpu, wal int64) {
+	smallest := func(current, value int64, first bool) int64 {
+		if first || value < current {
+			return value
+		}
+		return current
+	}
+	resource := func(resources corev1.ResourceRequirements, name corev1.ResourceName) int64 {
+		if quantity, ok := resources.Limits[name]; ok {
+			return quantity.MilliValue()
+		}
+		if quantity, ok := resources.Requests[name]; ok {
+			return quantity.MilliValue()
+		}
+		return 0
+	}
+
+	for i, set := range cluster.Spec.InstanceSets {
+		volume := set.DataVolumeClaimSpec
+		if set.WALVolumeClaimSpec != nil {
+			volume = *set.WALVolumeClaimSpec
+		}
+
+		memory = smallest(memory, resource(set.Resources, corev1.ResourceMemory)/1000, i == 0)
+		cpu = smallest(cpu, resource(set.Resources, corev1.ResourceCPU), i == 0)
+		wal = smallest(wal, volume.Resources.Requests.Storage().Value(), i == 0)
+	}
+	return
+}
+
+// greatest returns the larger of a and b.
+func greatest(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// least returns the smaller of a and b.
+func least(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// StorageMedia returns "SSD" or "HDD" for the disks that class provisions, or
+// an empty string when that cannot be told. An annotation on class takes
+// precedence over the parameters of well-known provisioners.
+// - https://kubernetes.io/docs/concepts/storage/storage-classes/#parameters
+func StorageMedia(class *storagev1.StorageClass) string {
+	switch media := strings.ToUpper(class.Annotations[naming.StorageMedia]); media {
+	case "SSD", "HDD":
+		return media
+	}
+
+	// AWS EBS, Google Persistent Disk, and Azure Disk name their kind of disk
+	// in one of these parameters.
+	// - https://github.com/kubernetes-sigs/aws-ebs-csi-driver/blob/master/docs/parameters.md
+	// - https://cloud.google.com/kubernetes-engine/docs/how-to/persistent-volumes/gce-pd-csi-driver
+	// - https://learn.microsoft.com/azure/aks/azure-disk-csi
+	var kind string
+	for key, value := range class.Parameters {
+		switch strings.ToLower(key) {
+		case "type", "skuname", "storageaccounttype":
+			kind = strings.ToLower(value)
+		}
+	}
+
+	switch {
+	case kind == "":
+		return ""
+	case kind == "st1" || kind == "sc1" || kind == "standard" ||
+		kind == "pd-standard" || strings.HasPrefix(kind, "standard_") && !strings.Contains(kind, "ssd"):
+		return "HDD"
+	case strings.HasPrefix(kind, "gp") || strings.HasPrefix(kind, "io") ||
+		strings.HasPrefix(kind, "pd-") || strings.HasPrefix(kind, "hyperdisk") ||
+		strings.HasPrefix(kind, "premium") || strings.Contains(kind, "ssd") ||
+		strings.HasPrefix(kind, "ultra"):
+		return "SSD"
+	}
+	return ""
+}
+
+// maxConnections returns the max_connections that PostgreSQL of cluster uses
+// with pgParameters: mandatory values win over spec.patroni.dynamicConfiguration,
+// which wins over defaults. PostgreSQL itself defaults to 100.
+func maxConnections(cluster *v1beta1.PostgresCluster, pgParameters *Parameters) int64 {
+	const name = "max_connections"
+	value := "100"
+
+	if pgParameters.Default != nil && pgParameters.Default.Has(name) {
+		value = pgParameters.Default.Value(name)
+	}
+	if cluster.Spec.Patroni != nil {
+		if section, ok := cluster.Spec.Patroni.DynamicConfiguration["postgresql"].(map[string]any); ok {
+			if parameters, ok := section["parameters"].(map[string]any); ok && parameters[name] != nil {
+				value = fmt.Sprint(parameters[name])
+			}
+		}
+	}
+	if pgParameters.Mandatory != nil && pgParameters.Mandatory.Has(name) {
+		value = pgParameters.Mandatory.Value(name)
+	}
+
+	var connections int64
+	if _, err := fmt.Sscan(value, &connections); err != nil || connections < 1 {
+		connections = 100
+	}
+	return connections
+}
+
+// SetAutoTuning populates default PostgreSQL parameters from the memory and
+// CPU of instances, the size of their volumes, and media, the kind of storage
+// returned by StorageMedia. The ratios are those commonly recommended for a
+// mixed workload.
+// - https://www.postgresql.org/docs/current/runtime-config-resource.html
+// - https://wiki.postgresql.org/wiki/Tuning_Your_PostgreSQL_Server
+func SetAutoTuning(cluster *v1beta1.PostgresCluster, media string, pgParameters *Parameters) {
+	if !AutoTuningEnabled(cluster) {
+		return
+	}
+
+	const kB, MB, GB = int64(1024), int64(1024 * 1024), int64(1024 * 1024 * 1024)
+	size := func(bytes int64) string { return fmt.Sprintf("%dkB", bytes/kB) }
+
+	memory, cpu, wal := tuningResources(cluster)
+	cores := cpu / 1000
+
+	// A workload profile decides how parallel each query is.
+	gather := int64(1)
+	if cores >= 4 {
+		gather = least(cores/2, 4)
+
+		pgParameters.Default.Add("max_worker_processes", fmt.Sprint(greatest(cores, 8)))
+		pgParameters.Default.Add("max_parallel_workers", fmt.Sprint(cores))
+		pgParameters.Default.Add("max_parallel_maintenance_workers", fmt.Sprint(gather))
+
+		if pgParameters.Default.Has("max_parallel_workers_per_gather") {
+			_, _ = fmt.Sscan(pgParameters.Default.Value("max_parallel_workers_per_gather"), &gather)
+			gather = greatest(gather, 1)
+		} else {
+			pgParameters.Default.Add("max_parallel_workers_per_gather", fmt.Sprint(gather))
+		}
+	}
+
+	if memory >= 256*MB {
+		connections := maxConnections(cluster, pgParameters)
+		buffers := memory / 4
+
+		pgParameters.Default.Add("shared_buffers", size(buffers))
+		pgParameters.Default.Add("effective_cache_size", size(memory*3/4))
+		pgParameters.Default.Add("maintenance_work_mem", size(least(memory/16, 2*GB)))
+		pgParameters.Default.Add("work_mem",
+			size(greatest((memory-buffers)/(connections*3)/gather, 64*kB)))
+	}
+
+	if wal > 0 {
+		maximum := least(greatest(wal/10, 1*GB), 16*GB)
+		pgParameters.Default.Add("max_wal_size", fmt.Sprintf("%dMB", maximum/MB))
+		pgParameters.Default.Add("min_wal_size", fmt.Sprintf("%dMB", maximum/4/MB))
+	}
+
+	// The kind of storage is more telling than any workload profile, but only
+	// when it is known.
+	switch {
+	case media == "HDD":
+		pgParameters.Default.Add("random_page_cost", "4")
+		pgParameters.Default.Add("effective_io_concurrency", "2")
+	case media == "SSD" || !pgParameters.Default.Has("random_page_cost"):
+		pgParameters.Default.Add("random_page_cost", "1.1")
+		pgParameters.Default.Add("effective_io_concurrency", "200")
+	default:
+		pgParameters.Default.Add("effective_io_concurrency", "200")
+	}
+}
+
+// TuneCheckpoints returns the max_wal_size and checkpoint_timeout for WAL
+// written at rate bytes per second. Checkpoints that start because WAL reached
+// max_wal_size write more at once than those that start on time, so max_wal_size
+// holds the WAL written between timed checkpoints when the WAL volume has room
+// for it. Otherwise, max_wal_size is a quarter of that volume and
+// checkpoint_timeout is shorter so that checkpoints are spread evenly.
+// - https://www.postgresql.org/docs/current/wal-configuration.html
+func TuneCheckpoints(cluster *v1beta1.PostgresCluster, rate int64) (maxWALSize, checkpointTimeout string) {
+	const MB, GB = int64(1024 * 1024), int64(1024 * 1024 * 1024)
+	const shortest, longest = int64(5 * 60), int64(15 * 60)
+
+	_, _, wal := tuningResources(cluster)
+	upper := 16 * GB
+	if wal > 0 {
+		upper = wal / 4
+	}
+	lower := least(1*GB, upper)
+
+	// A checkpoint starts when the WAL since the previous one reaches about
+	// max_wal_size / (1 + checkpoint_completion_target).
+	timeout := longest
+	if rate > 0 && rate*timeout*2 > upper {
+		timeout = least(greatest(upper/(rate*2), shortest), longest)
+	}
+	size := least(greatest(rate*timeout*2, lower), upper)
+
+	return fmt.Sprintf("%dMB", (size+MB-1)/MB), fmt.Sprintf("%dmin", timeout/60)
+}
+
+// SetCheckpointTuning populates default PostgreSQL parameters from the
+// checkpoint parameters in the status of cluster. Call it after SetAutoTuning,
+// which sizes WAL from volumes alone.
+func SetCheckpointTuning(cluster *v1beta1.PostgresCluster, pgParameters *Parameters) {
+	status := cluster.Status.CheckpointTuning
+	if !CheckpointTuningEnabled(cluster) || status == nil || status.MaxWALSize == "" {
+		return
+	}
+
+	var size int64
+	if _, err := fmt.Sscanf(status.MaxWALSize, "%dMB", &size); err == nil {
+		pgParameters.Default.Add("min_wal_size", fmt.Sprintf("%dMB", greatest(size/4, 32)))
+	}
+	pgParameters.Default.Add("max_wal_size", status.MaxWALSize)
+	pgParameters.Default.Add("checkpoint_timeout", status.CheckpointTimeout)
+	pgParameters.Default.Add("checkpoint_completion_target", "0.9")
+}
+
+// SetWorkloadProfile populates default PostgreSQL parameters for the kind of
+// queries that cluster runs. Call it before SetAutoTuning, which sizes the
+// settings that depend on resources.
+// - https://www.postgresql.org/docs/current/runtime-config-query.html
+// - https://www.postgresql.org/docs/current/runtime-config-autovacuum.html
+func SetWorkloadProfile(cluster *v1beta1.PostgresCluster, pgParameters *Parameters) {
+	var profile map[string]string
+
+	switch cluster.Spec.WorkloadProfile {
+	case "OLTP":
+		// Many short transactions that read few rows through indexes. Parallel
+		// plans cost more than they save, and tables change constantly.
+		profile = map[string]string{
+			"random_page_cost":                "1.1",
+			"max_parallel_workers_per_gather": "0",
+			"checkpoint_timeout":              "15min",
+			"checkpoint_completion_target":    "0.9",
+			"autovacuum_naptime":              "15s",
+			"autovacuum_vacuum_scale_factor":  "0.05",
+			"autovacuum_analyze_scale_factor": "0.02",
+			"autovacuum_vacuum_cost_limit":    "2000",
+			"default_statistics_target":       "100",
+		}
+	case "OLAP":
+		// Few large queries that scan and aggregate many rows. They benefit
+		// from parallel plans and detailed statistics; tables change in bulk.
+		profile = map[string]string{
+			"random_page_cost":                "2",
+			"max_parallel_workers_per_gather": "4",
+			"checkpoint_timeout":              "30min",
+			"checkpoint_completion_target":    "0.9",
+			"autovacuum_vacuum_scale_factor":  "0.1",
+			"autovacuum_analyze_scale_factor": "0.05",
+			"default_statistics_target":       "500",
+			"jit":                             "on",
+		}
+	case "Mixed":
+		profile = map[string]string{
+			"random_page_cost":                "1.5",
+			"max_parallel_workers_per_gather": "2",
+			"checkpoint_timeout":              "15min",
+			"checkpoint_completion_target":    "0.9",
+			"autovacuum_vacuum_scale_factor":  "0.1",
+			"autovacuum_analyze_scale_factor": "0.05",
+			"autovacuum_vacuum_cost_limit":    "1000",
+			"default_statistics_target":       "200",
+		}
+	}
+
+	for name, value := range profile {
+		pgParameters.Default.Add(name, value)
+	}
+}
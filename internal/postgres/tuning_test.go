@@ -0,0 +1,256 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postgres
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestSetAutoTuning(t *testing.T) {
+	instance := func(memory, cpu, storage string) v1beta1.PostgresInstanceSetSpec {
+		set := v1beta1.PostgresInstanceSetSpec{}
+		set.Resources.Limits = corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse(memory),
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+		}
+		set.DataVolumeClaimSpec.Resources.Requests = corev1.ResourceList{
+			corev1.ResourceStorage: resource.MustParse(storage),
+		}
+		return set
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{instance("4Gi", "2", "100Gi")}
+
+		parameters := NewParameters()
+		SetAutoTuning(cluster, "", &parameters)
+		assert.Assert(t, !parameters.Default.Has("shared_buffers"))
+
+		cluster.Spec.Tuning = &v1beta1.TuningSpec{Mode: "Manual"}
+		SetAutoTuning(cluster, "", &parameters)
+		assert.Assert(t, !parameters.Default.Has("shared_buffers"))
+	})
+
+	t.Run("Auto", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.Tuning = &v1beta1.TuningSpec{Mode: "Auto"}
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{
+			instance("8Gi", "8", "200Gi"),
+			instance("4Gi", "4", "100Gi"),
+		}
+
+		parameters := NewParameters()
+		SetAutoTuning(cluster, "", &parameters)
+
+		// The smallest instance set applies.
+		assert.Equal(t, parameters.Default.Value("shared_buffers"), "1048576kB")
+		assert.Equal(t, parameters.Default.Value("effective_cache_size"), "3145728kB")
+		assert.Equal(t, parameters.Default.Value("maintenance_work_mem"), "262144kB")
+		assert.Equal(t, parameters.Default.Value("work_mem"), "5242kB")
+		assert.Equal(t, parameters.Default.Value("max_worker_processes"), "8")
+		assert.Equal(t, parameters.Default.Value("max_parallel_workers"), "4")
+		assert.Equal(t, parameters.Default.Value("max_parallel_workers_per_gather"), "2")
+		assert.Equal(t, parameters.Default.Value("max_wal_size"), "10240MB")
+		assert.Equal(t, parameters.Default.Value("min_wal_size"), "2560MB")
+		assert.Equal(t, parameters.Default.Value("random_page_cost"), "1.1")
+	})
+
+	t.Run("SmallAndSlow", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.Tuning = &v1beta1.TuningSpec{Mode: "Auto"}
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{instance("128Mi", "500m", "1Gi")}
+
+		parameters := NewParameters()
+		SetAutoTuning(cluster, "HDD", &parameters)
+
+		assert.Assert(t, !parameters.Default.Has("shared_buffers"))
+		assert.Assert(t, !parameters.Default.Has("max_parallel_workers"))
+		assert.Equal(t, parameters.Default.Value("max_wal_size"), "1024MB")
+		assert.Equal(t, parameters.Default.Value("random_page_cost"), "4")
+		assert.Equal(t, parameters.Default.Value("effective_io_concurrency"), "2")
+	})
+
+	t.Run("MaxConnections", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.Tuning = &v1beta1.TuningSpec{Mode: "Auto"}
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{instance("4Gi", "4", "100Gi")}
+		cluster.Spec.Patroni = &v1beta1.PatroniSpec{
+			DynamicConfiguration: map[string]any{
+				"postgresql": map[string]any{
+					"parameters": map[string]any{"max_connections": int64(200)},
+				},
+			},
+		}
+
+		parameters := NewParameters()
+		SetAutoTuning(cluster, "", &parameters)
+		assert.Equal(t, parameters.Default.Value("work_mem"), "2621kB")
+
+		// Mandatory values take precedence.
+		parameters = NewParameters()
+		parameters.Mandatory.Add("max_connections", "50")
+		SetAutoTuning(cluster, "", &parameters)
+		assert.Equal(t, parameters.Default.Value("work_mem"), "10485kB")
+	})
+}
+
+func TestTuneCheckpoints(t *testing.T) {
+	const MiB = int64(1024 * 1024)
+
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{}}
+	cluster.Spec.InstanceSets[0].DataVolumeClaimSpec.Resources.Requests = corev1.ResourceList{
+		corev1.ResourceStorage: resource.MustParse("100Gi"),
+	}
+
+	for _, tt := range []struct {
+		rate          int64
+		size, timeout string
+	}{
+		{rate: 0, size: "1024MB", timeout: "15min"},
+		{rate: 1 * MiB, size: "1800MB", timeout: "15min"},
+
+		// A quarter of the volume is not enough for 15 minutes of WAL.
+		{rate: 20 * MiB, size: "25600MB", timeout: "10min"},
+		{rate: 100 * MiB, size: "25600MB", timeout: "5min"},
+	} {
+		size, timeout := TuneCheckpoints(cluster, tt.rate)
+		assert.Equal(t, size, tt.size, "rate %d", tt.rate)
+		assert.Equal(t, timeout, tt.timeout, "rate %d", tt.rate)
+	}
+
+	t.Run("SmallVolume", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.InstanceSets[0].WALVolumeClaimSpec = &corev1.PersistentVolumeClaimSpec{}
+		cluster.Spec.InstanceSets[0].WALVolumeClaimSpec.Resources.Requests = corev1.ResourceList{
+			corev1.ResourceStorage: resource.MustParse("2Gi"),
+		}
+
+		size, timeout := TuneCheckpoints(cluster, 0)
+		assert.Equal(t, size, "512MB")
+		assert.Equal(t, timeout, "15min")
+	})
+
+	t.Run("Parameters", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Status.CheckpointTuning = &v1beta1.CheckpointTuningStatus{
+			MaxWALSize: "1800MB", CheckpointTimeout: "15min",
+		}
+
+		parameters := NewParameters()
+		SetCheckpointTuning(cluster, &parameters)
+		assert.Assert(t, !parameters.Default.Has("max_wal_size"))
+
+		cluster.Spec.Tuning = &v1beta1.TuningSpec{Mode: "Manual", Checkpoints: "Observed"}
+		SetCheckpointTuning(cluster, &parameters)
+		assert.Equal(t, parameters.Default.Value("max_wal_size"), "1800MB")
+		assert.Equal(t, parameters.Default.Value("min_wal_size"), "450MB")
+		assert.Equal(t, parameters.Default.Value("checkpoint_timeout"), "15min")
+	})
+}
+
+func TestStorageMedia(t *testing.T) {
+	class := func(annotations, parameters map[string]string) *storagev1.StorageClass {
+		c := new(storagev1.StorageClass)
+		c.Annotations = annotations
+		c.Parameters = parameters
+		return c
+	}
+
+	assert.Equal(t, StorageMedia(class(nil, nil)), "")
+	assert.Equal(t, StorageMedia(class(nil, map[string]string{"fstype": "ext4"})), "")
+
+	for value, expected := range map[string]string{
+		"gp3": "SSD", "io2": "SSD", "st1": "HDD", "sc1": "HDD", "standard": "HDD",
+		"pd-ssd": "SSD", "pd-balanced": "SSD", "pd-standard": "HDD",
+	} {
+		assert.Equal(t, StorageMedia(class(nil, map[string]string{"type": value})), expected, value)
+	}
+	for value, expected := range map[string]string{
+		"Premium_LRS": "SSD", "StandardSSD_ZRS": "SSD", "Standard_LRS": "HDD",
+	} {
+		assert.Equal(t, StorageMedia(class(nil, map[string]string{"skuName": value})), expected, value)
+	}
+
+	// The annotation takes precedence.
+	assert.Equal(t, StorageMedia(class(
+		map[string]string{"postgres-operator.crunchydata.com/storage-media": "hdd"},
+		map[string]string{"type": "gp3"})), "HDD")
+}
+
+func TestSetWorkloadProfile(t *testing.T) {
+	t.Run("None", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+
+		parameters := NewParameters()
+		SetWorkloadProfile(cluster, &parameters)
+		assert.Assert(t, !parameters.Default.Has("random_page_cost"))
+	})
+
+	t.Run("OLTP", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.WorkloadProfile = "OLTP"
+
+		parameters := NewParameters()
+		SetWorkloadProfile(cluster, &parameters)
+		assert.Equal(t, parameters.Default.Value("max_parallel_workers_per_gather"), "0")
+		assert.Equal(t, parameters.Default.Value("autovacuum_vacuum_scale_factor"), "0.05")
+	})
+
+	t.Run("OLAP", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.WorkloadProfile = "OLAP"
+
+		parameters := NewParameters()
+		SetWorkloadProfile(cluster, &parameters)
+		assert.Equal(t, parameters.Default.Value("max_parallel_workers_per_gather"), "4")
+		assert.Equal(t, parameters.Default.Value("default_statistics_target"), "500")
+	})
+
+	t.Run("WithAutoTuning", func(t *testing.T) {
+		cluster := new(v1beta1.PostgresCluster)
+		cluster.Spec.WorkloadProfile = "OLTP"
+		cluster.Spec.Tuning = &v1beta1.TuningSpec{Mode: "Auto"}
+		cluster.Spec.InstanceSets = []v1beta1.PostgresInstanceSetSpec{{}}
+		cluster.Spec.InstanceSets[0].Resources.Limits = corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("8"),
+		}
+
+		parameters := NewParameters()
+		SetWorkloadProfile(cluster, &parameters)
+		SetAutoTuning(cluster, "", &parameters)
+
+		// The profile decides parallelism and planner costs.
+		assert.Equal(t, parameters.Default.Value("max_parallel_workers_per_gather"), "0")
+		assert.Equal(t, parameters.Default.Value("max_parallel_workers"), "8")
+		assert.Equal(t, parameters.Default.Value("random_page_cost"), "1.1")
+
+		// Known storage takes precedence.
+		cluster.Spec.WorkloadProfile = "OLAP"
+		parameters = NewParameters()
+		SetWorkloadProfile(cluster, &parameters)
+		SetAutoTuning(cluster, "HDD", &parameters)
+		assert.Equal(t, parameters.Default.Value("random_page_cost"), "4")
+	})
+}
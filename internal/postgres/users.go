@@ -24,6 +24,18 @@ import (
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
+// UserCertificateHBAs provides the HBA rules that require users with
+// certificate authentication to connect over TLS with their certificate.
+// - https://www.postgresql.org/docs/current/auth-cert.html
+func UserCertificateHBAs(cluster *v1beta1.PostgresCluster, outHBAs *HBAs) {
+	for _, user := range cluster.Spec.Users {
+		if user.CertAuth {
+			outHBAs.Mandatory = append(outHBAs.Mandatory,
+				*NewHBA().TLS().User(string(user.Name)).Method("cert"))
+		}
+	}
+}
+
 // WriteUsersInPostgreSQL calls exec to create users that do not exist in
 // PostgreSQL. Once they exist, it updates their options and passwords and
 // grants them access to their specified databases. The databases must already
@@ -28,6 +28,19 @@ import (
 	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
 )
 
+func TestUserCertificateHBAs(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Spec.Users = []v1beta1.PostgresUserSpec{
+		{Name: "app"},
+		{Name: "reports", CertAuth: true},
+	}
+
+	hbas := HBAs{}
+	UserCertificateHBAs(cluster, &hbas)
+	assert.Equal(t, len(hbas.Mandatory), 1)
+	assert.Equal(t, hbas.Mandatory[0].String(), `hostssl all "reports" all cert`)
+}
+
 func TestWriteUsersInPostgreSQL(t *testing.T) {
 	ctx := context.Background()
 
@@ -0,0 +1,135 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postupgrade
+
+import (
+	"context"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/internal/logging"
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	// User is the PostgreSQL role that PGUpgrade Jobs use to check catalogs,
+	// rebuild statistics, and update extensions. The latter require ownership
+	// of every table and extension, so User is a superuser. It authenticates
+	// with a client certificate and exists only while an upgrade needs it.
+	User = "_crunchyupgrade"
+
+	// CertFile, KeyFile, and CAFile are the Secret keys of the client
+	// certificate, its private key, and the certificate authority of User.
+	CertFile = "tls.crt"
+	KeyFile  = "tls.key"
+	CAFile   = "ca.crt"
+)
+
+// Enabled returns whether or not User should exist in cluster. It exists only
+// while the cluster is annotated to allow an upgrade and that upgrade has not
+// reported that it is done with User.
+func Enabled(cluster *v1beta1.PostgresCluster) bool {
+	upgrade := cluster.GetAnnotations()[naming.AllowUpgrade]
+	status := cluster.Status.UpgradeUser
+
+	return upgrade != "" && (status == nil || status.Completed != upgrade)
+}
+
+// PostgreSQLHBAs provides the HBA rules that allow PGUpgrade Jobs to connect.
+func PostgreSQLHBAs(cluster *v1beta1.PostgresCluster, outHBAs *postgres.HBAs) {
+	if Enabled(cluster) {
+		// Only certificate authentication over TLS is allowed for this user.
+		outHBAs.Mandatory = append(outHBAs.Mandatory,
+			*postgres.NewHBA().TLS().User(User).Method("cert"),
+			*postgres.NewHBA().TCP().User(User).Method("reject"),
+		)
+	}
+}
+
+// DisableInPostgreSQL removes the upgrade user. Any objects it created, such as
+// those of an updated extension, are given to the "postgres" superuser first.
+func DisableInPostgreSQL(ctx context.Context, exec postgres.Executor) error {
+	log := logging.FromContext(ctx)
+
+	stdout, stderr, err := exec.ExecInAllDatabases(ctx,
+		strings.Join([]string{
+			// Quiet NOTICE messages from IF EXISTS statements.
+			// - https://www.postgresql.org/docs/current/runtime-config-client.html
+			`SET client_min_messages = WARNING;`,
+
+			strings.TrimSpace(`
+SELECT pg_catalog.format('REASSIGN OWNED BY %I TO postgres', :'username'),
+       pg_catalog.format('DROP OWNED BY %I CASCADE', :'username')
+ WHERE EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = :'username')
+\gexec`),
+		}, "\n"),
+		map[string]string{
+			"username": User,
+
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("reassigned upgrade objects", "stdout", stdout, "stderr", stderr)
+
+	if err == nil {
+		stdout, stderr, err = exec.ExecInDatabasesFromQuery(ctx,
+			`SELECT pg_catalog.current_database()`,
+			`SET client_min_messages = WARNING; DROP ROLE IF EXISTS :"username";`,
+			map[string]string{
+				"username": User,
+
+				"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+				"QUIET":         "on", // Do not print successful statements to stdout.
+			})
+
+		log.V(1).Info("removed upgrade user", "stdout", stdout, "stderr", stderr)
+	}
+
+	return err
+}
+
+// EnableInPostgreSQL creates the upgrade user.
+func EnableInPostgreSQL(ctx context.Context, exec postgres.Executor) error {
+	log := logging.FromContext(ctx)
+
+	stdout, stderr, err := exec.ExecInDatabasesFromQuery(ctx,
+		`SELECT pg_catalog.current_database()`,
+		strings.Join([]string{
+			// Quiet NOTICE messages from IF NOT EXISTS statements.
+			// - https://www.postgresql.org/docs/current/runtime-config-client.html
+			`SET client_min_messages = WARNING;`,
+
+			strings.TrimSpace(`
+SELECT pg_catalog.format('CREATE ROLE %I NOLOGIN', :'username')
+ WHERE NOT EXISTS (SELECT 1 FROM pg_catalog.pg_roles WHERE rolname = :'username')
+\gexec`),
+
+			// The user authenticates with a certificate, never a password.
+			`ALTER ROLE :"username" SUPERUSER LOGIN PASSWORD NULL;`,
+		}, "\n"),
+		map[string]string{
+			"username": User,
+
+			"ON_ERROR_STOP": "on", // Abort when any one statement fails.
+			"QUIET":         "on", // Do not print successful statements to stdout.
+		})
+
+	log.V(1).Info("enabled upgrade user", "stdout", stdout, "stderr", stderr)
+
+	return err
+}
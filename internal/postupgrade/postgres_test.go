@@ -0,0 +1,105 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package postupgrade
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/naming"
+	"github.com/crunchydata/postgres-operator/internal/postgres"
+	"github.com/crunchydata/postgres-operator/internal/testing/cmp"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestEnabled(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	assert.Assert(t, !Enabled(cluster))
+
+	cluster.Annotations = map[string]string{naming.AllowUpgrade: ""}
+	assert.Assert(t, !Enabled(cluster), "expected the name of an upgrade")
+
+	cluster.Annotations[naming.AllowUpgrade] = "to-sixteen"
+	assert.Assert(t, Enabled(cluster))
+
+	cluster.Status.UpgradeUser = &v1beta1.UpgradeUserStatus{Completed: "to-sixteen"}
+	assert.Assert(t, !Enabled(cluster), "expected the upgrade to be done")
+
+	cluster.Annotations[naming.AllowUpgrade] = "to-seventeen"
+	assert.Assert(t, Enabled(cluster))
+}
+
+func TestPostgreSQLHBAs(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+
+	hbas := postgres.HBAs{}
+	PostgreSQLHBAs(cluster, &hbas)
+	assert.Equal(t, len(hbas.Mandatory), 0)
+
+	cluster.Annotations = map[string]string{naming.AllowUpgrade: "to-sixteen"}
+	PostgreSQLHBAs(cluster, &hbas)
+	assert.Equal(t, len(hbas.Mandatory), 2)
+	assert.Equal(t, hbas.Mandatory[0].String(), `hostssl all "_crunchyupgrade" all cert`)
+	assert.Equal(t, hbas.Mandatory[1].String(), `host all "_crunchyupgrade" all reject`)
+}
+
+func TestDisableInPostgreSQL(t *testing.T) {
+	expected := errors.New("whoops")
+	exec := func(
+		_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		assert.Assert(t, stdout != nil, "should capture stdout")
+		assert.Assert(t, stderr != nil, "should capture stderr")
+		assert.Assert(t, strings.Contains(strings.Join(command, "\n"),
+			`--set=username=_crunchyupgrade`))
+
+		b, err := io.ReadAll(stdin)
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(string(b), `REASSIGN OWNED BY %I TO postgres`))
+		assert.Assert(t, cmp.Contains(string(b), `DROP OWNED BY %I CASCADE`))
+
+		return expected
+	}
+
+	ctx := context.Background()
+	assert.Equal(t, expected, DisableInPostgreSQL(ctx, exec))
+}
+
+func TestEnableInPostgreSQL(t *testing.T) {
+	expected := errors.New("whoops")
+	exec := func(
+		_ context.Context, stdin io.Reader, stdout, stderr io.Writer, command ...string,
+	) error {
+		assert.Assert(t, strings.Contains(strings.Join(command, "\n"),
+			`SELECT pg_catalog.current_database()`,
+		), "expected only one database")
+
+		b, err := io.ReadAll(stdin)
+		assert.NilError(t, err)
+		assert.Assert(t, cmp.Contains(string(b), `CREATE ROLE %I NOLOGIN`))
+		assert.Assert(t, cmp.Contains(string(b), `ALTER ROLE :"username" SUPERUSER LOGIN PASSWORD NULL;`))
+
+		return expected
+	}
+
+	ctx := context.Background()
+	assert.Equal(t, expected, EnableInPostgreSQL(ctx, exec))
+}
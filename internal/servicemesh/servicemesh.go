@@ -0,0 +1,161 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+// Package servicemesh annotates the Pods of a PostgresCluster for the proxies
+// that a service mesh, such as Istio or Linkerd, injects into them.
+package servicemesh
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/crunchydata/postgres-operator/internal/pgbackrest"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+const (
+	Istio   = "Istio"
+	Linkerd = "Linkerd"
+)
+
+// NativeSidecars is whether Kubernetes runs native sidecar containers, which
+// it does by default since 1.29. The operator sets this when it starts.
+// - https://kubernetes.io/docs/concepts/workloads/pods/sidecar-containers/
+var NativeSidecars = false
+
+// PodAnnotations returns the annotations for the proxy that a service mesh
+// injects into a long-running Pod of cluster. It returns nil when cluster has
+// no service mesh.
+//
+// PostgreSQL, Patroni, pgBackRest, and PgBouncer encrypt and authenticate
+// their own connections, and replicas and pgBackRest connect to the IP of
+// each Pod. Their ports bypass the proxy in both directions so that the mesh
+// neither intercepts nor rejects these connections. Containers start after
+// the proxy so that those which call the Kubernetes API can reach it.
+func PodAnnotations(cluster *v1beta1.PostgresCluster) map[string]string {
+	if cluster.Spec.ServiceMesh == nil {
+		return nil
+	}
+
+	var ports []string
+	if cluster.Spec.Port != nil {
+		ports = append(ports, fmt.Sprint(*cluster.Spec.Port))
+	}
+	if cluster.Spec.Patroni != nil && cluster.Spec.Patroni.Port != nil {
+		ports = append(ports, fmt.Sprint(*cluster.Spec.Patroni.Port))
+	}
+	ports = append(ports, fmt.Sprint(pgbackrest.IANAPortNumber))
+	if cluster.Spec.Proxy != nil && cluster.Spec.Proxy.PGBouncer != nil &&
+		cluster.Spec.Proxy.PGBouncer.Port != nil {
+		ports = append(ports, fmt.Sprint(*cluster.Spec.Proxy.PGBouncer.Port))
+	}
+	skip := strings.Join(ports, ",")
+
+	switch cluster.Spec.ServiceMesh.Type {
+	case Istio:
+		// - https://istio.io/latest/docs/reference/config/annotations/
+		return map[string]string{
+			"proxy.istio.io/config":                         `{"holdApplicationUntilProxyStarts":true}`,
+			"traffic.sidecar.istio.io/excludeInboundPorts":  skip,
+			"traffic.sidecar.istio.io/excludeOutboundPorts": skip,
+		}
+	case Linkerd:
+		// - https://linkerd.io/2/reference/proxy-configuration/
+		return map[string]string{
+			"config.linkerd.io/proxy-await":         "enabled",
+			"config.linkerd.io/skip-inbound-ports":  skip,
+			"config.linkerd.io/skip-outbound-ports": skip,
+		}
+	}
+	return nil
+}
+
+// JobAnnotations returns the annotations for the proxy that a service mesh
+// injects into the Pod of a Job of cluster that connects to other services,
+// such as PostgreSQL or object storage. It returns nil when cluster has no
+// service mesh.
+//
+// These are the PodAnnotations and those that inject the proxy as a native
+// sidecar container. Kubernetes stops a native sidecar once the containers of
+// the Job exit, so the Job completes rather than waiting on the proxy forever.
+// Without NativeSidecars, the proxy is stopped by the command of each container
+// instead; see JobCommand.
+func JobAnnotations(cluster *v1beta1.PostgresCluster) map[string]string {
+	annotations := PodAnnotations(cluster)
+	if annotations == nil {
+		return nil
+	}
+
+	switch {
+	case cluster.Spec.ServiceMesh.Type == Istio && NativeSidecars:
+		annotations["sidecar.istio.io/nativeSidecar"] = "true"
+	case cluster.Spec.ServiceMesh.Type == Linkerd && NativeSidecars:
+		annotations["config.alpha.linkerd.io/proxy-enable-native-sidecar"] = "true"
+	case cluster.Spec.ServiceMesh.Type == Linkerd:
+		// - https://linkerd.io/2/tasks/graceful-shutdown/
+		annotations["config.linkerd.io/proxy-admin-shutdown"] = "enabled"
+	}
+	return annotations
+}
+
+// JobCommand returns command wrapped so that it stops the proxy that a service
+// mesh injects into the Pod of a Job of cluster once it exits. The exit code
+// of command is kept. It returns command unchanged when cluster has no service
+// mesh or when the proxy is a native sidecar, which Kubernetes stops.
+//
+// These are the endpoints that "pilot-agent request POST quitquitquit" and
+// "linkerd-await --shutdown" call, which are not in the images of the Job.
+// - https://istio.io/latest/docs/reference/commands/pilot-agent/
+// - https://github.com/linkerd/linkerd-await
+func JobCommand(cluster *v1beta1.PostgresCluster, command []string) []string {
+	if cluster.Spec.ServiceMesh == nil || NativeSidecars || len(command) == 0 {
+		return command
+	}
+
+	var endpoint string
+	switch cluster.Spec.ServiceMesh.Type {
+	case Istio:
+		endpoint = "http://127.0.0.1:15020/quitquitquit"
+	case Linkerd:
+		endpoint = "http://127.0.0.1:4191/shutdown"
+	default:
+		return command
+	}
+
+	script := `"$@"; rc=$?; ` +
+		`curl --silent --show-error --request POST ` + endpoint + ` || true; ` +
+		`exit "${rc}"`
+
+	return append([]string{"sh", "-c", script, "--"}, command...)
+}
+
+// NoProxyAnnotations returns the annotations that keep a service mesh from
+// injecting a proxy into the Pod of a Job of cluster that needs no network or
+// only calls the Kubernetes API, such as a backup. A proxy would keep running
+// after the Job finishes so that the Job never completes. It returns nil when
+// cluster has no service mesh.
+func NoProxyAnnotations(cluster *v1beta1.PostgresCluster) map[string]string {
+	if cluster.Spec.ServiceMesh == nil {
+		return nil
+	}
+
+	switch cluster.Spec.ServiceMesh.Type {
+	case Istio:
+		return map[string]string{"sidecar.istio.io/inject": "false"}
+	case Linkerd:
+		return map[string]string{"linkerd.io/inject": "disabled"}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package servicemesh
+
+import (
+	"testing"
+
+	"gotest.tools/v3/assert"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+func TestAnnotations(t *testing.T) {
+	cluster := new(v1beta1.PostgresCluster)
+	cluster.Default()
+	assert.Assert(t, PodAnnotations(cluster) == nil)
+	assert.Assert(t, JobAnnotations(cluster) == nil)
+	assert.Assert(t, NoProxyAnnotations(cluster) == nil)
+	assert.DeepEqual(t, JobCommand(cluster, []string{"true"}), []string{"true"})
+
+	t.Run("Istio", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.ServiceMesh = &v1beta1.ServiceMeshSpec{Type: "Istio"}
+
+		assert.DeepEqual(t, PodAnnotations(cluster), map[string]string{
+			"proxy.istio.io/config":                         `{"holdApplicationUntilProxyStarts":true}`,
+			"traffic.sidecar.istio.io/excludeInboundPorts":  "5432,8008,8432",
+			"traffic.sidecar.istio.io/excludeOutboundPorts": "5432,8008,8432",
+		})
+		assert.DeepEqual(t, NoProxyAnnotations(cluster), map[string]string{
+			"sidecar.istio.io/inject": "false",
+		})
+
+		assert.DeepEqual(t, JobAnnotations(cluster), PodAnnotations(cluster))
+		assert.DeepEqual(t, JobCommand(cluster, []string{"pgbackrest", "restore"}), []string{
+			"sh", "-c", `"$@"; rc=$?; curl --silent --show-error --request POST http://127.0.0.1:15020/quitquitquit || true; exit "${rc}"`,
+			"--", "pgbackrest", "restore",
+		})
+
+		t.Run("NativeSidecars", func(t *testing.T) {
+			NativeSidecars = true
+			t.Cleanup(func() { NativeSidecars = false })
+
+			job := JobAnnotations(cluster)
+			assert.Equal(t, job["sidecar.istio.io/nativeSidecar"], "true")
+			assert.Equal(t, job["traffic.sidecar.istio.io/excludeOutboundPorts"], "5432,8008,8432")
+			assert.DeepEqual(t, JobCommand(cluster, []string{"true"}), []string{"true"})
+		})
+	})
+
+	t.Run("Linkerd", func(t *testing.T) {
+		cluster := cluster.DeepCopy()
+		cluster.Spec.ServiceMesh = &v1beta1.ServiceMeshSpec{Type: "Linkerd"}
+		cluster.Spec.Port = initialize.Int32(5555)
+		cluster.Spec.Proxy = &v1beta1.PostgresProxySpec{
+			PGBouncer: &v1beta1.PGBouncerPodSpec{Port: initialize.Int32(6432)},
+		}
+
+		assert.DeepEqual(t, PodAnnotations(cluster), map[string]string{
+			"config.linkerd.io/proxy-await":         "enabled",
+			"config.linkerd.io/skip-inbound-ports":  "5555,8008,8432,6432",
+			"config.linkerd.io/skip-outbound-ports": "5555,8008,8432,6432",
+		})
+		assert.DeepEqual(t, NoProxyAnnotations(cluster), map[string]string{
+			"linkerd.io/inject": "disabled",
+		})
+
+		job := JobAnnotations(cluster)
+		assert.Equal(t, job["config.linkerd.io/proxy-admin-shutdown"], "enabled")
+		assert.Equal(t, job["config.linkerd.io/proxy-await"], "enabled")
+		assert.DeepEqual(t, JobCommand(cluster, []string{"psql", "-c", "SELECT 1"}), []string{
+			"sh", "-c", `"$@"; rc=$?; curl --silent --show-error --request POST http://127.0.0.1:4191/shutdown || true; exit "${rc}"`,
+			"--", "psql", "-c", "SELECT 1",
+		})
+
+		t.Run("NativeSidecars", func(t *testing.T) {
+			NativeSidecars = true
+			t.Cleanup(func() { NativeSidecars = false })
+
+			job := JobAnnotations(cluster)
+			assert.Equal(t, job["config.alpha.linkerd.io/proxy-enable-native-sidecar"], "true")
+			assert.Equal(t, job["config.linkerd.io/proxy-admin-shutdown"], "")
+			assert.DeepEqual(t, JobCommand(cluster, []string{"true"}), []string{"true"})
+		})
+	})
+}
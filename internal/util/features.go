@@ -17,6 +17,7 @@ package util
 
 import (
 	"fmt"
+	"sort"
 
 	"k8s.io/component-base/featuregate"
 )
@@ -40,6 +41,12 @@ const (
 	// Enables Kubernetes-native way to manage Crunchy Bridge managed Postgresclusters
 	CrunchyBridgeClusters featuregate.Feature = "CrunchyBridgeClusters"
 	//
+	// Enables an agent in PostgreSQL instance Pods that runs commands for the operator
+	ExecAgent featuregate.Feature = "ExecAgent"
+	//
+	// Enables annotations that inject faults into PostgresClusters for testing
+	FaultInjection featuregate.Feature = "FaultInjection"
+	//
 	// Enables support of custom sidecars for PostgreSQL instance Pods
 	InstanceSidecars featuregate.Feature = "InstanceSidecars"
 	//
@@ -61,11 +68,22 @@ var pgoFeatures = map[featuregate.Feature]featuregate.FeatureSpec{
 	AppendCustomQueries:   {Default: false, PreRelease: featuregate.Alpha},
 	BridgeIdentifiers:     {Default: false, PreRelease: featuregate.Alpha},
 	CrunchyBridgeClusters: {Default: false, PreRelease: featuregate.Alpha},
+	ExecAgent:             {Default: false, PreRelease: featuregate.Alpha},
+	FaultInjection:        {Default: false, PreRelease: featuregate.Alpha},
 	InstanceSidecars:      {Default: false, PreRelease: featuregate.Alpha},
 	PGBouncerSidecars:     {Default: false, PreRelease: featuregate.Alpha},
 	TablespaceVolumes:     {Default: false, PreRelease: featuregate.Alpha},
 }
 
+// clusterFeatures are the features that can be enabled or disabled for each
+// PostgresCluster. Their names are listed in the description of its spec.
+var clusterFeatures = map[featuregate.Feature]bool{
+	AppendCustomQueries: true,
+	InstanceSidecars:    true,
+	PGBouncerSidecars:   true,
+	TablespaceVolumes:   true,
+}
+
 // DefaultMutableFeatureGate is a mutable, shared global FeatureGate.
 // It is used to indicate whether a given feature is enabled or not.
 //
@@ -94,3 +112,26 @@ func AddAndSetFeatureGates(features string) error {
 	}
 	return nil
 }
+
+// FeatureEnabled returns whether feature is enabled for an object that sets
+// overrides. Overrides apply only to features that can be set per cluster;
+// the operator's feature gates decide the rest.
+func FeatureEnabled(overrides map[string]bool, feature featuregate.Feature) bool {
+	if enabled, ok := overrides[string(feature)]; ok && clusterFeatures[feature] {
+		return enabled
+	}
+	return DefaultMutableFeatureGate.Enabled(feature)
+}
+
+// EnabledFeatures returns the sorted names of the PGO features enabled for an
+// object that sets overrides.
+func EnabledFeatures(overrides map[string]bool) []string {
+	var names []string
+	for feature := range pgoFeatures {
+		if FeatureEnabled(overrides, feature) {
+			names = append(names, string(feature))
+		}
+	}
+	sort.Strings(names)
+	return names
+}
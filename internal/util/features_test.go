@@ -75,3 +75,31 @@ func TestAddAndSetFeatureGates(t *testing.T) {
 		assert.ErrorContains(t, err, "invalid value of GateNotSet=foo, err: strconv.ParseBool")
 	})
 }
+
+func TestFeatureEnabled(t *testing.T) {
+	pgoFeatures = map[featuregate.Feature]featuregate.FeatureSpec{
+		ExecAgent:         {Default: false, PreRelease: featuregate.Alpha},
+		InstanceSidecars:  {Default: false, PreRelease: featuregate.Alpha},
+		TablespaceVolumes: {Default: false, PreRelease: featuregate.Alpha},
+	}
+	assert.NilError(t, AddAndSetFeatureGates("InstanceSidecars=true"))
+	t.Cleanup(func() {
+		assert.NilError(t, AddAndSetFeatureGates("InstanceSidecars=false"))
+	})
+
+	assert.Assert(t, FeatureEnabled(nil, InstanceSidecars))
+	assert.Assert(t, !FeatureEnabled(nil, TablespaceVolumes))
+	assert.DeepEqual(t, EnabledFeatures(nil), []string{"InstanceSidecars"})
+
+	overrides := map[string]bool{
+		"ExecAgent":         true,
+		"InstanceSidecars":  false,
+		"TablespaceVolumes": true,
+	}
+
+	assert.Assert(t, !FeatureEnabled(overrides, InstanceSidecars))
+	assert.Assert(t, FeatureEnabled(overrides, TablespaceVolumes))
+	assert.Assert(t, !FeatureEnabled(overrides, ExecAgent),
+		"expected only cluster features to be overridden")
+	assert.DeepEqual(t, EnabledFeatures(overrides), []string{"TablespaceVolumes"})
+}
@@ -0,0 +1,107 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+
+	"sigs.k8s.io/controller-runtime/pkg/conversion"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// RemovedFieldsAnnotation holds the fields of a v1beta1 PostgresCluster that
+// are not in v1, so converting to v1 and back does not lose them.
+const RemovedFieldsAnnotation = "postgres-operator.crunchydata.com/v1beta1-removed-fields"
+
+// removedFields are the fields of v1beta1.PostgresCluster that are not in v1.
+type removedFields struct {
+	Spec struct {
+		UserInterface *v1beta1.UserInterfaceSpec `json:"userInterface,omitempty"`
+	} `json:"spec,omitempty"`
+	Status struct {
+		UserInterface *v1beta1.PostgresUserInterfaceStatus `json:"userInterface,omitempty"`
+	} `json:"status,omitempty"`
+}
+
+var _ conversion.Convertible = (*PostgresCluster)(nil)
+
+// ConvertTo converts src to the Hub version, v1beta1.
+func (src *PostgresCluster) ConvertTo(hub conversion.Hub) error {
+	dst := hub.(*v1beta1.PostgresCluster)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+
+	err := convert(&src.Spec, &dst.Spec)
+	if err == nil {
+		err = convert(&src.Status, &dst.Status)
+	}
+
+	// Restore the fields that were removed when converting from v1beta1.
+	if value, ok := dst.Annotations[RemovedFieldsAnnotation]; ok && err == nil {
+		var removed removedFields
+		if err = json.Unmarshal([]byte(value), &removed); err == nil {
+			dst.Spec.UserInterface = removed.Spec.UserInterface
+			dst.Status.UserInterface = removed.Status.UserInterface
+		}
+
+		delete(dst.Annotations, RemovedFieldsAnnotation)
+		if len(dst.Annotations) == 0 {
+			dst.Annotations = nil
+		}
+	}
+	return err
+}
+
+// ConvertFrom converts the Hub version, v1beta1, to dst.
+func (dst *PostgresCluster) ConvertFrom(hub conversion.Hub) error {
+	src := hub.(*v1beta1.PostgresCluster)
+	src.ObjectMeta.DeepCopyInto(&dst.ObjectMeta)
+
+	err := convert(&src.Spec, &dst.Spec)
+	if err == nil {
+		err = convert(&src.Status, &dst.Status)
+	}
+
+	// Keep the fields that are not in v1 in an annotation.
+	var removed removedFields
+	removed.Spec.UserInterface = src.Spec.UserInterface
+	removed.Status.UserInterface = src.Status.UserInterface
+
+	delete(dst.Annotations, RemovedFieldsAnnotation)
+	if err == nil && (removed.Spec.UserInterface != nil || removed.Status.UserInterface != nil) {
+		var value []byte
+		if value, err = json.Marshal(removed); err == nil {
+			if dst.Annotations == nil {
+				dst.Annotations = make(map[string]string)
+			}
+			dst.Annotations[RemovedFieldsAnnotation] = string(value)
+		}
+	}
+	if len(dst.Annotations) == 0 {
+		dst.Annotations = nil
+	}
+	return err
+}
+
+// convert copies the fields of in that are also in out. The two must have
+// the same JSON representation.
+func convert(in, out any) error {
+	data, err := json.Marshal(in)
+	if err == nil {
+		err = json.Unmarshal(data, out)
+	}
+	return err
+}
@@ -0,0 +1,129 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// hubCluster is a v1beta1 PostgresCluster that uses the fields removed in v1.
+const hubCluster = `
+metadata:
+  name: hippo
+  namespace: ns1
+  annotations:
+    some: thing
+spec:
+  postgresVersion: 16
+  image: postgres:16
+  instances:
+  - name: one
+    replicas: 2
+    dataVolumeClaimSpec:
+      accessModes: [ReadWriteOnce]
+      resources: { requests: { storage: 1Gi } }
+  backups:
+    pgbackrest:
+      repos:
+      - name: repo1
+        volume:
+          volumeClaimSpec:
+            accessModes: [ReadWriteOnce]
+            resources: { requests: { storage: 1Gi } }
+  proxy:
+    pgBouncer:
+      replicas: 1
+  userInterface:
+    pgAdmin:
+      dataVolumeClaimSpec:
+        accessModes: [ReadWriteOnce]
+        resources: { requests: { storage: 1Gi } }
+status:
+  postgresVersion: 16
+  userInterface:
+    pgAdmin:
+      usersRevision: abc
+  conditions:
+  - type: Ready
+    status: "True"
+    reason: Ready
+    message: ""
+    lastTransitionTime: "2024-01-01T00:00:00Z"
+`
+
+func TestPostgresClusterConversion(t *testing.T) {
+	var hub v1beta1.PostgresCluster
+	assert.NilError(t, yaml.Unmarshal([]byte(hubCluster), &hub))
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		var spoke PostgresCluster
+		assert.NilError(t, spoke.ConvertFrom(hub.DeepCopy()))
+
+		data, err := json.Marshal(spoke.Spec)
+		assert.NilError(t, err)
+		assert.Assert(t, !strings.Contains(string(data), "userInterface"))
+		assert.Equal(t, spoke.Spec.PostgresVersion, 16)
+		assert.Equal(t, len(spoke.Spec.InstanceSets), 1)
+		assert.Assert(t, spoke.Annotations[RemovedFieldsAnnotation] != "")
+		assert.Equal(t, spoke.Annotations["some"], "thing")
+
+		var back v1beta1.PostgresCluster
+		assert.NilError(t, spoke.ConvertTo(&back))
+
+		expected, err := json.Marshal(hub)
+		assert.NilError(t, err)
+		actual, err := json.Marshal(back)
+		assert.NilError(t, err)
+		assert.Equal(t, string(actual), string(expected))
+	})
+
+	t.Run("Spoke", func(t *testing.T) {
+		var spoke PostgresCluster
+		assert.NilError(t, yaml.Unmarshal([]byte(hubCluster), &spoke))
+		assert.Assert(t, spoke.Annotations[RemovedFieldsAnnotation] == "")
+
+		var middle v1beta1.PostgresCluster
+		assert.NilError(t, spoke.ConvertTo(&middle))
+		assert.Assert(t, middle.Spec.UserInterface == nil)
+
+		var back PostgresCluster
+		assert.NilError(t, back.ConvertFrom(&middle))
+
+		expected, err := json.Marshal(spoke)
+		assert.NilError(t, err)
+		actual, err := json.Marshal(back)
+		assert.NilError(t, err)
+		assert.Equal(t, string(actual), string(expected))
+	})
+
+	t.Run("NoRemovedFields", func(t *testing.T) {
+		hub := hub.DeepCopy()
+		hub.Spec.UserInterface = nil
+		hub.Status.UserInterface = nil
+		hub.Annotations = nil
+
+		var spoke PostgresCluster
+		assert.NilError(t, spoke.ConvertFrom(hub))
+		assert.Assert(t, spoke.Annotations == nil)
+	})
+}
@@ -0,0 +1,601 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// PostgresClusterSpec defines the desired state of PostgresCluster
+type PostgresClusterSpec struct {
+	// +optional
+	Metadata *v1beta1.Metadata `json:"metadata,omitempty"`
+
+	// Specifies a data source for bootstrapping the PostgreSQL cluster.
+	// +optional
+	DataSource *v1beta1.DataSource `json:"dataSource,omitempty"`
+
+	// PostgreSQL backup configuration. Required unless it comes from a template.
+	// +optional
+	Backups v1beta1.Backups `json:"backups"`
+
+	// The secret containing the Certificates and Keys to encrypt PostgreSQL
+	// traffic will need to contain the server TLS certificate, TLS key and the
+	// Certificate Authority certificate with the data keys set to tls.crt,
+	// tls.key and ca.crt, respectively. It will then be mounted as a volume
+	// projection to the '/pgconf/tls' directory. For more information on
+	// Kubernetes secret projections, please see
+	// https://k8s.io/docs/concepts/configuration/secret/#projection-of-secret-keys-to-specific-paths
+	// NOTE: If CustomTLSSecret is provided, CustomReplicationClientTLSSecret
+	// MUST be provided and the ca.crt provided must be the same.
+	// +optional
+	CustomTLSSecret *corev1.SecretProjection `json:"customTLSSecret,omitempty"`
+
+	// The secret containing the replication client certificates and keys for
+	// secure connections to the PostgreSQL server. It will need to contain the
+	// client TLS certificate, TLS key and the Certificate Authority certificate
+	// with the data keys set to tls.crt, tls.key and ca.crt, respectively.
+	// NOTE: If CustomReplicationClientTLSSecret is provided, CustomTLSSecret
+	// MUST be provided and the ca.crt provided must be the same.
+	// +optional
+	CustomReplicationClientTLSSecret *corev1.SecretProjection `json:"customReplicationTLSSecret,omitempty"`
+
+	// Configuration of PostgreSQL data page checksums.
+	// +optional
+	DataChecksums *v1beta1.DataChecksumsSpec `json:"dataChecksums,omitempty"`
+
+	// Options for initializing the PostgreSQL data directory of a new cluster.
+	// These take effect only when the cluster is created; changing them later
+	// has no effect. Data page checksums are configured by dataChecksums.
+	// More info: https://www.postgresql.org/docs/current/app-initdb.html
+	// +optional
+	Initdb *v1beta1.InitdbSpec `json:"initdb,omitempty"`
+
+	// The IANA name of the time zone of PostgreSQL, its logs, scheduled Jobs,
+	// pgAdmin, and PgBouncer, such as "America/New_York". Defaults to UTC.
+	// Kubernetes 1.25 or later is required for the schedules of CronJobs.
+	// More info: https://docs.k8s.io/concepts/workloads/controllers/cron-jobs/#time-zones
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	TimeZone string `json:"timezone,omitempty"`
+
+	// DatabaseInitSQL defines a ConfigMap containing custom SQL that will
+	// be run after the cluster is initialized. This ConfigMap must be in the same
+	// namespace as the cluster.
+	// +optional
+	DatabaseInitSQL *v1beta1.DatabaseInitSQL `json:"databaseInitSQL,omitempty"`
+
+	// Databases to create inside PostgreSQL and the foreign servers they can
+	// reach. Removing a database or foreign server from this list does NOT
+	// drop it.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Databases []v1beta1.PostgresDatabaseSpec `json:"databases,omitempty"`
+
+	// PostgresClusters that must reach some state before this cluster is
+	// created. Once they have, they are not checked again.
+	// +optional
+	// +listType=atomic
+	DependsOn []v1beta1.Dependency `json:"dependsOn,omitempty"`
+
+	// What happens to PersistentVolumeClaims when the PostgresCluster is
+	// deleted. "Delete" removes them along with the cluster. "Retain" keeps
+	// them so that a PostgresCluster created later with the same name adopts
+	// and reuses them. Defaults to "Delete".
+	// +optional
+	// +kubebuilder:validation:Enum={Delete,Retain}
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+
+	// Whether or not the PostgreSQL cluster should use the defined default
+	// scheduling constraints. If the field is unset or false, the default
+	// scheduling constraints will be used in addition to any custom constraints
+	// provided.
+	// +optional
+	DisableDefaultPodScheduling *bool `json:"disableDefaultPodScheduling,omitempty"`
+
+	// Feature gates to enable or disable for this cluster, overriding those of
+	// the operator. Only AppendCustomQueries, InstanceSidecars,
+	// PGBouncerSidecars, and TablespaceVolumes can be set here; other names
+	// are ignored.
+	// +optional
+	Features map[string]bool `json:"features,omitempty"`
+
+	// The image name to use for PostgreSQL containers. When omitted, the value
+	// comes from an operator environment variable. For standard PostgreSQL images,
+	// the format is RELATED_IMAGE_POSTGRES_{postgresVersion},
+	// e.g. RELATED_IMAGE_POSTGRES_13. For PostGIS enabled PostgreSQL images,
+	// the format is RELATED_IMAGE_POSTGRES_{postgresVersion}_GIS_{postGISVersion},
+	// e.g. RELATED_IMAGE_POSTGRES_13_GIS_3.1.
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=1
+	Image string `json:"image,omitempty"`
+
+	// ImagePullPolicy is used to determine when Kubernetes will attempt to
+	// pull (download) container images.
+	// More info: https://kubernetes.io/docs/concepts/containers/images/#image-pull-policy
+	// +kubebuilder:validation:Enum={Always,Never,IfNotPresent}
+	// +optional
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// The image pull secrets used to pull from a private registry
+	// Changing this value causes all running pods to restart.
+	// https://k8s.io/docs/tasks/configure-pod-container/pull-image-private-registry/
+	// +optional
+	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// Specifies one or more sets of PostgreSQL pods that replicate data for
+	// this cluster. Required unless they come from a template.
+	// +listType=map
+	// +listMapKey=name
+	// +kubebuilder:validation:MinItems=1
+	// +optional
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=2
+	InstanceSets []v1beta1.PostgresInstanceSetSpec `json:"instances,omitempty"`
+
+	// What happens to the volumes of instance sets that are renamed or removed.
+	// Delete removes them along with the StatefulSets, ConfigMaps, and
+	// certificates of those instances. Retain removes everything else but keeps
+	// the volumes and reports them in status. Defaults to Delete.
+	// +optional
+	// +kubebuilder:validation:Enum={Delete,Retain}
+	RemovedInstanceSetPolicy string `json:"removedInstanceSetPolicy,omitempty"`
+
+	// Scheduled synthetic transactions that check whether the cluster can be
+	// used: a heartbeat is written and read through PgBouncer, when enabled,
+	// or the primary, and replayed by a replica. The outcome is reported in
+	// the ClusterUsable condition.
+	// +optional
+	HealthProbes *v1beta1.HealthProbesSpec `json:"healthProbes,omitempty"`
+
+	// Scheduled checks of PostgreSQL tables and indexes for corruption using
+	// pg_amcheck. Requires PostgreSQL v14 or later.
+	// More info: https://www.postgresql.org/docs/current/app-pgamcheck.html
+	// +optional
+	IntegrityChecks *v1beta1.IntegrityChecksSpec `json:"integrityChecks,omitempty"`
+
+	// Scheduled rewrites of tables whose estimated bloat exceeds a threshold,
+	// during a maintenance window. Progress is reported in status and the
+	// outcome in the MaintenanceCompleted condition.
+	// +optional
+	Maintenance *v1beta1.MaintenanceSpec `json:"maintenance,omitempty"`
+
+	// Rebuilds indexes, without blocking reads or writes, when collation
+	// versions change, integrity checks fail, or PostgreSQL is upgraded from
+	// v11 or earlier. Progress is reported in the IndexesRebuilt condition.
+	// Requires PostgreSQL v12 or later.
+	// More info: https://www.postgresql.org/docs/current/sql-reindex.html
+	// +optional
+	Reindex *v1beta1.ReindexSpec `json:"reindex,omitempty"`
+
+	// Whether or not the PostgreSQL cluster is being deployed to an OpenShift
+	// environment. If the field is unset, the operator will automatically
+	// detect the environment from the namespace of the cluster.
+	// +optional
+	OpenShift *bool `json:"openshift,omitempty"`
+
+	// Tables partitioned and maintained by pg_partman. Requires PostgreSQL v14
+	// or later.
+	// More info: https://github.com/pgpartman/pg_partman
+	// +optional
+	Partitioning *v1beta1.PartitioningSpec `json:"partitioning,omitempty"`
+
+	// +optional
+	Patroni *v1beta1.PatroniSpec `json:"patroni,omitempty"`
+
+	// Suspends the rollout and reconciliation of changes made to the
+	// PostgresCluster spec.
+	// +optional
+	Paused *bool `json:"paused,omitempty"`
+
+	// Whether or not PostgreSQL rejects writes by default. Every transaction
+	// is read-only unless its session sets default_transaction_read_only to
+	// off, including connections through PgBouncer. The "postgres" superuser
+	// and the operator can still write.
+	// More info: https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-DEFAULT-TRANSACTION-READ-ONLY
+	// +optional
+	ReadOnly *bool `json:"readOnly,omitempty"`
+
+	// The port on which PostgreSQL should listen.
+	// +optional
+	// +kubebuilder:default=5432
+	// +kubebuilder:validation:Minimum=1024
+	Port *int32 `json:"port,omitempty"`
+
+	// The major version of PostgreSQL installed in the PostgreSQL image.
+	// Required unless it comes from a template.
+	// +optional
+	// +kubebuilder:validation:Minimum=10
+	// +kubebuilder:validation:Maximum=17
+	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=1
+	PostgresVersion int `json:"postgresVersion,omitempty"`
+
+	// The PostGIS extension version installed in the PostgreSQL image.
+	// When image is not set, indicates a PostGIS enabled image will be used.
+	// +optional
+	PostGISVersion string `json:"postGISVersion,omitempty"`
+
+	// The specification of a proxy that connects to PostgreSQL.
+	// +optional
+	Proxy *v1beta1.PostgresProxySpec `json:"proxy,omitempty"`
+
+	// The specification of monitoring tools that connect to PostgreSQL
+	// +optional
+	Monitoring *v1beta1.MonitoringSpec `json:"monitoring,omitempty"`
+
+	// Specification of the service that exposes the PostgreSQL primary instance.
+	// +optional
+	Service *v1beta1.ServiceSpec `json:"service,omitempty"`
+
+	// Specification of the service that exposes PostgreSQL replica instances
+	// +optional
+	ReplicaService *v1beta1.ServiceSpec `json:"replicaService,omitempty"`
+
+	// Hostnames for external-dns to publish for the Services of the cluster.
+	// Each hostname goes with a Service rather than a Pod, so it continues to
+	// reach the primary after a failover.
+	// More info: https://github.com/kubernetes-sigs/external-dns
+	// +optional
+	DNS *v1beta1.PostgresClusterDNS `json:"dns,omitempty"`
+
+	// Compatibility with a service mesh that injects proxies into the Pods of
+	// the cluster. PostgreSQL, Patroni, pgBackRest, and PgBouncer connect
+	// around the proxies, and containers start once the proxies are ready.
+	// +optional
+	ServiceMesh *v1beta1.ServiceMeshSpec `json:"serviceMesh,omitempty"`
+
+	// Limits of the Jobs that the operator creates to back up, restore,
+	// upgrade, and prepare the volumes of the cluster.
+	// +optional
+	Jobs *v1beta1.JobsSpec `json:"jobs,omitempty"`
+
+	// Capture diagnostics when a PostgreSQL process crashes: an excerpt of the
+	// log, snapshots of statistics views, and any core file. They are kept in
+	// the "diagnostics" directory of the data volume of the instance, and the
+	// most recent crash is recorded in the CrashDetected condition.
+	// +optional
+	Diagnostics *v1beta1.DiagnosticsSpec `json:"diagnostics,omitempty"`
+
+	// The IP family policy of every Service of the cluster. Set this to
+	// "PreferDualStack" or "RequireDualStack" on a dual-stack Kubernetes
+	// cluster. When empty, Kubernetes assigns the default of "SingleStack".
+	// - https://docs.k8s.io/concepts/services-networking/dual-stack/#services
+	// +optional
+	// +kubebuilder:validation:Enum={SingleStack,PreferDualStack,RequireDualStack}
+	IPFamilyPolicy *corev1.IPFamilyPolicyType `json:"ipFamilyPolicy,omitempty"`
+
+	// The IP families of every Service of the cluster, in order of preference.
+	// The first is the primary family and cannot change once Services exist.
+	// Processes that cannot listen on every family bind the wildcard address
+	// of the primary family. When empty, Kubernetes assigns the default family
+	// of the Kubernetes cluster.
+	// +listType=atomic
+	// +optional
+	// +kubebuilder:validation:MaxItems=2
+	// +kubebuilder:validation:items:Enum={IPv4,IPv6}
+	IPFamilies []corev1.IPFamily `json:"ipFamilies,omitempty"`
+
+	// Whether or not the PostgreSQL cluster should be stopped.
+	// When this is true, workloads are scaled to zero and CronJobs
+	// are suspended.
+	// Other resources, such as Services and Volumes, remain in place.
+	// +optional
+	Shutdown *bool `json:"shutdown,omitempty"`
+
+	// Protects data from changes that stop or replace it. With
+	// SnapshotBeforeRestart, a differential pgBackRest backup is taken before
+	// the operator changes the PostgreSQL image, restores in place, or allows
+	// a major upgrade. Those changes wait until the backup finishes, so allow
+	// an upgrade before shutting down the cluster. Defaults to None.
+	// +optional
+	// +kubebuilder:validation:Enum={None,SnapshotBeforeRestart}
+	ChangeProtection string `json:"changeProtection,omitempty"`
+
+	// Run this cluster as a read-only copy of an existing cluster or archive.
+	// +optional
+	Standby *v1beta1.PostgresStandbySpec `json:"standby,omitempty"`
+
+	// The number of pods wanted in the instance set that scales on demand. It
+	// is set by KEDA or a HorizontalPodAutoscaler through the scale subresource
+	// and kept between the minimum and maximum of that instance set.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	DemandReplicas *int32 `json:"demandReplicas,omitempty"`
+
+	// The name of a PostgresClusterTemplate in the same namespace. Each field
+	// of this spec that is not set comes from the template as a whole; fields
+	// that are set, including those set by default such as port, replace what
+	// is in the template.
+	// +optional
+	TemplateName string `json:"templateName,omitempty"`
+
+	// A list of group IDs applied to the process of a container. These can be
+	// useful when accessing shared file systems with constrained permissions.
+	// More info: https://kubernetes.io/docs/reference/kubernetes-api/workload-resources/pod-v1/#security-context
+	// +optional
+	SupplementalGroups []int64 `json:"supplementalGroups,omitempty"`
+
+	// Settings derived from the resources of PostgreSQL instances. Parameters
+	// in spec.patroni.dynamicConfiguration take precedence.
+	// +optional
+	Tuning *v1beta1.TuningSpec `json:"tuning,omitempty"`
+
+	// How changes to instance Pods are rolled out.
+	// +optional
+	UpdateStrategy *v1beta1.UpdateStrategySpec `json:"updateStrategy,omitempty"`
+
+	// Users to create inside PostgreSQL and the databases they should access.
+	// The default creates one user that can access one database matching the
+	// PostgresCluster name. An empty list creates no users. Removing a user
+	// from this list does NOT drop the user nor revoke their access.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Users []v1beta1.PostgresUserSpec `json:"users,omitempty"`
+
+	// Whether or not the Secrets generated for users are immutable. Each
+	// Secret is named for its user and a version, "<cluster>-pguser-<user>-v1",
+	// and any change to its contents creates a Secret with the next version.
+	// The current Secret of each user is in status.userSecrets. Annotate it with
+	// "postgres-operator.crunchydata.com/rotate-password" to generate a new
+	// password.
+	// More info: https://kubernetes.io/docs/concepts/configuration/secret/#secret-immutable
+	// +optional
+	ImmutableUserSecrets *bool `json:"immutableUserSecrets,omitempty"`
+
+	// A Secret to which the connection details of one user are written in the
+	// keys that Crossplane expects. When set, the Ready and Synced conditions
+	// that Crossplane reads from composed resources are reported, too.
+	// More info: https://docs.crossplane.io/latest/concepts/managed-resources/
+	// +optional
+	WriteConnectionSecretToRef *v1beta1.ConnectionSecretReference `json:"writeConnectionSecretToRef,omitempty"`
+
+	// A preset of planner, parallelism, checkpoint, and autovacuum parameters
+	// for the kind of queries PostgreSQL runs: "OLTP" for many short
+	// transactions, "OLAP" for large analytical queries, or "Mixed".
+	// Parameters in spec.patroni.dynamicConfiguration take precedence.
+	// +optional
+	// +kubebuilder:validation:Enum={OLTP,OLAP,Mixed}
+	WorkloadProfile string `json:"workloadProfile,omitempty"`
+
+	Config v1beta1.PostgresAdditionalConfig `json:"config,omitempty"`
+}
+
+// PostgresClusterStatus defines the observed state of PostgresCluster
+type PostgresClusterStatus struct {
+
+	// Identifies the databases that have been installed into PostgreSQL.
+	DatabaseRevision string `json:"databaseRevision,omitempty"`
+
+	// Identifies the foreign servers and user mappings that have been
+	// installed into PostgreSQL.
+	// +optional
+	ForeignServersRevision string `json:"foreignServersRevision,omitempty"`
+
+	// Identifies the read-only settings that have been installed into
+	// PostgreSQL.
+	// +optional
+	ReadOnlyRevision string `json:"readOnlyRevision,omitempty"`
+
+	// Identifies the tablespace for temporary files that has been installed
+	// into PostgreSQL.
+	// +optional
+	TempTablespaceRevision string `json:"tempTablespaceRevision,omitempty"`
+
+	// Current state of PostgreSQL instances.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	InstanceSets []v1beta1.PostgresInstanceSetStatus `json:"instances,omitempty"`
+
+	// +optional
+	Patroni v1beta1.PatroniStatus `json:"patroni,omitempty"`
+
+	// Status information for pgBackRest
+	// +optional
+	PGBackRest *v1beta1.PGBackRestStatus `json:"pgbackrest,omitempty"`
+
+	// Version information for installations with a registration requirement.
+	// +optional
+	RegistrationRequired *v1beta1.RegistrationRequirementStatus `json:"registrationRequired,omitempty"`
+
+	// Signals the need for a token to be applied when registration is required.
+	// +optional
+	TokenRequired string `json:"tokenRequired,omitempty"`
+
+	// Stores the current PostgreSQL major version following a successful
+	// major PostgreSQL upgrade.
+	// +optional
+	PostgresVersion int `json:"postgresVersion"`
+
+	// Current state of the PostgreSQL proxy.
+	// +optional
+	Proxy v1beta1.PostgresProxyStatus `json:"proxy,omitempty"`
+
+	// The instance that should be started first when bootstrapping and/or starting a
+	// PostgresCluster.
+	// +optional
+	StartupInstance string `json:"startupInstance,omitempty"`
+
+	// The instance set associated with the startupInstance
+	// +optional
+	StartupInstanceSet string `json:"startupInstanceSet,omitempty"`
+
+	// Identifies the users that have been installed into PostgreSQL.
+	UsersRevision string `json:"usersRevision,omitempty"`
+
+	// The current Secret of each user when user Secrets are immutable.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	UserSecrets []v1beta1.PostgresUserSecretStatus `json:"userSecrets,omitempty"`
+
+	// Current state of PostgreSQL cluster monitoring tool configuration
+	// +optional
+	Monitoring v1beta1.MonitoringStatus `json:"monitoring,omitempty"`
+
+	// DatabaseInitSQL state of custom database initialization in the cluster
+	// +optional
+	DatabaseInitSQL *string `json:"databaseInitSQL,omitempty"`
+
+	// Current state of PostgreSQL data page checksums
+	// +optional
+	DataChecksums *v1beta1.DataChecksumsStatus `json:"dataChecksums,omitempty"`
+
+	// Current state of backups taken before disruptive changes
+	// +optional
+	ChangeProtection *v1beta1.ChangeProtectionStatus `json:"changeProtection,omitempty"`
+
+	// Current state of collation version comparisons
+	// +optional
+	Collations *v1beta1.CollationsStatus `json:"collations,omitempty"`
+
+	// Current state of integrity checks
+	// +optional
+	IntegrityChecks *v1beta1.IntegrityChecksStatus `json:"integrityChecks,omitempty"`
+
+	// Current state of rebuilding indexes
+	// +optional
+	Reindex *v1beta1.ReindexStatus `json:"reindex,omitempty"`
+
+	// Current state of health probes
+	// +optional
+	HealthProbes *v1beta1.HealthProbesStatus `json:"healthProbes,omitempty"`
+
+	// Current state of scheduled maintenance
+	// +optional
+	Maintenance *v1beta1.MaintenanceStatus `json:"maintenance,omitempty"`
+
+	// Current state of partition maintenance
+	// +optional
+	Partitioning *v1beta1.PartitioningStatus `json:"partitioning,omitempty"`
+
+	// The most recent restore points created by the restore-point annotation,
+	// oldest first. At most 20 are kept.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	RestorePoints []v1beta1.RestorePointStatus `json:"restorePoints,omitempty"`
+
+	// Current state of the user that finishes a major upgrade
+	// +optional
+	UpgradeUser *v1beta1.UpgradeUserStatus `json:"upgradeUser,omitempty"`
+
+	// Current state of scheduled instance set scaling
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Autoscaling []v1beta1.InstanceSetAutoscalingStatus `json:"autoscaling,omitempty"`
+
+	// The number of pods in the instance set that scales on demand
+	// +optional
+	DemandReplicas int32 `json:"demandReplicas,omitempty"`
+
+	// The label selector of pods in the instance set that scales on demand
+	// +optional
+	DemandSelector string `json:"demandSelector,omitempty"`
+
+	// Faults injected by annotations when the FaultInjection feature gate is enabled
+	// +optional
+	FaultInjection *v1beta1.FaultInjectionStatus `json:"faultInjection,omitempty"`
+
+	// Totals of the compute and storage of every Pod and volume of the cluster
+	// +optional
+	Resources *v1beta1.ResourcesStatus `json:"resources,omitempty"`
+
+	// Resources of instance sets that are no longer in the spec and were left
+	// in place: volumes kept by the removedInstanceSetPolicy and resources that
+	// are not controlled by the cluster, such as those of older operators.
+	// +optional
+	OrphanedResources []v1beta1.OrphanedResource `json:"orphanedResources,omitempty"`
+
+	// Names of the feature gates enabled for this cluster
+	// +optional
+	EnabledFeatures []string `json:"enabledFeatures,omitempty"`
+
+	// Current state of WAL streaming by pg_receivewal
+	// +optional
+	WALStreaming *v1beta1.WALStreamingStatus `json:"walStreaming,omitempty"`
+
+	// Current state of the root certificate authority that issues certificates
+	// for the cluster
+	// +optional
+	CertificateAuthority *v1beta1.CertificateAuthorityStatus `json:"certificateAuthority,omitempty"`
+
+	// Checkpoint parameters derived from the rate at which the primary writes WAL
+	// +optional
+	CheckpointTuning *v1beta1.CheckpointTuningStatus `json:"checkpointTuning,omitempty"`
+
+	// Crashes of PostgreSQL found in its logs
+	// +optional
+	Diagnostics *v1beta1.DiagnosticsStatus `json:"diagnostics,omitempty"`
+
+	// Lifecycle hooks that have been called for the cluster
+	// +optional
+	LifecycleHooks *v1beta1.LifecycleHooksStatus `json:"lifecycleHooks,omitempty"`
+
+	// The most recent replica that was split into a new PostgresCluster
+	// +optional
+	Split *v1beta1.SplitStatus `json:"split,omitempty"`
+
+	// observedGeneration represents the .metadata.generation on which the status was based.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// conditions represent the observations of postgrescluster's current state.
+	// Known .status.conditions.type are: "ChangesHeld", "ClusterUsable", "CollationVersionMismatch", "CrashDetected",
+	// "DataChecksumsVerified", "DataMasked", "DependenciesSatisfied", "DiskProtectionEngaged", "IndexesRebuilt", "IntegrityChecked",
+	// "MaintenanceCompleted", "PartitionsMaintained", "PausedByUser", "PermissionsAvailable", "PersistentVolumeResizing", "Progressing", "ProxyAvailable",
+	// "Ready", "SecretsAvailable", "Synced", "TemplateAvailable", "WALExpirationHeld"
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	// +operator-sdk:csv:customresourcedefinitions:type=status,xDescriptors={"urn:alm:descriptor:io.kubernetes.conditions"}
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.demandReplicas,statuspath=.status.demandReplicas,selectorpath=.status.demandSelector
+
+// PostgresCluster is the Schema for the postgresclusters API
+type PostgresCluster struct {
+	// ObjectMeta.Name is a DNS subdomain.
+	// - https://docs.k8s.io/concepts/overview/working-with-objects/names/#dns-subdomain-names
+
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PostgresClusterSpec   `json:"spec,omitempty"`
+	Status PostgresClusterStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// PostgresClusterList contains a list of PostgresCluster
+type PostgresClusterList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PostgresCluster `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PostgresCluster{}, &PostgresClusterList{})
+}
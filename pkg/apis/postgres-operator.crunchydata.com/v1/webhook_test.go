@@ -0,0 +1,128 @@
+//go:build envtest
+// +build envtest
+
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gotest.tools/v3/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/conversion"
+	"sigs.k8s.io/yaml"
+
+	"github.com/crunchydata/postgres-operator/internal/initialize"
+	"github.com/crunchydata/postgres-operator/pkg/apis/postgres-operator.crunchydata.com/v1beta1"
+)
+
+// TestConversionWebhook converts PostgresClusters through the API server the
+// way the operator serves them: at "/convert" of its webhook server.
+func TestConversionWebhook(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	scheme := runtime.NewScheme()
+	assert.NilError(t, clientgoscheme.AddToScheme(scheme))
+	assert.NilError(t, v1beta1.AddToScheme(scheme))
+	assert.NilError(t, AddToScheme(scheme))
+
+	// The API server calls the webhook of every convertible kind in scheme.
+	env := &envtest.Environment{
+		CRDDirectoryPaths: []string{
+			filepath.Join("..", "..", "..", "config", "crd", "bases"),
+		},
+		CRDInstallOptions: envtest.CRDInstallOptions{Scheme: scheme},
+	}
+	config, err := env.Start()
+	assert.NilError(t, err)
+	t.Cleanup(func() { assert.Check(t, env.Stop()) })
+
+	server := &webhook.Server{
+		Host:    env.WebhookInstallOptions.LocalServingHost,
+		Port:    env.WebhookInstallOptions.LocalServingPort,
+		CertDir: env.WebhookInstallOptions.LocalServingCertDir,
+	}
+	server.Register("/convert", &conversion.Webhook{})
+	go func() { assert.Check(t, server.StartStandalone(ctx, scheme)) }()
+
+	for ready := server.StartedChecker(); ready(nil) != nil; {
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	cc, err := client.New(config, client.Options{Scheme: scheme})
+	assert.NilError(t, err)
+
+	namespace := &corev1.Namespace{}
+	namespace.GenerateName = "postgres-operator-test-"
+	assert.NilError(t, cc.Create(ctx, namespace))
+	t.Cleanup(func() { assert.Check(t, client.IgnoreNotFound(cc.Delete(ctx, namespace))) })
+
+	t.Run("FromStorage", func(t *testing.T) {
+		hub := &v1beta1.PostgresCluster{}
+		assert.NilError(t, yaml.Unmarshal([]byte(hubCluster), hub))
+		hub.Namespace, hub.Name = namespace.Name, "from-storage"
+		hub.Status = v1beta1.PostgresClusterStatus{}
+		assert.NilError(t, cc.Create(ctx, hub))
+
+		// Read the stored v1beta1 object as v1.
+		spoke := &PostgresCluster{}
+		assert.NilError(t, cc.Get(ctx, client.ObjectKeyFromObject(hub), spoke))
+		assert.Equal(t, spoke.Spec.PostgresVersion, 16)
+		assert.Equal(t, spoke.Annotations["some"], "thing")
+		assert.Assert(t, spoke.Annotations[RemovedFieldsAnnotation] != "")
+
+		// Write it as v1 and read it again as v1beta1. The fields that are
+		// not in v1 are not lost.
+		spoke.Spec.InstanceSets[0].Replicas = initialize.Int32(3)
+		assert.NilError(t, cc.Update(ctx, spoke))
+
+		stored := &v1beta1.PostgresCluster{}
+		assert.NilError(t, cc.Get(ctx, client.ObjectKeyFromObject(hub), stored))
+		assert.Equal(t, *stored.Spec.InstanceSets[0].Replicas, int32(3))
+		assert.Assert(t, stored.Spec.UserInterface != nil)
+		assert.Assert(t, stored.Spec.UserInterface.PGAdmin != nil)
+		assert.Equal(t, stored.Annotations[RemovedFieldsAnnotation], "")
+		assert.Equal(t, stored.Annotations["some"], "thing")
+	})
+
+	t.Run("FromSpoke", func(t *testing.T) {
+		spoke := &PostgresCluster{}
+		assert.NilError(t, yaml.Unmarshal([]byte(hubCluster), spoke))
+		spoke.Namespace, spoke.Name = namespace.Name, "from-spoke"
+		spoke.Status = PostgresClusterStatus{}
+		assert.NilError(t, cc.Create(ctx, spoke))
+
+		stored := &v1beta1.PostgresCluster{}
+		assert.NilError(t, cc.Get(ctx, client.ObjectKeyFromObject(spoke), stored))
+		assert.Equal(t, stored.Spec.PostgresVersion, 16)
+		assert.Assert(t, stored.Spec.UserInterface == nil)
+
+		again := &PostgresCluster{}
+		assert.NilError(t, cc.Get(ctx, client.ObjectKeyFromObject(spoke), again))
+		assert.DeepEqual(t, again.Spec, spoke.Spec)
+		assert.Equal(t, again.Annotations[RemovedFieldsAnnotation], "")
+	})
+}
@@ -0,0 +1,129 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// InstanceSetAutoscaling changes the number of pods in an instance set on a
+// recurring schedule, on demand, or both. Outside every scheduled period, the
+// replicas field of the instance set applies. When demand calls for more pods
+// than the schedule, the larger number applies.
+type InstanceSetAutoscaling struct {
+	// Periods of time that have their own number of pods. When periods
+	// overlap, the first one in this list applies.
+	// +optional
+	// +listType=atomic
+	Schedules []InstanceSetReplicaSchedule `json:"schedules,omitempty"`
+
+	// Change the number of pods according to client connections. Only one
+	// instance set of a cluster can scale on demand.
+	// +optional
+	Demand *InstanceSetDemandScaling `json:"demand,omitempty"`
+
+	// The IANA name of the time zone of every schedule, such as
+	// "America/New_York". Defaults to UTC.
+	// +optional
+	TimeZone string `json:"timeZone,omitempty"`
+
+	// How long pods that are no longer scheduled keep running so that
+	// queries on them can finish. Defaults to 300 seconds.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ScaleDownDelaySeconds *int32 `json:"scaleDownDelaySeconds,omitempty"`
+}
+
+// InstanceSetReplicaSchedule is a recurring period of time during the week.
+type InstanceSetReplicaSchedule struct {
+	// Days of the week on which the period starts. Defaults to every day.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:items:Enum={Sun,Mon,Tue,Wed,Thu,Fri,Sat}
+	Days []string `json:"days,omitempty"`
+
+	// The time of day, "HH:MM", when the period starts.
+	// +required
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// The time of day, "HH:MM", when the period ends. A period that ends at
+	// or before its start continues into the next day.
+	// +required
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	End string `json:"end"`
+
+	// Number of desired PostgreSQL pods during the period.
+	// +required
+	// +kubebuilder:validation:Minimum=1
+	Replicas int32 `json:"replicas"`
+}
+
+// InstanceSetDemandScaling changes the number of pods in an instance set
+// through the scale subresource of its PostgresCluster. The operator creates a
+// KEDA ScaledObject that reads the "ccp_autoscaling" metrics of the exporter
+// from Prometheus, so KEDA and the exporter must be installed. The exporter
+// exports those metrics whenever an instance set scales on demand. A
+// HorizontalPodAutoscaler can use the scale subresource instead when
+// createScaledObject is false.
+// - https://keda.sh/docs/latest/scalers/prometheus/
+type InstanceSetDemandScaling struct {
+	// The fewest pods to run.
+	// +required
+	// +kubebuilder:validation:Minimum=1
+	MinReplicas int32 `json:"minReplicas"`
+
+	// The most pods to run.
+	// +required
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicas int32 `json:"maxReplicas"`
+
+	// Whether or not the operator creates a KEDA ScaledObject. Defaults to true.
+	// +optional
+	CreateScaledObject *bool `json:"createScaledObject,omitempty"`
+
+	// The address of the Prometheus server that scrapes the exporter, such as
+	// "http://prometheus.monitoring.svc:9090".
+	// +optional
+	PrometheusAddress string `json:"prometheusAddress,omitempty"`
+
+	// The PromQL query of the number of active client connections in the
+	// cluster. Defaults to the sum of "ccp_autoscaling_active_connections"
+	// with the "pg_cluster" label of the cluster.
+	// +optional
+	Query string `json:"query,omitempty"`
+
+	// The number of active client connections that each pod can handle.
+	// Defaults to 20.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ActiveConnectionsPerReplica *int32 `json:"activeConnectionsPerReplica,omitempty"`
+}
+
+// InstanceSetAutoscalingStatus is the observed state of autoscaling in one
+// instance set.
+type InstanceSetAutoscalingStatus struct {
+	// The name of the instance set.
+	Name string `json:"name"`
+
+	// The number of pods currently wanted in the instance set.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// When the instance set was first scheduled to have fewer pods. Pods
+	// are removed once the scale down delay has passed.
+	// +optional
+	ScaleDownTime *metav1.Time `json:"scaleDownTime,omitempty"`
+}
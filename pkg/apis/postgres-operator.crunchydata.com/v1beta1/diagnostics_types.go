@@ -0,0 +1,70 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DiagnosticsSpec defines what is captured when a PostgreSQL process crashes.
+type DiagnosticsSpec struct {
+	// Whether or not PostgreSQL processes can write core files. A process
+	// writes its core file in the data directory when the Node allows it,
+	// and the file is moved with the other diagnostics of the crash. Core
+	// files can be as large as the memory of PostgreSQL. Changing this value
+	// causes PostgreSQL to restart.
+	// +optional
+	CoreDumps bool `json:"coreDumps,omitempty"`
+
+	// The number of crashes whose diagnostics are kept on each instance.
+	// Diagnostics of older crashes are removed. Defaults to 3.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	Keep *int32 `json:"keep,omitempty"`
+}
+
+// DiagnosticsStatus records when the logs of PostgreSQL were last read and
+// the most recent crash found in them.
+type DiagnosticsStatus struct {
+	// When the logs of PostgreSQL were last read.
+	// +optional
+	ObservedTime *metav1.Time `json:"observedTime,omitempty"`
+
+	// The most recent crash of a PostgreSQL process.
+	// +optional
+	LastCrash *CrashStatus `json:"lastCrash,omitempty"`
+}
+
+// CrashStatus describes a crash of a PostgreSQL process and where its
+// diagnostics are kept.
+type CrashStatus struct {
+	// The Pod in which PostgreSQL crashed.
+	// +optional
+	Pod string `json:"pod,omitempty"`
+
+	// When PostgreSQL logged the crash.
+	// +optional
+	Time *metav1.Time `json:"time,omitempty"`
+
+	// The process that crashed and how, as PostgreSQL logged it.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// The directory in the Pod that contains the log excerpt, statistics
+	// views, and any core file of the crash.
+	// +optional
+	Path string `json:"path,omitempty"`
+}
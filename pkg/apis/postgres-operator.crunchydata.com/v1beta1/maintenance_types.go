@@ -0,0 +1,107 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// MaintenanceSpec defines scheduled rewrites of bloated tables.
+type MaintenanceSpec struct {
+	// When maintenance is allowed to run.
+	// +required
+	Window MaintenanceWindow `json:"window"`
+
+	// How bloated tables are rewritten. "Vacuum" reclaims space for reuse
+	// without blocking reads or writes. "VacuumFull" returns space to the
+	// filesystem but blocks reads and writes of each table while it is
+	// rewritten. "Repack" returns space to the filesystem using pg_repack,
+	// which holds an exclusive lock only briefly at the start and end of each
+	// table. VacuumFull and Repack skip tables that cannot be locked within a
+	// few seconds, and every method skips tables owned by superusers.
+	// Rewrites happen on the primary and replicate to every replica.
+	// Defaults to "Vacuum".
+	// More info: https://www.postgresql.org/docs/current/routine-vacuuming.html
+	// +optional
+	// +kubebuilder:default=Vacuum
+	// +kubebuilder:validation:Enum={Vacuum,VacuumFull,Repack}
+	Method string `json:"method,omitempty"`
+
+	// Tables whose dead rows and free space, as estimated by
+	// pgstattuple_approx, exceed this percentage of their size are
+	// rewritten. Defaults to 30.
+	// More info: https://www.postgresql.org/docs/current/pgstattuple.html
+	// +optional
+	// +kubebuilder:default=30
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	BloatThreshold *int32 `json:"bloatThreshold,omitempty"`
+
+	// The databases to maintain. When empty, every database that allows
+	// connections is maintained.
+	// +optional
+	// +listType=set
+	Databases []string `json:"databases,omitempty"`
+
+	// Resource requirements of the maintenance Job.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Limit the lifetime of a Job that has finished.
+	// More info: https://kubernetes.io/docs/concepts/workloads/controllers/job
+	// +optional
+	// +kubebuilder:validation:Minimum=60
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+}
+
+// MaintenanceWindow is a recurring period of time.
+type MaintenanceWindow struct {
+	// The Cron schedule that starts the window. Follows the standard Cron
+	// schedule syntax:
+	// https://k8s.io/docs/concepts/workloads/controllers/cron-jobs/#cron-schedule-syntax
+	// +required
+	// +kubebuilder:validation:MinLength=6
+	Schedule string `json:"schedule"`
+
+	// The length of the window. A maintenance Job that is still running at
+	// the end of the window is stopped. When not set, the Job runs until it
+	// finishes.
+	// +optional
+	// +kubebuilder:validation:Minimum=60
+	DurationSeconds *int64 `json:"durationSeconds,omitempty"`
+}
+
+// MaintenanceStatus is the observed state of scheduled maintenance.
+type MaintenanceStatus struct {
+	// Identifies the maintenance privileges that have been granted in PostgreSQL.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// The completion time of the most recent maintenance Job.
+	// +optional
+	LastMaintenanceTime *metav1.Time `json:"lastMaintenanceTime,omitempty"`
+
+	// The number of tables that exceeded bloatThreshold during the most
+	// recent maintenance Job.
+	// +optional
+	BloatedTables int32 `json:"bloatedTables,omitempty"`
+
+	// The number of bloated tables that the most recent maintenance Job
+	// rewrote. The rest were skipped or did not fit in the window.
+	// +optional
+	RewrittenTables int32 `json:"rewrittenTables,omitempty"`
+}
@@ -0,0 +1,106 @@
+/*
+ Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+ Licensed under the Apache License, Version 2.0 (the "License");
+ you may not use this file except in compliance with the License.
+ You may obtain a copy of the License at
+
+ http://www.apache.org/licenses/LICENSE-2.0
+
+ Unless required by applicable law or agreed to in writing, software
+ distributed under the License is distributed on an "AS IS" BASIS,
+ WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ See the License for the specific language governing permissions and
+ limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PartitioningSpec defines tables that are partitioned by pg_partman and a
+// scheduled Job that maintains their partitions.
+type PartitioningSpec struct {
+	// The Cron schedule of the Job that creates upcoming partitions and drops
+	// expired ones. Follows the standard Cron schedule syntax:
+	// https://k8s.io/docs/concepts/workloads/controllers/cron-jobs/#cron-schedule-syntax
+	// +required
+	// +kubebuilder:validation:MinLength=6
+	Schedule string `json:"schedule"`
+
+	// Partitioned tables to manage. Each must already exist and be owned by
+	// a role that is not a superuser.
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	// +listType=map
+	// +listMapKey=database
+	// +listMapKey=table
+	Tables []PartitionedTable `json:"tables"`
+
+	// Resource requirements of the partition maintenance Job.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Limit the lifetime of a Job that has finished.
+	// More info: https://kubernetes.io/docs/concepts/workloads/controllers/job
+	// +optional
+	// +kubebuilder:validation:Minimum=60
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+}
+
+// PartitionedTable is a table partitioned by ranges of one column.
+// More info: https://github.com/pgpartman/pg_partman/blob/master/doc/pg_partman.md
+type PartitionedTable struct {
+	// The database that contains the table.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Database string `json:"database"`
+
+	// The schema-qualified name of the partitioned table.
+	// +required
+	// +kubebuilder:validation:Pattern=`^[^.]+\.[^.]+$`
+	Table string `json:"table"`
+
+	// The column by which the table is partitioned.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Column string `json:"column"`
+
+	// The range of each partition, such as "1 day" or "1 month" for time
+	// columns, or a number for integer columns. This cannot be changed after
+	// the table is first partitioned.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Interval string `json:"interval"`
+
+	// The number of partitions to create ahead of the current one.
+	// Defaults to 4.
+	// +optional
+	// +kubebuilder:default=4
+	// +kubebuilder:validation:Minimum=1
+	Premake *int32 `json:"premake,omitempty"`
+
+	// How long to keep partitions, such as "30 days". Older partitions are
+	// dropped. When not set, partitions are kept forever.
+	// +optional
+	Retention string `json:"retention,omitempty"`
+}
+
+// PartitioningStatus is the observed state of partition maintenance.
+type PartitioningStatus struct {
+	// Identifies the pg_partman configuration that has been written into PostgreSQL.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// The completion time of the most recent partition maintenance Job.
+	// +optional
+	LastMaintenanceTime *metav1.Time `json:"lastMaintenanceTime,omitempty"`
+
+	// Tables that did not exist when pg_partman was last configured, as
+	// "database/table". They are registered after the next maintenance Job.
+	// +optional
+	// +listType=atomic
+	UnregisteredTables []string `json:"unregisteredTables,omitempty"`
+}
@@ -53,6 +53,25 @@ type PatroniSpec struct {
 	// +optional
 	Switchover *PatroniSwitchover `json:"switchover,omitempty"`
 
+	// Restart gives options to restart or reload PostgreSQL on demand.
+	// +optional
+	Restart *PatroniRestart `json:"restart,omitempty"`
+
+	// Whether or not Patroni should stop managing PostgreSQL. While paused,
+	// Patroni does not fail over, promote, or restart instances so that manual
+	// maintenance, such as single-user mode repairs, can be done. The operator
+	// resumes Patroni after pausedTimeoutSeconds; set this false and true
+	// again to pause another time.
+	// More info: https://patroni.readthedocs.io/en/latest/pause.html
+	// +optional
+	Paused *bool `json:"paused,omitempty"`
+
+	// The longest time Patroni may be paused before the operator resumes it.
+	// +optional
+	// +kubebuilder:default=3600
+	// +kubebuilder:validation:Minimum=60
+	PausedTimeoutSeconds *int32 `json:"pausedTimeoutSeconds,omitempty"`
+
 	// TODO(cbandy): Add UseConfigMaps bool, default false.
 	// TODO(cbandy): Allow other DCS: etcd, raft, etc?
 	// N.B. changing this will cause downtime.
@@ -82,6 +101,26 @@ type PatroniSwitchover struct {
 	Type string `json:"type,omitempty"`
 }
 
+type PatroniRestart struct {
+
+	// Whether or not the operator should restart or reload PostgreSQL when
+	// the trigger-restart or trigger-reload annotation changes.
+	// +required
+	Enabled bool `json:"enabled"`
+
+	// The instances to restart or reload. When empty, every instance in the
+	// PostgresCluster is restarted or reloaded. Replicas always restart before
+	// the primary.
+	// +listType=set
+	// +optional
+	TargetInstances []string `json:"targetInstances,omitempty"`
+
+	// Whether or not to restart only those instances with PostgreSQL
+	// parameters that are pending a restart.
+	// +optional
+	PendingOnly bool `json:"pendingOnly,omitempty"`
+}
+
 // PatroniSwitchover types.
 const (
 	PatroniSwitchoverTypeFailover   = "Failover"
@@ -93,6 +132,7 @@ const (
 // - Lock Lease Duration
 // - Patroni's API port
 // - Frequency of syncing with Kube API
+// - Longest time Patroni may be paused
 func (s *PatroniSpec) Default() {
 	if s.LeaderLeaseDurationSeconds == nil {
 		s.LeaderLeaseDurationSeconds = new(int32)
@@ -106,6 +146,10 @@ func (s *PatroniSpec) Default() {
 		s.SyncPeriodSeconds = new(int32)
 		*s.SyncPeriodSeconds = 10
 	}
+	if s.PausedTimeoutSeconds == nil {
+		s.PausedTimeoutSeconds = new(int32)
+		*s.PausedTimeoutSeconds = 3600
+	}
 }
 
 type PatroniStatus struct {
@@ -125,4 +169,12 @@ type PatroniStatus struct {
 	// Tracks the current timeline during switchovers
 	// +optional
 	SwitchoverTimeline *int64 `json:"switchoverTimeline,omitempty"`
+
+	// Tracks the execution of the restart requests.
+	// +optional
+	Restart *string `json:"restart,omitempty"`
+
+	// Tracks the execution of the reload requests.
+	// +optional
+	Reload *string `json:"reload,omitempty"`
 }
@@ -17,6 +17,7 @@ package v1beta1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -93,6 +94,41 @@ type PGBackRestScheduledBackupStatus struct {
 	// The number of Pods for the manual backup Job that reached the "Failed" phase.
 	// +optional
 	Failed int32 `json:"failed,omitempty"`
+
+	// The name of the backup Job. The Job may have been deleted since.
+	// +optional
+	JobName string `json:"jobName,omitempty"`
+
+	// The label pgBackRest assigned to the backup, e.g. "20230102-030405F"
+	// +optional
+	BackupLabel string `json:"backupLabel,omitempty"`
+
+	// How long the backup Job took to complete
+	// +optional
+	Duration *metav1.Duration `json:"duration,omitempty"`
+
+	// The size of the database in bytes at the time of the backup
+	// +optional
+	Size *int64 `json:"size,omitempty"`
+
+	// The reason the backup Job failed, if it did
+	// +optional
+	FailureMessage string `json:"failureMessage,omitempty"`
+}
+
+// PGBackRestJobHistoryLimit defines how many finished scheduled backup Jobs to keep.
+type PGBackRestJobHistoryLimit struct {
+	// The number of successful Jobs to keep for each backup schedule.
+	// Defaults to 3.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Successful *int32 `json:"successful,omitempty"`
+
+	// The number of failed Jobs to keep for each backup schedule.
+	// Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Failed *int32 `json:"failed,omitempty"`
 }
 
 // PGBackRestArchive defines a pgBackRest archive configuration
@@ -114,6 +150,12 @@ type PGBackRestArchive struct {
 	// +optional
 	Global map[string]string `json:"global,omitempty"`
 
+	// Defines what happens to WAL that PostgreSQL cannot archive, such as while
+	// a repository is unreachable. The DiskProtectionEngaged condition reports
+	// when WAL is being dropped to protect the primary.
+	// +optional
+	ArchiveFailure *PGBackRestArchiveFailure `json:"archiveFailure,omitempty"`
+
 	// The image name to use for pgBackRest containers.  Utilized to run
 	// pgBackRest repository hosts and backups. The image may also be set using
 	// the RELATED_IMAGE_PGBACKREST environment variable
@@ -124,6 +166,11 @@ type PGBackRestArchive struct {
 	// +optional
 	Jobs *BackupJobs `json:"jobs,omitempty"`
 
+	// The number of finished scheduled backup Jobs to keep. Jobs beyond these
+	// limits are deleted, but their status remains in scheduledBackups.
+	// +optional
+	JobHistoryLimit *PGBackRestJobHistoryLimit `json:"jobHistoryLimit,omitempty"`
+
 	// Defines a pgBackRest repository
 	// +kubebuilder:validation:MinItems=1
 	// +listType=map
@@ -136,10 +183,25 @@ type PGBackRestArchive struct {
 	// +optional
 	RepoHost *PGBackRestRepoHost `json:"repoHost,omitempty"`
 
+	// Whether or not full and differential backups to "volume" repositories
+	// should read data files from a replica rather than the primary. Backups
+	// read from the primary when no replica is ready as they start.
+	// More info: https://pgbackrest.org/configuration.html#section-backup/option-backup-standby
+	// +optional
+	BackupStandby *bool `json:"backupStandby,omitempty"`
+
 	// Defines details for manual pgBackRest backup Jobs
 	// +optional
 	Manual *PGBackRestManualBackup `json:"manual,omitempty"`
 
+	// Moves backups from one repository to another, such as from a volume to
+	// object storage. A full backup is taken in the new repository, and the
+	// volume of the old repository is kept while this is defined, even after
+	// the old repository is removed from the repos list. Backups on that volume
+	// can be restored only while the old repository is in the repos list.
+	// +optional
+	RepoMigration *PGBackRestRepoMigration `json:"repoMigration,omitempty"`
+
 	// Defines details for performing an in-place restore using pgBackRest
 	// +optional
 	Restore *PGBackRestRestore `json:"restore,omitempty"`
@@ -149,6 +211,27 @@ type PGBackRestArchive struct {
 	Sidecars *PGBackRestSidecars `json:"sidecars,omitempty"`
 }
 
+// PGBackRestArchiveFailure defines what happens to WAL that PostgreSQL cannot
+// archive.
+type PGBackRestArchiveFailure struct {
+	// What to do with WAL that cannot be archived. "Retain" keeps every WAL
+	// file until it is archived, so WAL grows until the repository is reachable
+	// again or the volume is full and PostgreSQL stops. "Drop" keeps at most
+	// maxQueueSize of WAL. Beyond that, pgBackRest reports WAL as archived
+	// without storing it. This protects the primary but breaks point-in-time
+	// recovery until the next backup.
+	// More info: https://pgbackrest.org/configuration.html#section-archive/option-archive-push-queue-max
+	// +kubebuilder:validation:Enum={Retain,Drop}
+	// +kubebuilder:default=Retain
+	// +optional
+	Policy string `json:"policy,omitempty"`
+
+	// The most WAL to keep while it cannot be archived. This is required when
+	// the policy is "Drop" and should be well below the size of the WAL volume.
+	// +optional
+	MaxQueueSize *resource.Quantity `json:"maxQueueSize,omitempty"`
+}
+
 // PGBackRestSidecars defines the configuration for pgBackRest sidecar containers
 type PGBackRestSidecars struct {
 	// Defines the configuration for the pgBackRest sidecar container
@@ -188,6 +271,21 @@ type BackupJobs struct {
 	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
 }
 
+// PGBackRestRepoMigration identifies a repository that is being replaced by another.
+type PGBackRestRepoMigration struct {
+	// The name of the repository being replaced. Its volume is kept until this
+	// migration is removed from the spec.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=^repo[1-4]
+	From string `json:"from"`
+
+	// The name of the repository replacing it. This repository must be defined
+	// in the repos list.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=^repo[1-4]
+	To string `json:"to"`
+}
+
 // PGBackRestManualBackup contains information that is used for creating a
 // pgBackRest backup that is invoked manually (i.e. it's unscheduled).
 type PGBackRestManualBackup struct {
@@ -253,6 +351,23 @@ type PGBackRestRestore struct {
 	*PostgresClusterDataSource `json:",inline"`
 }
 
+// PGBackRestRestoreDatabases limits a pgBackRest restore to some databases.
+// PostgreSQL template databases and the "postgres" database are always restored.
+// Files of the other databases are restored empty, so those databases cannot be
+// used and should be dropped once the restore is complete. Tables and other
+// objects within a database cannot be filtered.
+type PGBackRestRestoreDatabases struct {
+	// Names of the databases to restore. All others are restored empty.
+	// +listType=set
+	// +optional
+	Include []string `json:"include,omitempty"`
+
+	// Names of the databases to restore empty. All others are restored.
+	// +listType=set
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+}
+
 // PGBackRestBackupSchedules defines a pgBackRest scheduled backup
 type PGBackRestBackupSchedules struct {
 	// Validation set to minimum length of six to account for @daily option
@@ -277,6 +392,14 @@ type PGBackRestBackupSchedules struct {
 	// +optional
 	// +kubebuilder:validation:MinLength=6
 	Incremental *string `json:"incremental,omitempty"`
+
+	// The IANA name of the time zone of these schedules, such as
+	// "America/New_York". Defaults to the time zone of the cluster.
+	// Kubernetes 1.25 or later is required.
+	// More info: https://docs.k8s.io/concepts/workloads/controllers/cron-jobs/#time-zones
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9_+-]+(/[A-Za-z0-9_+-]+)*$`
+	TimeZone string `json:"timeZone,omitempty"`
 }
 
 // PGBackRestStatus defines the status of pgBackRest within a PostgresCluster
@@ -303,6 +426,37 @@ type PGBackRestStatus struct {
 	// Status information for in-place restores
 	// +optional
 	Restore *PGBackRestJobStatus `json:"restore,omitempty"`
+
+	// Status information for a repository migration
+	// +optional
+	RepoMigration *PGBackRestRepoMigrationStatus `json:"repoMigration,omitempty"`
+
+	// When the full backups of each repository were last read from pgBackRest.
+	// +optional
+	BackupsObserved *metav1.Time `json:"backupsObserved,omitempty"`
+}
+
+// PGBackRestRepoMigrationStatus contains information about the state of a repository migration.
+type PGBackRestRepoMigrationStatus struct {
+
+	// The name of the repository being replaced
+	// +kubebuilder:validation:Required
+	From string `json:"from"`
+
+	// The name of the repository replacing it
+	// +kubebuilder:validation:Required
+	To string `json:"to"`
+
+	// Specifies whether or not a full backup has completed in the new repository.
+	// The old repository can be removed once the retention of the new repository
+	// covers the period needed for recovery.
+	// +kubebuilder:validation:Required
+	Finished bool `json:"finished"`
+
+	// Represents the time the full backup in the new repository completed.
+	// It is represented in RFC3339 form and is in UTC.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 }
 
 // PGBackRestRepo represents a pgBackRest repository.  Only one of its members may be specified.
@@ -335,9 +489,52 @@ type PGBackRestRepo struct {
 	// +optional
 	S3 *RepoS3 `json:"s3,omitempty"`
 
+	// Represents a pgBackRest repository on a server that is reachable using SFTP
+	// +optional
+	SFTP *RepoSFTP `json:"sftp,omitempty"`
+
 	// Represents a pgBackRest repository that is created using a PersistentVolumeClaim
 	// +optional
 	Volume *RepoPVC `json:"volume,omitempty"`
+
+	// Defines how pgBackRest connects to Azure, GCS, or S3 storage. This is
+	// ignored for volume repositories.
+	// +optional
+	Connection *RepoConnection `json:"connection,omitempty"`
+}
+
+// RepoConnection defines how pgBackRest connects to the storage of a repository
+type RepoConnection struct {
+	// A key in a ConfigMap containing PEM-encoded certificate authorities that
+	// pgBackRest uses to verify the storage endpoint or proxy.
+	// +optional
+	CABundle *corev1.ConfigMapKeySelector `json:"caBundle,omitempty"`
+
+	// Sends storage requests to another host, such as a gateway that forwards
+	// them to the storage endpoint. pgBackRest does not use HTTP CONNECT proxies,
+	// so the environment variables HTTP_PROXY and HTTPS_PROXY have no effect.
+	// More info: https://pgbackrest.org/configuration.html#section-repository/option-repo-storage-host
+	// +optional
+	Proxy *RepoProxy `json:"proxy,omitempty"`
+
+	// Whether or not pgBackRest verifies the TLS certificate of the storage
+	// endpoint or proxy. Defaults to true.
+	// +optional
+	VerifyTLS *bool `json:"verifyTLS,omitempty"`
+}
+
+// RepoProxy identifies a host that forwards requests to repository storage
+type RepoProxy struct {
+	// The hostname or IP address of the proxy
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Host string `json:"host"`
+
+	// The port of the proxy. Defaults to 443.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
 }
 
 // RepoHostStatus defines the status of a pgBackRest repository host
@@ -352,7 +549,9 @@ type RepoHostStatus struct {
 // RepoPVC represents a pgBackRest repository that is created using a PersistentVolumeClaim
 type RepoPVC struct {
 
-	// Defines a PersistentVolumeClaim spec used to create and/or bind a volume
+	// Defines a PersistentVolumeClaim spec used to create and/or bind a volume.
+	// The storage request of an existing volume can grow, when its StorageClass
+	// allows expansion, but never shrink. Its other fields do not change.
 	// +kubebuilder:validation:Required
 	VolumeClaimSpec corev1.PersistentVolumeClaimSpec `json:"volumeClaimSpec"`
 }
@@ -390,6 +589,38 @@ type RepoS3 struct {
 	Region string `json:"region"`
 }
 
+// RepoSFTP represents a pgBackRest repository on a server that is reachable using SFTP
+type RepoSFTP struct {
+
+	// The hostname or IP address of the SFTP server
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Host string `json:"host"`
+
+	// The port of the SFTP server. Defaults to 22.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
+
+	// The user pgBackRest logs in as
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	User string `json:"user"`
+
+	// The name of a Secret containing the private key pgBackRest logs in with.
+	// The key is read from "ssh-privatekey", as in Secrets of type "kubernetes.io/ssh-auth".
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	KeyPairSecret string `json:"keyPairSecret"`
+
+	// A key in a Secret containing the host keys of the SFTP server in the
+	// format of an OpenSSH known_hosts file. pgBackRest does not connect to
+	// a server whose host key is not listed.
+	// +kubebuilder:validation:Required
+	KnownHosts corev1.SecretKeySelector `json:"knownHosts"`
+}
+
 // RepoStatus the status of a pgBackRest repository
 type RepoStatus struct {
 
@@ -418,6 +649,21 @@ type RepoStatus struct {
 	// commands accordingly.
 	// +optional
 	RepoOptionsHash string `json:"repoOptionsHash,omitempty"`
+
+	// The WAL file at which each full backup in the repository starts, oldest
+	// first, as last read from pgBackRest.
+	// +optional
+	FullBackupWAL []string `json:"fullBackupWAL,omitempty"`
+
+	// The name pgBackRest uses for the most recent backup in the repository,
+	// as last read from pgBackRest.
+	// +optional
+	LatestBackupLabel string `json:"latestBackupLabel,omitempty"`
+
+	// When the most recent backup in the repository finished, as last read
+	// from pgBackRest.
+	// +optional
+	LatestBackupCompletion *metav1.Time `json:"latestBackupCompletion,omitempty"`
 }
 
 // PGBackRestDataSource defines a pgBackRest configuration specifically for restoring from cloud-based data source
@@ -450,6 +696,19 @@ type PGBackRestDataSource struct {
 	// +optional
 	Options []string `json:"options,omitempty"`
 
+	// Restores some databases rather than all of them.
+	// More info: https://pgbackrest.org/command.html#command-restore/category-command/option-db-include
+	// +optional
+	Databases *PGBackRestRestoreDatabases `json:"databases,omitempty"`
+
+	// The name of a restore point at which recovery stops, such as one created
+	// by the restore-point annotation. This cannot be combined with the
+	// "--type" or "--target" options.
+	// More info: https://pgbackrest.org/command.html#command-restore/category-command/option-type
+	// +optional
+	// +kubebuilder:validation:MaxLength=63
+	RestorePoint string `json:"restorePoint,omitempty"`
+
 	// Resource requirements for the pgBackRest restore Job.
 	// +optional
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
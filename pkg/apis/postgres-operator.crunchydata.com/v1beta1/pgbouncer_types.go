@@ -17,6 +17,7 @@ package v1beta1
 
 import (
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
@@ -78,6 +79,14 @@ type PGBouncerPodSpec struct {
 	// +optional
 	Containers []corev1.Container `json:"containers,omitempty"`
 
+	// Pause PgBouncer around operations that interrupt connections to the
+	// primary so that clients wait rather than fail. Active transactions may
+	// finish before the operation starts, and PgBouncer resumes once the
+	// operation is done.
+	// More info: https://www.pgbouncer.org/usage.html#pause-db
+	// +optional
+	Cutover *PGBouncerCutoverSpec `json:"cutover,omitempty"`
+
 	// A secret projection containing a certificate and key with which to encrypt
 	// connections to PgBouncer. The "tls.crt", "tls.key", and "ca.crt" paths must
 	// be PEM-encoded certificates and keys. Changing this value causes PgBouncer
@@ -112,6 +121,15 @@ type PGBouncerPodSpec struct {
 	// +kubebuilder:validation:Minimum=0
 	Replicas *int32 `json:"replicas,omitempty"`
 
+	// Whether to run PgBouncer while the cluster is a standby. PgBouncer then
+	// serves read-only connections through the standby leader. It logs in as
+	// the PgBouncer user replicated from the primary cluster, so the
+	// "pgbouncer-password" and "pgbouncer-verifier" of the PgBouncer Secret
+	// must match those of the primary cluster. When false, PgBouncer is
+	// scaled to zero while the cluster is a standby.
+	// +optional
+	ServeStandby *bool `json:"serveStandby,omitempty"`
+
 	// Minimum number of pods that should be available at a time.
 	// Defaults to one when the replicas field is greater than one.
 	// +optional
@@ -144,6 +162,42 @@ type PGBouncerPodSpec struct {
 	TopologySpreadConstraints []corev1.TopologySpreadConstraint `json:"topologySpreadConstraints,omitempty"`
 }
 
+// PGBouncerCutoverSpec defines how PgBouncer drains connections around
+// operations that interrupt the primary.
+type PGBouncerCutoverSpec struct {
+
+	// The operations around which PgBouncer pauses: "Restart" of the primary
+	// by the trigger-restart annotation or to apply PostgreSQL parameters,
+	// "Restore" in place, and "Switchover".
+	// +listType=set
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:items:Enum={Restart,Restore,Switchover}
+	Operations []string `json:"operations"`
+
+	// Number of seconds to wait for active transactions to finish once
+	// PgBouncer is paused.
+	// +optional
+	// +kubebuilder:default=30
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=3600
+	DrainTimeoutSeconds *int32 `json:"drainTimeoutSeconds,omitempty"`
+
+	// What to do when transactions are still active after drainTimeoutSeconds.
+	// "Abort" resumes PgBouncer and attempts the operation again later.
+	// "Proceed" performs the operation anyway.
+	// +optional
+	// +kubebuilder:default=Abort
+	// +kubebuilder:validation:Enum={Abort,Proceed}
+	OnTimeout string `json:"onTimeout,omitempty"`
+}
+
+// The operations around which PgBouncer can pause.
+const (
+	PGBouncerCutoverRestart    = "Restart"
+	PGBouncerCutoverRestore    = "Restore"
+	PGBouncerCutoverSwitchover = "Switchover"
+)
+
 // PGBouncerSidecars defines the configuration for pgBouncer sidecar containers
 type PGBouncerSidecars struct {
 	// Defines the configuration for the pgBouncer config sidecar container
@@ -167,6 +221,10 @@ func (s *PGBouncerPodSpec) Default() {
 
 type PGBouncerPodStatus struct {
 
+	// The operation for which PgBouncer is paused, if any.
+	// +optional
+	Cutover *PGBouncerCutoverStatus `json:"cutover,omitempty"`
+
 	// Identifies the revision of PgBouncer assets that have been installed into
 	// PostgreSQL.
 	PostgreSQLRevision string `json:"postgresRevision,omitempty"`
@@ -177,3 +235,14 @@ type PGBouncerPodStatus struct {
 	// Total number of non-terminated pods.
 	Replicas int32 `json:"replicas,omitempty"`
 }
+
+type PGBouncerCutoverStatus struct {
+
+	// The operation for which PgBouncer is paused.
+	// +optional
+	Operation string `json:"operation,omitempty"`
+
+	// When PgBouncer was paused.
+	// +optional
+	PauseTime *metav1.Time `json:"pauseTime,omitempty"`
+}
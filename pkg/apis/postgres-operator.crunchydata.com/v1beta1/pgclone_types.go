@@ -0,0 +1,120 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PGCloneSpec defines the desired state of PGClone
+type PGCloneSpec struct {
+
+	// The name of the PostgresCluster whose backups are restored.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	SourceClusterName string `json:"sourceClusterName"`
+
+	// The pgBackRest repository of the source cluster to restore from.
+	// +required
+	// +kubebuilder:validation:Pattern=^repo[1-4]
+	RepoName string `json:"repoName"`
+
+	// The name of the PostgresCluster that is deleted and created again from
+	// the latest backup of the source cluster. An existing PostgresCluster of
+	// this name that is not controlled by this PGClone is never deleted.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	TargetClusterName string `json:"targetClusterName"`
+
+	// The Cron schedule of the refresh. Follows the standard Cron schedule
+	// syntax:
+	// https://k8s.io/docs/concepts/workloads/controllers/cron-jobs/#cron-schedule-syntax
+	// +required
+	// +kubebuilder:validation:MinLength=6
+	Schedule string `json:"schedule"`
+
+	// Whether or not scheduled refreshes are suspended. A refresh that has
+	// already started will finish.
+	// +optional
+	Suspend *bool `json:"suspend,omitempty"`
+
+	// Scripts that mask or anonymize data in the target cluster before
+	// applications can connect.
+	// +optional
+	Masking *DataMaskingSpec `json:"masking,omitempty"`
+
+	// The number of Pods in each instance set of the target cluster. When not
+	// set, the target has as many as the source.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Compute resources of the PostgreSQL container in each instance set of
+	// the target cluster. When not set, the target uses those of the source.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
+// PGCloneStatus defines the observed state of PGClone
+type PGCloneStatus struct {
+	// conditions represent the observations of PGClone's current state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// observedGeneration represents the .metadata.generation on which the status was based.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// The name of the scheduled Job that started the most recent refresh.
+	// +optional
+	LastScheduledJob string `json:"lastScheduledJob,omitempty"`
+
+	// When the target cluster was most recently created again.
+	// +optional
+	LastRefreshTime *metav1.Time `json:"lastRefreshTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// PGClone is the Schema for the pgclones API. It deletes and creates a target
+// PostgresCluster from the latest backup of a source PostgresCluster on a
+// schedule, such as when refreshing a staging environment. The target takes
+// no scheduled backups and keeps only the pgBackRest repositories of the
+// source that are on volumes.
+type PGClone struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PGCloneSpec   `json:"spec,omitempty"`
+	Status PGCloneStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PGCloneList contains a list of PGClone
+type PGCloneList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PGClone `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PGClone{}, &PGCloneList{})
+}
@@ -25,6 +25,22 @@ type PGMonitorSpec struct {
 
 type ExporterSpec struct {
 
+	// How the exporter authenticates to PostgreSQL. "Password" uses a generated
+	// password stored in the monitoring Secret. "Certificate" connects over TLS
+	// using a client certificate issued by the operator; the monitoring user
+	// then has no password at all. Defaults to "Password".
+	// Changing this value causes PostgreSQL and the exporter to restart.
+	// +optional
+	// +kubebuilder:validation:Enum={Password,Certificate}
+	Authentication string `json:"authentication,omitempty"`
+
+	// Whether or not to export the number of active client connections and
+	// the replication lag of each instance as "ccp_autoscaling" metrics.
+	// These are meant for scalers, such as KEDA or a Prometheus adapter of
+	// the external metrics API, that add replicas when demand is high.
+	// +optional
+	AutoscalingMetrics bool `json:"autoscalingMetrics,omitempty"`
+
 	// Projected volumes containing custom PostgreSQL Exporter configuration.  Currently supports
 	// the customization of PostgreSQL Exporter queries. If a "queries.yml" file is detected in
 	// any volume projected using this field, it will be loaded using the "extend.query-path" flag:
@@ -47,4 +63,12 @@ type ExporterSpec struct {
 	// More info: https://kubernetes.io/docs/concepts/configuration/manage-resources-containers
 	// +optional
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Whether or not to measure the CPU time and filesystem reads and writes
+	// of each query using pg_stat_kcache and export them as "ccp_pg_stat_kcache"
+	// metrics. The PostgreSQL image must include the extension.
+	// Changing this value causes PostgreSQL to restart.
+	// More info: https://github.com/powa-team/pg_stat_kcache
+	// +optional
+	StatKCache bool `json:"statKCache,omitempty"`
 }
@@ -0,0 +1,134 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PGSQLRequestSpec defines the desired state of PGSQLRequest
+type PGSQLRequestSpec struct {
+
+	// The name of the PostgresCluster in which to execute the statement.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	PostgresClusterName string `json:"postgresClusterName"`
+
+	// The database in which to execute the statement.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Database string `json:"database"`
+
+	// The kind of statement. The statement must begin with the command of its
+	// class: "Analyze" is ANALYZE, "CreateIndexConcurrently" is CREATE INDEX
+	// CONCURRENTLY or CREATE UNIQUE INDEX CONCURRENTLY, "DropIndexConcurrently"
+	// is DROP INDEX CONCURRENTLY, "ReindexConcurrently" is REINDEX with the
+	// CONCURRENTLY option, and "Vacuum" is VACUUM without the FULL option.
+	// +required
+	// +kubebuilder:validation:Enum={Analyze,CreateIndexConcurrently,DropIndexConcurrently,ReindexConcurrently,Vacuum}
+	Class string `json:"class"`
+
+	// A single SQL statement of class. Comments, quoted semicolons, and more
+	// than one statement are rejected.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=4096
+	Statement string `json:"statement"`
+
+	// The PostgreSQL user that executes the statement. It must be one of the
+	// users of the cluster, and it must not be a superuser. The statement is
+	// executed with the privileges of this user only.
+	// +required
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:Type=string
+	User PostgresIdentifier `json:"user"`
+
+	// The most time the statement may wait for a lock before it fails.
+	// Defaults to 60 seconds.
+	// More info: https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-LOCK-TIMEOUT
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	LockTimeoutSeconds *int32 `json:"lockTimeoutSeconds,omitempty"`
+}
+
+// PGSQLRequestStatus defines the observed state of PGSQLRequest
+type PGSQLRequestStatus struct {
+	// conditions represent the observations of PGSQLRequest's current state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// observedGeneration represents the .metadata.generation on which the status was based.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// The statement exactly as it was executed.
+	// +optional
+	Statement string `json:"statement,omitempty"`
+
+	// The SHA-256 of the statement as it was executed.
+	// +optional
+	StatementHash string `json:"statementHash,omitempty"`
+
+	// The Pod in which the statement was executed.
+	// +optional
+	Pod string `json:"pod,omitempty"`
+
+	// When execution of the statement started. A statement is executed at
+	// most once; it is not executed again when the operator restarts.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// When execution of the statement finished.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// The end of the output of PostgreSQL, such as a command tag or an error.
+	// +optional
+	Output string `json:"output,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// PGSQLRequest is the Schema for the pgsqlrequests API. It executes one SQL
+// statement of an approved class, such as CREATE INDEX CONCURRENTLY or ANALYZE,
+// on the primary of a PostgresCluster. The statement is executed only after
+// the "postgres-operator.crunchydata.com/approve-sql-request" annotation is
+// set to its SHA-256. The statement is executed at most once, and what was
+// executed and what happened are recorded in its status, in events, and in
+// the operator log.
+type PGSQLRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PGSQLRequestSpec   `json:"spec,omitempty"`
+	Status PGSQLRequestStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PGSQLRequestList contains a list of PGSQLRequest
+type PGSQLRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PGSQLRequest `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PGSQLRequest{}, &PGSQLRequestList{})
+}
@@ -0,0 +1,82 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PGSupportBundleSpec defines the desired state of PGSupportBundle
+type PGSupportBundleSpec struct {
+
+	// The name of the PostgresCluster to collect information about.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	PostgresClusterName string `json:"postgresClusterName"`
+
+	// The number of lines to collect from the end of each log. Defaults to 500.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	LogLines *int32 `json:"logLines,omitempty"`
+}
+
+// PGSupportBundleStatus defines the observed state of PGSupportBundle
+type PGSupportBundleStatus struct {
+	// conditions represent the observations of PGSupportBundle's current state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// observedGeneration represents the .metadata.generation on which the status was based.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// The name of the ConfigMap that contains the archive in its "bundle.tar.gz" key.
+	// +optional
+	ConfigMap string `json:"configMap,omitempty"`
+
+	// When the information was collected.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// PGSupportBundle is the Schema for the pgsupportbundles API. It collects
+// the resources, state, and recent logs of a PostgresCluster into a single
+// archive. The contents of Secrets are not collected.
+type PGSupportBundle struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PGSupportBundleSpec   `json:"spec,omitempty"`
+	Status PGSupportBundleStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PGSupportBundleList contains a list of PGSupportBundle
+type PGSupportBundleList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PGSupportBundle `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PGSupportBundle{}, &PGSupportBundleList{})
+}
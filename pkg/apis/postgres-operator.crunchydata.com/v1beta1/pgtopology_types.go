@@ -0,0 +1,126 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PGTopologySpec defines the desired state of PGTopology
+type PGTopologySpec struct {
+
+	// The name of the member that accepts writes. Every other member is a
+	// standby that follows it. Changing this promotes the named member only
+	// after every other member has stopped acting as primary. To promote
+	// without waiting for a member that is lost, remove it from members.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Primary string `json:"primary"`
+
+	// The PostgresClusters in this topology. Each must be in the same
+	// namespace as the PGTopology.
+	// +required
+	// +kubebuilder:validation:MinItems=2
+	// +listType=map
+	// +listMapKey=name
+	Members []PGTopologyMember `json:"members"`
+
+	// The name of a pgBackRest repository that every member defines with the
+	// same storage. Standbys apply WAL files from it, and only the primary
+	// writes to it.
+	// +optional
+	// +kubebuilder:validation:Pattern=^repo[1-4]
+	RepoName string `json:"repoName,omitempty"`
+
+	// Whether or not standbys stream WAL from the primary Service of the
+	// primary. Every member must trust the same certificate authority.
+	// At least one of repoName or streaming is required.
+	// +optional
+	Streaming bool `json:"streaming,omitempty"`
+}
+
+// PGTopologyMember is a PostgresCluster in a topology.
+type PGTopologyMember struct {
+	// The name of the PostgresCluster.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// PGTopologyStatus defines the observed state of PGTopology
+type PGTopologyStatus struct {
+	// conditions represent the observations of PGTopology's current state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// observedGeneration represents the .metadata.generation on which the status was based.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// The observed state of each member.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Members []PGTopologyMemberStatus `json:"members,omitempty"`
+}
+
+// PGTopologyMemberStatus is the observed state of a member of a topology.
+type PGTopologyMemberStatus struct {
+	// The name of the PostgresCluster.
+	// +required
+	Name string `json:"name"`
+
+	// The role of the leader of the member: "Primary", "Standby", or "Unknown".
+	// +optional
+	Role string `json:"role,omitempty"`
+
+	// The WAL position of the leader of the member, in bytes.
+	// +optional
+	WALPosition *int64 `json:"walPosition,omitempty"`
+
+	// How far the member is behind the primary, in bytes of WAL.
+	// +optional
+	LagBytes *int64 `json:"lagBytes,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// PGTopology is the Schema for the pgtopologies API. It describes a primary
+// PostgresCluster and its standby PostgresClusters, coordinates promotion
+// between them, and reports replication health in one place.
+type PGTopology struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PGTopologySpec   `json:"spec,omitempty"`
+	Status PGTopologyStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PGTopologyList contains a list of PGTopology
+type PGTopologyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PGTopology `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PGTopology{}, &PGTopologyList{})
+}
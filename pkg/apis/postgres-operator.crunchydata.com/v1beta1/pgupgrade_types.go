@@ -59,20 +59,65 @@ type PGUpgradeSpec struct {
 	// The major version of PostgreSQL before the upgrade.
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:Minimum=10
-	// +kubebuilder:validation:Maximum=16
+	// +kubebuilder:validation:Maximum=17
 	FromPostgresVersion int `json:"fromPostgresVersion"`
 
+	// Major versions of PostgreSQL to stop at, in order, between
+	// fromPostgresVersion and toPostgresVersion. Each step runs pg_upgrade and
+	// the data directory it produces is validated before the next step begins.
+	// When omitted, pg_upgrade upgrades directly to toPostgresVersion. The
+	// image must have every version.
+	// +kubebuilder:validation:MaxItems=5
+	// +listType=atomic
+	// +optional
+	IntermediatePostgresVersions []int `json:"intermediatePostgresVersions,omitempty"`
+
 	// TODO(benjaminjb): define webhook validation to make sure
 	// `fromPostgresVersion` is below `toPostgresVersion`
 	// or leverage other validation rules, such as the Common Expression Language
 	// rules currently in alpha as of Kubernetes 1.23
 
-	// The major version of PostgreSQL to be upgraded to.
+	// The major version of PostgreSQL to be upgraded to. This can be more
+	// than one major version above fromPostgresVersion; see also
+	// intermediatePostgresVersions.
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:Minimum=10
-	// +kubebuilder:validation:Maximum=16
+	// +kubebuilder:validation:Maximum=17
 	ToPostgresVersion int `json:"toPostgresVersion"`
 
+	// How to upgrade. "PGUpgrade" runs pg_upgrade while the cluster is shutdown.
+	// "LogicalReplication" copies data into a new cluster of the new version
+	// while the old one keeps running, then switches over.
+	// +kubebuilder:validation:Enum={PGUpgrade,LogicalReplication}
+	// +kubebuilder:default=PGUpgrade
+	// +optional
+	Method string `json:"method,omitempty"`
+
+	// Settings of the "LogicalReplication" method.
+	// +optional
+	LogicalReplication *PGUpgradeLogicalReplicationSpec `json:"logicalReplication,omitempty"`
+
+	// Whether or not to stop after the preflight checks. While the cluster is
+	// running, its databases are checked for extensions and data types that the
+	// new version lacks, and downtime is estimated. Once the cluster is shut
+	// down, pg_upgrade runs in its "--check" mode. Neither changes any data.
+	// Reports are written to status and to a ConfigMap.
+	// +optional
+	PreflightOnly bool `json:"preflightOnly,omitempty"`
+
+	// Whether or not to return the PostgresCluster to the major version it had
+	// before the upgrade. The upgrade keeps the old data directory, which can be
+	// used again only until the upgraded cluster starts. With the
+	// "LogicalReplication" method, the PgBouncer databases of the old cluster
+	// are put back instead. Once this is set, the upgrade cannot resume; create
+	// another PGUpgrade to try again. See status.rollback for what would be lost.
+	// +optional
+	Rollback bool `json:"rollback,omitempty"`
+
+	// Work to do once the cluster is running the new version of PostgreSQL.
+	// +optional
+	PostUpgrade *PGUpgradePostUpgradeSpec `json:"postUpgrade,omitempty"`
+
 	// The image name to use for PostgreSQL containers after upgrade.
 	// When omitted, the value comes from an operator environment variable.
 	// +optional
@@ -102,6 +147,41 @@ type PGUpgradeSpec struct {
 	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
 }
 
+// PGUpgradeLogicalReplicationSpec defines an upgrade that replicates into a
+// new PostgresCluster. The new cluster is created from the specification of the
+// old one. Both clusters are annotated to allow the upgrade while it runs.
+type PGUpgradeLogicalReplicationSpec struct {
+	// The name of the new PostgresCluster. Defaults to the name of the old
+	// cluster followed by the new major version, e.g. "hippo-pg16".
+	// +optional
+	// +kubebuilder:validation:MaxLength=63
+	TargetClusterName string `json:"targetClusterName,omitempty"`
+
+	// Whether or not to switch over to the new cluster once its tables are
+	// caught up. The PgBouncer of the old cluster is paused, the old cluster
+	// becomes read-only, and remaining changes and sequences are copied. Then
+	// only the PgBouncer databases that connect to the old cluster are pointed
+	// at the new cluster, and PgBouncer is resumed.
+	// +optional
+	Cutover bool `json:"cutover,omitempty"`
+}
+
+// PGUpgradePostUpgradeSpec defines the work done after an upgrade. One Job
+// processes every database while the PostgresCluster is annotated to allow the
+// upgrade.
+type PGUpgradePostUpgradeSpec struct {
+	// Whether or not to rebuild planner statistics with vacuumdb --analyze-in-stages.
+	// pg_upgrade does not keep statistics, so queries can be slow until this finishes.
+	// Defaults to true.
+	// +optional
+	Analyze *bool `json:"analyze,omitempty"`
+
+	// Whether or not to update extensions to the default version available
+	// in the new version of PostgreSQL. Defaults to true.
+	// +optional
+	UpdateExtensions *bool `json:"updateExtensions,omitempty"`
+}
+
 // PGUpgradeStatus defines the observed state of PGUpgrade
 type PGUpgradeStatus struct {
 	// conditions represent the observations of PGUpgrade's current state.
@@ -114,6 +194,83 @@ type PGUpgradeStatus struct {
 	// +optional
 	// +kubebuilder:validation:Minimum=0
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// The outcome of the checks that run before anything is changed.
+	// +optional
+	Preflight *PGUpgradePreflightStatus `json:"preflight,omitempty"`
+
+	// Whether or not the upgrade can be rolled back and what would be lost.
+	// +optional
+	Rollback *PGUpgradeRollbackStatus `json:"rollback,omitempty"`
+
+	// The progress of the "LogicalReplication" method.
+	// +optional
+	LogicalReplication *PGUpgradeLogicalReplicationStatus `json:"logicalReplication,omitempty"`
+}
+
+// PGUpgradeLogicalReplicationStatus is the progress of an upgrade that
+// replicates into a new PostgresCluster.
+type PGUpgradeLogicalReplicationStatus struct {
+	// The name of the new PostgresCluster.
+	// +optional
+	TargetClusterName string `json:"targetClusterName,omitempty"`
+
+	// One of "Provisioning", "Replicating", "CuttingOver", or "Completed".
+	// +optional
+	Phase string `json:"phase,omitempty"`
+
+	// The number of tables still being copied for the first time.
+	// +optional
+	PendingTables *int32 `json:"pendingTables,omitempty"`
+
+	// How far, in bytes of WAL, the new cluster is behind the old one.
+	// +optional
+	LagBytes *int64 `json:"lagBytes,omitempty"`
+
+	// When the lag was last measured.
+	// +optional
+	LastLagCheckTime *metav1.Time `json:"lastLagCheckTime,omitempty"`
+
+	// Whether or not the PgBouncer of the old cluster sends connections to the
+	// new cluster.
+	// +optional
+	PGBouncerRerouted bool `json:"pgBouncerRerouted,omitempty"`
+
+	// The PgBouncer databases of the old cluster before cutover. They are
+	// put back when the upgrade is rolled back.
+	// +optional
+	PreviousPGBouncerDatabases map[string]string `json:"previousPGBouncerDatabases,omitempty"`
+}
+
+// PGUpgradeRollbackStatus describes the boundary of a rollback.
+type PGUpgradeRollbackStatus struct {
+	// Whether or not the old data directory can be used again. This becomes
+	// false once the upgraded cluster starts and writes to the files it shares
+	// with the old data directory.
+	// +optional
+	Available bool `json:"available"`
+
+	// A human-readable description of the data lost by rolling back.
+	// +optional
+	DataLoss string `json:"dataLoss,omitempty"`
+}
+
+// PGUpgradePreflightStatus is the outcome of the checks that run before an upgrade.
+type PGUpgradePreflightStatus struct {
+	// Whether or not the finished checks found the cluster compatible with
+	// the new version of PostgreSQL.
+	// +optional
+	Compatible bool `json:"compatible"`
+
+	// The name of the ConfigMap that contains the full report.
+	// +optional
+	ConfigMap string `json:"configMap,omitempty"`
+
+	// A human-readable report of the checks, including an estimate of downtime.
+	// The upgrade uses hard links, so downtime grows with the number of
+	// relations more than with their size.
+	// +optional
+	Report string `json:"report,omitempty"`
 }
 
 //+kubebuilder:object:root=true
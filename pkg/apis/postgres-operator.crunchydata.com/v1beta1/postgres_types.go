@@ -42,7 +42,8 @@ type PostgresUserSpec struct {
 
 	// This value goes into the name of a corev1.Secret and a label value, so
 	// it must match both IsDNS1123Subdomain and IsValidLabelValue. The pattern
-	// below is IsDNS1123Subdomain without any dots, U+002E.
+	// below is IsDNS1123Subdomain without any dots, U+002E. The name of the
+	// operator, "postgres-operator", is reserved by "build/crd" validation.
 
 	// The name of this PostgreSQL user. The value may contain only lowercase
 	// letters, numbers, and hyphen so that it fits into Kubernetes metadata.
@@ -50,6 +51,15 @@ type PostgresUserSpec struct {
 	// +kubebuilder:validation:Type=string
 	Name PostgresIdentifier `json:"name"`
 
+	// Whether or not this user authenticates with a client certificate rather
+	// than a password. When true, the certificate, its key, and the cluster
+	// certificate authority are written to a Secret named
+	// "<cluster>-pgusercert-<user>", and TLS connections as this user must
+	// present that certificate. PgBouncer cannot connect as this user.
+	// More info: https://www.postgresql.org/docs/current/auth-cert.html
+	// +optional
+	CertAuth bool `json:"certAuth,omitempty"`
+
 	// Databases to which this user can connect and create objects. Removing a
 	// database from this list does NOT revoke access. This field is ignored for
 	// the "postgres" user.
@@ -68,3 +78,108 @@ type PostgresUserSpec struct {
 	// +optional
 	Password *PostgresPasswordSpec `json:"password,omitempty"`
 }
+
+// PostgresUserSecretStatus identifies the current version of the immutable
+// Secret of a PostgreSQL user.
+type PostgresUserSecretStatus struct {
+	// The name of the PostgreSQL user.
+	// +required
+	Name PostgresIdentifier `json:"name"`
+
+	// The name of the Secret containing the current password and connection
+	// information of the user.
+	// +required
+	Secret string `json:"secret"`
+
+	// The version of the Secret. It increases each time the contents of the
+	// Secret change.
+	// +required
+	Version int32 `json:"version"`
+}
+
+// PostgresDatabaseSpec defines a database inside PostgreSQL.
+type PostgresDatabaseSpec struct {
+	// The name of the database. It is created when it does not exist.
+	// +required
+	Name PostgresIdentifier `json:"name"`
+
+	// Remote PostgreSQL servers that this database reaches through the
+	// postgres_fdw extension.
+	// More info: https://www.postgresql.org/docs/current/postgres-fdw.html
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	ForeignServers []PostgresForeignServerSpec `json:"foreignServers,omitempty"`
+}
+
+// PostgresForeignServerSpec defines a postgres_fdw foreign server, the users
+// that can connect to it, and the schemas imported from it.
+type PostgresForeignServerSpec struct {
+	// The name of the foreign server inside PostgreSQL.
+	// +required
+	Name PostgresIdentifier `json:"name"`
+
+	// The host name or IP address of the remote server.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Host string `json:"host"`
+
+	// The port of the remote server.
+	// +optional
+	// +kubebuilder:default=5432
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
+
+	// The name of the database on the remote server.
+	// +required
+	DBName PostgresIdentifier `json:"dbname"`
+
+	// Other options of the foreign server, such as "sslmode" or "fetch_size".
+	// Options that are removed from this map are removed from the server.
+	// More info: https://www.postgresql.org/docs/current/postgres-fdw.html#POSTGRES-FDW-OPTIONS
+	// +optional
+	Options map[string]string `json:"options,omitempty"`
+
+	// PostgreSQL users that connect to the remote server. Mappings that are
+	// removed from this list are dropped.
+	// +listType=map
+	// +listMapKey=user
+	// +optional
+	UserMappings []PostgresUserMappingSpec `json:"userMappings,omitempty"`
+
+	// Remote schemas from which foreign tables are imported. Tables are
+	// imported by the first user mapping, once, when the local schema has no
+	// foreign tables from this server.
+	// +listType=map
+	// +listMapKey=localSchema
+	// +optional
+	ImportSchemas []PostgresForeignSchemaSpec `json:"importSchemas,omitempty"`
+}
+
+// PostgresUserMappingSpec maps a PostgreSQL user to credentials on a foreign
+// server.
+type PostgresUserMappingSpec struct {
+	// The local PostgreSQL user. It is granted USAGE on the foreign server.
+	// +required
+	User PostgresIdentifier `json:"user"`
+
+	// The name of a Secret in the namespace of the cluster that has "user"
+	// and "password" keys for the remote server. Changes to the Secret are
+	// applied the next time the cluster is reconciled.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
+}
+
+// PostgresForeignSchemaSpec defines a remote schema to import as foreign tables.
+type PostgresForeignSchemaSpec struct {
+	// The schema on the remote server.
+	// +required
+	RemoteSchema PostgresIdentifier `json:"remoteSchema"`
+
+	// The local schema in which to create foreign tables. It is created, and
+	// owned by the user of the first mapping, when it does not exist.
+	// +required
+	LocalSchema PostgresIdentifier `json:"localSchema"`
+}
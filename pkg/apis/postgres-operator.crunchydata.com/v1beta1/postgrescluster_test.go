@@ -54,13 +54,12 @@ spec:
     pgbackrest:
       repos: null
   config: {}
-  instances: null
   patroni:
     leaderLeaseDurationSeconds: 30
+    pausedTimeoutSeconds: 3600
     port: 8008
     syncPeriodSeconds: 10
   port: 5432
-  postgresVersion: 0
 status:
   monitoring: {}
   patroni: {}
@@ -95,10 +94,10 @@ spec:
     resources: {}
   patroni:
     leaderLeaseDurationSeconds: 30
+    pausedTimeoutSeconds: 3600
     port: 8008
     syncPeriodSeconds: 10
   port: 5432
-  postgresVersion: 0
 status:
   monitoring: {}
   patroni: {}
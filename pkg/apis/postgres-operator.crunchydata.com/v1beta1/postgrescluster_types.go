@@ -32,8 +32,8 @@ type PostgresClusterSpec struct {
 	// +optional
 	DataSource *DataSource `json:"dataSource,omitempty"`
 
-	// PostgreSQL backup configuration
-	// +kubebuilder:validation:Required
+	// PostgreSQL backup configuration. Required unless it comes from a template.
+	// +optional
 	Backups Backups `json:"backups"`
 
 	// The secret containing the Certificates and Keys to encrypt PostgreSQL
@@ -57,11 +57,53 @@ type PostgresClusterSpec struct {
 	// +optional
 	CustomReplicationClientTLSSecret *corev1.SecretProjection `json:"customReplicationTLSSecret,omitempty"`
 
+	// Configuration of PostgreSQL data page checksums.
+	// +optional
+	DataChecksums *DataChecksumsSpec `json:"dataChecksums,omitempty"`
+
+	// Options for initializing the PostgreSQL data directory of a new cluster.
+	// These take effect only when the cluster is created; changing them later
+	// has no effect. Data page checksums are configured by dataChecksums.
+	// More info: https://www.postgresql.org/docs/current/app-initdb.html
+	// +optional
+	Initdb *InitdbSpec `json:"initdb,omitempty"`
+
+	// The IANA name of the time zone of PostgreSQL, its logs, scheduled Jobs,
+	// pgAdmin, and PgBouncer, such as "America/New_York". Defaults to UTC.
+	// Kubernetes 1.25 or later is required for the schedules of CronJobs.
+	// More info: https://docs.k8s.io/concepts/workloads/controllers/cron-jobs/#time-zones
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	TimeZone string `json:"timezone,omitempty"`
+
 	// DatabaseInitSQL defines a ConfigMap containing custom SQL that will
 	// be run after the cluster is initialized. This ConfigMap must be in the same
 	// namespace as the cluster.
 	// +optional
 	DatabaseInitSQL *DatabaseInitSQL `json:"databaseInitSQL,omitempty"`
+
+	// Databases to create inside PostgreSQL and the foreign servers they can
+	// reach. Removing a database or foreign server from this list does NOT
+	// drop it.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Databases []PostgresDatabaseSpec `json:"databases,omitempty"`
+
+	// PostgresClusters that must reach some state before this cluster is
+	// created. Once they have, they are not checked again.
+	// +optional
+	// +listType=atomic
+	DependsOn []Dependency `json:"dependsOn,omitempty"`
+
+	// What happens to PersistentVolumeClaims when the PostgresCluster is
+	// deleted. "Delete" removes them along with the cluster. "Retain" keeps
+	// them so that a PostgresCluster created later with the same name adopts
+	// and reuses them. Defaults to "Delete".
+	// +optional
+	// +kubebuilder:validation:Enum={Delete,Retain}
+	DeletionPolicy string `json:"deletionPolicy,omitempty"`
+
 	// Whether or not the PostgreSQL cluster should use the defined default
 	// scheduling constraints. If the field is unset or false, the default
 	// scheduling constraints will be used in addition to any custom constraints
@@ -69,6 +111,13 @@ type PostgresClusterSpec struct {
 	// +optional
 	DisableDefaultPodScheduling *bool `json:"disableDefaultPodScheduling,omitempty"`
 
+	// Feature gates to enable or disable for this cluster, overriding those of
+	// the operator. Only AppendCustomQueries, InstanceSidecars,
+	// PGBouncerSidecars, and TablespaceVolumes can be set here; other names
+	// are ignored.
+	// +optional
+	Features map[string]bool `json:"features,omitempty"`
+
 	// The image name to use for PostgreSQL containers. When omitted, the value
 	// comes from an operator environment variable. For standard PostgreSQL images,
 	// the format is RELATED_IMAGE_POSTGRES_{postgresVersion},
@@ -93,19 +142,61 @@ type PostgresClusterSpec struct {
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 
 	// Specifies one or more sets of PostgreSQL pods that replicate data for
-	// this cluster.
+	// this cluster. Required unless they come from a template.
 	// +listType=map
 	// +listMapKey=name
 	// +kubebuilder:validation:MinItems=1
+	// +optional
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=2
-	InstanceSets []PostgresInstanceSetSpec `json:"instances"`
+	InstanceSets []PostgresInstanceSetSpec `json:"instances,omitempty"`
+
+	// What happens to the volumes of instance sets that are renamed or removed.
+	// Delete removes them along with the StatefulSets, ConfigMaps, and
+	// certificates of those instances. Retain removes everything else but keeps
+	// the volumes and reports them in status. Defaults to Delete.
+	// +optional
+	// +kubebuilder:validation:Enum={Delete,Retain}
+	RemovedInstanceSetPolicy string `json:"removedInstanceSetPolicy,omitempty"`
+
+	// Scheduled synthetic transactions that check whether the cluster can be
+	// used: a heartbeat is written and read through PgBouncer, when enabled,
+	// or the primary, and replayed by a replica. The outcome is reported in
+	// the ClusterUsable condition.
+	// +optional
+	HealthProbes *HealthProbesSpec `json:"healthProbes,omitempty"`
+
+	// Scheduled checks of PostgreSQL tables and indexes for corruption using
+	// pg_amcheck. Requires PostgreSQL v14 or later.
+	// More info: https://www.postgresql.org/docs/current/app-pgamcheck.html
+	// +optional
+	IntegrityChecks *IntegrityChecksSpec `json:"integrityChecks,omitempty"`
+
+	// Scheduled rewrites of tables whose estimated bloat exceeds a threshold,
+	// during a maintenance window. Progress is reported in status and the
+	// outcome in the MaintenanceCompleted condition.
+	// +optional
+	Maintenance *MaintenanceSpec `json:"maintenance,omitempty"`
+
+	// Rebuilds indexes, without blocking reads or writes, when collation
+	// versions change, integrity checks fail, or PostgreSQL is upgraded from
+	// v11 or earlier. Progress is reported in the IndexesRebuilt condition.
+	// Requires PostgreSQL v12 or later.
+	// More info: https://www.postgresql.org/docs/current/sql-reindex.html
+	// +optional
+	Reindex *ReindexSpec `json:"reindex,omitempty"`
 
 	// Whether or not the PostgreSQL cluster is being deployed to an OpenShift
 	// environment. If the field is unset, the operator will automatically
-	// detect the environment.
+	// detect the environment from the namespace of the cluster.
 	// +optional
 	OpenShift *bool `json:"openshift,omitempty"`
 
+	// Tables partitioned and maintained by pg_partman. Requires PostgreSQL v14
+	// or later.
+	// More info: https://github.com/pgpartman/pg_partman
+	// +optional
+	Partitioning *PartitioningSpec `json:"partitioning,omitempty"`
+
 	// +optional
 	Patroni *PatroniSpec `json:"patroni,omitempty"`
 
@@ -114,18 +205,27 @@ type PostgresClusterSpec struct {
 	// +optional
 	Paused *bool `json:"paused,omitempty"`
 
+	// Whether or not PostgreSQL rejects writes by default. Every transaction
+	// is read-only unless its session sets default_transaction_read_only to
+	// off, including connections through PgBouncer. The "postgres" superuser
+	// and the operator can still write.
+	// More info: https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-DEFAULT-TRANSACTION-READ-ONLY
+	// +optional
+	ReadOnly *bool `json:"readOnly,omitempty"`
+
 	// The port on which PostgreSQL should listen.
 	// +optional
 	// +kubebuilder:default=5432
 	// +kubebuilder:validation:Minimum=1024
 	Port *int32 `json:"port,omitempty"`
 
-	// The major version of PostgreSQL installed in the PostgreSQL image
-	// +kubebuilder:validation:Required
+	// The major version of PostgreSQL installed in the PostgreSQL image.
+	// Required unless it comes from a template.
+	// +optional
 	// +kubebuilder:validation:Minimum=10
-	// +kubebuilder:validation:Maximum=16
+	// +kubebuilder:validation:Maximum=17
 	// +operator-sdk:csv:customresourcedefinitions:type=spec,order=1
-	PostgresVersion int `json:"postgresVersion"`
+	PostgresVersion int `json:"postgresVersion,omitempty"`
 
 	// The PostGIS extension version installed in the PostgreSQL image.
 	// When image is not set, indicates a PostGIS enabled image will be used.
@@ -152,6 +252,50 @@ type PostgresClusterSpec struct {
 	// +optional
 	ReplicaService *ServiceSpec `json:"replicaService,omitempty"`
 
+	// Hostnames for external-dns to publish for the Services of the cluster.
+	// Each hostname goes with a Service rather than a Pod, so it continues to
+	// reach the primary after a failover.
+	// More info: https://github.com/kubernetes-sigs/external-dns
+	// +optional
+	DNS *PostgresClusterDNS `json:"dns,omitempty"`
+
+	// Compatibility with a service mesh that injects proxies into the Pods of
+	// the cluster. PostgreSQL, Patroni, pgBackRest, and PgBouncer connect
+	// around the proxies, and containers start once the proxies are ready.
+	// +optional
+	ServiceMesh *ServiceMeshSpec `json:"serviceMesh,omitempty"`
+
+	// Limits of the Jobs that the operator creates to back up, restore,
+	// upgrade, and prepare the volumes of the cluster.
+	// +optional
+	Jobs *JobsSpec `json:"jobs,omitempty"`
+
+	// Capture diagnostics when a PostgreSQL process crashes: an excerpt of the
+	// log, snapshots of statistics views, and any core file. They are kept in
+	// the "diagnostics" directory of the data volume of the instance, and the
+	// most recent crash is recorded in the CrashDetected condition.
+	// +optional
+	Diagnostics *DiagnosticsSpec `json:"diagnostics,omitempty"`
+
+	// The IP family policy of every Service of the cluster. Set this to
+	// "PreferDualStack" or "RequireDualStack" on a dual-stack Kubernetes
+	// cluster. When empty, Kubernetes assigns the default of "SingleStack".
+	// - https://docs.k8s.io/concepts/services-networking/dual-stack/#services
+	// +optional
+	// +kubebuilder:validation:Enum={SingleStack,PreferDualStack,RequireDualStack}
+	IPFamilyPolicy *corev1.IPFamilyPolicyType `json:"ipFamilyPolicy,omitempty"`
+
+	// The IP families of every Service of the cluster, in order of preference.
+	// The first is the primary family and cannot change once Services exist.
+	// Processes that cannot listen on every family bind the wildcard address
+	// of the primary family. When empty, Kubernetes assigns the default family
+	// of the Kubernetes cluster.
+	// +listType=atomic
+	// +optional
+	// +kubebuilder:validation:MaxItems=2
+	// +kubebuilder:validation:items:Enum={IPv4,IPv6}
+	IPFamilies []corev1.IPFamily `json:"ipFamilies,omitempty"`
+
 	// Whether or not the PostgreSQL cluster should be stopped.
 	// When this is true, workloads are scaled to zero and CronJobs
 	// are suspended.
@@ -159,16 +303,48 @@ type PostgresClusterSpec struct {
 	// +optional
 	Shutdown *bool `json:"shutdown,omitempty"`
 
+	// Protects data from changes that stop or replace it. With
+	// SnapshotBeforeRestart, a differential pgBackRest backup is taken before
+	// the operator changes the PostgreSQL image, restores in place, or allows
+	// a major upgrade. Those changes wait until the backup finishes, so allow
+	// an upgrade before shutting down the cluster. Defaults to None.
+	// +optional
+	// +kubebuilder:validation:Enum={None,SnapshotBeforeRestart}
+	ChangeProtection string `json:"changeProtection,omitempty"`
+
 	// Run this cluster as a read-only copy of an existing cluster or archive.
 	// +optional
 	Standby *PostgresStandbySpec `json:"standby,omitempty"`
 
+	// The number of pods wanted in the instance set that scales on demand. It
+	// is set by KEDA or a HorizontalPodAutoscaler through the scale subresource
+	// and kept between the minimum and maximum of that instance set.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	DemandReplicas *int32 `json:"demandReplicas,omitempty"`
+
+	// The name of a PostgresClusterTemplate in the same namespace. Each field
+	// of this spec that is not set comes from the template as a whole; fields
+	// that are set, including those set by default such as port, replace what
+	// is in the template.
+	// +optional
+	TemplateName string `json:"templateName,omitempty"`
+
 	// A list of group IDs applied to the process of a container. These can be
 	// useful when accessing shared file systems with constrained permissions.
 	// More info: https://kubernetes.io/docs/reference/kubernetes-api/workload-resources/pod-v1/#security-context
 	// +optional
 	SupplementalGroups []int64 `json:"supplementalGroups,omitempty"`
 
+	// Settings derived from the resources of PostgreSQL instances. Parameters
+	// in spec.patroni.dynamicConfiguration take precedence.
+	// +optional
+	Tuning *TuningSpec `json:"tuning,omitempty"`
+
+	// How changes to instance Pods are rolled out.
+	// +optional
+	UpdateStrategy *UpdateStrategySpec `json:"updateStrategy,omitempty"`
+
 	// Users to create inside PostgreSQL and the databases they should access.
 	// The default creates one user that can access one database matching the
 	// PostgresCluster name. An empty list creates no users. Removing a user
@@ -178,6 +354,31 @@ type PostgresClusterSpec struct {
 	// +optional
 	Users []PostgresUserSpec `json:"users,omitempty"`
 
+	// Whether or not the Secrets generated for users are immutable. Each
+	// Secret is named for its user and a version, "<cluster>-pguser-<user>-v1",
+	// and any change to its contents creates a Secret with the next version.
+	// The current Secret of each user is in status.userSecrets. Annotate it with
+	// "postgres-operator.crunchydata.com/rotate-password" to generate a new
+	// password.
+	// More info: https://kubernetes.io/docs/concepts/configuration/secret/#secret-immutable
+	// +optional
+	ImmutableUserSecrets *bool `json:"immutableUserSecrets,omitempty"`
+
+	// A Secret to which the connection details of one user are written in the
+	// keys that Crossplane expects. When set, the Ready and Synced conditions
+	// that Crossplane reads from composed resources are reported, too.
+	// More info: https://docs.crossplane.io/latest/concepts/managed-resources/
+	// +optional
+	WriteConnectionSecretToRef *ConnectionSecretReference `json:"writeConnectionSecretToRef,omitempty"`
+
+	// A preset of planner, parallelism, checkpoint, and autovacuum parameters
+	// for the kind of queries PostgreSQL runs: "OLTP" for many short
+	// transactions, "OLAP" for large analytical queries, or "Mixed".
+	// Parameters in spec.patroni.dynamicConfiguration take precedence.
+	// +optional
+	// +kubebuilder:validation:Enum={OLTP,OLAP,Mixed}
+	WorkloadProfile string `json:"workloadProfile,omitempty"`
+
 	Config PostgresAdditionalConfig `json:"config,omitempty"`
 }
 
@@ -190,6 +391,12 @@ type DataSource struct {
 	// +optional
 	PGBackRest *PGBackRestDataSource `json:"pgbackrest,omitempty"`
 
+	// Defines scripts that mask or anonymize the data of a new PostgresCluster
+	// after it is restored from a data source. Only PostgreSQL users managed
+	// by the operator can connect until every script has succeeded.
+	// +optional
+	Masking *DataMaskingSpec `json:"masking,omitempty"`
+
 	// Defines a pgBackRest data source that can be used to pre-populate the PostgreSQL data
 	// directory for a new PostgreSQL cluster using a pgBackRest restore.
 	// The PGBackRest field is incompatible with the PostgresCluster field: only one
@@ -202,6 +409,20 @@ type DataSource struct {
 	Volumes *DataSourceVolumes `json:"volumes,omitempty"`
 }
 
+// DataMaskingSpec defines the SQL that masks data restored into a new
+// PostgresCluster.
+type DataMaskingSpec struct {
+	// ConfigMaps containing SQL to execute, in order, against the "postgres"
+	// database of the primary. Use \connect to reach other databases. Scripts
+	// run until they have all succeeded, and they may run again when status is
+	// lost, so they should be idempotent. Extensions such as postgresql_anonymizer
+	// must be available in the PostgreSQL image.
+	// More info: https://postgresql-anonymizer.readthedocs.io/
+	// +kubebuilder:validation:MinItems=1
+	// +listType=atomic
+	Scripts []DatabaseInitSQL `json:"scripts"`
+}
+
 // DataSourceVolumes defines any existing volumes to reuse for this PostgresCluster.
 type DataSourceVolumes struct {
 	// Defines the existing pgData volume and directory to use in the current
@@ -245,6 +466,301 @@ type DatabaseInitSQL struct {
 	Key string `json:"key"`
 }
 
+// DataChecksumsSpec defines how PostgreSQL data page checksums are managed.
+type DataChecksumsSpec struct {
+	// Whether or not PostgreSQL should calculate and verify a checksum on every
+	// data page. New clusters are initialized with checksums when this is true.
+	// Existing clusters without checksums have them enabled by pg_checksums the
+	// next time the cluster is shutdown; every file of the cluster is rewritten,
+	// so this can take a long time on large clusters.
+	// More info: https://www.postgresql.org/docs/current/app-pgchecksums.html
+	// +optional
+	// +kubebuilder:default=true
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Defines a scheduled Job that reads every data page of the cluster and
+	// reports any that fail checksum verification.
+	// +optional
+	Verification *DataChecksumsVerification `json:"verification,omitempty"`
+}
+
+// DataChecksumsVerification defines a scheduled Job that verifies data page
+// checksums by streaming a base backup of the cluster and discarding it.
+type DataChecksumsVerification struct {
+	// The Cron schedule of the verification Job. Follows the standard Cron
+	// schedule syntax:
+	// https://k8s.io/docs/concepts/workloads/controllers/cron-jobs/#cron-schedule-syntax
+	// +required
+	// +kubebuilder:validation:MinLength=6
+	Schedule string `json:"schedule"`
+
+	// Resource requirements of the verification Job.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Limit the lifetime of a Job that has finished.
+	// More info: https://kubernetes.io/docs/concepts/workloads/controllers/job
+	// +optional
+	// +kubebuilder:validation:Minimum=60
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+}
+
+// InitdbSpec defines options passed to `initdb` when a cluster is created.
+type InitdbSpec struct {
+	// The encoding of the template databases. Defaults to "UTF8".
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	Encoding string `json:"encoding,omitempty"`
+
+	// The default locale of the template databases, such as "en_US.UTF-8".
+	// Defaults to the locale of the container image.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	Locale string `json:"locale,omitempty"`
+
+	// The collation order (LC_COLLATE) of the template databases. Defaults to
+	// the value of locale.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	LCCollate string `json:"lcCollate,omitempty"`
+
+	// The character classification (LC_CTYPE) of the template databases.
+	// Defaults to the value of locale.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	LCCType string `json:"lcCType,omitempty"`
+
+	// The library that provides collations of the template databases: "libc"
+	// of the operating system or "icu". Collations from ICU do not change when
+	// the operating system of the container image changes. Requires PostgreSQL
+	// 15 or later. Defaults to "libc".
+	// +optional
+	// +kubebuilder:validation:Enum={libc,icu}
+	LocaleProvider string `json:"localeProvider,omitempty"`
+
+	// The ICU locale of the template databases, such as "en-US", when the
+	// locale provider is "icu". Requires PostgreSQL 15 or later.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	ICULocale string `json:"icuLocale,omitempty"`
+
+	// The size of WAL segment files in megabytes. Larger segments mean fewer
+	// files on busy clusters. Defaults to 16.
+	// +optional
+	// +kubebuilder:validation:Enum={1,2,4,8,16,32,64,128,256,512,1024}
+	WALSegmentSize *int32 `json:"walSegmentSize,omitempty"`
+}
+
+// DataChecksumsStatus is the observed state of PostgreSQL data page checksums.
+type CollationsStatus struct {
+	// The image of the primary when collation versions were last compared.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// The value of the reindex-collations annotation when indexes were last
+	// rebuilt and collation versions refreshed.
+	// +optional
+	Reindexed string `json:"reindexed,omitempty"`
+}
+
+type DataChecksumsStatus struct {
+	// Whether or not PostgreSQL reported that data page checksums are enabled.
+	// +optional
+	Enabled bool `json:"enabled"`
+
+	// The completion time of the most recent verification Job.
+	// +optional
+	LastVerificationTime *metav1.Time `json:"lastVerificationTime,omitempty"`
+}
+
+// HealthProbesSpec defines a scheduled Job that runs a synthetic transaction
+// against a PostgreSQL cluster.
+type HealthProbesSpec struct {
+	// The Cron schedule of the health probe Job. Follows the standard Cron
+	// schedule syntax:
+	// https://k8s.io/docs/concepts/workloads/controllers/cron-jobs/#cron-schedule-syntax
+	// +required
+	// +kubebuilder:validation:MinLength=6
+	Schedule string `json:"schedule"`
+
+	// How long a replica may take to replay the heartbeat before the cluster
+	// is considered unusable. Defaults to 30.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxReplicaLagSeconds *int32 `json:"maxReplicaLagSeconds,omitempty"`
+
+	// Resource requirements of the health probe Job.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Limit the lifetime of a Job that has finished.
+	// More info: https://kubernetes.io/docs/concepts/workloads/controllers/job
+	// +optional
+	// +kubebuilder:validation:Minimum=60
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+}
+
+// HealthProbesStatus is the observed state of health probes.
+type HealthProbesStatus struct {
+	// Identifies the health probe objects that have been installed into PostgreSQL.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// The completion time of the most recent health probe Job.
+	// +optional
+	LastProbeTime *metav1.Time `json:"lastProbeTime,omitempty"`
+}
+
+// IntegrityChecksSpec defines a scheduled Job that checks PostgreSQL tables and
+// indexes for corruption.
+type IntegrityChecksSpec struct {
+	// The Cron schedule of the integrity check Job. Follows the standard Cron
+	// schedule syntax:
+	// https://k8s.io/docs/concepts/workloads/controllers/cron-jobs/#cron-schedule-syntax
+	// +required
+	// +kubebuilder:validation:MinLength=6
+	Schedule string `json:"schedule"`
+
+	// The databases to check. When empty, every database that allows
+	// connections is checked.
+	// +optional
+	// +listType=set
+	Databases []string `json:"databases,omitempty"`
+
+	// The instance to check. "Replica" keeps the work away from the primary,
+	// but fails when there are no replicas. Defaults to "Primary".
+	// +optional
+	// +kubebuilder:default=Primary
+	// +kubebuilder:validation:Enum={Primary,Replica}
+	Target string `json:"target,omitempty"`
+
+	// The number of concurrent connections pg_amcheck opens to PostgreSQL.
+	// Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Connections *int32 `json:"connections,omitempty"`
+
+	// Whether or not to verify that every heap tuple has an index entry. This
+	// is thorough but considerably slower.
+	// +optional
+	HeapAllIndexed bool `json:"heapAllIndexed,omitempty"`
+
+	// Resource requirements of the integrity check Job.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Limit the lifetime of a Job that has finished.
+	// More info: https://kubernetes.io/docs/concepts/workloads/controllers/job
+	// +optional
+	// +kubebuilder:validation:Minimum=60
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+}
+
+// UpgradeUserStatus is the observed state of the user that PGUpgrade Jobs use
+// to finish a major upgrade.
+type UpgradeUserStatus struct {
+	// Identifies the user that has been created in PostgreSQL.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// The name of the PGUpgrade that no longer needs the user. The user is
+	// removed until the cluster is annotated for another upgrade.
+	// +optional
+	Completed string `json:"completed,omitempty"`
+}
+
+// ResourcesStatus totals the compute and storage of a PostgresCluster. CPU and
+// memory are counted for containers of Pods that have not finished, and storage
+// for every PersistentVolumeClaim.
+type ResourcesStatus struct {
+	// The CPU and memory requested by containers and the storage requested
+	// by PersistentVolumeClaims
+	// +optional
+	Requests corev1.ResourceList `json:"requests,omitempty"`
+
+	// The CPU and memory limits of containers
+	// +optional
+	Limits corev1.ResourceList `json:"limits,omitempty"`
+
+	// The storage capacity of bound PersistentVolumeClaims
+	// +optional
+	Capacity corev1.ResourceList `json:"capacity,omitempty"`
+
+	// The CPU and memory used by Pods, as reported by the Kubernetes metrics API,
+	// and the storage used by PostgreSQL data, WAL, and tablespace volumes.
+	// Resources that could not be measured are omitted.
+	// +optional
+	Usage corev1.ResourceList `json:"usage,omitempty"`
+
+	// The time at which usage was last measured
+	// +optional
+	UsageTime *metav1.Time `json:"usageTime,omitempty"`
+}
+
+// IntegrityChecksStatus is the observed state of integrity checks.
+type IntegrityChecksStatus struct {
+	// Identifies the amcheck objects that have been installed into PostgreSQL.
+	// +optional
+	Revision string `json:"revision,omitempty"`
+
+	// The completion time of the most recent integrity check Job.
+	// +optional
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+}
+
+// ReindexSpec defines how indexes are rebuilt.
+type ReindexSpec struct {
+	// The number of indexes rebuilt at the same time. Each one uses a
+	// connection and up to maintenance_work_mem of memory. Defaults to 1.
+	// +optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=16
+	Concurrency *int32 `json:"concurrency,omitempty"`
+}
+
+// ReindexStatus is the progress of rebuilding indexes.
+type ReindexStatus struct {
+	// Why indexes are being rebuilt: CollationVersionChanged,
+	// IntegrityCheckFailed, or MajorUpgrade. Empty when no indexes are
+	// being rebuilt.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+
+	// The major version of PostgreSQL whose indexes are current.
+	// +optional
+	PostgresVersion int `json:"postgresVersion,omitempty"`
+
+	// The completion time of the most recent failed integrity check that
+	// caused indexes to be rebuilt.
+	// +optional
+	IntegrityCheckTime *metav1.Time `json:"integrityCheckTime,omitempty"`
+
+	// The database in which indexes are being rebuilt.
+	// +optional
+	Database string `json:"database,omitempty"`
+
+	// The qualified name of the most recent index rebuilt in database.
+	// +optional
+	Index string `json:"index,omitempty"`
+
+	// The number of indexes to rebuild.
+	// +optional
+	Total int32 `json:"total,omitempty"`
+
+	// The number of indexes that have been rebuilt or verified.
+	// +optional
+	Completed int32 `json:"completed,omitempty"`
+
+	// When indexes started being rebuilt.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// When indexes finished being rebuilt.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
 // PostgresClusterDataSource defines a data source for bootstrapping PostgreSQL clusters using a
 // an existing PostgresCluster.
 type PostgresClusterDataSource struct {
@@ -271,6 +787,19 @@ type PostgresClusterDataSource struct {
 	// +optional
 	Options []string `json:"options,omitempty"`
 
+	// Restores some databases rather than all of them.
+	// More info: https://pgbackrest.org/command.html#command-restore/category-command/option-db-include
+	// +optional
+	Databases *PGBackRestRestoreDatabases `json:"databases,omitempty"`
+
+	// The name of a restore point at which recovery stops, such as one created
+	// by the restore-point annotation. This cannot be combined with the
+	// "--type" or "--target" options.
+	// More info: https://pgbackrest.org/command.html#command-restore/category-command/option-type
+	// +optional
+	// +kubebuilder:validation:MaxLength=63
+	RestorePoint string `json:"restorePoint,omitempty"`
+
 	// Resource requirements for the pgBackRest restore Job.
 	// +optional
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
@@ -323,6 +852,88 @@ type Backups struct {
 	// pgBackRest archive configuration
 	// +kubebuilder:validation:Required
 	PGBackRest PGBackRestArchive `json:"pgbackrest"`
+
+	// Stream WAL from the primary to a volume that is independent of the
+	// pgBackRest repositories using pg_receivewal.
+	// +optional
+	WALStreaming *WALStreamingSpec `json:"walStreaming,omitempty"`
+}
+
+// WALStreamingSpec defines a Deployment that runs pg_receivewal to copy WAL
+// from the primary as it is written. The copy does not depend on pgBackRest
+// archiving, so a volume from a different storage provider than the pgBackRest
+// repositories limits the data lost when either fails.
+// More info: https://www.postgresql.org/docs/current/app-pgreceivewal.html
+type WALStreamingSpec struct {
+	// Defines a PersistentVolumeClaim for the streamed WAL.
+	// +required
+	VolumeClaimSpec corev1.PersistentVolumeClaimSpec `json:"volumeClaimSpec"`
+
+	// Resource requirements of the pg_receivewal container.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+
+	// Whether or not pg_receivewal flushes WAL to disk as soon as it is
+	// received and reports it as flushed. PostgreSQL waits for it when
+	// "pgo_walstream" is in the synchronous_standby_names parameter.
+	// +optional
+	Synchronous *bool `json:"synchronous,omitempty"`
+}
+
+// WALStreamingStatus is the observed state of pg_receivewal.
+type WALStreamingStatus struct {
+	// Whether or not pg_receivewal is connected and writing WAL.
+	// +optional
+	Ready bool `json:"ready"`
+
+	// The number of times the pg_receivewal container has restarted. It
+	// restarts when its connection to the primary is lost, such as after
+	// a failover.
+	// +optional
+	Restarts int32 `json:"restarts,omitempty"`
+}
+
+// FaultInjectionStatus records faults that have been injected so that each
+// is injected only once.
+type FaultInjectionStatus struct {
+	// The value of the fault-kill-primary annotation when the primary was
+	// last deleted.
+	// +optional
+	PrimaryKilled string `json:"primaryKilled,omitempty"`
+}
+
+// CertificateAuthorityStatus records the root certificate authority of a
+// cluster so that a replacement can be trusted by every pod before it is used.
+type CertificateAuthorityStatus struct {
+	// The PEM-encoded certificate of the root certificate authority that issues
+	// certificates for the cluster.
+	// +optional
+	Certificate string `json:"certificate,omitempty"`
+
+	// The PEM-encoded certificate of the root certificate authority that was
+	// replaced. It is trusted until every pod has certificates from the current one.
+	// +optional
+	Previous string `json:"previous,omitempty"`
+
+	// When the previous root certificate authority was replaced.
+	// +optional
+	ReplacedAt *metav1.Time `json:"replacedAt,omitempty"`
+}
+
+// ConnectionSecretReference identifies a Secret in the namespace of a
+// PostgresCluster and the user whose connection details it contains.
+type ConnectionSecretReference struct {
+	// The name of the Secret.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// The PostgreSQL user whose connection details are written. Defaults to the
+	// first user in spec.users or, when that is not set, the default user.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:Type=string
+	User PostgresIdentifier `json:"user,omitempty"`
 }
 
 // PostgresClusterStatus defines the observed state of PostgresCluster
@@ -331,6 +942,21 @@ type PostgresClusterStatus struct {
 	// Identifies the databases that have been installed into PostgreSQL.
 	DatabaseRevision string `json:"databaseRevision,omitempty"`
 
+	// Identifies the foreign servers and user mappings that have been
+	// installed into PostgreSQL.
+	// +optional
+	ForeignServersRevision string `json:"foreignServersRevision,omitempty"`
+
+	// Identifies the read-only settings that have been installed into
+	// PostgreSQL.
+	// +optional
+	ReadOnlyRevision string `json:"readOnlyRevision,omitempty"`
+
+	// Identifies the tablespace for temporary files that has been installed
+	// into PostgreSQL.
+	// +optional
+	TempTablespaceRevision string `json:"tempTablespaceRevision,omitempty"`
+
 	// Current state of PostgreSQL instances.
 	// +listType=map
 	// +listMapKey=name
@@ -377,6 +1003,12 @@ type PostgresClusterStatus struct {
 	// Identifies the users that have been installed into PostgreSQL.
 	UsersRevision string `json:"usersRevision,omitempty"`
 
+	// The current Secret of each user when user Secrets are immutable.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	UserSecrets []PostgresUserSecretStatus `json:"userSecrets,omitempty"`
+
 	// Current state of PostgreSQL cluster monitoring tool configuration
 	// +optional
 	Monitoring MonitoringStatus `json:"monitoring,omitempty"`
@@ -385,14 +1017,116 @@ type PostgresClusterStatus struct {
 	// +optional
 	DatabaseInitSQL *string `json:"databaseInitSQL,omitempty"`
 
+	// Current state of PostgreSQL data page checksums
+	// +optional
+	DataChecksums *DataChecksumsStatus `json:"dataChecksums,omitempty"`
+
+	// Current state of backups taken before disruptive changes
+	// +optional
+	ChangeProtection *ChangeProtectionStatus `json:"changeProtection,omitempty"`
+
+	// Current state of collation version comparisons
+	// +optional
+	Collations *CollationsStatus `json:"collations,omitempty"`
+
+	// Current state of integrity checks
+	// +optional
+	IntegrityChecks *IntegrityChecksStatus `json:"integrityChecks,omitempty"`
+
+	// Current state of rebuilding indexes
+	// +optional
+	Reindex *ReindexStatus `json:"reindex,omitempty"`
+
+	// Current state of health probes
+	// +optional
+	HealthProbes *HealthProbesStatus `json:"healthProbes,omitempty"`
+
+	// Current state of scheduled maintenance
+	// +optional
+	Maintenance *MaintenanceStatus `json:"maintenance,omitempty"`
+
+	// Current state of partition maintenance
+	// +optional
+	Partitioning *PartitioningStatus `json:"partitioning,omitempty"`
+
+	// The most recent restore points created by the restore-point annotation,
+	// oldest first. At most 20 are kept.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	RestorePoints []RestorePointStatus `json:"restorePoints,omitempty"`
+
+	// Current state of the user that finishes a major upgrade
+	// +optional
+	UpgradeUser *UpgradeUserStatus `json:"upgradeUser,omitempty"`
+
+	// Current state of scheduled instance set scaling
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	Autoscaling []InstanceSetAutoscalingStatus `json:"autoscaling,omitempty"`
+
+	// The number of pods in the instance set that scales on demand
+	// +optional
+	DemandReplicas int32 `json:"demandReplicas,omitempty"`
+
+	// The label selector of pods in the instance set that scales on demand
+	// +optional
+	DemandSelector string `json:"demandSelector,omitempty"`
+
+	// Faults injected by annotations when the FaultInjection feature gate is enabled
+	// +optional
+	FaultInjection *FaultInjectionStatus `json:"faultInjection,omitempty"`
+
+	// Totals of the compute and storage of every Pod and volume of the cluster
+	// +optional
+	Resources *ResourcesStatus `json:"resources,omitempty"`
+
+	// Resources of instance sets that are no longer in the spec and were left
+	// in place: volumes kept by the removedInstanceSetPolicy and resources that
+	// are not controlled by the cluster, such as those of older operators.
+	// +optional
+	OrphanedResources []OrphanedResource `json:"orphanedResources,omitempty"`
+
+	// Names of the feature gates enabled for this cluster
+	// +optional
+	EnabledFeatures []string `json:"enabledFeatures,omitempty"`
+
+	// Current state of WAL streaming by pg_receivewal
+	// +optional
+	WALStreaming *WALStreamingStatus `json:"walStreaming,omitempty"`
+
+	// Current state of the root certificate authority that issues certificates
+	// for the cluster
+	// +optional
+	CertificateAuthority *CertificateAuthorityStatus `json:"certificateAuthority,omitempty"`
+
+	// Checkpoint parameters derived from the rate at which the primary writes WAL
+	// +optional
+	CheckpointTuning *CheckpointTuningStatus `json:"checkpointTuning,omitempty"`
+
+	// Crashes of PostgreSQL found in its logs
+	// +optional
+	Diagnostics *DiagnosticsStatus `json:"diagnostics,omitempty"`
+
+	// Lifecycle hooks that have been called for the cluster
+	// +optional
+	LifecycleHooks *LifecycleHooksStatus `json:"lifecycleHooks,omitempty"`
+
+	// The most recent replica that was split into a new PostgresCluster
+	// +optional
+	Split *SplitStatus `json:"split,omitempty"`
+
 	// observedGeneration represents the .metadata.generation on which the status was based.
 	// +optional
 	// +kubebuilder:validation:Minimum=0
 	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 
 	// conditions represent the observations of postgrescluster's current state.
-	// Known .status.conditions.type are: "PersistentVolumeResizing",
-	// "Progressing", "ProxyAvailable"
+	// Known .status.conditions.type are: "ChangesHeld", "ClusterUsable", "CollationVersionMismatch", "CrashDetected",
+	// "DataChecksumsVerified", "DataMasked", "DependenciesSatisfied", "DiskProtectionEngaged", "IndexesRebuilt", "IntegrityChecked",
+	// "MaintenanceCompleted", "PartitionsMaintained", "PausedByUser", "PermissionsAvailable", "PersistentVolumeResizing", "Progressing", "ProxyAvailable",
+	// "Ready", "SecretsAvailable", "Synced", "TemplateAvailable", "WALExpirationHeld"
 	// +optional
 	// +listType=map
 	// +listMapKey=type
@@ -400,13 +1134,221 @@ type PostgresClusterStatus struct {
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 }
 
+// PostgresClusterSpec.RemovedInstanceSetPolicy values.
+const (
+	RemovedInstanceSetDelete = "Delete"
+	RemovedInstanceSetRetain = "Retain"
+)
+
+// OrphanedResource identifies a resource of an instance set that is no longer
+// in the spec.
+type OrphanedResource struct {
+	// The kind of the resource, such as PersistentVolumeClaim.
+	// +required
+	Kind string `json:"kind"`
+
+	// The name of the resource.
+	// +required
+	Name string `json:"name"`
+
+	// The instance set to which the resource belonged.
+	// +optional
+	InstanceSet string `json:"instanceSet,omitempty"`
+
+	// Why the resource was left in place: Retained or Uncontrolled.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
+// PostgresClusterSpec.ChangeProtection values.
+const (
+	ChangeProtectionNone                  = "None"
+	ChangeProtectionSnapshotBeforeRestart = "SnapshotBeforeRestart"
+)
+
+// ChangeProtectionStatus describes the backups taken before disruptive changes.
+type ChangeProtectionStatus struct {
+	// The changes that are waiting for a backup.
+	// +optional
+	Pending string `json:"pending,omitempty"`
+
+	// The changes that the most recent backup protects.
+	// +optional
+	Protected string `json:"protected,omitempty"`
+
+	// The most recent changes that were protected by a backup, oldest first.
+	// At most 10 are kept.
+	// +optional
+	History []ProtectedChange `json:"history,omitempty"`
+}
+
+// ProtectedChange describes changes and the backup that was taken before them.
+type ProtectedChange struct {
+	// The changes that were protected.
+	// +required
+	Changes string `json:"changes"`
+
+	// The name of the Job that took the backup.
+	// +optional
+	Backup string `json:"backup,omitempty"`
+
+	// When the backup finished.
+	// +optional
+	Time metav1.Time `json:"time,omitempty"`
+}
+
+// RestorePointStatus describes a named restore point in the WAL of a cluster.
+type RestorePointStatus struct {
+	// The name of the restore point.
+	// +required
+	Name string `json:"name"`
+
+	// The WAL location of the restore point.
+	// +optional
+	LSN string `json:"lsn,omitempty"`
+
+	// When the restore point was created.
+	// +optional
+	Time metav1.Time `json:"time,omitempty"`
+}
+
+// SplitStatus describes a replica that is stopped so that a new PostgresCluster
+// can start from its volumes.
+type SplitStatus struct {
+	// The name of the instance that was split from this cluster.
+	// +required
+	Instance string `json:"instance"`
+
+	// The name of the PostgresCluster created from the volumes of instance.
+	// +required
+	ClusterName string `json:"clusterName"`
+
+	// When the instance began to stop.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// When the new PostgresCluster was created. The split is in progress
+	// until this is set.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// PostgresClusterDNS is annotations for external-dns on the Services of a cluster.
+type PostgresClusterDNS struct {
+	// The hostnames of each Service.
+	Hostnames PostgresClusterDNSHostnames `json:"hostnames"`
+
+	// The time to live of the DNS records, in seconds. Defaults to the TTL
+	// configured in external-dns.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	TTLSeconds *int32 `json:"ttlSeconds,omitempty"`
+}
+
+// PostgresClusterDNSHostnames are the hostnames of the Services of a cluster.
+// external-dns publishes Services of type LoadBalancer and NodePort, and those
+// of type ClusterIP when it runs with --publish-internal-services.
+type PostgresClusterDNSHostnames struct {
+	// Hostnames of the Service of the primary, configured by spec.service.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=10
+	// +kubebuilder:validation:items:MaxLength=253
+	// +kubebuilder:validation:items:Pattern=`^(\*\.)?([a-z0-9]([-a-z0-9]*[a-z0-9])?\.)*[a-z0-9]([-a-z0-9]*[a-z0-9])?\.?$`
+	Primary []string `json:"primary,omitempty"`
+
+	// Hostnames of the Service of replicas, configured by spec.replicaService.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=10
+	// +kubebuilder:validation:items:MaxLength=253
+	// +kubebuilder:validation:items:Pattern=`^(\*\.)?([a-z0-9]([-a-z0-9]*[a-z0-9])?\.)*[a-z0-9]([-a-z0-9]*[a-z0-9])?\.?$`
+	Replicas []string `json:"replicas,omitempty"`
+
+	// Hostnames of the Service of PgBouncer, configured by
+	// spec.proxy.pgBouncer.service.
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=10
+	// +kubebuilder:validation:items:MaxLength=253
+	// +kubebuilder:validation:items:Pattern=`^(\*\.)?([a-z0-9]([-a-z0-9]*[a-z0-9])?\.)*[a-z0-9]([-a-z0-9]*[a-z0-9])?\.?$`
+	PGBouncer []string `json:"pgBouncer,omitempty"`
+}
+
+// ServiceMeshSpec is the service mesh of a cluster.
+type ServiceMeshSpec struct {
+	// The service mesh that injects proxies into the Pods of the cluster.
+	// +required
+	// +kubebuilder:validation:Enum={Istio,Linkerd}
+	Type string `json:"type"`
+}
+
+// JobsSpec limits the Jobs that the operator creates for a cluster.
+type JobsSpec struct {
+	// The most seconds that each Job may run before it is stopped and fails.
+	// This applies to backup, restore, upgrade, and volume preparation Jobs.
+	// More info: https://kubernetes.io/docs/concepts/workloads/controllers/job/#job-termination-and-cleanup
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+
+	// The number of times that each Job is retried before it fails. This
+	// applies to backup, restore, and volume preparation Jobs and to upgrade
+	// Jobs that can be retried; pg_upgrade and rollback run at most once.
+	// Defaults to 6.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	BackoffLimit *int32 `json:"backoffLimit,omitempty"`
+
+	// Limit the lifetime of backup Jobs that have finished. The operator
+	// depends on other Jobs until it is done with them and removes them itself.
+	// The TTL of spec.backups.pgbackrest.jobs takes precedence over this one.
+	// More info: https://kubernetes.io/docs/concepts/workloads/controllers/job
+	// +optional
+	// +kubebuilder:validation:Minimum=60
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+}
+
+// LifecycleHooksStatus records the lifecycle hooks called for a cluster so
+// that each is called once.
+type LifecycleHooksStatus struct {
+	// The events whose hooks have succeeded, such as PostReady and PreDelete
+	// +optional
+	// +listType=set
+	Called []string `json:"called,omitempty"`
+
+	// The instance that was the primary when hooks were last called
+	// +optional
+	Primary string `json:"primary,omitempty"`
+}
+
 // PostgresClusterStatus condition types.
 const (
+	ChangesHeld                = "ChangesHeld"
+	ClusterUsable              = "ClusterUsable"
+	CollationVersionMismatch   = "CollationVersionMismatch"
+	CrashDetected              = "CrashDetected"
+	DataChecksumsVerified      = "DataChecksumsVerified"
+	DataMasked                 = "DataMasked"
+	DependenciesSatisfied      = "DependenciesSatisfied"
+	DiskProtectionEngaged      = "DiskProtectionEngaged"
+	IndexesRebuilt             = "IndexesRebuilt"
+	IntegrityChecked           = "IntegrityChecked"
+	MaintenanceCompleted       = "MaintenanceCompleted"
+	PartitionsMaintained       = "PartitionsMaintained"
+	PausedByUser               = "PausedByUser"
+	PermissionsAvailable       = "PermissionsAvailable"
 	PersistentVolumeResizing   = "PersistentVolumeResizing"
 	PostgresClusterProgressing = "Progressing"
 	ProxyAvailable             = "ProxyAvailable"
+	Ready                      = "Ready"
 	RegistrationRequired       = "RegistrationRequired"
+	SecretsAvailable           = "SecretsAvailable"
+	Stalled                    = "Stalled"
+	Synced                     = "Synced"
+	TemplateAvailable          = "TemplateAvailable"
 	TokenRequired              = "TokenRequired"
+	WALExpirationHeld          = "WALExpirationHeld"
 )
 
 type PostgresInstanceSetSpec struct {
@@ -447,6 +1389,13 @@ type PostgresInstanceSetSpec struct {
 	// +optional
 	Containers []corev1.Container `json:"containers,omitempty"`
 
+	// Settings for the init containers that the operator adds to PostgreSQL
+	// pods, such as for namespaces with a LimitRange or ResourceQuota that
+	// requires resources of every container. Changing this value causes
+	// PostgreSQL to restart.
+	// +optional
+	InitContainers *InstanceInitContainers `json:"initContainers,omitempty"`
+
 	// Defines a PersistentVolumeClaim for PostgreSQL data.
 	// More info: https://kubernetes.io/docs/concepts/storage/persistent-volumes
 	// +kubebuilder:validation:Required
@@ -464,6 +1413,12 @@ type PostgresInstanceSetSpec struct {
 	// +kubebuilder:validation:Minimum=1
 	Replicas *int32 `json:"replicas,omitempty"`
 
+	// Change the number of pods on a schedule, such as more replicas during
+	// business hours, or on demand. The replicas field applies outside every
+	// scheduled period.
+	// +optional
+	Autoscaling *InstanceSetAutoscaling `json:"autoscaling,omitempty"`
+
 	// Minimum number of pods that should be available at a time.
 	// Defaults to one when the replicas field is greater than one.
 	// +optional
@@ -493,14 +1448,39 @@ type PostgresInstanceSetSpec struct {
 	// +optional
 	WALVolumeClaimSpec *corev1.PersistentVolumeClaimSpec `json:"walVolumeClaimSpec,omitempty"`
 
+	// Defines a separate PersistentVolumeClaim for the temporary files of
+	// PostgreSQL, such as sorts and hashes that spill from memory and temporary
+	// tables. When any instance set has one, the operator creates a "pgtemp"
+	// tablespace on it and sets temp_tablespaces to that. Other instances keep
+	// their temporary files in an emptyDir volume. Parameters in
+	// spec.patroni.dynamicConfiguration take precedence.
+	// More info: https://www.postgresql.org/docs/current/runtime-config-client.html#GUC-TEMP-TABLESPACES
+	// +optional
+	TempVolumeClaimSpec *corev1.PersistentVolumeClaimSpec `json:"tempVolumeClaimSpec,omitempty"`
+
 	// The list of tablespaces volumes to mount for this postgrescluster
 	// This field requires enabling TablespaceVolumes feature gate
 	// +listType=map
 	// +listMapKey=name
 	// +optional
 	TablespaceVolumes []TablespaceVolume `json:"tablespaceVolumes,omitempty"`
+
+	// What to do when an instance cannot be scheduled because its volumes are
+	// bound to a Node that is unavailable, as happens with local volumes when
+	// their Node dies. "Wait" keeps the instance and its data until the Node
+	// returns and reports how to recover in an Event. "Rebuild" deletes the
+	// volumes of a replica after five minutes so it is recreated on another
+	// Node and copies its data from the primary. Defaults to "Wait".
+	// +optional
+	// +kubebuilder:validation:Enum={Wait,Rebuild}
+	VolumeNodeAffinityPolicy string `json:"volumeNodeAffinityPolicy,omitempty"`
 }
 
+const (
+	VolumeNodeAffinityWait    = "Wait"
+	VolumeNodeAffinityRebuild = "Rebuild"
+)
+
 type TablespaceVolume struct {
 	// This value goes into
 	// a. the name of a corev1.PersistentVolumeClaim,
@@ -530,6 +1510,19 @@ type InstanceSidecars struct {
 	ReplicaCertCopy *Sidecar `json:"replicaCertCopy,omitempty"`
 }
 
+// InstanceInitContainers configures the init containers of PostgreSQL pods.
+type InstanceInitContainers struct {
+	// Defines the configuration for the container that prepares the data
+	// directory of PostgreSQL, postgres-startup.
+	// +optional
+	Startup *InitContainer `json:"startup,omitempty"`
+
+	// Defines the configuration for the container that prepares nss_wrapper,
+	// nss-wrapper-init.
+	// +optional
+	NSSWrapper *InitContainer `json:"nssWrapper,omitempty"`
+}
+
 // Default sets the default values for an instance set spec, including the name
 // suffix and number of replicas.
 func (s *PostgresInstanceSetSpec) Default(i int) {
@@ -604,6 +1597,53 @@ type PostgresStandbySpec struct {
 	Port *int32 `json:"port,omitempty"`
 }
 
+// UpdateStrategySpec defines how changes to instance Pods are rolled out.
+type UpdateStrategySpec struct {
+
+	// Settings for redeploying instances one or a few at a time.
+	// +optional
+	RollingUpdate *RollingUpdateSpec `json:"rollingUpdate,omitempty"`
+}
+
+type RollingUpdateSpec struct {
+
+	// Which instances to redeploy first. "ReplicasFirst" redeploys the primary
+	// last so that it changes at most once. "PrimaryFirst" redeploys the
+	// primary before any replicas.
+	// +optional
+	// +kubebuilder:default=ReplicasFirst
+	// +kubebuilder:validation:Enum={ReplicasFirst,PrimaryFirst}
+	Order string `json:"order,omitempty"`
+
+	// The most instances of each instance set that may be unavailable while
+	// they are redeployed. When this is not set, one instance of the entire
+	// PostgresCluster is redeployed at a time. The primary is always
+	// redeployed apart from the other instances of its set.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxUnavailable *int32 `json:"maxUnavailable,omitempty"`
+
+	// Whether or not to switch over to another instance before redeploying the
+	// primary. When this is false, the primary is redeployed and Patroni fails
+	// over to another instance, if any.
+	// +optional
+	// +kubebuilder:default=true
+	SwitchoverPrimary *bool `json:"switchoverPrimary,omitempty"`
+
+	// The most bytes of WAL that replicas may be behind the primary before the
+	// next instance is redeployed. When this is not set, replication lag does
+	// not hold back a rollout.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxLagBytes *int64 `json:"maxLagBytes,omitempty"`
+}
+
+// RollingUpdateSpec orders.
+const (
+	RollingUpdatePrimaryFirst  = "PrimaryFirst"
+	RollingUpdateReplicasFirst = "ReplicasFirst"
+)
+
 // UserInterfaceSpec is a union of the supported PostgreSQL user interfaces.
 type UserInterfaceSpec struct {
 
@@ -632,6 +1672,7 @@ type PostgresAdditionalConfig struct {
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:subresource:scale:specpath=.spec.demandReplicas,statuspath=.status.demandReplicas,selectorpath=.status.demandSelector
 // +operator-sdk:csv:customresourcedefinitions:resources={{ConfigMap,v1},{Secret,v1},{Service,v1},{CronJob,v1beta1},{Deployment,v1},{Job,v1},{StatefulSet,v1},{PersistentVolumeClaim,v1}}
 
 // PostgresCluster is the Schema for the postgresclusters API
@@ -663,6 +1704,11 @@ func (c *PostgresCluster) Default() {
 	c.Spec.Default()
 }
 
+// Hub marks this type as the version to and from which other versions of
+// PostgresCluster convert. It is the version stored by Kubernetes.
+// - https://book.kubebuilder.io/multiversion-tutorial/conversion-concepts.html
+func (*PostgresCluster) Hub() {}
+
 // +kubebuilder:object:root=true
 
 // PostgresClusterList contains a list of PostgresCluster
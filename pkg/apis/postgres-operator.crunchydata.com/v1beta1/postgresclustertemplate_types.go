@@ -0,0 +1,54 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PostgresClusterTemplateSpec defines the desired state of PostgresClusterTemplate
+type PostgresClusterTemplateSpec struct {
+
+	// The specification that PostgresClusters referencing this template start
+	// from. Its templateName is ignored.
+	// +required
+	Template PostgresClusterSpec `json:"template"`
+}
+
+//+kubebuilder:object:root=true
+
+// PostgresClusterTemplate is the Schema for the postgresclustertemplates API.
+// It is a PostgresCluster spec that platform teams publish and that
+// PostgresClusters in the same namespace reference by name, overriding only
+// the fields they need.
+type PostgresClusterTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec PostgresClusterTemplateSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PostgresClusterTemplateList contains a list of PostgresClusterTemplate
+type PostgresClusterTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PostgresClusterTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PostgresClusterTemplate{}, &PostgresClusterTemplateList{})
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PostgresDatabaseClaimSpec defines the desired state of PostgresDatabaseClaim
+type PostgresDatabaseClaimSpec struct {
+
+	// The name of the database to create.
+	// +required
+	DatabaseName PostgresIdentifier `json:"databaseName"`
+
+	// The name of the PostgreSQL user that owns the database. The value may
+	// contain only lowercase letters, numbers, and hyphen so that it fits into
+	// Kubernetes metadata.
+	// +required
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:Type=string
+	Owner PostgresIdentifier `json:"owner"`
+
+	// The size of a dedicated cluster. This is the name of the
+	// PostgresClusterTemplate in the same namespace from which the dedicated
+	// cluster is created. It is ignored when the claim is fulfilled on a shared
+	// cluster.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	SizeClass string `json:"sizeClass"`
+
+	// A PostgresCluster, possibly in another namespace, on which to fulfill
+	// this claim. The owner and database are added to its users, so that
+	// cluster should list its users explicitly. Its "allow-database-claims"
+	// annotation must list the namespace of this claim or be "*". When not
+	// set, a dedicated PostgresCluster named after this claim is created.
+	// +optional
+	SharedCluster *PostgresClusterReference `json:"sharedCluster,omitempty"`
+}
+
+// PostgresClusterReference identifies a PostgresCluster by namespace and name.
+type PostgresClusterReference struct {
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// PostgresDatabaseClaimStatus defines the observed state of PostgresDatabaseClaim
+type PostgresDatabaseClaimStatus struct {
+	// conditions represent the observations of PostgresDatabaseClaim's current state.
+	// +optional
+	// +listType=map
+	// +listMapKey=type
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// observedGeneration represents the .metadata.generation on which the status was based.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// The PostgresCluster on which this claim is fulfilled.
+	// +optional
+	Cluster *PostgresClusterReference `json:"cluster,omitempty"`
+
+	// The name of the Secret in this namespace that contains the connection
+	// details of the database.
+	// +optional
+	ConnectionSecretName string `json:"connectionSecretName,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// PostgresDatabaseClaim is the Schema for the postgresdatabaseclaims API. It
+// requests a database and its owner on a shared PostgresCluster or on a
+// dedicated one, and the connection details arrive in a Secret next to it.
+type PostgresDatabaseClaim struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   PostgresDatabaseClaimSpec   `json:"spec,omitempty"`
+	Status PostgresDatabaseClaimStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// PostgresDatabaseClaimList contains a list of PostgresDatabaseClaim
+type PostgresDatabaseClaimList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []PostgresDatabaseClaim `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&PostgresDatabaseClaim{}, &PostgresDatabaseClaimList{})
+}
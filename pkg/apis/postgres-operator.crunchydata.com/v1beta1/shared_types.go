@@ -57,6 +57,23 @@ type ServiceSpec struct {
 	Type string `json:"type"`
 }
 
+// Dependency names a PostgresCluster in the same namespace that must reach
+// some state before the resource that depends on it is created.
+type Dependency struct {
+	// The name of a PostgresCluster in the same namespace.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	ClusterName string `json:"clusterName"`
+
+	// The state the PostgresCluster must reach. "Ready" waits for all its
+	// instances to be ready. "BackupCompleted" waits for one of its backups
+	// to complete, such as before it is cloned. Defaults to "Ready".
+	// +optional
+	// +kubebuilder:default=Ready
+	// +kubebuilder:validation:Enum={Ready,BackupCompleted}
+	Condition string `json:"condition,omitempty"`
+}
+
 // Sidecar defines the configuration of a sidecar container
 type Sidecar struct {
 	// Resource requirements for a sidecar container
@@ -64,6 +81,20 @@ type Sidecar struct {
 	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
 }
 
+// InitContainer configures an init container that the operator adds to a pod.
+type InitContainer struct {
+	// The image of the init container, such as the same image by digest or a
+	// copy in a registry that policy permits. It must run the same commands
+	// as the image it replaces. Defaults to the image of the main container.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Resource requirements for an init container. Defaults to the resources
+	// of the main container.
+	// +optional
+	Resources *corev1.ResourceRequirements `json:"resources,omitempty"`
+}
+
 // Metadata contains metadata for custom resources
 type Metadata struct {
 	// +optional
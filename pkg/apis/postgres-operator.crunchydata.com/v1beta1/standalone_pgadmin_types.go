@@ -21,6 +21,14 @@ import (
 
 // PGAdminConfiguration represents pgAdmin configuration files.
 type StandalonePGAdminConfiguration struct {
+	// A Secret containing the value for the CONFIG_DATABASE_URI setting. This
+	// is a PostgreSQL database in which pgAdmin keeps its users, servers, and
+	// preferences rather than in SQLite on its data volume. When this is set,
+	// pgAdmin runs in a Deployment that can have more than one Pod.
+	// More info: https://www.pgadmin.org/docs/pgadmin4/latest/config_py.html
+	// +optional
+	ConfigDatabaseURI *corev1.SecretKeySelector `json:"configDatabaseURI,omitempty"`
+
 	// Files allows the user to mount projected volumes into the pgAdmin
 	// container so that files can be referenced by pgAdmin as needed.
 	Files []corev1.VolumeProjection `json:"files,omitempty"`
@@ -30,6 +38,32 @@ type StandalonePGAdminConfiguration struct {
 	// +optional
 	LDAPBindPassword *corev1.SecretKeySelector `json:"ldapBindPassword,omitempty"`
 
+	// The SMTP server through which pgAdmin sends email, such as the links
+	// of the "Forgot password" page and two-factor codes.
+	// More info: https://www.pgadmin.org/docs/pgadmin4/latest/config_py.html
+	// +optional
+	Mail *StandalonePGAdminMail `json:"mail,omitempty"`
+
+	// Two-factor authentication for pgAdmin users. When this is set, users can
+	// register a second factor that they use when they log in.
+	// More info: https://www.pgadmin.org/docs/pgadmin4/latest/mfa.html
+	// +optional
+	MFA *StandalonePGAdminMFA `json:"mfa,omitempty"`
+
+	// OAuth2 identity providers with which users can log in to pgAdmin. Each
+	// provider is a button on the login page.
+	// More info: https://www.pgadmin.org/docs/pgadmin4/latest/oauth2.html
+	// +optional
+	OAuth2 *StandalonePGAdminOAuth2 `json:"oauth2,omitempty"`
+
+	// A set of settings that harden pgAdmin. "Strict" expires idle sessions,
+	// locks accounts after failed logins, sends cookies only over HTTPS, sends
+	// security headers, and does not save passwords. Any of these can be
+	// changed in settings. Defaults to "Default", which changes nothing.
+	// +optional
+	// +kubebuilder:validation:Enum={Default,Strict}
+	SecurityProfile string `json:"securityProfile,omitempty"`
+
 	// Settings for the pgAdmin server process. Keys should be uppercase and
 	// values must be constants.
 	// More info: https://www.pgadmin.org/docs/pgadmin4/latest/config_py.html
@@ -38,6 +72,280 @@ type StandalonePGAdminConfiguration struct {
 	// +kubebuilder:validation:Schemaless
 	// +kubebuilder:validation:Type=object
 	Settings SchemalessObject `json:"settings,omitempty"`
+
+	// How many proxies in front of pgAdmin to trust for each X-Forwarded
+	// header. pgAdmin uses these headers to build redirect URLs and to log
+	// the addresses of clients.
+	// More info: https://www.pgadmin.org/docs/pgadmin4/latest/config_py.html
+	// +optional
+	TrustedProxies *StandalonePGAdminTrustedProxies `json:"trustedProxies,omitempty"`
+
+	// The path at which pgAdmin is served, such as "/pgadmin", when it shares
+	// a hostname with other applications behind a proxy. pgAdmin generates its
+	// links and cookies under this path. A proxy may also send this path in the
+	// X-Script-Name header.
+	// More info: https://www.pgadmin.org/docs/pgadmin4/latest/container_deployment.html#reverse-proxying
+	// +optional
+	// +kubebuilder:validation:MaxLength=128
+	// +kubebuilder:validation:Pattern=`^(/[-._~A-Za-z0-9]+)+$`
+	URLPrefix string `json:"urlPrefix,omitempty"`
+}
+
+// StandalonePGAdminMail represents the SMTP server of pgAdmin.
+type StandalonePGAdminMail struct {
+	// The hostname or IP address of the SMTP server, MAIL_SERVER.
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Server string `json:"server"`
+
+	// The port of the SMTP server, MAIL_PORT. Defaults to 25.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port *int32 `json:"port,omitempty"`
+
+	// Whether or not to upgrade the connection to TLS with STARTTLS,
+	// MAIL_USE_TLS.
+	// +optional
+	UseTLS bool `json:"useTLS,omitempty"`
+
+	// The name of a Secret in the namespace of the PGAdmin with the values of
+	// MAIL_USERNAME and MAIL_PASSWORD in its "username" and "password" fields,
+	// such as a Secret of type "kubernetes.io/basic-auth". When this is not
+	// set, pgAdmin does not authenticate to the SMTP server.
+	// More info: https://docs.k8s.io/concepts/configuration/secret/#basic-authentication-secret
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+}
+
+// StandalonePGAdminMFA represents the two-factor authentication of pgAdmin.
+type StandalonePGAdminMFA struct {
+	// The second factors that users can register: "authenticator" is a
+	// time-based one-time password from an application such as Google
+	// Authenticator, and "email" is a code sent by email. Sending email
+	// requires the MAIL_SERVER and related settings.
+	// +kubebuilder:validation:MinItems=1
+	// +listType=set
+	Methods []StandalonePGAdminMFAMethod `json:"methods"`
+
+	// Whether or not users must register a second factor before they can use
+	// pgAdmin. When false, registering a second factor is optional.
+	// +optional
+	Required bool `json:"required,omitempty"`
+}
+
+// +kubebuilder:validation:Enum={authenticator,email}
+type StandalonePGAdminMFAMethod string
+
+// StandalonePGAdminOAuth2 configures login to pgAdmin through OAuth2.
+type StandalonePGAdminOAuth2 struct {
+	// Whether or not to create a pgAdmin user the first time someone logs in
+	// through a provider, OAUTH2_AUTO_CREATE_USER. When false, users must be
+	// created in pgAdmin before they can log in. Defaults to true.
+	// +optional
+	AutoCreateUser *bool `json:"autoCreateUser,omitempty"`
+
+	// The identity providers, OAUTH2_CONFIG.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=8
+	// +listType=map
+	// +listMapKey=name
+	Providers []StandalonePGAdminOAuth2Provider `json:"providers"`
+}
+
+type StandalonePGAdminOAuth2Provider struct {
+	// A name that identifies the provider, OAUTH2_NAME.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[A-Za-z0-9][-_A-Za-z0-9]*$`
+	Name string `json:"name"`
+
+	// The text of the login button, OAUTH2_DISPLAY_NAME. Defaults to name.
+	// +optional
+	DisplayName string `json:"displayName,omitempty"`
+
+	// The client ID that pgAdmin is registered with at the provider,
+	// OAUTH2_CLIENT_ID.
+	// +kubebuilder:validation:MinLength=1
+	ClientID string `json:"clientID"`
+
+	// A Secret containing the client secret that pgAdmin is registered with
+	// at the provider, OAUTH2_CLIENT_SECRET.
+	ClientSecret corev1.SecretKeySelector `json:"clientSecret"`
+
+	// The authorization endpoint of the provider, OAUTH2_AUTHORIZATION_URL.
+	// +kubebuilder:validation:MinLength=1
+	AuthorizationURL string `json:"authorizationURL"`
+
+	// The token endpoint of the provider, OAUTH2_TOKEN_URL.
+	// +kubebuilder:validation:MinLength=1
+	TokenURL string `json:"tokenURL"`
+
+	// The base URL of the API of the provider, OAUTH2_API_BASE_URL.
+	// +kubebuilder:validation:MinLength=1
+	APIBaseURL string `json:"apiBaseURL"`
+
+	// The endpoint, relative to apiBaseURL, that returns the email address
+	// of a user, OAUTH2_USERINFO_ENDPOINT.
+	// +kubebuilder:validation:MinLength=1
+	UserInfoEndpoint string `json:"userInfoEndpoint"`
+
+	// The OpenID Connect discovery document of the provider,
+	// OAUTH2_SERVER_METADATA_URL.
+	// +optional
+	ServerMetadataURL string `json:"serverMetadataURL,omitempty"`
+
+	// The scopes that pgAdmin requests, OAUTH2_SCOPE. Defaults to "openid",
+	// "email", and "profile".
+	// +optional
+	// +listType=set
+	Scopes []string `json:"scopes,omitempty"`
+}
+
+// StandalonePGAdminTrustedProxies represents how many proxies pgAdmin trusts
+// for each X-Forwarded header. Zero ignores the header. When a field is unset,
+// pgAdmin uses its default: one for X-Forwarded-For and X-Forwarded-Port, and
+// zero for the others.
+type StandalonePGAdminTrustedProxies struct {
+	// The number of proxies trusted to set X-Forwarded-For, PROXY_X_FOR_COUNT.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	For *int32 `json:"for,omitempty"`
+
+	// The number of proxies trusted to set X-Forwarded-Host, PROXY_X_HOST_COUNT.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Host *int32 `json:"host,omitempty"`
+
+	// The number of proxies trusted to set X-Forwarded-Port, PROXY_X_PORT_COUNT.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Port *int32 `json:"port,omitempty"`
+
+	// The number of proxies trusted to set X-Forwarded-Prefix, PROXY_X_PREFIX_COUNT.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Prefix *int32 `json:"prefix,omitempty"`
+
+	// The number of proxies trusted to set X-Forwarded-Proto, PROXY_X_PROTO_COUNT.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Proto *int32 `json:"proto,omitempty"`
+}
+
+// StandalonePGAdminProbes is the timing of the probes of the pgAdmin container.
+type StandalonePGAdminProbes struct {
+	// How long pgAdmin may take to start, including any upgrade of its data.
+	// Defaults to checking every 10 seconds for up to 5 minutes.
+	// +optional
+	Startup *StandalonePGAdminProbe `json:"startup,omitempty"`
+
+	// When pgAdmin receives traffic. Defaults to checking every 10 seconds
+	// and stopping traffic after 3 failures.
+	// +optional
+	Readiness *StandalonePGAdminProbe `json:"readiness,omitempty"`
+
+	// When pgAdmin is restarted. Defaults to checking every 20 seconds and
+	// restarting after 3 failures.
+	// +optional
+	Liveness *StandalonePGAdminProbe `json:"liveness,omitempty"`
+}
+
+// StandalonePGAdminProbe is the timing of one probe. Fields that are not set
+// keep their defaults.
+type StandalonePGAdminProbe struct {
+	// Seconds after the container starts before the probe is first checked.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	InitialDelaySeconds *int32 `json:"initialDelaySeconds,omitempty"`
+
+	// Seconds between checks of the probe.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	PeriodSeconds *int32 `json:"periodSeconds,omitempty"`
+
+	// Seconds that pgAdmin has to answer each check.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// Failed checks in a row for the probe to fail.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+}
+
+// StandalonePGAdminGunicorn configures gunicorn. pgAdmin supports only one
+// gunicorn worker, so its threads are how many requests it handles at once.
+type StandalonePGAdminGunicorn struct {
+	// The number of threads that handle requests. Each browser session can
+	// hold a thread while a query runs. Defaults to 25.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=1000
+	Threads *int32 `json:"threads,omitempty"`
+
+	// Seconds that the worker may be silent before gunicorn restarts it. Zero
+	// waits forever. Defaults to 86400, one day, so that long queries finish.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+
+	// Whether or not to log each request to the output of the container.
+	// Defaults to false.
+	// +optional
+	AccessLog bool `json:"accessLog,omitempty"`
+}
+
+// StandalonePGAdminIngress is an Ingress that routes requests to pgAdmin.
+type StandalonePGAdminIngress struct {
+	// +optional
+	Metadata *Metadata `json:"metadata,omitempty"`
+
+	// The fully qualified domain name at which pgAdmin is served, such as
+	// "pgadmin.example.com". Requests for config.urlPrefix on this host, or
+	// for any path when that is not set, go to pgAdmin.
+	// More info: https://docs.k8s.io/concepts/services-networking/ingress/#ingress-rules
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=253
+	Host string `json:"host"`
+
+	// The name of the IngressClass that implements the Ingress. Defaults to
+	// the default IngressClass of the Kubernetes cluster.
+	// More info: https://docs.k8s.io/concepts/services-networking/ingress/#ingress-class
+	// +optional
+	IngressClassName *string `json:"ingressClassName,omitempty"`
+
+	// The name of a Secret in the namespace of the PGAdmin with the certificate
+	// and private key of host in its "tls.crt" and "tls.key" fields. When this
+	// is set, the Ingress accepts HTTPS for host.
+	// More info: https://docs.k8s.io/concepts/services-networking/ingress/#tls
+	// +optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+}
+
+// StandalonePGAdminService is the Service that exposes pgAdmin.
+type StandalonePGAdminService struct {
+	ServiceSpec `json:",inline"`
+
+	// The client IP ranges allowed to reach pgAdmin when type is LoadBalancer
+	// and the cloud provider supports it, such as "203.0.113.0/24".
+	// More info: https://docs.k8s.io/tasks/access-application-cluster/create-external-load-balancer/#restricting-access-to-a-load-balancer
+	// +optional
+	// +listType=atomic
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+}
+
+// StandalonePGAdminTLS is the certificate of the pgAdmin web server.
+type StandalonePGAdminTLS struct {
+	// The name of a Secret in the namespace of the PGAdmin with the certificate
+	// and private key of pgAdmin in its "tls.crt" and "tls.key" fields, such
+	// as a Secret of type "kubernetes.io/tls".
+	// More info: https://docs.k8s.io/concepts/configuration/secret/#tls-secrets
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	SecretName string `json:"secretName"`
 }
 
 // PGAdminSpec defines the desired state of PGAdmin
@@ -52,11 +360,74 @@ type PGAdminSpec struct {
 	// +optional
 	Config StandalonePGAdminConfiguration `json:"config,omitempty"`
 
+	// Serve pgAdmin over HTTPS with a certificate from a Secret. When this is
+	// set, pgAdmin runs in gunicorn and its port accepts only HTTPS.
+	// +optional
+	TLS *StandalonePGAdminTLS `json:"tls,omitempty"`
+
+	// Settings for gunicorn, the web server that handles the requests of many
+	// browser sessions. When this is set, pgAdmin runs in gunicorn.
+	// More info: https://docs.gunicorn.org/en/stable/settings.html
+	// +optional
+	Gunicorn *StandalonePGAdminGunicorn `json:"gunicorn,omitempty"`
+
+	// An Ingress that routes requests for a hostname to the Service of
+	// pgAdmin. When this is set, pgAdmin trusts one proxy for each of the
+	// X-Forwarded-For, -Host, -Port, and -Proto headers unless trustedProxies
+	// says otherwise. When spec.tls is also set, the ingress controller must
+	// connect to pgAdmin with HTTPS, which is usually set by an annotation.
+	// More info: https://docs.k8s.io/concepts/services-networking/ingress/
+	// +optional
+	Ingress *StandalonePGAdminIngress `json:"ingress,omitempty"`
+
+	// The name of the Service that exposes pgAdmin. Defaults to a name
+	// generated from the UID of the PGAdmin. When this changes, the Service
+	// with the previous name is deleted.
+	// +optional
+	// +kubebuilder:validation:MaxLength=63
+	// +kubebuilder:validation:Pattern=`^[a-z]([-a-z0-9]*[a-z0-9])?$`
+	ServiceName string `json:"serviceName,omitempty"`
+
+	// Specification of the Service that exposes pgAdmin. Changes to these
+	// values are applied to the existing Service.
+	// More info: https://docs.k8s.io/concepts/services-networking/service/
+	// +optional
+	Service *StandalonePGAdminService `json:"service,omitempty"`
+
 	// Defines a PersistentVolumeClaim for pgAdmin data.
 	// More info: https://kubernetes.io/docs/concepts/storage/persistent-volumes
 	// +kubebuilder:validation:Required
 	DataVolumeClaimSpec corev1.PersistentVolumeClaimSpec `json:"dataVolumeClaimSpec"`
 
+	// Defines a PersistentVolumeClaim for the files of pgAdmin users, such as
+	// exported query results and uploads. When this is set, pgAdmin keeps
+	// those files, its STORAGE_DIR, on this volume rather than with its data.
+	// Removing this field leaves the volume in place until the PGAdmin is
+	// deleted, but pgAdmin stops using it.
+	// More info: https://www.pgadmin.org/docs/pgadmin4/latest/storage_manager.html
+	// +optional
+	StorageVolumeClaimSpec *corev1.PersistentVolumeClaimSpec `json:"storageVolumeClaimSpec,omitempty"`
+
+	// PostgresClusters that must reach some state before this pgAdmin is
+	// created. Once they have, they are not checked again.
+	// +optional
+	// +listType=atomic
+	DependsOn []Dependency `json:"dependsOn,omitempty"`
+
+	// Python packages to install with pip before pgAdmin starts, such as
+	// additional database drivers or pgAdmin extensions. Each is a package name
+	// with optional extras and version, like "psycopg[binary]==3.1.18". They
+	// are installed from the package index that pip uses in the image into a
+	// volume that is discarded with the Pod. Changing this value causes pgAdmin
+	// to restart.
+	// More info: https://pip.pypa.io/en/stable/reference/requirement-specifiers/
+	// +optional
+	// +listType=set
+	// +kubebuilder:validation:MaxItems=32
+	// +kubebuilder:validation:items:MaxLength=128
+	// +kubebuilder:validation:items:Pattern=`^[A-Za-z0-9][-A-Za-z0-9._]*(\[[-A-Za-z0-9._,]+\])?((===?|[<>!~]=|[<>])[-A-Za-z0-9.*+!]+)?$`
+	ExtraPythonPackages []string `json:"extraPythonPackages,omitempty"`
+
 	// The image name to use for pgAdmin instance.
 	// +optional
 	Image *string `json:"image,omitempty"`
@@ -74,10 +445,24 @@ type PGAdminSpec struct {
 	// +optional
 	ImagePullSecrets []corev1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
 
+	// The number of pgAdmin Pods when spec.config.configDatabaseURI is set.
+	// Each Pod keeps its own login sessions, so clients must stay with one
+	// Pod, such as by session affinity. Without an external configuration
+	// database, pgAdmin runs in one Pod. Defaults to 2.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	Replicas *int32 `json:"replicas,omitempty"`
+
 	// Resource requirements for the PGAdmin container.
 	// +optional
 	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
 
+	// The timing of the probes of the PGAdmin container. Each probe requests
+	// the /misc/ping endpoint of pgAdmin on its port.
+	// More info: https://kubernetes.io/docs/concepts/workloads/pods/pod-lifecycle#container-probes
+	// +optional
+	Probes *StandalonePGAdminProbes `json:"probes,omitempty"`
+
 	// Scheduling constraints of the PGAdmin pod.
 	// More info: https://kubernetes.io/docs/concepts/scheduling-eviction/assign-pod-node
 	// +optional
@@ -89,6 +474,11 @@ type PGAdminSpec struct {
 	// +optional
 	PriorityClassName *string `json:"priorityClassName,omitempty"`
 
+	// Whether or not pgAdmin is being deployed to an OpenShift environment.
+	// If the field is unset, the operator detects it from the namespace.
+	// +optional
+	OpenShift *bool `json:"openshift,omitempty"`
+
 	// Tolerations of the PGAdmin pod.
 	// More info: https://kubernetes.io/docs/concepts/scheduling-eviction/taint-and-toleration
 	// +optional
@@ -101,6 +491,33 @@ type PGAdminSpec struct {
 	// added manually.
 	// +optional
 	ServerGroups []ServerGroup `json:"serverGroups"`
+
+	// pgAdmin users that are managed by the operator. Each user is created
+	// when it is missing, and its role and password are updated to match.
+	// Users removed from this list are not removed from pgAdmin. The servers
+	// of ServerGroups are loaded for each of these users and for the
+	// administrator.
+	// +optional
+	// +listType=map
+	// +listMapKey=username
+	Users []PGAdminUser `json:"users,omitempty"`
+}
+
+type PGAdminUser struct {
+	// The name that the user logs in with, usually an email address.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=256
+	Username string `json:"username"`
+
+	// Whether or not the user can administer pgAdmin, such as by managing
+	// users. Defaults to "User".
+	// +optional
+	// +kubebuilder:validation:Enum={Administrator,User}
+	Role string `json:"role,omitempty"`
+
+	// A Secret containing the password of the user. pgAdmin requires
+	// passwords of at least six characters.
+	PasswordRef corev1.SecretKeySelector `json:"passwordRef"`
 }
 
 type ServerGroup struct {
@@ -117,8 +534,8 @@ type ServerGroup struct {
 type PGAdminStatus struct {
 
 	// conditions represent the observations of pgadmin's current state.
-	// Known .status.conditions.type are: "PersistentVolumeResizing",
-	// "Progressing", "ProxyAvailable"
+	// Known .status.conditions.type are: "DependenciesSatisfied",
+	// "PersistentVolumeResizing", "Progressing", "ProxyAvailable"
 	// +optional
 	// +listType=map
 	// +listMapKey=type
@@ -0,0 +1,74 @@
+// Copyright 2021 - 2024 Crunchy Data Solutions, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TuningSpec defines how PostgreSQL parameters are derived from the resources
+// of its instances.
+type TuningSpec struct {
+	// "Auto" derives memory, WAL, parallelism, and planner parameters from the
+	// memory and CPU limits and the data volumes of instances. Planner costs
+	// follow the StorageClass of those volumes: "SSD" or "HDD" in its
+	// postgres-operator.crunchydata.com/storage-media annotation, or the disk
+	// type of well-known provisioners. They are derived again when those
+	// change. "Manual" derives nothing.
+	// Some parameters, such as shared_buffers, take effect after PostgreSQL restarts.
+	// +required
+	// +kubebuilder:validation:Enum={Auto,Manual}
+	Mode string `json:"mode"`
+
+	// "Observed" derives max_wal_size and checkpoint_timeout from the rate at
+	// which the primary writes WAL and derives them again as that rate changes.
+	// max_wal_size stays between 1GB and a quarter of the smallest WAL volume;
+	// checkpoint_timeout stays between 5min and 15min. Each decision is recorded
+	// in status.checkpointTuning and as an event. "Off" derives them as mode says.
+	// More info: https://www.postgresql.org/docs/current/wal-configuration.html
+	// +optional
+	// +kubebuilder:validation:Enum={Observed,Off}
+	Checkpoints string `json:"checkpoints,omitempty"`
+}
+
+// CheckpointTuningStatus records the rate at which the primary writes WAL and
+// the checkpoint parameters derived from it.
+type CheckpointTuningStatus struct {
+	// The WAL location of the primary when it was last observed.
+	// +optional
+	WALLocation string `json:"walLocation,omitempty"`
+
+	// When the WAL location was last observed.
+	// +optional
+	ObservedTime *metav1.Time `json:"observedTime,omitempty"`
+
+	// Bytes of WAL written per second. This follows increases immediately and
+	// decreases gradually.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	WriteRate int64 `json:"writeRate,omitempty"`
+
+	// The max_wal_size derived from writeRate
+	// +optional
+	MaxWALSize string `json:"maxWALSize,omitempty"`
+
+	// The checkpoint_timeout derived from writeRate
+	// +optional
+	CheckpointTimeout string `json:"checkpointTimeout,omitempty"`
+
+	// When maxWALSize or checkpointTimeout last changed
+	// +optional
+	TunedTime *metav1.Time `json:"tunedTime,omitempty"`
+}
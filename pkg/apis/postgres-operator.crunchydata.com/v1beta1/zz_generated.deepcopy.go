@@ -87,6 +87,11 @@ func (in *BackupJobs) DeepCopy() *BackupJobs {
 func (in *Backups) DeepCopyInto(out *Backups) {
 	*out = *in
 	in.PGBackRest.DeepCopyInto(&out.PGBackRest)
+	if in.WALStreaming != nil {
+		in, out := &in.WALStreaming, &out.WALStreaming
+		*out = new(WALStreamingSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Backups.
@@ -99,6 +104,70 @@ func (in *Backups) DeepCopy() *Backups {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CertificateAuthorityStatus) DeepCopyInto(out *CertificateAuthorityStatus) {
+	*out = *in
+	if in.ReplacedAt != nil {
+		in, out := &in.ReplacedAt, &out.ReplacedAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CertificateAuthorityStatus.
+func (in *CertificateAuthorityStatus) DeepCopy() *CertificateAuthorityStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CertificateAuthorityStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChangeProtectionStatus) DeepCopyInto(out *ChangeProtectionStatus) {
+	*out = *in
+	if in.History != nil {
+		in, out := &in.History, &out.History
+		*out = make([]ProtectedChange, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChangeProtectionStatus.
+func (in *ChangeProtectionStatus) DeepCopy() *ChangeProtectionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ChangeProtectionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CheckpointTuningStatus) DeepCopyInto(out *CheckpointTuningStatus) {
+	*out = *in
+	if in.ObservedTime != nil {
+		in, out := &in.ObservedTime, &out.ObservedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.TunedTime != nil {
+		in, out := &in.TunedTime, &out.TunedTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CheckpointTuningStatus.
+func (in *CheckpointTuningStatus) DeepCopy() *CheckpointTuningStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CheckpointTuningStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterUpgrade) DeepCopyInto(out *ClusterUpgrade) {
 	*out = *in
@@ -125,6 +194,56 @@ func (in *ClusterUpgrade) DeepCopy() *ClusterUpgrade {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CollationsStatus) DeepCopyInto(out *CollationsStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CollationsStatus.
+func (in *CollationsStatus) DeepCopy() *CollationsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CollationsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ConnectionSecretReference) DeepCopyInto(out *ConnectionSecretReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ConnectionSecretReference.
+func (in *ConnectionSecretReference) DeepCopy() *ConnectionSecretReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ConnectionSecretReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CrashStatus) DeepCopyInto(out *CrashStatus) {
+	*out = *in
+	if in.Time != nil {
+		in, out := &in.Time, &out.Time
+		*out = new(v1.Time)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CrashStatus.
+func (in *CrashStatus) DeepCopy() *CrashStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CrashStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CrunchyBridgeCluster) DeepCopyInto(out *CrunchyBridgeCluster) {
 	*out = *in
@@ -280,6 +399,91 @@ func (in *CrunchyBridgeClusterStatus) DeepCopy() *CrunchyBridgeClusterStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataChecksumsSpec) DeepCopyInto(out *DataChecksumsSpec) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(DataChecksumsVerification)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataChecksumsSpec.
+func (in *DataChecksumsSpec) DeepCopy() *DataChecksumsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DataChecksumsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataChecksumsStatus) DeepCopyInto(out *DataChecksumsStatus) {
+	*out = *in
+	if in.LastVerificationTime != nil {
+		in, out := &in.LastVerificationTime, &out.LastVerificationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataChecksumsStatus.
+func (in *DataChecksumsStatus) DeepCopy() *DataChecksumsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DataChecksumsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataChecksumsVerification) DeepCopyInto(out *DataChecksumsVerification) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataChecksumsVerification.
+func (in *DataChecksumsVerification) DeepCopy() *DataChecksumsVerification {
+	if in == nil {
+		return nil
+	}
+	out := new(DataChecksumsVerification)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataMaskingSpec) DeepCopyInto(out *DataMaskingSpec) {
+	*out = *in
+	if in.Scripts != nil {
+		in, out := &in.Scripts, &out.Scripts
+		*out = make([]DatabaseInitSQL, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataMaskingSpec.
+func (in *DataMaskingSpec) DeepCopy() *DataMaskingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DataMaskingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DataSource) DeepCopyInto(out *DataSource) {
 	*out = *in
@@ -288,6 +492,11 @@ func (in *DataSource) DeepCopyInto(out *DataSource) {
 		*out = new(PGBackRestDataSource)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Masking != nil {
+		in, out := &in.Masking, &out.Masking
+		*out = new(DataMaskingSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.PostgresCluster != nil {
 		in, out := &in.PostgresCluster, &out.PostgresCluster
 		*out = new(PostgresClusterDataSource)
@@ -370,6 +579,66 @@ func (in *DatabaseInitSQL) DeepCopy() *DatabaseInitSQL {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Dependency) DeepCopyInto(out *Dependency) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Dependency.
+func (in *Dependency) DeepCopy() *Dependency {
+	if in == nil {
+		return nil
+	}
+	out := new(Dependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiagnosticsSpec) DeepCopyInto(out *DiagnosticsSpec) {
+	*out = *in
+	if in.Keep != nil {
+		in, out := &in.Keep, &out.Keep
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiagnosticsSpec.
+func (in *DiagnosticsSpec) DeepCopy() *DiagnosticsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DiagnosticsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiagnosticsStatus) DeepCopyInto(out *DiagnosticsStatus) {
+	*out = *in
+	if in.ObservedTime != nil {
+		in, out := &in.ObservedTime, &out.ObservedTime
+		*out = new(v1.Time)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastCrash != nil {
+		in, out := &in.LastCrash, &out.LastCrash
+		*out = new(CrashStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiagnosticsStatus.
+func (in *DiagnosticsStatus) DeepCopy() *DiagnosticsStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DiagnosticsStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExporterSpec) DeepCopyInto(out *ExporterSpec) {
 	*out = *in
@@ -399,769 +668,585 @@ func (in *ExporterSpec) DeepCopy() *ExporterSpec {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *InstanceSidecars) DeepCopyInto(out *InstanceSidecars) {
+func (in *FaultInjectionStatus) DeepCopyInto(out *FaultInjectionStatus) {
 	*out = *in
-	if in.ReplicaCertCopy != nil {
-		in, out := &in.ReplicaCertCopy, &out.ReplicaCertCopy
-		*out = new(Sidecar)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceSidecars.
-func (in *InstanceSidecars) DeepCopy() *InstanceSidecars {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FaultInjectionStatus.
+func (in *FaultInjectionStatus) DeepCopy() *FaultInjectionStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(InstanceSidecars)
+	out := new(FaultInjectionStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Metadata) DeepCopyInto(out *Metadata) {
+func (in *HealthProbesSpec) DeepCopyInto(out *HealthProbesSpec) {
 	*out = *in
-	if in.Labels != nil {
-		in, out := &in.Labels, &out.Labels
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	if in.MaxReplicaLagSeconds != nil {
+		in, out := &in.MaxReplicaLagSeconds, &out.MaxReplicaLagSeconds
+		*out = new(int32)
+		**out = **in
 	}
-	if in.Annotations != nil {
-		in, out := &in.Annotations, &out.Annotations
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metadata.
-func (in *Metadata) DeepCopy() *Metadata {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthProbesSpec.
+func (in *HealthProbesSpec) DeepCopy() *HealthProbesSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(Metadata)
+	out := new(HealthProbesSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MonitoringSpec) DeepCopyInto(out *MonitoringSpec) {
+func (in *HealthProbesStatus) DeepCopyInto(out *HealthProbesStatus) {
 	*out = *in
-	if in.PGMonitor != nil {
-		in, out := &in.PGMonitor, &out.PGMonitor
-		*out = new(PGMonitorSpec)
-		(*in).DeepCopyInto(*out)
+	if in.LastProbeTime != nil {
+		in, out := &in.LastProbeTime, &out.LastProbeTime
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringSpec.
-func (in *MonitoringSpec) DeepCopy() *MonitoringSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new HealthProbesStatus.
+func (in *HealthProbesStatus) DeepCopy() *HealthProbesStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(MonitoringSpec)
+	out := new(HealthProbesStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *MonitoringStatus) DeepCopyInto(out *MonitoringStatus) {
+func (in *InitContainer) DeepCopyInto(out *InitContainer) {
 	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringStatus.
-func (in *MonitoringStatus) DeepCopy() *MonitoringStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitContainer.
+func (in *InitContainer) DeepCopy() *InitContainer {
 	if in == nil {
 		return nil
 	}
-	out := new(MonitoringStatus)
+	out := new(InitContainer)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGAdmin) DeepCopyInto(out *PGAdmin) {
+func (in *InitdbSpec) DeepCopyInto(out *InitdbSpec) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.WALSegmentSize != nil {
+		in, out := &in.WALSegmentSize, &out.WALSegmentSize
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAdmin.
-func (in *PGAdmin) DeepCopy() *PGAdmin {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InitdbSpec.
+func (in *InitdbSpec) DeepCopy() *InitdbSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PGAdmin)
+	out := new(InitdbSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PGAdmin) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceInitContainers) DeepCopyInto(out *InstanceInitContainers) {
+	*out = *in
+	if in.Startup != nil {
+		in, out := &in.Startup, &out.Startup
+		*out = new(InitContainer)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NSSWrapper != nil {
+		in, out := &in.NSSWrapper, &out.NSSWrapper
+		*out = new(InitContainer)
+		(*in).DeepCopyInto(*out)
 	}
-	return nil
 }
 
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGAdminConfiguration) DeepCopyInto(out *PGAdminConfiguration) {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceInitContainers.
+func (in *InstanceInitContainers) DeepCopy() *InstanceInitContainers {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceInitContainers)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceSetAutoscaling) DeepCopyInto(out *InstanceSetAutoscaling) {
 	*out = *in
-	if in.Files != nil {
-		in, out := &in.Files, &out.Files
-		*out = make([]corev1.VolumeProjection, len(*in))
+	if in.Schedules != nil {
+		in, out := &in.Schedules, &out.Schedules
+		*out = make([]InstanceSetReplicaSchedule, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.LDAPBindPassword != nil {
-		in, out := &in.LDAPBindPassword, &out.LDAPBindPassword
-		*out = new(corev1.SecretKeySelector)
+	if in.Demand != nil {
+		in, out := &in.Demand, &out.Demand
+		*out = new(InstanceSetDemandScaling)
 		(*in).DeepCopyInto(*out)
 	}
-	in.Settings.DeepCopyInto(&out.Settings)
+	if in.ScaleDownDelaySeconds != nil {
+		in, out := &in.ScaleDownDelaySeconds, &out.ScaleDownDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAdminConfiguration.
-func (in *PGAdminConfiguration) DeepCopy() *PGAdminConfiguration {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceSetAutoscaling.
+func (in *InstanceSetAutoscaling) DeepCopy() *InstanceSetAutoscaling {
 	if in == nil {
 		return nil
 	}
-	out := new(PGAdminConfiguration)
+	out := new(InstanceSetAutoscaling)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGAdminList) DeepCopyInto(out *PGAdminList) {
+func (in *InstanceSetAutoscalingStatus) DeepCopyInto(out *InstanceSetAutoscalingStatus) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]PGAdmin, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.ScaleDownTime != nil {
+		in, out := &in.ScaleDownTime, &out.ScaleDownTime
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAdminList.
-func (in *PGAdminList) DeepCopy() *PGAdminList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceSetAutoscalingStatus.
+func (in *InstanceSetAutoscalingStatus) DeepCopy() *InstanceSetAutoscalingStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PGAdminList)
+	out := new(InstanceSetAutoscalingStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PGAdminList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGAdminPodSpec) DeepCopyInto(out *PGAdminPodSpec) {
+func (in *InstanceSetDemandScaling) DeepCopyInto(out *InstanceSetDemandScaling) {
 	*out = *in
-	if in.Metadata != nil {
-		in, out := &in.Metadata, &out.Metadata
-		*out = new(Metadata)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Affinity != nil {
-		in, out := &in.Affinity, &out.Affinity
-		*out = new(corev1.Affinity)
-		(*in).DeepCopyInto(*out)
-	}
-	in.Config.DeepCopyInto(&out.Config)
-	in.DataVolumeClaimSpec.DeepCopyInto(&out.DataVolumeClaimSpec)
-	if in.PriorityClassName != nil {
-		in, out := &in.PriorityClassName, &out.PriorityClassName
-		*out = new(string)
+	if in.CreateScaledObject != nil {
+		in, out := &in.CreateScaledObject, &out.CreateScaledObject
+		*out = new(bool)
 		**out = **in
 	}
-	if in.Replicas != nil {
-		in, out := &in.Replicas, &out.Replicas
+	if in.ActiveConnectionsPerReplica != nil {
+		in, out := &in.ActiveConnectionsPerReplica, &out.ActiveConnectionsPerReplica
 		*out = new(int32)
 		**out = **in
 	}
-	in.Resources.DeepCopyInto(&out.Resources)
-	if in.Service != nil {
-		in, out := &in.Service, &out.Service
-		*out = new(ServiceSpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]corev1.Toleration, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.TopologySpreadConstraints != nil {
-		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
-		*out = make([]corev1.TopologySpreadConstraint, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAdminPodSpec.
-func (in *PGAdminPodSpec) DeepCopy() *PGAdminPodSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceSetDemandScaling.
+func (in *InstanceSetDemandScaling) DeepCopy() *InstanceSetDemandScaling {
 	if in == nil {
 		return nil
 	}
-	out := new(PGAdminPodSpec)
+	out := new(InstanceSetDemandScaling)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGAdminPodStatus) DeepCopyInto(out *PGAdminPodStatus) {
+func (in *InstanceSetReplicaSchedule) DeepCopyInto(out *InstanceSetReplicaSchedule) {
 	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAdminPodStatus.
-func (in *PGAdminPodStatus) DeepCopy() *PGAdminPodStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceSetReplicaSchedule.
+func (in *InstanceSetReplicaSchedule) DeepCopy() *InstanceSetReplicaSchedule {
 	if in == nil {
 		return nil
 	}
-	out := new(PGAdminPodStatus)
+	out := new(InstanceSetReplicaSchedule)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGAdminSpec) DeepCopyInto(out *PGAdminSpec) {
+func (in *InstanceSidecars) DeepCopyInto(out *InstanceSidecars) {
 	*out = *in
-	if in.Metadata != nil {
-		in, out := &in.Metadata, &out.Metadata
-		*out = new(Metadata)
+	if in.ReplicaCertCopy != nil {
+		in, out := &in.ReplicaCertCopy, &out.ReplicaCertCopy
+		*out = new(Sidecar)
 		(*in).DeepCopyInto(*out)
 	}
-	in.Config.DeepCopyInto(&out.Config)
-	in.DataVolumeClaimSpec.DeepCopyInto(&out.DataVolumeClaimSpec)
-	if in.Image != nil {
-		in, out := &in.Image, &out.Image
-		*out = new(string)
-		**out = **in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceSidecars.
+func (in *InstanceSidecars) DeepCopy() *InstanceSidecars {
+	if in == nil {
+		return nil
 	}
-	if in.ImagePullSecrets != nil {
-		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
-		*out = make([]corev1.LocalObjectReference, len(*in))
+	out := new(InstanceSidecars)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IntegrityChecksSpec) DeepCopyInto(out *IntegrityChecksSpec) {
+	*out = *in
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
-	in.Resources.DeepCopyInto(&out.Resources)
-	if in.Affinity != nil {
-		in, out := &in.Affinity, &out.Affinity
-		*out = new(corev1.Affinity)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.PriorityClassName != nil {
-		in, out := &in.PriorityClassName, &out.PriorityClassName
-		*out = new(string)
+	if in.Connections != nil {
+		in, out := &in.Connections, &out.Connections
+		*out = new(int32)
 		**out = **in
 	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]corev1.Toleration, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.ServerGroups != nil {
-		in, out := &in.ServerGroups, &out.ServerGroups
-		*out = make([]ServerGroup, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAdminSpec.
-func (in *PGAdminSpec) DeepCopy() *PGAdminSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntegrityChecksSpec.
+func (in *IntegrityChecksSpec) DeepCopy() *IntegrityChecksSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PGAdminSpec)
+	out := new(IntegrityChecksSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGAdminStatus) DeepCopyInto(out *PGAdminStatus) {
+func (in *IntegrityChecksStatus) DeepCopyInto(out *IntegrityChecksStatus) {
 	*out = *in
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.LastCheckTime != nil {
+		in, out := &in.LastCheckTime, &out.LastCheckTime
+		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAdminStatus.
-func (in *PGAdminStatus) DeepCopy() *PGAdminStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntegrityChecksStatus.
+func (in *IntegrityChecksStatus) DeepCopy() *IntegrityChecksStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PGAdminStatus)
+	out := new(IntegrityChecksStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGBackRestArchive) DeepCopyInto(out *PGBackRestArchive) {
+func (in *JobsSpec) DeepCopyInto(out *JobsSpec) {
 	*out = *in
-	if in.Metadata != nil {
-		in, out := &in.Metadata, &out.Metadata
-		*out = new(Metadata)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Configuration != nil {
-		in, out := &in.Configuration, &out.Configuration
-		*out = make([]corev1.VolumeProjection, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.Global != nil {
-		in, out := &in.Global, &out.Global
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Jobs != nil {
-		in, out := &in.Jobs, &out.Jobs
-		*out = new(BackupJobs)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Repos != nil {
-		in, out := &in.Repos, &out.Repos
-		*out = make([]PGBackRestRepo, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.RepoHost != nil {
-		in, out := &in.RepoHost, &out.RepoHost
-		*out = new(PGBackRestRepoHost)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Manual != nil {
-		in, out := &in.Manual, &out.Manual
-		*out = new(PGBackRestManualBackup)
-		(*in).DeepCopyInto(*out)
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
 	}
-	if in.Restore != nil {
-		in, out := &in.Restore, &out.Restore
-		*out = new(PGBackRestRestore)
-		(*in).DeepCopyInto(*out)
+	if in.BackoffLimit != nil {
+		in, out := &in.BackoffLimit, &out.BackoffLimit
+		*out = new(int32)
+		**out = **in
 	}
-	if in.Sidecars != nil {
-		in, out := &in.Sidecars, &out.Sidecars
-		*out = new(PGBackRestSidecars)
-		(*in).DeepCopyInto(*out)
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestArchive.
-func (in *PGBackRestArchive) DeepCopy() *PGBackRestArchive {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobsSpec.
+func (in *JobsSpec) DeepCopy() *JobsSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PGBackRestArchive)
+	out := new(JobsSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGBackRestBackupSchedules) DeepCopyInto(out *PGBackRestBackupSchedules) {
+func (in *LifecycleHooksStatus) DeepCopyInto(out *LifecycleHooksStatus) {
 	*out = *in
-	if in.Full != nil {
-		in, out := &in.Full, &out.Full
-		*out = new(string)
-		**out = **in
-	}
-	if in.Differential != nil {
-		in, out := &in.Differential, &out.Differential
-		*out = new(string)
-		**out = **in
-	}
-	if in.Incremental != nil {
-		in, out := &in.Incremental, &out.Incremental
-		*out = new(string)
-		**out = **in
+	if in.Called != nil {
+		in, out := &in.Called, &out.Called
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestBackupSchedules.
-func (in *PGBackRestBackupSchedules) DeepCopy() *PGBackRestBackupSchedules {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LifecycleHooksStatus.
+func (in *LifecycleHooksStatus) DeepCopy() *LifecycleHooksStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PGBackRestBackupSchedules)
+	out := new(LifecycleHooksStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGBackRestDataSource) DeepCopyInto(out *PGBackRestDataSource) {
+func (in *MaintenanceSpec) DeepCopyInto(out *MaintenanceSpec) {
 	*out = *in
-	if in.Configuration != nil {
-		in, out := &in.Configuration, &out.Configuration
-		*out = make([]corev1.VolumeProjection, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	in.Window.DeepCopyInto(&out.Window)
+	if in.BloatThreshold != nil {
+		in, out := &in.BloatThreshold, &out.BloatThreshold
+		*out = new(int32)
+		**out = **in
 	}
-	if in.Global != nil {
-		in, out := &in.Global, &out.Global
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	in.Repo.DeepCopyInto(&out.Repo)
-	if in.Options != nil {
-		in, out := &in.Options, &out.Options
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
 	in.Resources.DeepCopyInto(&out.Resources)
-	if in.Affinity != nil {
-		in, out := &in.Affinity, &out.Affinity
-		*out = new(corev1.Affinity)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.PriorityClassName != nil {
-		in, out := &in.PriorityClassName, &out.PriorityClassName
-		*out = new(string)
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
 		**out = **in
 	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]corev1.Toleration, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestDataSource.
-func (in *PGBackRestDataSource) DeepCopy() *PGBackRestDataSource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceSpec.
+func (in *MaintenanceSpec) DeepCopy() *MaintenanceSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PGBackRestDataSource)
+	out := new(MaintenanceSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGBackRestJobStatus) DeepCopyInto(out *PGBackRestJobStatus) {
+func (in *MaintenanceStatus) DeepCopyInto(out *MaintenanceStatus) {
 	*out = *in
-	if in.StartTime != nil {
-		in, out := &in.StartTime, &out.StartTime
-		*out = (*in).DeepCopy()
-	}
-	if in.CompletionTime != nil {
-		in, out := &in.CompletionTime, &out.CompletionTime
+	if in.LastMaintenanceTime != nil {
+		in, out := &in.LastMaintenanceTime, &out.LastMaintenanceTime
 		*out = (*in).DeepCopy()
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestJobStatus.
-func (in *PGBackRestJobStatus) DeepCopy() *PGBackRestJobStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceStatus.
+func (in *MaintenanceStatus) DeepCopy() *MaintenanceStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PGBackRestJobStatus)
+	out := new(MaintenanceStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGBackRestManualBackup) DeepCopyInto(out *PGBackRestManualBackup) {
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
 	*out = *in
-	if in.Options != nil {
-		in, out := &in.Options, &out.Options
-		*out = make([]string, len(*in))
-		copy(*out, *in)
+	if in.DurationSeconds != nil {
+		in, out := &in.DurationSeconds, &out.DurationSeconds
+		*out = new(int64)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestManualBackup.
-func (in *PGBackRestManualBackup) DeepCopy() *PGBackRestManualBackup {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
 	if in == nil {
 		return nil
 	}
-	out := new(PGBackRestManualBackup)
+	out := new(MaintenanceWindow)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGBackRestRepo) DeepCopyInto(out *PGBackRestRepo) {
+func (in *Metadata) DeepCopyInto(out *Metadata) {
 	*out = *in
-	if in.BackupSchedules != nil {
-		in, out := &in.BackupSchedules, &out.BackupSchedules
-		*out = new(PGBackRestBackupSchedules)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Azure != nil {
-		in, out := &in.Azure, &out.Azure
-		*out = new(RepoAzure)
-		**out = **in
-	}
-	if in.GCS != nil {
-		in, out := &in.GCS, &out.GCS
-		*out = new(RepoGCS)
-		**out = **in
-	}
-	if in.S3 != nil {
-		in, out := &in.S3, &out.S3
-		*out = new(RepoS3)
-		**out = **in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
-	if in.Volume != nil {
-		in, out := &in.Volume, &out.Volume
-		*out = new(RepoPVC)
-		(*in).DeepCopyInto(*out)
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestRepo.
-func (in *PGBackRestRepo) DeepCopy() *PGBackRestRepo {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metadata.
+func (in *Metadata) DeepCopy() *Metadata {
 	if in == nil {
 		return nil
 	}
-	out := new(PGBackRestRepo)
+	out := new(Metadata)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGBackRestRepoHost) DeepCopyInto(out *PGBackRestRepoHost) {
+func (in *MonitoringSpec) DeepCopyInto(out *MonitoringSpec) {
 	*out = *in
-	if in.Affinity != nil {
-		in, out := &in.Affinity, &out.Affinity
-		*out = new(corev1.Affinity)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.PriorityClassName != nil {
-		in, out := &in.PriorityClassName, &out.PriorityClassName
-		*out = new(string)
-		**out = **in
-	}
-	in.Resources.DeepCopyInto(&out.Resources)
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]corev1.Toleration, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.TopologySpreadConstraints != nil {
-		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
-		*out = make([]corev1.TopologySpreadConstraint, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.SSHConfiguration != nil {
-		in, out := &in.SSHConfiguration, &out.SSHConfiguration
-		*out = new(corev1.ConfigMapProjection)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.SSHSecret != nil {
-		in, out := &in.SSHSecret, &out.SSHSecret
-		*out = new(corev1.SecretProjection)
+	if in.PGMonitor != nil {
+		in, out := &in.PGMonitor, &out.PGMonitor
+		*out = new(PGMonitorSpec)
 		(*in).DeepCopyInto(*out)
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestRepoHost.
-func (in *PGBackRestRepoHost) DeepCopy() *PGBackRestRepoHost {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringSpec.
+func (in *MonitoringSpec) DeepCopy() *MonitoringSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PGBackRestRepoHost)
+	out := new(MonitoringSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGBackRestRestore) DeepCopyInto(out *PGBackRestRestore) {
+func (in *MonitoringStatus) DeepCopyInto(out *MonitoringStatus) {
 	*out = *in
-	if in.Enabled != nil {
-		in, out := &in.Enabled, &out.Enabled
-		*out = new(bool)
-		**out = **in
-	}
-	if in.PostgresClusterDataSource != nil {
-		in, out := &in.PostgresClusterDataSource, &out.PostgresClusterDataSource
-		*out = new(PostgresClusterDataSource)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestRestore.
-func (in *PGBackRestRestore) DeepCopy() *PGBackRestRestore {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MonitoringStatus.
+func (in *MonitoringStatus) DeepCopy() *MonitoringStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PGBackRestRestore)
+	out := new(MonitoringStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGBackRestScheduledBackupStatus) DeepCopyInto(out *PGBackRestScheduledBackupStatus) {
+func (in *OrphanedResource) DeepCopyInto(out *OrphanedResource) {
 	*out = *in
-	if in.StartTime != nil {
-		in, out := &in.StartTime, &out.StartTime
-		*out = (*in).DeepCopy()
-	}
-	if in.CompletionTime != nil {
-		in, out := &in.CompletionTime, &out.CompletionTime
-		*out = (*in).DeepCopy()
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestScheduledBackupStatus.
-func (in *PGBackRestScheduledBackupStatus) DeepCopy() *PGBackRestScheduledBackupStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrphanedResource.
+func (in *OrphanedResource) DeepCopy() *OrphanedResource {
 	if in == nil {
 		return nil
 	}
-	out := new(PGBackRestScheduledBackupStatus)
+	out := new(OrphanedResource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGBackRestSidecars) DeepCopyInto(out *PGBackRestSidecars) {
+func (in *PGAdmin) DeepCopyInto(out *PGAdmin) {
 	*out = *in
-	if in.PGBackRest != nil {
-		in, out := &in.PGBackRest, &out.PGBackRest
-		*out = new(Sidecar)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.PGBackRestConfig != nil {
-		in, out := &in.PGBackRestConfig, &out.PGBackRestConfig
-		*out = new(Sidecar)
-		(*in).DeepCopyInto(*out)
-	}
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestSidecars.
-func (in *PGBackRestSidecars) DeepCopy() *PGBackRestSidecars {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAdmin.
+func (in *PGAdmin) DeepCopy() *PGAdmin {
 	if in == nil {
 		return nil
 	}
-	out := new(PGBackRestSidecars)
+	out := new(PGAdmin)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PGAdmin) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGBackRestStatus) DeepCopyInto(out *PGBackRestStatus) {
+func (in *PGAdminConfiguration) DeepCopyInto(out *PGAdminConfiguration) {
 	*out = *in
-	if in.ManualBackup != nil {
-		in, out := &in.ManualBackup, &out.ManualBackup
-		*out = new(PGBackRestJobStatus)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.ScheduledBackups != nil {
-		in, out := &in.ScheduledBackups, &out.ScheduledBackups
-		*out = make([]PGBackRestScheduledBackupStatus, len(*in))
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]corev1.VolumeProjection, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.RepoHost != nil {
-		in, out := &in.RepoHost, &out.RepoHost
-		*out = new(RepoHostStatus)
-		**out = **in
-	}
-	if in.Repos != nil {
-		in, out := &in.Repos, &out.Repos
-		*out = make([]RepoStatus, len(*in))
-		copy(*out, *in)
-	}
-	if in.Restore != nil {
-		in, out := &in.Restore, &out.Restore
-		*out = new(PGBackRestJobStatus)
+	if in.LDAPBindPassword != nil {
+		in, out := &in.LDAPBindPassword, &out.LDAPBindPassword
+		*out = new(corev1.SecretKeySelector)
 		(*in).DeepCopyInto(*out)
 	}
+	in.Settings.DeepCopyInto(&out.Settings)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestStatus.
-func (in *PGBackRestStatus) DeepCopy() *PGBackRestStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAdminConfiguration.
+func (in *PGAdminConfiguration) DeepCopy() *PGAdminConfiguration {
 	if in == nil {
 		return nil
 	}
-	out := new(PGBackRestStatus)
+	out := new(PGAdminConfiguration)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGBouncerConfiguration) DeepCopyInto(out *PGBouncerConfiguration) {
+func (in *PGAdminList) DeepCopyInto(out *PGAdminList) {
 	*out = *in
-	if in.Files != nil {
-		in, out := &in.Files, &out.Files
-		*out = make([]corev1.VolumeProjection, len(*in))
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PGAdmin, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.Global != nil {
-		in, out := &in.Global, &out.Global
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Databases != nil {
-		in, out := &in.Databases, &out.Databases
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.Users != nil {
-		in, out := &in.Users, &out.Users
-		*out = make(map[string]string, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBouncerConfiguration.
-func (in *PGBouncerConfiguration) DeepCopy() *PGBouncerConfiguration {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAdminList.
+func (in *PGAdminList) DeepCopy() *PGAdminList {
 	if in == nil {
 		return nil
 	}
-	out := new(PGBouncerConfiguration)
+	out := new(PGAdminList)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PGAdminList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGBouncerPodSpec) DeepCopyInto(out *PGBouncerPodSpec) {
+func (in *PGAdminPodSpec) DeepCopyInto(out *PGAdminPodSpec) {
 	*out = *in
 	if in.Metadata != nil {
 		in, out := &in.Metadata, &out.Metadata
@@ -1174,23 +1259,7 @@ func (in *PGBouncerPodSpec) DeepCopyInto(out *PGBouncerPodSpec) {
 		(*in).DeepCopyInto(*out)
 	}
 	in.Config.DeepCopyInto(&out.Config)
-	if in.Containers != nil {
-		in, out := &in.Containers, &out.Containers
-		*out = make([]corev1.Container, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.CustomTLSSecret != nil {
-		in, out := &in.CustomTLSSecret, &out.CustomTLSSecret
-		*out = new(corev1.SecretProjection)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Port != nil {
-		in, out := &in.Port, &out.Port
-		*out = new(int32)
-		**out = **in
-	}
+	in.DataVolumeClaimSpec.DeepCopyInto(&out.DataVolumeClaimSpec)
 	if in.PriorityClassName != nil {
 		in, out := &in.PriorityClassName, &out.PriorityClassName
 		*out = new(string)
@@ -1201,22 +1270,12 @@ func (in *PGBouncerPodSpec) DeepCopyInto(out *PGBouncerPodSpec) {
 		*out = new(int32)
 		**out = **in
 	}
-	if in.MinAvailable != nil {
-		in, out := &in.MinAvailable, &out.MinAvailable
-		*out = new(intstr.IntOrString)
-		**out = **in
-	}
 	in.Resources.DeepCopyInto(&out.Resources)
 	if in.Service != nil {
 		in, out := &in.Service, &out.Service
 		*out = new(ServiceSpec)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Sidecars != nil {
-		in, out := &in.Sidecars, &out.Sidecars
-		*out = new(PGBouncerSidecars)
-		(*in).DeepCopyInto(*out)
-	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
 		*out = make([]corev1.Toleration, len(*in))
@@ -1233,137 +1292,70 @@ func (in *PGBouncerPodSpec) DeepCopyInto(out *PGBouncerPodSpec) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBouncerPodSpec.
-func (in *PGBouncerPodSpec) DeepCopy() *PGBouncerPodSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAdminPodSpec.
+func (in *PGAdminPodSpec) DeepCopy() *PGAdminPodSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PGBouncerPodSpec)
+	out := new(PGAdminPodSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGBouncerPodStatus) DeepCopyInto(out *PGBouncerPodStatus) {
+func (in *PGAdminPodStatus) DeepCopyInto(out *PGAdminPodStatus) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBouncerPodStatus.
-func (in *PGBouncerPodStatus) DeepCopy() *PGBouncerPodStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAdminPodStatus.
+func (in *PGAdminPodStatus) DeepCopy() *PGAdminPodStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PGBouncerPodStatus)
+	out := new(PGAdminPodStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGBouncerSidecars) DeepCopyInto(out *PGBouncerSidecars) {
+func (in *PGAdminSpec) DeepCopyInto(out *PGAdminSpec) {
 	*out = *in
-	if in.PGBouncerConfig != nil {
-		in, out := &in.PGBouncerConfig, &out.PGBouncerConfig
-		*out = new(Sidecar)
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(Metadata)
 		(*in).DeepCopyInto(*out)
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBouncerSidecars.
-func (in *PGBouncerSidecars) DeepCopy() *PGBouncerSidecars {
-	if in == nil {
-		return nil
+	in.Config.DeepCopyInto(&out.Config)
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(StandalonePGAdminTLS)
+		**out = **in
 	}
-	out := new(PGBouncerSidecars)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGMonitorSpec) DeepCopyInto(out *PGMonitorSpec) {
-	*out = *in
-	if in.Exporter != nil {
-		in, out := &in.Exporter, &out.Exporter
-		*out = new(ExporterSpec)
+	if in.Gunicorn != nil {
+		in, out := &in.Gunicorn, &out.Gunicorn
+		*out = new(StandalonePGAdminGunicorn)
 		(*in).DeepCopyInto(*out)
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGMonitorSpec.
-func (in *PGMonitorSpec) DeepCopy() *PGMonitorSpec {
-	if in == nil {
-		return nil
-	}
-	out := new(PGMonitorSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGUpgrade) DeepCopyInto(out *PGUpgrade) {
-	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGUpgrade.
-func (in *PGUpgrade) DeepCopy() *PGUpgrade {
-	if in == nil {
-		return nil
-	}
-	out := new(PGUpgrade)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PGUpgrade) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGUpgradeList) DeepCopyInto(out *PGUpgradeList) {
-	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]PGUpgrade, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		*out = new(StandalonePGAdminIngress)
+		(*in).DeepCopyInto(*out)
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGUpgradeList.
-func (in *PGUpgradeList) DeepCopy() *PGUpgradeList {
-	if in == nil {
-		return nil
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(StandalonePGAdminService)
+		(*in).DeepCopyInto(*out)
 	}
-	out := new(PGUpgradeList)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PGUpgradeList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+	in.DataVolumeClaimSpec.DeepCopyInto(&out.DataVolumeClaimSpec)
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]Dependency, len(*in))
+		copy(*out, *in)
 	}
-	return nil
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGUpgradeSpec) DeepCopyInto(out *PGUpgradeSpec) {
-	*out = *in
-	if in.Metadata != nil {
-		in, out := &in.Metadata, &out.Metadata
-		*out = new(Metadata)
-		(*in).DeepCopyInto(*out)
+	if in.ExtraPythonPackages != nil {
+		in, out := &in.ExtraPythonPackages, &out.ExtraPythonPackages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 	if in.Image != nil {
 		in, out := &in.Image, &out.Image
@@ -1375,7 +1367,17 @@ func (in *PGUpgradeSpec) DeepCopyInto(out *PGUpgradeSpec) {
 		*out = make([]corev1.LocalObjectReference, len(*in))
 		copy(*out, *in)
 	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
 	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(StandalonePGAdminProbes)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
 		*out = new(corev1.Affinity)
@@ -1386,6 +1388,11 @@ func (in *PGUpgradeSpec) DeepCopyInto(out *PGUpgradeSpec) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.OpenShift != nil {
+		in, out := &in.OpenShift, &out.OpenShift
+		*out = new(bool)
+		**out = **in
+	}
 	if in.Tolerations != nil {
 		in, out := &in.Tolerations, &out.Tolerations
 		*out = make([]corev1.Toleration, len(*in))
@@ -1393,20 +1400,39 @@ func (in *PGUpgradeSpec) DeepCopyInto(out *PGUpgradeSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.ServerGroups != nil {
+		in, out := &in.ServerGroups, &out.ServerGroups
+		*out = make([]ServerGroup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StorageVolumeClaimSpec != nil {
+		in, out := &in.StorageVolumeClaimSpec, &out.StorageVolumeClaimSpec
+		*out = new(corev1.PersistentVolumeClaimSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]PGAdminUser, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGUpgradeSpec.
-func (in *PGUpgradeSpec) DeepCopy() *PGUpgradeSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAdminSpec.
+func (in *PGAdminSpec) DeepCopy() *PGAdminSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(PGUpgradeSpec)
+	out := new(PGAdminSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PGUpgradeStatus) DeepCopyInto(out *PGUpgradeStatus) {
+func (in *PGAdminStatus) DeepCopyInto(out *PGAdminStatus) {
 	*out = *in
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
@@ -1417,154 +1443,196 @@ func (in *PGUpgradeStatus) DeepCopyInto(out *PGUpgradeStatus) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGUpgradeStatus.
-func (in *PGUpgradeStatus) DeepCopy() *PGUpgradeStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAdminStatus.
+func (in *PGAdminStatus) DeepCopy() *PGAdminStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PGUpgradeStatus)
+	out := new(PGAdminStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PatroniSpec) DeepCopyInto(out *PatroniSpec) {
+func (in *PGAdminUser) DeepCopyInto(out *PGAdminUser) {
 	*out = *in
-	in.DynamicConfiguration.DeepCopyInto(&out.DynamicConfiguration)
-	if in.LeaderLeaseDurationSeconds != nil {
-		in, out := &in.LeaderLeaseDurationSeconds, &out.LeaderLeaseDurationSeconds
-		*out = new(int32)
-		**out = **in
-	}
-	if in.Port != nil {
-		in, out := &in.Port, &out.Port
-		*out = new(int32)
-		**out = **in
-	}
-	if in.SyncPeriodSeconds != nil {
-		in, out := &in.SyncPeriodSeconds, &out.SyncPeriodSeconds
-		*out = new(int32)
-		**out = **in
-	}
-	if in.Switchover != nil {
-		in, out := &in.Switchover, &out.Switchover
-		*out = new(PatroniSwitchover)
-		(*in).DeepCopyInto(*out)
-	}
+	in.PasswordRef.DeepCopyInto(&out.PasswordRef)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniSpec.
-func (in *PatroniSpec) DeepCopy() *PatroniSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGAdminUser.
+func (in *PGAdminUser) DeepCopy() *PGAdminUser {
 	if in == nil {
 		return nil
 	}
-	out := new(PatroniSpec)
+	out := new(PGAdminUser)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PatroniStatus) DeepCopyInto(out *PatroniStatus) {
+func (in *PGBackRestArchive) DeepCopyInto(out *PGBackRestArchive) {
 	*out = *in
-	if in.Switchover != nil {
-		in, out := &in.Switchover, &out.Switchover
-		*out = new(string)
-		**out = **in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(Metadata)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.SwitchoverTimeline != nil {
-		in, out := &in.SwitchoverTimeline, &out.SwitchoverTimeline
-		*out = new(int64)
+	if in.Configuration != nil {
+		in, out := &in.Configuration, &out.Configuration
+		*out = make([]corev1.VolumeProjection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Global != nil {
+		in, out := &in.Global, &out.Global
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ArchiveFailure != nil {
+		in, out := &in.ArchiveFailure, &out.ArchiveFailure
+		*out = new(PGBackRestArchiveFailure)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Jobs != nil {
+		in, out := &in.Jobs, &out.Jobs
+		*out = new(BackupJobs)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.JobHistoryLimit != nil {
+		in, out := &in.JobHistoryLimit, &out.JobHistoryLimit
+		*out = new(PGBackRestJobHistoryLimit)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Repos != nil {
+		in, out := &in.Repos, &out.Repos
+		*out = make([]PGBackRestRepo, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RepoHost != nil {
+		in, out := &in.RepoHost, &out.RepoHost
+		*out = new(PGBackRestRepoHost)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BackupStandby != nil {
+		in, out := &in.BackupStandby, &out.BackupStandby
+		*out = new(bool)
 		**out = **in
 	}
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniStatus.
-func (in *PatroniStatus) DeepCopy() *PatroniStatus {
-	if in == nil {
-		return nil
+	if in.Manual != nil {
+		in, out := &in.Manual, &out.Manual
+		*out = new(PGBackRestManualBackup)
+		(*in).DeepCopyInto(*out)
 	}
-	out := new(PatroniStatus)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PatroniSwitchover) DeepCopyInto(out *PatroniSwitchover) {
-	*out = *in
-	if in.TargetInstance != nil {
-		in, out := &in.TargetInstance, &out.TargetInstance
-		*out = new(string)
+	if in.RepoMigration != nil {
+		in, out := &in.RepoMigration, &out.RepoMigration
+		*out = new(PGBackRestRepoMigration)
 		**out = **in
 	}
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(PGBackRestRestore)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Sidecars != nil {
+		in, out := &in.Sidecars, &out.Sidecars
+		*out = new(PGBackRestSidecars)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniSwitchover.
-func (in *PatroniSwitchover) DeepCopy() *PatroniSwitchover {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestArchive.
+func (in *PGBackRestArchive) DeepCopy() *PGBackRestArchive {
 	if in == nil {
 		return nil
 	}
-	out := new(PatroniSwitchover)
+	out := new(PGBackRestArchive)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostgresAdditionalConfig) DeepCopyInto(out *PostgresAdditionalConfig) {
+func (in *PGBackRestArchiveFailure) DeepCopyInto(out *PGBackRestArchiveFailure) {
 	*out = *in
-	if in.Files != nil {
-		in, out := &in.Files, &out.Files
-		*out = make([]corev1.VolumeProjection, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.MaxQueueSize != nil {
+		in, out := &in.MaxQueueSize, &out.MaxQueueSize
+		x := (*in).DeepCopy()
+		*out = &x
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresAdditionalConfig.
-func (in *PostgresAdditionalConfig) DeepCopy() *PostgresAdditionalConfig {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestArchiveFailure.
+func (in *PGBackRestArchiveFailure) DeepCopy() *PGBackRestArchiveFailure {
 	if in == nil {
 		return nil
 	}
-	out := new(PostgresAdditionalConfig)
+	out := new(PGBackRestArchiveFailure)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostgresCluster) DeepCopyInto(out *PostgresCluster) {
+func (in *PGBackRestBackupSchedules) DeepCopyInto(out *PGBackRestBackupSchedules) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
+	if in.Full != nil {
+		in, out := &in.Full, &out.Full
+		*out = new(string)
+		**out = **in
+	}
+	if in.Differential != nil {
+		in, out := &in.Differential, &out.Differential
+		*out = new(string)
+		**out = **in
+	}
+	if in.Incremental != nil {
+		in, out := &in.Incremental, &out.Incremental
+		*out = new(string)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresCluster.
-func (in *PostgresCluster) DeepCopy() *PostgresCluster {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestBackupSchedules.
+func (in *PGBackRestBackupSchedules) DeepCopy() *PGBackRestBackupSchedules {
 	if in == nil {
 		return nil
 	}
-	out := new(PostgresCluster)
+	out := new(PGBackRestBackupSchedules)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PostgresCluster) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostgresClusterDataSource) DeepCopyInto(out *PostgresClusterDataSource) {
+func (in *PGBackRestDataSource) DeepCopyInto(out *PGBackRestDataSource) {
 	*out = *in
+	if in.Configuration != nil {
+		in, out := &in.Configuration, &out.Configuration
+		*out = make([]corev1.VolumeProjection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Global != nil {
+		in, out := &in.Global, &out.Global
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	in.Repo.DeepCopyInto(&out.Repo)
 	if in.Options != nil {
 		in, out := &in.Options, &out.Options
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = new(PGBackRestRestoreDatabases)
+		(*in).DeepCopyInto(*out)
+	}
 	in.Resources.DeepCopyInto(&out.Resources)
 	if in.Affinity != nil {
 		in, out := &in.Affinity, &out.Affinity
@@ -1585,372 +1653,3220 @@ func (in *PostgresClusterDataSource) DeepCopyInto(out *PostgresClusterDataSource
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresClusterDataSource.
-func (in *PostgresClusterDataSource) DeepCopy() *PostgresClusterDataSource {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestDataSource.
+func (in *PGBackRestDataSource) DeepCopy() *PGBackRestDataSource {
 	if in == nil {
 		return nil
 	}
-	out := new(PostgresClusterDataSource)
+	out := new(PGBackRestDataSource)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostgresClusterList) DeepCopyInto(out *PostgresClusterList) {
+func (in *PGBackRestJobHistoryLimit) DeepCopyInto(out *PGBackRestJobHistoryLimit) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ListMeta.DeepCopyInto(&out.ListMeta)
-	if in.Items != nil {
-		in, out := &in.Items, &out.Items
-		*out = make([]PostgresCluster, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+	if in.Successful != nil {
+		in, out := &in.Successful, &out.Successful
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Failed != nil {
+		in, out := &in.Failed, &out.Failed
+		*out = new(int32)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresClusterList.
-func (in *PostgresClusterList) DeepCopy() *PostgresClusterList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestJobHistoryLimit.
+func (in *PGBackRestJobHistoryLimit) DeepCopy() *PGBackRestJobHistoryLimit {
 	if in == nil {
 		return nil
 	}
-	out := new(PostgresClusterList)
+	out := new(PGBackRestJobHistoryLimit)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *PostgresClusterList) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBackRestJobStatus) DeepCopyInto(out *PGBackRestJobStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
 	}
-	return nil
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestJobStatus.
+func (in *PGBackRestJobStatus) DeepCopy() *PGBackRestJobStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBackRestJobStatus)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostgresClusterSpec) DeepCopyInto(out *PostgresClusterSpec) {
+func (in *PGBackRestManualBackup) DeepCopyInto(out *PGBackRestManualBackup) {
 	*out = *in
-	if in.Metadata != nil {
-		in, out := &in.Metadata, &out.Metadata
-		*out = new(Metadata)
-		(*in).DeepCopyInto(*out)
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.DataSource != nil {
-		in, out := &in.DataSource, &out.DataSource
-		*out = new(DataSource)
-		(*in).DeepCopyInto(*out)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestManualBackup.
+func (in *PGBackRestManualBackup) DeepCopy() *PGBackRestManualBackup {
+	if in == nil {
+		return nil
 	}
-	in.Backups.DeepCopyInto(&out.Backups)
-	if in.CustomTLSSecret != nil {
-		in, out := &in.CustomTLSSecret, &out.CustomTLSSecret
-		*out = new(corev1.SecretProjection)
+	out := new(PGBackRestManualBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBackRestRepo) DeepCopyInto(out *PGBackRestRepo) {
+	*out = *in
+	if in.BackupSchedules != nil {
+		in, out := &in.BackupSchedules, &out.BackupSchedules
+		*out = new(PGBackRestBackupSchedules)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.CustomReplicationClientTLSSecret != nil {
-		in, out := &in.CustomReplicationClientTLSSecret, &out.CustomReplicationClientTLSSecret
-		*out = new(corev1.SecretProjection)
-		(*in).DeepCopyInto(*out)
+	if in.Azure != nil {
+		in, out := &in.Azure, &out.Azure
+		*out = new(RepoAzure)
+		**out = **in
 	}
-	if in.DatabaseInitSQL != nil {
-		in, out := &in.DatabaseInitSQL, &out.DatabaseInitSQL
-		*out = new(DatabaseInitSQL)
+	if in.GCS != nil {
+		in, out := &in.GCS, &out.GCS
+		*out = new(RepoGCS)
 		**out = **in
 	}
-	if in.DisableDefaultPodScheduling != nil {
-		in, out := &in.DisableDefaultPodScheduling, &out.DisableDefaultPodScheduling
-		*out = new(bool)
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		*out = new(RepoS3)
 		**out = **in
 	}
-	if in.ImagePullSecrets != nil {
-		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
-		*out = make([]corev1.LocalObjectReference, len(*in))
-		copy(*out, *in)
+	if in.SFTP != nil {
+		in, out := &in.SFTP, &out.SFTP
+		*out = new(RepoSFTP)
+		(*in).DeepCopyInto(*out)
 	}
-	if in.InstanceSets != nil {
-		in, out := &in.InstanceSets, &out.InstanceSets
-		*out = make([]PostgresInstanceSetSpec, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.Volume != nil {
+		in, out := &in.Volume, &out.Volume
+		*out = new(RepoPVC)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Connection != nil {
+		in, out := &in.Connection, &out.Connection
+		*out = new(RepoConnection)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestRepo.
+func (in *PGBackRestRepo) DeepCopy() *PGBackRestRepo {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBackRestRepo)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBackRestRepoHost) DeepCopyInto(out *PGBackRestRepoHost) {
+	*out = *in
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PriorityClassName != nil {
+		in, out := &in.PriorityClassName, &out.PriorityClassName
+		*out = new(string)
+		**out = **in
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
-	if in.OpenShift != nil {
-		in, out := &in.OpenShift, &out.OpenShift
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]corev1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SSHConfiguration != nil {
+		in, out := &in.SSHConfiguration, &out.SSHConfiguration
+		*out = new(corev1.ConfigMapProjection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SSHSecret != nil {
+		in, out := &in.SSHSecret, &out.SSHSecret
+		*out = new(corev1.SecretProjection)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestRepoHost.
+func (in *PGBackRestRepoHost) DeepCopy() *PGBackRestRepoHost {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBackRestRepoHost)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBackRestRepoMigration) DeepCopyInto(out *PGBackRestRepoMigration) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestRepoMigration.
+func (in *PGBackRestRepoMigration) DeepCopy() *PGBackRestRepoMigration {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBackRestRepoMigration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBackRestRepoMigrationStatus) DeepCopyInto(out *PGBackRestRepoMigrationStatus) {
+	*out = *in
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestRepoMigrationStatus.
+func (in *PGBackRestRepoMigrationStatus) DeepCopy() *PGBackRestRepoMigrationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBackRestRepoMigrationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBackRestRestore) DeepCopyInto(out *PGBackRestRestore) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
 		*out = new(bool)
 		**out = **in
 	}
-	if in.Patroni != nil {
-		in, out := &in.Patroni, &out.Patroni
-		*out = new(PatroniSpec)
+	if in.PostgresClusterDataSource != nil {
+		in, out := &in.PostgresClusterDataSource, &out.PostgresClusterDataSource
+		*out = new(PostgresClusterDataSource)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Paused != nil {
-		in, out := &in.Paused, &out.Paused
-		*out = new(bool)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestRestore.
+func (in *PGBackRestRestore) DeepCopy() *PGBackRestRestore {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBackRestRestore)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBackRestRestoreDatabases) DeepCopyInto(out *PGBackRestRestoreDatabases) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestRestoreDatabases.
+func (in *PGBackRestRestoreDatabases) DeepCopy() *PGBackRestRestoreDatabases {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBackRestRestoreDatabases)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBackRestScheduledBackupStatus) DeepCopyInto(out *PGBackRestScheduledBackupStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Duration != nil {
+		in, out := &in.Duration, &out.Duration
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Size != nil {
+		in, out := &in.Size, &out.Size
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestScheduledBackupStatus.
+func (in *PGBackRestScheduledBackupStatus) DeepCopy() *PGBackRestScheduledBackupStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBackRestScheduledBackupStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBackRestSidecars) DeepCopyInto(out *PGBackRestSidecars) {
+	*out = *in
+	if in.PGBackRest != nil {
+		in, out := &in.PGBackRest, &out.PGBackRest
+		*out = new(Sidecar)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PGBackRestConfig != nil {
+		in, out := &in.PGBackRestConfig, &out.PGBackRestConfig
+		*out = new(Sidecar)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestSidecars.
+func (in *PGBackRestSidecars) DeepCopy() *PGBackRestSidecars {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBackRestSidecars)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBackRestStatus) DeepCopyInto(out *PGBackRestStatus) {
+	*out = *in
+	if in.ManualBackup != nil {
+		in, out := &in.ManualBackup, &out.ManualBackup
+		*out = new(PGBackRestJobStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ScheduledBackups != nil {
+		in, out := &in.ScheduledBackups, &out.ScheduledBackups
+		*out = make([]PGBackRestScheduledBackupStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RepoHost != nil {
+		in, out := &in.RepoHost, &out.RepoHost
+		*out = new(RepoHostStatus)
 		**out = **in
 	}
+	if in.Repos != nil {
+		in, out := &in.Repos, &out.Repos
+		*out = make([]RepoStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Restore != nil {
+		in, out := &in.Restore, &out.Restore
+		*out = new(PGBackRestJobStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RepoMigration != nil {
+		in, out := &in.RepoMigration, &out.RepoMigration
+		*out = new(PGBackRestRepoMigrationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.BackupsObserved != nil {
+		in, out := &in.BackupsObserved, &out.BackupsObserved
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBackRestStatus.
+func (in *PGBackRestStatus) DeepCopy() *PGBackRestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBackRestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBouncerConfiguration) DeepCopyInto(out *PGBouncerConfiguration) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]corev1.VolumeProjection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Global != nil {
+		in, out := &in.Global, &out.Global
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBouncerConfiguration.
+func (in *PGBouncerConfiguration) DeepCopy() *PGBouncerConfiguration {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBouncerConfiguration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBouncerCutoverSpec) DeepCopyInto(out *PGBouncerCutoverSpec) {
+	*out = *in
+	if in.Operations != nil {
+		in, out := &in.Operations, &out.Operations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DrainTimeoutSeconds != nil {
+		in, out := &in.DrainTimeoutSeconds, &out.DrainTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBouncerCutoverSpec.
+func (in *PGBouncerCutoverSpec) DeepCopy() *PGBouncerCutoverSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBouncerCutoverSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBouncerCutoverStatus) DeepCopyInto(out *PGBouncerCutoverStatus) {
+	*out = *in
+	if in.PauseTime != nil {
+		in, out := &in.PauseTime, &out.PauseTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBouncerCutoverStatus.
+func (in *PGBouncerCutoverStatus) DeepCopy() *PGBouncerCutoverStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBouncerCutoverStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBouncerPodSpec) DeepCopyInto(out *PGBouncerPodSpec) {
+	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(Metadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Config.DeepCopyInto(&out.Config)
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]corev1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Cutover != nil {
+		in, out := &in.Cutover, &out.Cutover
+		*out = new(PGBouncerCutoverSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CustomTLSSecret != nil {
+		in, out := &in.CustomTLSSecret, &out.CustomTLSSecret
+		*out = new(corev1.SecretProjection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PriorityClassName != nil {
+		in, out := &in.PriorityClassName, &out.PriorityClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ServeStandby != nil {
+		in, out := &in.ServeStandby, &out.ServeStandby
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(ServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Sidecars != nil {
+		in, out := &in.Sidecars, &out.Sidecars
+		*out = new(PGBouncerSidecars)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]corev1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBouncerPodSpec.
+func (in *PGBouncerPodSpec) DeepCopy() *PGBouncerPodSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBouncerPodSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBouncerPodStatus) DeepCopyInto(out *PGBouncerPodStatus) {
+	*out = *in
+	if in.Cutover != nil {
+		in, out := &in.Cutover, &out.Cutover
+		*out = new(PGBouncerCutoverStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBouncerPodStatus.
+func (in *PGBouncerPodStatus) DeepCopy() *PGBouncerPodStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBouncerPodStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGBouncerSidecars) DeepCopyInto(out *PGBouncerSidecars) {
+	*out = *in
+	if in.PGBouncerConfig != nil {
+		in, out := &in.PGBouncerConfig, &out.PGBouncerConfig
+		*out = new(Sidecar)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGBouncerSidecars.
+func (in *PGBouncerSidecars) DeepCopy() *PGBouncerSidecars {
+	if in == nil {
+		return nil
+	}
+	out := new(PGBouncerSidecars)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGClone) DeepCopyInto(out *PGClone) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGClone.
+func (in *PGClone) DeepCopy() *PGClone {
+	if in == nil {
+		return nil
+	}
+	out := new(PGClone)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PGClone) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGCloneList) DeepCopyInto(out *PGCloneList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PGClone, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGCloneList.
+func (in *PGCloneList) DeepCopy() *PGCloneList {
+	if in == nil {
+		return nil
+	}
+	out := new(PGCloneList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PGCloneList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGCloneSpec) DeepCopyInto(out *PGCloneSpec) {
+	*out = *in
+	if in.Suspend != nil {
+		in, out := &in.Suspend, &out.Suspend
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Masking != nil {
+		in, out := &in.Masking, &out.Masking
+		*out = new(DataMaskingSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGCloneSpec.
+func (in *PGCloneSpec) DeepCopy() *PGCloneSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGCloneSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGCloneStatus) DeepCopyInto(out *PGCloneStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastRefreshTime != nil {
+		in, out := &in.LastRefreshTime, &out.LastRefreshTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGCloneStatus.
+func (in *PGCloneStatus) DeepCopy() *PGCloneStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PGCloneStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGMonitorSpec) DeepCopyInto(out *PGMonitorSpec) {
+	*out = *in
+	if in.Exporter != nil {
+		in, out := &in.Exporter, &out.Exporter
+		*out = new(ExporterSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGMonitorSpec.
+func (in *PGMonitorSpec) DeepCopy() *PGMonitorSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGMonitorSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGSQLRequest) DeepCopyInto(out *PGSQLRequest) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGSQLRequest.
+func (in *PGSQLRequest) DeepCopy() *PGSQLRequest {
+	if in == nil {
+		return nil
+	}
+	out := new(PGSQLRequest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PGSQLRequest) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGSQLRequestList) DeepCopyInto(out *PGSQLRequestList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PGSQLRequest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGSQLRequestList.
+func (in *PGSQLRequestList) DeepCopy() *PGSQLRequestList {
+	if in == nil {
+		return nil
+	}
+	out := new(PGSQLRequestList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PGSQLRequestList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGSQLRequestSpec) DeepCopyInto(out *PGSQLRequestSpec) {
+	*out = *in
+	if in.LockTimeoutSeconds != nil {
+		in, out := &in.LockTimeoutSeconds, &out.LockTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGSQLRequestSpec.
+func (in *PGSQLRequestSpec) DeepCopy() *PGSQLRequestSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGSQLRequestSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGSQLRequestStatus) DeepCopyInto(out *PGSQLRequestStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGSQLRequestStatus.
+func (in *PGSQLRequestStatus) DeepCopy() *PGSQLRequestStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PGSQLRequestStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGSupportBundle) DeepCopyInto(out *PGSupportBundle) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGSupportBundle.
+func (in *PGSupportBundle) DeepCopy() *PGSupportBundle {
+	if in == nil {
+		return nil
+	}
+	out := new(PGSupportBundle)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PGSupportBundle) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGSupportBundleList) DeepCopyInto(out *PGSupportBundleList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PGSupportBundle, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGSupportBundleList.
+func (in *PGSupportBundleList) DeepCopy() *PGSupportBundleList {
+	if in == nil {
+		return nil
+	}
+	out := new(PGSupportBundleList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PGSupportBundleList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGSupportBundleSpec) DeepCopyInto(out *PGSupportBundleSpec) {
+	*out = *in
+	if in.LogLines != nil {
+		in, out := &in.LogLines, &out.LogLines
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGSupportBundleSpec.
+func (in *PGSupportBundleSpec) DeepCopy() *PGSupportBundleSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGSupportBundleSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGSupportBundleStatus) DeepCopyInto(out *PGSupportBundleStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGSupportBundleStatus.
+func (in *PGSupportBundleStatus) DeepCopy() *PGSupportBundleStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PGSupportBundleStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGTopology) DeepCopyInto(out *PGTopology) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGTopology.
+func (in *PGTopology) DeepCopy() *PGTopology {
+	if in == nil {
+		return nil
+	}
+	out := new(PGTopology)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PGTopology) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGTopologyList) DeepCopyInto(out *PGTopologyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PGTopology, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGTopologyList.
+func (in *PGTopologyList) DeepCopy() *PGTopologyList {
+	if in == nil {
+		return nil
+	}
+	out := new(PGTopologyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PGTopologyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGTopologyMember) DeepCopyInto(out *PGTopologyMember) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGTopologyMember.
+func (in *PGTopologyMember) DeepCopy() *PGTopologyMember {
+	if in == nil {
+		return nil
+	}
+	out := new(PGTopologyMember)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGTopologyMemberStatus) DeepCopyInto(out *PGTopologyMemberStatus) {
+	*out = *in
+	if in.WALPosition != nil {
+		in, out := &in.WALPosition, &out.WALPosition
+		*out = new(int64)
+		**out = **in
+	}
+	if in.LagBytes != nil {
+		in, out := &in.LagBytes, &out.LagBytes
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGTopologyMemberStatus.
+func (in *PGTopologyMemberStatus) DeepCopy() *PGTopologyMemberStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PGTopologyMemberStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGTopologySpec) DeepCopyInto(out *PGTopologySpec) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]PGTopologyMember, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGTopologySpec.
+func (in *PGTopologySpec) DeepCopy() *PGTopologySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGTopologySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGTopologyStatus) DeepCopyInto(out *PGTopologyStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]PGTopologyMemberStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGTopologyStatus.
+func (in *PGTopologyStatus) DeepCopy() *PGTopologyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PGTopologyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGUpgrade) DeepCopyInto(out *PGUpgrade) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGUpgrade.
+func (in *PGUpgrade) DeepCopy() *PGUpgrade {
+	if in == nil {
+		return nil
+	}
+	out := new(PGUpgrade)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PGUpgrade) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGUpgradeList) DeepCopyInto(out *PGUpgradeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PGUpgrade, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGUpgradeList.
+func (in *PGUpgradeList) DeepCopy() *PGUpgradeList {
+	if in == nil {
+		return nil
+	}
+	out := new(PGUpgradeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PGUpgradeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGUpgradeLogicalReplicationSpec) DeepCopyInto(out *PGUpgradeLogicalReplicationSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGUpgradeLogicalReplicationSpec.
+func (in *PGUpgradeLogicalReplicationSpec) DeepCopy() *PGUpgradeLogicalReplicationSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGUpgradeLogicalReplicationSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGUpgradeLogicalReplicationStatus) DeepCopyInto(out *PGUpgradeLogicalReplicationStatus) {
+	*out = *in
+	if in.PendingTables != nil {
+		in, out := &in.PendingTables, &out.PendingTables
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LagBytes != nil {
+		in, out := &in.LagBytes, &out.LagBytes
+		*out = new(int64)
+		**out = **in
+	}
+	if in.LastLagCheckTime != nil {
+		in, out := &in.LastLagCheckTime, &out.LastLagCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PreviousPGBouncerDatabases != nil {
+		in, out := &in.PreviousPGBouncerDatabases, &out.PreviousPGBouncerDatabases
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGUpgradeLogicalReplicationStatus.
+func (in *PGUpgradeLogicalReplicationStatus) DeepCopy() *PGUpgradeLogicalReplicationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PGUpgradeLogicalReplicationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGUpgradePostUpgradeSpec) DeepCopyInto(out *PGUpgradePostUpgradeSpec) {
+	*out = *in
+	if in.Analyze != nil {
+		in, out := &in.Analyze, &out.Analyze
+		*out = new(bool)
+		**out = **in
+	}
+	if in.UpdateExtensions != nil {
+		in, out := &in.UpdateExtensions, &out.UpdateExtensions
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGUpgradePostUpgradeSpec.
+func (in *PGUpgradePostUpgradeSpec) DeepCopy() *PGUpgradePostUpgradeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGUpgradePostUpgradeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGUpgradePreflightStatus) DeepCopyInto(out *PGUpgradePreflightStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGUpgradePreflightStatus.
+func (in *PGUpgradePreflightStatus) DeepCopy() *PGUpgradePreflightStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PGUpgradePreflightStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGUpgradeRollbackStatus) DeepCopyInto(out *PGUpgradeRollbackStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGUpgradeRollbackStatus.
+func (in *PGUpgradeRollbackStatus) DeepCopy() *PGUpgradeRollbackStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PGUpgradeRollbackStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGUpgradeSpec) DeepCopyInto(out *PGUpgradeSpec) {
+	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(Metadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Image != nil {
+		in, out := &in.Image, &out.Image
+		*out = new(string)
+		**out = **in
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.IntermediatePostgresVersions != nil {
+		in, out := &in.IntermediatePostgresVersions, &out.IntermediatePostgresVersions
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	if in.LogicalReplication != nil {
+		in, out := &in.LogicalReplication, &out.LogicalReplication
+		*out = new(PGUpgradeLogicalReplicationSpec)
+		**out = **in
+	}
+	if in.PostUpgrade != nil {
+		in, out := &in.PostUpgrade, &out.PostUpgrade
+		*out = new(PGUpgradePostUpgradeSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PriorityClassName != nil {
+		in, out := &in.PriorityClassName, &out.PriorityClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGUpgradeSpec.
+func (in *PGUpgradeSpec) DeepCopy() *PGUpgradeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PGUpgradeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PGUpgradeStatus) DeepCopyInto(out *PGUpgradeStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Preflight != nil {
+		in, out := &in.Preflight, &out.Preflight
+		*out = new(PGUpgradePreflightStatus)
+		**out = **in
+	}
+	if in.Rollback != nil {
+		in, out := &in.Rollback, &out.Rollback
+		*out = new(PGUpgradeRollbackStatus)
+		**out = **in
+	}
+	if in.LogicalReplication != nil {
+		in, out := &in.LogicalReplication, &out.LogicalReplication
+		*out = new(PGUpgradeLogicalReplicationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PGUpgradeStatus.
+func (in *PGUpgradeStatus) DeepCopy() *PGUpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PGUpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PartitionedTable) DeepCopyInto(out *PartitionedTable) {
+	*out = *in
+	if in.Premake != nil {
+		in, out := &in.Premake, &out.Premake
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PartitionedTable.
+func (in *PartitionedTable) DeepCopy() *PartitionedTable {
+	if in == nil {
+		return nil
+	}
+	out := new(PartitionedTable)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PartitioningSpec) DeepCopyInto(out *PartitioningSpec) {
+	*out = *in
+	if in.Tables != nil {
+		in, out := &in.Tables, &out.Tables
+		*out = make([]PartitionedTable, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.TTLSecondsAfterFinished != nil {
+		in, out := &in.TTLSecondsAfterFinished, &out.TTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PartitioningSpec.
+func (in *PartitioningSpec) DeepCopy() *PartitioningSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PartitioningSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PartitioningStatus) DeepCopyInto(out *PartitioningStatus) {
+	*out = *in
+	if in.LastMaintenanceTime != nil {
+		in, out := &in.LastMaintenanceTime, &out.LastMaintenanceTime
+		*out = (*in).DeepCopy()
+	}
+	if in.UnregisteredTables != nil {
+		in, out := &in.UnregisteredTables, &out.UnregisteredTables
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PartitioningStatus.
+func (in *PartitioningStatus) DeepCopy() *PartitioningStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PartitioningStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatroniRestart) DeepCopyInto(out *PatroniRestart) {
+	*out = *in
+	if in.TargetInstances != nil {
+		in, out := &in.TargetInstances, &out.TargetInstances
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniRestart.
+func (in *PatroniRestart) DeepCopy() *PatroniRestart {
+	if in == nil {
+		return nil
+	}
+	out := new(PatroniRestart)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatroniSpec) DeepCopyInto(out *PatroniSpec) {
+	*out = *in
+	in.DynamicConfiguration.DeepCopyInto(&out.DynamicConfiguration)
+	if in.LeaderLeaseDurationSeconds != nil {
+		in, out := &in.LeaderLeaseDurationSeconds, &out.LeaderLeaseDurationSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SyncPeriodSeconds != nil {
+		in, out := &in.SyncPeriodSeconds, &out.SyncPeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Switchover != nil {
+		in, out := &in.Switchover, &out.Switchover
+		*out = new(PatroniSwitchover)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Restart != nil {
+		in, out := &in.Restart, &out.Restart
+		*out = new(PatroniRestart)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Paused != nil {
+		in, out := &in.Paused, &out.Paused
+		*out = new(bool)
+		**out = **in
+	}
+	if in.PausedTimeoutSeconds != nil {
+		in, out := &in.PausedTimeoutSeconds, &out.PausedTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniSpec.
+func (in *PatroniSpec) DeepCopy() *PatroniSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PatroniSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatroniStatus) DeepCopyInto(out *PatroniStatus) {
+	*out = *in
+	if in.Switchover != nil {
+		in, out := &in.Switchover, &out.Switchover
+		*out = new(string)
+		**out = **in
+	}
+	if in.SwitchoverTimeline != nil {
+		in, out := &in.SwitchoverTimeline, &out.SwitchoverTimeline
+		*out = new(int64)
+		**out = **in
+	}
+	if in.Restart != nil {
+		in, out := &in.Restart, &out.Restart
+		*out = new(string)
+		**out = **in
+	}
+	if in.Reload != nil {
+		in, out := &in.Reload, &out.Reload
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniStatus.
+func (in *PatroniStatus) DeepCopy() *PatroniStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PatroniStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PatroniSwitchover) DeepCopyInto(out *PatroniSwitchover) {
+	*out = *in
+	if in.TargetInstance != nil {
+		in, out := &in.TargetInstance, &out.TargetInstance
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PatroniSwitchover.
+func (in *PatroniSwitchover) DeepCopy() *PatroniSwitchover {
+	if in == nil {
+		return nil
+	}
+	out := new(PatroniSwitchover)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresAdditionalConfig) DeepCopyInto(out *PostgresAdditionalConfig) {
+	*out = *in
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]corev1.VolumeProjection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresAdditionalConfig.
+func (in *PostgresAdditionalConfig) DeepCopy() *PostgresAdditionalConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresAdditionalConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresCluster) DeepCopyInto(out *PostgresCluster) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresCluster.
+func (in *PostgresCluster) DeepCopy() *PostgresCluster {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresCluster) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresClusterDNS) DeepCopyInto(out *PostgresClusterDNS) {
+	*out = *in
+	in.Hostnames.DeepCopyInto(&out.Hostnames)
+	if in.TTLSeconds != nil {
+		in, out := &in.TTLSeconds, &out.TTLSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresClusterDNS.
+func (in *PostgresClusterDNS) DeepCopy() *PostgresClusterDNS {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresClusterDNS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresClusterDNSHostnames) DeepCopyInto(out *PostgresClusterDNSHostnames) {
+	*out = *in
+	if in.Primary != nil {
+		in, out := &in.Primary, &out.Primary
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PGBouncer != nil {
+		in, out := &in.PGBouncer, &out.PGBouncer
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresClusterDNSHostnames.
+func (in *PostgresClusterDNSHostnames) DeepCopy() *PostgresClusterDNSHostnames {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresClusterDNSHostnames)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresClusterDataSource) DeepCopyInto(out *PostgresClusterDataSource) {
+	*out = *in
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = new(PGBackRestRestoreDatabases)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PriorityClassName != nil {
+		in, out := &in.PriorityClassName, &out.PriorityClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresClusterDataSource.
+func (in *PostgresClusterDataSource) DeepCopy() *PostgresClusterDataSource {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresClusterDataSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresClusterList) DeepCopyInto(out *PostgresClusterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PostgresCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresClusterList.
+func (in *PostgresClusterList) DeepCopy() *PostgresClusterList {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresClusterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresClusterReference) DeepCopyInto(out *PostgresClusterReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresClusterReference.
+func (in *PostgresClusterReference) DeepCopy() *PostgresClusterReference {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresClusterReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresClusterSpec) DeepCopyInto(out *PostgresClusterSpec) {
+	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(Metadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DataSource != nil {
+		in, out := &in.DataSource, &out.DataSource
+		*out = new(DataSource)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Backups.DeepCopyInto(&out.Backups)
+	if in.CustomTLSSecret != nil {
+		in, out := &in.CustomTLSSecret, &out.CustomTLSSecret
+		*out = new(corev1.SecretProjection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CustomReplicationClientTLSSecret != nil {
+		in, out := &in.CustomReplicationClientTLSSecret, &out.CustomReplicationClientTLSSecret
+		*out = new(corev1.SecretProjection)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DataChecksums != nil {
+		in, out := &in.DataChecksums, &out.DataChecksums
+		*out = new(DataChecksumsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Initdb != nil {
+		in, out := &in.Initdb, &out.Initdb
+		*out = new(InitdbSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DatabaseInitSQL != nil {
+		in, out := &in.DatabaseInitSQL, &out.DatabaseInitSQL
+		*out = new(DatabaseInitSQL)
+		**out = **in
+	}
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]PostgresDatabaseSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]Dependency, len(*in))
+		copy(*out, *in)
+	}
+	if in.DisableDefaultPodScheduling != nil {
+		in, out := &in.DisableDefaultPodScheduling, &out.DisableDefaultPodScheduling
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Features != nil {
+		in, out := &in.Features, &out.Features
+		*out = make(map[string]bool, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]corev1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.InstanceSets != nil {
+		in, out := &in.InstanceSets, &out.InstanceSets
+		*out = make([]PostgresInstanceSetSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HealthProbes != nil {
+		in, out := &in.HealthProbes, &out.HealthProbes
+		*out = new(HealthProbesSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IntegrityChecks != nil {
+		in, out := &in.IntegrityChecks, &out.IntegrityChecks
+		*out = new(IntegrityChecksSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(MaintenanceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Reindex != nil {
+		in, out := &in.Reindex, &out.Reindex
+		*out = new(ReindexSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OpenShift != nil {
+		in, out := &in.OpenShift, &out.OpenShift
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Partitioning != nil {
+		in, out := &in.Partitioning, &out.Partitioning
+		*out = new(PartitioningSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Patroni != nil {
+		in, out := &in.Patroni, &out.Patroni
+		*out = new(PatroniSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Paused != nil {
+		in, out := &in.Paused, &out.Paused
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ReadOnly != nil {
+		in, out := &in.ReadOnly, &out.ReadOnly
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(PostgresProxySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UserInterface != nil {
+		in, out := &in.UserInterface, &out.UserInterface
+		*out = new(UserInterfaceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(MonitoringSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		*out = new(ServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReplicaService != nil {
+		in, out := &in.ReplicaService, &out.ReplicaService
+		*out = new(ServiceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DNS != nil {
+		in, out := &in.DNS, &out.DNS
+		*out = new(PostgresClusterDNS)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceMesh != nil {
+		in, out := &in.ServiceMesh, &out.ServiceMesh
+		*out = new(ServiceMeshSpec)
+		**out = **in
+	}
+	if in.Jobs != nil {
+		in, out := &in.Jobs, &out.Jobs
+		*out = new(JobsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Diagnostics != nil {
+		in, out := &in.Diagnostics, &out.Diagnostics
+		*out = new(DiagnosticsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IPFamilyPolicy != nil {
+		in, out := &in.IPFamilyPolicy, &out.IPFamilyPolicy
+		*out = new(corev1.IPFamilyPolicyType)
+		**out = **in
+	}
+	if in.IPFamilies != nil {
+		in, out := &in.IPFamilies, &out.IPFamilies
+		*out = make([]corev1.IPFamily, len(*in))
+		copy(*out, *in)
+	}
+	if in.Shutdown != nil {
+		in, out := &in.Shutdown, &out.Shutdown
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Standby != nil {
+		in, out := &in.Standby, &out.Standby
+		*out = new(PostgresStandbySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DemandReplicas != nil {
+		in, out := &in.DemandReplicas, &out.DemandReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SupplementalGroups != nil {
+		in, out := &in.SupplementalGroups, &out.SupplementalGroups
+		*out = make([]int64, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tuning != nil {
+		in, out := &in.Tuning, &out.Tuning
+		*out = new(TuningSpec)
+		**out = **in
+	}
+	if in.UpdateStrategy != nil {
+		in, out := &in.UpdateStrategy, &out.UpdateStrategy
+		*out = new(UpdateStrategySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]PostgresUserSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImmutableUserSecrets != nil {
+		in, out := &in.ImmutableUserSecrets, &out.ImmutableUserSecrets
+		*out = new(bool)
+		**out = **in
+	}
+	if in.WriteConnectionSecretToRef != nil {
+		in, out := &in.WriteConnectionSecretToRef, &out.WriteConnectionSecretToRef
+		*out = new(ConnectionSecretReference)
+		**out = **in
+	}
+	in.Config.DeepCopyInto(&out.Config)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresClusterSpec.
+func (in *PostgresClusterSpec) DeepCopy() *PostgresClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresClusterStatus) DeepCopyInto(out *PostgresClusterStatus) {
+	*out = *in
+	if in.InstanceSets != nil {
+		in, out := &in.InstanceSets, &out.InstanceSets
+		*out = make([]PostgresInstanceSetStatus, len(*in))
+		copy(*out, *in)
+	}
+	in.Patroni.DeepCopyInto(&out.Patroni)
+	if in.PGBackRest != nil {
+		in, out := &in.PGBackRest, &out.PGBackRest
+		*out = new(PGBackRestStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RegistrationRequired != nil {
+		in, out := &in.RegistrationRequired, &out.RegistrationRequired
+		*out = new(RegistrationRequirementStatus)
+		**out = **in
+	}
+	in.Proxy.DeepCopyInto(&out.Proxy)
+	if in.UserInterface != nil {
+		in, out := &in.UserInterface, &out.UserInterface
+		*out = new(PostgresUserInterfaceStatus)
+		**out = **in
+	}
+	if in.UserSecrets != nil {
+		in, out := &in.UserSecrets, &out.UserSecrets
+		*out = make([]PostgresUserSecretStatus, len(*in))
+		copy(*out, *in)
+	}
+	out.Monitoring = in.Monitoring
+	if in.DatabaseInitSQL != nil {
+		in, out := &in.DatabaseInitSQL, &out.DatabaseInitSQL
+		*out = new(string)
+		**out = **in
+	}
+	if in.DataChecksums != nil {
+		in, out := &in.DataChecksums, &out.DataChecksums
+		*out = new(DataChecksumsStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ChangeProtection != nil {
+		in, out := &in.ChangeProtection, &out.ChangeProtection
+		*out = new(ChangeProtectionStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Collations != nil {
+		in, out := &in.Collations, &out.Collations
+		*out = new(CollationsStatus)
+		**out = **in
+	}
+	if in.IntegrityChecks != nil {
+		in, out := &in.IntegrityChecks, &out.IntegrityChecks
+		*out = new(IntegrityChecksStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Reindex != nil {
+		in, out := &in.Reindex, &out.Reindex
+		*out = new(ReindexStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HealthProbes != nil {
+		in, out := &in.HealthProbes, &out.HealthProbes
+		*out = new(HealthProbesStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(MaintenanceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Partitioning != nil {
+		in, out := &in.Partitioning, &out.Partitioning
+		*out = new(PartitioningStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.RestorePoints != nil {
+		in, out := &in.RestorePoints, &out.RestorePoints
+		*out = make([]RestorePointStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UpgradeUser != nil {
+		in, out := &in.UpgradeUser, &out.UpgradeUser
+		*out = new(UpgradeUserStatus)
+		**out = **in
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = make([]InstanceSetAutoscalingStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.FaultInjection != nil {
+		in, out := &in.FaultInjection, &out.FaultInjection
+		*out = new(FaultInjectionStatus)
+		**out = **in
+	}
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(ResourcesStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OrphanedResources != nil {
+		in, out := &in.OrphanedResources, &out.OrphanedResources
+		*out = make([]OrphanedResource, len(*in))
+		copy(*out, *in)
+	}
+	if in.EnabledFeatures != nil {
+		in, out := &in.EnabledFeatures, &out.EnabledFeatures
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.WALStreaming != nil {
+		in, out := &in.WALStreaming, &out.WALStreaming
+		*out = new(WALStreamingStatus)
+		**out = **in
+	}
+	if in.CertificateAuthority != nil {
+		in, out := &in.CertificateAuthority, &out.CertificateAuthority
+		*out = new(CertificateAuthorityStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CheckpointTuning != nil {
+		in, out := &in.CheckpointTuning, &out.CheckpointTuning
+		*out = new(CheckpointTuningStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Diagnostics != nil {
+		in, out := &in.Diagnostics, &out.Diagnostics
+		*out = new(DiagnosticsStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LifecycleHooks != nil {
+		in, out := &in.LifecycleHooks, &out.LifecycleHooks
+		*out = new(LifecycleHooksStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Split != nil {
+		in, out := &in.Split, &out.Split
+		*out = new(SplitStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresClusterStatus.
+func (in *PostgresClusterStatus) DeepCopy() *PostgresClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresClusterTemplate) DeepCopyInto(out *PostgresClusterTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresClusterTemplate.
+func (in *PostgresClusterTemplate) DeepCopy() *PostgresClusterTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresClusterTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresClusterTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresClusterTemplateList) DeepCopyInto(out *PostgresClusterTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PostgresClusterTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresClusterTemplateList.
+func (in *PostgresClusterTemplateList) DeepCopy() *PostgresClusterTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresClusterTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresClusterTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresClusterTemplateSpec) DeepCopyInto(out *PostgresClusterTemplateSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresClusterTemplateSpec.
+func (in *PostgresClusterTemplateSpec) DeepCopy() *PostgresClusterTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresClusterTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresDatabaseClaim) DeepCopyInto(out *PostgresDatabaseClaim) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresDatabaseClaim.
+func (in *PostgresDatabaseClaim) DeepCopy() *PostgresDatabaseClaim {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresDatabaseClaim)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresDatabaseClaim) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresDatabaseClaimList) DeepCopyInto(out *PostgresDatabaseClaimList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]PostgresDatabaseClaim, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresDatabaseClaimList.
+func (in *PostgresDatabaseClaimList) DeepCopy() *PostgresDatabaseClaimList {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresDatabaseClaimList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *PostgresDatabaseClaimList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresDatabaseClaimSpec) DeepCopyInto(out *PostgresDatabaseClaimSpec) {
+	*out = *in
+	if in.SharedCluster != nil {
+		in, out := &in.SharedCluster, &out.SharedCluster
+		*out = new(PostgresClusterReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresDatabaseClaimSpec.
+func (in *PostgresDatabaseClaimSpec) DeepCopy() *PostgresDatabaseClaimSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresDatabaseClaimSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresDatabaseClaimStatus) DeepCopyInto(out *PostgresDatabaseClaimStatus) {
+	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]v1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Cluster != nil {
+		in, out := &in.Cluster, &out.Cluster
+		*out = new(PostgresClusterReference)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresDatabaseClaimStatus.
+func (in *PostgresDatabaseClaimStatus) DeepCopy() *PostgresDatabaseClaimStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresDatabaseClaimStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresDatabaseSpec) DeepCopyInto(out *PostgresDatabaseSpec) {
+	*out = *in
+	if in.ForeignServers != nil {
+		in, out := &in.ForeignServers, &out.ForeignServers
+		*out = make([]PostgresForeignServerSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresDatabaseSpec.
+func (in *PostgresDatabaseSpec) DeepCopy() *PostgresDatabaseSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresDatabaseSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresForeignSchemaSpec) DeepCopyInto(out *PostgresForeignSchemaSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresForeignSchemaSpec.
+func (in *PostgresForeignSchemaSpec) DeepCopy() *PostgresForeignSchemaSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresForeignSchemaSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresForeignServerSpec) DeepCopyInto(out *PostgresForeignServerSpec) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.UserMappings != nil {
+		in, out := &in.UserMappings, &out.UserMappings
+		*out = make([]PostgresUserMappingSpec, len(*in))
+		copy(*out, *in)
+	}
+	if in.ImportSchemas != nil {
+		in, out := &in.ImportSchemas, &out.ImportSchemas
+		*out = make([]PostgresForeignSchemaSpec, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresForeignServerSpec.
+func (in *PostgresForeignServerSpec) DeepCopy() *PostgresForeignServerSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresForeignServerSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresInstanceSetSpec) DeepCopyInto(out *PostgresInstanceSetSpec) {
+	*out = *in
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(Metadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		*out = new(corev1.Affinity)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Containers != nil {
+		in, out := &in.Containers, &out.Containers
+		*out = make([]corev1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InitContainers != nil {
+		in, out := &in.InitContainers, &out.InitContainers
+		*out = new(InstanceInitContainers)
+		(*in).DeepCopyInto(*out)
+	}
+	in.DataVolumeClaimSpec.DeepCopyInto(&out.DataVolumeClaimSpec)
+	if in.PriorityClassName != nil {
+		in, out := &in.PriorityClassName, &out.PriorityClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Autoscaling != nil {
+		in, out := &in.Autoscaling, &out.Autoscaling
+		*out = new(InstanceSetAutoscaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MinAvailable != nil {
+		in, out := &in.MinAvailable, &out.MinAvailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Sidecars != nil {
+		in, out := &in.Sidecars, &out.Sidecars
+		*out = new(InstanceSidecars)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TopologySpreadConstraints != nil {
+		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
+		*out = make([]corev1.TopologySpreadConstraint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.WALVolumeClaimSpec != nil {
+		in, out := &in.WALVolumeClaimSpec, &out.WALVolumeClaimSpec
+		*out = new(corev1.PersistentVolumeClaimSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TempVolumeClaimSpec != nil {
+		in, out := &in.TempVolumeClaimSpec, &out.TempVolumeClaimSpec
+		*out = new(corev1.PersistentVolumeClaimSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TablespaceVolumes != nil {
+		in, out := &in.TablespaceVolumes, &out.TablespaceVolumes
+		*out = make([]TablespaceVolume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresInstanceSetSpec.
+func (in *PostgresInstanceSetSpec) DeepCopy() *PostgresInstanceSetSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresInstanceSetSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresInstanceSetStatus) DeepCopyInto(out *PostgresInstanceSetStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresInstanceSetStatus.
+func (in *PostgresInstanceSetStatus) DeepCopy() *PostgresInstanceSetStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresInstanceSetStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresPasswordSpec) DeepCopyInto(out *PostgresPasswordSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresPasswordSpec.
+func (in *PostgresPasswordSpec) DeepCopy() *PostgresPasswordSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresPasswordSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresProxySpec) DeepCopyInto(out *PostgresProxySpec) {
+	*out = *in
+	if in.PGBouncer != nil {
+		in, out := &in.PGBouncer, &out.PGBouncer
+		*out = new(PGBouncerPodSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresProxySpec.
+func (in *PostgresProxySpec) DeepCopy() *PostgresProxySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresProxySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresProxyStatus) DeepCopyInto(out *PostgresProxyStatus) {
+	*out = *in
+	in.PGBouncer.DeepCopyInto(&out.PGBouncer)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresProxyStatus.
+func (in *PostgresProxyStatus) DeepCopy() *PostgresProxyStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresProxyStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresStandbySpec) DeepCopyInto(out *PostgresStandbySpec) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresStandbySpec.
+func (in *PostgresStandbySpec) DeepCopy() *PostgresStandbySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresStandbySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresUserInterfaceStatus) DeepCopyInto(out *PostgresUserInterfaceStatus) {
+	*out = *in
+	out.PGAdmin = in.PGAdmin
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresUserInterfaceStatus.
+func (in *PostgresUserInterfaceStatus) DeepCopy() *PostgresUserInterfaceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresUserInterfaceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresUserMappingSpec) DeepCopyInto(out *PostgresUserMappingSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresUserMappingSpec.
+func (in *PostgresUserMappingSpec) DeepCopy() *PostgresUserMappingSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresUserMappingSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresUserSecretStatus) DeepCopyInto(out *PostgresUserSecretStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresUserSecretStatus.
+func (in *PostgresUserSecretStatus) DeepCopy() *PostgresUserSecretStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresUserSecretStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PostgresUserSpec) DeepCopyInto(out *PostgresUserSpec) {
+	*out = *in
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]PostgresIdentifier, len(*in))
+		copy(*out, *in)
+	}
+	if in.Password != nil {
+		in, out := &in.Password, &out.Password
+		*out = new(PostgresPasswordSpec)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresUserSpec.
+func (in *PostgresUserSpec) DeepCopy() *PostgresUserSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PostgresUserSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProtectedChange) DeepCopyInto(out *ProtectedChange) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProtectedChange.
+func (in *ProtectedChange) DeepCopy() *ProtectedChange {
+	if in == nil {
+		return nil
+	}
+	out := new(ProtectedChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RegistrationRequirementStatus) DeepCopyInto(out *RegistrationRequirementStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistrationRequirementStatus.
+func (in *RegistrationRequirementStatus) DeepCopy() *RegistrationRequirementStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RegistrationRequirementStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReindexSpec) DeepCopyInto(out *ReindexSpec) {
+	*out = *in
+	if in.Concurrency != nil {
+		in, out := &in.Concurrency, &out.Concurrency
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReindexSpec.
+func (in *ReindexSpec) DeepCopy() *ReindexSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReindexSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReindexStatus) DeepCopyInto(out *ReindexStatus) {
+	*out = *in
+	if in.IntegrityCheckTime != nil {
+		in, out := &in.IntegrityCheckTime, &out.IntegrityCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReindexStatus.
+func (in *ReindexStatus) DeepCopy() *ReindexStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReindexStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepoAzure) DeepCopyInto(out *RepoAzure) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepoAzure.
+func (in *RepoAzure) DeepCopy() *RepoAzure {
+	if in == nil {
+		return nil
+	}
+	out := new(RepoAzure)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepoConnection) DeepCopyInto(out *RepoConnection) {
+	*out = *in
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(RepoProxy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.VerifyTLS != nil {
+		in, out := &in.VerifyTLS, &out.VerifyTLS
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepoConnection.
+func (in *RepoConnection) DeepCopy() *RepoConnection {
+	if in == nil {
+		return nil
+	}
+	out := new(RepoConnection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepoGCS) DeepCopyInto(out *RepoGCS) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepoGCS.
+func (in *RepoGCS) DeepCopy() *RepoGCS {
+	if in == nil {
+		return nil
+	}
+	out := new(RepoGCS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepoHostStatus) DeepCopyInto(out *RepoHostStatus) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepoHostStatus.
+func (in *RepoHostStatus) DeepCopy() *RepoHostStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RepoHostStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepoPVC) DeepCopyInto(out *RepoPVC) {
+	*out = *in
+	in.VolumeClaimSpec.DeepCopyInto(&out.VolumeClaimSpec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepoPVC.
+func (in *RepoPVC) DeepCopy() *RepoPVC {
+	if in == nil {
+		return nil
+	}
+	out := new(RepoPVC)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepoProxy) DeepCopyInto(out *RepoProxy) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepoProxy.
+func (in *RepoProxy) DeepCopy() *RepoProxy {
+	if in == nil {
+		return nil
+	}
+	out := new(RepoProxy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepoS3) DeepCopyInto(out *RepoS3) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepoS3.
+func (in *RepoS3) DeepCopy() *RepoS3 {
+	if in == nil {
+		return nil
+	}
+	out := new(RepoS3)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepoSFTP) DeepCopyInto(out *RepoSFTP) {
+	*out = *in
 	if in.Port != nil {
 		in, out := &in.Port, &out.Port
 		*out = new(int32)
 		**out = **in
 	}
-	if in.Proxy != nil {
-		in, out := &in.Proxy, &out.Proxy
-		*out = new(PostgresProxySpec)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.UserInterface != nil {
-		in, out := &in.UserInterface, &out.UserInterface
-		*out = new(UserInterfaceSpec)
-		(*in).DeepCopyInto(*out)
+	in.KnownHosts.DeepCopyInto(&out.KnownHosts)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepoSFTP.
+func (in *RepoSFTP) DeepCopy() *RepoSFTP {
+	if in == nil {
+		return nil
 	}
-	if in.Monitoring != nil {
-		in, out := &in.Monitoring, &out.Monitoring
-		*out = new(MonitoringSpec)
-		(*in).DeepCopyInto(*out)
+	out := new(RepoSFTP)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RepoStatus) DeepCopyInto(out *RepoStatus) {
+	*out = *in
+	if in.FullBackupWAL != nil {
+		in, out := &in.FullBackupWAL, &out.FullBackupWAL
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.Service != nil {
-		in, out := &in.Service, &out.Service
-		*out = new(ServiceSpec)
-		(*in).DeepCopyInto(*out)
+	if in.LatestBackupCompletion != nil {
+		in, out := &in.LatestBackupCompletion, &out.LatestBackupCompletion
+		*out = (*in).DeepCopy()
 	}
-	if in.ReplicaService != nil {
-		in, out := &in.ReplicaService, &out.ReplicaService
-		*out = new(ServiceSpec)
-		(*in).DeepCopyInto(*out)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepoStatus.
+func (in *RepoStatus) DeepCopy() *RepoStatus {
+	if in == nil {
+		return nil
 	}
-	if in.Shutdown != nil {
-		in, out := &in.Shutdown, &out.Shutdown
-		*out = new(bool)
-		**out = **in
+	out := new(RepoStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourcesStatus) DeepCopyInto(out *ResourcesStatus) {
+	*out = *in
+	if in.Requests != nil {
+		in, out := &in.Requests, &out.Requests
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
 	}
-	if in.Standby != nil {
-		in, out := &in.Standby, &out.Standby
-		*out = new(PostgresStandbySpec)
-		(*in).DeepCopyInto(*out)
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
 	}
-	if in.SupplementalGroups != nil {
-		in, out := &in.SupplementalGroups, &out.SupplementalGroups
-		*out = make([]int64, len(*in))
-		copy(*out, *in)
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
 	}
-	if in.Users != nil {
-		in, out := &in.Users, &out.Users
-		*out = make([]PostgresUserSpec, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
 		}
 	}
-	in.Config.DeepCopyInto(&out.Config)
+	if in.UsageTime != nil {
+		in, out := &in.UsageTime, &out.UsageTime
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresClusterSpec.
-func (in *PostgresClusterSpec) DeepCopy() *PostgresClusterSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourcesStatus.
+func (in *ResourcesStatus) DeepCopy() *ResourcesStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PostgresClusterSpec)
+	out := new(ResourcesStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostgresClusterStatus) DeepCopyInto(out *PostgresClusterStatus) {
+func (in *RestorePointStatus) DeepCopyInto(out *RestorePointStatus) {
 	*out = *in
-	if in.InstanceSets != nil {
-		in, out := &in.InstanceSets, &out.InstanceSets
-		*out = make([]PostgresInstanceSetStatus, len(*in))
-		copy(*out, *in)
-	}
-	in.Patroni.DeepCopyInto(&out.Patroni)
-	if in.PGBackRest != nil {
-		in, out := &in.PGBackRest, &out.PGBackRest
-		*out = new(PGBackRestStatus)
-		(*in).DeepCopyInto(*out)
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestorePointStatus.
+func (in *RestorePointStatus) DeepCopy() *RestorePointStatus {
+	if in == nil {
+		return nil
 	}
-	if in.RegistrationRequired != nil {
-		in, out := &in.RegistrationRequired, &out.RegistrationRequired
-		*out = new(RegistrationRequirementStatus)
+	out := new(RestorePointStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RollingUpdateSpec) DeepCopyInto(out *RollingUpdateSpec) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(int32)
 		**out = **in
 	}
-	out.Proxy = in.Proxy
-	if in.UserInterface != nil {
-		in, out := &in.UserInterface, &out.UserInterface
-		*out = new(PostgresUserInterfaceStatus)
+	if in.SwitchoverPrimary != nil {
+		in, out := &in.SwitchoverPrimary, &out.SwitchoverPrimary
+		*out = new(bool)
 		**out = **in
 	}
-	out.Monitoring = in.Monitoring
-	if in.DatabaseInitSQL != nil {
-		in, out := &in.DatabaseInitSQL, &out.DatabaseInitSQL
-		*out = new(string)
+	if in.MaxLagBytes != nil {
+		in, out := &in.MaxLagBytes, &out.MaxLagBytes
+		*out = new(int64)
 		**out = **in
 	}
-	if in.Conditions != nil {
-		in, out := &in.Conditions, &out.Conditions
-		*out = make([]v1.Condition, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RollingUpdateSpec.
+func (in *RollingUpdateSpec) DeepCopy() *RollingUpdateSpec {
+	if in == nil {
+		return nil
 	}
+	out := new(RollingUpdateSpec)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresClusterStatus.
-func (in *PostgresClusterStatus) DeepCopy() *PostgresClusterStatus {
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in SchemalessObject) DeepCopyInto(out *SchemalessObject) {
+	{
+		in := &in
+		clone := in.DeepCopy()
+		*out = *clone
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServerGroup) DeepCopyInto(out *ServerGroup) {
+	*out = *in
+	in.PostgresClusterSelector.DeepCopyInto(&out.PostgresClusterSelector)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerGroup.
+func (in *ServerGroup) DeepCopy() *ServerGroup {
 	if in == nil {
 		return nil
 	}
-	out := new(PostgresClusterStatus)
+	out := new(ServerGroup)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostgresInstanceSetSpec) DeepCopyInto(out *PostgresInstanceSetSpec) {
+func (in *ServiceMeshSpec) DeepCopyInto(out *ServiceMeshSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceMeshSpec.
+func (in *ServiceMeshSpec) DeepCopy() *ServiceMeshSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceMeshSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServiceSpec) DeepCopyInto(out *ServiceSpec) {
 	*out = *in
 	if in.Metadata != nil {
 		in, out := &in.Metadata, &out.Metadata
 		*out = new(Metadata)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.Affinity != nil {
-		in, out := &in.Affinity, &out.Affinity
-		*out = new(corev1.Affinity)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Containers != nil {
-		in, out := &in.Containers, &out.Containers
-		*out = make([]corev1.Container, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	in.DataVolumeClaimSpec.DeepCopyInto(&out.DataVolumeClaimSpec)
-	if in.PriorityClassName != nil {
-		in, out := &in.PriorityClassName, &out.PriorityClassName
-		*out = new(string)
-		**out = **in
-	}
-	if in.Replicas != nil {
-		in, out := &in.Replicas, &out.Replicas
+	if in.NodePort != nil {
+		in, out := &in.NodePort, &out.NodePort
 		*out = new(int32)
 		**out = **in
 	}
-	if in.MinAvailable != nil {
-		in, out := &in.MinAvailable, &out.MinAvailable
-		*out = new(intstr.IntOrString)
-		**out = **in
-	}
-	in.Resources.DeepCopyInto(&out.Resources)
-	if in.Sidecars != nil {
-		in, out := &in.Sidecars, &out.Sidecars
-		*out = new(InstanceSidecars)
-		(*in).DeepCopyInto(*out)
-	}
-	if in.Tolerations != nil {
-		in, out := &in.Tolerations, &out.Tolerations
-		*out = make([]corev1.Toleration, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.TopologySpreadConstraints != nil {
-		in, out := &in.TopologySpreadConstraints, &out.TopologySpreadConstraints
-		*out = make([]corev1.TopologySpreadConstraint, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceSpec.
+func (in *ServiceSpec) DeepCopy() *ServiceSpec {
+	if in == nil {
+		return nil
 	}
-	if in.WALVolumeClaimSpec != nil {
-		in, out := &in.WALVolumeClaimSpec, &out.WALVolumeClaimSpec
-		*out = new(corev1.PersistentVolumeClaimSpec)
+	out := new(ServiceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Sidecar) DeepCopyInto(out *Sidecar) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = new(corev1.ResourceRequirements)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.TablespaceVolumes != nil {
-		in, out := &in.TablespaceVolumes, &out.TablespaceVolumes
-		*out = make([]TablespaceVolume, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresInstanceSetSpec.
-func (in *PostgresInstanceSetSpec) DeepCopy() *PostgresInstanceSetSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Sidecar.
+func (in *Sidecar) DeepCopy() *Sidecar {
 	if in == nil {
 		return nil
 	}
-	out := new(PostgresInstanceSetSpec)
+	out := new(Sidecar)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostgresInstanceSetStatus) DeepCopyInto(out *PostgresInstanceSetStatus) {
+func (in *SplitStatus) DeepCopyInto(out *SplitStatus) {
 	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresInstanceSetStatus.
-func (in *PostgresInstanceSetStatus) DeepCopy() *PostgresInstanceSetStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SplitStatus.
+func (in *SplitStatus) DeepCopy() *SplitStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PostgresInstanceSetStatus)
+	out := new(SplitStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostgresPasswordSpec) DeepCopyInto(out *PostgresPasswordSpec) {
+func (in *StandalonePGAdminConfiguration) DeepCopyInto(out *StandalonePGAdminConfiguration) {
 	*out = *in
+	if in.ConfigDatabaseURI != nil {
+		in, out := &in.ConfigDatabaseURI, &out.ConfigDatabaseURI
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Files != nil {
+		in, out := &in.Files, &out.Files
+		*out = make([]corev1.VolumeProjection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LDAPBindPassword != nil {
+		in, out := &in.LDAPBindPassword, &out.LDAPBindPassword
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Mail != nil {
+		in, out := &in.Mail, &out.Mail
+		*out = new(StandalonePGAdminMail)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MFA != nil {
+		in, out := &in.MFA, &out.MFA
+		*out = new(StandalonePGAdminMFA)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OAuth2 != nil {
+		in, out := &in.OAuth2, &out.OAuth2
+		*out = new(StandalonePGAdminOAuth2)
+		(*in).DeepCopyInto(*out)
+	}
+	in.Settings.DeepCopyInto(&out.Settings)
+	if in.TrustedProxies != nil {
+		in, out := &in.TrustedProxies, &out.TrustedProxies
+		*out = new(StandalonePGAdminTrustedProxies)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresPasswordSpec.
-func (in *PostgresPasswordSpec) DeepCopy() *PostgresPasswordSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StandalonePGAdminConfiguration.
+func (in *StandalonePGAdminConfiguration) DeepCopy() *StandalonePGAdminConfiguration {
 	if in == nil {
 		return nil
 	}
-	out := new(PostgresPasswordSpec)
+	out := new(StandalonePGAdminConfiguration)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostgresProxySpec) DeepCopyInto(out *PostgresProxySpec) {
+func (in *StandalonePGAdminGunicorn) DeepCopyInto(out *StandalonePGAdminGunicorn) {
 	*out = *in
-	if in.PGBouncer != nil {
-		in, out := &in.PGBouncer, &out.PGBouncer
-		*out = new(PGBouncerPodSpec)
-		(*in).DeepCopyInto(*out)
+	if in.Threads != nil {
+		in, out := &in.Threads, &out.Threads
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresProxySpec.
-func (in *PostgresProxySpec) DeepCopy() *PostgresProxySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StandalonePGAdminGunicorn.
+func (in *StandalonePGAdminGunicorn) DeepCopy() *StandalonePGAdminGunicorn {
 	if in == nil {
 		return nil
 	}
-	out := new(PostgresProxySpec)
+	out := new(StandalonePGAdminGunicorn)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostgresProxyStatus) DeepCopyInto(out *PostgresProxyStatus) {
+func (in *StandalonePGAdminIngress) DeepCopyInto(out *StandalonePGAdminIngress) {
 	*out = *in
-	out.PGBouncer = in.PGBouncer
+	if in.Metadata != nil {
+		in, out := &in.Metadata, &out.Metadata
+		*out = new(Metadata)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.IngressClassName != nil {
+		in, out := &in.IngressClassName, &out.IngressClassName
+		*out = new(string)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresProxyStatus.
-func (in *PostgresProxyStatus) DeepCopy() *PostgresProxyStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StandalonePGAdminIngress.
+func (in *StandalonePGAdminIngress) DeepCopy() *StandalonePGAdminIngress {
 	if in == nil {
 		return nil
 	}
-	out := new(PostgresProxyStatus)
+	out := new(StandalonePGAdminIngress)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostgresStandbySpec) DeepCopyInto(out *PostgresStandbySpec) {
+func (in *StandalonePGAdminMail) DeepCopyInto(out *StandalonePGAdminMail) {
 	*out = *in
 	if in.Port != nil {
 		in, out := &in.Port, &out.Port
@@ -1959,309 +4875,359 @@ func (in *PostgresStandbySpec) DeepCopyInto(out *PostgresStandbySpec) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresStandbySpec.
-func (in *PostgresStandbySpec) DeepCopy() *PostgresStandbySpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StandalonePGAdminMail.
+func (in *StandalonePGAdminMail) DeepCopy() *StandalonePGAdminMail {
 	if in == nil {
 		return nil
 	}
-	out := new(PostgresStandbySpec)
+	out := new(StandalonePGAdminMail)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostgresUserInterfaceStatus) DeepCopyInto(out *PostgresUserInterfaceStatus) {
+func (in *StandalonePGAdminMFA) DeepCopyInto(out *StandalonePGAdminMFA) {
 	*out = *in
-	out.PGAdmin = in.PGAdmin
+	if in.Methods != nil {
+		in, out := &in.Methods, &out.Methods
+		*out = make([]StandalonePGAdminMFAMethod, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresUserInterfaceStatus.
-func (in *PostgresUserInterfaceStatus) DeepCopy() *PostgresUserInterfaceStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StandalonePGAdminMFA.
+func (in *StandalonePGAdminMFA) DeepCopy() *StandalonePGAdminMFA {
 	if in == nil {
 		return nil
 	}
-	out := new(PostgresUserInterfaceStatus)
+	out := new(StandalonePGAdminMFA)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PostgresUserSpec) DeepCopyInto(out *PostgresUserSpec) {
+func (in *StandalonePGAdminOAuth2) DeepCopyInto(out *StandalonePGAdminOAuth2) {
 	*out = *in
-	if in.Databases != nil {
-		in, out := &in.Databases, &out.Databases
-		*out = make([]PostgresIdentifier, len(*in))
-		copy(*out, *in)
-	}
-	if in.Password != nil {
-		in, out := &in.Password, &out.Password
-		*out = new(PostgresPasswordSpec)
+	if in.AutoCreateUser != nil {
+		in, out := &in.AutoCreateUser, &out.AutoCreateUser
+		*out = new(bool)
 		**out = **in
 	}
+	if in.Providers != nil {
+		in, out := &in.Providers, &out.Providers
+		*out = make([]StandalonePGAdminOAuth2Provider, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PostgresUserSpec.
-func (in *PostgresUserSpec) DeepCopy() *PostgresUserSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StandalonePGAdminOAuth2.
+func (in *StandalonePGAdminOAuth2) DeepCopy() *StandalonePGAdminOAuth2 {
 	if in == nil {
 		return nil
 	}
-	out := new(PostgresUserSpec)
+	out := new(StandalonePGAdminOAuth2)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RegistrationRequirementStatus) DeepCopyInto(out *RegistrationRequirementStatus) {
+func (in *StandalonePGAdminOAuth2Provider) DeepCopyInto(out *StandalonePGAdminOAuth2Provider) {
 	*out = *in
+	in.ClientSecret.DeepCopyInto(&out.ClientSecret)
+	if in.Scopes != nil {
+		in, out := &in.Scopes, &out.Scopes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RegistrationRequirementStatus.
-func (in *RegistrationRequirementStatus) DeepCopy() *RegistrationRequirementStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StandalonePGAdminOAuth2Provider.
+func (in *StandalonePGAdminOAuth2Provider) DeepCopy() *StandalonePGAdminOAuth2Provider {
 	if in == nil {
 		return nil
 	}
-	out := new(RegistrationRequirementStatus)
+	out := new(StandalonePGAdminOAuth2Provider)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RepoAzure) DeepCopyInto(out *RepoAzure) {
+func (in *StandalonePGAdminProbe) DeepCopyInto(out *StandalonePGAdminProbe) {
 	*out = *in
+	if in.InitialDelaySeconds != nil {
+		in, out := &in.InitialDelaySeconds, &out.InitialDelaySeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PeriodSeconds != nil {
+		in, out := &in.PeriodSeconds, &out.PeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepoAzure.
-func (in *RepoAzure) DeepCopy() *RepoAzure {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StandalonePGAdminProbe.
+func (in *StandalonePGAdminProbe) DeepCopy() *StandalonePGAdminProbe {
 	if in == nil {
 		return nil
 	}
-	out := new(RepoAzure)
+	out := new(StandalonePGAdminProbe)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RepoGCS) DeepCopyInto(out *RepoGCS) {
+func (in *StandalonePGAdminProbes) DeepCopyInto(out *StandalonePGAdminProbes) {
 	*out = *in
+	if in.Startup != nil {
+		in, out := &in.Startup, &out.Startup
+		*out = new(StandalonePGAdminProbe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Readiness != nil {
+		in, out := &in.Readiness, &out.Readiness
+		*out = new(StandalonePGAdminProbe)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Liveness != nil {
+		in, out := &in.Liveness, &out.Liveness
+		*out = new(StandalonePGAdminProbe)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepoGCS.
-func (in *RepoGCS) DeepCopy() *RepoGCS {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StandalonePGAdminProbes.
+func (in *StandalonePGAdminProbes) DeepCopy() *StandalonePGAdminProbes {
 	if in == nil {
 		return nil
 	}
-	out := new(RepoGCS)
+	out := new(StandalonePGAdminProbes)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RepoHostStatus) DeepCopyInto(out *RepoHostStatus) {
+func (in *StandalonePGAdminService) DeepCopyInto(out *StandalonePGAdminService) {
 	*out = *in
-	out.TypeMeta = in.TypeMeta
+	in.ServiceSpec.DeepCopyInto(&out.ServiceSpec)
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepoHostStatus.
-func (in *RepoHostStatus) DeepCopy() *RepoHostStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StandalonePGAdminService.
+func (in *StandalonePGAdminService) DeepCopy() *StandalonePGAdminService {
 	if in == nil {
 		return nil
 	}
-	out := new(RepoHostStatus)
+	out := new(StandalonePGAdminService)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RepoPVC) DeepCopyInto(out *RepoPVC) {
+func (in *StandalonePGAdminTLS) DeepCopyInto(out *StandalonePGAdminTLS) {
 	*out = *in
-	in.VolumeClaimSpec.DeepCopyInto(&out.VolumeClaimSpec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepoPVC.
-func (in *RepoPVC) DeepCopy() *RepoPVC {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StandalonePGAdminTLS.
+func (in *StandalonePGAdminTLS) DeepCopy() *StandalonePGAdminTLS {
 	if in == nil {
 		return nil
 	}
-	out := new(RepoPVC)
+	out := new(StandalonePGAdminTLS)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RepoS3) DeepCopyInto(out *RepoS3) {
+func (in *StandalonePGAdminTrustedProxies) DeepCopyInto(out *StandalonePGAdminTrustedProxies) {
 	*out = *in
+	if in.For != nil {
+		in, out := &in.For, &out.For
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Host != nil {
+		in, out := &in.Host, &out.Host
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Prefix != nil {
+		in, out := &in.Prefix, &out.Prefix
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Proto != nil {
+		in, out := &in.Proto, &out.Proto
+		*out = new(int32)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepoS3.
-func (in *RepoS3) DeepCopy() *RepoS3 {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StandalonePGAdminTrustedProxies.
+func (in *StandalonePGAdminTrustedProxies) DeepCopy() *StandalonePGAdminTrustedProxies {
 	if in == nil {
 		return nil
 	}
-	out := new(RepoS3)
+	out := new(StandalonePGAdminTrustedProxies)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *RepoStatus) DeepCopyInto(out *RepoStatus) {
+func (in *TablespaceVolume) DeepCopyInto(out *TablespaceVolume) {
 	*out = *in
+	in.DataVolumeClaimSpec.DeepCopyInto(&out.DataVolumeClaimSpec)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RepoStatus.
-func (in *RepoStatus) DeepCopy() *RepoStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TablespaceVolume.
+func (in *TablespaceVolume) DeepCopy() *TablespaceVolume {
 	if in == nil {
 		return nil
 	}
-	out := new(RepoStatus)
+	out := new(TablespaceVolume)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in SchemalessObject) DeepCopyInto(out *SchemalessObject) {
-	{
-		in := &in
-		clone := in.DeepCopy()
-		*out = *clone
-	}
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ServerGroup) DeepCopyInto(out *ServerGroup) {
+func (in *TuningSpec) DeepCopyInto(out *TuningSpec) {
 	*out = *in
-	in.PostgresClusterSelector.DeepCopyInto(&out.PostgresClusterSelector)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerGroup.
-func (in *ServerGroup) DeepCopy() *ServerGroup {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TuningSpec.
+func (in *TuningSpec) DeepCopy() *TuningSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ServerGroup)
+	out := new(TuningSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *ServiceSpec) DeepCopyInto(out *ServiceSpec) {
+func (in *UpdateStrategySpec) DeepCopyInto(out *UpdateStrategySpec) {
 	*out = *in
-	if in.Metadata != nil {
-		in, out := &in.Metadata, &out.Metadata
-		*out = new(Metadata)
+	if in.RollingUpdate != nil {
+		in, out := &in.RollingUpdate, &out.RollingUpdate
+		*out = new(RollingUpdateSpec)
 		(*in).DeepCopyInto(*out)
 	}
-	if in.NodePort != nil {
-		in, out := &in.NodePort, &out.NodePort
-		*out = new(int32)
-		**out = **in
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServiceSpec.
-func (in *ServiceSpec) DeepCopy() *ServiceSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpdateStrategySpec.
+func (in *UpdateStrategySpec) DeepCopy() *UpdateStrategySpec {
 	if in == nil {
 		return nil
 	}
-	out := new(ServiceSpec)
+	out := new(UpdateStrategySpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *Sidecar) DeepCopyInto(out *Sidecar) {
+func (in *UpgradeOperation) DeepCopyInto(out *UpgradeOperation) {
 	*out = *in
-	if in.Resources != nil {
-		in, out := &in.Resources, &out.Resources
-		*out = new(corev1.ResourceRequirements)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Sidecar.
-func (in *Sidecar) DeepCopy() *Sidecar {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeOperation.
+func (in *UpgradeOperation) DeepCopy() *UpgradeOperation {
 	if in == nil {
 		return nil
 	}
-	out := new(Sidecar)
+	out := new(UpgradeOperation)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StandalonePGAdminConfiguration) DeepCopyInto(out *StandalonePGAdminConfiguration) {
+func (in *UpgradeUserStatus) DeepCopyInto(out *UpgradeUserStatus) {
 	*out = *in
-	if in.Files != nil {
-		in, out := &in.Files, &out.Files
-		*out = make([]corev1.VolumeProjection, len(*in))
-		for i := range *in {
-			(*in)[i].DeepCopyInto(&(*out)[i])
-		}
-	}
-	if in.LDAPBindPassword != nil {
-		in, out := &in.LDAPBindPassword, &out.LDAPBindPassword
-		*out = new(corev1.SecretKeySelector)
-		(*in).DeepCopyInto(*out)
-	}
-	in.Settings.DeepCopyInto(&out.Settings)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StandalonePGAdminConfiguration.
-func (in *StandalonePGAdminConfiguration) DeepCopy() *StandalonePGAdminConfiguration {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeUserStatus.
+func (in *UpgradeUserStatus) DeepCopy() *UpgradeUserStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(StandalonePGAdminConfiguration)
+	out := new(UpgradeUserStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TablespaceVolume) DeepCopyInto(out *TablespaceVolume) {
+func (in *UserInterfaceSpec) DeepCopyInto(out *UserInterfaceSpec) {
 	*out = *in
-	in.DataVolumeClaimSpec.DeepCopyInto(&out.DataVolumeClaimSpec)
+	if in.PGAdmin != nil {
+		in, out := &in.PGAdmin, &out.PGAdmin
+		*out = new(PGAdminPodSpec)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TablespaceVolume.
-func (in *TablespaceVolume) DeepCopy() *TablespaceVolume {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserInterfaceSpec.
+func (in *UserInterfaceSpec) DeepCopy() *UserInterfaceSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(TablespaceVolume)
+	out := new(UserInterfaceSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *UpgradeOperation) DeepCopyInto(out *UpgradeOperation) {
+func (in *WALStreamingSpec) DeepCopyInto(out *WALStreamingSpec) {
 	*out = *in
+	in.VolumeClaimSpec.DeepCopyInto(&out.VolumeClaimSpec)
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Synchronous != nil {
+		in, out := &in.Synchronous, &out.Synchronous
+		*out = new(bool)
+		**out = **in
+	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeOperation.
-func (in *UpgradeOperation) DeepCopy() *UpgradeOperation {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WALStreamingSpec.
+func (in *WALStreamingSpec) DeepCopy() *WALStreamingSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(UpgradeOperation)
+	out := new(WALStreamingSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *UserInterfaceSpec) DeepCopyInto(out *UserInterfaceSpec) {
+func (in *WALStreamingStatus) DeepCopyInto(out *WALStreamingStatus) {
 	*out = *in
-	if in.PGAdmin != nil {
-		in, out := &in.PGAdmin, &out.PGAdmin
-		*out = new(PGAdminPodSpec)
-		(*in).DeepCopyInto(*out)
-	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserInterfaceSpec.
-func (in *UserInterfaceSpec) DeepCopy() *UserInterfaceSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WALStreamingStatus.
+func (in *WALStreamingStatus) DeepCopy() *WALStreamingStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(UserInterfaceSpec)
+	out := new(WALStreamingStatus)
 	in.DeepCopyInto(out)
 	return out
 }